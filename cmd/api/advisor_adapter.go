@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	advisorrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/repository"
+	advisorservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/service"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+)
+
+// advisorPlanListLimit caps how many plans the advisor workspace lists
+const advisorPlanListLimit = 100
+
+// advisorWorkspaceAdapter adapts the plan and insights services to advisorservice's WorkspaceSource interface
+type advisorWorkspaceAdapter struct {
+	plans     *planservice.PlanService
+	insights  *insights.Service
+	snapshots *shareSnapshotAdapter // Builds the same plan and report views as share links
+}
+
+// newAdvisorWorkspaceAdapter creates a new adapter
+func newAdvisorWorkspaceAdapter(plans *planservice.PlanService, insightsSvc *insights.Service) advisorservice.WorkspaceSource {
+	return &advisorWorkspaceAdapter{
+		plans:     plans,
+		insights:  insightsSvc,
+		snapshots: &shareSnapshotAdapter{plans: plans, insights: insightsSvc},
+	}
+}
+
+// ListPlans implements advisorservice.WorkspaceSource
+func (a *advisorWorkspaceAdapter) ListPlans(ctx context.Context, clientID uuid.UUID) ([]advisorservice.PlanRef, error) {
+	plans, _, err := a.plans.ListPlans(ctx, clientID, nil, advisorPlanListLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]advisorservice.PlanRef, 0, len(plans))
+	for _, p := range plans {
+		refs = append(refs, advisorservice.PlanRef{
+			ID:           p.ID,
+			Name:         p.Name,
+			Status:       string(p.Status),
+			CurrencyCode: p.CurrencyCode,
+		})
+	}
+	return refs, nil
+}
+
+// Plan implements advisorservice.WorkspaceSource
+func (a *advisorWorkspaceAdapter) Plan(ctx context.Context, clientID, planID uuid.UUID) (*advisorservice.PlanView, error) {
+	snapshot, err := a.snapshots.PlanSnapshot(ctx, clientID, planID)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	view := &advisorservice.PlanView{
+		Name:               snapshot.Name,
+		CurrencyCode:       snapshot.CurrencyCode,
+		PeriodStart:        snapshot.PeriodStart,
+		PeriodEnd:          snapshot.PeriodEnd,
+		TotalBudgetedMinor: snapshot.TotalBudgetedMinor,
+		TotalActualMinor:   snapshot.TotalActualMinor,
+	}
+	for _, g := range snapshot.Groups {
+		group := advisorservice.PlanGroup{Name: g.Name}
+		for _, item := range g.Items {
+			group.Items = append(group.Items, advisorservice.PlanItem{
+				Name:          item.Name,
+				ItemType:      item.ItemType,
+				BudgetedMinor: item.BudgetedMinor,
+				ActualMinor:   item.ActualMinor,
+			})
+		}
+		view.Groups = append(view.Groups, group)
+	}
+	return view, nil
+}
+
+// MonthlyReport implements advisorservice.WorkspaceSource
+func (a *advisorWorkspaceAdapter) MonthlyReport(ctx context.Context, clientID uuid.UUID, monthStart time.Time) (*advisorservice.ReportView, error) {
+	snapshot, err := a.snapshots.MonthlyReportSnapshot(ctx, clientID, monthStart)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	view := &advisorservice.ReportView{
+		MonthStart:         snapshot.MonthStart,
+		TotalSpendMinor:    snapshot.TotalSpendMinor,
+		TotalIncomeMinor:   snapshot.TotalIncomeMinor,
+		NetMinor:           snapshot.NetMinor,
+		SpendChangePercent: snapshot.SpendChangePercent,
+		Highlights:         snapshot.Highlights,
+	}
+	for _, c := range snapshot.TopCategories {
+		view.TopCategories = append(view.TopCategories, advisorservice.NamedAmount{Name: c.Name, AmountMinor: c.AmountMinor})
+	}
+	for _, m := range snapshot.TopMerchants {
+		view.TopMerchants = append(view.TopMerchants, advisorservice.NamedAmount{Name: m.Name, AmountMinor: m.AmountMinor})
+	}
+	return view, nil
+}
+
+// SpendingTrend implements advisorservice.WorkspaceSource
+func (a *advisorWorkspaceAdapter) SpendingTrend(ctx context.Context, clientID uuid.UUID, from, to time.Time) ([]advisorservice.TrendPoint, error) {
+	type key struct {
+		month    time.Time
+		currency string
+	}
+	var points []advisorservice.TrendPoint
+	index := make(map[key]int)
+	point := func(row insights.AggregateRow) *advisorservice.TrendPoint {
+		k := key{month: *row.Month, currency: row.CurrencyCode}
+		i, ok := index[k]
+		if !ok {
+			i = len(points)
+			index[k] = i
+			points = append(points, advisorservice.TrendPoint{Month: *row.Month, CurrencyCode: row.CurrencyCode})
+		}
+		return &points[i]
+	}
+
+	for _, direction := range []insights.AggregateDirection{insights.DirectionExpense, insights.DirectionIncome} {
+		rows, err := a.insights.QueryAggregates(ctx, clientID, insights.AggregateQuery{
+			Dimensions: []insights.AggregateDimension{insights.DimensionMonth},
+			Filter:     insights.AggregateFilter{From: &from, To: &to, Direction: direction},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if row.Month == nil || row.SumMinor == nil {
+				continue
+			}
+			p := point(row)
+			if direction == insights.DirectionExpense {
+				p.SpendMinor = -*row.SumMinor
+			} else {
+				p.IncomeMinor = *row.SumMinor
+			}
+		}
+	}
+	return points, nil
+}
+
+// advisorNotesAdapter adapts insights.Service to advisorservice's AnnotationNotifier interface
+type advisorNotesAdapter struct {
+	insights *insights.Service
+}
+
+// newAdvisorNotesAdapter creates a new adapter
+func newAdvisorNotesAdapter(insightsSvc *insights.Service) advisorservice.AnnotationNotifier {
+	return &advisorNotesAdapter{insights: insightsSvc}
+}
+
+// NotifyAnnotation implements advisorservice.AnnotationNotifier
+func (a *advisorNotesAdapter) NotifyAnnotation(ctx context.Context, annotation *advisorrepo.Annotation) error {
+	note := &insights.AdvisorNote{
+		ID:             annotation.ID,
+		Recommendation: annotation.Kind == advisorrepo.KindRecommendation,
+		Subject:        string(annotation.Subject),
+		Body:           annotation.Body,
+	}
+	if annotation.AdvisorName != nil {
+		note.AdvisorName = *annotation.AdvisorName
+	}
+	return a.insights.TriggerAdvisorNoteAlert(ctx, annotation.ClientID, note)
+}
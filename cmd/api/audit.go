@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"github.com/google/uuid"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/audit"
+)
+
+// auditedProcedures are the destructive or permission-changing RPCs recorded in the audit log
+var auditedProcedures = []string{
+	echov1connect.FinanceServiceDeleteImportBatchProcedure,
+	echov1connect.FinanceServiceDeleteGoalProcedure,
+	echov1connect.FinanceServiceCreateCategoryRuleProcedure,
+	echov1connect.PlanServiceDeletePlanProcedure,
+	echov1connect.PlanServiceUpdatePlanStructureProcedure,
+	echov1connect.PlanServiceDeleteItemConfigProcedure,
+	echov1connect.AutomationServiceCreateRuleProcedure,
+	echov1connect.AutomationServiceUpdateRuleProcedure,
+	echov1connect.AutomationServiceDeleteRuleProcedure,
+	echov1connect.AdminUserServiceUpdateUserProcedure,
+	echov1connect.AdminUserServiceSetUserStatusProcedure,
+	echov1connect.AdminUserServiceSetUserRolesProcedure,
+}
+
+// newAuditInterceptor audits auditedProcedures, with before summaries of the
+// plans and import batches that are deleted or restructured
+func newAuditInterceptor(store audit.Store, plans *planservice.PlanService, imports importrepo.ImportRepository, logger *slog.Logger) *audit.Interceptor {
+	planSnapshot := func(ctx context.Context, actorID uuid.UUID, req any) (map[string]any, error) {
+		var planID string
+		switch msg := req.(type) {
+		case *echov1.DeletePlanRequest:
+			planID = msg.GetPlanId()
+		case *echov1.UpdatePlanStructureRequest:
+			planID = msg.GetPlanId()
+		}
+		id, err := uuid.Parse(planID)
+		if err != nil {
+			return nil, nil
+		}
+		details, err := plans.GetPlanWithDetails(ctx, actorID, id)
+		if err != nil || details == nil {
+			return nil, err
+		}
+		return map[string]any{
+			"name":                 details.Plan.Name,
+			"status":               details.Plan.Status,
+			"currency_code":        details.Plan.CurrencyCode,
+			"total_income_minor":   details.Plan.TotalIncomeMinor,
+			"total_expenses_minor": details.Plan.TotalExpensesMinor,
+			"groups":               len(details.Groups),
+			"categories":           len(details.Categories),
+			"items":                len(details.Items),
+		}, nil
+	}
+
+	importSnapshot := func(ctx context.Context, actorID uuid.UUID, req any) (map[string]any, error) {
+		msg, ok := req.(*echov1.DeleteImportBatchRequest)
+		if !ok {
+			return nil, nil
+		}
+		id, err := uuid.Parse(msg.GetImportJobId())
+		if err != nil {
+			return nil, nil
+		}
+		job, err := imports.GetImportJobByID(ctx, id)
+		if err != nil || job == nil || job.UserID != actorID {
+			return nil, err
+		}
+		return map[string]any{
+			"kind":          job.Kind,
+			"status":        job.Status,
+			"file_id":       job.FileID,
+			"rows_imported": job.RowsImported,
+			"requested_at":  job.RequestedAt,
+		}, nil
+	}
+
+	return audit.NewInterceptor(store, logger, auditedProcedures...).
+		WithSnapshot(echov1connect.PlanServiceDeletePlanProcedure, planSnapshot).
+		WithSnapshot(echov1connect.PlanServiceUpdatePlanStructureProcedure, planSnapshot).
+		WithSnapshot(echov1connect.FinanceServiceDeleteImportBatchProcedure, importSnapshot)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/user"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+)
+
+// calendarAdapter adapts user.UserRepo to calendar's CountryLookup interface
+type calendarAdapter struct {
+	repo user.UserRepo
+}
+
+// newCalendarAdapter creates a new adapter
+func newCalendarAdapter(repo user.UserRepo) calendar.CountryLookup {
+	return &calendarAdapter{repo: repo}
+}
+
+// UserCountry implements calendar.CountryLookup
+func (a *calendarAdapter) UserCountry(ctx context.Context, userID uuid.UUID) (string, error) {
+	profile, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if profile.Country == nil {
+		return "", nil
+	}
+	return *profile.Country, nil
+}
@@ -33,6 +33,9 @@ func (a *categorizationAdapter) CategorizeBatch(ctx context.Context, userID uuid
 			CleanMerchantName: r.CleanMerchantName,
 			CategoryID:        r.CategoryID,
 			IsRecurring:       r.IsRecurring,
+			RuleID:            r.RuleID,
+			MerchantID:        r.MerchantID,
+			MatchedPattern:    r.MatchedPattern,
 		}
 	}
 
@@ -53,8 +56,65 @@ func (a *categorizationAdapter) CategorizeBatchFast(ctx context.Context, userID
 			CleanMerchantName: r.CleanMerchantName,
 			CategoryID:        r.CategoryID,
 			IsRecurring:       r.IsRecurring,
+			RuleID:            r.RuleID,
+			MerchantID:        r.MerchantID,
+			MatchedPattern:    r.MatchedPattern,
 		}
 	}
 
 	return importResults, nil
 }
+
+// SuggestMatch implements importservice.CategorizationService using the fuzzy matcher
+func (a *categorizationAdapter) SuggestMatch(ctx context.Context, userID uuid.UUID, description string) (*importservice.MatchSuggestion, error) {
+	matches, err := a.svc.SuggestMerchantMatches(ctx, userID, description, 1)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+
+	m := matches[0]
+	return &importservice.MatchSuggestion{
+		Pattern:    m.Pattern,
+		CleanName:  m.CleanName,
+		CategoryID: m.CategoryID,
+		Score:      m.Score,
+		IsRule:     m.IsRule,
+	}, nil
+}
+
+// transactionRulesAdapter adapts categorization.Service to import's TransactionRuleExecutor interface
+type transactionRulesAdapter struct {
+	svc *categorization.Service
+}
+
+// newTransactionRulesAdapter creates a new adapter
+func newTransactionRulesAdapter(svc *categorization.Service) importservice.TransactionRuleExecutor {
+	return &transactionRulesAdapter{svc: svc}
+}
+
+// ApplyTransactionRules implements importservice.TransactionRuleExecutor
+func (a *transactionRulesAdapter) ApplyTransactionRules(ctx context.Context, userID uuid.UUID, inputs []importservice.TransactionRuleInput) ([]*importservice.TransactionRuleOutcome, error) {
+	catInputs := make([]categorization.TransactionRuleInput, len(inputs))
+	for i, in := range inputs {
+		catInputs[i] = categorization.TransactionRuleInput{Description: in.Description, AmountMinor: in.AmountMinor}
+	}
+
+	outcomes, err := a.svc.ApplyTransactionRules(ctx, userID, catInputs)
+	if err != nil {
+		return nil, err
+	}
+
+	importOutcomes := make([]*importservice.TransactionRuleOutcome, len(outcomes))
+	for i, o := range outcomes {
+		if o == nil {
+			continue
+		}
+		importOutcomes[i] = &importservice.TransactionRuleOutcome{
+			Tags:     o.Tags,
+			RenameTo: o.RenameTo,
+			RuleIDs:  o.RuleIDs,
+		}
+	}
+
+	return importOutcomes, nil
+}
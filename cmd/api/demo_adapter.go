@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	demoservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/demo/service"
+	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+)
+
+// demoPlanAdapter adapts planservice.PlanService to the demo service's
+// PlanCreator interface
+type demoPlanAdapter struct {
+	plans *planservice.PlanService
+}
+
+// newDemoPlanAdapter creates a new adapter
+func newDemoPlanAdapter(plans *planservice.PlanService) demoservice.PlanCreator {
+	return &demoPlanAdapter{plans: plans}
+}
+
+// CreateDemoPlan implements demoservice.PlanCreator: one group with a budget
+// item per category, set as the active plan
+func (a *demoPlanAdapter) CreateDemoPlan(ctx context.Context, userID uuid.UUID, name, currency string, budgets []demoservice.PlanBudget) (uuid.UUID, error) {
+	categories := make([]planservice.CreateCategoryInput, 0, len(budgets))
+	for _, budget := range budgets {
+		categories = append(categories, planservice.CreateCategoryInput{
+			Name: budget.Category,
+			Items: []planservice.CreateItemInput{{
+				Name:          budget.Category,
+				BudgetedMinor: budget.BudgetMinor,
+				ItemType:      planrepo.ItemTypeBudget,
+			}},
+		})
+	}
+
+	plan, err := a.plans.CreatePlan(ctx, userID, &planservice.CreatePlanInput{
+		Name:         name,
+		CurrencyCode: currency,
+		CategoryGroups: []planservice.CreateCategoryGroupInput{{
+			Name:       "Spending",
+			Categories: categories,
+		}},
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := a.plans.SetActivePlan(ctx, userID, plan.Plan.ID); err != nil {
+		return uuid.Nil, err
+	}
+	return plan.Plan.ID, nil
+}
@@ -1,42 +1,105 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/user"
 	userhandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/user/handler"
 
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/admin"
+	advisorrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/repository"
+	advisorservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/service"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/handler"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/repository"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/balance"
 	balancehandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/balance/handler"
+	billinghandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/handler"
+	billingrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/repository"
+	billingservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/service"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/categorization"
+	demorepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/demo/repository"
+	demoservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/demo/service"
 	financehandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/finance/handler"
 	goalshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/handler"
 	goalsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
 	goalsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/service"
+	householdrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/household/repository"
+	householdservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/household/service"
 	importhandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/handler"
 	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
 	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
 	insightshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights/handler"
+	installmentsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/installments/repository"
+	installmentsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/installments/service"
+	merchantsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/repository"
+	merchantsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/service"
+	notificationshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/handler"
+	notificationsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/repository"
+	notificationsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/service"
+	offlinesyncrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/offlinesync/repository"
+	offlinesyncservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/offlinesync/service"
+	onboardingrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/onboarding/repository"
+	onboardingservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/onboarding/service"
 	planhandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/handler"
 	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
 	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	purchasesrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/repository"
+	purchasesservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/service"
+	quotasrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/repository"
+	quotasservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/service"
+	reportshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/reports/handler"
+	reportsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/reports/service"
+	rewardsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/repository"
+	rewardsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/service"
+	sharelinkshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/handler"
+	sharelinksrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/repository"
+	sharelinksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/service"
 	subscriptionshandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/handler"
 	subscriptionsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/repository"
 	subscriptionsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/service"
+	telegramhandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/handler"
+	telegramrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/repository"
+	telegramservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/service"
+	trashrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/trash/repository"
+	trashservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/trash/service"
 	waitlisthandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/waitlist/handler"
 	waitlistrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/waitlist/repository"
 	waitlistservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/waitlist/service"
+	webhooksrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/repository"
+	webhooksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/service"
 
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/audit"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cache"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/chaos"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cron"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/featureflags"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/idempotency"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money/fx"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/pgnotify"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/push"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/sheets"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/speech"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/stripe"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/telegram"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webhook"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webpush"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
 )
 
 // Dependencies holds all application dependencies
@@ -47,42 +110,127 @@ type Dependencies struct {
 
 	// Repositories
 	AuthRepo           repository.AuthRepository
+	APIKeyRepo         repository.APIKeyRepository
 	UserRepo           user.UserRepo
 	ImportRepo         importrepo.ImportRepository
+	StorageRepo        importrepo.StorageRepository
+	UploadRepo         importrepo.UploadRepository
+	ScanRepo           importrepo.ScanRepository
+	LocationRepo       importrepo.LocationRepository
+	AccountRepo        importrepo.AccountRepository
 	CategorizationRepo *categorization.Repository
 	InsightsRepo       *insights.Repository
 	BalanceRepo        *balance.Repository
 	PlanRepo           planrepo.PlanRepository
+	BudgetPeriodRepo   planrepo.BudgetPeriodRepository
 	GoalsRepo          goalsrepo.GoalRepository
+	HouseholdRepo      householdrepo.HouseholdRepository
+	AdvisorRepo        advisorrepo.AdvisorRepository
 	SubscriptionsRepo  subscriptionsrepo.SubscriptionRepository
+	BillingRepo        billingrepo.BillingRepository
+	OnboardingRepo     onboardingrepo.OnboardingRepository
+	DemoRepo           demorepo.DemoRepository
+	InstallmentsRepo   installmentsrepo.InstallmentRepository
+	PurchasesRepo      purchasesrepo.PurchaseRepository
+	RewardsRepo        rewardsrepo.RewardRepository
+	MerchantsRepo      merchantsrepo.MerchantRepository
+	SyncRepo           offlinesyncrepo.SyncRepository
+	TrashRepo          trashrepo.TrashRepository
+	UsageRepo          quotasrepo.UsageRepository
+	SheetSyncRepo      planrepo.SheetSyncRepository
+	PlanRevisionRepo   planrepo.PlanRevisionRepository
+	ItemMappingRepo    planrepo.ItemMappingRepository
+	ShareLinkRepo      sharelinksrepo.ShareLinkRepository
+	NotificationsRepo  notificationsrepo.NotificationRepository
+	TelegramRepo       telegramrepo.TelegramRepository
+	WebhooksRepo       webhooksrepo.WebhookRepository
 	WaitlistRepo       waitlistrepo.WaitlistRepository
+	MaintenanceRepo    admin.MaintenanceRepo
+	AccountClosureRepo admin.AccountClosureRepo
+	DataExportRepo     admin.DataExportRepo
+	SupportRepo        admin.SupportRepo
+	AuditStore         audit.Store
+	IdempotencyStore   idempotency.Store
+	FeatureFlagStore   featureflags.Store
 
 	// Services
-	TokenManager          service.TokenManager
-	AuthService           *service.AuthService
-	UserSvc               user.UserService
-	ImportService         *importservice.ImportService
-	CategorizationService *categorization.Service
-	InsightsService       *insights.Service
-	PushService           *push.Service
-	BalanceService        *balance.Service
-	PlanService           *planservice.PlanService
-	GoalsService          *goalsservice.Service
-	SubscriptionsService  *subscriptionsservice.Service
-	WaitlistService       *waitlistservice.WaitlistService
-	FileStorage           storage.Storage
+	TokenManager           service.TokenManager
+	AuthService            *service.AuthService
+	APIKeyService          *service.APIKeyService
+	OAuthLoginService      *service.OAuthLoginService
+	UserSvc                user.UserService
+	ImportService          *importservice.ImportService
+	CategorizationService  *categorization.Service
+	InsightsService        *insights.Service
+	PushService            *push.Service
+	BalanceService         *balance.Service
+	PlanService            *planservice.PlanService
+	BudgetPeriodService    *planservice.BudgetPeriodService
+	GoalsService           *goalsservice.Service
+	HouseholdService       *householdservice.Service
+	AdvisorService         *advisorservice.Service
+	SubscriptionsService   *subscriptionsservice.Service
+	InstallmentsService    *installmentsservice.Service
+	PurchasesService       *purchasesservice.Service
+	RewardsService         *rewardsservice.Service
+	MerchantsService       *merchantsservice.Service
+	SyncService            *offlinesyncservice.Service
+	TrashService           *trashservice.Service
+	QuotaService           *quotasservice.Service
+	BillingService         *billingservice.Service
+	OnboardingService      *onboardingservice.Service
+	DemoService            *demoservice.Service
+	SheetSyncService       *planservice.SheetSyncService
+	ShareLinkService       *sharelinksservice.Service
+	ReportsService         *reportsservice.Service
+	NotificationsService   *notificationsservice.Service
+	TelegramService        *telegramservice.Service
+	TelegramBot            *telegram.Client // Set only when a bot token is configured
+	WebhooksService        *webhooksservice.Service
+	WaitlistService        *waitlistservice.WaitlistService
+	MaintenanceService     *admin.MaintenanceService
+	AccountClosureService  *admin.AccountClosureService
+	DataExportService      *admin.DataExportService
+	SupportService         *admin.SupportService
+	AuditService           *audit.Service
+	AuditInterceptor       *audit.Interceptor
+	IdempotencyInterceptor *idempotency.Interceptor
+	FeatureFlags           *featureflags.Service
+	FeatureFlagInterceptor *featureflags.Interceptor
+	RateBudgetInterceptor  *interceptors.RateBudgetInterceptor
+	TrustedProxies         *interceptors.TrustedProxies // Proxies whose X-Forwarded-For gives the client address
+	FileStorage            storage.Storage
+	FaultInjector          *chaos.Injector // Set only when CHAOS_ENABLED
+	FXConverter            *fx.Converter   // Set only when an exchange-rate feed or manual rates are configured
+	Scheduler              *cron.Scheduler
+	WorkQueue              *workqueue.Queue // Background work, with interactive recomputes ahead of backfill
+	stopAlertListener      context.CancelFunc
+	redisClient            *redis.Client // Set only when rate limits are shared through Redis
+	cacheRedisClient       *redis.Client // Set only when the cache is shared through Redis
+	ReplicaDB              *db.DB        // Set only when POSTGRES_REPLICA_URL is configured
+	Cache                  *cache.Cache
+	shutdownTracing        func(context.Context) error // Set only when TRACING_ENABLED
 
 	// Handlers
-	AuthHandler          *handler.AuthHandler
-	UserHandler          *userhandler.UserHandler
-	FinanceHandler       *financehandler.FinanceHandler
-	ImportHandler        *importhandler.ImportHandler
-	InsightsHandler      *insightshandler.InsightsHandler
-	BalanceHandler       *balancehandler.BalanceHandler
-	PlanHandler          *planhandler.PlanHandler
-	GoalsHandler         *goalshandler.GoalsHandler
-	SubscriptionsHandler *subscriptionshandler.SubscriptionsHandler
-	WaitlistHandler      *waitlisthandler.WaitlistHandler
+	AuthHandler           *handler.AuthHandler
+	OAuthLoginHandler     *handler.OAuthLoginHandler
+	UserHandler           *userhandler.UserHandler
+	FinanceHandler        *financehandler.FinanceHandler
+	ImportHandler         *importhandler.ImportHandler
+	InsightsHandler       *insightshandler.InsightsHandler
+	BalanceHandler        *balancehandler.BalanceHandler
+	PlanHandler           *planhandler.PlanHandler
+	GoalsHandler          *goalshandler.GoalsHandler
+	SubscriptionsHandler  *subscriptionshandler.SubscriptionsHandler
+	EmailOpenHandler      *notificationshandler.EmailOpenHandler
+	SheetsOAuthHandler    *planhandler.SheetsOAuthHandler
+	ShareLinkHandler      *sharelinkshandler.ShareLinkHandler
+	ReportDownloadHandler *reportshandler.DownloadHandler
+	DataExportHandler     *admin.DataExportHandler
+	SupportHandler        *admin.SupportHandler
+	TelegramHandler       *telegramhandler.WebhookHandler
+	BillingHandler        *billinghandler.WebhookHandler
+	WaitlistHandler       *waitlisthandler.WaitlistHandler
 }
 
 // InitDependencies initializes all application dependencies
@@ -92,6 +240,11 @@ func InitDependencies(cfg *config.Config, logger *slog.Logger) (*Dependencies, e
 		Logger: logger,
 	}
 
+	// Initialize tracing first so database and RPC spans are exported
+	if err := deps.initTracing(); err != nil {
+		return nil, fmt.Errorf("failed to init tracing: %w", err)
+	}
+
 	// Initialize database
 	if err := deps.initDatabase(); err != nil {
 		return nil, fmt.Errorf("failed to init database: %w", err)
@@ -112,19 +265,61 @@ func InitDependencies(cfg *config.Config, logger *slog.Logger) (*Dependencies, e
 		return nil, fmt.Errorf("failed to init handlers: %w", err)
 	}
 
+	// Initialize background jobs
+	if err := deps.initScheduler(); err != nil {
+		return nil, fmt.Errorf("failed to init scheduler: %w", err)
+	}
+
 	logger.Info("all dependencies initialized successfully")
 
 	return deps, nil
 }
 
+// initTracing exports spans over OTLP when tracing is enabled; otherwise
+// spans are created by the no-op global provider and dropped
+func (d *Dependencies) initTracing() error {
+	cfg := d.Config.Observability
+	if !cfg.TracingEnabled {
+		return nil
+	}
+	shutdown, err := observability.SetupTracing(context.Background(), observability.TracingConfig{
+		ServiceName: "echo-api",
+		Endpoint:    cfg.OTLPEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: float64(cfg.TraceSamplePercent) / 100,
+	})
+	if err != nil {
+		return err
+	}
+	d.shutdownTracing = shutdown
+	d.Logger.Info("tracing enabled", slog.Int("sample_percent", cfg.TraceSamplePercent))
+	return nil
+}
+
 // initDatabase initializes the database connection and runs migrations
 func (d *Dependencies) initDatabase() error {
+	if d.Config.Chaos.Enabled {
+		faults, err := chaos.ParseFaults(d.Config.Chaos.Faults)
+		if err != nil {
+			return fmt.Errorf("failed to parse CHAOS_FAULTS: %w", err)
+		}
+		d.FaultInjector = chaos.NewInjector(faults)
+		d.Logger.Warn("chaos failure injection enabled; do not run this in production",
+			slog.Int("faults", len(faults)),
+		)
+	}
+
+	dbCfg := d.Config.Database
 	database, err := db.New(db.Config{
-		DSN:             d.Config.Database.DSN(),
-		MaxConns:        25,
-		MinConns:        5,
-		MaxConnLifetime: 5 * time.Minute,
-		MaxConnIdleTime: 10 * time.Minute,
+		DSN:               dbCfg.DSN(),
+		MaxConns:          int32(dbCfg.MaxConns),
+		MinConns:          int32(dbCfg.MinConns),
+		MaxConnLifetime:   time.Duration(dbCfg.MaxConnLifetimeSeconds) * time.Second,
+		MaxConnIdleTime:   time.Duration(dbCfg.MaxConnIdleSeconds) * time.Second,
+		HealthCheckPeriod: time.Duration(dbCfg.HealthCheckSeconds) * time.Second,
+		StatementTimeout:  time.Duration(dbCfg.StatementTimeoutSeconds) * time.Second,
+		FaultInjector:     d.FaultInjector,
+		Tracer:            observability.NewQueryTracer(),
 	}, d.Logger)
 	if err != nil {
 		return err
@@ -137,21 +332,83 @@ func (d *Dependencies) initDatabase() error {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	if dbCfg.ReplicaURL != "" {
+		replica, err := db.New(db.Config{
+			DSN:               dbCfg.ReplicaURL,
+			MaxConns:          int32(dbCfg.MaxConns),
+			MinConns:          int32(dbCfg.MinConns),
+			MaxConnLifetime:   time.Duration(dbCfg.MaxConnLifetimeSeconds) * time.Second,
+			MaxConnIdleTime:   time.Duration(dbCfg.MaxConnIdleSeconds) * time.Second,
+			HealthCheckPeriod: time.Duration(dbCfg.HealthCheckSeconds) * time.Second,
+			StatementTimeout:  time.Duration(dbCfg.StatementTimeoutSeconds) * time.Second,
+			Tracer:            observability.NewQueryTracer(),
+		}, d.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to connect to read replica: %w", err)
+		}
+		d.ReplicaDB = replica
+		d.Logger.Info("routing analytics queries to read replica")
+	}
+
 	d.Logger.Info("database connected and migrations completed successfully")
 	return nil
 }
 
+// readPool returns the read replica's pool, or nil when there is none so
+// repositories keep reading from the primary
+func (d *Dependencies) readPool() *pgxpool.Pool {
+	if d.ReplicaDB == nil {
+		return nil
+	}
+	return d.ReplicaDB.Pool
+}
+
 // initRepositories initializes all repository layer dependencies
 func (d *Dependencies) initRepositories() error {
 	d.AuthRepo = repository.NewPostgresAuthRepository(d.DB.Pool)
-	d.ImportRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
+	d.APIKeyRepo = repository.NewPostgresAPIKeyRepository(d.DB.Pool)
+	d.ImportRepo = importrepo.NewPostgresImportRepository(d.DB.Pool).WithReadPool(d.readPool())
+	d.StorageRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
+	d.UploadRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
+	d.ScanRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
+	d.LocationRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
+	d.AccountRepo = importrepo.NewPostgresImportRepository(d.DB.Pool)
 	d.CategorizationRepo = categorization.NewRepository(d.DB.Pool)
-	d.InsightsRepo = insights.NewRepository(d.DB.Pool)
+	d.InsightsRepo = insights.NewRepository(d.DB.Pool).
+		WithReadPool(d.readPool()).
+		WithQueryTimeout(time.Duration(d.Config.Database.AnalyticsTimeoutSeconds) * time.Second)
 	d.BalanceRepo = balance.NewRepository(d.DB.Pool)
 	d.PlanRepo = planrepo.NewPostgresPlanRepository(d.DB.Pool)
+	d.BudgetPeriodRepo = planrepo.NewPostgresBudgetPeriodRepository(d.DB.Pool)
 	d.GoalsRepo = goalsrepo.NewPostgresGoalRepository(d.DB.Pool)
+	d.HouseholdRepo = householdrepo.NewPostgresHouseholdRepository(d.DB.Pool)
+	d.AdvisorRepo = advisorrepo.NewPostgresAdvisorRepository(d.DB.Pool)
 	d.SubscriptionsRepo = subscriptionsrepo.NewPostgresSubscriptionRepository(d.DB.Pool)
+	d.InstallmentsRepo = installmentsrepo.NewPostgresInstallmentRepository(d.DB.Pool)
+	d.PurchasesRepo = purchasesrepo.NewPostgresPurchaseRepository(d.DB.Pool)
+	d.NotificationsRepo = notificationsrepo.NewPostgresNotificationRepository(d.DB.Pool)
+	d.TelegramRepo = telegramrepo.NewPostgresTelegramRepository(d.DB.Pool)
+	d.WebhooksRepo = webhooksrepo.NewPostgresWebhookRepository(d.DB.Pool)
+	d.RewardsRepo = rewardsrepo.NewPostgresRewardRepository(d.DB.Pool)
+	d.MerchantsRepo = merchantsrepo.NewPostgresMerchantRepository(d.DB.Pool)
+	d.SyncRepo = offlinesyncrepo.NewPostgresSyncRepository(d.DB.Pool)
+	d.TrashRepo = trashrepo.NewPostgresTrashRepository(d.DB.Pool)
+	d.UsageRepo = quotasrepo.NewPostgresUsageRepository(d.DB.Pool)
+	d.BillingRepo = billingrepo.NewPostgresBillingRepository(d.DB.Pool)
+	d.OnboardingRepo = onboardingrepo.NewPostgresOnboardingRepository(d.DB.Pool)
+	d.DemoRepo = demorepo.NewPostgresDemoRepository(d.DB.Pool)
+	d.SheetSyncRepo = planrepo.NewPostgresSheetSyncRepository(d.DB.Pool)
+	d.PlanRevisionRepo = planrepo.NewPostgresPlanRevisionRepository(d.DB.Pool)
+	d.ItemMappingRepo = planrepo.NewPostgresItemMappingRepository(d.DB.Pool)
+	d.ShareLinkRepo = sharelinksrepo.NewPostgresShareLinkRepository(d.DB.Pool)
 	d.WaitlistRepo = waitlistrepo.NewPostgresWaitlistRepository(d.DB.Pool)
+	d.MaintenanceRepo = admin.NewPostgresMaintenanceRepo(d.DB.Pool)
+	d.AccountClosureRepo = admin.NewPostgresAccountClosureRepo(d.DB.Pool)
+	d.DataExportRepo = admin.NewPostgresDataExportRepo(d.DB.Pool).WithReadPool(d.readPool())
+	d.SupportRepo = admin.NewPostgresSupportRepo(d.DB.Pool)
+	d.AuditStore = audit.NewPostgresStore(d.DB.Pool)
+	d.IdempotencyStore = idempotency.NewPostgresStore(d.DB.Pool)
+	d.FeatureFlagStore = featureflags.NewPostgresStore(d.DB.Pool)
 
 	d.Logger.Info("repositories initialized")
 	return nil
@@ -164,6 +421,20 @@ func (d *Dependencies) initServices() error {
 		return fmt.Errorf("jwt secret is required")
 	}
 
+	// Client addresses come from X-Forwarded-For only behind these proxies
+	trustedProxies, err := interceptors.ParseTrustedProxies(d.Config.Server.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("invalid SERVER_TRUSTED_PROXIES: %w", err)
+	}
+	d.TrustedProxies = trustedProxies
+
+	// One worker pool per priority class, shared by the scheduler and on-demand recomputes
+	d.WorkQueue = workqueue.New(map[workqueue.Class]workqueue.Pool{
+		workqueue.Interactive: {Workers: d.Config.Scheduler.InteractiveWorkers},
+		workqueue.Scheduled:   {Workers: d.Config.Scheduler.ScheduledWorkers},
+		workqueue.Backfill:    {Workers: d.Config.Scheduler.BackfillWorkers},
+	}, d.Logger)
+
 	accessTokenTTL := 1 * time.Hour // Increased from 15 minutes for better UX
 	refreshTokenTTL := 30 * 24 * time.Hour
 
@@ -176,37 +447,182 @@ func (d *Dependencies) initServices() error {
 		d.Logger,
 		refreshTokenTTL,
 	)
+	// Scoped API keys for scripts, accepted by the auth interceptor
+	d.APIKeyService = service.NewAPIKeyService(d.APIKeyRepo, d.Logger)
+
+	// Google and Apple sign-in, for whichever clients are configured
+	oauthLogin := service.NewOAuthLoginService(d.AuthService, service.OAuthConfig{
+		GoogleClientID:     d.Config.Google.ClientID,
+		GoogleClientSecret: d.Config.Google.ClientSecret,
+		AppleClientID:      d.Config.OAuth.AppleClientID,
+		AppleTeamID:        d.Config.OAuth.AppleTeamID,
+		AppleKeyID:         d.Config.OAuth.AppleKeyID,
+		ApplePrivateKey:    d.Config.OAuth.ApplePrivateKey,
+		CallbackURL:        strings.TrimSuffix(d.Config.Server.BaseURL, "/") + service.OAuthLoginPath,
+		RedirectURLs:       d.Config.OAuth.RedirectURLs,
+	}, jwtSecret)
+	if len(oauthLogin.Providers()) > 0 {
+		d.OAuthLoginService = oauthLogin
+	}
 
 	d.UserSvc = user.NewUserService(d.UserRepo, d.Logger)
 
+	// Merchant enrichment (clean names, logos, MCC) for transaction details
+	// and as the categorization fallback
+	d.MerchantsService = merchantsservice.NewService(d.MerchantsRepo)
+
 	// Categorization service for transaction enrichment
-	d.CategorizationService = categorization.NewService(d.CategorizationRepo)
+	d.CategorizationService = categorization.NewService(d.CategorizationRepo).
+		WithMerchantHints(newMerchantHintsAdapter(d.MerchantsService))
 
-	// Import service with categorization wired in
+	// Import service with categorization and transaction rules wired in
 	d.ImportService = importservice.NewImportService(d.ImportRepo, d.Logger)
-	d.ImportService.WithCategorizationService(newCategorizationAdapter(d.CategorizationService))
+	d.ImportService.WithCategorizationService(newCategorizationAdapter(d.CategorizationService)).
+		WithTransactionRules(newTransactionRulesAdapter(d.CategorizationService)).
+		WithLocations(d.LocationRepo).
+		WithWorkQueue(d.WorkQueue)
 
 	// Push notification service
 	d.PushService = push.NewService(d.Logger)
 
-	// Insights service for spending pulse and dashboard (with push notifications)
-	d.InsightsService = insights.NewService(d.InsightsRepo, d.PushService, d.AuthRepo, d.Logger)
+	// Outbound webhooks: users subscribe endpoints to import, goal, alert and plan events
+	webhookSender := webhook.NewService()
+	d.WebhooksService = webhooksservice.NewService(d.WebhooksRepo, webhookSender, d.Logger)
+	webhookEvents := newWebhookEventsAdapter(d.WebhooksService, d.PlanRepo, d.Logger)
+
+	// Notification inbox with per-channel delivery tracking
+	d.NotificationsService = notificationsservice.NewService(d.NotificationsRepo, d.Logger).
+		WithPush(d.PushService).
+		WithEmail(emailService, d.Config.Server.BaseURL).
+		WithWebhook(webhookSender)
+	if vapidKey := d.Config.WebPush.VAPIDPrivateKey; vapidKey != "" {
+		subject := d.Config.WebPush.Subject
+		if subject == "" {
+			subject = d.Config.Server.BaseURL
+		}
+		webPush, err := webpush.NewService(vapidKey, subject)
+		if err != nil {
+			return fmt.Errorf("failed to initialize web push: %w", err)
+		}
+		d.NotificationsService.WithWebPush(webPush)
+	}
+	if token := d.Config.Telegram.BotToken; token != "" {
+		d.TelegramBot = telegram.NewClient(token)
+		d.NotificationsService.WithTelegram(d.TelegramBot)
+	}
+
+	// Business calendars pick weekend and bank holiday rules from each user's country
+	calendars := calendar.NewResolver(newCalendarAdapter(d.UserRepo))
+
+	// Households let partners share plans, accounts and categories
+	d.HouseholdService = householdservice.NewService(d.HouseholdRepo)
+
+	// Per-user cache of dashboard queries, shared between servers when Redis is configured
+	var cacheStore cache.Store = cache.NewMemoryStore()
+	if d.Config.Cache.RedisURL != "" {
+		opts, err := redis.ParseURL(d.Config.Cache.RedisURL)
+		if err != nil {
+			return fmt.Errorf("invalid CACHE_REDIS_URL: %w", err)
+		}
+		d.cacheRedisClient = redis.NewClient(opts)
+		cacheStore = cache.NewRedisStore(d.cacheRedisClient)
+	}
+	d.Cache = cache.New(cacheStore, time.Duration(d.Config.Cache.TTLSeconds)*time.Second, d.Logger)
+
+	// Insights service for spending pulse and dashboard (alerts go through the inbox)
+	d.InsightsService = insights.NewService(d.InsightsRepo, d.PushService, d.AuthRepo, d.Logger).
+		WithNotifier(newNotificationAdapter(d.NotificationsService, webhookEvents)).
+		WithCalendars(calendars).
+		WithHouseholds(d.HouseholdService).
+		WithCache(d.Cache)
 
 	// Wire insights adapter to import service for post-import quality metrics
 	insightsAdapter := insights.NewServiceAdapter(d.InsightsService)
-	d.ImportService.WithInsightsService(insightsAdapter)
+	d.ImportService.WithInsightsService(insightsAdapter).
+		WithImportListener(importListeners{webhookEvents, insightsAdapter})
+
+	// New alerts and dashboard invalidations are streamed to connected clients
+	// through Postgres LISTEN/NOTIFY, so every replica sees them
+	alertBroker := insights.NewAlertBroker(func(ctx context.Context, payload string) error {
+		return pgnotify.Notify(ctx, d.DB.Pool, insights.AlertsChannel, payload)
+	}, pgnotify.MaxPayload, d.Logger)
+	d.InsightsService.WithAlertBroker(alertBroker)
+	listenerCtx, stopListener := context.WithCancel(context.Background())
+	d.stopAlertListener = stopListener
+	go pgnotify.NewListener(d.DB.Pool, insights.AlertsChannel, alertBroker.Dispatch, d.Logger).Run(listenerCtx)
 
 	// Balance service for computing user balances
 	d.BalanceService = balance.NewService(d.BalanceRepo)
+	d.InsightsService.WithNetWorth(newNetWorthAdapter(d.BalanceService))
 
 	// Plan service for user financial plans (BYOS)
-	d.PlanService = planservice.NewPlanService(d.PlanRepo, d.ImportRepo, d.DB.Pool, d.Logger)
+	d.PlanService = planservice.NewPlanService(d.PlanRepo, d.ImportRepo, d.DB.Pool, d.Logger).
+		WithRevisionRepository(d.PlanRevisionRepo).
+		WithItemMappingRepository(d.ItemMappingRepo).
+		WithBudgetPeriods(d.BudgetPeriodRepo).
+		WithHouseholds(newHouseholdsAdapter(d.HouseholdService)).
+		WithWorkQueue(d.WorkQueue)
+
+	// Two-way Google Sheets sync for plans (enabled when a Google OAuth client is configured)
+	planListeners := planChangeListeners{webhookEvents}
+	if d.Config.Google.ClientID != "" {
+		oauthCfg := sheets.NewOAuthConfig(d.Config.Google.ClientID, d.Config.Google.ClientSecret,
+			strings.TrimSuffix(d.Config.Server.BaseURL, "/")+planservice.SheetsOAuthCallbackPath)
+		d.SheetSyncService = planservice.NewSheetSyncService(d.SheetSyncRepo, d.PlanService, sheets.NewClient(), oauthCfg, jwtSecret, d.Logger)
+		planListeners = append(planListeners, d.SheetSyncService)
+	}
+	d.PlanService.WithChangeListener(planListeners)
+
+	// Budget period service for monthly budget snapshots
+	d.BudgetPeriodService = planservice.NewBudgetPeriodService(d.BudgetPeriodRepo, d.PlanRepo)
+
+	// Budget streaks on the dashboard and in Wrapped come from closed periods
+	d.InsightsService.WithStreaks(newStreaksAdapter(d.PlanRepo, d.BudgetPeriodService))
+
+	// Read-only share links to plan and monthly report snapshots, e.g. for an advisor
+	d.ShareLinkService = sharelinksservice.NewService(d.ShareLinkRepo,
+		newShareSnapshotAdapter(d.PlanService, d.InsightsService), jwtSecret, d.Config.Server.BaseURL)
+
+	// Invited advisors get a read-only workspace and leave annotations clients see as alerts
+	d.AdvisorService = advisorservice.NewService(d.AdvisorRepo,
+		newAdvisorWorkspaceAdapter(d.PlanService, d.InsightsService), d.Logger).
+		WithNotifier(newAdvisorNotesAdapter(d.InsightsService))
 
 	// Goals service for savings goals with progress tracking
-	d.GoalsService = goalsservice.NewService(d.GoalsRepo)
+	d.GoalsService = goalsservice.NewService(d.GoalsRepo).
+		WithMilestoneListener(webhookEvents).
+		WithCache(d.Cache)
 
 	// Subscriptions service for recurring charge detection
-	d.SubscriptionsService = subscriptionsservice.NewService(d.SubscriptionsRepo)
+	d.SubscriptionsService = subscriptionsservice.NewService(d.SubscriptionsRepo).
+		WithCalendars(calendars)
+
+	// Installments service for pay-later purchases split across periods
+	d.InstallmentsService = installmentsservice.NewService(d.InstallmentsRepo).
+		WithCalendars(calendars)
+
+	// Purchases service for return windows and warranties of tracked purchases
+	d.PurchasesService = purchasesservice.NewService(d.PurchasesRepo)
+
+	// Rewards service for cash-back credits, flagged on import and by the scheduler
+	d.RewardsService = rewardsservice.NewService(d.RewardsRepo)
+	d.ImportService.WithRewardsDetector(newRewardsAdapter(d.RewardsService))
+
+	// Offline sync: the change feed mobile clients pull from and push offline edits to
+	d.SyncService = offlinesyncservice.NewService(d.SyncRepo).
+		WithDashboardInvalidator(d.InsightsService)
+
+	// Trash of deleted transactions, plans, goals and rules, purged by the scheduler
+	d.TrashService = trashservice.NewService(d.TrashRepo).
+		WithRuleInvalidator(d.CategorizationService).
+		WithDashboardInvalidator(d.InsightsService)
+
+	// Onboarding wizard, resumed from the user's data on any device
+	d.OnboardingService = onboardingservice.NewService(d.OnboardingRepo)
+
+	// Sample data for new users, behind the demo_data flag
+	d.DemoService = demoservice.NewService(d.DemoRepo).
+		WithPlanCreator(newDemoPlanAdapter(d.PlanService))
 
 	// Waitlist service for pre-launch signups with Resend email integration
 	d.WaitlistService = waitlistservice.NewWaitlistService(d.WaitlistRepo, d.Logger)
@@ -220,7 +636,104 @@ func (d *Dependencies) initServices() error {
 	if err != nil {
 		return fmt.Errorf("failed to init file storage: %w", err)
 	}
+	// Statements are encrypted at rest once master keys are configured
+	if keys := d.Config.Storage.EncryptionKeys; keys != "" {
+		keyring, err := storage.ParseKeyring(keys)
+		if err != nil {
+			return fmt.Errorf("failed to load storage encryption keys: %w", err)
+		}
+		fileStorage = storage.NewEncryptedStorage(fileStorage, keyring)
+	} else {
+		d.Logger.Warn("STORAGE_ENCRYPTION_KEYS not set, uploaded files are stored unencrypted")
+	}
 	d.FileStorage = fileStorage
+	d.ImportService.WithFileStorage(d.StorageRepo, d.FileStorage, importservice.StorageQuotas{
+		Free:    int64(d.Config.Storage.FreeQuotaMB) << 20,
+		Premium: int64(d.Config.Storage.PremiumQuotaMB) << 20,
+	}).WithUploads(d.UploadRepo)
+
+	// Transaction and plan quotas by tier, next to the storage quota
+	quota := d.Config.Quota
+	d.QuotaService = quotasservice.NewService(d.UsageRepo, newStorageMeterAdapter(d.ImportService)).
+		WithTierLimits(quotasservice.TierLimits{
+			quotasservice.TierFree:    {MaxTransactions: int64(quota.FreeMaxTransactions), MaxPlans: quota.FreeMaxPlans},
+			quotasservice.TierPremium: {MaxTransactions: int64(quota.PremiumMaxTransactions), MaxPlans: quota.PremiumMaxPlans},
+		})
+	d.ImportService.WithTransactionQuota(d.QuotaService)
+	d.PlanService.WithPlanQuota(d.QuotaService)
+	d.SyncService.WithTransactionQuota(d.QuotaService)
+
+	// Uploads are checked by type and size, and by ClamAV once configured
+	scanner := filescan.New(importservice.MaxUploadBytes)
+	if addr := d.Config.Storage.ClamAVAddress; addr != "" {
+		scanner.WithAntivirus(filescan.NewClamAV(addr))
+	} else {
+		d.Logger.Info("CLAMAV_ADDRESS not set, uploads are not scanned for malware")
+	}
+	d.ImportService.WithScanner(scanner, d.ScanRepo)
+
+	// PDF and XLSX exports of reports, and CSV/XLSX exports of transactions,
+	// downloaded through signed links
+	d.ReportsService = reportsservice.NewService(newReportSourceAdapter(d.PlanService, d.InsightsService),
+		d.FileStorage, jwtSecret, d.Config.Server.BaseURL).
+		WithTransactions(d.ImportRepo)
+
+	// Maintenance service for scheduled vacuum, compaction and storage cleanup
+	d.MaintenanceService = admin.NewMaintenanceService(d.MaintenanceRepo, d.FileStorage, d.Logger)
+
+	// Closed accounts lose access at once and are anonymized after a grace period
+	d.AccountClosureService = admin.NewAccountClosureService(d.AccountClosureRepo, d.Logger).
+		WithFileStorage(d.FileStorage).
+		WithErasureGracePeriod(time.Duration(d.Config.Auth.AccountDeletionGraceDays) * 24 * time.Hour)
+
+	// Full exports of a user's data (GDPR), built by the scheduler
+	d.DataExportService = admin.NewDataExportService(d.DataExportRepo, d.FileStorage, jwtSecret, d.Config.Server.BaseURL, d.Logger)
+
+	// Audit log of sensitive mutations, recorded by an interceptor on the RPC chain
+	d.AuditService = audit.NewService(d.AuditStore)
+
+	// Support lookups, import retries and read-only impersonation for admins and support staff
+	d.SupportService = admin.NewSupportService(d.SupportRepo, d.ImportService, jwtSecret, d.Logger).
+		WithAuditLog(d.AuditStore)
+	d.AuditInterceptor = newAuditInterceptor(d.AuditStore, d.PlanService, d.ImportRepo, d.Logger).
+		WithTrustedProxies(d.TrustedProxies)
+
+	// Retried mutations sent with an Idempotency-Key replay their first response
+	d.IdempotencyInterceptor = newIdempotencyInterceptor(d.IdempotencyStore, d.Logger)
+
+	// Paid plans billed through Stripe once configured; the plan's entitlements gate paid features
+	d.BillingService = billingservice.NewService(d.BillingRepo, d.Logger)
+	if key := d.Config.Stripe.SecretKey; key != "" {
+		d.BillingService.WithStripe(stripe.NewClient(key), billingservice.Prices{
+			billingrepo.PlanPremiumMonthly: d.Config.Stripe.PriceMonthly,
+			billingrepo.PlanPremiumAnnual:  d.Config.Stripe.PriceAnnual,
+		}, d.Config.Stripe.WebhookSecret)
+	}
+
+	// Feature flags for gradual rollouts, evaluated per request for handlers
+	d.FeatureFlags = featureflags.NewService(d.FeatureFlagStore, d.Logger).
+		WithEntitlements(d.BillingService, d.BillingService.PaidFeatures()...)
+	d.FeatureFlagInterceptor = featureflags.NewInterceptor(d.FeatureFlags)
+
+	// Per-user and per-IP rate limits, shared between servers when Redis is configured
+	var rateStore interceptors.RateLimitStore = interceptors.NewMemoryRateLimitStore()
+	if d.Config.RateLimit.RedisURL != "" {
+		opts, err := redis.ParseURL(d.Config.RateLimit.RedisURL)
+		if err != nil {
+			return fmt.Errorf("invalid RATE_LIMIT_REDIS_URL: %w", err)
+		}
+		d.redisClient = redis.NewClient(opts)
+		rateStore = interceptors.NewRedisRateLimitStore(d.redisClient)
+	}
+	d.RateBudgetInterceptor = newRateBudgetInterceptor(d.Config.RateLimit, rateStore, d.Logger).
+		WithTrustedProxies(d.TrustedProxies)
+
+	// Exchange rates for converting amounts between currencies
+	fxConverter, err := newFXConverter(d.Config.FX)
+	if err != nil {
+		return err
+	}
+	d.FXConverter = fxConverter
 
 	d.Logger.Info("services initialized")
 	return nil
@@ -229,26 +742,181 @@ func (d *Dependencies) initServices() error {
 // initHandlers initializes all handler dependencies
 func (d *Dependencies) initHandlers() error {
 	d.AuthHandler = handler.NewAuthHandler(d.AuthService)
+	if d.OAuthLoginService != nil {
+		d.OAuthLoginHandler = handler.NewOAuthLoginHandler(d.OAuthLoginService, d.Logger)
+	}
 	d.FinanceHandler = financehandler.NewFinanceHandler(d.ImportService, d.ImportRepo, d.CategorizationService).
 		WithGoalsService(d.GoalsService).
 		WithSubscriptionsService(d.SubscriptionsService).
-		WithPlanService(d.PlanService)
+		WithPlanService(d.PlanService).
+		WithLanguageLookup(newLanguageAdapter(d.UserRepo)).
+		WithAccounts(d.AccountRepo).
+		WithDashboardInvalidator(d.InsightsService)
+
+	// Voice capture transcribes locally with whisper.cpp, or in the cloud once configured
+	switch cfg := d.Config.Speech; {
+	case cfg.WhisperModel != "":
+		d.FinanceHandler.WithTranscriber(speech.NewWhisperCPP(cfg.WhisperBinary, cfg.WhisperModel))
+	case cfg.APIKey != "":
+		cloud := speech.NewCloud(cfg.APIKey)
+		if cfg.APIURL != "" {
+			cloud.WithBaseURL(cfg.APIURL)
+		}
+		if cfg.Model != "" {
+			cloud.WithModel(cfg.Model)
+		}
+		d.FinanceHandler.WithTranscriber(cloud)
+	}
+
+	// Telegram bot for Quick Capture; it records through the finance handler, so it's built here
+	var botSender telegramservice.MessageSender
+	if d.TelegramBot != nil {
+		botSender = d.TelegramBot
+	}
+	d.TelegramService = telegramservice.NewService(d.TelegramRepo, newTelegramCaptureAdapter(d.FinanceHandler), botSender, d.Logger).
+		WithBotUsername(d.Config.Telegram.BotUsername)
+	if d.TelegramBot != nil {
+		if d.Config.Telegram.WebhookSecret == "" {
+			d.Logger.Warn("telegram webhook disabled: TELEGRAM_WEBHOOK_SECRET is not set")
+		} else {
+			d.TelegramHandler = telegramhandler.NewWebhookHandler(d.TelegramService, d.Config.Telegram.WebhookSecret, d.Logger)
+		}
+	}
+	if d.Config.Stripe.SecretKey != "" {
+		if d.Config.Stripe.WebhookSecret == "" {
+			d.Logger.Warn("stripe webhook disabled: STRIPE_WEBHOOK_SECRET is not set")
+		} else {
+			d.BillingHandler = billinghandler.NewWebhookHandler(d.BillingService, d.Logger)
+		}
+	}
 	d.ImportHandler = importhandler.NewImportHandler(d.ImportService, d.FileStorage, d.Logger)
 	d.InsightsHandler = insightshandler.NewInsightsHandler(d.InsightsService)
 	d.BalanceHandler = balancehandler.NewBalanceHandler(d.BalanceService)
-	d.PlanHandler = planhandler.NewPlanHandler(d.PlanService, d.FileStorage)
+	d.PlanHandler = planhandler.NewPlanHandler(d.PlanService, d.FileStorage).
+		WithBudgetPeriodService(d.BudgetPeriodService).
+		WithFileGuard(d.ImportService)
 	d.GoalsHandler = goalshandler.NewGoalsHandler(d.GoalsService)
 	d.SubscriptionsHandler = subscriptionshandler.NewSubscriptionsHandler(d.SubscriptionsService)
 	d.WaitlistHandler = waitlisthandler.NewWaitlistHandler(d.WaitlistService)
+	d.EmailOpenHandler = notificationshandler.NewEmailOpenHandler(d.NotificationsService, d.Logger)
+	d.ShareLinkHandler = sharelinkshandler.NewShareLinkHandler(d.ShareLinkService, d.Logger).
+		WithTrustedProxies(d.TrustedProxies)
+	d.ReportDownloadHandler = reportshandler.NewDownloadHandler(d.ReportsService, d.Logger)
+	d.DataExportHandler = admin.NewDataExportHandler(d.DataExportService, d.Logger)
+	d.SupportHandler = admin.NewSupportHandler(d.SupportService, d.Logger)
+	if d.SheetSyncService != nil {
+		d.SheetsOAuthHandler = planhandler.NewSheetsOAuthHandler(d.SheetSyncService, d.Logger)
+	}
 
 	d.Logger.Info("handlers initialized")
 	return nil
 }
 
+// initScheduler registers and starts the background maintenance jobs.
+// Jobs are guarded by advisory locks so multiple replicas don't double-run them.
+func (d *Dependencies) initScheduler() error {
+	cfg := d.Config.Scheduler
+	if !cfg.Enabled {
+		d.Logger.Info("scheduler disabled")
+		return nil
+	}
+
+	scheduler := cron.NewScheduler(d.PlanRepo, d.PlanService, d.Logger).
+		WithLocker(cron.NewPostgresLocker(d.DB.Pool)).
+		WithQueue(d.WorkQueue).
+		WithPlanActualsSchedule(cfg.PlanActualsSchedule)
+
+	jobs := []cron.Job{
+		cron.MonthlyInsightsJob(d.InsightsService, cfg.MonthlyInsightsSchedule, d.Logger),
+		cron.SubscriptionDetectionJob(d.SubscriptionsService, cfg.SubscriptionDetectionSchedule, d.Logger),
+		cron.BudgetRolloverJob(d.PlanRepo, d.BudgetPeriodService, d.InsightsService, cfg.BudgetRolloverSchedule, d.Logger),
+		cron.DataSourceHealthJob(d.InsightsService, cfg.DataSourceHealthSchedule),
+		cron.AggregateRollupsJob(d.InsightsService, cfg.AggregateRollupsSchedule),
+		cron.WeeklyDigestJob(d.InsightsService, cfg.WeeklyDigestSchedule, d.Logger),
+		cron.AlertCleanupJob(d.InsightsService, cfg.AlertCleanupSchedule, d.Logger),
+		cron.PlanItemLinkSyncJob(d.PlanService, cfg.PlanItemLinkSchedule, d.Logger),
+		cron.InstallmentMatchingJob(d.InstallmentsService, cfg.InstallmentMatchingSchedule, d.Logger),
+		cron.PurchaseRemindersJob(d.PurchasesService, d.InsightsService, cfg.PurchaseRemindersSchedule, d.Logger),
+		cron.RewardsDetectionJob(d.RewardsService, cfg.RewardsDetectionSchedule, d.Logger),
+		cron.PushReceiptsJob(d.NotificationsService, cfg.PushReceiptsSchedule, d.Logger),
+		cron.DeferredNotificationsJob(d.NotificationsService, cfg.DeferredNotificationsSchedule, d.Logger),
+		cron.WebhookDeliveriesJob(d.WebhooksService, cfg.WebhookDeliveriesSchedule, d.Logger),
+		cron.BudgetAlertsJob(d.PlanRepo, d.PlanService, d.InsightsService, cfg.BudgetAlertsSchedule, d.Logger),
+		cron.DatabaseMaintenanceJob(d.MaintenanceService, cfg.DatabaseMaintenanceSchedule, d.Logger),
+		cron.AccountClosureJob(d.AccountClosureService, cfg.AccountClosureSchedule, d.Logger),
+		cron.DataExportJob(d.DataExportService, cfg.DataExportSchedule, d.Logger),
+		cron.FileScanJob(d.ImportService, cfg.FileScanSchedule, d.Logger),
+		cron.TrashPurgeJob(d.TrashService, cfg.TrashPurgeSchedule, d.Logger),
+	}
+	for _, job := range jobs {
+		if err := scheduler.Register(job); err != nil {
+			return err
+		}
+	}
+
+	if d.SheetSyncService != nil {
+		if err := scheduler.Register(cron.SheetSyncJob(d.SheetSyncService, cfg.SheetSyncSchedule, d.Logger)); err != nil {
+			return err
+		}
+	} else {
+		d.Logger.Info("google sheets not configured, plan_sheet_sync job not registered")
+	}
+
+	if d.FXConverter != nil {
+		if err := scheduler.Register(cron.FXRefreshJob(d.FXConverter.Refresh, cfg.FXRefreshSchedule)); err != nil {
+			return err
+		}
+	} else {
+		d.Logger.Info("no exchange-rate provider configured, fx_rate_refresh job not registered")
+	}
+
+	if err := scheduler.Start(); err != nil {
+		return err
+	}
+	d.Scheduler = scheduler
+
+	d.Logger.Info("scheduler initialized")
+	return nil
+}
+
 // Cleanup closes all resources
 func (d *Dependencies) Cleanup() {
+	if d.Scheduler != nil {
+		// Wait for running jobs to finish, but don't hold up shutdown indefinitely
+		select {
+		case <-d.Scheduler.Stop().Done():
+		case <-time.After(30 * time.Second):
+			d.Logger.Warn("scheduler jobs still running at shutdown")
+		}
+	}
+	if d.WorkQueue != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := d.WorkQueue.Stop(ctx); err != nil {
+			d.Logger.Warn("background tasks still running at shutdown", slog.Any("error", err))
+		}
+		cancel()
+	}
+	if d.stopAlertListener != nil {
+		d.stopAlertListener()
+	}
+	if d.redisClient != nil {
+		_ = d.redisClient.Close()
+	}
+	if d.cacheRedisClient != nil {
+		_ = d.cacheRedisClient.Close()
+	}
+	if d.ReplicaDB != nil {
+		d.ReplicaDB.Close()
+	}
 	if d.DB != nil {
 		d.DB.Close()
 	}
+	if d.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := d.shutdownTracing(ctx); err != nil {
+			d.Logger.Warn("failed to flush traces", slog.Any("error", err))
+		}
+		cancel()
+	}
 	d.Logger.Info("cleanup completed")
 }
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money/fx"
+)
+
+// newFXConverter builds the exchange-rate converter from the configured feed
+// and manual rates, manual rates first so they override the feed. Returns nil
+// when neither is configured.
+func newFXConverter(cfg config.FXConfig) (*fx.Converter, error) {
+	var providers []fx.RateProvider
+	if cfg.ManualRates != "" {
+		manual, err := fx.ParseManual(cfg.ManualRates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FX_MANUAL_RATES: %w", err)
+		}
+		providers = append(providers, manual)
+	}
+
+	switch cfg.Provider {
+	case "":
+	case "ecb":
+		providers = append(providers, fx.NewECB())
+	case "exchangeratehost":
+		if cfg.AccessKey == "" {
+			return nil, fmt.Errorf("FX_ACCESS_KEY is required for FX_PROVIDER=exchangeratehost")
+		}
+		providers = append(providers, fx.NewExchangeRateHost(cfg.AccessKey, cfg.Source))
+	default:
+		return nil, fmt.Errorf("unknown FX_PROVIDER %q: want ecb or exchangeratehost", cfg.Provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	return fx.NewConverter(providers...), nil
+}
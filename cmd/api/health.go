@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/buildinfo"
+)
+
+// readinessTimeout bounds each readiness check, keeping probes fast when a
+// dependency hangs
+const readinessTimeout = 2 * time.Second
+
+// readinessClient probes external APIs; each probe is bounded by readinessTimeout
+var readinessClient = &http.Client{Timeout: readinessTimeout}
+
+// readinessCheck is a dependency the server can't serve requests without
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// readinessChecks lists the configured dependencies to probe
+func readinessChecks(deps *Dependencies) []readinessCheck {
+	checks := []readinessCheck{
+		{name: "db", check: deps.DB.Pool.Ping},
+	}
+	if deps.ReplicaDB != nil {
+		checks = append(checks, readinessCheck{name: "db_replica", check: deps.ReplicaDB.Pool.Ping})
+	}
+	if deps.FileStorage != nil {
+		checks = append(checks, readinessCheck{name: "storage", check: deps.FileStorage.Ping})
+	}
+	if deps.redisClient != nil {
+		checks = append(checks, readinessCheck{name: "redis", check: func(ctx context.Context) error {
+			return deps.redisClient.Ping(ctx).Err()
+		}})
+	}
+	if deps.cacheRedisClient != nil {
+		checks = append(checks, readinessCheck{name: "cache_redis", check: func(ctx context.Context) error {
+			return deps.cacheRedisClient.Ping(ctx).Err()
+		}})
+	}
+	if url := deps.Config.Plaid.BaseURL; url != "" {
+		checks = append(checks, readinessCheck{name: "aggregator", check: reachable(readinessClient, url)})
+	}
+	return checks
+}
+
+// reachable probes an external API over HTTP. Any answer short of a server
+// error counts, since an unauthenticated probe is expected to be refused.
+func reachable(client *http.Client, url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// healthzHandler reports the process is alive. It checks no dependencies, so
+// an outage elsewhere doesn't get the server restarted.
+func healthzHandler(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte("ok")); err != nil {
+			logger.Error("failed to write liveness response", slog.Any("error", err))
+		}
+	}
+}
+
+// readyzHandler runs the readiness checks in parallel, answering 503 when any
+// fails so load balancers stop routing to the server. The endpoint is
+// unauthenticated, so it reports only each dependency's status; why a check
+// failed is logged.
+func readyzHandler(checks []readinessCheck, logger *slog.Logger) http.HandlerFunc {
+	type status struct {
+		Status string `json:"status"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		results := make(map[string]status, len(checks))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, c := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+				defer cancel()
+				result := status{Status: "ok"}
+				if err := c.check(ctx); err != nil {
+					logger.Warn("readiness check failed", slog.String("check", c.name), slog.Any("error", err))
+					result = status{Status: "fail"}
+				}
+				mu.Lock()
+				results[c.name] = result
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		code := http.StatusOK
+		for _, result := range results {
+			if result.Status != "ok" {
+				code = http.StatusServiceUnavailable
+				break
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			logger.Error("failed to encode readiness response", slog.Any("error", err))
+		}
+	}
+}
+
+// ============================================================================
+// Server Info (Internal Integration)
+// ============================================================================
+// GET /info returns the build and the optional features this server has
+// enabled, so deployments can verify a rollout and clients hide features the
+// server can't serve. To expose as API endpoints, add the following proto
+// definitions:
+// - rpc GetServerInfo(GetServerInfoRequest) returns (GetServerInfoResponse)
+// - message GetServerInfoResponse { string version; string commit; string build_time; map<string, bool> features }
+
+// serverInfo is the /info response
+type serverInfo struct {
+	buildinfo.Info
+	Features map[string]bool `json:"features"`
+}
+
+// enabledFeatures reports the optional integrations configured on this server
+func enabledFeatures(deps *Dependencies) map[string]bool {
+	cfg := deps.Config
+	return map[string]bool{
+		"google_sign_in":      cfg.Google.ClientID != "",
+		"apple_sign_in":       cfg.OAuth.AppleClientID != "",
+		"google_sheets_sync":  deps.SheetSyncService != nil,
+		"ai_categorization":   cfg.Gemini.APIKey != "",
+		"web_push":            cfg.WebPush.VAPIDPrivateKey != "",
+		"telegram":            cfg.Telegram.BotToken != "",
+		"scheduled_jobs":      cfg.Scheduler.Enabled,
+		"shared_rate_limits":  deps.redisClient != nil,
+		"shared_cache":        deps.cacheRedisClient != nil,
+		"read_replica":        deps.ReplicaDB != nil,
+		"tracing":             cfg.Observability.TracingEnabled,
+		"metrics":             cfg.Observability.MetricsEnabled,
+		"failure_injection":   cfg.Chaos.Enabled,
+		"idempotent_requests": deps.IdempotencyInterceptor != nil,
+	}
+}
+
+// infoHandler serves the build and enabled features, fixed for the process lifetime
+func infoHandler(deps *Dependencies) http.HandlerFunc {
+	body, err := json.Marshal(serverInfo{Info: buildinfo.Get(), Features: enabledFeatures(deps)})
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if err != nil {
+			http.Error(w, "failed to encode server info", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		if _, err := w.Write(body); err != nil {
+			deps.Logger.Error("failed to write server info", slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+func TestHealthzHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	healthzHandler(slog.New(slog.DiscardHandler)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestReadyzHandler(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error {
+		return errors.New("dial tcp 10.0.3.7:5432: connect: connection refused")
+	}
+
+	tests := []struct {
+		name     string
+		checks   []readinessCheck
+		wantCode int
+		want     map[string]string
+	}{
+		{
+			name:     "all dependencies up",
+			checks:   []readinessCheck{{name: "db", check: ok}, {name: "storage", check: ok}},
+			wantCode: http.StatusOK,
+			want:     map[string]string{"db": "ok", "storage": "ok"},
+		},
+		{
+			name:     "a dependency down",
+			checks:   []readinessCheck{{name: "db", check: failing}, {name: "aggregator", check: ok}},
+			wantCode: http.StatusServiceUnavailable,
+			want:     map[string]string{"db": "fail", "aggregator": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+			rec := httptest.NewRecorder()
+			readyzHandler(tt.checks, logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			// Only statuses go out; the failure's detail stays in the logs
+			assert.NotContains(t, rec.Body.String(), "10.0.3.7")
+
+			var body map[string]struct {
+				Status string `json:"status"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			got := make(map[string]string, len(body))
+			for name, result := range body {
+				got[name] = result.Status
+			}
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantCode != http.StatusOK {
+				assert.Contains(t, logs.String(), "10.0.3.7")
+			}
+		})
+	}
+}
+
+func TestReachable(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"answers", http.StatusOK, false},
+		{"refuses unauthenticated probes", http.StatusUnauthorized, false},
+		{"server error", http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := reachable(server.Client(), server.URL)(context.Background())
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		assert.Error(t, reachable(http.DefaultClient, server.URL)(context.Background()))
+	})
+}
+
+func TestReadinessChecks_Aggregator(t *testing.T) {
+	// Never connects; readinessChecks only needs the pool to exist
+	pool, err := pgxpool.New(context.Background(), "postgres://echo@/echo?host=/nonexistent")
+	require.NoError(t, err)
+	defer pool.Close()
+	deps := &Dependencies{Config: &config.Config{}, DB: &db.DB{Pool: pool}}
+
+	names := func() []string {
+		var names []string
+		for _, c := range readinessChecks(deps) {
+			names = append(names, c.name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"db"}, names())
+
+	deps.Config.Plaid.BaseURL = "https://sandbox.plaid.com"
+	assert.Equal(t, []string{"db", "aggregator"}, names())
+}
+
+func TestInfoHandler(t *testing.T) {
+	deps := &Dependencies{
+		Config: &config.Config{
+			Google:    config.GoogleConfig{ClientID: "client"},
+			Scheduler: config.SchedulerConfig{Enabled: true},
+		},
+		Logger: slog.New(slog.DiscardHandler),
+	}
+
+	rec := httptest.NewRecorder()
+	infoHandler(deps).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+	var info serverInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "dev", info.Version)
+	assert.True(t, info.Features["google_sign_in"])
+	assert.True(t, info.Features["scheduled_jobs"])
+	assert.False(t, info.Features["read_replica"])
+	assert.False(t, info.Features["telegram"])
+}
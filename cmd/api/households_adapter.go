@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	householdservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/household/service"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+)
+
+// householdsAdapter adapts householdservice.Service to planservice's HouseholdResolver interface
+type householdsAdapter struct {
+	svc *householdservice.Service
+}
+
+// newHouseholdsAdapter creates a new adapter
+func newHouseholdsAdapter(svc *householdservice.Service) planservice.HouseholdResolver {
+	return &householdsAdapter{svc: svc}
+}
+
+// MemberRole implements planservice.HouseholdResolver
+func (a *householdsAdapter) MemberRole(ctx context.Context, householdID, userID uuid.UUID) (planservice.HouseholdRole, error) {
+	role, err := a.svc.MemberRole(ctx, householdID, userID)
+	if err != nil {
+		return "", err
+	}
+	return planservice.HouseholdRole(role), nil
+}
+
+// MemberIDs implements planservice.HouseholdResolver
+func (a *householdsAdapter) MemberIDs(ctx context.Context, householdID uuid.UUID) ([]uuid.UUID, error) {
+	return a.svc.HouseholdMemberIDs(ctx, householdID)
+}
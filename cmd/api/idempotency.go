@@ -0,0 +1,20 @@
+package api
+
+import (
+	"log/slog"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/idempotency"
+)
+
+// newIdempotencyInterceptor makes the mutations that mobile clients retry on
+// flaky networks safe to send twice
+func newIdempotencyInterceptor(store idempotency.Store, logger *slog.Logger) *idempotency.Interceptor {
+	return idempotency.NewInterceptor(store, logger).
+		WithProcedure(echov1connect.FinanceServiceCreateManualTransactionProcedure,
+			idempotency.Replay[echov1.CreateManualTransactionResponse]).
+		WithProcedure(echov1connect.FinanceServiceContributeToGoalProcedure,
+			idempotency.Replay[echov1.ContributeToGoalResponse])
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	financehandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/finance/handler"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/user"
+)
+
+// languageAdapter adapts user.UserRepo to the finance handler's LanguageLookup interface
+type languageAdapter struct {
+	repo user.UserRepo
+}
+
+// newLanguageAdapter creates a new adapter
+func newLanguageAdapter(repo user.UserRepo) financehandler.LanguageLookup {
+	return &languageAdapter{repo: repo}
+}
+
+// UserLanguage implements financehandler.LanguageLookup
+func (a *languageAdapter) UserLanguage(ctx context.Context, userID uuid.UUID) (string, error) {
+	profile, err := a.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if profile.Language == nil {
+		return "", nil
+	}
+	return *profile.Language, nil
+}
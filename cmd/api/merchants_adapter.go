@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/categorization"
+	merchantsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/service"
+)
+
+// merchantHintsAdapter adapts merchantsservice.Service to categorization's MerchantHinter interface
+type merchantHintsAdapter struct {
+	svc *merchantsservice.Service
+}
+
+// newMerchantHintsAdapter creates a new adapter
+func newMerchantHintsAdapter(svc *merchantsservice.Service) categorization.MerchantHinter {
+	return &merchantHintsAdapter{svc: svc}
+}
+
+// HintMerchants implements categorization.MerchantHinter
+func (a *merchantHintsAdapter) HintMerchants(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*categorization.MerchantHint, error) {
+	hints, err := a.svc.HintMerchants(ctx, userID, descriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*categorization.MerchantHint, len(hints))
+	for i, h := range hints {
+		if h != nil {
+			result[i] = &categorization.MerchantHint{Name: h.Name, CategoryID: h.CategoryID}
+		}
+	}
+	return result, nil
+}
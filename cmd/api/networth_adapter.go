@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/balance"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+)
+
+// netWorthAdapter adapts balance.Service to insights' NetWorthSource interface
+type netWorthAdapter struct {
+	balances *balance.Service
+}
+
+// newNetWorthAdapter creates a new adapter
+func newNetWorthAdapter(balances *balance.Service) insights.NetWorthSource {
+	return &netWorthAdapter{balances: balances}
+}
+
+// NetWorth implements insights.NetWorthSource
+func (a *netWorthAdapter) NetWorth(ctx context.Context, userID uuid.UUID) (int64, string, error) {
+	result, err := a.balances.GetBalance(ctx, userID, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	return result.TotalNetWorthCents, result.CurrencyCode, nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	notificationsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/repository"
+	notificationsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/service"
+)
+
+const (
+	// alertSourceType marks inbox notifications created from insights alerts
+	alertSourceType = "alert"
+	// digestSourceType marks inbox notifications carrying the weekly digest
+	digestSourceType = "weekly_digest"
+)
+
+// notificationAdapter adapts notificationsservice.Service to insights' Notifier interface
+type notificationAdapter struct {
+	svc    *notificationsservice.Service
+	events *webhookEventsAdapter
+}
+
+// newNotificationAdapter creates a new adapter; alerts are also published to
+// the user's webhook endpoints as alert.created events
+func newNotificationAdapter(svc *notificationsservice.Service, events *webhookEventsAdapter) insights.Notifier {
+	return &notificationAdapter{svc: svc, events: events}
+}
+
+// NotifyAlert implements insights.Notifier. By default alerts go to push, web
+// push, webhooks and Telegram, and critical ones are emailed too; the user's
+// alert settings can pick other channels.
+func (a *notificationAdapter) NotifyAlert(ctx context.Context, alert *insights.Alert, alertChannels []insights.AlertChannel, pushData map[string]any) error {
+	channels := []notificationsrepo.Channel{
+		notificationsrepo.ChannelPush, notificationsrepo.ChannelWebPush,
+		notificationsrepo.ChannelWebhook, notificationsrepo.ChannelTelegram,
+	}
+	if alert.Severity == insights.AlertSeverityCritical {
+		channels = append(channels, notificationsrepo.ChannelEmail)
+	}
+	if alertChannels != nil {
+		channels = make([]notificationsrepo.Channel, 0, len(alertChannels))
+		for _, ch := range alertChannels {
+			channels = append(channels, notificationsrepo.Channel(ch))
+		}
+	}
+	sourceType := alertSourceType
+	_, err := a.svc.Send(ctx, notificationsservice.SendInput{
+		UserID:     alert.UserID,
+		Kind:       notificationsrepo.KindAlert,
+		Title:      alert.Title,
+		Body:       alert.Message,
+		Metadata:   alert.Metadata,
+		SourceType: &sourceType,
+		SourceID:   &alert.ID,
+		Channels:   channels,
+		PushData:   pushData,
+	})
+	a.events.AlertCreated(ctx, alert)
+	return err
+}
+
+// MarkAlertRead implements insights.Notifier
+func (a *notificationAdapter) MarkAlertRead(ctx context.Context, alertID uuid.UUID) error {
+	return a.svc.MarkSourceRead(ctx, alertSourceType, alertID)
+}
+
+// NotifyDigest implements insights.Notifier
+func (a *notificationAdapter) NotifyDigest(ctx context.Context, userID uuid.UUID, digest *insights.WeeklyDigest) error {
+	sourceType := digestSourceType
+	_, err := a.svc.Send(ctx, notificationsservice.SendInput{
+		UserID: userID,
+		Kind:   notificationsrepo.KindDigest,
+		Title:  digest.Title,
+		Body:   digest.Summary,
+		Metadata: map[string]any{
+			"week_start":            digest.WeekStart.Format("2006-01-02"),
+			"spend_minor":           digest.SpendMinor,
+			"last_week_spend_minor": digest.LastWeekSpendMinor,
+			"upcoming_count":        len(digest.Upcoming),
+			"upcoming_minor":        digest.UpcomingMinor,
+			"goal_nudges":           len(digest.Nudges),
+		},
+		SourceType: &sourceType,
+		Channels: []notificationsrepo.Channel{
+			notificationsrepo.ChannelPush, notificationsrepo.ChannelWebPush,
+			notificationsrepo.ChannelEmail, notificationsrepo.ChannelWebhook,
+		},
+		PushData: map[string]any{"week_start": digest.WeekStart.Format("2006-01-02")},
+	})
+	return err
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	quotasservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/service"
+)
+
+// storageMeterAdapter adapts importservice.ImportService to the quotas
+// service's StorageMeter interface
+type storageMeterAdapter struct {
+	imports *importservice.ImportService
+}
+
+// newStorageMeterAdapter creates a new adapter
+func newStorageMeterAdapter(imports *importservice.ImportService) quotasservice.StorageMeter {
+	return &storageMeterAdapter{imports: imports}
+}
+
+// GetStorageUsage implements quotasservice.StorageMeter
+func (a *storageMeterAdapter) GetStorageUsage(ctx context.Context, userID uuid.UUID) (*quotasservice.StorageUsage, error) {
+	usage, err := a.imports.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &quotasservice.StorageUsage{
+		Tier:       quotasservice.Tier(usage.Tier),
+		UsedBytes:  usage.UsedBytes,
+		QuotaBytes: usage.QuotaBytes,
+	}, nil
+}
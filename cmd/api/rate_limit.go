@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log/slog"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// authProcedures are guessable by brute force, so they get a strict per-IP budget
+var authProcedures = []string{
+	echov1connect.AuthServiceLoginProcedure,
+	echov1connect.AuthServiceRegisterProcedure,
+	echov1connect.AuthServiceRefreshProcedure,
+	echov1connect.WaitlistServiceAddToWaitlistProcedure,
+}
+
+// uploadProcedures carry whole files, so their budget is in bytes
+var uploadProcedures = []string{
+	echov1connect.FinanceServiceImportTransactionsCsvProcedure,
+	echov1connect.ImportServiceUploadUserFileProcedure,
+}
+
+// newRateBudgetInterceptor applies the configured per-user and per-IP budgets
+func newRateBudgetInterceptor(cfg config.RateLimitConfig, store interceptors.RateLimitStore, logger *slog.Logger) *interceptors.RateBudgetInterceptor {
+	perSecond := func(rate, burst int) interceptors.Budget {
+		return interceptors.Budget{Rate: float64(rate), Burst: burst}
+	}
+	perMinute := func(n int) interceptors.Budget {
+		return interceptors.Budget{Rate: float64(n) / 60, Burst: n}
+	}
+
+	return interceptors.NewRateBudgetInterceptor(store, interceptors.RateClass{
+		Name:    "api",
+		PerUser: perSecond(cfg.UserPerSecond, cfg.UserBurst),
+		PerIP:   perSecond(cfg.IPPerSecond, cfg.IPBurst),
+	}, logger).
+		WithClass(interceptors.RateClass{
+			Name:  "auth",
+			PerIP: perMinute(cfg.AuthPerMinute),
+		}, authProcedures...).
+		WithClass(interceptors.RateClass{
+			Name:    "upload",
+			PerUser: perMinute(cfg.ImportMBPerMinute << 20),
+			Bytes:   true,
+		}, uploadProcedures...)
+}
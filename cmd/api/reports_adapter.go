@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	reportsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/reports/service"
+)
+
+// reportSourceAdapter adapts the plan and insights services to reports' ReportSource interface
+type reportSourceAdapter struct {
+	plans    *planservice.PlanService
+	insights *insights.Service
+}
+
+// newReportSourceAdapter creates a new adapter
+func newReportSourceAdapter(plans *planservice.PlanService, insightsSvc *insights.Service) reportsservice.ReportSource {
+	return &reportSourceAdapter{plans: plans, insights: insightsSvc}
+}
+
+// MonthlyInsightsDocument implements reportsservice.ReportSource
+func (a *reportSourceAdapter) MonthlyInsightsDocument(ctx context.Context, userID uuid.UUID, monthStart time.Time) (*reportsservice.Document, error) {
+	report, err := a.insights.GetMonthlyInsights(ctx, userID, monthStart, insights.InsightsFilter{})
+	if err != nil || report == nil {
+		return nil, err
+	}
+
+	doc := &reportsservice.Document{
+		Title:        "Monthly insights",
+		Subtitle:     report.MonthStart.Format("January 2006"),
+		CurrencyCode: "EUR", // Default, should come from user settings
+	}
+	doc.Sections = append(doc.Sections, reportsservice.Section{
+		Heading: "Summary",
+		Table: &reportsservice.Table{
+			Columns: []string{"", "Amount"},
+			Rows: [][]reportsservice.Cell{
+				{reportsservice.Text("Spending"), reportsservice.Amount(report.TotalSpend)},
+				{reportsservice.Text("Income"), reportsservice.Amount(report.TotalIncome)},
+				{reportsservice.Text("Net"), reportsservice.Amount(report.Net)},
+				{reportsservice.Text("Spending vs. last month"), reportsservice.Amount(report.SpendVsLastMonth)},
+			},
+		},
+	})
+	if len(report.Highlights) > 0 {
+		doc.Sections = append(doc.Sections, reportsservice.Section{Heading: "Highlights", Paragraphs: report.Highlights})
+	}
+
+	categories := &reportsservice.Table{Columns: []string{"Category", "Spent", "Transactions"}}
+	for _, c := range report.TopCategories {
+		categories.Rows = append(categories.Rows, []reportsservice.Cell{
+			reportsservice.Text(c.CategoryName), reportsservice.Amount(c.AmountCents), reportsservice.Text(fmt.Sprint(c.TxCount)),
+		})
+	}
+	doc.Sections = append(doc.Sections, reportsservice.Section{Heading: "Top categories", Table: categories})
+
+	merchants := &reportsservice.Table{Columns: []string{"Merchant", "Spent", "Transactions"}}
+	for _, m := range report.TopMerchants {
+		merchants.Rows = append(merchants.Rows, []reportsservice.Cell{
+			reportsservice.Text(m.MerchantName), reportsservice.Amount(m.AmountCents), reportsservice.Text(fmt.Sprint(m.TxCount)),
+		})
+	}
+	doc.Sections = append(doc.Sections, reportsservice.Section{Heading: "Top merchants", Table: merchants})
+
+	var changes []string
+	for _, c := range report.Changes {
+		changes = append(changes, c.Title+": "+c.Description)
+	}
+	if len(changes) > 0 {
+		doc.Sections = append(doc.Sections, reportsservice.Section{Heading: "What changed", Paragraphs: changes})
+	}
+	if action := report.RecommendedAction; action != nil {
+		doc.Sections = append(doc.Sections, reportsservice.Section{
+			Heading:    "Recommended action",
+			Paragraphs: []string{action.Title, action.Description},
+		})
+	}
+	return doc, nil
+}
+
+// BudgetReportDocument implements reportsservice.ReportSource
+func (a *reportSourceAdapter) BudgetReportDocument(ctx context.Context, userID uuid.UUID, asOf time.Time) (*reportsservice.Document, error) {
+	report, err := a.plans.GetBudgetReport(ctx, userID, asOf)
+	if errors.Is(err, planservice.ErrNoActivePlan) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Period.End is exclusive; show the period's last day
+	lastDay := report.Period.End.AddDate(0, 0, -1)
+	doc := &reportsservice.Document{
+		Title: "Budget report · " + report.PlanName,
+		Subtitle: fmt.Sprintf("%s – %s (%.0f%% of the period elapsed)",
+			report.Period.Start.Format("2 Jan 2006"), lastDay.Format("2 Jan 2006"), report.ElapsedPercent),
+		CurrencyCode: report.CurrencyCode,
+	}
+
+	table := &reportsservice.Table{
+		Columns: []string{"Line", "Budgeted", "Actual", "Variance", "Used", "Projected", "Overrun"},
+	}
+	for _, row := range report.Rows {
+		var name string
+		switch row.Level {
+		case planservice.BudgetReportGroup:
+			name = row.Group
+		case planservice.BudgetReportCategory:
+			name = "  " + row.Category
+		case planservice.BudgetReportItem:
+			name = "    " + row.Item
+		case planservice.BudgetReportTotal:
+			name = "Total"
+		}
+		table.Rows = append(table.Rows, []reportsservice.Cell{
+			reportsservice.Text(name),
+			reportsservice.Amount(row.BudgetedMinor),
+			reportsservice.Amount(row.ActualMinor),
+			reportsservice.Amount(row.VarianceMinor),
+			reportsservice.Percent(row.PercentUsed),
+			reportsservice.Amount(row.ProjectedMinor),
+			reportsservice.Amount(row.ProjectedOverrunMinor),
+		})
+	}
+	doc.Sections = append(doc.Sections, reportsservice.Section{Heading: "Budget vs. actual", Table: table})
+	return doc, nil
+}
+
+// WrappedDocument implements reportsservice.ReportSource
+func (a *reportSourceAdapter) WrappedDocument(ctx context.Context, userID uuid.UUID, period string, start, end time.Time) (*reportsservice.Document, error) {
+	summary, err := a.insights.GetWrapped(ctx, userID, period, start, end)
+	if err != nil || summary == nil {
+		return nil, err
+	}
+
+	title := "Your month, wrapped"
+	subtitle := start.Format("January 2006")
+	if period == "year" {
+		title, subtitle = "Your year, wrapped", start.Format("2006")
+	}
+	doc := &reportsservice.Document{Title: title, Subtitle: subtitle}
+	for _, card := range summary.Cards {
+		section := reportsservice.Section{Heading: card.Title, Accent: card.Accent}
+		for _, p := range []string{card.Subtitle, card.Body} {
+			if p != "" {
+				section.Paragraphs = append(section.Paragraphs, p)
+			}
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc, nil
+}
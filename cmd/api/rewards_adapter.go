@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	rewardsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/service"
+)
+
+// rewardsAdapter adapts rewardsservice.Service to import's RewardsDetector interface
+type rewardsAdapter struct {
+	svc *rewardsservice.Service
+}
+
+// newRewardsAdapter creates a new adapter
+func newRewardsAdapter(svc *rewardsservice.Service) importservice.RewardsDetector {
+	return &rewardsAdapter{svc: svc}
+}
+
+// DetectRewards implements importservice.RewardsDetector
+func (a *rewardsAdapter) DetectRewards(ctx context.Context, userID uuid.UUID, since time.Time) error {
+	_, err := a.svc.DetectRewards(ctx, userID, since)
+	return err
+}
@@ -16,8 +16,17 @@ import (
 	"go.opentelemetry.io/otel"
 	"golang.org/x/time/rate"
 
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/admin"
+	authservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
+	billingservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/service"
+	notificationsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/service"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	reportsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/reports/service"
+	sharelinksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/service"
+	telegramservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/service"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/validation"
 )
 
 // SetupRouter configures all routes and returns the HTTP service
@@ -48,9 +57,53 @@ func SetupRouter(deps *Dependencies) http.Handler {
 		rateLimiter = interceptors.NewRateLimitInterceptor(limiter)
 	}
 
+	// Failure injection for resilience tests; innermost so faults only hit handlers
+	var chaosInterceptor connect.Interceptor
+	if deps.FaultInjector != nil {
+		chaosInterceptor = deps.FaultInjector.NewInterceptor()
+	}
+
+	// Audit log of sensitive mutations; after auth so it knows the caller
+	var auditInterceptor connect.Interceptor
+	if deps.AuditInterceptor != nil {
+		auditInterceptor = deps.AuditInterceptor
+	}
+
+	// Session JWTs, unless their session was signed out, plus scoped API keys for scripts
+	authInterceptor := interceptors.NewAuthInterceptor(jwtSecret, publicProcedures...).
+		WithTrustedProxies(deps.TrustedProxies)
+	if deps.AuthService != nil {
+		authInterceptor.WithSessions(deps.AuthService)
+	}
+	if deps.APIKeyService != nil {
+		authInterceptor.WithAPIKeys(deps.APIKeyService)
+	}
+	// The admin service is internal: admins and support staff only
+	authInterceptor.WithServiceRoles("/"+echov1connect.AdminUserServiceName+"/", admin.SupportRole)
+
+	// Per-user and per-IP budgets; after auth so it knows the user
+	var rateBudgetInterceptor connect.Interceptor
+	if deps.RateBudgetInterceptor != nil {
+		rateBudgetInterceptor = deps.RateBudgetInterceptor
+	}
+
+	// Flags evaluated for the caller; after auth, as cohorts are per user and role
+	var featureFlagInterceptor connect.Interceptor
+	if deps.FeatureFlagInterceptor != nil {
+		featureFlagInterceptor = deps.FeatureFlagInterceptor
+	}
+
+	// Replays responses to retried mutations; after auth, as keys are per user
+	var idempotencyInterceptor connect.Interceptor
+	if deps.IdempotencyInterceptor != nil {
+		idempotencyInterceptor = deps.IdempotencyInterceptor
+	}
+
 	requestIDInterceptor := interceptors.NewRequestIDInterceptor("X-Request-ID")
 	tracingInterceptor := interceptors.NewTracingInterceptor(tracer)
 	validationInterceptor := validate.NewInterceptor()
+	// Bounds the proto rules leave open: amounts, currencies, dates, pages and uploads
+	limitsInterceptor := validation.NewInterceptor(validationLimits(deps.Config.Validation))
 	// subscriptionInterceptor := subscription.NewRateLimitInterceptor(deps.SubscriptionService)
 
 	// Setup interceptor chain
@@ -58,31 +111,71 @@ func SetupRouter(deps *Dependencies) http.Handler {
 		requestIDInterceptor,
 		tracingInterceptor,
 		validationInterceptor,
+		limitsInterceptor,
 		rateLimiter,
 		// subscriptionInterceptor,
 		interceptors.NewRecoveryInterceptor(deps.Logger),
 		interceptors.NewLoggingInterceptor(deps.Logger),
-		interceptors.NewAuthInterceptor(jwtSecret, publicProcedures...),
+		authInterceptor,
+		featureFlagInterceptor,
+		rateBudgetInterceptor,
+		idempotencyInterceptor,
+		auditInterceptor,
 		observability.NewMetricsInterceptor(),
+		chaosInterceptor,
 	)
 
 	// Register Connect RPC routes
 	registerConnectRoutes(mux, deps, interceptorChain)
 
-	// Register Webhooks
-	//if deps.PaymentService != nil {
-	//	mux.Handle("/webhooks/stripe", payment.WebhookHandler(deps.PaymentService, deps.Logger))
-	//	deps.Logger.Info("registered webhook", "path", "/webhooks/stripe")
-	//}
+	// Email open tracking pixel for notifications
+	if deps.EmailOpenHandler != nil {
+		mux.Handle(notificationsservice.EmailOpenPath, deps.EmailOpenHandler)
+	}
+
+	// Google and Apple sign-in redirects
+	if deps.OAuthLoginHandler != nil {
+		mux.Handle(authservice.OAuthLoginPath, deps.OAuthLoginHandler)
+	}
+
+	// Google Sheets OAuth callback for plan sync
+	if deps.SheetsOAuthHandler != nil {
+		mux.Handle(planservice.SheetsOAuthCallbackPath, deps.SheetsOAuthHandler)
+	}
+
+	// Read-only share links, opened without authentication
+	if deps.ShareLinkHandler != nil {
+		mux.Handle(sharelinksservice.SharePath, deps.ShareLinkHandler)
+	}
+
+	// Exported report downloads, authorized by the signed token in the URL
+	if deps.ReportDownloadHandler != nil {
+		mux.Handle(reportsservice.DownloadPath, deps.ReportDownloadHandler)
+	}
+
+	// User data export archives, authorized by the signed token in the URL
+	if deps.DataExportHandler != nil {
+		mux.Handle(admin.DataExportPath, deps.DataExportHandler)
+	}
+
+	// Telegram bot updates, authenticated by the webhook secret
+	if deps.TelegramHandler != nil {
+		mux.Handle(telegramservice.WebhookPath, deps.TelegramHandler)
+	}
+
+	// Stripe subscription changes, authenticated by the event signature
+	if deps.BillingHandler != nil {
+		mux.Handle(billingservice.WebhookPath, deps.BillingHandler)
+	}
 
 	// Register health and metrics routes
 	registerUtilityRoutes(mux, deps)
 
 	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},                               // For testing ONLY—narrow to specifics like "http://localhost:3000" once working. Avoid in prod.
-		AllowedMethods:   c.AllowedMethods(),                          // ["GET", "POST", "OPTIONS"]
-		AllowedHeaders:   append(c.AllowedHeaders(), "Authorization"), // Adds "Authorization" for safety; full list: ["Accept-Encoding", "Content-Encoding", "Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent", "Authorization"]
-		ExposedHeaders:   c.ExposedHeaders(),                          // ["Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"]
+		AllowedOrigins:   []string{"*"},                                                                                                            // For testing ONLY—narrow to specifics like "http://localhost:3000" once working. Avoid in prod.
+		AllowedMethods:   c.AllowedMethods(),                                                                                                       // ["GET", "POST", "OPTIONS"]
+		AllowedHeaders:   append(c.AllowedHeaders(), "Authorization", "X-Device-Name", "Idempotency-Key", "If-Match", "Traceparent", "Tracestate"), // Adds "Authorization", the session device name, retry keys, plan versions and trace context; full list: ["Accept-Encoding", "Content-Encoding", "Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms", "Grpc-Timeout", "X-Grpc-Web", "X-User-Agent", "Authorization", "X-Device-Name", "Idempotency-Key", "If-Match", "Traceparent", "Tracestate"]
+		ExposedHeaders:   append(c.ExposedHeaders(), "Retry-After", "Idempotent-Replayed", "ETag"),                                                 // ["Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin", "Retry-After", "Idempotent-Replayed", "ETag"]
 		AllowCredentials: true,
 		MaxAge:           7200, // Cache preflights for 2 hours
 	})
@@ -167,6 +260,12 @@ func registerConnectRoutes(mux *http.ServeMux, deps *Dependencies, opts connect.
 		deps.Logger.Info("registered Connect RPC service", "path", waitlistPath)
 	}
 
+	if deps.SupportHandler != nil {
+		adminPath, adminHandler := echov1connect.NewAdminUserServiceHandler(deps.SupportHandler, opts)
+		mux.Handle(adminPath, adminHandler)
+		deps.Logger.Info("registered Connect RPC service", "path", adminPath)
+	}
+
 	deps.Logger.Info("Connect RPC routes configured")
 }
 
@@ -242,14 +341,16 @@ func registerUtilityRoutes(mux *http.ServeMux, deps *Dependencies) {
 	})
 	deps.Logger.Info("registered health details", "path", "/health/details")
 
-	// Readiness check endpoint
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write([]byte("ready")); err != nil {
-			deps.Logger.Error("failed to write readiness response", slog.Any("error", err))
-		}
-	})
-	deps.Logger.Info("registered readiness check", "path", "/ready")
+	// Liveness and readiness probes; /ready is kept for existing deployments
+	mux.HandleFunc("/healthz", healthzHandler(deps.Logger))
+	ready := readyzHandler(readinessChecks(deps), deps.Logger)
+	mux.HandleFunc("/readyz", ready)
+	mux.HandleFunc("/ready", ready)
+	deps.Logger.Info("registered liveness and readiness checks", "paths", []string{"/healthz", "/readyz", "/ready"})
+
+	// Build and enabled features, for deployments and client feature gating
+	mux.HandleFunc("/info", infoHandler(deps))
+	deps.Logger.Info("registered server info", "path", "/info")
 
 	// Metrics endpoint (Prometheus)
 	if deps.Config.Observability.MetricsEnabled {
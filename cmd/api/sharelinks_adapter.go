@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	sharelinksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/service"
+)
+
+// shareSnapshotAdapter adapts the plan and insights services to sharelinks' SnapshotSource interface
+type shareSnapshotAdapter struct {
+	plans    *planservice.PlanService
+	insights *insights.Service
+}
+
+// newShareSnapshotAdapter creates a new adapter
+func newShareSnapshotAdapter(plans *planservice.PlanService, insightsSvc *insights.Service) sharelinksservice.SnapshotSource {
+	return &shareSnapshotAdapter{plans: plans, insights: insightsSvc}
+}
+
+// PlanSnapshot implements sharelinksservice.SnapshotSource
+func (a *shareSnapshotAdapter) PlanSnapshot(ctx context.Context, userID, planID uuid.UUID) (*sharelinksservice.PlanSnapshot, error) {
+	details, err := a.plans.GetPlanWithDetails(ctx, userID, planID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || details == nil || details.Plan == nil {
+		return nil, err
+	}
+
+	snapshot := &sharelinksservice.PlanSnapshot{
+		Name:         details.Plan.Name,
+		CurrencyCode: details.Plan.CurrencyCode,
+	}
+	if details.Period != nil {
+		start, end := details.Period.Start, details.Period.End
+		snapshot.PeriodStart, snapshot.PeriodEnd = &start, &end
+	}
+
+	// Items hang off categories, which hang off groups; keep the plan's group order
+	groupOf := make(map[uuid.UUID]uuid.UUID, len(details.Categories))
+	for _, c := range details.Categories {
+		if c.GroupID != nil {
+			groupOf[c.ID] = *c.GroupID
+		}
+	}
+	groupIndex := make(map[uuid.UUID]int, len(details.Groups))
+	for _, g := range details.Groups {
+		groupIndex[g.ID] = len(snapshot.Groups)
+		snapshot.Groups = append(snapshot.Groups, sharelinksservice.SnapshotGroup{Name: g.Name})
+	}
+	other := -1
+
+	for _, item := range details.Items {
+		idx := -1
+		if item.CategoryID != nil {
+			if groupID, ok := groupOf[*item.CategoryID]; ok {
+				if i, ok := groupIndex[groupID]; ok {
+					idx = i
+				}
+			}
+		}
+		if idx < 0 {
+			if other < 0 {
+				other = len(snapshot.Groups)
+				snapshot.Groups = append(snapshot.Groups, sharelinksservice.SnapshotGroup{Name: "Other"})
+			}
+			idx = other
+		}
+
+		snapshot.Groups[idx].Items = append(snapshot.Groups[idx].Items, sharelinksservice.SnapshotItem{
+			Name:          item.Name,
+			ItemType:      string(item.ItemType),
+			BudgetedMinor: item.BudgetedMinor,
+			ActualMinor:   item.ActualMinor,
+		})
+		snapshot.TotalBudgetedMinor += item.BudgetedMinor
+		snapshot.TotalActualMinor += item.ActualMinor
+	}
+	return snapshot, nil
+}
+
+// MonthlyReportSnapshot implements sharelinksservice.SnapshotSource
+func (a *shareSnapshotAdapter) MonthlyReportSnapshot(ctx context.Context, userID uuid.UUID, monthStart time.Time) (*sharelinksservice.ReportSnapshot, error) {
+	report, err := a.insights.GetMonthlyInsights(ctx, userID, monthStart, insights.InsightsFilter{})
+	if err != nil || report == nil {
+		return nil, err
+	}
+
+	snapshot := &sharelinksservice.ReportSnapshot{
+		MonthStart:         report.MonthStart,
+		TotalSpendMinor:    report.TotalSpend,
+		TotalIncomeMinor:   report.TotalIncome,
+		NetMinor:           report.Net,
+		SpendChangePercent: report.SpendChangePercent,
+		Highlights:         report.Highlights,
+	}
+	for _, c := range report.TopCategories {
+		snapshot.TopCategories = append(snapshot.TopCategories, sharelinksservice.SnapshotAmount{Name: c.CategoryName, AmountMinor: c.AmountCents})
+	}
+	for _, m := range report.TopMerchants {
+		snapshot.TopMerchants = append(snapshot.TopMerchants, sharelinksservice.SnapshotAmount{Name: m.MerchantName, AmountMinor: m.AmountCents})
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+)
+
+// streaksAdapter adapts planservice.BudgetPeriodService to insights' StreakSource interface
+type streaksAdapter struct {
+	plans   planrepo.PlanRepository
+	periods *planservice.BudgetPeriodService
+}
+
+// newStreaksAdapter creates a new adapter
+func newStreaksAdapter(plans planrepo.PlanRepository, periods *planservice.BudgetPeriodService) insights.StreakSource {
+	return &streaksAdapter{plans: plans, periods: periods}
+}
+
+// GetBudgetStreaks implements insights.StreakSource
+func (a *streaksAdapter) GetBudgetStreaks(ctx context.Context, userID uuid.UUID, asOf time.Time) (*insights.BudgetStreaks, error) {
+	plan, err := a.plans.GetActivePlan(ctx, userID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	streaks, err := a.periods.ComputeAdherenceStreaks(ctx, plan.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &insights.BudgetStreaks{
+		PlanID:  plan.ID,
+		Overall: insights.BudgetStreak{Current: streaks.Overall.Current, Longest: streaks.Overall.Longest},
+	}
+	for _, c := range streaks.Categories {
+		result.Categories = append(result.Categories, insights.BudgetStreak{
+			CategoryName: c.CategoryName,
+			Current:      c.Current,
+			Longest:      c.Longest,
+		})
+	}
+	return result, nil
+}
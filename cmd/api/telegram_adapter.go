@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	financehandler "github.com/FACorreiaa/smart-finance-tracker/internal/domain/finance/handler"
+	telegramservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/service"
+)
+
+// telegramCaptureAdapter adapts the finance handler's Quick Capture to telegramservice's QuickCapture interface
+type telegramCaptureAdapter struct {
+	finance *financehandler.FinanceHandler
+}
+
+// newTelegramCaptureAdapter creates a new adapter
+func newTelegramCaptureAdapter(finance *financehandler.FinanceHandler) telegramservice.QuickCapture {
+	return &telegramCaptureAdapter{finance: finance}
+}
+
+// Capture implements telegramservice.QuickCapture
+func (a *telegramCaptureAdapter) Capture(ctx context.Context, userID uuid.UUID, text string) (*telegramservice.CapturedTransaction, error) {
+	tx, err := a.finance.CaptureText(ctx, userID, text)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return &telegramservice.CapturedTransaction{
+		Description:  tx.GetDescription(),
+		AmountMinor:  tx.GetAmount().GetAmountMinor(),
+		CurrencyCode: tx.GetAmount().GetCurrencyCode(),
+	}, nil
+}
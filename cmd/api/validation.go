@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/validation"
+)
+
+// validationLimits tightens the default request bounds with the configured
+// CSV and page caps; non-positive values keep the defaults
+func validationLimits(cfg config.ValidationConfig) validation.Limits {
+	limits := validation.DefaultLimits()
+	if cfg.MaxCSVMB > 0 {
+		limits.MaxCSVBytes = cfg.MaxCSVMB << 20
+	}
+	if cfg.MaxCSVRows > 0 {
+		limits.MaxCSVRows = cfg.MaxCSVRows
+	}
+	if cfg.MaxPageSize > 0 {
+		limits.MaxPageSize = int32(cfg.MaxPageSize)
+	}
+	return limits
+}
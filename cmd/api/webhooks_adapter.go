@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	goalsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+	goalsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/service"
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	webhooksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/service"
+)
+
+// webhookEventsAdapter publishes import, goal, alert and plan events to the
+// user's webhook endpoints
+type webhookEventsAdapter struct {
+	svc    *webhooksservice.Service
+	plans  planrepo.PlanRepository
+	logger *slog.Logger
+}
+
+// newWebhookEventsAdapter creates a new adapter
+func newWebhookEventsAdapter(svc *webhooksservice.Service, plans planrepo.PlanRepository, logger *slog.Logger) *webhookEventsAdapter {
+	return &webhookEventsAdapter{svc: svc, plans: plans, logger: logger}
+}
+
+// ImportCompleted implements importservice.ImportListener
+func (a *webhookEventsAdapter) ImportCompleted(ctx context.Context, userID uuid.UUID, result *importservice.ImportResult, institutionName string) {
+	a.publish(ctx, userID, webhooksservice.EventTransactionImported, map[string]any{
+		"import_job_id":    result.JobID.String(),
+		"institution_name": institutionName,
+		"rows_imported":    result.RowsImported,
+		"rows_failed":      result.RowsFailed,
+	})
+}
+
+// GoalMilestoneReached implements goalsservice.MilestoneListener
+func (a *webhookEventsAdapter) GoalMilestoneReached(ctx context.Context, goal *goalsrepo.Goal, milestone *goalsservice.MilestoneReached) {
+	a.publish(ctx, goal.UserID, webhooksservice.EventGoalMilestone, map[string]any{
+		"goal_id":              goal.ID.String(),
+		"goal_name":            goal.Name,
+		"percent":              milestone.Percent,
+		"current_amount_minor": goal.CurrentAmountMinor,
+		"target_amount_minor":  goal.TargetAmountMinor,
+		"currency_code":        goal.CurrencyCode,
+	})
+}
+
+// AlertCreated publishes an alert the user was notified of
+func (a *webhookEventsAdapter) AlertCreated(ctx context.Context, alert *insights.Alert) {
+	a.publish(ctx, alert.UserID, webhooksservice.EventAlertCreated, map[string]any{
+		"alert_id":   alert.ID.String(),
+		"alert_type": string(alert.AlertType),
+		"severity":   string(alert.Severity),
+		"title":      alert.Title,
+		"message":    alert.Message,
+		"metadata":   alert.Metadata,
+	})
+}
+
+// PlanChanged implements planservice.PlanChangeListener
+func (a *webhookEventsAdapter) PlanChanged(ctx context.Context, planID uuid.UUID) {
+	plan, err := a.plans.GetPlanByID(ctx, planID)
+	if err != nil {
+		a.logger.Warn("failed to load plan for webhook event", slog.String("plan_id", planID.String()), slog.Any("error", err))
+		return
+	}
+	a.publish(ctx, plan.UserID, webhooksservice.EventPlanUpdated, map[string]any{
+		"plan_id":   plan.ID.String(),
+		"plan_name": plan.Name,
+	})
+}
+
+// publish logs rather than returns failures: webhooks must never fail the
+// operation that raised the event
+func (a *webhookEventsAdapter) publish(ctx context.Context, userID uuid.UUID, eventType webhooksservice.EventType, data map[string]any) {
+	if err := a.svc.Publish(ctx, userID, eventType, data); err != nil {
+		a.logger.Warn("failed to publish webhook event",
+			slog.String("event_type", string(eventType)), slog.String("user_id", userID.String()), slog.Any("error", err))
+	}
+}
+
+// importListeners fans a completed import out to several listeners
+type importListeners []importservice.ImportListener
+
+// ImportCompleted implements importservice.ImportListener
+func (l importListeners) ImportCompleted(ctx context.Context, userID uuid.UUID, result *importservice.ImportResult, institutionName string) {
+	for _, listener := range l {
+		listener.ImportCompleted(ctx, userID, result, institutionName)
+	}
+}
+
+// planChangeListeners fans a plan change out to several listeners
+type planChangeListeners []planservice.PlanChangeListener
+
+// PlanChanged implements planservice.PlanChangeListener
+func (l planChangeListeners) PlanChanged(ctx context.Context, planID uuid.UUID) {
+	for _, listener := range l {
+		listener.PlanChanged(ctx, planID)
+	}
+}
@@ -0,0 +1,402 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// AccountClosureState tracks a closed account through the anonymization pipeline
+type AccountClosureState string
+
+const (
+	// AccountClosureClosed: access is revoked and the data is soft deleted; the
+	// account can still be reopened until the grace period ends
+	AccountClosureClosed AccountClosureState = "closed"
+	// AccountClosureReopened: the user came back during the grace period
+	AccountClosureReopened AccountClosureState = "reopened"
+	// AccountClosureAnonymized: personal data is irreversibly removed; only
+	// anonymized transactional records remain, for aggregate stats
+	AccountClosureAnonymized AccountClosureState = "anonymized"
+)
+
+// AccountClosureMode is what happens to a closed account's data once its
+// grace period ends
+type AccountClosureMode string
+
+const (
+	// AccountClosureAnonymize keeps anonymized transactional records for aggregate stats
+	AccountClosureAnonymize AccountClosureMode = "anonymize"
+	// AccountClosureErase deletes everything, leaving only a tombstone
+	AccountClosureErase AccountClosureMode = "erase"
+)
+
+const (
+	// ClosureGracePeriod is how long a closed account can be reopened before
+	// it's anonymized
+	ClosureGracePeriod = 30 * 24 * time.Hour
+	// anonymizationBatchSize caps the accounts one anonymization run processes
+	anonymizationBatchSize = 50
+)
+
+var (
+	// ErrAccountClosed is returned when closing an account that's already closed
+	ErrAccountClosed = errors.New("account is already closed")
+	// ErrAccountNotClosed is returned when the user has no closure to act on
+	ErrAccountNotClosed = errors.New("account is not closed")
+	// ErrGracePeriodOver is returned when reopening an account after its grace
+	// period, or once it's anonymized
+	ErrGracePeriodOver = errors.New("account can no longer be reopened")
+)
+
+// AccountClosure is the recorded state of a closed account
+type AccountClosure struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	State          AccountClosureState
+	Mode           AccountClosureMode
+	Reason         *string
+	ClosedAt       time.Time
+	PurgeAfter     time.Time
+	ReopenedAt     *time.Time
+	AnonymizedAt   *time.Time
+	AnonymizedRows map[string]int64
+	VerifiedAt     *time.Time
+	Residual       map[string]int64
+	LastError      *string
+	UpdatedAt      time.Time
+}
+
+// AccountTombstone is the record kept of an erased account: no personal
+// data, only that it existed and when it was erased
+type AccountTombstone struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	ClosedAt     time.Time
+	ErasedAt     time.Time
+	ErasedRows   map[string]int64 // Rows deleted per table
+	FilesDeleted int64
+}
+
+// AnonymizationReport is the result of checking an anonymized account for
+// personal data left behind
+type AnonymizationReport struct {
+	UserID    uuid.UUID
+	CheckedAt time.Time
+	Residual  map[string]int64 // Rows still holding personal data, per check
+}
+
+// Passed reports whether no personal data was found
+func (r *AnonymizationReport) Passed() bool {
+	for _, n := range r.Residual {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String lists the failing checks, e.g. "profile=1, transaction_text=12"
+func (r *AnonymizationReport) String() string {
+	var failing []string
+	for check, n := range r.Residual {
+		if n > 0 {
+			failing = append(failing, fmt.Sprintf("%s=%d", check, n))
+		}
+	}
+	if len(failing) == 0 {
+		return "no personal data found"
+	}
+	sort.Strings(failing)
+	return strings.Join(failing, ", ")
+}
+
+// AccountClosureRepo defines database access for account closures
+type AccountClosureRepo interface {
+	// CloseAccount deactivates the user, revokes every credential and
+	// integration, and records the closure, in one transaction. Returns
+	// ErrAccountClosed if an earlier closure is still in effect.
+	CloseAccount(ctx context.Context, closure *AccountClosure) error
+	// GetClosure returns the user's closure, or sql.ErrNoRows
+	GetClosure(ctx context.Context, userID uuid.UUID) (*AccountClosure, error)
+	// ReopenAccount reactivates a closed account whose grace period hasn't
+	// ended; sql.ErrNoRows otherwise. Credentials stay revoked.
+	ReopenAccount(ctx context.Context, userID uuid.UUID, at time.Time) error
+	// ListDueClosures lists closed accounts whose grace period ended by now
+	ListDueClosures(ctx context.Context, now time.Time, limit int) ([]*AccountClosure, error)
+	// AnonymizeAccount irreversibly removes the personal data of a closed
+	// account past its grace period and marks it anonymized, in one
+	// transaction. Returns the rows changed per step, or sql.ErrNoRows if the
+	// account isn't due (e.g. it was reopened meanwhile).
+	AnonymizeAccount(ctx context.Context, userID uuid.UUID, at time.Time) (map[string]int64, error)
+	// EraseAccount deletes a closed account in erase mode past its grace
+	// period, with everything that belongs to it, and writes its tombstone, in
+	// one transaction. Returns sql.ErrNoRows if the account isn't due.
+	EraseAccount(ctx context.Context, userID uuid.UUID, at time.Time) (*AccountTombstone, error)
+	// RecordErasedFiles stores how many files were removed from storage on the user's tombstone
+	RecordErasedFiles(ctx context.Context, userID uuid.UUID, filesDeleted int64) error
+	// CountResidualData counts the rows still holding the user's personal data, per check
+	CountResidualData(ctx context.Context, userID uuid.UUID) (map[string]int64, error)
+	// RecordVerification stores a verification result on the user's closure
+	RecordVerification(ctx context.Context, userID uuid.UUID, residual map[string]int64, verifiedAt *time.Time, lastError *string) error
+}
+
+// AccountClosureService runs the staged anonymization of closed accounts:
+// access is revoked immediately, the data is kept for a grace period in which
+// the account can be reopened, and then it's anonymized and verified. Deleted
+// accounts go through the same stages but are erased instead.
+type AccountClosureService struct {
+	repo               AccountClosureRepo
+	storage            storage.Storage
+	erasureGracePeriod time.Duration
+	logger             *slog.Logger
+	now                func() time.Time
+}
+
+// NewAccountClosureService creates a new account closure service
+func NewAccountClosureService(repo AccountClosureRepo, logger *slog.Logger) *AccountClosureService {
+	return &AccountClosureService{
+		repo:               repo,
+		erasureGracePeriod: ClosureGracePeriod,
+		logger:             logger,
+		now:                time.Now,
+	}
+}
+
+// WithFileStorage sets the storage erased accounts' files are deleted from
+func (s *AccountClosureService) WithFileStorage(fileStorage storage.Storage) *AccountClosureService {
+	s.storage = fileStorage
+	return s
+}
+
+// WithErasureGracePeriod sets how long a deleted account can be restored
+// before it's erased. Non-positive values keep ClosureGracePeriod.
+func (s *AccountClosureService) WithErasureGracePeriod(d time.Duration) *AccountClosureService {
+	if d > 0 {
+		s.erasureGracePeriod = d
+	}
+	return s
+}
+
+// CloseAccount closes the user's account: it's deactivated and every token,
+// session and integration revoked at once, and its data is anonymized once
+// ClosureGracePeriod passes
+func (s *AccountClosureService) CloseAccount(ctx context.Context, userID uuid.UUID, reason string) (*AccountClosure, error) {
+	return s.closeAccount(ctx, userID, reason, AccountClosureAnonymize, ClosureGracePeriod)
+}
+
+// DeleteMyAccount deletes the user's account: access is revoked at once as
+// with CloseAccount, and once the erasure grace period passes all of its data
+// and stored files are erased, leaving only a tombstone. It can be undone
+// with ReopenAccount until then.
+func (s *AccountClosureService) DeleteMyAccount(ctx context.Context, userID uuid.UUID, reason string) (*AccountClosure, error) {
+	return s.closeAccount(ctx, userID, reason, AccountClosureErase, s.erasureGracePeriod)
+}
+
+func (s *AccountClosureService) closeAccount(ctx context.Context, userID uuid.UUID, reason string, mode AccountClosureMode, grace time.Duration) (*AccountClosure, error) {
+	now := s.now()
+	closure := &AccountClosure{
+		UserID:     userID,
+		State:      AccountClosureClosed,
+		Mode:       mode,
+		ClosedAt:   now,
+		PurgeAfter: now.Add(grace),
+	}
+	if reason = strings.TrimSpace(reason); reason != "" {
+		closure.Reason = &reason
+	}
+	if err := s.repo.CloseAccount(ctx, closure); err != nil {
+		return nil, err
+	}
+	s.logger.Info("account closed",
+		slog.String("user_id", userID.String()),
+		slog.String("mode", string(mode)),
+		slog.Time("purge_after", closure.PurgeAfter),
+	)
+	return closure, nil
+}
+
+// ReopenAccount reactivates a closed account during its grace period. The
+// user signs in again; revoked tokens and integrations aren't restored.
+func (s *AccountClosureService) ReopenAccount(ctx context.Context, userID uuid.UUID) error {
+	err := s.repo.ReopenAccount(ctx, userID, s.now())
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	closure, getErr := s.GetAccountClosure(ctx, userID)
+	if getErr != nil {
+		return getErr
+	}
+	if closure.State == AccountClosureReopened {
+		return ErrAccountNotClosed
+	}
+	return ErrGracePeriodOver
+}
+
+// GetAccountClosure returns the state of the user's account closure
+func (s *AccountClosureService) GetAccountClosure(ctx context.Context, userID uuid.UUID) (*AccountClosure, error) {
+	closure, err := s.repo.GetClosure(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotClosed
+	}
+	return closure, err
+}
+
+// AnonymizeDue anonymizes the closed accounts whose grace period has ended and
+// verifies each one; deleted accounts are erased instead. A failing account is
+// recorded and doesn't stop the others; the returned error joins all
+// failures. Returns how many accounts were anonymized or erased.
+func (s *AccountClosureService) AnonymizeDue(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.repo.ListDueClosures(ctx, now, anonymizationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var anonymized int
+	var errs []error
+	for _, closure := range due {
+		if closure.Mode == AccountClosureErase {
+			erased, err := s.eraseAccount(ctx, closure.UserID, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %s: %w", closure.UserID, err))
+			}
+			if erased {
+				anonymized++
+			}
+			continue
+		}
+
+		rows, err := s.repo.AnonymizeAccount(ctx, closure.UserID, now)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // Reopened since it was listed
+		}
+		if err != nil {
+			msg := err.Error()
+			if recordErr := s.repo.RecordVerification(ctx, closure.UserID, nil, nil, &msg); recordErr != nil {
+				s.logger.Warn("failed to record anonymization failure", slog.Any("error", recordErr))
+			}
+			errs = append(errs, fmt.Errorf("user %s: %w", closure.UserID, err))
+			continue
+		}
+		anonymized++
+		s.logger.Info("account anonymized",
+			slog.String("user_id", closure.UserID.String()),
+			slog.Any("rows", rows),
+		)
+
+		report, err := s.VerifyAnonymization(ctx, closure.UserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", closure.UserID, err))
+		} else if !report.Passed() {
+			errs = append(errs, fmt.Errorf("user %s: personal data left after anonymization: %s", closure.UserID, report))
+		}
+	}
+	return anonymized, errors.Join(errs...)
+}
+
+// eraseAccount deletes a due account's data and then its stored files.
+// Reports whether the account was erased; it isn't if it was reopened
+// meanwhile. Files that fail to delete are left to the orphaned_files
+// maintenance task, as nothing references them any more.
+func (s *AccountClosureService) eraseAccount(ctx context.Context, userID uuid.UUID, now time.Time) (bool, error) {
+	tombstone, err := s.repo.EraseAccount(ctx, userID, now)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil // Reopened since it was listed
+	}
+	if err != nil {
+		msg := err.Error()
+		if recordErr := s.repo.RecordVerification(ctx, userID, nil, nil, &msg); recordErr != nil {
+			s.logger.Warn("failed to record erasure failure", slog.Any("error", recordErr))
+		}
+		return false, err
+	}
+	s.logger.Info("account erased",
+		slog.String("user_id", userID.String()),
+		slog.Any("rows", tombstone.ErasedRows),
+	)
+
+	if s.storage == nil {
+		return true, nil
+	}
+	files, err := s.storage.List(ctx, userID)
+	if err != nil {
+		return true, fmt.Errorf("failed to list files of erased account: %w", err)
+	}
+	var deleted int64
+	var errs []error
+	for _, f := range files {
+		if err := s.storage.Delete(ctx, userID, f.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete file %s: %w", f.ID, err))
+			continue
+		}
+		deleted++
+	}
+	if err := s.repo.RecordErasedFiles(ctx, userID, deleted); err != nil {
+		errs = append(errs, err)
+	}
+	return true, errors.Join(errs...)
+}
+
+// VerifyAnonymization checks an anonymized account for personal data left
+// behind and records the result on its closure. It can be rerun at any time,
+// e.g. after a schema change adds a table holding personal data.
+func (s *AccountClosureService) VerifyAnonymization(ctx context.Context, userID uuid.UUID) (*AnonymizationReport, error) {
+	closure, err := s.GetAccountClosure(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if closure.State != AccountClosureAnonymized {
+		return nil, fmt.Errorf("%w: account is %s", ErrAccountNotClosed, closure.State)
+	}
+
+	residual, err := s.repo.CountResidualData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	report := &AnonymizationReport{UserID: userID, CheckedAt: s.now(), Residual: residual}
+
+	var verifiedAt *time.Time
+	var lastError *string
+	if report.Passed() {
+		verifiedAt = &report.CheckedAt
+	} else {
+		msg := "personal data left: " + report.String()
+		lastError = &msg
+		s.logger.Error("anonymization verification failed",
+			slog.String("user_id", userID.String()),
+			slog.String("residual", report.String()),
+		)
+	}
+	if err := s.repo.RecordVerification(ctx, userID, residual, verifiedAt, lastError); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// ============================================================================
+// Account Closure (Internal Integration)
+// ============================================================================
+// The following methods are available on AccountClosureService but require
+// proto definitions to be exposed:
+//
+// - CloseAccount / ReopenAccount / GetAccountClosure: for the signed-in user
+// - DeleteMyAccount: close the signed-in user's account for full erasure
+// - VerifyAnonymization: rerun verification for one account (admin only)
+//
+// Anonymization and erasure run from the scheduler (account_closure_anonymization).
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CloseAccountRequest/Response, ReopenAccountRequest/Response
+// - DeleteMyAccountRequest/Response (UserService.DeleteMyAccount), with an optional reason
+// - GetAccountClosureRequest/Response, AccountClosure, AccountClosureState
+// - AdminVerifyAnonymizationRequest/Response, AnonymizationReport
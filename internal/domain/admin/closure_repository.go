@@ -0,0 +1,393 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// anonymizedDescription replaces the text of retained transactions
+const anonymizedDescription = "[anonymized]"
+
+// closureStep is one statement of a closure stage, run with the user ID as $1
+type closureStep struct {
+	name  string
+	query string
+}
+
+// revokeAccessSteps run when an account is closed. Nothing is deleted yet, so
+// the account can be reopened during the grace period.
+var revokeAccessSteps = []closureStep{
+	{"user", `UPDATE users SET is_active = FALSE, expo_push_token = NULL WHERE id = $1`},
+	{"refresh_tokens", `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`},
+	{"sessions", `UPDATE sessions SET invalidated_at = NOW() WHERE user_id = $1 AND invalidated_at IS NULL`},
+	{"user_sessions", `DELETE FROM user_sessions WHERE user_id = $1`},
+	{"user_tokens", `DELETE FROM user_tokens WHERE user_id = $1`},
+	{"api_keys", `UPDATE api_keys SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`},
+	{"oauth_tokens", `UPDATE user_oauth_identities SET provider_access_token = NULL, provider_refresh_token = NULL WHERE user_id = $1`},
+	{"google_sheets_connections", `DELETE FROM google_sheets_connections WHERE user_id = $1`},
+	{"web_push_subscriptions", `DELETE FROM web_push_subscriptions WHERE user_id = $1`},
+	{"telegram", `DELETE FROM telegram_links WHERE user_id = $1`},
+	{"webhook_endpoints", `UPDATE webhook_endpoints SET enabled = FALSE WHERE user_id = $1 AND enabled`},
+	{"share_links", `UPDATE share_links SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`},
+	{"advisor_grants", `UPDATE advisor_grants SET revoked_at = NOW() WHERE (client_id = $1 OR advisor_id = $1) AND revoked_at IS NULL`},
+}
+
+// anonymizeSteps irreversibly remove personal data once the grace period ends.
+// Transactions, accounts, budgets and goals are kept for aggregate stats, with
+// their free text cleared. Stored files are removed by the orphaned_files
+// maintenance task once their user_files rows are gone.
+var anonymizeSteps = []closureStep{
+	{"profile", `
+		UPDATE users SET
+			email = 'deleted-' || id::TEXT || '@anonymized.invalid',
+			username = NULL, firstname = NULL, lastname = NULL, age = NULL, city = NULL,
+			about_you = NULL, phone = NULL, display_name = NULL, profile_image_url = NULL,
+			password_hash = NULL, expo_push_token = NULL, is_active = FALSE
+		WHERE id = $1`},
+	{"transaction_text", `
+		UPDATE transactions SET
+			description = '` + anonymizedDescription + `', original_description = NULL,
+			merchant_name = NULL, notes = NULL, external_id = NULL
+		WHERE user_id = $1`},
+	{"account_names", `UPDATE accounts SET name = 'Account ' || LEFT(id::TEXT, 8), last4 = NULL WHERE user_id = $1`},
+	{"goal_contribution_notes", `UPDATE goal_contributions SET note = NULL WHERE goal_id IN (SELECT id FROM goals WHERE user_id = $1)`},
+	{"refresh_tokens", `DELETE FROM refresh_tokens WHERE user_id = $1`},
+	{"sessions", `DELETE FROM sessions WHERE user_id = $1`},
+	{"user_sessions", `DELETE FROM user_sessions WHERE user_id = $1`},
+	{"user_tokens", `DELETE FROM user_tokens WHERE user_id = $1`},
+	{"api_keys", `DELETE FROM api_keys WHERE user_id = $1`},
+	{"idempotency_keys", `DELETE FROM idempotency_keys WHERE user_id = $1`},
+	{"feature_flag_overrides", `DELETE FROM feature_flag_overrides WHERE user_id = $1`},
+	{"oauth_identities", `DELETE FROM user_oauth_identities WHERE user_id = $1`},
+	{"user_providers", `DELETE FROM user_providers WHERE user_id = $1`},
+	{"period_notes", `DELETE FROM period_notes WHERE user_id = $1`},
+	{"notifications", `DELETE FROM notifications WHERE user_id = $1`},
+	{"advisor_annotations", `DELETE FROM advisor_annotations WHERE client_id = $1 OR advisor_id = $1`},
+	{"advisor_grants", `DELETE FROM advisor_grants WHERE client_id = $1 OR advisor_id = $1`},
+	{"documents", `DELETE FROM documents WHERE user_id = $1`},
+	{"webhook_endpoints", `DELETE FROM webhook_endpoints WHERE user_id = $1`},
+	{"share_links", `DELETE FROM share_links WHERE user_id = $1`},
+	{"telegram_link_codes", `DELETE FROM telegram_link_codes WHERE user_id = $1`},
+	{"household_members", `DELETE FROM household_members WHERE user_id = $1`},
+	{"upload_sessions", `DELETE FROM upload_sessions WHERE user_id = $1`},
+	{"files", `DELETE FROM user_files WHERE user_id = $1`},
+}
+
+// erasureCounts each count the rows of a dataset about to be erased, for the
+// tombstone. Everything else referencing the user goes with it by cascade.
+var erasureCounts = []closureStep{
+	{"accounts", `SELECT COUNT(*) FROM accounts WHERE user_id = $1`},
+	{"transactions", `SELECT COUNT(*) FROM transactions WHERE user_id = $1`},
+	{"categories", `SELECT COUNT(*) FROM categories WHERE user_id = $1`},
+	{"plans", `SELECT COUNT(*) FROM user_plans WHERE user_id = $1`},
+	{"goals", `SELECT COUNT(*) FROM goals WHERE user_id = $1`},
+	{"subscriptions", `SELECT COUNT(*) FROM recurring_subscriptions WHERE user_id = $1`},
+	{"files", `SELECT COUNT(*) FROM user_files WHERE user_id = $1`},
+	{"import_jobs", `SELECT COUNT(*) FROM import_jobs WHERE user_id = $1`},
+	{"data_exports", `SELECT COUNT(*) FROM data_exports WHERE user_id = $1`},
+}
+
+// residualChecks each count the rows still holding a user's personal data.
+// They mirror anonymizeSteps, plus what revokeAccessSteps removed.
+var residualChecks = []closureStep{
+	{"profile", `
+		SELECT COUNT(*) FROM users
+		WHERE id = $1 AND (email <> 'deleted-' || id::TEXT || '@anonymized.invalid'
+			OR COALESCE(username, firstname, lastname, city, about_you, phone, display_name,
+				profile_image_url, password_hash, expo_push_token) IS NOT NULL
+			OR age IS NOT NULL OR is_active)`},
+	{"transaction_text", `
+		SELECT COUNT(*) FROM transactions
+		WHERE user_id = $1 AND (description <> '` + anonymizedDescription + `'
+			OR COALESCE(original_description, merchant_name, notes, external_id) IS NOT NULL)`},
+	{"account_names", `SELECT COUNT(*) FROM accounts WHERE user_id = $1 AND (name <> 'Account ' || LEFT(id::TEXT, 8) OR last4 IS NOT NULL)`},
+	{"goal_contribution_notes", `
+		SELECT COUNT(*) FROM goal_contributions
+		WHERE note IS NOT NULL AND goal_id IN (SELECT id FROM goals WHERE user_id = $1)`},
+	{"credentials", `
+		SELECT (SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM sessions WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM user_sessions WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM user_tokens WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM api_keys WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM user_oauth_identities WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM user_providers WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM google_sheets_connections WHERE user_id = $1)`},
+	{"notes_and_messages", `
+		SELECT (SELECT COUNT(*) FROM period_notes WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM notifications WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM advisor_annotations WHERE client_id = $1 OR advisor_id = $1)
+		     + (SELECT COUNT(*) FROM documents WHERE user_id = $1)`},
+	{"integrations", `
+		SELECT (SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM share_links WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM advisor_grants WHERE client_id = $1 OR advisor_id = $1)
+		     + (SELECT COUNT(*) FROM telegram_links WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM telegram_link_codes WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM web_push_subscriptions WHERE user_id = $1)
+		     + (SELECT COUNT(*) FROM household_members WHERE user_id = $1)`},
+	{"files", `SELECT COUNT(*) FROM user_files WHERE user_id = $1`},
+}
+
+// PostgresAccountClosureRepo implements AccountClosureRepo using PostgreSQL
+type PostgresAccountClosureRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAccountClosureRepo creates a new PostgreSQL account closure repository
+func NewPostgresAccountClosureRepo(pool *pgxpool.Pool) *PostgresAccountClosureRepo {
+	return &PostgresAccountClosureRepo{pool: pool}
+}
+
+const closureColumns = `id, user_id, state, mode, reason, closed_at, purge_after, reopened_at, anonymized_at,
+	anonymized_rows, verified_at, residual, last_error, updated_at`
+
+func scanClosure(row pgx.Row) (*AccountClosure, error) {
+	c := &AccountClosure{}
+	err := row.Scan(&c.ID, &c.UserID, &c.State, &c.Mode, &c.Reason, &c.ClosedAt, &c.PurgeAfter, &c.ReopenedAt, &c.AnonymizedAt,
+		&c.AnonymizedRows, &c.VerifiedAt, &c.Residual, &c.LastError, &c.UpdatedAt)
+	return c, err
+}
+
+// CloseAccount revokes access and records the closure in one transaction
+func (r *PostgresAccountClosureRepo) CloseAccount(ctx context.Context, c *AccountClosure) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// A reopened account can be closed again, which starts a new grace period
+	closure, err := scanClosure(tx.QueryRow(ctx, `
+		INSERT INTO account_closures (user_id, state, mode, reason, closed_at, purge_after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			state = EXCLUDED.state, mode = EXCLUDED.mode, reason = EXCLUDED.reason, closed_at = EXCLUDED.closed_at,
+			purge_after = EXCLUDED.purge_after, reopened_at = NULL, last_error = NULL
+		WHERE account_closures.state = 'reopened'
+		RETURNING `+closureColumns,
+		c.UserID, c.State, c.Mode, c.Reason, c.ClosedAt, c.PurgeAfter))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAccountClosed
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record account closure: %w", err)
+	}
+
+	for _, step := range revokeAccessSteps {
+		if _, err := tx.Exec(ctx, step.query, c.UserID); err != nil {
+			return fmt.Errorf("failed to revoke %s: %w", step.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit account closure: %w", err)
+	}
+	*c = *closure
+	return nil
+}
+
+// GetClosure returns the user's closure
+func (r *PostgresAccountClosureRepo) GetClosure(ctx context.Context, userID uuid.UUID) (*AccountClosure, error) {
+	c, err := scanClosure(r.pool.QueryRow(ctx, `SELECT `+closureColumns+` FROM account_closures WHERE user_id = $1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account closure: %w", err)
+	}
+	return c, nil
+}
+
+// ReopenAccount reactivates a closed account within its grace period
+func (r *PostgresAccountClosureRepo) ReopenAccount(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE account_closures SET state = 'reopened', reopened_at = $2
+		WHERE user_id = $1 AND state = 'closed' AND purge_after > $2`, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to reopen account closure: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET is_active = TRUE WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit account reopening: %w", err)
+	}
+	return nil
+}
+
+// ListDueClosures lists closed accounts past their grace period, oldest first
+func (r *PostgresAccountClosureRepo) ListDueClosures(ctx context.Context, now time.Time, limit int) ([]*AccountClosure, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+closureColumns+` FROM account_closures
+		WHERE state = 'closed' AND purge_after <= $1
+		ORDER BY purge_after
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due account closures: %w", err)
+	}
+	defer rows.Close()
+
+	var closures []*AccountClosure
+	for rows.Next() {
+		c, err := scanClosure(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account closure: %w", err)
+		}
+		closures = append(closures, c)
+	}
+	return closures, rows.Err()
+}
+
+// AnonymizeAccount removes a due account's personal data in one transaction
+func (r *PostgresAccountClosureRepo) AnonymizeAccount(ctx context.Context, userID uuid.UUID, at time.Time) (map[string]int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the closure so a concurrent reopen waits, then sees it anonymized
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM account_closures
+		WHERE user_id = $1 AND state = 'closed' AND purge_after <= $2
+		FOR UPDATE`, userID, at).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock account closure: %w", err)
+	}
+
+	rows := make(map[string]int64, len(anonymizeSteps))
+	for _, step := range anonymizeSteps {
+		tag, err := tx.Exec(ctx, step.query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to anonymize %s: %w", step.name, err)
+		}
+		rows[step.name] = tag.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE account_closures SET state = 'anonymized', anonymized_at = $2, anonymized_rows = $3, last_error = NULL
+		WHERE id = $1`, id, at, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark account anonymized: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit anonymization: %w", err)
+	}
+	return rows, nil
+}
+
+// EraseAccount deletes a due account in erase mode and writes its tombstone in
+// one transaction. Deleting the user cascades to every table owned by it,
+// including the closure itself.
+func (r *PostgresAccountClosureRepo) EraseAccount(ctx context.Context, userID uuid.UUID, at time.Time) (*AccountTombstone, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Lock the closure so a concurrent reopen waits, then finds nothing to reopen
+	t := &AccountTombstone{UserID: userID, ErasedAt: at}
+	err = tx.QueryRow(ctx, `
+		SELECT closed_at FROM account_closures
+		WHERE user_id = $1 AND state = 'closed' AND mode = 'erase' AND purge_after <= $2
+		FOR UPDATE`, userID, at).Scan(&t.ClosedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock account closure: %w", err)
+	}
+
+	t.ErasedRows = make(map[string]int64, len(erasureCounts))
+	for _, count := range erasureCounts {
+		var n int64
+		if err := tx.QueryRow(ctx, count.query, userID).Scan(&n); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", count.name, err)
+		}
+		t.ErasedRows[count.name] = n
+	}
+
+	// Budget history doesn't cascade, as it's shared with the plan's other editors
+	if _, err := tx.Exec(ctx, `UPDATE budget_history SET changed_by = NULL WHERE changed_by = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to detach budget history: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO account_tombstones (user_id, closed_at, erased_at, erased_rows)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, userID, t.ClosedAt, t.ErasedAt, t.ErasedRows).Scan(&t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write account tombstone: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit erasure: %w", err)
+	}
+	return t, nil
+}
+
+// RecordErasedFiles stores how many stored files were removed on the tombstone
+func (r *PostgresAccountClosureRepo) RecordErasedFiles(ctx context.Context, userID uuid.UUID, filesDeleted int64) error {
+	_, err := r.pool.Exec(ctx, `UPDATE account_tombstones SET files_deleted = $2 WHERE user_id = $1`, userID, filesDeleted)
+	if err != nil {
+		return fmt.Errorf("failed to record erased files: %w", err)
+	}
+	return nil
+}
+
+// CountResidualData runs every residual check for the user
+func (r *PostgresAccountClosureRepo) CountResidualData(ctx context.Context, userID uuid.UUID) (map[string]int64, error) {
+	residual := make(map[string]int64, len(residualChecks))
+	for _, check := range residualChecks {
+		var n int64
+		if err := r.pool.QueryRow(ctx, check.query, userID).Scan(&n); err != nil {
+			return nil, fmt.Errorf("failed to check residual %s: %w", check.name, err)
+		}
+		residual[check.name] = n
+	}
+	return residual, nil
+}
+
+// RecordVerification stores a verification result. A nil residual keeps the
+// previous one, for failures before anything was checked.
+func (r *PostgresAccountClosureRepo) RecordVerification(ctx context.Context, userID uuid.UUID, residual map[string]int64, verifiedAt *time.Time, lastError *string) error {
+	var residualArg any
+	if residual != nil {
+		residualArg = residual
+	}
+	_, err := r.pool.Exec(ctx, `
+		UPDATE account_closures
+		SET residual = COALESCE($2, residual), verified_at = COALESCE($3, verified_at), last_error = $4
+		WHERE user_id = $1`, userID, residualArg, verifiedAt, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to record anonymization verification: %w", err)
+	}
+	return nil
+}
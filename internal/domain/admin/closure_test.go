@@ -0,0 +1,259 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// fakeClosureRepo keeps closures in memory; residual is what CountResidualData
+// reports after anonymization
+type fakeClosureRepo struct {
+	closures   map[uuid.UUID]*AccountClosure
+	active     map[uuid.UUID]bool
+	residual   map[uuid.UUID]map[string]int64
+	anonymized []uuid.UUID
+	tombstones map[uuid.UUID]*AccountTombstone
+}
+
+func newFakeClosureRepo() *fakeClosureRepo {
+	return &fakeClosureRepo{
+		closures:   make(map[uuid.UUID]*AccountClosure),
+		active:     make(map[uuid.UUID]bool),
+		residual:   make(map[uuid.UUID]map[string]int64),
+		tombstones: make(map[uuid.UUID]*AccountTombstone),
+	}
+}
+
+func (r *fakeClosureRepo) CloseAccount(_ context.Context, c *AccountClosure) error {
+	if existing, ok := r.closures[c.UserID]; ok && existing.State != AccountClosureReopened {
+		return ErrAccountClosed
+	}
+	c.ID = uuid.New()
+	copied := *c
+	r.closures[c.UserID] = &copied
+	r.active[c.UserID] = false
+	return nil
+}
+
+func (r *fakeClosureRepo) GetClosure(_ context.Context, userID uuid.UUID) (*AccountClosure, error) {
+	c, ok := r.closures[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *fakeClosureRepo) ReopenAccount(_ context.Context, userID uuid.UUID, at time.Time) error {
+	c, ok := r.closures[userID]
+	if !ok || c.State != AccountClosureClosed || !c.PurgeAfter.After(at) {
+		return sql.ErrNoRows
+	}
+	c.State = AccountClosureReopened
+	c.ReopenedAt = &at
+	r.active[userID] = true
+	return nil
+}
+
+func (r *fakeClosureRepo) ListDueClosures(_ context.Context, now time.Time, limit int) ([]*AccountClosure, error) {
+	var due []*AccountClosure
+	for _, c := range r.closures {
+		if c.State == AccountClosureClosed && !c.PurgeAfter.After(now) && len(due) < limit {
+			due = append(due, c)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeClosureRepo) AnonymizeAccount(_ context.Context, userID uuid.UUID, at time.Time) (map[string]int64, error) {
+	c, ok := r.closures[userID]
+	if !ok || c.State != AccountClosureClosed || c.PurgeAfter.After(at) {
+		return nil, sql.ErrNoRows
+	}
+	c.State = AccountClosureAnonymized
+	c.AnonymizedAt = &at
+	r.anonymized = append(r.anonymized, userID)
+	return map[string]int64{"profile": 1}, nil
+}
+
+func (r *fakeClosureRepo) EraseAccount(_ context.Context, userID uuid.UUID, at time.Time) (*AccountTombstone, error) {
+	c, ok := r.closures[userID]
+	if !ok || c.State != AccountClosureClosed || c.Mode != AccountClosureErase || c.PurgeAfter.After(at) {
+		return nil, sql.ErrNoRows
+	}
+	delete(r.closures, userID)
+	delete(r.active, userID)
+	t := &AccountTombstone{ID: uuid.New(), UserID: userID, ClosedAt: c.ClosedAt, ErasedAt: at, ErasedRows: map[string]int64{"transactions": 12}}
+	r.tombstones[userID] = t
+	return t, nil
+}
+
+func (r *fakeClosureRepo) RecordErasedFiles(_ context.Context, userID uuid.UUID, filesDeleted int64) error {
+	r.tombstones[userID].FilesDeleted = filesDeleted
+	return nil
+}
+
+func (r *fakeClosureRepo) CountResidualData(_ context.Context, userID uuid.UUID) (map[string]int64, error) {
+	residual := map[string]int64{"profile": 0, "transaction_text": 0}
+	for check, n := range r.residual[userID] {
+		residual[check] = n
+	}
+	return residual, nil
+}
+
+func (r *fakeClosureRepo) RecordVerification(_ context.Context, userID uuid.UUID, residual map[string]int64, verifiedAt *time.Time, lastError *string) error {
+	c := r.closures[userID]
+	if residual != nil {
+		c.Residual = residual
+	}
+	if verifiedAt != nil {
+		c.VerifiedAt = verifiedAt
+	}
+	c.LastError = lastError
+	return nil
+}
+
+func newTestClosureService(repo AccountClosureRepo, now *time.Time) *AccountClosureService {
+	svc := NewAccountClosureService(repo, slog.New(slog.DiscardHandler))
+	svc.now = func() time.Time { return *now }
+	return svc
+}
+
+func TestCloseAccount_ReopenWithinGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	repo := newFakeClosureRepo()
+	svc := newTestClosureService(repo, &now)
+	userID := uuid.New()
+
+	closure, err := svc.CloseAccount(ctx, userID, "  moving banks ")
+	require.NoError(t, err)
+	assert.Equal(t, AccountClosureClosed, closure.State)
+	assert.Equal(t, now.Add(ClosureGracePeriod), closure.PurgeAfter)
+	require.NotNil(t, closure.Reason)
+	assert.Equal(t, "moving banks", *closure.Reason)
+	assert.False(t, repo.active[userID])
+
+	_, err = svc.CloseAccount(ctx, userID, "")
+	assert.ErrorIs(t, err, ErrAccountClosed)
+
+	now = now.Add(ClosureGracePeriod - time.Hour)
+	require.NoError(t, svc.ReopenAccount(ctx, userID))
+	assert.True(t, repo.active[userID])
+	assert.ErrorIs(t, svc.ReopenAccount(ctx, userID), ErrAccountNotClosed)
+
+	// Closing again starts a new grace period, which can run out
+	closure, err = svc.CloseAccount(ctx, userID, "")
+	require.NoError(t, err)
+	assert.Nil(t, closure.Reason)
+	now = closure.PurgeAfter
+	assert.ErrorIs(t, svc.ReopenAccount(ctx, userID), ErrGracePeriodOver)
+
+	assert.ErrorIs(t, svc.ReopenAccount(ctx, uuid.New()), ErrAccountNotClosed)
+}
+
+func TestAnonymizeDue_AnonymizesAndVerifies(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	repo := newFakeClosureRepo()
+	svc := newTestClosureService(repo, &now)
+	clean, leaky, recent := uuid.New(), uuid.New(), uuid.New()
+
+	for _, userID := range []uuid.UUID{clean, leaky} {
+		_, err := svc.CloseAccount(ctx, userID, "")
+		require.NoError(t, err)
+	}
+	repo.residual[leaky] = map[string]int64{"transaction_text": 3}
+	now = now.Add(ClosureGracePeriod)
+	_, err := svc.CloseAccount(ctx, recent, "")
+	require.NoError(t, err)
+
+	anonymized, err := svc.AnonymizeDue(ctx)
+	assert.Equal(t, 2, anonymized)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), leaky.String())
+	assert.Contains(t, err.Error(), "transaction_text=3")
+	assert.NotContains(t, repo.anonymized, recent, "still in its grace period")
+
+	closure, err := svc.GetAccountClosure(ctx, clean)
+	require.NoError(t, err)
+	assert.Equal(t, AccountClosureAnonymized, closure.State)
+	assert.Equal(t, &now, closure.VerifiedAt)
+	assert.Nil(t, closure.LastError)
+
+	closure, err = svc.GetAccountClosure(ctx, leaky)
+	require.NoError(t, err)
+	assert.Nil(t, closure.VerifiedAt)
+	require.NotNil(t, closure.LastError)
+	assert.Contains(t, *closure.LastError, "transaction_text=3")
+
+	// Verification can be rerun once the leftover data is dealt with
+	delete(repo.residual, leaky)
+	report, err := svc.VerifyAnonymization(ctx, leaky)
+	require.NoError(t, err)
+	assert.True(t, report.Passed())
+	closure, err = svc.GetAccountClosure(ctx, leaky)
+	require.NoError(t, err)
+	assert.NotNil(t, closure.VerifiedAt)
+	assert.Nil(t, closure.LastError)
+
+	// Anonymized accounts are past reopening, and nothing is due twice
+	assert.ErrorIs(t, svc.ReopenAccount(ctx, clean), ErrGracePeriodOver)
+	anonymized, err = svc.AnonymizeDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, anonymized)
+}
+
+func TestDeleteMyAccount_ErasesDataAndFilesAfterGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	repo := newFakeClosureRepo()
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	grace := 7 * 24 * time.Hour
+	svc := newTestClosureService(repo, &now).WithFileStorage(fileStorage).WithErasureGracePeriod(grace)
+	deleted, closed := uuid.New(), uuid.New()
+
+	for _, name := range []string{"statement.csv", "receipt.pdf"} {
+		_, err := fileStorage.Upload(ctx, deleted, name, "text/plain", strings.NewReader(name))
+		require.NoError(t, err)
+	}
+	kept, err := fileStorage.Upload(ctx, closed, "statement.csv", "text/csv", strings.NewReader("Date,Amount\n"))
+	require.NoError(t, err)
+
+	closure, err := svc.DeleteMyAccount(ctx, deleted, "")
+	require.NoError(t, err)
+	assert.Equal(t, AccountClosureErase, closure.Mode)
+	assert.Equal(t, now.Add(grace), closure.PurgeAfter)
+	assert.False(t, repo.active[deleted], "access is revoked at once")
+	_, err = svc.CloseAccount(ctx, closed, "")
+	require.NoError(t, err)
+
+	now = now.Add(grace)
+	erased, err := svc.AnonymizeDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, erased, "the closed account is still in its grace period")
+
+	tombstone := repo.tombstones[deleted]
+	require.NotNil(t, tombstone)
+	assert.Equal(t, now, tombstone.ErasedAt)
+	assert.Equal(t, int64(2), tombstone.FilesDeleted)
+	files, err := fileStorage.List(ctx, deleted)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	_, err = fileStorage.GetInfo(ctx, closed, kept.ID)
+	assert.NoError(t, err, "other users' files are untouched")
+
+	_, err = svc.GetAccountClosure(ctx, deleted)
+	assert.ErrorIs(t, err, ErrAccountNotClosed, "nothing but the tombstone is left")
+}
@@ -0,0 +1,501 @@
+package admin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// DataExportStatus tracks an export from request to download
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending"
+	DataExportRunning DataExportStatus = "running"
+	DataExportReady   DataExportStatus = "ready"
+	DataExportFailed  DataExportStatus = "failed"
+)
+
+// DataExportPath is the public route export archives are downloaded at; the token is appended
+const DataExportPath = "/exports/download/"
+
+const (
+	// DataExportTTL is how long a finished export can be downloaded. Archives
+	// aren't tracked as user files, so the orphaned_files maintenance task
+	// removes them once its grace period has passed; links end before that.
+	DataExportTTL = orphanGracePeriod
+	// dataExportBatchSize caps the exports one run builds
+	dataExportBatchSize = 5
+	// dataExportStaleAfter is when a running export is assumed abandoned, e.g.
+	// by a restart, and is built again
+	dataExportStaleAfter = time.Hour
+)
+
+var (
+	// ErrDataExportInProgress is returned when the user already has an export pending or running
+	ErrDataExportInProgress = errors.New("a data export is already in progress")
+	// ErrDataExportNotFound is returned when the user's export doesn't exist
+	ErrDataExportNotFound = errors.New("data export not found")
+	// ErrInvalidDataExportLink is returned for a tampered, expired or unknown
+	// download link, or an export that isn't ready
+	ErrInvalidDataExportLink = errors.New("export link is invalid or has expired")
+)
+
+// DataExport is a requested export of all of a user's data, and the record
+// of it once the archive is gone
+type DataExport struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	Status           DataExportStatus
+	RequestedAt      time.Time
+	StartedAt        *time.Time
+	CompletedAt      *time.Time
+	ExpiresAt        *time.Time
+	FileID           *uuid.UUID
+	SizeBytes        int64
+	ChecksumSHA256   *string
+	RowCounts        map[string]int64 // Rows exported per dataset
+	DownloadCount    int
+	LastDownloadedAt *time.Time
+	LastError        *string
+}
+
+// Expired reports whether the export can no longer be downloaded at t
+func (e *DataExport) Expired(t time.Time) bool {
+	return e.ExpiresAt != nil && !t.Before(*e.ExpiresAt)
+}
+
+// DataExportTable is one dataset of a user's data, e.g. their transactions.
+// Values are strings, numbers, booleans, times, JSON values or nil.
+type DataExportTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// DataExportRepo defines database access for data exports
+type DataExportRepo interface {
+	// CreateExport records a pending export. Returns ErrDataExportInProgress
+	// if the user already has one pending or running.
+	CreateExport(ctx context.Context, export *DataExport) error
+	// GetExport returns an export by ID, or sql.ErrNoRows
+	GetExport(ctx context.Context, exportID uuid.UUID) (*DataExport, error)
+	// ListExports lists the user's exports, newest first
+	ListExports(ctx context.Context, userID uuid.UUID) ([]*DataExport, error)
+	// ClaimExports marks up to limit pending exports, and running ones started
+	// before staleBefore, as running at now and returns them
+	ClaimExports(ctx context.Context, now, staleBefore time.Time, limit int) ([]*DataExport, error)
+	// CompleteExport records a built archive and marks the export ready
+	CompleteExport(ctx context.Context, export *DataExport) error
+	// FailExport marks an export failed with the error
+	FailExport(ctx context.Context, exportID uuid.UUID, lastError string) error
+	// RecordDownload counts a download of the export at t
+	RecordDownload(ctx context.Context, exportID uuid.UUID, t time.Time) error
+	// DumpUserData returns every dataset of the user's data
+	DumpUserData(ctx context.Context, userID uuid.UUID) ([]*DataExportTable, error)
+	// ListUserFileIDs lists the user's uploaded files
+	ListUserFileIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// DataExportService builds full exports of a user's data: a ZIP with every
+// dataset as CSV and JSON plus their uploaded files. Exports are requested by
+// the user, built by the scheduler and downloaded through a signed link.
+type DataExportService struct {
+	repo    DataExportRepo
+	storage storage.Storage
+	secret  []byte
+	baseURL string
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewDataExportService creates a new data export service. Download links are
+// signed with secret and start with baseURL.
+func NewDataExportService(repo DataExportRepo, fileStorage storage.Storage, secret []byte, baseURL string, logger *slog.Logger) *DataExportService {
+	return &DataExportService{
+		repo:    repo,
+		storage: fileStorage,
+		secret:  secret,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// ExportUserData requests an export of all the user's data. It's built in the
+// background; poll GetDataExport until it's ready, then use DownloadURL.
+func (s *DataExportService) ExportUserData(ctx context.Context, userID uuid.UUID) (*DataExport, error) {
+	export := &DataExport{
+		UserID:      userID,
+		Status:      DataExportPending,
+		RequestedAt: s.now(),
+	}
+	if err := s.repo.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+	s.logger.Info("data export requested",
+		slog.String("user_id", userID.String()),
+		slog.String("export_id", export.ID.String()),
+	)
+	return export, nil
+}
+
+// GetDataExport returns one of the user's exports
+func (s *DataExportService) GetDataExport(ctx context.Context, userID, exportID uuid.UUID) (*DataExport, error) {
+	export, err := s.repo.GetExport(ctx, exportID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && export.UserID != userID) {
+		return nil, ErrDataExportNotFound
+	}
+	return export, err
+}
+
+// ListDataExports lists the user's exports, including expired and failed ones
+func (s *DataExportService) ListDataExports(ctx context.Context, userID uuid.UUID) ([]*DataExport, error) {
+	return s.repo.ListExports(ctx, userID)
+}
+
+// DownloadURL returns the signed download link of a ready export, or "" if it
+// can't be downloaded
+func (s *DataExportService) DownloadURL(export *DataExport) string {
+	if export.Status != DataExportReady || export.ExpiresAt == nil || export.Expired(s.now()) {
+		return ""
+	}
+	payload := export.ID.String() + "." + strconv.FormatInt(export.ExpiresAt.Unix(), 10)
+	return s.baseURL + DataExportPath + payload + "." + s.signature(payload)
+}
+
+// OpenDataExport returns the archive behind a download token and records the
+// download. The caller must close the returned reader.
+func (s *DataExportService) OpenDataExport(ctx context.Context, token string) (io.ReadCloser, *storage.FileInfo, error) {
+	now := s.now()
+	exportID, err := s.verifyToken(token, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	export, err := s.repo.GetExport(ctx, exportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrInvalidDataExportLink
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != DataExportReady || export.FileID == nil || export.Expired(now) {
+		return nil, nil, ErrInvalidDataExportLink
+	}
+
+	r, info, err := s.storage.Download(ctx, export.UserID, *export.FileID)
+	if err != nil {
+		return nil, nil, ErrInvalidDataExportLink
+	}
+	if err := s.repo.RecordDownload(ctx, export.ID, now); err != nil {
+		_ = r.Close()
+		return nil, nil, err
+	}
+	s.logger.Info("data export downloaded",
+		slog.String("user_id", export.UserID.String()),
+		slog.String("export_id", export.ID.String()),
+	)
+	return r, info, nil
+}
+
+// ProcessPending builds the pending exports. A failing export is recorded and
+// doesn't stop the others; the returned error joins all failures. Returns how
+// many exports were built.
+func (s *DataExportService) ProcessPending(ctx context.Context) (int, error) {
+	now := s.now()
+	exports, err := s.repo.ClaimExports(ctx, now, now.Add(-dataExportStaleAfter), dataExportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var built int
+	var errs []error
+	for _, export := range exports {
+		if err := s.buildExport(ctx, export); err != nil {
+			if failErr := s.repo.FailExport(ctx, export.ID, err.Error()); failErr != nil {
+				s.logger.Warn("failed to record data export failure", slog.Any("error", failErr))
+			}
+			errs = append(errs, fmt.Errorf("export %s: %w", export.ID, err))
+			continue
+		}
+		built++
+	}
+	return built, errors.Join(errs...)
+}
+
+// buildExport writes the user's archive to storage and marks the export ready
+func (s *DataExportService) buildExport(ctx context.Context, export *DataExport) error {
+	var buf bytes.Buffer
+	rowCounts, err := s.writeArchive(ctx, &buf, export)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	checksum := hex.EncodeToString(sum[:])
+	filename := "data-export-" + export.RequestedAt.UTC().Format("2006-01-02") + ".zip"
+	info, err := s.storage.Upload(ctx, export.UserID, filename, "application/zip", &buf)
+	if err != nil {
+		return fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	completedAt := s.now()
+	expiresAt := completedAt.Add(DataExportTTL).Truncate(time.Second)
+	export.Status = DataExportReady
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+	export.FileID = &info.ID
+	export.SizeBytes = info.Size
+	export.ChecksumSHA256 = &checksum
+	export.RowCounts = rowCounts
+	if err := s.repo.CompleteExport(ctx, export); err != nil {
+		return err
+	}
+
+	s.logger.Info("data export built",
+		slog.String("user_id", export.UserID.String()),
+		slog.String("export_id", export.ID.String()),
+		slog.Int64("size_bytes", info.Size),
+	)
+	return nil
+}
+
+// dataExportManifest describes an archive's contents
+type dataExportManifest struct {
+	ExportID    uuid.UUID        `json:"export_id"`
+	UserID      uuid.UUID        `json:"user_id"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Datasets    map[string]int64 `json:"datasets"` // Rows per dataset
+	Files       []string         `json:"files"`
+}
+
+// writeArchive writes the ZIP: manifest.json, each dataset as data/<name>.csv
+// and data/<name>.json, and each uploaded file under files/<id>/. Returns the
+// rows exported per dataset.
+func (s *DataExportService) writeArchive(ctx context.Context, w io.Writer, export *DataExport) (map[string]int64, error) {
+	tables, err := s.repo.DumpUserData(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := dataExportManifest{
+		ExportID:    export.ID,
+		UserID:      export.UserID,
+		GeneratedAt: s.now().UTC(),
+		Datasets:    make(map[string]int64, len(tables)),
+		Files:       []string{},
+	}
+	for _, table := range tables {
+		if err := writeDataExportTable(zw, table); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", table.Name, err)
+		}
+		manifest.Datasets[table.Name] = int64(len(table.Rows))
+	}
+
+	fileIDs, err := s.repo.ListUserFileIDs(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, fileID := range fileIDs {
+		name, err := s.copyFile(ctx, zw, export.UserID, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			manifest.Files = append(manifest.Files, name)
+		}
+	}
+
+	mw, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return manifest.Datasets, nil
+}
+
+// copyFile adds an uploaded file to the archive and returns its path there.
+// Files whose content was purged or is missing from storage are skipped.
+func (s *DataExportService) copyFile(ctx context.Context, zw *zip.Writer, userID, fileID uuid.UUID) (string, error) {
+	r, info, err := s.storage.Download(ctx, userID, fileID)
+	if err != nil {
+		s.logger.Warn("skipping file missing from storage in data export",
+			slog.String("user_id", userID.String()),
+			slog.String("file_id", fileID.String()),
+			slog.Any("error", err),
+		)
+		return "", nil
+	}
+	defer func() { _ = r.Close() }()
+
+	name := path.Join("files", fileID.String(), path.Base(info.Name))
+	fw, err := zw.Create(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to add file %s: %w", fileID, err)
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return "", fmt.Errorf("failed to copy file %s: %w", fileID, err)
+	}
+	return name, nil
+}
+
+// writeDataExportTable writes a dataset as CSV, with a header row, and as a
+// JSON array of objects keyed by column
+func writeDataExportTable(zw *zip.Writer, table *DataExportTable) error {
+	cw, err := zw.Create("data/" + table.Name + ".csv")
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(cw)
+	if err := w.Write(table.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for i, v := range row {
+			record[i] = csvValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	jw, err := zw.Create("data/" + table.Name + ".json")
+	if err != nil {
+		return err
+	}
+	// Encode rows one by one so column order is kept
+	if _, err := io.WriteString(jw, "["); err != nil {
+		return err
+	}
+	for n, row := range table.Rows {
+		if n > 0 {
+			if _, err := io.WriteString(jw, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(jw, "\n  {"); err != nil {
+			return err
+		}
+		for i, v := range row {
+			key, err := json.Marshal(table.Columns[i])
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", table.Columns[i], err)
+			}
+			sep := ", "
+			if i == 0 {
+				sep = ""
+			}
+			if _, err := fmt.Fprintf(jw, "%s%s: %s", sep, key, value); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(jw, "}"); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(jw, "\n]\n")
+	return err
+}
+
+// csvValue formats a value for CSV: times as RFC 3339, JSON values as JSON
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case bool, int, int16, int32, int64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// verifyToken returns the export a token was issued for
+func (s *DataExportService) verifyToken(token string, now time.Time) (uuid.UUID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, ErrInvalidDataExportLink
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(payload))) {
+		return uuid.Nil, ErrInvalidDataExportLink
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return uuid.Nil, ErrInvalidDataExportLink
+	}
+	exportID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, ErrInvalidDataExportLink
+	}
+	return exportID, nil
+}
+
+func (s *DataExportService) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("data-export:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ============================================================================
+// Data Export (Internal Integration)
+// ============================================================================
+// The following methods are available on DataExportService but require proto
+// definitions to be exposed:
+//
+// - ExportUserData: request an export for the signed-in user
+// - GetDataExport / ListDataExports: poll status; DownloadURL gives the link
+//
+// Archives are built by the scheduler (data_export) and downloaded over plain
+// HTTP at DataExportPath.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ExportUserDataRequest/Response (UserService.ExportUserData)
+// - GetDataExportRequest/Response, ListDataExportsRequest/Response
+// - DataExport (id, status, requested_at, completed_at, expires_at,
+//   size_bytes, download_url, row_counts), DataExportStatus
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DataExportHandler serves export archives to whoever holds a download link
+type DataExportHandler struct {
+	svc    *DataExportService
+	logger *slog.Logger
+}
+
+// NewDataExportHandler creates a new data export download handler
+func NewDataExportHandler(svc *DataExportService, logger *slog.Logger) *DataExportHandler {
+	return &DataExportHandler{svc: svc, logger: logger}
+}
+
+// ServeHTTP answers GET DataExportPath<token> with the archive as an attachment
+func (h *DataExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	token := strings.TrimPrefix(r.URL.Path, DataExportPath)
+	file, info, err := h.svc.OpenDataExport(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidDataExportLink) {
+			http.Error(w, "This download link is invalid or has expired.", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to open data export", slog.Any("error", err))
+		http.Error(w, "Failed to download export.", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+	if info.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.Warn("failed to send data export", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,281 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// exportDatasets each select one dataset of a user's data, with the user ID as
+// $1. Credentials, tokens and internal storage paths are left out.
+var exportDatasets = []closureStep{
+	{"profile", `
+		SELECT id, email, username, firstname, lastname, display_name, age, city, country, about_you,
+			phone, language, theme, profile_image_url, email_verified_at, last_login_at, created_at, updated_at
+		FROM users WHERE id = $1`},
+	{"accounts", `SELECT * FROM accounts WHERE user_id = $1 ORDER BY created_at`},
+	{"categories", `SELECT * FROM categories WHERE user_id = $1 ORDER BY name`},
+	{"transactions", `SELECT * FROM transactions WHERE user_id = $1 ORDER BY posted_at, id`},
+	{"plans", `SELECT * FROM user_plans WHERE user_id = $1 ORDER BY created_at`},
+	{"plan_category_groups", `
+		SELECT g.* FROM plan_category_groups g JOIN user_plans p ON p.id = g.plan_id
+		WHERE p.user_id = $1 ORDER BY g.plan_id, g.sort_order`},
+	{"plan_categories", `
+		SELECT c.* FROM plan_categories c JOIN user_plans p ON p.id = c.plan_id
+		WHERE p.user_id = $1 ORDER BY c.plan_id, c.sort_order`},
+	{"plan_items", `
+		SELECT i.* FROM plan_items i JOIN user_plans p ON p.id = i.plan_id
+		WHERE p.user_id = $1 ORDER BY i.plan_id, i.sort_order`},
+	{"goals", `SELECT * FROM goals WHERE user_id = $1 ORDER BY created_at`},
+	{"goal_contributions", `
+		SELECT c.* FROM goal_contributions c JOIN goals g ON g.id = c.goal_id
+		WHERE g.user_id = $1 ORDER BY c.created_at`},
+	{"subscriptions", `SELECT * FROM recurring_subscriptions WHERE user_id = $1 ORDER BY created_at`},
+	{"category_rules", `SELECT * FROM category_rules WHERE user_id = $1 ORDER BY priority DESC, created_at`},
+	{"files", `
+		SELECT id, type, mime_type, file_name, size_bytes, checksum_sha256, created_at
+		FROM user_files WHERE user_id = $1 ORDER BY created_at`},
+	{"import_jobs", `SELECT * FROM import_jobs WHERE user_id = $1 ORDER BY requested_at`},
+	{"monthly_insights", `SELECT * FROM monthly_insights WHERE user_id = $1 ORDER BY month_start`},
+	{"wrapped_summaries", `SELECT * FROM wrapped_summaries WHERE user_id = $1 ORDER BY period_start`},
+	{"alerts", `SELECT * FROM alerts WHERE user_id = $1 ORDER BY created_at`},
+	{"period_notes", `SELECT * FROM period_notes WHERE user_id = $1 ORDER BY created_at`},
+}
+
+// PostgresDataExportRepo implements DataExportRepo using PostgreSQL
+type PostgresDataExportRepo struct {
+	pool *pgxpool.Pool
+	read *pgxpool.Pool // Dumps the user's data; pool unless a replica is set
+}
+
+// NewPostgresDataExportRepo creates a new PostgreSQL data export repository
+func NewPostgresDataExportRepo(pool *pgxpool.Pool) *PostgresDataExportRepo {
+	return &PostgresDataExportRepo{pool: pool, read: pool}
+}
+
+// WithReadPool dumps user data from pool, typically a read replica, while
+// export bookkeeping stays on the primary. A nil pool is ignored.
+func (r *PostgresDataExportRepo) WithReadPool(pool *pgxpool.Pool) *PostgresDataExportRepo {
+	if pool != nil {
+		r.read = pool
+	}
+	return r
+}
+
+const dataExportColumns = `id, user_id, status, requested_at, started_at, completed_at, expires_at, file_id,
+	size_bytes, checksum_sha256, row_counts, download_count, last_downloaded_at, last_error`
+
+func scanDataExport(row pgx.Row) (*DataExport, error) {
+	e := &DataExport{}
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.RequestedAt, &e.StartedAt, &e.CompletedAt, &e.ExpiresAt, &e.FileID,
+		&e.SizeBytes, &e.ChecksumSHA256, &e.RowCounts, &e.DownloadCount, &e.LastDownloadedAt, &e.LastError)
+	return e, err
+}
+
+// CreateExport records a pending export
+func (r *PostgresDataExportRepo) CreateExport(ctx context.Context, e *DataExport) error {
+	export, err := scanDataExport(r.pool.QueryRow(ctx, `
+		INSERT INTO data_exports (user_id, status, requested_at)
+		VALUES ($1, $2, $3)
+		RETURNING `+dataExportColumns,
+		e.UserID, e.Status, e.RequestedAt))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDataExportInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+	*e = *export
+	return nil
+}
+
+// GetExport returns an export by ID
+func (r *PostgresDataExportRepo) GetExport(ctx context.Context, exportID uuid.UUID) (*DataExport, error) {
+	e, err := scanDataExport(r.pool.QueryRow(ctx, `SELECT `+dataExportColumns+` FROM data_exports WHERE id = $1`, exportID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return e, nil
+}
+
+// ListExports lists the user's exports, newest first
+func (r *PostgresDataExportRepo) ListExports(ctx context.Context, userID uuid.UUID) ([]*DataExport, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+dataExportColumns+` FROM data_exports
+		WHERE user_id = $1
+		ORDER BY requested_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	defer rows.Close()
+	return collectDataExports(rows)
+}
+
+// ClaimExports marks pending and stale running exports as running
+func (r *PostgresDataExportRepo) ClaimExports(ctx context.Context, now, staleBefore time.Time, limit int) ([]*DataExport, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE data_exports SET status = 'running', started_at = $1, last_error = NULL
+		WHERE id IN (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'running' AND started_at < $2)
+			ORDER BY requested_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+dataExportColumns, now, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data exports: %w", err)
+	}
+	defer rows.Close()
+	return collectDataExports(rows)
+}
+
+// CompleteExport records a built archive and marks the export ready
+func (r *PostgresDataExportRepo) CompleteExport(ctx context.Context, e *DataExport) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE data_exports
+		SET status = 'ready', completed_at = $2, expires_at = $3, file_id = $4,
+			size_bytes = $5, checksum_sha256 = $6, row_counts = $7, last_error = NULL
+		WHERE id = $1`,
+		e.ID, e.CompletedAt, e.ExpiresAt, e.FileID, e.SizeBytes, e.ChecksumSHA256, e.RowCounts)
+	if err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	return nil
+}
+
+// FailExport marks an export failed
+func (r *PostgresDataExportRepo) FailExport(ctx context.Context, exportID uuid.UUID, lastError string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE data_exports SET status = 'failed', last_error = $2, completed_at = NOW()
+		WHERE id = $1`, exportID, lastError)
+	if err != nil {
+		return fmt.Errorf("failed to fail data export: %w", err)
+	}
+	return nil
+}
+
+// RecordDownload counts a download of the export
+func (r *PostgresDataExportRepo) RecordDownload(ctx context.Context, exportID uuid.UUID, t time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE data_exports SET download_count = download_count + 1, last_downloaded_at = $2
+		WHERE id = $1`, exportID, t)
+	if err != nil {
+		return fmt.Errorf("failed to record data export download: %w", err)
+	}
+	return nil
+}
+
+// DumpUserData runs every export dataset for the user in one read-only
+// snapshot, so datasets are consistent with each other
+func (r *PostgresDataExportRepo) DumpUserData(ctx context.Context, userID uuid.UUID) ([]*DataExportTable, error) {
+	tx, err := r.read.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	// A long account history can outlast the pool's statement timeout
+	if err := db.LiftStatementTimeout(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	tables := make([]*DataExportTable, 0, len(exportDatasets))
+	for _, dataset := range exportDatasets {
+		table, err := dumpDataset(ctx, tx, dataset, userID)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// dumpDataset runs one dataset's query and converts its values to plain Go types
+func dumpDataset(ctx context.Context, tx pgx.Tx, dataset closureStep, userID uuid.UUID) (*DataExportTable, error) {
+	rows, err := tx.Query(ctx, dataset.query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", dataset.name, err)
+	}
+	defer rows.Close()
+
+	table := &DataExportTable{Name: dataset.name, Rows: [][]any{}}
+	for _, fd := range rows.FieldDescriptions() {
+		table.Columns = append(table.Columns, fd.Name)
+	}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dataset.name, err)
+		}
+		for i, v := range values {
+			values[i] = exportValue(v)
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", dataset.name, err)
+	}
+	return table, nil
+}
+
+// exportValue converts a pgx value into one CSV and JSON can encode: UUIDs
+// become strings and other driver values (e.g. numerics) their SQL value
+func exportValue(v any) any {
+	switch v := v.(type) {
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// ListUserFileIDs lists the user's uploaded files, oldest first
+func (r *PostgresDataExportRepo) ListUserFileIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM user_files WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user files: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user file: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func collectDataExports(rows pgx.Rows) ([]*DataExport, error) {
+	var exports []*DataExport
+	for rows.Next() {
+		e, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// fakeDataExportRepo keeps exports in memory and dumps fixed tables
+type fakeDataExportRepo struct {
+	exports map[uuid.UUID]*DataExport
+	tables  []*DataExportTable
+	files   []uuid.UUID
+}
+
+func newFakeDataExportRepo() *fakeDataExportRepo {
+	return &fakeDataExportRepo{exports: make(map[uuid.UUID]*DataExport)}
+}
+
+func (r *fakeDataExportRepo) CreateExport(_ context.Context, e *DataExport) error {
+	for _, existing := range r.exports {
+		if existing.UserID == e.UserID && (existing.Status == DataExportPending || existing.Status == DataExportRunning) {
+			return ErrDataExportInProgress
+		}
+	}
+	e.ID = uuid.New()
+	copied := *e
+	r.exports[e.ID] = &copied
+	return nil
+}
+
+func (r *fakeDataExportRepo) GetExport(_ context.Context, exportID uuid.UUID) (*DataExport, error) {
+	e, ok := r.exports[exportID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (r *fakeDataExportRepo) ListExports(_ context.Context, userID uuid.UUID) ([]*DataExport, error) {
+	var exports []*DataExport
+	for _, e := range r.exports {
+		if e.UserID == userID {
+			exports = append(exports, e)
+		}
+	}
+	return exports, nil
+}
+
+func (r *fakeDataExportRepo) ClaimExports(_ context.Context, now, staleBefore time.Time, limit int) ([]*DataExport, error) {
+	var claimed []*DataExport
+	for _, e := range r.exports {
+		stale := e.Status == DataExportRunning && e.StartedAt != nil && e.StartedAt.Before(staleBefore)
+		if len(claimed) < limit && (e.Status == DataExportPending || stale) {
+			e.Status = DataExportRunning
+			e.StartedAt = &now
+			copied := *e
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *fakeDataExportRepo) CompleteExport(_ context.Context, e *DataExport) error {
+	copied := *e
+	r.exports[e.ID] = &copied
+	return nil
+}
+
+func (r *fakeDataExportRepo) FailExport(_ context.Context, exportID uuid.UUID, lastError string) error {
+	r.exports[exportID].Status = DataExportFailed
+	r.exports[exportID].LastError = &lastError
+	return nil
+}
+
+func (r *fakeDataExportRepo) RecordDownload(_ context.Context, exportID uuid.UUID, t time.Time) error {
+	r.exports[exportID].DownloadCount++
+	r.exports[exportID].LastDownloadedAt = &t
+	return nil
+}
+
+func (r *fakeDataExportRepo) DumpUserData(context.Context, uuid.UUID) ([]*DataExportTable, error) {
+	return r.tables, nil
+}
+
+func (r *fakeDataExportRepo) ListUserFileIDs(context.Context, uuid.UUID) ([]uuid.UUID, error) {
+	return r.files, nil
+}
+
+func TestDataExport_BuildsArchiveAndRecordsDownloads(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	statement, err := fileStorage.Upload(ctx, userID, "statement.csv", "text/csv", strings.NewReader("Date,Amount\n"))
+	require.NoError(t, err)
+
+	posted := time.Date(2025, 6, 3, 10, 0, 0, 0, time.UTC)
+	repo := newFakeDataExportRepo()
+	repo.tables = []*DataExportTable{{
+		Name:    "transactions",
+		Columns: []string{"id", "description", "amount_minor", "posted_at", "notes"},
+		Rows:    [][]any{{"t1", "Coffee, large", int64(-350), posted, nil}},
+	}}
+	repo.files = []uuid.UUID{statement.ID}
+	svc := NewDataExportService(repo, fileStorage, []byte("secret"), "https://app.example.com", slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	export, err := svc.ExportUserData(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, svc.DownloadURL(export), "pending exports have no link")
+	_, err = svc.ExportUserData(ctx, userID)
+	assert.ErrorIs(t, err, ErrDataExportInProgress)
+
+	built, err := svc.ProcessPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, built)
+
+	export, err = svc.GetDataExport(ctx, userID, export.ID)
+	require.NoError(t, err)
+	require.Equal(t, DataExportReady, export.Status)
+	assert.Equal(t, map[string]int64{"transactions": 1}, export.RowCounts)
+	_, err = svc.GetDataExport(ctx, uuid.New(), export.ID)
+	assert.ErrorIs(t, err, ErrDataExportNotFound, "exports are private to their user")
+
+	token := strings.TrimPrefix(svc.DownloadURL(export), "https://app.example.com"+DataExportPath)
+	r, _, err := svc.OpenDataExport(ctx, token)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	_ = r.Close()
+	assert.Equal(t, 1, repo.exports[export.ID].DownloadCount)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+		contents[f.Name] = string(b)
+	}
+
+	assert.Equal(t, "id,description,amount_minor,posted_at,notes\nt1,\"Coffee, large\",-350,2025-06-03T10:00:00Z,\n",
+		contents["data/transactions.csv"])
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(contents["data/transactions.json"]), &rows))
+	require.Len(t, rows, 1)
+	assert.Equal(t, "Coffee, large", rows[0]["description"])
+	assert.Nil(t, rows[0]["notes"])
+	assert.Equal(t, "Date,Amount\n", contents["files/"+statement.ID.String()+"/statement.csv"])
+	assert.Contains(t, contents["manifest.json"], `"transactions": 1`)
+
+	// Links stop working once the export expires
+	svc.now = func() time.Time { return time.Now().Add(DataExportTTL + time.Minute) }
+	_, _, err = svc.OpenDataExport(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidDataExportLink)
+}
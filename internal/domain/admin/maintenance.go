@@ -0,0 +1,369 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// MaintenanceTask identifies a managed database maintenance task
+type MaintenanceTask string
+
+const (
+	MaintenanceTaskVacuumAnalyze    MaintenanceTask = "vacuum_analyze"
+	MaintenanceTaskRollupCompaction MaintenanceTask = "rollup_compaction"
+	MaintenanceTaskOrphanedFiles    MaintenanceTask = "orphaned_files"
+	MaintenanceTaskKeyRotation      MaintenanceTask = "storage_key_rotation"
+)
+
+// MaintenanceTasks lists every task in the order a full maintenance run executes them
+var MaintenanceTasks = []MaintenanceTask{
+	MaintenanceTaskRollupCompaction,
+	MaintenanceTaskOrphanedFiles,
+	MaintenanceTaskKeyRotation,   // After orphan cleanup, so deleted files aren't rewritten
+	MaintenanceTaskVacuumAnalyze, // Last, so it sees the rows the other tasks removed
+}
+
+// MaintenanceRunStatus is the outcome of a maintenance run
+type MaintenanceRunStatus string
+
+const (
+	MaintenanceRunRunning   MaintenanceRunStatus = "running"
+	MaintenanceRunSucceeded MaintenanceRunStatus = "succeeded"
+	MaintenanceRunFailed    MaintenanceRunStatus = "failed"
+)
+
+// HotTables are the high-churn tables vacuumed and analyzed on every run
+var HotTables = []string{
+	"transactions",
+	"plan_items",
+	"budget_period_items",
+	"budget_history",
+	"notifications",
+	"notification_deliveries",
+	"plan_sheet_row_states",
+}
+
+const (
+	// compactionAge is how old budget history must be before it's collapsed to one row per item
+	compactionAge = 90 * 24 * time.Hour
+	// logRetention is how long sync logs and maintenance runs are kept
+	logRetention = 90 * 24 * time.Hour
+	// orphanGracePeriod protects files uploaded moments before their user_files row is written
+	orphanGracePeriod = 24 * time.Hour
+)
+
+// ErrUnknownMaintenanceTask is returned when a task name isn't recognized
+var ErrUnknownMaintenanceTask = errors.New("unknown maintenance task")
+
+// MaintenanceRun is the recorded result of one maintenance task
+type MaintenanceRun struct {
+	ID           uuid.UUID
+	Task         MaintenanceTask
+	Status       MaintenanceRunStatus
+	TriggeredBy  *uuid.UUID // nil for scheduled runs
+	RowsAffected int64
+	Details      map[string]any
+	Error        *string
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+}
+
+// MaintenanceRepo defines database access for maintenance tasks and their results
+type MaintenanceRepo interface {
+	CreateRun(ctx context.Context, run *MaintenanceRun) error
+	FinishRun(ctx context.Context, run *MaintenanceRun) error
+	// ListRuns lists runs newest first; an empty task lists all tasks
+	ListRuns(ctx context.Context, task MaintenanceTask, limit, offset int) ([]*MaintenanceRun, int, error)
+
+	VacuumAnalyze(ctx context.Context, table string) error
+	// CompactBudgetHistory collapses each period item's history before the cutoff
+	// into a single net change, returning the number of rows removed
+	CompactBudgetHistory(ctx context.Context, before time.Time) (int64, error)
+	PruneSheetSyncLog(ctx context.Context, before time.Time) (int64, error)
+	PruneMaintenanceRuns(ctx context.Context, before time.Time) (int64, error)
+	// PruneIdempotencyKeys deletes idempotency keys that expired before the cutoff
+	PruneIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+	// PruneUploadSessions deletes chunked uploads that expired unfinished
+	// before the cutoff; their staged chunks are cleaned up as orphaned files
+	PruneUploadSessions(ctx context.Context, before time.Time) (int64, error)
+	// ListUserFileIDs returns the IDs of a user's tracked files
+	ListUserFileIDs(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// KeyRotator is implemented by file storage that encrypts files at rest
+// (storage.EncryptedStorage)
+type KeyRotator interface {
+	// RotateFileKey re-encrypts a file for the current primary master key
+	RotateFileKey(ctx context.Context, userID, fileID uuid.UUID) (storage.Rotation, error)
+}
+
+// MaintenanceService runs managed maintenance tasks and records their results
+type MaintenanceService struct {
+	repo    MaintenanceRepo
+	storage storage.Storage
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewMaintenanceService creates a new maintenance service. Orphaned file cleanup
+// is skipped when fileStorage is nil.
+func NewMaintenanceService(repo MaintenanceRepo, fileStorage storage.Storage, logger *slog.Logger) *MaintenanceService {
+	return &MaintenanceService{repo: repo, storage: fileStorage, logger: logger, now: time.Now}
+}
+
+// RunAll runs every maintenance task. A failing task is recorded and doesn't stop
+// the others; the returned error joins all task failures.
+func (s *MaintenanceService) RunAll(ctx context.Context) ([]*MaintenanceRun, error) {
+	runs := make([]*MaintenanceRun, 0, len(MaintenanceTasks))
+	var errs []error
+	for _, task := range MaintenanceTasks {
+		run, err := s.RunTask(ctx, task, nil)
+		if run != nil {
+			runs = append(runs, run)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", task, err))
+		}
+	}
+	return runs, errors.Join(errs...)
+}
+
+// RunTask runs one maintenance task and records the result. triggeredBy is the
+// operator who requested the run, or nil for scheduled runs.
+func (s *MaintenanceService) RunTask(ctx context.Context, task MaintenanceTask, triggeredBy *uuid.UUID) (*MaintenanceRun, error) {
+	var fn func(ctx context.Context, run *MaintenanceRun) error
+	switch task {
+	case MaintenanceTaskVacuumAnalyze:
+		fn = s.vacuumAnalyze
+	case MaintenanceTaskRollupCompaction:
+		fn = s.compactRollups
+	case MaintenanceTaskOrphanedFiles:
+		fn = s.cleanOrphanedFiles
+	case MaintenanceTaskKeyRotation:
+		fn = s.rotateStorageKeys
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMaintenanceTask, task)
+	}
+
+	run := &MaintenanceRun{
+		Task:        task,
+		Status:      MaintenanceRunRunning,
+		TriggeredBy: triggeredBy,
+		Details:     map[string]any{},
+		StartedAt:   s.now(),
+	}
+	if err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	taskErr := fn(ctx, run)
+	finished := s.now()
+	run.FinishedAt = &finished
+	run.Status = MaintenanceRunSucceeded
+	if taskErr != nil {
+		msg := taskErr.Error()
+		run.Status = MaintenanceRunFailed
+		run.Error = &msg
+	}
+
+	// Record the outcome even if the run was cut short by its context
+	if err := s.repo.FinishRun(context.WithoutCancel(ctx), run); err != nil {
+		s.logger.Warn("failed to record maintenance run",
+			slog.String("task", string(task)),
+			slog.Any("error", err),
+		)
+	}
+	return run, taskErr
+}
+
+// ListRuns lists recorded maintenance runs, newest first
+func (s *MaintenanceService) ListRuns(ctx context.Context, task MaintenanceTask, limit, offset int) ([]*MaintenanceRun, int, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	return s.repo.ListRuns(ctx, task, limit, offset)
+}
+
+// vacuumAnalyze reclaims dead tuples and refreshes planner statistics on hot tables
+func (s *MaintenanceService) vacuumAnalyze(ctx context.Context, run *MaintenanceRun) error {
+	var vacuumed []string
+	for _, table := range HotTables {
+		if err := s.repo.VacuumAnalyze(ctx, table); err != nil {
+			run.Details["tables"] = vacuumed
+			return err
+		}
+		vacuumed = append(vacuumed, table)
+	}
+	run.Details["tables"] = vacuumed
+	return nil
+}
+
+// compactRollups collapses old budget history and prunes expired logs and
+// idempotency keys
+func (s *MaintenanceService) compactRollups(ctx context.Context, run *MaintenanceRun) error {
+	now := s.now()
+
+	history, err := s.repo.CompactBudgetHistory(ctx, now.Add(-compactionAge))
+	if err != nil {
+		return err
+	}
+	run.Details["budget_history_rows"] = history
+
+	syncLog, err := s.repo.PruneSheetSyncLog(ctx, now.Add(-logRetention))
+	if err != nil {
+		return err
+	}
+	run.Details["sheet_sync_log_rows"] = syncLog
+
+	runs, err := s.repo.PruneMaintenanceRuns(ctx, now.Add(-logRetention))
+	if err != nil {
+		return err
+	}
+	run.Details["maintenance_run_rows"] = runs
+
+	keys, err := s.repo.PruneIdempotencyKeys(ctx, now)
+	if err != nil {
+		return err
+	}
+	run.Details["idempotency_key_rows"] = keys
+
+	uploads, err := s.repo.PruneUploadSessions(ctx, now)
+	if err != nil {
+		return err
+	}
+	run.Details["upload_session_rows"] = uploads
+
+	run.RowsAffected = history + syncLog + runs + keys + uploads
+	return nil
+}
+
+// cleanOrphanedFiles deletes stored files that no user_files row references,
+// including everything left behind by deleted users
+func (s *MaintenanceService) cleanOrphanedFiles(ctx context.Context, run *MaintenanceRun) error {
+	if s.storage == nil {
+		run.Details["skipped"] = "no file storage configured"
+		return nil
+	}
+
+	owners, err := s.storage.ListOwners(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := s.now().Add(-orphanGracePeriod)
+	var deleted, failed int64
+	for _, userID := range owners {
+		tracked, err := s.repo.ListUserFileIDs(ctx, userID)
+		if err != nil {
+			return err
+		}
+		files, err := s.storage.List(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to list files for user %s: %w", userID, err)
+		}
+
+		for _, f := range files {
+			if tracked[f.ID] || f.CreatedAt.After(cutoff) {
+				continue
+			}
+			if err := s.storage.Delete(ctx, userID, f.ID); err != nil {
+				s.logger.Warn("failed to delete orphaned file",
+					slog.String("user_id", userID.String()),
+					slog.String("file_id", f.ID.String()),
+					slog.Any("error", err),
+				)
+				failed++
+				continue
+			}
+			deleted++
+		}
+	}
+
+	run.RowsAffected = deleted
+	run.Details["owners_scanned"] = len(owners)
+	run.Details["files_deleted"] = deleted
+	run.Details["files_failed"] = failed
+	return nil
+}
+
+// rotateStorageKeys rewraps the data keys of files encrypted with a retired
+// master key, and encrypts files stored before encryption was enabled. A
+// retired key can be removed from STORAGE_ENCRYPTION_KEYS once a run
+// succeeds after the primary key changed.
+func (s *MaintenanceService) rotateStorageKeys(ctx context.Context, run *MaintenanceRun) error {
+	if s.storage == nil {
+		run.Details["skipped"] = "no file storage configured"
+		return nil
+	}
+	rotator, ok := s.storage.(KeyRotator)
+	if !ok {
+		run.Details["skipped"] = "file storage isn't encrypted"
+		return nil
+	}
+
+	owners, err := s.storage.ListOwners(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rewrapped, encrypted, failed int64
+	for _, userID := range owners {
+		files, err := s.storage.List(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to list files for user %s: %w", userID, err)
+		}
+
+		for _, f := range files {
+			rotation, err := rotator.RotateFileKey(ctx, userID, f.ID)
+			if errors.Is(err, storage.ErrReplaceUnsupported) {
+				return err
+			}
+			if err != nil {
+				s.logger.Warn("failed to rotate file key",
+					slog.String("user_id", userID.String()),
+					slog.String("file_id", f.ID.String()),
+					slog.Any("error", err),
+				)
+				failed++
+				continue
+			}
+			switch rotation {
+			case storage.RotationRewrapped:
+				rewrapped++
+			case storage.RotationEncrypted:
+				encrypted++
+			}
+		}
+	}
+
+	run.RowsAffected = rewrapped + encrypted
+	run.Details["owners_scanned"] = len(owners)
+	run.Details["files_rewrapped"] = rewrapped
+	run.Details["files_encrypted"] = encrypted
+	run.Details["files_failed"] = failed
+	if failed > 0 {
+		// Fail the run so nobody retires a key files still depend on
+		return fmt.Errorf("failed to rotate %d files", failed)
+	}
+	return nil
+}
+
+// ============================================================================
+// Database Maintenance (Internal Integration)
+// ============================================================================
+// The following methods are available on MaintenanceService but require proto
+// definitions to be exposed on the admin service:
+//
+// - RunTask: run one maintenance task on demand (admin only)
+// - ListRuns: recorded maintenance runs with their results
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AdminRunMaintenanceRequest/Response
+// - AdminListMaintenanceRunsRequest/Response, MaintenanceRun
@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// PostgresMaintenanceRepo implements MaintenanceRepo using PostgreSQL
+type PostgresMaintenanceRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresMaintenanceRepo creates a new PostgreSQL maintenance repository
+func NewPostgresMaintenanceRepo(pool *pgxpool.Pool) *PostgresMaintenanceRepo {
+	return &PostgresMaintenanceRepo{pool: pool}
+}
+
+// CreateRun records the start of a maintenance run
+func (r *PostgresMaintenanceRepo) CreateRun(ctx context.Context, run *MaintenanceRun) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO maintenance_runs (task, status, triggered_by, details, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		run.Task, run.Status, run.TriggeredBy, run.Details, run.StartedAt,
+	).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create maintenance run: %w", err)
+	}
+	return nil
+}
+
+// FinishRun records a maintenance run's outcome
+func (r *PostgresMaintenanceRepo) FinishRun(ctx context.Context, run *MaintenanceRun) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE maintenance_runs
+		SET status = $2, rows_affected = $3, details = $4, error = $5, finished_at = $6
+		WHERE id = $1`,
+		run.ID, run.Status, run.RowsAffected, run.Details, run.Error, run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish maintenance run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns lists maintenance runs, newest first
+func (r *PostgresMaintenanceRepo) ListRuns(ctx context.Context, task MaintenanceTask, limit, offset int) ([]*MaintenanceRun, int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM maintenance_runs WHERE ($1 = '' OR task = $1)`, task,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count maintenance runs: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, task, status, triggered_by, rows_affected, details, error, started_at, finished_at
+		FROM maintenance_runs
+		WHERE ($1 = '' OR task = $1)
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3`, task, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list maintenance runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*MaintenanceRun
+	for rows.Next() {
+		run := &MaintenanceRun{}
+		if err := rows.Scan(&run.ID, &run.Task, &run.Status, &run.TriggeredBy, &run.RowsAffected,
+			&run.Details, &run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan maintenance run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// VacuumAnalyze runs VACUUM (ANALYZE) on a table. VACUUM can't run inside a
+// transaction, so this must go straight to a connection, and may take longer
+// than the pool's statement timeout allows.
+func (r *PostgresMaintenanceRepo) VacuumAnalyze(ctx context.Context, table string) error {
+	return db.WithoutStatementTimeout(ctx, r.pool, func(conn *pgxpool.Conn) error {
+		if _, err := conn.Exec(ctx, "VACUUM (ANALYZE) "+pgx.Identifier{table}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to vacuum %s: %w", table, err)
+		}
+		return nil
+	})
+}
+
+// CompactBudgetHistory replaces an item's history rows before the cutoff with one
+// row spanning its first old values to its last new values. changed_by is cleared
+// since the compacted row may cover several users' edits.
+func (r *PostgresMaintenanceRepo) CompactBudgetHistory(ctx context.Context, before time.Time) (int64, error) {
+	var removed int64
+	err := r.pool.QueryRow(ctx, `
+		WITH removed AS (
+			DELETE FROM budget_history
+			WHERE changed_at < $1
+			  AND period_item_id IN (
+				SELECT period_item_id
+				FROM budget_history
+				WHERE changed_at < $1
+				GROUP BY period_item_id
+				HAVING COUNT(*) > 1
+			  )
+			RETURNING *
+		), inserted AS (
+			INSERT INTO budget_history (period_item_id, old_budgeted_minor, new_budgeted_minor, old_actual_minor, new_actual_minor, changed_at)
+			SELECT period_item_id,
+			       (ARRAY_AGG(old_budgeted_minor ORDER BY changed_at, id))[1],
+			       (ARRAY_AGG(new_budgeted_minor ORDER BY changed_at DESC, id DESC))[1],
+			       (ARRAY_AGG(old_actual_minor ORDER BY changed_at, id))[1],
+			       (ARRAY_AGG(new_actual_minor ORDER BY changed_at DESC, id DESC))[1],
+			       MAX(changed_at)
+			FROM removed
+			GROUP BY period_item_id
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM removed) - (SELECT COUNT(*) FROM inserted)`, before,
+	).Scan(&removed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact budget history: %w", err)
+	}
+	return removed, nil
+}
+
+// PruneSheetSyncLog deletes sheet sync log entries before the cutoff
+func (r *PostgresMaintenanceRepo) PruneSheetSyncLog(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM plan_sheet_sync_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sheet sync log: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneMaintenanceRuns deletes finished maintenance runs before the cutoff
+func (r *PostgresMaintenanceRepo) PruneMaintenanceRuns(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM maintenance_runs WHERE started_at < $1 AND status <> 'running'`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune maintenance runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneIdempotencyKeys deletes idempotency keys that expired before the cutoff
+func (r *PostgresMaintenanceRepo) PruneIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneUploadSessions deletes chunked uploads that expired unfinished before the cutoff
+func (r *PostgresMaintenanceRepo) PruneUploadSessions(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM upload_sessions WHERE status = 'pending' AND expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune upload sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListUserFileIDs returns the IDs of a user's tracked files
+func (r *PostgresMaintenanceRepo) ListUserFileIDs(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM user_files WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user files: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user file: %w", err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,66 @@
+//go:build integration
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// Run against any Postgres database:
+//
+//	TEST_DATABASE_URL=... go test -tags integration -run VacuumAnalyze ./internal/domain/admin/
+
+// TestVacuumAnalyze_OutlastsStatementTimeout holds a lock VACUUM waits for
+// past the pool's statement timeout, which lock waits count towards
+func TestVacuumAnalyze_OutlastsStatementTimeout(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	database, err := db.New(db.Config{
+		DSN:              dsn,
+		MaxConns:         2,
+		StatementTimeout: 50 * time.Millisecond,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(database.Close)
+
+	table := "vacuum_probe_" + uuid.NewString()[:8]
+	if _, err := database.Pool.Exec(ctx, fmt.Sprintf("CREATE TABLE %s (id int)", pgx.Identifier{table}.Sanitize())); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = database.Pool.Exec(context.Background(), "DROP TABLE IF EXISTS "+pgx.Identifier{table}.Sanitize())
+	})
+
+	lock, err := database.Pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	if _, err := lock.Exec(ctx, "LOCK TABLE "+pgx.Identifier{table}.Sanitize()+" IN SHARE UPDATE EXCLUSIVE MODE"); err != nil {
+		t.Fatalf("failed to lock table: %v", err)
+	}
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		_ = lock.Rollback(context.Background())
+	}()
+
+	repo := NewPostgresMaintenanceRepo(database.Pool)
+	if err := repo.VacuumAnalyze(ctx, table); err != nil {
+		t.Fatalf("expected VACUUM to wait out the lock, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+type fakeMaintenanceRepo struct {
+	runs       []*MaintenanceRun
+	finished   []*MaintenanceRun
+	vacuumed   []string
+	vacuumErr  error
+	userFiles  map[uuid.UUID]map[uuid.UUID]bool
+	compacted  int64
+	compactCut time.Time
+}
+
+func (r *fakeMaintenanceRepo) CreateRun(_ context.Context, run *MaintenanceRun) error {
+	run.ID = uuid.New()
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *fakeMaintenanceRepo) FinishRun(_ context.Context, run *MaintenanceRun) error {
+	r.finished = append(r.finished, run)
+	return nil
+}
+
+func (r *fakeMaintenanceRepo) ListRuns(context.Context, MaintenanceTask, int, int) ([]*MaintenanceRun, int, error) {
+	return r.finished, len(r.finished), nil
+}
+
+func (r *fakeMaintenanceRepo) VacuumAnalyze(_ context.Context, table string) error {
+	if r.vacuumErr != nil {
+		return r.vacuumErr
+	}
+	r.vacuumed = append(r.vacuumed, table)
+	return nil
+}
+
+func (r *fakeMaintenanceRepo) CompactBudgetHistory(_ context.Context, before time.Time) (int64, error) {
+	r.compactCut = before
+	return r.compacted, nil
+}
+
+func (r *fakeMaintenanceRepo) PruneSheetSyncLog(context.Context, time.Time) (int64, error) {
+	return 2, nil
+}
+
+func (r *fakeMaintenanceRepo) PruneMaintenanceRuns(context.Context, time.Time) (int64, error) {
+	return 1, nil
+}
+
+func (r *fakeMaintenanceRepo) PruneIdempotencyKeys(context.Context, time.Time) (int64, error) {
+	return 4, nil
+}
+
+func (r *fakeMaintenanceRepo) PruneUploadSessions(context.Context, time.Time) (int64, error) {
+	return 2, nil
+}
+
+func (r *fakeMaintenanceRepo) ListUserFileIDs(_ context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	return r.userFiles[userID], nil
+}
+
+type fakeStorage struct {
+	storage.Storage
+	files   map[uuid.UUID][]*storage.FileInfo
+	deleted []uuid.UUID
+}
+
+func (s *fakeStorage) ListOwners(context.Context) ([]uuid.UUID, error) {
+	owners := make([]uuid.UUID, 0, len(s.files))
+	for id := range s.files {
+		owners = append(owners, id)
+	}
+	return owners, nil
+}
+
+func (s *fakeStorage) List(_ context.Context, userID uuid.UUID) ([]*storage.FileInfo, error) {
+	return s.files[userID], nil
+}
+
+func (s *fakeStorage) Delete(_ context.Context, _ uuid.UUID, fileID uuid.UUID) error {
+	s.deleted = append(s.deleted, fileID)
+	return nil
+}
+
+func newTestMaintenanceService(repo MaintenanceRepo, fileStorage storage.Storage, now time.Time) *MaintenanceService {
+	svc := NewMaintenanceService(repo, fileStorage, slog.New(slog.DiscardHandler))
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestCleanOrphanedFiles_DeletesOnlyUntrackedOldFiles(t *testing.T) {
+	now := time.Date(2026, 3, 10, 5, 30, 0, 0, time.UTC)
+	activeUser, deletedUser := uuid.New(), uuid.New()
+	tracked, orphaned, fresh, abandoned := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	fs := &fakeStorage{files: map[uuid.UUID][]*storage.FileInfo{
+		activeUser: {
+			{ID: tracked, CreatedAt: now.AddDate(0, -1, 0)},
+			{ID: orphaned, CreatedAt: now.AddDate(0, 0, -3)},
+			{ID: fresh, CreatedAt: now.Add(-time.Hour)}, // Upload still in flight
+		},
+		deletedUser: {{ID: abandoned, CreatedAt: now.AddDate(0, -2, 0)}},
+	}}
+	repo := &fakeMaintenanceRepo{userFiles: map[uuid.UUID]map[uuid.UUID]bool{
+		activeUser: {tracked: true},
+	}}
+
+	run, err := newTestMaintenanceService(repo, fs, now).RunTask(context.Background(), MaintenanceTaskOrphanedFiles, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []uuid.UUID{orphaned, abandoned}, fs.deleted)
+	assert.Equal(t, MaintenanceRunSucceeded, run.Status)
+	assert.Equal(t, int64(2), run.RowsAffected)
+	require.Len(t, repo.finished, 1)
+	assert.NotNil(t, repo.finished[0].FinishedAt)
+}
+
+func TestRunAll_RecordsFailuresAndContinues(t *testing.T) {
+	now := time.Date(2026, 3, 10, 5, 30, 0, 0, time.UTC)
+	repo := &fakeMaintenanceRepo{vacuumErr: errors.New("lock timeout"), compacted: 12}
+
+	runs, err := newTestMaintenanceService(repo, nil, now).RunAll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vacuum_analyze")
+
+	require.Len(t, runs, len(MaintenanceTasks))
+	byTask := make(map[MaintenanceTask]*MaintenanceRun)
+	for _, run := range runs {
+		byTask[run.Task] = run
+	}
+
+	compaction := byTask[MaintenanceTaskRollupCompaction]
+	assert.Equal(t, MaintenanceRunSucceeded, compaction.Status)
+	assert.Equal(t, int64(21), compaction.RowsAffected)
+	assert.Equal(t, int64(4), compaction.Details["idempotency_key_rows"])
+	assert.Equal(t, int64(2), compaction.Details["upload_session_rows"])
+	assert.Equal(t, now.Add(-compactionAge), repo.compactCut)
+
+	assert.Equal(t, "no file storage configured", byTask[MaintenanceTaskOrphanedFiles].Details["skipped"])
+
+	vacuum := byTask[MaintenanceTaskVacuumAnalyze]
+	assert.Equal(t, MaintenanceRunFailed, vacuum.Status)
+	require.NotNil(t, vacuum.Error)
+	assert.Contains(t, *vacuum.Error, "lock timeout")
+	assert.Len(t, repo.finished, len(MaintenanceTasks))
+}
+
+func TestRunTask_UnknownTask(t *testing.T) {
+	repo := &fakeMaintenanceRepo{}
+	_, err := newTestMaintenanceService(repo, nil, time.Now()).RunTask(context.Background(), "reindex", nil)
+	assert.ErrorIs(t, err, ErrUnknownMaintenanceTask)
+	assert.Empty(t, repo.runs)
+}
+
+type fakeEncryptedStorage struct {
+	fakeStorage
+	rotations map[uuid.UUID]storage.Rotation
+	failing   uuid.UUID
+}
+
+func (s *fakeEncryptedStorage) RotateFileKey(_ context.Context, _ uuid.UUID, fileID uuid.UUID) (storage.Rotation, error) {
+	if fileID == s.failing {
+		return storage.RotationNone, storage.ErrCorruptFile
+	}
+	return s.rotations[fileID], nil
+}
+
+func TestRotateStorageKeys(t *testing.T) {
+	now := time.Date(2026, 3, 10, 5, 30, 0, 0, time.UTC)
+	userID := uuid.New()
+	current, retired, legacy, corrupt := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	fs := &fakeEncryptedStorage{
+		fakeStorage: fakeStorage{files: map[uuid.UUID][]*storage.FileInfo{
+			userID: {{ID: current}, {ID: retired}, {ID: legacy}},
+		}},
+		rotations: map[uuid.UUID]storage.Rotation{
+			current: storage.RotationNone,
+			retired: storage.RotationRewrapped,
+			legacy:  storage.RotationEncrypted,
+		},
+	}
+	repo := &fakeMaintenanceRepo{}
+
+	run, err := newTestMaintenanceService(repo, fs, now).RunTask(context.Background(), MaintenanceTaskKeyRotation, nil)
+	require.NoError(t, err)
+	assert.Equal(t, MaintenanceRunSucceeded, run.Status)
+	assert.Equal(t, int64(2), run.RowsAffected)
+	assert.Equal(t, int64(1), run.Details["files_rewrapped"])
+	assert.Equal(t, int64(1), run.Details["files_encrypted"])
+
+	// A file that can't be rotated fails the run, so its key isn't retired
+	fs.files[userID] = append(fs.files[userID], &storage.FileInfo{ID: corrupt})
+	fs.failing = corrupt
+	run, err = newTestMaintenanceService(repo, fs, now).RunTask(context.Background(), MaintenanceTaskKeyRotation, nil)
+	require.Error(t, err)
+	assert.Equal(t, MaintenanceRunFailed, run.Status)
+	assert.Equal(t, int64(1), run.Details["files_failed"])
+
+	// Unencrypted storage has nothing to rotate
+	run, err = newTestMaintenanceService(repo, &fakeStorage{}, now).RunTask(context.Background(), MaintenanceTaskKeyRotation, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file storage isn't encrypted", run.Details["skipped"])
+}
@@ -0,0 +1,295 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/audit"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// SupportRole is the role of support staff. They may use SupportService like
+// admins, but can't change users or settings.
+const SupportRole = "support"
+
+// ImpersonationTTL is how long an impersonation token stays valid
+const ImpersonationTTL = 15 * time.Minute
+
+// Audited support actions, recorded alongside the audited RPCs
+const (
+	SupportActionRetryImport = "admin.Support/RetryImportJob"
+	SupportActionImpersonate = "admin.Support/Impersonate"
+)
+
+const (
+	defaultSupportListLimit = 50
+	maxSupportListLimit     = 200
+)
+
+var (
+	// ErrSupportAccessRequired is returned when a caller without the admin or
+	// support role uses SupportService
+	ErrSupportAccessRequired = errors.New("support operations require the admin or support role")
+	// ErrSupportUserNotFound is returned when looking up a user that doesn't exist
+	ErrSupportUserNotFound = errors.New("user not found")
+	// ErrImpersonationReason is returned when impersonating without saying why
+	ErrImpersonationReason = errors.New("a reason is required to impersonate a user")
+	// ErrImpersonateStaff is returned when impersonating an admin or support account
+	ErrImpersonateStaff = errors.New("admin and support accounts can't be impersonated")
+)
+
+// SupportUser is a user's account as support staff see it
+type SupportUser struct {
+	ID              uuid.UUID
+	Email           string
+	Username        *string
+	DisplayName     *string
+	Role            string
+	IsActive        bool
+	EmailVerifiedAt *time.Time
+	LastLoginAt     *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// SupportUserFilter narrows a user listing. Zero values don't filter.
+type SupportUserFilter struct {
+	Query  string // Matches the ID exactly, or part of the email or username
+	Role   string
+	Active *bool
+	Limit  int // Defaults to 50, at most 200
+	Offset int
+}
+
+// SupportRepo defines database access for support lookups
+type SupportRepo interface {
+	// GetUser returns a user, or sql.ErrNoRows
+	GetUser(ctx context.Context, userID uuid.UUID) (*SupportUser, error)
+	// ListUsers returns the users matching the filter, newest first, and how many match
+	ListUsers(ctx context.Context, filter SupportUserFilter) ([]*SupportUser, int64, error)
+}
+
+// SupportImports is the part of the import service support staff use
+type SupportImports interface {
+	ListImportJobs(ctx context.Context, userID uuid.UUID, filter importrepo.ListImportJobsFilter) ([]*importrepo.ImportJob, int64, error)
+	RetryImportJob(ctx context.Context, jobID uuid.UUID) (*importservice.ImportResult, error)
+}
+
+// Impersonation is a short-lived, read-only token to call the API as a user
+type Impersonation struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// SupportService lets admins and support staff debug a user's account: look
+// the user up, see their imports and why they failed, re-run failed imports
+// and see the app as the user does through a read-only token. Every call
+// checks the caller's role, and retries and impersonations are audited.
+type SupportService struct {
+	repo    SupportRepo
+	imports SupportImports
+	secret  []byte
+	audit   audit.Store
+	logger  *slog.Logger
+	now     func() time.Time
+}
+
+// NewSupportService creates a new support service. Impersonation tokens are
+// signed with secret, which must be the access token secret.
+func NewSupportService(repo SupportRepo, imports SupportImports, secret []byte, logger *slog.Logger) *SupportService {
+	return &SupportService{repo: repo, imports: imports, secret: secret, logger: logger, now: time.Now}
+}
+
+// WithAuditLog records retries and impersonations in the audit log
+func (s *SupportService) WithAuditLog(store audit.Store) *SupportService {
+	s.audit = store
+	return s
+}
+
+// GetUser looks a user up by ID
+func (s *SupportService) GetUser(ctx context.Context, userID uuid.UUID) (*SupportUser, error) {
+	if _, err := requireSupport(ctx); err != nil {
+		return nil, err
+	}
+	user, err := s.repo.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSupportUserNotFound
+	}
+	return user, err
+}
+
+// ListUsers finds users by ID, email or username
+func (s *SupportService) ListUsers(ctx context.Context, filter SupportUserFilter) ([]*SupportUser, int64, error) {
+	if _, err := requireSupport(ctx); err != nil {
+		return nil, 0, err
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSupportListLimit
+	}
+	filter.Limit = min(filter.Limit, maxSupportListLimit)
+	filter.Offset = max(filter.Offset, 0)
+	return s.repo.ListUsers(ctx, filter)
+}
+
+// ListImportJobs returns a user's imports, newest first, with the error each
+// failed one stopped on
+func (s *SupportService) ListImportJobs(ctx context.Context, userID uuid.UUID, filter importrepo.ListImportJobsFilter) ([]*importrepo.ImportJob, int64, error) {
+	if _, err := requireSupport(ctx); err != nil {
+		return nil, 0, err
+	}
+	return s.imports.ListImportJobs(ctx, userID, filter)
+}
+
+// RetryImportJob re-runs a failed import from its stored file as a new job
+func (s *SupportService) RetryImportJob(ctx context.Context, jobID uuid.UUID) (*importservice.ImportResult, error) {
+	staffID, err := requireSupport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.imports.RetryImportJob(ctx, jobID)
+	details := map[string]any{}
+	if result != nil {
+		details["new_job_id"] = result.JobID.String()
+		details["rows_imported"] = result.RowsImported
+		details["rows_failed"] = result.RowsFailed
+	}
+	s.record(ctx, staffID, SupportActionRetryImport, []string{jobID.String()}, details, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry import job: %w", err)
+	}
+	return result, nil
+}
+
+// Impersonate issues a token that lets the caller read the user's account as
+// the user sees it for ImpersonationTTL. The token is read-only: the auth
+// interceptor only lets it call Get and List procedures, and never the admin
+// service. It isn't tied to a session, so it can't be refreshed or revoked;
+// it simply expires.
+func (s *SupportService) Impersonate(ctx context.Context, userID uuid.UUID, reason string) (*Impersonation, error) {
+	staffID, err := requireSupport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrImpersonationReason
+	}
+	user, err := s.repo.GetUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSupportUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == "admin" || user.Role == SupportRole {
+		return nil, ErrImpersonateStaff
+	}
+
+	now := s.now()
+	expiresAt := now.Add(ImpersonationTTL)
+	claims := &interceptors.Claims{
+		UserID:         user.ID.String(),
+		Email:          user.Email,
+		Role:           user.Role,
+		Scope:          interceptors.ScopeImpersonation,
+		ImpersonatedBy: staffID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	if user.Username != nil {
+		claims.Username = *user.Username
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign impersonation token: %w", err)
+	}
+
+	s.record(ctx, staffID, SupportActionImpersonate, []string{userID.String()}, map[string]any{
+		"reason":     reason,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	}, nil)
+	s.logger.Warn("support impersonation started",
+		slog.String("staff_id", staffID.String()),
+		slog.String("user_id", userID.String()),
+		slog.String("reason", reason),
+	)
+	return &Impersonation{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// record writes a support action to the audit log, if one is configured.
+// Failing to record is logged rather than failing the action.
+func (s *SupportService) record(ctx context.Context, staffID uuid.UUID, action string, entityIDs []string, details map[string]any, actionErr error) {
+	if s.audit == nil {
+		return
+	}
+	event := &audit.Event{
+		ID:         uuid.New(),
+		OccurredAt: s.now(),
+		ActorID:    &staffID,
+		Procedure:  action,
+		EntityIDs:  entityIDs,
+		After:      details,
+		Outcome:    audit.OutcomeOK,
+	}
+	if actionErr != nil {
+		msg := actionErr.Error()
+		event.Outcome = connect.CodeOf(actionErr).String()
+		event.Error = &msg
+	}
+	if requestID, ok := interceptors.RequestIDFromContext(ctx); ok {
+		event.RequestID = requestID
+	}
+	if err := s.audit.Record(ctx, event); err != nil {
+		s.logger.Error("failed to record support action",
+			slog.String("action", action),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// requireSupport returns the calling staff member's ID if they're an admin or
+// support, signed in as themselves
+func requireSupport(ctx context.Context) (uuid.UUID, error) {
+	claims, err := interceptors.GetClaimsFromContext(ctx)
+	if err != nil || claims.Scope == interceptors.ScopeImpersonation ||
+		(claims.Role != "admin" && claims.Role != SupportRole) {
+		return uuid.Nil, ErrSupportAccessRequired
+	}
+	id, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, ErrSupportAccessRequired
+	}
+	return id, nil
+}
+
+// ============================================================================
+// Support Operations (Internal Integration)
+// ============================================================================
+// GetUser and ListUsers are served on AdminUserService, which the auth
+// interceptor limits to admins and support staff. The rest of SupportService
+// requires proto definitions on the admin service:
+//
+// - ListImportJobs: a user's imports with their row counts and errors
+// - RetryImportJob: re-run a failed import from its stored file
+// - Impersonate: a 15 minute read-only token to see the app as the user
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AdminListUserImportJobsRequest/Response (AdminUserService.ListUserImportJobs)
+// - AdminRetryImportJobRequest/Response (AdminUserService.RetryImportJob)
+// - AdminImpersonateUserRequest/Response (AdminUserService.ImpersonateUser) with
+//   user_id and reason, returning the access token and its expiry
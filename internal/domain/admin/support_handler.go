@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ echov1connect.AdminUserServiceHandler = (*SupportHandler)(nil)
+
+// SupportHandler serves user lookups on AdminUserService. Changing users
+// isn't supported yet and returns Unimplemented.
+type SupportHandler struct {
+	echov1connect.UnimplementedAdminUserServiceHandler
+	svc    *SupportService
+	logger *slog.Logger
+}
+
+// NewSupportHandler creates a new admin user service handler
+func NewSupportHandler(svc *SupportService, logger *slog.Logger) *SupportHandler {
+	return &SupportHandler{svc: svc, logger: logger}
+}
+
+// GetUser returns one user with their last login
+func (h *SupportHandler) GetUser(ctx context.Context, req *connect.Request[echov1.AdminGetUserRequest]) (*connect.Response[echov1.AdminGetUserResponse], error) {
+	userID, err := uuid.Parse(req.Msg.GetUserId())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid user_id"))
+	}
+	user, err := h.svc.GetUser(ctx, userID)
+	if err != nil {
+		return nil, h.toConnectError(err, "failed to get user")
+	}
+	return connect.NewResponse(&echov1.AdminGetUserResponse{User: supportUserToProto(user)}), nil
+}
+
+// ListUsers finds users by ID, email or username, newest first
+func (h *SupportHandler) ListUsers(ctx context.Context, req *connect.Request[echov1.AdminListUsersRequest]) (*connect.Response[echov1.AdminListUsersResponse], error) {
+	filter := SupportUserFilter{Query: req.Msg.GetQuery()}
+	switch req.Msg.GetRole() {
+	case echov1.UserRole_USER_ROLE_ADMIN:
+		filter.Role = "admin"
+	case echov1.UserRole_USER_ROLE_USER:
+		filter.Role = "user"
+	}
+	switch req.Msg.GetStatus() {
+	case echov1.UserStatus_USER_STATUS_ACTIVE:
+		active := true
+		filter.Active = &active
+	case echov1.UserStatus_USER_STATUS_DISABLED:
+		active := false
+		filter.Active = &active
+	}
+	if page := req.Msg.GetPage(); page != nil {
+		filter.Limit = int(page.PageSize)
+		// Page tokens are offsets
+		if offset, err := strconv.Atoi(page.PageToken); err == nil && offset > 0 {
+			filter.Offset = offset
+		}
+	}
+
+	users, total, err := h.svc.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, h.toConnectError(err, "failed to list users")
+	}
+
+	protoUsers := make([]*echov1.AdminUser, 0, len(users))
+	for _, u := range users {
+		protoUsers = append(protoUsers, supportUserToProto(u))
+	}
+	var nextPageToken string
+	if int64(filter.Offset+len(users)) < total {
+		nextPageToken = strconv.Itoa(filter.Offset + len(users))
+	}
+	return connect.NewResponse(&echov1.AdminListUsersResponse{
+		Users: protoUsers,
+		Page:  &echov1.PageResponse{NextPageToken: nextPageToken},
+	}), nil
+}
+
+func (h *SupportHandler) toConnectError(err error, msg string) error {
+	switch {
+	case errors.Is(err, ErrSupportAccessRequired):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, ErrSupportUserNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	default:
+		h.logger.Error(msg, slog.Any("error", err))
+		return connect.NewError(connect.CodeInternal, errors.New(msg))
+	}
+}
+
+// supportUserToProto converts a SupportUser to proto
+func supportUserToProto(u *SupportUser) *echov1.AdminUser {
+	user := &echov1.User{
+		Id:        u.ID.String(),
+		Email:     u.Email,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+		Status:    echov1.UserStatus_USER_STATUS_ACTIVE,
+		Roles:     []echov1.UserRole{echov1.UserRole_USER_ROLE_USER},
+	}
+	if u.Username != nil {
+		user.Username = *u.Username
+	}
+	if u.DisplayName != nil {
+		user.DisplayName = *u.DisplayName
+	}
+	if !u.IsActive {
+		user.Status = echov1.UserStatus_USER_STATUS_DISABLED
+	}
+	if u.Role == "admin" {
+		user.Roles = []echov1.UserRole{echov1.UserRole_USER_ROLE_ADMIN}
+	}
+
+	result := &echov1.AdminUser{User: user}
+	if u.LastLoginAt != nil {
+		result.LastLoginAt = timestamppb.New(*u.LastLoginAt)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresSupportRepo implements SupportRepo using PostgreSQL
+type PostgresSupportRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSupportRepo creates a new PostgreSQL support repository
+func NewPostgresSupportRepo(pool *pgxpool.Pool) *PostgresSupportRepo {
+	return &PostgresSupportRepo{pool: pool}
+}
+
+const supportUserColumns = `id, email, username, display_name, role, is_active, email_verified_at,
+	last_login_at, created_at, updated_at`
+
+func scanSupportUser(row pgx.Row) (*SupportUser, error) {
+	u := &SupportUser{}
+	err := row.Scan(&u.ID, &u.Email, &u.Username, &u.DisplayName, &u.Role, &u.IsActive, &u.EmailVerifiedAt,
+		&u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt)
+	return u, err
+}
+
+// GetUser returns a user by ID
+func (r *PostgresSupportRepo) GetUser(ctx context.Context, userID uuid.UUID) (*SupportUser, error) {
+	u, err := scanSupportUser(r.pool.QueryRow(ctx, `SELECT `+supportUserColumns+` FROM users WHERE id = $1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// ListUsers lists the users matching the filter, newest first, with the total count
+func (r *PostgresSupportRepo) ListUsers(ctx context.Context, filter SupportUserFilter) ([]*SupportUser, int64, error) {
+	// Wildcards typed in the query match themselves
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Query) + "%"
+	where := `
+		WHERE ($1 = '' OR id::text = $1 OR email ILIKE $2 OR username ILIKE $2)
+		  AND ($3 = '' OR role = $3)
+		  AND ($4::boolean IS NULL OR is_active = $4)`
+	args := []any{filter.Query, pattern, filter.Role, filter.Active}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+supportUserColumns+` FROM users`+where+`
+		ORDER BY created_at DESC, id
+		LIMIT $5 OFFSET $6`, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*SupportUser
+	for rows.Next() {
+		u, err := scanSupportUser(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/audit"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+type fakeSupportRepo struct {
+	users map[uuid.UUID]*SupportUser
+}
+
+func (r *fakeSupportRepo) GetUser(_ context.Context, userID uuid.UUID) (*SupportUser, error) {
+	if u, ok := r.users[userID]; ok {
+		return u, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeSupportRepo) ListUsers(context.Context, SupportUserFilter) ([]*SupportUser, int64, error) {
+	return nil, 0, nil
+}
+
+type fakeSupportImports struct {
+	retryErr error
+}
+
+func (f *fakeSupportImports) ListImportJobs(context.Context, uuid.UUID, importrepo.ListImportJobsFilter) ([]*importrepo.ImportJob, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeSupportImports) RetryImportJob(context.Context, uuid.UUID) (*importservice.ImportResult, error) {
+	if f.retryErr != nil {
+		return nil, f.retryErr
+	}
+	return &importservice.ImportResult{JobID: uuid.New(), RowsImported: 3}, nil
+}
+
+type fakeAuditStore struct {
+	events []*audit.Event
+}
+
+func (s *fakeAuditStore) Record(_ context.Context, e *audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *fakeAuditStore) List(context.Context, audit.ListFilter) ([]*audit.Event, error) {
+	return s.events, nil
+}
+
+var supportSecret = []byte("secret")
+
+func staffContext(role string) (context.Context, uuid.UUID) {
+	id := uuid.New()
+	return interceptors.ContextWithClaims(context.Background(), &interceptors.Claims{UserID: id.String(), Role: role}), id
+}
+
+func TestImpersonate_IssuesReadOnlyToken(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	user := &SupportUser{ID: uuid.New(), Email: "ana@example.com", Role: "user", IsActive: true}
+	auditLog := &fakeAuditStore{}
+	svc := NewSupportService(&fakeSupportRepo{users: map[uuid.UUID]*SupportUser{user.ID: user}},
+		&fakeSupportImports{}, supportSecret, slog.New(slog.DiscardHandler)).WithAuditLog(auditLog)
+	svc.now = func() time.Time { return now }
+	ctx, staffID := staffContext(SupportRole)
+
+	imp, err := svc.Impersonate(ctx, user.ID, "  import stuck at 0 rows ")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(ImpersonationTTL), imp.ExpiresAt)
+
+	claims := &interceptors.Claims{}
+	_, err = jwt.ParseWithClaims(imp.Token, claims, func(*jwt.Token) (any, error) { return supportSecret, nil },
+		jwt.WithTimeFunc(func() time.Time { return now }))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims.UserID)
+	assert.Equal(t, interceptors.ScopeImpersonation, claims.Scope)
+	assert.Equal(t, staffID.String(), claims.ImpersonatedBy)
+	assert.Empty(t, claims.ID, "not tied to a session")
+
+	require.Len(t, auditLog.events, 1)
+	assert.Equal(t, SupportActionImpersonate, auditLog.events[0].Procedure)
+	assert.Equal(t, &staffID, auditLog.events[0].ActorID)
+	assert.Equal(t, []string{user.ID.String()}, auditLog.events[0].EntityIDs)
+	assert.Equal(t, "import stuck at 0 rows", auditLog.events[0].After["reason"])
+}
+
+func TestImpersonate_Refusals(t *testing.T) {
+	user := &SupportUser{ID: uuid.New(), Role: "user"}
+	admin := &SupportUser{ID: uuid.New(), Role: "admin"}
+	svc := NewSupportService(&fakeSupportRepo{users: map[uuid.UUID]*SupportUser{user.ID: user, admin.ID: admin}},
+		&fakeSupportImports{}, supportSecret, slog.New(slog.DiscardHandler))
+	staffCtx, _ := staffContext("admin")
+	userCtx, _ := staffContext("user")
+	impersonatingCtx := interceptors.ContextWithClaims(context.Background(), &interceptors.Claims{
+		UserID: uuid.NewString(), Role: "admin", Scope: interceptors.ScopeImpersonation,
+	})
+
+	_, err := svc.Impersonate(userCtx, user.ID, "debugging")
+	assert.ErrorIs(t, err, ErrSupportAccessRequired)
+	_, err = svc.Impersonate(impersonatingCtx, user.ID, "debugging")
+	assert.ErrorIs(t, err, ErrSupportAccessRequired, "impersonation tokens can't impersonate further")
+	_, err = svc.Impersonate(staffCtx, user.ID, " ")
+	assert.ErrorIs(t, err, ErrImpersonationReason)
+	_, err = svc.Impersonate(staffCtx, admin.ID, "debugging")
+	assert.ErrorIs(t, err, ErrImpersonateStaff)
+	_, err = svc.Impersonate(staffCtx, uuid.New(), "debugging")
+	assert.ErrorIs(t, err, ErrSupportUserNotFound)
+}
+
+func TestRetryImportJob_AuditsOutcome(t *testing.T) {
+	auditLog := &fakeAuditStore{}
+	imports := &fakeSupportImports{}
+	svc := NewSupportService(&fakeSupportRepo{}, imports, supportSecret, slog.New(slog.DiscardHandler)).WithAuditLog(auditLog)
+	ctx, _ := staffContext(SupportRole)
+	jobID := uuid.New()
+
+	result, err := svc.RetryImportJob(ctx, jobID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.RowsImported)
+
+	imports.retryErr = importservice.ErrImportFileUnavailable
+	_, err = svc.RetryImportJob(ctx, jobID)
+	assert.True(t, errors.Is(err, importservice.ErrImportFileUnavailable))
+
+	require.Len(t, auditLog.events, 2)
+	assert.Equal(t, audit.OutcomeOK, auditLog.events[0].Outcome)
+	assert.Equal(t, 3, auditLog.events[0].After["rows_imported"])
+	assert.NotEqual(t, audit.OutcomeOK, auditLog.events[1].Outcome)
+	require.NotNil(t, auditLog.events[1].Error)
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresAdvisorRepository implements AdvisorRepository using PostgreSQL
+type PostgresAdvisorRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAdvisorRepository creates a new PostgreSQL advisor repository
+func NewPostgresAdvisorRepository(pool *pgxpool.Pool) *PostgresAdvisorRepository {
+	return &PostgresAdvisorRepository{pool: pool}
+}
+
+// CreateGrant creates an invite, replacing any open invite for the same email
+func (r *PostgresAdvisorRepository) CreateGrant(ctx context.Context, grant *Grant) error {
+	if grant.ID == uuid.Nil {
+		grant.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM advisor_grants
+		WHERE client_id = $1 AND email = $2 AND accepted_at IS NULL`, grant.ClientID, grant.Email); err != nil {
+		return fmt.Errorf("failed to replace advisor invite: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO advisor_grants (id, client_id, email, token, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at`,
+		grant.ID, grant.ClientID, grant.Email, grant.Token, grant.ExpiresAt,
+	).Scan(&grant.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyAdvisor
+		}
+		return fmt.Errorf("failed to create advisor invite: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit advisor invite: %w", err)
+	}
+	return nil
+}
+
+const grantColumns = `g.id, g.client_id, g.advisor_id, g.email, g.token, g.expires_at, g.accepted_at, g.revoked_at, g.created_at, u.display_name`
+
+const grantFrom = `advisor_grants g LEFT JOIN users u ON u.id = g.advisor_id`
+
+func scanGrant(row pgx.Row) (*Grant, error) {
+	g := &Grant{}
+	err := row.Scan(&g.ID, &g.ClientID, &g.AdvisorID, &g.Email, &g.Token, &g.ExpiresAt, &g.AcceptedAt, &g.RevokedAt, &g.CreatedAt, &g.AdvisorName)
+	return g, err
+}
+
+// GetGrantByToken retrieves a grant by its invite token
+func (r *PostgresAdvisorRepository) GetGrantByToken(ctx context.Context, token string) (*Grant, error) {
+	grant, err := scanGrant(r.pool.QueryRow(ctx, `SELECT `+grantColumns+` FROM `+grantFrom+` WHERE g.token = $1`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get advisor grant: %w", err)
+	}
+	return grant, nil
+}
+
+// AcceptGrant activates an open invite for the advisor
+func (r *PostgresAdvisorRepository) AcceptGrant(ctx context.Context, grantID, advisorID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE advisor_grants SET advisor_id = $2, accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`, grantID, advisorID)
+	if err != nil {
+		return fmt.Errorf("failed to accept advisor invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetActiveGrant returns the advisor's active grant over the client
+func (r *PostgresAdvisorRepository) GetActiveGrant(ctx context.Context, clientID, advisorID uuid.UUID) (*Grant, error) {
+	grant, err := scanGrant(r.pool.QueryRow(ctx, `
+		SELECT `+grantColumns+`
+		FROM `+grantFrom+`
+		WHERE g.client_id = $1 AND g.advisor_id = $2
+		  AND g.accepted_at IS NOT NULL AND g.revoked_at IS NULL`, clientID, advisorID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get advisor grant: %w", err)
+	}
+	return grant, nil
+}
+
+// ListGrants lists a client's open invites, including expired ones, and active grants
+func (r *PostgresAdvisorRepository) ListGrants(ctx context.Context, clientID uuid.UUID) ([]*Grant, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+grantColumns+`
+		FROM `+grantFrom+`
+		WHERE g.client_id = $1 AND g.revoked_at IS NULL
+		ORDER BY g.created_at DESC`, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list advisor grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*Grant
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan advisor grant: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, rows.Err()
+}
+
+// RevokeGrant ends an invite or active grant of the client's
+func (r *PostgresAdvisorRepository) RevokeGrant(ctx context.Context, clientID, grantID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE advisor_grants SET revoked_at = NOW()
+		WHERE id = $1 AND client_id = $2 AND revoked_at IS NULL`, grantID, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke advisor grant: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListClients lists the users who gave the advisor access, most recent first
+func (r *PostgresAdvisorRepository) ListClients(ctx context.Context, advisorID uuid.UUID) ([]*Client, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT g.id, g.client_id, u.email, u.display_name, g.accepted_at
+		FROM advisor_grants g
+		JOIN users u ON u.id = g.client_id
+		WHERE g.advisor_id = $1 AND g.accepted_at IS NOT NULL AND g.revoked_at IS NULL
+		ORDER BY g.accepted_at DESC`, advisorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list advisor clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []*Client
+	for rows.Next() {
+		c := &Client{}
+		if err := rows.Scan(&c.GrantID, &c.UserID, &c.Email, &c.DisplayName, &c.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan advisor client: %w", err)
+		}
+		clients = append(clients, c)
+	}
+	return clients, rows.Err()
+}
+
+// GetUserEmail returns the email of a user's account
+func (r *PostgresAdvisorRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email string
+	err := r.pool.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	return email, nil
+}
+
+// CreateAnnotation stores an advisor's annotation
+func (r *PostgresAdvisorRepository) CreateAnnotation(ctx context.Context, a *Annotation) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO advisor_annotations (id, grant_id, client_id, advisor_id, kind, subject, plan_id, month_start, body)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at`,
+		a.ID, a.GrantID, a.ClientID, a.AdvisorID, a.Kind, a.Subject, a.PlanID, a.MonthStart, a.Body,
+	).Scan(&a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create advisor annotation: %w", err)
+	}
+	return nil
+}
+
+// ListAnnotations lists a client's annotations, newest first
+func (r *PostgresAdvisorRepository) ListAnnotations(ctx context.Context, clientID uuid.UUID, advisorID *uuid.UUID, limit int) ([]*Annotation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT a.id, a.grant_id, a.client_id, a.advisor_id, a.kind, a.subject, a.plan_id, a.month_start, a.body, a.created_at, u.display_name
+		FROM advisor_annotations a
+		JOIN users u ON u.id = a.advisor_id
+		WHERE a.client_id = $1 AND ($2::uuid IS NULL OR a.advisor_id = $2)
+		ORDER BY a.created_at DESC
+		LIMIT $3`, clientID, advisorID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list advisor annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []*Annotation
+	for rows.Next() {
+		a := &Annotation{}
+		if err := rows.Scan(&a.ID, &a.GrantID, &a.ClientID, &a.AdvisorID, &a.Kind, &a.Subject, &a.PlanID, &a.MonthStart, &a.Body, &a.CreatedAt, &a.AdvisorName); err != nil {
+			return nil, fmt.Errorf("failed to scan advisor annotation: %w", err)
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,102 @@
+// Package repository provides database operations for advisor access grants and annotations.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAlreadyAdvisor is returned when inviting an advisor who already has access
+var ErrAlreadyAdvisor = errors.New("advisor already has access")
+
+// AnnotationKind distinguishes plain notes from actionable recommendations
+type AnnotationKind string
+
+const (
+	KindNote           AnnotationKind = "note"
+	KindRecommendation AnnotationKind = "recommendation"
+)
+
+// Subject is the part of the workspace an annotation is about
+type Subject string
+
+const (
+	SubjectGeneral Subject = "general"
+	SubjectReport  Subject = "report" // A monthly report; MonthStart is set
+	SubjectTrend   Subject = "trend"
+	SubjectPlan    Subject = "plan" // A plan; PlanID is set
+)
+
+// Grant gives an advisor read-only access to a client's data. It starts as an
+// invite to an email and becomes active once the advisor accepts it.
+type Grant struct {
+	ID         uuid.UUID
+	ClientID   uuid.UUID
+	AdvisorID  *uuid.UUID // Set once accepted
+	Email      string
+	Token      string
+	ExpiresAt  time.Time // Deadline to accept the invite
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	AdvisorName *string // From the advisor's account, once accepted
+}
+
+// Active reports whether the advisor accepted the grant and it wasn't revoked
+func (g *Grant) Active() bool {
+	return g.AdvisorID != nil && g.AcceptedAt != nil && g.RevokedAt == nil
+}
+
+// Client is a user an advisor has access to
+type Client struct {
+	GrantID     uuid.UUID
+	UserID      uuid.UUID
+	Email       string
+	DisplayName *string
+	Since       time.Time // When the advisor accepted
+}
+
+// Annotation is a note or recommendation an advisor leaves for a client
+type Annotation struct {
+	ID         uuid.UUID
+	GrantID    uuid.UUID
+	ClientID   uuid.UUID
+	AdvisorID  uuid.UUID
+	Kind       AnnotationKind
+	Subject    Subject
+	PlanID     *uuid.UUID
+	MonthStart *time.Time
+	Body       string
+	CreatedAt  time.Time
+
+	AdvisorName *string // From the advisor's account
+}
+
+// AdvisorRepository defines the interface for advisor persistence
+type AdvisorRepository interface {
+	// Grants
+	// CreateGrant creates an invite, replacing any open invite for the same
+	// email. Returns ErrAlreadyAdvisor if that email already has access.
+	CreateGrant(ctx context.Context, grant *Grant) error
+	GetGrantByToken(ctx context.Context, token string) (*Grant, error)
+	// AcceptGrant activates an open invite for the advisor.
+	// Returns sql.ErrNoRows if it was already accepted or revoked.
+	AcceptGrant(ctx context.Context, grantID, advisorID uuid.UUID) error
+	// GetActiveGrant returns the advisor's active grant over the client
+	GetActiveGrant(ctx context.Context, clientID, advisorID uuid.UUID) (*Grant, error)
+	// ListGrants lists a client's open invites and active grants
+	ListGrants(ctx context.Context, clientID uuid.UUID) ([]*Grant, error)
+	RevokeGrant(ctx context.Context, clientID, grantID uuid.UUID) error
+	ListClients(ctx context.Context, advisorID uuid.UUID) ([]*Client, error)
+	GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error)
+
+	// Annotations
+	CreateAnnotation(ctx context.Context, annotation *Annotation) error
+	// ListAnnotations lists a client's annotations, newest first, optionally
+	// only those left by one advisor
+	ListAnnotations(ctx context.Context, clientID uuid.UUID, advisorID *uuid.UUID, limit int) ([]*Annotation, error)
+}
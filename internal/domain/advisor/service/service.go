@@ -0,0 +1,465 @@
+// Package service provides business logic for advisors: a client invites a
+// financial advisor, who gets a read-only workspace over the client's reports,
+// trends and plans and can leave annotations the client sees as alerts.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/repository"
+)
+
+// =============================================================================
+// Advisor Workspace (Internal Integration)
+// =============================================================================
+// Advisors are regular users. Every workspace read goes through an active grant
+// and nothing in the workspace can change the client's data; annotations are
+// the advisor's only output. Inviting, accepting, the workspace and annotations
+// are available on the service but require proto definitions to be exposed as
+// API endpoints.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - InviteAdvisorRequest/Response, AcceptAdvisorInviteRequest/Response
+// - ListAdvisorsRequest/Response, RevokeAdvisorRequest/Response, AdvisorGrant
+// - ListAdvisorClientsRequest/Response, AdvisorClient
+// - GetAdvisorWorkspaceRequest/Response, AdvisorWorkspace
+// - GetClientPlanRequest/Response, GetClientMonthlyReportRequest/Response
+// - GetClientSpendingTrendRequest/Response
+// - AddAdvisorAnnotationRequest/Response, ListAdvisorAnnotationsRequest/Response, AdvisorAnnotation
+// - ALERT_TYPE_ADVISOR_NOTE
+
+const (
+	// inviteTTL is how long an advisor invite can be accepted
+	inviteTTL = 7 * 24 * time.Hour
+	// trendMonths is how many months the workspace trend covers
+	trendMonths = 12
+	// workspaceAnnotationLimit caps the annotations shown in the workspace
+	workspaceAnnotationLimit = 20
+	// maxAnnotationLength caps an annotation's body, in characters
+	maxAnnotationLength = 2000
+	// defaultAnnotationLimit and maxAnnotationLimit bound annotation listings
+	defaultAnnotationLimit = 50
+	maxAnnotationLimit     = 200
+)
+
+// Advisor errors
+var (
+	ErrInvalidEmail         = errors.New("invalid advisor email")
+	ErrCannotAdviseSelf     = errors.New("you can't be your own advisor")
+	ErrInviteNotFound       = errors.New("advisor invite not found or already accepted")
+	ErrInviteExpired        = errors.New("advisor invite has expired")
+	ErrInviteWrongAccount   = errors.New("advisor invite was sent to a different email")
+	ErrGrantNotFound        = errors.New("advisor not found")
+	ErrNoAdvisorAccess      = errors.New("no advisor access to this client")
+	ErrPlanNotFound         = errors.New("plan not found")
+	ErrInvalidAnnotation    = errors.New("an annotation needs a body of at most 2000 characters")
+	ErrInvalidAnnotationRef = errors.New("plan annotations need a plan and report annotations a month")
+)
+
+// PlanRef is one of the client's plans
+type PlanRef struct {
+	ID           uuid.UUID
+	Name         string
+	Status       string
+	CurrencyCode string
+}
+
+// PlanView is a read-only view of a plan's budgets and actuals
+type PlanView struct {
+	Name               string
+	CurrencyCode       string
+	PeriodStart        *time.Time
+	PeriodEnd          *time.Time
+	TotalBudgetedMinor int64
+	TotalActualMinor   int64
+	Groups             []PlanGroup
+}
+
+// PlanGroup is a group of plan items
+type PlanGroup struct {
+	Name  string
+	Items []PlanItem
+}
+
+// PlanItem is a plan item's budget and actual
+type PlanItem struct {
+	Name          string
+	ItemType      string
+	BudgetedMinor int64
+	ActualMinor   int64
+}
+
+// ReportView is a read-only view of a monthly report
+type ReportView struct {
+	MonthStart         time.Time
+	TotalSpendMinor    int64
+	TotalIncomeMinor   int64
+	NetMinor           int64
+	SpendChangePercent float64
+	TopCategories      []NamedAmount
+	TopMerchants       []NamedAmount
+	Highlights         []string
+}
+
+// NamedAmount is a named amount, such as a category's spend
+type NamedAmount struct {
+	Name        string
+	AmountMinor int64
+}
+
+// TrendPoint is a month's spending and income in one currency
+type TrendPoint struct {
+	Month        time.Time
+	CurrencyCode string
+	SpendMinor   int64 // Positive
+	IncomeMinor  int64
+}
+
+// WorkspaceSource reads the client's data for the workspace
+type WorkspaceSource interface {
+	ListPlans(ctx context.Context, clientID uuid.UUID) ([]PlanRef, error)
+	// Plan returns nil when the client can't see the plan
+	Plan(ctx context.Context, clientID, planID uuid.UUID) (*PlanView, error)
+	// MonthlyReport returns the report for the month starting at monthStart
+	MonthlyReport(ctx context.Context, clientID uuid.UUID, monthStart time.Time) (*ReportView, error)
+	// SpendingTrend returns monthly totals for the months from..to, inclusive
+	SpendingTrend(ctx context.Context, clientID uuid.UUID, from, to time.Time) ([]TrendPoint, error)
+}
+
+// AnnotationNotifier tells a client about a new annotation
+type AnnotationNotifier interface {
+	NotifyAnnotation(ctx context.Context, annotation *repository.Annotation) error
+}
+
+// Workspace is what an advisor sees for one client
+type Workspace struct {
+	Client      *repository.Client
+	Plans       []PlanRef
+	Report      *ReportView // The last complete month
+	Trend       []TrendPoint
+	Annotations []*repository.Annotation // The advisor's own, newest first
+}
+
+// AnnotationInput describes an annotation to leave
+type AnnotationInput struct {
+	Kind       repository.AnnotationKind // Defaults to a note
+	Subject    repository.Subject        // Defaults to general
+	PlanID     *uuid.UUID                // Required for plan annotations
+	MonthStart *time.Time                // Required for report annotations; any day in the month
+	Body       string
+}
+
+// Service provides advisor business logic
+type Service struct {
+	repo     repository.AdvisorRepository
+	source   WorkspaceSource
+	notifier AnnotationNotifier
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewService creates a new advisor service
+func NewService(repo repository.AdvisorRepository, source WorkspaceSource, logger *slog.Logger) *Service {
+	return &Service{repo: repo, source: source, logger: logger, now: time.Now}
+}
+
+// WithNotifier alerts clients when an advisor leaves an annotation
+func (s *Service) WithNotifier(n AnnotationNotifier) *Service {
+	s.notifier = n
+	return s
+}
+
+// InviteAdvisor invites someone by email to advise the client. The returned
+// grant's token is what the advisor accepts.
+func (s *Service) InviteAdvisor(ctx context.Context, clientID uuid.UUID, email string) (*repository.Grant, error) {
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	own, err := s.repo.GetUserEmail(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(own, email) {
+		return nil, ErrCannotAdviseSelf
+	}
+
+	grant := &repository.Grant{
+		ClientID:  clientID,
+		Email:     email,
+		Token:     generateInviteToken(),
+		ExpiresAt: s.now().Add(inviteTTL),
+	}
+	if err := s.repo.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// AcceptInvite makes the user an advisor of the client who invited them. The
+// invite must have been sent to the user's account email.
+func (s *Service) AcceptInvite(ctx context.Context, advisorID uuid.UUID, token string) (*repository.Grant, error) {
+	grant, err := s.repo.GetGrantByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (grant.AcceptedAt != nil || grant.RevokedAt != nil)) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.now().After(grant.ExpiresAt) {
+		return nil, ErrInviteExpired
+	}
+	if grant.ClientID == advisorID {
+		return nil, ErrCannotAdviseSelf
+	}
+
+	email, err := s.repo.GetUserEmail(ctx, advisorID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(email, grant.Email) {
+		return nil, ErrInviteWrongAccount
+	}
+
+	err = s.repo.AcceptGrant(ctx, grant.ID, advisorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetActiveGrant(ctx, grant.ClientID, advisorID)
+}
+
+// ListAdvisors lists the client's advisors and open invites
+func (s *Service) ListAdvisors(ctx context.Context, clientID uuid.UUID) ([]*repository.Grant, error) {
+	return s.repo.ListGrants(ctx, clientID)
+}
+
+// RevokeAdvisor ends an advisor's access, or withdraws an invite, immediately
+func (s *Service) RevokeAdvisor(ctx context.Context, clientID, grantID uuid.UUID) error {
+	err := s.repo.RevokeGrant(ctx, clientID, grantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGrantNotFound
+	}
+	return err
+}
+
+// ListClients lists the clients the user advises
+func (s *Service) ListClients(ctx context.Context, advisorID uuid.UUID) ([]*repository.Client, error) {
+	return s.repo.ListClients(ctx, advisorID)
+}
+
+// GetWorkspace returns the advisor's overview of a client as of the given
+// time: their plans, last month's report, the spending trend and the
+// advisor's recent annotations
+func (s *Service) GetWorkspace(ctx context.Context, advisorID, clientID uuid.UUID, asOf time.Time) (*Workspace, error) {
+	grant, err := s.requireGrant(ctx, advisorID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := s.findClient(ctx, advisorID, grant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Client: client}
+	if ws.Plans, err = s.source.ListPlans(ctx, clientID); err != nil {
+		return nil, fmt.Errorf("failed to list client plans: %w", err)
+	}
+	lastMonth := monthStart(asOf).AddDate(0, -1, 0)
+	if ws.Report, err = s.source.MonthlyReport(ctx, clientID, lastMonth); err != nil {
+		return nil, fmt.Errorf("failed to get client report: %w", err)
+	}
+	if ws.Trend, err = s.trend(ctx, clientID, asOf); err != nil {
+		return nil, err
+	}
+	if ws.Annotations, err = s.repo.ListAnnotations(ctx, clientID, &advisorID, workspaceAnnotationLimit); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// GetPlan returns one of the client's plans
+func (s *Service) GetPlan(ctx context.Context, advisorID, clientID, planID uuid.UUID) (*PlanView, error) {
+	if _, err := s.requireGrant(ctx, advisorID, clientID); err != nil {
+		return nil, err
+	}
+	plan, err := s.source.Plan(ctx, clientID, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client plan: %w", err)
+	}
+	if plan == nil {
+		return nil, ErrPlanNotFound
+	}
+	return plan, nil
+}
+
+// GetMonthlyReport returns the client's report for the month containing month
+func (s *Service) GetMonthlyReport(ctx context.Context, advisorID, clientID uuid.UUID, month time.Time) (*ReportView, error) {
+	if _, err := s.requireGrant(ctx, advisorID, clientID); err != nil {
+		return nil, err
+	}
+	report, err := s.source.MonthlyReport(ctx, clientID, monthStart(month))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client report: %w", err)
+	}
+	return report, nil
+}
+
+// GetSpendingTrend returns the client's monthly spending and income for the
+// twelve months up to and including the month of asOf
+func (s *Service) GetSpendingTrend(ctx context.Context, advisorID, clientID uuid.UUID, asOf time.Time) ([]TrendPoint, error) {
+	if _, err := s.requireGrant(ctx, advisorID, clientID); err != nil {
+		return nil, err
+	}
+	return s.trend(ctx, clientID, asOf)
+}
+
+// AddAnnotation leaves a note or recommendation for the client, who is
+// alerted to it
+func (s *Service) AddAnnotation(ctx context.Context, advisorID, clientID uuid.UUID, input AnnotationInput) (*repository.Annotation, error) {
+	grant, err := s.requireGrant(ctx, advisorID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	annotation, err := s.newAnnotation(ctx, grant, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAnnotation(ctx, annotation); err != nil {
+		return nil, err
+	}
+	annotation.AdvisorName = grant.AdvisorName
+
+	// The annotation is saved either way; the alert is a courtesy
+	if s.notifier != nil {
+		if err := s.notifier.NotifyAnnotation(ctx, annotation); err != nil && s.logger != nil {
+			s.logger.Warn("failed to notify advisor annotation", "clientID", clientID, "error", err)
+		}
+	}
+	return annotation, nil
+}
+
+// ListAnnotations lists the annotations the client's advisors left, newest first
+func (s *Service) ListAnnotations(ctx context.Context, clientID uuid.UUID, limit int) ([]*repository.Annotation, error) {
+	if limit <= 0 {
+		limit = defaultAnnotationLimit
+	}
+	if limit > maxAnnotationLimit {
+		limit = maxAnnotationLimit
+	}
+	return s.repo.ListAnnotations(ctx, clientID, nil, limit)
+}
+
+// newAnnotation validates input into an annotation under the grant
+func (s *Service) newAnnotation(ctx context.Context, grant *repository.Grant, input AnnotationInput) (*repository.Annotation, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" || len([]rune(body)) > maxAnnotationLength {
+		return nil, ErrInvalidAnnotation
+	}
+
+	annotation := &repository.Annotation{
+		GrantID:   grant.ID,
+		ClientID:  grant.ClientID,
+		AdvisorID: *grant.AdvisorID,
+		Kind:      input.Kind,
+		Subject:   input.Subject,
+		Body:      body,
+	}
+	switch annotation.Kind {
+	case "":
+		annotation.Kind = repository.KindNote
+	case repository.KindNote, repository.KindRecommendation:
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidAnnotation, input.Kind)
+	}
+
+	switch annotation.Subject {
+	case "", repository.SubjectGeneral:
+		annotation.Subject = repository.SubjectGeneral
+	case repository.SubjectTrend:
+	case repository.SubjectPlan:
+		if input.PlanID == nil {
+			return nil, ErrInvalidAnnotationRef
+		}
+		plan, err := s.source.Plan(ctx, grant.ClientID, *input.PlanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client plan: %w", err)
+		}
+		if plan == nil {
+			return nil, ErrPlanNotFound
+		}
+		annotation.PlanID = input.PlanID
+	case repository.SubjectReport:
+		if input.MonthStart == nil {
+			return nil, ErrInvalidAnnotationRef
+		}
+		month := monthStart(*input.MonthStart)
+		annotation.MonthStart = &month
+	default:
+		return nil, fmt.Errorf("%w: unknown subject %q", ErrInvalidAnnotationRef, input.Subject)
+	}
+	return annotation, nil
+}
+
+// requireGrant returns the advisor's active grant over the client
+func (s *Service) requireGrant(ctx context.Context, advisorID, clientID uuid.UUID) (*repository.Grant, error) {
+	grant, err := s.repo.GetActiveGrant(ctx, clientID, advisorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoAdvisorAccess
+	}
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// findClient returns the advisor's client under the grant
+func (s *Service) findClient(ctx context.Context, advisorID, grantID uuid.UUID) (*repository.Client, error) {
+	clients, err := s.repo.ListClients(ctx, advisorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clients {
+		if c.GrantID == grantID {
+			return c, nil
+		}
+	}
+	return nil, ErrNoAdvisorAccess
+}
+
+// trend covers the trendMonths months ending with the month of asOf
+func (s *Service) trend(ctx context.Context, clientID uuid.UUID, asOf time.Time) ([]TrendPoint, error) {
+	to := monthStart(asOf)
+	from := to.AddDate(0, -(trendMonths - 1), 0)
+	points, err := s.source.SpendingTrend(ctx, clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client spending trend: %w", err)
+	}
+	return points, nil
+}
+
+// monthStart returns the first day of t's month, in UTC
+func monthStart(t time.Time) time.Time {
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// generateInviteToken creates a random 32-character hex token
+func generateInviteToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
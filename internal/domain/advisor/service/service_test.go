@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/advisor/repository"
+)
+
+// fakeAdvisorRepository keeps grants and annotations in memory
+type fakeAdvisorRepository struct {
+	grants      map[string]*repository.Grant // Keyed by token
+	annotations []*repository.Annotation
+	emails      map[uuid.UUID]string
+}
+
+func newFakeAdvisorRepository() *fakeAdvisorRepository {
+	return &fakeAdvisorRepository{
+		grants: make(map[string]*repository.Grant),
+		emails: make(map[uuid.UUID]string),
+	}
+}
+
+func (f *fakeAdvisorRepository) CreateGrant(_ context.Context, grant *repository.Grant) error {
+	grant.ID = uuid.New()
+	f.grants[grant.Token] = grant
+	return nil
+}
+
+func (f *fakeAdvisorRepository) GetGrantByToken(_ context.Context, token string) (*repository.Grant, error) {
+	grant, ok := f.grants[token]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return grant, nil
+}
+
+func (f *fakeAdvisorRepository) AcceptGrant(_ context.Context, grantID, advisorID uuid.UUID) error {
+	for _, g := range f.grants {
+		if g.ID == grantID && g.AcceptedAt == nil && g.RevokedAt == nil {
+			now := time.Now()
+			g.AdvisorID, g.AcceptedAt = &advisorID, &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeAdvisorRepository) GetActiveGrant(_ context.Context, clientID, advisorID uuid.UUID) (*repository.Grant, error) {
+	for _, g := range f.grants {
+		if g.ClientID == clientID && g.Active() && *g.AdvisorID == advisorID {
+			return g, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeAdvisorRepository) ListGrants(_ context.Context, clientID uuid.UUID) ([]*repository.Grant, error) {
+	var grants []*repository.Grant
+	for _, g := range f.grants {
+		if g.ClientID == clientID && g.RevokedAt == nil {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+func (f *fakeAdvisorRepository) RevokeGrant(_ context.Context, clientID, grantID uuid.UUID) error {
+	for _, g := range f.grants {
+		if g.ID == grantID && g.ClientID == clientID && g.RevokedAt == nil {
+			now := time.Now()
+			g.RevokedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeAdvisorRepository) ListClients(_ context.Context, advisorID uuid.UUID) ([]*repository.Client, error) {
+	var clients []*repository.Client
+	for _, g := range f.grants {
+		if g.Active() && *g.AdvisorID == advisorID {
+			clients = append(clients, &repository.Client{GrantID: g.ID, UserID: g.ClientID, Email: f.emails[g.ClientID], Since: *g.AcceptedAt})
+		}
+	}
+	return clients, nil
+}
+
+func (f *fakeAdvisorRepository) GetUserEmail(_ context.Context, userID uuid.UUID) (string, error) {
+	return f.emails[userID], nil
+}
+
+func (f *fakeAdvisorRepository) CreateAnnotation(_ context.Context, a *repository.Annotation) error {
+	a.ID = uuid.New()
+	f.annotations = append(f.annotations, a)
+	return nil
+}
+
+func (f *fakeAdvisorRepository) ListAnnotations(_ context.Context, clientID uuid.UUID, advisorID *uuid.UUID, limit int) ([]*repository.Annotation, error) {
+	var annotations []*repository.Annotation
+	for _, a := range f.annotations {
+		if a.ClientID == clientID && (advisorID == nil || a.AdvisorID == *advisorID) && len(annotations) < limit {
+			annotations = append(annotations, a)
+		}
+	}
+	return annotations, nil
+}
+
+// fakeWorkspaceSource serves one plan and records the trend window asked for
+type fakeWorkspaceSource struct {
+	planID    uuid.UUID
+	trendFrom time.Time
+	trendTo   time.Time
+}
+
+func (f *fakeWorkspaceSource) ListPlans(_ context.Context, _ uuid.UUID) ([]PlanRef, error) {
+	return []PlanRef{{ID: f.planID, Name: "2026"}}, nil
+}
+
+func (f *fakeWorkspaceSource) Plan(_ context.Context, _, planID uuid.UUID) (*PlanView, error) {
+	if planID != f.planID {
+		return nil, nil
+	}
+	return &PlanView{Name: "2026"}, nil
+}
+
+func (f *fakeWorkspaceSource) MonthlyReport(_ context.Context, _ uuid.UUID, monthStart time.Time) (*ReportView, error) {
+	return &ReportView{MonthStart: monthStart}, nil
+}
+
+func (f *fakeWorkspaceSource) SpendingTrend(_ context.Context, _ uuid.UUID, from, to time.Time) ([]TrendPoint, error) {
+	f.trendFrom, f.trendTo = from, to
+	return []TrendPoint{{Month: to, CurrencyCode: "EUR", SpendMinor: 120000}}, nil
+}
+
+// fakeNotifier records the annotations clients were alerted to
+type fakeNotifier struct {
+	notified []*repository.Annotation
+}
+
+func (f *fakeNotifier) NotifyAnnotation(_ context.Context, a *repository.Annotation) error {
+	f.notified = append(f.notified, a)
+	return nil
+}
+
+// setupAdvisor returns a service where advisor has accepted client's invite
+func setupAdvisor(t *testing.T) (svc *Service, repo *fakeAdvisorRepository, source *fakeWorkspaceSource, client, advisor uuid.UUID) {
+	t.Helper()
+	repo = newFakeAdvisorRepository()
+	source = &fakeWorkspaceSource{planID: uuid.New()}
+	svc = NewService(repo, source, nil)
+	client, advisor = uuid.New(), uuid.New()
+	repo.emails[client] = "client@example.com"
+	repo.emails[advisor] = "Advisor@Example.com"
+
+	grant, err := svc.InviteAdvisor(context.Background(), client, " advisor@example.com ")
+	require.NoError(t, err)
+	assert.Len(t, grant.Token, 32)
+	_, err = svc.AcceptInvite(context.Background(), advisor, grant.Token)
+	require.NoError(t, err)
+	return svc, repo, source, client, advisor
+}
+
+func TestAdvisorWorkspace_RequiresActiveGrant(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, source, client, advisor := setupAdvisor(t)
+	asOf := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	ws, err := svc.GetWorkspace(ctx, advisor, client, asOf)
+	require.NoError(t, err)
+	assert.Equal(t, client, ws.Client.UserID)
+	require.Len(t, ws.Plans, 1)
+	require.NotNil(t, ws.Report)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), ws.Report.MonthStart)
+	assert.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), source.trendFrom)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), source.trendTo)
+
+	// Someone without a grant has no workspace
+	_, err = svc.GetWorkspace(ctx, uuid.New(), client, asOf)
+	assert.ErrorIs(t, err, ErrNoAdvisorAccess)
+	_, err = svc.GetPlan(ctx, advisor, client, uuid.New())
+	assert.ErrorIs(t, err, ErrPlanNotFound)
+
+	grants, err := svc.ListAdvisors(ctx, client)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.NoError(t, svc.RevokeAdvisor(ctx, client, grants[0].ID))
+	assert.ErrorIs(t, svc.RevokeAdvisor(ctx, client, grants[0].ID), ErrGrantNotFound)
+
+	_, err = svc.GetPlan(ctx, advisor, client, source.planID)
+	assert.ErrorIs(t, err, ErrNoAdvisorAccess)
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Body: "Hello"})
+	assert.ErrorIs(t, err, ErrNoAdvisorAccess)
+	assert.Empty(t, repo.annotations)
+}
+
+func TestAcceptInvite_RejectsWrongAccountExpiredAndSelf(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeAdvisorRepository()
+	svc := NewService(repo, &fakeWorkspaceSource{}, nil)
+	client, stranger := uuid.New(), uuid.New()
+	repo.emails[client] = "client@example.com"
+	repo.emails[stranger] = "stranger@example.com"
+
+	_, err := svc.InviteAdvisor(ctx, client, "CLIENT@example.com")
+	assert.ErrorIs(t, err, ErrCannotAdviseSelf)
+	_, err = svc.InviteAdvisor(ctx, client, "not-an-email")
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+
+	grant, err := svc.InviteAdvisor(ctx, client, "advisor@example.com")
+	require.NoError(t, err)
+
+	_, err = svc.AcceptInvite(ctx, stranger, grant.Token)
+	assert.ErrorIs(t, err, ErrInviteWrongAccount)
+	_, err = svc.AcceptInvite(ctx, client, grant.Token)
+	assert.ErrorIs(t, err, ErrCannotAdviseSelf)
+
+	svc.now = func() time.Time { return time.Now().Add(inviteTTL + time.Hour) }
+	_, err = svc.AcceptInvite(ctx, stranger, grant.Token)
+	assert.ErrorIs(t, err, ErrInviteExpired)
+}
+
+func TestAddAnnotation_ValidatesAndAlertsClient(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, source, client, advisor := setupAdvisor(t)
+	notifier := &fakeNotifier{}
+	svc.WithNotifier(notifier)
+
+	note, err := svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Body: "  Looking good overall.  "})
+	require.NoError(t, err)
+	assert.Equal(t, repository.KindNote, note.Kind)
+	assert.Equal(t, repository.SubjectGeneral, note.Subject)
+	assert.Equal(t, "Looking good overall.", note.Body)
+	assert.Equal(t, advisor, note.AdvisorID)
+
+	month := time.Date(2026, 9, 17, 0, 0, 0, 0, time.UTC)
+	rec, err := svc.AddAnnotation(ctx, advisor, client, AnnotationInput{
+		Kind:       repository.KindRecommendation,
+		Subject:    repository.SubjectReport,
+		MonthStart: &month,
+		Body:       "Dining out doubled in September; consider a cap.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), *rec.MonthStart)
+
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Subject: repository.SubjectPlan, PlanID: &source.planID, Body: "Raise the buffer."})
+	require.NoError(t, err)
+
+	unknownPlan := uuid.New()
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Subject: repository.SubjectPlan, PlanID: &unknownPlan, Body: "x"})
+	assert.ErrorIs(t, err, ErrPlanNotFound)
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Subject: repository.SubjectReport, Body: "x"})
+	assert.ErrorIs(t, err, ErrInvalidAnnotationRef)
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Body: "   "})
+	assert.ErrorIs(t, err, ErrInvalidAnnotation)
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Body: strings.Repeat("a", maxAnnotationLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidAnnotation)
+	_, err = svc.AddAnnotation(ctx, advisor, client, AnnotationInput{Kind: "order", Body: "Sell everything"})
+	assert.ErrorIs(t, err, ErrInvalidAnnotation)
+
+	assert.Len(t, repo.annotations, 3)
+	assert.Len(t, notifier.notified, 3)
+
+	listed, err := svc.ListAnnotations(ctx, client, 0)
+	require.NoError(t, err)
+	assert.Len(t, listed, 3)
+}
@@ -9,4 +9,5 @@ var (
 	ErrInvalidToken       = errors.New("invalid or expired token")
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrInvalidCredentials = errors.New("invalid or expired credentials")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
 )
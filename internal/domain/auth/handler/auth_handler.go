@@ -183,8 +183,9 @@ func toAuthResponse(result *service.RegisterResult) *echov1.AuthResponse {
 
 func metadataFromRequest[T any](req *connect.Request[T]) service.SessionMetadata {
 	return service.SessionMetadata{
-		UserAgent: req.Header().Get("User-Agent"),
-		ClientIP:  req.Peer().Addr,
+		UserAgent:  req.Header().Get("User-Agent"),
+		ClientIP:   req.Peer().Addr,
+		DeviceName: req.Header().Get("X-Device-Name"),
 	}
 }
 
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
+)
+
+// oauthNonceCookie holds the nonce of a login in progress. It must be sent on
+// Apple's cross-site form POST, hence SameSite=None.
+const oauthNonceCookie = "echo_oauth_nonce"
+
+// OAuthLoginHandler serves the OAuth sign-in redirects under service.OAuthLoginPath
+type OAuthLoginHandler struct {
+	svc    *service.OAuthLoginService
+	logger *slog.Logger
+}
+
+// NewOAuthLoginHandler creates a new OAuth sign-in handler
+func NewOAuthLoginHandler(svc *service.OAuthLoginService, logger *slog.Logger) *OAuthLoginHandler {
+	return &OAuthLoginHandler{svc: svc, logger: logger}
+}
+
+// ServeHTTP routes {provider}/begin and {provider}/callback
+func (h *OAuthLoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	provider, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, service.OAuthLoginPath), "/")
+	switch {
+	case action == "begin" && r.Method == http.MethodGet:
+		h.begin(w, r, provider)
+	case action == "callback" && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+		h.callback(w, r, provider)
+	case action == "begin" || action == "callback":
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// begin sends the user to the provider's consent screen
+func (h *OAuthLoginHandler) begin(w http.ResponseWriter, r *http.Request, provider string) {
+	authURL, nonce, err := h.svc.BeginOAuthLogin(provider, r.URL.Query().Get("redirect_uri"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthNonceCookie,
+		Value:    nonce,
+		Path:     service.OAuthLoginPath,
+		MaxAge:   int((15 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// callback completes the login and hands the tokens to the app: in the URL
+// fragment of the redirect URI the login began with, or as JSON
+func (h *OAuthLoginHandler) callback(w http.ResponseWriter, r *http.Request, provider string) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid sign-in response.", http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("error") != "" {
+		http.Error(w, "Sign-in was cancelled or not granted.", http.StatusBadRequest)
+		return
+	}
+
+	var nonce string
+	if cookie, err := r.Cookie(oauthNonceCookie); err == nil {
+		nonce = cookie.Value
+	}
+	http.SetCookie(w, &http.Cookie{Name: oauthNonceCookie, Path: service.OAuthLoginPath, MaxAge: -1,
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteNoneMode})
+
+	result, err := h.svc.CompleteOAuthLogin(r.Context(), provider, nonce, r.Form, service.SessionMetadata{
+		UserAgent: r.UserAgent(),
+		ClientIP:  r.RemoteAddr,
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	tokens := url.Values{
+		"access_token":  {result.Tokens.AccessToken},
+		"refresh_token": {result.Tokens.RefreshToken},
+		"token_type":    {result.Tokens.TokenType},
+		"expires_at":    {result.Tokens.ExpiresAt.UTC().Format(time.RFC3339)},
+		"provider":      {result.Provider},
+		"new_user":      {strconv.FormatBool(result.NewUser)},
+	}
+	if result.RedirectURI != "" {
+		http.Redirect(w, r, result.RedirectURI+"#"+tokens.Encode(), http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token":  result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"token_type":    result.Tokens.TokenType,
+		"expires_at":    result.Tokens.ExpiresAt.UTC(),
+		"user_id":       result.User.ID,
+		"provider":      result.Provider,
+		"new_user":      result.NewUser,
+	})
+}
+
+func (h *OAuthLoginHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOAuthProviderUnavailable):
+		http.Error(w, "This sign-in provider is not available.", http.StatusNotFound)
+	case errors.Is(err, service.ErrOAuthRedirectNotAllowed):
+		http.Error(w, "This redirect URI is not allowed.", http.StatusBadRequest)
+	case errors.Is(err, service.ErrInvalidOAuthLoginState):
+		http.Error(w, "This sign-in has expired. Please try again.", http.StatusBadRequest)
+	case errors.Is(err, service.ErrOAuthEmailRequired):
+		http.Error(w, "The provider did not share your email address.", http.StatusBadRequest)
+	case errors.Is(err, service.ErrOAuthEmailUnverified):
+		http.Error(w, service.ErrOAuthEmailUnverified.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrAccountInactive):
+		http.Error(w, "This account is deactivated.", http.StatusForbidden)
+	default:
+		h.logger.Error("failed to complete oauth sign-in", slog.Any("error", err))
+		http.Error(w, "Failed to sign in.", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/common"
+)
+
+// APIKey is a long-lived credential for scripts. Only its hash is stored.
+type APIKey struct {
+	ID         uuid.UUID  `db:"id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Scope      string     `db:"scope"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	LastUsedIP *string    `db:"last_used_ip"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+// APIKeyOwner is an API key along with the user it acts as
+type APIKeyOwner struct {
+	APIKey
+	Email    string `db:"email"`
+	Username string `db:"username"`
+	Role     string `db:"role"`
+	IsActive bool   `db:"is_active"`
+}
+
+// APIKeyRepository stores API keys
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*APIKey, error)
+	CountActiveAPIKeys(ctx context.Context, userID uuid.UUID) (int, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKeyOwner, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID, at time.Time) error
+	TouchAPIKey(ctx context.Context, keyID uuid.UUID, at time.Time, ip string) error
+}
+
+// PostgresAPIKeyRepository implements APIKeyRepository using PostgreSQL
+type PostgresAPIKeyRepository struct {
+	pgpool PgxPool
+}
+
+var _ APIKeyRepository = (*PostgresAPIKeyRepository)(nil)
+
+// NewPostgresAPIKeyRepository creates a new API key repository
+func NewPostgresAPIKeyRepository(pgpool PgxPool) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{pgpool: pgpool}
+}
+
+const apiKeyColumns = `k.id, k.user_id, k.name, k.prefix, k.key_hash, k.scope, k.created_at,
+	k.expires_at, k.last_used_at, k.last_used_ip, k.revoked_at`
+
+// CreateAPIKey stores a new key
+func (r *PostgresAPIKeyRepository) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scope, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.pgpool.Exec(ctx, query, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scope, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// ListAPIKeys returns the user's keys, including revoked ones, newest first
+func (r *PostgresAPIKeyRepository) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys k WHERE k.user_id = $1 ORDER BY k.created_at DESC`
+
+	rows, err := r.pgpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[APIKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// CountActiveAPIKeys counts the user's keys that are neither revoked nor expired
+func (r *PostgresAPIKeyRepository) CountActiveAPIKeys(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`
+	var n int
+	if err := r.pgpool.QueryRow(ctx, query, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+	return n, nil
+}
+
+// GetAPIKeyByHash looks a key up by its hash, with its owner
+func (r *PostgresAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKeyOwner, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `, u.email, COALESCE(u.username, '') AS username, u.role, u.is_active
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[APIKeyOwner])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, common.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey revokes one of the user's keys. Revoking a revoked key is a no-op.
+func (r *PostgresAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND user_id = $2`
+	tag, err := r.pgpool.Exec(ctx, query, keyID, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return common.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records when and from where a key was last used
+func (r *PostgresAPIKeyRepository) TouchAPIKey(ctx context.Context, keyID uuid.UUID, at time.Time, ip string) error {
+	query := `UPDATE api_keys SET last_used_at = $2, last_used_ip = NULLIF($3, '') WHERE id = $1`
+	if _, err := r.pgpool.Exec(ctx, query, keyID, at, ip); err != nil {
+		return fmt.Errorf("failed to record api key use: %w", err)
+	}
+	return nil
+}
@@ -184,7 +184,7 @@ func (r *PostgresAuthRepository) GetUserSessionByToken(ctx context.Context, hash
 	query := `
 		SELECT id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at
 		FROM user_sessions
-		WHERE hashed_refresh_token = $1 AND expires_at > $2
+		WHERE hashed_refresh_token = $1 AND expires_at > $2 AND revoked_at IS NULL
 	`
 
 	rows, err := r.pgpool.Query(ctx, query, hashedToken, time.Now())
@@ -217,6 +217,120 @@ func (r *PostgresAuthRepository) DeleteAllUserSessions(ctx context.Context, user
 	return err
 }
 
+// TrackUserSession records the device and first access token of a new session
+func (r *PostgresAuthRepository) TrackUserSession(ctx context.Context, sessionID uuid.UUID, accessTokenID, deviceName string) error {
+	query := `
+		UPDATE user_sessions
+		SET access_token_id = NULLIF($2, ''), device_name = NULLIF($3, ''), last_seen_at = created_at, last_seen_ip = client_ip
+		WHERE id = $1
+	`
+	_, err := r.pgpool.Exec(ctx, query, sessionID, accessTokenID, deviceName)
+	return err
+}
+
+// RotateUserSession replaces a session's refresh token, remembering the old one.
+// It only rotates while the session still has currentHash, so of two refreshes
+// racing with the same token only one wins; the other gets ErrSessionNotFound.
+func (r *PostgresAuthRepository) RotateUserSession(ctx context.Context, sessionID uuid.UUID, currentHash, hashedRefreshToken, accessTokenID, clientIP string, expiresAt time.Time) error {
+	query := `
+		UPDATE user_sessions
+		SET previous_refresh_token_hash = hashed_refresh_token, hashed_refresh_token = $2,
+			access_token_id = NULLIF($3, ''), last_seen_ip = COALESCE(NULLIF($4, ''), last_seen_ip),
+			last_seen_at = NOW(), expires_at = $5
+		WHERE id = $1 AND hashed_refresh_token = $6 AND revoked_at IS NULL
+	`
+	tag, err := r.pgpool.Exec(ctx, query, sessionID, hashedRefreshToken, accessTokenID, clientIP, expiresAt, currentHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return common.ErrSessionNotFound
+	}
+	return nil
+}
+
+// GetUserSessionByPreviousToken finds the session a refresh token was rotated out of
+func (r *PostgresAuthRepository) GetUserSessionByPreviousToken(ctx context.Context, hashedToken string) (*UserSession, error) {
+	query := `
+		SELECT id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at
+		FROM user_sessions
+		WHERE previous_refresh_token_hash = $1
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, hashedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[UserSession])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, common.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActiveUserSessions returns the user's unexpired, unrevoked sessions, most recently seen first
+func (r *PostgresAuthRepository) ListActiveUserSessions(ctx context.Context, userID uuid.UUID) ([]ActiveSession, error) {
+	query := `
+		SELECT id, device_name, user_agent, client_ip, last_seen_ip, access_token_id, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND expires_at > NOW() AND revoked_at IS NULL
+		ORDER BY COALESCE(last_seen_at, created_at) DESC
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[ActiveSession])
+}
+
+// RevokeUserSession revokes one of the user's sessions and returns its latest access token ID
+func (r *PostgresAuthRepository) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) (string, error) {
+	query := `
+		UPDATE user_sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING COALESCE(access_token_id, '')
+	`
+
+	var accessTokenID string
+	err := r.pgpool.QueryRow(ctx, query, sessionID, userID).Scan(&accessTokenID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", common.ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return accessTokenID, nil
+}
+
+// TouchSessionByAccessToken records activity on the session an access token
+// belongs to and reports whether that session was revoked. Tokens no session
+// knows, e.g. ones rotated out by a refresh, are not revoked.
+func (r *PostgresAuthRepository) TouchSessionByAccessToken(ctx context.Context, accessTokenID, clientIP string, at time.Time) (bool, error) {
+	query := `
+		UPDATE user_sessions
+		SET last_seen_at = CASE WHEN revoked_at IS NULL THEN $3 ELSE last_seen_at END,
+			last_seen_ip = CASE WHEN revoked_at IS NULL THEN COALESCE(NULLIF($2, ''), last_seen_ip) ELSE last_seen_ip END
+		WHERE access_token_id = $1
+		RETURNING revoked_at IS NOT NULL
+	`
+
+	var revoked bool
+	err := r.pgpool.QueryRow(ctx, query, accessTokenID, clientIP, at).Scan(&revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
+
 // CreateUserToken creates a verification or reset token
 func (r *PostgresAuthRepository) CreateUserToken(ctx context.Context, userID uuid.UUID, tokenHash, tokenType string, expiresAt time.Time) error {
 	query := `
@@ -273,23 +387,41 @@ func (r *PostgresAuthRepository) UpdatePassword(ctx context.Context, userID uuid
 	return err
 }
 
-// CreateOrUpdateOAuthIdentity creates or updates an OAuth identity
-func (r *PostgresAuthRepository) CreateOrUpdateOAuthIdentity(ctx context.Context, providerName, providerUserID string, userID uuid.UUID, accessToken, refreshToken *string) error {
+// CreateOrUpdateOAuthIdentity creates or updates an OAuth identity, recording a sign-in
+func (r *PostgresAuthRepository) CreateOrUpdateOAuthIdentity(ctx context.Context, providerName, providerUserID string, userID uuid.UUID, email string, accessToken, refreshToken *string) error {
 	query := `
-		INSERT INTO user_oauth_identities (provider_name, provider_user_id, user_id, provider_access_token, provider_refresh_token, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO user_oauth_identities (provider_name, provider_user_id, user_id, email, provider_access_token, provider_refresh_token, last_login_at, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $7, $7)
 		ON CONFLICT (provider_name, provider_user_id)
 		DO UPDATE SET
+			email = COALESCE(EXCLUDED.email, user_oauth_identities.email),
 			provider_access_token = EXCLUDED.provider_access_token,
-			provider_refresh_token = EXCLUDED.provider_refresh_token,
+			provider_refresh_token = COALESCE(EXCLUDED.provider_refresh_token, user_oauth_identities.provider_refresh_token),
+			last_login_at = EXCLUDED.last_login_at,
 			updated_at = EXCLUDED.updated_at
 	`
 
-	now := time.Now()
-	_, err := r.pgpool.Exec(ctx, query, providerName, providerUserID, userID, accessToken, refreshToken, now, now)
+	_, err := r.pgpool.Exec(ctx, query, providerName, providerUserID, userID, email, accessToken, refreshToken, time.Now())
 	return err
 }
 
+// ListOAuthIdentities returns the providers linked to a user
+func (r *PostgresAuthRepository) ListOAuthIdentities(ctx context.Context, userID uuid.UUID) ([]OAuthIdentity, error) {
+	query := `
+		SELECT provider_name, provider_user_id, user_id, provider_access_token, provider_refresh_token,
+		       email, last_login_at, created_at, updated_at
+		FROM user_oauth_identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.pgpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[OAuthIdentity])
+}
+
 // GetUserByOAuthIdentity retrieves a user by OAuth provider identity
 func (r *PostgresAuthRepository) GetUserByOAuthIdentity(ctx context.Context, providerName, providerUserID string) (*User, error) {
 	query := `
@@ -168,6 +168,37 @@ func TestPostgresAuthRepository_CreateUserSession(t *testing.T) {
 	}
 }
 
+func TestPostgresAuthRepository_RotateUserSession_StaleToken(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("pgxmock.NewPool: %v", err)
+	}
+	defer mock.Close()
+
+	sessionID := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+	mock.ExpectExec(regexp.QuoteMeta(rotateSessionQuery)).
+		WithArgs(sessionID, "new-hash", "jti", "ip", expiresAt, "old-hash").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(regexp.QuoteMeta(rotateSessionQuery)).
+		WithArgs(sessionID, "other-hash", "jti", "ip", expiresAt, "old-hash").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	repo := NewPostgresAuthRepository(mock)
+	if err := repo.RotateUserSession(context.Background(), sessionID, "old-hash", "new-hash", "jti", "ip", expiresAt); err != nil {
+		t.Fatalf("RotateUserSession: %v", err)
+	}
+	// A concurrent refresh with the same token finds it already rotated
+	err = repo.RotateUserSession(context.Background(), sessionID, "old-hash", "other-hash", "jti", "ip", expiresAt)
+	if err != common.ErrSessionNotFound {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
 func TestPostgresAuthRepository_GetUserByOAuthIdentity_NotFound(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -211,7 +242,7 @@ var (
 	getUserSessionQuery = `
 		SELECT id, user_id, hashed_refresh_token, user_agent, client_ip, expires_at, created_at
 		FROM user_sessions
-		WHERE hashed_refresh_token = $1 AND expires_at > $2
+		WHERE hashed_refresh_token = $1 AND expires_at > $2 AND revoked_at IS NULL
 	`
 	getUserTokenQuery = `
 		SELECT token_hash, user_id, type, expires_at, created_at
@@ -224,6 +255,13 @@ var (
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at
 	`
+	rotateSessionQuery = `
+		UPDATE user_sessions
+		SET previous_refresh_token_hash = hashed_refresh_token, hashed_refresh_token = $2,
+			access_token_id = NULLIF($3, ''), last_seen_ip = COALESCE(NULLIF($4, ''), last_seen_ip),
+			last_seen_at = NOW(), expires_at = $5
+		WHERE id = $1 AND hashed_refresh_token = $6 AND revoked_at IS NULL
+	`
 	getUserByOAuthQuery = `
 		SELECT u.id, u.email, u.username, u.password_hash, u.display_name, u.profile_image_url, u.role,
 		       u.is_active, u.email_verified_at, u.created_at, u.updated_at, u.last_login_at
@@ -32,6 +32,19 @@ type UserSession struct {
 	CreatedAt          time.Time `db:"created_at"`
 }
 
+// ActiveSession is a signed-in device, as listed to its user
+type ActiveSession struct {
+	ID            uuid.UUID  `db:"id"`
+	DeviceName    *string    `db:"device_name"`
+	UserAgent     *string    `db:"user_agent"`
+	ClientIP      *string    `db:"client_ip"` // Address the session signed in from
+	LastSeenIP    *string    `db:"last_seen_ip"`
+	AccessTokenID *string    `db:"access_token_id"`
+	CreatedAt     time.Time  `db:"created_at"`
+	LastSeenAt    *time.Time `db:"last_seen_at"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+}
+
 type UserToken struct {
 	TokenHash string    `db:"token_hash"`
 	UserID    uuid.UUID `db:"user_id"`
@@ -41,13 +54,15 @@ type UserToken struct {
 }
 
 type OAuthIdentity struct {
-	ProviderName         string    `db:"provider_name"`
-	ProviderUserID       string    `db:"provider_user_id"`
-	UserID               uuid.UUID `db:"user_id"`
-	ProviderAccessToken  *string   `db:"provider_access_token"`
-	ProviderRefreshToken *string   `db:"provider_refresh_token"`
-	CreatedAt            time.Time `db:"created_at"`
-	UpdatedAt            time.Time `db:"updated_at"`
+	ProviderName         string     `db:"provider_name"`
+	ProviderUserID       string     `db:"provider_user_id"`
+	UserID               uuid.UUID  `db:"user_id"`
+	ProviderAccessToken  *string    `db:"provider_access_token"`
+	ProviderRefreshToken *string    `db:"provider_refresh_token"`
+	Email                *string    `db:"email"`
+	LastLoginAt          *time.Time `db:"last_login_at"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
 }
 
 type AuthRepository interface {
@@ -62,6 +77,12 @@ type AuthRepository interface {
 	GetUserSessionByToken(ctx context.Context, hashedToken string) (*UserSession, error)
 	DeleteUserSession(ctx context.Context, hashedToken string) error
 	DeleteAllUserSessions(ctx context.Context, userID uuid.UUID) error
+	TrackUserSession(ctx context.Context, sessionID uuid.UUID, accessTokenID, deviceName string) error
+	RotateUserSession(ctx context.Context, sessionID uuid.UUID, currentHash, hashedRefreshToken, accessTokenID, clientIP string, expiresAt time.Time) error
+	GetUserSessionByPreviousToken(ctx context.Context, hashedToken string) (*UserSession, error)
+	ListActiveUserSessions(ctx context.Context, userID uuid.UUID) ([]ActiveSession, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) (accessTokenID string, err error)
+	TouchSessionByAccessToken(ctx context.Context, accessTokenID, clientIP string, at time.Time) (revoked bool, err error)
 
 	CreateUserToken(ctx context.Context, userID uuid.UUID, tokenHash, tokenType string, expiresAt time.Time) error
 	GetUserTokenByHash(ctx context.Context, tokenHash, tokenType string) (*UserToken, error)
@@ -70,8 +91,9 @@ type AuthRepository interface {
 	VerifyEmail(ctx context.Context, userID uuid.UUID) error
 	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
 
-	CreateOrUpdateOAuthIdentity(ctx context.Context, providerName, providerUserID string, userID uuid.UUID, accessToken, refreshToken *string) error
+	CreateOrUpdateOAuthIdentity(ctx context.Context, providerName, providerUserID string, userID uuid.UUID, email string, accessToken, refreshToken *string) error
 	GetUserByOAuthIdentity(ctx context.Context, providerName, providerUserID string) (*User, error)
+	ListOAuthIdentities(ctx context.Context, userID uuid.UUID) ([]OAuthIdentity, error)
 
 	// Push notifications
 	UpdateExpoPushToken(ctx context.Context, userID uuid.UUID, pushToken string) error
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/common"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// APIKeyScope limits what an API key can call
+type APIKeyScope string
+
+const (
+	// APIKeyScopeReadOnly allows Get and List calls
+	APIKeyScopeReadOnly APIKeyScope = "read_only"
+	// APIKeyScopeImportOnly allows reads plus pushing transactions and files
+	APIKeyScopeImportOnly APIKeyScope = "import_only"
+	// APIKeyScopeFull allows everything except managing the account itself
+	APIKeyScopeFull APIKeyScope = "full"
+)
+
+const (
+	maxAPIKeysPerUser = 10
+	maxAPIKeyNameLen  = 100
+	apiKeyRandomBytes = 32
+	// apiKeyPrefixLen is how much of a key is kept in the clear to identify it
+	apiKeyPrefixLen = len(interceptors.APIKeyPrefix) + 8
+	// apiKeyTouchInterval throttles last-used writes for busy scripts
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	// ErrInvalidAPIKeyScope is returned for a scope other than the APIKeyScope constants
+	ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+	// ErrInvalidAPIKeyName is returned for an empty or overly long key name
+	ErrInvalidAPIKeyName = errors.New("api key name must be 1-100 characters")
+	// ErrTooManyAPIKeys is returned when a user already has maxAPIKeysPerUser active keys
+	ErrTooManyAPIKeys = errors.New("too many active api keys")
+)
+
+// importProcedures are the writes an import-only key may make
+var importProcedures = map[string]struct{}{
+	echov1connect.FinanceServiceImportTransactionsCsvProcedure:   {},
+	echov1connect.FinanceServiceCreateManualTransactionProcedure: {},
+	echov1connect.ImportServiceUploadUserFileProcedure:           {},
+	echov1connect.ImportServiceAnalyzeCsvFileProcedure:           {},
+	echov1connect.ImportServiceCreateImportJobProcedure:          {},
+	echov1connect.PlaidServiceSyncTransactionsProcedure:          {},
+}
+
+// accountServices can't be called with API keys of any scope: sessions,
+// billing and user administration stay with the logged-in user
+var accountServices = []string{
+	"/" + echov1connect.AuthServiceName + "/",
+	"/" + echov1connect.AdminUserServiceName + "/",
+	"/" + echov1connect.PaymentsServiceName + "/",
+}
+
+// Allows reports whether a key with this scope may call procedure
+func (s APIKeyScope) Allows(procedure string) bool {
+	if procedure == echov1connect.AuthServiceGetMeProcedure {
+		return true
+	}
+	for _, prefix := range accountServices {
+		if strings.HasPrefix(procedure, prefix) {
+			return false
+		}
+	}
+
+	method := procedure[strings.LastIndex(procedure, "/")+1:]
+	read := strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List")
+	switch s {
+	case APIKeyScopeFull:
+		return true
+	case APIKeyScopeImportOnly:
+		_, ok := importProcedures[procedure]
+		return read || ok
+	case APIKeyScopeReadOnly:
+		return read
+	default:
+		return false
+	}
+}
+
+func (s APIKeyScope) valid() bool {
+	return s == APIKeyScopeReadOnly || s == APIKeyScopeImportOnly || s == APIKeyScopeFull
+}
+
+// CreateAPIKeyParams describes a new API key
+type CreateAPIKeyParams struct {
+	Name      string
+	Scope     APIKeyScope
+	ExpiresAt *time.Time // Nil never expires
+}
+
+// CreatedAPIKey is a new key along with its plaintext, which is shown once
+type CreatedAPIKey struct {
+	Key       *repository.APIKey
+	Plaintext string
+}
+
+// APIKeyService issues, lists, revokes and verifies API keys
+type APIKeyService struct {
+	repo   repository.APIKeyRepository
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+var _ interceptors.APIKeyVerifier = (*APIKeyService)(nil)
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo repository.APIKeyRepository, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{repo: repo, logger: logger, now: time.Now}
+}
+
+// CreateAPIKey issues a key for the user. The plaintext is only returned here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID uuid.UUID, params CreateAPIKeyParams) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len([]rune(name)) > maxAPIKeyNameLen {
+		return nil, ErrInvalidAPIKeyName
+	}
+	if !params.Scope.valid() {
+		return nil, ErrInvalidAPIKeyScope
+	}
+	now := s.now()
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(now) {
+		return nil, errors.New("api key expiry must be in the future")
+	}
+
+	active, err := s.repo.CountActiveAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxAPIKeysPerUser {
+		return nil, ErrTooManyAPIKeys
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plaintext := interceptors.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := &repository.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plaintext[:apiKeyPrefixLen],
+		KeyHash:   hashToken(plaintext),
+		Scope:     string(params.Scope),
+		CreatedAt: now,
+		ExpiresAt: params.ExpiresAt,
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{Key: key, Plaintext: plaintext}, nil
+}
+
+// ListAPIKeys returns the user's keys, newest first
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*repository.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey revokes one of the user's keys; it stops working at once
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	return s.repo.RevokeAPIKey(ctx, userID, keyID, s.now())
+}
+
+// VerifyAPIKey implements interceptors.APIKeyVerifier. Keys that are unknown,
+// revoked, expired or belong to a deactivated user are rejected, and calls
+// outside the key's scope fail with interceptors.ErrAPIKeyScope.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, plaintext, procedure, clientIP string) (*interceptors.Claims, error) {
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashToken(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, common.ErrInvalidToken
+	}
+	if !key.IsActive {
+		return nil, ErrAccountInactive
+	}
+	scope := APIKeyScope(key.Scope)
+	if !scope.Allows(procedure) {
+		return nil, fmt.Errorf("%w: %s key calling %s", interceptors.ErrAPIKeyScope, scope, procedure)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now, clientIP); err != nil {
+			s.logger.WarnContext(ctx, "failed to record api key use", slog.Any("error", err))
+		}
+	}
+
+	return &interceptors.Claims{
+		UserID:   key.UserID.String(),
+		Email:    key.Email,
+		Username: key.Username,
+		Role:     key.Role,
+		Scope:    key.Scope,
+	}, nil
+}
+
+// ============================================================================
+// API Keys (Internal Integration)
+// ============================================================================
+// Keys are accepted by the auth interceptor as "Bearer echo_pat_..." tokens.
+// Managing them requires proto definitions on the auth service:
+//
+// - CreateAPIKey: issue a named key with a scope and optional expiry; returns the plaintext once
+// - ListAPIKeys: the user's keys with prefix, scope, last used time and IP, and revocation
+// - RevokeAPIKey: revoke a key by ID
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateAPIKeyRequest/Response (AuthService.CreateAPIKey)
+// - ListAPIKeysRequest/Response (AuthService.ListAPIKeys)
+// - RevokeAPIKeyRequest/Response (AuthService.RevokeAPIKey)
+// - APIKey (id, name, prefix, scope, created_at, expires_at, last_used_at, last_used_ip, revoked_at)
+// - APIKeyScope enum (READ_ONLY, IMPORT_ONLY, FULL)
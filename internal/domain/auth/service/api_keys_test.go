@@ -0,0 +1,180 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/common"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// fakeAPIKeyRepo keeps keys in memory, all owned by active members
+type fakeAPIKeyRepo struct {
+	keys    map[string]*repository.APIKey
+	touches int
+}
+
+func newFakeAPIKeyRepo() *fakeAPIKeyRepo {
+	return &fakeAPIKeyRepo{keys: map[string]*repository.APIKey{}}
+}
+
+func (r *fakeAPIKeyRepo) CreateAPIKey(_ context.Context, key *repository.APIKey) error {
+	r.keys[key.KeyHash] = key
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) ListAPIKeys(_ context.Context, userID uuid.UUID) ([]*repository.APIKey, error) {
+	var keys []*repository.APIKey
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepo) CountActiveAPIKeys(ctx context.Context, userID uuid.UUID) (int, error) {
+	keys, _ := r.ListAPIKeys(ctx, userID)
+	n := 0
+	for _, k := range keys {
+		if k.RevokedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeAPIKeyRepo) GetAPIKeyByHash(_ context.Context, keyHash string) (*repository.APIKeyOwner, error) {
+	k, ok := r.keys[keyHash]
+	if !ok {
+		return nil, common.ErrInvalidToken
+	}
+	return &repository.APIKeyOwner{APIKey: *k, Email: "script@example.com", Role: "member", IsActive: true}, nil
+}
+
+func (r *fakeAPIKeyRepo) RevokeAPIKey(_ context.Context, userID, keyID uuid.UUID, at time.Time) error {
+	for _, k := range r.keys {
+		if k.ID == keyID && k.UserID == userID {
+			k.RevokedAt = &at
+			return nil
+		}
+	}
+	return common.ErrAPIKeyNotFound
+}
+
+func (r *fakeAPIKeyRepo) TouchAPIKey(_ context.Context, keyID uuid.UUID, at time.Time, ip string) error {
+	for _, k := range r.keys {
+		if k.ID == keyID {
+			k.LastUsedAt, k.LastUsedIP = &at, &ip
+			r.touches++
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyService_CreateVerifyRevoke(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeAPIKeyRepo()
+	svc := service.NewAPIKeyService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	userID := uuid.New()
+
+	created, err := svc.CreateAPIKey(ctx, userID, service.CreateAPIKeyParams{Name: "bank sync", Scope: service.APIKeyScopeImportOnly})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if !strings.HasPrefix(created.Plaintext, interceptors.APIKeyPrefix) || !strings.HasPrefix(created.Plaintext, created.Key.Prefix) {
+		t.Fatalf("unexpected key %q with prefix %q", created.Plaintext, created.Key.Prefix)
+	}
+	if created.Key.KeyHash == created.Plaintext || strings.Contains(created.Key.KeyHash, created.Key.Prefix) {
+		t.Fatalf("expected only a hash of the key to be stored")
+	}
+
+	claims, err := svc.VerifyAPIKey(ctx, created.Plaintext, echov1connect.FinanceServiceImportTransactionsCsvProcedure, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("VerifyAPIKey() error = %v", err)
+	}
+	if claims.UserID != userID.String() || claims.Scope != string(service.APIKeyScopeImportOnly) {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+	if created.Key.LastUsedIP == nil || *created.Key.LastUsedIP != "203.0.113.7" {
+		t.Fatalf("expected last use to be recorded")
+	}
+	if _, err := svc.VerifyAPIKey(ctx, created.Plaintext, echov1connect.FinanceServiceListTransactionsProcedure, ""); err != nil {
+		t.Fatalf("expected reads to be allowed, got %v", err)
+	}
+	if repo.touches != 1 {
+		t.Fatalf("expected last use to be throttled, got %d writes", repo.touches)
+	}
+
+	_, err = svc.VerifyAPIKey(ctx, created.Plaintext, echov1connect.PlanServiceDeletePlanProcedure, "")
+	if !errors.Is(err, interceptors.ErrAPIKeyScope) {
+		t.Fatalf("expected scope error, got %v", err)
+	}
+	if _, err := svc.VerifyAPIKey(ctx, created.Plaintext+"x", echov1connect.FinanceServiceListTransactionsProcedure, ""); err == nil {
+		t.Fatalf("expected unknown key to be rejected")
+	}
+
+	if err := svc.RevokeAPIKey(ctx, uuid.New(), created.Key.ID); !errors.Is(err, common.ErrAPIKeyNotFound) {
+		t.Fatalf("expected other users not to revoke the key, got %v", err)
+	}
+	if err := svc.RevokeAPIKey(ctx, userID, created.Key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, err := svc.VerifyAPIKey(ctx, created.Plaintext, echov1connect.FinanceServiceListTransactionsProcedure, ""); !errors.Is(err, common.ErrInvalidToken) {
+		t.Fatalf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestAPIKeyScope_Allows(t *testing.T) {
+	tests := []struct {
+		scope     service.APIKeyScope
+		procedure string
+		want      bool
+	}{
+		{service.APIKeyScopeReadOnly, echov1connect.FinanceServiceListTransactionsProcedure, true},
+		{service.APIKeyScopeReadOnly, echov1connect.FinanceServiceImportTransactionsCsvProcedure, false},
+		{service.APIKeyScopeImportOnly, echov1connect.ImportServiceUploadUserFileProcedure, true},
+		{service.APIKeyScopeImportOnly, echov1connect.FinanceServiceDeleteGoalProcedure, false},
+		{service.APIKeyScopeFull, echov1connect.PlanServiceDeletePlanProcedure, true},
+		{service.APIKeyScopeFull, echov1connect.AuthServiceLogoutProcedure, false},
+		{service.APIKeyScopeFull, echov1connect.AdminUserServiceSetUserRolesProcedure, false},
+		{service.APIKeyScopeReadOnly, echov1connect.AuthServiceGetMeProcedure, true},
+		{service.APIKeyScope("admin"), echov1connect.FinanceServiceListTransactionsProcedure, false},
+	}
+	for _, tt := range tests {
+		if got := tt.scope.Allows(tt.procedure); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.scope, tt.procedure, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKeyService_CreateAPIKey_Validates(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewAPIKeyService(newFakeAPIKeyRepo(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	userID := uuid.New()
+
+	if _, err := svc.CreateAPIKey(ctx, userID, service.CreateAPIKeyParams{Name: "x", Scope: "admin"}); !errors.Is(err, service.ErrInvalidAPIKeyScope) {
+		t.Fatalf("expected invalid scope, got %v", err)
+	}
+	if _, err := svc.CreateAPIKey(ctx, userID, service.CreateAPIKeyParams{Name: " ", Scope: service.APIKeyScopeFull}); !errors.Is(err, service.ErrInvalidAPIKeyName) {
+		t.Fatalf("expected invalid name, got %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := svc.CreateAPIKey(ctx, userID, service.CreateAPIKeyParams{Name: "key", Scope: service.APIKeyScopeReadOnly}); err != nil {
+			t.Fatalf("CreateAPIKey() error = %v", err)
+		}
+	}
+	if _, err := svc.CreateAPIKey(ctx, userID, service.CreateAPIKeyParams{Name: "key", Scope: service.APIKeyScopeReadOnly}); !errors.Is(err, service.ErrTooManyAPIKeys) {
+		t.Fatalf("expected key limit, got %v", err)
+	}
+}
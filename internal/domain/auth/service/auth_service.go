@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,8 +31,9 @@ var ErrAccountInactive = errors.New("account is deactivated")
 
 // SessionMetadata captures client information useful for audit trails.
 type SessionMetadata struct {
-	UserAgent string
-	ClientIP  string
+	UserAgent  string
+	ClientIP   string
+	DeviceName string // Optional name the client gives itself, e.g. "Jane's iPhone"
 }
 
 // RegisterParams contains the required data for user registration.
@@ -80,6 +83,10 @@ type AuthService struct {
 	emailService EmailSender
 	sessionTTL   time.Duration
 	logger       *slog.Logger
+
+	// sessionChecks caches ValidateSession results by access token ID
+	sessionChecks    sync.Map
+	sessionSweptUnix atomic.Int64
 }
 
 // NewAuthService constructs a new AuthService.
@@ -130,7 +137,7 @@ func (s *AuthService) RegisterUser(ctx context.Context, params RegisterParams) (
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	if err := s.createSession(ctx, user.ID, tokens, params.Metadata); err != nil {
 		return nil, err
 	}
 
@@ -165,7 +172,7 @@ func (s *AuthService) Login(ctx context.Context, params LoginParams) (*LoginResu
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	if err := s.createSession(ctx, user.ID, tokens, params.Metadata); err != nil {
 		return nil, err
 	}
 
@@ -192,7 +199,9 @@ func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	return nil
 }
 
-// RefreshTokens validates the refresh token and issues a new pair.
+// RefreshTokens validates the refresh token and issues a new pair, rotating
+// the session's refresh token. Presenting a refresh token that was already
+// rotated out means it was copied, so the whole session is revoked.
 func (s *AuthService) RefreshTokens(ctx context.Context, params RefreshTokenParams) (*TokenPair, error) {
 	claims, err := s.tokenManager.ValidateRefreshToken(params.RefreshToken)
 	if err != nil {
@@ -200,7 +209,12 @@ func (s *AuthService) RefreshTokens(ctx context.Context, params RefreshTokenPara
 	}
 
 	hashedToken := hashToken(params.RefreshToken)
-	if _, err := s.repo.GetUserSessionByToken(ctx, hashedToken); err != nil {
+	session, err := s.repo.GetUserSessionByToken(ctx, hashedToken)
+	if errors.Is(err, common.ErrSessionNotFound) {
+		s.revokeReusedSession(ctx, hashedToken)
+		return nil, err
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -218,14 +232,15 @@ func (s *AuthService) RefreshTokens(ctx context.Context, params RefreshTokenPara
 		return nil, ErrAccountInactive
 	}
 
-	_ = s.repo.DeleteUserSession(ctx, hashedToken)
-
 	tokens, err := s.tokenManager.GenerateTokenPair(user.ID.String(), user.Email, user.Username, user.Role)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, params.Metadata); err != nil {
+	// Losing a race with a concurrent refresh of the same token isn't reuse:
+	// the winner's client holds the new token, so the session stays
+	if err := s.repo.RotateUserSession(ctx, session.ID, hashedToken, hashToken(tokens.RefreshToken), s.accessTokenID(tokens.AccessToken),
+		params.Metadata.ClientIP, time.Now().Add(s.sessionTTL)); err != nil {
 		return nil, err
 	}
 
@@ -394,7 +409,7 @@ func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string)
 	return &ResendVerificationResult{}, nil
 }
 
-func (s *AuthService) createSession(ctx context.Context, userID uuid.UUID, refreshToken string, meta SessionMetadata) error {
+func (s *AuthService) createSession(ctx context.Context, userID uuid.UUID, tokens *TokenPair, meta SessionMetadata) error {
 	userAgent := meta.UserAgent
 	if userAgent == "" {
 		userAgent = "unknown"
@@ -404,8 +419,20 @@ func (s *AuthService) createSession(ctx context.Context, userID uuid.UUID, refre
 		clientIP = "unknown"
 	}
 
-	_, err := s.repo.CreateUserSession(ctx, userID, hashToken(refreshToken), userAgent, clientIP, time.Now().Add(s.sessionTTL))
-	return err
+	session, err := s.repo.CreateUserSession(ctx, userID, hashToken(tokens.RefreshToken), userAgent, clientIP, time.Now().Add(s.sessionTTL))
+	if err != nil {
+		return err
+	}
+	return s.repo.TrackUserSession(ctx, session.ID, s.accessTokenID(tokens.AccessToken), meta.DeviceName)
+}
+
+// accessTokenID returns the jti an access token is tracked by in its session
+func (s *AuthService) accessTokenID(accessToken string) string {
+	claims, err := s.tokenManager.ValidateAccessToken(accessToken)
+	if err != nil {
+		return ""
+	}
+	return claims.ID
 }
 
 func (s *AuthService) sendEmailVerification(ctx context.Context, user *repository.User) error {
@@ -433,16 +460,23 @@ func hashToken(token string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-// LoginOrRegisterOAuth handles OAuth authentication - finds existing user or creates new one
-func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, provider string, gothUser *goth.User, meta SessionMetadata) (*LoginResult, bool, error) {
+// LoginOrRegisterOAuth handles OAuth authentication - finds existing user or creates new one.
+// A provider identity is linked to an existing account with the same email
+// only when the provider has verified that email.
+func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, provider string, gothUser *goth.User, emailVerified bool, meta SessionMetadata) (*LoginResult, bool, error) {
 	isNewUser := false
 
 	// Try to find existing user by OAuth identity
 	user, err := s.repo.GetUserByOAuthIdentity(ctx, provider, gothUser.UserID)
 	if errors.Is(err, common.ErrUserNotFound) {
+		if gothUser.Email == "" {
+			return nil, false, ErrOAuthEmailRequired
+		}
+
 		// No OAuth identity found, check if user exists by email
 		user, err = s.repo.GetUserByEmail(ctx, gothUser.Email)
-		if errors.Is(err, common.ErrUserNotFound) {
+		switch {
+		case errors.Is(err, common.ErrUserNotFound):
 			// Create new user
 			username := generateUsername(gothUser.NickName, gothUser.Email)
 			displayName := gothUser.Name
@@ -455,20 +489,24 @@ func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, provider string,
 				return nil, false, fmt.Errorf("failed to create user: %w", err)
 			}
 			isNewUser = true
-		} else if err != nil {
+			if emailVerified {
+				if err := s.repo.VerifyEmail(ctx, user.ID); err != nil {
+					return nil, false, fmt.Errorf("failed to verify email: %w", err)
+				}
+			}
+		case err != nil:
 			return nil, false, err
-		}
-
-		// Link OAuth identity to user
-		var accessToken, refreshToken *string
-		if gothUser.AccessToken != "" {
-			accessToken = &gothUser.AccessToken
-		}
-		if gothUser.RefreshToken != "" {
-			refreshToken = &gothUser.RefreshToken
-		}
-		if err := s.repo.CreateOrUpdateOAuthIdentity(ctx, provider, gothUser.UserID, user.ID, accessToken, refreshToken); err != nil {
-			return nil, false, fmt.Errorf("failed to link OAuth identity: %w", err)
+		case !emailVerified:
+			return nil, false, ErrOAuthEmailUnverified
+		case user.EmailVerifiedAt == nil:
+			// The provider proved the email is theirs; whoever registered it
+			// without verifying it loses their sessions
+			if err := s.repo.VerifyEmail(ctx, user.ID); err != nil {
+				return nil, false, fmt.Errorf("failed to verify email: %w", err)
+			}
+			if err := s.repo.DeleteAllUserSessions(ctx, user.ID); err != nil {
+				return nil, false, fmt.Errorf("failed to revoke sessions: %w", err)
+			}
 		}
 	} else if err != nil {
 		return nil, false, err
@@ -478,13 +516,25 @@ func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, provider string,
 		return nil, false, ErrAccountInactive
 	}
 
+	// Link the identity, or record the sign-in for one already linked
+	var accessToken, refreshToken *string
+	if gothUser.AccessToken != "" {
+		accessToken = &gothUser.AccessToken
+	}
+	if gothUser.RefreshToken != "" {
+		refreshToken = &gothUser.RefreshToken
+	}
+	if err := s.repo.CreateOrUpdateOAuthIdentity(ctx, provider, gothUser.UserID, user.ID, gothUser.Email, accessToken, refreshToken); err != nil {
+		return nil, false, fmt.Errorf("failed to link OAuth identity: %w", err)
+	}
+
 	// Generate tokens
 	tokens, err := s.tokenManager.GenerateTokenPair(user.ID.String(), user.Email, user.Username, user.Role)
 	if err != nil {
 		return nil, false, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, meta); err != nil {
+	if err := s.createSession(ctx, user.ID, tokens, meta); err != nil {
 		return nil, false, err
 	}
 
@@ -493,6 +543,11 @@ func (s *AuthService) LoginOrRegisterOAuth(ctx context.Context, provider string,
 	return &LoginResult{User: user, Tokens: tokens}, isNewUser, nil
 }
 
+// ListOAuthIdentities returns the sign-in providers linked to the user
+func (s *AuthService) ListOAuthIdentities(ctx context.Context, userID uuid.UUID) ([]repository.OAuthIdentity, error) {
+	return s.repo.ListOAuthIdentities(ctx, userID)
+}
+
 // LoginOrRegisterPhone handles phone authentication - finds existing user or creates new one
 func (s *AuthService) LoginOrRegisterPhone(ctx context.Context, phone string, meta SessionMetadata) (*LoginResult, bool, error) {
 	isNewUser := false
@@ -521,7 +576,7 @@ func (s *AuthService) LoginOrRegisterPhone(ctx context.Context, phone string, me
 		return nil, false, err
 	}
 
-	if err := s.createSession(ctx, user.ID, tokens.RefreshToken, meta); err != nil {
+	if err := s.createSession(ctx, user.ID, tokens, meta); err != nil {
 		return nil, false, err
 	}
 
@@ -5,6 +5,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -364,6 +369,93 @@ func TestAuthService_RefreshTokens_Success(t *testing.T) {
 	}
 }
 
+// racingSessionRepo holds every refresh after its session lookup until all
+// have looked it up, so they race to rotate the same token
+type racingSessionRepo struct {
+	*servicetest.MockAuthRepo
+	lookups sync.WaitGroup
+}
+
+func (r *racingSessionRepo) GetUserSessionByToken(ctx context.Context, hashedToken string) (*repository.UserSession, error) {
+	session, err := r.MockAuthRepo.GetUserSessionByToken(ctx, hashedToken)
+	r.lookups.Done()
+	r.lookups.Wait()
+	return session, err
+}
+
+func TestAuthService_RefreshTokens_ConcurrentSameToken(t *testing.T) {
+	ctx := context.Background()
+	const refreshes = 2
+	repo := &racingSessionRepo{MockAuthRepo: servicetest.NewMockAuthRepo()}
+	repo.lookups.Add(refreshes)
+	tokens := &servicetest.MockTokenManager{}
+	svc := service.NewAuthService(repo, tokens, &servicetest.MockEmailSender{}, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour)
+
+	user, err := repo.CreateUser(ctx, "jane@example.com", "jane", "hashed", "Jane")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	user.IsActive = true
+	repo.Users[user.Email] = user
+	session := &repository.UserSession{
+		ID:                 uuid.New(),
+		UserID:             user.ID,
+		HashedRefreshToken: hashTestToken("refresh-token"),
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+	repo.Sessions[session.HashedRefreshToken] = session
+
+	tokens.RefreshFunc = func(string) (*service.Claims, error) {
+		return &service.Claims{UserID: user.ID.String()}, nil
+	}
+	var issued atomic.Int64
+	tokens.GenerateFunc = func(_, _, _, _ string) (*service.TokenPair, error) {
+		n := issued.Add(1)
+		return &service.TokenPair{
+			AccessToken:  fmt.Sprintf("access-%d", n),
+			RefreshToken: fmt.Sprintf("refresh-%d", n),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	results := make([]*service.TokenPair, refreshes)
+	errs := make([]error, refreshes)
+	var wg sync.WaitGroup
+	for i := range refreshes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: "refresh-token"})
+		}()
+	}
+	wg.Wait()
+
+	var winner *service.TokenPair
+	for i := range refreshes {
+		switch {
+		case errs[i] == nil:
+			if winner != nil {
+				t.Fatalf("expected only one refresh to rotate the token")
+			}
+			winner = results[i]
+		case !errors.Is(errs[i], common.ErrSessionNotFound):
+			t.Fatalf("expected the losing refresh to get ErrSessionNotFound, got %v", errs[i])
+		}
+	}
+	if winner == nil {
+		t.Fatalf("expected one refresh to succeed, got %v", errs)
+	}
+	if repo.Revoked[session.ID] {
+		t.Fatalf("losing a refresh race must not revoke the session")
+	}
+
+	// The winner's token keeps working
+	repo.lookups.Add(1)
+	if _, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: winner.RefreshToken}); err != nil {
+		t.Fatalf("expected the winning token to refresh, got %v", err)
+	}
+}
+
 func TestAuthService_RequestPasswordReset(t *testing.T) {
 	ctx := context.Background()
 	svc, repo, _, email := servicetest.NewTestAuthService()
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
 )
@@ -11,6 +12,7 @@ type EmailSender interface {
 	SendVerificationEmail(toEmail, toName, token string) error
 	SendPasswordResetEmail(toEmail, toName, token string) error
 	SendWelcomeEmail(toEmail, toName string) error
+	SendNotificationEmail(toEmail, toName, subject, message, trackingURL string) error
 }
 
 type smtpEmailService struct {
@@ -135,6 +137,39 @@ func (s *smtpEmailService) SendWelcomeEmail(toEmail, toName string) error {
 	return s.sendEmail(toEmail, subject, body)
 }
 
+// SendNotificationEmail sends an inbox notification by email. trackingURL, when
+// set, is embedded as a pixel so opens can be recorded.
+func (s *smtpEmailService) SendNotificationEmail(toEmail, toName, subject, message, trackingURL string) error {
+	pixel := ""
+	if trackingURL != "" {
+		pixel = fmt.Sprintf(`<img src="%s" width="1" height="1" alt="" style="display:none;">`, html.EscapeString(trackingURL))
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <div style="background-color: #f8f9fa; border-radius: 10px; padding: 30px;">
+        <h1 style="color: #4a5568; margin-bottom: 20px;">%s</h1>
+        <p>Hi %s,</p>
+        <p>%s</p>
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="%s/dashboard" style="background-color: #4a5568; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">Open echo</a>
+        </div>
+        <p>The echo Team</p>
+    </div>
+    %s
+</body>
+</html>
+	`, html.EscapeString(subject), html.EscapeString(toName), html.EscapeString(message), s.frontendURL, pixel)
+
+	return s.sendEmail(toEmail, subject, body)
+}
+
 // sendEmail is a helper function to send emails via SMTP
 func (s *smtpEmailService) sendEmail(to, subject, body string) error {
 	// If SMTP is not configured, log and skip (for development)
@@ -1,81 +1,294 @@
 package service
 
 import (
-	"os"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gorilla/sessions"
 	"github.com/markbates/goth"
-	"github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/apple"
 	"github.com/markbates/goth/providers/google"
 )
 
+const (
+	// OAuthLoginPath prefixes the sign-in routes: {provider}/begin starts a
+	// login and {provider}/callback is where the provider sends users back
+	OAuthLoginPath = "/auth/oauth/"
+
+	// OAuthProviderGoogle and OAuthProviderApple are the supported providers
+	OAuthProviderGoogle = "google"
+	OAuthProviderApple  = "apple"
+
+	// oauthLoginTTL bounds how long a user has to finish signing in
+	oauthLoginTTL = 15 * time.Minute
+	// appleSecretTTL is the lifetime of the client secrets signed for Apple
+	appleSecretTTL = 5 * time.Minute
+)
+
+var (
+	// ErrOAuthProviderUnavailable is returned for a provider that isn't configured
+	ErrOAuthProviderUnavailable = errors.New("oauth provider is not available")
+	// ErrInvalidOAuthLoginState is returned for a tampered, expired or foreign state
+	ErrInvalidOAuthLoginState = errors.New("invalid or expired oauth login")
+	// ErrOAuthRedirectNotAllowed is returned for a redirect_uri that isn't allowed
+	ErrOAuthRedirectNotAllowed = errors.New("redirect uri is not allowed")
+	// ErrOAuthEmailRequired is returned when the provider shares no email
+	ErrOAuthEmailRequired = errors.New("oauth provider did not share an email address")
+	// ErrOAuthEmailUnverified is returned when a provider's unverified email
+	// matches an existing account, which can't be linked without a password login
+	ErrOAuthEmailUnverified = errors.New("an account with this email already exists; sign in with your password")
+)
+
 // OAuthConfig holds OAuth configuration
 type OAuthConfig struct {
 	GoogleClientID     string
 	GoogleClientSecret string
 	AppleClientID      string
-	AppleSecret        string
 	AppleTeamID        string
 	AppleKeyID         string
-	CallbackURL        string
-	SessionSecret      string
+	ApplePrivateKey    string   // PKCS#8 PEM used to sign Apple client secrets
+	CallbackURL        string   // Base URL of the callbacks, e.g. https://api.example.com/auth/oauth
+	RedirectURLs       []string // App URLs a login may return tokens to
 }
 
-// InitOAuth initializes OAuth providers (Google and Apple only)
-func InitOAuth(config OAuthConfig) error {
-	// Set up session store for gothic
-	store := sessions.NewCookieStore([]byte(config.SessionSecret))
-	store.Options.HttpOnly = true
-	store.Options.Secure = true // Set to true in production with HTTPS
-	gothic.Store = store
+// OAuthLoginResult is produced after a successful OAuth sign-in
+type OAuthLoginResult struct {
+	LoginResult
+	Provider    string
+	NewUser     bool
+	RedirectURI string // Where to hand the tokens to; empty to return them directly
+}
 
-	// Initialize providers
-	providers := []goth.Provider{}
+// OAuthLoginService runs the sign-in flow with Google and Apple. The state
+// sent to the provider is signed and carries a nonce the caller must keep
+// (in a cookie) and present when completing the login.
+type OAuthLoginService struct {
+	auth         *AuthService
+	providers    map[string]func() (goth.Provider, error)
+	redirectURLs []string
+	stateSecret  []byte
+	now          func() time.Time
+}
+
+// NewOAuthLoginService creates a new OAuth sign-in service with the providers
+// the config has credentials for
+func NewOAuthLoginService(auth *AuthService, config OAuthConfig, stateSecret []byte) *OAuthLoginService {
+	s := &OAuthLoginService{
+		auth:         auth,
+		providers:    make(map[string]func() (goth.Provider, error)),
+		redirectURLs: config.RedirectURLs,
+		stateSecret:  stateSecret,
+		now:          time.Now,
+	}
+	callback := strings.TrimSuffix(config.CallbackURL, "/")
 
-	// Google OAuth
 	if config.GoogleClientID != "" && config.GoogleClientSecret != "" {
-		providers = append(providers,
-			google.New(
-				config.GoogleClientID,
-				config.GoogleClientSecret,
-				config.CallbackURL+"/google/callback",
-				"email", "profile",
-			),
-		)
-	}
-
-	// Apple OAuth
-	if config.AppleClientID != "" && config.AppleSecret != "" {
-		// Apple requires additional configuration
-		// The secret is typically a JWT signed with your private key
-		providers = append(providers,
-			apple.New(
-				config.AppleClientID,
-				config.AppleSecret,
-				config.CallbackURL+"/apple/callback",
-				nil, // Optional client secret (use nil if using JWT)
-				apple.ScopeName,
-				apple.ScopeEmail,
-			),
-		)
-	}
-
-	goth.UseProviders(providers...)
-
-	return nil
+		provider := google.New(config.GoogleClientID, config.GoogleClientSecret,
+			callback+"/"+OAuthProviderGoogle+"/callback", "email", "profile")
+		s.WithProvider(OAuthProviderGoogle, provider)
+	}
+
+	// Apple client secrets are short-lived JWTs, so each login signs a new one
+	if config.AppleClientID != "" && config.ApplePrivateKey != "" {
+		s.providers[OAuthProviderApple] = func() (goth.Provider, error) {
+			now := s.now()
+			secret, err := apple.MakeSecret(apple.SecretParams{
+				PKCS8PrivateKey: config.ApplePrivateKey,
+				TeamId:          config.AppleTeamID,
+				KeyId:           config.AppleKeyID,
+				ClientId:        config.AppleClientID,
+				Iat:             int(now.Unix()),
+				Exp:             int(now.Add(appleSecretTTL).Unix()),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign apple client secret: %w", err)
+			}
+			return apple.New(config.AppleClientID, *secret, callback+"/"+OAuthProviderApple+"/callback",
+				nil, apple.ScopeName, apple.ScopeEmail), nil
+		}
+	}
+	return s
+}
+
+// WithProvider registers a provider under name, replacing any configured one
+func (s *OAuthLoginService) WithProvider(name string, provider goth.Provider) *OAuthLoginService {
+	s.providers[name] = func() (goth.Provider, error) { return provider, nil }
+	return s
 }
 
-// LoadOAuthConfigFromEnv loads OAuth config from environment variables
-func LoadOAuthConfigFromEnv() OAuthConfig {
-	return OAuthConfig{
-		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		AppleClientID:      os.Getenv("APPLE_CLIENT_ID"),
-		AppleSecret:        os.Getenv("APPLE_SECRET"),
-		AppleTeamID:        os.Getenv("APPLE_TEAM_ID"),
-		AppleKeyID:         os.Getenv("APPLE_KEY_ID"),
-		CallbackURL:        os.Getenv("OAUTH_CALLBACK_URL"),
-		SessionSecret:      os.Getenv("SESSION_SECRET"),
+// Providers lists the configured provider names
+func (s *OAuthLoginService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
+
+// BeginOAuthLogin returns the provider's consent URL and the nonce the caller
+// must present to CompleteOAuthLogin. redirectURI is optional and must be one
+// of the configured redirect URLs.
+func (s *OAuthLoginService) BeginOAuthLogin(providerName, redirectURI string) (authURL, nonce string, err error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+	if redirectURI != "" && !slices.Contains(s.redirectURLs, redirectURI) {
+		return "", "", ErrOAuthRedirectNotAllowed
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", fmt.Errorf("failed to generate oauth nonce: %w", err)
+	}
+	nonce = base64.RawURLEncoding.EncodeToString(random)
+
+	session, err := provider.BeginAuth(s.signState(providerName, nonce, redirectURI, s.now().Add(oauthLoginTTL)))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to begin %s login: %w", providerName, err)
+	}
+	authURL, err = session.GetAuthURL()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build %s login url: %w", providerName, err)
+	}
+	return authURL, nonce, nil
+}
+
+// CompleteOAuthLogin exchanges the callback parameters for the user's profile
+// and signs them in, linking or creating their account as needed
+func (s *OAuthLoginService) CompleteOAuthLogin(ctx context.Context, providerName, nonce string, params url.Values, meta SessionMetadata) (*OAuthLoginResult, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	redirectURI, err := s.verifyState(params.Get("state"), providerName, nonce, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := provider.BeginAuth(params.Get("state"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume %s login: %w", providerName, err)
+	}
+	if _, err := session.Authorize(provider, params); err != nil {
+		return nil, fmt.Errorf("failed to authorize %s login: %w", providerName, err)
+	}
+	user, err := provider.FetchUser(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s profile: %w", providerName, err)
+	}
+	if user.UserID == "" {
+		return nil, fmt.Errorf("%s returned no user id", providerName)
+	}
+	if user.Name == "" {
+		user.Name = appleUserName(params.Get("user"))
+	}
+
+	login, isNew, err := s.auth.LoginOrRegisterOAuth(ctx, providerName, &user, emailVerified(session, user), meta)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthLoginResult{LoginResult: *login, Provider: providerName, NewUser: isNew, RedirectURI: redirectURI}, nil
+}
+
+func (s *OAuthLoginService) provider(name string) (goth.Provider, error) {
+	build, ok := s.providers[name]
+	if !ok {
+		return nil, ErrOAuthProviderUnavailable
+	}
+	return build()
+}
+
+// signState encodes the provider, nonce, redirect and expiry, signed so the
+// callback can trust them
+func (s *OAuthLoginService) signState(provider, nonce, redirectURI string, expires time.Time) string {
+	payload := strings.Join([]string{
+		provider, nonce, strconv.FormatInt(expires.Unix(), 10),
+		base64.RawURLEncoding.EncodeToString([]byte(redirectURI)),
+	}, ".")
+	return payload + "." + s.stateSignature(payload)
+}
+
+// verifyState checks a state was issued for this provider and nonce and
+// returns its redirect URI
+func (s *OAuthLoginService) verifyState(state, provider, nonce string, now time.Time) (string, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 5 || nonce == "" {
+		return "", ErrInvalidOAuthLoginState
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(s.stateSignature(payload))) {
+		return "", ErrInvalidOAuthLoginState
+	}
+	if parts[0] != provider || !hmac.Equal([]byte(parts[1]), []byte(nonce)) {
+		return "", ErrInvalidOAuthLoginState
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return "", ErrInvalidOAuthLoginState
+	}
+	redirectURI, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", ErrInvalidOAuthLoginState
+	}
+	return string(redirectURI), nil
+}
+
+func (s *OAuthLoginService) stateSignature(payload string) string {
+	mac := hmac.New(sha256.New, s.stateSecret)
+	mac.Write([]byte("oauth_login:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// emailVerified reports whether the provider vouches for the user's email:
+// Apple in its ID token, Google and others in the profile
+func emailVerified(session goth.Session, user goth.User) bool {
+	if s, ok := session.(*apple.Session); ok {
+		return s.ID.EmailVerified
+	}
+	for _, key := range []string{"email_verified", "verified_email"} {
+		if verified, ok := user.RawData[key].(bool); ok {
+			return verified
+		}
+	}
+	return false
+}
+
+// appleUserName reads the name Apple posts, as JSON, on a user's first login only
+func appleUserName(raw string) string {
+	var posted struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if raw == "" || json.Unmarshal([]byte(raw), &posted) != nil {
+		return ""
+	}
+	return strings.TrimSpace(posted.Name.FirstName + " " + posted.Name.LastName)
+}
+
+// ============================================================================
+// OAuth Sign-In (Internal Integration)
+// ============================================================================
+// Sign-in runs over plain HTTP redirects under OAuthLoginPath, so it needs no
+// proto changes. Listing a user's linked providers is available on the auth
+// service but requires proto definitions to be exposed:
+//
+// - ListOAuthIdentities: providers linked to the user, with the email each reported
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ListLinkedProvidersRequest/Response (AuthService.ListLinkedProviders)
+// - LinkedProvider (provider, email, linked_at, last_login_at)
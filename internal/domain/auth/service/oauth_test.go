@@ -0,0 +1,147 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/servicetest"
+)
+
+// fakeOAuthProvider signs everyone in as the same profile
+type fakeOAuthProvider struct {
+	user goth.User
+}
+
+type fakeOAuthSession struct {
+	authURL string
+	code    string
+}
+
+func (s *fakeOAuthSession) GetAuthURL() (string, error) { return s.authURL, nil }
+func (s *fakeOAuthSession) Marshal() string             { return s.authURL }
+func (s *fakeOAuthSession) Authorize(_ goth.Provider, params goth.Params) (string, error) {
+	s.code = params.Get("code")
+	if s.code == "" {
+		return "", errors.New("missing code")
+	}
+	return "access-" + s.code, nil
+}
+
+func (p *fakeOAuthProvider) Name() string   { return "fake" }
+func (p *fakeOAuthProvider) SetName(string) {}
+func (p *fakeOAuthProvider) BeginAuth(state string) (goth.Session, error) {
+	return &fakeOAuthSession{authURL: "https://provider.example/auth?state=" + url.QueryEscape(state)}, nil
+}
+func (p *fakeOAuthProvider) UnmarshalSession(data string) (goth.Session, error) {
+	return &fakeOAuthSession{authURL: data}, nil
+}
+func (p *fakeOAuthProvider) FetchUser(goth.Session) (goth.User, error) { return p.user, nil }
+func (p *fakeOAuthProvider) Debug(bool)                                {}
+func (p *fakeOAuthProvider) RefreshToken(string) (*oauth2.Token, error) {
+	return nil, errors.New("not supported")
+}
+func (p *fakeOAuthProvider) RefreshTokenAvailable() bool { return false }
+
+// beginLogin starts a login and returns the callback parameters and nonce
+func beginLogin(t *testing.T, svc *service.OAuthLoginService, redirectURI string) (url.Values, string) {
+	t.Helper()
+	authURL, nonce, err := svc.BeginOAuthLogin("fake", redirectURI)
+	if err != nil {
+		t.Fatalf("BeginOAuthLogin() error = %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid auth url %q: %v", authURL, err)
+	}
+	return url.Values{"state": {parsed.Query().Get("state")}, "code": {"abc"}}, nonce
+}
+
+func TestOAuthLogin_RegistersNewUser(t *testing.T) {
+	ctx := context.Background()
+	auth, repo, _, _ := servicetest.NewTestAuthService()
+	provider := &fakeOAuthProvider{user: goth.User{
+		UserID: "g-1", Email: "new@example.com", Name: "New User",
+		RawData: map[string]any{"email_verified": true},
+	}}
+	svc := service.NewOAuthLoginService(auth, service.OAuthConfig{RedirectURLs: []string{"echo://login"}}, []byte("secret")).
+		WithProvider("fake", provider)
+
+	if _, _, err := svc.BeginOAuthLogin("fake", "https://evil.example"); !errors.Is(err, service.ErrOAuthRedirectNotAllowed) {
+		t.Fatalf("expected redirect to be rejected, got %v", err)
+	}
+	if _, _, err := svc.BeginOAuthLogin("github", ""); !errors.Is(err, service.ErrOAuthProviderUnavailable) {
+		t.Fatalf("expected unknown provider to be rejected, got %v", err)
+	}
+
+	params, nonce := beginLogin(t, svc, "echo://login")
+	if _, err := svc.CompleteOAuthLogin(ctx, "fake", "other-nonce", params, service.SessionMetadata{}); !errors.Is(err, service.ErrInvalidOAuthLoginState) {
+		t.Fatalf("expected a foreign nonce to be rejected, got %v", err)
+	}
+
+	result, err := svc.CompleteOAuthLogin(ctx, "fake", nonce, params, service.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("CompleteOAuthLogin() error = %v", err)
+	}
+	if !result.NewUser || result.RedirectURI != "echo://login" || result.Tokens == nil {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	user, err := repo.GetUserByEmail(ctx, "new@example.com")
+	if err != nil {
+		t.Fatalf("expected user to be created: %v", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Fatalf("expected the provider-verified email to be marked verified")
+	}
+
+	// Signing in again finds the user through the identity
+	params, nonce = beginLogin(t, svc, "")
+	again, err := svc.CompleteOAuthLogin(ctx, "fake", nonce, params, service.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("CompleteOAuthLogin() error = %v", err)
+	}
+	if again.NewUser || again.User.ID != user.ID {
+		t.Fatalf("expected the existing user, got %+v", again.User)
+	}
+	identities, err := auth.ListOAuthIdentities(ctx, user.ID)
+	if err != nil || len(identities) != 1 || identities[0].LastLoginAt == nil {
+		t.Fatalf("expected one identity with a recorded login, got %+v (%v)", identities, err)
+	}
+}
+
+func TestOAuthLogin_LinksExistingAccountOnlyWithVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	auth, repo, _, _ := servicetest.NewTestAuthService()
+	existing, err := repo.CreateUser(ctx, "jane@example.com", "jane", "hash", "Jane")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := repo.CreateUserSession(ctx, existing.ID, "squatter", "", "", existing.CreatedAt.AddDate(1, 0, 0)); err != nil {
+		t.Fatalf("CreateUserSession() error = %v", err)
+	}
+	provider := &fakeOAuthProvider{user: goth.User{UserID: "a-1", Email: "jane@example.com"}}
+	svc := service.NewOAuthLoginService(auth, service.OAuthConfig{}, []byte("secret")).WithProvider("fake", provider)
+
+	params, nonce := beginLogin(t, svc, "")
+	if _, err := svc.CompleteOAuthLogin(ctx, "fake", nonce, params, service.SessionMetadata{}); !errors.Is(err, service.ErrOAuthEmailUnverified) {
+		t.Fatalf("expected unverified email not to link, got %v", err)
+	}
+
+	provider.user.RawData = map[string]any{"verified_email": true}
+	params, nonce = beginLogin(t, svc, "")
+	result, err := svc.CompleteOAuthLogin(ctx, "fake", nonce, params, service.SessionMetadata{})
+	if err != nil {
+		t.Fatalf("CompleteOAuthLogin() error = %v", err)
+	}
+	if result.NewUser || result.User.ID != existing.ID {
+		t.Fatalf("expected the provider to be linked to the existing account")
+	}
+	if _, ok := repo.Sessions["squatter"]; ok {
+		t.Fatalf("expected sessions of the unverified account to be revoked")
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/common"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+const (
+	// sessionCheckInterval is how long a session check is cached, and so how
+	// long a revoked session's access token may keep working on other servers
+	sessionCheckInterval = 30 * time.Second
+	// sessionSweepInterval is how often expired cache entries are dropped
+	sessionSweepInterval = 10 * time.Minute
+)
+
+// ErrSessionRevoked is returned for access tokens of a signed-out session
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+// Session is a signed-in device, as listed to its user
+type Session struct {
+	repository.ActiveSession
+	Current bool // Whether this is the session making the request
+}
+
+type sessionCheck struct {
+	revoked   bool
+	checkedAt time.Time
+	expiresAt time.Time
+}
+
+var _ interceptors.SessionValidator = (*AuthService)(nil)
+
+// ListActiveSessions lists the user's signed-in devices, marking the one the
+// access token with currentAccessTokenID belongs to
+func (s *AuthService) ListActiveSessions(ctx context.Context, userID uuid.UUID, currentAccessTokenID string) ([]Session, error) {
+	active, err := s.repo.ListActiveUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(active))
+	for _, a := range active {
+		current := currentAccessTokenID != "" && a.AccessTokenID != nil && *a.AccessTokenID == currentAccessTokenID
+		sessions = append(sessions, Session{ActiveSession: a, Current: current})
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's devices out: its refresh token stops
+// working at once and its access token within sessionCheckInterval
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	accessTokenID, err := s.repo.RevokeUserSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if accessTokenID != "" {
+		s.sessionChecks.Delete(accessTokenID)
+	}
+	return nil
+}
+
+// ValidateSession implements interceptors.SessionValidator. Access tokens
+// without an ID, or that no session tracks, are left to their expiry. Lookup
+// failures are logged and let the request through.
+func (s *AuthService) ValidateSession(ctx context.Context, claims *interceptors.Claims, clientIP string) error {
+	if claims.ID == "" {
+		return nil
+	}
+	now := time.Now()
+	s.sweepSessionChecks(now)
+
+	if cached, ok := s.sessionChecks.Load(claims.ID); ok {
+		if check := cached.(sessionCheck); now.Sub(check.checkedAt) < sessionCheckInterval {
+			if check.revoked {
+				return ErrSessionRevoked
+			}
+			return nil
+		}
+	}
+
+	revoked, err := s.repo.TouchSessionByAccessToken(ctx, claims.ID, clientIP, now)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to check session", slog.Any("error", err))
+		return nil
+	}
+	check := sessionCheck{revoked: revoked, checkedAt: now, expiresAt: now.Add(sessionCheckInterval)}
+	if claims.ExpiresAt != nil {
+		check.expiresAt = claims.ExpiresAt.Time
+	}
+	s.sessionChecks.Store(claims.ID, check)
+
+	if revoked {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// sweepSessionChecks drops cached checks of expired access tokens
+func (s *AuthService) sweepSessionChecks(now time.Time) {
+	last := s.sessionSweptUnix.Load()
+	if now.Unix()-last < int64(sessionSweepInterval.Seconds()) || !s.sessionSweptUnix.CompareAndSwap(last, now.Unix()) {
+		return
+	}
+	s.sessionChecks.Range(func(key, value any) bool {
+		if value.(sessionCheck).expiresAt.Before(now) {
+			s.sessionChecks.Delete(key)
+		}
+		return true
+	})
+}
+
+// revokeReusedSession revokes the session a refresh token was rotated out
+// of, if any: the token was used twice, so someone else has a copy
+func (s *AuthService) revokeReusedSession(ctx context.Context, hashedToken string) {
+	session, err := s.repo.GetUserSessionByPreviousToken(ctx, hashedToken)
+	if errors.Is(err, common.ErrSessionNotFound) {
+		return
+	}
+	if err == nil {
+		err = s.RevokeSession(ctx, session.UserID, session.ID)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to revoke session after refresh token reuse", slog.Any("error", err))
+		return
+	}
+	s.logger.WarnContext(ctx, "refresh token reused; session revoked",
+		slog.String("user_id", session.UserID.String()), slog.String("session_id", session.ID.String()))
+}
+
+// ============================================================================
+// Session Management (Internal Integration)
+// ============================================================================
+// Sessions are tracked per device on login and rotated on refresh; the auth
+// interceptor rejects access tokens of revoked sessions via ValidateSession.
+// Listing and revoking them requires proto definitions on the auth service:
+//
+// - ListActiveSessions: the user's signed-in devices with IP and last seen time
+// - RevokeSession: sign a device out by session ID
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ListActiveSessionsRequest/Response (AuthService.ListActiveSessions)
+// - RevokeSessionRequest/Response (AuthService.RevokeSession)
+// - ActiveSession (id, device_name, user_agent, ip, created_at, last_seen_at, expires_at, current)
@@ -0,0 +1,115 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/common"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/service"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/servicetest"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+func TestAuthService_SessionManagement(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, tokens, _ := servicetest.NewTestAuthService()
+	user := servicetest.AddUser(repo, t, "devices@example.com", true, servicetest.MustHash(t, "Str0ng!Pass"))
+
+	// Each pair gets unique tokens; access tokens are their own jti
+	issued := 0
+	tokens.GenerateFunc = func(_, _, _, _ string) (*service.TokenPair, error) {
+		issued++
+		return &service.TokenPair{
+			AccessToken:  fmt.Sprintf("access-%d", issued),
+			RefreshToken: fmt.Sprintf("refresh-%d", issued),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil
+	}
+	tokens.AccessFunc = func(token string) (*service.Claims, error) {
+		return &service.Claims{UserID: user.ID.String(), RegisteredClaims: jwt.RegisteredClaims{ID: token}}, nil
+	}
+	tokens.RefreshFunc = func(string) (*service.Claims, error) {
+		return &service.Claims{UserID: user.ID.String()}, nil
+	}
+	login := func(device string) *service.TokenPair {
+		t.Helper()
+		res, err := svc.Login(ctx, service.LoginParams{
+			Email: user.Email, Password: "Str0ng!Pass",
+			Metadata: service.SessionMetadata{DeviceName: device, ClientIP: "198.51.100.1"},
+		})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		return res.Tokens
+	}
+
+	phone := login("Phone")
+	laptop := login("Laptop")
+
+	sessions, err := svc.ListActiveSessions(ctx, user.ID, laptop.AccessToken)
+	if err != nil {
+		t.Fatalf("ListActiveSessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	var phoneSession service.Session
+	for _, s := range sessions {
+		if *s.DeviceName == "Phone" {
+			phoneSession = s
+		}
+		if s.Current != (*s.DeviceName == "Laptop") {
+			t.Fatalf("expected only the laptop to be current, got %+v", s)
+		}
+	}
+
+	// Refreshing rotates the token within the same session
+	rotated, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: phone.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshTokens: %v", err)
+	}
+	sessions, _ = svc.ListActiveSessions(ctx, user.ID, rotated.AccessToken)
+	for _, s := range sessions {
+		if s.ID == phoneSession.ID && !s.Current {
+			t.Fatalf("expected the rotated token to stay on the phone's session")
+		}
+	}
+
+	// Replaying the old refresh token signs the phone out
+	if _, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: phone.RefreshToken}); !errors.Is(err, common.ErrSessionNotFound) {
+		t.Fatalf("expected reused refresh token to fail, got %v", err)
+	}
+	if _, err := svc.RefreshTokens(ctx, service.RefreshTokenParams{RefreshToken: rotated.RefreshToken}); !errors.Is(err, common.ErrSessionNotFound) {
+		t.Fatalf("expected the session to be revoked after reuse, got %v", err)
+	}
+	if err := svc.ValidateSession(ctx, &interceptors.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: rotated.AccessToken}}, ""); !errors.Is(err, service.ErrSessionRevoked) {
+		t.Fatalf("expected the phone's access token to be rejected, got %v", err)
+	}
+
+	// Revoking the laptop takes effect at once
+	laptopClaims := &interceptors.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: laptop.AccessToken}}
+	if err := svc.ValidateSession(ctx, laptopClaims, "203.0.113.9"); err != nil {
+		t.Fatalf("ValidateSession: %v", err)
+	}
+	for _, s := range sessions {
+		if *s.DeviceName == "Laptop" {
+			if err := svc.RevokeSession(ctx, user.ID, s.ID); err != nil {
+				t.Fatalf("RevokeSession: %v", err)
+			}
+			if err := svc.RevokeSession(ctx, user.ID, s.ID); !errors.Is(err, common.ErrSessionNotFound) {
+				t.Fatalf("expected revoking twice to fail, got %v", err)
+			}
+		}
+	}
+	if err := svc.ValidateSession(ctx, laptopClaims, ""); !errors.Is(err, service.ErrSessionRevoked) {
+		t.Fatalf("expected the laptop's access token to be rejected, got %v", err)
+	}
+	if sessions, _ := svc.ListActiveSessions(ctx, user.ID, ""); len(sessions) != 0 {
+		t.Fatalf("expected no active sessions, got %d", len(sessions))
+	}
+}
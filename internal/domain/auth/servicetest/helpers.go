@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -65,6 +66,10 @@ func (m *MockEmailSender) SendWelcomeEmail(_, _ string) error {
 	return nil
 }
 
+func (m *MockEmailSender) SendNotificationEmail(_, _, _, _, _ string) error {
+	return nil
+}
+
 func (m *MockEmailSender) VerificationSent() bool {
 	return m.verificationSent.Load()
 }
@@ -85,16 +90,26 @@ func (m *MockEmailSender) ResetFlags() {
 
 // MockAuthRepo is an in-memory AuthRepository.
 type MockAuthRepo struct {
-	Users    map[string]*repository.User
-	Sessions map[string]*repository.UserSession
-	Tokens   map[string]*repository.UserToken
+	Users      map[string]*repository.User
+	Sessions   map[string]*repository.UserSession
+	Tokens     map[string]*repository.UserToken
+	Identities map[string]*repository.OAuthIdentity    // Keyed by provider and provider user ID
+	Devices    map[uuid.UUID]*repository.ActiveSession // Session tracking, keyed by session ID
+	Rotated    map[string]uuid.UUID                    // Rotated-out refresh token hashes
+	Revoked    map[uuid.UUID]bool
+
+	mu sync.Mutex // Guards sessions for concurrent refreshes
 }
 
 func NewMockAuthRepo() *MockAuthRepo {
 	return &MockAuthRepo{
-		Users:    make(map[string]*repository.User),
-		Sessions: make(map[string]*repository.UserSession),
-		Tokens:   make(map[string]*repository.UserToken),
+		Users:      make(map[string]*repository.User),
+		Sessions:   make(map[string]*repository.UserSession),
+		Tokens:     make(map[string]*repository.UserToken),
+		Identities: make(map[string]*repository.OAuthIdentity),
+		Devices:    make(map[uuid.UUID]*repository.ActiveSession),
+		Rotated:    make(map[string]uuid.UUID),
+		Revoked:    make(map[uuid.UUID]bool),
 	}
 }
 
@@ -160,8 +175,10 @@ func (m *MockAuthRepo) CreateUserSession(_ context.Context, userID uuid.UUID, ha
 }
 
 func (m *MockAuthRepo) GetUserSessionByToken(_ context.Context, hashedToken string) (*repository.UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	session, ok := m.Sessions[hashedToken]
-	if !ok || session.ExpiresAt.Before(time.Now()) {
+	if !ok || session.ExpiresAt.Before(time.Now()) || m.Revoked[session.ID] {
 		return nil, common.ErrSessionNotFound
 	}
 	return session, nil
@@ -181,6 +198,94 @@ func (m *MockAuthRepo) DeleteAllUserSessions(_ context.Context, userID uuid.UUID
 	return nil
 }
 
+func (m *MockAuthRepo) TrackUserSession(_ context.Context, sessionID uuid.UUID, accessTokenID, deviceName string) error {
+	for _, session := range m.Sessions {
+		if session.ID == sessionID {
+			now := session.CreatedAt
+			m.Devices[sessionID] = &repository.ActiveSession{
+				ID: sessionID, DeviceName: &deviceName, UserAgent: session.UserAgent, ClientIP: session.ClientIP,
+				AccessTokenID: &accessTokenID, CreatedAt: session.CreatedAt, LastSeenAt: &now, ExpiresAt: session.ExpiresAt,
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockAuthRepo) RotateUserSession(_ context.Context, sessionID uuid.UUID, currentHash, hashedRefreshToken, accessTokenID, clientIP string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.Sessions[currentHash]
+	if !ok || session.ID != sessionID || m.Revoked[sessionID] {
+		return common.ErrSessionNotFound
+	}
+	delete(m.Sessions, currentHash)
+	m.Rotated[currentHash] = sessionID
+	session.HashedRefreshToken, session.ExpiresAt = hashedRefreshToken, expiresAt
+	m.Sessions[hashedRefreshToken] = session
+	if device, ok := m.Devices[sessionID]; ok {
+		now := time.Now()
+		device.AccessTokenID, device.LastSeenIP, device.LastSeenAt = &accessTokenID, &clientIP, &now
+	}
+	return nil
+}
+
+func (m *MockAuthRepo) GetUserSessionByPreviousToken(_ context.Context, hashedToken string) (*repository.UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sessionID, ok := m.Rotated[hashedToken]
+	if !ok {
+		return nil, common.ErrSessionNotFound
+	}
+	for _, session := range m.Sessions {
+		if session.ID == sessionID {
+			return session, nil
+		}
+	}
+	return nil, common.ErrSessionNotFound
+}
+
+func (m *MockAuthRepo) ListActiveUserSessions(_ context.Context, userID uuid.UUID) ([]repository.ActiveSession, error) {
+	var sessions []repository.ActiveSession
+	for _, session := range m.Sessions {
+		if session.UserID != userID || m.Revoked[session.ID] || session.ExpiresAt.Before(time.Now()) {
+			continue
+		}
+		if device, ok := m.Devices[session.ID]; ok {
+			sessions = append(sessions, *device)
+		} else {
+			sessions = append(sessions, repository.ActiveSession{ID: session.ID, CreatedAt: session.CreatedAt, ExpiresAt: session.ExpiresAt})
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockAuthRepo) RevokeUserSession(_ context.Context, userID, sessionID uuid.UUID) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, session := range m.Sessions {
+		if session.ID == sessionID && session.UserID == userID && !m.Revoked[sessionID] {
+			m.Revoked[sessionID] = true
+			if device, ok := m.Devices[sessionID]; ok && device.AccessTokenID != nil {
+				return *device.AccessTokenID, nil
+			}
+			return "", nil
+		}
+	}
+	return "", common.ErrSessionNotFound
+}
+
+func (m *MockAuthRepo) TouchSessionByAccessToken(_ context.Context, accessTokenID, _ string, at time.Time) (bool, error) {
+	for sessionID, device := range m.Devices {
+		if device.AccessTokenID != nil && *device.AccessTokenID == accessTokenID {
+			if m.Revoked[sessionID] {
+				return true, nil
+			}
+			device.LastSeenAt = &at
+		}
+	}
+	return false, nil
+}
+
 func (m *MockAuthRepo) CreateUserToken(_ context.Context, userID uuid.UUID, tokenHash, tokenType string, expiresAt time.Time) error {
 	m.Tokens[tokenHash] = &repository.UserToken{
 		TokenHash: tokenHash,
@@ -226,12 +331,38 @@ func (m *MockAuthRepo) UpdatePassword(_ context.Context, userID uuid.UUID, hashe
 	return common.ErrUserNotFound
 }
 
-func (m *MockAuthRepo) CreateOrUpdateOAuthIdentity(_ context.Context, _, _ string, _ uuid.UUID, _, _ *string) error {
+func (m *MockAuthRepo) CreateOrUpdateOAuthIdentity(_ context.Context, providerName, providerUserID string, userID uuid.UUID, email string, accessToken, refreshToken *string) error {
+	now := time.Now()
+	key := providerName + ":" + providerUserID
+	identity, ok := m.Identities[key]
+	if !ok {
+		identity = &repository.OAuthIdentity{ProviderName: providerName, ProviderUserID: providerUserID, UserID: userID, CreatedAt: now}
+		m.Identities[key] = identity
+	}
+	if email != "" {
+		identity.Email = &email
+	}
+	identity.ProviderAccessToken, identity.ProviderRefreshToken = accessToken, refreshToken
+	identity.LastLoginAt, identity.UpdatedAt = &now, now
 	return nil
 }
 
-func (m *MockAuthRepo) GetUserByOAuthIdentity(_ context.Context, _, _ string) (*repository.User, error) {
-	return nil, common.ErrUserNotFound
+func (m *MockAuthRepo) GetUserByOAuthIdentity(ctx context.Context, providerName, providerUserID string) (*repository.User, error) {
+	identity, ok := m.Identities[providerName+":"+providerUserID]
+	if !ok {
+		return nil, common.ErrUserNotFound
+	}
+	return m.GetUserByID(ctx, identity.UserID)
+}
+
+func (m *MockAuthRepo) ListOAuthIdentities(_ context.Context, userID uuid.UUID) ([]repository.OAuthIdentity, error) {
+	var identities []repository.OAuthIdentity
+	for _, identity := range m.Identities {
+		if identity.UserID == userID {
+			identities = append(identities, *identity)
+		}
+	}
+	return identities, nil
 }
 
 func (m *MockAuthRepo) GetUserByPhone(_ context.Context, phone string) (*repository.User, error) {
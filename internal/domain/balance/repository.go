@@ -111,7 +111,7 @@ func (r *Repository) GetAccountBalances(ctx context.Context, userID uuid.UUID) (
 				MAX(t.posted_at) AS last_activity
 			FROM transactions t
 			LEFT JOIN accounts a ON a.id = t.account_id
-			WHERE t.user_id = $1
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL
 			GROUP BY t.account_id, a.name, a.type
 		),
 		daily_change AS (
@@ -120,6 +120,7 @@ func (r *Repository) GetAccountBalances(ctx context.Context, userID uuid.UUID) (
 				COALESCE(SUM(amount_minor), 0) AS change_24h
 			FROM transactions
 			WHERE user_id = $1 
+			  AND deleted_at IS NULL
 			  AND posted_at >= NOW() - INTERVAL '24 hours'
 			GROUP BY account_id
 		)
@@ -213,22 +214,31 @@ func (r *Repository) GetTotalBalance(ctx context.Context, userID uuid.UUID) (int
 	query := `
 		SELECT COALESCE(SUM(amount_minor), 0)
 		FROM transactions
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 	var total int64
 	err := r.db.QueryRow(ctx, query, userID).Scan(&total)
 	return total, err
 }
 
-// GetUpcomingBills sums the expected recurring subscription amounts
+// GetUpcomingBills sums the expected recurring subscription and installment amounts
 func (r *Repository) GetUpcomingBills(ctx context.Context, userID uuid.UUID) (int64, error) {
-	// Sum recurring subscriptions expected in next 30 days
+	// Sum recurring subscriptions and unpaid installment charges expected in next 30 days
 	query := `
-		SELECT COALESCE(SUM(ABS(amount_minor)), 0)
-		FROM recurring_subscriptions
-		WHERE user_id = $1 
-		  AND status = 'active'
-		  AND next_expected_at <= NOW() + INTERVAL '30 days'
+		SELECT
+			(SELECT COALESCE(SUM(ABS(amount_minor)), 0)
+			 FROM recurring_subscriptions
+			 WHERE user_id = $1
+			   AND status = 'active'
+			   AND next_expected_at <= NOW() + INTERVAL '30 days')
+			+
+			(SELECT COALESCE(SUM(ic.amount_minor), 0)
+			 FROM installment_charges ic
+			 JOIN installment_plans ip ON ip.id = ic.plan_id
+			 WHERE ip.user_id = $1
+			   AND ip.status = 'active'
+			   AND ic.transaction_id IS NULL
+			   AND ic.due_at <= CURRENT_DATE + 30)
 	`
 	var total int64
 	err := r.db.QueryRow(ctx, query, userID).Scan(&total)
@@ -253,6 +263,7 @@ func (r *Repository) GetBalanceHistory(ctx context.Context, userID uuid.UUID, da
 				SUM(amount_minor) AS daily_sum
 			FROM transactions
 			WHERE user_id = $1
+			  AND deleted_at IS NULL
 			  AND posted_at >= CURRENT_DATE - ($2::integer)
 			GROUP BY DATE(posted_at)
 		),
@@ -298,6 +309,7 @@ func (r *Repository) GetBalanceStats(ctx context.Context, userID uuid.UUID, days
 				SUM(SUM(amount_minor)) OVER (ORDER BY DATE(posted_at)) AS running_balance
 			FROM transactions
 			WHERE user_id = $1
+			  AND deleted_at IS NULL
 			  AND posted_at >= CURRENT_DATE - ($2::integer)
 			GROUP BY DATE(posted_at)
 		)
@@ -0,0 +1,53 @@
+// Package handler receives Stripe webhook events over HTTP.
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/stripe"
+)
+
+// maxEventSize caps the body of an event
+const maxEventSize = 1 << 20
+
+// WebhookHandler accepts the events Stripe posts to the billing webhook
+type WebhookHandler struct {
+	svc    *service.Service
+	logger *slog.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(svc *service.Service, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{svc: svc, logger: logger}
+}
+
+// ServeHTTP answers POST WebhookPath. Events that fail are answered with an
+// error so Stripe delivers them again.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxEventSize))
+	if err != nil {
+		http.Error(w, "Invalid event.", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.HandleWebhook(r.Context(), payload, r.Header.Get(stripe.SignatureHeader))
+	switch {
+	case errors.Is(err, stripe.ErrInvalidSignature):
+		http.Error(w, "Invalid signature.", http.StatusBadRequest)
+		return
+	case err != nil:
+		h.logger.Error("failed to handle stripe event", slog.Any("error", err))
+		http.Error(w, "Failed to handle event.", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresBillingRepository implements BillingRepository using PostgreSQL
+type PostgresBillingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresBillingRepository creates a new PostgreSQL billing repository
+func NewPostgresBillingRepository(pool *pgxpool.Pool) *PostgresBillingRepository {
+	return &PostgresBillingRepository{pool: pool}
+}
+
+// GetSubscription returns the user's subscription
+func (r *PostgresBillingRepository) GetSubscription(ctx context.Context, userID uuid.UUID) (*Subscription, error) {
+	sub := &Subscription{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id, plan::TEXT, status::TEXT, start_date, end_date, trial_end_date,
+		       external_customer_id, external_subscription_id, updated_at
+		FROM subscriptions
+		WHERE user_id = $1
+	`, userID).Scan(&sub.UserID, &sub.Plan, &sub.Status, &sub.StartDate, &sub.EndDate, &sub.TrialEndDate,
+		&sub.CustomerID, &sub.ExternalSubscriptionID, &sub.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetUserEmail returns the user's email
+func (r *PostgresBillingRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email string
+	err := r.pool.QueryRow(ctx, `SELECT email::TEXT FROM users WHERE id = $1`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	return email, nil
+}
+
+// SetCustomerID links a Stripe customer to the user
+func (r *PostgresBillingRepository) SetCustomerID(ctx context.Context, userID uuid.UUID, customerID string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO subscriptions (user_id, external_provider, external_customer_id)
+		VALUES ($1, 'stripe', $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET external_provider = 'stripe', external_customer_id = EXCLUDED.external_customer_id
+	`, userID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to set stripe customer: %w", err)
+	}
+	return nil
+}
+
+// GetUserByCustomerID returns the user a Stripe customer is linked to
+func (r *PostgresBillingRepository) GetUserByCustomerID(ctx context.Context, customerID string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id FROM subscriptions WHERE external_customer_id = $1
+	`, customerID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get user by stripe customer: %w", err)
+	}
+	return userID, nil
+}
+
+// ApplySubscription saves a subscription unless a later event was applied
+func (r *PostgresBillingRepository) ApplySubscription(ctx context.Context, sub *Subscription, eventAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO subscriptions (
+			user_id, plan, status, start_date, end_date, trial_end_date,
+			external_provider, external_subscription_id, external_updated_at
+		) VALUES ($1, $2::subscription_plan_type, $3::subscription_status, $4, $5, $6, 'stripe', $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			plan = EXCLUDED.plan,
+			status = EXCLUDED.status,
+			start_date = EXCLUDED.start_date,
+			end_date = EXCLUDED.end_date,
+			trial_end_date = EXCLUDED.trial_end_date,
+			external_provider = EXCLUDED.external_provider,
+			external_subscription_id = EXCLUDED.external_subscription_id,
+			external_updated_at = EXCLUDED.external_updated_at
+		WHERE subscriptions.external_updated_at IS NULL
+		   OR subscriptions.external_updated_at <= EXCLUDED.external_updated_at
+	`, sub.UserID, string(sub.Plan), string(sub.Status), sub.StartDate, sub.EndDate, sub.TrialEndDate,
+		sub.ExternalSubscriptionID, eventAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply subscription: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,76 @@
+// Package repository provides database operations for users' paid subscriptions.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plan is a subscription plan
+type Plan string
+
+const (
+	PlanFree           Plan = "free"
+	PlanPremiumMonthly Plan = "premium_monthly"
+	PlanPremiumAnnual  Plan = "premium_annual"
+)
+
+// Status is the state of a subscription
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusTrialing Status = "trialing"
+	StatusPastDue  Status = "past_due" // Payment failed, still in effect while retried
+	StatusCanceled Status = "canceled" // In effect until EndDate
+	StatusExpired  Status = "expired"
+)
+
+// Subscription is a user's current subscription
+type Subscription struct {
+	UserID                 uuid.UUID
+	Plan                   Plan
+	Status                 Status
+	StartDate              time.Time
+	EndDate                *time.Time
+	TrialEndDate           *time.Time
+	CustomerID             *string // Stripe customer, once the user started a checkout
+	ExternalSubscriptionID *string
+	UpdatedAt              time.Time
+}
+
+// Paid reports whether a paid plan is in effect at now. Canceled subscriptions
+// stay in effect until they end.
+func (s *Subscription) Paid(now time.Time) bool {
+	if s.Plan == PlanFree {
+		return false
+	}
+	switch s.Status {
+	case StatusActive, StatusTrialing, StatusPastDue:
+		return true
+	case StatusCanceled:
+		return s.EndDate != nil && s.EndDate.After(now)
+	default:
+		return false
+	}
+}
+
+// BillingRepository defines the interface for subscription persistence
+type BillingRepository interface {
+	// GetSubscription returns the user's subscription; sql.ErrNoRows if they never had one
+	GetSubscription(ctx context.Context, userID uuid.UUID) (*Subscription, error)
+	// GetUserEmail returns the email the user's Stripe customer is created with
+	GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error)
+	// SetCustomerID links a Stripe customer to the user, giving them a free
+	// subscription if they have none
+	SetCustomerID(ctx context.Context, userID uuid.UUID, customerID string) error
+	// GetUserByCustomerID returns the user a Stripe customer is linked to;
+	// sql.ErrNoRows if none is
+	GetUserByCustomerID(ctx context.Context, customerID string) (uuid.UUID, error)
+	// ApplySubscription saves a subscription as of a webhook event sent at
+	// eventAt. It returns false, saving nothing, when a later event was
+	// already applied.
+	ApplySubscription(ctx context.Context, sub *Subscription, eventAt time.Time) (bool, error)
+}
@@ -0,0 +1,403 @@
+// Package service provides billing: Stripe Checkout for paid plans, the Stripe
+// billing portal, and the features each plan is entitled to.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/featureflags"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/stripe"
+)
+
+// =============================================================================
+// Billing (Internal Integration)
+// =============================================================================
+// Users upgrade through Stripe Checkout and manage or cancel their
+// subscription in the Stripe billing portal. Stripe posts subscription changes
+// to WebhookPath over plain HTTP; they update the user's subscription, which
+// their tier, entitlements and quotas follow. Entitlements gate feature flags
+// through featureflags.Service.WithEntitlements, so aggregator sync is only on
+// for paying users.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateCheckoutSessionRequest/Response (UserService.CreateCheckoutSession)
+// - GetBillingPortalUrlRequest/Response (UserService.GetBillingPortalUrl)
+// - GetSubscriptionRequest/Response (UserService.GetSubscription) with
+//   Subscription and its entitlements
+
+// WebhookPath is the route Stripe delivers webhook events to
+const WebhookPath = "/billing/stripe/webhook"
+
+// entitlementCacheTTL is how long a user's tier is served from memory. Webhook
+// updates drop the user's entry straight away.
+const entitlementCacheTTL = time.Minute
+
+// Tier groups plans by what they're entitled to
+type Tier string
+
+const (
+	TierFree    Tier = "free"
+	TierPremium Tier = "premium"
+)
+
+// DefaultTierFeatures are the paid features each tier is entitled to
+var DefaultTierFeatures = map[Tier][]string{
+	TierPremium: {featureflags.AggregatorSync},
+}
+
+var (
+	// ErrBillingDisabled is returned when Stripe isn't configured
+	ErrBillingDisabled = errors.New("billing is not configured")
+	// ErrUnknownPlan is returned when checking out a plan without a price
+	ErrUnknownPlan = errors.New("unknown plan")
+	// ErrNoCustomer is returned for the billing portal of users who never checked out
+	ErrNoCustomer = errors.New("user has no billing account")
+)
+
+// Payments is the Stripe API (stripe.Client)
+type Payments interface {
+	CreateCustomer(ctx context.Context, email, userID string) (string, error)
+	CreateCheckoutSession(ctx context.Context, params stripe.CheckoutParams) (*stripe.CheckoutSession, error)
+	CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error)
+}
+
+// Prices are the Stripe price IDs of the paid plans
+type Prices map[repository.Plan]string
+
+// cachedTier is a user's tier and when it was read
+type cachedTier struct {
+	tier   Tier
+	readAt time.Time
+}
+
+// Service handles billing business logic
+type Service struct {
+	repo          repository.BillingRepository
+	payments      Payments // Optional: nil if Stripe isn't configured
+	prices        Prices
+	webhookSecret string
+	features      map[Tier][]string
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu    sync.Mutex
+	tiers map[uuid.UUID]cachedTier
+}
+
+// NewService creates a new billing service entitling tiers to
+// DefaultTierFeatures. Without Stripe, users keep the plan they have.
+func NewService(repo repository.BillingRepository, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		features: DefaultTierFeatures,
+		logger:   logger,
+		now:      time.Now,
+		tiers:    make(map[uuid.UUID]cachedTier),
+	}
+}
+
+// WithStripe takes payments through Stripe at the given prices and accepts
+// webhook events signed with webhookSecret
+func (s *Service) WithStripe(payments Payments, prices Prices, webhookSecret string) *Service {
+	s.payments = payments
+	s.prices = prices
+	s.webhookSecret = webhookSecret
+	return s
+}
+
+// WithTierFeatures replaces the paid features each tier is entitled to
+func (s *Service) WithTierFeatures(features map[Tier][]string) *Service {
+	s.features = features
+	return s
+}
+
+// PaidFeatures lists every feature some tier is entitled to; their flags are
+// gated by plan
+func (s *Service) PaidFeatures() []string {
+	var features []string
+	seen := make(map[string]bool)
+	for _, tierFeatures := range s.features {
+		for _, feature := range tierFeatures {
+			if !seen[feature] {
+				seen[feature] = true
+				features = append(features, feature)
+			}
+		}
+	}
+	return features
+}
+
+// GetSubscription returns the user's subscription. Users who never subscribed
+// are on an active free plan.
+func (s *Service) GetSubscription(ctx context.Context, userID uuid.UUID) (*repository.Subscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &repository.Subscription{UserID: userID, Plan: repository.PlanFree, Status: repository.StatusActive}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// GetTier returns the tier of the user's plan in effect
+func (s *Service) GetTier(ctx context.Context, userID uuid.UUID) (Tier, error) {
+	now := s.now()
+	s.mu.Lock()
+	cached, ok := s.tiers[userID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.readAt) < entitlementCacheTTL {
+		return cached.tier, nil
+	}
+
+	sub, err := s.GetSubscription(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	tier := TierFree
+	if sub.Paid(now) {
+		tier = TierPremium
+	}
+	s.mu.Lock()
+	s.tiers[userID] = cachedTier{tier: tier, readAt: now}
+	s.mu.Unlock()
+	return tier, nil
+}
+
+// Entitled returns the paid features the user's plan is entitled to. It
+// implements featureflags.Entitlements.
+func (s *Service) Entitled(ctx context.Context, userID uuid.UUID) (map[string]bool, error) {
+	tier, err := s.GetTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entitled := make(map[string]bool, len(s.features[tier]))
+	for _, feature := range s.features[tier] {
+		entitled[feature] = true
+	}
+	return entitled, nil
+}
+
+// HasEntitlement reports whether the user's plan is entitled to a paid feature
+func (s *Service) HasEntitlement(ctx context.Context, userID uuid.UUID, feature string) (bool, error) {
+	entitled, err := s.Entitled(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return entitled[feature], nil
+}
+
+// CreateCheckoutSession starts a Stripe Checkout for a paid plan and returns
+// the URL to send the user to. The user's Stripe customer is created on their
+// first checkout.
+func (s *Service) CreateCheckoutSession(ctx context.Context, userID uuid.UUID, plan repository.Plan, successURL, cancelURL string) (string, error) {
+	if s.payments == nil {
+		return "", ErrBillingDisabled
+	}
+	priceID := s.prices[plan]
+	if priceID == "" {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPlan, plan)
+	}
+	customerID, err := s.ensureCustomer(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	session, err := s.payments.CreateCheckoutSession(ctx, stripe.CheckoutParams{
+		CustomerID:        customerID,
+		PriceID:           priceID,
+		ClientReferenceID: userID.String(),
+		SuccessURL:        successURL,
+		CancelURL:         cancelURL,
+	})
+	if err != nil {
+		return "", err
+	}
+	return session.URL, nil
+}
+
+// ensureCustomer returns the user's Stripe customer, creating it if needed
+func (s *Service) ensureCustomer(ctx context.Context, userID uuid.UUID) (string, error) {
+	sub, err := s.GetSubscription(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if sub.CustomerID != nil && *sub.CustomerID != "" {
+		return *sub.CustomerID, nil
+	}
+
+	email, err := s.repo.GetUserEmail(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	customerID, err := s.payments.CreateCustomer(ctx, email, userID.String())
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SetCustomerID(ctx, userID, customerID); err != nil {
+		return "", err
+	}
+	return customerID, nil
+}
+
+// GetBillingPortalURL returns the URL of a Stripe billing portal session where
+// the user manages their subscription, coming back to returnURL
+func (s *Service) GetBillingPortalURL(ctx context.Context, userID uuid.UUID, returnURL string) (string, error) {
+	if s.payments == nil {
+		return "", ErrBillingDisabled
+	}
+	sub, err := s.GetSubscription(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if sub.CustomerID == nil || *sub.CustomerID == "" {
+		return "", ErrNoCustomer
+	}
+	return s.payments.CreatePortalSession(ctx, *sub.CustomerID, returnURL)
+}
+
+// HandleWebhook verifies and applies a Stripe webhook event. It returns
+// stripe.ErrInvalidSignature for events not signed with the webhook secret.
+// Events about customers or prices this app doesn't know are logged and
+// skipped; other errors should make Stripe deliver the event again.
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := stripe.ConstructEvent(payload, signature, s.webhookSecret, s.now())
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case stripe.EventCheckoutCompleted:
+		return s.applyCheckout(ctx, event)
+	case stripe.EventSubscriptionCreated, stripe.EventSubscriptionUpdated, stripe.EventSubscriptionDeleted:
+		return s.applySubscription(ctx, event)
+	default:
+		return nil
+	}
+}
+
+// applyCheckout links the customer of a completed checkout to its user, in
+// case the checkout was started with a customer created elsewhere
+func (s *Service) applyCheckout(ctx context.Context, event *stripe.Event) error {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+		return fmt.Errorf("failed to decode checkout session: %w", err)
+	}
+	userID, err := uuid.Parse(session.ClientReferenceID)
+	if err != nil || session.Customer == "" {
+		s.logger.WarnContext(ctx, "skipping checkout without a user", slog.String("event_id", event.ID))
+		return nil
+	}
+	if err := s.repo.SetCustomerID(ctx, userID, session.Customer); err != nil {
+		return err
+	}
+	s.invalidate(userID)
+	return nil
+}
+
+// applySubscription saves the subscription an event carries for its customer's user
+func (s *Service) applySubscription(ctx context.Context, event *stripe.Event) error {
+	var stripeSub stripe.Subscription
+	if err := json.Unmarshal(event.Data.Object, &stripeSub); err != nil {
+		return fmt.Errorf("failed to decode subscription: %w", err)
+	}
+	userID, err := s.repo.GetUserByCustomerID(ctx, stripeSub.Customer)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.logger.WarnContext(ctx, "skipping subscription of unknown customer",
+			slog.String("event_id", event.ID), slog.String("customer", stripeSub.Customer))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	plan := s.planForPrice(stripeSub.PriceID())
+	if plan == "" {
+		s.logger.WarnContext(ctx, "skipping subscription with unknown price",
+			slog.String("event_id", event.ID), slog.String("price", stripeSub.PriceID()))
+		return nil
+	}
+	status, endDate, ok := subscriptionStatus(&stripeSub, event.Type == stripe.EventSubscriptionDeleted, s.now())
+	if !ok {
+		// Waiting on the first payment; the next update carries the outcome
+		return nil
+	}
+
+	subID := stripeSub.ID
+	sub := &repository.Subscription{
+		UserID:                 userID,
+		Plan:                   plan,
+		Status:                 status,
+		StartDate:              time.Unix(stripeSub.StartDate, 0),
+		EndDate:                endDate,
+		TrialEndDate:           unixTime(stripeSub.TrialEnd),
+		ExternalSubscriptionID: &subID,
+	}
+	applied, err := s.repo.ApplySubscription(ctx, sub, time.Unix(event.Created, 0))
+	if err != nil {
+		return err
+	}
+	if applied {
+		s.invalidate(userID)
+	}
+	return nil
+}
+
+// planForPrice returns the plan a Stripe price is for, or "" for unknown prices
+func (s *Service) planForPrice(priceID string) repository.Plan {
+	for plan, id := range s.prices {
+		if id != "" && id == priceID {
+			return plan
+		}
+	}
+	return ""
+}
+
+// subscriptionStatus maps a Stripe subscription to a status and end date. It
+// returns false for subscriptions still waiting on their first payment.
+func subscriptionStatus(sub *stripe.Subscription, deleted bool, now time.Time) (repository.Status, *time.Time, bool) {
+	periodEnd := time.Unix(sub.CurrentPeriodEnd, 0)
+	if deleted || sub.Status == "canceled" {
+		ended := now
+		if sub.EndedAt != nil {
+			ended = time.Unix(*sub.EndedAt, 0)
+		}
+		return repository.StatusExpired, &ended, true
+	}
+	switch sub.Status {
+	case "active", "trialing", "past_due":
+		if sub.CancelAtPeriodEnd {
+			return repository.StatusCanceled, &periodEnd, true
+		}
+		return repository.Status(sub.Status), nil, true
+	case "incomplete":
+		return "", nil, false
+	default:
+		// unpaid, paused and incomplete_expired subscriptions aren't paid for
+		return repository.StatusExpired, &now, true
+	}
+}
+
+func unixTime(seconds *int64) *time.Time {
+	if seconds == nil {
+		return nil
+	}
+	t := time.Unix(*seconds, 0)
+	return &t
+}
+
+// invalidate drops the user's cached tier
+func (s *Service) invalidate(userID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.tiers, userID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/billing/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/featureflags"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/stripe"
+)
+
+const webhookSecret = "whsec_test"
+
+// fakeBillingRepository keeps subscriptions in memory.
+type fakeBillingRepository struct {
+	subs      map[uuid.UUID]*repository.Subscription
+	appliedAt map[uuid.UUID]time.Time
+}
+
+func newFakeBillingRepository() *fakeBillingRepository {
+	return &fakeBillingRepository{subs: map[uuid.UUID]*repository.Subscription{}, appliedAt: map[uuid.UUID]time.Time{}}
+}
+
+func (f *fakeBillingRepository) GetSubscription(ctx context.Context, userID uuid.UUID) (*repository.Subscription, error) {
+	sub, ok := f.subs[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (f *fakeBillingRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	return "ana@example.com", nil
+}
+
+func (f *fakeBillingRepository) SetCustomerID(ctx context.Context, userID uuid.UUID, customerID string) error {
+	sub, ok := f.subs[userID]
+	if !ok {
+		sub = &repository.Subscription{UserID: userID, Plan: repository.PlanFree, Status: repository.StatusActive}
+		f.subs[userID] = sub
+	}
+	sub.CustomerID = &customerID
+	return nil
+}
+
+func (f *fakeBillingRepository) GetUserByCustomerID(ctx context.Context, customerID string) (uuid.UUID, error) {
+	for userID, sub := range f.subs {
+		if sub.CustomerID != nil && *sub.CustomerID == customerID {
+			return userID, nil
+		}
+	}
+	return uuid.Nil, sql.ErrNoRows
+}
+
+func (f *fakeBillingRepository) ApplySubscription(ctx context.Context, sub *repository.Subscription, eventAt time.Time) (bool, error) {
+	if last, ok := f.appliedAt[sub.UserID]; ok && eventAt.Before(last) {
+		return false, nil
+	}
+	f.appliedAt[sub.UserID] = eventAt
+	saved := *sub
+	saved.CustomerID = f.subs[sub.UserID].CustomerID
+	f.subs[sub.UserID] = &saved
+	return true, nil
+}
+
+// fakePayments records the sessions it creates.
+type fakePayments struct {
+	customers int
+	checkout  stripe.CheckoutParams
+}
+
+func (f *fakePayments) CreateCustomer(ctx context.Context, email, userID string) (string, error) {
+	f.customers++
+	return fmt.Sprintf("cus_%d", f.customers), nil
+}
+
+func (f *fakePayments) CreateCheckoutSession(ctx context.Context, params stripe.CheckoutParams) (*stripe.CheckoutSession, error) {
+	f.checkout = params
+	return &stripe.CheckoutSession{ID: "cs_1", URL: "https://checkout.stripe.com/c/cs_1"}, nil
+}
+
+func (f *fakePayments) CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error) {
+	return "https://billing.stripe.com/p/" + customerID, nil
+}
+
+func newTestService(repo repository.BillingRepository, payments Payments, now time.Time) *Service {
+	svc := NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithStripe(payments, Prices{repository.PlanPremiumMonthly: "price_monthly", repository.PlanPremiumAnnual: "price_annual"}, webhookSecret)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+// signedEvent builds a webhook payload and its signature header
+func signedEvent(t *testing.T, id, eventType string, created time.Time, object string) ([]byte, string) {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{"id":%q,"type":%q,"created":%d,"data":{"object":%s}}`, id, eventType, created.Unix(), object))
+	return payload, fmt.Sprintf("t=%d,v1=%s", created.Unix(), stripe.Sign(payload, webhookSecret, created.Unix()))
+}
+
+func TestCreateCheckoutSession(t *testing.T) {
+	repo := newFakeBillingRepository()
+	payments := &fakePayments{}
+	svc := newTestService(repo, payments, time.Now())
+	userID := uuid.New()
+	ctx := context.Background()
+
+	url, err := svc.CreateCheckoutSession(ctx, userID, repository.PlanPremiumAnnual, "https://app/success", "https://app/cancel")
+	require.NoError(t, err)
+	assert.Equal(t, "https://checkout.stripe.com/c/cs_1", url)
+	assert.Equal(t, "price_annual", payments.checkout.PriceID)
+	assert.Equal(t, userID.String(), payments.checkout.ClientReferenceID)
+	assert.Equal(t, "cus_1", payments.checkout.CustomerID)
+
+	_, err = svc.CreateCheckoutSession(ctx, userID, repository.PlanPremiumMonthly, "https://app/success", "https://app/cancel")
+	require.NoError(t, err)
+	assert.Equal(t, 1, payments.customers, "the customer is reused")
+
+	_, err = svc.CreateCheckoutSession(ctx, userID, repository.PlanFree, "", "")
+	assert.ErrorIs(t, err, ErrUnknownPlan)
+
+	_, err = NewService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))).CreateCheckoutSession(ctx, userID, repository.PlanPremiumAnnual, "", "")
+	assert.ErrorIs(t, err, ErrBillingDisabled)
+}
+
+func TestGetBillingPortalURL(t *testing.T) {
+	repo := newFakeBillingRepository()
+	svc := newTestService(repo, &fakePayments{}, time.Now())
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := svc.GetBillingPortalURL(ctx, userID, "https://app/settings")
+	assert.ErrorIs(t, err, ErrNoCustomer)
+
+	require.NoError(t, repo.SetCustomerID(ctx, userID, "cus_9"))
+	url, err := svc.GetBillingPortalURL(ctx, userID, "https://app/settings")
+	require.NoError(t, err)
+	assert.Equal(t, "https://billing.stripe.com/p/cus_9", url)
+}
+
+func TestHandleWebhook_Entitlements(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeBillingRepository()
+	svc := newTestService(repo, &fakePayments{}, now)
+	userID := uuid.New()
+	ctx := context.Background()
+	require.NoError(t, repo.SetCustomerID(ctx, userID, "cus_1"))
+
+	entitled, err := svc.HasEntitlement(ctx, userID, featureflags.AggregatorSync)
+	require.NoError(t, err)
+	assert.False(t, entitled, "free users aren't entitled to aggregator sync")
+
+	periodEnd := now.AddDate(0, 1, 0)
+	active := fmt.Sprintf(`{"id":"sub_1","customer":"cus_1","status":"active","current_period_end":%d,"start_date":%d,"items":{"data":[{"price":{"id":"price_monthly"}}]}}`,
+		periodEnd.Unix(), now.Unix())
+	payload, sig := signedEvent(t, "evt_1", stripe.EventSubscriptionCreated, now, active)
+	require.NoError(t, svc.HandleWebhook(ctx, payload, sig))
+
+	sub, err := svc.GetSubscription(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, repository.PlanPremiumMonthly, sub.Plan)
+	assert.Equal(t, repository.StatusActive, sub.Status)
+	entitled, err = svc.HasEntitlement(ctx, userID, featureflags.AggregatorSync)
+	require.NoError(t, err)
+	assert.True(t, entitled, "the webhook drops the cached tier")
+
+	canceling := fmt.Sprintf(`{"id":"sub_1","customer":"cus_1","status":"active","cancel_at_period_end":true,"current_period_end":%d,"start_date":%d,"items":{"data":[{"price":{"id":"price_monthly"}}]}}`,
+		periodEnd.Unix(), now.Unix())
+	payload, sig = signedEvent(t, "evt_2", stripe.EventSubscriptionUpdated, now, canceling)
+	require.NoError(t, svc.HandleWebhook(ctx, payload, sig))
+	sub, err = svc.GetSubscription(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, repository.StatusCanceled, sub.Status)
+	require.NotNil(t, sub.EndDate)
+	assert.True(t, sub.Paid(now), "canceled subscriptions last until the period ends")
+
+	payload, sig = signedEvent(t, "evt_3", stripe.EventSubscriptionDeleted, now, canceling)
+	require.NoError(t, svc.HandleWebhook(ctx, payload, sig))
+	entitled, err = svc.HasEntitlement(ctx, userID, featureflags.AggregatorSync)
+	require.NoError(t, err)
+	assert.False(t, entitled)
+}
+
+func TestHandleWebhook_Skips(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeBillingRepository()
+	svc := newTestService(repo, &fakePayments{}, now)
+	ctx := context.Background()
+
+	unknownCustomer := `{"id":"sub_1","customer":"cus_404","status":"active","items":{"data":[{"price":{"id":"price_monthly"}}]}}`
+	payload, sig := signedEvent(t, "evt_1", stripe.EventSubscriptionUpdated, now, unknownCustomer)
+	assert.NoError(t, svc.HandleWebhook(ctx, payload, sig), "unknown customers are skipped, not retried")
+
+	payload, sig = signedEvent(t, "evt_2", stripe.EventSubscriptionUpdated, now, unknownCustomer)
+	err := svc.HandleWebhook(ctx, payload, sig+"0")
+	assert.ErrorIs(t, err, stripe.ErrInvalidSignature)
+
+	userID := uuid.New()
+	checkout := fmt.Sprintf(`{"id":"cs_1","customer":"cus_7","client_reference_id":%q}`, userID)
+	payload, sig = signedEvent(t, "evt_3", stripe.EventCheckoutCompleted, now, checkout)
+	require.NoError(t, svc.HandleWebhook(ctx, payload, sig))
+	linked, err := repo.GetUserByCustomerID(ctx, "cus_7")
+	require.NoError(t, err)
+	assert.Equal(t, userID, linked)
+}
+
+func TestPaidFeatures(t *testing.T) {
+	svc := NewService(newFakeBillingRepository(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.Equal(t, []string{featureflags.AggregatorSync}, svc.PaidFeatures())
+}
@@ -0,0 +1,300 @@
+package categorization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+)
+
+// ============================================================================
+// Categorization Accuracy (Internal Integration)
+// ============================================================================
+// Transactions remember the category the engine assigned (auto_category_id) and
+// the rule or merchant behind it. A prediction counts as corrected once the
+// transaction's category differs from it, whether the user recategorized it
+// directly or applied a newer rule to existing transactions.
+//
+// - GetCategorizationAccuracy: overall, per-rule and per-merchant accuracy,
+//   with the rules worth reviewing
+// - SetTransactionCategory: recategorize one transaction
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetCategorizationAccuracyRequest/Response, RuleAccuracy, RuleReviewSuggestion
+// - SetTransactionCategoryRequest/Response
+
+const (
+	// minReviewSamples is how many predictions a rule needs before its accuracy is judged
+	minReviewSamples = 5
+	// reviewAccuracyThreshold flags rules that are right less often than this
+	reviewAccuracyThreshold = 0.7
+)
+
+// Prediction sources, used as metric labels
+const (
+	SourceRule     = "rule"
+	SourceMerchant = "merchant"
+)
+
+// RuleAccuracy is how often predictions from one rule or merchant were kept
+type RuleAccuracy struct {
+	RuleID             *uuid.UUID // Set for rule predictions
+	MerchantID         *uuid.UUID // Set for merchant predictions
+	Pattern            string     // Pattern of the rule or merchant; empty once it was deleted
+	AssignedCategoryID *uuid.UUID // Category the rule currently assigns
+	Predictions        int
+	Corrections        int
+	Accuracy           float64 // Share of predictions kept, 0-1
+
+	// CorrectedToCategoryID is the category users most often switched to
+	CorrectedToCategoryID *uuid.UUID
+}
+
+// RuleReviewSuggestion flags a rule users keep correcting
+type RuleReviewSuggestion struct {
+	RuleID                uuid.UUID
+	Pattern               string
+	Predictions           int
+	Corrections           int
+	Accuracy              float64
+	SuggestedCategoryID   *uuid.UUID // Category users most often corrected to, if any
+	SuggestedCategoryName string
+}
+
+// CategorizationAccuracy summarizes how well automatic categorization held up
+type CategorizationAccuracy struct {
+	Since       time.Time // Zero for all time
+	Predictions int
+	Corrections int
+	Accuracy    float64 // 1 when there were no predictions
+
+	Rules             []RuleAccuracy // Sorted by accuracy, worst first
+	Merchants         []RuleAccuracy // Sorted by accuracy, worst first
+	ReviewSuggestions []RuleReviewSuggestion
+}
+
+// GetCategorizationAccuracy measures automatic categories assigned to
+// transactions posted since the given time (zero for all time) against the
+// categories they have now
+func (s *Service) GetCategorizationAccuracy(ctx context.Context, userID uuid.UUID, since time.Time) (*CategorizationAccuracy, error) {
+	outcomes, err := s.repo.GetPredictionOutcomes(ctx, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorization outcomes: %w", err)
+	}
+
+	result := &CategorizationAccuracy{Since: since, Accuracy: 1}
+	for _, o := range outcomes {
+		result.Predictions += o.Predictions
+		result.Corrections += o.Corrections
+		if o.RuleID != nil {
+			result.Rules = append(result.Rules, o)
+		} else {
+			result.Merchants = append(result.Merchants, o)
+		}
+	}
+	if result.Predictions > 0 {
+		result.Accuracy = accuracy(result.Predictions, result.Corrections)
+	}
+	sortByAccuracy(result.Rules)
+	sortByAccuracy(result.Merchants)
+
+	result.ReviewSuggestions = ReviewSuggestions(result.Rules)
+	if len(result.ReviewSuggestions) > 0 {
+		names, err := s.repo.GetCategoryNames(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get category names: %w", err)
+		}
+		for i := range result.ReviewSuggestions {
+			if id := result.ReviewSuggestions[i].SuggestedCategoryID; id != nil {
+				result.ReviewSuggestions[i].SuggestedCategoryName = names[*id]
+			}
+		}
+	}
+	return result, nil
+}
+
+// ReviewSuggestions picks the rules with enough predictions that users
+// corrected too often, worst first. A rule that was deleted is skipped.
+func ReviewSuggestions(rules []RuleAccuracy) []RuleReviewSuggestion {
+	var suggestions []RuleReviewSuggestion
+	for _, r := range rules {
+		if r.RuleID == nil || r.Pattern == "" {
+			continue
+		}
+		if r.Predictions < minReviewSamples || r.Accuracy >= reviewAccuracyThreshold {
+			continue
+		}
+		suggestions = append(suggestions, RuleReviewSuggestion{
+			RuleID:              *r.RuleID,
+			Pattern:             r.Pattern,
+			Predictions:         r.Predictions,
+			Corrections:         r.Corrections,
+			Accuracy:            r.Accuracy,
+			SuggestedCategoryID: r.CorrectedToCategoryID,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Accuracy != suggestions[j].Accuracy {
+			return suggestions[i].Accuracy < suggestions[j].Accuracy
+		}
+		return suggestions[i].Predictions > suggestions[j].Predictions
+	})
+	return suggestions
+}
+
+// SetTransactionCategory recategorizes one transaction. Overriding an
+// automatic category is recorded as a correction.
+// Returns sql.ErrNoRows if the transaction doesn't belong to the user.
+func (s *Service) SetTransactionCategory(ctx context.Context, userID, transactionID uuid.UUID, categoryID *uuid.UUID) error {
+	prediction, err := s.repo.SetTransactionCategory(ctx, userID, transactionID, categoryID)
+	if err != nil {
+		return err
+	}
+	if prediction.CategoryID != nil && !sameCategory(prediction.CategoryID, categoryID) {
+		observability.CategorizationCorrectionsTotal.WithLabelValues(PredictionSource(prediction.RuleID)).Inc()
+	}
+	return nil
+}
+
+// PredictionSource labels what produced an automatic category
+func PredictionSource(ruleID *uuid.UUID) string {
+	if ruleID != nil {
+		return SourceRule
+	}
+	return SourceMerchant
+}
+
+func accuracy(predictions, corrections int) float64 {
+	if predictions == 0 {
+		return 1
+	}
+	return float64(predictions-corrections) / float64(predictions)
+}
+
+func sortByAccuracy(outcomes []RuleAccuracy) {
+	sort.SliceStable(outcomes, func(i, j int) bool {
+		if outcomes[i].Accuracy != outcomes[j].Accuracy {
+			return outcomes[i].Accuracy < outcomes[j].Accuracy
+		}
+		return outcomes[i].Predictions > outcomes[j].Predictions
+	})
+}
+
+func sameCategory(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// storedPrediction is the automatic categorization recorded on a transaction
+type storedPrediction struct {
+	CategoryID *uuid.UUID
+	RuleID     *uuid.UUID
+}
+
+// GetPredictionOutcomes groups automatically categorized transactions by the
+// rule or merchant that categorized them
+func (r *Repository) GetPredictionOutcomes(ctx context.Context, userID uuid.UUID, since time.Time) ([]RuleAccuracy, error) {
+	query := `
+		SELECT
+			t.categorized_by_rule_id,
+			t.categorized_by_merchant_id,
+			COALESCE(cr.match_pattern, m.raw_pattern, ''),
+			cr.assigned_category_id,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE t.category_id IS DISTINCT FROM t.auto_category_id),
+			MODE() WITHIN GROUP (ORDER BY t.category_id)
+				FILTER (WHERE t.category_id IS DISTINCT FROM t.auto_category_id)
+		FROM transactions t
+		LEFT JOIN category_rules cr ON cr.id = t.categorized_by_rule_id
+		LEFT JOIN merchants m ON m.id = t.categorized_by_merchant_id
+		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
+		  AND t.auto_category_id IS NOT NULL
+		  AND t.posted_at >= $2
+		GROUP BY t.categorized_by_rule_id, t.categorized_by_merchant_id,
+			cr.match_pattern, m.raw_pattern, cr.assigned_category_id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outcomes []RuleAccuracy
+	for rows.Next() {
+		var o RuleAccuracy
+		if err := rows.Scan(
+			&o.RuleID,
+			&o.MerchantID,
+			&o.Pattern,
+			&o.AssignedCategoryID,
+			&o.Predictions,
+			&o.Corrections,
+			&o.CorrectedToCategoryID,
+		); err != nil {
+			return nil, err
+		}
+		o.Accuracy = accuracy(o.Predictions, o.Corrections)
+		outcomes = append(outcomes, o)
+	}
+
+	return outcomes, rows.Err()
+}
+
+// GetCategoryNames maps the user's categories to their names
+func (r *Repository) GetCategoryNames(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]string, error) {
+	query := `
+		SELECT id, name
+		FROM categories
+		WHERE user_id = $1
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[uuid.UUID]string)
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+
+	return names, rows.Err()
+}
+
+// SetTransactionCategory updates a transaction's category and returns the
+// automatic categorization recorded on it
+func (r *Repository) SetTransactionCategory(ctx context.Context, userID, transactionID uuid.UUID, categoryID *uuid.UUID) (*storedPrediction, error) {
+	query := `
+		UPDATE transactions
+		SET category_id = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING auto_category_id, categorized_by_rule_id
+	`
+
+	var p storedPrediction
+	err := r.db.QueryRow(ctx, query, transactionID, userID, categoryID).Scan(&p.CategoryID, &p.RuleID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set transaction category: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,194 @@
+package categorization
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// Categorization Explainability (Internal Integration)
+// ============================================================================
+// Explains how a description gets its category, so users can trust automatic
+// categorization and know what to fix: the rule, merchant or fuzzy match that
+// decided, the alternatives considered with their scores, and whether one of
+// the user's own rules overrode the global merchants.
+//
+// - ExplainCategorization: runs the exact engine, then fuzzy matching, then
+//   merchant enrichment, like CategorizeWithFallback
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ExplainCategorizationRequest/Response, CategorizationCandidate
+
+// Sources of an explained categorization, besides SourceRule and SourceMerchant
+const (
+	SourceFuzzy        = "fuzzy"
+	SourceMerchantHint = "merchant_hint"
+	SourceNone         = "none"
+)
+
+const (
+	// ExplainFuzzyThreshold is the fuzzy score ExplainCategorization accepts,
+	// the one manual transactions are categorized with
+	ExplainFuzzyThreshold = 75
+	// explainFuzzyAlternatives is how many fuzzy alternatives are reported
+	explainFuzzyAlternatives = 5
+	// exactMatchScore is the score reported for exact pattern matches
+	exactMatchScore = 100
+)
+
+// CategorizationCandidate is a rule or merchant considered for a description
+type CategorizationCandidate struct {
+	Source      string // SourceRule, SourceMerchant or SourceFuzzy
+	Pattern     string
+	CleanName   string
+	CategoryID  *uuid.UUID
+	RuleID      *uuid.UUID
+	MerchantID  *uuid.UUID
+	UserDefined bool // One of the user's rules or merchants, not a global merchant
+	Score       int  // 100 for exact matches, the similarity score (0-100) for fuzzy ones
+}
+
+// CategorizationExplanation is how a description was categorized and why
+type CategorizationExplanation struct {
+	Description string
+	Result      *CategorizationResult
+	Source      string                   // What decided: SourceRule, SourceMerchant, SourceFuzzy, SourceMerchantHint or SourceNone
+	Chosen      *CategorizationCandidate // nil unless a rule, merchant or fuzzy match decided
+
+	// Alternatives are the other exact matches by priority, then the closest
+	// fuzzy patterns by score
+	Alternatives []CategorizationCandidate
+
+	// UserOverride is set when a user rule or merchant decided although a
+	// global merchant matched too
+	UserOverride bool
+}
+
+// ExplainCategorization categorizes a description the way manual transactions
+// are, reporting which match decided and what else was considered
+func (s *Service) ExplainCategorization(ctx context.Context, userID uuid.UUID, description string) (*CategorizationExplanation, error) {
+	engine, err := s.getOrBuildEngine(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := s.getOrBuildFuzzyMatcher(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	merchants, err := s.getMerchants(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userMerchants := make(map[uuid.UUID]bool)
+	for _, m := range merchants {
+		if m.UserID != nil && *m.UserID == userID {
+			userMerchants[m.ID] = true
+		}
+	}
+	userDefined := func(ruleID, merchantID *uuid.UUID) bool {
+		return ruleID != nil || (merchantID != nil && userMerchants[*merchantID])
+	}
+
+	explanation := &CategorizationExplanation{
+		Description: description,
+		Result:      &CategorizationResult{CleanMerchantName: cleanDescription(description)},
+		Source:      SourceNone,
+	}
+
+	// 1. Exact matches, highest priority first; the first one decides
+	seen := make(map[string]bool)
+	for i, match := range engine.MatchAll(description) {
+		source := SourceMerchant
+		if match.IsRule {
+			source = SourceRule
+		}
+		candidate := CategorizationCandidate{
+			Source:      source,
+			Pattern:     match.Pattern,
+			CleanName:   match.CleanName,
+			CategoryID:  match.CategoryID,
+			RuleID:      match.RuleID,
+			MerchantID:  match.MerchantID,
+			UserDefined: userDefined(match.RuleID, match.MerchantID),
+			Score:       exactMatchScore,
+		}
+		seen[candidateKey(candidate.RuleID, candidate.MerchantID)] = true
+		if i > 0 {
+			explanation.Alternatives = append(explanation.Alternatives, candidate)
+			if explanation.Chosen.UserDefined && !candidate.UserDefined {
+				explanation.UserOverride = true
+			}
+			continue
+		}
+
+		explanation.Chosen = &candidate
+		explanation.Source = source
+		if match.CleanName != "" {
+			explanation.Result.CleanMerchantName = match.CleanName
+		}
+		explanation.Result.CategoryID = match.CategoryID
+		explanation.Result.IsRecurring = match.IsRecurring
+		explanation.Result.RuleID = match.RuleID
+		explanation.Result.MerchantID = match.MerchantID
+		explanation.Result.MatchedPattern = match.Pattern
+	}
+
+	// 2. Fuzzy matches; the best one decides if nothing matched exactly and
+	// it scores at least the threshold
+	for _, match := range matcher.RankMatches(description, explainFuzzyAlternatives) {
+		key := candidateKey(match.RuleID, match.MerchantID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidate := CategorizationCandidate{
+			Source:      SourceFuzzy,
+			Pattern:     match.Pattern,
+			CleanName:   match.CleanName,
+			CategoryID:  match.CategoryID,
+			RuleID:      match.RuleID,
+			MerchantID:  match.MerchantID,
+			UserDefined: userDefined(match.RuleID, match.MerchantID),
+			Score:       match.Score,
+		}
+		if explanation.Chosen != nil || match.Score < ExplainFuzzyThreshold {
+			explanation.Alternatives = append(explanation.Alternatives, candidate)
+			continue
+		}
+
+		explanation.Chosen = &candidate
+		explanation.Source = SourceFuzzy
+		if match.CleanName != "" {
+			explanation.Result.CleanMerchantName = match.CleanName
+		}
+		explanation.Result.CategoryID = match.CategoryID
+		explanation.Result.RuleID = match.RuleID
+		explanation.Result.MerchantID = match.MerchantID
+		explanation.Result.MatchedPattern = match.Pattern
+	}
+
+	// 3. Merchant enrichment, when nothing matched
+	if explanation.Chosen == nil {
+		results := []*CategorizationResult{explanation.Result}
+		before := *explanation.Result
+		s.applyMerchantHints(ctx, userID, []string{description}, results)
+		if *explanation.Result != before {
+			explanation.Source = SourceMerchantHint
+		}
+	}
+
+	return explanation, nil
+}
+
+// candidateKey identifies the rule or merchant behind a candidate
+func candidateKey(ruleID, merchantID *uuid.UUID) string {
+	if ruleID != nil {
+		return "rule:" + ruleID.String()
+	}
+	if merchantID != nil {
+		return "merchant:" + merchantID.String()
+	}
+	return ""
+}
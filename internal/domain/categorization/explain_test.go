@@ -0,0 +1,73 @@
+package categorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainCategorization(t *testing.T) {
+	userID := uuid.New()
+	travel, transport, coffee, hinted := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	rule := CategoryRule{ID: uuid.New(), UserID: userID, MatchPattern: "%UBER%", CleanName: strPtr("Uber"), AssignedCategoryID: &travel}
+	uberTrip := Merchant{ID: uuid.New(), RawPattern: "%UBER TRIP%", CleanName: "Uber Trip", DefaultCategoryID: &transport, IsSystem: true}
+	starbucks := Merchant{ID: uuid.New(), UserID: &userID, RawPattern: "%STARBUCKS%", CleanName: "Starbucks", DefaultCategoryID: &coffee}
+
+	hinter := &fakeHinter{hints: []*MerchantHint{{Name: "Caixa Geral", CategoryID: &hinted}}}
+	s := NewService(nil).WithMerchantHints(hinter)
+	s.ruleCache[userID] = []CategoryRule{rule}
+	s.merchantCache = []Merchant{uberTrip, starbucks}
+	ctx := context.Background()
+
+	t.Run("user rule overrides a global merchant", func(t *testing.T) {
+		explanation, err := s.ExplainCategorization(ctx, userID, "UBER TRIP LISBOA")
+		require.NoError(t, err)
+		assert.Equal(t, SourceRule, explanation.Source)
+		require.NotNil(t, explanation.Chosen)
+		assert.Equal(t, rule.ID, *explanation.Chosen.RuleID)
+		assert.True(t, explanation.Chosen.UserDefined)
+		assert.True(t, explanation.UserOverride)
+		assert.Equal(t, "Uber", explanation.Result.CleanMerchantName)
+		assert.Equal(t, travel, *explanation.Result.CategoryID)
+
+		require.NotEmpty(t, explanation.Alternatives)
+		alt := explanation.Alternatives[0]
+		assert.Equal(t, SourceMerchant, alt.Source)
+		assert.Equal(t, uberTrip.ID, *alt.MerchantID)
+		assert.Equal(t, exactMatchScore, alt.Score)
+		assert.False(t, alt.UserDefined)
+		for _, alt := range explanation.Alternatives[1:] {
+			assert.Equal(t, SourceFuzzy, alt.Source, "fuzzy alternatives skip exact matches")
+		}
+	})
+
+	t.Run("fuzzy match decides when nothing matches exactly", func(t *testing.T) {
+		explanation, err := s.ExplainCategorization(ctx, userID, "STARBUKS")
+		require.NoError(t, err)
+		assert.Equal(t, SourceFuzzy, explanation.Source)
+		require.NotNil(t, explanation.Chosen)
+		assert.Equal(t, starbucks.ID, *explanation.Chosen.MerchantID)
+		assert.True(t, explanation.Chosen.UserDefined)
+		assert.GreaterOrEqual(t, explanation.Chosen.Score, ExplainFuzzyThreshold)
+		assert.False(t, explanation.UserOverride)
+		assert.Equal(t, coffee, *explanation.Result.CategoryID)
+		for _, alt := range explanation.Alternatives {
+			assert.LessOrEqual(t, alt.Score, explanation.Chosen.Score)
+		}
+	})
+
+	t.Run("merchant enrichment is the last resort", func(t *testing.T) {
+		explanation, err := s.ExplainCategorization(ctx, userID, "TRF CGD 0042")
+		require.NoError(t, err)
+		assert.Equal(t, SourceMerchantHint, explanation.Source)
+		assert.Nil(t, explanation.Chosen)
+		assert.Equal(t, "Caixa Geral", explanation.Result.CleanMerchantName)
+		assert.Equal(t, hinted, *explanation.Result.CategoryID)
+		for _, alt := range explanation.Alternatives {
+			assert.Less(t, alt.Score, ExplainFuzzyThreshold)
+		}
+	})
+}
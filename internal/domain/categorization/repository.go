@@ -2,6 +2,7 @@ package categorization
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 
 	"github.com/google/uuid"
@@ -38,6 +39,7 @@ type CategorizationResult struct {
 	IsRecurring       bool
 	RuleID            *uuid.UUID // Which rule matched, if any
 	MerchantID        *uuid.UUID // Which merchant matched, if any
+	MatchedPattern    string     // The rule or merchant pattern that matched, if any
 }
 
 // Repository handles database operations for categorization
@@ -55,7 +57,7 @@ func (r *Repository) GetUserRules(ctx context.Context, userID uuid.UUID) ([]Cate
 	query := `
 		SELECT id, user_id, match_pattern, clean_name, assigned_category_id, is_recurring, priority
 		FROM category_rules
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY priority DESC, created_at DESC
 	`
 
@@ -122,11 +124,20 @@ func (r *Repository) GetMerchants(ctx context.Context, userID *uuid.UUID) ([]Mer
 	return merchants, rows.Err()
 }
 
-// CreateRule creates a new categorization rule
+// CreateRule creates a new categorization rule. A rule for the same pattern
+// sitting in the trash is replaced.
 func (r *Repository) CreateRule(ctx context.Context, rule *CategoryRule) error {
 	query := `
 		INSERT INTO category_rules (user_id, match_pattern, clean_name, assigned_category_id, is_recurring, priority)
 		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, match_pattern) DO UPDATE SET
+			clean_name = EXCLUDED.clean_name,
+			assigned_category_id = EXCLUDED.assigned_category_id,
+			is_recurring = EXCLUDED.is_recurring,
+			priority = EXCLUDED.priority,
+			created_at = NOW(),
+			deleted_at = NULL
+		WHERE category_rules.deleted_at IS NOT NULL
 		RETURNING id
 	`
 
@@ -140,12 +151,27 @@ func (r *Repository) CreateRule(ctx context.Context, rule *CategoryRule) error {
 	).Scan(&rule.ID)
 }
 
+// DeleteRule moves a user's rule to the trash
+func (r *Repository) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE category_rules SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 // UpdateTransactionsMerchant updates merchant_name for matching transactions
 func (r *Repository) UpdateTransactionsMerchant(ctx context.Context, userID uuid.UUID, pattern, cleanName string, categoryID *uuid.UUID) (int64, error) {
 	query := `
 		UPDATE transactions
 		SET merchant_name = $3, category_id = $4
-		WHERE user_id = $1 AND description ILIKE $2
+		WHERE user_id = $1 AND description ILIKE $2 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.Exec(ctx, query, userID, pattern, cleanName, categoryID)
@@ -161,7 +187,7 @@ func (r *Repository) FindRuleByPattern(ctx context.Context, userID uuid.UUID, pa
 	query := `
 		SELECT id, user_id, match_pattern, clean_name, assigned_category_id, is_recurring, priority
 		FROM category_rules
-		WHERE user_id = $1 AND match_pattern = $2
+		WHERE user_id = $1 AND match_pattern = $2 AND deleted_at IS NULL
 	`
 
 	var rule CategoryRule
@@ -191,3 +217,106 @@ func matchPattern(description, pattern string) bool {
 	cleanPattern := strings.Trim(pattern, "%")
 	return strings.Contains(strings.ToUpper(description), strings.ToUpper(cleanPattern))
 }
+
+// transactionRuleColumns are the columns scanned by scanTransactionRule
+const transactionRuleColumns = `id, user_id, name, match_pattern, amount_above_minor, amount_below_minor,
+	add_tags, rename_to, priority, hit_count, last_hit_at, created_at`
+
+func scanTransactionRule(row pgx.Row) (TransactionRule, error) {
+	var rule TransactionRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.UserID,
+		&rule.Name,
+		&rule.MatchPattern,
+		&rule.AmountAboveMinor,
+		&rule.AmountBelowMinor,
+		&rule.AddTags,
+		&rule.RenameTo,
+		&rule.Priority,
+		&rule.HitCount,
+		&rule.LastHitAt,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
+
+// GetTransactionRules fetches a user's transaction rules, highest priority first
+func (r *Repository) GetTransactionRules(ctx context.Context, userID uuid.UUID) ([]TransactionRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+transactionRuleColumns+`
+		FROM transaction_rules
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY priority DESC, created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []TransactionRule
+	for rows.Next() {
+		rule, err := scanTransactionRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// CreateTransactionRule inserts a transaction rule, filling in its ID and
+// creation time
+func (r *Repository) CreateTransactionRule(ctx context.Context, rule *TransactionRule) error {
+	tags := rule.AddTags
+	if tags == nil {
+		tags = []string{}
+	}
+	return r.db.QueryRow(ctx, `
+		INSERT INTO transaction_rules (user_id, name, match_pattern, amount_above_minor, amount_below_minor, add_tags, rename_to, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`,
+		rule.UserID,
+		rule.Name,
+		rule.MatchPattern,
+		rule.AmountAboveMinor,
+		rule.AmountBelowMinor,
+		tags,
+		rule.RenameTo,
+		rule.Priority,
+	).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+// DeleteTransactionRule moves a user's transaction rule to the trash
+func (r *Repository) DeleteTransactionRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE transaction_rules SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AddTransactionRuleHits adds hits to the counters of a user's rules and
+// stamps them as last hit now
+func (r *Repository) AddTransactionRuleHits(ctx context.Context, userID uuid.UUID, hits map[uuid.UUID]int64) error {
+	ids := make([]uuid.UUID, 0, len(hits))
+	counts := make([]int64, 0, len(hits))
+	for id, n := range hits {
+		ids = append(ids, id)
+		counts = append(counts, n)
+	}
+	_, err := r.db.Exec(ctx, `
+		UPDATE transaction_rules tr
+		SET hit_count = tr.hit_count + h.hits, last_hit_at = NOW()
+		FROM UNNEST($2::UUID[], $3::BIGINT[]) AS h(id, hits)
+		WHERE tr.id = h.id AND tr.user_id = $1
+	`, userID, ids, counts)
+	return err
+}
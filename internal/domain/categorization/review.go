@@ -0,0 +1,358 @@
+package categorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+)
+
+// ============================================================================
+// Categorization Review Queue (Internal Integration)
+// ============================================================================
+// A guided workflow for transactions categorization couldn't settle: those
+// without a category and those categorized only from merchant enrichment
+// (no rule or merchant pattern matched). They are grouped by normalized
+// merchant with suggested categories, and resolving a group applies the
+// chosen category to its transactions and optionally creates a rule for the
+// next imports.
+//
+// - GetReviewQueue: review items, the merchants with most transactions first
+// - ResolveReviewItem: categorize or dismiss an item's transactions
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetReviewQueueRequest/Response, ReviewItem, CategorySuggestion
+// - ResolveReviewItemRequest/Response
+
+// Review reasons
+const (
+	ReviewUncategorized = "uncategorized"
+	ReviewLowConfidence = "low_confidence" // Categorized from merchant enrichment only
+)
+
+const (
+	// DefaultReviewItems is how many items GetReviewQueue returns by default
+	DefaultReviewItems = 20
+	// maxReviewItems caps the items GetReviewQueue returns
+	maxReviewItems = 100
+	// reviewCandidateLimit caps the transactions considered, newest first
+	reviewCandidateLimit = 2000
+	// maxReviewSuggestions is how many categories are suggested per item
+	maxReviewSuggestions = 3
+	// reviewFuzzyCandidates is how many fuzzy matches are ranked per item
+	reviewFuzzyCandidates = 10
+)
+
+// ErrInvalidReviewResolution is returned for resolutions without
+// transactions, or creating a rule without a category or pattern
+var ErrInvalidReviewResolution = errors.New("invalid review resolution")
+
+// ReviewTransaction is a transaction waiting for review
+type ReviewTransaction struct {
+	ID             uuid.UUID
+	Description    string
+	MerchantName   string
+	AmountMinor    int64
+	CurrencyCode   string
+	PostedAt       time.Time
+	CategoryID     *uuid.UUID
+	AutoCategoryID *uuid.UUID
+}
+
+// CategorySuggestion is a category suggested for a review item
+type CategorySuggestion struct {
+	CategoryID uuid.UUID
+	Source     string // SourceFuzzy or SourceMerchantHint
+	Pattern    string // The fuzzy pattern, for SourceFuzzy
+	Score      int    // The fuzzy similarity score (0-100), for SourceFuzzy
+}
+
+// ReviewItem groups the transactions of one merchant waiting for review
+type ReviewItem struct {
+	MerchantKey  string // Normalized merchant, e.g. "CONTINENTE"; a good rule pattern
+	DisplayName  string
+	Reason       string              // ReviewUncategorized if any transaction has no category, else ReviewLowConfidence
+	Transactions []ReviewTransaction // Newest first
+	Suggestions  []CategorySuggestion
+}
+
+// ReviewQueue is what's waiting for review
+type ReviewQueue struct {
+	Items        []ReviewItem
+	TotalItems   int // Items before the limit
+	Transactions int // Transactions across all items
+}
+
+// ReviewResolution is the user's choice for a review item
+type ReviewResolution struct {
+	TransactionIDs []uuid.UUID
+	CategoryID     *uuid.UUID // nil dismisses the item, keeping categories as they are
+	CreateRule     bool       // Also categorize future transactions matching Pattern
+	Pattern        string     // Rule pattern, usually the item's MerchantKey
+	CleanName      string     // Merchant name the rule assigns
+}
+
+// ReviewResolutionResult is what resolving a review item did
+type ReviewResolutionResult struct {
+	Updated int
+	Rule    *CategoryRule // The rule created, or the existing one for the pattern
+}
+
+// GetReviewQueue returns up to limit review items (DefaultReviewItems when
+// limit is 0), the merchants with most transactions first
+func (s *Service) GetReviewQueue(ctx context.Context, userID uuid.UUID, limit int) (*ReviewQueue, error) {
+	if limit <= 0 {
+		limit = DefaultReviewItems
+	}
+	if limit > maxReviewItems {
+		limit = maxReviewItems
+	}
+
+	candidates, err := s.repo.GetReviewCandidates(ctx, userID, reviewCandidateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review candidates: %w", err)
+	}
+
+	items := groupReviewCandidates(candidates)
+	queue := &ReviewQueue{TotalItems: len(items), Transactions: len(candidates)}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if len(items) > 0 {
+		s.suggestReviewCategories(ctx, userID, items)
+	}
+	queue.Items = items
+	return queue, nil
+}
+
+// ResolveReviewItem applies the chosen category to an item's transactions and
+// takes them out of the queue, optionally creating a rule for the pattern
+func (s *Service) ResolveReviewItem(ctx context.Context, userID uuid.UUID, resolution ReviewResolution) (*ReviewResolutionResult, error) {
+	pattern := strings.TrimSpace(resolution.Pattern)
+	switch {
+	case len(resolution.TransactionIDs) == 0:
+		return nil, fmt.Errorf("%w: no transactions", ErrInvalidReviewResolution)
+	case resolution.CreateRule && resolution.CategoryID == nil:
+		return nil, fmt.Errorf("%w: a rule needs a category", ErrInvalidReviewResolution)
+	case resolution.CreateRule && strings.Trim(pattern, "%") == "":
+		return nil, fmt.Errorf("%w: a rule needs a pattern", ErrInvalidReviewResolution)
+	}
+
+	predictions, err := s.repo.ResolveReviewTransactions(ctx, userID, resolution.TransactionIDs, resolution.CategoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve review transactions: %w", err)
+	}
+	if resolution.CategoryID != nil {
+		for _, p := range predictions {
+			if p.CategoryID != nil && !sameCategory(p.CategoryID, resolution.CategoryID) {
+				observability.CategorizationCorrectionsTotal.WithLabelValues(PredictionSource(p.RuleID)).Inc()
+			}
+		}
+	}
+	result := &ReviewResolutionResult{Updated: len(predictions)}
+
+	if resolution.CreateRule {
+		if !strings.Contains(pattern, "%") {
+			pattern = "%" + pattern + "%"
+		}
+		cleanName := strings.TrimSpace(resolution.CleanName)
+		if cleanName == "" {
+			cleanName = toTitleCase(strings.Trim(pattern, "%"))
+		}
+		rule, _, err := s.CreateRule(ctx, userID, pattern, cleanName, resolution.CategoryID, false, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rule: %w", err)
+		}
+		result.Rule = rule
+	}
+	return result, nil
+}
+
+// groupReviewCandidates groups transactions by normalized merchant, the
+// groups with most transactions first, then the most recent
+func groupReviewCandidates(candidates []ReviewTransaction) []ReviewItem {
+	index := make(map[string]int)
+	var items []ReviewItem
+	for _, tx := range candidates {
+		name := tx.MerchantName
+		if strings.TrimSpace(name) == "" {
+			name = tx.Description
+		}
+		key := reviewMerchantKey(name)
+		i, ok := index[key]
+		if !ok {
+			i = len(items)
+			index[key] = i
+			items = append(items, ReviewItem{
+				MerchantKey: key,
+				DisplayName: toTitleCase(key),
+				Reason:      ReviewLowConfidence,
+			})
+		}
+		items[i].Transactions = append(items[i].Transactions, tx)
+		if tx.CategoryID == nil {
+			items[i].Reason = ReviewUncategorized
+		}
+	}
+
+	for i := range items {
+		sort.SliceStable(items[i].Transactions, func(a, b int) bool {
+			return items[i].Transactions[a].PostedAt.After(items[i].Transactions[b].PostedAt)
+		})
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		if len(items[a].Transactions) != len(items[b].Transactions) {
+			return len(items[a].Transactions) > len(items[b].Transactions)
+		}
+		return items[a].Transactions[0].PostedAt.After(items[b].Transactions[0].PostedAt)
+	})
+	return items
+}
+
+// reviewMerchantKey normalizes a merchant name or description for grouping:
+// bank prefixes and words with digits (store numbers, references, dates) are
+// dropped, so "COMPRA CONTINENTE 0452" and "Continente 0311" group together
+func reviewMerchantKey(name string) string {
+	cleaned := strings.ToUpper(cleanDescription(name))
+	var words []string
+	for _, word := range strings.Fields(cleaned) {
+		word = strings.Trim(word, "*#-/.,")
+		if word == "" || strings.ContainsAny(word, "0123456789") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return cleaned
+	}
+	return strings.Join(words, " ")
+}
+
+// suggestReviewCategories fills in the items' suggestions: close fuzzy
+// matches first, then merchant enrichment, then weaker fuzzy matches
+func (s *Service) suggestReviewCategories(ctx context.Context, userID uuid.UUID, items []ReviewItem) {
+	var hints []*MerchantHint
+	if s.hinter != nil {
+		keys := make([]string, len(items))
+		for i, item := range items {
+			keys[i] = item.MerchantKey
+		}
+		hints, _ = s.hinter.HintMerchants(ctx, userID, keys) // Fails open
+	}
+	matcher, err := s.getOrBuildFuzzyMatcher(ctx, userID)
+	if err != nil {
+		matcher = nil // Fails open
+	}
+
+	for i := range items {
+		var strong, weak []CategorySuggestion
+		if matcher != nil {
+			for _, match := range matcher.RankMatches(items[i].MerchantKey, reviewFuzzyCandidates) {
+				if match.CategoryID == nil {
+					continue
+				}
+				suggestion := CategorySuggestion{CategoryID: *match.CategoryID, Source: SourceFuzzy, Pattern: match.Pattern, Score: match.Score}
+				if match.Score >= ExplainFuzzyThreshold {
+					strong = append(strong, suggestion)
+				} else {
+					weak = append(weak, suggestion)
+				}
+			}
+		}
+
+		suggestions := strong
+		if i < len(hints) && hints[i] != nil && hints[i].CategoryID != nil {
+			suggestions = append(suggestions, CategorySuggestion{CategoryID: *hints[i].CategoryID, Source: SourceMerchantHint})
+		}
+		suggestions = append(suggestions, weak...)
+
+		seen := make(map[uuid.UUID]bool)
+		for _, suggestion := range suggestions {
+			if seen[suggestion.CategoryID] || len(items[i].Suggestions) == maxReviewSuggestions {
+				continue
+			}
+			seen[suggestion.CategoryID] = true
+			items[i].Suggestions = append(items[i].Suggestions, suggestion)
+		}
+	}
+}
+
+// GetReviewCandidates returns the user's transactions waiting for review,
+// newest first: those without a category and those categorized automatically
+// without a rule or merchant match, not yet reviewed
+func (r *Repository) GetReviewCandidates(ctx context.Context, userID uuid.UUID, limit int) ([]ReviewTransaction, error) {
+	query := `
+		SELECT id, description, COALESCE(merchant_name, ''), amount_minor, currency_code,
+		       posted_at, category_id, auto_category_id
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL AND category_reviewed_at IS NULL
+		  AND (category_id IS NULL
+			OR (category_id = auto_category_id
+				AND categorized_by_rule_id IS NULL AND categorized_by_merchant_id IS NULL))
+		ORDER BY posted_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []ReviewTransaction
+	for rows.Next() {
+		var tx ReviewTransaction
+		if err := rows.Scan(
+			&tx.ID,
+			&tx.Description,
+			&tx.MerchantName,
+			&tx.AmountMinor,
+			&tx.CurrencyCode,
+			&tx.PostedAt,
+			&tx.CategoryID,
+			&tx.AutoCategoryID,
+		); err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+
+	return txs, rows.Err()
+}
+
+// ResolveReviewTransactions marks the user's transactions reviewed, setting
+// their category unless categoryID is nil, and returns the automatic
+// categorization recorded on each one updated
+func (r *Repository) ResolveReviewTransactions(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, categoryID *uuid.UUID) ([]storedPrediction, error) {
+	query := `
+		UPDATE transactions
+		SET category_id = COALESCE($3, category_id),
+		    category_reviewed_at = NOW(),
+		    updated_at = NOW()
+		WHERE user_id = $1 AND id = ANY($2) AND deleted_at IS NULL
+		RETURNING auto_category_id, categorized_by_rule_id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, ids, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []storedPrediction
+	for rows.Next() {
+		var p storedPrediction
+		if err := rows.Scan(&p.CategoryID, &p.RuleID); err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, p)
+	}
+
+	return predictions, rows.Err()
+}
@@ -0,0 +1,82 @@
+package categorization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupReviewCandidates(t *testing.T) {
+	hinted := uuid.New()
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	candidates := []ReviewTransaction{
+		{ID: uuid.New(), Description: "COMPRA CONTINENTE 0452", PostedAt: day},
+		{ID: uuid.New(), Description: "BOLT.EU/O/2603", MerchantName: "Bolt", PostedAt: day.AddDate(0, 0, 1), CategoryID: &hinted, AutoCategoryID: &hinted},
+		{ID: uuid.New(), Description: "Continente 0311", PostedAt: day.AddDate(0, 0, 2)},
+	}
+
+	items := groupReviewCandidates(candidates)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "CONTINENTE", items[0].MerchantKey)
+	assert.Equal(t, "Continente", items[0].DisplayName)
+	assert.Equal(t, ReviewUncategorized, items[0].Reason)
+	require.Len(t, items[0].Transactions, 2)
+	assert.Equal(t, candidates[2].ID, items[0].Transactions[0].ID, "newest first")
+
+	assert.Equal(t, "BOLT", items[1].MerchantKey)
+	assert.Equal(t, ReviewLowConfidence, items[1].Reason)
+}
+
+func TestSuggestReviewCategories(t *testing.T) {
+	userID := uuid.New()
+	groceries, hinted := uuid.New(), uuid.New()
+	rule := CategoryRule{ID: uuid.New(), UserID: userID, MatchPattern: "%CONTINENTE%", CleanName: strPtr("Continente"), AssignedCategoryID: &groceries}
+
+	hinter := &fakeHinter{hints: []*MerchantHint{
+		{Name: "Continente", CategoryID: &groceries},
+		{Name: "Bolt", CategoryID: &hinted},
+	}}
+	s := NewService(nil).WithMerchantHints(hinter)
+	s.ruleCache[userID] = []CategoryRule{rule}
+	s.merchantCache = []Merchant{}
+
+	items := []ReviewItem{{MerchantKey: "CONTINENTES"}, {MerchantKey: "BOLT"}}
+	s.suggestReviewCategories(context.Background(), userID, items)
+
+	assert.Equal(t, []string{"CONTINENTES", "BOLT"}, hinter.asked)
+
+	require.Len(t, items[0].Suggestions, 1, "the hint repeats the fuzzy match's category")
+	assert.Equal(t, SourceFuzzy, items[0].Suggestions[0].Source)
+	assert.Equal(t, groceries, items[0].Suggestions[0].CategoryID)
+	assert.GreaterOrEqual(t, items[0].Suggestions[0].Score, ExplainFuzzyThreshold)
+
+	require.NotEmpty(t, items[1].Suggestions)
+	assert.Equal(t, SourceMerchantHint, items[1].Suggestions[0].Source)
+	assert.Equal(t, hinted, items[1].Suggestions[0].CategoryID)
+}
+
+func TestResolveReviewItem_Validation(t *testing.T) {
+	s := NewService(nil)
+	category := uuid.New()
+	ids := []uuid.UUID{uuid.New()}
+
+	tests := []struct {
+		name       string
+		resolution ReviewResolution
+	}{
+		{"no transactions", ReviewResolution{CategoryID: &category}},
+		{"rule without category", ReviewResolution{TransactionIDs: ids, CreateRule: true, Pattern: "CONTINENTE"}},
+		{"rule without pattern", ReviewResolution{TransactionIDs: ids, CategoryID: &category, CreateRule: true, Pattern: " %% "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ResolveReviewItem(context.Background(), uuid.New(), tt.resolution)
+			assert.ErrorIs(t, err, ErrInvalidReviewResolution)
+		})
+	}
+}
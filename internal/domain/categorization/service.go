@@ -29,6 +29,26 @@ type Service struct {
 	// Search index for full-text search (shared across users)
 	searchIndex *SearchIndex
 	searchMu    sync.RWMutex
+
+	// Transaction rules per user, run at import time
+	txRuleCache map[uuid.UUID]*TransactionRuleSet
+	txRuleMu    sync.RWMutex
+
+	// Merchant enrichment, consulted when nothing else matches
+	hinter MerchantHinter
+}
+
+// MerchantHint is what merchant enrichment suggests for a description
+type MerchantHint struct {
+	Name       string     // Canonical merchant name, if the merchant is known
+	CategoryID *uuid.UUID // User's category for the kind of merchant, if any
+}
+
+// MerchantHinter suggests merchant names and categories from merchant data
+// (the merchants domain). Results are aligned to descriptions; nil entries
+// have no hint.
+type MerchantHinter interface {
+	HintMerchants(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*MerchantHint, error)
 }
 
 // NewService creates a new categorization service
@@ -39,9 +59,18 @@ func NewService(repo *Repository) *Service {
 		merchantCache: nil,
 		engineCache:   make(map[uuid.UUID]*Engine),
 		fuzzyCache:    make(map[uuid.UUID]*FuzzyMatcher),
+		txRuleCache:   make(map[uuid.UUID]*TransactionRuleSet),
 	}
 }
 
+// WithMerchantHints adds merchant enrichment as the last fallback: descriptions
+// no rule or merchant pattern matched get the canonical merchant name and a
+// category from the merchant's MCC
+func (s *Service) WithMerchantHints(hinter MerchantHinter) *Service {
+	s.hinter = hinter
+	return s
+}
+
 // NewServiceWithSearch creates a categorization service with full-text search enabled
 func NewServiceWithSearch(repo *Repository, indexPath string) (*Service, error) {
 	s := NewService(repo)
@@ -76,6 +105,7 @@ func (s *Service) Categorize(ctx context.Context, userID uuid.UUID, description
 			result.CategoryID = rule.AssignedCategoryID
 			result.IsRecurring = rule.IsRecurring
 			result.RuleID = &rule.ID
+			result.MatchedPattern = rule.MatchPattern
 			return result, nil
 		}
 	}
@@ -91,6 +121,7 @@ func (s *Service) Categorize(ctx context.Context, userID uuid.UUID, description
 			result.CleanMerchantName = merchant.CleanName
 			result.CategoryID = merchant.DefaultCategoryID
 			result.MerchantID = &merchant.ID
+			result.MatchedPattern = merchant.RawPattern
 			return result, nil
 		}
 	}
@@ -121,6 +152,7 @@ func (s *Service) CategorizeBatch(ctx context.Context, userID uuid.UUID, descrip
 				result.CategoryID = rule.AssignedCategoryID
 				result.IsRecurring = rule.IsRecurring
 				result.RuleID = &rule.ID
+				result.MatchedPattern = rule.MatchPattern
 				break
 			}
 		}
@@ -132,6 +164,7 @@ func (s *Service) CategorizeBatch(ctx context.Context, userID uuid.UUID, descrip
 					result.CleanMerchantName = merchant.CleanName
 					result.CategoryID = merchant.DefaultCategoryID
 					result.MerchantID = &merchant.ID
+					result.MatchedPattern = merchant.RawPattern
 					break
 				}
 			}
@@ -140,6 +173,7 @@ func (s *Service) CategorizeBatch(ctx context.Context, userID uuid.UUID, descrip
 		results[i] = result
 	}
 
+	s.applyMerchantHints(ctx, userID, descriptions, results)
 	return results, nil
 }
 
@@ -168,11 +202,7 @@ func (s *Service) CreateRule(ctx context.Context, userID uuid.UUID, pattern, cle
 		return nil, 0, err
 	}
 
-	// Invalidate caches (both rule cache and engine cache)
-	s.cacheMu.Lock()
-	delete(s.ruleCache, userID)
-	s.cacheMu.Unlock()
-	s.invalidateEngineCache(userID)
+	s.InvalidateRules(userID)
 
 	// Optionally apply to existing transactions
 	var updated int64
@@ -187,6 +217,26 @@ func (s *Service) CreateRule(ctx context.Context, userID uuid.UUID, pattern, cle
 	return rule, updated, nil
 }
 
+// DeleteRule moves a rule to the trash. Transactions it already categorized
+// keep their category.
+func (s *Service) DeleteRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	if err := s.repo.DeleteRule(ctx, userID, ruleID); err != nil {
+		return err
+	}
+	s.InvalidateRules(userID)
+	return nil
+}
+
+// InvalidateRules drops the cached rules and engines of a user (both rule
+// cache and engine cache). Call this when their rules change outside the
+// service, e.g. when one is restored from the trash.
+func (s *Service) InvalidateRules(userID uuid.UUID) {
+	s.cacheMu.Lock()
+	delete(s.ruleCache, userID)
+	s.cacheMu.Unlock()
+	s.invalidateEngineCache(userID)
+}
+
 // GetUserRules fetches rules with caching (exported for handler access)
 func (s *Service) GetUserRules(ctx context.Context, userID uuid.UUID) ([]CategoryRule, error) {
 	s.cacheMu.RLock()
@@ -327,6 +377,7 @@ func (s *Service) CategorizeFast(ctx context.Context, userID uuid.UUID, descript
 	result.IsRecurring = match.IsRecurring
 	result.RuleID = match.RuleID
 	result.MerchantID = match.MerchantID
+	result.MatchedPattern = match.Pattern
 
 	return result, nil
 }
@@ -345,6 +396,7 @@ func (s *Service) CategorizeBatchFast(ctx context.Context, userID uuid.UUID, des
 
 	engine, err := s.getOrBuildEngine(ctx, userID)
 	if err != nil {
+		s.applyMerchantHints(ctx, userID, descriptions, results)
 		return results, nil // Fail open with cleaned names
 	}
 
@@ -363,8 +415,10 @@ func (s *Service) CategorizeBatchFast(ctx context.Context, userID uuid.UUID, des
 		results[i].IsRecurring = match.IsRecurring
 		results[i].RuleID = match.RuleID
 		results[i].MerchantID = match.MerchantID
+		results[i].MatchedPattern = match.Pattern
 	}
 
+	s.applyMerchantHints(ctx, userID, descriptions, results)
 	return results, nil
 }
 
@@ -452,6 +506,7 @@ func (s *Service) CategorizeFuzzy(ctx context.Context, userID uuid.UUID, descrip
 	result.CategoryID = match.CategoryID
 	result.RuleID = match.RuleID
 	result.MerchantID = match.MerchantID
+	result.MatchedPattern = match.Pattern
 
 	return result, nil
 }
@@ -470,8 +525,52 @@ func (s *Service) CategorizeWithFallback(ctx context.Context, userID uuid.UUID,
 		return result, nil
 	}
 
-	// Fall back to fuzzy matching
-	return s.CategorizeFuzzy(ctx, userID, description, fuzzyThreshold)
+	// Fall back to fuzzy matching, then merchant enrichment
+	result, err = s.CategorizeFuzzy(ctx, userID, description, fuzzyThreshold)
+	if err != nil {
+		return result, err
+	}
+	s.applyMerchantHints(ctx, userID, []string{description}, []*CategorizationResult{result})
+	return result, nil
+}
+
+// applyMerchantHints fills in results no rule or merchant matched from
+// merchant enrichment. It fails open, leaving results as they are.
+func (s *Service) applyMerchantHints(ctx context.Context, userID uuid.UUID, descriptions []string, results []*CategorizationResult) {
+	if s.hinter == nil {
+		return
+	}
+
+	var unmatched []int
+	for i, result := range results {
+		if result.RuleID == nil && result.MerchantID == nil {
+			unmatched = append(unmatched, i)
+		}
+	}
+	if len(unmatched) == 0 {
+		return
+	}
+
+	pending := make([]string, len(unmatched))
+	for j, i := range unmatched {
+		pending[j] = descriptions[i]
+	}
+	hints, err := s.hinter.HintMerchants(ctx, userID, pending)
+	if err != nil {
+		return
+	}
+	for j, hint := range hints {
+		if hint == nil || j >= len(unmatched) {
+			continue
+		}
+		result := results[unmatched[j]]
+		if hint.Name != "" {
+			result.CleanMerchantName = hint.Name
+		}
+		if result.CategoryID == nil {
+			result.CategoryID = hint.CategoryID
+		}
+	}
 }
 
 // SuggestMerchantMatches returns the top fuzzy matches for a description.
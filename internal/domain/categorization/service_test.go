@@ -1,7 +1,10 @@
 package categorization
 
 import (
+	"context"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func TestCleanDescription(t *testing.T) {
@@ -144,3 +147,86 @@ func TestToTitleCase(t *testing.T) {
 		})
 	}
 }
+
+func TestReviewSuggestions(t *testing.T) {
+	id := func() *uuid.UUID {
+		v := uuid.New()
+		return &v
+	}
+	outcome := func(ruleID *uuid.UUID, pattern string, predictions, corrections int) RuleAccuracy {
+		return RuleAccuracy{
+			RuleID:      ruleID,
+			Pattern:     pattern,
+			Predictions: predictions,
+			Corrections: corrections,
+			Accuracy:    accuracy(predictions, corrections),
+		}
+	}
+
+	groceries := id()
+	poor := outcome(id(), "%LIDL%", 10, 6)
+	poor.CorrectedToCategoryID = groceries
+	worst := outcome(id(), "%AMAZON%", 8, 7)
+
+	rules := []RuleAccuracy{
+		outcome(id(), "%NETFLIX%", 20, 1), // accurate
+		outcome(id(), "%UBER%", 3, 3),     // too few predictions to judge
+		outcome(id(), "", 10, 10),         // deleted rule
+		outcome(nil, "%SHELL%", 10, 9),    // merchant, not a rule
+		poor,
+		worst,
+	}
+
+	got := ReviewSuggestions(rules)
+	if len(got) != 2 {
+		t.Fatalf("ReviewSuggestions() returned %d suggestions, want 2: %+v", len(got), got)
+	}
+	if got[0].Pattern != "%AMAZON%" || got[1].Pattern != "%LIDL%" {
+		t.Errorf("suggestions = [%s %s], want worst first [%%AMAZON%% %%LIDL%%]", got[0].Pattern, got[1].Pattern)
+	}
+	if got[1].SuggestedCategoryID == nil || *got[1].SuggestedCategoryID != *groceries {
+		t.Errorf("SuggestedCategoryID = %v, want %v", got[1].SuggestedCategoryID, *groceries)
+	}
+	if got[1].Accuracy != 0.4 {
+		t.Errorf("Accuracy = %v, want 0.4", got[1].Accuracy)
+	}
+}
+
+type fakeHinter struct {
+	hints []*MerchantHint
+	asked []string
+}
+
+func (f *fakeHinter) HintMerchants(_ context.Context, _ uuid.UUID, descriptions []string) ([]*MerchantHint, error) {
+	f.asked = descriptions
+	return f.hints, nil
+}
+
+func TestApplyMerchantHints(t *testing.T) {
+	ruleID, ruleCategory, hintCategory := uuid.New(), uuid.New(), uuid.New()
+	hinter := &fakeHinter{hints: []*MerchantHint{
+		{Name: "McDonald's", CategoryID: &hintCategory},
+		nil,
+	}}
+	s := NewService(nil).WithMerchantHints(hinter)
+
+	results := []*CategorizationResult{
+		{CleanMerchantName: "Rent", CategoryID: &ruleCategory, RuleID: &ruleID},
+		{CleanMerchantName: "Mcdonalds 0042"},
+		{CleanMerchantName: "Transferencia"},
+	}
+	s.applyMerchantHints(context.Background(), uuid.New(), []string{"RENT", "MCDONALDS 0042", "TRANSFERENCIA"}, results)
+
+	if len(hinter.asked) != 2 || hinter.asked[0] != "MCDONALDS 0042" {
+		t.Fatalf("expected only unmatched descriptions to be hinted, got %v", hinter.asked)
+	}
+	if results[0].CleanMerchantName != "Rent" || *results[0].CategoryID != ruleCategory {
+		t.Errorf("rule match was overwritten: %+v", results[0])
+	}
+	if results[1].CleanMerchantName != "McDonald's" || results[1].CategoryID == nil || *results[1].CategoryID != hintCategory {
+		t.Errorf("hint not applied: %+v", results[1])
+	}
+	if results[2].CleanMerchantName != "Transferencia" || results[2].CategoryID != nil {
+		t.Errorf("description without a hint changed: %+v", results[2])
+	}
+}
@@ -0,0 +1,227 @@
+package categorization
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// Categorization Statistics (Internal Integration)
+// ============================================================================
+// Per-user view of how well categorization covers their transactions, and an
+// explicit rebuild of the matching indexes after bulk rule changes (otherwise
+// rebuilt lazily when a rule is created or deleted through the service).
+//
+// - GetCategorizationStats: coverage rate, top uncategorized merchants and
+//   rules that never matched a transaction
+// - RebuildCategorizationIndex: rebuilds the user's Aho-Corasick automaton,
+//   fuzzy matcher and Bleve search index from the database
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetCategorizationStatsRequest/Response, UncategorizedMerchant
+// - RebuildCategorizationIndexRequest/Response
+
+// topUncategorizedLimit is how many uncategorized merchants the stats list
+const topUncategorizedLimit = 10
+
+// UncategorizedMerchant is a merchant whose transactions have no category
+type UncategorizedMerchant struct {
+	Name              string // Merchant name, or the description when there is none
+	SampleDescription string
+	Transactions      int
+	LastPostedAt      time.Time
+}
+
+// CategorizationStats summarizes categorization coverage for a user
+type CategorizationStats struct {
+	Since           time.Time // Zero for all time
+	Transactions    int
+	Categorized     int
+	AutoCategorized int     // Categorized by a rule or merchant
+	CoverageRate    float64 // Share of transactions with a category, 0-1; 1 when there are none
+
+	TopUncategorized []UncategorizedMerchant // Most transactions first
+	UnmatchedRules   []CategoryRule          // Rules matching none of the user's transactions
+}
+
+// IndexRebuild reports a rebuild of a user's categorization indexes
+type IndexRebuild struct {
+	Rules         int
+	Merchants     int
+	Patterns      int  // Unique patterns in the Aho-Corasick automaton
+	SearchIndexed bool // False when full-text search isn't enabled
+	Duration      time.Duration
+}
+
+// GetCategorizationStats measures categorization of the user's transactions
+// posted since the given time (zero for all time). Unmatched rules are judged
+// against all of them.
+func (s *Service) GetCategorizationStats(ctx context.Context, userID uuid.UUID, since time.Time) (*CategorizationStats, error) {
+	stats := &CategorizationStats{Since: since}
+	if err := s.repo.GetCoverageCounts(ctx, userID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to get categorization coverage: %w", err)
+	}
+	stats.CoverageRate = coverageRate(stats.Transactions, stats.Categorized)
+
+	var err error
+	stats.TopUncategorized, err = s.repo.GetTopUncategorizedMerchants(ctx, userID, since, topUncategorizedLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uncategorized merchants: %w", err)
+	}
+	stats.UnmatchedRules, err = s.repo.GetUnmatchedRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmatched rules: %w", err)
+	}
+	return stats, nil
+}
+
+// RebuildCategorizationIndex drops the user's cached rules, merchants and
+// matchers and builds them again from the database, along with the search
+// index. Use it after rules changed in bulk outside the service.
+func (s *Service) RebuildCategorizationIndex(ctx context.Context, userID uuid.UUID) (*IndexRebuild, error) {
+	start := time.Now()
+
+	s.InvalidateRules(userID)
+	s.invalidateTransactionRules(userID)
+	s.cacheMu.Lock()
+	s.merchantCache = nil
+	s.cacheMu.Unlock()
+
+	rules, err := s.GetUserRules(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+	merchants, err := s.getMerchants(ctx, &userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load merchants: %w", err)
+	}
+	engine, err := s.getOrBuildEngine(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build matching engine: %w", err)
+	}
+	if _, err := s.getOrBuildFuzzyMatcher(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to build fuzzy matcher: %w", err)
+	}
+	if err := s.RebuildSearchIndex(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+
+	s.searchMu.RLock()
+	searchIndexed := s.searchIndex != nil
+	s.searchMu.RUnlock()
+
+	return &IndexRebuild{
+		Rules:         len(rules),
+		Merchants:     len(merchants),
+		Patterns:      engine.PatternCount(),
+		SearchIndexed: searchIndexed,
+		Duration:      time.Since(start),
+	}, nil
+}
+
+func coverageRate(transactions, categorized int) float64 {
+	if transactions == 0 {
+		return 1
+	}
+	return float64(categorized) / float64(transactions)
+}
+
+// GetCoverageCounts counts the user's transactions posted since the given
+// time, categorized and automatically categorized ones, into stats
+func (r *Repository) GetCoverageCounts(ctx context.Context, userID uuid.UUID, since time.Time, stats *CategorizationStats) error {
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE category_id IS NOT NULL),
+			COUNT(*) FILTER (WHERE category_id IS NOT NULL
+				AND (categorized_by_rule_id IS NOT NULL OR categorized_by_merchant_id IS NOT NULL))
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2
+	`
+
+	return r.db.QueryRow(ctx, query, userID, since).Scan(
+		&stats.Transactions,
+		&stats.Categorized,
+		&stats.AutoCategorized,
+	)
+}
+
+// GetTopUncategorizedMerchants groups the user's uncategorized transactions
+// posted since the given time by merchant, most transactions first
+func (r *Repository) GetTopUncategorizedMerchants(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]UncategorizedMerchant, error) {
+	query := `
+		SELECT
+			COALESCE(NULLIF(merchant_name, ''), description) AS name,
+			MIN(description),
+			COUNT(*),
+			MAX(posted_at)
+		FROM transactions
+		WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2
+		  AND category_id IS NULL
+		GROUP BY name
+		ORDER BY COUNT(*) DESC, MAX(posted_at) DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merchants []UncategorizedMerchant
+	for rows.Next() {
+		var m UncategorizedMerchant
+		if err := rows.Scan(&m.Name, &m.SampleDescription, &m.Transactions, &m.LastPostedAt); err != nil {
+			return nil, err
+		}
+		merchants = append(merchants, m)
+	}
+
+	return merchants, rows.Err()
+}
+
+// GetUnmatchedRules returns the user's rules that neither categorized a
+// transaction nor match the description of any
+func (r *Repository) GetUnmatchedRules(ctx context.Context, userID uuid.UUID) ([]CategoryRule, error) {
+	query := `
+		SELECT cr.id, cr.user_id, cr.match_pattern, cr.clean_name, cr.assigned_category_id, cr.is_recurring, cr.priority
+		FROM category_rules cr
+		WHERE cr.user_id = $1 AND cr.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM transactions t
+			WHERE t.user_id = cr.user_id AND t.deleted_at IS NULL
+			  AND (t.categorized_by_rule_id = cr.id
+				OR t.description ILIKE '%' || TRIM(BOTH '%' FROM cr.match_pattern) || '%')
+		  )
+		ORDER BY cr.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []CategoryRule
+	for rows.Next() {
+		var rule CategoryRule
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.UserID,
+			&rule.MatchPattern,
+			&rule.CleanName,
+			&rule.AssignedCategoryID,
+			&rule.IsRecurring,
+			&rule.Priority,
+		); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
@@ -0,0 +1,253 @@
+//go:build integration
+
+package categorization
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// Run against any Postgres database:
+//
+//	TEST_DATABASE_URL=... go test -tags integration -run 'Stats|UnmatchedRules|RebuildCategorizationIndex' ./internal/domain/categorization/
+
+// statsTestPool connects to the test database and migrates it
+func statsTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	database, err := db.New(db.Config{DSN: dsn, MaxConns: 4}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err, "failed to connect")
+	t.Cleanup(database.Close)
+	require.NoError(t, database.RunMigrations(), "failed to migrate")
+	return database.Pool
+}
+
+// seedStatsUser creates a user, removed with everything they own when the test ends
+func seedStatsUser(t *testing.T, pool *pgxpool.Pool) uuid.UUID {
+	t.Helper()
+	var userID uuid.UUID
+	err := pool.QueryRow(context.Background(), `INSERT INTO users (email) VALUES ($1) RETURNING id`,
+		fmt.Sprintf("categorization-stats-%s@example.com", uuid.NewString())).Scan(&userID)
+	require.NoError(t, err, "failed to seed user")
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+	return userID
+}
+
+func seedStatsCategory(t *testing.T, pool *pgxpool.Pool, userID uuid.UUID, name string) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	err := pool.QueryRow(context.Background(),
+		`INSERT INTO categories (user_id, name) VALUES ($1, $2) RETURNING id`, userID, name).Scan(&id)
+	require.NoError(t, err, "failed to seed category %s", name)
+	return id
+}
+
+// statsTx is a transaction to seed
+type statsTx struct {
+	description string
+	merchant    string
+	postedAt    time.Time
+	categoryID  *uuid.UUID
+	ruleID      *uuid.UUID
+}
+
+func seedStatsTransactions(t *testing.T, pool *pgxpool.Pool, userID uuid.UUID, txs ...statsTx) {
+	t.Helper()
+	for _, tx := range txs {
+		_, err := pool.Exec(context.Background(), `
+			INSERT INTO transactions (user_id, posted_at, description, merchant_name, amount_minor, currency_code,
+				category_id, categorized_by_rule_id)
+			VALUES ($1, $2, $3, NULLIF($4, ''), -1000, 'EUR', $5, $6)
+		`, userID, tx.postedAt, tx.description, tx.merchant, tx.categoryID, tx.ruleID)
+		require.NoError(t, err, "failed to seed transaction %q", tx.description)
+	}
+}
+
+func seedStatsRule(t *testing.T, repo *Repository, userID uuid.UUID, pattern string, categoryID *uuid.UUID) CategoryRule {
+	t.Helper()
+	rule := CategoryRule{UserID: userID, MatchPattern: pattern, AssignedCategoryID: categoryID}
+	require.NoError(t, repo.CreateRule(context.Background(), &rule), "failed to seed rule %s", pattern)
+	return rule
+}
+
+func TestGetCategorizationStats(t *testing.T) {
+	pool := statsTestPool(t)
+	repo := NewRepository(pool)
+	s := NewService(repo)
+	ctx := context.Background()
+
+	userID := seedStatsUser(t, pool)
+	groceries := seedStatsCategory(t, pool, userID, "Groceries")
+	rule := seedStatsRule(t, repo, userID, "%PINGO DOCE%", &groceries)
+	now := time.Now().UTC()
+	seedStatsTransactions(t, pool, userID,
+		statsTx{description: "PINGO DOCE LISBOA", postedAt: now, categoryID: &groceries, ruleID: &rule.ID},
+		statsTx{description: "LIDL 0042", postedAt: now, categoryID: &groceries},
+		statsTx{description: "COMPRA CONTINENTE 0452", merchant: "Continente", postedAt: now.AddDate(0, 0, -1)},
+		statsTx{description: "COMPRA CONTINENTE 0311", merchant: "Continente", postedAt: now.AddDate(0, 0, -2)},
+		statsTx{description: "MB WAY 912", postedAt: now.AddDate(0, -3, 0)},
+	)
+
+	tests := []struct {
+		name            string
+		userID          uuid.UUID
+		since           time.Time
+		transactions    int
+		categorized     int
+		autoCategorized int
+		coverage        float64
+		topMerchants    []string
+	}{
+		{
+			name:         "no transactions counts as covered",
+			userID:       seedStatsUser(t, pool),
+			transactions: 0,
+			coverage:     1,
+		},
+		{
+			name:            "all time",
+			userID:          userID,
+			transactions:    5,
+			categorized:     2,
+			autoCategorized: 1,
+			coverage:        0.4,
+			topMerchants:    []string{"Continente", "MB WAY 912"},
+		},
+		{
+			name:            "since a month ago",
+			userID:          userID,
+			since:           now.AddDate(0, -1, 0),
+			transactions:    4,
+			categorized:     2,
+			autoCategorized: 1,
+			coverage:        0.5,
+			topMerchants:    []string{"Continente"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := s.GetCategorizationStats(ctx, tt.userID, tt.since)
+			require.NoError(t, err)
+			assert.Equal(t, tt.transactions, stats.Transactions)
+			assert.Equal(t, tt.categorized, stats.Categorized)
+			assert.Equal(t, tt.autoCategorized, stats.AutoCategorized)
+			assert.InDelta(t, tt.coverage, stats.CoverageRate, 1e-9)
+
+			var names []string
+			for _, m := range stats.TopUncategorized {
+				names = append(names, m.Name)
+			}
+			assert.Equal(t, tt.topMerchants, names)
+		})
+	}
+
+	stats, err := s.GetCategorizationStats(ctx, userID, time.Time{})
+	require.NoError(t, err)
+	require.NotEmpty(t, stats.TopUncategorized)
+	assert.Equal(t, 2, stats.TopUncategorized[0].Transactions)
+	assert.Equal(t, "COMPRA CONTINENTE 0311", stats.TopUncategorized[0].SampleDescription)
+}
+
+func TestGetUnmatchedRules(t *testing.T) {
+	pool := statsTestPool(t)
+	repo := NewRepository(pool)
+	ctx := context.Background()
+
+	userID := seedStatsUser(t, pool)
+	otherID := seedStatsUser(t, pool)
+	gym := seedStatsRule(t, repo, userID, "%GYM%", nil)
+	seedStatsTransactions(t, pool, userID,
+		statsTx{description: "Uber Trip Lisboa", postedAt: time.Now()},
+		statsTx{description: "NETFLIX.COM 866", postedAt: time.Now()},
+		statsTx{description: "FITNESS HUT", postedAt: time.Now(), ruleID: &gym.ID},
+	)
+	seedStatsTransactions(t, pool, otherID, statsTx{description: "BOLT.EU/O/2603", postedAt: time.Now()})
+
+	tests := []struct {
+		pattern   string
+		unmatched bool
+	}{
+		{"%UBER%", false},     // Matches regardless of case
+		{"netflix", false},    // Matches anywhere in the description without wildcards
+		{"%GYM%", false},      // Categorized a transaction its pattern no longer matches
+		{"%SPOTIFY%", true},   // No transaction at all
+		{"%BOLT%", true},      // Only another user's transaction matches
+		{"%UBER EATS%", true}, // Only part of the pattern matches
+	}
+	ruleIDs := map[uuid.UUID]string{gym.ID: gym.MatchPattern}
+	for _, tt := range tests {
+		if tt.pattern != gym.MatchPattern {
+			rule := seedStatsRule(t, repo, userID, tt.pattern, nil)
+			ruleIDs[rule.ID] = rule.MatchPattern
+		}
+	}
+
+	rules, err := repo.GetUnmatchedRules(ctx, userID)
+	require.NoError(t, err)
+	unmatched := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		assert.Contains(t, ruleIDs, rule.ID)
+		unmatched[rule.MatchPattern] = true
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.unmatched, unmatched[tt.pattern])
+		})
+	}
+}
+
+func TestRebuildCategorizationIndex(t *testing.T) {
+	pool := statsTestPool(t)
+	repo := NewRepository(pool)
+	s := NewService(repo)
+	ctx := context.Background()
+
+	userID := seedStatsUser(t, pool)
+	uber := seedStatsRule(t, repo, userID, "%UBER%", nil)
+
+	// Warm the caches, then change the rules behind the service's back
+	_, err := s.getOrBuildEngine(ctx, userID)
+	require.NoError(t, err)
+	bolt := seedStatsRule(t, repo, userID, "%BOLT%", nil)
+	require.NoError(t, repo.DeleteRule(ctx, userID, uber.ID))
+	cached, err := s.GetUserRules(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+	assert.Equal(t, uber.ID, cached[0].ID, "the cache still holds the deleted rule")
+
+	rebuild, err := s.RebuildCategorizationIndex(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rebuild.Rules)
+	assert.Positive(t, rebuild.Patterns)
+	assert.False(t, rebuild.SearchIndexed)
+
+	rules, err := s.GetUserRules(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, bolt.ID, rules[0].ID)
+
+	// The rebuilt engine matches the new rule
+	result, err := s.CategorizeFast(ctx, userID, "BOLT.EU/O/2603")
+	require.NoError(t, err)
+	require.NotNil(t, result.RuleID)
+	assert.Equal(t, bolt.ID, *result.RuleID)
+}
@@ -0,0 +1,27 @@
+package categorization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions int
+		categorized  int
+		want         float64
+	}{
+		{"no transactions counts as covered", 0, 0, 1},
+		{"nothing categorized", 4, 0, 0},
+		{"partly categorized", 4, 3, 0.75},
+		{"everything categorized", 4, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, coverageRate(tt.transactions, tt.categorized))
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package categorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================================
+// Transaction Rules (Internal Integration)
+// ============================================================================
+//
+// Transaction rules go beyond categorization: "if the description contains
+// UBER and the amount is over 30 → tag #travel, rename to 'Uber ride'". They
+// run at import time on the same Aho-Corasick engine as category rules, and
+// count how often each rule fired so users can debug them.
+//
+// To expose as API endpoints, add the following proto definitions:
+//   - rpc CreateTransactionRule(CreateTransactionRuleRequest) returns (CreateTransactionRuleResponse)
+//   - rpc ListTransactionRules(ListTransactionRulesRequest) returns (ListTransactionRulesResponse)
+//   - rpc DeleteTransactionRule(DeleteTransactionRuleRequest) returns (DeleteTransactionRuleResponse)
+//   - message TransactionRule { id, name, match_pattern, amount_above_minor, amount_below_minor, add_tags, rename_to, priority, hit_count, last_hit_at }
+
+// ErrInvalidTransactionRule is returned for rules without a pattern or an action
+var ErrInvalidTransactionRule = errors.New("invalid transaction rule")
+
+// TransactionRule tags and renames the transactions it matches on import
+type TransactionRule struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	Name             string
+	MatchPattern     string   // The description must contain this (case-insensitive)
+	AmountAboveMinor *int64   // The absolute amount must be greater than this, if set
+	AmountBelowMinor *int64   // The absolute amount must be less than this, if set
+	AddTags          []string // Lower-case, without the "#"
+	RenameTo         *string  // New merchant name, if set
+	Priority         int      // The highest priority rename wins
+	HitCount         int64
+	LastHitAt        *time.Time
+	CreatedAt        time.Time
+}
+
+// matchesAmount reports whether amountMinor is within the rule's bounds
+func (r *TransactionRule) matchesAmount(amountMinor int64) bool {
+	if amountMinor < 0 {
+		amountMinor = -amountMinor
+	}
+	if r.AmountAboveMinor != nil && amountMinor <= *r.AmountAboveMinor {
+		return false
+	}
+	if r.AmountBelowMinor != nil && amountMinor >= *r.AmountBelowMinor {
+		return false
+	}
+	return true
+}
+
+// TransactionRuleInput is a transaction to run the rules on
+type TransactionRuleInput struct {
+	Description string
+	AmountMinor int64 // Signed: negative for expenses
+}
+
+// TransactionRuleOutcome is what the rules did to a transaction
+type TransactionRuleOutcome struct {
+	Tags     []string    // Tags of every rule that fired, in priority order
+	RenameTo string      // Merchant name from the highest priority rename, or ""
+	RuleIDs  []uuid.UUID // Rules that fired, in priority order
+}
+
+// TransactionRuleSet runs a user's transaction rules. It is safe for
+// concurrent use.
+type TransactionRuleSet struct {
+	engine *Engine
+	rules  map[uuid.UUID]*TransactionRule
+
+	mu   sync.Mutex
+	hits map[uuid.UUID]int64 // Since the set was built
+}
+
+// NewTransactionRuleSet builds the matching engine for rules. The engine is
+// the categorization engine, fed the rules' patterns as category rules.
+func NewTransactionRuleSet(rules []TransactionRule) *TransactionRuleSet {
+	set := &TransactionRuleSet{
+		rules: make(map[uuid.UUID]*TransactionRule, len(rules)),
+		hits:  make(map[uuid.UUID]int64),
+	}
+	patterns := make([]CategoryRule, 0, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		set.rules[rule.ID] = rule
+		patterns = append(patterns, CategoryRule{
+			ID:           rule.ID,
+			UserID:       rule.UserID,
+			MatchPattern: rule.MatchPattern,
+			CleanName:    rule.RenameTo,
+			Priority:     rule.Priority,
+		})
+	}
+	set.engine = NewEngine(patterns, nil)
+	return set
+}
+
+// Apply runs the rules on a transaction, returning nil when none fired
+func (s *TransactionRuleSet) Apply(in TransactionRuleInput) *TransactionRuleOutcome {
+	var outcome *TransactionRuleOutcome
+	for _, match := range s.engine.MatchAll(in.Description) {
+		if match.RuleID == nil {
+			continue
+		}
+		rule, ok := s.rules[*match.RuleID]
+		if !ok || !rule.matchesAmount(in.AmountMinor) || (outcome != nil && slices.Contains(outcome.RuleIDs, rule.ID)) {
+			continue
+		}
+		if outcome == nil {
+			outcome = &TransactionRuleOutcome{}
+		}
+		outcome.RuleIDs = append(outcome.RuleIDs, rule.ID)
+		for _, tag := range rule.AddTags {
+			if !slices.Contains(outcome.Tags, tag) {
+				outcome.Tags = append(outcome.Tags, tag)
+			}
+		}
+		if outcome.RenameTo == "" && rule.RenameTo != nil {
+			outcome.RenameTo = *rule.RenameTo
+		}
+	}
+
+	if outcome != nil {
+		s.mu.Lock()
+		for _, id := range outcome.RuleIDs {
+			s.hits[id]++
+		}
+		s.mu.Unlock()
+	}
+	return outcome
+}
+
+// Hits returns how many transactions each rule fired on since the set was
+// built
+func (s *TransactionRuleSet) Hits() map[uuid.UUID]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hits := make(map[uuid.UUID]int64, len(s.hits))
+	for id, n := range s.hits {
+		hits[id] = n
+	}
+	return hits
+}
+
+// normalizeRuleTags lower-cases tags, strips "#" and drops empty and
+// repeated ones, like Quick Capture hashtags
+func normalizeRuleTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#")))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// CreateTransactionRule validates and saves a transaction rule for the user
+func (s *Service) CreateTransactionRule(ctx context.Context, userID uuid.UUID, rule *TransactionRule) error {
+	rule.UserID = userID
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.MatchPattern = strings.TrimSpace(strings.Trim(rule.MatchPattern, "%"))
+	rule.AddTags = normalizeRuleTags(rule.AddTags)
+	if rule.RenameTo != nil {
+		if name := strings.TrimSpace(*rule.RenameTo); name != "" {
+			rule.RenameTo = &name
+		} else {
+			rule.RenameTo = nil
+		}
+	}
+
+	switch {
+	case rule.MatchPattern == "":
+		return fmt.Errorf("%w: match pattern is required", ErrInvalidTransactionRule)
+	case len(rule.AddTags) == 0 && rule.RenameTo == nil:
+		return fmt.Errorf("%w: rule must add a tag or rename", ErrInvalidTransactionRule)
+	case rule.AmountAboveMinor != nil && *rule.AmountAboveMinor < 0,
+		rule.AmountBelowMinor != nil && *rule.AmountBelowMinor < 0:
+		return fmt.Errorf("%w: amount bounds must not be negative", ErrInvalidTransactionRule)
+	case rule.AmountAboveMinor != nil && rule.AmountBelowMinor != nil && *rule.AmountAboveMinor >= *rule.AmountBelowMinor:
+		return fmt.Errorf("%w: amount range is empty", ErrInvalidTransactionRule)
+	}
+
+	if err := s.repo.CreateTransactionRule(ctx, rule); err != nil {
+		return err
+	}
+	s.invalidateTransactionRules(userID)
+	return nil
+}
+
+// ListTransactionRules returns the user's transaction rules with their hit
+// counters, highest priority first
+func (s *Service) ListTransactionRules(ctx context.Context, userID uuid.UUID) ([]TransactionRule, error) {
+	return s.repo.GetTransactionRules(ctx, userID)
+}
+
+// DeleteTransactionRule moves a transaction rule to the trash. Transactions it
+// already tagged or renamed keep their changes.
+func (s *Service) DeleteTransactionRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	if err := s.repo.DeleteTransactionRule(ctx, userID, ruleID); err != nil {
+		return err
+	}
+	s.invalidateTransactionRules(userID)
+	return nil
+}
+
+// ApplyTransactionRules runs the user's transaction rules on a batch of
+// transactions, returning outcomes aligned to inputs (nil where no rule
+// fired), and adds the hits to the rules' counters. Counting fails open.
+func (s *Service) ApplyTransactionRules(ctx context.Context, userID uuid.UUID, inputs []TransactionRuleInput) ([]*TransactionRuleOutcome, error) {
+	set, err := s.getOrBuildTransactionRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]*TransactionRuleOutcome, len(inputs))
+	hits := make(map[uuid.UUID]int64)
+	for i, in := range inputs {
+		outcomes[i] = set.Apply(in)
+		if outcomes[i] != nil {
+			for _, id := range outcomes[i].RuleIDs {
+				hits[id]++
+			}
+		}
+	}
+	if len(hits) > 0 {
+		_ = s.repo.AddTransactionRuleHits(ctx, userID, hits)
+	}
+	return outcomes, nil
+}
+
+// getOrBuildTransactionRules returns the user's cached rule set, building it
+// if necessary
+func (s *Service) getOrBuildTransactionRules(ctx context.Context, userID uuid.UUID) (*TransactionRuleSet, error) {
+	s.txRuleMu.RLock()
+	if set, ok := s.txRuleCache[userID]; ok {
+		s.txRuleMu.RUnlock()
+		return set, nil
+	}
+	s.txRuleMu.RUnlock()
+
+	rules, err := s.repo.GetTransactionRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	set := NewTransactionRuleSet(rules)
+
+	s.txRuleMu.Lock()
+	s.txRuleCache[userID] = set
+	s.txRuleMu.Unlock()
+	return set, nil
+}
+
+// invalidateTransactionRules drops the user's cached rule set
+func (s *Service) invalidateTransactionRules(userID uuid.UUID) {
+	s.txRuleMu.Lock()
+	delete(s.txRuleCache, userID)
+	s.txRuleMu.Unlock()
+}
@@ -0,0 +1,60 @@
+package categorization
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionRuleSet_Apply(t *testing.T) {
+	above := func(v int64) *int64 { return &v }
+	uberRide := TransactionRule{
+		ID:               uuid.New(),
+		MatchPattern:     "UBER",
+		AmountAboveMinor: above(3000),
+		AddTags:          []string{"travel"},
+		RenameTo:         strPtr("Uber ride"),
+		Priority:         10,
+	}
+	uberEats := TransactionRule{
+		ID:           uuid.New(),
+		MatchPattern: "UBER EATS",
+		AddTags:      []string{"food", "travel"},
+		RenameTo:     strPtr("Uber Eats"),
+		Priority:     20,
+	}
+	work := TransactionRule{
+		ID:           uuid.New(),
+		MatchPattern: "%LISBOA%",
+		AddTags:      []string{"work"},
+	}
+	set := NewTransactionRuleSet([]TransactionRule{uberRide, uberEats, work})
+
+	t.Run("fires when the description and amount match", func(t *testing.T) {
+		outcome := set.Apply(TransactionRuleInput{Description: "UBER *TRIP HELP.UBER.COM", AmountMinor: -3550})
+		require.NotNil(t, outcome)
+		assert.Equal(t, []string{"travel"}, outcome.Tags)
+		assert.Equal(t, "Uber ride", outcome.RenameTo)
+		assert.Equal(t, []uuid.UUID{uberRide.ID}, outcome.RuleIDs)
+	})
+
+	t.Run("skips rules whose amount doesn't match", func(t *testing.T) {
+		assert.Nil(t, set.Apply(TransactionRuleInput{Description: "uber trip", AmountMinor: -3000}))
+	})
+
+	t.Run("combines rules, the highest priority rename winning", func(t *testing.T) {
+		outcome := set.Apply(TransactionRuleInput{Description: "UBER EATS LISBOA", AmountMinor: -4200})
+		require.NotNil(t, outcome)
+		assert.Equal(t, []string{"food", "travel", "work"}, outcome.Tags)
+		assert.Equal(t, "Uber Eats", outcome.RenameTo)
+		assert.Equal(t, []uuid.UUID{uberEats.ID, uberRide.ID, work.ID}, outcome.RuleIDs)
+	})
+
+	assert.Equal(t, map[uuid.UUID]int64{uberRide.ID: 2, uberEats.ID: 1, work.ID: 1}, set.Hits())
+}
+
+func TestNormalizeRuleTags(t *testing.T) {
+	assert.Equal(t, []string{"travel", "work"}, normalizeRuleTags([]string{" #Travel", "travel", "", "#", "WORK"}))
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresDemoRepository implements DemoRepository using PostgreSQL
+type PostgresDemoRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDemoRepository creates a new PostgreSQL demo repository
+func NewPostgresDemoRepository(pool *pgxpool.Pool) *PostgresDemoRepository {
+	return &PostgresDemoRepository{pool: pool}
+}
+
+// HasTransactions reports whether the user has any transactions
+func (r *PostgresDemoRepository) HasTransactions(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM transactions WHERE user_id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for transactions: %w", err)
+	}
+	return exists, nil
+}
+
+// Seed stores the demo data for the user
+func (r *PostgresDemoRepository) Seed(ctx context.Context, userID uuid.UUID, data *Data) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	a := data.Account
+	_, err = tx.Exec(ctx, `
+		INSERT INTO accounts (id, user_id, name, type, currency_code, institution)
+		VALUES ($1, $2, $3, $4::account_type, $5, $6)
+	`, a.ID, userID, a.Name, a.Type, a.CurrencyCode, a.Institution)
+	if err != nil {
+		return fmt.Errorf("failed to create demo account: %w", err)
+	}
+
+	categoryIDs, err := ensureCategories(ctx, tx, userID, data.Transactions)
+	if err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, t := range data.Transactions {
+		batch.Queue(`
+			INSERT INTO transactions (
+				id, user_id, account_id, category_id, posted_at, description, merchant_name,
+				amount_minor, currency_code, tags, source
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'manual')
+		`, t.ID, userID, a.ID, categoryIDs[t.Category], t.PostedAt, t.Description, t.MerchantName,
+			t.AmountMinor, data.CurrencyCode, t.Tags)
+	}
+	for _, s := range data.Subscriptions {
+		batch.Queue(`
+			INSERT INTO recurring_subscriptions (
+				user_id, merchant_name, amount_minor, currency_code, cadence, status,
+				first_seen_at, last_seen_at, next_expected_at, occurrence_count
+			) VALUES ($1, $2, $3, $4, 'monthly', 'active', $5, $6, $7, $8)
+		`, userID, s.MerchantName, s.AmountMinor, data.CurrencyCode, s.FirstSeenAt, s.LastSeenAt,
+			s.NextExpectedAt, s.OccurrenceCount)
+	}
+	for _, g := range data.Goals {
+		batch.Queue(`
+			INSERT INTO goals (user_id, name, type, status, target_amount_minor, currency_code, start_at, end_at)
+			VALUES ($1, $2, 'save', 'active', $3, $4, $5, $6)
+		`, userID, g.Name, g.TargetAmountMinor, data.CurrencyCode, g.StartAt, g.EndAt)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to seed demo data: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit demo data: %w", err)
+	}
+	return nil
+}
+
+// ensureCategories returns the IDs of the categories the transactions use by
+// name, creating those the user doesn't have. Uncategorized transactions
+// find no ID.
+func ensureCategories(ctx context.Context, tx pgx.Tx, userID uuid.UUID, txs []Transaction) (map[string]*uuid.UUID, error) {
+	ids := make(map[string]*uuid.UUID)
+	for _, t := range txs {
+		if t.Category == "" {
+			continue
+		}
+		if _, ok := ids[t.Category]; ok {
+			continue
+		}
+		var id uuid.UUID
+		err := tx.QueryRow(ctx, `
+			WITH existing AS (
+				SELECT id FROM categories WHERE user_id = $1 AND name = $2 LIMIT 1
+			), created AS (
+				INSERT INTO categories (user_id, name)
+				SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM existing)
+				RETURNING id
+			)
+			SELECT id FROM existing UNION ALL SELECT id FROM created
+		`, userID, t.Category).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create demo category %q: %w", t.Category, err)
+		}
+		ids[t.Category] = &id
+	}
+	return ids, nil
+}
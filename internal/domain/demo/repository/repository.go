@@ -0,0 +1,65 @@
+// Package repository provides database operations for seeding demo data.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account is the demo account transactions are recorded in
+type Account struct {
+	ID           uuid.UUID
+	Name         string
+	Type         string // account_type, e.g. "checking"
+	Institution  string
+	CurrencyCode string
+}
+
+// Transaction is a demo transaction
+type Transaction struct {
+	ID           uuid.UUID
+	PostedAt     time.Time
+	Description  string
+	MerchantName string
+	AmountMinor  int64 // Negative for expenses
+	Category     string
+	Tags         []string
+}
+
+// Subscription is a demo recurring subscription
+type Subscription struct {
+	MerchantName    string
+	AmountMinor     int64
+	FirstSeenAt     time.Time
+	LastSeenAt      time.Time
+	NextExpectedAt  time.Time
+	OccurrenceCount int
+}
+
+// Goal is a demo savings goal
+type Goal struct {
+	Name              string
+	TargetAmountMinor int64
+	StartAt           time.Time
+	EndAt             time.Time
+}
+
+// Data is everything seeded for a demo user
+type Data struct {
+	CurrencyCode  string
+	Account       Account
+	Transactions  []Transaction
+	Subscriptions []Subscription
+	Goals         []Goal
+}
+
+// DemoRepository defines the interface for demo data persistence
+type DemoRepository interface {
+	// HasTransactions reports whether the user has any transactions, trashed ones included
+	HasTransactions(ctx context.Context, userID uuid.UUID) (bool, error)
+	// Seed stores the demo data for the user in one database transaction,
+	// creating the categories its transactions use if the user lacks them
+	Seed(ctx context.Context, userID uuid.UUID, data *Data) error
+}
@@ -0,0 +1,236 @@
+// Package service seeds new accounts with realistic sample data, so users can
+// explore the app before importing their own statements.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/demo/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/featureflags"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+// =============================================================================
+// Demo Data (Internal Integration)
+// =============================================================================
+// Seeding gives a new user DemoMonths of generated transactions in a demo
+// account (salary, bills and everyday spending), a few monthly
+// subscriptions, savings goals and an active plan budgeted from the
+// generated spending. It's gated by the demo_data flag and only runs for
+// users without transactions, so real data is never mixed with sample data.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - SeedDemoDataRequest/Response (UserService.SeedDemoData) with
+//   DemoDataSummary
+
+// DemoMonths is how many months of history are generated, the current one included
+const DemoMonths = 6
+
+var (
+	// ErrDemoDisabled is returned when the demo_data flag is off for the user
+	ErrDemoDisabled = errors.New("demo data is not enabled")
+	// ErrNotNewUser is returned for users who already have transactions
+	ErrNotNewUser = errors.New("demo data can only be added to accounts without transactions")
+)
+
+// demoSubscriptions are charged monthly on their day
+var demoSubscriptions = []struct {
+	merchant    string
+	amountMinor int64
+	day         int
+}{
+	{merchant: "Netflix", amountMinor: 1599, day: 5},
+	{merchant: "Spotify", amountMinor: 1099, day: 12},
+	{merchant: "City Gym", amountMinor: 3500, day: 3},
+}
+
+// PlanBudget is a category's monthly budget in the demo plan
+type PlanBudget struct {
+	Category    string
+	BudgetMinor int64
+}
+
+// PlanCreator creates the demo plan and makes it the user's active plan (the
+// plan service)
+type PlanCreator interface {
+	CreateDemoPlan(ctx context.Context, userID uuid.UUID, name, currency string, budgets []PlanBudget) (uuid.UUID, error)
+}
+
+// Summary is what was seeded for the user
+type Summary struct {
+	AccountID     uuid.UUID
+	Transactions  int
+	Subscriptions int
+	Goals         int
+	PlanID        *uuid.UUID // nil without a plan creator
+}
+
+// Service seeds demo data
+type Service struct {
+	repo      repository.DemoRepository
+	plans     PlanCreator
+	generator func() *money.TestDataGenerator
+	now       func() time.Time
+}
+
+// NewService creates a new demo data service
+func NewService(repo repository.DemoRepository) *Service {
+	return &Service{repo: repo, generator: money.NewTestDataGenerator, now: time.Now}
+}
+
+// WithPlanCreator also creates a plan budgeted from the demo spending
+func (s *Service) WithPlanCreator(plans PlanCreator) *Service {
+	s.plans = plans
+	return s
+}
+
+// SeedDemoData populates a new user's account with sample data in the given
+// currency, EUR when empty
+func (s *Service) SeedDemoData(ctx context.Context, userID uuid.UUID, currency string) (*Summary, error) {
+	if !featureflags.Enabled(ctx, featureflags.DemoData) {
+		return nil, ErrDemoDisabled
+	}
+	if currency == "" {
+		currency = money.EUR
+	}
+	has, err := s.repo.HasTransactions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return nil, ErrNotNewUser
+	}
+
+	data := s.build(currency)
+	if err := s.repo.Seed(ctx, userID, data); err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		AccountID:     data.Account.ID,
+		Transactions:  len(data.Transactions),
+		Subscriptions: len(data.Subscriptions),
+		Goals:         len(data.Goals),
+	}
+
+	if s.plans != nil {
+		planID, err := s.plans.CreateDemoPlan(ctx, userID, "Demo budget", currency, planBudgets(data, s.now()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create demo plan: %w", err)
+		}
+		summary.PlanID = &planID
+	}
+	return summary, nil
+}
+
+// build generates the demo data, leaving out anything dated after now
+func (s *Service) build(currency string) *repository.Data {
+	gen := s.generator()
+	now := s.now()
+	salary := gen.RandomAmountRange(currency, 3000, 5000)
+	first := time.Date(now.Year(), now.Month()-(DemoMonths-1), 1, 0, 0, 0, 0, now.Location())
+
+	data := &repository.Data{
+		CurrencyCode: currency,
+		Account: repository.Account{
+			ID:           uuid.New(),
+			Name:         "Demo Checking",
+			Type:         "checking",
+			Institution:  "Demo Bank",
+			CurrencyCode: currency,
+		},
+	}
+
+	for i := 0; i < DemoMonths; i++ {
+		month := first.AddDate(0, i, 0)
+		for _, tx := range gen.MonthOfTransactions(currency, month, salary) {
+			if tx.Date.After(now) {
+				continue
+			}
+			data.Transactions = append(data.Transactions, repository.Transaction{
+				ID:           uuid.New(),
+				PostedAt:     tx.Date,
+				Description:  tx.Description,
+				MerchantName: tx.Merchant,
+				AmountMinor:  tx.Amount.Amount(),
+				Category:     tx.Category,
+				Tags:         tx.Tags,
+			})
+		}
+	}
+
+	for _, sub := range demoSubscriptions {
+		subscription := repository.Subscription{MerchantName: sub.merchant, AmountMinor: sub.amountMinor}
+		for i := 0; i < DemoMonths; i++ {
+			chargedAt := first.AddDate(0, i, sub.day-1).Add(9 * time.Hour)
+			if chargedAt.After(now) {
+				break
+			}
+			if subscription.OccurrenceCount == 0 {
+				subscription.FirstSeenAt = chargedAt
+			}
+			subscription.LastSeenAt = chargedAt
+			subscription.OccurrenceCount++
+			data.Transactions = append(data.Transactions, repository.Transaction{
+				ID:           uuid.New(),
+				PostedAt:     chargedAt,
+				Description:  sub.merchant + " subscription",
+				MerchantName: sub.merchant,
+				AmountMinor:  -sub.amountMinor,
+				Category:     "Subscriptions",
+				Tags:         []string{"recurring", "monthly"},
+			})
+		}
+		if subscription.OccurrenceCount == 0 {
+			continue
+		}
+		subscription.NextExpectedAt = subscription.LastSeenAt.AddDate(0, 1, 0)
+		data.Subscriptions = append(data.Subscriptions, subscription)
+	}
+
+	sort.Slice(data.Transactions, func(i, j int) bool {
+		return data.Transactions[i].PostedAt.Before(data.Transactions[j].PostedAt)
+	})
+
+	data.Goals = []repository.Goal{
+		{
+			Name:              "Emergency fund",
+			TargetAmountMinor: salary.Amount() * 3,
+			StartAt:           first,
+			EndAt:             first.AddDate(2, 0, 0),
+		},
+		{
+			Name:              "Summer holiday",
+			TargetAmountMinor: 150000,
+			StartAt:           first,
+			EndAt:             first.AddDate(1, 0, 0),
+		},
+	}
+	return data
+}
+
+// planBudgets budgets each spending category at its average over the full
+// months of demo data, rounded up to a whole 10 units
+func planBudgets(data *repository.Data, now time.Time) []PlanBudget {
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	spent := map[string]int64{}
+	for _, tx := range data.Transactions {
+		if tx.AmountMinor >= 0 || !tx.PostedAt.Before(currentMonth) {
+			continue
+		}
+		spent[tx.Category] -= tx.AmountMinor
+	}
+
+	budgets := make([]PlanBudget, 0, len(spent))
+	for category, total := range spent {
+		average := total / (DemoMonths - 1)
+		budgets = append(budgets, PlanBudget{Category: category, BudgetMinor: (average + 999) / 1000 * 1000})
+	}
+	sort.Slice(budgets, func(i, j int) bool { return budgets[i].Category < budgets[j].Category })
+	return budgets
+}
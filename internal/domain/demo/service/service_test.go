@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/demo/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/featureflags"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+// fakeDemoRepository records what was seeded.
+type fakeDemoRepository struct {
+	hasTransactions bool
+	seeded          *repository.Data
+}
+
+func (f *fakeDemoRepository) HasTransactions(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return f.hasTransactions, nil
+}
+
+func (f *fakeDemoRepository) Seed(ctx context.Context, userID uuid.UUID, data *repository.Data) error {
+	f.seeded = data
+	return nil
+}
+
+// fakePlanCreator records the budgets of the demo plan.
+type fakePlanCreator struct {
+	budgets []PlanBudget
+}
+
+func (f *fakePlanCreator) CreateDemoPlan(ctx context.Context, userID uuid.UUID, name, currency string, budgets []PlanBudget) (uuid.UUID, error) {
+	f.budgets = budgets
+	return uuid.New(), nil
+}
+
+func newTestService(repo repository.DemoRepository, now time.Time) *Service {
+	svc := NewService(repo)
+	svc.generator = func() *money.TestDataGenerator { return money.NewTestDataGeneratorWithSeed(42) }
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func demoEnabled() context.Context {
+	return featureflags.WithEvaluated(context.Background(), featureflags.Evaluated{featureflags.DemoData: true})
+}
+
+func TestSeedDemoData(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	repo := &fakeDemoRepository{}
+	plans := &fakePlanCreator{}
+	svc := newTestService(repo, now).WithPlanCreator(plans)
+
+	summary, err := svc.SeedDemoData(demoEnabled(), uuid.New(), "")
+	require.NoError(t, err)
+	require.NotNil(t, repo.seeded)
+	assert.Equal(t, money.EUR, repo.seeded.CurrencyCode)
+	assert.Equal(t, len(repo.seeded.Transactions), summary.Transactions)
+	assert.Equal(t, 3, summary.Subscriptions)
+	assert.Equal(t, 2, summary.Goals)
+	require.NotNil(t, summary.PlanID)
+
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	months := map[time.Month]bool{}
+	for _, tx := range repo.seeded.Transactions {
+		assert.False(t, tx.PostedAt.Before(first), "transactions start %d months back", DemoMonths)
+		assert.False(t, tx.PostedAt.After(now), "no transactions in the future")
+		months[tx.PostedAt.Month()] = true
+	}
+	assert.Len(t, months, DemoMonths)
+
+	for _, sub := range repo.seeded.Subscriptions {
+		assert.Equal(t, DemoMonths, sub.OccurrenceCount, sub.MerchantName)
+		assert.True(t, sub.NextExpectedAt.After(sub.LastSeenAt))
+	}
+
+	require.NotEmpty(t, plans.budgets)
+	for _, budget := range plans.budgets {
+		assert.NotEqual(t, "Salary", budget.Category, "income isn't budgeted")
+		assert.Positive(t, budget.BudgetMinor)
+		assert.Zero(t, budget.BudgetMinor%1000, "budgets are rounded to 10 units")
+	}
+}
+
+func TestSeedDemoData_Refused(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	repo := &fakeDemoRepository{}
+	_, err := newTestService(repo, now).SeedDemoData(context.Background(), uuid.New(), money.USD)
+	assert.ErrorIs(t, err, ErrDemoDisabled)
+
+	repo = &fakeDemoRepository{hasTransactions: true}
+	_, err = newTestService(repo, now).SeedDemoData(demoEnabled(), uuid.New(), money.USD)
+	assert.ErrorIs(t, err, ErrNotNewUser)
+	assert.Nil(t, repo.seeded)
+}
@@ -6,7 +6,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -23,9 +22,12 @@ import (
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
 	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
 	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	quotasservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/service"
 	subscriptionsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/repository"
 	subscriptionsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/service"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/speech"
 )
 
 // FinanceHandler implements the FinanceService Connect handlers.
@@ -37,6 +39,16 @@ type FinanceHandler struct {
 	goalsSvc         *goalsservice.Service
 	subscriptionsSvc *subscriptionsservice.Service
 	planSvc          *planservice.PlanService
+	languages        LanguageLookup
+	accounts         repository.AccountRepository
+	transcriber      speech.Transcriber
+	dashboard        DashboardInvalidator
+}
+
+// DashboardInvalidator is told when a user's transactions change, so cached
+// dashboard queries are recomputed (the insights service)
+type DashboardInvalidator interface {
+	InvalidateDashboard(ctx context.Context, userID uuid.UUID, blockTypes ...string)
 }
 
 // NewFinanceHandler constructs a new handler.
@@ -66,6 +78,20 @@ func (h *FinanceHandler) WithPlanService(svc *planservice.PlanService) *FinanceH
 	return h
 }
 
+// WithDashboardInvalidator refreshes the user's dashboard after transactions
+// are added or deleted here
+func (h *FinanceHandler) WithDashboardInvalidator(dashboard DashboardInvalidator) *FinanceHandler {
+	h.dashboard = dashboard
+	return h
+}
+
+// transactionsChanged invalidates the user's dashboard, if anything follows it
+func (h *FinanceHandler) transactionsChanged(ctx context.Context, userID uuid.UUID) {
+	if h.dashboard != nil {
+		h.dashboard.InvalidateDashboard(ctx, userID)
+	}
+}
+
 // ImportTransactionsCsv handles CSV transaction import with column mapping.
 func (h *FinanceHandler) ImportTransactionsCsv(
 	ctx context.Context,
@@ -92,11 +118,6 @@ func (h *FinanceHandler) ImportTransactionsCsv(
 		accountID = &parsed
 	}
 
-	// Validate CSV bytes
-	if len(req.Msg.CsvBytes) == 0 {
-		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("csv_bytes is required"))
-	}
-
 	// Convert proto CsvMapping to service ColumnMapping
 	mapping := h.protoMappingToService(req.Msg.Mapping, req.Msg.DateFormat)
 
@@ -106,6 +127,9 @@ func (h *FinanceHandler) ImportTransactionsCsv(
 		Timezone:        req.Msg.Timezone,
 		InstitutionName: req.Msg.InstitutionName,
 	})
+	if errors.Is(err, quotasservice.ErrQuotaExceeded) {
+		return nil, connect.NewError(connect.CodeResourceExhausted, err)
+	}
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
@@ -239,8 +263,9 @@ func (h *FinanceHandler) ListTransactions(
 
 	// Build filter from request
 	filter := repository.ListTransactionsFilter{
-		Limit:  50, // Default
-		Offset: 0,
+		Limit:            50, // Default
+		Offset:           0,
+		IncludeHousehold: true,
 	}
 
 	// Parse pagination
@@ -389,6 +414,7 @@ func (h *FinanceHandler) DeleteImportBatch(
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to delete import batch: %w", err))
 	}
+	h.transactionsChanged(ctx, userID)
 
 	return connect.NewResponse(&echov1.DeleteImportBatchResponse{
 		DeletedCount: int32(deletedCount),
@@ -514,28 +540,48 @@ func (h *FinanceHandler) CreateManualTransaction(
 		return nil, connect.NewError(connect.CodeInternal, errors.New("invalid user ID in context"))
 	}
 
-	// Parse natural language input
-	parsed := parseNaturalLanguage(req.Msg.RawText)
+	resp, err := h.createManualTransaction(ctx, userID, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// createManualTransaction parses and stores a Quick Capture transaction for the user
+func (h *FinanceHandler) createManualTransaction(
+	ctx context.Context,
+	userID uuid.UUID,
+	msg *echov1.CreateManualTransactionRequest,
+) (*echov1.CreateManualTransactionResponse, error) {
+	if err := h.importSvc.CheckTransactionQuota(ctx, userID, 1); err != nil {
+		if errors.Is(err, quotasservice.ErrQuotaExceeded) {
+			return nil, connect.NewError(connect.CodeResourceExhausted, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// Parse natural language input in the user's language
+	parsed := parseCapture(msg.RawText, h.captureOptionsFor(ctx, userID))
 
 	// Allow overrides from request
 	description := parsed.Description
-	if req.Msg.Description != nil && *req.Msg.Description != "" {
-		description = *req.Msg.Description
+	if msg.Description != nil && *msg.Description != "" {
+		description = *msg.Description
 	}
 
 	amountMinor := parsed.AmountMinor
-	if req.Msg.AmountMinor != nil {
-		amountMinor = *req.Msg.AmountMinor
+	if msg.AmountMinor != nil {
+		amountMinor = *msg.AmountMinor
 	}
 
 	txDate := parsed.Date
-	if req.Msg.Date != nil {
-		txDate = req.Msg.Date.AsTime()
+	if msg.Date != nil {
+		txDate = msg.Date.AsTime()
 	}
 
-	var accountID *uuid.UUID
-	if req.Msg.AccountId != nil && *req.Msg.AccountId != "" {
-		id, err := uuid.Parse(*req.Msg.AccountId)
+	accountID := parsed.AccountID
+	if msg.AccountId != nil && *msg.AccountId != "" {
+		id, err := uuid.Parse(*msg.AccountId)
 		if err != nil {
 			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid account_id"))
 		}
@@ -545,9 +591,10 @@ func (h *FinanceHandler) CreateManualTransaction(
 	// Auto-categorize using the high-performance categorization service
 	var categoryID *uuid.UUID
 	var suggestedCategoryID *string
-	if req.Msg.CategoryId != nil && *req.Msg.CategoryId != "" {
+	var autoCategoryID, ruleID, merchantID *uuid.UUID
+	if msg.CategoryId != nil && *msg.CategoryId != "" {
 		// User provided category override
-		id, err := uuid.Parse(*req.Msg.CategoryId)
+		id, err := uuid.Parse(*msg.CategoryId)
 		if err != nil {
 			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid category_id"))
 		}
@@ -557,6 +604,8 @@ func (h *FinanceHandler) CreateManualTransaction(
 		catResult, _ := h.catService.CategorizeWithFallback(ctx, userID, description, 75)
 		if catResult != nil && catResult.CategoryID != nil {
 			categoryID = catResult.CategoryID
+			autoCategoryID, ruleID, merchantID = catResult.CategoryID, catResult.RuleID, catResult.MerchantID
+			observability.CategorizationPredictionsTotal.WithLabelValues(categorization.PredictionSource(ruleID)).Inc()
 			s := catResult.CategoryID.String()
 			suggestedCategoryID = &s
 		}
@@ -569,23 +618,25 @@ func (h *FinanceHandler) CreateManualTransaction(
 		AccountID:           accountID,
 		CategoryID:          categoryID,
 		Description:         description,
-		OriginalDescription: &req.Msg.RawText,
+		OriginalDescription: &msg.RawText,
 		AmountCents:         amountMinor,
 		CurrencyCode:        parsed.Currency,
 		Date:                txDate,
 		Source:              "manual",
+		AutoCategoryID:      autoCategoryID,
+		RuleID:              ruleID,
+		MerchantID:          merchantID,
+		Tags:                parsed.Tags,
 	}
 
-	err = h.importRepo.InsertTransaction(ctx, tx)
-	if err != nil {
+	if err := h.importRepo.InsertTransaction(ctx, tx); err != nil {
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create transaction: %w", err))
 	}
+	h.transactionsChanged(ctx, userID)
 
 	// Double-Entry: Update Active Plan Actuals
-	// Pass description as category name hint for matching budget items
 	if h.planSvc != nil {
-		categoryName := description // Use description as category hint
-		if err := h.planSvc.ProcessTransaction(ctx, userID, tx.AmountCents, tx.CategoryID, categoryName); err != nil {
+		if err := h.planSvc.ProcessTransaction(ctx, userID); err != nil {
 			// Log error but don't fail the request (budget tracking is secondary to data integrity)
 			fmt.Printf("failed to process transaction for plan: %v\n", err)
 		}
@@ -595,105 +646,13 @@ func (h *FinanceHandler) CreateManualTransaction(
 	// For now, return empty - can be enhanced later
 	var budgetImpact *string
 
-	return connect.NewResponse(&echov1.CreateManualTransactionResponse{
+	return &echov1.CreateManualTransactionResponse{
 		Transaction:         transactionToProto(tx),
 		ParsedDescription:   parsed.Description,
 		ParsedAmountMinor:   parsed.AmountMinor,
 		SuggestedCategoryId: suggestedCategoryID,
 		BudgetImpact:        budgetImpact,
-	}), nil
-}
-
-// parseNaturalLanguage extracts transaction details from natural language input.
-// By default, amounts are treated as EXPENSES (negative).
-// Use "+" prefix for income transactions (e.g., "+Salary 100$").
-func parseNaturalLanguage(rawText string) parsedTransaction {
-	result := parsedTransaction{
-		Date:     timestamppb.Now().AsTime(),
-		Currency: "EUR",
-	}
-
-	rawText = strings.TrimSpace(rawText)
-	if rawText == "" {
-		return result
-	}
-
-	// Check for income prefix "+"
-	isIncome := false
-	if strings.HasPrefix(rawText, "+") {
-		isIncome = true
-		rawText = strings.TrimPrefix(rawText, "+")
-		rawText = strings.TrimSpace(rawText)
-	}
-
-	// Simple regex-based parsing for amounts
-	// Matches: $1, 1$, €5, 5€, $10.50, 10,50€, etc.
-	amountPattern := regexp.MustCompile(`(?:(\$|€|EUR|USD)\s*)?(\d+(?:[.,]\d{1,2})?)\s*(\$|€|EUR|USD)?`)
-	matches := amountPattern.FindAllStringSubmatchIndex(rawText, -1)
-
-	if len(matches) == 0 {
-		// No amount found, entire text is description
-		result.Description = rawText
-		return result
-	}
-
-	// Use the last match (most likely the amount)
-	match := matches[len(matches)-1]
-	fullMatchStart := match[0]
-	fullMatchEnd := match[1]
-
-	// Extract amount string
-	amountStart := match[4]
-	amountEnd := match[5]
-	amountStr := rawText[amountStart:amountEnd]
-
-	// Detect currency from prefix or suffix
-	if match[2] != -1 && match[3] != -1 {
-		prefix := rawText[match[2]:match[3]]
-		result.Currency = normalizeCurrency(prefix)
-	} else if match[6] != -1 && match[7] != -1 {
-		suffix := rawText[match[6]:match[7]]
-		result.Currency = normalizeCurrency(suffix)
-	}
-
-	// Parse amount - handle European format (comma as decimal)
-	amountStr = strings.Replace(amountStr, ",", ".", 1)
-	if amount, err := strconv.ParseFloat(amountStr, 64); err == nil {
-		amountMinor := int64(amount * 100)
-		// Default to NEGATIVE (expense) unless explicitly marked as income with "+"
-		if !isIncome {
-			amountMinor = -amountMinor
-		}
-		result.AmountMinor = amountMinor
-	}
-
-	// Extract description (text without the amount part)
-	description := rawText[:fullMatchStart] + rawText[fullMatchEnd:]
-	description = strings.Join(strings.Fields(description), " ")
-	if len(description) > 0 {
-		description = strings.ToUpper(description[:1]) + description[1:]
-	}
-	result.Description = description
-
-	return result
-}
-
-type parsedTransaction struct {
-	Description string
-	AmountMinor int64
-	Currency    string
-	Date        time.Time
-}
-
-func normalizeCurrency(symbol string) string {
-	switch strings.ToUpper(symbol) {
-	case "$", "USD":
-		return "USD"
-	case "€", "EUR":
-		return "EUR"
-	default:
-		return "EUR"
-	}
+	}, nil
 }
 
 // ============================================================================
@@ -707,6 +666,10 @@ func normalizeCurrency(symbol string) string {
 // - CategorizeWithFallback: Fast exact + fuzzy fallback
 // - SuggestMerchantMatches: Fuzzy autocomplete suggestions
 // - SearchMerchants: Full-text Bleve search
+// - ExplainCategorization: Which rule, merchant or fuzzy match decided, with the
+//   alternatives considered and whether a user rule overrode a global merchant
+// - GetReviewQueue / ResolveReviewItem: Uncategorized and low-confidence
+//   transactions grouped by merchant with suggested categories, resolved in bulk
 //
 // These are integrated internally via CategorizeWithFallback in CreateManualTransaction
 // and CategorizeBatchFast in the import service for maximum performance.
@@ -717,6 +680,8 @@ func normalizeCurrency(symbol string) string {
 // - CategorizeWithFallbackRequest/Response
 // - SuggestMerchantMatchesRequest/Response
 // - SearchMerchantsRequest/Response
+// - ExplainCategorizationRequest/Response
+// - GetReviewQueueRequest/Response, ResolveReviewItemRequest/Response
 
 // ============================================================================
 // Goals Management
@@ -770,7 +735,7 @@ func (h *FinanceHandler) GetGoal(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("goals service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -780,7 +745,7 @@ func (h *FinanceHandler) GetGoal(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	goal, err := h.goalsSvc.GetGoal(ctx, goalID)
+	goal, err := h.goalsSvc.GetGoal(ctx, userID, goalID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -788,7 +753,7 @@ func (h *FinanceHandler) GetGoal(
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
-	progress, err := h.goalsSvc.GetGoalProgress(ctx, goalID)
+	progress, err := h.goalsSvc.GetGoalProgress(ctx, userID, goalID)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
@@ -807,7 +772,7 @@ func (h *FinanceHandler) UpdateGoal(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("goals service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -839,7 +804,7 @@ func (h *FinanceHandler) UpdateGoal(
 		status = &s
 	}
 
-	goal, err := h.goalsSvc.UpdateGoal(ctx, goalID, name, targetMinor, endAt, status)
+	goal, err := h.goalsSvc.UpdateGoal(ctx, userID, goalID, name, targetMinor, endAt, status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -861,7 +826,7 @@ func (h *FinanceHandler) DeleteGoal(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("goals service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -871,7 +836,7 @@ func (h *FinanceHandler) DeleteGoal(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	if err := h.goalsSvc.DeleteGoal(ctx, goalID); err != nil {
+	if err := h.goalsSvc.DeleteGoal(ctx, userID, goalID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
 		}
@@ -901,15 +866,14 @@ func (h *FinanceHandler) ListGoals(
 		statusFilter = &s
 	}
 
-	goals, err := h.goalsSvc.ListGoals(ctx, userID, statusFilter)
+	goals, err := h.goalsSvc.ListGoalProgress(ctx, userID, statusFilter)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
 	protoGoals := make([]*echov1.Goal, 0, len(goals))
-	for _, goal := range goals {
-		progress, _ := h.goalsSvc.GetGoalProgress(ctx, goal.ID)
-		protoGoals = append(protoGoals, goalWithProgressToProto(goal, progress))
+	for _, progress := range goals {
+		protoGoals = append(protoGoals, goalWithProgressToProto(progress.Goal, progress))
 	}
 
 	return connect.NewResponse(&echov1.ListGoalsResponse{
@@ -926,7 +890,7 @@ func (h *FinanceHandler) GetGoalProgress(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("goals service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -936,7 +900,7 @@ func (h *FinanceHandler) GetGoalProgress(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	progress, err := h.goalsSvc.GetGoalProgress(ctx, goalID)
+	progress, err := h.goalsSvc.GetGoalProgress(ctx, userID, goalID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -991,7 +955,7 @@ func (h *FinanceHandler) ContributeToGoal(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("goals service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1011,7 +975,7 @@ func (h *FinanceHandler) ContributeToGoal(
 		note = req.Msg.Note
 	}
 
-	progress, milestone, err := h.goalsSvc.ContributeToGoal(ctx, goalID, req.Msg.Amount.AmountMinor, currency, note)
+	progress, milestone, err := h.goalsSvc.ContributeToGoal(ctx, userID, goalID, req.Msg.Amount.AmountMinor, currency, note)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -1140,7 +1104,7 @@ func (h *FinanceHandler) UpdateSubscriptionStatus(
 		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("subscriptions service not configured"))
 	}
 
-	_, err := getUserIDFromContext(ctx)
+	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1152,7 +1116,7 @@ func (h *FinanceHandler) UpdateSubscriptionStatus(
 
 	status := protoToSubscriptionStatus(req.Msg.Status)
 
-	sub, err := h.subscriptionsSvc.UpdateStatus(ctx, subID, status)
+	sub, err := h.subscriptionsSvc.UpdateStatus(ctx, userID, subID, status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("subscription not found"))
@@ -1408,3 +1372,19 @@ func reviewReasonToProto(r subscriptionsservice.ReviewReason) echov1.Subscriptio
 		return echov1.SubscriptionReviewReason_SUBSCRIPTION_REVIEW_REASON_UNSPECIFIED
 	}
 }
+
+// ============================================================================
+// Cash-back and Rewards (Internal Integration)
+// ============================================================================
+// The following methods are available on the rewards service but require
+// proto definitions to be exposed as API endpoints:
+//
+// - GetYearlySummary: a year's rewards by month, originating category and rule
+// - MarkReward / UnmarkReward: manually flag a credit as a reward (or back to income)
+//
+// Detection runs after each import and on the scheduler. Rewards are excluded
+// from income and attributed rewards reduce their category's net spend.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetRewardsSummaryRequest/Response
+// - MarkRewardRequest/Response
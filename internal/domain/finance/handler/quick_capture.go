@@ -0,0 +1,860 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// LanguageLookup returns a user's preferred language (e.g. "pt" or "pt-PT"),
+// which picks the words and number format Quick Capture understands
+type LanguageLookup interface {
+	UserLanguage(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// WithLanguageLookup parses Quick Capture input in each user's language.
+// Without it, input is parsed with the conventions of every supported language.
+func (h *FinanceHandler) WithLanguageLookup(languages LanguageLookup) *FinanceHandler {
+	h.languages = languages
+	return h
+}
+
+// userLanguage returns the user's Quick Capture language, "" if unknown
+func (h *FinanceHandler) userLanguage(ctx context.Context, userID uuid.UUID) string {
+	if h.languages == nil {
+		return ""
+	}
+	lang, err := h.languages.UserLanguage(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	return lang
+}
+
+// WithAccounts lets Quick Capture input name one of the user's accounts, as
+// in "lunch 12€ on revolut"
+func (h *FinanceHandler) WithAccounts(accounts repository.AccountRepository) *FinanceHandler {
+	h.accounts = accounts
+	return h
+}
+
+// captureOptionsFor returns the options to parse the user's Quick Capture
+// input with. Lookups that fail only cost the input its language and account
+// hints.
+func (h *FinanceHandler) captureOptionsFor(ctx context.Context, userID uuid.UUID) captureOptions {
+	opts := captureOptions{Language: h.userLanguage(ctx, userID), Now: time.Now()}
+	if h.accounts != nil {
+		if accounts, err := h.accounts.ListActiveAccounts(ctx, userID); err == nil {
+			opts.Accounts = accounts
+		}
+	}
+	return opts
+}
+
+// CaptureText records a Quick Capture transaction from raw text for the user,
+// the same way CreateManualTransaction does, for integrations such as chat
+// bots. Returns nil without an error when the text holds no amount.
+func (h *FinanceHandler) CaptureText(ctx context.Context, userID uuid.UUID, rawText string) (*echov1.Transaction, error) {
+	if parseNaturalLanguageIn(rawText, h.userLanguage(ctx, userID), time.Now()).AmountMinor == 0 {
+		return nil, nil
+	}
+	resp, err := h.createManualTransaction(ctx, userID, &echov1.CreateManualTransactionRequest{RawText: rawText})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transaction, nil
+}
+
+type parsedTransaction struct {
+	Description string
+	AmountMinor int64 // Total for all units when a quantity is given
+	Currency    string
+	Date        time.Time
+	Quantity    int        // "2x coffee", 1 when not given
+	Tags        []string   // Hashtags, lower-cased without the "#"
+	AccountID   *uuid.UUID // Account hinted with e.g. "on revolut"
+}
+
+// captureOptions configure how Quick Capture input is read
+type captureOptions struct {
+	Language string // User's locale, "" accepts every supported language
+	Now      time.Time
+	Accounts []repository.AccountRef // Accounts an "on <account>" hint can name
+}
+
+// captureLanguage holds the words and conventions of one Quick Capture language
+type captureLanguage struct {
+	decimalComma bool           // "1,20" is a decimal and "1.500" a thousand
+	dayOffsets   map[string]int // Relative date words, in days before today
+	numbers      map[string]int // Number words
+	multipliers  map[string]int // "hundred", "mil", ...
+	connectors   map[string]bool
+	currencies   map[string]string // Currency words
+
+	weekdays     map[string]time.Weekday
+	weekdayHints map[string]time.Weekday // Weekday words that only mean a date after a preposition or "last"
+	lastWords    map[string]bool         // "last friday", "sexta passada"
+	months       map[string]time.Month
+	datePreps    map[string]bool // Prepositions before a date, dropped from the description
+	accountPreps map[string]bool // Words before an account hint, "on revolut"
+	possessives  map[string]bool // "my", "meu", ... between the preposition and the account
+}
+
+var captureLanguages = map[string]*captureLanguage{
+	"en": {
+		dayOffsets: map[string]int{"today": 0, "yesterday": 1},
+		numbers: map[string]int{
+			"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+			"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13,
+			"fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18,
+			"nineteen": 19, "twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+			"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+		},
+		multipliers: map[string]int{"hundred": 100, "thousand": 1000},
+		connectors:  map[string]bool{"and": true},
+		currencies: map[string]string{
+			"euro": "EUR", "euros": "EUR", "dollar": "USD", "dollars": "USD", "pound": "GBP",
+			"pounds": "GBP", "reais": "BRL", "franc": "CHF", "francs": "CHF",
+		},
+		weekdays: map[string]time.Weekday{
+			"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+			"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+			"sunday": time.Sunday,
+		},
+		lastWords: map[string]bool{"last": true},
+		months: map[string]time.Month{
+			"january": time.January, "jan": time.January, "february": time.February,
+			"feb": time.February, "march": time.March, "mar": time.March, "april": time.April,
+			"apr": time.April, "may": time.May, "june": time.June, "jun": time.June,
+			"july": time.July, "jul": time.July, "august": time.August, "aug": time.August,
+			"september": time.September, "sep": time.September, "sept": time.September,
+			"october": time.October, "oct": time.October, "november": time.November,
+			"nov": time.November, "december": time.December, "dec": time.December,
+		},
+		datePreps:    map[string]bool{"on": true},
+		accountPreps: map[string]bool{"on": true, "with": true, "via": true, "using": true, "from": true},
+		possessives:  map[string]bool{"my": true},
+	},
+	"pt": {
+		decimalComma: true,
+		dayOffsets:   map[string]int{"hoje": 0, "ontem": 1, "anteontem": 2},
+		numbers: map[string]int{
+			"um": 1, "uma": 1, "dois": 2, "duas": 2, "três": 3, "tres": 3, "quatro": 4,
+			"cinco": 5, "seis": 6, "sete": 7, "oito": 8, "nove": 9, "dez": 10, "onze": 11,
+			"doze": 12, "treze": 13, "catorze": 14, "quatorze": 14, "quinze": 15,
+			"dezasseis": 16, "dezesseis": 16, "dezassete": 17, "dezessete": 17,
+			"dezoito": 18, "dezanove": 19, "dezenove": 19, "vinte": 20, "trinta": 30,
+			"quarenta": 40, "cinquenta": 50, "sessenta": 60, "setenta": 70,
+			"oitenta": 80, "noventa": 90, "cem": 100, "cento": 100, "duzentos": 200,
+			"trezentos": 300, "quatrocentos": 400, "quinhentos": 500, "seiscentos": 600,
+			"setecentos": 700, "oitocentos": 800, "novecentos": 900,
+		},
+		multipliers: map[string]int{"mil": 1000},
+		connectors:  map[string]bool{"e": true},
+		currencies: map[string]string{
+			"euro": "EUR", "euros": "EUR", "dólar": "USD", "dólares": "USD", "libras": "GBP",
+			"reais": "BRL", "francos": "CHF",
+		},
+		weekdays: map[string]time.Weekday{
+			"segunda-feira": time.Monday, "terça-feira": time.Tuesday, "quarta-feira": time.Wednesday,
+			"quinta-feira": time.Thursday, "sexta-feira": time.Friday, "sábado": time.Saturday,
+			"domingo": time.Sunday,
+		},
+		// "segunda" is also "second" and "quinta" a farm
+		weekdayHints: map[string]time.Weekday{
+			"segunda": time.Monday, "terça": time.Tuesday, "quarta": time.Wednesday,
+			"quinta": time.Thursday, "sexta": time.Friday,
+		},
+		lastWords: map[string]bool{"passada": true, "passado": true, "último": true, "última": true},
+		months: map[string]time.Month{
+			"janeiro": time.January, "fevereiro": time.February, "março": time.March,
+			"abril": time.April, "maio": time.May, "junho": time.June, "julho": time.July,
+			"agosto": time.August, "setembro": time.September, "outubro": time.October,
+			"novembro": time.November, "dezembro": time.December,
+		},
+		datePreps:    map[string]bool{"na": true, "no": true, "em": true, "a": true},
+		accountPreps: map[string]bool{"com": true, "no": true, "na": true, "pelo": true, "pela": true},
+		possessives:  map[string]bool{"meu": true, "minha": true, "o": true, "a": true},
+	},
+	"es": {
+		decimalComma: true,
+		dayOffsets:   map[string]int{"hoy": 0, "ayer": 1, "anteayer": 2},
+		numbers: map[string]int{
+			"un": 1, "uno": 1, "una": 1, "dos": 2, "tres": 3, "cuatro": 4, "cinco": 5,
+			"seis": 6, "siete": 7, "ocho": 8, "nueve": 9, "diez": 10, "once": 11,
+			"doce": 12, "trece": 13, "catorce": 14, "quince": 15, "dieciséis": 16,
+			"diecisiete": 17, "dieciocho": 18, "diecinueve": 19, "veinte": 20,
+			"veintiuno": 21, "veintidós": 22, "veintitrés": 23, "veinticuatro": 24,
+			"veinticinco": 25, "veintiséis": 26, "veintisiete": 27, "veintiocho": 28,
+			"veintinueve": 29, "treinta": 30, "cuarenta": 40, "cincuenta": 50,
+			"sesenta": 60, "setenta": 70, "ochenta": 80, "noventa": 90, "cien": 100,
+			"ciento": 100, "doscientos": 200, "trescientos": 300, "cuatrocientos": 400,
+			"quinientos": 500, "seiscientos": 600, "setecientos": 700, "ochocientos": 800,
+			"novecientos": 900,
+		},
+		multipliers: map[string]int{"mil": 1000},
+		connectors:  map[string]bool{"y": true},
+		currencies: map[string]string{
+			"euro": "EUR", "euros": "EUR", "dólar": "USD", "dólares": "USD", "libras": "GBP",
+			"reales": "BRL", "francos": "CHF",
+		},
+		weekdays: map[string]time.Weekday{
+			"lunes": time.Monday, "martes": time.Tuesday, "miércoles": time.Wednesday,
+			"jueves": time.Thursday, "viernes": time.Friday, "sábado": time.Saturday,
+			"domingo": time.Sunday,
+		},
+		lastWords: map[string]bool{"pasado": true, "pasada": true},
+		months: map[string]time.Month{
+			"enero": time.January, "febrero": time.February, "marzo": time.March,
+			"abril": time.April, "mayo": time.May, "junio": time.June, "julio": time.July,
+			"agosto": time.August, "septiembre": time.September, "setiembre": time.September,
+			"octubre": time.October, "noviembre": time.November, "diciembre": time.December,
+		},
+		datePreps:    map[string]bool{"el": true},
+		accountPreps: map[string]bool{"con": true, "en": true, "por": true},
+		possessives:  map[string]bool{"mi": true, "la": true, "el": true},
+	},
+	"de": {
+		decimalComma: true,
+		dayOffsets:   map[string]int{"heute": 0, "gestern": 1, "vorgestern": 2},
+		numbers: map[string]int{
+			"ein": 1, "eins": 1, "eine": 1, "zwei": 2, "drei": 3, "vier": 4, "fünf": 5,
+			"sechs": 6, "sieben": 7, "acht": 8, "neun": 9, "zehn": 10, "elf": 11,
+			"zwölf": 12, "dreizehn": 13, "vierzehn": 14, "fünfzehn": 15, "sechzehn": 16,
+			"siebzehn": 17, "achtzehn": 18, "neunzehn": 19, "zwanzig": 20, "dreißig": 30,
+			"vierzig": 40, "fünfzig": 50, "sechzig": 60, "siebzig": 70, "achtzig": 80,
+			"neunzig": 90,
+		},
+		multipliers: map[string]int{"hundert": 100, "tausend": 1000},
+		connectors:  map[string]bool{"und": true},
+		currencies: map[string]string{
+			"euro": "EUR", "euros": "EUR", "dollar": "USD", "pfund": "GBP", "reais": "BRL",
+			"franken": "CHF",
+		},
+		weekdays: map[string]time.Weekday{
+			"montag": time.Monday, "dienstag": time.Tuesday, "mittwoch": time.Wednesday,
+			"donnerstag": time.Thursday, "freitag": time.Friday, "samstag": time.Saturday,
+			"sonntag": time.Sunday,
+		},
+		lastWords: map[string]bool{"letzten": true, "letzter": true, "vergangenen": true},
+		months: map[string]time.Month{
+			"januar": time.January, "jänner": time.January, "februar": time.February,
+			"märz": time.March, "april": time.April, "mai": time.May, "juni": time.June,
+			"juli": time.July, "august": time.August, "september": time.September,
+			"oktober": time.October, "november": time.November, "dezember": time.December,
+		},
+		datePreps:    map[string]bool{"am": true},
+		accountPreps: map[string]bool{"mit": true, "per": true, "über": true, "auf": true},
+		possessives:  map[string]bool{"meiner": true, "meinem": true, "mein": true, "meine": true, "der": true, "dem": true},
+	},
+}
+
+// amountPattern matches an amount with an optional currency symbol or code on
+// either side, e.g. "$1", "1,20€", "R$ 35", "1.500,00 EUR" or "1,500.00"
+var amountPattern = regexp.MustCompile(`(?i)(?:(R\$|\$|€|£|\b(?:EUR|USD|GBP|BRL|CHF)\b)\s*)?(\d+(?:[.,]\d{3})*(?:[.,]\d{1,2})?)\s*(R\$|\$|€|£|\b(?:EUR|USD|GBP|BRL|CHF)\b)?`)
+
+var (
+	// hashtagPattern matches "#groceries" style tags
+	hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+	// quantityPattern matches "2x", "2 x", "x2" or "2×"
+	quantityPattern = regexp.MustCompile(`(?i)(?:^|\s)(?:(\d{1,3})\s?[x×]|[x×]\s?(\d{1,3}))(?:\s|$)`)
+	// followsAmount tells a "march 12" date from "march 12€"
+	followsAmount = regexp.MustCompile(`(?i)^\s*(?:[.,]\d|R\$|\$|€|£|(?:EUR|USD|GBP|BRL|CHF)\b)`)
+
+	isoDatePattern   = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	slashDatePattern = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})(?:/(\d{4}|\d{2}))?\b`)
+	dotDatePattern   = regexp.MustCompile(`\b(\d{1,2})\.(\d{1,2})\.(\d{4})\b`) // A year is required so "1.20" stays an amount
+	dayMonthPattern  = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th|º|\.)?\s+(?:de\s+)?(` + monthNames() + `)\.?(?:\s+(?:de\s+)?(\d{4})\b)?`)
+	monthDayPattern  = regexp.MustCompile(`(?i)(?:^|\s)(` + monthNames() + `)\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4})\b)?`)
+)
+
+// dateMarker stands in for an explicit date taken out of the input, so the
+// preposition before it can be dropped from the description
+const dateMarker = "\x00"
+
+// monthNames is a regexp alternation of the month names of every language,
+// longest first so "september" wins over "sep"
+func monthNames() string {
+	var names []string
+	for _, lang := range captureLanguages {
+		for name := range lang.months {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, "|")
+}
+
+// parseNaturalLanguage parses Quick Capture input with the conventions of
+// every supported language
+func parseNaturalLanguage(rawText string) parsedTransaction {
+	return parseNaturalLanguageIn(rawText, "", time.Now())
+}
+
+// parseNaturalLanguageIn parses Quick Capture input in the given language
+// ("" accepts all of them) without account hints
+func parseNaturalLanguageIn(rawText, language string, now time.Time) parsedTransaction {
+	return parseCapture(rawText, captureOptions{Language: language, Now: now})
+}
+
+// parseCapture extracts transaction details from natural language input, e.g.
+// "Coffee 1$", "café 1,20€ ontem", "Kaffee zwei Euro gestern" or
+// "2x coffee 3€ last friday on revolut #work". By default, amounts are
+// treated as EXPENSES (negative); use a "+" prefix for income (e.g.
+// "+ordenado 1500"). Besides the amount and currency it reads:
+//   - relative dates ("yesterday", "last friday", "sexta passada") and explicit
+//     ones ("2026-03-06", "6/3", "12 de março", "march 12")
+//   - a quantity, which multiplies the amount ("2x coffee 3€" is 6€)
+//   - an account hint naming one of opts.Accounts ("on revolut", "com o
+//     millennium"), whose currency applies when none is given
+//   - hashtags, which become tags
+func parseCapture(rawText string, opts captureOptions) parsedTransaction {
+	now := opts.Now
+	result := parsedTransaction{
+		Date:     now,
+		Currency: "EUR",
+		Quantity: 1,
+	}
+
+	rawText = strings.TrimSpace(rawText)
+	if rawText == "" {
+		return result
+	}
+
+	// Check for income prefix "+"
+	isIncome := false
+	if strings.HasPrefix(rawText, "+") {
+		isIncome = true
+		rawText = strings.TrimSpace(strings.TrimPrefix(rawText, "+"))
+	}
+
+	langs := captureLanguagesFor(opts.Language)
+
+	rawText, result.Tags = extractHashtags(rawText)
+	rawText, explicitDate := extractDates(rawText, opts.Language, langs, now)
+	if explicitDate != nil {
+		result.Date = *explicitDate
+	}
+	if match := quantityPattern.FindStringSubmatchIndex(rawText); match != nil {
+		digits := match[2:4]
+		if digits[0] == -1 {
+			digits = match[4:6]
+		}
+		if qty, err := strconv.Atoi(rawText[digits[0]:digits[1]]); err == nil && qty > 0 {
+			result.Quantity = qty
+			rawText = rawText[:match[0]] + " " + rawText[match[1]:]
+		}
+	}
+
+	var amount float64
+	var found, currencyGiven bool
+	if matches := amountPattern.FindAllStringSubmatchIndex(rawText, -1); len(matches) > 0 {
+		// Use the last match (most likely the amount)
+		match := matches[len(matches)-1]
+		amount, found = parseLocalizedAmount(rawText[match[4]:match[5]], decimalCommaFor(opts.Language))
+		if match[2] != -1 {
+			result.Currency, currencyGiven = normalizeCurrency(rawText[match[2]:match[3]]), true
+		} else if match[6] != -1 {
+			result.Currency, currencyGiven = normalizeCurrency(rawText[match[6]:match[7]]), true
+		}
+		rawText = rawText[:match[0]] + " " + rawText[match[1]:]
+	}
+
+	// The remaining words: number words, currency words, dates and account
+	// hints, then the description
+	var description []string
+	var account *repository.AccountRef
+	words := strings.Fields(rawText)
+	for i := 0; i < len(words); i++ {
+		word := captureWord(words[i])
+		if word == dateMarker {
+			description = dropDatePreposition(langs, description)
+			continue
+		}
+		if offset, ok := lookupDayOffset(langs, word); ok {
+			result.Date = now.AddDate(0, 0, -offset)
+			continue
+		}
+		if weekday, needsContext, ok := lookupWeekday(langs, word); ok {
+			lastAfter := i+1 < len(words) && isLastWord(langs, captureWord(words[i+1]))
+			lastBefore := len(description) > 0 && isLastWord(langs, captureWord(description[len(description)-1]))
+			prepBefore := len(description) > 0 && isDatePreposition(langs, captureWord(description[len(description)-1]))
+			if !needsContext || lastAfter || lastBefore || prepBefore {
+				result.Date = previousWeekday(now, weekday)
+				if lastAfter {
+					i++
+				}
+				if lastBefore {
+					description = description[:len(description)-1]
+				}
+				description = dropDatePreposition(langs, description)
+				continue
+			}
+		}
+		if code, ok := lookupCurrencyWord(langs, word); ok && (found || i > 0) {
+			result.Currency, currencyGiven = code, true
+			continue
+		}
+		if isAccountPreposition(langs, word) {
+			if acct, n := matchAccount(langs, opts.Accounts, words[i+1:]); acct != nil {
+				account = acct
+				i += n
+				continue
+			}
+		}
+		if !found {
+			if value, n := parseNumberWords(langs, words[i:]); n > 0 {
+				amount, found = float64(value), true
+				i += n - 1
+				continue
+			}
+		}
+		description = append(description, words[i])
+	}
+
+	if account != nil {
+		id := account.ID
+		result.AccountID = &id
+		if !currencyGiven && account.CurrencyCode != "" {
+			result.Currency = account.CurrencyCode
+		}
+	}
+	if found {
+		amountMinor := int64(math.Round(amount*100)) * int64(result.Quantity)
+		// Default to NEGATIVE (expense) unless explicitly marked as income with "+"
+		if !isIncome {
+			amountMinor = -amountMinor
+		}
+		result.AmountMinor = amountMinor
+	}
+	result.Description = capitalizeFirst(strings.Join(description, " "))
+	return result
+}
+
+// captureWord lower-cases a word and strips the punctuation around it
+func captureWord(word string) string {
+	return strings.ToLower(strings.Trim(word, ".,;:!?"))
+}
+
+// extractHashtags takes "#tags" out of the text, returning them lower-cased
+// without duplicates
+func extractHashtags(text string) (string, []string) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.ToLower(m[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return hashtagPattern.ReplaceAllString(text, " "), tags
+}
+
+// extractDates replaces explicit dates in the text with dateMarker and
+// returns the date. Numeric dates are day first, except in American
+// English; dates without a year are the most recent such day.
+func extractDates(text, locale string, langs []*captureLanguage, now time.Time) (string, *time.Time) {
+	monthFirst := strings.EqualFold(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "en-US")
+
+	var date *time.Time
+	replace := func(pattern *regexp.Regexp, resolve func(text string, m []int) (time.Time, bool)) {
+		matches := pattern.FindAllStringSubmatchIndex(text, -1)
+		for i := len(matches) - 1; i >= 0; i-- {
+			m := matches[i]
+			d, ok := resolve(text, m)
+			if !ok {
+				continue
+			}
+			if date == nil {
+				date = &d
+			}
+			text = text[:m[0]] + " " + dateMarker + " " + text[m[1]:]
+		}
+	}
+	group := func(text string, m []int, n int) string {
+		if m[2*n] == -1 {
+			return ""
+		}
+		return text[m[2*n]:m[2*n+1]]
+	}
+
+	replace(isoDatePattern, func(text string, m []int) (time.Time, bool) {
+		return captureDate(now, atoi(group(text, m, 1)), atoi(group(text, m, 2)), atoi(group(text, m, 3)))
+	})
+	numeric := func(text string, m []int) (time.Time, bool) {
+		day, month := atoi(group(text, m, 1)), atoi(group(text, m, 2))
+		if monthFirst {
+			day, month = month, day
+		}
+		return captureDate(now, atoi(group(text, m, 3)), month, day)
+	}
+	replace(dotDatePattern, numeric)
+	replace(slashDatePattern, numeric)
+	replace(dayMonthPattern, func(text string, m []int) (time.Time, bool) {
+		month, ok := lookupMonth(langs, strings.ToLower(group(text, m, 2)))
+		if !ok {
+			return time.Time{}, false
+		}
+		return captureDate(now, atoi(group(text, m, 3)), int(month), atoi(group(text, m, 1)))
+	})
+	replace(monthDayPattern, func(text string, m []int) (time.Time, bool) {
+		month, ok := lookupMonth(langs, strings.ToLower(group(text, m, 1)))
+		if !ok || followsAmount.MatchString(text[m[1]:]) {
+			return time.Time{}, false
+		}
+		return captureDate(now, atoi(group(text, m, 3)), int(month), atoi(group(text, m, 2)))
+	})
+	return text, date
+}
+
+// captureDate builds a date at now's time of day. A zero year is the most
+// recent such day and a two-digit year is in this century.
+func captureDate(now time.Time, year, month, day int) (time.Time, bool) {
+	yearGiven := year != 0
+	switch {
+	case !yearGiven:
+		year = now.Year()
+	case year < 100:
+		year += 2000
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return time.Time{}, false
+	}
+	d := time.Date(year, time.Month(month), day, now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location())
+	if d.Day() != day {
+		return time.Time{}, false // e.g. 31/02
+	}
+	if !yearGiven && d.After(now) {
+		d = d.AddDate(-1, 0, 0)
+	}
+	return d, true
+}
+
+// previousWeekday returns the most recent weekday before today, so on a
+// Friday "friday" is a week ago
+func previousWeekday(now time.Time, weekday time.Weekday) time.Time {
+	days := (int(now.Weekday()) - int(weekday) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return now.AddDate(0, 0, -days)
+}
+
+// matchAccount reads an account name at the start of words, after an optional
+// possessive: the full name, or its first word when that's unique. Returns the
+// account and how many words it used.
+func matchAccount(langs []*captureLanguage, accounts []repository.AccountRef, words []string) (*repository.AccountRef, int) {
+	if len(accounts) == 0 || len(words) == 0 {
+		return nil, 0
+	}
+	offsets := []int{0}
+	if len(words) > 1 && isPossessive(langs, captureWord(words[0])) {
+		offsets = []int{1, 0}
+	}
+
+	for _, off := range offsets {
+		rest := words[off:]
+		var best *repository.AccountRef
+		bestLen := 0
+		var byFirst []*repository.AccountRef
+		for i := range accounts {
+			name := strings.Fields(strings.ToLower(accounts[i].Name))
+			if len(name) == 0 || captureWord(rest[0]) != name[0] {
+				continue
+			}
+			byFirst = append(byFirst, &accounts[i])
+			if len(name) > bestLen && len(name) <= len(rest) && nameMatches(name, rest) {
+				best, bestLen = &accounts[i], len(name)
+			}
+		}
+		if best != nil {
+			return best, off + bestLen
+		}
+		if len(byFirst) == 1 {
+			return byFirst[0], off + 1
+		}
+	}
+	return nil, 0
+}
+
+func nameMatches(name, words []string) bool {
+	for i, part := range name {
+		if captureWord(words[i]) != part {
+			return false
+		}
+	}
+	return true
+}
+
+// dropDatePreposition removes a preposition such as "on" or "na" that
+// introduced a date from the end of the description
+func dropDatePreposition(langs []*captureLanguage, description []string) []string {
+	if n := len(description); n > 0 && isDatePreposition(langs, captureWord(description[n-1])) {
+		return description[:n-1]
+	}
+	return description
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// captureLanguagesFor returns the languages to read input in: the user's
+// language and English, or all of them when the language is unknown
+func captureLanguagesFor(language string) []*captureLanguage {
+	if lang, ok := captureLanguages[baseLanguage(language)]; ok {
+		if lang == captureLanguages["en"] {
+			return []*captureLanguage{lang}
+		}
+		return []*captureLanguage{lang, captureLanguages["en"]}
+	}
+	return []*captureLanguage{captureLanguages["en"], captureLanguages["pt"], captureLanguages["es"], captureLanguages["de"]}
+}
+
+// decimalCommaFor reports whether the language writes decimals with a comma.
+// Nil means unknown, so the separator is guessed from the digits after it.
+func decimalCommaFor(language string) *bool {
+	if lang, ok := captureLanguages[baseLanguage(language)]; ok {
+		return &lang.decimalComma
+	}
+	return nil
+}
+
+// baseLanguage reduces a locale such as "pt-BR" or "de_DE" to its language
+func baseLanguage(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+// parseLocalizedAmount parses a number written with either decimal convention.
+// A separator followed by three digits groups thousands and one followed by one
+// or two digits is the decimal point; when both appear, the last is the decimal
+// point. decimalComma breaks the tie for "1,500" and "1.500".
+func parseLocalizedAmount(s string, decimalComma *bool) (float64, bool) {
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	decimalSep := byte(0)
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimalSep = s[max(lastDot, lastComma)]
+	case lastDot >= 0 || lastComma >= 0:
+		i := max(lastDot, lastComma)
+		digitsAfter := len(s) - i - 1
+		sep := s[i]
+		switch {
+		case digitsAfter != 3:
+			decimalSep = sep
+		case decimalComma != nil && *decimalComma && sep == ',':
+			decimalSep = sep // "1,500" in Portuguese is one and a half
+		case decimalComma != nil && !*decimalComma && sep == '.':
+			decimalSep = sep
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			b.WriteByte(s[i])
+		case s[i] == decimalSep:
+			b.WriteByte('.')
+		}
+	}
+	v, err := strconv.ParseFloat(b.String(), 64)
+	return v, err == nil
+}
+
+// parseNumberWords reads a number written in words at the start of words,
+// e.g. "vinte e cinco", "fünfundzwanzig" or "two hundred". Returns the value
+// and how many words it used (0 if words doesn't start with a number).
+func parseNumberWords(langs []*captureLanguage, words []string) (int, int) {
+	total, current, used := 0, 0, 0
+	for i, w := range words {
+		word := strings.ToLower(strings.Trim(w, ".,;:!?"))
+		if v, ok := lookupNumberWord(langs, word); ok {
+			current += v
+			used = i + 1
+			continue
+		}
+		if m, ok := lookupMultiplier(langs, word); ok && used > 0 || ok && m == 1000 {
+			if current == 0 {
+				current = 1
+			}
+			if m == 1000 {
+				total += current * m
+				current = 0
+			} else {
+				current *= m
+			}
+			used = i + 1
+			continue
+		}
+		// A connector continues the number only if another number word follows
+		if isConnector(langs, word) && used == i && i+1 < len(words) {
+			continue
+		}
+		break
+	}
+	return total + current, used
+}
+
+// lookupNumberWord returns the value of a number word, including German
+// compounds such as "fünfundzwanzig"
+func lookupNumberWord(langs []*captureLanguage, word string) (int, bool) {
+	for _, lang := range langs {
+		if v, ok := lang.numbers[word]; ok {
+			return v, true
+		}
+	}
+	if before, after, ok := strings.Cut(word, "und"); ok && before != "" && after != "" {
+		units, ok1 := lookupNumberWord(langs, before)
+		tens, ok2 := lookupNumberWord(langs, after)
+		if ok1 && ok2 && units < 10 && tens >= 20 && tens%10 == 0 {
+			return tens + units, true
+		}
+	}
+	return 0, false
+}
+
+func lookupMultiplier(langs []*captureLanguage, word string) (int, bool) {
+	for _, lang := range langs {
+		if m, ok := lang.multipliers[word]; ok {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func isConnector(langs []*captureLanguage, word string) bool {
+	for _, lang := range langs {
+		if lang.connectors[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupDayOffset(langs []*captureLanguage, word string) (int, bool) {
+	for _, lang := range langs {
+		if d, ok := lang.dayOffsets[word]; ok {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func lookupCurrencyWord(langs []*captureLanguage, word string) (string, bool) {
+	for _, lang := range langs {
+		if code, ok := lang.currencies[word]; ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// lookupWeekday returns the weekday a word names, and whether it only names
+// one after a preposition or "last"
+func lookupWeekday(langs []*captureLanguage, word string) (time.Weekday, bool, bool) {
+	for _, lang := range langs {
+		if d, ok := lang.weekdays[word]; ok {
+			return d, false, true
+		}
+	}
+	for _, lang := range langs {
+		if d, ok := lang.weekdayHints[word]; ok {
+			return d, true, true
+		}
+	}
+	return 0, false, false
+}
+
+func lookupMonth(langs []*captureLanguage, word string) (time.Month, bool) {
+	for _, lang := range langs {
+		if m, ok := lang.months[word]; ok {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func isLastWord(langs []*captureLanguage, word string) bool {
+	for _, lang := range langs {
+		if lang.lastWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func isDatePreposition(langs []*captureLanguage, word string) bool {
+	for _, lang := range langs {
+		if lang.datePreps[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func isAccountPreposition(langs []*captureLanguage, word string) bool {
+	for _, lang := range langs {
+		if lang.accountPreps[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func isPossessive(langs []*captureLanguage, word string) bool {
+	for _, lang := range langs {
+		if lang.possessives[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalizeFirst upper-cases the first letter, including accented ones
+func capitalizeFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func normalizeCurrency(symbol string) string {
+	switch strings.ToUpper(symbol) {
+	case "$", "USD":
+		return "USD"
+	case "£", "GBP":
+		return "GBP"
+	case "€", "EUR":
+		return "EUR"
+	case "R$", "BRL":
+		return "BRL"
+	case "CHF":
+		return "CHF"
+	default:
+		return "EUR"
+	}
+}
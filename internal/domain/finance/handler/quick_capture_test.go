@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+type quickCaptureCase struct {
+	input        string
+	wantDesc     string
+	wantAmount   int64
+	wantCurrency string
+	wantDaysAgo  int
+}
+
+func runQuickCaptureCases(t *testing.T, language string, tests []quickCaptureCase) {
+	t.Helper()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := parseNaturalLanguageIn(tt.input, language, now)
+			if result.Description != tt.wantDesc {
+				t.Errorf("description = %q, want %q", result.Description, tt.wantDesc)
+			}
+			if result.AmountMinor != tt.wantAmount {
+				t.Errorf("amount = %d, want %d", result.AmountMinor, tt.wantAmount)
+			}
+			wantCurrency := tt.wantCurrency
+			if wantCurrency == "" {
+				wantCurrency = "EUR"
+			}
+			if result.Currency != wantCurrency {
+				t.Errorf("currency = %q, want %q", result.Currency, wantCurrency)
+			}
+			if want := now.AddDate(0, 0, -tt.wantDaysAgo); !result.Date.Equal(want) {
+				t.Errorf("date = %v, want %v", result.Date, want)
+			}
+		})
+	}
+}
+
+func TestParseNaturalLanguage_Portuguese(t *testing.T) {
+	runQuickCaptureCases(t, "pt-PT", []quickCaptureCase{
+		{input: "café 1,20€ ontem", wantDesc: "Café", wantAmount: -120, wantDaysAgo: 1},
+		{input: "+ordenado 1500", wantDesc: "Ordenado", wantAmount: 150000},
+		{input: "renda 1.250,00 € hoje", wantDesc: "Renda", wantAmount: -125000},
+		{input: "jantar vinte e cinco euros anteontem", wantDesc: "Jantar", wantAmount: -2500, wantDaysAgo: 2},
+		{input: "supermercado 1,500", wantDesc: "Supermercado", wantAmount: -150},
+	})
+}
+
+func TestParseNaturalLanguage_Spanish(t *testing.T) {
+	runQuickCaptureCases(t, "es", []quickCaptureCase{
+		{input: "cena 32,50€ ayer", wantDesc: "Cena", wantAmount: -3250, wantDaysAgo: 1},
+		{input: "+nómina 2.100", wantDesc: "Nómina", wantAmount: 210000},
+		{input: "taxi veintidós euros hoy", wantDesc: "Taxi", wantAmount: -2200},
+		{input: "alquiler ochocientos cincuenta", wantDesc: "Alquiler", wantAmount: -85000},
+		{input: "gimnasio treinta y cinco", wantDesc: "Gimnasio", wantAmount: -3500},
+	})
+}
+
+func TestParseNaturalLanguage_German(t *testing.T) {
+	runQuickCaptureCases(t, "de_DE", []quickCaptureCase{
+		{input: "Miete 1.234,56 €", wantDesc: "Miete", wantAmount: -123456},
+		{input: "Kaffee 3,80 gestern", wantDesc: "Kaffee", wantAmount: -380, wantDaysAgo: 1},
+		{input: "+Gehalt 2500 EUR", wantDesc: "Gehalt", wantAmount: 250000},
+		{input: "Pizza fünfundzwanzig Euro vorgestern", wantDesc: "Pizza", wantAmount: -2500, wantDaysAgo: 2},
+	})
+}
+
+func TestParseNaturalLanguage_NumberWords(t *testing.T) {
+	runQuickCaptureCases(t, "en", []quickCaptureCase{
+		{input: "lunch twelve dollars yesterday", wantDesc: "Lunch", wantAmount: -1200, wantCurrency: "USD", wantDaysAgo: 1},
+		{input: "rent one thousand two hundred", wantDesc: "Rent", wantAmount: -120000},
+		{input: "books 1,500.00$", wantDesc: "Books", wantAmount: -150000, wantCurrency: "USD"},
+		{input: "+bonus two hundred and fifty", wantDesc: "Bonus", wantAmount: 25000},
+	})
+}
+
+func TestParseCapture_Corpus(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC) // A Tuesday
+	revolut := repository.AccountRef{ID: uuid.New(), Name: "Revolut", CurrencyCode: "EUR"}
+	nubank := repository.AccountRef{ID: uuid.New(), Name: "Nubank", CurrencyCode: "BRL"}
+	millennium := repository.AccountRef{ID: uuid.New(), Name: "Millennium BCP", CurrencyCode: "EUR"}
+	accounts := []repository.AccountRef{revolut, nubank, millennium}
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		language     string
+		input        string
+		wantDesc     string
+		wantAmount   int64
+		wantCurrency string
+		wantDate     time.Time
+		wantQuantity int
+		wantTags     []string
+		wantAccount  *uuid.UUID
+	}{
+		// Relative dates
+		{"en", "dinner 40€ last friday", "Dinner", -4000, "EUR", day(time.March, 6), 1, nil, nil},
+		{"en", "gym 30 on monday", "Gym", -3000, "EUR", day(time.March, 9), 1, nil, nil},
+		{"en", "taxi 12 tuesday", "Taxi", -1200, "EUR", day(time.March, 3), 1, nil, nil},
+		{"pt-PT", "jantar 25€ sexta passada", "Jantar", -2500, "EUR", day(time.March, 6), 1, nil, nil},
+		{"pt-PT", "livro segunda mão 8€", "Livro segunda mão", -800, "EUR", now, 1, nil, nil},
+		{"pt-PT", "mercado na segunda-feira 42,10", "Mercado", -4210, "EUR", day(time.March, 9), 1, nil, nil},
+		{"es", "cine 9€ el viernes pasado", "Cine", -900, "EUR", day(time.March, 6), 1, nil, nil},
+		{"de", "Bäcker 4,50 am Samstag", "Bäcker", -450, "EUR", day(time.March, 7), 1, nil, nil},
+
+		// Explicit dates
+		{"en", "flight 2026-02-14 320€", "Flight", -32000, "EUR", day(time.February, 14), 1, nil, nil},
+		{"pt-PT", "dentista 60€ 3/2", "Dentista", -6000, "EUR", day(time.February, 3), 1, nil, nil},
+		{"en-US", "hotel $210 on 2/3", "Hotel", -21000, "USD", day(time.February, 3), 1, nil, nil},
+		{"de", "Zahnarzt 80 € am 14.02.2026", "Zahnarzt", -8000, "EUR", day(time.February, 14), 1, nil, nil},
+		{"pt-PT", "seguro 120€ a 12 de março", "Seguro", -12000, "EUR", day(time.March, 12).AddDate(-1, 0, 0), 1, nil, nil},
+		{"en", "concert march 1 45€", "Concert", -4500, "EUR", day(time.March, 1), 1, nil, nil},
+		{"en", "tickets on 5 jan 2025 30€", "Tickets", -3000, "EUR", time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC), 1, nil, nil},
+
+		// Currencies
+		{"en", "tea £3.20", "Tea", -320, "GBP", now, 1, nil, nil},
+		{"pt-BR", "almoço R$ 35,90", "Almoço", -3590, "BRL", now, 1, nil, nil},
+		{"pt-BR", "uber 18 reais", "Uber", -1800, "BRL", now, 1, nil, nil},
+		{"de", "Fondue 42 CHF", "Fondue", -4200, "CHF", now, 1, nil, nil},
+		{"de", "Zug zwanzig Franken", "Zug", -2000, "CHF", now, 1, nil, nil},
+
+		// Quantities
+		{"en", "2x coffee 3€", "Coffee", -600, "EUR", now, 2, nil, nil},
+		{"en", "beer x3 4.50", "Beer", -1350, "EUR", now, 3, nil, nil},
+		{"pt-PT", "pastel de nata 3 × 1,30€", "Pastel de nata", -390, "EUR", now, 3, nil, nil},
+
+		// Account hints
+		{"en", "lunch 12€ on revolut", "Lunch", -1200, "EUR", now, 1, nil, &revolut.ID},
+		{"pt-BR", "farmácia 27,50 no nubank", "Farmácia", -2750, "BRL", now, 1, nil, &nubank.ID},
+		{"pt-PT", "luz 45€ com o millennium bcp", "Luz", -4500, "EUR", now, 1, nil, &millennium.ID},
+		{"en", "coffee 2 with my millennium", "Coffee", -200, "EUR", now, 1, nil, &millennium.ID},
+		{"en", "dinner on the boat 30€", "Dinner on the boat", -3000, "EUR", now, 1, nil, nil},
+
+		// Hashtags
+		{"en", "team lunch 60€ #work #Client-A #work", "Team lunch", -6000, "EUR", now, 1, []string{"work", "client-a"}, nil},
+
+		// Everything at once
+		{"en", "+refund 2x 15$ yesterday on revolut #travel", "Refund", 3000, "USD", day(time.March, 9), 2, []string{"travel"}, &revolut.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language+"/"+tt.input, func(t *testing.T) {
+			result := parseCapture(tt.input, captureOptions{Language: tt.language, Now: now, Accounts: accounts})
+			if result.Description != tt.wantDesc {
+				t.Errorf("description = %q, want %q", result.Description, tt.wantDesc)
+			}
+			if result.AmountMinor != tt.wantAmount {
+				t.Errorf("amount = %d, want %d", result.AmountMinor, tt.wantAmount)
+			}
+			if result.Currency != tt.wantCurrency {
+				t.Errorf("currency = %q, want %q", result.Currency, tt.wantCurrency)
+			}
+			if !result.Date.Equal(tt.wantDate) {
+				t.Errorf("date = %v, want %v", result.Date, tt.wantDate)
+			}
+			if result.Quantity != tt.wantQuantity {
+				t.Errorf("quantity = %d, want %d", result.Quantity, tt.wantQuantity)
+			}
+			if !slices.Equal(result.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", result.Tags, tt.wantTags)
+			}
+			switch {
+			case tt.wantAccount == nil && result.AccountID != nil:
+				t.Errorf("account = %v, want none", *result.AccountID)
+			case tt.wantAccount != nil && (result.AccountID == nil || *result.AccountID != *tt.wantAccount):
+				t.Errorf("account = %v, want %v", result.AccountID, *tt.wantAccount)
+			}
+		})
+	}
+}
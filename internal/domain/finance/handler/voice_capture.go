@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/speech"
+)
+
+// =============================================================================
+// Voice Capture (Internal Integration)
+// =============================================================================
+// A voice note is transcribed and read by the Quick Capture parser into a
+// draft the user confirms before anything is stored. Confirming sends the
+// transcript (or the edited fields) to CreateManualTransaction.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateTransactionFromAudioRequest (audio bytes, mime_type)
+// - CreateTransactionFromAudioResponse (FinanceService.CreateTransactionFromAudio)
+//   with the transcript and the parsed draft fields below
+
+// VoiceCaptureDraft is a transaction read from a voice note, not yet stored
+type VoiceCaptureDraft struct {
+	Transcript          string
+	Description         string
+	AmountMinor         int64 // Zero when no amount was heard
+	Currency            string
+	Date                time.Time
+	Quantity            int
+	Tags                []string
+	AccountID           *uuid.UUID
+	SuggestedCategoryID *uuid.UUID
+}
+
+// WithTranscriber enables voice capture
+func (h *FinanceHandler) WithTranscriber(transcriber speech.Transcriber) *FinanceHandler {
+	h.transcriber = transcriber
+	return h
+}
+
+// CreateTransactionFromAudio transcribes a short voice note in the user's
+// language and parses the transcript as Quick Capture input, returning the
+// draft for the user to confirm
+func (h *FinanceHandler) CreateTransactionFromAudio(ctx context.Context, userID uuid.UUID, audio []byte, mimeType string) (*VoiceCaptureDraft, error) {
+	if h.transcriber == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errors.New("voice capture is not configured"))
+	}
+
+	opts := h.captureOptionsFor(ctx, userID)
+	transcript, err := h.transcriber.Transcribe(ctx, audio, mimeType, opts.Language)
+	switch {
+	case errors.Is(err, speech.ErrEmptyAudio), errors.Is(err, speech.ErrAudioTooLarge):
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, speech.ErrNoSpeech):
+		return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+	case err != nil:
+		return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("failed to transcribe audio: %w", err))
+	}
+
+	parsed := parseCapture(transcript, opts)
+	draft := &VoiceCaptureDraft{
+		Transcript:  transcript,
+		Description: parsed.Description,
+		AmountMinor: parsed.AmountMinor,
+		Currency:    parsed.Currency,
+		Date:        parsed.Date,
+		Quantity:    parsed.Quantity,
+		Tags:        parsed.Tags,
+		AccountID:   parsed.AccountID,
+	}
+	if h.catService != nil && parsed.Description != "" {
+		if result, _ := h.catService.CategorizeWithFallback(ctx, userID, parsed.Description, 75); result != nil {
+			draft.SuggestedCategoryID = result.CategoryID
+		}
+	}
+	return draft, nil
+}
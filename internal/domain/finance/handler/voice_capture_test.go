@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/speech"
+)
+
+type fakeTranscriber struct {
+	text     string
+	err      error
+	language string
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType, language string) (string, error) {
+	f.language = language
+	return f.text, f.err
+}
+
+type fakeLanguages string
+
+func (f fakeLanguages) UserLanguage(ctx context.Context, userID uuid.UUID) (string, error) {
+	return string(f), nil
+}
+
+type fakeAccounts []repository.AccountRef
+
+func (f fakeAccounts) ListActiveAccounts(ctx context.Context, userID uuid.UUID) ([]repository.AccountRef, error) {
+	return f, nil
+}
+
+func TestCreateTransactionFromAudio(t *testing.T) {
+	nubank := repository.AccountRef{ID: uuid.New(), Name: "Nubank", CurrencyCode: "BRL"}
+	transcriber := &fakeTranscriber{text: "Almoço 35 reais no Nubank #trabalho"}
+	h := NewFinanceHandler(nil, nil, nil).
+		WithLanguageLookup(fakeLanguages("pt-BR")).
+		WithAccounts(fakeAccounts{nubank}).
+		WithTranscriber(transcriber)
+
+	draft, err := h.CreateTransactionFromAudio(context.Background(), uuid.New(), []byte("OggS"), "audio/ogg")
+	if err != nil {
+		t.Fatalf("CreateTransactionFromAudio failed: %v", err)
+	}
+	if transcriber.language != "pt-BR" {
+		t.Errorf("language = %q, want the user's", transcriber.language)
+	}
+	if draft.Transcript != transcriber.text || draft.Description != "Almoço" || draft.AmountMinor != -3500 || draft.Currency != "BRL" {
+		t.Errorf("unexpected draft %+v", draft)
+	}
+	if draft.AccountID == nil || *draft.AccountID != nubank.ID {
+		t.Errorf("account = %v, want %v", draft.AccountID, nubank.ID)
+	}
+	if len(draft.Tags) != 1 || draft.Tags[0] != "trabalho" {
+		t.Errorf("tags = %v, want [trabalho]", draft.Tags)
+	}
+}
+
+func TestCreateTransactionFromAudio_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		transcriber speech.Transcriber
+		want        connect.Code
+	}{
+		{"not configured", nil, connect.CodeUnimplemented},
+		{"empty clip", &fakeTranscriber{err: speech.ErrEmptyAudio}, connect.CodeInvalidArgument},
+		{"silence", &fakeTranscriber{err: speech.ErrNoSpeech}, connect.CodeFailedPrecondition},
+		{"provider down", &fakeTranscriber{err: errors.New("connection refused")}, connect.CodeUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewFinanceHandler(nil, nil, nil)
+			if tt.transcriber != nil {
+				h.WithTranscriber(tt.transcriber)
+			}
+			_, err := h.CreateTransactionFromAudio(context.Background(), uuid.New(), nil, "audio/ogg")
+			if code := connect.CodeOf(err); code != tt.want {
+				t.Errorf("code = %v, want %v (%v)", code, tt.want, err)
+			}
+		})
+	}
+}
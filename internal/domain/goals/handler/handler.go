@@ -67,7 +67,7 @@ func (h *GoalsHandler) GetGoal(
 	ctx context.Context,
 	req *connect.Request[echov1.GetGoalRequest],
 ) (*connect.Response[echov1.GetGoalResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +77,7 @@ func (h *GoalsHandler) GetGoal(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	goal, err := h.svc.GetGoal(ctx, goalID)
+	goal, err := h.svc.GetGoal(ctx, userID, goalID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -86,7 +86,7 @@ func (h *GoalsHandler) GetGoal(
 	}
 
 	// Get progress to populate computed fields
-	progress, err := h.svc.GetGoalProgress(ctx, goalID)
+	progress, err := h.svc.GetGoalProgress(ctx, userID, goalID)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
@@ -101,7 +101,7 @@ func (h *GoalsHandler) UpdateGoal(
 	ctx context.Context,
 	req *connect.Request[echov1.UpdateGoalRequest],
 ) (*connect.Response[echov1.UpdateGoalResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +133,7 @@ func (h *GoalsHandler) UpdateGoal(
 		status = &s
 	}
 
-	goal, err := h.svc.UpdateGoal(ctx, goalID, name, targetMinor, endAt, status)
+	goal, err := h.svc.UpdateGoal(ctx, userID, goalID, name, targetMinor, endAt, status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -151,7 +151,7 @@ func (h *GoalsHandler) DeleteGoal(
 	ctx context.Context,
 	req *connect.Request[echov1.DeleteGoalRequest],
 ) (*connect.Response[echov1.DeleteGoalResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +161,7 @@ func (h *GoalsHandler) DeleteGoal(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	if err := h.svc.DeleteGoal(ctx, goalID); err != nil {
+	if err := h.svc.DeleteGoal(ctx, userID, goalID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
 		}
@@ -187,15 +187,14 @@ func (h *GoalsHandler) ListGoals(
 		statusFilter = &s
 	}
 
-	goals, err := h.svc.ListGoals(ctx, userID, statusFilter)
+	goals, err := h.svc.ListGoalProgress(ctx, userID, statusFilter)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
 	protoGoals := make([]*echov1.Goal, 0, len(goals))
-	for _, goal := range goals {
-		progress, _ := h.svc.GetGoalProgress(ctx, goal.ID)
-		protoGoals = append(protoGoals, goalWithProgressToProto(goal, progress))
+	for _, progress := range goals {
+		protoGoals = append(protoGoals, goalWithProgressToProto(progress.Goal, progress))
 	}
 
 	return connect.NewResponse(&echov1.ListGoalsResponse{
@@ -208,7 +207,7 @@ func (h *GoalsHandler) GetGoalProgress(
 	ctx context.Context,
 	req *connect.Request[echov1.GetGoalProgressRequest],
 ) (*connect.Response[echov1.GetGoalProgressResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +217,7 @@ func (h *GoalsHandler) GetGoalProgress(
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid goal ID"))
 	}
 
-	progress, err := h.svc.GetGoalProgress(ctx, goalID)
+	progress, err := h.svc.GetGoalProgress(ctx, userID, goalID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -271,7 +270,7 @@ func (h *GoalsHandler) ContributeToGoal(
 	ctx context.Context,
 	req *connect.Request[echov1.ContributeToGoalRequest],
 ) (*connect.Response[echov1.ContributeToGoalResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -291,7 +290,7 @@ func (h *GoalsHandler) ContributeToGoal(
 		note = req.Msg.Note
 	}
 
-	progress, milestone, err := h.svc.ContributeToGoal(ctx, goalID, req.Msg.Amount.AmountMinor, currency, note)
+	progress, milestone, err := h.svc.ContributeToGoal(ctx, userID, goalID, req.Msg.Amount.AmountMinor, currency, note)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("goal not found"))
@@ -437,3 +436,35 @@ func protoToGoalStatus(s echov1.GoalStatus) repository.GoalStatus {
 		return repository.GoalStatusActive
 	}
 }
+
+// ============================================================================
+// Goal Allocation (Internal Integration)
+// ============================================================================
+// Goals can be grouped as short/medium/long term and ranked, and the monthly
+// surplus allocated across them by priority and end date. The following methods
+// are available on the goals service but require proto definitions to be
+// exposed as API endpoints:
+//
+// - GetGoalAllocationPlan: suggested monthly contribution per goal, by term
+// - SetGoalPriorities: rank goals in funding order
+// - SetGoalTerm: override a goal's derived term
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetGoalAllocationPlanRequest/Response, GoalAllocation, GoalAllocationGroup
+// - SetGoalPrioritiesRequest/Response
+// - GoalTerm enum and term/priority fields on Goal
+
+// ============================================================================
+// Historical Contributions Import (Internal Integration)
+// ============================================================================
+// Past contributions to a goal can be imported in bulk from a CSV with date,
+// amount and optional note columns, recomputing milestones from the full
+// history. The following method is available on the goals service but
+// requires proto definitions to be exposed as an API endpoint:
+//
+// - ImportGoalContributions: import a CSV of past contributions into a goal
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ImportGoalContributionsRequest/Response (FinanceService.ImportGoalContributions),
+//   with goal_id and csv_data, returning imported, failed, errors, total_minor,
+//   milestones_reached and the goal's progress
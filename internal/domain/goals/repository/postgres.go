@@ -25,8 +25,8 @@ func NewPostgresGoalRepository(pool *pgxpool.Pool) *PostgresGoalRepository {
 // Create inserts a new goal
 func (r *PostgresGoalRepository) Create(ctx context.Context, goal *Goal) error {
 	query := `
-		INSERT INTO goals (id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO goals (id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at, term, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at, updated_at`
 
 	if goal.ID == uuid.Nil {
@@ -44,6 +44,8 @@ func (r *PostgresGoalRepository) Create(ctx context.Context, goal *Goal) error {
 		goal.CurrentAmountMinor,
 		goal.StartAt,
 		goal.EndAt,
+		goal.Term,
+		goal.Priority,
 	).Scan(&goal.CreatedAt, &goal.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create goal: %w", err)
@@ -51,15 +53,15 @@ func (r *PostgresGoalRepository) Create(ctx context.Context, goal *Goal) error {
 	return nil
 }
 
-// GetByID retrieves a goal by ID
-func (r *PostgresGoalRepository) GetByID(ctx context.Context, id uuid.UUID) (*Goal, error) {
+// GetByID retrieves one of the user's goals by ID
+func (r *PostgresGoalRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*Goal, error) {
 	query := `
-		SELECT id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at, created_at, updated_at
+		SELECT id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at, term, priority, created_at, updated_at
 		FROM goals
-		WHERE id = $1`
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	goal := &Goal{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
 		&goal.ID,
 		&goal.UserID,
 		&goal.Name,
@@ -70,6 +72,8 @@ func (r *PostgresGoalRepository) GetByID(ctx context.Context, id uuid.UUID) (*Go
 		&goal.CurrentAmountMinor,
 		&goal.StartAt,
 		&goal.EndAt,
+		&goal.Term,
+		&goal.Priority,
 		&goal.CreatedAt,
 		&goal.UpdatedAt,
 	)
@@ -87,8 +91,9 @@ func (r *PostgresGoalRepository) GetByID(ctx context.Context, id uuid.UUID) (*Go
 func (r *PostgresGoalRepository) Update(ctx context.Context, goal *Goal) error {
 	query := `
 		UPDATE goals
-		SET name = $2, type = $3, status = $4, target_amount_minor = $5, current_amount_minor = $6, end_at = $7
-		WHERE id = $1
+		SET name = $2, type = $3, status = $4, target_amount_minor = $5, current_amount_minor = $6, end_at = $7,
+		    term = $8, priority = $9
+		WHERE id = $1 AND user_id = $10 AND deleted_at IS NULL
 		RETURNING updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
@@ -99,6 +104,9 @@ func (r *PostgresGoalRepository) Update(ctx context.Context, goal *Goal) error {
 		goal.TargetAmountMinor,
 		goal.CurrentAmountMinor,
 		goal.EndAt,
+		goal.Term,
+		goal.Priority,
+		goal.UserID,
 	).Scan(&goal.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -110,10 +118,11 @@ func (r *PostgresGoalRepository) Update(ctx context.Context, goal *Goal) error {
 	return nil
 }
 
-// Delete removes a goal
-func (r *PostgresGoalRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM goals WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, id)
+// Delete moves a goal to the trash; it and its contributions are removed for
+// good when the trash is purged
+func (r *PostgresGoalRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	query := `UPDATE goals SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	result, err := r.pool.Exec(ctx, query, id, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete goal: %w", err)
 	}
@@ -126,16 +135,16 @@ func (r *PostgresGoalRepository) Delete(ctx context.Context, id uuid.UUID) error
 // ListByUserID retrieves all goals for a user
 func (r *PostgresGoalRepository) ListByUserID(ctx context.Context, userID uuid.UUID, statusFilter *GoalStatus) ([]*Goal, error) {
 	query := `
-		SELECT id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at, created_at, updated_at
+		SELECT id, user_id, name, type, status, target_amount_minor, currency_code, current_amount_minor, start_at, end_at, term, priority, created_at, updated_at
 		FROM goals
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND deleted_at IS NULL`
 
 	args := []interface{}{userID}
 	if statusFilter != nil {
 		query += ` AND status = $2`
 		args = append(args, *statusFilter)
 	}
-	query += ` ORDER BY end_at ASC`
+	query += ` ORDER BY priority = 0, priority, end_at ASC`
 
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -157,6 +166,8 @@ func (r *PostgresGoalRepository) ListByUserID(ctx context.Context, userID uuid.U
 			&goal.CurrentAmountMinor,
 			&goal.StartAt,
 			&goal.EndAt,
+			&goal.Term,
+			&goal.Priority,
 			&goal.CreatedAt,
 			&goal.UpdatedAt,
 		)
@@ -168,8 +179,84 @@ func (r *PostgresGoalRepository) ListByUserID(ctx context.Context, userID uuid.U
 	return goals, nil
 }
 
-// AddContribution adds a contribution to a goal
-func (r *PostgresGoalRepository) AddContribution(ctx context.Context, contribution *GoalContribution) error {
+// ListWithContributions lists the user's goals, each with up to
+// contributionLimit recent contributions. Goals come back once per
+// contribution from a lateral join and are grouped here.
+func (r *PostgresGoalRepository) ListWithContributions(ctx context.Context, userID uuid.UUID, statusFilter *GoalStatus, contributionLimit int) ([]*GoalWithContributions, error) {
+	query := `
+		SELECT g.id, g.user_id, g.name, g.type, g.status, g.target_amount_minor, g.currency_code, g.current_amount_minor,
+		       g.start_at, g.end_at, g.term, g.priority, g.created_at, g.updated_at,
+		       c.id, c.amount_minor, c.currency_code, c.note, c.transaction_id, c.contributed_at, c.created_at
+		FROM goals g
+		LEFT JOIN LATERAL (
+			SELECT id, amount_minor, currency_code, note, transaction_id, contributed_at, created_at
+			FROM goal_contributions
+			WHERE goal_id = g.id
+			ORDER BY contributed_at DESC
+			LIMIT $2
+		) c ON TRUE
+		WHERE g.user_id = $1 AND g.deleted_at IS NULL`
+
+	args := []interface{}{userID, contributionLimit}
+	if statusFilter != nil {
+		query += ` AND g.status = $3`
+		args = append(args, *statusFilter)
+	}
+	query += ` ORDER BY g.priority = 0, g.priority, g.end_at ASC, g.id, c.contributed_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals with contributions: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []*GoalWithContributions
+	for rows.Next() {
+		goal := &Goal{}
+		var (
+			contributionID *uuid.UUID
+			amountMinor    *int64
+			currencyCode   *string
+			note           *string
+			transactionID  *uuid.UUID
+			contributedAt  *time.Time
+			createdAt      *time.Time
+		)
+		err := rows.Scan(
+			&goal.ID, &goal.UserID, &goal.Name, &goal.Type, &goal.Status, &goal.TargetAmountMinor,
+			&goal.CurrencyCode, &goal.CurrentAmountMinor, &goal.StartAt, &goal.EndAt, &goal.Term,
+			&goal.Priority, &goal.CreatedAt, &goal.UpdatedAt,
+			&contributionID, &amountMinor, &currencyCode, &note, &transactionID, &contributedAt, &createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan goal with contribution: %w", err)
+		}
+
+		if n := len(goals); n == 0 || goals[n-1].Goal.ID != goal.ID {
+			goals = append(goals, &GoalWithContributions{Goal: goal})
+		}
+		if contributionID != nil {
+			last := goals[len(goals)-1]
+			last.Contributions = append(last.Contributions, &GoalContribution{
+				ID:            *contributionID,
+				GoalID:        goal.ID,
+				AmountMinor:   *amountMinor,
+				CurrencyCode:  *currencyCode,
+				Note:          note,
+				TransactionID: transactionID,
+				ContributedAt: *contributedAt,
+				CreatedAt:     *createdAt,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list goals with contributions: %w", err)
+	}
+	return goals, nil
+}
+
+// AddContribution adds a contribution to one of the user's goals
+func (r *PostgresGoalRepository) AddContribution(ctx context.Context, userID uuid.UUID, contribution *GoalContribution) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -206,25 +293,124 @@ func (r *PostgresGoalRepository) AddContribution(ctx context.Context, contributi
 	updateQuery := `
 		UPDATE goals
 		SET current_amount_minor = current_amount_minor + $2
-		WHERE id = $1`
-	_, err = tx.Exec(ctx, updateQuery, contribution.GoalID, contribution.AmountMinor)
+		WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL`
+	result, err := tx.Exec(ctx, updateQuery, contribution.GoalID, contribution.AmountMinor, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update goal amount: %w", err)
 	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
 
 	return tx.Commit(ctx)
 }
 
-// ListContributions retrieves recent contributions for a goal
-func (r *PostgresGoalRepository) ListContributions(ctx context.Context, goalID uuid.UUID, limit int) ([]*GoalContribution, error) {
+// ListContributions retrieves recent contributions for one of the user's goals
+func (r *PostgresGoalRepository) ListContributions(ctx context.Context, userID, goalID uuid.UUID, limit int) ([]*GoalContribution, error) {
 	query := `
-		SELECT id, goal_id, amount_minor, currency_code, note, transaction_id, contributed_at, created_at
-		FROM goal_contributions
-		WHERE goal_id = $1
-		ORDER BY contributed_at DESC
+		SELECT c.id, c.goal_id, c.amount_minor, c.currency_code, c.note, c.transaction_id, c.contributed_at, c.created_at
+		FROM goal_contributions c
+		JOIN goals g ON g.id = c.goal_id AND g.user_id = $3
+		WHERE c.goal_id = $1
+		ORDER BY c.contributed_at DESC
 		LIMIT $2`
 
-	rows, err := r.pool.Query(ctx, query, goalID, limit)
+	rows, err := r.pool.Query(ctx, query, goalID, limit, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contributions: %w", err)
+	}
+	defer rows.Close()
+
+	var contributions []*GoalContribution
+	for rows.Next() {
+		c := &GoalContribution{}
+		err := rows.Scan(
+			&c.ID,
+			&c.GoalID,
+			&c.AmountMinor,
+			&c.CurrencyCode,
+			&c.Note,
+			&c.TransactionID,
+			&c.ContributedAt,
+			&c.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contribution: %w", err)
+		}
+		contributions = append(contributions, c)
+	}
+	return contributions, nil
+}
+
+// BulkAddContributions inserts historical contributions in a single transaction
+// and increments the goal's current amount by their total
+func (r *PostgresGoalRepository) BulkAddContributions(ctx context.Context, userID, goalID uuid.UUID, contributions []*GoalContribution) error {
+	if len(contributions) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	insertQuery := `
+		INSERT INTO goal_contributions (id, goal_id, amount_minor, currency_code, note, transaction_id, contributed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`
+
+	var total int64
+	for _, c := range contributions {
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+		if c.ContributedAt.IsZero() {
+			c.ContributedAt = time.Now()
+		}
+		c.GoalID = goalID
+
+		err = tx.QueryRow(ctx, insertQuery,
+			c.ID,
+			c.GoalID,
+			c.AmountMinor,
+			c.CurrencyCode,
+			c.Note,
+			c.TransactionID,
+			c.ContributedAt,
+		).Scan(&c.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert contribution: %w", err)
+		}
+		total += c.AmountMinor
+	}
+
+	updateQuery := `
+		UPDATE goals
+		SET current_amount_minor = current_amount_minor + $2
+		WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL`
+	result, err := tx.Exec(ctx, updateQuery, goalID, total, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update goal amount: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListAllContributions retrieves every contribution for one of the user's goals
+// in chronological order
+func (r *PostgresGoalRepository) ListAllContributions(ctx context.Context, userID, goalID uuid.UUID) ([]*GoalContribution, error) {
+	query := `
+		SELECT c.id, c.goal_id, c.amount_minor, c.currency_code, c.note, c.transaction_id, c.contributed_at, c.created_at
+		FROM goal_contributions c
+		JOIN goals g ON g.id = c.goal_id AND g.user_id = $2
+		WHERE c.goal_id = $1
+		ORDER BY c.contributed_at ASC, c.created_at ASC`
+
+	rows, err := r.pool.Query(ctx, query, goalID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list contributions: %w", err)
 	}
@@ -252,9 +438,9 @@ func (r *PostgresGoalRepository) ListContributions(ctx context.Context, goalID u
 }
 
 // UpdateCurrentAmount directly sets the current amount for a goal
-func (r *PostgresGoalRepository) UpdateCurrentAmount(ctx context.Context, goalID uuid.UUID, amountMinor int64) error {
-	query := `UPDATE goals SET current_amount_minor = $2 WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, goalID, amountMinor)
+func (r *PostgresGoalRepository) UpdateCurrentAmount(ctx context.Context, userID, goalID uuid.UUID, amountMinor int64) error {
+	query := `UPDATE goals SET current_amount_minor = $2 WHERE id = $1 AND user_id = $3 AND deleted_at IS NULL`
+	result, err := r.pool.Exec(ctx, query, goalID, amountMinor, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update current amount: %w", err)
 	}
@@ -263,3 +449,70 @@ func (r *PostgresGoalRepository) UpdateCurrentAmount(ctx context.Context, goalID
 	}
 	return nil
 }
+
+// SetPriorities ranks the given goals 1..n in a single transaction and unranks
+// the user's other goals
+func (r *PostgresGoalRepository) SetPriorities(ctx context.Context, userID uuid.UUID, goalIDs []uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `UPDATE goals SET priority = 0 WHERE user_id = $1 AND priority <> 0 AND deleted_at IS NULL`, userID); err != nil {
+		return fmt.Errorf("failed to reset goal priorities: %w", err)
+	}
+
+	if len(goalIDs) > 0 {
+		result, err := tx.Exec(ctx, `
+			UPDATE goals g
+			SET priority = ranked.position
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS ranked(id, position)
+			WHERE g.id = ranked.id AND g.user_id = $1 AND g.deleted_at IS NULL`,
+			userID, goalIDs,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to set goal priorities: %w", err)
+		}
+		if result.RowsAffected() != int64(len(goalIDs)) {
+			return sql.ErrNoRows
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit goal priorities: %w", err)
+	}
+	return nil
+}
+
+// GetMonthlySurplus sums each month's transactions; income is positive and
+// spending negative, so the sum is the month's surplus. Months without
+// transactions are included as zero.
+func (r *PostgresGoalRepository) GetMonthlySurplus(ctx context.Context, userID uuid.UUID, since, until time.Time) ([]int64, error) {
+	query := `
+		SELECT COALESCE(SUM(t.amount_minor), 0)
+		FROM generate_series(date_trunc('month', $2::timestamptz), $3::timestamptz - INTERVAL '1 month', INTERVAL '1 month') AS m(start)
+		LEFT JOIN transactions t
+		       ON t.user_id = $1
+		      AND t.deleted_at IS NULL
+		      AND t.posted_at >= m.start
+		      AND t.posted_at < m.start + INTERVAL '1 month'
+		GROUP BY m.start
+		ORDER BY m.start`
+
+	rows, err := r.pool.Query(ctx, query, userID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly surplus: %w", err)
+	}
+	defer rows.Close()
+
+	var surpluses []int64
+	for rows.Next() {
+		var surplus int64
+		if err := rows.Scan(&surplus); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly surplus: %w", err)
+		}
+		surpluses = append(surpluses, surplus)
+	}
+	return surpluses, rows.Err()
+}
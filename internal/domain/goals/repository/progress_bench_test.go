@@ -0,0 +1,116 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Compare loading goals for ListGoals with a query per goal and with one
+// query, against a migrated database:
+//
+//	TEST_DATABASE_URL=... go test -tags integration -run '^$' -bench GoalProgress ./internal/domain/goals/repository/
+
+const (
+	benchGoals         = 20
+	benchContributions = 30
+	benchRecent        = 10
+)
+
+// benchGoalRepository creates a user with goals and contributions, removed
+// once the benchmark is done
+func benchGoalRepository(b *testing.B) (*PostgresGoalRepository, uuid.UUID) {
+	b.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		b.Fatalf("failed to connect: %v", err)
+	}
+	b.Cleanup(pool.Close)
+
+	var userID uuid.UUID
+	err = pool.QueryRow(ctx, `INSERT INTO users (email) VALUES ($1) RETURNING id`,
+		fmt.Sprintf("bench-%s@example.com", uuid.NewString())).Scan(&userID)
+	if err != nil {
+		b.Fatalf("failed to create user: %v", err)
+	}
+	b.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+
+	repo := NewPostgresGoalRepository(pool)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < benchGoals; i++ {
+		goal := &Goal{
+			ID:                uuid.New(),
+			UserID:            userID,
+			Name:              fmt.Sprintf("Goal %d", i),
+			Type:              GoalTypeSave,
+			Status:            GoalStatusActive,
+			TargetAmountMinor: 1_000_000,
+			CurrencyCode:      "EUR",
+			StartAt:           start,
+			EndAt:             start.AddDate(2, 0, 0),
+		}
+		if err := repo.Create(ctx, goal); err != nil {
+			b.Fatalf("failed to create goal: %v", err)
+		}
+		contributions := make([]*GoalContribution, benchContributions)
+		for j := range contributions {
+			contributions[j] = &GoalContribution{
+				ID:            uuid.New(),
+				GoalID:        goal.ID,
+				AmountMinor:   1_000,
+				CurrencyCode:  "EUR",
+				ContributedAt: start.AddDate(0, 0, j),
+			}
+		}
+		if err := repo.BulkAddContributions(ctx, userID, goal.ID, contributions); err != nil {
+			b.Fatalf("failed to add contributions: %v", err)
+		}
+	}
+	return repo, userID
+}
+
+func BenchmarkGoalProgress(b *testing.B) {
+	repo, userID := benchGoalRepository(b)
+	ctx := context.Background()
+
+	b.Run("per_goal", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			goals, err := repo.ListByUserID(ctx, userID, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, goal := range goals {
+				if _, err := repo.GetByID(ctx, userID, goal.ID); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := repo.ListContributions(ctx, userID, goal.ID, benchRecent); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			goals, err := repo.ListWithContributions(ctx, userID, nil, benchRecent)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(goals) != benchGoals || len(goals[0].Contributions) != benchRecent {
+				b.Fatalf("got %d goals", len(goals))
+			}
+		}
+	})
+}
@@ -27,6 +27,15 @@ const (
 	GoalStatusArchived  GoalStatus = "archived"
 )
 
+// GoalTerm groups goals by horizon
+type GoalTerm string
+
+const (
+	GoalTermShort  GoalTerm = "short_term"  // Due within a year
+	GoalTermMedium GoalTerm = "medium_term" // Due within five years
+	GoalTermLong   GoalTerm = "long_term"
+)
+
 // Goal represents a financial goal
 type Goal struct {
 	ID                 uuid.UUID
@@ -39,6 +48,8 @@ type Goal struct {
 	CurrentAmountMinor int64
 	StartAt            time.Time
 	EndAt              time.Time
+	Term               *GoalTerm // nil = derived from EndAt
+	Priority           int       // 1 is funded first; 0 = unranked, funded after ranked goals
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 }
@@ -55,19 +66,40 @@ type GoalContribution struct {
 	CreatedAt     time.Time
 }
 
+// GoalWithContributions is a goal with its most recent contributions, newest
+// first
+type GoalWithContributions struct {
+	Goal          *Goal
+	Contributions []*GoalContribution
+}
+
 // GoalRepository defines the interface for goal persistence operations
 type GoalRepository interface {
-	// CRUD operations
+	// CRUD operations. Lookups by ID are scoped to the owning user and return
+	// sql.ErrNoRows for another user's goal.
 	Create(ctx context.Context, goal *Goal) error
-	GetByID(ctx context.Context, id uuid.UUID) (*Goal, error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*Goal, error)
 	Update(ctx context.Context, goal *Goal) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
 	ListByUserID(ctx context.Context, userID uuid.UUID, statusFilter *GoalStatus) ([]*Goal, error)
+	// ListWithContributions lists the user's goals like ListByUserID, each with
+	// up to contributionLimit recent contributions, in one query
+	ListWithContributions(ctx context.Context, userID uuid.UUID, statusFilter *GoalStatus, contributionLimit int) ([]*GoalWithContributions, error)
 
 	// Contribution operations
-	AddContribution(ctx context.Context, contribution *GoalContribution) error
-	ListContributions(ctx context.Context, goalID uuid.UUID, limit int) ([]*GoalContribution, error)
+	AddContribution(ctx context.Context, userID uuid.UUID, contribution *GoalContribution) error
+	ListContributions(ctx context.Context, userID, goalID uuid.UUID, limit int) ([]*GoalContribution, error)
+	BulkAddContributions(ctx context.Context, userID, goalID uuid.UUID, contributions []*GoalContribution) error
+	ListAllContributions(ctx context.Context, userID, goalID uuid.UUID) ([]*GoalContribution, error)
 
 	// Progress operations
-	UpdateCurrentAmount(ctx context.Context, goalID uuid.UUID, amountMinor int64) error
+	UpdateCurrentAmount(ctx context.Context, userID, goalID uuid.UUID, amountMinor int64) error
+
+	// Prioritization
+	// SetPriorities ranks the user's goals in the given order (first = 1) and
+	// unranks the rest. Returns sql.ErrNoRows if a goal isn't the user's.
+	SetPriorities(ctx context.Context, userID uuid.UUID, goalIDs []uuid.UUID) error
+	// GetMonthlySurplus returns the user's net cash flow (income minus spending)
+	// for each full month in [since, until)
+	GetMonthlySurplus(ctx context.Context, userID uuid.UUID, since, until time.Time) ([]int64, error)
 }
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+)
+
+// surplusLookbackMonths is how many full months estimate the surplus when none is given
+const surplusLookbackMonths = 3
+
+// ErrGoalNotFound is returned when a goal doesn't exist or isn't the user's
+var ErrGoalNotFound = errors.New("goal not found")
+
+// goalTermOrder ranks terms for allocation: nearer horizons are funded first
+var goalTermOrder = map[repository.GoalTerm]int{
+	repository.GoalTermShort:  0,
+	repository.GoalTermMedium: 1,
+	repository.GoalTermLong:   2,
+}
+
+// GoalAllocation is the share of the monthly surplus suggested for one goal
+type GoalAllocation struct {
+	Goal                 *repository.Goal
+	Term                 repository.GoalTerm
+	RequiredMonthlyMinor int64 // Needed per month to reach the target by the end date
+	AllocatedMinor       int64 // Suggested contribution this month
+	ShortfallMinor       int64 // Part of the required amount the surplus couldn't cover
+}
+
+// GoalAllocationGroup collects a term's allocations
+type GoalAllocationGroup struct {
+	Term           repository.GoalTerm
+	Allocations    []*GoalAllocation
+	AllocatedMinor int64
+}
+
+// GoalAllocationPlan splits a monthly surplus across the user's goals
+type GoalAllocationPlan struct {
+	SurplusMinor     int64
+	SurplusEstimated bool // True when the surplus was averaged from recent months
+	AllocatedMinor   int64
+	UnallocatedMinor int64
+	Allocations      []*GoalAllocation // In funding order
+	Groups           []GoalAllocationGroup
+}
+
+// GetGoalAllocationPlan allocates a monthly surplus across the user's active
+// savings and debt goals. Goals are funded in priority order (ranked goals
+// first, then by term and end date): each first gets what it needs per month to
+// finish on time, then whatever is left speeds up goals in the same order. A nil
+// surplus is estimated from the average of the last full months.
+func (s *Service) GetGoalAllocationPlan(ctx context.Context, userID uuid.UUID, surplusMinor *int64, now time.Time) (*GoalAllocationPlan, error) {
+	estimated := surplusMinor == nil
+	var surplus int64
+	if estimated {
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		months, err := s.repo.GetMonthlySurplus(ctx, userID, monthStart.AddDate(0, -surplusLookbackMonths, 0), monthStart)
+		if err != nil {
+			return nil, err
+		}
+		surplus = averageSurplus(months)
+	} else {
+		surplus = *surplusMinor
+	}
+
+	active := repository.GoalStatusActive
+	goals, err := s.repo.ListByUserID(ctx, userID, &active)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := allocateSurplus(goals, surplus, now)
+	plan.SurplusEstimated = estimated
+	return plan, nil
+}
+
+// SetGoalPriorities ranks the user's goals in the given order; goals left out
+// become unranked
+func (s *Service) SetGoalPriorities(ctx context.Context, userID uuid.UUID, goalIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(goalIDs))
+	for _, id := range goalIDs {
+		if seen[id] {
+			return fmt.Errorf("goal %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+
+	err := s.repo.SetPriorities(ctx, userID, goalIDs)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGoalNotFound
+	}
+	return err
+}
+
+// SetGoalTerm groups a goal as short, medium or long term. A nil term derives
+// it from the goal's end date.
+func (s *Service) SetGoalTerm(ctx context.Context, userID, goalID uuid.UUID, term *repository.GoalTerm) (*repository.Goal, error) {
+	if term != nil {
+		if _, ok := goalTermOrder[*term]; !ok {
+			return nil, fmt.Errorf("invalid goal term %q", *term)
+		}
+	}
+
+	goal, err := s.repo.GetByID(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+	goal.Term = term
+	if err := s.repo.Update(ctx, goal); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, goal.UserID)
+	return goal, nil
+}
+
+// EffectiveTerm returns the goal's term, deriving it from the time left until
+// its end date when not set explicitly
+func EffectiveTerm(goal *repository.Goal, now time.Time) repository.GoalTerm {
+	if goal.Term != nil {
+		return *goal.Term
+	}
+	switch {
+	case !goal.EndAt.After(now.AddDate(1, 0, 0)):
+		return repository.GoalTermShort
+	case !goal.EndAt.After(now.AddDate(5, 0, 0)):
+		return repository.GoalTermMedium
+	default:
+		return repository.GoalTermLong
+	}
+}
+
+// allocateSurplus splits the surplus across fundable goals in priority order
+func allocateSurplus(goals []*repository.Goal, surplus int64, now time.Time) *GoalAllocationPlan {
+	plan := &GoalAllocationPlan{SurplusMinor: surplus}
+
+	for _, g := range goals {
+		if g.Status != repository.GoalStatusActive || g.Type == repository.GoalTypeSpendCap || g.CurrentAmountMinor >= g.TargetAmountMinor {
+			continue
+		}
+		plan.Allocations = append(plan.Allocations, &GoalAllocation{
+			Goal:                 g,
+			Term:                 EffectiveTerm(g, now),
+			RequiredMonthlyMinor: requiredMonthly(g, now),
+		})
+	}
+
+	sort.SliceStable(plan.Allocations, func(i, j int) bool {
+		a, b := plan.Allocations[i], plan.Allocations[j]
+		if ra, rb := a.Goal.Priority == 0, b.Goal.Priority == 0; ra != rb {
+			return rb // Ranked goals before unranked
+		}
+		if a.Goal.Priority != b.Goal.Priority {
+			return a.Goal.Priority < b.Goal.Priority
+		}
+		if goalTermOrder[a.Term] != goalTermOrder[b.Term] {
+			return goalTermOrder[a.Term] < goalTermOrder[b.Term]
+		}
+		return a.Goal.EndAt.Before(b.Goal.EndAt)
+	})
+
+	remaining := max(surplus, 0)
+
+	// First cover what each goal needs to stay on schedule
+	for _, a := range plan.Allocations {
+		a.AllocatedMinor = min(a.RequiredMonthlyMinor, remaining)
+		a.ShortfallMinor = a.RequiredMonthlyMinor - a.AllocatedMinor
+		remaining -= a.AllocatedMinor
+	}
+	// Then put the rest towards finishing goals early, in the same order
+	for _, a := range plan.Allocations {
+		if remaining == 0 {
+			break
+		}
+		extra := min(a.Goal.TargetAmountMinor-a.Goal.CurrentAmountMinor-a.AllocatedMinor, remaining)
+		a.AllocatedMinor += extra
+		remaining -= extra
+	}
+
+	groups := make(map[repository.GoalTerm]*GoalAllocationGroup)
+	for _, a := range plan.Allocations {
+		plan.AllocatedMinor += a.AllocatedMinor
+		group, ok := groups[a.Term]
+		if !ok {
+			group = &GoalAllocationGroup{Term: a.Term}
+			groups[a.Term] = group
+		}
+		group.Allocations = append(group.Allocations, a)
+		group.AllocatedMinor += a.AllocatedMinor
+	}
+	for _, term := range []repository.GoalTerm{repository.GoalTermShort, repository.GoalTermMedium, repository.GoalTermLong} {
+		if group, ok := groups[term]; ok {
+			plan.Groups = append(plan.Groups, *group)
+		}
+	}
+	plan.UnallocatedMinor = max(surplus, 0) - plan.AllocatedMinor
+	return plan
+}
+
+// requiredMonthly is the remaining amount spread over the months left (a partial
+// month counts as one); overdue goals need the whole remainder now
+func requiredMonthly(goal *repository.Goal, now time.Time) int64 {
+	remaining := goal.TargetAmountMinor - goal.CurrentAmountMinor
+	monthsLeft := (goal.EndAt.Year()-now.Year())*12 + int(goal.EndAt.Month()-now.Month())
+	if now.AddDate(0, monthsLeft, 0).Before(goal.EndAt) {
+		monthsLeft++
+	}
+	if monthsLeft < 1 {
+		return remaining
+	}
+	return int64(math.Ceil(float64(remaining) / float64(monthsLeft)))
+}
+
+// averageSurplus averages monthly surpluses, treating a net deficit as no surplus
+func averageSurplus(months []int64) int64 {
+	if len(months) == 0 {
+		return 0
+	}
+	var total int64
+	for _, m := range months {
+		total += m
+	}
+	return max(total/int64(len(months)), 0)
+}
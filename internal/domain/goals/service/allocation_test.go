@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+)
+
+func TestAllocateSurplus_PriorityThenTermThenEndDate(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	goal := func(name string, target, current int64, months, priority int) *repository.Goal {
+		return &repository.Goal{
+			ID:                 uuid.New(),
+			Name:               name,
+			Type:               repository.GoalTypeSave,
+			Status:             repository.GoalStatusActive,
+			TargetAmountMinor:  target,
+			CurrentAmountMinor: current,
+			EndAt:              now.AddDate(0, months, 0),
+			Priority:           priority,
+		}
+	}
+	house := goal("House", 6_000_000, 0, 120, 1) // Ranked first despite its long term
+	emergency := goal("Emergency fund", 300_000, 0, 6, 0)
+	holiday := goal("Holiday", 120_000, 0, 12, 0)
+	done := goal("Laptop", 100_000, 100_000, 3, 0)
+	capGoal := goal("Eating out", 30_000, 0, 1, 0)
+	capGoal.Type = repository.GoalTypeSpendCap
+
+	plan := allocateSurplus([]*repository.Goal{holiday, done, emergency, capGoal, house}, 100_000, now)
+
+	require.Len(t, plan.Allocations, 3, "completed and spend cap goals aren't funded")
+	assert.Equal(t, []string{"House", "Emergency fund", "Holiday"},
+		[]string{plan.Allocations[0].Goal.Name, plan.Allocations[1].Goal.Name, plan.Allocations[2].Goal.Name})
+
+	// House and the emergency fund each need 50000/month; nothing is left for
+	// the holiday
+	assert.Equal(t, int64(50_000), plan.Allocations[0].RequiredMonthlyMinor)
+	assert.Equal(t, int64(50_000), plan.Allocations[0].AllocatedMinor)
+	assert.Equal(t, int64(50_000), plan.Allocations[1].AllocatedMinor)
+	assert.Equal(t, int64(0), plan.Allocations[2].AllocatedMinor)
+	assert.Equal(t, int64(10_000), plan.Allocations[2].ShortfallMinor)
+	assert.Equal(t, int64(0), plan.UnallocatedMinor)
+
+	require.Len(t, plan.Groups, 2)
+	assert.Equal(t, repository.GoalTermShort, plan.Groups[0].Term)
+	assert.Equal(t, int64(50_000), plan.Groups[0].AllocatedMinor)
+	assert.Equal(t, repository.GoalTermLong, plan.Groups[1].Term)
+}
+
+func TestAllocateSurplus_LeftoverSpeedsUpGoals(t *testing.T) {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	goal := &repository.Goal{
+		Type:              repository.GoalTypeSave,
+		Status:            repository.GoalStatusActive,
+		TargetAmountMinor: 120_000,
+		EndAt:             now.AddDate(1, 0, 0),
+	}
+
+	plan := allocateSurplus([]*repository.Goal{goal}, 200_000, now)
+
+	require.Len(t, plan.Allocations, 1)
+	assert.Equal(t, int64(120_000), plan.Allocations[0].AllocatedMinor, "capped at the remaining amount")
+	assert.Equal(t, int64(80_000), plan.UnallocatedMinor)
+	assert.Equal(t, int64(0), averageSurplus([]int64{-50_000, 20_000}))
+}
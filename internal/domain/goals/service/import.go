@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+// maxImportedContributions caps the number of rows accepted in a single import
+const maxImportedContributions = 5000
+
+// contributionDateLayouts are the date formats accepted in contribution CSVs
+var contributionDateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"2006/01/02",
+	time.RFC3339,
+}
+
+// ContributionImportResult summarizes a historical contributions import
+type ContributionImportResult struct {
+	Imported          int
+	Failed            int
+	Errors            []string
+	TotalMinor        int64
+	MilestonesReached []MilestoneReached // Milestones crossed by the imported history
+	Progress          *GoalProgress
+}
+
+// ImportGoalContributions imports past contributions from a CSV with
+// date, amount and (optional) note columns. A header row is detected and skipped.
+// Milestones are recomputed from the full contribution history afterwards.
+func (s *Service) ImportGoalContributions(ctx context.Context, userID, goalID uuid.UUID, csvData []byte) (*ContributionImportResult, error) {
+	goal, err := s.repo.GetByID(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	contributions, rowErrors, err := parseContributionsCSV(csvData, goal.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(contributions) == 0 {
+		if len(rowErrors) > 0 {
+			return nil, fmt.Errorf("no valid contributions found: %s", rowErrors[0])
+		}
+		return nil, errors.New("no contributions found in file")
+	}
+
+	result := &ContributionImportResult{
+		Failed: len(rowErrors),
+		Errors: rowErrors,
+	}
+	for _, c := range contributions {
+		result.TotalMinor += c.AmountMinor
+	}
+
+	if err := s.repo.BulkAddContributions(ctx, userID, goalID, contributions); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, goal.UserID)
+	result.Imported = len(contributions)
+
+	previousAmount := goal.CurrentAmountMinor
+	newAmount := previousAmount + result.TotalMinor
+	for _, pct := range []int{25, 50, 75, 100} {
+		threshold := (goal.TargetAmountMinor * int64(pct)) / 100
+		if previousAmount < threshold && newAmount >= threshold {
+			result.MilestonesReached = append(result.MilestonesReached, MilestoneReached{
+				Percent: pct,
+				Message: s.getMilestoneMessage(pct),
+			})
+		}
+	}
+
+	if newAmount >= goal.TargetAmountMinor && goal.Status == repository.GoalStatusActive {
+		status := repository.GoalStatusCompleted
+		if _, err := s.UpdateGoal(ctx, userID, goalID, nil, nil, nil, &status); err != nil {
+			return nil, err
+		}
+	}
+
+	progress, err := s.GetGoalProgress(ctx, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones, err := s.RecomputeMilestones(ctx, progress.Goal)
+	if err != nil {
+		return nil, err
+	}
+	progress.Milestones = milestones
+	result.Progress = progress
+
+	return result, nil
+}
+
+// RecomputeMilestones derives milestone reach dates from the full contribution history.
+// Any amount not backed by contributions (e.g. a manually set balance) counts as
+// reached at the goal's start date.
+func (s *Service) RecomputeMilestones(ctx context.Context, goal *repository.Goal) ([]Milestone, error) {
+	contributions, err := s.repo.ListAllContributions(ctx, goal.UserID, goal.ID)
+	if err != nil {
+		return nil, err
+	}
+	return milestonesFromHistory(goal, contributions), nil
+}
+
+// milestonesFromHistory walks contributions chronologically and records when
+// each milestone threshold was first crossed
+func milestonesFromHistory(goal *repository.Goal, contributions []*repository.GoalContribution) []Milestone {
+	var contributed int64
+	for _, c := range contributions {
+		contributed += c.AmountMinor
+	}
+	baseline := goal.CurrentAmountMinor - contributed
+
+	totalDays := goal.EndAt.Sub(goal.StartAt).Hours() / 24
+	percents := []int{25, 50, 75, 100}
+	milestones := make([]Milestone, len(percents))
+
+	for i, pct := range percents {
+		threshold := (goal.TargetAmountMinor * int64(pct)) / 100
+		expectedDays := (totalDays * float64(pct)) / 100
+		milestones[i] = Milestone{
+			Percent:    pct,
+			ExpectedBy: goal.StartAt.Add(time.Duration(expectedDays*24) * time.Hour),
+		}
+
+		running := baseline
+		if running >= threshold {
+			reachedAt := goal.StartAt
+			milestones[i].Reached = true
+			milestones[i].ReachedAt = &reachedAt
+			continue
+		}
+		for _, c := range contributions {
+			running += c.AmountMinor
+			if running >= threshold {
+				reachedAt := c.ContributedAt
+				milestones[i].Reached = true
+				milestones[i].ReachedAt = &reachedAt
+				break
+			}
+		}
+	}
+	return milestones
+}
+
+// parseContributionsCSV parses date, amount, note rows into contributions.
+// Row-level problems are collected and returned rather than aborting the import.
+func parseContributionsCSV(data []byte, currency string) ([]*repository.GoalContribution, []string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil, errors.New("csv file is empty")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectContributionDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var contributions []*repository.GoalContribution
+	var rowErrors []string
+
+	for rowNum := 1; ; rowNum++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: %v", rowNum, err))
+			continue
+		}
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if len(record) < 2 {
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: expected at least date and amount columns", rowNum))
+			continue
+		}
+
+		contributedAt, err := parseContributionDate(record[0])
+		if err != nil {
+			// A first row that doesn't parse is treated as the header
+			if rowNum == 1 {
+				continue
+			}
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: invalid date %q", rowNum, record[0]))
+			continue
+		}
+
+		amountStr := strings.TrimSpace(record[1])
+		amount, err := money.NewFromString(amountStr, currency, isEuropeanAmount(amountStr))
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: invalid amount %q", rowNum, record[1]))
+			continue
+		}
+		if amount.IsZero() {
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: amount must not be zero", rowNum))
+			continue
+		}
+
+		contribution := &repository.GoalContribution{
+			AmountMinor:   amount.Amount(),
+			CurrencyCode:  currency,
+			ContributedAt: contributedAt,
+		}
+		if len(record) > 2 {
+			if note := strings.TrimSpace(strings.Join(record[2:], " ")); note != "" {
+				contribution.Note = &note
+			}
+		}
+
+		contributions = append(contributions, contribution)
+		if len(contributions) > maxImportedContributions {
+			return nil, nil, fmt.Errorf("too many rows: maximum is %d contributions per import", maxImportedContributions)
+		}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].ContributedAt.Before(contributions[j].ContributedAt)
+	})
+
+	return contributions, rowErrors, nil
+}
+
+// detectContributionDelimiter picks between comma, semicolon and tab based on the first line
+func detectContributionDelimiter(data []byte) rune {
+	firstLine := string(data)
+	if idx := strings.IndexByte(firstLine, '\n'); idx >= 0 {
+		firstLine = firstLine[:idx]
+	}
+
+	best, bestCount := ',', strings.Count(firstLine, ",")
+	for _, d := range []rune{';', '\t'} {
+		if n := strings.Count(firstLine, string(d)); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+// isEuropeanAmount reports whether the amount uses a comma as decimal separator (1.234,56)
+func isEuropeanAmount(s string) bool {
+	lastComma := strings.LastIndex(s, ",")
+	lastDot := strings.LastIndex(s, ".")
+	if lastComma < 0 {
+		return false
+	}
+	if lastDot > lastComma {
+		return false
+	}
+	// "1,234" is ambiguous; treat three trailing digits as a thousands separator
+	return lastDot >= 0 || len(s)-lastComma-1 != 3
+}
+
+func parseContributionDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range contributionDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format: %s", s)
+}
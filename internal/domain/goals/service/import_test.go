@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+)
+
+func TestParseContributionsCSV_HeaderAndFormats(t *testing.T) {
+	data := []byte("date,amount,note\n" +
+		"2025-03-01,150.00,March savings\n" +
+		"2025-01-15,\"1,200.50\",Bonus\n" +
+		"15/02/2025,75,\n")
+
+	contributions, rowErrors, err := parseContributionsCSV(data, "EUR")
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	require.Len(t, contributions, 3)
+
+	// Sorted chronologically
+	assert.Equal(t, int64(120050), contributions[0].AmountMinor)
+	assert.Equal(t, "Bonus", *contributions[0].Note)
+	assert.Equal(t, int64(7500), contributions[1].AmountMinor)
+	assert.Nil(t, contributions[1].Note)
+	assert.Equal(t, int64(15000), contributions[2].AmountMinor)
+	assert.Equal(t, "EUR", contributions[2].CurrencyCode)
+}
+
+func TestParseContributionsCSV_SemicolonEuropean(t *testing.T) {
+	data := []byte("01.06.2024;1.234,56;Holiday fund\n02.07.2024;10,5\n")
+
+	contributions, rowErrors, err := parseContributionsCSV(data, "EUR")
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	require.Len(t, contributions, 2)
+	assert.Equal(t, int64(123456), contributions[0].AmountMinor)
+	assert.Equal(t, int64(1050), contributions[1].AmountMinor)
+}
+
+func TestParseContributionsCSV_CollectsRowErrors(t *testing.T) {
+	data := []byte("2025-01-01,100\nnot-a-date,50\n2025-01-03,abc\n2025-01-04,0\n")
+
+	contributions, rowErrors, err := parseContributionsCSV(data, "EUR")
+	require.NoError(t, err)
+	assert.Len(t, contributions, 1)
+	assert.Len(t, rowErrors, 3)
+}
+
+func TestMilestonesFromHistory(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	goal := &repository.Goal{
+		TargetAmountMinor:  10000,
+		CurrentAmountMinor: 6000,
+		StartAt:            start,
+		EndAt:              start.AddDate(1, 0, 0),
+	}
+	contributions := []*repository.GoalContribution{
+		{AmountMinor: 2000, ContributedAt: start.AddDate(0, 1, 0)},
+		{AmountMinor: 4000, ContributedAt: start.AddDate(0, 3, 0)},
+	}
+
+	milestones := milestonesFromHistory(goal, contributions)
+	require.Len(t, milestones, 4)
+
+	// 25% reached when the second contribution landed (2000 < 2500 <= 6000)
+	assert.True(t, milestones[0].Reached)
+	assert.Equal(t, start.AddDate(0, 3, 0), *milestones[0].ReachedAt)
+	assert.True(t, milestones[1].Reached)
+	assert.False(t, milestones[2].Reached)
+	assert.Nil(t, milestones[2].ReachedAt)
+	assert.False(t, milestones[3].Reached)
+}
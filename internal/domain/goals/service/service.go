@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cache"
 )
 
 // GoalProgress contains calculated progress information
@@ -36,9 +37,16 @@ type Milestone struct {
 	ExpectedBy time.Time
 }
 
+// MilestoneListener is told when a contribution takes a goal past a milestone
+type MilestoneListener interface {
+	GoalMilestoneReached(ctx context.Context, goal *repository.Goal, milestone *MilestoneReached)
+}
+
 // Service provides goal management business logic
 type Service struct {
-	repo repository.GoalRepository
+	repo     repository.GoalRepository
+	listener MilestoneListener // Optional: nil if nothing follows milestones
+	cache    *cache.Cache      // Optional: nil computes progress on every call
 }
 
 // NewService creates a new goals service
@@ -46,6 +54,18 @@ func NewService(repo repository.GoalRepository) *Service {
 	return &Service{repo: repo}
 }
 
+// WithMilestoneListener notifies the listener when a goal reaches a milestone
+func (s *Service) WithMilestoneListener(listener MilestoneListener) *Service {
+	s.listener = listener
+	return s
+}
+
+// WithCache keeps computed goal progress in c until the goal changes
+func (s *Service) WithCache(c *cache.Cache) *Service {
+	s.cache = c
+	return s
+}
+
 // CreateGoal creates a new goal
 func (s *Service) CreateGoal(ctx context.Context, userID uuid.UUID, name string, goalType repository.GoalType, targetMinor int64, currency string, startAt, endAt time.Time) (*repository.Goal, error) {
 	if endAt.Before(startAt) {
@@ -74,14 +94,14 @@ func (s *Service) CreateGoal(ctx context.Context, userID uuid.UUID, name string,
 	return goal, nil
 }
 
-// GetGoal retrieves a goal by ID
-func (s *Service) GetGoal(ctx context.Context, goalID uuid.UUID) (*repository.Goal, error) {
-	return s.repo.GetByID(ctx, goalID)
+// GetGoal retrieves one of the user's goals by ID
+func (s *Service) GetGoal(ctx context.Context, userID, goalID uuid.UUID) (*repository.Goal, error) {
+	return s.repo.GetByID(ctx, userID, goalID)
 }
 
-// UpdateGoal updates a goal
-func (s *Service) UpdateGoal(ctx context.Context, goalID uuid.UUID, name *string, targetMinor *int64, endAt *time.Time, status *repository.GoalStatus) (*repository.Goal, error) {
-	goal, err := s.repo.GetByID(ctx, goalID)
+// UpdateGoal updates one of the user's goals
+func (s *Service) UpdateGoal(ctx context.Context, userID, goalID uuid.UUID, name *string, targetMinor *int64, endAt *time.Time, status *repository.GoalStatus) (*repository.Goal, error) {
+	goal, err := s.repo.GetByID(ctx, userID, goalID)
 	if err != nil {
 		return nil, err
 	}
@@ -102,12 +122,13 @@ func (s *Service) UpdateGoal(ctx context.Context, goalID uuid.UUID, name *string
 	if err := s.repo.Update(ctx, goal); err != nil {
 		return nil, err
 	}
+	s.cache.Invalidate(ctx, goal.UserID)
 	return goal, nil
 }
 
-// DeleteGoal removes a goal
-func (s *Service) DeleteGoal(ctx context.Context, goalID uuid.UUID) error {
-	return s.repo.Delete(ctx, goalID)
+// DeleteGoal removes one of the user's goals
+func (s *Service) DeleteGoal(ctx context.Context, userID, goalID uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, goalID)
 }
 
 // ListGoals retrieves all goals for a user
@@ -116,18 +137,46 @@ func (s *Service) ListGoals(ctx context.Context, userID uuid.UUID, statusFilter
 }
 
 // GetGoalProgress calculates detailed progress for a goal
-func (s *Service) GetGoalProgress(ctx context.Context, goalID uuid.UUID) (*GoalProgress, error) {
-	goal, err := s.repo.GetByID(ctx, goalID)
+func (s *Service) GetGoalProgress(ctx context.Context, userID, goalID uuid.UUID) (*GoalProgress, error) {
+	goal, err := s.repo.GetByID(ctx, userID, goalID)
 	if err != nil {
 		return nil, err
 	}
+	// Keyed by the goal's version too, so changes made outside this service miss
+	key := fmt.Sprintf("goal_progress:%s:%d", goalID, goal.UpdatedAt.UnixNano())
+	return cache.GetOrLoad(ctx, s.cache, goal.UserID, key, func(ctx context.Context) (*GoalProgress, error) {
+		return s.computeGoalProgress(ctx, goal)
+	})
+}
 
-	contributions, err := s.repo.ListContributions(ctx, goalID, 10)
+// ListGoalProgress calculates progress for all of a user's goals, loading
+// them with their recent contributions in one query
+func (s *Service) ListGoalProgress(ctx context.Context, userID uuid.UUID, statusFilter *repository.GoalStatus) ([]*GoalProgress, error) {
+	goals, err := s.repo.ListWithContributions(ctx, userID, statusFilter, recentContributionLimit)
 	if err != nil {
 		return nil, err
 	}
-
 	now := time.Now()
+	progress := make([]*GoalProgress, len(goals))
+	for i, g := range goals {
+		progress[i] = s.buildGoalProgress(g.Goal, g.Contributions, now)
+	}
+	return progress, nil
+}
+
+// recentContributionLimit is how many contributions progress lists
+const recentContributionLimit = 10
+
+func (s *Service) computeGoalProgress(ctx context.Context, goal *repository.Goal) (*GoalProgress, error) {
+	contributions, err := s.repo.ListContributions(ctx, goal.UserID, goal.ID, recentContributionLimit)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildGoalProgress(goal, contributions, time.Now()), nil
+}
+
+// buildGoalProgress calculates a goal's progress as of now
+func (s *Service) buildGoalProgress(goal *repository.Goal, contributions []*repository.GoalContribution, now time.Time) *GoalProgress {
 	progress := &GoalProgress{
 		Goal:                goal,
 		RecentContributions: contributions,
@@ -187,7 +236,7 @@ func (s *Service) GetGoalProgress(ctx context.Context, goalID uuid.UUID) (*GoalP
 	// Generate nudge if needed
 	progress.NeedsAttention, progress.NudgeMessage, progress.SuggestedContribution = s.generateNudge(progress, now)
 
-	return progress, nil
+	return progress
 }
 
 func (s *Service) generatePaceMessage(progress *GoalProgress) string {
@@ -281,8 +330,8 @@ func (s *Service) generateNudge(progress *GoalProgress, now time.Time) (needsAtt
 }
 
 // ContributeToGoal adds a contribution and returns milestone info
-func (s *Service) ContributeToGoal(ctx context.Context, goalID uuid.UUID, amountMinor int64, currency string, note *string) (*GoalProgress, *MilestoneReached, error) {
-	goal, err := s.repo.GetByID(ctx, goalID)
+func (s *Service) ContributeToGoal(ctx context.Context, userID, goalID uuid.UUID, amountMinor int64, currency string, note *string) (*GoalProgress, *MilestoneReached, error) {
+	goal, err := s.repo.GetByID(ctx, userID, goalID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -299,12 +348,13 @@ func (s *Service) ContributeToGoal(ctx context.Context, goalID uuid.UUID, amount
 		Note:         note,
 	}
 
-	if err := s.repo.AddContribution(ctx, contribution); err != nil {
+	if err := s.repo.AddContribution(ctx, userID, contribution); err != nil {
 		return nil, nil, err
 	}
+	s.cache.Invalidate(ctx, goal.UserID)
 
 	// Get updated progress
-	progress, err := s.GetGoalProgress(ctx, goalID)
+	progress, err := s.GetGoalProgress(ctx, userID, goalID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -326,10 +376,14 @@ func (s *Service) ContributeToGoal(ctx context.Context, goalID uuid.UUID, amount
 	// Check if goal was completed
 	if newAmount >= goal.TargetAmountMinor && goal.Status == repository.GoalStatusActive {
 		status := repository.GoalStatusCompleted
-		_, _ = s.UpdateGoal(ctx, goalID, nil, nil, nil, &status)
+		_, _ = s.UpdateGoal(ctx, userID, goalID, nil, nil, nil, &status)
 		progress.Goal.Status = repository.GoalStatusCompleted
 	}
 
+	if milestoneReached != nil && s.listener != nil {
+		s.listener.GoalMilestoneReached(ctx, progress.Goal, milestoneReached)
+	}
+
 	return progress, milestoneReached, nil
 }
 
@@ -357,17 +411,13 @@ func (s *Service) getMilestoneMessage(percent int) string {
 // GetGoalsBehindPace returns all goals that are behind schedule for nudge alerts
 func (s *Service) GetGoalsBehindPace(ctx context.Context, userID uuid.UUID, behindThreshold float64) ([]*GoalProgress, error) {
 	status := repository.GoalStatusActive
-	goals, err := s.repo.ListByUserID(ctx, userID, &status)
+	goals, err := s.ListGoalProgress(ctx, userID, &status)
 	if err != nil {
 		return nil, err
 	}
 
 	var behindGoals []*GoalProgress
-	for _, goal := range goals {
-		progress, err := s.GetGoalProgress(ctx, goal.ID)
-		if err != nil {
-			continue
-		}
+	for _, progress := range goals {
 		if progress.IsBehindPace && progress.PacePercent < behindThreshold {
 			behindGoals = append(behindGoals, progress)
 		}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresHouseholdRepository implements HouseholdRepository using PostgreSQL
+type PostgresHouseholdRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresHouseholdRepository creates a new PostgreSQL household repository
+func NewPostgresHouseholdRepository(pool *pgxpool.Pool) *PostgresHouseholdRepository {
+	return &PostgresHouseholdRepository{pool: pool}
+}
+
+// CreateHousehold creates the household and adds its creator as owner
+func (r *PostgresHouseholdRepository) CreateHousehold(ctx context.Context, h *Household) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO households (id, name, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at`,
+		h.ID, h.Name, h.CreatedBy,
+	).Scan(&h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create household: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO household_members (household_id, user_id, role)
+		VALUES ($1, $2, $3)`, h.ID, h.CreatedBy, RoleOwner); err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyInHousehold
+		}
+		return fmt.Errorf("failed to add household owner: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit household: %w", err)
+	}
+	return nil
+}
+
+// GetHousehold retrieves a household by ID
+func (r *PostgresHouseholdRepository) GetHousehold(ctx context.Context, id uuid.UUID) (*Household, error) {
+	h := &Household{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM households WHERE id = $1`, id,
+	).Scan(&h.ID, &h.Name, &h.CreatedBy, &h.CreatedAt, &h.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household: %w", err)
+	}
+	return h, nil
+}
+
+const memberSelect = `
+	SELECT m.household_id, m.user_id, m.role, u.email, u.display_name, m.joined_at
+	FROM household_members m
+	JOIN users u ON u.id = m.user_id`
+
+func scanMember(row pgx.Row) (*Member, error) {
+	m := &Member{}
+	err := row.Scan(&m.HouseholdID, &m.UserID, &m.Role, &m.Email, &m.DisplayName, &m.JoinedAt)
+	return m, err
+}
+
+// GetMembership retrieves the user's household membership
+func (r *PostgresHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*Member, error) {
+	m, err := scanMember(r.pool.QueryRow(ctx, memberSelect+` WHERE m.user_id = $1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household membership: %w", err)
+	}
+	return m, nil
+}
+
+// ListMembers lists a household's members, owners first
+func (r *PostgresHouseholdRepository) ListMembers(ctx context.Context, householdID uuid.UUID) ([]*Member, error) {
+	rows, err := r.pool.Query(ctx, memberSelect+`
+		WHERE m.household_id = $1
+		ORDER BY m.role = 'owner' DESC, m.joined_at`, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list household members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*Member
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan household member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// UpdateMemberRole changes a member's role
+func (r *PostgresHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID, userID uuid.UUID, role Role) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE household_members SET role = $3
+		WHERE household_id = $1 AND user_id = $2`, householdID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to update household member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RemoveMember removes the member and unshares what they shared with the household
+func (r *PostgresHouseholdRepository) RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `DELETE FROM household_members WHERE household_id = $1 AND user_id = $2`, householdID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove household member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	for _, table := range []string{"user_plans", "accounts", "categories"} {
+		if _, err := tx.Exec(ctx, `UPDATE `+table+` SET household_id = NULL WHERE household_id = $1 AND user_id = $2`, householdID, userID); err != nil {
+			return fmt.Errorf("failed to unshare %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit member removal: %w", err)
+	}
+	return nil
+}
+
+// CreateInvite creates an invite, replacing any open invite for the same email
+func (r *PostgresHouseholdRepository) CreateInvite(ctx context.Context, invite *Invite) error {
+	if invite.ID == uuid.Nil {
+		invite.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM household_invites
+		WHERE household_id = $1 AND email = $2 AND accepted_at IS NULL`, invite.HouseholdID, invite.Email); err != nil {
+		return fmt.Errorf("failed to replace household invite: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO household_invites (id, household_id, email, role, token, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at`,
+		invite.ID, invite.HouseholdID, invite.Email, invite.Role, invite.Token, invite.InvitedBy, invite.ExpiresAt,
+	).Scan(&invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create household invite: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit household invite: %w", err)
+	}
+	return nil
+}
+
+const inviteColumns = `id, household_id, email, role, token, invited_by, expires_at, accepted_at, created_at`
+
+func scanInvite(row pgx.Row) (*Invite, error) {
+	i := &Invite{}
+	err := row.Scan(&i.ID, &i.HouseholdID, &i.Email, &i.Role, &i.Token, &i.InvitedBy, &i.ExpiresAt, &i.AcceptedAt, &i.CreatedAt)
+	return i, err
+}
+
+// GetInviteByToken retrieves an invite by its token
+func (r *PostgresHouseholdRepository) GetInviteByToken(ctx context.Context, token string) (*Invite, error) {
+	invite, err := scanInvite(r.pool.QueryRow(ctx, `SELECT `+inviteColumns+` FROM household_invites WHERE token = $1`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household invite: %w", err)
+	}
+	return invite, nil
+}
+
+// ListPendingInvites lists a household's open invites, including expired ones
+func (r *PostgresHouseholdRepository) ListPendingInvites(ctx context.Context, householdID uuid.UUID) ([]*Invite, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+inviteColumns+`
+		FROM household_invites
+		WHERE household_id = $1 AND accepted_at IS NULL
+		ORDER BY created_at DESC`, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list household invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*Invite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan household invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	return invites, rows.Err()
+}
+
+// AcceptInvite adds the user to the invite's household and closes the invite
+func (r *PostgresHouseholdRepository) AcceptInvite(ctx context.Context, invite *Invite, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE household_invites SET accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL`, invite.ID)
+	if err != nil {
+		return fmt.Errorf("failed to close household invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO household_members (household_id, user_id, role)
+		VALUES ($1, $2, $3)`, invite.HouseholdID, userID, invite.Role); err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyInHousehold
+		}
+		return fmt.Errorf("failed to add household member: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit invite acceptance: %w", err)
+	}
+	return nil
+}
+
+// GetUserEmail returns the email of a user's account
+func (r *PostgresHouseholdRepository) GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error) {
+	var email string
+	err := r.pool.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", sql.ErrNoRows
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	return email, nil
+}
+
+// ShareAccount sets or clears the household of one of the user's accounts
+func (r *PostgresHouseholdRepository) ShareAccount(ctx context.Context, userID, accountID uuid.UUID, householdID *uuid.UUID) error {
+	return r.share(ctx, "accounts", userID, accountID, householdID)
+}
+
+// ShareCategory sets or clears the household of one of the user's categories
+func (r *PostgresHouseholdRepository) ShareCategory(ctx context.Context, userID, categoryID uuid.UUID, householdID *uuid.UUID) error {
+	return r.share(ctx, "categories", userID, categoryID, householdID)
+}
+
+func (r *PostgresHouseholdRepository) share(ctx context.Context, table string, userID, id uuid.UUID, householdID *uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE `+table+` SET household_id = $3 WHERE id = $1 AND user_id = $2`, id, userID, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to share %s: %w", table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,86 @@
+// Package repository provides database operations for households and their members.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAlreadyInHousehold is returned when a user who already belongs to a household joins another
+var ErrAlreadyInHousehold = errors.New("user already belongs to a household")
+
+// Role is a member's permissions in a household
+type Role string
+
+const (
+	RoleOwner  Role = "owner"  // Manages members and invites, edits shared plans
+	RoleEditor Role = "editor" // Edits shared plans
+	RoleViewer Role = "viewer" // Sees shared plans, accounts and insights
+)
+
+// CanEdit reports whether the role may change shared resources
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// Household is a group of users sharing plans, accounts and categories
+type Household struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedBy uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Member is a user's membership of a household
+type Member struct {
+	HouseholdID uuid.UUID
+	UserID      uuid.UUID
+	Role        Role
+	Email       string // From the user's account
+	DisplayName *string
+	JoinedAt    time.Time
+}
+
+// Invite is a pending invitation to join a household
+type Invite struct {
+	ID          uuid.UUID
+	HouseholdID uuid.UUID
+	Email       string
+	Role        Role
+	Token       string
+	InvitedBy   uuid.UUID
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+// HouseholdRepository defines the interface for household persistence
+type HouseholdRepository interface {
+	// CreateHousehold creates the household with its creator as owner
+	CreateHousehold(ctx context.Context, h *Household) error
+	GetHousehold(ctx context.Context, id uuid.UUID) (*Household, error)
+
+	// Members
+	GetMembership(ctx context.Context, userID uuid.UUID) (*Member, error)
+	ListMembers(ctx context.Context, householdID uuid.UUID) ([]*Member, error)
+	UpdateMemberRole(ctx context.Context, householdID, userID uuid.UUID, role Role) error
+	// RemoveMember removes the member and unshares the plans, accounts and
+	// categories they shared with the household
+	RemoveMember(ctx context.Context, householdID, userID uuid.UUID) error
+
+	// Invites
+	CreateInvite(ctx context.Context, invite *Invite) error
+	GetInviteByToken(ctx context.Context, token string) (*Invite, error)
+	ListPendingInvites(ctx context.Context, householdID uuid.UUID) ([]*Invite, error)
+	// AcceptInvite adds the user as a member with the invite's role and closes the invite
+	AcceptInvite(ctx context.Context, invite *Invite, userID uuid.UUID) error
+	GetUserEmail(ctx context.Context, userID uuid.UUID) (string, error)
+
+	// Sharing: set or clear (nil) the household of a resource owned by userID
+	ShareAccount(ctx context.Context, userID, accountID uuid.UUID, householdID *uuid.UUID) error
+	ShareCategory(ctx context.Context, userID, categoryID uuid.UUID, householdID *uuid.UUID) error
+}
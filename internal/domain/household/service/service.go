@@ -0,0 +1,310 @@
+// Package service provides business logic for households: membership, invites
+// and sharing plans, accounts and categories between partners.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/household/repository"
+)
+
+// inviteTTL is how long an invite can be accepted
+const inviteTTL = 7 * 24 * time.Hour
+
+// Household errors
+var (
+	ErrInvalidHousehold   = errors.New("a household needs a name")
+	ErrNotInHousehold     = errors.New("user does not belong to a household")
+	ErrNotHouseholdOwner  = errors.New("only the household owner can manage members")
+	ErrInvalidRole        = errors.New("members can be invited as editor or viewer")
+	ErrInviteNotFound     = errors.New("invite not found or already accepted")
+	ErrInviteExpired      = errors.New("invite has expired")
+	ErrInviteWrongAccount = errors.New("invite was sent to a different email")
+	ErrOwnerCannotLeave   = errors.New("the owner can't leave while other members remain")
+	ErrMemberNotFound     = errors.New("household member not found")
+	ErrViewerCannotShare  = errors.New("viewers can't share with the household")
+)
+
+// HouseholdDetails is a household with its members and, for the owner, open invites
+type HouseholdDetails struct {
+	Household *repository.Household
+	Members   []*repository.Member
+	Invites   []*repository.Invite // Only listed for the owner
+	Role      repository.Role      // The requesting user's role
+}
+
+// Service provides household business logic
+type Service struct {
+	repo repository.HouseholdRepository
+	now  func() time.Time
+}
+
+// NewService creates a new household service
+func NewService(repo repository.HouseholdRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// CreateHousehold creates a household owned by the user
+func (s *Service) CreateHousehold(ctx context.Context, userID uuid.UUID, name string) (*repository.Household, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidHousehold
+	}
+
+	h := &repository.Household{Name: name, CreatedBy: userID}
+	if err := s.repo.CreateHousehold(ctx, h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// GetHousehold returns the user's household, or nil if they don't belong to one
+func (s *Service) GetHousehold(ctx context.Context, userID uuid.UUID) (*HouseholdDetails, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil || member == nil {
+		return nil, err
+	}
+
+	h, err := s.repo.GetHousehold(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListMembers(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &HouseholdDetails{Household: h, Members: members, Role: member.Role}
+	if member.Role == repository.RoleOwner {
+		if details.Invites, err = s.repo.ListPendingInvites(ctx, member.HouseholdID); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
+// InviteMember invites someone by email to the owner's household. The returned
+// invite's token is what the invitee accepts.
+func (s *Service) InviteMember(ctx context.Context, userID uuid.UUID, email string, role repository.Role) (*repository.Invite, error) {
+	if role != repository.RoleEditor && role != repository.RoleViewer {
+		return nil, ErrInvalidRole
+	}
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid invite email %q", email)
+	}
+
+	owner, err := s.requireOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &repository.Invite{
+		HouseholdID: owner.HouseholdID,
+		Email:       email,
+		Role:        role,
+		Token:       generateInviteToken(),
+		InvitedBy:   userID,
+		ExpiresAt:   s.now().Add(inviteTTL),
+	}
+	if err := s.repo.CreateInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// AcceptInvite joins the user to the invite's household. The invite must have
+// been sent to the user's account email.
+func (s *Service) AcceptInvite(ctx context.Context, userID uuid.UUID, token string) (*repository.Member, error) {
+	invite, err := s.repo.GetInviteByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && invite.AcceptedAt != nil) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.now().After(invite.ExpiresAt) {
+		return nil, ErrInviteExpired
+	}
+
+	email, err := s.repo.GetUserEmail(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(email, invite.Email) {
+		return nil, ErrInviteWrongAccount
+	}
+
+	err = s.repo.AcceptInvite(ctx, invite, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembership(ctx, userID)
+}
+
+// UpdateMemberRole changes another member's role. Only the owner can, and
+// ownership can't be handed over this way.
+func (s *Service) UpdateMemberRole(ctx context.Context, userID, memberID uuid.UUID, role repository.Role) error {
+	if role != repository.RoleEditor && role != repository.RoleViewer {
+		return ErrInvalidRole
+	}
+	owner, err := s.requireOwner(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == userID {
+		return ErrInvalidRole
+	}
+
+	err = s.repo.UpdateMemberRole(ctx, owner.HouseholdID, memberID, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// RemoveMember removes a member from the user's household: the owner can remove
+// anyone, other members only themselves. What the member shared is unshared.
+func (s *Service) RemoveMember(ctx context.Context, userID, memberID uuid.UUID) error {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNotInHousehold
+	}
+
+	if memberID != userID && member.Role != repository.RoleOwner {
+		return ErrNotHouseholdOwner
+	}
+	if memberID == userID && member.Role == repository.RoleOwner {
+		members, err := s.repo.ListMembers(ctx, member.HouseholdID)
+		if err != nil {
+			return err
+		}
+		if len(members) > 1 {
+			return ErrOwnerCannotLeave
+		}
+	}
+
+	err = s.repo.RemoveMember(ctx, member.HouseholdID, memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// ShareAccount shares one of the user's accounts with their household, or
+// stops sharing it. Members see the account's transactions.
+func (s *Service) ShareAccount(ctx context.Context, userID, accountID uuid.UUID, shared bool) error {
+	householdID, err := s.shareTarget(ctx, userID, shared)
+	if err != nil {
+		return err
+	}
+	return s.repo.ShareAccount(ctx, userID, accountID, householdID)
+}
+
+// ShareCategory shares one of the user's categories with their household, or
+// stops sharing it
+func (s *Service) ShareCategory(ctx context.Context, userID, categoryID uuid.UUID, shared bool) error {
+	householdID, err := s.shareTarget(ctx, userID, shared)
+	if err != nil {
+		return err
+	}
+	return s.repo.ShareCategory(ctx, userID, categoryID, householdID)
+}
+
+// MemberRole returns the user's role in the household, or "" if they aren't a member
+func (s *Service) MemberRole(ctx context.Context, householdID, userID uuid.UUID) (repository.Role, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil || member == nil || member.HouseholdID != householdID {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// HouseholdMemberIDs lists the IDs of a household's members
+func (s *Service) HouseholdMemberIDs(ctx context.Context, householdID uuid.UUID) ([]uuid.UUID, error) {
+	members, err := s.repo.ListMembers(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids, nil
+}
+
+// MemberIDs lists the user and the other members of their household; just the
+// user when they don't belong to one
+func (s *Service) MemberIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return []uuid.UUID{userID}, nil
+	}
+	return s.HouseholdMemberIDs(ctx, member.HouseholdID)
+}
+
+// membership returns the user's membership, or nil if they have none
+func (s *Service) membership(ctx context.Context, userID uuid.UUID) (*repository.Member, error) {
+	member, err := s.repo.GetMembership(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return member, err
+}
+
+func (s *Service) requireOwner(ctx context.Context, userID uuid.UUID) (*repository.Member, error) {
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotInHousehold
+	}
+	if member.Role != repository.RoleOwner {
+		return nil, ErrNotHouseholdOwner
+	}
+	return member, nil
+}
+
+// shareTarget is the household to share into, or nil to unshare. Viewers can't share.
+func (s *Service) shareTarget(ctx context.Context, userID uuid.UUID, shared bool) (*uuid.UUID, error) {
+	if !shared {
+		return nil, nil
+	}
+	member, err := s.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNotInHousehold
+	}
+	if !member.Role.CanEdit() {
+		return nil, ErrViewerCannotShare
+	}
+	return &member.HouseholdID, nil
+}
+
+// generateInviteToken creates a random 32-character hex token
+func generateInviteToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
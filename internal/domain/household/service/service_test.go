@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/household/repository"
+)
+
+// fakeHouseholdRepository keeps households, members and invites in memory
+type fakeHouseholdRepository struct {
+	repository.HouseholdRepository
+	households map[uuid.UUID]*repository.Household
+	members    map[uuid.UUID]*repository.Member // Keyed by user
+	invites    map[string]*repository.Invite    // Keyed by token
+	emails     map[uuid.UUID]string
+}
+
+func newFakeHouseholdRepository() *fakeHouseholdRepository {
+	return &fakeHouseholdRepository{
+		households: make(map[uuid.UUID]*repository.Household),
+		members:    make(map[uuid.UUID]*repository.Member),
+		invites:    make(map[string]*repository.Invite),
+		emails:     make(map[uuid.UUID]string),
+	}
+}
+
+func (f *fakeHouseholdRepository) CreateHousehold(_ context.Context, h *repository.Household) error {
+	if _, ok := f.members[h.CreatedBy]; ok {
+		return repository.ErrAlreadyInHousehold
+	}
+	h.ID = uuid.New()
+	f.households[h.ID] = h
+	f.members[h.CreatedBy] = &repository.Member{HouseholdID: h.ID, UserID: h.CreatedBy, Role: repository.RoleOwner}
+	return nil
+}
+
+func (f *fakeHouseholdRepository) GetMembership(_ context.Context, userID uuid.UUID) (*repository.Member, error) {
+	m, ok := f.members[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return m, nil
+}
+
+func (f *fakeHouseholdRepository) ListMembers(_ context.Context, householdID uuid.UUID) ([]*repository.Member, error) {
+	var members []*repository.Member
+	for _, m := range f.members {
+		if m.HouseholdID == householdID {
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
+
+func (f *fakeHouseholdRepository) UpdateMemberRole(_ context.Context, householdID, userID uuid.UUID, role repository.Role) error {
+	m, ok := f.members[userID]
+	if !ok || m.HouseholdID != householdID {
+		return sql.ErrNoRows
+	}
+	m.Role = role
+	return nil
+}
+
+func (f *fakeHouseholdRepository) RemoveMember(_ context.Context, householdID, userID uuid.UUID) error {
+	m, ok := f.members[userID]
+	if !ok || m.HouseholdID != householdID {
+		return sql.ErrNoRows
+	}
+	delete(f.members, userID)
+	return nil
+}
+
+func (f *fakeHouseholdRepository) CreateInvite(_ context.Context, invite *repository.Invite) error {
+	invite.ID = uuid.New()
+	f.invites[invite.Token] = invite
+	return nil
+}
+
+func (f *fakeHouseholdRepository) GetInviteByToken(_ context.Context, token string) (*repository.Invite, error) {
+	invite, ok := f.invites[token]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return invite, nil
+}
+
+func (f *fakeHouseholdRepository) AcceptInvite(_ context.Context, invite *repository.Invite, userID uuid.UUID) error {
+	if _, ok := f.members[userID]; ok {
+		return repository.ErrAlreadyInHousehold
+	}
+	now := time.Now()
+	invite.AcceptedAt = &now
+	f.members[userID] = &repository.Member{HouseholdID: invite.HouseholdID, UserID: userID, Role: invite.Role}
+	return nil
+}
+
+func (f *fakeHouseholdRepository) GetUserEmail(_ context.Context, userID uuid.UUID) (string, error) {
+	return f.emails[userID], nil
+}
+
+func TestInviteAndAccept_JoinsWithInvitedRole(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeHouseholdRepository()
+	svc := NewService(repo)
+	owner, partner := uuid.New(), uuid.New()
+	repo.emails[partner] = "Partner@Example.com"
+
+	household, err := svc.CreateHousehold(ctx, owner, "  Home  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Home", household.Name)
+
+	invite, err := svc.InviteMember(ctx, owner, "partner@example.com", repository.RoleViewer)
+	require.NoError(t, err)
+	assert.Len(t, invite.Token, 32)
+
+	member, err := svc.AcceptInvite(ctx, partner, invite.Token)
+	require.NoError(t, err)
+	assert.Equal(t, household.ID, member.HouseholdID)
+	assert.Equal(t, repository.RoleViewer, member.Role)
+
+	// The invite is single-use
+	_, err = svc.AcceptInvite(ctx, uuid.New(), invite.Token)
+	assert.ErrorIs(t, err, ErrInviteNotFound)
+
+	ids, err := svc.MemberIDs(ctx, partner)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{owner, partner}, ids)
+
+	role, err := svc.MemberRole(ctx, household.ID, partner)
+	require.NoError(t, err)
+	assert.Equal(t, repository.RoleViewer, role)
+	role, err = svc.MemberRole(ctx, uuid.New(), partner)
+	require.NoError(t, err)
+	assert.Empty(t, role)
+}
+
+func TestAcceptInvite_RejectsExpiredAndWrongAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeHouseholdRepository()
+	svc := NewService(repo)
+	owner, stranger := uuid.New(), uuid.New()
+	repo.emails[stranger] = "stranger@example.com"
+
+	_, err := svc.CreateHousehold(ctx, owner, "Home")
+	require.NoError(t, err)
+	invite, err := svc.InviteMember(ctx, owner, "partner@example.com", repository.RoleEditor)
+	require.NoError(t, err)
+
+	_, err = svc.AcceptInvite(ctx, stranger, invite.Token)
+	assert.ErrorIs(t, err, ErrInviteWrongAccount)
+
+	svc.now = func() time.Time { return time.Now().Add(inviteTTL + time.Hour) }
+	_, err = svc.AcceptInvite(ctx, stranger, invite.Token)
+	assert.ErrorIs(t, err, ErrInviteExpired)
+}
+
+func TestMemberManagement_RequiresOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeHouseholdRepository()
+	svc := NewService(repo)
+	owner, editor := uuid.New(), uuid.New()
+	repo.emails[editor] = "editor@example.com"
+
+	_, err := svc.CreateHousehold(ctx, owner, "Home")
+	require.NoError(t, err)
+	invite, err := svc.InviteMember(ctx, owner, "editor@example.com", repository.RoleEditor)
+	require.NoError(t, err)
+	_, err = svc.AcceptInvite(ctx, editor, invite.Token)
+	require.NoError(t, err)
+
+	_, err = svc.InviteMember(ctx, editor, "someone@example.com", repository.RoleViewer)
+	assert.ErrorIs(t, err, ErrNotHouseholdOwner)
+	_, err = svc.InviteMember(ctx, owner, "someone@example.com", repository.RoleOwner)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, editor, owner), ErrNotHouseholdOwner)
+	assert.ErrorIs(t, svc.RemoveMember(ctx, owner, owner), ErrOwnerCannotLeave)
+
+	require.NoError(t, svc.UpdateMemberRole(ctx, owner, editor, repository.RoleViewer))
+	assert.Equal(t, repository.RoleViewer, repo.members[editor].Role)
+
+	// Members can always leave
+	require.NoError(t, svc.RemoveMember(ctx, editor, editor))
+	details, err := svc.GetHousehold(ctx, editor)
+	require.NoError(t, err)
+	assert.Nil(t, details)
+}
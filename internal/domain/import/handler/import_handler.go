@@ -5,7 +5,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
@@ -14,8 +16,10 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
 	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
 	planexcel "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/excel"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
 )
@@ -138,10 +142,27 @@ func (h *ImportHandler) UploadUserFile(ctx context.Context, req *connect.Request
 		return nil, connect.NewError(connect.CodeInvalidArgument, nil)
 	}
 
-	// Calculate checksum if not provided
+	if err := h.importSvc.CheckStorageQuota(ctx, userID, int64(len(fileBytes))); err != nil {
+		if errors.Is(err, importservice.ErrStorageQuotaExceeded) {
+			return nil, connect.NewError(connect.CodeResourceExhausted, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// Check the content matches its type and strip macros before storing it
+	fileType := protoFileTypeToString(req.Msg.GetType())
+	scan, err := h.importSvc.ScanUpload(ctx, fileType, fileBytes)
+	if err != nil {
+		if errors.Is(err, filescan.ErrEmptyFile) || errors.Is(err, filescan.ErrFileTooLarge) || errors.Is(err, filescan.ErrTypeMismatch) {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// Calculate checksum if not provided, or if the content changed
 	checksum := req.Msg.GetChecksumSha256()
-	if checksum == "" {
-		hash := sha256.Sum256(fileBytes)
+	if checksum == "" || scan.MacrosStripped {
+		hash := sha256.Sum256(scan.Data)
 		checksum = hex.EncodeToString(hash[:])
 	}
 
@@ -150,8 +171,8 @@ func (h *ImportHandler) UploadUserFile(ctx context.Context, req *connect.Request
 		ctx,
 		userID,
 		req.Msg.GetFileName(),
-		req.Msg.GetMimeType(),
-		bytes.NewReader(fileBytes),
+		scan.MimeType,
+		bytes.NewReader(scan.Data),
 	)
 	if err != nil {
 		h.logger.Error("failed to upload file to storage", slog.Any("error", err))
@@ -162,12 +183,13 @@ func (h *ImportHandler) UploadUserFile(ctx context.Context, req *connect.Request
 	userFile, err := h.importSvc.CreateUserFile(ctx, importservice.CreateUserFileInput{
 		UserID:     userID,
 		FileID:     fileInfo.ID,
-		Type:       protoFileTypeToString(req.Msg.GetType()),
-		MimeType:   req.Msg.GetMimeType(),
+		Type:       fileType,
+		MimeType:   scan.MimeType,
 		FileName:   req.Msg.GetFileName(),
-		SizeBytes:  int64(len(fileBytes)),
+		SizeBytes:  int64(len(scan.Data)),
 		Checksum:   checksum,
 		StorageURL: fileInfo.Path,
+		Scan:       scan,
 	})
 	if err != nil {
 		h.logger.Error("failed to create user file record", slog.Any("error", err))
@@ -227,9 +249,124 @@ func (h *ImportHandler) GetImportJob(ctx context.Context, req *connect.Request[e
 	return nil, connect.NewError(connect.CodeUnimplemented, nil)
 }
 
-// ListImportJobs lists import jobs
+// ListImportJobs lists the user's import history, newest first
 func (h *ImportHandler) ListImportJobs(ctx context.Context, req *connect.Request[echov1.ListImportJobsRequest]) (*connect.Response[echov1.ListImportJobsResponse], error) {
-	return nil, connect.NewError(connect.CodeUnimplemented, nil)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
+		return nil, connect.NewError(connect.CodeUnauthenticated, nil)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	filter := repository.ListImportJobsFilter{
+		Status: importStatusFromProto(req.Msg.GetStatus()),
+		Kind:   importKindFromProto(req.Msg.GetKind()),
+		Limit:  50,
+	}
+	if page := req.Msg.GetPage(); page != nil {
+		if page.PageSize > 0 {
+			filter.Limit = int(page.PageSize)
+		}
+		// Page tokens are offsets
+		if offset, err := strconv.Atoi(page.PageToken); err == nil && offset > 0 {
+			filter.Offset = offset
+		}
+	}
+
+	jobs, total, err := h.importSvc.ListImportJobs(ctx, userID, filter)
+	if err != nil {
+		h.logger.Error("failed to list import jobs", slog.Any("error", err))
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	protoJobs := make([]*echov1.ImportJob, 0, len(jobs))
+	for _, job := range jobs {
+		protoJobs = append(protoJobs, importJobToProto(job))
+	}
+
+	var nextPageToken string
+	if int64(filter.Offset+len(jobs)) < total {
+		nextPageToken = strconv.Itoa(filter.Offset + len(jobs))
+	}
+
+	return connect.NewResponse(&echov1.ListImportJobsResponse{
+		Jobs: protoJobs,
+		Page: &echov1.PageResponse{NextPageToken: nextPageToken},
+	}), nil
+}
+
+// importJobToProto converts a repository ImportJob to proto
+func importJobToProto(job *repository.ImportJob) *echov1.ImportJob {
+	result := &echov1.ImportJob{
+		Id:     job.ID.String(),
+		UserId: job.UserID.String(),
+		FileId: job.FileID.String(),
+		Kind:   importKindToProto(job.Kind),
+		Status: importStatusToProto(job.Status),
+		Stats: &echov1.ImportStats{
+			RowsTotal:    int32(job.RowsTotal),
+			RowsImported: int32(job.RowsImported),
+			RowsFailed:   int32(job.RowsFailed),
+		},
+		RequestedAt: timestamppb.New(job.RequestedAt),
+	}
+	if job.Timezone != nil {
+		result.Timezone = *job.Timezone
+	}
+	if job.ErrorMessage != nil {
+		result.ErrorMessage = *job.ErrorMessage
+	}
+	if job.StartedAt != nil {
+		result.StartedAt = timestamppb.New(*job.StartedAt)
+	}
+	if job.FinishedAt != nil {
+		result.FinishedAt = timestamppb.New(*job.FinishedAt)
+	}
+	return result
+}
+
+var importStatuses = map[string]echov1.ImportStatus{
+	"pending":   echov1.ImportStatus_IMPORT_STATUS_PENDING,
+	"running":   echov1.ImportStatus_IMPORT_STATUS_RUNNING,
+	"succeeded": echov1.ImportStatus_IMPORT_STATUS_SUCCEEDED,
+	"failed":    echov1.ImportStatus_IMPORT_STATUS_FAILED,
+	"canceled":  echov1.ImportStatus_IMPORT_STATUS_CANCELED,
+}
+
+var importKinds = map[string]echov1.ImportKind{
+	"transactions": echov1.ImportKind_IMPORT_KIND_TRANSACTIONS,
+	"invoice":      echov1.ImportKind_IMPORT_KIND_INVOICE,
+}
+
+func importStatusToProto(status string) echov1.ImportStatus {
+	return importStatuses[status]
+}
+
+func importKindToProto(kind string) echov1.ImportKind {
+	return importKinds[kind]
+}
+
+// importStatusFromProto returns the stored status, or "" for unspecified
+func importStatusFromProto(status echov1.ImportStatus) string {
+	for name, s := range importStatuses {
+		if s == status {
+			return name
+		}
+	}
+	return ""
+}
+
+// importKindFromProto returns the stored kind, or "" for unspecified
+func importKindFromProto(kind echov1.ImportKind) string {
+	for name, k := range importKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return ""
 }
 
 // GetDocument retrieves a document
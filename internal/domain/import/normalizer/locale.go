@@ -0,0 +1,285 @@
+// locale.go cleans bank descriptors with locale packs: the card prefixes,
+// store numbers and city suffixes one country's banks wrap merchant names in.
+package normalizer
+
+import (
+	"sort"
+	"strings"
+)
+
+// LocalePack is the descriptor noise of one country's banks. Phrases are
+// matched case-insensitively on whole words.
+type LocalePack struct {
+	Code         string   // ISO 3166-1 alpha-2 country code, e.g. "PT"
+	CardPrefixes []string // Transaction-type prefixes, e.g. "COMPRA"
+	StoreMarkers []string // Words introducing a store number, e.g. "LOJA"
+	CitySuffixes []string // Trailing cities, their bank abbreviations and the country
+	Noise        []string // Phrases removed wherever they appear, e.g. "SAGT DANKE"
+}
+
+// localePacks are the built-in packs by code
+var localePacks = map[string]*LocalePack{
+	"PT": {
+		Code: "PT",
+		CardPrefixes: []string{
+			"COMPRAS C.DEB", "COMPRA C.DEB", "C.DEB", "COMPRAS", "COMPRA", "PAGAMENTO", "PAG.", "PAG",
+			"PGTO", "TRF.", "TRF", "TRANSF", "TRANSFERENCIA", "MB WAY", "MBWAY", "MULTIBANCO",
+			"LEVANTAMENTO", "LEV.", "DD", "DEB.DIRECTO", "DEBITO DIRECTO", "TPA", "CARTAO",
+			"VISA", "MASTERCARD", "MAESTRO",
+		},
+		StoreMarkers: []string{"LOJA", "LJ", "LOJ", "FIL", "FILIAL"},
+		CitySuffixes: []string{
+			"LISBOA", "LISB", "LX", "PORTO", "PRT", "MAIA", "MAI", "MATOSINHOS", "VILA NOVA DE GAIA",
+			"V N GAIA", "VN GAIA", "GAIA", "BRAGA", "COIMBRA", "AVEIRO", "LEIRIA", "VISEU", "FARO",
+			"SETUBAL", "SINTRA", "CASCAIS", "OEIRAS", "AMADORA", "ALMADA", "LOURES", "ODIVELAS",
+			"GUIMARAES", "EVORA", "FUNCHAL", "PONTA DELGADA", "PORTUGAL", "PT",
+		},
+		Noise: []string{"LDA", "LDA.", "S.A.", "UNIPESSOAL"},
+	},
+	"ES": {
+		Code: "ES",
+		CardPrefixes: []string{
+			"COMPRA TARJ.", "COMPRA TARJETA", "COMPRA TARJ", "COMPRA EN", "COMPRA", "PAGO MOVIL EN",
+			"PAGO MOVIL", "PAGO EN", "PAGO", "TARJ.", "TARJETA", "TRANSFERENCIA", "TRANSF.",
+			"TRANSF", "RECIBO", "ADEUDO", "BIZUM", "REINTEGRO", "CAJERO",
+			"VISA", "MASTERCARD", "MAESTRO",
+		},
+		StoreMarkers: []string{"TIENDA", "TDA", "TDA.", "SUC", "SUC.", "SUCURSAL"},
+		CitySuffixes: []string{
+			"MADRID", "BARCELONA", "BCN", "VALENCIA", "SEVILLA", "ZARAGOZA", "MALAGA", "MURCIA",
+			"PALMA DE MALLORCA", "PALMA", "LAS PALMAS", "BILBAO", "ALICANTE", "VALLADOLID",
+			"VIGO", "GIJON", "A CORUNA", "LA CORUNA", "CORUNA", "GRANADA", "SAN SEBASTIAN",
+			"DONOSTIA", "SANTANDER", "ESPANA", "ESP", "ES",
+		},
+		Noise: []string{"S.L.", "S.L.U.", "S.A."},
+	},
+	"DE": {
+		Code: "DE",
+		CardPrefixes: []string{
+			"EC-KARTENZAHLUNG", "KARTENZAHLUNG", "GIROCARD", "SEPA-LASTSCHRIFT", "SEPA LASTSCHRIFT",
+			"LASTSCHRIFT", "SEPA-UEBERWEISUNG", "UEBERWEISUNG", "ÜBERWEISUNG", "DAUERAUFTRAG",
+			"KARTE", "ELV", "POS", "VISA", "MASTERCARD", "MAESTRO",
+		},
+		StoreMarkers: []string{"FIL", "FIL.", "FILIALE", "MARKT", "NR", "NR."},
+		CitySuffixes: []string{
+			"BERLIN", "HAMBURG", "MUENCHEN", "MÜNCHEN", "MUNCHEN", "KOELN", "KÖLN", "KOLN",
+			"FRANKFURT AM MAIN", "FRANKFURT", "STUTTGART", "DUESSELDORF", "DÜSSELDORF",
+			"DUSSELDORF", "LEIPZIG", "DORTMUND", "ESSEN", "BREMEN", "DRESDEN", "HANNOVER",
+			"NUERNBERG", "NÜRNBERG", "NURNBERG", "DEUTSCHLAND", "DEU", "DE",
+		},
+		Noise: []string{"SAGT DANKE", "GMBH", "GMBH & CO. KG", "GMBH & CO KG", "AG", "KG", "E.K."},
+	},
+}
+
+// LocalePackFor returns the built-in pack of a country code, e.g. "pt"
+func LocalePackFor(code string) (*LocalePack, bool) {
+	pack, ok := localePacks[strings.ToUpper(strings.TrimSpace(code))]
+	return pack, ok
+}
+
+// LocaleCodes returns the codes of the built-in packs, sorted
+func LocaleCodes() []string {
+	codes := make([]string, 0, len(localePacks))
+	for code := range localePacks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// DescriptorCleaner strips a locale pack's noise from bank descriptors, so
+// "COMPRA 1234 CONTINENTE MAI LISBOA" becomes "CONTINENTE"
+type DescriptorCleaner struct {
+	prefixes [][]string // Upper-cased words, longest phrase first
+	markers  map[string]bool
+	suffixes [][]string
+	noise    [][]string
+}
+
+// descriptorCleaners are the cleaners of the built-in packs, built once
+var descriptorCleaners = func() map[string]*DescriptorCleaner {
+	cleaners := make(map[string]*DescriptorCleaner, len(localePacks))
+	for code, pack := range localePacks {
+		cleaners[code] = NewDescriptorCleaner(pack)
+	}
+	return cleaners
+}()
+
+// DescriptorCleanerFor returns the cleaner of a built-in pack, or nil for an
+// empty or unknown code
+func DescriptorCleanerFor(code string) *DescriptorCleaner {
+	return descriptorCleaners[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// NewDescriptorCleaner creates a cleaner for a pack
+func NewDescriptorCleaner(pack *LocalePack) *DescriptorCleaner {
+	c := &DescriptorCleaner{
+		prefixes: phraseWords(pack.CardPrefixes),
+		markers:  make(map[string]bool, len(pack.StoreMarkers)),
+		suffixes: phraseWords(pack.CitySuffixes),
+		noise:    phraseWords(pack.Noise),
+	}
+	for _, marker := range pack.StoreMarkers {
+		c.markers[strings.ToUpper(marker)] = true
+	}
+	return c
+}
+
+// phraseWords splits phrases into upper-cased words, longest phrase first so
+// "COMPRA TARJ." is tried before "COMPRA"
+func phraseWords(phrases []string) [][]string {
+	words := make([][]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		if w := strings.Fields(strings.ToUpper(phrase)); len(w) > 0 {
+			words = append(words, w)
+		}
+	}
+	sort.SliceStable(words, func(i, j int) bool { return len(words[i]) > len(words[j]) })
+	return words
+}
+
+// Clean returns the merchant part of a descriptor. It removes, in order, the
+// leading card prefixes with the card numbers and dates after them, noise
+// phrases, store numbers and trailing cities, always keeping one word.
+func (c *DescriptorCleaner) Clean(raw string) string {
+	words := strings.Fields(raw)
+	upper := strings.Fields(strings.ToUpper(raw))
+	if len(words) == 0 {
+		return ""
+	}
+
+	// Leading prefixes, card numbers and dates, in any order
+	for len(upper) > 1 {
+		if n := matchPhraseAt(upper, 0, c.prefixes); n > 0 && n < len(upper) {
+			words, upper = words[n:], upper[n:]
+			continue
+		}
+		if isReferenceWord(upper[0]) {
+			words, upper = words[1:], upper[1:]
+			continue
+		}
+		break
+	}
+
+	// Noise phrases and store numbers anywhere
+	keptWords, keptUpper := make([]string, 0, len(words)), make([]string, 0, len(upper))
+	for i := 0; i < len(upper); {
+		if n := matchPhraseAt(upper, i, c.noise); n > 0 {
+			i += n
+			continue
+		}
+		if c.markers[upper[i]] && i+1 < len(upper) && isStoreNumber(upper[i+1]) {
+			i += 2
+			continue
+		}
+		if i > 0 && (isStoreNumber(upper[i]) || c.isMarkedNumber(upper[i])) {
+			i++
+			continue
+		}
+		keptWords, keptUpper = append(keptWords, words[i]), append(keptUpper, upper[i])
+		i++
+	}
+	if len(keptWords) > 0 {
+		words, upper = keptWords, keptUpper
+	}
+
+	// Trailing cities, abbreviations and countries
+	for len(upper) > 1 {
+		n := 0
+		for _, suffix := range c.suffixes {
+			if len(suffix) < len(upper) && matchPhraseAt(upper, len(upper)-len(suffix), [][]string{suffix}) > 0 {
+				n = len(suffix)
+				break
+			}
+		}
+		if n == 0 && isReferenceWord(upper[len(upper)-1]) {
+			n = 1
+		}
+		if n == 0 {
+			break
+		}
+		words, upper = words[:len(words)-n], upper[:len(upper)-n]
+	}
+
+	return strings.Join(words, " ")
+}
+
+// matchPhraseAt returns the number of words of the first phrase found at
+// words[i:], or 0
+func matchPhraseAt(words []string, i int, phrases [][]string) int {
+	for _, phrase := range phrases {
+		if i+len(phrase) > len(words) {
+			continue
+		}
+		matched := true
+		for j, w := range phrase {
+			if strings.TrimRight(words[i+j], ":,") != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return len(phrase)
+		}
+	}
+	return 0
+}
+
+// isMarkedNumber reports whether a word is a store marker glued to its
+// number, e.g. "LJ123" or "FIL.0452"
+func (c *DescriptorCleaner) isMarkedNumber(word string) bool {
+	for marker := range c.markers {
+		if rest, ok := strings.CutPrefix(word, marker); ok && isStoreNumber(strings.TrimPrefix(rest, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStoreNumber reports whether a word is a store number: 3 or more digits,
+// optionally after "#", "Nº" or "N."
+func isStoreNumber(word string) bool {
+	for _, p := range []string{"#", "Nº", "N°", "N.", "NO."} {
+		word = strings.TrimPrefix(word, p)
+	}
+	return len(word) >= 3 && isDigits(word)
+}
+
+// isReferenceWord reports whether a word is a card number, reference or date:
+// 4 or more digits, a masked card like "****1234" or "5402XXXX1234", or a date
+// like "12/01" or "12.01.2024"
+func isReferenceWord(word string) bool {
+	if len(word) >= 4 && isDigits(word) {
+		return true
+	}
+	if strings.ContainsAny(word, "*X") {
+		digits := strings.Map(func(r rune) rune {
+			if r == '*' || r == 'X' {
+				return -1
+			}
+			return r
+		}, word)
+		if len(digits) >= 4 && isDigits(digits) {
+			return true
+		}
+	}
+	parts := strings.FieldsFunc(word, func(r rune) bool { return r == '/' || r == '.' || r == '-' })
+	if len(parts) < 2 || len(parts) > 3 || len(strings.Join(parts, "")) == len(word) {
+		return false
+	}
+	for _, p := range parts {
+		if !isDigits(p) || len(p) > 4 {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,69 @@
+package normalizer
+
+import (
+	"encoding/csv"
+	"os"
+	"testing"
+)
+
+// TestDescriptorCleaner_Corpus runs the cleaner over real-world descriptors
+// from testdata/descriptors.csv: locale, descriptor, expected merchant
+func TestDescriptorCleaner_Corpus(t *testing.T) {
+	f, err := os.Open("testdata/descriptors.csv")
+	if err != nil {
+		t.Fatalf("failed to open corpus: %v", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+
+	for _, record := range records {
+		locale, descriptor, want := record[0], record[1], record[2]
+		t.Run(locale+"/"+descriptor, func(t *testing.T) {
+			cleaner := DescriptorCleanerFor(locale)
+			if cleaner == nil {
+				t.Fatalf("no locale pack for %q", locale)
+			}
+			if got := cleaner.Clean(descriptor); got != want {
+				t.Errorf("Clean(%q) = %q, want %q", descriptor, got, want)
+			}
+		})
+	}
+}
+
+func TestDescriptorCleaner_KeepsOneWord(t *testing.T) {
+	cleaner := DescriptorCleanerFor("pt")
+	tests := map[string]string{
+		"":                           "",
+		"COMPRA":                     "COMPRA",
+		"LISBOA":                     "LISBOA",
+		"Continente":                 "Continente",
+		"compra 1234 continente mai": "continente",
+	}
+	for input, want := range tests {
+		if got := cleaner.Clean(input); got != want {
+			t.Errorf("Clean(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDescriptorCleanerFor_Unknown(t *testing.T) {
+	if DescriptorCleanerFor("") != nil || DescriptorCleanerFor("XX") != nil {
+		t.Fatal("expected no cleaner for empty and unknown locales")
+	}
+	if codes := LocaleCodes(); len(codes) != 3 || codes[0] != "DE" || codes[1] != "ES" || codes[2] != "PT" {
+		t.Fatalf("unexpected locale codes %v", codes)
+	}
+}
+
+func TestMerchantSanitizer_WithLocale(t *testing.T) {
+	info := NewMerchantSanitizer().WithLocale("PT").Sanitize("COMPRA 1234 PADARIA PORTUGUESA MAI LISBOA")
+	if info.NormalizedName != "Padaria Portuguesa" {
+		t.Fatalf("expected the locale pack to clean the descriptor, got %q", info.NormalizedName)
+	}
+}
@@ -26,6 +26,7 @@ type MerchantPattern struct {
 // MerchantSanitizer normalizes merchant names and detects categories
 type MerchantSanitizer struct {
 	patterns []MerchantPattern
+	cleaner  *DescriptorCleaner // Optional: the institution's locale pack
 }
 
 // NewMerchantSanitizer creates a new sanitizer with common merchant patterns
@@ -35,6 +36,13 @@ func NewMerchantSanitizer() *MerchantSanitizer {
 	}
 }
 
+// WithLocale cleans descriptors with a built-in locale pack (e.g. "PT")
+// before matching. Unknown codes leave the sanitizer as it is.
+func (s *MerchantSanitizer) WithLocale(code string) *MerchantSanitizer {
+	s.cleaner = DescriptorCleanerFor(code)
+	return s
+}
+
 // Sanitize normalizes a merchant name and detects its category
 func (s *MerchantSanitizer) Sanitize(rawMerchant string) MerchantInfo {
 	result := MerchantInfo{
@@ -43,7 +51,11 @@ func (s *MerchantSanitizer) Sanitize(rawMerchant string) MerchantInfo {
 	}
 
 	// Clean the input
-	cleaned := cleanMerchantName(rawMerchant)
+	cleaned := rawMerchant
+	if s.cleaner != nil {
+		cleaned = s.cleaner.Clean(cleaned)
+	}
+	cleaned = cleanMerchantName(cleaned)
 	result.NormalizedName = cleaned
 
 	// Try to match against known patterns
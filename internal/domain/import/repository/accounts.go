@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// AccountRef names one of a user's accounts
+type AccountRef struct {
+	ID           uuid.UUID
+	Name         string
+	CurrencyCode string
+}
+
+// AccountRepository defines lookups of a user's accounts
+type AccountRepository interface {
+	// ListActiveAccounts lists the user's active accounts by name
+	ListActiveAccounts(ctx context.Context, userID uuid.UUID) ([]AccountRef, error)
+}
+
+// ListActiveAccounts lists the user's active accounts by name
+func (r *PostgresImportRepository) ListActiveAccounts(ctx context.Context, userID uuid.UUID) ([]AccountRef, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name::TEXT, currency_code
+		FROM accounts
+		WHERE user_id = $1 AND is_active
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []AccountRef
+	for rows.Next() {
+		var a AccountRef
+		if err := rows.Scan(&a.ID, &a.Name, &a.CurrencyCode); err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
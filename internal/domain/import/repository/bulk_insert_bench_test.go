@@ -0,0 +1,99 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Compare import throughput of COPY through a staging table and multi-row
+// INSERT against a migrated database:
+//
+//	TEST_DATABASE_URL=... go test -tags integration -run '^$' -bench BulkInsert ./internal/domain/import/repository/
+
+// benchImport creates a user with an import job to import into and removes
+// them, with their transactions, once the benchmark is done
+func benchImport(b *testing.B) (*PostgresImportRepository, uuid.UUID, uuid.UUID) {
+	b.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		b.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		b.Fatalf("failed to connect: %v", err)
+	}
+	b.Cleanup(pool.Close)
+
+	var userID, fileID, jobID uuid.UUID
+	err = pool.QueryRow(ctx, `INSERT INTO users (email) VALUES ($1) RETURNING id`,
+		fmt.Sprintf("bench-%s@example.com", uuid.NewString())).Scan(&userID)
+	if err != nil {
+		b.Fatalf("failed to create user: %v", err)
+	}
+	b.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID) })
+	err = pool.QueryRow(ctx, `
+		INSERT INTO user_files (user_id, type, mime_type, file_name, size_bytes)
+		VALUES ($1, 'csv', 'text/csv', 'bench.csv', 1) RETURNING id
+	`, userID).Scan(&fileID)
+	if err != nil {
+		b.Fatalf("failed to create file: %v", err)
+	}
+	err = pool.QueryRow(ctx, `
+		INSERT INTO import_jobs (user_id, file_id, kind, status) VALUES ($1, $2, 'transactions', 'running') RETURNING id
+	`, userID, fileID).Scan(&jobID)
+	if err != nil {
+		b.Fatalf("failed to create import job: %v", err)
+	}
+	return NewPostgresImportRepository(pool), userID, jobID
+}
+
+// benchRows returns n distinct transactions; run makes them unique across
+// iterations so every row is inserted
+func benchRows(userID, jobID uuid.UUID, run, n int) [][]any {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([][]any, n)
+	for i := range rows {
+		tx := &ParsedTransaction{
+			Date:        start.Add(time.Duration(i) * time.Minute),
+			Description: fmt.Sprintf("CARD PAYMENT %d-%d", run, i),
+			AmountCents: -int64(100 + i%5000),
+		}
+		rows[i] = importRow(userID, nil, "EUR", jobID, nil, tx)
+	}
+	return rows
+}
+
+func benchmarkBulkInsert(b *testing.B, batchSize int, insert func(*PostgresImportRepository, context.Context, [][]any) (int, error)) {
+	repo, userID, jobID := benchImport(b)
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		rows := benchRows(userID, jobID, i, batchSize)
+		b.StartTimer()
+		if _, err := insert(repo, ctx, rows); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "rows/s")
+}
+
+func BenchmarkBulkInsert(b *testing.B) {
+	for _, batchSize := range []int{100, 500, 2000} {
+		b.Run(fmt.Sprintf("copy/%d", batchSize), func(b *testing.B) {
+			benchmarkBulkInsert(b, batchSize, (*PostgresImportRepository).copyTransactionRows)
+		})
+		b.Run(fmt.Sprintf("insert/%d", batchSize), func(b *testing.B) {
+			benchmarkBulkInsert(b, batchSize, (*PostgresImportRepository).insertTransactionRows)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a transaction's location came from
+const (
+	LocationSourceAggregator = "aggregator" // Sent with the transaction by a bank data aggregator
+	LocationSourceManual     = "manual"     // Pinned by the user
+)
+
+// TransactionLocation is where a transaction took place. Coordinates and the
+// place name are each optional, but a location has at least one of them.
+type TransactionLocation struct {
+	Latitude  *float64
+	Longitude *float64
+	PlaceName *string
+	Source    string // LocationSourceAggregator or LocationSourceManual
+}
+
+// LocationCluster is the spending at one spot on the map: located expenses
+// whose coordinates round to the same cell
+type LocationCluster struct {
+	Latitude     float64 // Centroid of the cell's transactions
+	Longitude    float64
+	PlaceName    *string // Most common place name in the cell, if any
+	CurrencyCode string
+	TotalMinor   int64 // Absolute value of spending
+	Count        int
+}
+
+// LocationRepository defines data access for transaction locations
+type LocationRepository interface {
+	// UpdateTransactionLocation sets a transaction's location, or clears it when
+	// loc is nil. Returns sql.ErrNoRows if the transaction isn't the user's.
+	UpdateTransactionLocation(ctx context.Context, userID, transactionID uuid.UUID, loc *TransactionLocation) error
+	// GetSpendingByLocation totals located expenses posted in [startDate,
+	// endDate) by cells of coordinates rounded to precision decimal places,
+	// largest first
+	GetSpendingByLocation(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, precision, limit int) ([]LocationCluster, error)
+}
+
+// UpdateTransactionLocation sets or clears a transaction's location
+func (r *PostgresImportRepository) UpdateTransactionLocation(ctx context.Context, userID, transactionID uuid.UUID, loc *TransactionLocation) error {
+	if loc == nil {
+		loc = &TransactionLocation{}
+	}
+	var source *string
+	if loc.Source != "" {
+		source = &loc.Source
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE transactions
+		SET latitude = $3, longitude = $4, place_name = $5, location_source = $6, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, transactionID, userID, loc.Latitude, loc.Longitude, loc.PlaceName, source)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction location: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSpendingByLocation aggregates located expenses into map cells. Amounts
+// are only summed within a currency, so a cell visited in two currencies
+// appears twice.
+func (r *PostgresImportRepository) GetSpendingByLocation(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, precision, limit int) ([]LocationCluster, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT AVG(t.latitude), AVG(t.longitude),
+		       MODE() WITHIN GROUP (ORDER BY t.place_name),
+		       t.currency_code, SUM(-t.amount_minor)::BIGINT, COUNT(*)
+		FROM transactions t
+		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
+		  AND t.posted_at >= $2
+		  AND t.posted_at < $3
+		  AND t.latitude IS NOT NULL
+		  AND t.amount_minor < 0
+		GROUP BY ROUND(t.latitude::NUMERIC, $4), ROUND(t.longitude::NUMERIC, $4), t.currency_code
+		ORDER BY SUM(-t.amount_minor) DESC
+		LIMIT $5
+	`, userID, startDate, endDate, precision, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending by location: %w", err)
+	}
+	defer rows.Close()
+
+	var clusters []LocationCluster
+	for rows.Next() {
+		var c LocationCluster
+		if err := rows.Scan(&c.Latitude, &c.Longitude, &c.PlaceName, &c.CurrencyCode, &c.TotalMinor, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan location cluster: %w", err)
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, rows.Err()
+}
@@ -5,21 +5,35 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
 )
 
 // PostgresImportRepository implements ImportRepository using PostgreSQL
 type PostgresImportRepository struct {
 	pool *pgxpool.Pool
+	read *pgxpool.Pool // Runs transaction searches; pool unless a replica is set
 }
 
 // NewPostgresImportRepository creates a new PostgreSQL-backed import repository
 func NewPostgresImportRepository(pool *pgxpool.Pool) *PostgresImportRepository {
-	return &PostgresImportRepository{pool: pool}
+	return &PostgresImportRepository{pool: pool, read: pool}
+}
+
+// WithReadPool runs transaction searches against pool, typically a read
+// replica. Plain listings stay on the primary so a list right after an
+// import or edit sees it. A nil pool is ignored.
+func (r *PostgresImportRepository) WithReadPool(pool *pgxpool.Pool) *PostgresImportRepository {
+	if pool != nil {
+		r.read = pool
+	}
+	return r
 }
 
 // GetMappingByFingerprint looks up a bank mapping by its fingerprint
@@ -28,7 +42,7 @@ func (r *PostgresImportRepository) GetMappingByFingerprint(ctx context.Context,
 	query := `
 		SELECT id, user_id, fingerprint, bank_name, delimiter, skip_lines, date_format,
 		       date_col, desc_col, category_col, amount_col, debit_col, credit_col,
-		       is_european_format, created_at, updated_at
+		       is_european_format, locale, created_at, updated_at
 		FROM bank_mappings
 		WHERE fingerprint = $1 AND (user_id = $2 OR user_id IS NULL)
 		ORDER BY user_id NULLS LAST
@@ -41,7 +55,7 @@ func (r *PostgresImportRepository) GetMappingByFingerprint(ctx context.Context,
 		&mapping.Delimiter, &mapping.SkipLines, &mapping.DateFormat,
 		&mapping.DateCol, &mapping.DescCol, &mapping.CategoryCol,
 		&mapping.AmountCol, &mapping.DebitCol, &mapping.CreditCol,
-		&mapping.IsEuropeanFormat, &mapping.CreatedAt, &mapping.UpdatedAt,
+		&mapping.IsEuropeanFormat, &mapping.Locale, &mapping.CreatedAt, &mapping.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -63,8 +77,8 @@ func (r *PostgresImportRepository) CreateMapping(ctx context.Context, mapping *B
 		INSERT INTO bank_mappings (
 			id, user_id, fingerprint, bank_name, delimiter, skip_lines, date_format,
 			date_col, desc_col, category_col, amount_col, debit_col, credit_col,
-			is_european_format
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			is_european_format, locale
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -72,7 +86,7 @@ func (r *PostgresImportRepository) CreateMapping(ctx context.Context, mapping *B
 		mapping.Delimiter, mapping.SkipLines, mapping.DateFormat,
 		mapping.DateCol, mapping.DescCol, mapping.CategoryCol,
 		mapping.AmountCol, mapping.DebitCol, mapping.CreditCol,
-		mapping.IsEuropeanFormat,
+		mapping.IsEuropeanFormat, mapping.Locale,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create bank mapping: %w", err)
@@ -87,7 +101,7 @@ func (r *PostgresImportRepository) UpdateMapping(ctx context.Context, mapping *B
 		UPDATE bank_mappings SET
 			bank_name = $2, delimiter = $3, skip_lines = $4, date_format = $5,
 			date_col = $6, desc_col = $7, category_col = $8, amount_col = $9,
-			debit_col = $10, credit_col = $11, is_european_format = $12,
+			debit_col = $10, credit_col = $11, is_european_format = $12, locale = $13,
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -95,7 +109,7 @@ func (r *PostgresImportRepository) UpdateMapping(ctx context.Context, mapping *B
 	_, err := r.pool.Exec(ctx, query,
 		mapping.ID, mapping.BankName, mapping.Delimiter, mapping.SkipLines, mapping.DateFormat,
 		mapping.DateCol, mapping.DescCol, mapping.CategoryCol, mapping.AmountCol,
-		mapping.DebitCol, mapping.CreditCol, mapping.IsEuropeanFormat,
+		mapping.DebitCol, mapping.CreditCol, mapping.IsEuropeanFormat, mapping.Locale,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update bank mapping: %w", err)
@@ -109,7 +123,7 @@ func (r *PostgresImportRepository) ListUserMappings(ctx context.Context, userID
 	query := `
 		SELECT id, user_id, fingerprint, bank_name, delimiter, skip_lines, date_format,
 		       date_col, desc_col, category_col, amount_col, debit_col, credit_col,
-		       is_european_format, created_at, updated_at
+		       is_european_format, locale, created_at, updated_at
 		FROM bank_mappings
 		WHERE user_id = $1 OR user_id IS NULL
 		ORDER BY created_at DESC
@@ -129,7 +143,7 @@ func (r *PostgresImportRepository) ListUserMappings(ctx context.Context, userID
 			&m.Delimiter, &m.SkipLines, &m.DateFormat,
 			&m.DateCol, &m.DescCol, &m.CategoryCol,
 			&m.AmountCol, &m.DebitCol, &m.CreditCol,
-			&m.IsEuropeanFormat, &m.CreatedAt, &m.UpdatedAt,
+			&m.IsEuropeanFormat, &m.Locale, &m.CreatedAt, &m.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bank mapping: %w", err)
@@ -165,15 +179,23 @@ func (r *PostgresImportRepository) CreateUserFile(ctx context.Context, file *Use
 	if file.ID == uuid.Nil {
 		file.ID = uuid.New()
 	}
+	if file.Purpose == "" {
+		file.Purpose = FilePurposeOther
+	}
+	if file.ScanStatus == "" {
+		file.ScanStatus = ScanPending
+	}
 
 	query := `
-		INSERT INTO user_files (id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO user_files (id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url, purpose,
+			scan_status, scan_detail, scanned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
 		file.ID, file.UserID, file.Type, file.MimeType, file.FileName,
-		file.SizeBytes, file.ChecksumSHA256, file.StorageURL,
+		file.SizeBytes, file.ChecksumSHA256, file.StorageURL, file.Purpose,
+		file.ScanStatus, file.ScanDetail, file.ScannedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create user file: %w", err)
@@ -185,14 +207,16 @@ func (r *PostgresImportRepository) CreateUserFile(ctx context.Context, file *Use
 // GetUserFileByID retrieves a user file by ID
 func (r *PostgresImportRepository) GetUserFileByID(ctx context.Context, id uuid.UUID) (*UserFile, error) {
 	query := `
-		SELECT id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url, created_at
+		SELECT id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url,
+		       purpose, purged_at, scan_status, scan_detail, scanned_at, created_at
 		FROM user_files WHERE id = $1
 	`
 
 	var file UserFile
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&file.ID, &file.UserID, &file.Type, &file.MimeType, &file.FileName,
-		&file.SizeBytes, &file.ChecksumSHA256, &file.StorageURL, &file.CreatedAt,
+		&file.SizeBytes, &file.ChecksumSHA256, &file.StorageURL,
+		&file.Purpose, &file.PurgedAt, &file.ScanStatus, &file.ScanDetail, &file.ScannedAt, &file.CreatedAt,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -252,6 +276,70 @@ func (r *PostgresImportRepository) GetImportJobByID(ctx context.Context, id uuid
 	return &job, nil
 }
 
+// ListImportJobs lists a user's import jobs, newest first, with the total count
+func (r *PostgresImportRepository) ListImportJobs(ctx context.Context, userID uuid.UUID, filter ListImportJobsFilter) ([]*ImportJob, int64, error) {
+	args := []any{userID}
+	whereClauses := []string{"user_id = $1"}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		whereClauses = append(whereClauses, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		whereClauses = append(whereClauses, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	whereSQL := "WHERE " + joinStrings(whereClauses, " AND ")
+
+	var totalCount int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM import_jobs `+whereSQL, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count import jobs: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, file_id, kind, status, account_id, timezone, date_format,
+		       error_message, rows_total, rows_imported, rows_failed,
+		       requested_at, started_at, finished_at
+		FROM import_jobs
+		%s
+		ORDER BY requested_at DESC, id
+		LIMIT %d OFFSET %d
+	`, whereSQL, limit, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*ImportJob
+	for rows.Next() {
+		var job ImportJob
+		if err := rows.Scan(
+			&job.ID, &job.UserID, &job.FileID, &job.Kind, &job.Status,
+			&job.AccountID, &job.Timezone, &job.DateFormat,
+			&job.ErrorMessage, &job.RowsTotal, &job.RowsImported, &job.RowsFailed,
+			&job.RequestedAt, &job.StartedAt, &job.FinishedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan import job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating import jobs: %w", err)
+	}
+
+	return jobs, totalCount, nil
+}
+
 // GetImportJobStats retrieves aggregated statistics for an import job
 func (r *PostgresImportRepository) GetImportJobStats(ctx context.Context, importJobID uuid.UUID) (*ImportJobStats, error) {
 	query := `
@@ -264,7 +352,7 @@ func (r *PostgresImportRepository) GetImportJobStats(ctx context.Context, import
 			MAX(posted_at) as latest_date,
 			COUNT(*) FILTER (WHERE category_id IS NULL) as uncategorized_count
 		FROM transactions
-		WHERE import_job_id = $1
+		WHERE import_job_id = $1 AND deleted_at IS NULL
 	`
 
 	var stats ImportJobStats
@@ -327,91 +415,173 @@ func (r *PostgresImportRepository) FinishImportJob(ctx context.Context, id uuid.
 	return nil
 }
 
-// BulkInsertTransactions inserts multiple transactions, skipping duplicates
+// importColumns are the transaction columns an import fills, in the order of
+// importRow's values
+var importColumns = []string{
+	"id", "user_id", "account_id", "posted_at", "description", "original_description", "merchant_name",
+	"amount_minor", "currency_code", "source", "external_id", "import_job_id", "institution_name",
+	"category_id", "auto_category_id", "categorized_by_rule_id", "categorized_by_merchant_id",
+	"latitude", "longitude", "place_name", "location_source", "tags",
+}
+
+// importConflictClause is the reconciliation loop: if a duplicate is found
+// (same external ID, or date, description and amount), merge instead of
+// inserting:
+// - Keep the existing category_id if it was manually set
+// - Update source and external_id to link with bank record
+// Other duplicates are skipped.
+const importConflictClause = ` ON CONFLICT (user_id, source, external_id) WHERE external_id IS NOT NULL
+	DO UPDATE SET
+		import_job_id = EXCLUDED.import_job_id,
+		institution_name = COALESCE(EXCLUDED.institution_name, transactions.institution_name),
+		merchant_name = COALESCE(EXCLUDED.merchant_name, transactions.merchant_name),
+		deleted_at = NULL,
+		updated_at = NOW()
+	WHERE transactions.source = 'manual' OR transactions.category_id IS NULL
+		OR transactions.deleted_at IS NOT NULL`
+
+// copyMinRows is the batch size from which COPY into a staging table beats a
+// multi-row INSERT; below it the staging table costs more than it saves
+const copyMinRows = 100
+
+// BulkInsertTransactions inserts multiple transactions, skipping duplicates.
+// Large batches are streamed with COPY into a temporary staging table and
+// merged from there, since COPY doesn't support ON CONFLICT; small ones use
+// a multi-row INSERT.
 func (r *PostgresImportRepository) BulkInsertTransactions(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, currencyCode string, importJobID uuid.UUID, institutionName string, txs []*ParsedTransaction) (int, error) {
 	if len(txs) == 0 {
 		return 0, nil
 	}
 
-	// Use batch INSERT with ON CONFLICT DO NOTHING to handle duplicates gracefully
-	// COPY doesn't support ON CONFLICT, so we use batched inserts
-	const batchSize = 100
-	totalInserted := 0
-
 	// Handle nullable institution name
 	var instNamePtr *string
 	if institutionName != "" {
 		instNamePtr = &institutionName
 	}
 
-	for i := 0; i < len(txs); i += batchSize {
-		end := i + batchSize
-		if end > len(txs) {
-			end = len(txs)
-		}
-		batch := txs[i:end]
+	rows := make([][]any, len(txs))
+	for i, tx := range txs {
+		rows[i] = importRow(userID, accountID, currencyCode, importJobID, instNamePtr, tx)
+	}
+	if len(rows) < copyMinRows {
+		return r.insertTransactionRows(ctx, rows)
+	}
+	return r.copyTransactionRows(ctx, rows)
+}
+
+// importRow returns the values of importColumns for an imported transaction
+func importRow(userID uuid.UUID, accountID *uuid.UUID, currencyCode string, importJobID uuid.UUID, institutionName *string, tx *ParsedTransaction) []any {
+	// Use MerchantName if set, otherwise fall back to Description
+	merchantName := tx.MerchantName
+	if merchantName == "" {
+		merchantName = tx.Description
+	}
+
+	loc := tx.Location
+	if loc == nil {
+		loc = &TransactionLocation{}
+	}
+	var locationSource *string
+	if loc.Source != "" {
+		locationSource = &loc.Source
+	}
+	tags := tx.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return []any{
+		uuid.New(),             // id
+		userID,                 // user_id
+		accountID,              // account_id
+		tx.Date,                // posted_at
+		tx.Description,         // description (raw)
+		tx.Description,         // original_description
+		merchantName,           // merchant_name (cleaned)
+		tx.AmountCents,         // amount_minor
+		currencyCode,           // currency_code
+		"csv",                  // source
+		generateExternalID(tx), // external_id
+		importJobID,            // import_job_id
+		institutionName,        // institution_name
+		tx.CategoryID,          // category_id
+		tx.AutoCategoryID,      // auto_category_id
+		tx.RuleID,              // categorized_by_rule_id
+		tx.MerchantID,          // categorized_by_merchant_id
+		loc.Latitude,           // latitude
+		loc.Longitude,          // longitude
+		loc.PlaceName,          // place_name
+		locationSource,         // location_source
+		tags,                   // tags
+	}
+}
 
-		// Build batch insert query (14 columns now including merchant_name and category_id)
-		query := `
-			INSERT INTO transactions (id, user_id, account_id, posted_at, description, original_description, merchant_name, amount_minor, currency_code, source, external_id, import_job_id, institution_name, category_id)
-			VALUES `
+// insertTransactionRows merges rows with a multi-row INSERT
+func (r *PostgresImportRepository) insertTransactionRows(ctx context.Context, rows [][]any) (int, error) {
+	var query strings.Builder
+	query.WriteString("INSERT INTO transactions (" + strings.Join(importColumns, ", ") + ") VALUES ")
 
-		args := make([]any, 0, len(batch)*14)
-		for j, tx := range batch {
+	args := make([]any, 0, len(rows)*len(importColumns))
+	for i, row := range rows {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for j := range row {
 			if j > 0 {
-				query += ", "
-			}
-			externalID := generateExternalID(tx)
-			argOffset := j * 14
-			query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-				argOffset+1, argOffset+2, argOffset+3, argOffset+4, argOffset+5,
-				argOffset+6, argOffset+7, argOffset+8, argOffset+9, argOffset+10,
-				argOffset+11, argOffset+12, argOffset+13, argOffset+14)
-
-			// Use MerchantName if set, otherwise fall back to Description
-			merchantName := tx.MerchantName
-			if merchantName == "" {
-				merchantName = tx.Description
+				query.WriteString(", ")
 			}
-
-			args = append(args,
-				uuid.New(),     // id
-				userID,         // user_id
-				accountID,      // account_id
-				tx.Date,        // posted_at
-				tx.Description, // description (raw)
-				tx.Description, // original_description
-				merchantName,   // merchant_name (cleaned)
-				tx.AmountCents, // amount_minor
-				currencyCode,   // currency_code
-				"csv",          // source
-				externalID,     // external_id
-				importJobID,    // import_job_id
-				instNamePtr,    // institution_name
-				tx.CategoryID,  // category_id
-			)
+			fmt.Fprintf(&query, "$%d", len(args)+j+1)
 		}
+		query.WriteString(")")
+		args = append(args, row...)
+	}
+	query.WriteString(importConflictClause)
 
-		// ON CONFLICT: Reconciliation Loop
-		// If a duplicate is found (same date+description+amount), merge:
-		// - Keep the existing category_id if it was manually set
-		// - Update source and external_id to link with bank record
-		query += ` ON CONFLICT (user_id, source, external_id) WHERE external_id IS NOT NULL 
-		           DO UPDATE SET 
-		             import_job_id = EXCLUDED.import_job_id,
-		             institution_name = COALESCE(EXCLUDED.institution_name, transactions.institution_name),
-		             merchant_name = COALESCE(EXCLUDED.merchant_name, transactions.merchant_name),
-		             updated_at = NOW()
-		           WHERE transactions.source = 'manual' OR transactions.category_id IS NULL`
-
-		result, err := r.pool.Exec(ctx, query, args...)
-		if err != nil {
-			return totalInserted, fmt.Errorf("failed to insert transactions batch: %w", err)
-		}
-		totalInserted += int(result.RowsAffected())
+	result, err := r.pool.Exec(ctx, query.String(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert transactions batch: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// copyTransactionRows streams rows into a staging table with COPY and merges
+// them into transactions in one statement. Rows repeating an external ID
+// within the batch are merged once.
+func (r *PostgresImportRepository) copyTransactionRows(ctx context.Context, rows [][]any) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	// Merging a large file can outlast the pool's statement timeout
+	if err := db.LiftStatementTimeout(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	columns := strings.Join(importColumns, ", ")
+	_, err = tx.Exec(ctx, `CREATE TEMP TABLE import_staging ON COMMIT DROP AS
+		SELECT `+columns+` FROM transactions WITH NO DATA`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create import staging table: %w", err)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_staging"}, importColumns, pgx.CopyFromRows(rows)); err != nil {
+		return 0, fmt.Errorf("failed to copy transactions batch: %w", err)
 	}
 
-	return totalInserted, nil
+	result, err := tx.Exec(ctx, `
+		INSERT INTO transactions (`+columns+`)
+		SELECT `+columns+` FROM (
+			SELECT DISTINCT ON (external_id) * FROM import_staging ORDER BY external_id
+		) staged`+importConflictClause)
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge transactions batch: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transactions batch: %w", err)
+	}
+	return int(result.RowsAffected()), nil
 }
 
 // InsertTransaction inserts a single transaction (for manual entry via Quick Capture)
@@ -428,8 +598,11 @@ func (r *PostgresImportRepository) InsertTransaction(ctx context.Context, tx *Tr
 		INSERT INTO transactions (
 			id, user_id, account_id, category_id, amount_minor, currency_code,
 			posted_at, description, original_description, merchant_name,
-			source, external_id, notes, institution_name, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			source, external_id, notes, institution_name, created_at, updated_at,
+			auto_category_id, categorized_by_rule_id, categorized_by_merchant_id,
+			latitude, longitude, place_name, location_source, tags
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23,
+			COALESCE($24::TEXT[], '{}'))
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -449,6 +622,14 @@ func (r *PostgresImportRepository) InsertTransaction(ctx context.Context, tx *Tr
 		tx.InstitutionName,
 		tx.CreatedAt,
 		tx.UpdatedAt,
+		tx.AutoCategoryID,
+		tx.RuleID,
+		tx.MerchantID,
+		tx.Latitude,
+		tx.Longitude,
+		tx.PlaceName,
+		tx.LocationSource,
+		tx.Tags,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert transaction: %w", err)
@@ -457,8 +638,12 @@ func (r *PostgresImportRepository) InsertTransaction(ctx context.Context, tx *Tr
 	return nil
 }
 
-// generateExternalID creates a unique identifier for deduplication
+// generateExternalID creates a unique identifier for deduplication. An ID
+// supplied by the source is kept, namespaced so it can't collide with hashes.
 func generateExternalID(tx *ParsedTransaction) string {
+	if tx.ExternalID != "" {
+		return "ext:" + tx.ExternalID
+	}
 	data := fmt.Sprintf("%s|%s|%d", tx.Date.Format(time.RFC3339), tx.Description, tx.AmountCents)
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:16]) // First 16 bytes for reasonable length
@@ -470,6 +655,13 @@ func (r *PostgresImportRepository) ListTransactions(ctx context.Context, userID
 	args := []any{userID}
 	argIdx := 2
 	whereClauses := []string{"t.user_id = $1"}
+	if filter.IncludeHousehold {
+		whereClauses[0] = `(t.user_id = $1 OR t.account_id IN (
+			SELECT a.id FROM accounts a
+			JOIN household_members m ON m.household_id = a.household_id
+			WHERE m.user_id = $1))`
+	}
+	whereClauses = append(whereClauses, "t.deleted_at IS NULL")
 
 	if filter.AccountID != nil {
 		whereClauses = append(whereClauses, fmt.Sprintf("t.account_id = $%d", argIdx))
@@ -509,10 +701,15 @@ func (r *PostgresImportRepository) ListTransactions(ctx context.Context, userID
 
 	whereSQL := "WHERE " + fmt.Sprintf("%s", joinStrings(whereClauses, " AND "))
 
+	pool := r.pool
+	if filter.Search != "" {
+		pool = r.read
+	}
+
 	// Get total count
 	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM transactions t %s`, whereSQL)
 	var totalCount int64
-	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
+	if err := pool.QueryRow(ctx, countQuery, args...).Scan(&totalCount); err != nil {
 		return nil, 0, fmt.Errorf("failed to count transactions: %w", err)
 	}
 
@@ -530,15 +727,17 @@ func (r *PostgresImportRepository) ListTransactions(ctx context.Context, userID
 		SELECT t.id, t.user_id, t.account_id, t.category_id, c.name as category_name,
 		       t.posted_at, t.description, t.merchant_name, t.original_description,
 		       t.amount_minor, t.currency_code, t.source,
-		       t.external_id, t.notes, t.institution_name, t.created_at, t.updated_at
+		       t.external_id, t.notes, t.institution_name,
+		       t.latitude, t.longitude, t.place_name, t.location_source, t.tags,
+		       t.created_at, t.updated_at
 		FROM transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
 		%s
-		ORDER BY t.posted_at DESC
+		ORDER BY t.posted_at DESC, t.id
 		LIMIT %d OFFSET %d
 	`, whereSQL, limit, offset)
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	rows, err := pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list transactions: %w", err)
 	}
@@ -551,7 +750,9 @@ func (r *PostgresImportRepository) ListTransactions(ctx context.Context, userID
 			&tx.ID, &tx.UserID, &tx.AccountID, &tx.CategoryID, &tx.CategoryName,
 			&tx.Date, &tx.Description, &tx.MerchantName, &tx.OriginalDescription,
 			&tx.AmountCents, &tx.CurrencyCode, &tx.Source,
-			&tx.ExternalID, &tx.Notes, &tx.InstitutionName, &tx.CreatedAt, &tx.UpdatedAt,
+			&tx.ExternalID, &tx.Notes, &tx.InstitutionName,
+			&tx.Latitude, &tx.Longitude, &tx.PlaceName, &tx.LocationSource, &tx.Tags,
+			&tx.CreatedAt, &tx.UpdatedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan transaction: %w", err)
 		}
@@ -573,9 +774,13 @@ func joinStrings(strs []string, sep string) string {
 	return result
 }
 
-// DeleteByImportJobID deletes all transactions from a specific import batch
+// DeleteByImportJobID moves all transactions from a specific import batch to
+// the trash. They are purged for good once they have been there for the
+// retention period.
 func (r *PostgresImportRepository) DeleteByImportJobID(ctx context.Context, userID uuid.UUID, importJobID uuid.UUID) (int, error) {
-	query := `DELETE FROM transactions WHERE user_id = $1 AND import_job_id = $2`
+	query := `
+		UPDATE transactions SET deleted_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND import_job_id = $2 AND deleted_at IS NULL`
 	result, err := r.pool.Exec(ctx, query, userID, importJobID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete transactions by import job: %w", err)
@@ -586,19 +791,69 @@ func (r *PostgresImportRepository) DeleteByImportJobID(ctx context.Context, user
 // GetCategoryTotals aggregates spending by category for a date range
 // Used for computing plan actuals from transaction data
 func (r *PostgresImportRepository) GetCategoryTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]CategoryTotal, error) {
+	// Installment purchases are budgeted by their scheduled charges rather than
+	// the original purchase, and charge transactions matched to a schedule are
+	// skipped so they aren't counted twice. Rewards attributed to a category
+	// reduce its net spend. Other inflows (refunds, reimbursements) are totalled
+	// separately for plan items that expect them.
 	query := `
-		SELECT 
-			t.category_id,
-			COALESCE(c.name, t.category, 'Uncategorized') AS category_name,
-			ABS(SUM(t.amount_minor)) AS total_minor,
-			COUNT(*) AS tx_count
-		FROM transactions t
-		LEFT JOIN categories c ON t.category_id = c.id
-		WHERE t.user_id = $1
-		  AND t.posted_at >= $2
-		  AND t.posted_at < $3
-		  AND t.amount_minor < 0  -- Only expenses (negative amounts)
-		GROUP BY t.category_id, COALESCE(c.name, t.category, 'Uncategorized')
+		WITH spend AS (
+			SELECT t.category_id, COALESCE(c.name, 'Uncategorized') AS category_name, t.amount_minor, 0::BIGINT AS inflow_minor
+			FROM transactions t
+			LEFT JOIN categories c ON t.category_id = c.id
+			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
+			  AND t.posted_at >= $2
+			  AND t.posted_at < $3
+			  AND t.amount_minor < 0  -- Only expenses (negative amounts)
+			  AND NOT EXISTS (
+			      SELECT 1 FROM installment_plans ip
+			      WHERE ip.transaction_id = t.id AND ip.status <> 'canceled'
+			  )
+			  AND NOT EXISTS (
+			      SELECT 1 FROM installment_charges ic
+			      JOIN installment_plans ip ON ip.id = ic.plan_id
+			      WHERE ic.transaction_id = t.id AND ip.status <> 'canceled'
+			  )
+			UNION ALL
+			SELECT COALESCE(ip.category_id, t.category_id), COALESCE(c.name, 'Uncategorized'), -ic.amount_minor, 0
+			FROM installment_charges ic
+			JOIN installment_plans ip ON ip.id = ic.plan_id
+			LEFT JOIN transactions t ON t.id = ip.transaction_id
+			LEFT JOIN categories c ON c.id = COALESCE(ip.category_id, t.category_id)
+			WHERE ip.user_id = $1
+			  AND ip.status <> 'canceled'
+			  AND ic.due_at >= $2
+			  AND ic.due_at < $3
+			UNION ALL
+			SELECT t.reward_category_id, c.name, t.amount_minor, 0
+			FROM transactions t
+			JOIN categories c ON c.id = t.reward_category_id
+			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
+			  AND t.is_reward
+			  AND t.posted_at >= $2
+			  AND t.posted_at < $3
+			UNION ALL
+			SELECT t.category_id, COALESCE(c.name, 'Uncategorized'), 0, t.amount_minor
+			FROM transactions t
+			LEFT JOIN categories c ON t.category_id = c.id
+			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
+			  AND t.posted_at >= $2
+			  AND t.posted_at < $3
+			  AND t.amount_minor > 0
+			  AND NOT t.is_reward
+		)
+		SELECT
+			category_id,
+			category_name,
+			GREATEST(-SUM(amount_minor), 0) AS total_minor,
+			SUM(inflow_minor) AS inflow_minor,
+			COUNT(*) FILTER (WHERE amount_minor < 0) AS tx_count
+		FROM spend
+		GROUP BY category_id, category_name
+		HAVING COUNT(*) FILTER (WHERE amount_minor < 0) > 0 OR SUM(inflow_minor) > 0
 		ORDER BY total_minor DESC
 	`
 
@@ -611,7 +866,7 @@ func (r *PostgresImportRepository) GetCategoryTotals(ctx context.Context, userID
 	var results []CategoryTotal
 	for rows.Next() {
 		var ct CategoryTotal
-		if err := rows.Scan(&ct.CategoryID, &ct.CategoryName, &ct.TotalMinor, &ct.Count); err != nil {
+		if err := rows.Scan(&ct.CategoryID, &ct.CategoryName, &ct.TotalMinor, &ct.InflowMinor, &ct.Count); err != nil {
 			return nil, fmt.Errorf("failed to scan category total: %w", err)
 		}
 		results = append(results, ct)
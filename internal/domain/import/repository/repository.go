@@ -24,6 +24,7 @@ type BankMapping struct {
 	DebitCol         *int       `db:"debit_col"`
 	CreditCol        *int       `db:"credit_col"`
 	IsEuropeanFormat bool       `db:"is_european_format"`
+	Locale           *string    `db:"locale"` // Locale pack cleaning descriptors, e.g. "PT"
 	CreatedAt        time.Time  `db:"created_at"`
 	UpdatedAt        time.Time  `db:"updated_at"`
 }
@@ -47,17 +48,30 @@ type ImportJob struct {
 	FinishedAt   *time.Time `db:"finished_at"`
 }
 
+// ListImportJobsFilter narrows an import job listing
+type ListImportJobsFilter struct {
+	Status string // Empty means any status
+	Kind   string // Empty means any kind
+	Limit  int
+	Offset int
+}
+
 // UserFile represents an uploaded file
 type UserFile struct {
-	ID             uuid.UUID `db:"id"`
-	UserID         uuid.UUID `db:"user_id"`
-	Type           string    `db:"type"` // "csv", "xlsx", "pdf", "image"
-	MimeType       string    `db:"mime_type"`
-	FileName       string    `db:"file_name"`
-	SizeBytes      int64     `db:"size_bytes"`
-	ChecksumSHA256 *string   `db:"checksum_sha256"`
-	StorageURL     *string   `db:"storage_url"`
-	CreatedAt      time.Time `db:"created_at"`
+	ID             uuid.UUID   `db:"id"`
+	UserID         uuid.UUID   `db:"user_id"`
+	Type           string      `db:"type"` // "csv", "xlsx", "pdf", "image"
+	MimeType       string      `db:"mime_type"`
+	FileName       string      `db:"file_name"`
+	SizeBytes      int64       `db:"size_bytes"`
+	ChecksumSHA256 *string     `db:"checksum_sha256"`
+	StorageURL     *string     `db:"storage_url"`
+	Purpose        FilePurpose `db:"purpose"`     // Defaults to FilePurposeOther
+	PurgedAt       *time.Time  `db:"purged_at"`   // Set once the content is deleted; the row stays for audit
+	ScanStatus     string      `db:"scan_status"` // "pending", "clean" or "quarantined"; defaults to "pending"
+	ScanDetail     *string     `db:"scan_detail"` // Why the file is quarantined or still pending
+	ScannedAt      *time.Time  `db:"scanned_at"`
+	CreatedAt      time.Time   `db:"created_at"`
 }
 
 // ParsedTransaction represents a transaction extracted from a file
@@ -68,7 +82,20 @@ type ParsedTransaction struct {
 	AmountCents  int64      // Signed: negative for expenses, positive for income
 	Category     string     // Raw category from CSV
 	CategoryID   *uuid.UUID // Resolved category ID from categorization engine
-	ExternalID   string     // For deduplication (e.g., row hash)
+	ExternalID   string     // Source-provided ID for deduplication; otherwise date, description and amount are hashed
+
+	// Set when CategoryID came from the categorization engine, so later
+	// corrections can be measured against it
+	AutoCategoryID *uuid.UUID
+	RuleID         *uuid.UUID // Matching category rule, if any
+	MerchantID     *uuid.UUID // Matching merchant, if any
+
+	Location *TransactionLocation // Where it took place, when the source knows
+	Tags     []string             // From transaction rules
+
+	// Description cleaned by the institution's locale pack, matched instead
+	// of it when set
+	MatchDescription string
 }
 
 // ImportRepository defines data access operations for imports
@@ -89,6 +116,7 @@ type ImportRepository interface {
 	// Import Jobs
 	CreateImportJob(ctx context.Context, job *ImportJob) error
 	GetImportJobByID(ctx context.Context, id uuid.UUID) (*ImportJob, error)
+	ListImportJobs(ctx context.Context, userID uuid.UUID, filter ListImportJobsFilter) ([]*ImportJob, int64, error)
 	GetImportJobStats(ctx context.Context, importJobID uuid.UUID) (*ImportJobStats, error)
 	UpdateImportJobProgress(ctx context.Context, id uuid.UUID, rowsImported, rowsFailed int) error
 	UpdateImportJobStatus(ctx context.Context, id uuid.UUID, status string, errorMessage *string) error
@@ -106,7 +134,7 @@ type ImportRepository interface {
 	// Transactions (aggregation for plan actuals)
 	GetCategoryTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]CategoryTotal, error)
 
-	// Transactions (delete by import job)
+	// Transactions (move to the trash by import job)
 	DeleteByImportJobID(ctx context.Context, userID uuid.UUID, importJobID uuid.UUID) (int, error)
 }
 
@@ -139,20 +167,29 @@ type Transaction struct {
 	ExternalID          *string    `db:"external_id"`
 	Notes               *string    `db:"notes"`
 	InstitutionName     *string    `db:"institution_name"`
+	AutoCategoryID      *uuid.UUID `db:"auto_category_id"`           // Category the engine predicted
+	RuleID              *uuid.UUID `db:"categorized_by_rule_id"`     // Rule behind the prediction
+	MerchantID          *uuid.UUID `db:"categorized_by_merchant_id"` // Merchant behind the prediction
+	Latitude            *float64   `db:"latitude"`
+	Longitude           *float64   `db:"longitude"`
+	PlaceName           *string    `db:"place_name"`
+	LocationSource      *string    `db:"location_source"` // LocationSourceAggregator or LocationSourceManual
+	Tags                []string   `db:"tags"`
 	CreatedAt           time.Time  `db:"created_at"`
 	UpdatedAt           time.Time  `db:"updated_at"`
 }
 
 // ListTransactionsFilter specifies filter/pagination options for listing transactions
 type ListTransactionsFilter struct {
-	AccountID   *uuid.UUID
-	CategoryID  *uuid.UUID
-	ImportJobID *uuid.UUID // Filter by import batch (for staging view)
-	StartDate   *time.Time
-	EndDate     *time.Time
-	Search      string // Search in description
-	Limit       int
-	Offset      int
+	AccountID        *uuid.UUID
+	CategoryID       *uuid.UUID
+	ImportJobID      *uuid.UUID // Filter by import batch (for staging view)
+	StartDate        *time.Time
+	EndDate          *time.Time
+	Search           string // Search in description
+	IncludeHousehold bool   // Also list transactions on accounts shared with the user's household
+	Limit            int
+	Offset           int
 }
 
 // CategoryTotal contains aggregated spending for a single category
@@ -160,5 +197,6 @@ type CategoryTotal struct {
 	CategoryID   *uuid.UUID
 	CategoryName string
 	TotalMinor   int64 // Absolute value of spending (always positive for expenses)
-	Count        int   // Number of transactions
+	InflowMinor  int64 // Refunds and other inflows, excluding rewards (always positive)
+	Count        int   // Number of expense transactions
 }
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scan statuses of user files, as filescan.Status
+const (
+	ScanPending     = "pending"
+	ScanClean       = "clean"
+	ScanQuarantined = "quarantined"
+)
+
+// ScanRepository defines data access for file scan results
+type ScanRepository interface {
+	// ListUnscannedFiles lists stored files still pending a scan, oldest first
+	ListUnscannedFiles(ctx context.Context, limit int) ([]*UserFile, error)
+	// UpdateUserFileScan records a file's scan. Returns sql.ErrNoRows if the
+	// file doesn't exist.
+	UpdateUserFileScan(ctx context.Context, fileID uuid.UUID, status string, detail *string, at time.Time) error
+}
+
+// ListUnscannedFiles lists stored files still pending a scan
+func (r *PostgresImportRepository) ListUnscannedFiles(ctx context.Context, limit int) ([]*UserFile, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url,
+		       purpose, purged_at, scan_status, scan_detail, scanned_at, created_at
+		FROM user_files
+		WHERE scan_status = 'pending' AND storage_url IS NOT NULL AND purged_at IS NULL
+		ORDER BY created_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unscanned files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*UserFile
+	for rows.Next() {
+		f := &UserFile{}
+		if err := rows.Scan(
+			&f.ID, &f.UserID, &f.Type, &f.MimeType, &f.FileName, &f.SizeBytes,
+			&f.ChecksumSHA256, &f.StorageURL, &f.Purpose, &f.PurgedAt,
+			&f.ScanStatus, &f.ScanDetail, &f.ScannedAt, &f.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user file: %w", err)
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// UpdateUserFileScan records a file's scan
+func (r *PostgresImportRepository) UpdateUserFileScan(ctx context.Context, fileID uuid.UUID, status string, detail *string, at time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE user_files SET scan_status = $2, scan_detail = $3, scanned_at = $4 WHERE id = $1
+	`, fileID, status, detail, at)
+	if err != nil {
+		return fmt.Errorf("failed to update user file scan: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
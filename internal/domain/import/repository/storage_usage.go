@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// FilePurpose is what a stored file is kept for
+type FilePurpose string
+
+const (
+	FilePurposeStatement FilePurpose = "statement" // Bank statements and exports to import
+	FilePurposeReceipt   FilePurpose = "receipt"   // Receipts, invoices and warranty documents
+	FilePurposeReport    FilePurpose = "report"    // Generated reports
+	FilePurposeOther     FilePurpose = "other"
+)
+
+// StorageTier decides a user's storage quota
+type StorageTier string
+
+const (
+	StorageTierFree    StorageTier = "free"
+	StorageTierPremium StorageTier = "premium"
+)
+
+// StorageUsage is the stored files of one purpose
+type StorageUsage struct {
+	Purpose FilePurpose
+	Files   int
+	Bytes   int64
+}
+
+// StorageRepository defines data access for per-user file storage usage
+type StorageRepository interface {
+	// GetStorageUsage sums a user's files whose content is still stored, by purpose
+	GetStorageUsage(ctx context.Context, userID uuid.UUID) ([]StorageUsage, error)
+	// GetStorageTier returns StorageTierPremium while a paid subscription is in effect
+	GetStorageTier(ctx context.Context, userID uuid.UUID) (StorageTier, error)
+	// ListImportedStatementFiles lists a user's stored statement files that were
+	// imported successfully and have no import in progress, oldest first
+	ListImportedStatementFiles(ctx context.Context, userID uuid.UUID, limit int) ([]*UserFile, error)
+	// MarkUserFilePurged records that a file's content was deleted, keeping the row
+	// and its checksum. Returns sql.ErrNoRows if the file isn't the user's or is
+	// already purged.
+	MarkUserFilePurged(ctx context.Context, userID, fileID uuid.UUID) error
+}
+
+// GetStorageUsage sums a user's stored files by purpose
+func (r *PostgresImportRepository) GetStorageUsage(ctx context.Context, userID uuid.UUID) ([]StorageUsage, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT purpose, COUNT(*), COALESCE(SUM(size_bytes), 0)
+		FROM user_files
+		WHERE user_id = $1 AND purged_at IS NULL AND storage_url IS NOT NULL
+		GROUP BY purpose
+		ORDER BY purpose
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []StorageUsage
+	for rows.Next() {
+		var u StorageUsage
+		if err := rows.Scan(&u.Purpose, &u.Files, &u.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan storage usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// GetStorageTier returns the user's storage tier from their subscription. Canceled
+// subscriptions keep the paid tier until they end.
+func (r *PostgresImportRepository) GetStorageTier(ctx context.Context, userID uuid.UUID) (StorageTier, error) {
+	var premium bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions
+			WHERE user_id = $1
+			  AND plan <> 'free'
+			  AND (status IN ('active', 'trialing', 'past_due')
+			       OR (status = 'canceled' AND end_date > NOW()))
+		)
+	`, userID).Scan(&premium)
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage tier: %w", err)
+	}
+	if premium {
+		return StorageTierPremium, nil
+	}
+	return StorageTierFree, nil
+}
+
+// ListImportedStatementFiles lists stored statement files that are safe to purge.
+// A file counts as imported once an import job for it succeeded or a plan was
+// built from it.
+func (r *PostgresImportRepository) ListImportedStatementFiles(ctx context.Context, userID uuid.UUID, limit int) ([]*UserFile, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT uf.id, uf.user_id, uf.type, uf.mime_type, uf.file_name, uf.size_bytes,
+		       uf.checksum_sha256, uf.storage_url, uf.purpose, uf.purged_at, uf.created_at
+		FROM user_files uf
+		WHERE uf.user_id = $1
+		  AND uf.purpose = 'statement'
+		  AND uf.purged_at IS NULL
+		  AND uf.storage_url IS NOT NULL
+		  AND (EXISTS (SELECT 1 FROM import_jobs ij WHERE ij.file_id = uf.id AND ij.status = 'succeeded')
+		       OR EXISTS (SELECT 1 FROM user_plans up WHERE up.source_file_id = uf.id))
+		  AND NOT EXISTS (
+		      SELECT 1 FROM import_jobs ij
+		      WHERE ij.file_id = uf.id AND ij.status IN ('pending', 'running')
+		  )
+		ORDER BY uf.created_at
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list imported statement files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*UserFile
+	for rows.Next() {
+		f := &UserFile{}
+		if err := rows.Scan(
+			&f.ID, &f.UserID, &f.Type, &f.MimeType, &f.FileName, &f.SizeBytes,
+			&f.ChecksumSHA256, &f.StorageURL, &f.Purpose, &f.PurgedAt, &f.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user file: %w", err)
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// MarkUserFilePurged records that a file's content was deleted
+func (r *PostgresImportRepository) MarkUserFilePurged(ctx context.Context, userID, fileID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE user_files
+		SET purged_at = NOW(), storage_url = NULL
+		WHERE id = $1 AND user_id = $2 AND purged_at IS NULL
+	`, fileID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark user file purged: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
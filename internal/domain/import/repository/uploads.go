@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// UploadStatus is the state of a chunked upload
+type UploadStatus string
+
+const (
+	UploadPending   UploadStatus = "pending"   // Receiving chunks
+	UploadCompleted UploadStatus = "completed" // Assembled into FileID
+	UploadAborted   UploadStatus = "aborted"
+)
+
+// UploadSession is a file being uploaded in chunks
+type UploadSession struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	FileName       string
+	MimeType       string
+	FileType       string // As user_files.type
+	SizeBytes      int64
+	ChunkSize      int // Every chunk but the last has exactly this size
+	ChecksumSHA256 string
+	Status         UploadStatus
+	FileID         *uuid.UUID // The assembled user file, once completed
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	CompletedAt    *time.Time
+}
+
+// UploadChunk is one received chunk, staged in file storage until the upload completes
+type UploadChunk struct {
+	UploadID       uuid.UUID
+	Index          int
+	StorageFileID  uuid.UUID
+	SizeBytes      int
+	ChecksumSHA256 string
+	ReceivedAt     time.Time
+}
+
+// UploadRepository defines data access for chunked uploads
+type UploadRepository interface {
+	CreateUploadSession(ctx context.Context, session *UploadSession) error
+	// GetUploadSession returns one of the user's uploads, or sql.ErrNoRows
+	GetUploadSession(ctx context.Context, userID, uploadID uuid.UUID) (*UploadSession, error)
+	// ListUploadChunks returns an upload's received chunks by index
+	ListUploadChunks(ctx context.Context, uploadID uuid.UUID) ([]*UploadChunk, error)
+	// PutUploadChunk records a received chunk, replacing one sent before with
+	// the same index. It returns the storage file of the replaced chunk, if any.
+	PutUploadChunk(ctx context.Context, chunk *UploadChunk) (*uuid.UUID, error)
+	// CompleteUploadSession records the assembled file and marks a pending
+	// upload completed, dropping its chunks. Returns sql.ErrNoRows if the
+	// upload is no longer pending.
+	CompleteUploadSession(ctx context.Context, uploadID uuid.UUID, file *UserFile, at time.Time) error
+	// AbortUploadSession marks a pending upload aborted, dropping its chunks.
+	// Returns sql.ErrNoRows if the upload is no longer pending.
+	AbortUploadSession(ctx context.Context, uploadID uuid.UUID) error
+}
+
+const uploadSessionColumns = `id, user_id, file_name, mime_type, file_type, size_bytes, chunk_size,
+	checksum_sha256, status, file_id, created_at, expires_at, completed_at`
+
+// CreateUploadSession records a new pending upload
+func (r *PostgresImportRepository) CreateUploadSession(ctx context.Context, s *UploadSession) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO upload_sessions (user_id, file_name, mime_type, file_type, size_bytes, chunk_size,
+			checksum_sha256, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`,
+		s.UserID, s.FileName, s.MimeType, s.FileType, s.SizeBytes, s.ChunkSize,
+		s.ChecksumSHA256, s.Status, s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+	return nil
+}
+
+// GetUploadSession returns one of the user's uploads
+func (r *PostgresImportRepository) GetUploadSession(ctx context.Context, userID, uploadID uuid.UUID) (*UploadSession, error) {
+	s := &UploadSession{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT `+uploadSessionColumns+` FROM upload_sessions WHERE id = $1 AND user_id = $2`,
+		uploadID, userID,
+	).Scan(&s.ID, &s.UserID, &s.FileName, &s.MimeType, &s.FileType, &s.SizeBytes, &s.ChunkSize,
+		&s.ChecksumSHA256, &s.Status, &s.FileID, &s.CreatedAt, &s.ExpiresAt, &s.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+	return s, nil
+}
+
+// ListUploadChunks returns an upload's received chunks by index
+func (r *PostgresImportRepository) ListUploadChunks(ctx context.Context, uploadID uuid.UUID) ([]*UploadChunk, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT upload_id, chunk_index, storage_file_id, size_bytes, checksum_sha256, received_at
+		FROM upload_chunks
+		WHERE upload_id = $1
+		ORDER BY chunk_index`, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []*UploadChunk
+	for rows.Next() {
+		c := &UploadChunk{}
+		if err := rows.Scan(&c.UploadID, &c.Index, &c.StorageFileID, &c.SizeBytes, &c.ChecksumSHA256, &c.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan upload chunk: %w", err)
+		}
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// PutUploadChunk records a received chunk, replacing any with the same index
+func (r *PostgresImportRepository) PutUploadChunk(ctx context.Context, c *UploadChunk) (*uuid.UUID, error) {
+	var replaced *uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		WITH previous AS (
+			SELECT storage_file_id FROM upload_chunks
+			WHERE upload_id = $1 AND chunk_index = $2
+			FOR UPDATE
+		)
+		INSERT INTO upload_chunks (upload_id, chunk_index, storage_file_id, size_bytes, checksum_sha256, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (upload_id, chunk_index) DO UPDATE
+		SET storage_file_id = EXCLUDED.storage_file_id, size_bytes = EXCLUDED.size_bytes,
+			checksum_sha256 = EXCLUDED.checksum_sha256, received_at = EXCLUDED.received_at
+		RETURNING (SELECT storage_file_id FROM previous)`,
+		c.UploadID, c.Index, c.StorageFileID, c.SizeBytes, c.ChecksumSHA256, c.ReceivedAt,
+	).Scan(&replaced)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put upload chunk: %w", err)
+	}
+	return replaced, nil
+}
+
+// CompleteUploadSession records the assembled file and completes the upload
+func (r *PostgresImportRepository) CompleteUploadSession(ctx context.Context, uploadID uuid.UUID, file *UserFile, at time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status UploadStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM upload_sessions WHERE id = $1 FOR UPDATE`, uploadID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && status != UploadPending) {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock upload session: %w", err)
+	}
+
+	if file.Purpose == "" {
+		file.Purpose = FilePurposeOther
+	}
+	if file.ScanStatus == "" {
+		file.ScanStatus = ScanPending
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_files (id, user_id, type, mime_type, file_name, size_bytes, checksum_sha256, storage_url, purpose,
+			scan_status, scan_detail, scanned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at`,
+		file.ID, file.UserID, file.Type, file.MimeType, file.FileName,
+		file.SizeBytes, file.ChecksumSHA256, file.StorageURL, file.Purpose,
+		file.ScanStatus, file.ScanDetail, file.ScannedAt,
+	).Scan(&file.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create user file: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE upload_sessions SET status = $2, file_id = $3, completed_at = $4 WHERE id = $1`,
+		uploadID, UploadCompleted, file.ID, at); err != nil {
+		return fmt.Errorf("failed to complete upload session: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM upload_chunks WHERE upload_id = $1`, uploadID); err != nil {
+		return fmt.Errorf("failed to delete upload chunks: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit upload completion: %w", err)
+	}
+	return nil
+}
+
+// AbortUploadSession marks a pending upload aborted
+func (r *PostgresImportRepository) AbortUploadSession(ctx context.Context, uploadID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE upload_sessions SET status = $2 WHERE id = $1 AND status = $3`,
+		uploadID, UploadAborted, UploadPending)
+	if err != nil {
+		return fmt.Errorf("failed to abort upload session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	if _, err := r.pool.Exec(ctx, `DELETE FROM upload_chunks WHERE upload_id = $1`, uploadID); err != nil {
+		return fmt.Errorf("failed to delete upload chunks: %w", err)
+	}
+	return nil
+}
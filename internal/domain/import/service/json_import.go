@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// =============================================================================
+// JSON Transaction Import (Internal Integration)
+// =============================================================================
+// Integrations that already have structured transactions (other apps, custom
+// scripts) can import them directly instead of building a CSV. The import runs
+// through the same job, categorization, dedup and insights flow as CSV imports.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ImportTransactionsJsonRequest/Response (FinanceService.ImportTransactionsJson)
+// - JsonTransaction
+
+const (
+	// maxJSONImportTransactions caps the transactions accepted in one import
+	maxJSONImportTransactions = 10000
+	// maxExternalIDLength caps source-provided dedup IDs
+	maxExternalIDLength = 128
+	// maxDescriptionLength caps transaction descriptions
+	maxDescriptionLength = 500
+)
+
+var (
+	// ErrEmptyJSONImport is returned when there are no transactions to import
+	ErrEmptyJSONImport = errors.New("no transactions to import")
+	// ErrJSONImportTooLarge is returned when an import exceeds maxJSONImportTransactions
+	ErrJSONImportTooLarge = fmt.Errorf("at most %d transactions can be imported at once", maxJSONImportTransactions)
+)
+
+// JSONTransaction is a pre-structured transaction to import
+type JSONTransaction struct {
+	Date         string `json:"date"`          // RFC 3339 timestamp or YYYY-MM-DD
+	Description  string `json:"description"`   // Required
+	AmountMinor  *int64 `json:"amount_minor"`  // Signed: negative for expenses, positive for income
+	CurrencyCode string `json:"currency_code"` // Optional; must match the import's currency
+	MerchantName string `json:"merchant_name"` // Optional; categorization fills it in otherwise
+	ExternalID   string `json:"external_id"`   // Optional stable ID for deduplication
+
+	// Optional location, as aggregators send it
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	PlaceName string   `json:"place_name"`
+}
+
+// JSONImportOptions configures a JSON import
+type JSONImportOptions struct {
+	CurrencyCode    string // Used when no account is given; else taken from the transactions
+	Timezone        string // For date-only values; UTC if empty
+	InstitutionName string
+	TraceRows       int // Trace the categorization of this many transactions (0 = off)
+}
+
+// ParseJSONTransactions decodes a JSON array of transactions, or an object with
+// a "transactions" array
+func ParseJSONTransactions(data []byte) ([]JSONTransaction, error) {
+	data = bytes.TrimSpace(stripUTF8BOM(data))
+	var txs []JSONTransaction
+	if len(data) > 0 && data[0] == '{' {
+		var wrapper struct {
+			Transactions []JSONTransaction `json:"transactions"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return nil, fmt.Errorf("invalid transactions JSON: %w", err)
+		}
+		txs = wrapper.Transactions
+	} else if err := json.Unmarshal(data, &txs); err != nil {
+		return nil, fmt.Errorf("invalid transactions JSON: %w", err)
+	}
+	return txs, nil
+}
+
+// ImportTransactionsJSON imports pre-structured transactions. Invalid
+// transactions are reported and skipped; the rest are imported under a new
+// import job like a CSV import.
+func (s *ImportService) ImportTransactionsJSON(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, txs []JSONTransaction, opts JSONImportOptions) (*ImportResult, error) {
+	if len(txs) == 0 {
+		return nil, ErrEmptyJSONImport
+	}
+	if len(txs) > maxJSONImportTransactions {
+		return nil, ErrJSONImportTooLarge
+	}
+	if err := s.CheckTransactionQuota(ctx, userID, 1); err != nil {
+		return nil, err
+	}
+
+	currencyCode, err := s.resolveJSONCurrency(ctx, userID, accountID, txs, opts.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := resolveLocation(opts.Timezone)
+	if loc == nil {
+		loc = time.UTC
+	}
+	parsed := make([]*repository.ParsedTransaction, 0, len(txs))
+	var invalid []string
+	for i, tx := range txs {
+		p, err := validateJSONTransaction(tx, currencyCode, loc)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("transaction %d: %v", i+1, err))
+			continue
+		}
+		parsed = append(parsed, p)
+	}
+
+	data, _ := json.Marshal(txs)
+	fileRecord := &repository.UserFile{
+		UserID:    userID,
+		Type:      "json",
+		MimeType:  "application/json",
+		FileName:  "import.json",
+		SizeBytes: int64(len(data)),
+		Purpose:   repository.FilePurposeStatement,
+	}
+	if err := s.repo.CreateUserFile(ctx, fileRecord); err != nil {
+		return nil, fmt.Errorf("failed to create file record: %w", err)
+	}
+
+	job := &repository.ImportJob{
+		UserID:    userID,
+		FileID:    fileRecord.ID,
+		Kind:      "transactions",
+		Status:    "running",
+		AccountID: accountID,
+		RowsTotal: len(txs),
+	}
+	if err := s.repo.CreateImportJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan parseResult)
+	go func() {
+		defer close(results)
+		for i, p := range parsed {
+			select {
+			case results <- parseResult{lineNum: i + 1, tx: p}:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return s.runImportJob(ctx, job, currencyCode, opts.InstitutionName, opts.TraceRows, results, invalid, cancel)
+}
+
+// resolveJSONCurrency picks the import's currency: the account's, else the
+// requested one, else the one all transactions share
+func (s *ImportService) resolveJSONCurrency(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, txs []JSONTransaction, requested string) (string, error) {
+	if accountID != nil {
+		return s.resolveCurrencyCode(ctx, userID, accountID, nil, nil)
+	}
+	if requested != "" {
+		code, ok := normalizeCurrencyCode(requested)
+		if !ok {
+			return "", fmt.Errorf("invalid currency code: %s", requested)
+		}
+		return code, nil
+	}
+
+	var found string
+	for _, tx := range txs {
+		if tx.CurrencyCode == "" {
+			continue
+		}
+		code, ok := normalizeCurrencyCode(tx.CurrencyCode)
+		if !ok {
+			continue // Reported per transaction
+		}
+		if found != "" && code != found {
+			return "", fmt.Errorf("transactions use several currencies (%s, %s); import each currency separately", found, code)
+		}
+		found = code
+	}
+	if found == "" {
+		return "", fmt.Errorf("currency code not found; provide account_id, currency_code or a currency on each transaction")
+	}
+	return found, nil
+}
+
+// validateJSONTransaction checks a transaction and converts it for insertion
+func validateJSONTransaction(tx JSONTransaction, currencyCode string, loc *time.Location) (*repository.ParsedTransaction, error) {
+	date, err := parseJSONDate(tx.Date, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(tx.Description)
+	if description == "" {
+		return nil, errors.New("description is required")
+	}
+	if len(description) > maxDescriptionLength {
+		return nil, fmt.Errorf("description is longer than %d characters", maxDescriptionLength)
+	}
+
+	if tx.AmountMinor == nil {
+		return nil, errors.New("amount_minor is required")
+	}
+	if *tx.AmountMinor == 0 {
+		return nil, errors.New("amount_minor must not be zero")
+	}
+
+	if tx.CurrencyCode != "" {
+		code, ok := normalizeCurrencyCode(tx.CurrencyCode)
+		if !ok {
+			return nil, fmt.Errorf("invalid currency_code %q", tx.CurrencyCode)
+		}
+		if code != currencyCode {
+			return nil, fmt.Errorf("currency_code %s doesn't match the import's %s", code, currencyCode)
+		}
+	}
+
+	externalID := strings.TrimSpace(tx.ExternalID)
+	if len(externalID) > maxExternalIDLength {
+		return nil, fmt.Errorf("external_id is longer than %d characters", maxExternalIDLength)
+	}
+
+	parsed := &repository.ParsedTransaction{
+		Date:         date,
+		Description:  description,
+		MerchantName: strings.TrimSpace(tx.MerchantName),
+		AmountCents:  *tx.AmountMinor,
+		ExternalID:   externalID,
+	}
+	if tx.Latitude != nil || tx.Longitude != nil || strings.TrimSpace(tx.PlaceName) != "" {
+		loc := &repository.TransactionLocation{
+			Latitude:  tx.Latitude,
+			Longitude: tx.Longitude,
+			PlaceName: &tx.PlaceName,
+			Source:    repository.LocationSourceAggregator,
+		}
+		if err := validateLocation(loc); err != nil {
+			return nil, err
+		}
+		parsed.Location = loc
+	}
+	return parsed, nil
+}
+
+// parseJSONDate parses an RFC 3339 timestamp, or a date at midnight in loc
+func parseJSONDate(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("date is required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q; use YYYY-MM-DD or RFC 3339", value)
+}
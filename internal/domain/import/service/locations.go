@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// =============================================================================
+// Transaction Locations (Internal Integration)
+// =============================================================================
+// Transactions can carry where they took place: coordinates and a place name
+// sent by aggregators with JSON imports, or pinned by the user with
+// UpdateTransactionLocation. GetSpendingByLocation groups located expenses
+// into map cells for a map-based spending view.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - Transaction.location (TransactionLocation: latitude, longitude, place_name, source)
+// - UpdateTransactionLocationRequest/Response (FinanceService.UpdateTransactionLocation)
+// - GetSpendingByLocationRequest/Response (FinanceService.GetSpendingByLocation)
+//   with LocationCluster
+
+const (
+	// DefaultLocationPrecision rounds coordinates to 3 decimal places, cells
+	// of roughly 100 m
+	DefaultLocationPrecision = 3
+	// maxLocationPrecision is about 1 m; finer cells would never group anything
+	maxLocationPrecision = 5
+	// maxLocationClusters caps the cells returned for one map
+	maxLocationClusters = 500
+	// maxPlaceNameLength caps place names
+	maxPlaceNameLength = 200
+)
+
+var (
+	// ErrLocationsDisabled is returned when no location repository is configured
+	ErrLocationsDisabled = errors.New("transaction locations are not configured")
+	// ErrTransactionNotFound is returned for transactions that don't exist or aren't the user's
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// WithLocations enables pinning transactions and the spending map
+func (s *ImportService) WithLocations(locations repository.LocationRepository) *ImportService {
+	s.locations = locations
+	return s
+}
+
+// UpdateTransactionLocation pins a transaction to a location, replacing any
+// the aggregator sent. A nil location unpins it.
+func (s *ImportService) UpdateTransactionLocation(ctx context.Context, userID, transactionID uuid.UUID, loc *repository.TransactionLocation) error {
+	if s.locations == nil {
+		return ErrLocationsDisabled
+	}
+	if loc != nil {
+		pinned := *loc
+		pinned.Source = repository.LocationSourceManual
+		if err := validateLocation(&pinned); err != nil {
+			return err
+		}
+		loc = &pinned
+	}
+
+	err := s.locations.UpdateTransactionLocation(ctx, userID, transactionID, loc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTransactionNotFound
+	}
+	return err
+}
+
+// GetSpendingByLocation totals the expenses posted in [startDate, endDate)
+// by map cell. precision is the decimal places coordinates are rounded to;
+// zero uses DefaultLocationPrecision. Transactions without coordinates aren't
+// counted.
+func (s *ImportService) GetSpendingByLocation(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, precision int) ([]repository.LocationCluster, error) {
+	if s.locations == nil {
+		return nil, ErrLocationsDisabled
+	}
+	if !endDate.After(startDate) {
+		return nil, errors.New("end date must be after start date")
+	}
+	if precision == 0 {
+		precision = DefaultLocationPrecision
+	}
+	if precision < 0 || precision > maxLocationPrecision {
+		return nil, fmt.Errorf("precision must be between 1 and %d", maxLocationPrecision)
+	}
+	return s.locations.GetSpendingByLocation(ctx, userID, startDate, endDate, precision, maxLocationClusters)
+}
+
+// validateLocation checks a location's coordinates and trims its place name
+func validateLocation(loc *repository.TransactionLocation) error {
+	if (loc.Latitude == nil) != (loc.Longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if loc.Latitude != nil {
+		lat, lng := *loc.Latitude, *loc.Longitude
+		if math.IsNaN(lat) || lat < -90 || lat > 90 {
+			return fmt.Errorf("invalid latitude %v", lat)
+		}
+		if math.IsNaN(lng) || lng < -180 || lng > 180 {
+			return fmt.Errorf("invalid longitude %v", lng)
+		}
+	}
+	if loc.PlaceName != nil {
+		name := strings.TrimSpace(*loc.PlaceName)
+		if len(name) > maxPlaceNameLength {
+			return fmt.Errorf("place name is longer than %d characters", maxPlaceNameLength)
+		}
+		loc.PlaceName = &name
+		if name == "" {
+			loc.PlaceName = nil
+		}
+	}
+	if loc.Latitude == nil && loc.PlaceName == nil {
+		return errors.New("location needs coordinates or a place name")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// fakeLocationRepo stores locations of the transactions it knows
+type fakeLocationRepo struct {
+	locations map[uuid.UUID]*repository.TransactionLocation
+	precision int
+}
+
+func (f *fakeLocationRepo) UpdateTransactionLocation(ctx context.Context, userID, transactionID uuid.UUID, loc *repository.TransactionLocation) error {
+	if _, ok := f.locations[transactionID]; !ok {
+		return sql.ErrNoRows
+	}
+	f.locations[transactionID] = loc
+	return nil
+}
+
+func (f *fakeLocationRepo) GetSpendingByLocation(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time, precision, limit int) ([]repository.LocationCluster, error) {
+	f.precision = precision
+	return nil, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestUpdateTransactionLocation(t *testing.T) {
+	txID := uuid.New()
+	repo := &fakeLocationRepo{locations: map[uuid.UUID]*repository.TransactionLocation{txID: nil}}
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).WithLocations(repo)
+	ctx := context.Background()
+
+	err := svc.UpdateTransactionLocation(ctx, uuid.New(), txID, &repository.TransactionLocation{
+		Latitude:  ptr(38.7369),
+		Longitude: ptr(-9.1427),
+		PlaceName: ptr("  Mercado de Campo de Ourique "),
+		Source:    repository.LocationSourceAggregator,
+	})
+	if err != nil {
+		t.Fatalf("UpdateTransactionLocation failed: %v", err)
+	}
+	loc := repo.locations[txID]
+	if loc.Source != repository.LocationSourceManual {
+		t.Fatalf("expected a pinned location to be manual, got %q", loc.Source)
+	}
+	if *loc.PlaceName != "Mercado de Campo de Ourique" {
+		t.Fatalf("expected the place name to be trimmed, got %q", *loc.PlaceName)
+	}
+
+	if err := svc.UpdateTransactionLocation(ctx, uuid.New(), txID, nil); err != nil || repo.locations[txID] != nil {
+		t.Fatalf("expected the location to be cleared, got %+v (%v)", repo.locations[txID], err)
+	}
+
+	invalid := []*repository.TransactionLocation{
+		{Latitude: ptr(38.7)},
+		{Latitude: ptr(91.0), Longitude: ptr(0.0)},
+		{Latitude: ptr(0.0), Longitude: ptr(-180.5)},
+		{PlaceName: ptr("   ")},
+	}
+	for _, loc := range invalid {
+		if err := svc.UpdateTransactionLocation(ctx, uuid.New(), txID, loc); err == nil {
+			t.Fatalf("expected an error for %+v", loc)
+		}
+	}
+
+	err = svc.UpdateTransactionLocation(ctx, uuid.New(), uuid.New(), &repository.TransactionLocation{PlaceName: ptr("Lisbon")})
+	if !errors.Is(err, ErrTransactionNotFound) {
+		t.Fatalf("expected ErrTransactionNotFound, got %v", err)
+	}
+}
+
+func TestGetSpendingByLocation_Precision(t *testing.T) {
+	repo := &fakeLocationRepo{}
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).WithLocations(repo)
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	if _, err := svc.GetSpendingByLocation(ctx, uuid.New(), start, end, 0); err != nil {
+		t.Fatalf("GetSpendingByLocation failed: %v", err)
+	}
+	if repo.precision != DefaultLocationPrecision {
+		t.Fatalf("expected the default precision, got %d", repo.precision)
+	}
+
+	if _, err := svc.GetSpendingByLocation(ctx, uuid.New(), start, end, maxLocationPrecision+1); err == nil {
+		t.Fatal("expected an error for a precision finer than the maximum")
+	}
+	if _, err := svc.GetSpendingByLocation(ctx, uuid.New(), end, start, 0); err == nil {
+		t.Fatal("expected an error when the end date is before the start date")
+	}
+
+	disabled := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := disabled.GetSpendingByLocation(ctx, uuid.New(), start, end, 0); !errors.Is(err, ErrLocationsDisabled) {
+		t.Fatalf("expected ErrLocationsDisabled, got %v", err)
+	}
+}
+
+func TestValidateJSONTransaction_Location(t *testing.T) {
+	amount := int64(-1250)
+	p, err := validateJSONTransaction(JSONTransaction{
+		Date: "2024-03-02", Description: "PINGO DOCE ALVALADE", AmountMinor: &amount,
+		Latitude: ptr(38.7527), Longitude: ptr(-9.1447), PlaceName: "Pingo Doce Alvalade",
+	}, "EUR", time.UTC)
+	if err != nil {
+		t.Fatalf("validateJSONTransaction failed: %v", err)
+	}
+	if p.Location == nil || p.Location.Source != repository.LocationSourceAggregator {
+		t.Fatalf("expected an aggregator location, got %+v", p.Location)
+	}
+
+	p, err = validateJSONTransaction(JSONTransaction{Date: "2024-03-02", Description: "Rent", AmountMinor: &amount}, "EUR", time.UTC)
+	if err != nil || p.Location != nil {
+		t.Fatalf("expected no location, got %+v (%v)", p, err)
+	}
+
+	if _, err := validateJSONTransaction(JSONTransaction{
+		Date: "2024-03-02", Description: "Coffee", AmountMinor: &amount, Longitude: ptr(-9.14),
+	}, "EUR", time.UTC); err == nil {
+		t.Fatal("expected an error for a longitude without a latitude")
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrImportJobNotFound is returned when retrying a job that doesn't exist
+	ErrImportJobNotFound = errors.New("import job not found")
+	// ErrImportJobNotRetryable is returned when retrying a job that hasn't
+	// failed, or that imported something other than a transaction file
+	ErrImportJobNotRetryable = errors.New("only failed transaction imports can be retried")
+	// ErrImportFileUnavailable is returned when a job's file wasn't kept or
+	// has since been cleaned up, so there is nothing to re-run it from
+	ErrImportFileUnavailable = errors.New("import file is no longer stored")
+)
+
+// RetryImportJob re-runs a failed import from its stored file as a new job,
+// with the original account and timezone and an auto-detected column mapping.
+// Rows that did make it in the first time are skipped by deduplication.
+//
+// It doesn't check who is asking: callers must only let the job's owner or
+// support staff retry it.
+func (s *ImportService) RetryImportJob(ctx context.Context, jobID uuid.UUID) (*ImportResult, error) {
+	job, err := s.repo.GetImportJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrImportJobNotFound
+	}
+	if job.Status != "failed" || job.Kind != "transactions" {
+		return nil, ErrImportJobNotRetryable
+	}
+	if s.files == nil {
+		return nil, ErrImportFileUnavailable
+	}
+
+	r, err := s.files.GetReader(ctx, job.UserID, job.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFileUnavailable, err)
+	}
+	defer func() { _ = r.Close() }()
+	if err := s.RequireScannedFile(ctx, job.UserID, job.FileID); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	opts := ImportOptions{}
+	if job.Timezone != nil {
+		opts.Timezone = *job.Timezone
+	}
+	return s.ImportWithOptions(ctx, job.UserID, job.AccountID, data, ColumnMapping{}, opts)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
+)
+
+// =============================================================================
+// File Scanning (Internal Integration)
+// =============================================================================
+// Uploads are scanned before they're stored: UploadUserFile and CompleteUpload
+// reject files that are too large or whose magic bytes don't match their
+// declared type, strip VBA macros from workbooks, and run the optional ClamAV
+// scan. The outcome is kept on user_files.scan_status, and importers call
+// RequireScannedFile before reading a file. Files left pending because the
+// antivirus was down, or stored before scanning existed, are picked up by
+// ScanPendingFiles.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - UserFile.scan_status (enum: pending, clean, quarantined) and scan_detail
+
+// scanBatchSize caps the files one ScanPendingFiles run rescans
+const scanBatchSize = 100
+
+// ErrUserFileNotFound is returned for files that don't exist or aren't the user's
+var ErrUserFileNotFound = errors.New("file not found")
+
+// ScanSummary counts the files a rescan looked at
+type ScanSummary struct {
+	Clean       int
+	Quarantined int
+	Pending     int // Still waiting, usually on the antivirus
+}
+
+// WithScanner replaces the default type and size checks with scanner, which
+// can add an antivirus. scans enables ScanPendingFiles.
+func (s *ImportService) WithScanner(scanner *filescan.Scanner, scans repository.ScanRepository) *ImportService {
+	s.scanner = scanner
+	s.scans = scans
+	return s
+}
+
+// ScanUpload scans a file about to be stored. Store the result's Data, which
+// has macros removed, and pass the result on to CreateUserFile. Errors wrap
+// filescan.ErrEmptyFile, ErrFileTooLarge or ErrTypeMismatch.
+func (s *ImportService) ScanUpload(ctx context.Context, fileType string, data []byte) (*filescan.Result, error) {
+	result, err := s.scanner.Scan(ctx, fileType, data)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != filescan.StatusClean {
+		s.logger.Warn("uploaded file not clean",
+			slog.String("status", string(result.Status)),
+			slog.String("detail", result.Detail),
+		)
+	}
+	return result, nil
+}
+
+// RequireScannedFile returns nil if one of the user's files passed its scan,
+// filescan.ErrQuarantined or ErrNotScanned if it didn't, and
+// ErrUserFileNotFound if it isn't theirs. Anything reading a stored file to
+// import it must call this first.
+func (s *ImportService) RequireScannedFile(ctx context.Context, userID, fileID uuid.UUID) error {
+	file, err := s.repo.GetUserFileByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if file == nil || file.UserID != userID {
+		return ErrUserFileNotFound
+	}
+	switch file.ScanStatus {
+	case repository.ScanClean:
+		return nil
+	case repository.ScanQuarantined:
+		if file.ScanDetail != nil {
+			return fmt.Errorf("%w: %s", filescan.ErrQuarantined, *file.ScanDetail)
+		}
+		return filescan.ErrQuarantined
+	default:
+		return filescan.ErrNotScanned
+	}
+}
+
+// ScanPendingFiles rescans stored files still pending, oldest first. Stored
+// content can't be rewritten, so a workbook that still has macros is
+// quarantined rather than stripped. The run stops early while the antivirus
+// is unavailable.
+func (s *ImportService) ScanPendingFiles(ctx context.Context) (*ScanSummary, error) {
+	if s.scans == nil || s.files == nil {
+		return nil, ErrFileStorageDisabled
+	}
+	files, err := s.scans.ListUnscannedFiles(ctx, scanBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ScanSummary{}
+	for _, f := range files {
+		status, detail, avDown := s.rescan(ctx, f)
+		var detailPtr *string
+		if detail != "" {
+			detailPtr = &detail
+		}
+		if err := s.scans.UpdateUserFileScan(ctx, f.ID, status, detailPtr, s.now()); err != nil {
+			return summary, err
+		}
+		switch status {
+		case repository.ScanClean:
+			summary.Clean++
+		case repository.ScanQuarantined:
+			summary.Quarantined++
+			s.logger.Warn("stored file quarantined",
+				slog.String("user_id", f.UserID.String()),
+				slog.String("file_id", f.ID.String()),
+				slog.String("detail", detail),
+			)
+		default:
+			summary.Pending++
+			if avDown {
+				return summary, nil
+			}
+		}
+	}
+	return summary, nil
+}
+
+// rescan scans a stored file, returning its new status and detail, and
+// whether it is still pending because the antivirus is unavailable
+func (s *ImportService) rescan(ctx context.Context, f *repository.UserFile) (string, string, bool) {
+	r, err := s.files.GetReader(ctx, f.UserID, f.ID)
+	if err != nil {
+		return repository.ScanPending, fmt.Sprintf("failed to read file: %v", err), false
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return repository.ScanPending, fmt.Sprintf("failed to read file: %v", err), false
+	}
+
+	result, err := s.scanner.Scan(ctx, f.Type, data)
+	switch {
+	case err != nil:
+		return repository.ScanQuarantined, err.Error(), false
+	case result.MacrosStripped:
+		return repository.ScanQuarantined, "workbook contains VBA macros", false
+	}
+	return string(result.Status), result.Detail, result.Status == filescan.StatusPending
+}
+
+// applyScan records a scan result on a file about to be created
+func applyScan(f *repository.UserFile, result *filescan.Result, at time.Time) {
+	if result == nil {
+		return
+	}
+	f.ScanStatus = string(result.Status)
+	if result.Detail != "" {
+		f.ScanDetail = &result.Detail
+	}
+	if result.Status != filescan.StatusPending {
+		f.ScannedAt = &at
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
+	"github.com/google/uuid"
+)
+
+// scanImportRepo serves user files and records their scans
+type scanImportRepo struct {
+	fakeImportRepo
+	files map[uuid.UUID]*repository.UserFile
+}
+
+func (r *scanImportRepo) GetUserFileByID(ctx context.Context, id uuid.UUID) (*repository.UserFile, error) {
+	return r.files[id], nil
+}
+
+func (r *scanImportRepo) ListUnscannedFiles(ctx context.Context, limit int) ([]*repository.UserFile, error) {
+	var files []*repository.UserFile
+	for _, f := range r.files {
+		if f.ScanStatus == repository.ScanPending {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func (r *scanImportRepo) UpdateUserFileScan(ctx context.Context, fileID uuid.UUID, status string, detail *string, at time.Time) error {
+	f := r.files[fileID]
+	f.ScanStatus, f.ScanDetail, f.ScannedAt = status, detail, &at
+	return nil
+}
+
+type downAntivirus struct{}
+
+func (downAntivirus) Scan(context.Context, []byte) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestRequireScannedFile(t *testing.T) {
+	userID := uuid.New()
+	detail := "malware detected: Eicar-Test-Signature"
+	clean := &repository.UserFile{ID: uuid.New(), UserID: userID, ScanStatus: repository.ScanClean}
+	pending := &repository.UserFile{ID: uuid.New(), UserID: userID, ScanStatus: repository.ScanPending}
+	quarantined := &repository.UserFile{ID: uuid.New(), UserID: userID, ScanStatus: repository.ScanQuarantined, ScanDetail: &detail}
+	repo := &scanImportRepo{files: map[uuid.UUID]*repository.UserFile{
+		clean.ID: clean, pending.ID: pending, quarantined.ID: quarantined,
+	}}
+	svc := NewImportService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	if err := svc.RequireScannedFile(ctx, userID, clean.ID); err != nil {
+		t.Fatalf("expected a clean file to pass, got %v", err)
+	}
+	if err := svc.RequireScannedFile(ctx, userID, pending.ID); !errors.Is(err, filescan.ErrNotScanned) {
+		t.Fatalf("expected ErrNotScanned, got %v", err)
+	}
+	if err := svc.RequireScannedFile(ctx, userID, quarantined.ID); !errors.Is(err, filescan.ErrQuarantined) {
+		t.Fatalf("expected ErrQuarantined, got %v", err)
+	}
+	if err := svc.RequireScannedFile(ctx, uuid.New(), clean.ID); !errors.Is(err, ErrUserFileNotFound) {
+		t.Fatalf("expected another user's file to be not found, got %v", err)
+	}
+}
+
+func TestScanPendingFiles(t *testing.T) {
+	userID := uuid.New()
+	files := &memoryFileStorage{files: map[uuid.UUID][]byte{}}
+	csvID, exeID, missingID := uuid.New(), uuid.New(), uuid.New()
+	files.files[csvID] = []byte("Date,Amount\n01/02/2024,-2.50\n")
+	files.files[exeID] = []byte("MZ\x90\x00 not a statement")
+	repo := &scanImportRepo{files: map[uuid.UUID]*repository.UserFile{
+		csvID:     {ID: csvID, UserID: userID, Type: "csv", ScanStatus: repository.ScanPending},
+		exeID:     {ID: exeID, UserID: userID, Type: "csv", ScanStatus: repository.ScanPending},
+		missingID: {ID: missingID, UserID: userID, Type: "csv", ScanStatus: repository.ScanPending},
+	}}
+	svc := NewImportService(repo, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithFileStorage(&fakeStorageRepo{}, files, StorageQuotas{}).
+		WithScanner(filescan.New(MaxUploadBytes), repo)
+
+	summary, err := svc.ScanPendingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("ScanPendingFiles failed: %v", err)
+	}
+	if summary.Clean != 1 || summary.Quarantined != 1 || summary.Pending != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if got := repo.files[csvID].ScanStatus; got != repository.ScanClean {
+		t.Fatalf("expected the CSV to be clean, got %s", got)
+	}
+	if got := repo.files[exeID].ScanStatus; got != repository.ScanQuarantined {
+		t.Fatalf("expected the executable to be quarantined, got %s", got)
+	}
+	if got := repo.files[missingID].ScanStatus; got != repository.ScanPending {
+		t.Fatalf("expected the unreadable file to stay pending, got %s", got)
+	}
+
+	// While the antivirus is down the run stops at the first file
+	repo.files[csvID].ScanStatus = repository.ScanPending
+	repo.files[exeID].ScanStatus = repository.ScanPending
+	delete(repo.files, missingID)
+	svc.WithScanner(filescan.New(MaxUploadBytes).WithAntivirus(downAntivirus{}), repo)
+	summary, err = svc.ScanPendingFiles(context.Background())
+	if err != nil {
+		t.Fatalf("ScanPendingFiles failed: %v", err)
+	}
+	if summary.Pending != 1 || summary.Clean+summary.Quarantined > 1 {
+		t.Fatalf("expected the run to stop once the antivirus is unavailable, got %+v", summary)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"log/slog"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -22,6 +23,10 @@ import (
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/parser"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/sniffer"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
 )
 
 // ColumnMapping defines how to map CSV columns to transaction fields
@@ -36,8 +41,9 @@ type ColumnMapping struct {
 	IsEuropeanFormat bool // True for European number format (1.234,56)
 	DateFormat       string
 	Location         *time.Location
-	Delimiter        rune // Detected delimiter from AnalyzeCsvFile
-	SkipLines        int  // Number of lines to skip before header
+	Delimiter        rune   // Detected delimiter from AnalyzeCsvFile
+	SkipLines        int    // Number of lines to skip before header
+	Locale           string // Locale pack cleaning descriptors for matching, e.g. "PT" ("" = none)
 }
 
 // AnalyzeResult contains the result of analyzing an uploaded file
@@ -62,6 +68,7 @@ type ImportResult struct {
 	RowsImported int
 	RowsFailed   int
 	Errors       []string
+	Trace        []CategorizationTrace // First rows' categorization, when requested
 }
 
 // ImportOptions allows callers to override detected file settings.
@@ -69,6 +76,7 @@ type ImportOptions struct {
 	HeaderRows      int
 	Timezone        string
 	InstitutionName string // Name of the bank/institution for this import
+	TraceRows       int    // Trace the categorization of this many rows (0 = off)
 }
 
 // CategorizationService defines the interface for transaction categorization
@@ -77,6 +85,9 @@ type CategorizationService interface {
 	CategorizeBatch(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*CategorizationResult, error)
 	// CategorizeBatchFast uses Aho-Corasick for high-performance batch categorization (5M+ tx/sec)
 	CategorizeBatchFast(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*CategorizationResult, error)
+	// SuggestMatch returns the closest fuzzy rule or merchant for a description
+	// (nil if none), to explain rows that didn't categorize
+	SuggestMatch(ctx context.Context, userID uuid.UUID, description string) (*MatchSuggestion, error)
 }
 
 // CategorizationResult holds the result of categorizing a transaction
@@ -84,6 +95,30 @@ type CategorizationResult struct {
 	CleanMerchantName string
 	CategoryID        *uuid.UUID
 	IsRecurring       bool
+	RuleID            *uuid.UUID // Which rule matched, if any
+	MerchantID        *uuid.UUID // Which merchant matched, if any
+	MatchedPattern    string
+}
+
+// TransactionRuleExecutor runs the user's transaction rules, which tag and
+// rename transactions (the categorization domain)
+type TransactionRuleExecutor interface {
+	// ApplyTransactionRules returns outcomes aligned to inputs, nil where no
+	// rule fired
+	ApplyTransactionRules(ctx context.Context, userID uuid.UUID, inputs []TransactionRuleInput) ([]*TransactionRuleOutcome, error)
+}
+
+// TransactionRuleInput is a transaction to run the rules on
+type TransactionRuleInput struct {
+	Description string
+	AmountMinor int64
+}
+
+// TransactionRuleOutcome is what the rules did to a transaction
+type TransactionRuleOutcome struct {
+	Tags     []string
+	RenameTo string // New merchant name, or ""
+	RuleIDs  []uuid.UUID
 }
 
 // InsightsService defines the interface for computing import insights
@@ -92,6 +127,21 @@ type InsightsService interface {
 	RefreshDataSourceHealth(ctx context.Context) error
 }
 
+// RewardsDetector flags cash-back and reward credits among imported transactions
+type RewardsDetector interface {
+	DetectRewards(ctx context.Context, userID uuid.UUID, since time.Time) error
+}
+
+// TransactionQuota caps the transactions a user can keep (the quotas domain)
+type TransactionQuota interface {
+	CheckTransactions(ctx context.Context, userID uuid.UUID, adding int) error
+}
+
+// ImportListener is told when an import job adds transactions
+type ImportListener interface {
+	ImportCompleted(ctx context.Context, userID uuid.UUID, result *ImportResult, institutionName string)
+}
+
 // ImportInsights contains computed quality metrics for an import job
 type ImportInsights struct {
 	ImportJobID        uuid.UUID
@@ -119,14 +169,28 @@ type ImportIssue struct {
 // ImportService orchestrates file analysis and import operations
 type ImportService struct {
 	repo        repository.ImportRepository
-	catService  CategorizationService // Optional: nil if categorization not available
-	insightsSvc InsightsService       // Optional: nil if insights not available
+	catService  CategorizationService        // Optional: nil if categorization not available
+	txRules     TransactionRuleExecutor      // Optional: nil skips transaction rules
+	insightsSvc InsightsService              // Optional: nil if insights not available
+	rewards     RewardsDetector              // Optional: nil if reward detection not available
+	listener    ImportListener               // Optional: nil if nothing follows imports
+	txQuota     TransactionQuota             // Optional: nil leaves transactions unlimited
+	storageRepo repository.StorageRepository // Optional: nil disables storage quotas and cleanup
+	files       storage.Storage
+	quotas      StorageQuotas
+	uploads     repository.UploadRepository // Optional: nil disables chunked uploads
+	scanner     *filescan.Scanner
+	scans       repository.ScanRepository     // Optional: nil disables rescans
+	locations   repository.LocationRepository // Optional: nil disables pinning and the spending map
+	queue       *workqueue.Queue              // Optional: nil runs post-import work in its own goroutine
 	logger      *slog.Logger
+	now         func() time.Time
 }
 
 const (
 	importBatchSize           = 500
 	importProgressUpdateEvery = 500
+	postImportTimeout         = 30 * time.Second
 )
 
 type parseJob struct {
@@ -143,8 +207,10 @@ type parseResult struct {
 // NewImportService creates a new import service
 func NewImportService(repo repository.ImportRepository, logger *slog.Logger) *ImportService {
 	return &ImportService{
-		repo:   repo,
-		logger: logger,
+		repo:    repo,
+		logger:  logger,
+		now:     time.Now,
+		scanner: filescan.New(MaxUploadBytes),
 	}
 }
 
@@ -154,12 +220,53 @@ func (s *ImportService) WithCategorizationService(catService CategorizationServi
 	return s
 }
 
+// WithTransactionRules runs the user's transaction rules on imported
+// transactions after categorization
+func (s *ImportService) WithTransactionRules(rules TransactionRuleExecutor) *ImportService {
+	s.txRules = rules
+	return s
+}
+
 // WithInsightsService adds import insights support to the import service
 func (s *ImportService) WithInsightsService(insightsSvc InsightsService) *ImportService {
 	s.insightsSvc = insightsSvc
 	return s
 }
 
+// WithRewardsDetector adds cash-back/reward detection to imports
+func (s *ImportService) WithRewardsDetector(rewards RewardsDetector) *ImportService {
+	s.rewards = rewards
+	return s
+}
+
+// WithWorkQueue runs post-import insights and reward detection on the work
+// queue's interactive workers, ahead of any backfill
+func (s *ImportService) WithWorkQueue(queue *workqueue.Queue) *ImportService {
+	s.queue = queue
+	return s
+}
+
+// WithImportListener notifies the listener after an import adds transactions
+func (s *ImportService) WithImportListener(listener ImportListener) *ImportService {
+	s.listener = listener
+	return s
+}
+
+// WithTransactionQuota checks imports against the user's transaction quota
+func (s *ImportService) WithTransactionQuota(quota TransactionQuota) *ImportService {
+	s.txQuota = quota
+	return s
+}
+
+// CheckTransactionQuota returns an error wrapping the quota's when adding
+// transactions would put the user over it. Always passes without a quota.
+func (s *ImportService) CheckTransactionQuota(ctx context.Context, userID uuid.UUID, adding int) error {
+	if s.txQuota == nil {
+		return nil
+	}
+	return s.txQuota.CheckTransactions(ctx, userID, adding)
+}
+
 // AnalyzeFile analyzes an uploaded CSV/TSV file and determines if it can be auto-imported
 func (s *ImportService) AnalyzeFile(ctx context.Context, userID uuid.UUID, fileData []byte) (*AnalyzeResult, error) {
 	// Step 1: Detect file configuration
@@ -235,6 +342,9 @@ func (s *ImportService) SaveMapping(ctx context.Context, userID uuid.UUID, finge
 		CreditCol:        creditCol,
 		IsEuropeanFormat: mapping.IsEuropeanFormat,
 	}
+	if pack, ok := normalizer.LocalePackFor(mapping.Locale); ok {
+		m.Locale = &pack.Code
+	}
 
 	return s.repo.CreateMapping(ctx, m)
 }
@@ -246,6 +356,10 @@ func (s *ImportService) ImportWithMapping(ctx context.Context, userID uuid.UUID,
 
 // ImportWithOptions processes a file using the provided column mapping and options.
 func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID, accountID *uuid.UUID, fileData []byte, mapping ColumnMapping, opts ImportOptions) (*ImportResult, error) {
+	if err := s.CheckTransactionQuota(ctx, userID, 1); err != nil {
+		return nil, err
+	}
+
 	normalizedData := normalizeCSVBytes(fileData)
 
 	detectOpts := &sniffer.DetectOptions{HeaderRowIndex: -1}
@@ -275,6 +389,9 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 
 	applyFormatDefaults(config, &resolvedMapping)
 	resolvedMapping.Location = resolveLocation(opts.Timezone)
+	if resolvedMapping.Locale == "" {
+		resolvedMapping.Locale = s.mappingLocale(ctx, userID, config.Fingerprint)
+	}
 
 	currencyCode, err := s.resolveCurrencyCode(ctx, userID, accountID, normalizedData, config)
 	if err != nil {
@@ -288,6 +405,7 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 		MimeType:  "text/csv",
 		FileName:  "import.csv",
 		SizeBytes: int64(len(fileData)),
+		Purpose:   repository.FilePurposeStatement,
 	}
 	if err := s.repo.CreateUserFile(ctx, fileRecord); err != nil {
 		return nil, fmt.Errorf("failed to create file record: %w", err)
@@ -310,6 +428,19 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 	defer cancel()
 
 	results, preErrors := s.parseTransactionsStream(parseCtx, normalizedData, config, resolvedMapping)
+	return s.runImportJob(ctx, job, currencyCode, opts.InstitutionName, opts.TraceRows, results, preErrors, cancel)
+}
+
+// runImportJob inserts parsed transactions under job in batches, enriching them
+// with categorization, then finishes the job and computes import insights and
+// reward credits in the background. Each batch is checked against the
+// transaction quota, counting every row as new; the job fails at the first
+// batch over it, keeping the batches before. The first traceRows rows get a
+// categorization trace. cancel stops the producer of results when an insert
+// fails.
+func (s *ImportService) runImportJob(ctx context.Context, job *repository.ImportJob, currencyCode, institutionName string, traceRows int, results <-chan parseResult, preErrors []string, cancel context.CancelFunc) (*ImportResult, error) {
+	userID, accountID := job.UserID, job.AccountID
+	startedAt := time.Now()
 
 	errors := make([]string, 0, len(preErrors))
 	errors = append(errors, preErrors...)
@@ -323,7 +454,9 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 
 	var parseErrors []parseError
 	batch := make([]*repository.ParsedTransaction, 0, importBatchSize)
+	batchLines := make([]int, 0, importBatchSize)
 	progressSinceUpdate := rowsFailed
+	tracer := newCategorizationTracer(traceRows)
 
 	updateProgress := func() {
 		if err := s.repo.UpdateImportJobProgress(ctx, job.ID, rowsImported, rowsFailed); err != nil {
@@ -335,16 +468,33 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 		if len(batch) == 0 {
 			return nil
 		}
+		// Rows the file already categorized keep their category
+		var fromFile []bool
+		for i := 0; i < len(batch) && i < tracer.wants(); i++ {
+			fromFile = append(fromFile, batch[i].CategoryID != nil)
+		}
 		// Enrich transactions with categorization if service is available
+		var catResults []*CategorizationResult
 		if s.catService != nil {
-			s.enrichBatch(ctx, userID, batch)
+			catResults = s.enrichBatch(ctx, userID, batch)
+		}
+		if s.txRules != nil {
+			s.applyTransactionRules(ctx, userID, batch)
 		}
-		imported, err := s.repo.BulkInsertTransactions(ctx, userID, accountID, currencyCode, job.ID, opts.InstitutionName, batch)
+		if len(fromFile) > 0 {
+			s.recordTraces(ctx, tracer, userID, batch, batchLines, fromFile, catResults)
+		}
+		if err := s.CheckTransactionQuota(ctx, userID, len(batch)); err != nil {
+			return err
+		}
+		imported, err := s.repo.BulkInsertTransactions(ctx, userID, accountID, currencyCode, job.ID, institutionName, batch)
 		if err != nil {
 			return err
 		}
 		rowsImported += imported
+		observability.ImportRowsTotal.WithLabelValues("imported").Add(float64(imported))
 		batch = batch[:0]
+		batchLines = batchLines[:0]
 		updateProgress()
 		progressSinceUpdate = 0
 		return nil
@@ -367,6 +517,7 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 		}
 
 		batch = append(batch, result.tx)
+		batchLines = append(batchLines, result.lineNum)
 		if len(batch) >= importBatchSize {
 			if err := flushBatch(); err != nil {
 				insertErr = err
@@ -397,6 +548,7 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 	if insertErr != nil {
 		errMsg := insertErr.Error()
 		s.repo.FinishImportJob(ctx, job.ID, "failed", rowsImported, rowsFailed, &errMsg)
+		recordImportMetrics("failed", rowsFailed, startedAt)
 		return nil, fmt.Errorf("failed to insert transactions: %w", insertErr)
 	}
 
@@ -405,38 +557,79 @@ func (s *ImportService) ImportWithOptions(ctx context.Context, userID uuid.UUID,
 	if err := s.repo.FinishImportJob(ctx, job.ID, status, rowsImported, rowsFailed, nil); err != nil {
 		s.logger.Warn("failed to finish import job", "error", err)
 	}
+	recordImportMetrics(status, rowsFailed, startedAt)
 
 	// Compute and store import insights (async, non-blocking)
 	if s.insightsSvc != nil && rowsImported > 0 {
-		go func() {
-			insightsCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
-
-			insights, err := s.computeImportInsights(insightsCtx, job.ID, opts.InstitutionName, currencyCode)
+		s.afterImport(job.ID, "import_insights", func(insightsCtx context.Context) error {
+			insights, err := s.computeImportInsights(insightsCtx, job.ID, institutionName, currencyCode)
 			if err != nil {
 				s.logger.Warn("failed to compute import insights", "jobID", job.ID, "error", err)
-				return
+				return err
 			}
 
 			if err := s.insightsSvc.UpsertImportInsights(insightsCtx, insights); err != nil {
 				s.logger.Warn("failed to store import insights", "jobID", job.ID, "error", err)
-				return
+				return err
 			}
 
 			// Refresh the data source health view
 			if err := s.insightsSvc.RefreshDataSourceHealth(insightsCtx); err != nil {
 				s.logger.Warn("failed to refresh data source health", "error", err)
+				return err
 			}
-		}()
+			return nil
+		})
 	}
 
-	return &ImportResult{
+	// Flag reward credits so they aren't counted as income (async, non-blocking)
+	if s.rewards != nil && rowsImported > 0 {
+		s.afterImport(job.ID, "import_rewards_detection", func(rewardsCtx context.Context) error {
+			since := time.Now().AddDate(-1, 0, 0)
+			if stats, err := s.repo.GetImportJobStats(rewardsCtx, job.ID); err == nil && stats.EarliestDate != nil {
+				since = *stats.EarliestDate
+			}
+			if err := s.rewards.DetectRewards(rewardsCtx, userID, since); err != nil {
+				s.logger.Warn("failed to detect rewards", "jobID", job.ID, "error", err)
+				return err
+			}
+			return nil
+		})
+	}
+
+	result := &ImportResult{
 		JobID:        job.ID,
 		RowsTotal:    rowsImported + rowsFailed,
 		RowsImported: rowsImported,
 		RowsFailed:   rowsFailed,
 		Errors:       errors,
-	}, nil
+		Trace:        tracer.result(),
+	}
+	if s.listener != nil && rowsImported > 0 {
+		s.listener.ImportCompleted(ctx, userID, result, institutionName)
+	}
+	return result, nil
+}
+
+// afterImport runs follow-up work for a finished import in the background, as
+// interactive work: the user is waiting to see it on their dashboard
+func (s *ImportService) afterImport(jobID uuid.UUID, name string, run func(ctx context.Context) error) {
+	err := s.queue.Submit(workqueue.Task{
+		Name:    name,
+		Class:   workqueue.Interactive,
+		Timeout: postImportTimeout,
+		Run:     run,
+	})
+	if err != nil {
+		s.logger.Warn("failed to queue post-import work", "jobID", jobID, "task", name, "error", err)
+	}
+}
+
+// recordImportMetrics counts a finished job's failed rows and times it;
+// imported rows are counted as each batch is inserted
+func recordImportMetrics(status string, rowsFailed int, startedAt time.Time) {
+	observability.ImportRowsTotal.WithLabelValues("failed").Add(float64(rowsFailed))
+	observability.ImportDuration.WithLabelValues(status).Observe(time.Since(startedAt).Seconds())
 }
 
 // computeImportInsights queries the imported transactions and computes quality metrics
@@ -490,6 +683,12 @@ func (s *ImportService) ImportWithExistingMapping(ctx context.Context, userID uu
 	return nil, fmt.Errorf("import with existing mapping not yet implemented")
 }
 
+// ListImportJobs returns the user's import history, newest first, and the
+// number of jobs matching the filter
+func (s *ImportService) ListImportJobs(ctx context.Context, userID uuid.UUID, filter repository.ListImportJobsFilter) ([]*repository.ImportJob, int64, error) {
+	return s.repo.ListImportJobs(ctx, userID, filter)
+}
+
 // parseTransactionsStream streams parsed rows from a CSV file.
 func (s *ImportService) parseTransactionsStream(ctx context.Context, fileData []byte, config *sniffer.FileConfig, mapping ColumnMapping) (<-chan parseResult, []string) {
 	results := make(chan parseResult, 1)
@@ -631,12 +830,30 @@ func (s *ImportService) parseRow(record []string, mapping ColumnMapping, _ int)
 		category = normalizer.CleanDescription(record[mapping.CategoryCol])
 	}
 
-	return &repository.ParsedTransaction{
+	tx := &repository.ParsedTransaction{
 		Date:        date,
 		Description: description,
 		AmountCents: amountCents,
 		Category:    category,
-	}, nil
+	}
+	if cleaner := normalizer.DescriptorCleanerFor(mapping.Locale); cleaner != nil {
+		tx.MatchDescription = cleaner.Clean(description)
+	}
+	return tx, nil
+}
+
+// mappingLocale returns the locale of the institution's saved mapping for a
+// file fingerprint, or "" when it has none
+func (s *ImportService) mappingLocale(ctx context.Context, userID uuid.UUID, fingerprint string) string {
+	mapping, err := s.repo.GetMappingByFingerprint(ctx, fingerprint, &userID)
+	if err != nil {
+		s.logger.Warn("failed to look up mapping locale", "error", err)
+		return ""
+	}
+	if mapping == nil || mapping.Locale == nil {
+		return ""
+	}
+	return *mapping.Locale
 }
 
 // ============================================================================
@@ -1127,36 +1344,97 @@ func hasDecimalSuffix(value string, sep rune) bool {
 }
 
 // enrichBatch calls the categorization service to populate MerchantName and CategoryID
-// Uses the high-performance Aho-Corasick batch categorization for maximum throughput
-func (s *ImportService) enrichBatch(ctx context.Context, userID uuid.UUID, batch []*repository.ParsedTransaction) {
+// Uses the high-performance Aho-Corasick batch categorization for maximum throughput.
+// Returns the results aligned to batch, or nil if categorization failed.
+func (s *ImportService) enrichBatch(ctx context.Context, userID uuid.UUID, batch []*repository.ParsedTransaction) []*CategorizationResult {
 	if s.catService == nil || len(batch) == 0 {
-		return
+		return nil
 	}
 
-	// Collect descriptions
+	// Collect descriptions, cleaned by the institution's locale pack if any
 	descriptions := make([]string, len(batch))
 	for i, tx := range batch {
 		descriptions[i] = tx.Description
+		if tx.MatchDescription != "" {
+			descriptions[i] = tx.MatchDescription
+		}
 	}
 
 	// Try fast categorization first (Aho-Corasick, 5M+ tx/sec)
-	results, err := s.catService.CategorizeBatchFast(ctx, userID, descriptions)
+	results, err := timedCategorization("fast", descriptions, func() ([]*CategorizationResult, error) {
+		return s.catService.CategorizeBatchFast(ctx, userID, descriptions)
+	})
 	if err != nil {
 		// Fall back to standard batch categorization
-		results, err = s.catService.CategorizeBatch(ctx, userID, descriptions)
+		results, err = timedCategorization("standard", descriptions, func() ([]*CategorizationResult, error) {
+			return s.catService.CategorizeBatch(ctx, userID, descriptions)
+		})
 		if err != nil {
 			s.logger.Warn("categorization failed, using raw descriptions", "error", err)
-			return
+			return nil
 		}
 	}
 
-	// Apply results
+	// Apply results, keeping merchants and categories the source already provided
 	for i, result := range results {
 		if i < len(batch) && result != nil {
-			batch[i].MerchantName = result.CleanMerchantName
-			batch[i].CategoryID = result.CategoryID
+			if batch[i].MerchantName == "" {
+				batch[i].MerchantName = result.CleanMerchantName
+			}
+			if batch[i].CategoryID == nil && result.CategoryID != nil {
+				batch[i].CategoryID = result.CategoryID
+				batch[i].AutoCategoryID = result.CategoryID
+				batch[i].RuleID = result.RuleID
+				batch[i].MerchantID = result.MerchantID
+				source := "merchant"
+				if result.RuleID != nil {
+					source = "rule"
+				}
+				observability.CategorizationPredictionsTotal.WithLabelValues(source).Inc()
+			}
+		}
+	}
+	return results
+}
+
+// applyTransactionRules tags and renames the batch's transactions by the
+// user's transaction rules. Renames win over the merchant names from the
+// source and categorization. It fails open, leaving the batch as it is.
+func (s *ImportService) applyTransactionRules(ctx context.Context, userID uuid.UUID, batch []*repository.ParsedTransaction) {
+	inputs := make([]TransactionRuleInput, len(batch))
+	for i, tx := range batch {
+		inputs[i] = TransactionRuleInput{Description: tx.Description, AmountMinor: tx.AmountCents}
+	}
+	outcomes, err := s.txRules.ApplyTransactionRules(ctx, userID, inputs)
+	if err != nil {
+		s.logger.Warn("transaction rules failed, importing without them", "error", err)
+		return
+	}
+	for i, outcome := range outcomes {
+		if i >= len(batch) || outcome == nil {
+			continue
+		}
+		for _, tag := range outcome.Tags {
+			if !slices.Contains(batch[i].Tags, tag) {
+				batch[i].Tags = append(batch[i].Tags, tag)
+			}
 		}
+		if outcome.RenameTo != "" {
+			batch[i].MerchantName = outcome.RenameTo
+		}
+	}
+}
+
+// timedCategorization runs a batch categorization, recording its duration and
+// the descriptions it categorized by method
+func timedCategorization(method string, descriptions []string, categorize func() ([]*CategorizationResult, error)) ([]*CategorizationResult, error) {
+	start := time.Now()
+	results, err := categorize()
+	observability.CategorizationBatchDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err == nil {
+		observability.CategorizationDescriptionsTotal.WithLabelValues(method).Add(float64(len(descriptions)))
 	}
+	return results, err
 }
 
 // ============================================================================
@@ -1173,6 +1451,8 @@ type CreateUserFileInput struct {
 	SizeBytes  int64
 	Checksum   string
 	StorageURL string
+	Purpose    repository.FilePurpose // Guessed from Type when empty
+	Scan       *filescan.Result       // Outcome of ScanUpload; the file stays pending without one
 }
 
 // UserFile represents a stored user file
@@ -1185,6 +1465,7 @@ type UserFile struct {
 	SizeBytes  int64
 	Checksum   string
 	StorageURL string
+	ScanStatus string // "pending", "clean" or "quarantined"
 	CreatedAt  time.Time
 }
 
@@ -1193,6 +1474,11 @@ func (s *ImportService) CreateUserFile(ctx context.Context, input CreateUserFile
 	storageURL := input.StorageURL
 	checksum := input.Checksum
 
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = purposeForFileType(input.Type)
+	}
+
 	uf := &repository.UserFile{
 		ID:             input.FileID,
 		UserID:         input.UserID,
@@ -1202,7 +1488,9 @@ func (s *ImportService) CreateUserFile(ctx context.Context, input CreateUserFile
 		SizeBytes:      input.SizeBytes,
 		ChecksumSHA256: &checksum,
 		StorageURL:     &storageURL,
+		Purpose:        purpose,
 	}
+	applyScan(uf, input.Scan, s.now())
 
 	if err := s.repo.CreateUserFile(ctx, uf); err != nil {
 		return nil, fmt.Errorf("create user file: %w", err)
@@ -1217,6 +1505,7 @@ func (s *ImportService) CreateUserFile(ctx context.Context, input CreateUserFile
 		SizeBytes:  uf.SizeBytes,
 		Checksum:   checksum,
 		StorageURL: storageURL,
+		ScanStatus: uf.ScanStatus,
 		CreatedAt:  uf.CreatedAt,
 	}, nil
 }
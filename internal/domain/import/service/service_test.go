@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/sniffer"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
 	"github.com/google/uuid"
 )
 
@@ -334,6 +336,158 @@ func collectParseResults(results <-chan parseResult) ([]*repository.ParsedTransa
 	return transactions, parseErrors
 }
 
+func TestImportTransactionsJSON_SkipsInvalidTransactions(t *testing.T) {
+	txs, err := ParseJSONTransactions([]byte(`{"transactions": [
+		{"date": "2024-02-13", "description": "Coffee", "amount_minor": -350, "external_id": "abc-1"},
+		{"date": "2024-02-14T09:30:00Z", "description": "Salary", "amount_minor": 250000, "currency_code": "usd"},
+		{"date": "13/02/2024", "description": "Bad date", "amount_minor": -100},
+		{"date": "2024-02-15", "description": "No amount"},
+		{"date": "2024-02-15", "description": "Wrong currency", "amount_minor": -100, "currency_code": "EUR"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseJSONTransactions failed: %v", err)
+	}
+	if len(txs) != 5 {
+		t.Fatalf("expected 5 transactions, got %d", len(txs))
+	}
+
+	repo := &fakeImportRepo{}
+	svc := NewImportService(repo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	result, err := svc.ImportTransactionsJSON(context.Background(), uuid.New(), nil, txs, JSONImportOptions{CurrencyCode: "USD"})
+	if err != nil {
+		t.Fatalf("ImportTransactionsJSON failed: %v", err)
+	}
+	if result.RowsImported != 2 {
+		t.Fatalf("expected 2 imported transactions, got %d", result.RowsImported)
+	}
+	if result.RowsFailed != 3 {
+		t.Fatalf("expected 3 failed transactions, got %d (%v)", result.RowsFailed, result.Errors)
+	}
+	if !strings.HasPrefix(result.Errors[0], "transaction 3:") {
+		t.Fatalf("expected errors to reference the transaction position, got %q", result.Errors[0])
+	}
+
+	if _, err := svc.ImportTransactionsJSON(context.Background(), uuid.New(), nil, nil, JSONImportOptions{}); err != ErrEmptyJSONImport {
+		t.Fatalf("expected ErrEmptyJSONImport, got %v", err)
+	}
+}
+
+func TestResolveJSONCurrency(t *testing.T) {
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	code, err := svc.resolveJSONCurrency(ctx, uuid.New(), nil, []JSONTransaction{{CurrencyCode: "eur"}, {}}, "")
+	if err != nil || code != "EUR" {
+		t.Fatalf("expected EUR from the transactions, got %q (%v)", code, err)
+	}
+
+	if _, err := svc.resolveJSONCurrency(ctx, uuid.New(), nil, []JSONTransaction{{CurrencyCode: "EUR"}, {CurrencyCode: "USD"}}, ""); err == nil {
+		t.Fatal("expected an error for mixed currencies")
+	}
+	if _, err := svc.resolveJSONCurrency(ctx, uuid.New(), nil, []JSONTransaction{{}}, ""); err == nil {
+		t.Fatal("expected an error when no currency is known")
+	}
+}
+
+// fakeStorageRepo serves fixed usage and purgeable files
+type fakeStorageRepo struct {
+	tier   repository.StorageTier
+	usage  []repository.StorageUsage
+	files  []*repository.UserFile
+	purged map[uuid.UUID]bool
+}
+
+func (f *fakeStorageRepo) GetStorageUsage(ctx context.Context, userID uuid.UUID) ([]repository.StorageUsage, error) {
+	return f.usage, nil
+}
+
+func (f *fakeStorageRepo) GetStorageTier(ctx context.Context, userID uuid.UUID) (repository.StorageTier, error) {
+	return f.tier, nil
+}
+
+func (f *fakeStorageRepo) ListImportedStatementFiles(ctx context.Context, userID uuid.UUID, limit int) ([]*repository.UserFile, error) {
+	return f.files, nil
+}
+
+func (f *fakeStorageRepo) MarkUserFilePurged(ctx context.Context, userID, fileID uuid.UUID) error {
+	f.purged[fileID] = true
+	return nil
+}
+
+// fakeFileStorage fails to delete the files in failing
+type fakeFileStorage struct {
+	storage.Storage
+	failing map[uuid.UUID]bool
+	deleted []uuid.UUID
+}
+
+func (f *fakeFileStorage) Delete(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	if f.failing[fileID] {
+		return fmt.Errorf("disk unavailable")
+	}
+	f.deleted = append(f.deleted, fileID)
+	return nil
+}
+
+func TestCheckStorageQuota_UsesTierQuota(t *testing.T) {
+	repo := &fakeStorageRepo{
+		tier: repository.StorageTierFree,
+		usage: []repository.StorageUsage{
+			{Purpose: repository.FilePurposeStatement, Files: 3, Bytes: 600},
+			{Purpose: repository.FilePurposeReceipt, Files: 2, Bytes: 300},
+		},
+	}
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithFileStorage(repo, &fakeFileStorage{}, StorageQuotas{Free: 1000, Premium: 5000})
+	ctx := context.Background()
+
+	usage, err := svc.GetStorageUsage(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("GetStorageUsage failed: %v", err)
+	}
+	if usage.UsedBytes != 900 || usage.QuotaBytes != 1000 || usage.RemainingBytes() != 100 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+
+	if err := svc.CheckStorageQuota(ctx, uuid.New(), 100); err != nil {
+		t.Fatalf("expected an upload that fits to pass, got %v", err)
+	}
+	if err := svc.CheckStorageQuota(ctx, uuid.New(), 101); !errors.Is(err, ErrStorageQuotaExceeded) {
+		t.Fatalf("expected ErrStorageQuotaExceeded, got %v", err)
+	}
+
+	repo.tier = repository.StorageTierPremium
+	if err := svc.CheckStorageQuota(ctx, uuid.New(), 101); err != nil {
+		t.Fatalf("expected the premium quota to apply, got %v", err)
+	}
+}
+
+func TestCleanupImportedStatements_KeepsFailedFiles(t *testing.T) {
+	kept, failing := uuid.New(), uuid.New()
+	repo := &fakeStorageRepo{
+		files: []*repository.UserFile{
+			{ID: kept, SizeBytes: 400},
+			{ID: failing, SizeBytes: 700},
+		},
+		purged: map[uuid.UUID]bool{},
+	}
+	files := &fakeFileStorage{failing: map[uuid.UUID]bool{failing: true}}
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithFileStorage(repo, files, StorageQuotas{})
+
+	result, err := svc.CleanupImportedStatements(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("CleanupImportedStatements failed: %v", err)
+	}
+	if result.FilesDeleted != 1 || result.BytesFreed != 400 || result.FilesFailed != 1 {
+		t.Fatalf("unexpected cleanup result: %+v", result)
+	}
+	if !repo.purged[kept] || repo.purged[failing] {
+		t.Fatalf("expected only the deleted file to be marked purged, got %v", repo.purged)
+	}
+}
+
 type progressSnapshot struct {
 	rowsImported int
 	rowsFailed   int
@@ -388,6 +542,10 @@ func (f *fakeImportRepo) GetImportJobByID(ctx context.Context, id uuid.UUID) (*r
 	return nil, nil
 }
 
+func (f *fakeImportRepo) ListImportJobs(ctx context.Context, userID uuid.UUID, filter repository.ListImportJobsFilter) ([]*repository.ImportJob, int64, error) {
+	return nil, 0, nil
+}
+
 func (f *fakeImportRepo) GetImportJobStats(ctx context.Context, importJobID uuid.UUID) (*repository.ImportJobStats, error) {
 	return &repository.ImportJobStats{
 		TotalCount:         0,
@@ -745,3 +903,119 @@ func loadCaixaFixture(b *testing.B) ([]byte, *sniffer.FileConfig, ColumnMapping)
 
 	return data, config, mapping
 }
+
+// fakeCategorizer categorizes descriptions containing "COFFEE" by rule
+type fakeCategorizer struct {
+	ruleID uuid.UUID
+}
+
+func (f *fakeCategorizer) CategorizeBatch(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*CategorizationResult, error) {
+	return f.CategorizeBatchFast(ctx, userID, descriptions)
+}
+
+func (f *fakeCategorizer) CategorizeBatchFast(_ context.Context, _ uuid.UUID, descriptions []string) ([]*CategorizationResult, error) {
+	results := make([]*CategorizationResult, len(descriptions))
+	for i, desc := range descriptions {
+		results[i] = &CategorizationResult{CleanMerchantName: desc}
+		if strings.Contains(strings.ToUpper(desc), "COFFEE") {
+			results[i].RuleID = &f.ruleID
+			results[i].MatchedPattern = "COFFEE"
+		}
+	}
+	return results, nil
+}
+
+func (f *fakeCategorizer) SuggestMatch(context.Context, uuid.UUID, string) (*MatchSuggestion, error) {
+	return &MatchSuggestion{Pattern: "COFFEE", Score: 40, IsRule: true}, nil
+}
+
+func TestImportTransactionsJSON_TracesFirstRows(t *testing.T) {
+	cat := &fakeCategorizer{ruleID: uuid.New()}
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithCategorizationService(cat)
+
+	amount := func(v int64) *int64 { return &v }
+	txs := []JSONTransaction{
+		{Date: "2024-02-13", Description: "Coffee Shop", AmountMinor: amount(-350)},
+		{Date: "2024-02-14", Description: "CAFE LISBOA", AmountMinor: amount(-200)},
+		{Date: "2024-02-15", Description: "Groceries", AmountMinor: amount(-4000)},
+	}
+	result, err := svc.ImportTransactionsJSON(context.Background(), uuid.New(), nil, txs, JSONImportOptions{CurrencyCode: "EUR", TraceRows: 2})
+	if err != nil {
+		t.Fatalf("ImportTransactionsJSON failed: %v", err)
+	}
+	if len(result.Trace) != 2 {
+		t.Fatalf("expected 2 traced rows, got %d", len(result.Trace))
+	}
+
+	first := result.Trace[0]
+	if first.Line != 1 || first.Source != TraceSourceRule || first.RuleID == nil || *first.RuleID != cat.ruleID || first.Pattern != "COFFEE" {
+		t.Fatalf("expected row 1 to be traced to the COFFEE rule, got %+v", first)
+	}
+	second := result.Trace[1]
+	if second.Source != TraceSourceNone || second.Suggestion == nil || second.Suggestion.Pattern != "COFFEE" {
+		t.Fatalf("expected row 2 to be unmatched with a suggestion, got %+v", second)
+	}
+
+	result, err = svc.ImportTransactionsJSON(context.Background(), uuid.New(), nil, txs, JSONImportOptions{CurrencyCode: "EUR"})
+	if err != nil {
+		t.Fatalf("ImportTransactionsJSON failed: %v", err)
+	}
+	if result.Trace != nil {
+		t.Fatalf("expected no trace by default, got %d rows", len(result.Trace))
+	}
+}
+
+// fakeTransactionRules tags and renames descriptions containing "UBER"
+type fakeTransactionRules struct{}
+
+func (fakeTransactionRules) ApplyTransactionRules(_ context.Context, _ uuid.UUID, inputs []TransactionRuleInput) ([]*TransactionRuleOutcome, error) {
+	outcomes := make([]*TransactionRuleOutcome, len(inputs))
+	for i, in := range inputs {
+		if strings.Contains(in.Description, "UBER") && in.AmountMinor < -3000 {
+			outcomes[i] = &TransactionRuleOutcome{Tags: []string{"travel"}, RenameTo: "Uber ride"}
+		}
+	}
+	return outcomes, nil
+}
+
+func TestApplyTransactionRules_TagsAndRenames(t *testing.T) {
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithTransactionRules(fakeTransactionRules{})
+
+	batch := []*repository.ParsedTransaction{
+		{Description: "UBER *TRIP", MerchantName: "Uber Trip", AmountCents: -3550, Tags: []string{"travel"}},
+		{Description: "UBER *TRIP", MerchantName: "Uber Trip", AmountCents: -1200},
+	}
+	svc.applyTransactionRules(context.Background(), uuid.New(), batch)
+
+	if batch[0].MerchantName != "Uber ride" || len(batch[0].Tags) != 1 || batch[0].Tags[0] != "travel" {
+		t.Fatalf("expected the first row renamed and tagged once, got %+v", batch[0])
+	}
+	if batch[1].MerchantName != "Uber Trip" || batch[1].Tags != nil {
+		t.Fatalf("expected the second row untouched, got %+v", batch[1])
+	}
+}
+
+func TestParseRow_CleansDescriptorsWithMappingLocale(t *testing.T) {
+	svc := NewImportService(&fakeImportRepo{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mapping := ColumnMapping{DateCol: 0, DescCol: 1, AmountCol: 2, CategoryCol: -1, DebitCol: -1, CreditCol: -1, IsEuropeanFormat: true, DateFormat: "DD-MM-YYYY"}
+	record := []string{"13-02-2024", "COMPRA 1234 CONTINENTE MAI LISBOA", "-12,50"}
+
+	tx, err := svc.parseRow(record, mapping, 1)
+	if err != nil {
+		t.Fatalf("parseRow failed: %v", err)
+	}
+	if tx.MatchDescription != "" {
+		t.Fatalf("expected no cleaning without a locale, got %q", tx.MatchDescription)
+	}
+
+	mapping.Locale = "PT"
+	tx, err = svc.parseRow(record, mapping, 1)
+	if err != nil {
+		t.Fatalf("parseRow failed: %v", err)
+	}
+	if tx.Description != "COMPRA 1234 CONTINENTE MAI LISBOA" || tx.MatchDescription != "CONTINENTE" {
+		t.Fatalf("expected the raw description kept and CONTINENTE matched, got %q / %q", tx.Description, tx.MatchDescription)
+	}
+}
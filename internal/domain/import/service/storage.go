@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// =============================================================================
+// Storage Usage and Cleanup (Internal Integration)
+// =============================================================================
+// Uploads count against a per-user quota that depends on the subscription tier;
+// UploadUserFile rejects files that don't fit. Statement files that were imported
+// can be deleted to free space, keeping their rows and checksums for audit.
+//
+// Storage usage is reported with the user's other quotas by the quotas
+// service.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CleanupImportedFilesRequest/Response (ImportService.CleanupImportedFiles)
+
+const (
+	// DefaultFreeStorageQuota is the storage quota of free users, in bytes
+	DefaultFreeStorageQuota int64 = 250 << 20
+	// DefaultPremiumStorageQuota is the storage quota of paying users, in bytes
+	DefaultPremiumStorageQuota int64 = 10 << 30
+	// cleanupBatchSize caps the files deleted by one cleanup
+	cleanupBatchSize = 500
+)
+
+var (
+	// ErrStorageQuotaExceeded is returned when an upload doesn't fit the user's quota
+	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrFileStorageDisabled is returned when no file storage is configured
+	ErrFileStorageDisabled = errors.New("file storage is not configured")
+)
+
+// StorageQuotas are the storage quotas per tier, in bytes
+type StorageQuotas struct {
+	Free    int64
+	Premium int64
+}
+
+// For returns the quota of a tier
+func (q StorageQuotas) For(tier repository.StorageTier) int64 {
+	if tier == repository.StorageTierPremium {
+		return q.Premium
+	}
+	return q.Free
+}
+
+// StorageUsage is a user's storage use against their quota
+type StorageUsage struct {
+	Tier       repository.StorageTier
+	QuotaBytes int64
+	UsedBytes  int64
+	ByPurpose  []repository.StorageUsage
+}
+
+// RemainingBytes is what can still be uploaded, never negative
+func (u *StorageUsage) RemainingBytes() int64 {
+	return max(u.QuotaBytes-u.UsedBytes, 0)
+}
+
+// CleanupResult reports the statement files a cleanup deleted
+type CleanupResult struct {
+	FilesDeleted int
+	BytesFreed   int64
+	FilesFailed  int // Left in place; a later cleanup retries them
+}
+
+// WithFileStorage enables storage quotas and cleanup. Zero quotas fall back to
+// DefaultFreeStorageQuota and DefaultPremiumStorageQuota.
+func (s *ImportService) WithFileStorage(storageRepo repository.StorageRepository, files storage.Storage, quotas StorageQuotas) *ImportService {
+	if quotas.Free <= 0 {
+		quotas.Free = DefaultFreeStorageQuota
+	}
+	if quotas.Premium <= 0 {
+		quotas.Premium = DefaultPremiumStorageQuota
+	}
+	s.storageRepo = storageRepo
+	s.files = files
+	s.quotas = quotas
+	return s
+}
+
+// GetStorageUsage returns the user's stored bytes by purpose against their quota
+func (s *ImportService) GetStorageUsage(ctx context.Context, userID uuid.UUID) (*StorageUsage, error) {
+	if s.storageRepo == nil {
+		return nil, ErrFileStorageDisabled
+	}
+	tier, err := s.storageRepo.GetStorageTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byPurpose, err := s.storageRepo.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &StorageUsage{Tier: tier, QuotaBytes: s.quotas.For(tier), ByPurpose: byPurpose}
+	for _, u := range byPurpose {
+		usage.UsedBytes += u.Bytes
+	}
+	return usage, nil
+}
+
+// CheckStorageQuota returns ErrStorageQuotaExceeded if storing sizeBytes more
+// would put the user over their quota. Always passes without file storage.
+func (s *ImportService) CheckStorageQuota(ctx context.Context, userID uuid.UUID, sizeBytes int64) error {
+	if s.storageRepo == nil {
+		return nil
+	}
+	usage, err := s.GetStorageUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if sizeBytes > usage.RemainingBytes() {
+		return fmt.Errorf("%w: %d of %d bytes used", ErrStorageQuotaExceeded, usage.UsedBytes, usage.QuotaBytes)
+	}
+	return nil
+}
+
+// CleanupImportedStatements deletes the content of statement files that were
+// imported successfully. Their rows, checksums and import history stay.
+func (s *ImportService) CleanupImportedStatements(ctx context.Context, userID uuid.UUID) (*CleanupResult, error) {
+	if s.storageRepo == nil || s.files == nil {
+		return nil, ErrFileStorageDisabled
+	}
+	files, err := s.storageRepo.ListImportedStatementFiles(ctx, userID, cleanupBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CleanupResult{}
+	for _, f := range files {
+		if err := s.files.Delete(ctx, userID, f.ID); err != nil {
+			s.logger.Warn("failed to delete imported statement file",
+				slog.String("user_id", userID.String()),
+				slog.String("file_id", f.ID.String()),
+				slog.Any("error", err),
+			)
+			result.FilesFailed++
+			continue
+		}
+		err := s.storageRepo.MarkUserFilePurged(ctx, userID, f.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue // Purged concurrently
+		}
+		if err != nil {
+			return result, err
+		}
+		result.FilesDeleted++
+		result.BytesFreed += f.SizeBytes
+	}
+	return result, nil
+}
+
+// purposeForFileType guesses why a file is kept from its type: images are
+// receipts, everything else uploaded is a statement to import
+func purposeForFileType(fileType string) repository.FilePurpose {
+	if fileType == "image" {
+		return repository.FilePurposeReceipt
+	}
+	return repository.FilePurposeStatement
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// =============================================================================
+// Categorization Trace (Internal Integration)
+// =============================================================================
+// Imports can return a trace of how their first rows were categorized, so users
+// can see why a new bank's descriptions don't match their rules without a
+// separate preview call.
+//
+// To expose over the API, add the following proto definitions:
+// - trace_rows on ImportTransactionsCsvRequest
+// - repeated CategorizationTrace trace on ImportTransactionsCsvResponse
+
+// maxTraceRows caps the rows an import traces
+const maxTraceRows = 100
+
+// TraceSource is what categorized a traced row
+type TraceSource string
+
+const (
+	TraceSourceRule     TraceSource = "rule"     // One of the user's rules
+	TraceSourceMerchant TraceSource = "merchant" // The merchant dictionary
+	TraceSourceFile     TraceSource = "file"     // The imported file already had a category
+	TraceSourceNone     TraceSource = "none"     // Nothing matched
+)
+
+// MatchSuggestion is the closest fuzzy rule or merchant to a description
+type MatchSuggestion struct {
+	Pattern    string
+	CleanName  string
+	CategoryID *uuid.UUID
+	Score      int // Similarity 0-100
+	IsRule     bool
+}
+
+// CategorizationTrace explains how one imported row was categorized
+type CategorizationTrace struct {
+	Line         int
+	Description  string
+	Source       TraceSource
+	Pattern      string // The rule or merchant pattern that matched
+	RuleID       *uuid.UUID
+	MerchantID   *uuid.UUID
+	CategoryID   *uuid.UUID
+	MerchantName string
+	Suggestion   *MatchSuggestion // Closest fuzzy match, when nothing matched
+}
+
+// categorizationTracer collects traces for the first rows of an import
+type categorizationTracer struct {
+	limit  int
+	traces []CategorizationTrace
+}
+
+// newCategorizationTracer returns a tracer for the first rows rows, or nil if
+// tracing is off
+func newCategorizationTracer(rows int) *categorizationTracer {
+	if rows <= 0 {
+		return nil
+	}
+	return &categorizationTracer{limit: min(rows, maxTraceRows)}
+}
+
+// wants reports how many rows of the next batch still need a trace
+func (t *categorizationTracer) wants() int {
+	if t == nil {
+		return 0
+	}
+	return t.limit - len(t.traces)
+}
+
+// record traces the first rows of an enriched batch. fromFile marks rows that
+// had a category before enrichment; results are the categorization results
+// aligned to batch, nil if categorization didn't run.
+func (s *ImportService) recordTraces(ctx context.Context, t *categorizationTracer, userID uuid.UUID, batch []*repository.ParsedTransaction, lines []int, fromFile []bool, results []*CategorizationResult) {
+	for i := 0; i < len(batch) && i < len(fromFile) && t.wants() > 0; i++ {
+		tx := batch[i]
+		trace := CategorizationTrace{
+			Line:         lines[i],
+			Description:  tx.Description,
+			Source:       TraceSourceNone,
+			CategoryID:   tx.CategoryID,
+			MerchantName: tx.MerchantName,
+		}
+
+		var result *CategorizationResult
+		if i < len(results) {
+			result = results[i]
+		}
+		switch {
+		case fromFile[i]:
+			trace.Source = TraceSourceFile
+		case result != nil && result.RuleID != nil:
+			trace.Source = TraceSourceRule
+			trace.RuleID = result.RuleID
+			trace.Pattern = result.MatchedPattern
+		case result != nil && result.MerchantID != nil:
+			trace.Source = TraceSourceMerchant
+			trace.MerchantID = result.MerchantID
+			trace.Pattern = result.MatchedPattern
+		case s.catService != nil:
+			suggestion, err := s.catService.SuggestMatch(ctx, userID, tx.Description)
+			if err != nil {
+				s.logger.Warn("failed to suggest categorization match", "error", err)
+			}
+			trace.Suggestion = suggestion
+		}
+		t.traces = append(t.traces, trace)
+	}
+}
+
+// result returns the traces in file order
+func (t *categorizationTracer) result() []CategorizationTrace {
+	if t == nil {
+		return nil
+	}
+	sort.Slice(t.traces, func(i, j int) bool { return t.traces[i].Line < t.traces[j].Line })
+	return t.traces
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// =============================================================================
+// Resumable Chunked Uploads (Internal Integration)
+// =============================================================================
+// Large statements fail as a single UploadUserFile call on mobile networks.
+// Instead, the client begins an upload with the file's size and SHA-256, sends
+// it in chunks of any order (re-sending a chunk replaces it), checks which
+// chunks arrived with GetUpload after a dropped connection, and completes the
+// upload. Completing checks the whole file's checksum and produces the same
+// user file UploadUserFile does, so its ID works with AnalyzeFile, the plan
+// Excel imports and the import jobs.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - BeginUploadRequest/Response (ImportService.BeginUpload): file_name,
+//   mime_type, type, size_bytes, checksum_sha256, optional chunk_size;
+//   returns the Upload
+// - UploadChunkRequest/Response (ImportService.UploadChunk): upload_id, index,
+//   data, optional checksum_sha256 of the chunk
+// - GetUploadRequest/Response (ImportService.GetUpload)
+// - CompleteUploadRequest/Response (ImportService.CompleteUpload): returns UserFile
+// - AbortUploadRequest/Response (ImportService.AbortUpload)
+// - Upload (id, status, size_bytes, chunk_size, chunk_count, received_chunks,
+//   expires_at, file_id)
+
+const (
+	// DefaultUploadChunkSize is the chunk size of uploads that don't choose one
+	DefaultUploadChunkSize = 1 << 20
+	// MinUploadChunkSize and MaxUploadChunkSize bound the chosen chunk size
+	MinUploadChunkSize = 64 << 10
+	MaxUploadChunkSize = 8 << 20
+	// MaxUploadBytes is the largest file that can be uploaded, as for user files
+	MaxUploadBytes = 20_000_000
+	// UploadSessionTTL is how long an upload can take. Staged chunks aren't
+	// user files, so database maintenance deletes them as orphans a day after
+	// they're sent; uploads must not outlive that.
+	UploadSessionTTL = 24 * time.Hour
+)
+
+var (
+	// ErrUploadNotFound is returned for uploads that don't exist or aren't the user's
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrInvalidUpload is returned when beginning an upload with a bad size,
+	// chunk size, file name or checksum
+	ErrInvalidUpload = fmt.Errorf("uploads need a file name, a SHA-256 checksum and a size of 1 to %d bytes", MaxUploadBytes)
+	// ErrUploadClosed is returned when sending chunks to, or completing, an
+	// upload that was aborted or has expired
+	ErrUploadClosed = errors.New("upload was aborted or has expired")
+	// ErrInvalidUploadChunk is returned for chunks with an index outside the
+	// upload, the wrong size or a checksum that doesn't match their data
+	ErrInvalidUploadChunk = errors.New("chunk doesn't fit the upload or failed its checksum")
+	// ErrUploadIncomplete is returned when completing an upload missing chunks
+	ErrUploadIncomplete = errors.New("upload is missing chunks")
+	// ErrUploadChecksumMismatch is returned when the assembled file doesn't
+	// match the checksum the upload began with. The upload stays open so the
+	// client can re-send its chunks.
+	ErrUploadChecksumMismatch = errors.New("uploaded file doesn't match its checksum")
+)
+
+var sha256Hex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// BeginUploadInput describes a file about to be uploaded in chunks
+type BeginUploadInput struct {
+	FileName       string
+	MimeType       string
+	Type           string // As for CreateUserFileInput
+	SizeBytes      int64
+	ChunkSize      int // DefaultUploadChunkSize when zero
+	ChecksumSHA256 string
+}
+
+// Upload is a chunked upload and the chunks received so far
+type Upload struct {
+	*repository.UploadSession
+	ChunkCount     int
+	ReceivedChunks []int // Indexes, ascending
+}
+
+// MissingChunks returns the indexes of the chunks still to send
+func (u *Upload) MissingChunks() []int {
+	received := make(map[int]bool, len(u.ReceivedChunks))
+	for _, i := range u.ReceivedChunks {
+		received[i] = true
+	}
+	var missing []int
+	for i := range u.ChunkCount {
+		if !received[i] {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// chunkSizeAt returns the size chunk index must have in an upload
+func chunkSizeAt(s *repository.UploadSession, index int) int {
+	rest := s.SizeBytes - int64(index)*int64(s.ChunkSize)
+	return int(min(rest, int64(s.ChunkSize)))
+}
+
+func chunkCount(s *repository.UploadSession) int {
+	return int((s.SizeBytes + int64(s.ChunkSize) - 1) / int64(s.ChunkSize))
+}
+
+// WithUploads enables chunked uploads, staged in the file storage set by
+// WithFileStorage
+func (s *ImportService) WithUploads(uploads repository.UploadRepository) *ImportService {
+	s.uploads = uploads
+	return s
+}
+
+// BeginUpload starts a chunked upload, checking the whole file fits the
+// user's storage quota
+func (s *ImportService) BeginUpload(ctx context.Context, userID uuid.UUID, input BeginUploadInput) (*Upload, error) {
+	if s.uploads == nil || s.files == nil {
+		return nil, ErrFileStorageDisabled
+	}
+	if input.ChunkSize == 0 {
+		input.ChunkSize = DefaultUploadChunkSize
+	}
+	input.FileName = strings.TrimSpace(input.FileName)
+	input.ChecksumSHA256 = strings.ToLower(input.ChecksumSHA256)
+	if input.FileName == "" || input.SizeBytes < 1 || input.SizeBytes > MaxUploadBytes ||
+		input.ChunkSize < MinUploadChunkSize || input.ChunkSize > MaxUploadChunkSize ||
+		!sha256Hex.MatchString(input.ChecksumSHA256) {
+		return nil, ErrInvalidUpload
+	}
+	if err := s.CheckStorageQuota(ctx, userID, input.SizeBytes); err != nil {
+		return nil, err
+	}
+
+	session := &repository.UploadSession{
+		UserID:         userID,
+		FileName:       input.FileName,
+		MimeType:       input.MimeType,
+		FileType:       input.Type,
+		SizeBytes:      input.SizeBytes,
+		ChunkSize:      input.ChunkSize,
+		ChecksumSHA256: input.ChecksumSHA256,
+		Status:         repository.UploadPending,
+		ExpiresAt:      s.now().Add(UploadSessionTTL),
+	}
+	if err := s.uploads.CreateUploadSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return &Upload{UploadSession: session, ChunkCount: chunkCount(session)}, nil
+}
+
+// GetUpload returns an upload with the chunks received so far, so an
+// interrupted client knows what is left to send
+func (s *ImportService) GetUpload(ctx context.Context, userID, uploadID uuid.UUID) (*Upload, error) {
+	session, err := s.getUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	return s.describeUpload(ctx, session)
+}
+
+// UploadChunk stores chunk index of an upload. Chunks can arrive in any
+// order; sending one again replaces it. checksum, the chunk's SHA-256, is
+// optional but catches corruption before the upload is completed.
+func (s *ImportService) UploadChunk(ctx context.Context, userID, uploadID uuid.UUID, index int, data []byte, checksum string) (*Upload, error) {
+	session, err := s.openUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	chunkChecksum := hex.EncodeToString(sum[:])
+	if index < 0 || index >= chunkCount(session) || len(data) != chunkSizeAt(session, index) ||
+		(checksum != "" && !strings.EqualFold(checksum, chunkChecksum)) {
+		return nil, ErrInvalidUploadChunk
+	}
+
+	info, err := s.files.Upload(ctx, userID, fmt.Sprintf("%s.part%d", session.ID, index),
+		"application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage upload chunk: %w", err)
+	}
+	replaced, err := s.uploads.PutUploadChunk(ctx, &repository.UploadChunk{
+		UploadID:       session.ID,
+		Index:          index,
+		StorageFileID:  info.ID,
+		SizeBytes:      len(data),
+		ChecksumSHA256: chunkChecksum,
+		ReceivedAt:     s.now(),
+	})
+	if err != nil {
+		_ = s.files.Delete(ctx, userID, info.ID)
+		return nil, err
+	}
+	if replaced != nil {
+		s.deleteStagedChunks(ctx, userID, *replaced)
+	}
+	return s.describeUpload(ctx, session)
+}
+
+// CompleteUpload assembles an upload's chunks into a user file once they
+// have all arrived and match the upload's checksum, scanning it like
+// UploadUserFile does. Completing an upload again returns the same file, so a
+// client that lost the response can retry.
+func (s *ImportService) CompleteUpload(ctx context.Context, userID, uploadID uuid.UUID) (*UserFile, error) {
+	session, err := s.getUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == repository.UploadCompleted && session.FileID != nil {
+		return s.completedUploadFile(ctx, userID, *session.FileID)
+	}
+	if session.Status != repository.UploadPending || !s.now().Before(session.ExpiresAt) {
+		return nil, ErrUploadClosed
+	}
+
+	chunks, err := s.uploads.ListUploadChunks(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) != chunkCount(session) {
+		return nil, fmt.Errorf("%w: %d of %d received", ErrUploadIncomplete, len(chunks), chunkCount(session))
+	}
+
+	// Uploads are at most MaxUploadBytes, and scanning needs the whole file
+	data, err := io.ReadAll(&chunkReader{ctx: ctx, s: s, userID: userID, chunks: chunks})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble upload: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if int64(len(data)) != session.SizeBytes || hex.EncodeToString(sum[:]) != session.ChecksumSHA256 {
+		return nil, ErrUploadChecksumMismatch
+	}
+	scan, err := s.ScanUpload(ctx, session.FileType, data)
+	if err != nil {
+		return nil, err
+	}
+	checksum := session.ChecksumSHA256
+	if scan.MacrosStripped {
+		sum = sha256.Sum256(scan.Data)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	info, err := s.files.Upload(ctx, userID, session.FileName, scan.MimeType, bytes.NewReader(scan.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	storageURL := info.Path
+	file := &repository.UserFile{
+		ID:             info.ID,
+		UserID:         userID,
+		Type:           session.FileType,
+		MimeType:       scan.MimeType,
+		FileName:       session.FileName,
+		SizeBytes:      int64(len(scan.Data)),
+		ChecksumSHA256: &checksum,
+		StorageURL:     &storageURL,
+		Purpose:        purposeForFileType(session.FileType),
+	}
+	applyScan(file, scan, s.now())
+	err = s.uploads.CompleteUploadSession(ctx, session.ID, file, s.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		// Completed or aborted concurrently; keep whichever file won
+		_ = s.files.Delete(ctx, userID, info.ID)
+		return s.CompleteUpload(ctx, userID, uploadID)
+	}
+	if err != nil {
+		_ = s.files.Delete(ctx, userID, info.ID)
+		return nil, err
+	}
+
+	staged := make([]uuid.UUID, len(chunks))
+	for i, c := range chunks {
+		staged[i] = c.StorageFileID
+	}
+	s.deleteStagedChunks(ctx, userID, staged...)
+	return userFileFromRepo(file), nil
+}
+
+// AbortUpload cancels a pending upload and deletes its chunks
+func (s *ImportService) AbortUpload(ctx context.Context, userID, uploadID uuid.UUID) error {
+	session, err := s.getUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		return err
+	}
+	chunks, err := s.uploads.ListUploadChunks(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.uploads.AbortUploadSession(ctx, session.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUploadClosed
+		}
+		return err
+	}
+	for _, c := range chunks {
+		s.deleteStagedChunks(ctx, userID, c.StorageFileID)
+	}
+	return nil
+}
+
+func (s *ImportService) getUploadSession(ctx context.Context, userID, uploadID uuid.UUID) (*repository.UploadSession, error) {
+	if s.uploads == nil || s.files == nil {
+		return nil, ErrFileStorageDisabled
+	}
+	session, err := s.uploads.GetUploadSession(ctx, userID, uploadID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUploadNotFound
+	}
+	return session, err
+}
+
+// openUploadSession returns an upload still accepting chunks
+func (s *ImportService) openUploadSession(ctx context.Context, userID, uploadID uuid.UUID) (*repository.UploadSession, error) {
+	session, err := s.getUploadSession(ctx, userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != repository.UploadPending || !s.now().Before(session.ExpiresAt) {
+		return nil, ErrUploadClosed
+	}
+	return session, nil
+}
+
+func (s *ImportService) describeUpload(ctx context.Context, session *repository.UploadSession) (*Upload, error) {
+	upload := &Upload{UploadSession: session, ChunkCount: chunkCount(session)}
+	if session.Status != repository.UploadPending {
+		return upload, nil
+	}
+	chunks, err := s.uploads.ListUploadChunks(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chunks {
+		upload.ReceivedChunks = append(upload.ReceivedChunks, c.Index)
+	}
+	return upload, nil
+}
+
+func (s *ImportService) completedUploadFile(ctx context.Context, userID, fileID uuid.UUID) (*UserFile, error) {
+	file, err := s.repo.GetUserFileByID(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil || file.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	return userFileFromRepo(file), nil
+}
+
+// deleteStagedChunks removes staged chunk files. Failures are left for the
+// orphaned file cleanup.
+func (s *ImportService) deleteStagedChunks(ctx context.Context, userID uuid.UUID, fileIDs ...uuid.UUID) {
+	for _, id := range fileIDs {
+		if err := s.files.Delete(ctx, userID, id); err != nil {
+			s.logger.Warn("failed to delete staged upload chunk",
+				slog.String("user_id", userID.String()),
+				slog.String("file_id", id.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func userFileFromRepo(f *repository.UserFile) *UserFile {
+	file := &UserFile{
+		ID:         f.ID,
+		UserID:     f.UserID,
+		Type:       f.Type,
+		MimeType:   f.MimeType,
+		FileName:   f.FileName,
+		SizeBytes:  f.SizeBytes,
+		ScanStatus: f.ScanStatus,
+		CreatedAt:  f.CreatedAt,
+	}
+	if f.ChecksumSHA256 != nil {
+		file.Checksum = *f.ChecksumSHA256
+	}
+	if f.StorageURL != nil {
+		file.StorageURL = *f.StorageURL
+	}
+	return file
+}
+
+// chunkReader reads an upload's staged chunks in order as one stream, opening
+// each chunk only when the previous one is used up
+type chunkReader struct {
+	ctx     context.Context
+	s       *ImportService
+	userID  uuid.UUID
+	chunks  []*repository.UploadChunk
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			rc, err := r.s.files.GetReader(r.ctx, r.userID, r.chunks[0].StorageFileID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to open upload chunk %d: %w", r.chunks[0].Index, err)
+			}
+			r.current, r.chunks = rc, r.chunks[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			_ = r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
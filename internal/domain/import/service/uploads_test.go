@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// memoryFileStorage keeps files in memory
+type memoryFileStorage struct {
+	storage.Storage
+	files map[uuid.UUID][]byte
+}
+
+func (m *memoryFileStorage) Upload(ctx context.Context, userID uuid.UUID, filename string, contentType string, r io.Reader) (*storage.FileInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	m.files[id] = data
+	return &storage.FileInfo{ID: id, Name: filename, Size: int64(len(data)), Path: id.String()}, nil
+}
+
+func (m *memoryFileStorage) GetReader(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (io.ReadCloser, error) {
+	data, ok := m.files[fileID]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryFileStorage) Delete(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	delete(m.files, fileID)
+	return nil
+}
+
+type fakeUploadRepo struct {
+	sessions map[uuid.UUID]*repository.UploadSession
+	chunks   map[uuid.UUID]map[int]*repository.UploadChunk
+	files    map[uuid.UUID]*repository.UserFile
+}
+
+func newFakeUploadRepo() *fakeUploadRepo {
+	return &fakeUploadRepo{
+		sessions: map[uuid.UUID]*repository.UploadSession{},
+		chunks:   map[uuid.UUID]map[int]*repository.UploadChunk{},
+		files:    map[uuid.UUID]*repository.UserFile{},
+	}
+}
+
+func (f *fakeUploadRepo) CreateUploadSession(ctx context.Context, s *repository.UploadSession) error {
+	s.ID = uuid.New()
+	f.sessions[s.ID] = s
+	f.chunks[s.ID] = map[int]*repository.UploadChunk{}
+	return nil
+}
+
+func (f *fakeUploadRepo) GetUploadSession(ctx context.Context, userID, uploadID uuid.UUID) (*repository.UploadSession, error) {
+	s, ok := f.sessions[uploadID]
+	if !ok || s.UserID != userID {
+		return nil, sql.ErrNoRows
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (f *fakeUploadRepo) ListUploadChunks(ctx context.Context, uploadID uuid.UUID) ([]*repository.UploadChunk, error) {
+	var chunks []*repository.UploadChunk
+	for _, c := range f.chunks[uploadID] {
+		chunks = append(chunks, c)
+	}
+	slices.SortFunc(chunks, func(a, b *repository.UploadChunk) int { return a.Index - b.Index })
+	return chunks, nil
+}
+
+func (f *fakeUploadRepo) PutUploadChunk(ctx context.Context, c *repository.UploadChunk) (*uuid.UUID, error) {
+	var replaced *uuid.UUID
+	if prev, ok := f.chunks[c.UploadID][c.Index]; ok {
+		replaced = &prev.StorageFileID
+	}
+	f.chunks[c.UploadID][c.Index] = c
+	return replaced, nil
+}
+
+func (f *fakeUploadRepo) CompleteUploadSession(ctx context.Context, uploadID uuid.UUID, file *repository.UserFile, at time.Time) error {
+	s := f.sessions[uploadID]
+	if s.Status != repository.UploadPending {
+		return sql.ErrNoRows
+	}
+	f.files[file.ID] = file
+	s.Status, s.FileID, s.CompletedAt = repository.UploadCompleted, &file.ID, &at
+	delete(f.chunks, uploadID)
+	return nil
+}
+
+func (f *fakeUploadRepo) AbortUploadSession(ctx context.Context, uploadID uuid.UUID) error {
+	s := f.sessions[uploadID]
+	if s.Status != repository.UploadPending {
+		return sql.ErrNoRows
+	}
+	s.Status = repository.UploadAborted
+	delete(f.chunks, uploadID)
+	return nil
+}
+
+// uploadImportRepo serves the user files completed uploads create
+type uploadImportRepo struct {
+	fakeImportRepo
+	uploads *fakeUploadRepo
+}
+
+func (r *uploadImportRepo) GetUserFileByID(ctx context.Context, id uuid.UUID) (*repository.UserFile, error) {
+	return r.uploads.files[id], nil
+}
+
+func newUploadTestService() (*ImportService, *fakeUploadRepo, *memoryFileStorage) {
+	uploads := newFakeUploadRepo()
+	files := &memoryFileStorage{files: map[uuid.UUID][]byte{}}
+	svc := NewImportService(&uploadImportRepo{uploads: uploads}, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithFileStorage(&fakeStorageRepo{}, files, StorageQuotas{}).
+		WithUploads(uploads)
+	return svc, uploads, files
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestChunkedUpload_ResumesAndCompletes(t *testing.T) {
+	svc, _, files := newUploadTestService()
+	ctx, userID := context.Background(), uuid.New()
+	data := bytes.Repeat([]byte("Date,Description,Amount\n"), 6000) // 144000 bytes, 3 chunks
+
+	upload, err := svc.BeginUpload(ctx, userID, BeginUploadInput{
+		FileName:       "statement.csv",
+		MimeType:       "text/csv",
+		Type:           "csv",
+		SizeBytes:      int64(len(data)),
+		ChunkSize:      MinUploadChunkSize,
+		ChecksumSHA256: checksumOf(data),
+	})
+	if err != nil {
+		t.Fatalf("BeginUpload failed: %v", err)
+	}
+	if upload.ChunkCount != 3 {
+		t.Fatalf("expected 3 chunks, got %d", upload.ChunkCount)
+	}
+	chunk := func(i int) []byte {
+		return data[i*MinUploadChunkSize : min((i+1)*MinUploadChunkSize, len(data))]
+	}
+
+	// Out of order, with a corrupted chunk re-sent
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 2, chunk(2), ""); err != nil {
+		t.Fatalf("UploadChunk 2 failed: %v", err)
+	}
+	corrupt := bytes.Repeat([]byte("x"), MinUploadChunkSize)
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, corrupt, checksumOf(chunk(0))); !errors.Is(err, ErrInvalidUploadChunk) {
+		t.Fatalf("expected ErrInvalidUploadChunk for a bad checksum, got %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, corrupt, ""); err != nil {
+		t.Fatalf("UploadChunk 0 failed: %v", err)
+	}
+	if _, err := svc.CompleteUpload(ctx, userID, upload.ID); !errors.Is(err, ErrUploadIncomplete) {
+		t.Fatalf("expected ErrUploadIncomplete, got %v", err)
+	}
+
+	resumed, err := svc.GetUpload(ctx, userID, upload.ID)
+	if err != nil {
+		t.Fatalf("GetUpload failed: %v", err)
+	}
+	if missing := resumed.MissingChunks(); !slices.Equal(missing, []int{1}) {
+		t.Fatalf("expected chunk 1 to be missing, got %v", missing)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 1, chunk(1), checksumOf(chunk(1))); err != nil {
+		t.Fatalf("UploadChunk 1 failed: %v", err)
+	}
+	if _, err := svc.CompleteUpload(ctx, userID, upload.ID); !errors.Is(err, ErrUploadChecksumMismatch) {
+		t.Fatalf("expected ErrUploadChecksumMismatch with the corrupt chunk, got %v", err)
+	}
+	if len(files.files) != 3 {
+		t.Fatalf("expected only the 3 staged chunks to be stored, got %d files", len(files.files))
+	}
+
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, chunk(0), ""); err != nil {
+		t.Fatalf("re-sending chunk 0 failed: %v", err)
+	}
+	file, err := svc.CompleteUpload(ctx, userID, upload.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload failed: %v", err)
+	}
+	if file.SizeBytes != int64(len(data)) || file.Checksum != checksumOf(data) || file.Type != "csv" ||
+		file.ScanStatus != repository.ScanClean {
+		t.Fatalf("unexpected user file: %+v", file)
+	}
+	if len(files.files) != 1 || !bytes.Equal(files.files[file.ID], data) {
+		t.Fatalf("expected only the assembled file to remain, got %d files", len(files.files))
+	}
+
+	again, err := svc.CompleteUpload(ctx, userID, upload.ID)
+	if err != nil || again.ID != file.ID {
+		t.Fatalf("expected completing again to return the same file, got %+v, %v", again, err)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, chunk(0), ""); !errors.Is(err, ErrUploadClosed) {
+		t.Fatalf("expected ErrUploadClosed after completion, got %v", err)
+	}
+}
+
+func TestChunkedUpload_Rejections(t *testing.T) {
+	svc, _, files := newUploadTestService()
+	ctx, userID := context.Background(), uuid.New()
+	data := []byte("Date,Description,Amount\n01/02/2024,Coffee,-2.50\n")
+	input := BeginUploadInput{FileName: "s.csv", Type: "csv", SizeBytes: int64(len(data)), ChecksumSHA256: checksumOf(data)}
+
+	for name, bad := range map[string]BeginUploadInput{
+		"no checksum":   {FileName: "s.csv", SizeBytes: 10},
+		"too large":     {FileName: "s.csv", SizeBytes: MaxUploadBytes + 1, ChecksumSHA256: checksumOf(data)},
+		"tiny chunks":   {FileName: "s.csv", SizeBytes: 10, ChunkSize: 1024, ChecksumSHA256: checksumOf(data)},
+		"no file name":  {FileName: " ", SizeBytes: 10, ChecksumSHA256: checksumOf(data)},
+		"negative size": {FileName: "s.csv", SizeBytes: -1, ChecksumSHA256: checksumOf(data)},
+	} {
+		if _, err := svc.BeginUpload(ctx, userID, bad); !errors.Is(err, ErrInvalidUpload) {
+			t.Fatalf("%s: expected ErrInvalidUpload, got %v", name, err)
+		}
+	}
+
+	upload, err := svc.BeginUpload(ctx, userID, input)
+	if err != nil {
+		t.Fatalf("BeginUpload failed: %v", err)
+	}
+	if _, err := svc.GetUpload(ctx, uuid.New(), upload.ID); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("expected another user's upload to be not found, got %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 1, data, ""); !errors.Is(err, ErrInvalidUploadChunk) {
+		t.Fatalf("expected ErrInvalidUploadChunk for an index past the end, got %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, data[1:], ""); !errors.Is(err, ErrInvalidUploadChunk) {
+		t.Fatalf("expected ErrInvalidUploadChunk for a short chunk, got %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, userID, upload.ID, 0, data, ""); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	if err := svc.AbortUpload(ctx, userID, upload.ID); err != nil {
+		t.Fatalf("AbortUpload failed: %v", err)
+	}
+	if len(files.files) != 0 {
+		t.Fatalf("expected aborting to delete staged chunks, got %d files", len(files.files))
+	}
+	if _, err := svc.CompleteUpload(ctx, userID, upload.ID); !errors.Is(err, ErrUploadClosed) {
+		t.Fatalf("expected ErrUploadClosed after aborting, got %v", err)
+	}
+
+	expiring, err := svc.BeginUpload(ctx, userID, input)
+	if err != nil {
+		t.Fatalf("BeginUpload failed: %v", err)
+	}
+	svc.now = func() time.Time { return time.Now().Add(UploadSessionTTL + time.Minute) }
+	if _, err := svc.UploadChunk(ctx, userID, expiring.ID, 0, data, ""); !errors.Is(err, ErrUploadClosed) {
+		t.Fatalf("expected ErrUploadClosed once expired, got %v", err)
+	}
+}
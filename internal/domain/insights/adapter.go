@@ -4,6 +4,8 @@ package insights
 import (
 	"context"
 
+	"github.com/google/uuid"
+
 	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
 )
 
@@ -52,3 +54,9 @@ func (a *ServiceAdapter) UpsertImportInsights(ctx context.Context, importInsight
 func (a *ServiceAdapter) RefreshDataSourceHealth(ctx context.Context) error {
 	return a.svc.RefreshDataSourceHealth(ctx)
 }
+
+// ImportCompleted tells the user's connected clients that the imported
+// transactions changed their dashboard.
+func (a *ServiceAdapter) ImportCompleted(ctx context.Context, userID uuid.UUID, _ *importservice.ImportResult, _ string) {
+	a.svc.InvalidateDashboard(ctx, userID)
+}
@@ -0,0 +1,78 @@
+package insights
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// advisorNotePreviewLength caps how much of a note the alert message repeats
+const advisorNotePreviewLength = 200
+
+// AdvisorNote is an annotation an advisor left in the user's workspace
+type AdvisorNote struct {
+	ID             uuid.UUID
+	AdvisorName    string // Empty when the advisor has no display name
+	Recommendation bool   // A recommendation rather than a plain note
+	Subject        string // "general", "report", "trend" or "plan"
+	Body           string
+}
+
+// TriggerAdvisorNoteAlert tells the user an advisor left them a note. Like
+// other alerts there is at most one per day; later notes are still listed
+// with the advisor's annotations.
+func (s *Service) TriggerAdvisorNoteAlert(ctx context.Context, userID uuid.UUID, note *AdvisorNote) error {
+	setting := s.alertSetting(ctx, userID, AlertTypeAdvisorNote)
+	if !setting.Enabled {
+		return nil
+	}
+
+	today := time.Now()
+	hasAlert, err := s.repo.HasAlertToday(ctx, userID, AlertTypeAdvisorNote, today)
+	if err != nil || hasAlert {
+		return err
+	}
+
+	who := "Your advisor"
+	if note.AdvisorName != "" {
+		who = note.AdvisorName
+	}
+	title := who + " left you a note"
+	if note.Recommendation {
+		title = who + " has a recommendation for you"
+	}
+
+	message := note.Body
+	if runes := []rune(message); len(runes) > advisorNotePreviewLength {
+		message = string(runes[:advisorNotePreviewLength]) + "…"
+	}
+
+	referenceType := "advisor_annotation"
+	alert := &Alert{
+		UserID:        userID,
+		AlertType:     AlertTypeAdvisorNote,
+		Severity:      AlertSeverityInfo,
+		Title:         title,
+		Message:       message,
+		ReferenceType: &referenceType,
+		ReferenceID:   &note.ID,
+		Metadata: map[string]any{
+			"advisor_name":   note.AdvisorName,
+			"recommendation": note.Recommendation,
+			"subject":        note.Subject,
+		},
+		AlertDate: today,
+	}
+
+	if err := s.createAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, map[string]any{
+		"alert_type":    string(alert.AlertType),
+		"severity":      string(alert.Severity),
+		"annotation_id": note.ID.String(),
+	})
+	return nil
+}
@@ -0,0 +1,299 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// =============================================================================
+// Ad-hoc Aggregations (Internal Integration)
+// =============================================================================
+// Charts can ask for sums, counts and averages grouped by any of a fixed set of
+// dimensions, instead of each chart needing its own endpoint. Queries run over
+// the transaction_monthly_rollups view and are built only from whitelisted
+// fragments; every value is a query parameter. Rows grouped by month carry the
+// user's period notes on that month.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - QueryAggregatesRequest/Response (InsightsService.QueryAggregates)
+// - AggregateFilter, AggregateRow
+
+// AggregateDimension is a field results can be grouped by
+type AggregateDimension string
+
+const (
+	DimensionCategory AggregateDimension = "category"
+	DimensionMerchant AggregateDimension = "merchant"
+	DimensionAccount  AggregateDimension = "account"
+	DimensionMonth    AggregateDimension = "month"
+)
+
+// AggregateMeasure is a value computed for each group
+type AggregateMeasure string
+
+const (
+	MeasureSum   AggregateMeasure = "sum"
+	MeasureCount AggregateMeasure = "count"
+	MeasureAvg   AggregateMeasure = "avg" // Average transaction amount
+)
+
+// AggregateDirection restricts a query to expenses or income
+type AggregateDirection string
+
+const (
+	DirectionAll     AggregateDirection = ""
+	DirectionExpense AggregateDirection = "expense"
+	DirectionIncome  AggregateDirection = "income"
+)
+
+const (
+	maxAggregateDimensions = 3
+	defaultAggregateLimit  = 100
+	maxAggregateLimit      = 1000
+	maxAggregateFilterIDs  = 100
+)
+
+// ErrInvalidAggregateQuery is returned for queries outside the supported shape
+var ErrInvalidAggregateQuery = errors.New("invalid aggregate query")
+
+// AggregateFilter narrows the transactions a query covers. Empty fields don't filter.
+type AggregateFilter struct {
+	From        *time.Time // First month included
+	To          *time.Time // Last month included
+	CategoryIDs []uuid.UUID
+	AccountIDs  []uuid.UUID
+	Merchants   []string // Case-insensitive exact names
+	Direction   AggregateDirection
+}
+
+// AggregateQuery asks for measures grouped by dimensions. Results are always
+// split by currency, since amounts in different currencies can't be added.
+type AggregateQuery struct {
+	Dimensions []AggregateDimension
+	Measures   []AggregateMeasure // Sum when empty
+	Filter     AggregateFilter
+	OrderBy    AggregateMeasure // Sorted by this measure, largest magnitude first; else by dimensions
+	Limit      int
+}
+
+// AggregateRow is one group of an aggregate query. Only the requested
+// dimensions and measures are set.
+type AggregateRow struct {
+	Month        *time.Time
+	CategoryID   *uuid.UUID
+	CategoryName string
+	Merchant     string
+	AccountID    *uuid.UUID
+	AccountName  string
+	CurrencyCode string
+
+	SumMinor *int64
+	Count    *int64
+	AvgMinor *int64
+
+	Notes []PeriodNote // The user's notes on the row's month, when grouped by month
+}
+
+// aggregateDimensions maps each dimension to the columns it selects and groups by
+var aggregateDimensions = map[AggregateDimension]struct {
+	columns []string
+	join    string
+}{
+	DimensionMonth:    {columns: []string{"r.month"}},
+	DimensionMerchant: {columns: []string{"r.merchant_name"}},
+	DimensionCategory: {
+		columns: []string{"r.category_id", "COALESCE(c.name::TEXT, '')"},
+		join:    "LEFT JOIN categories c ON c.id = r.category_id",
+	},
+	DimensionAccount: {
+		columns: []string{"r.account_id", "COALESCE(a.name::TEXT, '')"},
+		join:    "LEFT JOIN accounts a ON a.id = r.account_id",
+	},
+}
+
+// aggregateMeasures maps each measure to its expression over the rollups
+var aggregateMeasures = map[AggregateMeasure]string{
+	MeasureSum:   "SUM(r.sum_minor)::BIGINT",
+	MeasureCount: "SUM(r.tx_count)::BIGINT",
+	MeasureAvg:   "ROUND(SUM(r.sum_minor)::NUMERIC / NULLIF(SUM(r.tx_count), 0))::BIGINT",
+}
+
+// CompileAggregateQuery validates q and builds its SQL and arguments for userID
+func CompileAggregateQuery(userID uuid.UUID, q AggregateQuery) (string, []any, error) {
+	if len(q.Dimensions) > maxAggregateDimensions {
+		return "", nil, fmt.Errorf("%w: at most %d dimensions", ErrInvalidAggregateQuery, maxAggregateDimensions)
+	}
+	if len(q.Measures) == 0 {
+		q.Measures = []AggregateMeasure{MeasureSum}
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultAggregateLimit
+	}
+	if q.Limit > maxAggregateLimit {
+		return "", nil, fmt.Errorf("%w: limit is at most %d", ErrInvalidAggregateQuery, maxAggregateLimit)
+	}
+	f := q.Filter
+	if len(f.CategoryIDs) > maxAggregateFilterIDs || len(f.AccountIDs) > maxAggregateFilterIDs || len(f.Merchants) > maxAggregateFilterIDs {
+		return "", nil, fmt.Errorf("%w: at most %d values per filter", ErrInvalidAggregateQuery, maxAggregateFilterIDs)
+	}
+
+	var selects, groups, joins []string
+	seen := make(map[AggregateDimension]bool)
+	for _, d := range q.Dimensions {
+		dim, ok := aggregateDimensions[d]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown dimension %q", ErrInvalidAggregateQuery, d)
+		}
+		if seen[d] {
+			return "", nil, fmt.Errorf("%w: duplicate dimension %q", ErrInvalidAggregateQuery, d)
+		}
+		seen[d] = true
+		selects = append(selects, dim.columns...)
+		groups = append(groups, dim.columns...)
+		if dim.join != "" {
+			joins = append(joins, dim.join)
+		}
+	}
+	selects = append(selects, "r.currency_code")
+	groups = append(groups, "r.currency_code")
+
+	orderBy := ""
+	for _, m := range q.Measures {
+		expr, ok := aggregateMeasures[m]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unknown measure %q", ErrInvalidAggregateQuery, m)
+		}
+		selects = append(selects, expr)
+		if m == q.OrderBy {
+			orderBy = "ABS(" + expr + ") DESC"
+		}
+	}
+	if q.OrderBy != "" && orderBy == "" {
+		return "", nil, fmt.Errorf("%w: order_by must be one of the requested measures", ErrInvalidAggregateQuery)
+	}
+	if orderBy == "" {
+		orderBy = strings.Join(groups, ", ")
+	}
+
+	args := []any{userID}
+	where := []string{"r.user_id = $1"}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if f.From != nil {
+		where = append(where, "r.month >= date_trunc('month', "+arg(*f.From)+"::DATE)")
+	}
+	if f.To != nil {
+		where = append(where, "r.month <= date_trunc('month', "+arg(*f.To)+"::DATE)")
+	}
+	if len(f.CategoryIDs) > 0 {
+		where = append(where, "r.category_id = ANY("+arg(f.CategoryIDs)+")")
+	}
+	if len(f.AccountIDs) > 0 {
+		where = append(where, "r.account_id = ANY("+arg(f.AccountIDs)+")")
+	}
+	if len(f.Merchants) > 0 {
+		lowered := make([]string, len(f.Merchants))
+		for i, m := range f.Merchants {
+			lowered[i] = strings.ToLower(strings.TrimSpace(m))
+		}
+		where = append(where, "LOWER(r.merchant_name) = ANY("+arg(lowered)+")")
+	}
+	switch f.Direction {
+	case DirectionAll:
+	case DirectionExpense:
+		where = append(where, "NOT r.is_income")
+	case DirectionIncome:
+		where = append(where, "r.is_income")
+	default:
+		return "", nil, fmt.Errorf("%w: unknown direction %q", ErrInvalidAggregateQuery, f.Direction)
+	}
+
+	sql := "SELECT " + strings.Join(selects, ", ") +
+		" FROM transaction_monthly_rollups r"
+	if len(joins) > 0 {
+		sql += " " + strings.Join(joins, " ")
+	}
+	sql += " WHERE " + strings.Join(where, " AND ") +
+		" GROUP BY " + strings.Join(groups, ", ") +
+		" ORDER BY " + orderBy +
+		" LIMIT " + arg(q.Limit)
+	return sql, args, nil
+}
+
+// QueryAggregates runs an ad-hoc aggregation of the user's transactions.
+// Returns ErrInvalidAggregateQuery for unsupported queries.
+func (s *Service) QueryAggregates(ctx context.Context, userID uuid.UUID, q AggregateQuery) ([]AggregateRow, error) {
+	sql, args, err := CompileAggregateQuery(userID, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Measures) == 0 {
+		q.Measures = []AggregateMeasure{MeasureSum}
+	}
+
+	rows, err := s.repo.DB().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var results []AggregateRow
+	for rows.Next() {
+		var row AggregateRow
+		var dest []any
+		for _, d := range q.Dimensions {
+			switch d {
+			case DimensionMonth:
+				dest = append(dest, &row.Month)
+			case DimensionMerchant:
+				dest = append(dest, &row.Merchant)
+			case DimensionCategory:
+				dest = append(dest, &row.CategoryID, &row.CategoryName)
+			case DimensionAccount:
+				dest = append(dest, &row.AccountID, &row.AccountName)
+			}
+		}
+		dest = append(dest, &row.CurrencyCode)
+		for _, m := range q.Measures {
+			switch m {
+			case MeasureSum:
+				dest = append(dest, &row.SumMinor)
+			case MeasureCount:
+				dest = append(dest, &row.Count)
+			case MeasureAvg:
+				dest = append(dest, &row.AvgMinor)
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate: %w", err)
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate aggregates: %w", err)
+	}
+
+	s.attachAggregateNotes(ctx, userID, results, q.Dimensions)
+	return results, nil
+}
+
+// RefreshAggregateRollups refreshes the rollups ad-hoc aggregations run over
+func (s *Service) RefreshAggregateRollups(ctx context.Context) error {
+	return db.WithoutStatementTimeout(ctx, s.repo.DB(), func(conn *pgxpool.Conn) error {
+		_, err := conn.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY transaction_monthly_rollups`)
+		if err != nil {
+			return fmt.Errorf("failed to refresh transaction rollups: %w", err)
+		}
+		return nil
+	})
+}
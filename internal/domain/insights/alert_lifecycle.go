@@ -0,0 +1,118 @@
+package insights
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Alert Lifecycle (Internal Integration)
+// =============================================================================
+// Alerts can be marked read all at once or snoozed until a date, and go stale
+// on their own: month-scoped alerts (pace, surprise expenses, budgets,
+// streaks) expire when their month ends, the rest after DefaultAlertLifetime.
+// The alert_cleanup job dismisses expired alerts and deletes old handled ones.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - MarkAllAlertsReadRequest/Response (InsightsService.MarkAllAlertsRead)
+//   returning the number of alerts marked
+// - SnoozeAlertRequest/Response (alert_id, google.protobuf.Timestamp until)
+// - snoozed_until and expires_at on Alert
+
+const (
+	// DefaultAlertLifetime is how long alerts that aren't tied to a month stay current
+	DefaultAlertLifetime = 30 * 24 * time.Hour
+	// MaxAlertSnooze is the furthest ahead an alert can be snoozed
+	MaxAlertSnooze = 90 * 24 * time.Hour
+	// AlertRetention is how long read and dismissed alerts are kept
+	AlertRetention = 180 * 24 * time.Hour
+)
+
+var (
+	// ErrAlertNotFound is returned when the user has no such alert
+	ErrAlertNotFound = errors.New("alert not found")
+	// ErrInvalidSnooze is returned for a snooze that isn't in the future or is too far out
+	ErrInvalidSnooze = errors.New("alerts can be snoozed for up to 90 days")
+)
+
+// AlertCleanupResult summarizes an alert cleanup run
+type AlertCleanupResult struct {
+	Expired int64 // Alerts dismissed because they went stale
+	Deleted int64 // Read or dismissed alerts past AlertRetention
+}
+
+// alertExpiry returns when an alert goes stale
+func alertExpiry(alert *Alert) time.Time {
+	switch alert.AlertType {
+	case AlertTypePaceWarning, AlertTypeSurpriseExpense, AlertTypeBudgetOverspend, AlertTypeStreakBroken:
+		year, month, _ := alert.AlertDate.Date()
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, alert.AlertDate.Location())
+	case AlertTypeReturnWindow, AlertTypeWarrantyExpiry:
+		// Nothing to act on once the deadline has passed
+		if deadline, ok := alert.Metadata["deadline"].(string); ok {
+			if t, err := time.ParseInLocation("2006-01-02", deadline, alert.AlertDate.Location()); err == nil {
+				return t.AddDate(0, 0, 1)
+			}
+		}
+	}
+	return alert.AlertDate.Add(DefaultAlertLifetime)
+}
+
+// createAlert stores an alert with its expiry
+func (s *Service) createAlert(ctx context.Context, alert *Alert) error {
+	if alert.ExpiresAt == nil {
+		expiresAt := alertExpiry(alert)
+		alert.ExpiresAt = &expiresAt
+	}
+	return s.repo.CreateAlert(ctx, alert)
+}
+
+// MarkAllAlertsRead marks every unread alert of the user read, including
+// snoozed ones, and returns how many were marked
+func (s *Service) MarkAllAlertsRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	ids, err := s.repo.MarkAllAlertsRead(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark alerts read: %w", err)
+	}
+	if s.notifier != nil {
+		for _, id := range ids {
+			if err := s.notifier.MarkAlertRead(ctx, id); err != nil {
+				return len(ids), err
+			}
+		}
+	}
+	return len(ids), nil
+}
+
+// SnoozeAlert hides an alert from the unread list until the given time, when
+// it comes back unread
+func (s *Service) SnoozeAlert(ctx context.Context, userID, alertID uuid.UUID, until time.Time) error {
+	now := time.Now()
+	if !until.After(now) || until.After(now.Add(MaxAlertSnooze)) {
+		return ErrInvalidSnooze
+	}
+	err := s.repo.SnoozeAlert(ctx, userID, alertID, until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAlertNotFound
+	}
+	return err
+}
+
+// CleanupAlerts dismisses alerts that went stale by now and deletes read or
+// dismissed alerts older than AlertRetention
+func (s *Service) CleanupAlerts(ctx context.Context, now time.Time) (*AlertCleanupResult, error) {
+	expired, err := s.repo.ExpireAlerts(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire alerts: %w", err)
+	}
+	deleted, err := s.repo.DeleteAlertsBefore(ctx, now.Add(-AlertRetention))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete old alerts: %w", err)
+	}
+	return &AlertCleanupResult{Expired: expired, Deleted: deleted}, nil
+}
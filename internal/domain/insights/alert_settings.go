@@ -0,0 +1,275 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Alert Settings (Internal Integration)
+// =============================================================================
+// Users turn alert types on and off, tune the pace and surprise expense
+// thresholds, and pick the channels each type is delivered on. Types without
+// saved settings use DefaultAlertSettings.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetAlertSettingsRequest/Response (InsightsService.GetAlertSettings)
+// - UpdateAlertSettingsRequest/Response (InsightsService.UpdateAlertSettings)
+// - AlertSetting (alert_type, enabled, optional double threshold, repeated
+//   channels, bool default_channels) and an AlertChannel enum
+
+// AlertChannel is a channel an alert is delivered on besides the in-app inbox,
+// which always gets it
+type AlertChannel string
+
+const (
+	AlertChannelPush     AlertChannel = "push"
+	AlertChannelWebPush  AlertChannel = "web_push"
+	AlertChannelEmail    AlertChannel = "email"
+	AlertChannelWebhook  AlertChannel = "webhook"
+	AlertChannelTelegram AlertChannel = "telegram"
+)
+
+// AlertTypes lists every alert type, in the order settings are returned
+var AlertTypes = []AlertType{
+	AlertTypePaceWarning,
+	AlertTypeSurpriseExpense,
+	AlertTypeBudgetOverspend,
+	AlertTypeStreakBroken,
+	AlertTypeGoalProgress,
+	AlertTypeSubscriptionDue,
+	AlertTypeReturnWindow,
+	AlertTypeWarrantyExpiry,
+	AlertTypeAdvisorNote,
+}
+
+// Default thresholds of the alert types that have one
+const (
+	// NotificationThreshold is the pace, as a percentage of last month's, that
+	// triggers a pace warning
+	NotificationThreshold = 120.0 // 20% over
+	// SurpriseExpenseThreshold is the size, in minor units, from which a new
+	// merchant's charge triggers a surprise expense alert
+	SurpriseExpenseThreshold = 10000.0 // $100
+)
+
+// ErrInvalidAlertSetting is returned for settings that can't be saved
+var ErrInvalidAlertSetting = errors.New("invalid alert setting")
+
+// AlertSetting is how a user wants one alert type handled
+type AlertSetting struct {
+	AlertType AlertType
+	Enabled   bool
+	// Threshold is the pace percentage for pace warnings and the minimum
+	// amount in minor units for surprise expenses; nil for other types
+	Threshold *float64
+	// Channels the alert goes to besides the inbox; nil means the default
+	// routing (every channel, email only for critical alerts)
+	Channels []AlertChannel
+}
+
+// DefaultAlertSettings returns the settings of users who never changed them
+func DefaultAlertSettings() []AlertSetting {
+	settings := make([]AlertSetting, 0, len(AlertTypes))
+	for _, alertType := range AlertTypes {
+		settings = append(settings, defaultAlertSetting(alertType))
+	}
+	return settings
+}
+
+func defaultAlertSetting(alertType AlertType) AlertSetting {
+	setting := AlertSetting{AlertType: alertType, Enabled: true}
+	if threshold, ok := defaultAlertThreshold(alertType); ok {
+		setting.Threshold = &threshold
+	}
+	return setting
+}
+
+// defaultAlertThreshold returns the default threshold of the alert type, and
+// whether it has one
+func defaultAlertThreshold(alertType AlertType) (float64, bool) {
+	switch alertType {
+	case AlertTypePaceWarning:
+		return NotificationThreshold, true
+	case AlertTypeSurpriseExpense:
+		return SurpriseExpenseThreshold, true
+	}
+	return 0, false
+}
+
+// GetAlertSettings returns the user's settings for every alert type
+func (s *Service) GetAlertSettings(ctx context.Context, userID uuid.UUID) ([]AlertSetting, error) {
+	saved, err := s.repo.GetAlertSettings(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert settings: %w", err)
+	}
+	byType := make(map[AlertType]AlertSetting, len(saved))
+	for _, setting := range saved {
+		byType[setting.AlertType] = setting
+	}
+
+	settings := DefaultAlertSettings()
+	for i, setting := range settings {
+		if custom, ok := byType[setting.AlertType]; ok {
+			settings[i] = withDefaultThreshold(custom)
+		}
+	}
+	return settings, nil
+}
+
+// UpdateAlertSettings saves settings for the given alert types, leaving the
+// others as they are, and returns the settings for every type. A nil
+// threshold restores the default.
+func (s *Service) UpdateAlertSettings(ctx context.Context, userID uuid.UUID, settings []AlertSetting) ([]AlertSetting, error) {
+	seen := make(map[AlertType]bool, len(settings))
+	for i := range settings {
+		if err := validateAlertSetting(&settings[i]); err != nil {
+			return nil, err
+		}
+		if seen[settings[i].AlertType] {
+			return nil, fmt.Errorf("%w: %s is given twice", ErrInvalidAlertSetting, settings[i].AlertType)
+		}
+		seen[settings[i].AlertType] = true
+	}
+
+	for i := range settings {
+		if err := s.repo.UpsertAlertSetting(ctx, userID, &settings[i]); err != nil {
+			return nil, fmt.Errorf("failed to save alert setting: %w", err)
+		}
+	}
+	return s.GetAlertSettings(ctx, userID)
+}
+
+// validateAlertSetting checks the setting and drops repeated channels
+func validateAlertSetting(setting *AlertSetting) error {
+	if _, ok := defaultAlertThreshold(setting.AlertType); !ok {
+		known := false
+		for _, alertType := range AlertTypes {
+			known = known || alertType == setting.AlertType
+		}
+		if !known {
+			return fmt.Errorf("%w: unknown alert type %q", ErrInvalidAlertSetting, setting.AlertType)
+		}
+		if setting.Threshold != nil {
+			return fmt.Errorf("%w: %s alerts have no threshold", ErrInvalidAlertSetting, setting.AlertType)
+		}
+	}
+	if t := setting.Threshold; t != nil && (*t <= 0 || math.IsNaN(*t) || math.IsInf(*t, 0)) {
+		return fmt.Errorf("%w: threshold must be positive", ErrInvalidAlertSetting)
+	}
+
+	if setting.Channels == nil {
+		return nil
+	}
+	channels := make([]AlertChannel, 0, len(setting.Channels))
+	for _, ch := range setting.Channels {
+		switch ch {
+		case AlertChannelPush, AlertChannelWebPush, AlertChannelEmail, AlertChannelWebhook, AlertChannelTelegram:
+		default:
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidAlertSetting, ch)
+		}
+		duplicate := false
+		for _, existing := range channels {
+			duplicate = duplicate || existing == ch
+		}
+		if !duplicate {
+			channels = append(channels, ch)
+		}
+	}
+	setting.Channels = channels
+	return nil
+}
+
+// withDefaultThreshold fills in the default threshold of a saved setting
+// that doesn't override it
+func withDefaultThreshold(setting AlertSetting) AlertSetting {
+	if setting.Threshold == nil {
+		if threshold, ok := defaultAlertThreshold(setting.AlertType); ok {
+			setting.Threshold = &threshold
+		}
+	}
+	return setting
+}
+
+// alertSetting returns the user's setting for one alert type. Alerts must not
+// stop on a settings lookup, so failures fall back to the default.
+func (s *Service) alertSetting(ctx context.Context, userID uuid.UUID, alertType AlertType) AlertSetting {
+	saved, err := s.repo.GetAlertSettings(ctx, userID)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("failed to get alert settings", "userID", userID, "error", err)
+		}
+		return defaultAlertSetting(alertType)
+	}
+	for _, setting := range saved {
+		if setting.AlertType == alertType {
+			return withDefaultThreshold(setting)
+		}
+	}
+	return defaultAlertSetting(alertType)
+}
+
+// TriggerSurpriseExpenseAlert alerts the user, at most once a day, when the
+// largest charge from a merchant new this month reaches their threshold
+func (s *Service) TriggerSurpriseExpenseAlert(ctx context.Context, userID uuid.UUID, pulse *SpendingPulse) error {
+	if len(pulse.SurpriseExpenses) == 0 {
+		return nil
+	}
+	setting := s.alertSetting(ctx, userID, AlertTypeSurpriseExpense)
+	if !setting.Enabled {
+		return nil
+	}
+	// Surprise expenses come largest first
+	surprise := pulse.SurpriseExpenses[0]
+	amount := surprise.AmountCents
+	if amount < 0 {
+		amount = -amount
+	}
+	if float64(amount) < *setting.Threshold {
+		return nil
+	}
+
+	today := time.Now()
+	hasAlert, err := s.repo.HasAlertToday(ctx, userID, AlertTypeSurpriseExpense, today)
+	if err != nil || hasAlert {
+		return err
+	}
+
+	severity := AlertSeverityInfo
+	if float64(amount) >= 5**setting.Threshold {
+		severity = AlertSeverityWarning
+	}
+	referenceType := "transaction"
+	alert := &Alert{
+		UserID:        userID,
+		AlertType:     AlertTypeSurpriseExpense,
+		Severity:      severity,
+		Title:         fmt.Sprintf("New this month: %s at %s", formatMoney(amount), surprise.MerchantName),
+		Message:       fmt.Sprintf("You spent %s at %s on %s, and you didn't shop there last month.", formatMoney(amount), surprise.MerchantName, surprise.PostedAt.Format("Jan 2")),
+		ReferenceType: &referenceType,
+		ReferenceID:   &surprise.TransactionID,
+		Metadata: map[string]any{
+			"merchant":     surprise.MerchantName,
+			"amount_minor": amount,
+			"category":     surprise.CategoryName,
+			"posted_at":    surprise.PostedAt.Format("2006-01-02"),
+		},
+		AlertDate: today,
+	}
+
+	if err := s.createAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, map[string]any{
+		"alert_type":     string(alert.AlertType),
+		"severity":       string(alert.Severity),
+		"transaction_id": surprise.TransactionID.String(),
+	})
+	return nil
+}
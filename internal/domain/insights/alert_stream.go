@@ -0,0 +1,187 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Alert Streaming (Internal Integration)
+// =============================================================================
+// Connected clients receive new alerts and dashboard-block invalidations as
+// they happen instead of polling ListAlerts. Events go through Postgres
+// LISTEN/NOTIFY (see pkg/pgnotify), so a stream on any replica sees alerts
+// raised on every replica.
+//
+// The auth interceptor already authenticates streaming handlers. To expose as
+// an API endpoint, add the following proto definitions:
+// - rpc StreamAlerts(StreamAlertsRequest) returns (stream StreamAlertsResponse)
+//   on InsightsService; the handler ranges over SubscribeAlerts until the
+//   client disconnects
+// - StreamAlertsResponse with a oneof of Alert and DashboardInvalidation
+//   (repeated string block_types)
+
+// AlertEventKind identifies what a streamed event carries
+type AlertEventKind string
+
+const (
+	AlertEventAlert                AlertEventKind = "alert"
+	AlertEventDashboardInvalidated AlertEventKind = "dashboard_invalidated"
+)
+
+// AlertsChannel is the Postgres notification channel alert events travel on
+const AlertsChannel = "insights_alerts"
+
+// alertStreamBuffer is how many events a slow stream may fall behind by before
+// further events are dropped for it
+const alertStreamBuffer = 16
+
+// ErrAlertStreamUnavailable is returned when streaming isn't configured
+var ErrAlertStreamUnavailable = errors.New("alert streaming is not available")
+
+// AlertEvent is a new alert, or a notice that dashboard blocks are stale
+type AlertEvent struct {
+	Kind   AlertEventKind
+	UserID uuid.UUID
+	Alert  *Alert   `json:",omitempty"`
+	Blocks []string `json:",omitempty"` // DashboardBlock types to refetch; empty means all
+}
+
+// AlertPublisher sends an encoded event to every replica, which hands it to
+// AlertBroker.Dispatch
+type AlertPublisher func(ctx context.Context, payload string) error
+
+// AlertBroker fans alert events out to the streams of the user they're for
+type AlertBroker struct {
+	publish AlertPublisher // nil dispatches in-process only
+	maxSize int
+	logger  *slog.Logger
+
+	mu      sync.Mutex
+	streams map[uuid.UUID]map[chan AlertEvent]struct{}
+}
+
+// NewAlertBroker creates a broker. With a publisher, events travel through it
+// (e.g. Postgres NOTIFY) and come back through Dispatch; maxPayload caps the
+// encoded size it accepts. Without one, events are dispatched in-process.
+func NewAlertBroker(publish AlertPublisher, maxPayload int, logger *slog.Logger) *AlertBroker {
+	return &AlertBroker{
+		publish: publish,
+		maxSize: maxPayload,
+		logger:  logger,
+		streams: make(map[uuid.UUID]map[chan AlertEvent]struct{}),
+	}
+}
+
+// Publish sends an event to the user's streams on every replica
+func (b *AlertBroker) Publish(ctx context.Context, event *AlertEvent) error {
+	if b.publish == nil {
+		b.dispatch(*event)
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert event: %w", err)
+	}
+	if b.maxSize > 0 && len(payload) > b.maxSize && event.Alert != nil {
+		// Metadata is the only unbounded part; clients can fetch it with the alert
+		trimmed := *event
+		alert := *event.Alert
+		alert.Metadata = nil
+		trimmed.Alert = &alert
+		if payload, err = json.Marshal(&trimmed); err != nil {
+			return fmt.Errorf("failed to encode alert event: %w", err)
+		}
+	}
+	return b.publish(ctx, string(payload))
+}
+
+// Dispatch delivers an event received from the publisher to local streams
+func (b *AlertBroker) Dispatch(payload string) {
+	var event AlertEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		if b.logger != nil {
+			b.logger.Warn("failed to decode alert event", "error", err)
+		}
+		return
+	}
+	b.dispatch(event)
+}
+
+// dispatch hands the event to each of the user's streams without blocking;
+// a stream whose buffer is full misses it
+func (b *AlertBroker) dispatch(event AlertEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.streams[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe opens a stream of the user's events. It's closed once ctx is done.
+func (b *AlertBroker) Subscribe(ctx context.Context, userID uuid.UUID) <-chan AlertEvent {
+	ch := make(chan AlertEvent, alertStreamBuffer)
+	b.mu.Lock()
+	if b.streams[userID] == nil {
+		b.streams[userID] = make(map[chan AlertEvent]struct{})
+	}
+	b.streams[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.streams[userID], ch)
+		if len(b.streams[userID]) == 0 {
+			delete(b.streams, userID)
+		}
+		close(ch)
+		b.mu.Unlock()
+	}()
+	return ch
+}
+
+// WithAlertBroker streams new alerts and dashboard invalidations to connected clients
+func (s *Service) WithAlertBroker(broker *AlertBroker) *Service {
+	s.broker = broker
+	return s
+}
+
+// SubscribeAlerts streams the user's new alerts and dashboard invalidations
+// until ctx is done
+func (s *Service) SubscribeAlerts(ctx context.Context, userID uuid.UUID) (<-chan AlertEvent, error) {
+	if s.broker == nil {
+		return nil, ErrAlertStreamUnavailable
+	}
+	return s.broker.Subscribe(ctx, userID), nil
+}
+
+// InvalidateDashboard tells the user's connected clients to refetch dashboard
+// blocks of the given types, or all blocks when none are given, and drops the
+// user's cached spending trends and dashboard queries
+func (s *Service) InvalidateDashboard(ctx context.Context, userID uuid.UUID, blockTypes ...string) {
+	s.trends.invalidate(userID)
+	s.cache.Invalidate(ctx, userID)
+	s.publishAlertEvent(ctx, &AlertEvent{Kind: AlertEventDashboardInvalidated, UserID: userID, Blocks: blockTypes})
+}
+
+// publishAlertEvent publishes an event; streaming is best-effort, so failures
+// are only logged
+func (s *Service) publishAlertEvent(ctx context.Context, event *AlertEvent) {
+	if s.broker == nil {
+		return
+	}
+	if err := s.broker.Publish(ctx, event); err != nil && s.logger != nil {
+		s.logger.Warn("failed to publish alert event", "userID", event.UserID, "kind", event.Kind, "error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package insights_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+)
+
+func receiveAlertEvent(t *testing.T, events <-chan insights.AlertEvent) insights.AlertEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no alert event received")
+		return insights.AlertEvent{}
+	}
+}
+
+func TestSubscribeAlerts_StreamsNewAlertsToTheirUser(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := svc.SubscribeAlerts(ctx, uuid.New())
+	assert.ErrorIs(t, err, insights.ErrAlertStreamUnavailable)
+
+	svc.WithAlertBroker(insights.NewAlertBroker(nil, 0, nil))
+	userID, otherID := uuid.New(), uuid.New()
+	events, err := svc.SubscribeAlerts(ctx, userID)
+	require.NoError(t, err)
+	otherEvents, err := svc.SubscribeAlerts(ctx, otherID)
+	require.NoError(t, err)
+
+	err = svc.TriggerPaceAlert(ctx, userID, &insights.SpendingPulse{
+		CurrentMonthSpend: 60000,
+		LastMonthSpend:    40000,
+		PacePercent:       150.0,
+		DayOfMonth:        15,
+		AsOfDate:          time.Now(),
+	})
+	require.NoError(t, err)
+
+	event := receiveAlertEvent(t, events)
+	assert.Equal(t, insights.AlertEventAlert, event.Kind)
+	require.NotNil(t, event.Alert)
+	assert.Equal(t, repo.GetAlerts()[0].ID, event.Alert.ID)
+
+	svc.InvalidateDashboard(ctx, userID, "status")
+	event = receiveAlertEvent(t, events)
+	assert.Equal(t, insights.AlertEventDashboardInvalidated, event.Kind)
+	assert.Equal(t, []string{"status"}, event.Blocks)
+
+	select {
+	case event := <-otherEvents:
+		t.Fatalf("other user received %s event", event.Kind)
+	default:
+	}
+
+	// The stream closes when the client goes away
+	cancel()
+	for range events {
+	}
+}
+
+func TestAlertBroker_RoundTripsThroughPublisher(t *testing.T) {
+	var broker *insights.AlertBroker
+	var payloads []string
+	broker = insights.NewAlertBroker(func(_ context.Context, payload string) error {
+		payloads = append(payloads, payload)
+		broker.Dispatch(payload)
+		return nil
+	}, 512, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	userID := uuid.New()
+	events := broker.Subscribe(ctx, userID)
+
+	alert := &insights.Alert{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Title:    "Spending ahead",
+		Metadata: map[string]any{"note": strings.Repeat("x", 1024)},
+	}
+	require.NoError(t, broker.Publish(ctx, &insights.AlertEvent{Kind: insights.AlertEventAlert, UserID: userID, Alert: alert}))
+
+	// Metadata is dropped to fit the payload limit; the rest arrives intact
+	require.Len(t, payloads, 1)
+	assert.LessOrEqual(t, len(payloads[0]), 512)
+	event := receiveAlertEvent(t, events)
+	require.NotNil(t, event.Alert)
+	assert.Equal(t, alert.ID, event.Alert.ID)
+	assert.Equal(t, "Spending ahead", event.Alert.Title)
+	assert.Nil(t, event.Alert.Metadata)
+	assert.NotNil(t, alert.Metadata, "the caller's alert is left alone")
+
+	// Payloads that don't decode are ignored
+	broker.Dispatch("not json")
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected %s event", event.Kind)
+	default:
+	}
+}
@@ -0,0 +1,382 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Finance Questions (Internal Integration)
+// =============================================================================
+// AskFinance answers questions like "how much did I spend on groceries in
+// Q2?". A template planner reads the measure, direction, period, category and
+// merchant out of the question into a QuestionPlan, which runs as an ordinary
+// aggregate query: the question never becomes SQL. When the templates can't
+// read a question, an optional QuestionInterpreter (e.g. an LLM) may fill in
+// the same plan, which is validated like any other.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AskFinanceRequest/Response (InsightsService.AskFinance)
+// - QuestionPlan, with the AggregateRow totals and breakdown
+
+const (
+	// maxQuestionLength caps questions
+	maxQuestionLength = 500
+	// maxQuestionMonths caps the period a question can cover
+	maxQuestionMonths = 120
+	// questionBreakdownLimit caps the rows of an answer's breakdown
+	questionBreakdownLimit = 10
+)
+
+var (
+	// ErrInvalidQuestion is returned for empty or overly long questions
+	ErrInvalidQuestion = errors.New("invalid question")
+	// ErrUnsupportedQuestion is returned for questions no plan can be made for
+	ErrUnsupportedQuestion = errors.New("question not understood")
+)
+
+// QuestionPlan is what a question asks for. It only names things (a measure,
+// months, a category the user has), so it's safe to accept from an interpreter.
+type QuestionPlan struct {
+	Measure   AggregateMeasure
+	Direction AggregateDirection
+	From      time.Time // First month included
+	To        time.Time // Last month included
+	Category  string    // One of the user's category names, if any
+	Merchant  string    // Exact merchant name, if any
+}
+
+// QuestionInterpreter plans questions the templates can't, e.g. with an LLM.
+// categories are the user's category names, which Category must be one of.
+type QuestionInterpreter interface {
+	InterpretQuestion(ctx context.Context, question string, categories []string, asOf time.Time) (*QuestionPlan, error)
+}
+
+// FinanceAnswer is the figure a question asks for and what it's made of
+type FinanceAnswer struct {
+	Question string
+	Plan     QuestionPlan
+	Summary  string // What was computed, e.g. "Spending on Groceries from Apr 2025 to Jun 2025"
+
+	// Totals has one row per currency. Sums keep their sign, so spending is negative.
+	Totals      []AggregateRow
+	BreakdownBy AggregateDimension // Month for multi-month periods, else category or merchant
+	Breakdown   []AggregateRow
+}
+
+// WithQuestionInterpreter sets the fallback for questions the templates can't plan
+func (s *Service) WithQuestionInterpreter(interpreter QuestionInterpreter) *Service {
+	s.interpreter = interpreter
+	return s
+}
+
+// AskFinance answers a natural-language question about the user's
+// transactions as of asOf. Returns ErrUnsupportedQuestion when it can't be
+// planned.
+func (s *Service) AskFinance(ctx context.Context, userID uuid.UUID, question string, asOf time.Time) (*FinanceAnswer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" || len(question) > maxQuestionLength {
+		return nil, fmt.Errorf("%w: ask in 1 to %d characters", ErrInvalidQuestion, maxQuestionLength)
+	}
+
+	categories, names, err := s.userCategories(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := PlanQuestion(question, names, asOf)
+	if errors.Is(err, ErrUnsupportedQuestion) && s.interpreter != nil {
+		plan, err = s.interpreter.InterpretQuestion(ctx, question, names, asOf)
+		if err == nil {
+			err = validatePlan(plan, categories)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filter := AggregateFilter{Direction: plan.Direction}
+	from, to := plan.From, plan.To
+	filter.From, filter.To = &from, &to
+	if plan.Category != "" {
+		filter.CategoryIDs = categories[strings.ToLower(plan.Category)]
+	}
+	if plan.Merchant != "" {
+		filter.Merchants = []string{plan.Merchant}
+	}
+
+	answer := &FinanceAnswer{Question: question, Plan: *plan, Summary: describePlan(plan)}
+	measures := []AggregateMeasure{plan.Measure}
+	if answer.Totals, err = s.QueryAggregates(ctx, userID, AggregateQuery{Measures: measures, Filter: filter}); err != nil {
+		return nil, err
+	}
+
+	breakdown := AggregateQuery{Measures: measures, Filter: filter, Limit: questionBreakdownLimit}
+	switch {
+	case !plan.From.Equal(plan.To):
+		breakdown.Dimensions = []AggregateDimension{DimensionMonth}
+		breakdown.Limit = maxQuestionMonths
+	case plan.Category == "":
+		breakdown.Dimensions, breakdown.OrderBy = []AggregateDimension{DimensionCategory}, plan.Measure
+	default:
+		breakdown.Dimensions, breakdown.OrderBy = []AggregateDimension{DimensionMerchant}, plan.Measure
+	}
+	answer.BreakdownBy = breakdown.Dimensions[0]
+	if answer.Breakdown, err = s.QueryAggregates(ctx, userID, breakdown); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// userCategories maps the user's lower-case category names to their IDs, and
+// lists the names as the user wrote them
+func (s *Service) userCategories(ctx context.Context, userID uuid.UUID) (map[string][]uuid.UUID, []string, error) {
+	rows, err := s.repo.DB().Query(ctx, `SELECT id, name FROM categories WHERE user_id = $1 ORDER BY name`, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make(map[string][]uuid.UUID)
+	var names []string
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		key := strings.ToLower(name)
+		if _, seen := categories[key]; !seen {
+			names = append(names, name)
+		}
+		categories[key] = append(categories[key], id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate categories: %w", err)
+	}
+	return categories, names, nil
+}
+
+var (
+	quarterPattern    = regexp.MustCompile(`\bq([1-4])(?:\s+(?:of\s+)?(\d{4}))?\b`)
+	lastMonthsPattern = regexp.MustCompile(`\b(?:last|past)\s+(\d{1,3})\s+months?\b`)
+	monthPattern      = regexp.MustCompile(`\b(january|february|march|april|may|june|july|august|september|october|november|december)(?:\s+(?:of\s+)?(\d{4}))?\b`)
+	yearPattern       = regexp.MustCompile(`\b((?:19|20)\d{2})\b`)
+	// merchantPattern finds a capitalized name after "at", "on", "from" or
+	// "to", e.g. "at Pingo Doce"
+	merchantPattern = regexp.MustCompile(`\b(?:at|on|from|to)\s+([A-Z][\w&'.-]*(?:\s+[A-Z][\w&'.-]*)*)`)
+)
+
+// questionIntents are the phrases that make a question one the templates can plan
+var questionIntents = []string{
+	"how much", "how many", "number of", "total", "average", "spend", "spent",
+	"spending", "cost", "paid", "earn", "income", "received",
+}
+
+// incomeWords mark questions about money coming in
+var incomeWords = []string{"earn", "income", "salary", "received", "receive", "paid me"}
+
+// finerPeriods are periods shorter than the monthly totals questions run over
+var finerPeriods = []string{"today", "yesterday", "this week", "last week", "past week"}
+
+// PlanQuestion reads a question into a plan with fixed templates. categories
+// are the user's category names; the longest one the question mentions is
+// used. Periods are months, quarters or years; without one the question is
+// about the current month. Returns ErrUnsupportedQuestion when the question
+// isn't one the templates know.
+func PlanQuestion(question string, categories []string, asOf time.Time) (*QuestionPlan, error) {
+	lower := strings.ToLower(question)
+	if !containsAny(lower, questionIntents) {
+		return nil, fmt.Errorf("%w: ask how much, how many or the average of your spending or income", ErrUnsupportedQuestion)
+	}
+	if containsAny(lower, finerPeriods) {
+		return nil, fmt.Errorf("%w: answers cover whole months, quarters or years", ErrUnsupportedQuestion)
+	}
+
+	plan := &QuestionPlan{Measure: MeasureSum, Direction: DirectionExpense}
+	switch {
+	case strings.Contains(lower, "how many") || strings.Contains(lower, "number of"):
+		plan.Measure = MeasureCount
+	case strings.Contains(lower, "average"):
+		plan.Measure = MeasureAvg
+	}
+	if containsAny(lower, incomeWords) {
+		plan.Direction = DirectionIncome
+	}
+
+	plan.From, plan.To = questionPeriod(lower, asOf)
+
+	for _, name := range categories {
+		if len(name) > len(plan.Category) && containsWord(lower, strings.ToLower(name)) {
+			plan.Category = name
+		}
+	}
+	for _, m := range merchantPattern.FindAllStringSubmatch(question, -1) {
+		name := m[1]
+		lowerName := strings.ToLower(name)
+		if strings.EqualFold(name, plan.Category) || monthPattern.MatchString(lowerName) || quarterPattern.MatchString(lowerName) {
+			continue
+		}
+		plan.Merchant = name
+		break
+	}
+	return plan, nil
+}
+
+// questionPeriod returns the first and last month a question covers
+func questionPeriod(lower string, asOf time.Time) (time.Time, time.Time) {
+	thisMonth := startOfMonth(asOf)
+	year := func(match string) (int, bool) {
+		y, err := strconv.Atoi(match)
+		return y, err == nil
+	}
+
+	switch {
+	case strings.Contains(lower, "last month"):
+		m := thisMonth.AddDate(0, -1, 0)
+		return m, m
+	case strings.Contains(lower, "this quarter"), strings.Contains(lower, "last quarter"):
+		start := thisMonth.AddDate(0, -(int(thisMonth.Month()-1) % 3), 0)
+		if strings.Contains(lower, "last quarter") {
+			start = start.AddDate(0, -3, 0)
+		}
+		return start, start.AddDate(0, 2, 0)
+	case strings.Contains(lower, "last year"):
+		start := time.Date(asOf.Year()-1, time.January, 1, 0, 0, 0, 0, asOf.Location())
+		return start, start.AddDate(0, 11, 0)
+	case strings.Contains(lower, "this year"), strings.Contains(lower, "year to date"), containsWord(lower, "ytd"):
+		return time.Date(asOf.Year(), time.January, 1, 0, 0, 0, 0, asOf.Location()), thisMonth
+	}
+
+	if m := lastMonthsPattern.FindStringSubmatch(lower); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		n = min(max(n, 1), maxQuestionMonths)
+		// The last n complete months
+		return thisMonth.AddDate(0, -n, 0), thisMonth.AddDate(0, -1, 0)
+	}
+	if m := quarterPattern.FindStringSubmatch(lower); m != nil {
+		q, _ := strconv.Atoi(m[1])
+		y, explicit := year(m[2])
+		if !explicit {
+			y = asOf.Year()
+		}
+		start := time.Date(y, time.Month(3*q-2), 1, 0, 0, 0, 0, asOf.Location())
+		if !explicit && start.After(thisMonth) {
+			start = start.AddDate(-1, 0, 0) // The most recent Q2, not a future one
+		}
+		return start, start.AddDate(0, 2, 0)
+	}
+	if m := monthPattern.FindStringSubmatch(lower); m != nil {
+		month, _ := time.Parse("January", strings.ToUpper(m[1][:1])+m[1][1:])
+		y, explicit := year(m[2])
+		if !explicit {
+			y = asOf.Year()
+		}
+		start := time.Date(y, month.Month(), 1, 0, 0, 0, 0, asOf.Location())
+		if !explicit && start.After(thisMonth) {
+			start = start.AddDate(-1, 0, 0)
+		}
+		return start, start
+	}
+	if m := yearPattern.FindStringSubmatch(lower); m != nil {
+		y, _ := year(m[1])
+		start := time.Date(y, time.January, 1, 0, 0, 0, 0, asOf.Location())
+		return start, start.AddDate(0, 11, 0)
+	}
+	return thisMonth, thisMonth
+}
+
+// validatePlan checks a plan from an interpreter against what the templates
+// would produce
+func validatePlan(plan *QuestionPlan, categories map[string][]uuid.UUID) error {
+	if plan == nil {
+		return ErrUnsupportedQuestion
+	}
+	if _, ok := aggregateMeasures[plan.Measure]; !ok {
+		return fmt.Errorf("%w: unknown measure %q", ErrUnsupportedQuestion, plan.Measure)
+	}
+	switch plan.Direction {
+	case DirectionAll, DirectionExpense, DirectionIncome:
+	default:
+		return fmt.Errorf("%w: unknown direction %q", ErrUnsupportedQuestion, plan.Direction)
+	}
+
+	plan.From, plan.To = startOfMonth(plan.From), startOfMonth(plan.To)
+	if plan.From.IsZero() || plan.To.Before(plan.From) || plan.From.AddDate(0, maxQuestionMonths, 0).Before(plan.To) {
+		return fmt.Errorf("%w: the period must be 1 to %d months", ErrUnsupportedQuestion, maxQuestionMonths)
+	}
+	if plan.Category != "" {
+		if _, ok := categories[strings.ToLower(plan.Category)]; !ok {
+			return fmt.Errorf("%w: no category named %q", ErrUnsupportedQuestion, plan.Category)
+		}
+	}
+	plan.Merchant = strings.TrimSpace(plan.Merchant)
+	return nil
+}
+
+// describePlan says in words what a plan computes
+func describePlan(plan *QuestionPlan) string {
+	subject := map[AggregateDirection]string{
+		DirectionExpense: "spending",
+		DirectionIncome:  "income",
+		DirectionAll:     "transactions",
+	}[plan.Direction]
+	switch plan.Measure {
+	case MeasureCount:
+		subject = "number of " + map[AggregateDirection]string{
+			DirectionExpense: "expenses",
+			DirectionIncome:  "incoming payments",
+			DirectionAll:     "transactions",
+		}[plan.Direction]
+	case MeasureAvg:
+		subject = "average " + subject
+	}
+
+	summary := strings.ToUpper(subject[:1]) + subject[1:]
+	if plan.Category != "" {
+		summary += " on " + plan.Category
+	}
+	if plan.Merchant != "" {
+		summary += " at " + plan.Merchant
+	}
+	if plan.From.Equal(plan.To) {
+		return summary + " in " + plan.From.Format("Jan 2006")
+	}
+	return summary + " from " + plan.From.Format("Jan 2006") + " to " + plan.To.Format("Jan 2006")
+}
+
+// containsAny reports whether s contains any of the phrases
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWord reports whether s contains word on word boundaries
+func containsWord(s, word string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], word)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isWordByte(s[start-1])) && (end == len(s) || !isWordByte(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,180 @@
+package insights
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Dashboard Block Providers (Internal Integration)
+// =============================================================================
+// Every dashboard block type is built by a DashboardBlockProvider registered
+// on the service. The built-in blocks register themselves in NewService; new
+// ones (e.g. upcoming bills) register with RegisterDashboardBlock while wiring
+// dependencies and can then be placed on dashboard layouts. The handler maps
+// blocks generically, so it doesn't change when blocks are added.
+//
+// Blocks carry typed payloads next to their display strings: a sparkline of
+// the series behind the value, progress towards a target and a deep link.
+// The handler sends the action's ID as DashboardBlock.action until the proto
+// carries the rest.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - DashboardSparkline (repeated int64 points, currency_code, label) and
+//   DashboardProgress (current_minor, target_minor, double ratio)
+// - DashboardAction (id, deep_link)
+// - Optional sparkline, progress and DashboardAction fields on DashboardBlock
+// - repeated string available_block_types on GetDashboardLayoutResponse
+
+// DeepLinkScheme is the URL scheme the app opens deep links with
+const DeepLinkScheme = "echo://"
+
+// DashboardAction is what tapping a dashboard block does
+type DashboardAction struct {
+	ID   string // Stable identifier clients switch on, e.g. "view_goal"
+	Path string // Screen in the app, e.g. "/goals/<id>"
+}
+
+// DeepLink returns the app URL of the action's screen
+func (a DashboardAction) DeepLink() string {
+	return DeepLinkScheme + strings.TrimPrefix(a.Path, "/")
+}
+
+// DashboardSparkline is the series behind a block's value, oldest first
+type DashboardSparkline struct {
+	Points       []int64 // Minor units
+	CurrencyCode string
+	Label        string
+}
+
+// DashboardProgress is progress towards a target
+type DashboardProgress struct {
+	CurrentMinor int64
+	TargetMinor  int64
+	Ratio        float64 // CurrentMinor / TargetMinor; may exceed 1, 0 without a target
+}
+
+// newDashboardProgress returns the progress of current towards target
+func newDashboardProgress(current, target int64) *DashboardProgress {
+	p := &DashboardProgress{CurrentMinor: current, TargetMinor: target}
+	if target > 0 {
+		p.Ratio = float64(current) / float64(target)
+	}
+	return p
+}
+
+// DashboardBlockRequest is what providers build a block from
+type DashboardBlockRequest struct {
+	UserID uuid.UUID
+	AsOf   time.Time
+	Pulse  *SpendingPulse // Shared by all blocks of the dashboard
+}
+
+// DashboardBlockProvider builds one type of dashboard block
+type DashboardBlockProvider interface {
+	// DashboardBlock builds the block, or returns nil when the user has
+	// nothing to show in it
+	DashboardBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error)
+}
+
+// DashboardBlockProviderFunc adapts a function to DashboardBlockProvider
+type DashboardBlockProviderFunc func(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error)
+
+// DashboardBlock implements DashboardBlockProvider
+func (f DashboardBlockProviderFunc) DashboardBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	return f(ctx, req)
+}
+
+// RegisterDashboardBlock makes blockType available on dashboard layouts,
+// built by p. Registering a type again replaces its provider. Not safe to call
+// once the service is serving requests.
+func (s *Service) RegisterDashboardBlock(blockType string, p DashboardBlockProvider) *Service {
+	if s.blockProviders == nil {
+		s.blockProviders = make(map[string]DashboardBlockProvider)
+	}
+	if _, ok := s.blockProviders[blockType]; !ok {
+		s.blockTypes = append(s.blockTypes, blockType)
+	}
+	s.blockProviders[blockType] = p
+	return s
+}
+
+// DashboardBlockTypes returns the block types users can place on their
+// dashboard, in registration order
+func (s *Service) DashboardBlockTypes() []string {
+	return append([]string(nil), s.blockTypes...)
+}
+
+// registerBuiltinDashboardBlocks registers the blocks the service builds itself
+func (s *Service) registerBuiltinDashboardBlocks() {
+	s.RegisterDashboardBlock(DashboardBlockStatus, DashboardBlockProviderFunc(s.statusBlock))
+	s.RegisterDashboardBlock(DashboardBlockHook, DashboardBlockProviderFunc(hookBlock))
+	s.RegisterDashboardBlock(DashboardBlockStreak, DashboardBlockProviderFunc(s.streakBlock))
+	s.RegisterDashboardBlock(DashboardBlockHousehold, DashboardBlockProviderFunc(s.householdBlock))
+	s.RegisterDashboardBlock(DashboardBlockGoals, DashboardBlockProviderFunc(s.goalsBlock))
+	s.RegisterDashboardBlock(DashboardBlockSubscriptions, DashboardBlockProviderFunc(s.subscriptionsBlock))
+	s.RegisterDashboardBlock(DashboardBlockNetWorth, DashboardBlockProviderFunc(s.netWorthBlock))
+	s.RegisterDashboardBlock(DashboardBlockSafeToSpend, DashboardBlockProviderFunc(s.safeToSpendBlock))
+	s.RegisterDashboardBlock(DashboardBlockCTA, DashboardBlockProviderFunc(s.ctaBlock))
+}
+
+// computeDashboardBlocks builds the blocks of the user's layout in order.
+// Blocks that fail are left out rather than failing the whole dashboard.
+func (s *Service) computeDashboardBlocks(ctx context.Context, userID uuid.UUID, asOf time.Time) ([]DashboardBlock, error) {
+	layout, err := s.GetDashboardLayout(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	pulse, err := s.GetSpendingPulse(ctx, userID, asOf, InsightsFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	req := DashboardBlockRequest{UserID: userID, AsOf: asOf, Pulse: pulse}
+	blocks := make([]DashboardBlock, 0, len(layout.Blocks))
+	for _, placed := range layout.Blocks {
+		provider, ok := s.blockProviders[placed.Type]
+		if !ok {
+			continue // No longer registered
+		}
+		block, err := provider.DashboardBlock(ctx, req)
+		if err != nil {
+			if s.logger != nil {
+				s.logger.Warn("failed to build dashboard block", "userID", userID, "block", placed.Type, "error", err)
+			}
+			continue
+		}
+		if block == nil {
+			continue
+		}
+		block.Type = placed.Type
+		block.Size = placed.Size
+		blocks = append(blocks, *block)
+	}
+	return blocks, nil
+}
+
+// spendingSparkline sums monthly spending across categories in the currency
+// of the largest series
+func spendingSparkline(trends *SpendingTrends) *DashboardSparkline {
+	if trends == nil || len(trends.Series) == 0 {
+		return nil
+	}
+	sparkline := &DashboardSparkline{
+		Points:       make([]int64, len(trends.Months)),
+		CurrencyCode: trends.Series[0].CurrencyCode,
+		Label:        "Last " + formatInt(len(trends.Months)) + " months",
+	}
+	for _, series := range trends.Series {
+		if series.CurrencyCode != sparkline.CurrencyCode {
+			continue
+		}
+		for i, v := range series.MonthlyMinor {
+			sparkline.Points[i] += v
+		}
+	}
+	return sparkline
+}
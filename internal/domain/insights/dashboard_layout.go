@@ -0,0 +1,268 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Dashboard Layout (Internal Integration)
+// =============================================================================
+// Users pick which dashboard blocks they see, in what order and at what size.
+// Users who never changed it get DefaultDashboardLayout. Blocks without data
+// (no household, no goals) are left out of the dashboard even when picked.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetDashboardLayoutRequest/Response (InsightsService.GetDashboardLayout)
+//   with the layout, bool is_default and the available block types
+// - UpdateDashboardLayoutRequest/Response (InsightsService.UpdateDashboardLayout)
+// - DashboardLayoutBlock (type, DashboardBlockSize enum) and a size field on
+//   DashboardBlock
+
+// Dashboard block types
+const (
+	DashboardBlockStatus        = "status" // Spending pulse
+	DashboardBlockHook          = "hook"   // Surprise expense or top category
+	DashboardBlockStreak        = "streak"
+	DashboardBlockHousehold     = "household"
+	DashboardBlockGoals         = "goals"
+	DashboardBlockSubscriptions = "subscriptions"
+	DashboardBlockNetWorth      = "net_worth"
+	DashboardBlockSafeToSpend   = "safe_to_spend"
+	DashboardBlockCTA           = "cta"
+)
+
+// DashboardBlockSize is how much of the bento grid a block takes
+type DashboardBlockSize string
+
+const (
+	DashboardBlockSmall  DashboardBlockSize = "small"
+	DashboardBlockMedium DashboardBlockSize = "medium"
+	DashboardBlockLarge  DashboardBlockSize = "large"
+)
+
+// ErrInvalidDashboardLayout is returned for layouts that can't be saved
+var ErrInvalidDashboardLayout = errors.New("invalid dashboard layout")
+
+// DashboardLayoutBlock is a block placed on the dashboard
+type DashboardLayoutBlock struct {
+	Type string             `json:"type"`
+	Size DashboardBlockSize `json:"size"`
+}
+
+// DashboardLayout is the user's dashboard blocks in display order
+type DashboardLayout struct {
+	Blocks    []DashboardLayoutBlock
+	IsDefault bool // The user never changed it
+}
+
+// DefaultDashboardLayout returns the layout of users who never changed it
+func DefaultDashboardLayout() []DashboardLayoutBlock {
+	return []DashboardLayoutBlock{
+		{Type: DashboardBlockStatus, Size: DashboardBlockLarge},
+		{Type: DashboardBlockHook, Size: DashboardBlockMedium},
+		{Type: DashboardBlockStreak, Size: DashboardBlockSmall},
+		{Type: DashboardBlockHousehold, Size: DashboardBlockMedium},
+		{Type: DashboardBlockCTA, Size: DashboardBlockSmall},
+	}
+}
+
+// ValidateDashboardLayout checks that blocks are of the available types, each
+// placed at most once, with a valid size. Blocks without a size get a medium one.
+func ValidateDashboardLayout(blocks []DashboardLayoutBlock, available []string) ([]DashboardLayoutBlock, error) {
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("%w: pick at least one block", ErrInvalidDashboardLayout)
+	}
+	known := make(map[string]bool, len(available))
+	for _, t := range available {
+		known[t] = true
+	}
+
+	validated := make([]DashboardLayoutBlock, 0, len(blocks))
+	seen := make(map[string]bool, len(blocks))
+	for _, b := range blocks {
+		if !known[b.Type] {
+			return nil, fmt.Errorf("%w: unknown block type %q", ErrInvalidDashboardLayout, b.Type)
+		}
+		if seen[b.Type] {
+			return nil, fmt.Errorf("%w: block %q placed twice", ErrInvalidDashboardLayout, b.Type)
+		}
+		seen[b.Type] = true
+		switch b.Size {
+		case "":
+			b.Size = DashboardBlockMedium
+		case DashboardBlockSmall, DashboardBlockMedium, DashboardBlockLarge:
+		default:
+			return nil, fmt.Errorf("%w: unknown size %q", ErrInvalidDashboardLayout, b.Size)
+		}
+		validated = append(validated, b)
+	}
+	return validated, nil
+}
+
+// GetDashboardLayout returns the user's dashboard layout, or the default one
+func (s *Service) GetDashboardLayout(ctx context.Context, userID uuid.UUID) (*DashboardLayout, error) {
+	blocks, err := s.repo.GetDashboardLayout(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard layout: %w", err)
+	}
+	if blocks == nil {
+		return &DashboardLayout{Blocks: DefaultDashboardLayout(), IsDefault: true}, nil
+	}
+	return &DashboardLayout{Blocks: blocks}, nil
+}
+
+// UpdateDashboardLayout replaces the user's dashboard layout. Returns
+// ErrInvalidDashboardLayout for unknown or repeated blocks and invalid sizes.
+func (s *Service) UpdateDashboardLayout(ctx context.Context, userID uuid.UUID, blocks []DashboardLayoutBlock) (*DashboardLayout, error) {
+	blocks, err := ValidateDashboardLayout(blocks, s.DashboardBlockTypes())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertDashboardLayout(ctx, userID, blocks); err != nil {
+		return nil, fmt.Errorf("failed to save dashboard layout: %w", err)
+	}
+	s.InvalidateDashboard(ctx, userID)
+	return &DashboardLayout{Blocks: blocks}, nil
+}
+
+// GetDashboardLayout returns the layout the user saved, or nil if they never did
+func (r *Repository) GetDashboardLayout(ctx context.Context, userID uuid.UUID) ([]DashboardLayoutBlock, error) {
+	var raw []byte
+	err := r.db.QueryRow(ctx, `SELECT blocks FROM dashboard_layouts WHERE user_id = $1`, userID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var blocks []DashboardLayoutBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to decode dashboard layout: %w", err)
+	}
+	return blocks, nil
+}
+
+// UpsertDashboardLayout saves the user's layout
+func (r *Repository) UpsertDashboardLayout(ctx context.Context, userID uuid.UUID, blocks []DashboardLayoutBlock) error {
+	raw, err := json.Marshal(blocks)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `
+		INSERT INTO dashboard_layouts (user_id, blocks)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET blocks = EXCLUDED.blocks
+	`, userID, raw)
+	return err
+}
+
+// NetWorthSource provides the user's current net worth
+type NetWorthSource interface {
+	// NetWorth returns the user's net worth in minor units and its currency
+	NetWorth(ctx context.Context, userID uuid.UUID) (int64, string, error)
+}
+
+// WithNetWorth enables the net worth dashboard block
+func (s *Service) WithNetWorth(src NetWorthSource) *Service {
+	s.netWorth = src
+	return s
+}
+
+// netWorthBlock shows the user's net worth on the dashboard
+func (s *Service) netWorthBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	if s.netWorth == nil {
+		return nil, nil
+	}
+	amount, currency, err := s.netWorth.NetWorth(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	color := "green"
+	if amount < 0 {
+		color = "red"
+	}
+	return &DashboardBlock{
+		Type:     DashboardBlockNetWorth,
+		Title:    "Net Worth",
+		Subtitle: "Across all accounts in " + currency,
+		Value:    formatMoney(amount),
+		Icon:     "wallet",
+		Color:    color,
+		Action:   &DashboardAction{ID: "view_balance", Path: "/balance"},
+	}, nil
+}
+
+// safeToSpendBlock shows today's safe-to-spend allowance on the dashboard
+func (s *Service) safeToSpendBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	sts, err := s.GetSafeToSpend(ctx, req.UserID, req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	block := &DashboardBlock{
+		Type:     DashboardBlockSafeToSpend,
+		Title:    "Safe to Spend Today",
+		Subtitle: formatInt(sts.DaysRemaining) + " days left in this cycle",
+		Value:    formatMoney(sts.DailyMinor),
+		Icon:     "shield-check",
+		Color:    "green",
+		Action:   &DashboardAction{ID: "view_safe_to_spend", Path: "/insights/safe-to-spend"},
+		// How much of what the cycle allows has been spent
+		Progress: newDashboardProgress(sts.SpentMinor, sts.SpentMinor+max(sts.AvailableMinor, 0)),
+	}
+	if sts.AvailableMinor < 0 {
+		block.Subtitle = formatMoney(-sts.AvailableMinor) + " overcommitted this cycle"
+		block.Color = "red"
+	}
+	return block, nil
+}
+
+// goalsBlock shows progress on the user's first savings goal on the dashboard
+func (s *Service) goalsBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	goals, err := s.digestGoals(ctx, req.UserID)
+	if err != nil || len(goals) == 0 {
+		return nil, err
+	}
+	goal := goals[0]
+	subtitle := goal.Name
+	if len(goals) > 1 {
+		subtitle += " · " + formatInt(len(goals)-1) + " more"
+	}
+	progress := newDashboardProgress(goal.CurrentMinor, goal.TargetMinor)
+	return &DashboardBlock{
+		Type:     DashboardBlockGoals,
+		Title:    "Goals",
+		Subtitle: subtitle,
+		Value:    formatInt(int(progress.Ratio*100)) + "%",
+		Icon:     "target",
+		Color:    "blue",
+		Action:   &DashboardAction{ID: "view_goal", Path: "/goals/" + goal.ID.String()},
+		Progress: progress,
+	}, nil
+}
+
+// subscriptionsBlock shows the subscriptions due in the next week on the dashboard
+func (s *Service) subscriptionsBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	upcoming, err := s.upcomingSubscriptions(ctx, req.UserID, req.AsOf, req.AsOf.AddDate(0, 0, 7))
+	if err != nil || len(upcoming) == 0 {
+		return nil, err
+	}
+	var total int64
+	for _, sub := range upcoming {
+		total += sub.AmountMinor
+	}
+	return &DashboardBlock{
+		Type:     DashboardBlockSubscriptions,
+		Title:    "Due This Week",
+		Subtitle: formatInt(len(upcoming)) + " subscriptions, next " + upcoming[0].MerchantName,
+		Value:    formatMoney(total),
+		Icon:     "repeat",
+		Color:    "purple",
+		Action:   &DashboardAction{ID: "view_subscriptions", Path: "/subscriptions"},
+	}, nil
+}
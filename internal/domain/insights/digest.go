@@ -0,0 +1,357 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Weekly Digest (Internal Integration)
+// =============================================================================
+// A compact weekly summary: spend against the week before, subscriptions due in
+// the coming week and nudges for goals falling behind. Users opt in and pick
+// the weekday it's delivered on; a daily job sends it through the notifier.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetWeeklyDigestRequest/Response (InsightsService.GetWeeklyDigest)
+// - GetDigestSettingsRequest/Response, UpdateDigestSettingsRequest/Response
+// - WeeklyDigest, UpcomingSubscription, GoalNudge, DigestSettings
+
+const (
+	// maxDigestNudges caps the goal nudges in one digest
+	maxDigestNudges = 3
+	// goalNudgeTolerance is how far behind its pace a goal may fall before it's nudged
+	goalNudgeTolerance = 0.9
+)
+
+// ErrInvalidDigestSettings is returned for a delivery weekday outside Sunday-Saturday
+var ErrInvalidDigestSettings = errors.New("digest delivery weekday must be between 0 (Sunday) and 6 (Saturday)")
+
+// DigestSettings is a user's weekly digest opt-in
+type DigestSettings struct {
+	Enabled         bool
+	DeliveryWeekday time.Weekday
+	LastSentAt      *time.Time
+}
+
+// UpcomingSubscription is a subscription charge expected in the coming week
+type UpcomingSubscription struct {
+	MerchantName string
+	AmountMinor  int64 // Positive
+	CurrencyCode string
+	DueAt        time.Time
+}
+
+// DigestGoal is the progress of an active savings goal
+type DigestGoal struct {
+	ID           uuid.UUID
+	Name         string
+	TargetMinor  int64
+	CurrentMinor int64
+	StartAt      time.Time
+	EndAt        time.Time
+}
+
+// GoalNudge points out a goal behind the pace needed to reach it on time
+type GoalNudge struct {
+	GoalID            uuid.UUID
+	Name              string
+	CurrentMinor      int64
+	ExpectedMinor     int64 // Where the goal would be at an even pace
+	WeeklyNeededMinor int64 // Weekly saving that still reaches the target on time
+	EndAt             time.Time
+}
+
+// WeeklyDigest summarizes the week ending at WeekEnd
+type WeeklyDigest struct {
+	WeekStart time.Time
+	WeekEnd   time.Time // Exclusive
+
+	SpendMinor         int64
+	LastWeekSpendMinor int64
+	ChangePercent      *float64 // Nil without spend last week
+
+	Upcoming      []UpcomingSubscription
+	UpcomingMinor int64
+	Nudges        []GoalNudge
+
+	Title   string
+	Summary string
+}
+
+// IsEmpty reports whether the digest has nothing worth sending
+func (d *WeeklyDigest) IsEmpty() bool {
+	return d.SpendMinor == 0 && d.LastWeekSpendMinor == 0 && len(d.Upcoming) == 0 && len(d.Nudges) == 0
+}
+
+// GetWeeklyDigest builds the digest for the week before asOf's day
+func (s *Service) GetWeeklyDigest(ctx context.Context, userID uuid.UUID, asOf time.Time) (*WeeklyDigest, error) {
+	weekEnd := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, asOf.Location())
+	weekStart := weekEnd.AddDate(0, 0, -7)
+	d := &WeeklyDigest{WeekStart: weekStart, WeekEnd: weekEnd}
+
+	var err error
+	if d.SpendMinor, _, err = s.getMonthTotals(ctx, userID, weekStart, weekEnd, InsightsFilter{}); err != nil {
+		return nil, fmt.Errorf("failed to get weekly spend: %w", err)
+	}
+	if d.LastWeekSpendMinor, _, err = s.getMonthTotals(ctx, userID, weekStart.AddDate(0, 0, -7), weekStart, InsightsFilter{}); err != nil {
+		return nil, fmt.Errorf("failed to get last week's spend: %w", err)
+	}
+	if d.LastWeekSpendMinor > 0 {
+		change := float64(d.SpendMinor-d.LastWeekSpendMinor) / float64(d.LastWeekSpendMinor) * 100
+		d.ChangePercent = &change
+	}
+
+	if d.Upcoming, err = s.upcomingSubscriptions(ctx, userID, asOf, asOf.AddDate(0, 0, 7)); err != nil {
+		return nil, err
+	}
+	for _, u := range d.Upcoming {
+		d.UpcomingMinor += u.AmountMinor
+	}
+
+	goals, err := s.digestGoals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	d.Nudges = GoalNudges(goals, asOf)
+
+	d.Title, d.Summary = composeDigest(d)
+	return d, nil
+}
+
+// GoalNudges returns nudges for the goals furthest behind an even pace from
+// their start to their end date, at most maxDigestNudges
+func GoalNudges(goals []DigestGoal, asOf time.Time) []GoalNudge {
+	var nudges []GoalNudge
+	for _, g := range goals {
+		if !asOf.After(g.StartAt) || !asOf.Before(g.EndAt) || g.CurrentMinor >= g.TargetMinor {
+			continue
+		}
+		elapsed := asOf.Sub(g.StartAt).Hours() / g.EndAt.Sub(g.StartAt).Hours()
+		expected := int64(math.Round(float64(g.TargetMinor) * elapsed))
+		if float64(g.CurrentMinor) >= float64(expected)*goalNudgeTolerance {
+			continue
+		}
+
+		weeksLeft := math.Max(math.Ceil(g.EndAt.Sub(asOf).Hours()/(24*7)), 1)
+		nudges = append(nudges, GoalNudge{
+			GoalID:            g.ID,
+			Name:              g.Name,
+			CurrentMinor:      g.CurrentMinor,
+			ExpectedMinor:     expected,
+			WeeklyNeededMinor: int64(math.Ceil(float64(g.TargetMinor-g.CurrentMinor) / weeksLeft)),
+			EndAt:             g.EndAt,
+		})
+	}
+
+	// Furthest behind first
+	sort.SliceStable(nudges, func(i, j int) bool {
+		return nudges[i].ExpectedMinor-nudges[i].CurrentMinor > nudges[j].ExpectedMinor-nudges[j].CurrentMinor
+	})
+	if len(nudges) > maxDigestNudges {
+		nudges = nudges[:maxDigestNudges]
+	}
+	return nudges
+}
+
+// composeDigest writes the digest's notification title and body
+func composeDigest(d *WeeklyDigest) (title, summary string) {
+	title = fmt.Sprintf("Your week: %s spent", formatMoney(d.SpendMinor))
+
+	var lines []string
+	switch {
+	case d.ChangePercent == nil:
+		lines = append(lines, fmt.Sprintf("You spent %s this week.", formatMoney(d.SpendMinor)))
+	case *d.ChangePercent >= 0.5:
+		lines = append(lines, fmt.Sprintf("You spent %s this week, %.0f%% more than last week.", formatMoney(d.SpendMinor), *d.ChangePercent))
+	case *d.ChangePercent <= -0.5:
+		lines = append(lines, fmt.Sprintf("You spent %s this week, %.0f%% less than last week.", formatMoney(d.SpendMinor), -*d.ChangePercent))
+	default:
+		lines = append(lines, fmt.Sprintf("You spent %s this week, about the same as last week.", formatMoney(d.SpendMinor)))
+	}
+
+	if len(d.Upcoming) > 0 {
+		names := make([]string, 0, len(d.Upcoming))
+		for _, u := range d.Upcoming {
+			names = append(names, u.MerchantName)
+		}
+		renew := fmt.Sprintf("%d subscriptions renew", len(d.Upcoming))
+		if len(d.Upcoming) == 1 {
+			renew = "1 subscription renews"
+		}
+		lines = append(lines, fmt.Sprintf("%s in the next 7 days (%s): %s.", renew, formatMoney(d.UpcomingMinor), strings.Join(names, ", ")))
+	}
+
+	for _, n := range d.Nudges {
+		lines = append(lines, fmt.Sprintf("%s is behind pace; save %s a week to reach it by %s.", n.Name, formatMoney(n.WeeklyNeededMinor), n.EndAt.Format("2 Jan")))
+	}
+	return title, strings.Join(lines, " ")
+}
+
+// upcomingSubscriptions lists active subscriptions expected in (from, to]
+func (s *Service) upcomingSubscriptions(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]UpcomingSubscription, error) {
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT merchant_name, ABS(amount_minor), currency_code, next_expected_at
+		FROM recurring_subscriptions
+		WHERE user_id = $1 AND status = 'active'
+		  AND next_expected_at > $2 AND next_expected_at <= $3
+		ORDER BY next_expected_at
+	`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upcoming subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var upcoming []UpcomingSubscription
+	for rows.Next() {
+		var u UpcomingSubscription
+		if err := rows.Scan(&u.MerchantName, &u.AmountMinor, &u.CurrencyCode, &u.DueAt); err != nil {
+			return nil, fmt.Errorf("failed to scan upcoming subscription: %w", err)
+		}
+		upcoming = append(upcoming, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate upcoming subscriptions: %w", err)
+	}
+	return upcoming, nil
+}
+
+// digestGoals lists the user's active savings goals
+func (s *Service) digestGoals(ctx context.Context, userID uuid.UUID) ([]DigestGoal, error) {
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT id, name::TEXT, target_amount_minor, current_amount_minor, start_at, end_at
+		FROM goals
+		WHERE user_id = $1 AND status = 'active' AND type = 'save' AND deleted_at IS NULL
+		ORDER BY priority = 0, priority, end_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []DigestGoal
+	for rows.Next() {
+		var g DigestGoal
+		if err := rows.Scan(&g.ID, &g.Name, &g.TargetMinor, &g.CurrentMinor, &g.StartAt, &g.EndAt); err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate goals: %w", err)
+	}
+	return goals, nil
+}
+
+// GetDigestSettings returns the user's digest settings; users who never opted
+// in get the disabled defaults
+func (s *Service) GetDigestSettings(ctx context.Context, userID uuid.UUID) (*DigestSettings, error) {
+	settings := &DigestSettings{DeliveryWeekday: time.Monday}
+	var weekday int16
+	err := s.repo.DB().QueryRow(ctx, `
+		SELECT enabled, delivery_weekday, last_sent_at
+		FROM weekly_digest_settings
+		WHERE user_id = $1
+	`, userID).Scan(&settings.Enabled, &weekday, &settings.LastSentAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest settings: %w", err)
+	}
+	settings.DeliveryWeekday = time.Weekday(weekday)
+	return settings, nil
+}
+
+// UpdateDigestSettings opts the user in or out of the weekly digest
+func (s *Service) UpdateDigestSettings(ctx context.Context, userID uuid.UUID, enabled bool, weekday time.Weekday) (*DigestSettings, error) {
+	if weekday < time.Sunday || weekday > time.Saturday {
+		return nil, ErrInvalidDigestSettings
+	}
+	settings := &DigestSettings{Enabled: enabled, DeliveryWeekday: weekday}
+	err := s.repo.DB().QueryRow(ctx, `
+		INSERT INTO weekly_digest_settings (user_id, enabled, delivery_weekday)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, delivery_weekday = EXCLUDED.delivery_weekday
+		RETURNING last_sent_at
+	`, userID, enabled, int16(weekday)).Scan(&settings.LastSentAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update digest settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SendWeeklyDigests sends the digest to every opted-in user whose delivery day
+// is today. Each user is claimed before sending, so a digest goes out at most
+// once a week even if the job runs again. Returns the number of digests sent.
+func (s *Service) SendWeeklyDigests(ctx context.Context, now time.Time) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT user_id
+		FROM weekly_digest_settings
+		WHERE enabled AND delivery_weekday = $1
+		  AND (last_sent_at IS NULL OR last_sent_at < $2)
+	`, int16(now.Weekday()), now.AddDate(0, 0, -6))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan digest recipient: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+
+		tag, err := s.repo.DB().Exec(ctx, `
+			UPDATE weekly_digest_settings SET last_sent_at = $2
+			WHERE user_id = $1 AND (last_sent_at IS NULL OR last_sent_at < $3)
+		`, userID, now, now.AddDate(0, 0, -6))
+		if err != nil {
+			s.logger.Warn("failed to claim weekly digest", "userID", userID, "error", err)
+			continue
+		}
+		if tag.RowsAffected() == 0 {
+			continue // Sent by a concurrent run
+		}
+
+		digest, err := s.GetWeeklyDigest(ctx, userID, now)
+		if err != nil {
+			s.logger.Warn("failed to build weekly digest", "userID", userID, "error", err)
+			continue
+		}
+		if digest.IsEmpty() {
+			continue
+		}
+		if err := s.notifier.NotifyDigest(ctx, userID, digest); err != nil {
+			s.logger.Warn("failed to send weekly digest", "userID", userID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
@@ -0,0 +1,124 @@
+package insights
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Insights Filters (Internal Integration)
+// =============================================================================
+// The spending pulse and monthly insights can be narrowed to some accounts and
+// leave out categories, e.g. to keep a business account out of personal
+// spending. Accounts and categories the user flags as excluded from insights
+// are always left out, filter or not.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - repeated string account_ids and exclude_category_ids on
+//   GetSpendingPulseRequest and GetMonthlyInsightsRequest
+// - SetAccountExcludedFromInsightsRequest/Response and
+//   SetCategoryExcludedFromInsightsRequest/Response (id, bool excluded)
+// - bool excluded_from_insights on Account and Category
+
+// InsightsFilter narrows insights to some of the user's transactions. The
+// zero value covers every account and category not excluded from insights.
+type InsightsFilter struct {
+	AccountIDs         []uuid.UUID // Only these accounts; empty means all
+	ExcludeCategoryIDs []uuid.UUID // Leave these categories out
+}
+
+// cacheKey distinguishes cached results per filter; empty for the zero filter
+// so unfiltered keys are unchanged
+func (f InsightsFilter) cacheKey() string {
+	if len(f.AccountIDs) == 0 && len(f.ExcludeCategoryIDs) == 0 {
+		return ""
+	}
+	return ":a=" + joinSortedIDs(f.AccountIDs) + ":x=" + joinSortedIDs(f.ExcludeCategoryIDs)
+}
+
+func joinSortedIDs(ids []uuid.UUID) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	slices.Sort(s)
+	return strings.Join(s, ",")
+}
+
+// sql returns the conditions restricting the transactions referenced as t to
+// the filter, taking its account and category lists from parameters $n and
+// $n+1 (see args)
+func (f InsightsFilter) sql(t string, n int) string {
+	return fmt.Sprintf(`
+		  AND ($%[2]d::uuid[] IS NULL OR %[1]s.account_id = ANY($%[2]d::uuid[]))
+		  AND (%[1]s.category_id IS NULL OR %[1]s.category_id <> ALL(COALESCE($%[3]d::uuid[], '{}')))
+		  AND NOT EXISTS (SELECT 1 FROM accounts xa WHERE xa.id = %[1]s.account_id AND xa.excluded_from_insights)
+		  AND NOT EXISTS (SELECT 1 FROM categories xc WHERE xc.id = %[1]s.category_id AND xc.excluded_from_insights)`,
+		t, n, n+1)
+}
+
+// args returns the parameters for sql; an empty account list is NULL so it
+// matches every account
+func (f InsightsFilter) args() []any {
+	var accountIDs any
+	if len(f.AccountIDs) > 0 {
+		accountIDs = f.AccountIDs
+	}
+	return []any{accountIDs, f.ExcludeCategoryIDs}
+}
+
+// SetAccountExcludedFromInsights flags the user's account so its transactions
+// are left out of insights. Returns sql.ErrNoRows if it isn't the user's.
+func (r *Repository) SetAccountExcludedFromInsights(ctx context.Context, userID, accountID uuid.UUID, excluded bool) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE accounts SET excluded_from_insights = $3 WHERE id = $2 AND user_id = $1`,
+		userID, accountID, excluded)
+	if err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetCategoryExcludedFromInsights flags the user's category so its
+// transactions are left out of insights. Returns sql.ErrNoRows if it isn't
+// the user's.
+func (r *Repository) SetCategoryExcludedFromInsights(ctx context.Context, userID, categoryID uuid.UUID, excluded bool) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE categories SET excluded_from_insights = $3 WHERE id = $2 AND user_id = $1`,
+		userID, categoryID, excluded)
+	if err != nil {
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetAccountExcludedFromInsights leaves the account out of (or back in) the
+// user's insights
+func (s *Service) SetAccountExcludedFromInsights(ctx context.Context, userID, accountID uuid.UUID, excluded bool) error {
+	if err := s.repo.SetAccountExcludedFromInsights(ctx, userID, accountID, excluded); err != nil {
+		return err
+	}
+	s.InvalidateDashboard(ctx, userID)
+	return nil
+}
+
+// SetCategoryExcludedFromInsights leaves the category out of (or back in) the
+// user's insights
+func (s *Service) SetCategoryExcludedFromInsights(ctx context.Context, userID, categoryID uuid.UUID, excluded bool) error {
+	if err := s.repo.SetCategoryExcludedFromInsights(ctx, userID, categoryID, excluded); err != nil {
+		return err
+	}
+	s.InvalidateDashboard(ctx, userID)
+	return nil
+}
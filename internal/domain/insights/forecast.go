@@ -0,0 +1,292 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Cash-flow Forecast (Internal Integration)
+// =============================================================================
+// Projects the end-of-month balance from the expected salary, scheduled
+// subscriptions and installments, and the average daily discretionary spend,
+// answering "will I make it to payday".
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetCashflowForecastRequest/Response (InsightsService.GetCashflowForecast)
+// - CashflowScenario, ScheduledCharge
+
+const (
+	// forecastLookbackDays is how much spending history sets the daily average
+	forecastLookbackDays = 90
+	// forecastBandZ sets the best and worst bands at roughly the 10th and 90th
+	// percentile of the remaining month's discretionary spend
+	forecastBandZ = 1.28
+)
+
+// ScheduledCharge is a known payment due before the end of the month
+type ScheduledCharge struct {
+	Name        string
+	AmountMinor int64 // Positive
+	DueAt       time.Time
+}
+
+// CashflowScenario is one projection of the rest of the month
+type CashflowScenario struct {
+	DiscretionaryMinor int64 // Projected discretionary spend until the end of the month
+	EndBalanceMinor    int64
+}
+
+// CashflowForecast projects the user's balance at the end of the month
+type CashflowForecast struct {
+	AsOf          time.Time
+	PeriodEnd     time.Time // Last day of the month
+	DaysRemaining int
+
+	CurrentBalanceMinor     int64
+	ExpectedIncomeMinor     int64      // Salary expected before the end of the month
+	NextPayday              *time.Time // Nil when payday can't be inferred
+	ScheduledCharges        []ScheduledCharge
+	ScheduledMinor          int64
+	DailyDiscretionaryMinor int64 // Average over the lookback
+
+	Best     CashflowScenario
+	Expected CashflowScenario
+	Worst    CashflowScenario
+
+	// SavingsRate is the share of this month's income expected to be left over
+	// (negative when spending exceeds it); nil without income this month
+	SavingsRate *float64
+	// AtRisk is true when the worst case ends the month below zero
+	AtRisk bool
+}
+
+// CashflowInputs are the figures a forecast is projected from
+type CashflowInputs struct {
+	AsOf            time.Time
+	BalanceMinor    int64
+	DailySpendMinor []int64 // Discretionary spend of each past day, days without spend included
+	IncomeMinor     int64   // Income still expected this month
+	NextPayday      *time.Time
+	Charges         []ScheduledCharge
+	MonthIncome     int64 // Income received so far this month
+	MonthSpend      int64 // Spend so far this month, positive
+}
+
+// GetCashflowForecast projects the user's end-of-month balance as of asOf
+func (s *Service) GetCashflowForecast(ctx context.Context, userID uuid.UUID, asOf time.Time) (*CashflowForecast, error) {
+	periodEnd := endOfMonth(asOf)
+	db := s.repo.DB()
+
+	in := CashflowInputs{AsOf: asOf}
+
+	err := db.QueryRow(ctx, `
+		SELECT
+			COALESCE((SELECT SUM(amount_minor) FROM transactions WHERE user_id = $1 AND deleted_at IS NULL AND posted_at <= $2), 0)
+			+ COALESCE((SELECT amount_minor FROM balance_snapshots
+			            WHERE user_id = $1 AND snapshot_type = 'opening_balance'), 0),
+			COALESCE((SELECT SUM(amount_minor) FROM transactions
+			          WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $3 AND posted_at <= $2
+			            AND amount_minor > 0 AND NOT is_reward), 0),
+			COALESCE((SELECT -SUM(amount_minor) FROM transactions
+			          WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $3 AND posted_at <= $2
+			            AND amount_minor < 0), 0)
+	`, userID, asOf, startOfMonth(asOf)).Scan(&in.BalanceMinor, &in.MonthIncome, &in.MonthSpend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	if in.DailySpendMinor, err = s.dailyDiscretionarySpend(ctx, userID, asOf); err != nil {
+		return nil, err
+	}
+	if in.Charges, err = s.scheduledCharges(ctx, userID, asOf, periodEnd); err != nil {
+		return nil, err
+	}
+
+	salaries, err := s.recentSalaries(ctx, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]time.Time, len(salaries))
+	for i, p := range salaries {
+		dates[i] = p.PostedAt
+	}
+	if payday := InferPayday(s.calendars.ForUser(ctx, userID), dates, asOf); payday != nil {
+		in.NextPayday = &payday.Next
+		if !payday.Next.After(periodEnd) {
+			in.IncomeMinor = medianSalary(salaries)
+		}
+	}
+
+	return ProjectCashflow(in), nil
+}
+
+// ProjectCashflow projects the rest of the month. Discretionary spend over the
+// remaining days is the daily average, with bands from its day-to-day spread.
+func ProjectCashflow(in CashflowInputs) *CashflowForecast {
+	periodEnd := endOfMonth(in.AsOf)
+	days := periodEnd.Day() - in.AsOf.Day()
+
+	f := &CashflowForecast{
+		AsOf:                in.AsOf,
+		PeriodEnd:           periodEnd,
+		DaysRemaining:       days,
+		CurrentBalanceMinor: in.BalanceMinor,
+		ExpectedIncomeMinor: in.IncomeMinor,
+		NextPayday:          in.NextPayday,
+		ScheduledCharges:    in.Charges,
+	}
+	for _, c := range in.Charges {
+		f.ScheduledMinor += c.AmountMinor
+	}
+
+	mean, stddev := meanStddev(in.DailySpendMinor)
+	f.DailyDiscretionaryMinor = int64(math.Round(mean))
+
+	expected := mean * float64(days)
+	spread := forecastBandZ * stddev * math.Sqrt(float64(days))
+	start := in.BalanceMinor + in.IncomeMinor - f.ScheduledMinor
+	scenario := func(discretionary float64) CashflowScenario {
+		d := int64(math.Round(math.Max(discretionary, 0)))
+		return CashflowScenario{DiscretionaryMinor: d, EndBalanceMinor: start - d}
+	}
+	f.Best = scenario(expected - spread)
+	f.Expected = scenario(expected)
+	f.Worst = scenario(expected + spread)
+	f.AtRisk = f.Worst.EndBalanceMinor < 0
+
+	if income := in.MonthIncome + in.IncomeMinor; income > 0 {
+		spend := in.MonthSpend + f.ScheduledMinor + f.Expected.DiscretionaryMinor
+		rate := float64(income-spend) / float64(income)
+		f.SavingsRate = &rate
+	}
+	return f
+}
+
+// dailyDiscretionarySpend returns the spend of each of the last days, leaving
+// out subscriptions and installments, which the forecast schedules separately
+func (s *Service) dailyDiscretionarySpend(ctx context.Context, userID uuid.UUID, asOf time.Time) ([]int64, error) {
+	rows, err := s.repo.DB().Query(ctx, `
+		WITH days AS (
+			SELECT generate_series($2::date - ($3::int - 1), $2::date, INTERVAL '1 day')::date AS day
+		)
+		SELECT COALESCE(SUM(-t.amount_minor), 0)
+		FROM days d
+		LEFT JOIN transactions t
+		  ON t.user_id = $1 AND t.deleted_at IS NULL AND t.posted_at::date = d.day AND t.amount_minor < 0
+		 AND NOT EXISTS (
+		     SELECT 1 FROM recurring_subscriptions rs
+		     WHERE rs.user_id = t.user_id AND rs.status = 'active'
+		       AND LOWER(rs.merchant_name) = LOWER(COALESCE(t.merchant_name, t.description)))
+		 AND NOT EXISTS (SELECT 1 FROM installment_charges ic WHERE ic.transaction_id = t.id)
+		GROUP BY d.day
+		ORDER BY d.day
+	`, userID, asOf, forecastLookbackDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily spend: %w", err)
+	}
+	defer rows.Close()
+
+	var spend []int64
+	for rows.Next() {
+		var amount int64
+		if err := rows.Scan(&amount); err != nil {
+			return nil, fmt.Errorf("failed to scan daily spend: %w", err)
+		}
+		spend = append(spend, amount)
+	}
+	return spend, rows.Err()
+}
+
+// scheduledCharges lists active subscriptions and unpaid installments due after
+// asOf and up to periodEnd. Weekly subscriptions repeat within the month.
+func (s *Service) scheduledCharges(ctx context.Context, userID uuid.UUID, asOf, periodEnd time.Time) ([]ScheduledCharge, error) {
+	end := periodEnd.AddDate(0, 0, 1) // Exclusive, so the whole last day counts
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT merchant_name, ABS(amount_minor), next_expected_at, cadence = 'weekly'
+		FROM recurring_subscriptions
+		WHERE user_id = $1 AND status = 'active'
+		  AND next_expected_at > $2 AND next_expected_at < $3
+		UNION ALL
+		SELECT ip.merchant_name, ic.amount_minor, ic.due_at::timestamptz, FALSE
+		FROM installment_charges ic
+		JOIN installment_plans ip ON ip.id = ic.plan_id
+		WHERE ip.user_id = $1 AND ip.status = 'active'
+		  AND ic.transaction_id IS NULL
+		  AND ic.due_at > $2::date AND ic.due_at < $3::date
+	`, userID, asOf, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []ScheduledCharge
+	for rows.Next() {
+		var c ScheduledCharge
+		var weekly bool
+		if err := rows.Scan(&c.Name, &c.AmountMinor, &c.DueAt, &weekly); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled charge: %w", err)
+		}
+		for weekly && c.DueAt.Before(end) {
+			charges = append(charges, c)
+			c.DueAt = c.DueAt.AddDate(0, 0, 7)
+		}
+		if !weekly {
+			charges = append(charges, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate scheduled charges: %w", err)
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool { return charges[i].DueAt.Before(charges[j].DueAt) })
+	return charges, nil
+}
+
+// medianSalary is the median of the monthly salaries, robust to a bonus month
+func medianSalary(salaries []salaryPayment) int64 {
+	if len(salaries) == 0 {
+		return 0
+	}
+	amounts := make([]int64, len(salaries))
+	for i, p := range salaries {
+		amounts[i] = p.AmountMinor
+	}
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i] < amounts[j] })
+	mid := len(amounts) / 2
+	if len(amounts)%2 == 0 {
+		return (amounts[mid-1] + amounts[mid]) / 2
+	}
+	return amounts[mid]
+}
+
+// meanStddev returns the mean and population standard deviation of values
+func meanStddev(values []int64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += float64(v)
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		d := float64(v) - mean
+		stddev += d * d
+	}
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
+
+// startOfMonth returns midnight on the first day of t's month
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// endOfMonth returns midnight on the last day of t's month
+func endOfMonth(t time.Time) time.Time {
+	return startOfMonth(t).AddDate(0, 1, -1)
+}
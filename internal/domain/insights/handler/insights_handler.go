@@ -3,7 +3,9 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"connectrpc.com/connect"
@@ -48,7 +50,7 @@ func (h *InsightsHandler) GetSpendingPulse(
 		asOf = req.Msg.AsOf.AsTime()
 	}
 
-	pulse, err := h.svc.GetSpendingPulse(ctx, userID, asOf)
+	pulse, err := h.svc.GetSpendingPulse(ctx, userID, asOf, insights.InsightsFilter{})
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
@@ -66,7 +68,7 @@ func (h *InsightsHandler) GetSpendingPulse(
 			TransactionCount:  int32(pulse.TransactionCount),
 			AsOfDate:          timestamppb.New(pulse.AsOfDate),
 		},
-		ShouldNotify: h.svc.ShouldNotify(pulse),
+		ShouldNotify: h.svc.ShouldNotify(ctx, userID, pulse),
 	}
 
 	// Add top categories
@@ -96,12 +98,13 @@ func (h *InsightsHandler) GetSpendingPulse(
 		resp.Pulse.SurpriseExpenses = append(resp.Pulse.SurpriseExpenses, protoExp)
 	}
 
-	// Auto-trigger pace alert if user is overspending (fire and forget)
-	if resp.ShouldNotify {
-		go func() {
+	// Auto-trigger pace and surprise expense alerts (fire and forget)
+	go func() {
+		if resp.ShouldNotify {
 			_ = h.svc.TriggerPaceAlert(context.Background(), userID, pulse)
-		}()
-	}
+		}
+		_ = h.svc.TriggerSurpriseExpenseAlert(context.Background(), userID, pulse)
+	}()
 
 	return connect.NewResponse(resp), nil
 }
@@ -136,8 +139,8 @@ func (h *InsightsHandler) GetDashboardBlocks(
 			Icon:     b.Icon,
 			Color:    b.Color,
 		}
-		if b.Action != "" {
-			protoBlock.Action = &b.Action
+		if b.Action != nil {
+			protoBlock.Action = &b.Action.ID
 		}
 		protoBlocks = append(protoBlocks, protoBlock)
 	}
@@ -281,20 +284,28 @@ func (h *InsightsHandler) GetImportInsights(
 	ctx context.Context,
 	req *connect.Request[echov1.GetImportInsightsRequest],
 ) (*connect.Response[echov1.GetImportInsightsResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
-	if !ok {
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
+	if !ok || userIDStr == "" {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
 	}
 
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, errors.New("invalid user ID in context"))
+	}
+
 	importJobID, err := uuid.Parse(req.Msg.ImportJobId)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid import job ID"))
 	}
 
-	insights, err := h.svc.GetImportInsights(ctx, importJobID)
-	if err != nil {
+	insights, err := h.svc.GetImportInsights(ctx, userID, importJobID)
+	if errors.Is(err, sql.ErrNoRows) {
 		return nil, connect.NewError(connect.CodeNotFound, errors.New("import insights not found"))
 	}
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get import insights: %w", err))
+	}
 
 	// Convert domain issues to proto
 	protoIssues := make([]*echov1.ImportIssue, 0, len(insights.Issues))
@@ -311,9 +322,9 @@ func (h *InsightsHandler) GetImportInsights(
 		Insights: &echov1.ImportInsights{
 			ImportJobId:        insights.ImportJobID.String(),
 			InstitutionName:    insights.InstitutionName,
-			TotalRows:          int32(insights.DuplicatesSkipped), // TODO: compute from import job
-			RowsImported:       int32(0),                          // TODO: compute from import job
-			RowsFailed:         int32(0),                          // TODO: compute from import job
+			TotalRows:          int32(insights.RowsTotal),
+			RowsImported:       int32(insights.RowsImported),
+			RowsFailed:         int32(insights.RowsFailed),
 			DuplicatesSkipped:  int32(insights.DuplicatesSkipped),
 			CategorizationRate: insights.CategorizationRate,
 			DateQualityScore:   insights.DateQualityScore,
@@ -439,7 +450,7 @@ func (h *InsightsHandler) GetMonthlyInsights(
 	}
 
 	monthStart := req.Msg.MonthStart.AsTime()
-	mi, err := h.svc.GetMonthlyInsights(ctx, userID, monthStart)
+	mi, err := h.svc.GetMonthlyInsights(ctx, userID, monthStart, insights.InsightsFilter{})
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
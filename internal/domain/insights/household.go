@@ -0,0 +1,105 @@
+package insights
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Household Insights (Internal Integration)
+// =============================================================================
+// Members of a household see their combined spending next to their own: the
+// month's total against last month's pace and the top categories across all
+// members. It appears as a dashboard block, but requires proto definitions to
+// be exposed as an API endpoint.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetHouseholdSpendingRequest/Response (InsightsService.GetHouseholdSpending)
+// - HouseholdSpending
+
+// HouseholdSource lists the members of a user's household
+type HouseholdSource interface {
+	// MemberIDs returns the user and their household's other members; just the
+	// user when they don't belong to a household
+	MemberIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// HouseholdSpending is the combined spending of a household's members
+type HouseholdSpending struct {
+	MemberCount       int
+	CurrentMonthSpend int64
+	LastMonthSpend    int64 // Through the same day
+	PacePercent       float64
+	TopCategories     []TopCategory
+}
+
+// WithHouseholds enables combined household insights
+func (s *Service) WithHouseholds(src HouseholdSource) *Service {
+	s.households = src
+	return s
+}
+
+// GetHouseholdSpending returns the combined spending of the user's household,
+// or nil when households aren't configured or the user doesn't belong to one
+func (s *Service) GetHouseholdSpending(ctx context.Context, userID uuid.UUID, asOf time.Time) (*HouseholdSpending, error) {
+	if s.households == nil {
+		return nil, nil
+	}
+	memberIDs, err := s.households.MemberIDs(ctx, userID)
+	if err != nil || len(memberIDs) < 2 {
+		return nil, err
+	}
+
+	data, err := s.repo.GetHouseholdSpendingPulseData(ctx, memberIDs, asOf)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.GetHouseholdTopCategories(ctx, memberIDs, asOf, 5)
+	if err != nil {
+		categories = nil // Non-critical
+	}
+
+	spending := &HouseholdSpending{
+		MemberCount:       len(memberIDs),
+		CurrentMonthSpend: data.CurrentMonthSpend,
+		LastMonthSpend:    data.LastMonthSpend,
+		TopCategories:     categories,
+	}
+	if data.LastMonthSpend > 0 {
+		spending.PacePercent = float64(data.CurrentMonthSpend) / float64(data.LastMonthSpend) * 100
+	} else if data.CurrentMonthSpend > 0 {
+		spending.PacePercent = 100 // No baseline, assume on track
+	}
+	return spending, nil
+}
+
+// householdBlock shows the household's combined spending on the dashboard
+func (s *Service) householdBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	spending, err := s.GetHouseholdSpending(ctx, req.UserID, req.AsOf)
+	if err != nil || spending == nil {
+		return nil, err
+	}
+
+	color := "green"
+	if spending.PacePercent > PaceThreshold {
+		color = "red"
+	} else if spending.PacePercent > 110 {
+		color = "yellow"
+	}
+
+	subtitle := "Combined spending of " + formatInt(spending.MemberCount) + " members"
+	if len(spending.TopCategories) > 0 {
+		subtitle += " · most on " + spending.TopCategories[0].CategoryName
+	}
+	return &DashboardBlock{
+		Type:     DashboardBlockHousehold,
+		Title:    "Household This Month",
+		Subtitle: subtitle,
+		Value:    formatMoney(spending.CurrentMonthSpend),
+		Icon:     "users",
+		Color:    color,
+		Action:   &DashboardAction{ID: "view_household", Path: "/household"},
+	}, nil
+}
@@ -2,6 +2,7 @@ package insights
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"sort"
@@ -95,6 +96,7 @@ type MonthlyInsights struct {
 	RecommendedAction  *ActionRecommendation
 	SpendVsLastMonth   int64
 	SpendChangePercent float64
+	Notes              []PeriodNote // The user's notes on the month and its categories
 	CreatedAt          time.Time
 }
 
@@ -105,8 +107,9 @@ type MerchantSpend struct {
 	TxCount      int
 }
 
-// GetMonthlyInsights generates monthly insights with "3 things changed" and "1 action"
-func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, monthStart time.Time) (*MonthlyInsights, error) {
+// GetMonthlyInsights generates monthly insights with "3 things changed" and "1 action",
+// narrowed by the filter
+func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, monthStart time.Time, filter InsightsFilter) (*MonthlyInsights, error) {
 	// Normalize to first of month
 	year, month, _ := monthStart.Date()
 	monthStart = time.Date(year, month, 1, 0, 0, 0, 0, monthStart.Location())
@@ -122,7 +125,7 @@ func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, mont
 	}
 
 	// Get current month totals
-	currentSpend, currentIncome, err := s.getMonthTotals(ctx, userID, monthStart, monthEnd)
+	currentSpend, currentIncome, err := s.getMonthTotals(ctx, userID, monthStart, monthEnd, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current month totals: %w", err)
 	}
@@ -131,7 +134,7 @@ func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, mont
 	insights.Net = currentIncome - currentSpend
 
 	// Get last month totals for comparison
-	lastSpend, _, err := s.getMonthTotals(ctx, userID, lastMonthStart, lastMonthEnd)
+	lastSpend, _, err := s.getMonthTotals(ctx, userID, lastMonthStart, lastMonthEnd, filter)
 	if err != nil {
 		lastSpend = 0
 	}
@@ -141,19 +144,19 @@ func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, mont
 	}
 
 	// Get top categories
-	categories, err := s.repo.GetTopCategories(ctx, userID, monthEnd.AddDate(0, 0, -1), 5)
+	categories, err := s.repo.GetTopCategories(ctx, userID, monthEnd.AddDate(0, 0, -1), 5, filter)
 	if err == nil {
 		insights.TopCategories = categories
 	}
 
 	// Get top merchants
-	merchants, err := s.getTopMerchants(ctx, userID, monthStart, monthEnd, 5)
+	merchants, err := s.getTopMerchants(ctx, userID, monthStart, monthEnd, 5, filter)
 	if err == nil {
 		insights.TopMerchants = merchants
 	}
 
 	// Generate "3 things that changed"
-	insights.Changes = s.detectChanges(ctx, userID, monthStart, monthEnd, lastMonthStart, lastMonthEnd)
+	insights.Changes = s.detectChanges(ctx, userID, monthStart, monthEnd, lastMonthStart, lastMonthEnd, filter)
 
 	// Generate "1 action to take"
 	insights.RecommendedAction = s.generateRecommendation(ctx, userID, insights)
@@ -161,35 +164,132 @@ func (s *Service) GetMonthlyInsights(ctx context.Context, userID uuid.UUID, mont
 	// Generate highlights
 	insights.Highlights = s.generateHighlights(insights)
 
+	// Carry the user's explanations for the month alongside its numbers
+	notes, err := s.ListPeriodNotes(ctx, userID, monthStart, monthStart)
+	if err == nil {
+		insights.Notes = notes
+	}
+
 	return insights, nil
 }
 
-// getMonthTotals returns total spending and income for a month
-func (s *Service) getMonthTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) (spend, income int64, err error) {
+// PrecomputeMonthlyInsights generates and stores monthly insights for every user
+// with transactions in the given month. Returns the number of users processed.
+func (s *Service) PrecomputeMonthlyInsights(ctx context.Context, monthStart time.Time) (int, error) {
+	year, month, _ := monthStart.Date()
+	monthStart = time.Date(year, month, 1, 0, 0, 0, 0, monthStart.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	// Use each user's dominant currency for the month
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT user_id, MODE() WITHIN GROUP (ORDER BY currency_code)
+		FROM transactions
+		WHERE posted_at >= $1 AND posted_at < $2 AND deleted_at IS NULL
+		GROUP BY user_id
+	`, monthStart, monthEnd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users with transactions: %w", err)
+	}
+
+	type userCurrency struct {
+		userID   uuid.UUID
+		currency string
+	}
+	var users []userCurrency
+	for rows.Next() {
+		var u userCurrency
+		if err := rows.Scan(&u.userID, &u.currency); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list users with transactions: %w", err)
+	}
+
+	processed := 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+
+		insights, err := s.GetMonthlyInsights(ctx, u.userID, monthStart, InsightsFilter{})
+		if err != nil {
+			s.logger.Warn("failed to compute monthly insights", "userID", u.userID, "error", err)
+			continue
+		}
+		if err := s.saveMonthlyInsights(ctx, insights, u.currency); err != nil {
+			s.logger.Warn("failed to store monthly insights", "userID", u.userID, "error", err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// saveMonthlyInsights upserts a monthly insights snapshot
+func (s *Service) saveMonthlyInsights(ctx context.Context, insights *MonthlyInsights, currency string) error {
+	topCategories, err := json.Marshal(insights.TopCategories)
+	if err != nil {
+		return err
+	}
+	topMerchants, err := json.Marshal(insights.TopMerchants)
+	if err != nil {
+		return err
+	}
+	highlights, err := json.Marshal(insights.Highlights)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.DB().Exec(ctx, `
+		INSERT INTO monthly_insights (
+			user_id, month_start, total_spend_minor, total_income_minor, net_minor,
+			currency_code, top_categories_json, top_merchants_json, highlights_json
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, month_start) DO UPDATE SET
+			total_spend_minor = EXCLUDED.total_spend_minor,
+			total_income_minor = EXCLUDED.total_income_minor,
+			net_minor = EXCLUDED.net_minor,
+			currency_code = EXCLUDED.currency_code,
+			top_categories_json = EXCLUDED.top_categories_json,
+			top_merchants_json = EXCLUDED.top_merchants_json,
+			highlights_json = EXCLUDED.highlights_json,
+			created_at = NOW()
+	`, insights.UserID, insights.MonthStart, insights.TotalSpend, insights.TotalIncome, insights.Net,
+		currency, topCategories, topMerchants, highlights)
+	return err
+}
+
+// getMonthTotals returns total spending and income for a month. Cash-back and
+// reward credits reduce spending instead of counting as income.
+func (s *Service) getMonthTotals(ctx context.Context, userID uuid.UUID, start, end time.Time, filter InsightsFilter) (spend, income int64, err error) {
 	query := `
 		SELECT
-			COALESCE(SUM(CASE WHEN amount_minor < 0 THEN ABS(amount_minor) ELSE 0 END), 0) as spend,
-			COALESCE(SUM(CASE WHEN amount_minor > 0 THEN amount_minor ELSE 0 END), 0) as income
-		FROM transactions
-		WHERE user_id = $1 AND posted_at >= $2 AND posted_at < $3
-	`
-	err = s.repo.DB().QueryRow(ctx, query, userID, start, end).Scan(&spend, &income)
+			GREATEST(COALESCE(SUM(CASE WHEN amount_minor < 0 THEN ABS(amount_minor) WHEN is_reward THEN -amount_minor ELSE 0 END), 0), 0) as spend,
+			COALESCE(SUM(CASE WHEN amount_minor > 0 AND NOT is_reward THEN amount_minor ELSE 0 END), 0) as income
+		FROM transactions t
+		WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2 AND posted_at < $3` + filter.sql("t", 4)
+	err = s.repo.DB().QueryRow(ctx, query, append([]any{userID, start, end}, filter.args()...)...).Scan(&spend, &income)
 	return
 }
 
 // getTopMerchants returns top merchants by spend for a period
-func (s *Service) getTopMerchants(ctx context.Context, userID uuid.UUID, start, end time.Time, limit int) ([]MerchantSpend, error) {
+func (s *Service) getTopMerchants(ctx context.Context, userID uuid.UUID, start, end time.Time, limit int, filter InsightsFilter) ([]MerchantSpend, error) {
 	query := `
 		SELECT COALESCE(merchant_name, description) as merchant,
 			   SUM(ABS(amount_minor)) as total,
 			   COUNT(*) as tx_count
-		FROM transactions
-		WHERE user_id = $1 AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0
+		FROM transactions t
+		WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0` + filter.sql("t", 5) + `
 		GROUP BY COALESCE(merchant_name, description)
 		ORDER BY total DESC
 		LIMIT $4
 	`
-	rows, err := s.repo.DB().Query(ctx, query, userID, start, end, limit)
+	rows, err := s.repo.DB().Query(ctx, query, append([]any{userID, start, end, limit}, filter.args()...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -207,19 +307,19 @@ func (s *Service) getTopMerchants(ctx context.Context, userID uuid.UUID, start,
 }
 
 // detectChanges identifies the top 3 significant changes this month
-func (s *Service) detectChanges(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time) []InsightChange {
+func (s *Service) detectChanges(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time, filter InsightsFilter) []InsightChange {
 	var allChanges []InsightChange
 
 	// 1. Detect category changes
-	categoryChanges := s.detectCategoryChanges(ctx, userID, currentStart, currentEnd, lastStart, lastEnd)
+	categoryChanges := s.detectCategoryChanges(ctx, userID, currentStart, currentEnd, lastStart, lastEnd, filter)
 	allChanges = append(allChanges, categoryChanges...)
 
 	// 2. Detect new merchants
-	newMerchants := s.detectNewMerchants(ctx, userID, currentStart, currentEnd, lastStart, lastEnd)
+	newMerchants := s.detectNewMerchants(ctx, userID, currentStart, currentEnd, lastStart, lastEnd, filter)
 	allChanges = append(allChanges, newMerchants...)
 
 	// 3. Detect income changes
-	incomeChange := s.detectIncomeChange(ctx, userID, currentStart, currentEnd, lastStart, lastEnd)
+	incomeChange := s.detectIncomeChange(ctx, userID, currentStart, currentEnd, lastStart, lastEnd, filter)
 	if incomeChange != nil {
 		allChanges = append(allChanges, *incomeChange)
 	}
@@ -237,19 +337,19 @@ func (s *Service) detectChanges(ctx context.Context, userID uuid.UUID, currentSt
 }
 
 // detectCategoryChanges finds categories with significant spending changes
-func (s *Service) detectCategoryChanges(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time) []InsightChange {
+func (s *Service) detectCategoryChanges(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time, filter InsightsFilter) []InsightChange {
 	query := `
 		WITH current_month AS (
 			SELECT category_id, COALESCE(c.name, 'Uncategorized') as cat_name, SUM(ABS(amount_minor)) as total
 			FROM transactions t
 			LEFT JOIN categories c ON t.category_id = c.id
-			WHERE t.user_id = $1 AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0` + filter.sql("t", 6) + `
 			GROUP BY category_id, c.name
 		),
 		last_month AS (
 			SELECT category_id, SUM(ABS(amount_minor)) as total
-			FROM transactions
-			WHERE user_id = $1 AND posted_at >= $4 AND posted_at < $5 AND amount_minor < 0
+			FROM transactions t
+			WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $4 AND posted_at < $5 AND amount_minor < 0` + filter.sql("t", 6) + `
 			GROUP BY category_id
 		)
 		SELECT cm.category_id, cm.cat_name, cm.total as current_total, COALESCE(lm.total, 0) as last_total
@@ -260,7 +360,7 @@ func (s *Service) detectCategoryChanges(ctx context.Context, userID uuid.UUID, c
 		LIMIT 5
 	`
 
-	rows, err := s.repo.DB().Query(ctx, query, userID, currentStart, currentEnd, lastStart, lastEnd)
+	rows, err := s.repo.DB().Query(ctx, query, append([]any{userID, currentStart, currentEnd, lastStart, lastEnd}, filter.args()...)...)
 	if err != nil {
 		return nil
 	}
@@ -310,18 +410,18 @@ func (s *Service) detectCategoryChanges(ctx context.Context, userID uuid.UUID, c
 }
 
 // detectNewMerchants finds new merchants not seen last month
-func (s *Service) detectNewMerchants(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time) []InsightChange {
+func (s *Service) detectNewMerchants(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time, filter InsightsFilter) []InsightChange {
 	query := `
 		WITH current_merchants AS (
 			SELECT COALESCE(merchant_name, description) as merchant, SUM(ABS(amount_minor)) as total
-			FROM transactions
-			WHERE user_id = $1 AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0
+			FROM transactions t
+			WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2 AND posted_at < $3 AND amount_minor < 0` + filter.sql("t", 6) + `
 			GROUP BY COALESCE(merchant_name, description)
 		),
 		last_merchants AS (
 			SELECT DISTINCT COALESCE(merchant_name, description) as merchant
-			FROM transactions
-			WHERE user_id = $1 AND posted_at >= $4 AND posted_at < $5
+			FROM transactions t
+			WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $4 AND posted_at < $5` + filter.sql("t", 6) + `
 		)
 		SELECT cm.merchant, cm.total
 		FROM current_merchants cm
@@ -331,7 +431,7 @@ func (s *Service) detectNewMerchants(ctx context.Context, userID uuid.UUID, curr
 		LIMIT 3
 	`
 
-	rows, err := s.repo.DB().Query(ctx, query, userID, currentStart, currentEnd, lastStart, lastEnd)
+	rows, err := s.repo.DB().Query(ctx, query, append([]any{userID, currentStart, currentEnd, lastStart, lastEnd}, filter.args()...)...)
 	if err != nil {
 		return nil
 	}
@@ -362,17 +462,17 @@ func (s *Service) detectNewMerchants(ctx context.Context, userID uuid.UUID, curr
 }
 
 // detectIncomeChange detects significant income changes
-func (s *Service) detectIncomeChange(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time) *InsightChange {
+func (s *Service) detectIncomeChange(ctx context.Context, userID uuid.UUID, currentStart, currentEnd, lastStart, lastEnd time.Time, filter InsightsFilter) *InsightChange {
 	query := `
 		SELECT
 			COALESCE(SUM(CASE WHEN posted_at >= $2 AND posted_at < $3 THEN amount_minor ELSE 0 END), 0) as current_income,
 			COALESCE(SUM(CASE WHEN posted_at >= $4 AND posted_at < $5 THEN amount_minor ELSE 0 END), 0) as last_income
-		FROM transactions
-		WHERE user_id = $1 AND amount_minor > 0
-	`
+		FROM transactions t
+		WHERE user_id = $1 AND deleted_at IS NULL AND amount_minor > 0 AND NOT is_reward` + filter.sql("t", 6)
 
 	var currentIncome, lastIncome int64
-	if err := s.repo.DB().QueryRow(ctx, query, userID, currentStart, currentEnd, lastStart, lastEnd).Scan(&currentIncome, &lastIncome); err != nil {
+	args := append([]any{userID, currentStart, currentEnd, lastStart, lastEnd}, filter.args()...)
+	if err := s.repo.DB().QueryRow(ctx, query, args...).Scan(&currentIncome, &lastIncome); err != nil {
 		return nil
 	}
 
@@ -460,7 +560,7 @@ func (s *Service) getUncategorizedStats(ctx context.Context, userID uuid.UUID, m
 	query := `
 		SELECT COUNT(*), COALESCE(SUM(ABS(amount_minor)), 0)
 		FROM transactions
-		WHERE user_id = $1 AND posted_at >= $2 AND category_id IS NULL
+		WHERE user_id = $1 AND deleted_at IS NULL AND posted_at >= $2 AND category_id IS NULL
 	`
 	_ = s.repo.DB().QueryRow(ctx, query, userID, monthStart).Scan(&count, &amount)
 	return
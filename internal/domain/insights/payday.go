@@ -0,0 +1,132 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+)
+
+// =============================================================================
+// Payday Inference (Internal Integration)
+// =============================================================================
+// Payday inference is available on the insights service but requires proto
+// definitions to be exposed as an API endpoint.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetPaydayRequest/Response (InsightsService.GetPayday)
+
+// paydayLookbackMonths is how much salary history payday inference considers
+const paydayLookbackMonths = 6
+
+// Payday is the user's inferred salary schedule
+type Payday struct {
+	// NominalDay is the contractual day of month (1-31) salaries are paid on
+	NominalDay int
+	// Next is the next expected payment, moved to the preceding business day
+	// when the nominal day falls on a weekend or bank holiday
+	Next time.Time
+	// Previous is the last expected payment on or before asOf, which starts
+	// the current pay cycle
+	Previous time.Time
+	// Matched is how many of the observed payments fit the schedule
+	Matched  int
+	Observed int
+}
+
+// WithCalendars sets the resolver used to pick each user's bank holidays
+func (s *Service) WithCalendars(calendars *calendar.Resolver) *Service {
+	s.calendars = calendars
+	return s
+}
+
+// GetPayday infers when the user is paid from the largest income of each of the
+// last months. Returns nil when there isn't enough history.
+func (s *Service) GetPayday(ctx context.Context, userID uuid.UUID, asOf time.Time) (*Payday, error) {
+	salaries, err := s.recentSalaries(ctx, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, len(salaries))
+	for i, p := range salaries {
+		dates[i] = p.PostedAt
+	}
+	return InferPayday(s.calendars.ForUser(ctx, userID), dates, asOf), nil
+}
+
+// salaryPayment is the largest income of a month, taken to be the salary
+type salaryPayment struct {
+	PostedAt    time.Time
+	AmountMinor int64
+}
+
+// recentSalaries returns the largest income of each of the last months, oldest first
+func (s *Service) recentSalaries(ctx context.Context, userID uuid.UUID, asOf time.Time) ([]salaryPayment, error) {
+	since := time.Date(asOf.Year(), asOf.Month()-paydayLookbackMonths, 1, 0, 0, 0, 0, asOf.Location())
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT DISTINCT ON (date_trunc('month', posted_at)) posted_at, amount_minor
+		FROM transactions
+		WHERE user_id = $1 AND posted_at >= $2 AND posted_at <= $3
+		  AND amount_minor > 0 AND NOT is_reward AND deleted_at IS NULL
+		ORDER BY date_trunc('month', posted_at), amount_minor DESC
+	`, userID, since, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query income: %w", err)
+	}
+	defer rows.Close()
+
+	var salaries []salaryPayment
+	for rows.Next() {
+		var p salaryPayment
+		if err := rows.Scan(&p.PostedAt, &p.AmountMinor); err != nil {
+			return nil, fmt.Errorf("failed to scan income: %w", err)
+		}
+		salaries = append(salaries, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate income: %w", err)
+	}
+	return salaries, nil
+}
+
+// InferPayday finds the nominal day of month that explains the most payment
+// dates once adjusted to the preceding business day, so a salary paid on the
+// Friday before a weekend 25th still reads as "the 25th". Returns nil for fewer
+// than two payments or when no day explains at least half of them.
+func InferPayday(cal *calendar.Calendar, dates []time.Time, asOf time.Time) *Payday {
+	if len(dates) < 2 {
+		return nil
+	}
+
+	bestDay, bestMatched := 0, 0
+	for day := 1; day <= 31; day++ {
+		matched := 0
+		for _, d := range dates {
+			expected := cal.Adjust(calendar.WithDayClamped(d, day), calendar.Preceding)
+			if expected.Year() == d.Year() && expected.YearDay() == d.YearDay() {
+				matched++
+			}
+		}
+		if matched > bestMatched {
+			bestDay, bestMatched = day, matched
+		}
+	}
+	if bestMatched*2 < len(dates) {
+		return nil
+	}
+
+	monthStart := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, asOf.Location())
+	paydayIn := func(months int) time.Time {
+		return cal.Adjust(calendar.WithDayClamped(monthStart.AddDate(0, months, 0), bestDay), calendar.Preceding)
+	}
+	previous, next := paydayIn(-1), paydayIn(0)
+	if !next.After(asOf) {
+		previous, next = next, paydayIn(1)
+	}
+
+	return &Payday{NominalDay: bestDay, Next: next, Previous: previous, Matched: bestMatched, Observed: len(dates)}
+}
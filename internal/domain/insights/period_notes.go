@@ -0,0 +1,227 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// Period Notes (Internal Integration)
+// =============================================================================
+// Users annotate a month, or a category in a month, so unusual numbers keep
+// their explanation ("moved apartments in May"). Notes are attached to
+// MonthlyInsights and to aggregate rows grouped by month.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AddPeriodNoteRequest/Response (InsightsService.AddPeriodNote)
+// - UpdatePeriodNoteRequest/Response, DeletePeriodNoteRequest/Response
+// - ListPeriodNotesRequest/Response
+// - PeriodNote, and a repeated PeriodNote notes field on MonthlyInsights
+
+// maxPeriodNoteLength caps the length of a note's body, in characters
+const maxPeriodNoteLength = 500
+
+var (
+	// ErrInvalidPeriodNote is returned for an empty or too long note
+	ErrInvalidPeriodNote = errors.New("a note needs between 1 and 500 characters")
+	// ErrPeriodNoteNotFound is returned when the user has no such note
+	ErrPeriodNoteNotFound = errors.New("period note not found")
+	// ErrPeriodNoteCategory is returned for a category the user can't see
+	ErrPeriodNoteCategory = errors.New("category not found")
+)
+
+// PeriodNote is a user's note on a month, or on one category in a month
+type PeriodNote struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	MonthStart   time.Time
+	CategoryID   *uuid.UUID // Nil for a note on the whole month
+	CategoryName *string
+	Body         string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// PeriodNoteInput describes a note to add
+type PeriodNoteInput struct {
+	MonthStart time.Time // Any day of the month
+	CategoryID *uuid.UUID
+	Body       string
+}
+
+// AddPeriodNote stores a note on a month or a category in a month
+func (s *Service) AddPeriodNote(ctx context.Context, userID uuid.UUID, input PeriodNoteInput) (*PeriodNote, error) {
+	body, err := validPeriodNoteBody(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	year, month, _ := input.MonthStart.Date()
+	note := &PeriodNote{
+		ID:         uuid.New(),
+		UserID:     userID,
+		MonthStart: time.Date(year, month, 1, 0, 0, 0, 0, time.UTC),
+		CategoryID: input.CategoryID,
+		Body:       body,
+	}
+
+	if note.CategoryID != nil {
+		// Own categories and those shared with the user's household
+		err := s.repo.DB().QueryRow(ctx, `
+			SELECT name::TEXT FROM categories
+			WHERE id = $1
+			  AND (user_id = $2 OR household_id IN (SELECT household_id FROM household_members WHERE user_id = $2))
+		`, *note.CategoryID, userID).Scan(&note.CategoryName)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPeriodNoteCategory
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get note category: %w", err)
+		}
+	}
+
+	err = s.repo.DB().QueryRow(ctx, `
+		INSERT INTO period_notes (id, user_id, month_start, category_id, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, note.ID, userID, note.MonthStart, note.CategoryID, note.Body).Scan(&note.CreatedAt, &note.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add period note: %w", err)
+	}
+	return note, nil
+}
+
+// UpdatePeriodNote replaces the body of one of the user's notes
+func (s *Service) UpdatePeriodNote(ctx context.Context, userID, noteID uuid.UUID, body string) error {
+	body, err := validPeriodNoteBody(body)
+	if err != nil {
+		return err
+	}
+	tag, err := s.repo.DB().Exec(ctx, `
+		UPDATE period_notes SET body = $3
+		WHERE id = $1 AND user_id = $2
+	`, noteID, userID, body)
+	if err != nil {
+		return fmt.Errorf("failed to update period note: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPeriodNoteNotFound
+	}
+	return nil
+}
+
+// DeletePeriodNote removes one of the user's notes
+func (s *Service) DeletePeriodNote(ctx context.Context, userID, noteID uuid.UUID) error {
+	tag, err := s.repo.DB().Exec(ctx, `DELETE FROM period_notes WHERE id = $1 AND user_id = $2`, noteID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete period note: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPeriodNoteNotFound
+	}
+	return nil
+}
+
+// ListPeriodNotes lists the user's notes on the months from through to,
+// inclusive, oldest month first; month-wide notes come before category notes
+func (s *Service) ListPeriodNotes(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]PeriodNote, error) {
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT n.id, n.user_id, n.month_start, n.category_id, c.name::TEXT, n.body, n.created_at, n.updated_at
+		FROM period_notes n
+		LEFT JOIN categories c ON c.id = n.category_id
+		WHERE n.user_id = $1
+		  AND n.month_start >= date_trunc('month', $2::DATE)
+		  AND n.month_start <= date_trunc('month', $3::DATE)
+		ORDER BY n.month_start, n.category_id NULLS FIRST, n.created_at
+	`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list period notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []PeriodNote
+	for rows.Next() {
+		var n PeriodNote
+		if err := rows.Scan(&n.ID, &n.UserID, &n.MonthStart, &n.CategoryID, &n.CategoryName, &n.Body, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan period note: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// AttachPeriodNotes sets the notes of aggregate rows grouped by month. When
+// rows are also grouped by category, a row gets the month-wide notes and those
+// on its category; otherwise it gets every note on its month.
+func AttachPeriodNotes(rows []AggregateRow, dimensions []AggregateDimension, notes []PeriodNote) {
+	byCategory := false
+	for _, d := range dimensions {
+		if d == DimensionCategory {
+			byCategory = true
+		}
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Month == nil {
+			continue
+		}
+		for _, n := range notes {
+			if !sameMonth(n.MonthStart, *row.Month) {
+				continue
+			}
+			if byCategory && n.CategoryID != nil && (row.CategoryID == nil || *row.CategoryID != *n.CategoryID) {
+				continue
+			}
+			row.Notes = append(row.Notes, n)
+		}
+	}
+}
+
+// attachAggregateNotes loads the notes on the months of rows grouped by month.
+// Notes only explain the numbers, so failing to load them doesn't fail the query.
+func (s *Service) attachAggregateNotes(ctx context.Context, userID uuid.UUID, rows []AggregateRow, dimensions []AggregateDimension) {
+	var from, to time.Time
+	for _, row := range rows {
+		if row.Month == nil {
+			continue
+		}
+		if from.IsZero() || row.Month.Before(from) {
+			from = *row.Month
+		}
+		if row.Month.After(to) {
+			to = *row.Month
+		}
+	}
+	if from.IsZero() {
+		return
+	}
+
+	notes, err := s.ListPeriodNotes(ctx, userID, from, to)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Warn("failed to load period notes for aggregates", "error", err)
+		}
+		return
+	}
+	AttachPeriodNotes(rows, dimensions, notes)
+}
+
+func validPeriodNoteBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > maxPeriodNoteLength {
+		return "", ErrInvalidPeriodNote
+	}
+	return body, nil
+}
+
+func sameMonth(a, b time.Time) bool {
+	ay, am, _ := a.Date()
+	by, bm, _ := b.Date()
+	return ay == by && am == bm
+}
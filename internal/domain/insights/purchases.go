@@ -0,0 +1,103 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Return Window & Warranty Reminders (Internal Integration)
+// =============================================================================
+// Tracked purchases get an alert a few days before their return window closes
+// and a month before their warranty ends. Purchases, merchant return policies
+// and receipt attachments are managed by the purchases domain, which requires
+// proto definitions to be exposed as an API endpoint.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - TrackPurchaseRequest/Response, ListTrackedPurchasesRequest/Response
+// - UpdateTrackedPurchaseRequest/Response (mark returned, archive, attach receipt)
+// - SetMerchantReturnPolicyRequest/Response, ListMerchantReturnPoliciesRequest/Response
+// - TrackedPurchase, MerchantReturnPolicy
+
+// PurchaseReminder is an upcoming return or warranty deadline of a tracked purchase
+type PurchaseReminder struct {
+	PurchaseID    uuid.UUID
+	MerchantName  string
+	AmountMinor   int64
+	PurchasedAt   time.Time
+	Deadline      time.Time
+	DaysLeft      int
+	Warranty      bool // false for a return window
+	HasAttachment bool
+}
+
+// TriggerPurchaseReminder creates an alert for a closing return window or an
+// ending warranty. Callers send each reminder once per purchase, so there is
+// no per-day limit.
+func (s *Service) TriggerPurchaseReminder(ctx context.Context, userID uuid.UUID, reminder PurchaseReminder) error {
+	alertType := AlertTypeReturnWindow
+	severity := AlertSeverityWarning
+	title := fmt.Sprintf("Return window for %s closes %s", reminder.MerchantName, daysLeftPhrase(reminder.DaysLeft))
+	message := fmt.Sprintf("Your %s purchase from %s can be returned until %s. Return it before then if you aren't keeping it.",
+		formatMoney(reminder.AmountMinor), reminder.PurchasedAt.Format("Jan 2"), reminder.Deadline.Format("Jan 2"))
+	if reminder.Warranty {
+		alertType = AlertTypeWarrantyExpiry
+		severity = AlertSeverityInfo
+		title = fmt.Sprintf("Warranty for your %s purchase ends %s", reminder.MerchantName, daysLeftPhrase(reminder.DaysLeft))
+		message = fmt.Sprintf("The warranty on your %s purchase from %s runs until %s. Check it still works while it's covered.",
+			formatMoney(reminder.AmountMinor), reminder.PurchasedAt.Format("Jan 2, 2006"), reminder.Deadline.Format("Jan 2, 2006"))
+		if reminder.HasAttachment {
+			message += " Your receipt is saved with the purchase."
+		}
+	}
+	setting := s.alertSetting(ctx, userID, alertType)
+	if !setting.Enabled {
+		return nil
+	}
+
+	referenceType := "tracked_purchase"
+	alert := &Alert{
+		UserID:        userID,
+		AlertType:     alertType,
+		Severity:      severity,
+		Title:         title,
+		Message:       message,
+		ReferenceType: &referenceType,
+		ReferenceID:   &reminder.PurchaseID,
+		Metadata: map[string]any{
+			"merchant":       reminder.MerchantName,
+			"amount_minor":   reminder.AmountMinor,
+			"purchased_at":   reminder.PurchasedAt.Format("2006-01-02"),
+			"deadline":       reminder.Deadline.Format("2006-01-02"),
+			"days_left":      reminder.DaysLeft,
+			"has_attachment": reminder.HasAttachment,
+		},
+		AlertDate: time.Now(),
+	}
+
+	if err := s.createAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, map[string]any{
+		"alert_type":  string(alert.AlertType),
+		"severity":    string(alert.Severity),
+		"purchase_id": reminder.PurchaseID.String(),
+	})
+	return nil
+}
+
+// daysLeftPhrase describes a deadline relative to today
+func daysLeftPhrase(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
@@ -2,11 +2,16 @@ package insights
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
 )
 
 // SpendingPulseData contains the raw data for pulse calculation
@@ -37,20 +42,61 @@ type TopCategory struct {
 	TxCount      int
 }
 
+// MonthlyCategorySpend is a category's spending in one month and currency
+type MonthlyCategorySpend struct {
+	Month        time.Time
+	CategoryID   *uuid.UUID
+	CategoryName string
+	CurrencyCode string
+	TotalMinor   int64
+}
+
 // InsightsRepository defines the interface for insights data access
 type InsightsRepository interface {
-	GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time) (*SpendingPulseData, error)
-	GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time) (int, error)
-	GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]TopCategory, error)
-	GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]SurpriseExpense, error)
+	GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (*SpendingPulseData, error)
+	GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (int, error)
+	GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter InsightsFilter) ([]TopCategory, error)
+	GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter InsightsFilter) ([]SurpriseExpense, error)
+	GetMonthlyCategorySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]MonthlyCategorySpend, error)
+	// GetMonthlyRollups reads the monthly rollups for months in [from, to)
+	GetMonthlyRollups(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]MonthlyRollup, error)
+
+	// Combined spending of a household's members, leaving out what each member
+	// excluded from insights
+	GetHouseholdSpendingPulseData(ctx context.Context, userIDs []uuid.UUID, asOf time.Time) (*SpendingPulseData, error)
+	GetHouseholdTopCategories(ctx context.Context, userIDs []uuid.UUID, asOf time.Time, limit int) ([]TopCategory, error)
+
 	HasAlertToday(ctx context.Context, userID uuid.UUID, alertType AlertType, date time.Time) (bool, error)
 	CreateAlert(ctx context.Context, alert *Alert) error
 	GetUnreadAlerts(ctx context.Context, userID uuid.UUID, limit int) ([]Alert, error)
 	MarkAlertRead(ctx context.Context, alertID uuid.UUID) error
 	MarkAlertDismissed(ctx context.Context, alertID uuid.UUID) error
+	// MarkAllAlertsRead marks the user's unread alerts read and returns their IDs
+	MarkAllAlertsRead(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	// SnoozeAlert hides the user's alert until the given time; sql.ErrNoRows if
+	// the user has no such alert
+	SnoozeAlert(ctx context.Context, userID, alertID uuid.UUID, until time.Time) error
+	// ExpireAlerts dismisses alerts whose expiry has passed
+	ExpireAlerts(ctx context.Context, now time.Time) (int64, error)
+	// DeleteAlertsBefore deletes read or dismissed alerts dated before the cutoff
+	DeleteAlertsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+
+	// Alert settings; only types the user changed are stored
+	GetAlertSettings(ctx context.Context, userID uuid.UUID) ([]AlertSetting, error)
+	UpsertAlertSetting(ctx context.Context, userID uuid.UUID, setting *AlertSetting) error
+
+	// Dashboard layout; nil when the user never saved one
+	GetDashboardLayout(ctx context.Context, userID uuid.UUID) ([]DashboardLayoutBlock, error)
+	UpsertDashboardLayout(ctx context.Context, userID uuid.UUID, blocks []DashboardLayoutBlock) error
+
+	// Excluded-from-insights flags; sql.ErrNoRows if the row isn't the user's
+	SetAccountExcludedFromInsights(ctx context.Context, userID, accountID uuid.UUID, excluded bool) error
+	SetCategoryExcludedFromInsights(ctx context.Context, userID, categoryID uuid.UUID, excluded bool) error
 
 	// Import quality insights
-	GetImportInsights(ctx context.Context, importJobID uuid.UUID) (*ImportJobInsights, error)
+	// GetImportJobInsights returns the insights of the user's import job with
+	// the job's row counters; sql.ErrNoRows if there are none
+	GetImportJobInsights(ctx context.Context, userID, importJobID uuid.UUID) (*ImportJobInsights, error)
 	UpsertImportInsights(ctx context.Context, insights *ImportJobInsights) error
 
 	// Data source health
@@ -75,6 +121,11 @@ type ImportJobInsights struct {
 	CurrencyCode       string
 	DuplicatesSkipped  int
 	Issues             []ImportIssue
+
+	// From the import job; not stored with the insights
+	RowsTotal    int
+	RowsImported int
+	RowsFailed   int
 }
 
 // ImportIssue represents a data quality issue found during import
@@ -102,12 +153,34 @@ var _ InsightsRepository = (*Repository)(nil)
 
 // Repository handles database queries for insights
 type Repository struct {
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	read         *pgxpool.Pool // Runs uncached reports; db unless a replica is set
+	queryTimeout time.Duration
 }
 
 // NewRepository creates a new insights repository
 func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+	return &Repository{db: db, read: db}
+}
+
+// WithReadPool runs the uncached reports (year-over-year rollups) against
+// pool, typically a read replica. Queries that fill the dashboard cache stay
+// on the primary: they run right after an import invalidates it, and a
+// lagging replica would cache the pre-import result for the cache's TTL.
+// A nil pool is ignored.
+func (r *Repository) WithReadPool(pool *pgxpool.Pool) *Repository {
+	if pool != nil {
+		r.read = pool
+	}
+	return r
+}
+
+// WithQueryTimeout bounds each analytics query (pulse, top categories,
+// surprises, data source health) so a pathological one is cancelled rather
+// than holding a pool connection. Zero leaves them bounded by the caller only.
+func (r *Repository) WithQueryTimeout(timeout time.Duration) *Repository {
+	r.queryTimeout = timeout
+	return r
 }
 
 // DB returns the database pool for direct queries
@@ -116,7 +189,20 @@ func (r *Repository) DB() *pgxpool.Pool {
 }
 
 // GetSpendingPulseData fetches spending data for current vs last month comparison
-func (r *Repository) GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time) (*SpendingPulseData, error) {
+func (r *Repository) GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (*SpendingPulseData, error) {
+	return r.spendingPulseData(ctx, []uuid.UUID{userID}, asOf, filter)
+}
+
+// GetHouseholdSpendingPulseData fetches the combined spending of several users
+// (a household's members) for current vs last month comparison
+func (r *Repository) GetHouseholdSpendingPulseData(ctx context.Context, userIDs []uuid.UUID, asOf time.Time) (*SpendingPulseData, error) {
+	return r.spendingPulseData(ctx, userIDs, asOf, InsightsFilter{})
+}
+
+func (r *Repository) spendingPulseData(ctx context.Context, userIDs []uuid.UUID, asOf time.Time, filter InsightsFilter) (*SpendingPulseData, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	// Calculate date ranges
 	year, month, day := asOf.Date()
 	currentMonthStart := time.Date(year, month, 1, 0, 0, 0, 0, asOf.Location())
@@ -137,12 +223,13 @@ func (r *Repository) GetSpendingPulseData(ctx context.Context, userID uuid.UUID,
 	var currentSpend int64
 	err := r.db.QueryRow(ctx, `
 		SELECT COALESCE(SUM(ABS(amount_minor)), 0)
-		FROM transactions
-		WHERE user_id = $1
+		FROM transactions t
+		WHERE user_id = ANY($1)
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
 		  AND posted_at < $3
-		  AND amount_minor < 0
-	`, userID, currentMonthStart, currentMonthEnd).Scan(&currentSpend)
+		  AND amount_minor < 0`+filter.sql("t", 4),
+		append([]any{userIDs, currentMonthStart, currentMonthEnd}, filter.args()...)...).Scan(&currentSpend)
 	if err != nil {
 		return nil, err
 	}
@@ -151,12 +238,13 @@ func (r *Repository) GetSpendingPulseData(ctx context.Context, userID uuid.UUID,
 	var lastSpend int64
 	err = r.db.QueryRow(ctx, `
 		SELECT COALESCE(SUM(ABS(amount_minor)), 0)
-		FROM transactions
-		WHERE user_id = $1
+		FROM transactions t
+		WHERE user_id = ANY($1)
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
 		  AND posted_at <= $3
-		  AND amount_minor < 0
-	`, userID, lastMonthStart, lastMonthSameDay).Scan(&lastSpend)
+		  AND amount_minor < 0`+filter.sql("t", 4),
+		append([]any{userIDs, lastMonthStart, lastMonthSameDay}, filter.args()...)...).Scan(&lastSpend)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +260,10 @@ func (r *Repository) GetSpendingPulseData(ctx context.Context, userID uuid.UUID,
 }
 
 // GetSurpriseExpenses finds high-value transactions in current month not in last month
-func (r *Repository) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]SurpriseExpense, error) {
+func (r *Repository) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter InsightsFilter) ([]SurpriseExpense, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	year, month, _ := asOf.Date()
 	currentMonthStart := time.Date(year, month, 1, 0, 0, 0, 0, asOf.Location())
 	lastMonthStart := currentMonthStart.AddDate(0, -1, 0)
@@ -185,16 +276,18 @@ func (r *Repository) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID,
 			FROM transactions t
 			LEFT JOIN categories c ON t.category_id = c.id
 			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
 			  AND t.posted_at >= $2
 			  AND t.posted_at < $3
-			  AND t.amount_minor < 0
+			  AND t.amount_minor < 0` + filter.sql("t", 7) + `
 		),
 		last_month_merchants AS (
 			SELECT DISTINCT COALESCE(merchant_name, description) as merchant
-			FROM transactions
+			FROM transactions t
 			WHERE user_id = $1
+			  AND deleted_at IS NULL
 			  AND posted_at >= $4
-			  AND posted_at < $5
+			  AND posted_at < $5` + filter.sql("t", 7) + `
 		)
 		SELECT cm.id, cm.description, cm.merchant_name, cm.amount_minor, cm.posted_at, cm.category_name
 		FROM current_month_txs cm
@@ -203,14 +296,15 @@ func (r *Repository) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID,
 		LIMIT $6
 	`
 
-	rows, err := r.db.Query(ctx, query,
+	args := []any{
 		userID,
 		currentMonthStart,
 		asOf.AddDate(0, 0, 1),
 		lastMonthStart,
 		lastMonthEnd,
 		limit,
-	)
+	}
+	rows, err := r.db.Query(ctx, query, append(args, filter.args()...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +329,10 @@ func (r *Repository) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID,
 }
 
 // GetTopCategories returns spending by category for current month
-func (r *Repository) GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]TopCategory, error) {
+func (r *Repository) GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter InsightsFilter) ([]TopCategory, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	year, month, _ := asOf.Date()
 	currentMonthStart := time.Date(year, month, 1, 0, 0, 0, 0, asOf.Location())
 
@@ -246,15 +343,17 @@ func (r *Repository) GetTopCategories(ctx context.Context, userID uuid.UUID, asO
 		FROM transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
 		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
 		  AND t.posted_at >= $2
 		  AND t.posted_at < $3
-		  AND t.amount_minor < 0
+		  AND t.amount_minor < 0` + filter.sql("t", 5) + `
 		GROUP BY t.category_id, c.name
 		ORDER BY total_amount DESC
 		LIMIT $4
 	`
 
-	rows, err := r.db.Query(ctx, query, userID, currentMonthStart, asOf.AddDate(0, 0, 1), limit)
+	rows, err := r.db.Query(ctx, query,
+		append([]any{userID, currentMonthStart, asOf.AddDate(0, 0, 1), limit}, filter.args()...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -272,19 +371,105 @@ func (r *Repository) GetTopCategories(ctx context.Context, userID uuid.UUID, asO
 	return categories, rows.Err()
 }
 
+// GetMonthlyCategorySpend returns spending per month, category and currency
+// for transactions posted in [from, to)
+func (r *Repository) GetMonthlyCategorySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]MonthlyCategorySpend, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT date_trunc('month', t.posted_at)::DATE AS month,
+		       t.category_id, COALESCE(c.name, 'Uncategorized') AS category_name,
+		       t.currency_code,
+		       SUM(-t.amount_minor) AS total_minor
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id
+		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
+		  AND t.posted_at >= $2
+		  AND t.posted_at < $3
+		  AND t.amount_minor < 0
+		GROUP BY 1, t.category_id, c.name, t.currency_code
+		ORDER BY 1, total_minor DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spend []MonthlyCategorySpend
+	for rows.Next() {
+		var m MonthlyCategorySpend
+		if err := rows.Scan(&m.Month, &m.CategoryID, &m.CategoryName, &m.CurrencyCode, &m.TotalMinor); err != nil {
+			return nil, err
+		}
+		spend = append(spend, m)
+	}
+
+	return spend, rows.Err()
+}
+
+// GetHouseholdTopCategories returns the combined top spending categories of
+// several users for the current month. Members' categories are grouped by name,
+// so CategoryID is not set.
+func (r *Repository) GetHouseholdTopCategories(ctx context.Context, userIDs []uuid.UUID, asOf time.Time, limit int) ([]TopCategory, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	year, month, _ := asOf.Date()
+	currentMonthStart := time.Date(year, month, 1, 0, 0, 0, 0, asOf.Location())
+
+	rows, err := r.db.Query(ctx, `
+		SELECT COALESCE(c.name, 'Uncategorized') as category_name,
+		       SUM(ABS(t.amount_minor)) as total_amount,
+		       COUNT(*) as tx_count
+		FROM transactions t
+		LEFT JOIN categories c ON t.category_id = c.id
+		WHERE t.user_id = ANY($1)
+		  AND t.deleted_at IS NULL
+		  AND t.posted_at >= $2
+		  AND t.posted_at < $3
+		  AND t.amount_minor < 0`+InsightsFilter{}.sql("t", 5)+`
+		GROUP BY 1
+		ORDER BY total_amount DESC
+		LIMIT $4
+	`, append([]any{userIDs, currentMonthStart, asOf.AddDate(0, 0, 1), limit}, InsightsFilter{}.args()...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []TopCategory
+	for rows.Next() {
+		var c TopCategory
+		if err := rows.Scan(&c.CategoryName, &c.AmountCents, &c.TxCount); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, rows.Err()
+}
+
 // GetTransactionCount returns the number of transactions for current month
-func (r *Repository) GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time) (int, error) {
+func (r *Repository) GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (int, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	year, month, _ := asOf.Date()
 	currentMonthStart := time.Date(year, month, 1, 0, 0, 0, 0, asOf.Location())
 
 	var count int
 	err := r.db.QueryRow(ctx, `
 		SELECT COUNT(*)
-		FROM transactions
+		FROM transactions t
 		WHERE user_id = $1
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
-		  AND posted_at < $3
-	`, userID, currentMonthStart, asOf.AddDate(0, 0, 1)).Scan(&count)
+		  AND posted_at < $3`+filter.sql("t", 4),
+		append([]any{userID, currentMonthStart, asOf.AddDate(0, 0, 1)}, filter.args()...)...).Scan(&count)
 
 	return count, err
 }
@@ -297,6 +482,11 @@ const (
 	AlertTypeSurpriseExpense AlertType = "surprise_expense"
 	AlertTypeGoalProgress    AlertType = "goal_progress"
 	AlertTypeSubscriptionDue AlertType = "subscription_due"
+	AlertTypeBudgetOverspend AlertType = "budget_overspend"
+	AlertTypeStreakBroken    AlertType = "streak_broken"
+	AlertTypeReturnWindow    AlertType = "return_window"
+	AlertTypeWarrantyExpiry  AlertType = "warranty_expiry"
+	AlertTypeAdvisorNote     AlertType = "advisor_note"
 )
 
 // AlertSeverity defines the severity level
@@ -325,13 +515,15 @@ type Alert struct {
 	CreatedAt     time.Time
 	ReadAt        *time.Time
 	DismissedAt   *time.Time
+	SnoozedUntil  *time.Time // Hidden from the unread list until then
+	ExpiresAt     *time.Time // Dismissed by the cleanup job from then on
 }
 
 // CreateAlert creates a new alert (with deduplication - one per type per day)
 func (r *Repository) CreateAlert(ctx context.Context, alert *Alert) error {
 	query := `
-		INSERT INTO alerts (user_id, alert_type, severity, title, message, metadata, reference_type, reference_id, alert_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO alerts (user_id, alert_type, severity, title, message, metadata, reference_type, reference_id, alert_date, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (user_id, alert_type, alert_date) DO NOTHING
 		RETURNING id, created_at
 	`
@@ -346,6 +538,7 @@ func (r *Repository) CreateAlert(ctx context.Context, alert *Alert) error {
 		alert.ReferenceType,
 		alert.ReferenceID,
 		alert.AlertDate,
+		alert.ExpiresAt,
 	).Scan(&alert.ID, &alert.CreatedAt)
 
 	// Ignore duplicate (ON CONFLICT DO NOTHING)
@@ -372,9 +565,11 @@ func (r *Repository) HasAlertToday(ctx context.Context, userID uuid.UUID, alertT
 func (r *Repository) GetUnreadAlerts(ctx context.Context, userID uuid.UUID, limit int) ([]Alert, error) {
 	query := `
 		SELECT id, user_id, alert_type, severity, title, message, metadata,
-		       reference_type, reference_id, is_read, is_dismissed, alert_date, created_at
+		       reference_type, reference_id, is_read, is_dismissed, alert_date, created_at,
+		       snoozed_until, expires_at
 		FROM alerts
 		WHERE user_id = $1 AND is_read = false AND is_dismissed = false
+		  AND (snoozed_until IS NULL OR snoozed_until <= NOW())
 		ORDER BY created_at DESC
 		LIMIT $2
 	`
@@ -391,6 +586,7 @@ func (r *Repository) GetUnreadAlerts(ctx context.Context, userID uuid.UUID, limi
 		if err := rows.Scan(
 			&a.ID, &a.UserID, &a.AlertType, &a.Severity, &a.Title, &a.Message, &a.Metadata,
 			&a.ReferenceType, &a.ReferenceID, &a.IsRead, &a.IsDismissed, &a.AlertDate, &a.CreatedAt,
+			&a.SnoozedUntil, &a.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -416,30 +612,138 @@ func (r *Repository) MarkAlertDismissed(ctx context.Context, alertID uuid.UUID)
 	return err
 }
 
-// GetImportInsights retrieves quality insights for an import job
-func (r *Repository) GetImportInsights(ctx context.Context, importJobID uuid.UUID) (*ImportJobInsights, error) {
+// MarkAllAlertsRead marks the user's unread alerts read, snoozed ones included
+func (r *Repository) MarkAllAlertsRead(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE alerts SET is_read = true, read_at = NOW()
+		WHERE user_id = $1 AND is_read = false AND is_dismissed = false
+		RETURNING id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// SnoozeAlert hides an alert from the unread list until the given time
+func (r *Repository) SnoozeAlert(ctx context.Context, userID, alertID uuid.UUID, until time.Time) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE alerts SET snoozed_until = $3
+		WHERE id = $1 AND user_id = $2 AND is_dismissed = false
+	`, alertID, userID, until)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ExpireAlerts dismisses alerts whose expiry has passed
+func (r *Repository) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE alerts SET is_dismissed = true, dismissed_at = $1
+		WHERE expires_at <= $1 AND is_dismissed = false
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteAlertsBefore deletes read or dismissed alerts dated before the cutoff
+func (r *Repository) DeleteAlertsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM alerts
+		WHERE alert_date < $1 AND (is_read OR is_dismissed)
+	`, cutoff.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetAlertSettings returns the alert settings the user saved
+func (r *Repository) GetAlertSettings(ctx context.Context, userID uuid.UUID) ([]AlertSetting, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT alert_type, enabled, threshold, channels
+		FROM alert_settings
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settings []AlertSetting
+	for rows.Next() {
+		var setting AlertSetting
+		var channels []string
+		if err := rows.Scan(&setting.AlertType, &setting.Enabled, &setting.Threshold, &channels); err != nil {
+			return nil, err
+		}
+		if channels != nil {
+			setting.Channels = make([]AlertChannel, 0, len(channels))
+			for _, ch := range channels {
+				setting.Channels = append(setting.Channels, AlertChannel(ch))
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings, rows.Err()
+}
+
+// UpsertAlertSetting saves the user's setting for one alert type; nil channels
+// are stored as NULL so the default routing applies
+func (r *Repository) UpsertAlertSetting(ctx context.Context, userID uuid.UUID, setting *AlertSetting) error {
+	var channels []string
+	if setting.Channels != nil {
+		channels = make([]string, 0, len(setting.Channels))
+		for _, ch := range setting.Channels {
+			channels = append(channels, string(ch))
+		}
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO alert_settings (user_id, alert_type, enabled, threshold, channels)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, alert_type) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			threshold = EXCLUDED.threshold,
+			channels = EXCLUDED.channels
+	`, userID, setting.AlertType, setting.Enabled, setting.Threshold, channels)
+	return err
+}
+
+// GetImportJobInsights retrieves quality insights for a user's import job,
+// joined with the job's row counters
+func (r *Repository) GetImportJobInsights(ctx context.Context, userID, importJobID uuid.UUID) (*ImportJobInsights, error) {
 	query := `
 		SELECT 
-			import_job_id,
-			COALESCE(institution_name, ''),
-			COALESCE(categorization_rate, 0),
-			COALESCE(date_quality_score, 1),
-			COALESCE(amount_quality_score, 1),
-			earliest_date,
-			latest_date,
-			COALESCE(total_income_minor, 0),
-			COALESCE(total_expenses_minor, 0),
-			COALESCE(currency_code, 'EUR'),
-			COALESCE(duplicates_skipped, 0),
-			COALESCE(issues_json, '[]'::jsonb)
-		FROM import_job_insights
-		WHERE import_job_id = $1
+			i.import_job_id,
+			COALESCE(i.institution_name, ''),
+			COALESCE(i.categorization_rate, 0),
+			COALESCE(i.date_quality_score, 1),
+			COALESCE(i.amount_quality_score, 1),
+			i.earliest_date,
+			i.latest_date,
+			COALESCE(i.total_income_minor, 0),
+			COALESCE(i.total_expenses_minor, 0),
+			COALESCE(i.currency_code, 'EUR'),
+			COALESCE(i.duplicates_skipped, 0),
+			COALESCE(i.issues_json, '[]'::jsonb),
+			j.rows_total,
+			j.rows_imported,
+			j.rows_failed
+		FROM import_job_insights i
+		JOIN import_jobs j ON j.id = i.import_job_id
+		WHERE i.import_job_id = $1 AND j.user_id = $2
 	`
 
 	var insights ImportJobInsights
 	var issuesJSON []byte
 
-	err := r.db.QueryRow(ctx, query, importJobID).Scan(
+	err := r.db.QueryRow(ctx, query, importJobID, userID).Scan(
 		&insights.ImportJobID,
 		&insights.InstitutionName,
 		&insights.CategorizationRate,
@@ -452,7 +756,13 @@ func (r *Repository) GetImportInsights(ctx context.Context, importJobID uuid.UUI
 		&insights.CurrencyCode,
 		&insights.DuplicatesSkipped,
 		&issuesJSON,
+		&insights.RowsTotal,
+		&insights.RowsImported,
+		&insights.RowsFailed,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -515,6 +825,9 @@ func (r *Repository) UpsertImportInsights(ctx context.Context, insights *ImportJ
 
 // GetDataSourceHealth returns health metrics for all data sources of a user
 func (r *Repository) GetDataSourceHealth(ctx context.Context, userID uuid.UUID) ([]DataSourceHealth, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	query := `
 		SELECT 
 			institution_name,
@@ -557,8 +870,11 @@ func (r *Repository) GetDataSourceHealth(ctx context.Context, userID uuid.UUID)
 	return sources, rows.Err()
 }
 
-// RefreshDataSourceHealth refreshes the materialized view
+// RefreshDataSourceHealth refreshes the materialized view, however long that
+// takes past the pool's statement timeout
 func (r *Repository) RefreshDataSourceHealth(ctx context.Context) error {
-	_, err := r.db.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY data_source_health`)
-	return err
+	return db.WithoutStatementTimeout(ctx, r.db, func(conn *pgxpool.Conn) error {
+		_, err := conn.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY data_source_health`)
+		return err
+	})
 }
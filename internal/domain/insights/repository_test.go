@@ -0,0 +1,91 @@
+package insights_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+)
+
+// unreachablePool is a pool that never connects, so every query fails with an
+// error naming its database: that tells which pool a query went to
+func unreachablePool(t *testing.T, database string) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://echo@/"+database+"?host=/nonexistent&connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestRepository_ReadRouting(t *testing.T) {
+	repo := insights.NewRepository(unreachablePool(t, "primary")).
+		WithReadPool(unreachablePool(t, "replica"))
+	ctx := context.Background()
+	userID := uuid.New()
+	asOf := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	filter := insights.InsightsFilter{}
+
+	tests := []struct {
+		name string
+		pool string
+		call func() error
+	}{
+		// Cached for the dashboard, and refilled right after an import
+		// invalidates the cache, so a lagging replica would pin stale results
+		{"spending pulse", "primary", func() error {
+			_, err := repo.GetSpendingPulseData(ctx, userID, asOf, filter)
+			return err
+		}},
+		{"household spending pulse", "primary", func() error {
+			_, err := repo.GetHouseholdSpendingPulseData(ctx, []uuid.UUID{userID}, asOf)
+			return err
+		}},
+		{"transaction count", "primary", func() error {
+			_, err := repo.GetTransactionCount(ctx, userID, asOf, filter)
+			return err
+		}},
+		{"top categories", "primary", func() error {
+			_, err := repo.GetTopCategories(ctx, userID, asOf, 5, filter)
+			return err
+		}},
+		{"household top categories", "primary", func() error {
+			_, err := repo.GetHouseholdTopCategories(ctx, []uuid.UUID{userID}, asOf, 5)
+			return err
+		}},
+		{"surprise expenses", "primary", func() error {
+			_, err := repo.GetSurpriseExpenses(ctx, userID, asOf, 3, filter)
+			return err
+		}},
+		{"monthly category spend", "primary", func() error {
+			_, err := repo.GetMonthlyCategorySpend(ctx, userID, asOf.AddDate(0, -6, 0), asOf)
+			return err
+		}},
+		{"data source health", "primary", func() error {
+			_, err := repo.GetDataSourceHealth(ctx, userID)
+			return err
+		}},
+		{"unread alerts", "primary", func() error {
+			_, err := repo.GetUnreadAlerts(ctx, userID, 10)
+			return err
+		}},
+		// Uncached reports can take replica lag
+		{"monthly rollups", "replica", func() error {
+			_, err := repo.GetMonthlyRollups(ctx, userID, asOf.AddDate(-1, 0, 0), asOf)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "database="+tt.pool)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Safe to Spend (Internal Integration)
+// =============================================================================
+// A single headline number for the dashboard: what's left of the cycle's
+// income once committed bills, subscriptions, installments and goal
+// contributions are set aside, spread over the days until the next payday
+// (or the end of the month when payday can't be inferred).
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetSafeToSpendRequest/Response (InsightsService.GetSafeToSpend)
+// - SafeToSpend, with the ScheduledCharge list the forecast uses
+
+// GoalCommitment is an active goal the cycle should contribute to
+type GoalCommitment struct {
+	TargetMinor      int64
+	CurrentMinor     int64 // Includes ContributedMinor
+	EndAt            time.Time
+	ContributedMinor int64 // Contributed since the cycle started
+}
+
+// SafeToSpendInputs are the figures safe to spend is computed from
+type SafeToSpendInputs struct {
+	AsOf        time.Time
+	CycleStart  time.Time
+	CycleEnd    time.Time // Exclusive: the next payday, or the first of next month
+	IncomeMinor int64     // Income received since the cycle started
+	SalaryMinor int64     // Typical salary; expected when it hasn't been received yet
+	SpentMinor  int64     // Spend since the cycle started, positive, goal transfers excluded
+	Charges     []ScheduledCharge
+	Goals       []GoalCommitment
+	PaydayCycle bool
+}
+
+// SafeToSpend is how much can be spent per day until the cycle ends
+type SafeToSpend struct {
+	AsOf          time.Time
+	CycleStart    time.Time
+	CycleEnd      time.Time
+	PaydayCycle   bool // False when the cycle is the calendar month
+	DaysRemaining int  // Including today
+
+	ExpectedIncomeMinor    int64
+	CommittedMinor         int64 // Bills, subscriptions and installments still due
+	GoalContributionsMinor int64 // Still to set aside for goals this cycle
+	SpentMinor             int64
+	Charges                []ScheduledCharge
+
+	// AvailableMinor is what's left for the rest of the cycle; negative when
+	// the cycle is already overcommitted
+	AvailableMinor int64
+	// DailyMinor is AvailableMinor spread over the days remaining, never negative
+	DailyMinor int64
+}
+
+// GetSafeToSpend computes the user's daily allowance for the rest of the
+// current pay cycle as of asOf
+func (s *Service) GetSafeToSpend(ctx context.Context, userID uuid.UUID, asOf time.Time) (*SafeToSpend, error) {
+	salaries, err := s.recentSalaries(ctx, userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]time.Time, len(salaries))
+	for i, p := range salaries {
+		dates[i] = p.PostedAt
+	}
+
+	in := SafeToSpendInputs{
+		AsOf:        asOf,
+		CycleStart:  startOfMonth(asOf),
+		CycleEnd:    startOfMonth(asOf).AddDate(0, 1, 0),
+		SalaryMinor: medianSalary(salaries),
+	}
+	if payday := InferPayday(s.calendars.ForUser(ctx, userID), dates, asOf); payday != nil {
+		in.CycleStart, in.CycleEnd, in.PaydayCycle = payday.Previous, payday.Next, true
+	}
+
+	// Transfers recorded as goal contributions count as goal money, not spend
+	err = s.repo.DB().QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(t.amount_minor) FILTER (WHERE t.amount_minor > 0 AND NOT t.is_reward), 0),
+			COALESCE(-SUM(t.amount_minor) FILTER (WHERE t.amount_minor < 0), 0)
+		FROM transactions t
+		WHERE t.user_id = $1 AND t.posted_at >= $2 AND t.posted_at <= $3
+		  AND t.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM goal_contributions gc WHERE gc.transaction_id = t.id)
+	`, userID, in.CycleStart, asOf).Scan(&in.IncomeMinor, &in.SpentMinor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle income and spend: %w", err)
+	}
+
+	if in.Charges, err = s.scheduledCharges(ctx, userID, asOf, in.CycleEnd.AddDate(0, 0, -1)); err != nil {
+		return nil, err
+	}
+	if in.Goals, err = s.goalCommitments(ctx, userID, in.CycleStart); err != nil {
+		return nil, err
+	}
+
+	return ComputeSafeToSpend(in), nil
+}
+
+// ComputeSafeToSpend works out the daily allowance: expected income less
+// what's committed, set aside for goals and already spent, over the days left.
+// Income is the larger of what was received and the typical salary, so a
+// salary that hasn't landed yet still counts.
+func ComputeSafeToSpend(in SafeToSpendInputs) *SafeToSpend {
+	today := time.Date(in.AsOf.Year(), in.AsOf.Month(), in.AsOf.Day(), 0, 0, 0, 0, in.AsOf.Location())
+	days := max(int(math.Round(in.CycleEnd.Sub(today).Hours()/24)), 1)
+
+	s := &SafeToSpend{
+		AsOf:                in.AsOf,
+		CycleStart:          in.CycleStart,
+		CycleEnd:            in.CycleEnd,
+		PaydayCycle:         in.PaydayCycle,
+		DaysRemaining:       days,
+		ExpectedIncomeMinor: max(in.IncomeMinor, in.SalaryMinor),
+		SpentMinor:          in.SpentMinor,
+		Charges:             in.Charges,
+	}
+	for _, c := range in.Charges {
+		s.CommittedMinor += c.AmountMinor
+	}
+	for _, g := range in.Goals {
+		s.GoalContributionsMinor += goalDueInCycle(g, in.CycleStart)
+	}
+
+	s.AvailableMinor = s.ExpectedIncomeMinor - s.CommittedMinor - s.GoalContributionsMinor - s.SpentMinor
+	s.DailyMinor = max(s.AvailableMinor, 0) / int64(days)
+	return s
+}
+
+// goalDueInCycle is what's still to be contributed to a goal this cycle: the
+// remainder at the start of the cycle spread over the months left (a partial
+// month counts as one), less what was already contributed
+func goalDueInCycle(g GoalCommitment, cycleStart time.Time) int64 {
+	remaining := g.TargetMinor - g.CurrentMinor + g.ContributedMinor
+	if remaining <= 0 {
+		return 0
+	}
+	months := (g.EndAt.Year()-cycleStart.Year())*12 + int(g.EndAt.Month()-cycleStart.Month())
+	if cycleStart.AddDate(0, months, 0).Before(g.EndAt) {
+		months++
+	}
+	due := remaining
+	if months > 1 {
+		due = int64(math.Ceil(float64(remaining) / float64(months)))
+	}
+	return max(due-g.ContributedMinor, 0)
+}
+
+// goalCommitments lists the user's active saving and debt goals with what was
+// contributed to them since the cycle started
+func (s *Service) goalCommitments(ctx context.Context, userID uuid.UUID, cycleStart time.Time) ([]GoalCommitment, error) {
+	rows, err := s.repo.DB().Query(ctx, `
+		SELECT g.target_amount_minor, g.current_amount_minor, g.end_at,
+		       COALESCE(SUM(gc.amount_minor) FILTER (WHERE gc.contributed_at >= $2), 0)
+		FROM goals g
+		LEFT JOIN goal_contributions gc ON gc.goal_id = g.id
+		WHERE g.user_id = $1 AND g.status = 'active' AND g.type IN ('save', 'pay_down_debt')
+		  AND g.deleted_at IS NULL
+		GROUP BY g.id
+	`, userID, cycleStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	var goals []GoalCommitment
+	for rows.Next() {
+		var g GoalCommitment
+		if err := rows.Scan(&g.TargetMinor, &g.CurrentMinor, &g.EndAt, &g.ContributedMinor); err != nil {
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate goals: %w", err)
+	}
+	return goals, nil
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	authrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/auth/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cache"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/push"
 )
 
@@ -39,13 +43,25 @@ type SpendingPulse struct {
 
 // DashboardBlock represents a single block for the bento grid dashboard
 type DashboardBlock struct {
-	Type     string // "status", "hook", "cta"
+	Type     string // A type registered with RegisterDashboardBlock
+	Size     DashboardBlockSize
 	Title    string
 	Subtitle string
 	Value    string
 	Icon     string
 	Color    string // For status indication
-	Action   string // Optional action identifier
+
+	Action    *DashboardAction    // Optional: where tapping the block goes
+	Sparkline *DashboardSparkline // Optional: series behind Value
+	Progress  *DashboardProgress  // Optional: progress towards a target
+}
+
+// Notifier records alerts and digests in the notification inbox and delivers them
+type Notifier interface {
+	// NotifyAlert delivers on the given channels; nil means the default routing
+	NotifyAlert(ctx context.Context, alert *Alert, channels []AlertChannel, pushData map[string]any) error
+	NotifyDigest(ctx context.Context, userID uuid.UUID, digest *WeeklyDigest) error
+	MarkAlertRead(ctx context.Context, alertID uuid.UUID) error
 }
 
 // Service handles insights business logic
@@ -53,49 +69,82 @@ type Service struct {
 	repo     InsightsRepository
 	push     *push.Service
 	authRepo authrepo.AuthRepository
+	notifier Notifier
+	broker   *AlertBroker // Optional: nil if alerts aren't streamed
+	trends   trendsCache
+	cache    *cache.Cache // Optional: nil computes dashboard queries on every call
 	logger   *slog.Logger
+
+	calendars   *calendar.Resolver
+	streaks     StreakSource
+	households  HouseholdSource
+	netWorth    NetWorthSource
+	interpreter QuestionInterpreter // Optional: nil answers only questions the templates can plan
+
+	blockProviders map[string]DashboardBlockProvider
+	blockTypes     []string // Registration order
 }
 
 // NewService creates a new insights service
 func NewService(repo InsightsRepository, pushSvc *push.Service, authRepo authrepo.AuthRepository, logger *slog.Logger) *Service {
-	return &Service{
+	s := &Service{
 		repo:     repo,
 		push:     pushSvc,
 		authRepo: authRepo,
 		logger:   logger,
 	}
+	s.registerBuiltinDashboardBlocks()
+	return s
+}
+
+// WithNotifier routes alerts through the notification inbox instead of sending push directly
+func (s *Service) WithNotifier(n Notifier) *Service {
+	s.notifier = n
+	return s
+}
+
+// WithCache keeps the spending pulse and dashboard blocks in c until the
+// user's data changes
+func (s *Service) WithCache(c *cache.Cache) *Service {
+	s.cache = c
+	return s
 }
 
 const (
 	// PaceThreshold is the percentage above which we consider "over pace"
 	PaceThreshold = 125.0 // 25% over last month's pace
-
-	// NotificationThreshold triggers a pace notification
-	NotificationThreshold = 120.0 // 20% over
 )
 
-// GetSpendingPulse computes the spending pulse for a user
-func (s *Service) GetSpendingPulse(ctx context.Context, userID uuid.UUID, asOf time.Time) (*SpendingPulse, error) {
+// GetSpendingPulse computes the spending pulse for a user, narrowed by the
+// filter
+func (s *Service) GetSpendingPulse(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (*SpendingPulse, error) {
+	key := "spending_pulse:" + asOf.Format(time.DateOnly) + filter.cacheKey()
+	return cache.GetOrLoad(ctx, s.cache, userID, key, func(ctx context.Context) (*SpendingPulse, error) {
+		return s.computeSpendingPulse(ctx, userID, asOf, filter)
+	})
+}
+
+func (s *Service) computeSpendingPulse(ctx context.Context, userID uuid.UUID, asOf time.Time, filter InsightsFilter) (*SpendingPulse, error) {
 	// Get raw spending data
-	data, err := s.repo.GetSpendingPulseData(ctx, userID, asOf)
+	data, err := s.repo.GetSpendingPulseData(ctx, userID, asOf, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get transaction count
-	txCount, err := s.repo.GetTransactionCount(ctx, userID, asOf)
+	txCount, err := s.repo.GetTransactionCount(ctx, userID, asOf, filter)
 	if err != nil {
 		txCount = 0 // Non-critical
 	}
 
 	// Get top categories
-	categories, err := s.repo.GetTopCategories(ctx, userID, asOf, 5)
+	categories, err := s.repo.GetTopCategories(ctx, userID, asOf, 5, filter)
 	if err != nil {
 		categories = nil // Non-critical
 	}
 
 	// Get surprise expenses
-	surprises, err := s.repo.GetSurpriseExpenses(ctx, userID, asOf, 3)
+	surprises, err := s.repo.GetSurpriseExpenses(ctx, userID, asOf, 3, filter)
 	if err != nil {
 		surprises = nil // Non-critical
 	}
@@ -133,21 +182,24 @@ func (s *Service) GetSpendingPulse(ctx context.Context, userID uuid.UUID, asOf t
 	return pulse, nil
 }
 
-// ShouldNotify checks if a pace notification should be triggered
-func (s *Service) ShouldNotify(pulse *SpendingPulse) bool {
-	return pulse.PacePercent > NotificationThreshold && pulse.LastMonthSpend > 0
+// ShouldNotify checks if a pace notification should be triggered, going by
+// the user's pace warning setting
+func (s *Service) ShouldNotify(ctx context.Context, userID uuid.UUID, pulse *SpendingPulse) bool {
+	setting := s.alertSetting(ctx, userID, AlertTypePaceWarning)
+	return setting.Enabled && pulse.PacePercent > *setting.Threshold && pulse.LastMonthSpend > 0
 }
 
 // GetDashboardBlocks returns blocks for the bento grid dashboard
 func (s *Service) GetDashboardBlocks(ctx context.Context, userID uuid.UUID, asOf time.Time) ([]DashboardBlock, error) {
-	pulse, err := s.GetSpendingPulse(ctx, userID, asOf)
-	if err != nil {
-		return nil, err
-	}
-
-	blocks := make([]DashboardBlock, 0, 3)
+	return cache.GetOrLoad(ctx, s.cache, userID, "dashboard_blocks:"+asOf.Format(time.DateOnly), func(ctx context.Context) ([]DashboardBlock, error) {
+		return s.computeDashboardBlocks(ctx, userID, asOf)
+	})
+}
 
-	// Block 1: Status - Pace indicator
+// statusBlock shows the spending pace, with spending over the last months as
+// a sparkline
+func (s *Service) statusBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	pulse := req.Pulse
 	statusColor := "green"
 	if pulse.PacePercent > 110 {
 		statusColor = "yellow"
@@ -156,50 +208,67 @@ func (s *Service) GetDashboardBlocks(ctx context.Context, userID uuid.UUID, asOf
 		statusColor = "red"
 	}
 
-	blocks = append(blocks, DashboardBlock{
-		Type:     "status",
+	block := &DashboardBlock{
+		Type:     DashboardBlockStatus,
 		Title:    pulse.PaceMessage,
 		Subtitle: s.getStatusSubtitle(pulse),
 		Value:    formatMoney(pulse.CurrentMonthSpend),
 		Icon:     "trending-up",
 		Color:    statusColor,
-	})
+		Action:   &DashboardAction{ID: "view_spending", Path: "/insights/spending"},
+	}
+	trends, err := s.GetSpendingTrends(ctx, req.UserID, DefaultTrendMonths)
+	if err != nil {
+		return nil, err
+	}
+	block.Sparkline = spendingSparkline(trends)
+	return block, nil
+}
 
-	// Block 2: Hook - Top category or surprise expense
+// hookBlock shows the top surprise expense, else the top category
+func hookBlock(_ context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	pulse := req.Pulse
 	if len(pulse.SurpriseExpenses) > 0 {
 		surprise := pulse.SurpriseExpenses[0]
-		blocks = append(blocks, DashboardBlock{
-			Type:     "hook",
+		return &DashboardBlock{
+			Type:     DashboardBlockHook,
 			Title:    "New This Month",
 			Subtitle: surprise.MerchantName,
 			Value:    formatMoney(surprise.AmountCents),
 			Icon:     "alert-circle",
 			Color:    "blue",
-		})
-	} else if len(pulse.TopCategories) > 0 {
+			Action:   &DashboardAction{ID: "view_transaction", Path: "/transactions/" + surprise.TransactionID.String()},
+		}, nil
+	}
+	if len(pulse.TopCategories) > 0 {
 		top := pulse.TopCategories[0]
-		blocks = append(blocks, DashboardBlock{
-			Type:     "hook",
+		block := &DashboardBlock{
+			Type:     DashboardBlockHook,
 			Title:    "Top Category",
 			Subtitle: top.CategoryName,
 			Value:    formatMoney(top.AmountCents),
 			Icon:     "pie-chart",
 			Color:    "purple",
-		})
+		}
+		if top.CategoryID != nil {
+			block.Action = &DashboardAction{ID: "view_category", Path: "/categories/" + top.CategoryID.String()}
+		}
+		return block, nil
 	}
+	return nil, nil
+}
 
-	// Block 3: CTA - Action item
+// ctaBlock prompts the user to review transactions
+func (s *Service) ctaBlock(_ context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
 	// TODO: Check for uncategorized transactions
-	blocks = append(blocks, DashboardBlock{
-		Type:     "cta",
+	return &DashboardBlock{
+		Type:     DashboardBlockCTA,
 		Title:    "Review Transactions",
-		Subtitle: s.getTransactionCTA(pulse.TransactionCount),
+		Subtitle: s.getTransactionCTA(req.Pulse.TransactionCount),
 		Icon:     "check-circle",
 		Color:    "gray",
-		Action:   "review_transactions",
-	})
-
-	return blocks, nil
+		Action:   &DashboardAction{ID: "review_transactions", Path: "/transactions/review"},
+	}, nil
 }
 
 // getPaceMessage returns a human-readable pace message
@@ -285,8 +354,9 @@ func formatInt(n int) string {
 
 // TriggerPaceAlert creates a pace warning alert if conditions are met
 func (s *Service) TriggerPaceAlert(ctx context.Context, userID uuid.UUID, pulse *SpendingPulse) error {
-	// Only trigger if over notification threshold
-	if pulse.PacePercent < NotificationThreshold {
+	// Only trigger if over the user's notification threshold
+	setting := s.alertSetting(ctx, userID, AlertTypePaceWarning)
+	if !setting.Enabled || pulse.PacePercent < *setting.Threshold {
 		return nil
 	}
 
@@ -322,12 +392,118 @@ func (s *Service) TriggerPaceAlert(ctx context.Context, userID uuid.UUID, pulse
 		AlertDate: today,
 	}
 
-	if err := s.repo.CreateAlert(ctx, alert); err != nil {
+	if err := s.createAlert(ctx, alert); err != nil {
 		return err
 	}
 
-	// Send push notification if user has a push token
-	if s.push != nil && s.authRepo != nil {
+	pushData := map[string]any{
+		"alert_type":   string(alert.AlertType),
+		"severity":     string(alert.Severity),
+		"pace_percent": pulse.PacePercent,
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, pushData)
+	return nil
+}
+
+// BudgetOverspend is a budget line (an item, or a flex group as a whole) over its limit
+type BudgetOverspend struct {
+	Name          string
+	BudgetedMinor int64
+	ActualMinor   int64
+	IsGroup       bool
+}
+
+// TriggerBudgetAlert creates a budget overspend alert (at most one per day) for the given lines
+func (s *Service) TriggerBudgetAlert(ctx context.Context, userID, planID uuid.UUID, lines []BudgetOverspend) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	setting := s.alertSetting(ctx, userID, AlertTypeBudgetOverspend)
+	if !setting.Enabled {
+		return nil
+	}
+
+	today := time.Now()
+	hasAlert, err := s.repo.HasAlertToday(ctx, userID, AlertTypeBudgetOverspend, today)
+	if err != nil || hasAlert {
+		return err
+	}
+
+	var over int64
+	names := make([]string, 0, len(lines))
+	for _, l := range lines {
+		over += l.ActualMinor - l.BudgetedMinor
+		names = append(names, l.Name)
+	}
+
+	severity := AlertSeverityWarning
+	if len(lines) > 2 {
+		severity = AlertSeverityCritical
+	}
+
+	title := fmt.Sprintf("%s is over budget", lines[0].Name)
+	if len(lines) > 1 {
+		title = fmt.Sprintf("%d budgets are over their limit", len(lines))
+	}
+
+	referenceType := "plan"
+	alert := &Alert{
+		UserID:        userID,
+		AlertType:     AlertTypeBudgetOverspend,
+		Severity:      severity,
+		Title:         title,
+		Message:       fmt.Sprintf("%s went %s over budget this month.", strings.Join(names, ", "), formatMoney(over)),
+		ReferenceType: &referenceType,
+		ReferenceID:   &planID,
+		Metadata: map[string]any{
+			"lines":      names,
+			"over_minor": over,
+		},
+		AlertDate: today,
+	}
+
+	if err := s.createAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, map[string]any{
+		"alert_type": string(alert.AlertType),
+		"severity":   string(alert.Severity),
+		"plan_id":    planID.String(),
+	})
+	return nil
+}
+
+// deliverAlert records an alert in the inbox (or, without a notifier, sends it
+// as a push notification) and streams it to connected clients, in the background
+func (s *Service) deliverAlert(userID uuid.UUID, alert *Alert, channels []AlertChannel, pushData map[string]any) {
+	if s.broker != nil && alert.ID != uuid.Nil {
+		go func() {
+			streamCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			s.publishAlertEvent(streamCtx, &AlertEvent{Kind: AlertEventAlert, UserID: userID, Alert: alert})
+		}()
+	}
+
+	// Record in the inbox and fan out to the user's channels
+	if s.notifier != nil {
+		if alert.ID == uuid.Nil {
+			return // Deduplicated by a concurrent trigger
+		}
+		go func() {
+			notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := s.notifier.NotifyAlert(notifyCtx, alert, channels, pushData); err != nil && s.logger != nil {
+				s.logger.Warn("failed to notify alert", "userID", userID, "error", err)
+			}
+		}()
+		return
+	}
+
+	// Send push notification if user has a push token and wants these on push
+	if s.push != nil && s.authRepo != nil && (channels == nil || slices.Contains(channels, AlertChannelPush)) {
 		go func() {
 			pushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
@@ -347,11 +523,7 @@ func (s *Service) TriggerPaceAlert(ctx context.Context, userID uuid.UUID, pulse
 				To:    token,
 				Title: alert.Title,
 				Body:  alert.Message,
-				Data: map[string]any{
-					"alert_type":   string(alert.AlertType),
-					"severity":     string(alert.Severity),
-					"pace_percent": pulse.PacePercent,
-				},
+				Data:  pushData,
 			}
 
 			if err := s.push.Send(pushCtx, msg); err != nil && s.logger != nil {
@@ -360,7 +532,6 @@ func (s *Service) TriggerPaceAlert(ctx context.Context, userID uuid.UUID, pulse
 		}()
 	}
 
-	return nil
 }
 
 // GetUnreadAlerts returns unread alerts for a user
@@ -370,7 +541,13 @@ func (s *Service) GetUnreadAlerts(ctx context.Context, userID uuid.UUID, limit i
 
 // MarkAlertRead marks an alert as read
 func (s *Service) MarkAlertRead(ctx context.Context, alertID uuid.UUID) error {
-	return s.repo.MarkAlertRead(ctx, alertID)
+	if err := s.repo.MarkAlertRead(ctx, alertID); err != nil {
+		return err
+	}
+	if s.notifier != nil {
+		return s.notifier.MarkAlertRead(ctx, alertID)
+	}
+	return nil
 }
 
 // MarkAlertDismissed marks an alert as dismissed
@@ -378,9 +555,10 @@ func (s *Service) MarkAlertDismissed(ctx context.Context, alertID uuid.UUID) err
 	return s.repo.MarkAlertDismissed(ctx, alertID)
 }
 
-// GetImportInsights returns quality insights for an import job
-func (s *Service) GetImportInsights(ctx context.Context, importJobID uuid.UUID) (*ImportJobInsights, error) {
-	return s.repo.GetImportInsights(ctx, importJobID)
+// GetImportInsights returns quality insights for one of the user's import
+// jobs; sql.ErrNoRows if the job isn't theirs or has no insights yet
+func (s *Service) GetImportInsights(ctx context.Context, userID, importJobID uuid.UUID) (*ImportJobInsights, error) {
+	return s.repo.GetImportJobInsights(ctx, userID, importJobID)
 }
 
 // UpsertImportInsights creates or updates import insights
@@ -2,6 +2,8 @@ package insights_test
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cache"
 )
 
 // MockInsightsRepo is a mock implementation of the insights repository
@@ -18,16 +21,28 @@ type MockInsightsRepo struct {
 	alerts       []insights.Alert
 	alertsByUser map[uuid.UUID][]insights.Alert
 	alertToday   bool
+	settings     map[uuid.UUID][]insights.AlertSetting
+
+	monthlySpend      []insights.MonthlyCategorySpend
+	monthlySpendCalls int
+	pulseCalls        int
+	pulseFilters      []insights.InsightsFilter
+	rollups           []insights.MonthlyRollup
+	layouts           map[uuid.UUID][]insights.DashboardLayoutBlock
 }
 
 func NewMockInsightsRepo() *MockInsightsRepo {
 	return &MockInsightsRepo{
 		alerts:       make([]insights.Alert, 0),
 		alertsByUser: make(map[uuid.UUID][]insights.Alert),
+		settings:     make(map[uuid.UUID][]insights.AlertSetting),
+		layouts:      make(map[uuid.UUID][]insights.DashboardLayoutBlock),
 	}
 }
 
-func (m *MockInsightsRepo) GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time) (*insights.SpendingPulseData, error) {
+func (m *MockInsightsRepo) GetSpendingPulseData(ctx context.Context, userID uuid.UUID, asOf time.Time, filter insights.InsightsFilter) (*insights.SpendingPulseData, error) {
+	m.pulseCalls++
+	m.pulseFilters = append(m.pulseFilters, filter)
 	return &insights.SpendingPulseData{
 		CurrentMonthSpend: 50000, // $500
 		LastMonthSpend:    40000, // $400
@@ -36,18 +51,56 @@ func (m *MockInsightsRepo) GetSpendingPulseData(ctx context.Context, userID uuid
 	}, nil
 }
 
-func (m *MockInsightsRepo) GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time) (int, error) {
+func (m *MockInsightsRepo) GetTransactionCount(ctx context.Context, userID uuid.UUID, asOf time.Time, filter insights.InsightsFilter) (int, error) {
 	return 25, nil
 }
 
-func (m *MockInsightsRepo) GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]insights.TopCategory, error) {
+func (m *MockInsightsRepo) GetMonthlyCategorySpend(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]insights.MonthlyCategorySpend, error) {
+	m.monthlySpendCalls++
+	var spend []insights.MonthlyCategorySpend
+	for _, row := range m.monthlySpend {
+		if !row.Month.Before(from) && row.Month.Before(to) {
+			spend = append(spend, row)
+		}
+	}
+	return spend, nil
+}
+
+func (m *MockInsightsRepo) GetMonthlyRollups(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]insights.MonthlyRollup, error) {
+	var rollups []insights.MonthlyRollup
+	for _, row := range m.rollups {
+		if !row.Month.Before(from) && row.Month.Before(to) {
+			rollups = append(rollups, row)
+		}
+	}
+	return rollups, nil
+}
+
+func (m *MockInsightsRepo) GetTopCategories(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter insights.InsightsFilter) ([]insights.TopCategory, error) {
 	return []insights.TopCategory{
 		{CategoryName: "Food", AmountCents: 15000, TxCount: 10},
 		{CategoryName: "Transport", AmountCents: 8000, TxCount: 5},
 	}, nil
 }
 
-func (m *MockInsightsRepo) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int) ([]insights.SurpriseExpense, error) {
+func (m *MockInsightsRepo) GetHouseholdSpendingPulseData(ctx context.Context, userIDs []uuid.UUID, asOf time.Time) (*insights.SpendingPulseData, error) {
+	// Each member spends like the single-user mock
+	n := int64(len(userIDs))
+	return &insights.SpendingPulseData{
+		CurrentMonthSpend: 50000 * n,
+		LastMonthSpend:    40000 * n,
+		DayOfMonth:        15,
+		AsOfDate:          asOf,
+	}, nil
+}
+
+func (m *MockInsightsRepo) GetHouseholdTopCategories(ctx context.Context, userIDs []uuid.UUID, asOf time.Time, limit int) ([]insights.TopCategory, error) {
+	return []insights.TopCategory{
+		{CategoryName: "Groceries", AmountCents: 30000, TxCount: 12},
+	}, nil
+}
+
+func (m *MockInsightsRepo) GetSurpriseExpenses(ctx context.Context, userID uuid.UUID, asOf time.Time, limit int, filter insights.InsightsFilter) ([]insights.SurpriseExpense, error) {
 	return []insights.SurpriseExpense{}, nil
 }
 
@@ -67,7 +120,7 @@ func (m *MockInsightsRepo) GetUnreadAlerts(ctx context.Context, userID uuid.UUID
 	alerts := m.alertsByUser[userID]
 	result := make([]insights.Alert, 0)
 	for _, a := range alerts {
-		if !a.IsRead && !a.IsDismissed {
+		if !a.IsRead && !a.IsDismissed && (a.SnoozedUntil == nil || !a.SnoozedUntil.After(time.Now())) {
 			result = append(result, a)
 		}
 	}
@@ -115,9 +168,117 @@ func (m *MockInsightsRepo) MarkAlertDismissed(ctx context.Context, alertID uuid.
 	return nil
 }
 
+// forEachAlert applies fn to both copies of each alert
+func (m *MockInsightsRepo) forEachAlert(fn func(a *insights.Alert)) {
+	for i := range m.alerts {
+		fn(&m.alerts[i])
+	}
+	for _, alerts := range m.alertsByUser {
+		for i := range alerts {
+			fn(&alerts[i])
+		}
+	}
+}
+
+func (m *MockInsightsRepo) MarkAllAlertsRead(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, a := range m.alertsByUser[userID] {
+		if !a.IsRead && !a.IsDismissed {
+			ids = append(ids, a.ID)
+		}
+	}
+	for _, id := range ids {
+		_ = m.MarkAlertRead(ctx, id)
+	}
+	return ids, nil
+}
+
+func (m *MockInsightsRepo) SnoozeAlert(ctx context.Context, userID, alertID uuid.UUID, until time.Time) error {
+	found := false
+	m.forEachAlert(func(a *insights.Alert) {
+		if a.ID == alertID && a.UserID == userID && !a.IsDismissed {
+			a.SnoozedUntil = &until
+			found = true
+		}
+	})
+	if !found {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (m *MockInsightsRepo) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	var expired []uuid.UUID
+	for _, a := range m.alerts {
+		if a.ExpiresAt != nil && !a.ExpiresAt.After(now) && !a.IsDismissed {
+			expired = append(expired, a.ID)
+		}
+	}
+	for _, id := range expired {
+		_ = m.MarkAlertDismissed(ctx, id)
+	}
+	return int64(len(expired)), nil
+}
+
+func (m *MockInsightsRepo) DeleteAlertsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	stale := func(a insights.Alert) bool {
+		return a.AlertDate.Before(cutoff) && (a.IsRead || a.IsDismissed)
+	}
+	var kept []insights.Alert
+	for _, a := range m.alerts {
+		if !stale(a) {
+			kept = append(kept, a)
+		}
+	}
+	deleted := int64(len(m.alerts) - len(kept))
+	m.alerts = kept
+	for userID, alerts := range m.alertsByUser {
+		var userKept []insights.Alert
+		for _, a := range alerts {
+			if !stale(a) {
+				userKept = append(userKept, a)
+			}
+		}
+		m.alertsByUser[userID] = userKept
+	}
+	return deleted, nil
+}
+
+func (m *MockInsightsRepo) GetAlertSettings(ctx context.Context, userID uuid.UUID) ([]insights.AlertSetting, error) {
+	return m.settings[userID], nil
+}
+
+func (m *MockInsightsRepo) UpsertAlertSetting(ctx context.Context, userID uuid.UUID, setting *insights.AlertSetting) error {
+	for i, existing := range m.settings[userID] {
+		if existing.AlertType == setting.AlertType {
+			m.settings[userID][i] = *setting
+			return nil
+		}
+	}
+	m.settings[userID] = append(m.settings[userID], *setting)
+	return nil
+}
+
+func (m *MockInsightsRepo) SetAccountExcludedFromInsights(ctx context.Context, userID, accountID uuid.UUID, excluded bool) error {
+	return nil
+}
+
+func (m *MockInsightsRepo) SetCategoryExcludedFromInsights(ctx context.Context, userID, categoryID uuid.UUID, excluded bool) error {
+	return nil
+}
+
+func (m *MockInsightsRepo) GetDashboardLayout(ctx context.Context, userID uuid.UUID) ([]insights.DashboardLayoutBlock, error) {
+	return m.layouts[userID], nil
+}
+
+func (m *MockInsightsRepo) UpsertDashboardLayout(ctx context.Context, userID uuid.UUID, blocks []insights.DashboardLayoutBlock) error {
+	m.layouts[userID] = blocks
+	return nil
+}
+
 // Import insights mocks
-func (m *MockInsightsRepo) GetImportInsights(ctx context.Context, importJobID uuid.UUID) (*insights.ImportJobInsights, error) {
-	return nil, nil
+func (m *MockInsightsRepo) GetImportJobInsights(ctx context.Context, userID, importJobID uuid.UUID) (*insights.ImportJobInsights, error) {
+	return nil, sql.ErrNoRows
 }
 
 func (m *MockInsightsRepo) UpsertImportInsights(ctx context.Context, i *insights.ImportJobInsights) error {
@@ -340,7 +501,7 @@ func TestSpendingPulse_CalculatesPaceCorrectly(t *testing.T) {
 	svc := insights.NewService(repo, nil, nil, nil)
 
 	userID := uuid.New()
-	pulse, err := svc.GetSpendingPulse(context.Background(), userID, time.Now())
+	pulse, err := svc.GetSpendingPulse(context.Background(), userID, time.Now(), insights.InsightsFilter{})
 	require.NoError(t, err)
 
 	// Mock returns $500 current, $400 last
@@ -352,3 +513,696 @@ func TestSpendingPulse_CalculatesPaceCorrectly(t *testing.T) {
 	// At exactly 125%, IsOverPace is false (not strictly over)
 	assert.False(t, pulse.IsOverPace) // 125% == threshold, not over
 }
+
+func TestDashboardBlocks_CachedUntilInvalidated(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil).
+		WithCache(cache.New(cache.NewMemoryStore(), time.Minute, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+
+	first, err := svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	second, err := svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	_, err = svc.GetSpendingPulse(ctx, userID, now, insights.InsightsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.pulseCalls, "the pulse is computed once")
+
+	svc.InvalidateDashboard(ctx, userID)
+	_, err = svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.pulseCalls, "invalidation recomputes the dashboard")
+}
+
+func TestSpendingPulse_CachedPerFilter(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil).
+		WithCache(cache.New(cache.NewMemoryStore(), time.Minute, nil))
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+	business, personal := uuid.New(), uuid.New()
+
+	_, err := svc.GetSpendingPulse(ctx, userID, now, insights.InsightsFilter{})
+	require.NoError(t, err)
+	_, err = svc.GetSpendingPulse(ctx, userID, now, insights.InsightsFilter{AccountIDs: []uuid.UUID{business, personal}})
+	require.NoError(t, err)
+	// Same accounts in another order share the cached pulse
+	_, err = svc.GetSpendingPulse(ctx, userID, now, insights.InsightsFilter{AccountIDs: []uuid.UUID{personal, business}})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, repo.pulseCalls)
+	assert.Empty(t, repo.pulseFilters[0].AccountIDs)
+	assert.ElementsMatch(t, []uuid.UUID{business, personal}, repo.pulseFilters[1].AccountIDs)
+}
+
+// fakeHouseholds maps users to their household's member IDs
+type fakeHouseholds map[uuid.UUID][]uuid.UUID
+
+func (f fakeHouseholds) MemberIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	if ids, ok := f[userID]; ok {
+		return ids, nil
+	}
+	return []uuid.UUID{userID}, nil
+}
+
+func TestGetHouseholdSpending_CombinesMembers(t *testing.T) {
+	partnerA, partnerB, single := uuid.New(), uuid.New(), uuid.New()
+	households := fakeHouseholds{
+		partnerA: {partnerA, partnerB},
+		partnerB: {partnerA, partnerB},
+	}
+	svc := insights.NewService(NewMockInsightsRepo(), nil, nil, nil).WithHouseholds(households)
+
+	spending, err := svc.GetHouseholdSpending(context.Background(), partnerB, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, spending)
+	assert.Equal(t, 2, spending.MemberCount)
+	assert.Equal(t, int64(100000), spending.CurrentMonthSpend)
+	assert.InDelta(t, 125.0, spending.PacePercent, 0.1)
+	require.Len(t, spending.TopCategories, 1)
+
+	// Users outside a household get no combined view or dashboard block
+	spending, err = svc.GetHouseholdSpending(context.Background(), single, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, spending)
+
+	blocks, err := svc.GetDashboardBlocks(context.Background(), partnerA, time.Now())
+	require.NoError(t, err)
+	var found bool
+	for _, b := range blocks {
+		if b.Type == "household" {
+			found = true
+			assert.Equal(t, "$1.0k", b.Value)
+		}
+	}
+	assert.True(t, found, "expected a household block")
+}
+
+func TestProjectCashflow_BandsAroundDailyAverage(t *testing.T) {
+	asOf := time.Date(2025, time.June, 20, 12, 0, 0, 0, time.UTC)
+	payday := time.Date(2025, time.June, 25, 0, 0, 0, 0, time.UTC)
+
+	forecast := insights.ProjectCashflow(insights.CashflowInputs{
+		AsOf:            asOf,
+		BalanceMinor:    50000,
+		DailySpendMinor: []int64{1000, 3000, 1000, 3000},
+		IncomeMinor:     200000,
+		NextPayday:      &payday,
+		Charges: []insights.ScheduledCharge{
+			{Name: "Netflix", AmountMinor: 1500, DueAt: asOf.AddDate(0, 0, 3)},
+		},
+		MonthIncome: 0,
+		MonthSpend:  100000,
+	})
+
+	assert.Equal(t, 10, forecast.DaysRemaining)
+	assert.Equal(t, int64(2000), forecast.DailyDiscretionaryMinor)
+	assert.Equal(t, int64(20000), forecast.Expected.DiscretionaryMinor)
+	assert.Equal(t, int64(50000+200000-1500-20000), forecast.Expected.EndBalanceMinor)
+
+	// stddev 1000/day over 10 days: 1.28 * 1000 * sqrt(10)
+	assert.Equal(t, int64(15952), forecast.Best.DiscretionaryMinor)
+	assert.Equal(t, int64(24048), forecast.Worst.DiscretionaryMinor)
+	assert.Greater(t, forecast.Best.EndBalanceMinor, forecast.Worst.EndBalanceMinor)
+	assert.False(t, forecast.AtRisk)
+
+	require.NotNil(t, forecast.SavingsRate)
+	assert.InDelta(t, float64(200000-100000-1500-20000)/200000, *forecast.SavingsRate, 0.0001)
+}
+
+func TestProjectCashflow_AtRiskWithoutIncome(t *testing.T) {
+	forecast := insights.ProjectCashflow(insights.CashflowInputs{
+		AsOf:            time.Date(2025, time.February, 10, 0, 0, 0, 0, time.UTC),
+		BalanceMinor:    10000,
+		DailySpendMinor: []int64{500, 1500},
+	})
+
+	assert.Equal(t, 18, forecast.DaysRemaining)
+	assert.True(t, forecast.AtRisk)
+	assert.Nil(t, forecast.SavingsRate)
+}
+
+func TestComputeSafeToSpend_SetsAsideCommitmentsAndGoals(t *testing.T) {
+	asOf := time.Date(2025, time.June, 20, 15, 0, 0, 0, time.UTC)
+	cycleStart := time.Date(2025, time.May, 23, 0, 0, 0, 0, time.UTC)
+
+	safe := insights.ComputeSafeToSpend(insights.SafeToSpendInputs{
+		AsOf:        asOf,
+		CycleStart:  cycleStart,
+		CycleEnd:    time.Date(2025, time.June, 25, 0, 0, 0, 0, time.UTC),
+		IncomeMinor: 210000,
+		SalaryMinor: 200000,
+		SpentMinor:  150000,
+		Charges: []insights.ScheduledCharge{
+			{Name: "Netflix", AmountMinor: 1500, DueAt: asOf.AddDate(0, 0, 2)},
+			{Name: "Gym", AmountMinor: 3500, DueAt: asOf.AddDate(0, 0, 3)},
+		},
+		Goals: []insights.GoalCommitment{
+			// 30000 left at the start of the cycle over three months, 4000 already in
+			{TargetMinor: 100000, CurrentMinor: 74000, ContributedMinor: 4000, EndAt: time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)},
+			{TargetMinor: 50000, CurrentMinor: 50000, EndAt: time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		PaydayCycle: true,
+	})
+
+	assert.Equal(t, 5, safe.DaysRemaining)
+	assert.Equal(t, int64(210000), safe.ExpectedIncomeMinor)
+	assert.Equal(t, int64(5000), safe.CommittedMinor)
+	assert.Equal(t, int64(6000), safe.GoalContributionsMinor)
+	assert.Equal(t, int64(210000-5000-6000-150000), safe.AvailableMinor)
+	assert.Equal(t, int64(9800), safe.DailyMinor)
+}
+
+func TestComputeSafeToSpend_ExpectsSalaryAndNeverGoesNegative(t *testing.T) {
+	asOf := time.Date(2025, time.February, 10, 9, 0, 0, 0, time.UTC)
+	in := insights.SafeToSpendInputs{
+		AsOf:        asOf,
+		CycleStart:  time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+		CycleEnd:    time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		SalaryMinor: 190000,
+		SpentMinor:  40000,
+	}
+
+	safe := insights.ComputeSafeToSpend(in)
+	assert.Equal(t, 19, safe.DaysRemaining)
+	assert.Equal(t, int64(190000), safe.ExpectedIncomeMinor, "an unpaid salary still counts")
+	assert.Equal(t, int64(150000/19), safe.DailyMinor)
+
+	in.SpentMinor = 250000
+	safe = insights.ComputeSafeToSpend(in)
+	assert.Equal(t, int64(-60000), safe.AvailableMinor)
+	assert.Zero(t, safe.DailyMinor)
+}
+
+func TestPlanQuestion_Templates(t *testing.T) {
+	asOf := time.Date(2025, time.February, 12, 10, 0, 0, 0, time.UTC)
+	month := func(y int, m time.Month) time.Time { return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC) }
+	categories := []string{"Food", "Groceries", "Eating Out"}
+
+	plan, err := insights.PlanQuestion("How much did I spend on groceries in Q2?", categories, asOf)
+	require.NoError(t, err)
+	assert.Equal(t, insights.MeasureSum, plan.Measure)
+	assert.Equal(t, insights.DirectionExpense, plan.Direction)
+	assert.Equal(t, "Groceries", plan.Category)
+	assert.Empty(t, plan.Merchant)
+	// Q2 hasn't happened yet this year, so it's last year's
+	assert.Equal(t, month(2024, time.April), plan.From)
+	assert.Equal(t, month(2024, time.June), plan.To)
+
+	plan, err = insights.PlanQuestion("How many times did I eat out at Pingo Doce last month?", categories, asOf)
+	require.NoError(t, err)
+	assert.Equal(t, insights.MeasureCount, plan.Measure)
+	assert.Equal(t, "Pingo Doce", plan.Merchant)
+	assert.Equal(t, month(2025, time.January), plan.From)
+	assert.Equal(t, plan.From, plan.To)
+
+	plan, err = insights.PlanQuestion("What was my average income in the last 6 months?", categories, asOf)
+	require.NoError(t, err)
+	assert.Equal(t, insights.MeasureAvg, plan.Measure)
+	assert.Equal(t, insights.DirectionIncome, plan.Direction)
+	assert.Equal(t, month(2024, time.August), plan.From)
+	assert.Equal(t, month(2025, time.January), plan.To)
+
+	plan, err = insights.PlanQuestion("Total spending on eating out in March 2024", categories, asOf)
+	require.NoError(t, err)
+	assert.Equal(t, "Eating Out", plan.Category, "the longest matching category wins")
+	assert.Equal(t, month(2024, time.March), plan.From)
+}
+
+func TestPlanQuestion_RejectsUnsupportedQuestions(t *testing.T) {
+	asOf := time.Date(2025, time.February, 12, 10, 0, 0, 0, time.UTC)
+
+	for _, question := range []string{
+		"DROP TABLE transactions",
+		"How much did I spend today?",
+		"What's the weather like?",
+	} {
+		_, err := insights.PlanQuestion(question, nil, asOf)
+		assert.ErrorIs(t, err, insights.ErrUnsupportedQuestion, question)
+	}
+}
+
+func TestCompileAggregateQuery_ParameterizesFilters(t *testing.T) {
+	userID := uuid.New()
+	from := time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)
+	categoryID := uuid.New()
+
+	sql, args, err := insights.CompileAggregateQuery(userID, insights.AggregateQuery{
+		Dimensions: []insights.AggregateDimension{insights.DimensionCategory, insights.DimensionMonth},
+		Measures:   []insights.AggregateMeasure{insights.MeasureSum, insights.MeasureAvg},
+		Filter: insights.AggregateFilter{
+			From:        &from,
+			CategoryIDs: []uuid.UUID{categoryID},
+			Merchants:   []string{"Pingo Doce'; DROP TABLE transactions; --"},
+			Direction:   insights.DirectionExpense,
+		},
+		OrderBy: insights.MeasureSum,
+	})
+	require.NoError(t, err)
+
+	assert.NotContains(t, sql, "DROP TABLE")
+	assert.Contains(t, sql, "LEFT JOIN categories c")
+	assert.Contains(t, sql, "NOT r.is_income")
+	assert.Contains(t, sql, "GROUP BY r.category_id, COALESCE(c.name::TEXT, ''), r.month, r.currency_code")
+	assert.Contains(t, sql, "ORDER BY ABS(SUM(r.sum_minor)::BIGINT) DESC LIMIT $5")
+	require.Len(t, args, 5)
+	assert.Equal(t, userID, args[0])
+	assert.Equal(t, []string{"pingo doce'; drop table transactions; --"}, args[3])
+	assert.Equal(t, 100, args[4])
+}
+
+func TestCompileAggregateQuery_RejectsUnsupportedQueries(t *testing.T) {
+	cases := map[string]insights.AggregateQuery{
+		"unknown dimension": {Dimensions: []insights.AggregateDimension{"user_id"}},
+		"duplicate":         {Dimensions: []insights.AggregateDimension{insights.DimensionMonth, insights.DimensionMonth}},
+		"unknown measure":   {Measures: []insights.AggregateMeasure{"max"}},
+		"unrequested order": {Measures: []insights.AggregateMeasure{insights.MeasureCount}, OrderBy: insights.MeasureSum},
+		"limit":             {Limit: 5000},
+		"direction":         {Filter: insights.AggregateFilter{Direction: "transfers"}},
+	}
+	for name, q := range cases {
+		_, _, err := insights.CompileAggregateQuery(uuid.New(), q)
+		assert.ErrorIs(t, err, insights.ErrInvalidAggregateQuery, name)
+	}
+}
+
+func TestGoalNudges_BehindPaceOnly(t *testing.T) {
+	asOf := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	behind := insights.DigestGoal{ID: uuid.New(), Name: "Holiday", TargetMinor: 120000, CurrentMinor: 20000, StartAt: start, EndAt: end}
+	onPace := insights.DigestGoal{ID: uuid.New(), Name: "Car", TargetMinor: 120000, CurrentMinor: 60000, StartAt: start, EndAt: end}
+	reached := insights.DigestGoal{ID: uuid.New(), Name: "Laptop", TargetMinor: 50000, CurrentMinor: 50000, StartAt: start, EndAt: end}
+	ended := insights.DigestGoal{ID: uuid.New(), Name: "Wedding", TargetMinor: 50000, CurrentMinor: 0, StartAt: start, EndAt: asOf.AddDate(0, 0, -1)}
+
+	nudges := insights.GoalNudges([]insights.DigestGoal{onPace, behind, reached, ended}, asOf)
+	require.Len(t, nudges, 1)
+	assert.Equal(t, behind.ID, nudges[0].GoalID)
+	assert.Equal(t, int64(59670), nudges[0].ExpectedMinor) // 181 of 364 days
+	// 100000 left over 27 weeks
+	assert.Equal(t, int64(3704), nudges[0].WeeklyNeededMinor)
+}
+
+func TestAttachPeriodNotes_MatchesMonthAndCategory(t *testing.T) {
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	housing, groceries := uuid.New(), uuid.New()
+
+	moved := insights.PeriodNote{ID: uuid.New(), MonthStart: may, Body: "Moved apartments"}
+	deposit := insights.PeriodNote{ID: uuid.New(), MonthStart: may, CategoryID: &housing, Body: "Deposit for the new flat"}
+	party := insights.PeriodNote{ID: uuid.New(), MonthStart: june, CategoryID: &groceries, Body: "Birthday party"}
+	notes := []insights.PeriodNote{moved, deposit, party}
+
+	byMonth := []insights.AggregateRow{{Month: &may}, {Month: &june}, {}}
+	insights.AttachPeriodNotes(byMonth, []insights.AggregateDimension{insights.DimensionMonth}, notes)
+	assert.Equal(t, []insights.PeriodNote{moved, deposit}, byMonth[0].Notes)
+	assert.Equal(t, []insights.PeriodNote{party}, byMonth[1].Notes)
+	assert.Empty(t, byMonth[2].Notes)
+
+	byCategory := []insights.AggregateRow{
+		{Month: &may, CategoryID: &housing},
+		{Month: &may, CategoryID: &groceries},
+		{Month: &may},
+	}
+	insights.AttachPeriodNotes(byCategory, []insights.AggregateDimension{insights.DimensionMonth, insights.DimensionCategory}, notes)
+	assert.Equal(t, []insights.PeriodNote{moved, deposit}, byCategory[0].Notes)
+	assert.Equal(t, []insights.PeriodNote{moved}, byCategory[1].Notes)
+	assert.Equal(t, []insights.PeriodNote{moved}, byCategory[2].Notes)
+}
+
+func TestAddPeriodNote_ValidatesBody(t *testing.T) {
+	svc := insights.NewService(NewMockInsightsRepo(), nil, nil, nil)
+	for _, body := range []string{"", "   ", strings.Repeat("a", 501)} {
+		_, err := svc.AddPeriodNote(context.Background(), uuid.New(), insights.PeriodNoteInput{MonthStart: time.Now(), Body: body})
+		assert.ErrorIs(t, err, insights.ErrInvalidPeriodNote)
+	}
+	assert.ErrorIs(t, svc.UpdatePeriodNote(context.Background(), uuid.New(), uuid.New(), " "), insights.ErrInvalidPeriodNote)
+}
+
+func TestUpdateAlertSettings_ChangesPaceThreshold(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil)
+	userID := uuid.New()
+
+	settings, err := svc.GetAlertSettings(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, insights.DefaultAlertSettings(), settings)
+	pulse := &insights.SpendingPulse{CurrentMonthSpend: 52000, LastMonthSpend: 40000, PacePercent: 130.0, AsOfDate: time.Now()}
+	assert.True(t, svc.ShouldNotify(ctx, userID, pulse))
+
+	threshold := 150.0
+	settings, err = svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{{
+		AlertType: insights.AlertTypePaceWarning,
+		Enabled:   true,
+		Threshold: &threshold,
+		Channels:  []insights.AlertChannel{insights.AlertChannelEmail, insights.AlertChannelEmail},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, insights.AlertTypePaceWarning, settings[0].AlertType)
+	assert.Equal(t, 150.0, *settings[0].Threshold)
+	assert.Equal(t, []insights.AlertChannel{insights.AlertChannelEmail}, settings[0].Channels)
+
+	assert.False(t, svc.ShouldNotify(ctx, userID, pulse))
+	require.NoError(t, svc.TriggerPaceAlert(ctx, userID, pulse))
+	assert.Empty(t, repo.GetAlerts())
+
+	pulse.PacePercent = 160.0
+	assert.True(t, svc.ShouldNotify(ctx, userID, pulse))
+
+	// Turning the type off silences it whatever the pace
+	_, err = svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{{AlertType: insights.AlertTypePaceWarning}})
+	require.NoError(t, err)
+	assert.False(t, svc.ShouldNotify(ctx, userID, pulse))
+	require.NoError(t, svc.TriggerPaceAlert(ctx, userID, pulse))
+	assert.Empty(t, repo.GetAlerts())
+
+	negative := -1.0
+	for _, invalid := range []insights.AlertSetting{
+		{AlertType: "unknown", Enabled: true},
+		{AlertType: insights.AlertTypeBudgetOverspend, Enabled: true, Threshold: &threshold},
+		{AlertType: insights.AlertTypeSurpriseExpense, Enabled: true, Threshold: &negative},
+		{AlertType: insights.AlertTypeStreakBroken, Enabled: true, Channels: []insights.AlertChannel{"pigeon"}},
+	} {
+		_, err := svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{invalid})
+		assert.ErrorIs(t, err, insights.ErrInvalidAlertSetting)
+	}
+	_, err = svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{
+		{AlertType: insights.AlertTypeStreakBroken}, {AlertType: insights.AlertTypeStreakBroken},
+	})
+	assert.ErrorIs(t, err, insights.ErrInvalidAlertSetting)
+}
+
+// channelsNotifier records the channels each alert is delivered on
+type channelsNotifier struct {
+	channels chan []insights.AlertChannel
+}
+
+func (n *channelsNotifier) NotifyAlert(ctx context.Context, alert *insights.Alert, channels []insights.AlertChannel, pushData map[string]any) error {
+	n.channels <- channels
+	return nil
+}
+
+func (n *channelsNotifier) NotifyDigest(ctx context.Context, userID uuid.UUID, digest *insights.WeeklyDigest) error {
+	return nil
+}
+
+func (n *channelsNotifier) MarkAlertRead(ctx context.Context, alertID uuid.UUID) error {
+	return nil
+}
+
+func TestTriggerSurpriseExpenseAlert_UsesThresholdAndChannels(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockInsightsRepo()
+	notifier := &channelsNotifier{channels: make(chan []insights.AlertChannel, 1)}
+	svc := insights.NewService(repo, nil, nil, nil).WithNotifier(notifier)
+	userID := uuid.New()
+	pulse := &insights.SpendingPulse{
+		SurpriseExpenses: []insights.SurpriseExpense{{
+			TransactionID: uuid.New(),
+			MerchantName:  "Furniture Barn",
+			AmountCents:   -25000,
+			PostedAt:      time.Now(),
+		}},
+	}
+
+	threshold := 30000.0
+	_, err := svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{{
+		AlertType: insights.AlertTypeSurpriseExpense,
+		Enabled:   true,
+		Threshold: &threshold,
+		Channels:  []insights.AlertChannel{},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, svc.TriggerSurpriseExpenseAlert(ctx, userID, pulse))
+	assert.Empty(t, repo.GetAlerts(), "below the user's threshold")
+
+	threshold = 20000.0
+	_, err = svc.UpdateAlertSettings(ctx, userID, []insights.AlertSetting{{
+		AlertType: insights.AlertTypeSurpriseExpense,
+		Enabled:   true,
+		Threshold: &threshold,
+		Channels:  []insights.AlertChannel{},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, svc.TriggerSurpriseExpenseAlert(ctx, userID, pulse))
+
+	alerts := repo.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, insights.AlertTypeSurpriseExpense, alerts[0].AlertType)
+	assert.Contains(t, alerts[0].Title, "Furniture Barn")
+	select {
+	case channels := <-notifier.channels:
+		assert.NotNil(t, channels)
+		assert.Empty(t, channels, "in-app only")
+	case <-time.After(time.Second):
+		t.Fatal("alert was not delivered")
+	}
+}
+
+func TestAlertLifecycle_SnoozeMarkAllAndCleanup(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil)
+	userID := uuid.New()
+
+	require.NoError(t, svc.TriggerPaceAlert(ctx, userID, &insights.SpendingPulse{PacePercent: 140.0, LastMonthSpend: 40000, AsOfDate: time.Now()}))
+	deadline := time.Now().AddDate(0, 0, 3)
+	require.NoError(t, svc.TriggerPurchaseReminder(ctx, userID, insights.PurchaseReminder{
+		PurchaseID:   uuid.New(),
+		MerchantName: "Outdoor Store",
+		Deadline:     deadline,
+		DaysLeft:     3,
+	}))
+
+	alerts := repo.GetAlerts()
+	require.Len(t, alerts, 2)
+	pace, reminder := alerts[0], alerts[1]
+	year, month, _ := pace.AlertDate.Date()
+	assert.Equal(t, time.Date(year, month+1, 1, 0, 0, 0, 0, time.Local), *pace.ExpiresAt, "pace warnings go stale with their month")
+	year, month, day := deadline.Date()
+	assert.Equal(t, time.Date(year, month, day+1, 0, 0, 0, 0, time.Local), *reminder.ExpiresAt, "reminders go stale after the deadline")
+
+	assert.ErrorIs(t, svc.SnoozeAlert(ctx, userID, pace.ID, time.Now().Add(-time.Hour)), insights.ErrInvalidSnooze)
+	assert.ErrorIs(t, svc.SnoozeAlert(ctx, userID, pace.ID, time.Now().Add(insights.MaxAlertSnooze+time.Hour)), insights.ErrInvalidSnooze)
+	assert.ErrorIs(t, svc.SnoozeAlert(ctx, uuid.New(), pace.ID, time.Now().Add(time.Hour)), insights.ErrAlertNotFound)
+
+	require.NoError(t, svc.SnoozeAlert(ctx, userID, pace.ID, time.Now().Add(24*time.Hour)))
+	unread, err := svc.GetUnreadAlerts(ctx, userID, 10)
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, reminder.ID, unread[0].ID)
+
+	// Marking all read includes the snoozed alert
+	marked, err := svc.MarkAllAlertsRead(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	for _, a := range repo.GetAlerts() {
+		assert.True(t, a.IsRead)
+	}
+
+	result, err := svc.CleanupAlerts(ctx, time.Now().AddDate(0, 2, 0))
+	require.NoError(t, err)
+	assert.Equal(t, &insights.AlertCleanupResult{Expired: 2}, result)
+
+	result, err = svc.CleanupAlerts(ctx, time.Now().Add(insights.AlertRetention+24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Deleted)
+	assert.Empty(t, repo.GetAlerts())
+}
+
+func TestGetSpendingTrends_ZeroFillsAndCachesForTheDay(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	year, month, _ := time.Now().Date()
+	thisMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	foodID := uuid.New()
+	repo.monthlySpend = []insights.MonthlyCategorySpend{
+		{Month: lastMonth, CategoryID: &foodID, CategoryName: "Food", CurrencyCode: "EUR", TotalMinor: 30000},
+		{Month: thisMonth, CategoryID: &foodID, CategoryName: "Food", CurrencyCode: "EUR", TotalMinor: 12000},
+		{Month: thisMonth, CategoryName: "Uncategorized", CurrencyCode: "EUR", TotalMinor: 5000},
+		{Month: thisMonth.AddDate(0, -5, 0), CategoryName: "Travel", CurrencyCode: "EUR", TotalMinor: 90000},
+	}
+
+	trends, err := svc.GetSpendingTrends(ctx, userID, 3)
+	require.NoError(t, err)
+	require.Len(t, trends.Months, 3)
+	assert.Equal(t, thisMonth.Month(), trends.Months[2].Month())
+
+	// Travel is older than the window; Food spent more in total than Uncategorized
+	require.Len(t, trends.Series, 2)
+	assert.Equal(t, "Food", trends.Series[0].CategoryName)
+	assert.Equal(t, []int64{0, 30000, 12000}, trends.Series[0].MonthlyMinor)
+	assert.Equal(t, int64(42000), trends.Series[0].TotalMinor)
+	assert.Equal(t, []int64{0, 0, 5000}, trends.Series[1].MonthlyMinor)
+
+	// Served from the cache until the dashboard is invalidated
+	_, err = svc.GetSpendingTrends(ctx, userID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.monthlySpendCalls)
+
+	svc.InvalidateDashboard(ctx, userID)
+	_, err = svc.GetSpendingTrends(ctx, userID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.monthlySpendCalls)
+}
+
+func TestGetYearOverYear_ComparesMonthsAndCategories(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil)
+	groceries, dining := uuid.New(), uuid.New()
+	month := func(year int, m time.Month) time.Time { return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC) }
+	repo.rollups = []insights.MonthlyRollup{
+		{Month: month(2025, time.March), CategoryID: &groceries, CategoryName: "Groceries", CurrencyCode: "EUR", SumMinor: -40000},
+		{Month: month(2025, time.March), CategoryID: &dining, CategoryName: "Dining", CurrencyCode: "EUR", SumMinor: -5000},
+		{Month: month(2025, time.March), CurrencyCode: "EUR", IsIncome: true, SumMinor: 200000},
+		{Month: month(2024, time.March), CategoryID: &groceries, CategoryName: "Groceries", CurrencyCode: "EUR", SumMinor: -30000},
+		{Month: month(2024, time.March), CategoryID: &dining, CategoryName: "Dining", CurrencyCode: "EUR", SumMinor: -25000},
+		// Other currencies and years stay out
+		{Month: month(2025, time.March), CategoryName: "Travel", CurrencyCode: "USD", SumMinor: -1000},
+		{Month: month(2023, time.March), CategoryID: &dining, CategoryName: "Dining", CurrencyCode: "EUR", SumMinor: -99000},
+	}
+
+	yoy, err := svc.GetYearOverYear(context.Background(), uuid.New(), 2025, "")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", yoy.CurrencyCode, "the currency with the most spending")
+	assert.Equal(t, int64(45000), yoy.Current.SpendMinor)
+	assert.Equal(t, int64(55000), yoy.Previous.SpendMinor)
+	require.NotNil(t, yoy.Current.SavingsRate)
+	assert.InDelta(t, 0.775, *yoy.Current.SavingsRate, 0.0001)
+	assert.Nil(t, yoy.Previous.SavingsRate, "no income the previous year")
+
+	require.Len(t, yoy.Months, 12)
+	march := yoy.Months[time.March-1]
+	assert.Equal(t, int64(45000), march.Current.SpendMinor)
+	assert.Equal(t, int64(55000), march.Previous.SpendMinor)
+	assert.Zero(t, yoy.Months[time.April-1].Current.SpendMinor)
+
+	require.Len(t, yoy.Categories, 2)
+	assert.Equal(t, "Dining", yoy.Categories[0].CategoryName, "largest change first")
+	assert.Equal(t, int64(-20000), yoy.Categories[0].ChangeMinor)
+	require.NotNil(t, yoy.Categories[1].ChangePercent)
+	assert.InDelta(t, 33.33, *yoy.Categories[1].ChangePercent, 0.01)
+}
+
+// fakeNetWorth reports the same net worth for every user
+type fakeNetWorth int64
+
+func (f fakeNetWorth) NetWorth(ctx context.Context, userID uuid.UUID) (int64, string, error) {
+	return int64(f), "EUR", nil
+}
+
+func TestUpdateDashboardLayout_ValidatesBlocks(t *testing.T) {
+	svc := insights.NewService(NewMockInsightsRepo(), nil, nil, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	layout, err := svc.GetDashboardLayout(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, layout.IsDefault)
+	assert.Equal(t, insights.DefaultDashboardLayout(), layout.Blocks)
+
+	for name, blocks := range map[string][]insights.DashboardLayoutBlock{
+		"empty":        {},
+		"unknown type": {{Type: "stocks"}},
+		"duplicate":    {{Type: insights.DashboardBlockGoals}, {Type: insights.DashboardBlockGoals}},
+		"bad size":     {{Type: insights.DashboardBlockGoals, Size: "huge"}},
+	} {
+		_, err := svc.UpdateDashboardLayout(ctx, userID, blocks)
+		assert.ErrorIs(t, err, insights.ErrInvalidDashboardLayout, name)
+	}
+
+	layout, err = svc.UpdateDashboardLayout(ctx, userID, []insights.DashboardLayoutBlock{
+		{Type: insights.DashboardBlockNetWorth},
+		{Type: insights.DashboardBlockStatus, Size: insights.DashboardBlockSmall},
+	})
+	require.NoError(t, err)
+	assert.False(t, layout.IsDefault)
+	assert.Equal(t, insights.DashboardBlockMedium, layout.Blocks[0].Size, "size defaults to medium")
+}
+
+func TestDashboardBlocks_FollowSavedLayout(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	svc := insights.NewService(repo, nil, nil, nil).
+		WithCache(cache.New(cache.NewMemoryStore(), time.Minute, nil)).
+		WithNetWorth(fakeNetWorth(-2500000))
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+
+	// Cached blocks are dropped when the layout changes
+	_, err := svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	_, err = svc.UpdateDashboardLayout(ctx, userID, []insights.DashboardLayoutBlock{
+		{Type: insights.DashboardBlockNetWorth, Size: insights.DashboardBlockLarge},
+		{Type: insights.DashboardBlockHousehold}, // Not in a household, left out
+		{Type: insights.DashboardBlockStatus, Size: insights.DashboardBlockSmall},
+	})
+	require.NoError(t, err)
+
+	blocks, err := svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, insights.DashboardBlockNetWorth, blocks[0].Type)
+	assert.Equal(t, insights.DashboardBlockLarge, blocks[0].Size)
+	assert.Equal(t, "red", blocks[0].Color)
+	assert.Equal(t, insights.DashboardBlockStatus, blocks[1].Type)
+	assert.Equal(t, insights.DashboardBlockSmall, blocks[1].Size)
+}
+
+func TestDashboardBlocks_RegisteredProviders(t *testing.T) {
+	repo := NewMockInsightsRepo()
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	repo.monthlySpend = []insights.MonthlyCategorySpend{
+		{Month: thisMonth, CategoryName: "Food", CurrencyCode: "EUR", TotalMinor: 12000},
+		{Month: thisMonth, CategoryName: "Rent", CurrencyCode: "EUR", TotalMinor: 80000},
+	}
+	svc := insights.NewService(repo, nil, nil, nil).
+		RegisterDashboardBlock("upcoming_bills", insights.DashboardBlockProviderFunc(
+			func(ctx context.Context, req insights.DashboardBlockRequest) (*insights.DashboardBlock, error) {
+				return &insights.DashboardBlock{
+					Title:    "Bills",
+					Action:   &insights.DashboardAction{ID: "view_bills", Path: "/bills"},
+					Progress: &insights.DashboardProgress{CurrentMinor: 1, TargetMinor: 4, Ratio: 0.25},
+				}, nil
+			})).
+		RegisterDashboardBlock("broken", insights.DashboardBlockProviderFunc(
+			func(ctx context.Context, req insights.DashboardBlockRequest) (*insights.DashboardBlock, error) {
+				return nil, assert.AnError
+			}))
+	ctx := context.Background()
+	userID := uuid.New()
+
+	assert.Contains(t, svc.DashboardBlockTypes(), "upcoming_bills")
+	_, err := svc.UpdateDashboardLayout(ctx, userID, []insights.DashboardLayoutBlock{
+		{Type: "broken"},
+		{Type: "upcoming_bills"},
+		{Type: insights.DashboardBlockStatus},
+	})
+	require.NoError(t, err)
+
+	blocks, err := svc.GetDashboardBlocks(ctx, userID, now)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2, "a failing block is left out")
+
+	bills := blocks[0]
+	assert.Equal(t, "upcoming_bills", bills.Type)
+	require.NotNil(t, bills.Action)
+	assert.Equal(t, "echo://bills", bills.Action.DeepLink())
+	assert.InDelta(t, 0.25, bills.Progress.Ratio, 0.0001)
+
+	status := blocks[1]
+	require.NotNil(t, status.Sparkline)
+	assert.Equal(t, "EUR", status.Sparkline.CurrencyCode)
+	assert.Equal(t, int64(92000), status.Sparkline.Points[len(status.Sparkline.Points)-1])
+}
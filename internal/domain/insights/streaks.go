@@ -0,0 +1,195 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Budget Adherence Streaks (Internal Integration)
+// =============================================================================
+// Streaks count consecutive closed budget periods within budget, for the
+// user's active plan overall and per category. They feed a dashboard block, a
+// Wrapped card and streak-break alerts, but require proto definitions to be
+// exposed as an API endpoint.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetBudgetStreaksRequest/Response (InsightsService.GetBudgetStreaks)
+// - BudgetStreak, StreakBreak
+
+// BudgetStreak is a run of consecutive periods spent within budget
+type BudgetStreak struct {
+	CategoryName string // Empty for the plan as a whole
+	Current      int
+	Longest      int
+}
+
+// BudgetStreaks are the streaks of the user's active plan
+type BudgetStreaks struct {
+	PlanID     uuid.UUID
+	Overall    BudgetStreak
+	Categories []BudgetStreak
+}
+
+// StreakBreak is a streak that just ended, with the item that broke it
+type StreakBreak struct {
+	CategoryName   string // Empty when the plan's overall streak broke
+	PreviousStreak int
+	PeriodStart    time.Time
+	ItemID         uuid.UUID
+	ItemName       string
+	LimitMinor     int64
+	ActualMinor    int64
+}
+
+// StreakSource provides budget streaks for the user's active plan
+type StreakSource interface {
+	// GetBudgetStreaks returns nil when the user has no active plan
+	GetBudgetStreaks(ctx context.Context, userID uuid.UUID, asOf time.Time) (*BudgetStreaks, error)
+}
+
+// WithStreaks enables budget streaks on the dashboard and in Wrapped
+func (s *Service) WithStreaks(src StreakSource) *Service {
+	s.streaks = src
+	return s
+}
+
+// GetBudgetStreaks returns the user's budget streaks as of the given time, or
+// nil when streaks aren't configured or the user has no active plan
+func (s *Service) GetBudgetStreaks(ctx context.Context, userID uuid.UUID, asOf time.Time) (*BudgetStreaks, error) {
+	if s.streaks == nil {
+		return nil, nil
+	}
+	return s.streaks.GetBudgetStreaks(ctx, userID, asOf)
+}
+
+// TriggerStreakBrokenAlert creates an alert (at most one per day) naming the
+// item that ended each streak. The overall streak, when broken, comes first.
+func (s *Service) TriggerStreakBrokenAlert(ctx context.Context, userID, planID uuid.UUID, breaks []StreakBreak) error {
+	if len(breaks) == 0 {
+		return nil
+	}
+	setting := s.alertSetting(ctx, userID, AlertTypeStreakBroken)
+	if !setting.Enabled {
+		return nil
+	}
+
+	today := time.Now()
+	hasAlert, err := s.repo.HasAlertToday(ctx, userID, AlertTypeStreakBroken, today)
+	if err != nil || hasAlert {
+		return err
+	}
+
+	first := breaks[0]
+	severity := AlertSeverityInfo
+	title := fmt.Sprintf("Your %d-month budget streak ended", first.PreviousStreak)
+	if first.CategoryName != "" {
+		title = fmt.Sprintf("Your %d-month %s streak ended", first.PreviousStreak, first.CategoryName)
+	} else {
+		severity = AlertSeverityWarning
+	}
+
+	messages := make([]string, 0, len(breaks))
+	lines := make([]map[string]any, 0, len(breaks))
+	for _, b := range breaks {
+		messages = append(messages, fmt.Sprintf("%s went %s over budget in %s.", b.ItemName, formatMoney(b.ActualMinor-b.LimitMinor), b.PeriodStart.Format("January")))
+		lines = append(lines, map[string]any{
+			"category":        b.CategoryName,
+			"previous_streak": b.PreviousStreak,
+			"item_id":         b.ItemID.String(),
+			"item_name":       b.ItemName,
+			"limit_minor":     b.LimitMinor,
+			"actual_minor":    b.ActualMinor,
+			"period":          b.PeriodStart.Format("2006-01"),
+		})
+	}
+
+	referenceType := "plan"
+	alert := &Alert{
+		UserID:        userID,
+		AlertType:     AlertTypeStreakBroken,
+		Severity:      severity,
+		Title:         title,
+		Message:       strings.Join(messages, " "),
+		ReferenceType: &referenceType,
+		ReferenceID:   &planID,
+		Metadata: map[string]any{
+			"breaks": lines,
+		},
+		AlertDate: today,
+	}
+
+	if err := s.createAlert(ctx, alert); err != nil {
+		return err
+	}
+
+	s.deliverAlert(userID, alert, setting.Channels, map[string]any{
+		"alert_type": string(alert.AlertType),
+		"severity":   string(alert.Severity),
+		"plan_id":    planID.String(),
+		"item_id":    first.ItemID.String(),
+	})
+	return nil
+}
+
+// streakBlock shows the overall streak on the dashboard
+func (s *Service) streakBlock(ctx context.Context, req DashboardBlockRequest) (*DashboardBlock, error) {
+	streaks, err := s.GetBudgetStreaks(ctx, req.UserID, req.AsOf)
+	if err != nil || streaks == nil || streaks.Overall.Longest == 0 {
+		return nil, err
+	}
+
+	block := &DashboardBlock{
+		Type:     DashboardBlockStreak,
+		Title:    "Budget Streak",
+		Subtitle: fmt.Sprintf("Best: %s", streakLength(streaks.Overall.Longest)),
+		Value:    fmt.Sprintf("%d", streaks.Overall.Current),
+		Icon:     "flame",
+		Color:    "orange",
+		Action:   &DashboardAction{ID: "view_streaks", Path: "/plan/streaks"},
+		Progress: newDashboardProgress(int64(streaks.Overall.Current), int64(streaks.Overall.Longest)),
+	}
+	if streaks.Overall.Current == 0 {
+		block.Subtitle = "Stay within budget this month to start a new one"
+		block.Color = "gray"
+	}
+	return block, nil
+}
+
+// buildStreakCard celebrates the user's budget streak as of the period's end
+func (s *Service) buildStreakCard(ctx context.Context, userID uuid.UUID, end time.Time) (*WrappedCard, error) {
+	streaks, err := s.GetBudgetStreaks(ctx, userID, end)
+	if err != nil || streaks == nil || streaks.Overall.Longest == 0 {
+		return nil, err
+	}
+
+	card := &WrappedCard{
+		Title:    "Budget Streak",
+		Subtitle: fmt.Sprintf("%s within budget in a row", streakLength(streaks.Overall.Current)),
+		Body:     fmt.Sprintf("Your best run is %s.", streakLength(streaks.Overall.Longest)),
+		Accent:   "#F97316", // Orange
+	}
+
+	var best *BudgetStreak
+	for i, c := range streaks.Categories {
+		if c.Current > 0 && (best == nil || c.Current > best.Current) {
+			best = &streaks.Categories[i]
+		}
+	}
+	if best != nil {
+		card.Body += fmt.Sprintf(" %s has stayed on budget for %s.", best.CategoryName, streakLength(best.Current))
+	}
+	return card, nil
+}
+
+// streakLength formats a streak of monthly periods
+func streakLength(months int) string {
+	if months == 1 {
+		return "1 month"
+	}
+	return fmt.Sprintf("%d months", months)
+}
@@ -0,0 +1,169 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Spending Trends (Internal Integration)
+// =============================================================================
+// Monthly spending per category over the last N months, shaped for stacked
+// area and bar charts: every series has one value per month, zero-filled.
+// Trends are cached per user for the day; imports clear the user's entries
+// along with their dashboard.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetSpendingTrendsRequest/Response (InsightsService.GetSpendingTrends)
+//   with int32 months
+// - SpendingTrendSeries (category_id, category_name, currency_code,
+//   repeated int64 monthly_minor, total_minor) and repeated month timestamps
+
+const (
+	// DefaultTrendMonths is how many months trends cover when not specified
+	DefaultTrendMonths = 6
+	// MaxTrendMonths is the longest span trends can cover
+	MaxTrendMonths = 24
+)
+
+// SpendingTrends is spending per category for consecutive months, the
+// current one last
+type SpendingTrends struct {
+	Months []time.Time // First day of each month
+	Series []SpendingTrendSeries
+}
+
+// SpendingTrendSeries is one category's spending in one currency, aligned
+// with SpendingTrends.Months
+type SpendingTrendSeries struct {
+	CategoryID   *uuid.UUID
+	CategoryName string
+	CurrencyCode string
+	MonthlyMinor []int64
+	TotalMinor   int64
+}
+
+type trendsKey struct {
+	userID uuid.UUID
+	months int
+}
+
+// trendsCache holds the day's computed trends; it's reset when the day changes
+type trendsCache struct {
+	mu      sync.Mutex
+	day     string
+	entries map[trendsKey]*SpendingTrends
+}
+
+func (c *trendsCache) get(day string, key trendsKey) (*SpendingTrends, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.day != day {
+		return nil, false
+	}
+	trends, ok := c.entries[key]
+	return trends, ok
+}
+
+func (c *trendsCache) put(day string, key trendsKey, trends *SpendingTrends) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.day != day || c.entries == nil {
+		c.day = day
+		c.entries = make(map[trendsKey]*SpendingTrends)
+	}
+	c.entries[key] = trends
+}
+
+func (c *trendsCache) invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.userID == userID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// GetSpendingTrends returns the user's monthly spending per category for the
+// last months months, including the current one. Series are ordered by total,
+// largest first.
+func (s *Service) GetSpendingTrends(ctx context.Context, userID uuid.UUID, months int) (*SpendingTrends, error) {
+	if months <= 0 {
+		months = DefaultTrendMonths
+	}
+	if months > MaxTrendMonths {
+		months = MaxTrendMonths
+	}
+
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	key := trendsKey{userID: userID, months: months}
+	if trends, ok := s.trends.get(day, key); ok {
+		return trends, nil
+	}
+
+	year, month, _ := now.Date()
+	from := time.Date(year, month-time.Month(months-1), 1, 0, 0, 0, 0, now.Location())
+	to := time.Date(year, month+1, 1, 0, 0, 0, 0, now.Location())
+	spend, err := s.repo.GetMonthlyCategorySpend(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly category spend: %w", err)
+	}
+
+	trends := buildSpendingTrends(from, months, spend)
+	s.trends.put(day, key, trends)
+	return trends, nil
+}
+
+// buildSpendingTrends lays out spend as one zero-filled series per category
+// and currency over months months from start
+func buildSpendingTrends(start time.Time, months int, spend []MonthlyCategorySpend) *SpendingTrends {
+	trends := &SpendingTrends{Months: make([]time.Time, months), Series: []SpendingTrendSeries{}}
+	index := make(map[[2]int]int, months)
+	for i := range trends.Months {
+		m := start.AddDate(0, i, 0)
+		trends.Months[i] = m
+		index[[2]int{m.Year(), int(m.Month())}] = i
+	}
+
+	type seriesKey struct {
+		categoryID   uuid.UUID
+		categoryName string
+		currency     string
+	}
+	seriesIndex := make(map[seriesKey]int)
+	for _, row := range spend {
+		i, ok := index[[2]int{row.Month.Year(), int(row.Month.Month())}]
+		if !ok {
+			continue
+		}
+		key := seriesKey{categoryName: row.CategoryName, currency: row.CurrencyCode}
+		if row.CategoryID != nil {
+			key.categoryID = *row.CategoryID
+		}
+		n, ok := seriesIndex[key]
+		if !ok {
+			n = len(trends.Series)
+			seriesIndex[key] = n
+			trends.Series = append(trends.Series, SpendingTrendSeries{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+				CurrencyCode: row.CurrencyCode,
+				MonthlyMinor: make([]int64, months),
+			})
+		}
+		trends.Series[n].MonthlyMinor[i] += row.TotalMinor
+		trends.Series[n].TotalMinor += row.TotalMinor
+	}
+
+	sort.SliceStable(trends.Series, func(a, b int) bool {
+		return trends.Series[a].TotalMinor > trends.Series[b].TotalMinor
+	})
+	return trends
+}
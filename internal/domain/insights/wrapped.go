@@ -59,6 +59,11 @@ func (s *Service) GetWrapped(ctx context.Context, userID uuid.UUID, period strin
 		summary.Cards = append(summary.Cards, *card)
 	}
 
+	// 5. Budget Streak Card
+	if card, err := s.buildStreakCard(ctx, userID, periodEnd); err == nil && card != nil {
+		summary.Cards = append(summary.Cards, *card)
+	}
+
 	return summary, nil
 }
 
@@ -68,6 +73,7 @@ func (s *Service) buildTopMerchantCard(ctx context.Context, userID uuid.UUID, st
 		SELECT COALESCE(merchant_name, description) as merchant, COUNT(*) as visits
 		FROM transactions
 		WHERE user_id = $1
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
 		  AND posted_at < $3
 		  AND amount_minor < 0
@@ -108,6 +114,7 @@ func (s *Service) buildCategoryChangeCard(ctx context.Context, userID uuid.UUID,
 			FROM transactions t
 			LEFT JOIN categories c ON c.id = t.category_id
 			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
 			  AND t.posted_at >= $2
 			  AND t.posted_at < $3
 			  AND t.amount_minor < 0
@@ -118,6 +125,7 @@ func (s *Service) buildCategoryChangeCard(ctx context.Context, userID uuid.UUID,
 			FROM transactions t
 			LEFT JOIN categories c ON c.id = t.category_id
 			WHERE t.user_id = $1
+			  AND t.deleted_at IS NULL
 			  AND t.posted_at >= $4
 			  AND t.posted_at < $5
 			  AND t.amount_minor < 0
@@ -175,6 +183,7 @@ func (s *Service) buildNetWorthCard(ctx context.Context, userID uuid.UUID, start
 		SELECT COALESCE(SUM(amount_minor), 0)
 		FROM transactions
 		WHERE user_id = $1
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
 		  AND posted_at < $3
 	`
@@ -207,6 +216,7 @@ func (s *Service) buildTransactionCountCard(ctx context.Context, userID uuid.UUI
 		SELECT COUNT(*)
 		FROM transactions
 		WHERE user_id = $1
+		  AND deleted_at IS NULL
 		  AND posted_at >= $2
 		  AND posted_at < $3
 	`
@@ -0,0 +1,226 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/db"
+)
+
+// =============================================================================
+// Year-over-Year Comparison (Internal Integration)
+// =============================================================================
+// Compares a year with the one before it, month by month and per category.
+// Reads the transaction_monthly_rollups view rather than transactions, so it
+// is as fresh as the last rollup refresh.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetYearOverYearRequest/Response (InsightsService.GetYearOverYear) with
+//   int32 year and optional currency_code
+// - YearOverYearTotals (spend_minor, income_minor, optional savings_rate)
+// - YearOverYearMonth (int32 month, current and previous YearOverYearTotals)
+// - YearOverYearCategory (category_id, category_name, current_minor,
+//   previous_minor, change_minor, optional change_percent)
+
+// MonthlyRollup is one row of the monthly rollups: a month's spending or
+// income in one category and currency
+type MonthlyRollup struct {
+	Month        time.Time
+	CategoryID   *uuid.UUID
+	CategoryName string
+	CurrencyCode string
+	IsIncome     bool
+	SumMinor     int64 // Negative for spending
+}
+
+// YearOverYearTotals is spending and income over a month or a year
+type YearOverYearTotals struct {
+	SpendMinor  int64
+	IncomeMinor int64
+	SavingsRate *float64 // Share of income not spent; nil without income
+}
+
+// YearOverYearMonth is one calendar month of both years
+type YearOverYearMonth struct {
+	Month    time.Month
+	Current  YearOverYearTotals
+	Previous YearOverYearTotals
+}
+
+// YearOverYearCategory is a category's spending in both years
+type YearOverYearCategory struct {
+	CategoryID    *uuid.UUID
+	CategoryName  string
+	CurrentMinor  int64
+	PreviousMinor int64
+	ChangeMinor   int64
+	ChangePercent *float64 // nil when nothing was spent the previous year
+}
+
+// YearOverYear compares a year with the previous one in one currency
+type YearOverYear struct {
+	Year         int
+	CurrencyCode string
+	Current      YearOverYearTotals
+	Previous     YearOverYearTotals
+	Months       []YearOverYearMonth    // January to December
+	Categories   []YearOverYearCategory // Largest change first
+}
+
+// GetMonthlyRollups returns the user's rollups for months in [from, to)
+func (r *Repository) GetMonthlyRollups(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]MonthlyRollup, error) {
+	ctx, cancel := db.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.read.Query(ctx, `
+		SELECT r.month, r.category_id, COALESCE(c.name::TEXT, 'Uncategorized'),
+		       r.currency_code, r.is_income, SUM(r.sum_minor)::BIGINT
+		FROM transaction_monthly_rollups r
+		LEFT JOIN categories c ON c.id = r.category_id
+		WHERE r.user_id = $1
+		  AND r.month >= $2
+		  AND r.month < $3
+		GROUP BY r.month, r.category_id, c.name, r.currency_code, r.is_income
+	`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []MonthlyRollup
+	for rows.Next() {
+		var m MonthlyRollup
+		if err := rows.Scan(&m.Month, &m.CategoryID, &m.CategoryName, &m.CurrencyCode, &m.IsIncome, &m.SumMinor); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, m)
+	}
+	return rollups, rows.Err()
+}
+
+// GetYearOverYear compares the user's year with the previous one. Amounts in
+// different currencies can't be added, so only currencyCode is compared; when
+// empty, the currency the user spent the most in.
+func (s *Service) GetYearOverYear(ctx context.Context, userID uuid.UUID, year int, currencyCode string) (*YearOverYear, error) {
+	from := time.Date(year-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rollups, err := s.repo.GetMonthlyRollups(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly rollups: %w", err)
+	}
+	if currencyCode == "" {
+		currencyCode = mainSpendingCurrency(rollups)
+	}
+	return buildYearOverYear(year, currencyCode, rollups), nil
+}
+
+// mainSpendingCurrency returns the currency with the most spending
+func mainSpendingCurrency(rollups []MonthlyRollup) string {
+	spend := make(map[string]int64)
+	for _, r := range rollups {
+		if !r.IsIncome {
+			spend[r.CurrencyCode] -= r.SumMinor
+		}
+	}
+	var best string
+	for currency, total := range spend {
+		if best == "" || total > spend[best] || (total == spend[best] && currency < best) {
+			best = currency
+		}
+	}
+	return best
+}
+
+// buildYearOverYear lays out the rollups in currencyCode as a comparison of
+// year with the year before
+func buildYearOverYear(year int, currencyCode string, rollups []MonthlyRollup) *YearOverYear {
+	yoy := &YearOverYear{Year: year, CurrencyCode: currencyCode, Months: make([]YearOverYearMonth, 12), Categories: []YearOverYearCategory{}}
+	for i := range yoy.Months {
+		yoy.Months[i].Month = time.Month(i + 1)
+	}
+
+	type categoryKey struct {
+		id   uuid.UUID
+		name string
+	}
+	categoryIndex := make(map[categoryKey]int)
+	for _, r := range rollups {
+		if r.CurrencyCode != currencyCode {
+			continue
+		}
+		var totals, monthTotals *YearOverYearTotals
+		month := &yoy.Months[r.Month.Month()-1]
+		switch r.Month.Year() {
+		case year:
+			totals, monthTotals = &yoy.Current, &month.Current
+		case year - 1:
+			totals, monthTotals = &yoy.Previous, &month.Previous
+		default:
+			continue
+		}
+
+		if r.IsIncome {
+			totals.IncomeMinor += r.SumMinor
+			monthTotals.IncomeMinor += r.SumMinor
+			continue
+		}
+		spend := -r.SumMinor
+		totals.SpendMinor += spend
+		monthTotals.SpendMinor += spend
+
+		key := categoryKey{name: r.CategoryName}
+		if r.CategoryID != nil {
+			key.id = *r.CategoryID
+		}
+		n, ok := categoryIndex[key]
+		if !ok {
+			n = len(yoy.Categories)
+			categoryIndex[key] = n
+			yoy.Categories = append(yoy.Categories, YearOverYearCategory{CategoryID: r.CategoryID, CategoryName: r.CategoryName})
+		}
+		if r.Month.Year() == year {
+			yoy.Categories[n].CurrentMinor += spend
+		} else {
+			yoy.Categories[n].PreviousMinor += spend
+		}
+	}
+
+	yoy.Current.SavingsRate = savingsRate(yoy.Current)
+	yoy.Previous.SavingsRate = savingsRate(yoy.Previous)
+	for i := range yoy.Months {
+		yoy.Months[i].Current.SavingsRate = savingsRate(yoy.Months[i].Current)
+		yoy.Months[i].Previous.SavingsRate = savingsRate(yoy.Months[i].Previous)
+	}
+	for i := range yoy.Categories {
+		c := &yoy.Categories[i]
+		c.ChangeMinor = c.CurrentMinor - c.PreviousMinor
+		if c.PreviousMinor > 0 {
+			pct := float64(c.ChangeMinor) / float64(c.PreviousMinor) * 100
+			c.ChangePercent = &pct
+		}
+	}
+	sort.SliceStable(yoy.Categories, func(a, b int) bool {
+		return abs64(yoy.Categories[a].ChangeMinor) > abs64(yoy.Categories[b].ChangeMinor)
+	})
+	return yoy
+}
+
+// savingsRate returns the share of income not spent, or nil without income
+func savingsRate(t YearOverYearTotals) *float64 {
+	if t.IncomeMinor <= 0 {
+		return nil
+	}
+	rate := float64(t.IncomeMinor-t.SpendMinor) / float64(t.IncomeMinor)
+	return &rate
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
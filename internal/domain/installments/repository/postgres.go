@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresInstallmentRepository implements InstallmentRepository using PostgreSQL
+type PostgresInstallmentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresInstallmentRepository creates a new PostgreSQL installment repository
+func NewPostgresInstallmentRepository(pool *pgxpool.Pool) *PostgresInstallmentRepository {
+	return &PostgresInstallmentRepository{pool: pool}
+}
+
+const planColumns = `id, user_id, transaction_id, category_id, merchant_name, total_minor, currency_code,
+	installment_count, cadence, first_due_at, status, completed_at, created_at, updated_at`
+
+func scanPlan(row pgx.Row) (*InstallmentPlan, error) {
+	p := &InstallmentPlan{}
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.TransactionID, &p.CategoryID, &p.MerchantName, &p.TotalMinor, &p.CurrencyCode,
+		&p.InstallmentCount, &p.Cadence, &p.FirstDueAt, &p.Status, &p.CompletedAt, &p.CreatedAt, &p.UpdatedAt,
+	)
+	return p, err
+}
+
+// CreatePlan inserts a plan and its charges in one transaction
+func (r *PostgresInstallmentRepository) CreatePlan(ctx context.Context, plan *InstallmentPlan) error {
+	if plan.ID == uuid.Nil {
+		plan.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO installment_plans (id, user_id, transaction_id, category_id, merchant_name, total_minor,
+			currency_code, installment_count, cadence, first_due_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING created_at, updated_at`,
+		plan.ID, plan.UserID, plan.TransactionID, plan.CategoryID, plan.MerchantName, plan.TotalMinor,
+		plan.CurrencyCode, plan.InstallmentCount, plan.Cadence, plan.FirstDueAt, plan.Status,
+	).Scan(&plan.CreatedAt, &plan.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrTransactionAlreadySplit
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create installment plan: %w", err)
+	}
+
+	for _, c := range plan.Charges {
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+		c.PlanID = plan.ID
+		_, err := tx.Exec(ctx, `
+			INSERT INTO installment_charges (id, plan_id, sequence, due_at, amount_minor)
+			VALUES ($1, $2, $3, $4, $5)`,
+			c.ID, c.PlanID, c.Sequence, c.DueAt, c.AmountMinor,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create installment charge: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit installment plan: %w", err)
+	}
+	return nil
+}
+
+// GetPlan retrieves a plan with its charges
+func (r *PostgresInstallmentRepository) GetPlan(ctx context.Context, id uuid.UUID) (*InstallmentPlan, error) {
+	plan, err := scanPlan(r.pool.QueryRow(ctx, `SELECT `+planColumns+` FROM installment_plans WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment plan: %w", err)
+	}
+
+	charges, err := r.getCharges(ctx, []uuid.UUID{plan.ID})
+	if err != nil {
+		return nil, err
+	}
+	plan.Charges = charges[plan.ID]
+	return plan, nil
+}
+
+// ListPlans lists a user's plans, newest first
+func (r *PostgresInstallmentRepository) ListPlans(ctx context.Context, userID uuid.UUID, status *PlanStatus) ([]*InstallmentPlan, error) {
+	query := `SELECT ` + planColumns + ` FROM installment_plans WHERE user_id = $1`
+	args := []interface{}{userID}
+	if status != nil {
+		query += ` AND status = $2`
+		args = append(args, *status)
+	}
+	query += ` ORDER BY first_due_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installment plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*InstallmentPlan
+	var ids []uuid.UUID
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan installment plan: %w", err)
+		}
+		plans = append(plans, plan)
+		ids = append(ids, plan.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating installment plans: %w", err)
+	}
+
+	charges, err := r.getCharges(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, plan := range plans {
+		plan.Charges = charges[plan.ID]
+	}
+	return plans, nil
+}
+
+// getCharges loads charges for the given plans keyed by plan ID
+func (r *PostgresInstallmentRepository) getCharges(ctx context.Context, planIDs []uuid.UUID) (map[uuid.UUID][]*InstallmentCharge, error) {
+	result := make(map[uuid.UUID][]*InstallmentCharge, len(planIDs))
+	if len(planIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, plan_id, sequence, due_at, amount_minor, transaction_id, paid_at
+		FROM installment_charges
+		WHERE plan_id = ANY($1)
+		ORDER BY plan_id, sequence`, planIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installment charges: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &InstallmentCharge{}
+		if err := rows.Scan(&c.ID, &c.PlanID, &c.Sequence, &c.DueAt, &c.AmountMinor, &c.TransactionID, &c.PaidAt); err != nil {
+			return nil, fmt.Errorf("failed to scan installment charge: %w", err)
+		}
+		result[c.PlanID] = append(result[c.PlanID], c)
+	}
+	return result, rows.Err()
+}
+
+// UpdateStatus changes a plan's status
+func (r *PostgresInstallmentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status PlanStatus) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE installment_plans
+		SET status = $2,
+		    completed_at = CASE WHEN $2 = 'completed' THEN COALESCE(completed_at, NOW()) ELSE NULL END
+		WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update installment plan status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetPurchase loads one of the user's expense transactions
+func (r *PostgresInstallmentRepository) GetPurchase(ctx context.Context, userID, transactionID uuid.UUID) (*ChargeCandidate, error) {
+	c := &ChargeCandidate{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, category_id, COALESCE(merchant_name, ''), description, ABS(amount_minor), currency_code, posted_at
+		FROM transactions
+		WHERE id = $1 AND user_id = $2 AND amount_minor < 0 AND deleted_at IS NULL`, transactionID, userID,
+	).Scan(&c.TransactionID, &c.CategoryID, &c.MerchantName, &c.Description, &c.AmountMinor, &c.CurrencyCode, &c.PostedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase transaction: %w", err)
+	}
+	return c, nil
+}
+
+// ListPendingCharges lists unpaid charges of a user's active plans in due order
+func (r *PostgresInstallmentRepository) ListPendingCharges(ctx context.Context, userID uuid.UUID) ([]*PendingCharge, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT ic.id, ic.plan_id, ic.sequence, ic.due_at, ic.amount_minor,
+		       ip.user_id, ip.merchant_name, ip.currency_code
+		FROM installment_charges ic
+		JOIN installment_plans ip ON ip.id = ic.plan_id
+		WHERE ip.user_id = $1
+		  AND ip.status = 'active'
+		  AND ic.transaction_id IS NULL
+		ORDER BY ic.due_at, ic.sequence`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending installment charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []*PendingCharge
+	for rows.Next() {
+		c := &PendingCharge{}
+		if err := rows.Scan(&c.ID, &c.PlanID, &c.Sequence, &c.DueAt, &c.AmountMinor,
+			&c.UserID, &c.MerchantName, &c.CurrencyCode); err != nil {
+			return nil, fmt.Errorf("failed to scan pending installment charge: %w", err)
+		}
+		charges = append(charges, c)
+	}
+	return charges, rows.Err()
+}
+
+// ListChargeCandidates lists expense transactions since a date that aren't
+// already a plan's purchase or a matched charge
+func (r *PostgresInstallmentRepository) ListChargeCandidates(ctx context.Context, userID uuid.UUID, since time.Time) ([]*ChargeCandidate, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT t.id, t.category_id, COALESCE(t.merchant_name, ''), t.description, ABS(t.amount_minor), t.currency_code, t.posted_at
+		FROM transactions t
+		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
+		  AND t.posted_at >= $2
+		  AND t.amount_minor < 0
+		  AND NOT EXISTS (SELECT 1 FROM installment_charges ic WHERE ic.transaction_id = t.id)
+		  AND NOT EXISTS (SELECT 1 FROM installment_plans ip WHERE ip.transaction_id = t.id)
+		ORDER BY t.posted_at`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installment charge candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*ChargeCandidate
+	for rows.Next() {
+		c := &ChargeCandidate{}
+		if err := rows.Scan(&c.TransactionID, &c.CategoryID, &c.MerchantName, &c.Description,
+			&c.AmountMinor, &c.CurrencyCode, &c.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan installment charge candidate: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// MarkChargePaid records the transaction that paid a charge
+func (r *PostgresInstallmentRepository) MarkChargePaid(ctx context.Context, chargeID, transactionID uuid.UUID, paidAt time.Time) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE installment_charges
+		SET transaction_id = $2, paid_at = $3
+		WHERE id = $1 AND transaction_id IS NULL`, chargeID, transactionID, paidAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark installment charge paid: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CompleteIfPaid marks an active plan completed once every charge is paid
+func (r *PostgresInstallmentRepository) CompleteIfPaid(ctx context.Context, planID uuid.UUID) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE installment_plans ip
+		SET status = 'completed', completed_at = NOW()
+		WHERE ip.id = $1
+		  AND ip.status = 'active'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM installment_charges ic
+		      WHERE ic.plan_id = ip.id AND ic.transaction_id IS NULL
+		  )`, planID)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete installment plan: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ListUsersWithPendingCharges lists users that have active plans with unpaid charges
+func (r *PostgresInstallmentRepository) ListUsersWithPendingCharges(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ip.user_id
+		FROM installment_plans ip
+		JOIN installment_charges ic ON ic.plan_id = ip.id
+		WHERE ip.status = 'active' AND ic.transaction_id IS NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users with pending installments: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// ListUpcomingCharges lists unpaid charges of active plans due within [from, to)
+func (r *PostgresInstallmentRepository) ListUpcomingCharges(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*UpcomingCharge, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT ip.id, ip.merchant_name, ic.sequence, ip.installment_count, ic.due_at, ic.amount_minor, ip.currency_code
+		FROM installment_charges ic
+		JOIN installment_plans ip ON ip.id = ic.plan_id
+		WHERE ip.user_id = $1
+		  AND ip.status = 'active'
+		  AND ic.transaction_id IS NULL
+		  AND ic.due_at >= $2
+		  AND ic.due_at < $3
+		ORDER BY ic.due_at, ip.merchant_name`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upcoming installment charges: %w", err)
+	}
+	defer rows.Close()
+
+	var charges []*UpcomingCharge
+	for rows.Next() {
+		c := &UpcomingCharge{}
+		if err := rows.Scan(&c.PlanID, &c.MerchantName, &c.Sequence, &c.Count, &c.DueAt, &c.AmountMinor, &c.CurrencyCode); err != nil {
+			return nil, fmt.Errorf("failed to scan upcoming installment charge: %w", err)
+		}
+		charges = append(charges, c)
+	}
+	return charges, rows.Err()
+}
@@ -0,0 +1,111 @@
+// Package repository provides database operations for installment purchases.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTransactionAlreadySplit is returned when a purchase already has a non-canceled plan
+var ErrTransactionAlreadySplit = errors.New("transaction already has an installment plan")
+
+// PlanStatus represents the lifecycle of an installment plan
+type PlanStatus string
+
+const (
+	PlanStatusActive    PlanStatus = "active"
+	PlanStatusCompleted PlanStatus = "completed"
+	PlanStatusCanceled  PlanStatus = "canceled"
+)
+
+// InstallmentPlan is a purchase paid off in scheduled charges (e.g. pay in 3)
+type InstallmentPlan struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	TransactionID    *uuid.UUID // Original purchase, if recorded
+	CategoryID       *uuid.UUID
+	MerchantName     string
+	TotalMinor       int64
+	CurrencyCode     string
+	InstallmentCount int
+	Cadence          string // recurring_cadence: weekly, monthly, quarterly, annual
+	FirstDueAt       time.Time
+	Status           PlanStatus
+	CompletedAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Charges          []*InstallmentCharge
+}
+
+// InstallmentCharge is one scheduled payment of a plan
+type InstallmentCharge struct {
+	ID            uuid.UUID
+	PlanID        uuid.UUID
+	Sequence      int
+	DueAt         time.Time
+	AmountMinor   int64
+	TransactionID *uuid.UUID // Matched charge transaction once it arrives
+	PaidAt        *time.Time
+}
+
+// IsPaid reports whether the charge has been matched to a transaction
+func (c *InstallmentCharge) IsPaid() bool {
+	return c.TransactionID != nil
+}
+
+// PendingCharge is an unpaid charge with the plan details needed for matching
+type PendingCharge struct {
+	InstallmentCharge
+	UserID       uuid.UUID
+	MerchantName string
+	CurrencyCode string
+}
+
+// ChargeCandidate is an expense transaction that may pay an installment
+// (or, for GetPurchase, the purchase being split)
+type ChargeCandidate struct {
+	TransactionID uuid.UUID
+	CategoryID    *uuid.UUID
+	MerchantName  string
+	Description   string
+	AmountMinor   int64 // Absolute value
+	CurrencyCode  string
+	PostedAt      time.Time
+}
+
+// UpcomingCharge is a scheduled, unpaid charge used for cash-flow forecasts
+type UpcomingCharge struct {
+	PlanID       uuid.UUID
+	MerchantName string
+	Sequence     int
+	Count        int
+	DueAt        time.Time
+	ExpectedAt   time.Time // DueAt moved to the business day the charge should post on
+	AmountMinor  int64
+	CurrencyCode string
+}
+
+// InstallmentRepository defines the interface for installment persistence
+type InstallmentRepository interface {
+	// CreatePlan inserts a plan together with its charge schedule
+	CreatePlan(ctx context.Context, plan *InstallmentPlan) error
+	GetPlan(ctx context.Context, id uuid.UUID) (*InstallmentPlan, error)
+	ListPlans(ctx context.Context, userID uuid.UUID, status *PlanStatus) ([]*InstallmentPlan, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status PlanStatus) error
+
+	// GetPurchase loads an expense transaction to convert into a plan
+	GetPurchase(ctx context.Context, userID, transactionID uuid.UUID) (*ChargeCandidate, error)
+
+	// Matching
+	ListPendingCharges(ctx context.Context, userID uuid.UUID) ([]*PendingCharge, error)
+	ListChargeCandidates(ctx context.Context, userID uuid.UUID, since time.Time) ([]*ChargeCandidate, error)
+	MarkChargePaid(ctx context.Context, chargeID, transactionID uuid.UUID, paidAt time.Time) error
+	CompleteIfPaid(ctx context.Context, planID uuid.UUID) (bool, error)
+	ListUsersWithPendingCharges(ctx context.Context) ([]uuid.UUID, error)
+
+	// Forecasting
+	ListUpcomingCharges(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*UpcomingCharge, error)
+}
@@ -0,0 +1,356 @@
+// Package service provides business logic for installment purchases.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/installments/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+)
+
+const (
+	// matchWindowBefore/After bound how far from its due date a charge may post
+	matchWindowBefore = 7 * 24 * time.Hour
+	matchWindowAfter  = 14 * 24 * time.Hour
+	// matchAmountTolerance allows small rounding/fee differences (1%)
+	matchAmountTolerance = 0.01
+)
+
+// ErrInvalidSchedule is returned for an unusable installment count, cadence or amount
+var ErrInvalidSchedule = errors.New("installments require a positive total, 2-120 charges and a weekly, monthly, quarterly or annual cadence")
+
+// CreatePlanInput describes an installment purchase
+type CreatePlanInput struct {
+	TransactionID    *uuid.UUID // Optional: split an existing purchase transaction
+	CategoryID       *uuid.UUID
+	MerchantName     string
+	TotalMinor       int64
+	CurrencyCode     string
+	InstallmentCount int
+	Cadence          string     // Defaults to monthly
+	FirstDueAt       *time.Time // Defaults to the purchase date (or today)
+}
+
+// MatchResult summarizes a charge matching run
+type MatchResult struct {
+	Matched   int
+	Completed int
+}
+
+// Service provides installment management business logic
+type Service struct {
+	repo      repository.InstallmentRepository
+	calendars *calendar.Resolver
+}
+
+// NewService creates a new installments service
+func NewService(repo repository.InstallmentRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// WithCalendars sets the resolver used to predict which business day upcoming
+// charges post on. Without it only weekends are skipped.
+func (s *Service) WithCalendars(calendars *calendar.Resolver) *Service {
+	s.calendars = calendars
+	return s
+}
+
+// CreatePlan records an installment purchase and its charge schedule. When a
+// transaction is given, its amount, merchant and category are used and it stops
+// counting as a single expense in budgets.
+func (s *Service) CreatePlan(ctx context.Context, userID uuid.UUID, input CreatePlanInput) (*repository.InstallmentPlan, error) {
+	if input.Cadence == "" {
+		input.Cadence = "monthly"
+	}
+
+	firstDue := time.Now()
+	if input.TransactionID != nil {
+		purchase, err := s.repo.GetPurchase(ctx, userID, *input.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if input.TotalMinor == 0 {
+			input.TotalMinor = purchase.AmountMinor
+		}
+		if input.MerchantName == "" {
+			input.MerchantName = purchase.MerchantName
+		}
+		if input.MerchantName == "" {
+			input.MerchantName = purchase.Description
+		}
+		if input.CurrencyCode == "" {
+			input.CurrencyCode = purchase.CurrencyCode
+		}
+		if input.CategoryID == nil {
+			input.CategoryID = purchase.CategoryID
+		}
+		firstDue = purchase.PostedAt
+	}
+	if input.FirstDueAt != nil {
+		firstDue = *input.FirstDueAt
+	}
+	if input.CurrencyCode == "" {
+		input.CurrencyCode = "EUR"
+	}
+
+	charges, err := BuildSchedule(input.TotalMinor, input.InstallmentCount, input.Cadence, firstDue)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &repository.InstallmentPlan{
+		UserID:           userID,
+		TransactionID:    input.TransactionID,
+		CategoryID:       input.CategoryID,
+		MerchantName:     strings.TrimSpace(input.MerchantName),
+		TotalMinor:       input.TotalMinor,
+		CurrencyCode:     input.CurrencyCode,
+		InstallmentCount: input.InstallmentCount,
+		Cadence:          input.Cadence,
+		FirstDueAt:       charges[0].DueAt,
+		Status:           repository.PlanStatusActive,
+		Charges:          charges,
+	}
+	if err := s.repo.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// GetPlan retrieves a plan owned by the user (nil if missing or not owned)
+func (s *Service) GetPlan(ctx context.Context, userID, planID uuid.UUID) (*repository.InstallmentPlan, error) {
+	plan, err := s.repo.GetPlan(ctx, planID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if plan.UserID != userID {
+		return nil, nil
+	}
+	return plan, nil
+}
+
+// ListPlans lists a user's installment plans
+func (s *Service) ListPlans(ctx context.Context, userID uuid.UUID, status *repository.PlanStatus) ([]*repository.InstallmentPlan, error) {
+	return s.repo.ListPlans(ctx, userID, status)
+}
+
+// CancelPlan cancels a plan; its purchase counts as a single expense again
+func (s *Service) CancelPlan(ctx context.Context, userID, planID uuid.UUID) (*repository.InstallmentPlan, error) {
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateStatus(ctx, planID, repository.PlanStatusCanceled); err != nil {
+		return nil, err
+	}
+	plan.Status = repository.PlanStatusCanceled
+	return plan, nil
+}
+
+// UpcomingCharges lists unpaid charges due in the next `days` days, for cash-flow forecasts.
+// Recently overdue charges that may still post are included.
+func (s *Service) UpcomingCharges(ctx context.Context, userID uuid.UUID, days int) ([]*repository.UpcomingCharge, error) {
+	if days <= 0 {
+		days = 30
+	}
+	now := time.Now()
+	from := now.Add(-matchWindowAfter)
+	charges, err := s.repo.ListUpcomingCharges(ctx, userID, from, now.AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := s.calendars.ForUser(ctx, userID)
+	for _, c := range charges {
+		c.ExpectedAt = cal.Adjust(c.DueAt, calendar.Following)
+	}
+	return charges, nil
+}
+
+// MatchCharges links newly arrived transactions to the user's pending charges
+// and completes plans whose final charge has arrived.
+func (s *Service) MatchCharges(ctx context.Context, userID uuid.UUID) (*MatchResult, error) {
+	pending, err := s.repo.ListPendingCharges(ctx, userID)
+	if err != nil || len(pending) == 0 {
+		return &MatchResult{}, err
+	}
+
+	candidates, err := s.repo.ListChargeCandidates(ctx, userID, pending[0].DueAt.Add(-matchWindowBefore))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MatchResult{}
+	used := make(map[uuid.UUID]bool)
+	touched := make(map[uuid.UUID]bool)
+	for _, charge := range pending {
+		match := bestMatch(charge, candidates, used)
+		if match == nil {
+			continue
+		}
+		if err := s.repo.MarkChargePaid(ctx, charge.ID, match.TransactionID, match.PostedAt); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue // Matched concurrently
+			}
+			return nil, err
+		}
+		used[match.TransactionID] = true
+		touched[charge.PlanID] = true
+		result.Matched++
+	}
+
+	for planID := range touched {
+		completed, err := s.repo.CompleteIfPaid(ctx, planID)
+		if err != nil {
+			return nil, err
+		}
+		if completed {
+			result.Completed++
+		}
+	}
+	return result, nil
+}
+
+// MatchChargesForAllUsers runs charge matching for every user with pending charges
+func (s *Service) MatchChargesForAllUsers(ctx context.Context) (*MatchResult, error) {
+	userIDs, err := s.repo.ListUsersWithPendingCharges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &MatchResult{}
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		res, err := s.MatchCharges(ctx, userID)
+		if err != nil {
+			return total, fmt.Errorf("failed to match installments for user %s: %w", userID, err)
+		}
+		total.Matched += res.Matched
+		total.Completed += res.Completed
+	}
+	return total, nil
+}
+
+// BuildSchedule splits a total into count charges at the given cadence. Any
+// remainder from uneven division is added to the first charge.
+func BuildSchedule(totalMinor int64, count int, cadence string, firstDue time.Time) ([]*repository.InstallmentCharge, error) {
+	if totalMinor <= 0 || count < 2 || count > 120 || int64(count) > totalMinor {
+		return nil, ErrInvalidSchedule
+	}
+
+	var step func(i int) time.Time
+	first := time.Date(firstDue.Year(), firstDue.Month(), firstDue.Day(), 0, 0, 0, 0, time.UTC)
+	switch cadence {
+	case "weekly":
+		step = func(i int) time.Time { return first.AddDate(0, 0, 7*i) }
+	case "monthly":
+		step = func(i int) time.Time { return addMonthsClamped(first, i) }
+	case "quarterly":
+		step = func(i int) time.Time { return addMonthsClamped(first, 3*i) }
+	case "annual":
+		step = func(i int) time.Time { return addMonthsClamped(first, 12*i) }
+	default:
+		return nil, ErrInvalidSchedule
+	}
+
+	base := totalMinor / int64(count)
+	remainder := totalMinor - base*int64(count)
+
+	charges := make([]*repository.InstallmentCharge, count)
+	for i := range charges {
+		amount := base
+		if i == 0 {
+			amount += remainder
+		}
+		charges[i] = &repository.InstallmentCharge{
+			Sequence:    i + 1,
+			DueAt:       step(i),
+			AmountMinor: amount,
+		}
+	}
+	return charges, nil
+}
+
+// addMonthsClamped adds months keeping the day within the target month (Jan 31 + 1 = Feb 28)
+func addMonthsClamped(t time.Time, months int) time.Time {
+	target := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := target.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(target.Year(), target.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+// bestMatch picks the unused candidate closest to the charge's due date that
+// has a matching currency, amount and merchant
+func bestMatch(charge *repository.PendingCharge, candidates []*repository.ChargeCandidate, used map[uuid.UUID]bool) *repository.ChargeCandidate {
+	var best *repository.ChargeCandidate
+	var bestDistance time.Duration
+
+	earliest := charge.DueAt.Add(-matchWindowBefore)
+	latest := charge.DueAt.Add(matchWindowAfter)
+	merchant := normalizeMerchant(charge.MerchantName)
+
+	for _, c := range candidates {
+		if used[c.TransactionID] || c.CurrencyCode != charge.CurrencyCode {
+			continue
+		}
+		if c.PostedAt.Before(earliest) || c.PostedAt.After(latest) {
+			continue
+		}
+		if !amountMatches(c.AmountMinor, charge.AmountMinor) {
+			continue
+		}
+		if merchant != "" &&
+			!strings.Contains(normalizeMerchant(c.MerchantName), merchant) &&
+			!strings.Contains(normalizeMerchant(c.Description), merchant) {
+			continue
+		}
+
+		distance := c.PostedAt.Sub(charge.DueAt)
+		if distance < 0 {
+			distance = -distance
+		}
+		if best == nil || distance < bestDistance {
+			best, bestDistance = c, distance
+		}
+	}
+	return best
+}
+
+func amountMatches(actual, expected int64) bool {
+	diff := actual - expected
+	if diff < 0 {
+		diff = -diff
+	}
+	tolerance := int64(float64(expected) * matchAmountTolerance)
+	if tolerance < 1 {
+		tolerance = 1
+	}
+	return diff <= tolerance
+}
+
+// normalizeMerchant lowercases and strips everything but letters and digits
+func normalizeMerchant(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
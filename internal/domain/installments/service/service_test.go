@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/installments/repository"
+)
+
+func TestBuildSchedule_SplitsRemainderAndClampsDays(t *testing.T) {
+	first := time.Date(2025, 1, 31, 15, 30, 0, 0, time.UTC)
+
+	charges, err := BuildSchedule(10000, 3, "monthly", first)
+	require.NoError(t, err)
+	require.Len(t, charges, 3)
+
+	assert.Equal(t, int64(3334), charges[0].AmountMinor)
+	assert.Equal(t, int64(3333), charges[1].AmountMinor)
+	assert.Equal(t, int64(3333), charges[2].AmountMinor)
+
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), charges[0].DueAt)
+	assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), charges[1].DueAt)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), charges[2].DueAt)
+	assert.Equal(t, 3, charges[2].Sequence)
+}
+
+func TestBuildSchedule_RejectsInvalidInput(t *testing.T) {
+	now := time.Now()
+	for name, tc := range map[string]struct {
+		total   int64
+		count   int
+		cadence string
+	}{
+		"single charge":   {10000, 1, "monthly"},
+		"zero total":      {0, 3, "monthly"},
+		"unknown cadence": {10000, 3, "unknown"},
+		"too many":        {2, 3, "weekly"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := BuildSchedule(tc.total, tc.count, tc.cadence, now)
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestBestMatch_PrefersClosestMatchingCharge(t *testing.T) {
+	due := time.Date(2025, 4, 10, 0, 0, 0, 0, time.UTC)
+	charge := &repository.PendingCharge{
+		InstallmentCharge: repository.InstallmentCharge{ID: uuid.New(), DueAt: due, AmountMinor: 3333},
+		MerchantName:      "Klarna",
+		CurrencyCode:      "EUR",
+	}
+
+	far := &repository.ChargeCandidate{TransactionID: uuid.New(), Description: "KLARNA*SHOP", AmountMinor: 3333, CurrencyCode: "EUR", PostedAt: due.AddDate(0, 0, 10)}
+	near := &repository.ChargeCandidate{TransactionID: uuid.New(), MerchantName: "Klarna AB", AmountMinor: 3340, CurrencyCode: "EUR", PostedAt: due.AddDate(0, 0, 1)}
+	candidates := []*repository.ChargeCandidate{
+		far,
+		near,
+		{TransactionID: uuid.New(), MerchantName: "Klarna", AmountMinor: 5000, CurrencyCode: "EUR", PostedAt: due},                  // wrong amount
+		{TransactionID: uuid.New(), MerchantName: "Spotify", AmountMinor: 3333, CurrencyCode: "EUR", PostedAt: due},                 // wrong merchant
+		{TransactionID: uuid.New(), MerchantName: "Klarna", AmountMinor: 3333, CurrencyCode: "USD", PostedAt: due},                  // wrong currency
+		{TransactionID: uuid.New(), MerchantName: "Klarna", AmountMinor: 3333, CurrencyCode: "EUR", PostedAt: due.AddDate(0, 1, 0)}, // too late
+	}
+
+	assert.Equal(t, near, bestMatch(charge, candidates, map[uuid.UUID]bool{}))
+	assert.Equal(t, far, bestMatch(charge, candidates, map[uuid.UUID]bool{near.TransactionID: true}))
+	assert.Nil(t, bestMatch(charge, candidates, map[uuid.UUID]bool{near.TransactionID: true, far.TransactionID: true}))
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresMerchantRepository implements MerchantRepository using PostgreSQL
+type PostgresMerchantRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresMerchantRepository creates a new PostgreSQL merchant repository
+func NewPostgresMerchantRepository(pool *pgxpool.Pool) *PostgresMerchantRepository {
+	return &PostgresMerchantRepository{pool: pool}
+}
+
+const merchantColumns = `id, slug, name, aliases, website, logo_url, brand_color, mcc, source, created_at, updated_at`
+
+// ListMerchants lists every canonical merchant, dataset entries first
+func (r *PostgresMerchantRepository) ListMerchants(ctx context.Context) ([]*Merchant, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+merchantColumns+`
+		FROM canonical_merchants
+		ORDER BY source = 'dataset' DESC, slug`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchants: %w", err)
+	}
+	defer rows.Close()
+
+	var merchants []*Merchant
+	for rows.Next() {
+		m := &Merchant{}
+		if err := rows.Scan(&m.ID, &m.Slug, &m.Name, &m.Aliases, &m.Website, &m.LogoURL,
+			&m.BrandColor, &m.MCC, &m.Source, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant: %w", err)
+		}
+		merchants = append(merchants, m)
+	}
+	return merchants, rows.Err()
+}
+
+// SaveHeuristicMerchant inserts a derived merchant. An existing merchant with
+// the same slug keeps its details and gains the new aliases.
+func (r *PostgresMerchantRepository) SaveHeuristicMerchant(ctx context.Context, m *Merchant) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO canonical_merchants (slug, name, aliases, website, mcc, source)
+		VALUES ($1, $2, $3, $4, $5, 'heuristic')
+		ON CONFLICT (slug) DO UPDATE
+		SET aliases = ARRAY(SELECT DISTINCT unnest(canonical_merchants.aliases || EXCLUDED.aliases))
+		RETURNING `+merchantColumns,
+		m.Slug, m.Name, m.Aliases, m.Website, m.MCC,
+	).Scan(&m.ID, &m.Slug, &m.Name, &m.Aliases, &m.Website, &m.LogoURL,
+		&m.BrandColor, &m.MCC, &m.Source, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save merchant: %w", err)
+	}
+	return nil
+}
+
+// FindCategoryIDs returns the user's categories matching any of the names
+func (r *PostgresMerchantRepository) FindCategoryIDs(ctx context.Context, userID uuid.UUID, names []string) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name::TEXT
+		FROM categories
+		WHERE user_id = $1 AND name = ANY($2::citext[])
+		ORDER BY created_at`, userID, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		// The oldest category wins when names repeat
+		if _, ok := ids[strings.ToLower(name)]; !ok {
+			ids[strings.ToLower(name)] = id
+		}
+	}
+	return ids, rows.Err()
+}
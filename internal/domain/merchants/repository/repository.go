@@ -0,0 +1,45 @@
+// Package repository provides database operations for canonical merchants.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merchant sources
+const (
+	// SourceDataset merchants come from the curated brand data seeded by migration
+	SourceDataset = "dataset"
+	// SourceHeuristic merchants were derived from a transaction description
+	SourceHeuristic = "heuristic"
+)
+
+// Merchant is a canonical merchant that many raw descriptions resolve to
+type Merchant struct {
+	ID         uuid.UUID
+	Slug       string
+	Name       string
+	Aliases    []string // Normalized description prefixes
+	Website    *string
+	LogoURL    *string
+	BrandColor *string
+	MCC        *string
+	Source     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// MerchantRepository defines the interface for canonical merchant persistence
+type MerchantRepository interface {
+	// ListMerchants lists every canonical merchant
+	ListMerchants(ctx context.Context) ([]*Merchant, error)
+	// SaveHeuristicMerchant inserts a merchant derived from a description, or
+	// adds its aliases to the merchant already using its slug. Sets ID and
+	// the stored fields on m.
+	SaveHeuristicMerchant(ctx context.Context, m *Merchant) error
+	// FindCategoryIDs returns the IDs of the user's categories with any of the
+	// given names, keyed by lower-case name
+	FindCategoryIDs(ctx context.Context, userID uuid.UUID, names []string) (map[string]uuid.UUID, error)
+}
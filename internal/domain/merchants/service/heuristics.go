@@ -0,0 +1,155 @@
+package service
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/repository"
+)
+
+// maxNameTokens caps how much of a description is taken as the merchant name
+const maxNameTokens = 4
+
+var (
+	// domainPattern finds a website in a description, e.g. "NETFLIX.COM" or "www.loja.pt"
+	domainPattern   = regexp.MustCompile(`(?i)\b(?:www\.)?([a-z0-9][a-z0-9-]*\.(?:com|net|org|io|app|eu|co\.uk|pt|es|fr|de|it|nl|ie|be))\b`)
+	nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+	// accents folds the accented letters common in Portuguese, Spanish and
+	// French descriptions, so FARMÁCIA matches FARMACIA
+	accents = strings.NewReplacer(
+		"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A",
+		"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+		"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+		"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ö", "O",
+		"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+		"Ç", "C", "Ñ", "N",
+		// Joined rather than split: MCDONALD'S, H&M
+		"'", "", "’", "", "&", "",
+	)
+)
+
+// descriptionPrefixes are card payment and payment processor markers that
+// come before the merchant, as tokens
+var descriptionPrefixes = [][]string{
+	{"COMPRAS", "C", "DEB"},
+	{"CARD", "PAYMENT", "TO"},
+	{"DEBIT", "CARD"},
+	{"COMPRA"},
+	{"PURCHASE"},
+	{"POS"},
+	{"PAGAMENTO"},
+	{"PAG"},
+	{"DD"},
+	{"WWW"},
+	{"SQ"},
+	{"SQU"},
+	{"SUMUP"},
+	{"ZETTLE"},
+	{"IZ"},
+	{"TST"},
+	{"SP"},
+	{"CKO"},
+	{"PP"},
+	{"PAYPAL"}, // "PAYPAL *SPOTIFY" is Spotify; a bare "PAYPAL" stays PayPal
+}
+
+// stopTokens end the merchant name: legal forms and the start of a website
+// ("PASTELARIA ALEGRIA LDA WWW.ALEGRIA.PT")
+var stopTokens = map[string]bool{
+	"LDA": true, "UNIPESSOAL": true, "LTD": true, "LTDA": true, "INC": true,
+	"LLC": true, "GMBH": true, "SA": true, "SL": true, "WWW": true,
+}
+
+// trailingTokens are country codes and top-level domains that end many
+// descriptions ("AMZN MKTP US", "NETFLIX.COM")
+var trailingTokens = map[string]bool{
+	"US": true, "GB": true, "UK": true, "PT": true, "ES": true, "FR": true,
+	"DE": true, "IT": true, "NL": true, "IE": true, "BE": true, "LU": true,
+	"COM": true, "NET": true, "ORG": true,
+}
+
+// transferPrefixes mark payments to people rather than merchants. Their
+// descriptions name the payee, so they're never saved as merchants.
+var transferPrefixes = [][]string{
+	{"TRANSFER"},
+	{"TRANSFERENCIA"},
+	{"TRF"},
+	{"TRANSF"},
+	{"SEPA"},
+	{"MB", "WAY"},
+	{"BIZUM"},
+	{"ZELLE"},
+	{"VENMO"},
+}
+
+// isTransfer reports whether tokens describe a transfer to a person
+func isTransfer(tokens []string) bool {
+	for _, prefix := range transferPrefixes {
+		if len(tokens) >= len(prefix) && slices.Equal(tokens[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize normalizes a raw description into the upper-case words naming the
+// merchant: accents folded, punctuation, references and store numbers
+// dropped, and payment markers removed
+func tokenize(description string) []string {
+	upper := accents.Replace(strings.ToUpper(description))
+	var tokens []string
+	for _, t := range strings.Fields(nonAlphanumeric.ReplaceAllString(upper, " ")) {
+		if !strings.ContainsAny(t, "0123456789") {
+			tokens = append(tokens, t)
+		}
+	}
+
+	for stripped := true; stripped; {
+		stripped = false
+		for _, prefix := range descriptionPrefixes {
+			if len(tokens) > len(prefix) && slices.Equal(tokens[:len(prefix)], prefix) {
+				tokens = tokens[len(prefix):]
+				stripped = true
+				break
+			}
+		}
+	}
+	for i := 1; i < len(tokens); i++ {
+		if stopTokens[tokens[i]] {
+			tokens = tokens[:i]
+			break
+		}
+	}
+	for len(tokens) > 1 && trailingTokens[tokens[len(tokens)-1]] {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// deriveMerchant builds a merchant from a description no known merchant
+// matched: its name from the leading words, its website from any domain in
+// it, and its MCC from keywords
+func deriveMerchant(description string, tokens []string) *repository.Merchant {
+	nameTokens := tokens[:min(len(tokens), maxNameTokens)]
+	words := make([]string, len(nameTokens))
+	for i, t := range nameTokens {
+		words[i] = t[:1] + strings.ToLower(t[1:])
+	}
+
+	m := &repository.Merchant{
+		Slug:    strings.ToLower(strings.Join(nameTokens, "-")),
+		Name:    strings.Join(words, " "),
+		Aliases: []string{strings.Join(nameTokens, " ")},
+		Source:  repository.SourceHeuristic,
+	}
+	if match := domainPattern.FindStringSubmatch(description); match != nil {
+		website := "https://" + strings.ToLower(match[1])
+		m.Website = &website
+	}
+	if mcc := guessMCC(tokens); mcc != "" {
+		m.MCC = &mcc
+	}
+	return m
+}
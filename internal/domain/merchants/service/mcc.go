@@ -0,0 +1,123 @@
+package service
+
+import "strconv"
+
+// Category hints derived from merchant category codes
+const (
+	HintGroceries     = "Groceries"
+	HintDining        = "Dining"
+	HintTransport     = "Transport"
+	HintTravel        = "Travel"
+	HintUtilities     = "Utilities"
+	HintSubscriptions = "Subscriptions"
+	HintHealth        = "Health"
+	HintEntertainment = "Entertainment"
+	HintShopping      = "Shopping"
+	HintEducation     = "Education"
+	HintInsurance     = "Insurance"
+	HintHousing       = "Housing"
+)
+
+// categoryNames are the category names, lower-case and in order of
+// preference, a user is likely to have given each hint. Users name their own
+// categories, so English and Portuguese variants are both tried.
+var categoryNames = map[string][]string{
+	HintGroceries:     {"groceries", "grocery", "supermarket", "supermercado", "mercearia", "alimentação", "food"},
+	HintDining:        {"dining", "restaurants", "restaurant", "eating out", "food & drink", "restaurantes", "restauração"},
+	HintTransport:     {"transport", "transportation", "fuel", "car", "transportes", "combustível"},
+	HintTravel:        {"travel", "holidays", "vacation", "viagens", "férias"},
+	HintUtilities:     {"utilities", "bills", "phone & internet", "contas", "serviços"},
+	HintSubscriptions: {"subscriptions", "streaming", "subscrições", "assinaturas", "entertainment"},
+	HintHealth:        {"health", "healthcare", "medical", "pharmacy", "saúde", "farmácia"},
+	HintEntertainment: {"entertainment", "leisure", "lazer", "entretenimento"},
+	HintShopping:      {"shopping", "clothing", "compras", "roupa"},
+	HintEducation:     {"education", "educação"},
+	HintInsurance:     {"insurance", "seguros"},
+	HintHousing:       {"housing", "rent", "home", "habitação", "casa", "renda"},
+}
+
+// mccHints maps individual merchant category codes (ISO 18245) to hints
+var mccHints = map[int]string{
+	4111: HintTransport, 4112: HintTransport, 4121: HintTransport, 4131: HintTransport,
+	4784: HintTransport, 4789: HintTransport, 5541: HintTransport, 5542: HintTransport,
+	5983: HintTransport, 7523: HintTransport,
+	4411: HintTravel, 4511: HintTravel, 4722: HintTravel, 7011: HintTravel, 7012: HintTravel,
+	4812: HintUtilities, 4814: HintUtilities, 4900: HintUtilities,
+	4899: HintSubscriptions,
+	5411: HintGroceries, 5422: HintGroceries, 5441: HintGroceries, 5451: HintGroceries,
+	5462: HintGroceries, 5499: HintGroceries,
+	5811: HintDining, 5812: HintDining, 5813: HintDining, 5814: HintDining,
+	5912: HintHealth, 7997: HintHealth,
+	7832: HintEntertainment, 7841: HintEntertainment, 7922: HintEntertainment,
+	7929: HintEntertainment, 7991: HintEntertainment, 7994: HintEntertainment,
+	7996: HintEntertainment, 7999: HintEntertainment,
+	5300: HintShopping, 5310: HintShopping, 5311: HintShopping, 5331: HintShopping,
+	5399: HintShopping, 5712: HintShopping, 5732: HintShopping, 5734: HintShopping,
+	5941: HintShopping, 5942: HintShopping, 5945: HintShopping,
+	6300: HintInsurance,
+	6513: HintHousing,
+}
+
+// CategoryHint returns the kind of spending a merchant category code
+// indicates, or "" if it doesn't suggest one (e.g. money transfers)
+func CategoryHint(mcc string) string {
+	code, err := strconv.Atoi(mcc)
+	if err != nil {
+		return ""
+	}
+	if hint, ok := mccHints[code]; ok {
+		return hint
+	}
+	switch {
+	case code >= 3000 && code <= 3350: // Airlines
+		return HintTravel
+	case code >= 3351 && code <= 3500: // Car rental
+		return HintTransport
+	case code >= 3501 && code <= 3999: // Hotels
+		return HintTravel
+	case code >= 5611 && code <= 5699: // Clothing and accessories
+		return HintShopping
+	case code >= 5815 && code <= 5818: // Digital goods and media
+		return HintSubscriptions
+	case code >= 8011 && code <= 8099: // Medical services
+		return HintHealth
+	case code >= 8211 && code <= 8299: // Schools
+		return HintEducation
+	}
+	return ""
+}
+
+// mccKeywords guess the MCC of merchants outside the dataset from words in
+// their description, in English and Portuguese
+var mccKeywords = []struct {
+	mcc   string
+	words []string
+}{
+	{"5411", []string{"SUPERMARKET", "SUPERMERCADO", "HIPERMERCADO", "MINIMERCADO", "MERCEARIA", "GROCERY", "GROCERIES"}},
+	{"5812", []string{"RESTAURANT", "RESTAURANTE", "PIZZA", "PIZZERIA", "SUSHI", "BISTRO", "TASCA", "GRILL", "CHURRASQUEIRA"}},
+	{"5814", []string{"CAFE", "COFFEE", "PASTELARIA", "SNACK"}},
+	{"5813", []string{"BAR", "PUB", "TABERNA"}},
+	{"5462", []string{"BAKERY", "PADARIA"}},
+	{"5912", []string{"PHARMACY", "FARMACIA", "CHEMIST"}},
+	{"5541", []string{"FUEL", "PETROL", "GASOLINEIRA", "COMBUSTIVEIS"}},
+	{"7011", []string{"HOTEL", "HOSTEL"}},
+	{"4121", []string{"TAXI"}},
+	{"7523", []string{"PARKING", "ESTACIONAMENTO", "PARQUE"}},
+	{"7997", []string{"GYM", "FITNESS", "GINASIO"}},
+	{"7832", []string{"CINEMA", "CINEMAS"}},
+	{"5942", []string{"BOOKSTORE", "LIVRARIA"}},
+}
+
+// guessMCC returns the MCC suggested by a description's words, or ""
+func guessMCC(tokens []string) string {
+	for _, kw := range mccKeywords {
+		for _, word := range kw.words {
+			for _, t := range tokens {
+				if t == word {
+					return kw.mcc
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+// Package service provides merchant enrichment: resolving raw transaction
+// descriptions to canonical merchants with clean names, logos, brand colors
+// and MCC-derived category hints.
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/repository"
+)
+
+// =============================================================================
+// Merchant Enrichment (Internal Integration)
+// =============================================================================
+// Descriptions resolve to canonical merchants from the curated dataset by
+// their leading words ("AMZN MKTP US*2K3" is Amazon). Anything else is
+// derived heuristically and saved, so the same merchant resolves the same way
+// next time. Categorization uses HintMerchants as a fallback when no rule or
+// merchant pattern matches, through the adapter in cmd/api.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetMerchantDetailsRequest/Response, MerchantDetails
+// - Transaction.merchant (MerchantDetails), so transactionToProto can include
+//   the logo URL, brand color and MCC-derived category hint
+
+// DefaultLogoURLTemplate builds logo URLs from a merchant's domain
+const DefaultLogoURLTemplate = "https://icons.duckduckgo.com/ip3/{domain}.ico"
+
+// merchantCacheTTL is how long the canonical merchants are cached before
+// they're reloaded
+const merchantCacheTTL = time.Hour
+
+// ErrUnknownMerchant is returned for transfers, and for descriptions with
+// nothing left to name a merchant once references and payment markers are
+// removed
+var ErrUnknownMerchant = errors.New("no merchant in description")
+
+// MerchantDetails describes the merchant behind a transaction
+type MerchantDetails struct {
+	ID           uuid.UUID
+	Name         string
+	Website      string
+	LogoURL      string
+	BrandColor   string // "#RRGGBB"
+	MCC          string
+	CategoryHint string // The kind of spending the MCC indicates, e.g. "Groceries"
+	Source       string // repository.SourceDataset or SourceHeuristic
+}
+
+// Hint is what enrichment suggests for one description
+type Hint struct {
+	Name       string     // Canonical name, when the merchant is known
+	CategoryID *uuid.UUID // The user's category matching the merchant's MCC
+}
+
+// Service resolves descriptions to canonical merchants
+type Service struct {
+	repo            repository.MerchantRepository
+	logoURLTemplate string
+	now             func() time.Time
+
+	mu       sync.RWMutex
+	byAlias  map[string]*repository.Merchant
+	loadedAt time.Time
+}
+
+// NewService creates a new merchant enrichment service
+func NewService(repo repository.MerchantRepository) *Service {
+	return &Service{repo: repo, logoURLTemplate: DefaultLogoURLTemplate, now: time.Now}
+}
+
+// WithLogoURLTemplate sets the URL logos are fetched from; "{domain}" is
+// replaced by the merchant's domain. An empty template disables derived logos.
+func (s *Service) WithLogoURLTemplate(template string) *Service {
+	s.logoURLTemplate = template
+	return s
+}
+
+// GetMerchantDetails resolves a raw description, or a merchant name, to its
+// canonical merchant. Merchants outside the dataset are derived from the
+// description and saved.
+func (s *Service) GetMerchantDetails(ctx context.Context, description string) (*MerchantDetails, error) {
+	tokens := tokenize(description)
+	if len(tokens) == 0 || isTransfer(tokens) {
+		return nil, ErrUnknownMerchant
+	}
+
+	m, err := s.lookup(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = deriveMerchant(description, tokens)
+		if err := s.repo.SaveHeuristicMerchant(ctx, m); err != nil {
+			return nil, err
+		}
+		s.remember(m)
+	}
+	return s.details(m), nil
+}
+
+// HintMerchants suggests canonical names and categories for descriptions,
+// aligned to them. Unlike GetMerchantDetails nothing is saved, so it's cheap
+// enough for whole imports. Descriptions without a hint get a nil entry.
+func (s *Service) HintMerchants(ctx context.Context, userID uuid.UUID, descriptions []string) ([]*Hint, error) {
+	hints := make([]*Hint, len(descriptions))
+	categoryHints := make([]string, len(descriptions))
+	wanted := map[string]bool{}
+	for i, description := range descriptions {
+		tokens := tokenize(description)
+		if len(tokens) == 0 || isTransfer(tokens) {
+			continue
+		}
+		m, err := s.lookup(ctx, tokens)
+		if err != nil {
+			return nil, err
+		}
+
+		hint, mcc := &Hint{}, guessMCC(tokens)
+		if m != nil {
+			hint.Name = m.Name
+			if m.MCC != nil {
+				mcc = *m.MCC
+			}
+		}
+		hints[i] = hint
+		if c := CategoryHint(mcc); c != "" {
+			categoryHints[i] = c
+			for _, name := range categoryNames[c] {
+				wanted[name] = true
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		return hints, nil
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	categories, err := s.repo.FindCategoryIDs(ctx, userID, names)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range categoryHints {
+		for _, name := range categoryNames[c] {
+			if id, ok := categories[name]; ok {
+				hints[i].CategoryID = &id
+				break
+			}
+		}
+	}
+	return hints, nil
+}
+
+// lookup finds the known merchant whose alias is the longest run of leading
+// tokens, or nil
+func (s *Service) lookup(ctx context.Context, tokens []string) (*repository.Merchant, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for n := min(len(tokens), maxNameTokens); n > 0; n-- {
+		if m, ok := s.byAlias[strings.Join(tokens[:n], " ")]; ok {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+// load caches the canonical merchants by alias, reloading them once stale
+func (s *Service) load(ctx context.Context) error {
+	s.mu.RLock()
+	fresh := s.byAlias != nil && s.now().Sub(s.loadedAt) < merchantCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	merchants, err := s.repo.ListMerchants(ctx)
+	if err != nil {
+		return err
+	}
+	byAlias := make(map[string]*repository.Merchant, len(merchants))
+	for _, m := range merchants {
+		for _, alias := range m.Aliases {
+			// Dataset merchants are listed first and keep contested aliases
+			if _, taken := byAlias[alias]; !taken {
+				byAlias[alias] = m
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.byAlias = byAlias
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// remember caches a merchant just saved
+func (s *Service) remember(m *repository.Merchant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, alias := range m.Aliases {
+		if _, taken := s.byAlias[alias]; !taken {
+			s.byAlias[alias] = m
+		}
+	}
+}
+
+func (s *Service) details(m *repository.Merchant) *MerchantDetails {
+	d := &MerchantDetails{ID: m.ID, Name: m.Name, Source: m.Source}
+	if m.Website != nil {
+		d.Website = *m.Website
+	}
+	if m.BrandColor != nil {
+		d.BrandColor = *m.BrandColor
+	}
+	if m.MCC != nil {
+		d.MCC = *m.MCC
+		d.CategoryHint = CategoryHint(*m.MCC)
+	}
+	switch {
+	case m.LogoURL != nil:
+		d.LogoURL = *m.LogoURL
+	case d.Website != "" && s.logoURLTemplate != "":
+		if u, err := url.Parse(d.Website); err == nil && u.Hostname() != "" {
+			domain := strings.TrimPrefix(u.Hostname(), "www.")
+			d.LogoURL = strings.ReplaceAll(s.logoURLTemplate, "{domain}", domain)
+		}
+	}
+	return d
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/merchants/repository"
+)
+
+// fakeMerchantRepository keeps merchants and categories in memory.
+type fakeMerchantRepository struct {
+	merchants  []*repository.Merchant
+	categories map[string]uuid.UUID
+	lists      int
+}
+
+func (f *fakeMerchantRepository) ListMerchants(ctx context.Context) ([]*repository.Merchant, error) {
+	f.lists++
+	return f.merchants, nil
+}
+
+func (f *fakeMerchantRepository) SaveHeuristicMerchant(ctx context.Context, m *repository.Merchant) error {
+	m.ID = uuid.New()
+	f.merchants = append(f.merchants, m)
+	return nil
+}
+
+func (f *fakeMerchantRepository) FindCategoryIDs(ctx context.Context, userID uuid.UUID, names []string) (map[string]uuid.UUID, error) {
+	ids := make(map[string]uuid.UUID)
+	for _, name := range names {
+		if id, ok := f.categories[name]; ok {
+			ids[name] = id
+		}
+	}
+	return ids, nil
+}
+
+func ptr(s string) *string { return &s }
+
+func newFakeRepo() *fakeMerchantRepository {
+	return &fakeMerchantRepository{merchants: []*repository.Merchant{
+		{ID: uuid.New(), Slug: "amazon", Name: "Amazon", Aliases: []string{"AMAZON", "AMZN", "AMZN MKTP"},
+			Website: ptr("https://www.amazon.com"), BrandColor: ptr("#FF9900"), MCC: ptr("5942"), Source: repository.SourceDataset},
+		{ID: uuid.New(), Slug: "uber", Name: "Uber", Aliases: []string{"UBER"},
+			Website: ptr("https://www.uber.com"), MCC: ptr("4121"), Source: repository.SourceDataset},
+		{ID: uuid.New(), Slug: "uber-eats", Name: "Uber Eats", Aliases: []string{"UBER EATS"},
+			MCC: ptr("5814"), Source: repository.SourceDataset},
+		{ID: uuid.New(), Slug: "mcdonalds", Name: "McDonald's", Aliases: []string{"MCDONALDS"},
+			MCC: ptr("5814"), Source: repository.SourceDataset},
+	}}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"AMZN MKTP US*2K3LX9":           {"AMZN", "MKTP"},
+		"COMPRAS C.DEB McDonald's 0042": {"MCDONALDS"},
+		"PAYPAL *SPOTIFY":               {"SPOTIFY"},
+		"PAYPAL":                        {"PAYPAL"},
+		"SQ *BLUE BOTTLE COFFEE":        {"BLUE", "BOTTLE", "COFFEE"},
+		"Farmácia Estácio 12/03":        {"FARMACIA", "ESTACIO"},
+		"NETFLIX.COM":                   {"NETFLIX"},
+		"*1234 4567":                    nil,
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, tokenize(input), input)
+	}
+}
+
+func TestGetMerchantDetails_Dataset(t *testing.T) {
+	svc := NewService(newFakeRepo())
+
+	d, err := svc.GetMerchantDetails(context.Background(), "AMZN MKTP US*2K3LX9")
+	require.NoError(t, err)
+	assert.Equal(t, "Amazon", d.Name)
+	assert.Equal(t, "#FF9900", d.BrandColor)
+	assert.Equal(t, "https://icons.duckduckgo.com/ip3/amazon.com.ico", d.LogoURL)
+	assert.Equal(t, HintShopping, d.CategoryHint)
+	assert.Equal(t, repository.SourceDataset, d.Source)
+
+	// The longest alias wins
+	d, err = svc.GetMerchantDetails(context.Background(), "UBER *EATS PENDING")
+	require.NoError(t, err)
+	assert.Equal(t, "Uber Eats", d.Name)
+	assert.Equal(t, HintDining, d.CategoryHint)
+
+	_, err = svc.GetMerchantDetails(context.Background(), "12345")
+	assert.ErrorIs(t, err, ErrUnknownMerchant)
+	_, err = svc.GetMerchantDetails(context.Background(), "MB WAY JOAO SILVA")
+	assert.ErrorIs(t, err, ErrUnknownMerchant, "transfers name people, not merchants")
+}
+
+func TestGetMerchantDetails_HeuristicIsSaved(t *testing.T) {
+	repo := newFakeRepo()
+	svc := NewService(repo).WithLogoURLTemplate("https://logos.example/{domain}.png")
+	ctx := context.Background()
+
+	d, err := svc.GetMerchantDetails(ctx, "POS PASTELARIA ALEGRIA LDA 4411 www.alegria.pt")
+	require.NoError(t, err)
+	assert.Equal(t, "Pastelaria Alegria", d.Name)
+	assert.Equal(t, "https://alegria.pt", d.Website)
+	assert.Equal(t, "https://logos.example/alegria.pt.png", d.LogoURL)
+	assert.Equal(t, "5814", d.MCC)
+	assert.Equal(t, repository.SourceHeuristic, d.Source)
+	require.Len(t, repo.merchants, 5)
+
+	again, err := svc.GetMerchantDetails(ctx, "POS PASTELARIA ALEGRIA LDA 4411 www.alegria.pt")
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, again.ID)
+	assert.Len(t, repo.merchants, 5, "a known merchant isn't saved twice")
+}
+
+func TestHintMerchants(t *testing.T) {
+	repo := newFakeRepo()
+	dining, transport := uuid.New(), uuid.New()
+	repo.categories = map[string]uuid.UUID{"restaurantes": dining, "transport": transport}
+	svc := NewService(repo)
+
+	hints, err := svc.HintMerchants(context.Background(), uuid.New(), []string{
+		"COMPRAS C.DEB MCDONALDS 0042",
+		"UBER TRIP HELP.UBER.COM",
+		"RESTAURANTE O MANEL",
+		"TRANSFERENCIA JOAO SILVA",
+		"",
+	})
+	require.NoError(t, err)
+	require.Len(t, hints, 5)
+
+	assert.Equal(t, "McDonald's", hints[0].Name)
+	assert.Equal(t, &dining, hints[0].CategoryID)
+	assert.Equal(t, "Uber", hints[1].Name)
+	assert.Equal(t, &transport, hints[1].CategoryID)
+	assert.Empty(t, hints[2].Name, "unknown merchants keep the categorizer's name")
+	assert.Equal(t, &dining, hints[2].CategoryID, "guessed from the description's words")
+	assert.Nil(t, hints[3])
+	assert.Nil(t, hints[4])
+	assert.Len(t, repo.merchants, 4, "hints don't save merchants")
+}
+
+func TestMerchantCacheReloads(t *testing.T) {
+	repo := newFakeRepo()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	svc := NewService(repo)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := svc.HintMerchants(ctx, uuid.New(), []string{"AMAZON"})
+	require.NoError(t, err)
+	_, err = svc.HintMerchants(ctx, uuid.New(), []string{"AMAZON"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.lists)
+
+	now = now.Add(merchantCacheTTL)
+	_, err = svc.HintMerchants(ctx, uuid.New(), []string{"AMAZON"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.lists)
+}
+
+func TestCategoryHint(t *testing.T) {
+	assert.Equal(t, HintGroceries, CategoryHint("5411"))
+	assert.Equal(t, HintTravel, CategoryHint("3012"))
+	assert.Equal(t, HintHealth, CategoryHint("8062"))
+	assert.Equal(t, "", CategoryHint("6011"))
+	assert.Equal(t, "", CategoryHint(""))
+}
@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresNotificationRepository implements NotificationRepository using PostgreSQL
+type PostgresNotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresNotificationRepository creates a new PostgreSQL notification repository
+func NewPostgresNotificationRepository(pool *pgxpool.Pool) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{pool: pool}
+}
+
+const deliveryColumns = `id, notification_id, channel, status, provider_message_id, error,
+	sent_at, delivered_at, opened_at, updated_at, scheduled_for, payload`
+
+func scanDelivery(row pgx.Row) (*Delivery, error) {
+	d := &Delivery{}
+	err := row.Scan(
+		&d.ID, &d.NotificationID, &d.Channel, &d.Status, &d.ProviderMessageID, &d.Error,
+		&d.SentAt, &d.DeliveredAt, &d.OpenedAt, &d.UpdatedAt, &d.ScheduledFor, &d.Payload,
+	)
+	return d, err
+}
+
+// Create inserts a notification and its deliveries in one transaction
+func (r *PostgresNotificationRepository) Create(ctx context.Context, n *Notification) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO notifications (id, user_id, kind, title, body, metadata, source_type, source_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at`,
+		n.ID, n.UserID, n.Kind, n.Title, n.Body, n.Metadata, n.SourceType, n.SourceID,
+	).Scan(&n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	for _, d := range n.Deliveries {
+		if d.ID == uuid.Nil {
+			d.ID = uuid.New()
+		}
+		d.NotificationID = n.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO notification_deliveries (id, notification_id, channel, status, sent_at, delivered_at, scheduled_for, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING updated_at`,
+			d.ID, d.NotificationID, d.Channel, d.Status, d.SentAt, d.DeliveredAt, d.ScheduledFor, d.Payload,
+		).Scan(&d.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create notification delivery: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit notification: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of a user's notifications (newest first) with their deliveries,
+// plus the total matching the filter
+func (r *PostgresNotificationRepository) List(ctx context.Context, userID uuid.UUID, filter ListFilter) ([]*Notification, int, error) {
+	where := `WHERE n.user_id = $1`
+	args := []interface{}{userID}
+	if filter.Kind != nil {
+		args = append(args, *filter.Kind)
+		where += fmt.Sprintf(` AND n.kind = $%d`, len(args))
+	}
+	if filter.UnreadOnly {
+		where += ` AND NOT EXISTS (
+			SELECT 1 FROM notification_deliveries d
+			WHERE d.notification_id = n.id AND d.channel = 'in_app' AND d.status = 'opened'
+		)`
+	}
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications n `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	args = append(args, limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT n.id, n.user_id, n.kind, n.title, n.body, n.metadata, n.source_type, n.source_id, n.created_at
+		FROM notifications n
+		%s
+		ORDER BY n.created_at DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*Notification
+	byID := make(map[uuid.UUID]*Notification)
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		n := &Notification{}
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.Metadata, &n.SourceType, &n.SourceID, &n.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+		byID[n.ID] = n
+		ids = append(ids, n.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate notifications: %w", err)
+	}
+	if len(ids) == 0 {
+		return notifications, total, nil
+	}
+
+	deliveryRows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM notification_deliveries
+		WHERE notification_id = ANY($1)
+		ORDER BY channel`, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	defer deliveryRows.Close()
+
+	for deliveryRows.Next() {
+		d, err := scanDelivery(deliveryRows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		byID[d.NotificationID].Deliveries = append(byID[d.NotificationID].Deliveries, d)
+	}
+	if err := deliveryRows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate notification deliveries: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// CountUnread counts notifications not yet read in-app
+func (r *PostgresNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM notifications n
+		JOIN notification_deliveries d ON d.notification_id = n.id AND d.channel = 'in_app'
+		WHERE n.user_id = $1 AND d.status <> 'opened'`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks a user's notification as read in-app
+func (r *PostgresNotificationRepository) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries d
+		SET status = 'opened', opened_at = COALESCE(d.opened_at, NOW())
+		FROM notifications n
+		WHERE d.notification_id = n.id
+		  AND n.id = $1
+		  AND n.user_id = $2
+		  AND d.channel = 'in_app'`, notificationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkSourceRead marks notifications created from a source record as read in-app
+func (r *PostgresNotificationRepository) MarkSourceRead(ctx context.Context, sourceType string, sourceID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries d
+		SET status = 'opened', opened_at = COALESCE(d.opened_at, NOW())
+		FROM notifications n
+		WHERE d.notification_id = n.id
+		  AND n.source_type = $1
+		  AND n.source_id = $2
+		  AND d.channel = 'in_app'
+		  AND d.status <> 'opened'`, sourceType, sourceID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification source read: %w", err)
+	}
+	return nil
+}
+
+// UpdateDelivery moves a delivery forward. Statuses never regress, so a late
+// provider receipt can't undo an open; failures only apply before delivery.
+func (r *PostgresNotificationRepository) UpdateDelivery(ctx context.Context, deliveryID uuid.UUID, status DeliveryStatus, providerMessageID, errMsg *string) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status = $2,
+		    provider_message_id = COALESCE($3, provider_message_id),
+		    error = $4,
+		    sent_at = CASE WHEN $2 IN ('sent', 'delivered', 'opened') THEN COALESCE(sent_at, NOW()) ELSE sent_at END,
+		    delivered_at = CASE WHEN $2 IN ('delivered', 'opened') THEN COALESCE(delivered_at, NOW()) ELSE delivered_at END,
+		    opened_at = CASE WHEN $2 = 'opened' THEN COALESCE(opened_at, NOW()) ELSE opened_at END
+		WHERE id = $1
+		  AND array_position(ARRAY['pending', 'sent', 'delivered', 'opened'], status)
+		      < COALESCE(array_position(ARRAY['pending', 'sent', 'delivered', 'opened'], $2::text), 3)`,
+		deliveryID, string(status), providerMessageID, errMsg)
+	if err != nil {
+		return fmt.Errorf("failed to update notification delivery: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := r.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM notification_deliveries WHERE id = $1)`, deliveryID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check notification delivery: %w", err)
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// ListAwaitingReceipt lists sent push deliveries that still need a provider receipt
+func (r *PostgresNotificationRepository) ListAwaitingReceipt(ctx context.Context, sentAfter, sentBefore time.Time, limit int) ([]*Delivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM notification_deliveries
+		WHERE channel = 'push'
+		  AND status = 'sent'
+		  AND provider_message_id IS NOT NULL
+		  AND sent_at >= $1
+		  AND sent_at < $2
+		ORDER BY sent_at
+		LIMIT $3`, sentAfter, sentBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deliveries awaiting receipt: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// ClaimDeferred clears scheduled_for on due held-back deliveries, so a
+// concurrent run can't claim them again, and loads their notifications
+func (r *PostgresNotificationRepository) ClaimDeferred(ctx context.Context, dueBefore time.Time, limit int) ([]*Notification, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM notification_deliveries
+			WHERE status = 'pending' AND scheduled_for IS NOT NULL AND scheduled_for <= $1
+			ORDER BY scheduled_for
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notification_deliveries d
+		SET scheduled_for = NULL
+		FROM due
+		WHERE d.id = due.id
+		RETURNING d.id, d.notification_id, d.channel, d.status, d.provider_message_id, d.error,
+		          d.sent_at, d.delivered_at, d.opened_at, d.updated_at, d.scheduled_for, d.payload`,
+		dueBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deferred deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]*Notification)
+	var ids []uuid.UUID
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+		n, ok := byID[d.NotificationID]
+		if !ok {
+			n = &Notification{ID: d.NotificationID}
+			byID[n.ID] = n
+			ids = append(ids, n.ID)
+		}
+		n.Deliveries = append(n.Deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deferred deliveries: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	notificationRows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, kind, title, body, metadata, source_type, source_id, created_at
+		FROM notifications
+		WHERE id = ANY($1)
+		ORDER BY created_at`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deferred notifications: %w", err)
+	}
+	defer notificationRows.Close()
+
+	notifications := make([]*Notification, 0, len(ids))
+	for notificationRows.Next() {
+		var id uuid.UUID
+		n := &Notification{}
+		if err := notificationRows.Scan(&id, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.Metadata, &n.SourceType, &n.SourceID, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		n.ID = id
+		n.Deliveries = byID[id].Deliveries
+		notifications = append(notifications, n)
+	}
+	return notifications, notificationRows.Err()
+}
+
+// GetContact loads the user's email, display name, push token, web push
+// subscriptions and preferences
+func (r *PostgresNotificationRepository) GetContact(ctx context.Context, userID uuid.UUID) (*Contact, error) {
+	c := &Contact{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT u.email, COALESCE(u.display_name, u.username, u.firstname, ''), COALESCE(u.expo_push_token, ''), t.chat_id
+		FROM users u
+		LEFT JOIN telegram_links t ON t.user_id = u.id
+		WHERE u.id = $1 AND u.is_active`, userID).Scan(&c.Email, &c.Name, &c.PushToken, &c.TelegramChatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification contact: %w", err)
+	}
+
+	if c.Preferences, err = r.GetPreferences(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM web_push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list web push subscriptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		sub := &WebPushSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan web push subscription: %w", err)
+		}
+		c.WebPush = append(c.WebPush, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate web push subscriptions: %w", err)
+	}
+	return c, nil
+}
+
+// GetPreferences returns the user's notification preferences, or the defaults
+func (r *PostgresNotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error) {
+	p := &Preferences{}
+	var quietStart, quietEnd *int16
+	err := r.pool.QueryRow(ctx, `
+		SELECT email_enabled, push_enabled, web_push_enabled, telegram_enabled, COALESCE(webhook_url, ''), COALESCE(webhook_secret, ''),
+		       quiet_start_minute, quiet_end_minute, timezone
+		FROM notification_preferences
+		WHERE user_id = $1`, userID).Scan(
+		&p.EmailEnabled, &p.PushEnabled, &p.WebPushEnabled, &p.TelegramEnabled, &p.WebhookURL, &p.WebhookSecret,
+		&quietStart, &quietEnd, &p.Timezone,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DefaultPreferences(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if quietStart != nil && quietEnd != nil {
+		start, end := int(*quietStart), int(*quietEnd)
+		p.QuietStart, p.QuietEnd = &start, &end
+	}
+	return p, nil
+}
+
+// SavePreferences upserts the user's notification preferences
+func (r *PostgresNotificationRepository) SavePreferences(ctx context.Context, userID uuid.UUID, p *Preferences) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO notification_preferences (
+			user_id, email_enabled, push_enabled, web_push_enabled, telegram_enabled, webhook_url, webhook_secret,
+			quiet_start_minute, quiet_end_minute, timezone
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email_enabled = EXCLUDED.email_enabled,
+			push_enabled = EXCLUDED.push_enabled,
+			web_push_enabled = EXCLUDED.web_push_enabled,
+			telegram_enabled = EXCLUDED.telegram_enabled,
+			webhook_url = EXCLUDED.webhook_url,
+			webhook_secret = EXCLUDED.webhook_secret,
+			quiet_start_minute = EXCLUDED.quiet_start_minute,
+			quiet_end_minute = EXCLUDED.quiet_end_minute,
+			timezone = EXCLUDED.timezone`,
+		userID, p.EmailEnabled, p.PushEnabled, p.WebPushEnabled, p.TelegramEnabled, p.WebhookURL, p.WebhookSecret,
+		p.QuietStart, p.QuietEnd, p.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// SaveWebPushSubscription upserts a browser subscription by endpoint
+func (r *PostgresNotificationRepository) SaveWebPushSubscription(ctx context.Context, sub *WebPushSubscription) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO web_push_subscriptions (user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			p256dh = EXCLUDED.p256dh,
+			auth = EXCLUDED.auth
+		RETURNING id, created_at`,
+		sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save web push subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteWebPushSubscription removes a browser subscription
+func (r *PostgresNotificationRepository) DeleteWebPushSubscription(ctx context.Context, userID *uuid.UUID, endpoint string) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM web_push_subscriptions
+		WHERE endpoint = $1 AND ($2::uuid IS NULL OR user_id = $2)`, endpoint, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete web push subscription: %w", err)
+	}
+	return nil
+}
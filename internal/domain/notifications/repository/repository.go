@@ -0,0 +1,201 @@
+// Package repository provides database operations for the notification inbox.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind classifies what produced a notification
+type Kind string
+
+const (
+	KindAlert  Kind = "alert"
+	KindDigest Kind = "digest"
+	KindSystem Kind = "system"
+)
+
+// Channel is a medium a notification is delivered through
+type Channel string
+
+const (
+	ChannelInApp    Channel = "in_app"
+	ChannelEmail    Channel = "email"
+	ChannelPush     Channel = "push"     // Expo push to the mobile app
+	ChannelWebPush  Channel = "web_push" // Browser push to every subscribed browser
+	ChannelWebhook  Channel = "webhook"  // Signed JSON POST to the user's URL
+	ChannelTelegram Channel = "telegram" // Bot message to the user's linked Telegram chat
+)
+
+// DeliveryStatus tracks a notification on one channel
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusSent      DeliveryStatus = "sent"      // Handed to the provider
+	DeliveryStatusDelivered DeliveryStatus = "delivered" // Provider confirmed delivery (in-app: visible in inbox)
+	DeliveryStatusOpened    DeliveryStatus = "opened"    // In-app read or email opened
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// Notification is a single inbox entry
+type Notification struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Kind       Kind
+	Title      string
+	Body       string
+	Metadata   map[string]any
+	SourceType *string // e.g. "alert"
+	SourceID   *uuid.UUID
+	CreatedAt  time.Time
+	Deliveries []*Delivery
+}
+
+// Delivery returns the notification's delivery on a channel, if any
+func (n *Notification) Delivery(channel Channel) *Delivery {
+	for _, d := range n.Deliveries {
+		if d.Channel == channel {
+			return d
+		}
+	}
+	return nil
+}
+
+// IsRead reports whether the notification was read in-app
+func (n *Notification) IsRead() bool {
+	d := n.Delivery(ChannelInApp)
+	return d != nil && d.Status == DeliveryStatusOpened
+}
+
+// Delivery is the state of a notification on one channel
+type Delivery struct {
+	ID                uuid.UUID
+	NotificationID    uuid.UUID
+	Channel           Channel
+	Status            DeliveryStatus
+	ProviderMessageID *string
+	Error             *string
+	SentAt            *time.Time
+	DeliveredAt       *time.Time
+	OpenedAt          *time.Time
+	UpdatedAt         time.Time
+
+	// ScheduledFor is set on pending deliveries held back by quiet hours
+	ScheduledFor *time.Time
+	// Payload is the push data kept for a held-back delivery
+	Payload map[string]any
+}
+
+// ListFilter narrows ListNotifications
+type ListFilter struct {
+	Kind       *Kind
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// Contact holds the addresses a user can be reached at
+type Contact struct {
+	Email          string
+	Name           string
+	PushToken      string
+	WebPush        []*WebPushSubscription
+	TelegramChatID *int64       // Set when the user linked a Telegram chat
+	Preferences    *Preferences // Nil means the defaults
+}
+
+// Preferences are a user's notification channel choices
+type Preferences struct {
+	EmailEnabled    bool
+	PushEnabled     bool
+	WebPushEnabled  bool
+	TelegramEnabled bool
+	WebhookURL      string // Webhooks are off without a URL
+	WebhookSecret   string
+	// QuietStart and QuietEnd are minutes after midnight in Timezone. Push, web
+	// push and Telegram are held back between them; nil disables quiet hours.
+	QuietStart *int
+	QuietEnd   *int
+	Timezone   string
+}
+
+// DefaultPreferences returns the preferences of users who never changed them
+func DefaultPreferences() *Preferences {
+	return &Preferences{EmailEnabled: true, PushEnabled: true, WebPushEnabled: true, TelegramEnabled: true, Timezone: "UTC"}
+}
+
+// QuietUntil reports whether t falls in the quiet hours and, if so, when they end.
+// A window whose start is after its end wraps past midnight.
+func (p *Preferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if p == nil || p.QuietStart == nil || p.QuietEnd == nil || *p.QuietStart == *p.QuietEnd {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	start, end := *p.QuietStart, *p.QuietEnd
+
+	quiet := minute >= start && minute < end
+	if start > end {
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// WebPushSubscription is a browser subscribed to web push
+type WebPushSubscription struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Endpoint  string
+	P256dh    string
+	Auth      string
+	CreatedAt time.Time
+}
+
+// NotificationRepository defines the interface for notification persistence
+type NotificationRepository interface {
+	// Create inserts a notification together with its deliveries
+	Create(ctx context.Context, n *Notification) error
+	List(ctx context.Context, userID uuid.UUID, filter ListFilter) ([]*Notification, int, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+
+	// MarkRead marks the in-app delivery opened; sql.ErrNoRows if not found or not owned
+	MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error
+	// MarkSourceRead marks in-app deliveries for notifications created from a source record
+	MarkSourceRead(ctx context.Context, sourceType string, sourceID uuid.UUID) error
+
+	// UpdateDelivery records a channel status change, stamping the matching timestamp
+	UpdateDelivery(ctx context.Context, deliveryID uuid.UUID, status DeliveryStatus, providerMessageID, errMsg *string) error
+	// ListAwaitingReceipt lists push deliveries sent within [sentAfter, sentBefore) that have no receipt yet
+	ListAwaitingReceipt(ctx context.Context, sentAfter, sentBefore time.Time, limit int) ([]*Delivery, error)
+
+	// ClaimDeferred claims pending deliveries held back until at most dueBefore,
+	// returning their notifications with only the claimed deliveries
+	ClaimDeferred(ctx context.Context, dueBefore time.Time, limit int) ([]*Notification, error)
+
+	// GetContact loads the user's addresses, web push subscriptions, linked
+	// Telegram chat and preferences
+	GetContact(ctx context.Context, userID uuid.UUID) (*Contact, error)
+	// GetPreferences returns the user's preferences, or the defaults if never saved
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*Preferences, error)
+	SavePreferences(ctx context.Context, userID uuid.UUID, prefs *Preferences) error
+
+	// SaveWebPushSubscription adds a browser, moving it over if another user had its endpoint
+	SaveWebPushSubscription(ctx context.Context, sub *WebPushSubscription) error
+	// DeleteWebPushSubscription removes a browser by endpoint; a nil userID matches any owner
+	DeleteWebPushSubscription(ctx context.Context, userID *uuid.UUID, endpoint string) error
+}
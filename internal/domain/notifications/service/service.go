@@ -0,0 +1,560 @@
+// Package service provides business logic for the notification inbox.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/push"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webhook"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webpush"
+)
+
+const (
+	// receiptDelay is how long Expo needs before a push receipt is available
+	receiptDelay = 15 * time.Minute
+	// receiptRetention is how long Expo keeps push receipts
+	receiptRetention = 24 * time.Hour
+	// receiptBatchSize is the maximum number of tickets per receipts request
+	receiptBatchSize = 1000
+	// deferredBatchSize is how many held-back deliveries one run sends
+	deferredBatchSize = 500
+
+	// EmailOpenPath is the tracking pixel route; the delivery ID is appended
+	EmailOpenPath = "/notifications/email/opened/"
+)
+
+// ErrInvalidNotification is returned for a notification without a title or with an unknown kind
+var ErrInvalidNotification = errors.New("notification requires a title and a kind of alert, digest or system")
+
+// ErrInvalidPreferences is returned for preferences that can't be saved
+var ErrInvalidPreferences = errors.New("invalid notification preferences")
+
+// PushSender sends push notifications and reports their delivery
+type PushSender interface {
+	SendTicket(ctx context.Context, msg *push.Message) (string, error)
+	GetReceipts(ctx context.Context, ticketIDs []string) (map[string]push.Receipt, error)
+}
+
+// EmailSender sends notification emails
+type EmailSender interface {
+	SendNotificationEmail(toEmail, toName, subject, message, trackingURL string) error
+}
+
+// WebPushSender sends browser push notifications
+type WebPushSender interface {
+	Send(ctx context.Context, sub webpush.Subscription, msg *webpush.Message) error
+}
+
+// WebhookSender posts events to user webhooks
+type WebhookSender interface {
+	Send(ctx context.Context, targetURL, secret string, event *webhook.Event) error
+}
+
+// TelegramSender sends bot messages to linked Telegram chats
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// SendInput describes a notification to record and deliver
+type SendInput struct {
+	UserID     uuid.UUID
+	Kind       repository.Kind
+	Title      string
+	Body       string
+	Metadata   map[string]any
+	SourceType *string
+	SourceID   *uuid.UUID
+	// Channels beyond the in-app inbox, which is always used
+	Channels []repository.Channel
+	// PushData is attached to the push payload for client-side routing
+	PushData map[string]any
+}
+
+// Inbox is a page of notifications with counts
+type Inbox struct {
+	Notifications []*repository.Notification
+	Total         int
+	Unread        int
+}
+
+// ReceiptResult summarizes a push receipt check
+type ReceiptResult struct {
+	Delivered int
+	Failed    int
+}
+
+// Service records notifications and tracks their delivery on every channel
+type Service struct {
+	repo            repository.NotificationRepository
+	push            PushSender
+	webPush         WebPushSender
+	webhook         WebhookSender
+	telegram        TelegramSender
+	email           EmailSender
+	trackingBaseURL string
+	logger          *slog.Logger
+}
+
+// NewService creates a new notification service
+func NewService(repo repository.NotificationRepository, logger *slog.Logger) *Service {
+	return &Service{repo: repo, logger: logger}
+}
+
+// WithPush enables the push channel
+func (s *Service) WithPush(sender PushSender) *Service {
+	s.push = sender
+	return s
+}
+
+// WithWebPush enables the browser push channel
+func (s *Service) WithWebPush(sender WebPushSender) *Service {
+	s.webPush = sender
+	return s
+}
+
+// WithWebhook enables the webhook channel
+func (s *Service) WithWebhook(sender WebhookSender) *Service {
+	s.webhook = sender
+	return s
+}
+
+// WithTelegram enables the Telegram bot channel
+func (s *Service) WithTelegram(sender TelegramSender) *Service {
+	s.telegram = sender
+	return s
+}
+
+// WithEmail enables the email channel; opens are tracked through baseURL + EmailOpenPath
+func (s *Service) WithEmail(sender EmailSender, baseURL string) *Service {
+	s.email = sender
+	s.trackingBaseURL = strings.TrimRight(baseURL, "/")
+	return s
+}
+
+// Send records a notification in the user's inbox and delivers it on the
+// requested channels the user hasn't turned off. Push, web push and Telegram
+// wait out the user's quiet hours. Channel failures are recorded on the delivery rather
+// than returned, so the inbox always reflects what was attempted.
+func (s *Service) Send(ctx context.Context, input SendInput) (*repository.Notification, error) {
+	switch input.Kind {
+	case repository.KindAlert, repository.KindDigest, repository.KindSystem:
+	default:
+		return nil, ErrInvalidNotification
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return nil, ErrInvalidNotification
+	}
+
+	now := time.Now()
+	n := &repository.Notification{
+		UserID:     input.UserID,
+		Kind:       input.Kind,
+		Title:      input.Title,
+		Body:       input.Body,
+		Metadata:   input.Metadata,
+		SourceType: input.SourceType,
+		SourceID:   input.SourceID,
+		Deliveries: []*repository.Delivery{{
+			Channel:     repository.ChannelInApp,
+			Status:      repository.DeliveryStatusDelivered,
+			SentAt:      &now,
+			DeliveredAt: &now,
+		}},
+	}
+
+	contact, err := s.contactFor(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, ch := range input.Channels {
+		if !s.canDeliver(ch, contact) || n.Delivery(ch) != nil {
+			continue
+		}
+		d := &repository.Delivery{Channel: ch, Status: repository.DeliveryStatusPending}
+		if ch == repository.ChannelPush || ch == repository.ChannelWebPush || ch == repository.ChannelTelegram {
+			if until, quiet := contact.Preferences.QuietUntil(now); quiet {
+				d.ScheduledFor = &until
+				d.Payload = input.PushData
+			}
+		}
+		n.Deliveries = append(n.Deliveries, d)
+	}
+
+	if err := s.repo.Create(ctx, n); err != nil {
+		return nil, err
+	}
+
+	for _, d := range n.Deliveries {
+		if d.ScheduledFor == nil {
+			s.deliver(ctx, n, d, contact, input.PushData)
+		}
+	}
+	return n, nil
+}
+
+// DeliverDeferred sends the deliveries quiet hours held back until now.
+// Returns how many notifications were processed.
+func (s *Service) DeliverDeferred(ctx context.Context, now time.Time) (int, error) {
+	notifications, err := s.repo.ClaimDeferred(ctx, now, deferredBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, n := range notifications {
+		contact, err := s.repo.GetContact(ctx, n.UserID)
+		for _, d := range n.Deliveries {
+			if err != nil {
+				s.recordFailure(ctx, d, err)
+				continue
+			}
+			s.deliver(ctx, n, d, contact, d.Payload)
+		}
+	}
+	return len(notifications), nil
+}
+
+// GetPreferences returns the user's notification preferences
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (*repository.Preferences, error) {
+	return s.repo.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences validates and saves the user's notification preferences
+func (s *Service) UpdatePreferences(ctx context.Context, userID uuid.UUID, prefs *repository.Preferences) error {
+	if prefs.Timezone == "" {
+		prefs.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidPreferences, prefs.Timezone)
+	}
+	if (prefs.QuietStart == nil) != (prefs.QuietEnd == nil) {
+		return fmt.Errorf("%w: quiet hours need a start and an end", ErrInvalidPreferences)
+	}
+	for _, m := range []*int{prefs.QuietStart, prefs.QuietEnd} {
+		if m != nil && (*m < 0 || *m >= 24*60) {
+			return fmt.Errorf("%w: quiet hours are minutes after midnight", ErrInvalidPreferences)
+		}
+	}
+	if prefs.WebhookURL != "" {
+		if err := webhook.ValidateURL(prefs.WebhookURL); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+		}
+	}
+	return s.repo.SavePreferences(ctx, userID, prefs)
+}
+
+// SubscribeWebPush registers a browser for web push
+func (s *Service) SubscribeWebPush(ctx context.Context, userID uuid.UUID, sub webpush.Subscription) (*repository.WebPushSubscription, error) {
+	if sub.Endpoint == "" || sub.P256dh == "" || sub.Auth == "" {
+		return nil, fmt.Errorf("%w: web push subscriptions need an endpoint and keys", ErrInvalidPreferences)
+	}
+	saved := &repository.WebPushSubscription{UserID: userID, Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}
+	if err := s.repo.SaveWebPushSubscription(ctx, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// UnsubscribeWebPush removes one of the user's browsers
+func (s *Service) UnsubscribeWebPush(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	return s.repo.DeleteWebPushSubscription(ctx, &userID, endpoint)
+}
+
+// ListNotifications returns a page of the user's inbox
+func (s *Service) ListNotifications(ctx context.Context, userID uuid.UUID, filter repository.ListFilter) (*Inbox, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	notifications, total, err := s.repo.List(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []*repository.Notification{}
+	}
+	return &Inbox{Notifications: notifications, Total: total, Unread: unread}, nil
+}
+
+// MarkRead marks a notification as read in-app. Returns false if it doesn't
+// exist or belongs to another user.
+func (s *Service) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (bool, error) {
+	err := s.repo.MarkRead(ctx, userID, notificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkSourceRead marks notifications created from a source record (e.g. an alert) as read
+func (s *Service) MarkSourceRead(ctx context.Context, sourceType string, sourceID uuid.UUID) error {
+	return s.repo.MarkSourceRead(ctx, sourceType, sourceID)
+}
+
+// RecordEmailOpened records an open reported by the email tracking pixel
+func (s *Service) RecordEmailOpened(ctx context.Context, deliveryID uuid.UUID) error {
+	return s.repo.UpdateDelivery(ctx, deliveryID, repository.DeliveryStatusOpened, nil, nil)
+}
+
+// CheckPushReceipts exchanges pending push tickets for delivery receipts
+func (s *Service) CheckPushReceipts(ctx context.Context) (*ReceiptResult, error) {
+	result := &ReceiptResult{}
+	if s.push == nil {
+		return result, nil
+	}
+
+	now := time.Now()
+	deliveries, err := s.repo.ListAwaitingReceipt(ctx, now.Add(-receiptRetention), now.Add(-receiptDelay), receiptBatchSize)
+	if err != nil || len(deliveries) == 0 {
+		return result, err
+	}
+
+	byTicket := make(map[string]*repository.Delivery, len(deliveries))
+	tickets := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		byTicket[*d.ProviderMessageID] = d
+		tickets = append(tickets, *d.ProviderMessageID)
+	}
+
+	receipts, err := s.push.GetReceipts(ctx, tickets)
+	if err != nil {
+		return nil, err
+	}
+
+	for ticket, receipt := range receipts {
+		d, ok := byTicket[ticket]
+		if !ok {
+			continue
+		}
+		status, errMsg := repository.DeliveryStatusDelivered, (*string)(nil)
+		if receipt.Status != "ok" {
+			status = repository.DeliveryStatusFailed
+			msg := receipt.Message
+			if receipt.Details.Error != "" {
+				msg = receipt.Details.Error
+			}
+			errMsg = &msg
+		}
+		if err := s.repo.UpdateDelivery(ctx, d.ID, status, nil, errMsg); err != nil {
+			return result, fmt.Errorf("failed to record push receipt: %w", err)
+		}
+		if status == repository.DeliveryStatusFailed {
+			result.Failed++
+		} else {
+			result.Delivered++
+		}
+	}
+	return result, nil
+}
+
+// deliver sends one delivery on its channel
+func (s *Service) deliver(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact, pushData map[string]any) {
+	switch d.Channel {
+	case repository.ChannelPush:
+		s.deliverPush(ctx, n, d, contact, pushData)
+	case repository.ChannelWebPush:
+		s.deliverWebPush(ctx, n, d, contact, pushData)
+	case repository.ChannelEmail:
+		s.deliverEmail(ctx, n, d, contact)
+	case repository.ChannelWebhook:
+		s.deliverWebhook(ctx, n, d, contact)
+	case repository.ChannelTelegram:
+		s.deliverTelegram(ctx, n, d, contact)
+	}
+}
+
+// contactFor loads the user's addresses when an external channel is requested
+func (s *Service) contactFor(ctx context.Context, input SendInput) (*repository.Contact, error) {
+	for _, ch := range input.Channels {
+		if ch == repository.ChannelInApp {
+			continue
+		}
+		contact, err := s.repo.GetContact(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return contact, nil
+	}
+	return nil, nil
+}
+
+// canDeliver reports whether a channel is configured, the user is reachable on
+// it and hasn't turned it off
+func (s *Service) canDeliver(ch repository.Channel, contact *repository.Contact) bool {
+	if contact == nil {
+		return false
+	}
+	prefs := contact.Preferences
+	if prefs == nil {
+		prefs = repository.DefaultPreferences()
+	}
+	switch ch {
+	case repository.ChannelPush:
+		return s.push != nil && contact.PushToken != "" && prefs.PushEnabled
+	case repository.ChannelWebPush:
+		return s.webPush != nil && len(contact.WebPush) > 0 && prefs.WebPushEnabled
+	case repository.ChannelEmail:
+		return s.email != nil && contact.Email != "" && prefs.EmailEnabled
+	case repository.ChannelWebhook:
+		return s.webhook != nil && prefs.WebhookURL != ""
+	case repository.ChannelTelegram:
+		return s.telegram != nil && contact.TelegramChatID != nil && prefs.TelegramEnabled
+	default:
+		return false
+	}
+}
+
+func (s *Service) deliverPush(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact, data map[string]any) {
+	payload := map[string]any{"notification_id": n.ID.String(), "kind": string(n.Kind)}
+	for k, v := range data {
+		payload[k] = v
+	}
+
+	ticket, err := s.push.SendTicket(ctx, &push.Message{
+		To:    contact.PushToken,
+		Title: n.Title,
+		Body:  n.Body,
+		Data:  payload,
+	})
+	if err != nil {
+		s.recordFailure(ctx, d, err)
+		return
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusSent, &ticket, nil)
+}
+
+// deliverWebPush sends to every subscribed browser, dropping subscriptions the
+// push service reports gone. It succeeds if any browser accepted the message.
+func (s *Service) deliverWebPush(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact, data map[string]any) {
+	payload := map[string]any{"notification_id": n.ID.String(), "kind": string(n.Kind)}
+	for k, v := range data {
+		payload[k] = v
+	}
+	msg := &webpush.Message{Title: n.Title, Body: n.Body, Data: payload}
+
+	var lastErr error
+	sent := false
+	for _, sub := range contact.WebPush {
+		err := s.webPush.Send(ctx, webpush.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, msg)
+		if errors.Is(err, webpush.ErrSubscriptionGone) {
+			if err := s.repo.DeleteWebPushSubscription(ctx, nil, sub.Endpoint); err != nil && s.logger != nil {
+				s.logger.Warn("failed to remove expired web push subscription", slog.Any("error", err))
+			}
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		sent = true
+	}
+	if !sent {
+		if lastErr == nil {
+			lastErr = errors.New("no web push subscriptions")
+		}
+		s.recordFailure(ctx, d, lastErr)
+		return
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusSent, nil, nil)
+}
+
+// deliverWebhook posts the notification to the user's webhook
+func (s *Service) deliverWebhook(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact) {
+	prefs := contact.Preferences
+	if prefs == nil || prefs.WebhookURL == "" {
+		s.recordFailure(ctx, d, errors.New("no webhook configured"))
+		return
+	}
+	data := map[string]any{
+		"notification_id": n.ID.String(),
+		"kind":            string(n.Kind),
+		"title":           n.Title,
+		"body":            n.Body,
+		"metadata":        n.Metadata,
+	}
+	if n.SourceType != nil && n.SourceID != nil {
+		data["source_type"] = *n.SourceType
+		data["source_id"] = n.SourceID.String()
+	}
+	event := &webhook.Event{
+		ID:        d.ID.String(),
+		Type:      "notification." + string(n.Kind),
+		CreatedAt: n.CreatedAt,
+		Data:      data,
+	}
+	if err := s.webhook.Send(ctx, prefs.WebhookURL, prefs.WebhookSecret, event); err != nil {
+		s.recordFailure(ctx, d, err)
+		return
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusDelivered, nil, nil)
+}
+
+// deliverTelegram sends the notification as a plain-text bot message
+func (s *Service) deliverTelegram(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact) {
+	if contact.TelegramChatID == nil {
+		s.recordFailure(ctx, d, errors.New("no telegram chat linked"))
+		return
+	}
+	text := n.Title
+	if n.Body != "" {
+		text += "\n\n" + n.Body
+	}
+	if err := s.telegram.SendMessage(ctx, *contact.TelegramChatID, text); err != nil {
+		s.recordFailure(ctx, d, err)
+		return
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusDelivered, nil, nil)
+}
+
+func (s *Service) deliverEmail(ctx context.Context, n *repository.Notification, d *repository.Delivery, contact *repository.Contact) {
+	trackingURL := ""
+	if s.trackingBaseURL != "" {
+		trackingURL = s.trackingBaseURL + EmailOpenPath + d.ID.String()
+	}
+	if err := s.email.SendNotificationEmail(contact.Email, contact.Name, n.Title, n.Body, trackingURL); err != nil {
+		s.recordFailure(ctx, d, err)
+		return
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusSent, nil, nil)
+}
+
+func (s *Service) recordFailure(ctx context.Context, d *repository.Delivery, err error) {
+	msg := err.Error()
+	if s.logger != nil {
+		s.logger.Warn("notification delivery failed",
+			slog.String("notification_id", d.NotificationID.String()),
+			slog.String("channel", string(d.Channel)),
+			slog.Any("error", err),
+		)
+	}
+	s.updateDelivery(ctx, d, repository.DeliveryStatusFailed, nil, &msg)
+}
+
+func (s *Service) updateDelivery(ctx context.Context, d *repository.Delivery, status repository.DeliveryStatus, providerMessageID, errMsg *string) {
+	if err := s.repo.UpdateDelivery(ctx, d.ID, status, providerMessageID, errMsg); err != nil {
+		if s.logger != nil {
+			s.logger.Warn("failed to record notification delivery", slog.String("delivery_id", d.ID.String()), slog.Any("error", err))
+		}
+		return
+	}
+	d.Status = status
+	d.ProviderMessageID = providerMessageID
+	d.Error = errMsg
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/push"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webhook"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webpush"
+)
+
+// fakeNotificationRepository keeps notifications in memory.
+type fakeNotificationRepository struct {
+	notifications []*repository.Notification
+	deliveries    map[uuid.UUID]*repository.Delivery
+	contact       *repository.Contact
+}
+
+func newFakeNotificationRepository(contact *repository.Contact) *fakeNotificationRepository {
+	return &fakeNotificationRepository{deliveries: map[uuid.UUID]*repository.Delivery{}, contact: contact}
+}
+
+func (f *fakeNotificationRepository) Create(ctx context.Context, n *repository.Notification) error {
+	n.ID = uuid.New()
+	for _, d := range n.Deliveries {
+		d.ID = uuid.New()
+		d.NotificationID = n.ID
+		f.deliveries[d.ID] = d
+	}
+	f.notifications = append(f.notifications, n)
+	return nil
+}
+
+func (f *fakeNotificationRepository) List(ctx context.Context, userID uuid.UUID, filter repository.ListFilter) ([]*repository.Notification, int, error) {
+	var out []*repository.Notification
+	for _, n := range f.notifications {
+		if n.UserID == userID && (!filter.UnreadOnly || !n.IsRead()) {
+			out = append(out, n)
+		}
+	}
+	return out, len(out), nil
+}
+
+func (f *fakeNotificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	_, count, _ := f.List(ctx, userID, repository.ListFilter{UnreadOnly: true})
+	return count, nil
+}
+
+func (f *fakeNotificationRepository) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	for _, n := range f.notifications {
+		if n.ID == notificationID && n.UserID == userID {
+			n.Delivery(repository.ChannelInApp).Status = repository.DeliveryStatusOpened
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeNotificationRepository) MarkSourceRead(ctx context.Context, sourceType string, sourceID uuid.UUID) error {
+	return nil
+}
+
+func (f *fakeNotificationRepository) UpdateDelivery(ctx context.Context, deliveryID uuid.UUID, status repository.DeliveryStatus, providerMessageID, errMsg *string) error {
+	d, ok := f.deliveries[deliveryID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	d.Status = status
+	if providerMessageID != nil {
+		d.ProviderMessageID = providerMessageID
+	}
+	d.Error = errMsg
+	return nil
+}
+
+func (f *fakeNotificationRepository) ListAwaitingReceipt(ctx context.Context, sentAfter, sentBefore time.Time, limit int) ([]*repository.Delivery, error) {
+	var out []*repository.Delivery
+	for _, d := range f.deliveries {
+		if d.Channel == repository.ChannelPush && d.Status == repository.DeliveryStatusSent {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeNotificationRepository) ClaimDeferred(ctx context.Context, dueBefore time.Time, limit int) ([]*repository.Notification, error) {
+	var out []*repository.Notification
+	for _, n := range f.notifications {
+		claimed := &repository.Notification{ID: n.ID, UserID: n.UserID, Kind: n.Kind, Title: n.Title, Body: n.Body}
+		for _, d := range n.Deliveries {
+			if d.Status == repository.DeliveryStatusPending && d.ScheduledFor != nil && !d.ScheduledFor.After(dueBefore) {
+				d.ScheduledFor = nil
+				claimed.Deliveries = append(claimed.Deliveries, d)
+			}
+		}
+		if len(claimed.Deliveries) > 0 {
+			out = append(out, claimed)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeNotificationRepository) GetContact(ctx context.Context, userID uuid.UUID) (*repository.Contact, error) {
+	return f.contact, nil
+}
+
+func (f *fakeNotificationRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*repository.Preferences, error) {
+	if f.contact != nil && f.contact.Preferences != nil {
+		return f.contact.Preferences, nil
+	}
+	return repository.DefaultPreferences(), nil
+}
+
+func (f *fakeNotificationRepository) SavePreferences(ctx context.Context, userID uuid.UUID, prefs *repository.Preferences) error {
+	f.contact.Preferences = prefs
+	return nil
+}
+
+func (f *fakeNotificationRepository) SaveWebPushSubscription(ctx context.Context, sub *repository.WebPushSubscription) error {
+	sub.ID = uuid.New()
+	f.contact.WebPush = append(f.contact.WebPush, sub)
+	return nil
+}
+
+func (f *fakeNotificationRepository) DeleteWebPushSubscription(ctx context.Context, userID *uuid.UUID, endpoint string) error {
+	kept := f.contact.WebPush[:0]
+	for _, sub := range f.contact.WebPush {
+		if sub.Endpoint != endpoint {
+			kept = append(kept, sub)
+		}
+	}
+	f.contact.WebPush = kept
+	return nil
+}
+
+type fakePushSender struct {
+	err      error
+	receipts map[string]push.Receipt
+}
+
+func (f *fakePushSender) SendTicket(ctx context.Context, msg *push.Message) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return "ticket-" + msg.Data["notification_id"].(string), nil
+}
+
+func (f *fakePushSender) GetReceipts(ctx context.Context, ticketIDs []string) (map[string]push.Receipt, error) {
+	return f.receipts, nil
+}
+
+type fakeWebPushSender struct {
+	gone map[string]bool
+	sent []string
+}
+
+func (f *fakeWebPushSender) Send(ctx context.Context, sub webpush.Subscription, msg *webpush.Message) error {
+	if f.gone[sub.Endpoint] {
+		return webpush.ErrSubscriptionGone
+	}
+	f.sent = append(f.sent, sub.Endpoint)
+	return nil
+}
+
+type fakeWebhookSender struct {
+	events []*webhook.Event
+}
+
+func (f *fakeWebhookSender) Send(ctx context.Context, targetURL, secret string, event *webhook.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestSend_RecordsDeliveryPerChannel(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakeNotificationRepository(&repository.Contact{Email: "user@example.com", PushToken: "ExponentPushToken[abc123456789]"})
+	sender := &fakePushSender{}
+	svc := NewService(repo, nil).WithPush(sender)
+
+	n, err := svc.Send(ctx, SendInput{
+		UserID:   userID,
+		Kind:     repository.KindAlert,
+		Title:    "Spending pace",
+		Channels: []repository.Channel{repository.ChannelPush, repository.ChannelEmail},
+	})
+	require.NoError(t, err)
+
+	// Email isn't configured, so only in-app and push are recorded
+	require.Len(t, n.Deliveries, 2)
+	assert.Equal(t, repository.DeliveryStatusDelivered, n.Delivery(repository.ChannelInApp).Status)
+	pushDelivery := n.Delivery(repository.ChannelPush)
+	assert.Equal(t, repository.DeliveryStatusSent, pushDelivery.Status)
+	assert.Equal(t, "ticket-"+n.ID.String(), *pushDelivery.ProviderMessageID)
+
+	// Receipts move push deliveries to delivered or failed
+	receipt := push.Receipt{Status: "error"}
+	receipt.Details.Error = "DeviceNotRegistered"
+	sender.receipts = map[string]push.Receipt{*pushDelivery.ProviderMessageID: receipt}
+	result, err := svc.CheckPushReceipts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, repository.DeliveryStatusFailed, pushDelivery.Status)
+	assert.Equal(t, "DeviceNotRegistered", *pushDelivery.Error)
+}
+
+func TestSend_RecordsPushFailure(t *testing.T) {
+	repo := newFakeNotificationRepository(&repository.Contact{PushToken: "ExponentPushToken[abc123456789]"})
+	svc := NewService(repo, nil).WithPush(&fakePushSender{err: errors.New("expo unavailable")})
+
+	n, err := svc.Send(context.Background(), SendInput{
+		UserID:   uuid.New(),
+		Kind:     repository.KindSystem,
+		Title:    "Maintenance tonight",
+		Channels: []repository.Channel{repository.ChannelPush},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, repository.DeliveryStatusFailed, n.Delivery(repository.ChannelPush).Status)
+	assert.Equal(t, "expo unavailable", *n.Delivery(repository.ChannelPush).Error)
+}
+
+func TestListNotificationsAndMarkRead(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	repo := newFakeNotificationRepository(nil)
+	svc := NewService(repo, nil)
+
+	_, err := svc.Send(ctx, SendInput{UserID: owner, Kind: "newsletter", Title: "Hi"})
+	assert.ErrorIs(t, err, ErrInvalidNotification)
+
+	n, err := svc.Send(ctx, SendInput{UserID: owner, Kind: repository.KindDigest, Title: "Your March recap"})
+	require.NoError(t, err)
+
+	inbox, err := svc.ListNotifications(ctx, owner, repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, inbox.Total)
+	assert.Equal(t, 1, inbox.Unread)
+
+	ok, err := svc.MarkRead(ctx, uuid.New(), n.ID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = svc.MarkRead(ctx, owner, n.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	inbox, err = svc.ListNotifications(ctx, owner, repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, inbox.Unread)
+	assert.True(t, inbox.Notifications[0].IsRead())
+}
+
+func TestSend_FansOutToWebPushAndWebhook(t *testing.T) {
+	repo := newFakeNotificationRepository(&repository.Contact{
+		Email: "user@example.com",
+		WebPush: []*repository.WebPushSubscription{
+			{Endpoint: "https://push.example.com/expired"},
+			{Endpoint: "https://push.example.com/laptop"},
+		},
+		Preferences: &repository.Preferences{EmailEnabled: false, WebPushEnabled: true, WebhookURL: "https://hooks.example.com/echo", Timezone: "UTC"},
+	})
+	webPush := &fakeWebPushSender{gone: map[string]bool{"https://push.example.com/expired": true}}
+	hooks := &fakeWebhookSender{}
+	svc := NewService(repo, nil).WithWebPush(webPush).WithWebhook(hooks)
+
+	n, err := svc.Send(context.Background(), SendInput{
+		UserID:   uuid.New(),
+		Kind:     repository.KindAlert,
+		Title:    "Budget exceeded",
+		Channels: []repository.Channel{repository.ChannelWebPush, repository.ChannelWebhook, repository.ChannelEmail},
+	})
+	require.NoError(t, err)
+
+	// Email is turned off; the expired browser is dropped
+	assert.Nil(t, n.Delivery(repository.ChannelEmail))
+	assert.Equal(t, repository.DeliveryStatusSent, n.Delivery(repository.ChannelWebPush).Status)
+	assert.Equal(t, []string{"https://push.example.com/laptop"}, webPush.sent)
+	require.Len(t, repo.contact.WebPush, 1)
+
+	assert.Equal(t, repository.DeliveryStatusDelivered, n.Delivery(repository.ChannelWebhook).Status)
+	require.Len(t, hooks.events, 1)
+	assert.Equal(t, "notification.alert", hooks.events[0].Type)
+}
+
+func TestSend_HoldsPushDuringQuietHours(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	minute := now.Hour()*60 + now.Minute()
+	start, end := (minute+24*60-60)%(24*60), (minute+60)%(24*60)
+
+	repo := newFakeNotificationRepository(&repository.Contact{
+		PushToken:   "ExponentPushToken[abc123456789]",
+		Preferences: &repository.Preferences{PushEnabled: true, QuietStart: &start, QuietEnd: &end, Timezone: "UTC"},
+	})
+	svc := NewService(repo, nil).WithPush(&fakePushSender{})
+
+	n, err := svc.Send(ctx, SendInput{
+		UserID:   uuid.New(),
+		Kind:     repository.KindAlert,
+		Title:    "Spending pace",
+		Channels: []repository.Channel{repository.ChannelPush},
+		PushData: map[string]any{"alert_type": "pace_warning"},
+	})
+	require.NoError(t, err)
+
+	pushDelivery := n.Delivery(repository.ChannelPush)
+	assert.Equal(t, repository.DeliveryStatusPending, pushDelivery.Status)
+	require.NotNil(t, pushDelivery.ScheduledFor)
+	assert.WithinDuration(t, now.Add(time.Hour), *pushDelivery.ScheduledFor, time.Minute)
+
+	// Nothing is due until the quiet hours end
+	processed, err := svc.DeliverDeferred(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	processed, err = svc.DeliverDeferred(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, repository.DeliveryStatusSent, pushDelivery.Status)
+}
+
+func TestUpdatePreferences_Validates(t *testing.T) {
+	svc := NewService(newFakeNotificationRepository(&repository.Contact{}), nil)
+	start := 22 * 60
+
+	cases := map[string]*repository.Preferences{
+		"timezone":         {Timezone: "Mars/Olympus"},
+		"half quiet hours": {QuietStart: &start},
+		"http webhook":     {WebhookURL: "http://hooks.example.com"},
+	}
+	for name, prefs := range cases {
+		err := svc.UpdatePreferences(context.Background(), uuid.New(), prefs)
+		assert.ErrorIs(t, err, ErrInvalidPreferences, name)
+	}
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// writableTables are the tables of entities clients can change offline
+var writableTables = map[string]string{
+	EntityTransaction: "transactions",
+	EntityCategory:    "categories",
+	EntityGoal:        "goals",
+}
+
+// trashedTables are the writable tables whose rows are deleted to the trash
+var trashedTables = map[string]bool{
+	"transactions": true,
+	"goals":        true,
+}
+
+// changeColumns selects a change with its entity's current row. Plans carry
+// their groups, categories and items.
+const changeColumns = `
+	c.seq, c.entity_type, c.entity_id, c.deleted, c.changed_at,
+	CASE WHEN NOT c.deleted THEN CASE c.entity_type
+		WHEN 'transaction' THEN (SELECT to_jsonb(x) FROM transactions x WHERE x.id = c.entity_id)
+		WHEN 'category' THEN (SELECT to_jsonb(x) FROM categories x WHERE x.id = c.entity_id)
+		WHEN 'rule' THEN (SELECT to_jsonb(x) FROM category_rules x WHERE x.id = c.entity_id)
+		WHEN 'goal' THEN (SELECT to_jsonb(x) FROM goals x WHERE x.id = c.entity_id)
+		WHEN 'plan' THEN (
+			SELECT to_jsonb(p) || jsonb_build_object(
+				'groups', COALESCE((SELECT jsonb_agg(to_jsonb(g) ORDER BY g.sort_order) FROM plan_category_groups g WHERE g.plan_id = p.id), '[]'),
+				'categories', COALESCE((SELECT jsonb_agg(to_jsonb(pc) ORDER BY pc.sort_order) FROM plan_categories pc WHERE pc.plan_id = p.id), '[]'),
+				'items', COALESCE((SELECT jsonb_agg(to_jsonb(i) ORDER BY i.sort_order) FROM plan_items i WHERE i.plan_id = p.id), '[]'))
+			FROM user_plans p WHERE p.id = c.entity_id)
+	END END`
+
+// PostgresSyncRepository implements SyncRepository using PostgreSQL
+type PostgresSyncRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSyncRepository creates a new PostgreSQL sync repository
+func NewPostgresSyncRepository(pool *pgxpool.Pool) *PostgresSyncRepository {
+	return &PostgresSyncRepository{pool: pool}
+}
+
+// ListChanges pages through the user's change feed. A change is settled once
+// no transaction older than the one that wrote it is still running; only then
+// can no change with a lower seq still appear.
+func (r *PostgresSyncRepository) ListChanges(ctx context.Context, userID uuid.UUID, after int64, limit int) ([]Change, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+changeColumns+`,
+		       c.xid < pg_snapshot_xmin(pg_current_snapshot())
+		FROM sync_changes c
+		WHERE c.user_id = $1 AND c.seq > $2
+		ORDER BY c.seq
+		LIMIT $3
+	`, userID, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []Change
+	for rows.Next() {
+		var c Change
+		var settled bool
+		if err := rows.Scan(&c.Seq, &c.EntityType, &c.EntityID, &c.Deleted, &c.ChangedAt, &c.Data, &settled); err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		if !settled {
+			break
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// ApplyMutation locks the entity, compares its seq with the mutation's base
+// and writes the change, all in one transaction. The entity row is locked
+// before its feed row, the order the feed trigger takes them in.
+func (r *PostgresSyncRepository) ApplyMutation(ctx context.Context, userID uuid.UUID, m *Mutation) (*MutationResult, error) {
+	table, ok := writableTables[m.EntityType]
+	if !ok {
+		return nil, fmt.Errorf("entity type %q can't be changed offline", m.EntityType)
+	}
+	result := &MutationResult{EntityType: m.EntityType, EntityID: m.EntityID}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var owner uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT user_id FROM `+table+` WHERE id = $1 FOR UPDATE`, m.EntityID).Scan(&owner)
+	exists := err == nil
+	switch {
+	case err != nil && !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to lock %s: %w", m.EntityType, err)
+	case exists && owner != userID:
+		result.Status, result.Reason = MutationRejected, m.EntityType+" not found"
+		return result, nil
+	}
+
+	var seq int64
+	var deleted bool
+	err = tx.QueryRow(ctx, `
+		SELECT seq, deleted FROM sync_changes
+		WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+		FOR UPDATE
+	`, userID, m.EntityType, m.EntityID).Scan(&seq, &deleted)
+	tracked := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get entity version: %w", err)
+	}
+
+	switch {
+	case m.Delete && (!exists || deleted):
+		// Already gone; deleting again is a no-op
+		result.Status, result.Seq = MutationApplied, seq
+		return result, nil
+	case !tracked && m.BaseSeq != 0:
+		result.Status, result.Reason = MutationRejected, m.EntityType+" not found"
+		return result, nil
+	case tracked && seq > m.BaseSeq && !m.Force:
+		server, err := loadChange(ctx, tx, userID, m.EntityType, m.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		result.Status, result.Seq, result.Server = MutationConflict, seq, server
+		return result, nil
+	}
+
+	switch {
+	case m.Delete && trashedTables[table]:
+		_, err = tx.Exec(ctx, `UPDATE `+table+` SET deleted_at = NOW() WHERE id = $1 AND user_id = $2`, m.EntityID, userID)
+	case m.Delete:
+		_, err = tx.Exec(ctx, `DELETE FROM `+table+` WHERE id = $1 AND user_id = $2`, m.EntityID, userID)
+	default:
+		var reason string
+		reason, err = upsert(ctx, tx, userID, m)
+		if err == nil && reason != "" {
+			result.Status, result.Reason = MutationRejected, reason
+			return result, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s mutation: %w", m.EntityType, err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT seq FROM sync_changes WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+	`, userID, m.EntityType, m.EntityID).Scan(&result.Seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity version: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit mutation: %w", err)
+	}
+	result.Status = MutationApplied
+	return result, nil
+}
+
+// upsert creates or updates the mutation's entity. Returns a reason when it
+// references an account or category that isn't the user's.
+func upsert(ctx context.Context, tx pgx.Tx, userID uuid.UUID, m *Mutation) (string, error) {
+	switch {
+	case m.Transaction != nil:
+		d := m.Transaction
+		if reason, err := checkOwned(ctx, tx, userID, "accounts", "account", d.AccountID); reason != "" || err != nil {
+			return reason, err
+		}
+		if reason, err := checkOwned(ctx, tx, userID, "categories", "category", d.CategoryID); reason != "" || err != nil {
+			return reason, err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO transactions (
+				id, user_id, account_id, category_id, posted_at, description,
+				amount_minor, currency_code, notes, tags, source
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10::TEXT[], '{}'), 'manual')
+			ON CONFLICT (id) DO UPDATE SET
+				account_id = EXCLUDED.account_id,
+				category_id = EXCLUDED.category_id,
+				posted_at = EXCLUDED.posted_at,
+				description = EXCLUDED.description,
+				amount_minor = EXCLUDED.amount_minor,
+				currency_code = EXCLUDED.currency_code,
+				notes = EXCLUDED.notes,
+				tags = EXCLUDED.tags,
+				deleted_at = NULL
+			WHERE transactions.user_id = EXCLUDED.user_id
+		`, m.EntityID, userID, d.AccountID, d.CategoryID, d.PostedAt, d.Description,
+			d.AmountMinor, d.CurrencyCode, d.Notes, d.Tags)
+		return "", err
+
+	case m.Category != nil:
+		d := m.Category
+		if reason, err := checkOwned(ctx, tx, userID, "categories", "parent category", d.ParentID); reason != "" || err != nil {
+			return reason, err
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO categories (id, user_id, parent_id, name, color, icon)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (id) DO UPDATE SET
+				parent_id = EXCLUDED.parent_id,
+				name = EXCLUDED.name,
+				color = EXCLUDED.color,
+				icon = EXCLUDED.icon
+			WHERE categories.user_id = EXCLUDED.user_id
+		`, m.EntityID, userID, d.ParentID, d.Name, d.Color, d.Icon)
+		return "", err
+
+	case m.Goal != nil:
+		d := m.Goal
+		_, err := tx.Exec(ctx, `
+			INSERT INTO goals (
+				id, user_id, name, type, status, target_amount_minor, currency_code, start_at, end_at
+			) VALUES ($1, $2, $3, $4::goal_type, $5::goal_status, $6, $7, $8, $9)
+			ON CONFLICT (id) DO UPDATE SET
+				name = EXCLUDED.name,
+				type = EXCLUDED.type,
+				status = EXCLUDED.status,
+				target_amount_minor = EXCLUDED.target_amount_minor,
+				currency_code = EXCLUDED.currency_code,
+				start_at = EXCLUDED.start_at,
+				end_at = EXCLUDED.end_at,
+				deleted_at = NULL
+			WHERE goals.user_id = EXCLUDED.user_id
+		`, m.EntityID, userID, d.Name, d.Type, d.Status, d.TargetAmountMinor, d.CurrencyCode, d.StartAt, d.EndAt)
+		return "", err
+	}
+	return "", fmt.Errorf("%s mutation has no data", m.EntityType)
+}
+
+// checkOwned returns a reason when id is set but isn't one of the user's rows in table
+func checkOwned(ctx context.Context, tx pgx.Tx, userID uuid.UUID, table, what string, id *uuid.UUID) (string, error) {
+	if id == nil {
+		return "", nil
+	}
+	var owned bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1 AND user_id = $2)`, *id, userID).Scan(&owned)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s: %w", table, err)
+	}
+	if !owned {
+		return fmt.Sprintf("%s %s not found", what, id), nil
+	}
+	return "", nil
+}
+
+// loadChange reads an entity's current change
+func loadChange(ctx context.Context, tx pgx.Tx, userID uuid.UUID, entityType string, entityID uuid.UUID) (*Change, error) {
+	var c Change
+	err := tx.QueryRow(ctx, `
+		SELECT `+changeColumns+`
+		FROM sync_changes c
+		WHERE c.user_id = $1 AND c.entity_type = $2 AND c.entity_id = $3
+	`, userID, entityType, entityID).Scan(&c.Seq, &c.EntityType, &c.EntityID, &c.Deleted, &c.ChangedAt, &c.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", entityType, err)
+	}
+	return &c, nil
+}
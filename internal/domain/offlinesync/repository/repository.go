@@ -0,0 +1,105 @@
+// Package repository provides database access for the offline sync change feed.
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types in the change feed
+const (
+	EntityTransaction = "transaction"
+	EntityCategory    = "category"
+	EntityRule        = "rule"
+	EntityPlan        = "plan"
+	EntityGoal        = "goal"
+)
+
+// Change is the latest change to an entity
+type Change struct {
+	Seq        int64 // Cursor position
+	EntityType string
+	EntityID   uuid.UUID
+	Deleted    bool
+	ChangedAt  time.Time
+	Data       json.RawMessage // The entity's current row as JSON, nil when deleted
+}
+
+// TransactionData is a transaction as offline clients write it
+type TransactionData struct {
+	AccountID    *uuid.UUID `json:"account_id"`
+	CategoryID   *uuid.UUID `json:"category_id"`
+	PostedAt     time.Time  `json:"posted_at"`
+	Description  string     `json:"description"`
+	AmountMinor  int64      `json:"amount_minor"`
+	CurrencyCode string     `json:"currency_code"`
+	Notes        *string    `json:"notes"`
+	Tags         []string   `json:"tags"`
+}
+
+// CategoryData is a category as offline clients write it
+type CategoryData struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Name     string     `json:"name"`
+	Color    *string    `json:"color"`
+	Icon     *string    `json:"icon"`
+}
+
+// GoalData is a goal as offline clients write it
+type GoalData struct {
+	Name              string    `json:"name"`
+	Type              string    `json:"type"`
+	Status            string    `json:"status"`
+	TargetAmountMinor int64     `json:"target_amount_minor"`
+	CurrencyCode      string    `json:"currency_code"`
+	StartAt           time.Time `json:"start_at"`
+	EndAt             time.Time `json:"end_at"`
+}
+
+// Mutation is a validated client change to apply. Exactly one of the data
+// fields is set for an upsert, matching EntityType.
+type Mutation struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Delete     bool
+	// BaseSeq is the seq of the version the client changed, 0 for entities it
+	// created. A server version newer than it is a conflict.
+	BaseSeq int64
+	// Force applies the mutation over a newer server version
+	Force bool
+
+	Transaction *TransactionData
+	Category    *CategoryData
+	Goal        *GoalData
+}
+
+// Outcomes of applying a mutation
+const (
+	MutationApplied  = "applied"
+	MutationConflict = "conflict" // The server version is newer; nothing was written
+	MutationRejected = "rejected" // Not the user's entity, or it references one that isn't
+)
+
+// MutationResult is the outcome of a mutation
+type MutationResult struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Status     string
+	Seq        int64   // The entity's seq after the mutation, the client's next BaseSeq
+	Server     *Change // The server version, on conflict
+	Reason     string  // Why the mutation was rejected
+}
+
+// SyncRepository defines data access for the change feed
+type SyncRepository interface {
+	// ListChanges lists up to limit changes after the cursor, oldest first.
+	// It stops before the first change of a transaction that may still be in
+	// flight, so a cursor never moves past a change not yet visible.
+	ListChanges(ctx context.Context, userID uuid.UUID, after int64, limit int) ([]Change, error)
+	// ApplyMutation applies a mutation unless the server version is newer than
+	// its BaseSeq, atomically with the check
+	ApplyMutation(ctx context.Context, userID uuid.UUID, m *Mutation) (*MutationResult, error)
+}
@@ -0,0 +1,306 @@
+// Package service provides the offline sync protocol: a change feed offline
+// clients pull from, and a push path for the changes they made offline.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/offlinesync/repository"
+	quotasservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/service"
+)
+
+// =============================================================================
+// Offline Sync (Internal Integration)
+// =============================================================================
+// Clients keep a cursor into the user's change feed and pull what changed
+// since (transactions, categories, rules, plans and goals), page by page until
+// HasMore is false. Changes made offline are pushed with the seq of the
+// version they were based on. The server wins conflicts: a mutation based on
+// an older version than the server's isn't applied, and its result carries
+// the server version for the client to merge and push again, or to push with
+// Force to keep its own.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - SyncChangesRequest/Response (FinanceService.SyncChanges) with SyncChange
+// - PushChangesRequest/Response (FinanceService.PushChanges) with
+//   SyncMutation and SyncMutationResult
+
+const (
+	// DefaultPageSize is the number of changes returned when no limit is given
+	DefaultPageSize = 500
+	// MaxPageSize caps a page of changes
+	MaxPageSize = 1000
+	// MaxPushMutations caps the mutations in one push
+	MaxPushMutations = 100
+
+	maxDescriptionLength = 500
+	maxNameLength        = 100
+	maxTags              = 20
+)
+
+// ErrTooManyMutations is returned for pushes over MaxPushMutations
+var ErrTooManyMutations = fmt.Errorf("a push can hold at most %d mutations", MaxPushMutations)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ClientMutation is a change made offline, as the client sends it
+type ClientMutation struct {
+	EntityType string
+	EntityID   uuid.UUID // Generated by the client for new entities
+	Delete     bool
+	BaseSeq    int64 // Seq of the version the change is based on, 0 for new entities
+	Force      bool  // Overwrite a newer server version
+	Data       json.RawMessage
+}
+
+// ChangeSet is a page of the change feed
+type ChangeSet struct {
+	Changes []repository.Change
+	Cursor  int64 // Pass back to get the next page
+	HasMore bool
+}
+
+// TransactionQuota limits how many transactions a user keeps (the quotas
+// service)
+type TransactionQuota interface {
+	CheckTransactions(ctx context.Context, userID uuid.UUID, adding int) error
+}
+
+// DashboardInvalidator is told when pushed mutations change a user's
+// transactions (the insights service)
+type DashboardInvalidator interface {
+	InvalidateDashboard(ctx context.Context, userID uuid.UUID, blockTypes ...string)
+}
+
+// Service implements the offline sync protocol
+type Service struct {
+	repo      repository.SyncRepository
+	txQuota   TransactionQuota     // Optional: nil leaves transactions unlimited
+	dashboard DashboardInvalidator // Optional: nil if nothing caches dashboards
+}
+
+// NewService creates a new sync service
+func NewService(repo repository.SyncRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// WithTransactionQuota rejects new transactions pushed once the user is at
+// their transaction quota
+func (s *Service) WithTransactionQuota(quota TransactionQuota) *Service {
+	s.txQuota = quota
+	return s
+}
+
+// WithDashboardInvalidator refreshes the user's dashboard after pushed
+// transaction changes are applied
+func (s *Service) WithDashboardInvalidator(dashboard DashboardInvalidator) *Service {
+	s.dashboard = dashboard
+	return s
+}
+
+// SyncChanges returns the changes after the cursor; 0 starts from the
+// beginning. limit is capped at MaxPageSize, 0 uses DefaultPageSize.
+// Changes of transactions still committing are left for the next call.
+func (s *Service) SyncChanges(ctx context.Context, userID uuid.UUID, cursor int64, limit int) (*ChangeSet, error) {
+	if cursor < 0 {
+		return nil, errors.New("cursor must not be negative")
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	changes, err := s.repo.ListChanges(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	set := &ChangeSet{Changes: changes, Cursor: cursor}
+	if len(changes) > limit {
+		set.Changes, set.HasMore = changes[:limit], true
+	}
+	if n := len(set.Changes); n > 0 {
+		set.Cursor = set.Changes[n-1].Seq
+	}
+	return set, nil
+}
+
+// PushMutations applies changes made offline in order. Invalid mutations are
+// rejected without stopping the rest; the results line up with mutations.
+func (s *Service) PushMutations(ctx context.Context, userID uuid.UUID, mutations []ClientMutation) ([]*repository.MutationResult, error) {
+	if len(mutations) > MaxPushMutations {
+		return nil, ErrTooManyMutations
+	}
+
+	results := make([]*repository.MutationResult, 0, len(mutations))
+	transactionsChanged := false
+	defer func() {
+		if transactionsChanged && s.dashboard != nil {
+			s.dashboard.InvalidateDashboard(ctx, userID)
+		}
+	}()
+	for _, cm := range mutations {
+		m, err := toMutation(cm)
+		if err == nil && s.txQuota != nil && cm.EntityType == repository.EntityTransaction && cm.BaseSeq == 0 && !cm.Delete {
+			if err = s.txQuota.CheckTransactions(ctx, userID, 1); err != nil && !errors.Is(err, quotasservice.ErrQuotaExceeded) {
+				return results, err
+			}
+		}
+		if err != nil {
+			results = append(results, &repository.MutationResult{
+				EntityType: cm.EntityType,
+				EntityID:   cm.EntityID,
+				Status:     repository.MutationRejected,
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		result, err := s.repo.ApplyMutation(ctx, userID, m)
+		if err != nil {
+			return results, err
+		}
+		if result.Status == repository.MutationApplied && cm.EntityType == repository.EntityTransaction {
+			transactionsChanged = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// toMutation validates a client mutation and decodes its data
+func toMutation(cm ClientMutation) (*repository.Mutation, error) {
+	if cm.EntityID == uuid.Nil {
+		return nil, errors.New("entity ID is required")
+	}
+	if cm.BaseSeq < 0 {
+		return nil, errors.New("base seq must not be negative")
+	}
+	m := &repository.Mutation{
+		EntityType: cm.EntityType,
+		EntityID:   cm.EntityID,
+		Delete:     cm.Delete,
+		BaseSeq:    cm.BaseSeq,
+		Force:      cm.Force,
+	}
+
+	switch cm.EntityType {
+	case repository.EntityTransaction:
+		if !cm.Delete {
+			m.Transaction = &repository.TransactionData{}
+			if err := decode(cm.Data, m.Transaction); err != nil {
+				return nil, err
+			}
+			return m, validateTransaction(m.Transaction)
+		}
+	case repository.EntityCategory:
+		if !cm.Delete {
+			m.Category = &repository.CategoryData{}
+			if err := decode(cm.Data, m.Category); err != nil {
+				return nil, err
+			}
+			if m.Category.ParentID != nil && *m.Category.ParentID == cm.EntityID {
+				return nil, errors.New("a category can't be its own parent")
+			}
+			return m, validateCategory(m.Category)
+		}
+	case repository.EntityGoal:
+		if !cm.Delete {
+			m.Goal = &repository.GoalData{}
+			if err := decode(cm.Data, m.Goal); err != nil {
+				return nil, err
+			}
+			return m, validateGoal(m.Goal)
+		}
+	case repository.EntityRule, repository.EntityPlan:
+		return nil, fmt.Errorf("%ss can't be changed offline", cm.EntityType)
+	default:
+		return nil, fmt.Errorf("unknown entity type %q", cm.EntityType)
+	}
+	return m, nil
+}
+
+func decode(data json.RawMessage, v any) error {
+	if len(data) == 0 {
+		return errors.New("data is required")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	return nil
+}
+
+func validateTransaction(d *repository.TransactionData) error {
+	d.Description = strings.TrimSpace(d.Description)
+	d.CurrencyCode = strings.ToUpper(d.CurrencyCode)
+	switch {
+	case d.Description == "":
+		return errors.New("description is required")
+	case len(d.Description) > maxDescriptionLength:
+		return fmt.Errorf("description is longer than %d characters", maxDescriptionLength)
+	case d.PostedAt.IsZero():
+		return errors.New("posted_at is required")
+	case d.AmountMinor == 0:
+		return errors.New("amount_minor is required")
+	case !currencyPattern.MatchString(d.CurrencyCode):
+		return fmt.Errorf("invalid currency code %q", d.CurrencyCode)
+	}
+
+	// Tags are stored like Quick Capture hashtags: lower-case and unique
+	var tags []string
+	for _, tag := range d.Tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxTags {
+		return fmt.Errorf("a transaction can have at most %d tags", maxTags)
+	}
+	d.Tags = tags
+	return nil
+}
+
+func validateCategory(d *repository.CategoryData) error {
+	d.Name = strings.TrimSpace(d.Name)
+	switch {
+	case d.Name == "":
+		return errors.New("name is required")
+	case len(d.Name) > maxNameLength:
+		return fmt.Errorf("name is longer than %d characters", maxNameLength)
+	}
+	return nil
+}
+
+func validateGoal(d *repository.GoalData) error {
+	d.Name = strings.TrimSpace(d.Name)
+	d.CurrencyCode = strings.ToUpper(d.CurrencyCode)
+	if d.Status == "" {
+		d.Status = "active"
+	}
+	switch {
+	case d.Name == "":
+		return errors.New("name is required")
+	case len(d.Name) > maxNameLength:
+		return fmt.Errorf("name is longer than %d characters", maxNameLength)
+	case !slices.Contains([]string{"save", "pay_down_debt", "spend_cap"}, d.Type):
+		return fmt.Errorf("invalid goal type %q", d.Type)
+	case !slices.Contains([]string{"active", "paused", "completed", "archived"}, d.Status):
+		return fmt.Errorf("invalid goal status %q", d.Status)
+	case d.TargetAmountMinor <= 0:
+		return errors.New("target_amount_minor must be positive")
+	case !currencyPattern.MatchString(d.CurrencyCode):
+		return fmt.Errorf("invalid currency code %q", d.CurrencyCode)
+	case d.StartAt.IsZero() || d.EndAt.IsZero():
+		return errors.New("start_at and end_at are required")
+	case d.EndAt.Before(d.StartAt):
+		return errors.New("end_at must not be before start_at")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/offlinesync/repository"
+)
+
+// fakeSyncRepository serves a fixed feed and records applied mutations.
+type fakeSyncRepository struct {
+	changes []repository.Change
+	applied []*repository.Mutation
+}
+
+func (f *fakeSyncRepository) ListChanges(ctx context.Context, userID uuid.UUID, after int64, limit int) ([]repository.Change, error) {
+	var out []repository.Change
+	for _, c := range f.changes {
+		if c.Seq > after && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSyncRepository) ApplyMutation(ctx context.Context, userID uuid.UUID, m *repository.Mutation) (*repository.MutationResult, error) {
+	f.applied = append(f.applied, m)
+	return &repository.MutationResult{EntityType: m.EntityType, EntityID: m.EntityID, Status: repository.MutationApplied, Seq: int64(len(f.applied))}, nil
+}
+
+func TestSyncChanges_Pages(t *testing.T) {
+	repo := &fakeSyncRepository{}
+	for seq := int64(1); seq <= 5; seq++ {
+		repo.changes = append(repo.changes, repository.Change{Seq: seq * 10, EntityType: repository.EntityTransaction, EntityID: uuid.New()})
+	}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	page, err := svc.SyncChanges(ctx, uuid.New(), 0, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Changes, 2)
+	assert.Equal(t, int64(20), page.Cursor)
+	assert.True(t, page.HasMore)
+
+	page, err = svc.SyncChanges(ctx, uuid.New(), 30, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Changes, 2)
+	assert.Equal(t, int64(50), page.Cursor)
+	assert.False(t, page.HasMore)
+
+	page, err = svc.SyncChanges(ctx, uuid.New(), 50, 0)
+	require.NoError(t, err)
+	assert.Empty(t, page.Changes)
+	assert.Equal(t, int64(50), page.Cursor, "an empty page keeps the cursor")
+
+	_, err = svc.SyncChanges(ctx, uuid.New(), -1, 0)
+	assert.Error(t, err)
+}
+
+func TestPushMutations_Validation(t *testing.T) {
+	repo := &fakeSyncRepository{}
+	svc := NewService(repo)
+	data := func(v any) json.RawMessage {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+		return b
+	}
+	catID := uuid.New()
+
+	results, err := svc.PushMutations(context.Background(), uuid.New(), []ClientMutation{
+		{EntityType: repository.EntityTransaction, EntityID: uuid.New(), Data: data(map[string]any{
+			"posted_at": "2026-03-06T19:30:00Z", "description": " Dinner ", "amount_minor": -4000,
+			"currency_code": "eur", "tags": []string{"#Work", "work", " friends "},
+		})},
+		{EntityType: repository.EntityTransaction, EntityID: uuid.New(), Data: data(map[string]any{"description": "No amount"})},
+		{EntityType: repository.EntityCategory, EntityID: catID, Data: data(map[string]any{"name": "Loop", "parent_id": catID})},
+		{EntityType: repository.EntityRule, EntityID: uuid.New(), Delete: true},
+		{EntityType: repository.EntityGoal, EntityID: uuid.New(), Data: data(map[string]any{
+			"name": "Trip", "type": "save", "target_amount_minor": 150000, "currency_code": "EUR",
+			"start_at": "2026-01-01T00:00:00Z", "end_at": "2026-08-01T00:00:00Z",
+		})},
+		{EntityType: repository.EntityGoal, EntityID: uuid.New(), Delete: true, BaseSeq: 7},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	statuses := make([]string, len(results))
+	for i, r := range results {
+		statuses[i] = r.Status
+	}
+	assert.Equal(t, []string{
+		repository.MutationApplied, repository.MutationRejected, repository.MutationRejected,
+		repository.MutationRejected, repository.MutationApplied, repository.MutationApplied,
+	}, statuses)
+	assert.Contains(t, results[3].Reason, "can't be changed offline")
+
+	require.Len(t, repo.applied, 3)
+	tx := repo.applied[0].Transaction
+	assert.Equal(t, "Dinner", tx.Description)
+	assert.Equal(t, "EUR", tx.CurrencyCode)
+	assert.Equal(t, []string{"work", "friends"}, tx.Tags)
+	assert.Equal(t, "active", repo.applied[1].Goal.Status, "goals default to active")
+	assert.True(t, repo.applied[2].Delete)
+	assert.Equal(t, int64(7), repo.applied[2].BaseSeq)
+
+	_, err = svc.PushMutations(context.Background(), uuid.New(), make([]ClientMutation, MaxPushMutations+1))
+	assert.ErrorIs(t, err, ErrTooManyMutations)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresOnboardingRepository implements OnboardingRepository using PostgreSQL
+type PostgresOnboardingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresOnboardingRepository creates a new PostgreSQL onboarding repository
+func NewPostgresOnboardingRepository(pool *pgxpool.Pool) *PostgresOnboardingRepository {
+	return &PostgresOnboardingRepository{pool: pool}
+}
+
+// GetCompletion checks the user's data for each onboarding step
+func (r *PostgresOnboardingRepository) GetCompletion(ctx context.Context, userID uuid.UUID) (*Completion, error) {
+	c := &Completion{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM import_jobs WHERE user_id = $1 AND status = 'succeeded'),
+			EXISTS (SELECT 1 FROM categories WHERE user_id = $1)
+				OR EXISTS (SELECT 1 FROM category_rules WHERE user_id = $1 AND deleted_at IS NULL),
+			EXISTS (SELECT 1 FROM user_plans WHERE user_id = $1 AND deleted_at IS NULL),
+			EXISTS (SELECT 1 FROM goals WHERE user_id = $1 AND deleted_at IS NULL)
+	`, userID).Scan(&c.ImportedStatement, &c.SetCategories, &c.CreatedPlan, &c.SetGoal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding completion: %w", err)
+	}
+	return c, nil
+}
+
+// GetProgress returns the user's skipped steps and when they finished
+func (r *PostgresOnboardingRepository) GetProgress(ctx context.Context, userID uuid.UUID) (*Progress, error) {
+	p := &Progress{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT skipped_steps, completed_at, updated_at
+		FROM onboarding_progress
+		WHERE user_id = $1
+	`, userID).Scan(&p.SkippedSteps, &p.CompletedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding progress: %w", err)
+	}
+	return p, nil
+}
+
+// SkipStep records a skipped step
+func (r *PostgresOnboardingRepository) SkipStep(ctx context.Context, userID uuid.UUID, step string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO onboarding_progress (user_id, skipped_steps)
+		VALUES ($1, ARRAY[$2::TEXT])
+		ON CONFLICT (user_id) DO UPDATE
+		SET skipped_steps = array_append(onboarding_progress.skipped_steps, $2::TEXT)
+		WHERE NOT $2::TEXT = ANY(onboarding_progress.skipped_steps)
+	`, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to skip onboarding step: %w", err)
+	}
+	return nil
+}
+
+// MarkCompleted records when the user finished onboarding
+func (r *PostgresOnboardingRepository) MarkCompleted(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO onboarding_progress (user_id, completed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET completed_at = EXCLUDED.completed_at
+		WHERE onboarding_progress.completed_at IS NULL
+	`, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to complete onboarding: %w", err)
+	}
+	return nil
+}
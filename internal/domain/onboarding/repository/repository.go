@@ -0,0 +1,36 @@
+// Package repository provides database operations for users' onboarding progress.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Completion tells which onboarding steps the user's data shows as done
+type Completion struct {
+	ImportedStatement bool // An import succeeded
+	SetCategories     bool // The user has their own categories or rules
+	CreatedPlan       bool
+	SetGoal           bool
+}
+
+// Progress is what the user did in onboarding that their data can't show
+type Progress struct {
+	SkippedSteps []string
+	CompletedAt  *time.Time // Set once every step was done or skipped
+	UpdatedAt    time.Time
+}
+
+// OnboardingRepository defines the interface for onboarding persistence
+type OnboardingRepository interface {
+	// GetCompletion checks the user's data for each onboarding step
+	GetCompletion(ctx context.Context, userID uuid.UUID) (*Completion, error)
+	// GetProgress returns the user's progress; sql.ErrNoRows if they haven't skipped or finished anything
+	GetProgress(ctx context.Context, userID uuid.UUID) (*Progress, error)
+	// SkipStep records a skipped step
+	SkipStep(ctx context.Context, userID uuid.UUID, step string) error
+	// MarkCompleted records when the user finished onboarding, keeping the first time
+	MarkCompleted(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,166 @@
+// Package service provides the onboarding wizard: which steps a user has done,
+// worked out from their data, and which they chose to skip.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/onboarding/repository"
+)
+
+// =============================================================================
+// Onboarding (Internal Integration)
+// =============================================================================
+// The wizard walks new users through Steps in order. A step is completed once
+// the user's data shows it (an import succeeded, a plan exists, ...), so the
+// wizard resumes where the user left off on any device and steps done outside
+// it count too. The current step can be skipped; once every step is completed
+// or skipped the wizard is finished for good.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetOnboardingStateRequest/Response (UserService.GetOnboardingState) with
+//   OnboardingState and OnboardingStep
+// - AdvanceOnboardingRequest/Response (UserService.AdvanceOnboarding)
+
+// Onboarding steps, in the order the wizard shows them
+const (
+	StepCreateAccount   = "create_account"
+	StepImportStatement = "import_statement"
+	StepSetCategories   = "set_categories"
+	StepCreatePlan      = "create_plan"
+	StepSetGoal         = "set_goal"
+)
+
+// Steps are the onboarding steps in order
+var Steps = []string{StepCreateAccount, StepImportStatement, StepSetCategories, StepCreatePlan, StepSetGoal}
+
+// StepStatus is where the user is with a step
+type StepStatus string
+
+const (
+	StepPending   StepStatus = "pending"
+	StepCompleted StepStatus = "completed"
+	StepSkipped   StepStatus = "skipped"
+)
+
+var (
+	// ErrUnknownStep is returned for steps that aren't in Steps
+	ErrUnknownStep = errors.New("unknown onboarding step")
+	// ErrNotCurrentStep is returned when advancing past a step other than the current one
+	ErrNotCurrentStep = errors.New("onboarding step is not the current step")
+)
+
+// StepState is a step and the user's status on it
+type StepState struct {
+	Step   string
+	Status StepStatus
+}
+
+// State is a user's progress through onboarding
+type State struct {
+	Steps       []StepState
+	CurrentStep string // The first pending step, "" once finished
+	Finished    bool
+	FinishedAt  *time.Time
+}
+
+// Service tracks onboarding progress
+type Service struct {
+	repo repository.OnboardingRepository
+	now  func() time.Time
+}
+
+// NewService creates a new onboarding service
+func NewService(repo repository.OnboardingRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// GetOnboardingState returns the user's progress through onboarding. The
+// first time every step is completed or skipped, the wizard is recorded as
+// finished and stays finished even if the data behind a step is removed.
+func (s *Service) GetOnboardingState(ctx context.Context, userID uuid.UUID) (*State, error) {
+	progress, err := s.repo.GetProgress(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		progress = &repository.Progress{}
+	} else if err != nil {
+		return nil, err
+	}
+	completion, err := s.repo.GetCompletion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	completed := map[string]bool{
+		StepCreateAccount:   true,
+		StepImportStatement: completion.ImportedStatement,
+		StepSetCategories:   completion.SetCategories,
+		StepCreatePlan:      completion.CreatedPlan,
+		StepSetGoal:         completion.SetGoal,
+	}
+	state := &State{Steps: make([]StepState, len(Steps)), FinishedAt: progress.CompletedAt}
+	for i, step := range Steps {
+		status := StepPending
+		switch {
+		case completed[step]:
+			status = StepCompleted
+		case slices.Contains(progress.SkippedSteps, step):
+			status = StepSkipped
+		}
+		state.Steps[i] = StepState{Step: step, Status: status}
+		if status == StepPending && state.CurrentStep == "" {
+			state.CurrentStep = step
+		}
+	}
+
+	switch {
+	case progress.CompletedAt != nil:
+		state.CurrentStep, state.Finished = "", true
+	case state.CurrentStep == "":
+		now := s.now()
+		if err := s.repo.MarkCompleted(ctx, userID, now); err != nil {
+			return nil, err
+		}
+		state.Finished, state.FinishedAt = true, &now
+	}
+	return state, nil
+}
+
+// AdvanceOnboarding skips the current step, moving the wizard to the next
+// pending one. Completed steps need no advancing: they're left as soon as
+// their data exists.
+func (s *Service) AdvanceOnboarding(ctx context.Context, userID uuid.UUID, step string) (*State, error) {
+	if !slices.Contains(Steps, step) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStep, step)
+	}
+	state, err := s.GetOnboardingState(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Finished || stepStatus(state, step) == StepCompleted {
+		return state, nil
+	}
+	if step != state.CurrentStep {
+		return nil, fmt.Errorf("%w: the current step is %q", ErrNotCurrentStep, state.CurrentStep)
+	}
+
+	if err := s.repo.SkipStep(ctx, userID, step); err != nil {
+		return nil, err
+	}
+	return s.GetOnboardingState(ctx, userID)
+}
+
+func stepStatus(state *State, step string) StepStatus {
+	for _, s := range state.Steps {
+		if s.Step == step {
+			return s.Status
+		}
+	}
+	return StepPending
+}
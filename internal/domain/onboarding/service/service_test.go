@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/onboarding/repository"
+)
+
+// fakeOnboardingRepository keeps one user's data and progress in memory.
+type fakeOnboardingRepository struct {
+	completion repository.Completion
+	progress   *repository.Progress
+}
+
+func (f *fakeOnboardingRepository) GetCompletion(ctx context.Context, userID uuid.UUID) (*repository.Completion, error) {
+	c := f.completion
+	return &c, nil
+}
+
+func (f *fakeOnboardingRepository) GetProgress(ctx context.Context, userID uuid.UUID) (*repository.Progress, error) {
+	if f.progress == nil {
+		return nil, sql.ErrNoRows
+	}
+	p := *f.progress
+	return &p, nil
+}
+
+func (f *fakeOnboardingRepository) SkipStep(ctx context.Context, userID uuid.UUID, step string) error {
+	if f.progress == nil {
+		f.progress = &repository.Progress{}
+	}
+	if !slices.Contains(f.progress.SkippedSteps, step) {
+		f.progress.SkippedSteps = append(f.progress.SkippedSteps, step)
+	}
+	return nil
+}
+
+func (f *fakeOnboardingRepository) MarkCompleted(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	if f.progress == nil {
+		f.progress = &repository.Progress{}
+	}
+	if f.progress.CompletedAt == nil {
+		f.progress.CompletedAt = &at
+	}
+	return nil
+}
+
+func statuses(state *State) map[string]StepStatus {
+	out := make(map[string]StepStatus, len(state.Steps))
+	for _, s := range state.Steps {
+		out[s.Step] = s.Status
+	}
+	return out
+}
+
+func TestGetOnboardingState_FromData(t *testing.T) {
+	repo := &fakeOnboardingRepository{completion: repository.Completion{CreatedPlan: true}}
+	svc := NewService(repo)
+
+	state, err := svc.GetOnboardingState(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]StepStatus{
+		StepCreateAccount:   StepCompleted,
+		StepImportStatement: StepPending,
+		StepSetCategories:   StepPending,
+		StepCreatePlan:      StepCompleted,
+		StepSetGoal:         StepPending,
+	}, statuses(state), "a plan made outside the wizard counts")
+	assert.Equal(t, StepImportStatement, state.CurrentStep)
+	assert.False(t, state.Finished)
+}
+
+func TestAdvanceOnboarding(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := &fakeOnboardingRepository{completion: repository.Completion{ImportedStatement: true}}
+	svc := NewService(repo)
+	svc.now = func() time.Time { return now }
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := svc.AdvanceOnboarding(ctx, userID, StepSetGoal)
+	assert.ErrorIs(t, err, ErrNotCurrentStep)
+	_, err = svc.AdvanceOnboarding(ctx, userID, "connect_bank")
+	assert.ErrorIs(t, err, ErrUnknownStep)
+
+	state, err := svc.AdvanceOnboarding(ctx, userID, StepImportStatement)
+	require.NoError(t, err)
+	assert.Equal(t, StepSetCategories, state.CurrentStep, "completed steps need no advancing")
+
+	state, err = svc.AdvanceOnboarding(ctx, userID, StepSetCategories)
+	require.NoError(t, err)
+	assert.Equal(t, StepSkipped, statuses(state)[StepSetCategories])
+	assert.Equal(t, StepCreatePlan, state.CurrentStep)
+
+	repo.completion.CreatedPlan = true
+	state, err = svc.AdvanceOnboarding(ctx, userID, StepSetGoal)
+	require.NoError(t, err)
+	assert.True(t, state.Finished)
+	assert.Empty(t, state.CurrentStep)
+	require.NotNil(t, state.FinishedAt)
+	assert.Equal(t, now, *state.FinishedAt)
+
+	repo.completion.CreatedPlan = false
+	state, err = svc.GetOnboardingState(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, state.Finished, "finished onboarding doesn't come back")
+	assert.Equal(t, StepPending, statuses(state)[StepCreatePlan])
+}
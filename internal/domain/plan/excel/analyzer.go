@@ -244,6 +244,9 @@ func (a *StructuralAnalyzer) AnalyzeSheetTree(sheetName string, catCol, valCol s
 		catCell, _ := excelize.CoordinatesToCellName(catColIdx, rowIdx)
 		valCell, _ := excelize.CoordinatesToCellName(valColIdx, rowIdx)
 
+		// Compute formulas without a stored value so derived rows aren't empty
+		valValue, formula := evaluateCell(a.file, sheetName, valCell, valValue)
+
 		// Build row features for classification
 		features := a.buildRowFeatures(sheetName, catCell, valCell, catValue, valValue, rowIdx, totalRows)
 
@@ -252,7 +255,6 @@ func (a *StructuralAnalyzer) AnalyzeSheetTree(sheetName string, catCol, valCol s
 
 		// Parse value
 		var value float64
-		formula, _ := a.file.GetCellFormula(sheetName, valCell)
 		if v, err := parseNumericValue(valValue); err == nil {
 			value = v
 		}
@@ -0,0 +1,69 @@
+package excel
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// totalTolerance is how far (in currency units, per item) a sheet total may
+// drift from the sum of its items before it's reported, to absorb rounding
+const totalTolerance = 0.01
+
+// TotalMismatch reports a category whose total in the sheet doesn't match the
+// sum of the items imported under it
+type TotalMismatch struct {
+	Category   string  `json:"category"`
+	TotalCell  string  `json:"total_cell"`
+	SheetTotal float64 `json:"sheet_total"`
+	ItemsTotal float64 `json:"items_total"`
+}
+
+// evaluateCell returns a cell's value and formula. Excel stores the last
+// computed value of each formula, but files written by other tools often
+// don't, so formulas without a stored value are computed here.
+func evaluateCell(f *excelize.File, sheetName, cell, cached string) (value, formula string) {
+	formula, _ = f.GetCellFormula(sheetName, cell)
+	value = strings.TrimSpace(cached)
+	if formula == "" || value != "" {
+		return value, formula
+	}
+
+	computed, err := f.CalcCellValue(sheetName, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", formula // Unsupported function or bad reference; import as zero
+	}
+	computed = strings.TrimSpace(computed)
+	// Raw results carry full float precision, which parseNumericValue would read
+	// as thousands separators; amounts only need cents
+	if v, err := strconv.ParseFloat(computed, 64); err == nil {
+		return strconv.FormatFloat(v, 'f', 2, 64), formula
+	}
+	return computed, formula
+}
+
+// ValidateTotals compares each category's total in the sheet with the sum of
+// its items. Categories without a total or without items are skipped.
+func ValidateTotals(categories []CategoryExtraction) []TotalMismatch {
+	var mismatches []TotalMismatch
+	for _, cat := range categories {
+		if cat.TotalCell == "" || len(cat.Items) == 0 {
+			continue
+		}
+		var sum float64
+		for _, item := range cat.Items {
+			sum += item.Value
+		}
+		if math.Abs(sum-cat.TotalValue) > totalTolerance*float64(len(cat.Items)) {
+			mismatches = append(mismatches, TotalMismatch{
+				Category:   cat.Name,
+				TotalCell:  cat.TotalCell,
+				SheetTotal: cat.TotalValue,
+				ItemsTotal: sum,
+			})
+		}
+	}
+	return mismatches
+}
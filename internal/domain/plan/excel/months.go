@@ -0,0 +1,228 @@
+package excel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// monthNames maps English and Portuguese month names and abbreviations to
+// their month number
+var monthNames = map[string]int{
+	"jan": 1, "january": 1, "janeiro": 1,
+	"feb": 2, "february": 2, "fev": 2, "fevereiro": 2,
+	"mar": 3, "march": 3, "março": 3, "marco": 3,
+	"apr": 4, "april": 4, "abr": 4, "abril": 4,
+	"may": 5, "mai": 5, "maio": 5,
+	"jun": 6, "june": 6, "junho": 6,
+	"jul": 7, "july": 7, "julho": 7,
+	"aug": 8, "august": 8, "ago": 8, "agosto": 8,
+	"sep": 9, "sept": 9, "september": 9, "set": 9, "setembro": 9,
+	"oct": 10, "october": 10, "out": 10, "outubro": 10,
+	"nov": 11, "november": 11, "novembro": 11,
+	"dec": 12, "december": 12, "dez": 12, "dezembro": 12,
+}
+
+// MonthColumn is a column holding one month's budget values
+type MonthColumn struct {
+	Column string `json:"column"` // e.g., "C"
+	Header string `json:"header"`
+	Year   int    `json:"year"`
+	Month  int    `json:"month"`
+}
+
+// MonthSheet is a sheet holding one month's budget
+type MonthSheet struct {
+	Name  string `json:"name"`
+	Year  int    `json:"year"`
+	Month int    `json:"month"`
+}
+
+// MonthlyValue is an item's budget for one month
+type MonthlyValue struct {
+	Year  int
+	Month int
+	Value float64
+}
+
+// parseMonthHeader reads a month header such as "Jan", "jan-26", "Fevereiro 2025"
+// or "03/2025". The year is 0 when the header doesn't have one. Unlike
+// isMonthHeader, the whole header must be a month so "Marketing" doesn't match.
+func parseMonthHeader(value string) (month, year int, ok bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, 0, false
+	}
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '/' || r == '.' || r == '\''
+	})
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, false
+	}
+
+	month, ok = monthNames[fields[0]]
+	if !ok {
+		// Numeric "MM/YYYY"; a lone number is a value, not a month
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || len(fields) != 2 || n < 1 || n > 12 {
+			return 0, 0, false
+		}
+		month = n
+	}
+	if len(fields) == 1 {
+		return month, 0, true
+	}
+
+	y, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	switch {
+	case len(fields[1]) == 2:
+		year = 2000 + y
+	case len(fields[1]) == 4:
+		year = y
+	default:
+		return 0, 0, false
+	}
+	return month, year, true
+}
+
+// DetectMonthColumns finds the header row with the most month headers in the
+// first 10 rows and returns its month columns in sheet order. At least two
+// months are needed for a sheet to count as one column per month. Headers
+// without a year are dated from defaultYear, moving to the next year when
+// the months wrap around (e.g. "Dec" followed by "Jan").
+func (p *Parser) DetectMonthColumns(sheetName string, defaultYear int) ([]MonthColumn, error) {
+	rows, err := p.file.GetRows(sheetName)
+	if err != nil {
+		return nil, err
+	}
+
+	var best []MonthColumn
+	for rowIdx := 0; rowIdx < len(rows) && rowIdx < 10; rowIdx++ {
+		var found []MonthColumn
+		for colIdx, value := range rows[rowIdx] {
+			month, year, ok := parseMonthHeader(value)
+			if !ok {
+				continue
+			}
+			found = append(found, MonthColumn{
+				Column: idxToColLetter(colIdx + 1),
+				Header: strings.TrimSpace(value),
+				Year:   year,
+				Month:  month,
+			})
+		}
+		if len(found) > len(best) {
+			best = found
+		}
+	}
+	if len(best) < 2 {
+		return nil, nil
+	}
+
+	year, prev := defaultYear, 0
+	for i := range best {
+		if best[i].Year != 0 {
+			year, prev = best[i].Year, best[i].Month
+			continue
+		}
+		if best[i].Month < prev {
+			year++
+		}
+		best[i].Year, prev = year, best[i].Month
+	}
+	return best, nil
+}
+
+// DetectMonthSheets returns the sheets named after a month, ordered by date.
+// At least two are needed for a workbook to count as one sheet per month;
+// years are resolved as in DetectMonthColumns.
+func (p *Parser) DetectMonthSheets(defaultYear int) []MonthSheet {
+	var sheets []MonthSheet
+	year, prev := defaultYear, 0
+	for _, name := range p.file.GetSheetList() {
+		month, y, ok := parseMonthHeader(name)
+		if !ok {
+			continue
+		}
+		if y != 0 {
+			year = y
+		} else if month < prev {
+			year++
+		}
+		prev = month
+		sheets = append(sheets, MonthSheet{Name: name, Year: year, Month: month})
+	}
+	if len(sheets) < 2 {
+		return nil
+	}
+	sort.SliceStable(sheets, func(i, j int) bool {
+		if sheets[i].Year != sheets[j].Year {
+			return sheets[i].Year < sheets[j].Year
+		}
+		return sheets[i].Month < sheets[j].Month
+	})
+	return sheets
+}
+
+// FillMonthValues reads each item's value in every month column of its row
+func (p *Parser) FillMonthValues(sheetName string, categories []CategoryExtraction, months []MonthColumn) error {
+	for c := range categories {
+		for i := range categories[c].Items {
+			item := &categories[c].Items[i]
+			for _, m := range months {
+				cell, err := excelize.CoordinatesToCellName(colLetterToIdx(m.Column), item.Row)
+				if err != nil {
+					return err
+				}
+				cached, _ := p.file.GetCellValue(sheetName, cell)
+				value, _ := evaluateCell(p.file, sheetName, cell, cached)
+				v, err := parseNumericValue(value)
+				if err != nil {
+					continue // Blank months keep the plan's budget
+				}
+				item.Months = append(item.Months, MonthlyValue{Year: m.Year, Month: m.Month, Value: v})
+			}
+		}
+	}
+	return nil
+}
+
+// FillMonthValuesFromSheets reads each item's value from every month sheet,
+// laid out like the imported sheet. Items are matched by category and item
+// name, so rows may move between sheets.
+func (p *Parser) FillMonthValuesFromSheets(categories []CategoryExtraction, sheets []MonthSheet, categoryCol, valueCol string, startRow int) error {
+	for _, sheet := range sheets {
+		monthCategories, err := p.ExtractCategories(sheet.Name, categoryCol, valueCol, startRow)
+		if err != nil {
+			return err
+		}
+		values := make(map[string]float64)
+		for _, cat := range monthCategories {
+			for _, item := range cat.Items {
+				values[monthItemKey(cat.Name, item.Name)] = item.Value
+			}
+		}
+		for c := range categories {
+			for i := range categories[c].Items {
+				item := &categories[c].Items[i]
+				v, ok := values[monthItemKey(categories[c].Name, item.Name)]
+				if !ok {
+					continue
+				}
+				item.Months = append(item.Months, MonthlyValue{Year: sheet.Year, Month: sheet.Month, Value: v})
+			}
+		}
+	}
+	return nil
+}
+
+// monthItemKey identifies an item across month sheets
+func monthItemKey(category, item string) string {
+	return strings.ToLower(strings.TrimSpace(category)) + "\x00" + strings.ToLower(strings.TrimSpace(item))
+}
@@ -58,6 +58,7 @@ type ItemExtraction struct {
 	Value     float64
 	Formula   string
 	IsFormula bool
+	Months    []MonthlyValue // Per-month budgets, for sheets with a column or sheet per month
 }
 
 // Parser handles Excel file analysis and data extraction
@@ -425,14 +426,17 @@ func (p *Parser) ExtractCategories(sheetName string, categoryCol, valueCol strin
 			continue
 		}
 
-		// Skip rows that have no value (likely section headers or separators)
-		hasValue := valValue != "" || valValue == "0"
-		_ = hasValue // Used for potential future filtering
-
 		// Get cell references
 		catCell, _ := excelize.CoordinatesToCellName(catColIdx, rowIdx)
 		valCell, _ := excelize.CoordinatesToCellName(valColIdx, rowIdx)
 
+		// Derived rows (e.g. =SUM(B2:B10)) may not have a stored value
+		valValue, formula := evaluateCell(p.file, sheetName, valCell, valValue)
+
+		// Skip rows that have no value (likely section headers or separators)
+		hasValue := valValue != "" || valValue == "0"
+		_ = hasValue // Used for potential future filtering
+
 		// Check if this looks like a category header (bold, all caps, or followed by items)
 		style, _ := p.file.GetCellStyle(sheetName, catCell)
 		isBold := style > 0 // Simplified: assuming styled cells are headers
@@ -440,6 +444,17 @@ func (p *Parser) ExtractCategories(sheetName string, categoryCol, valueCol strin
 		isUpperCase := catValue == strings.ToUpper(catValue) && len(catValue) > 2
 		isCatHeader := isBold || isUpperCase || isCategoryKeyword(catValue)
 
+		// A "Total ..." row right after a category's items is that category's total
+		if isTotalLabel(catValue) && currentCategory != nil && currentCategory.TotalCell == "" && len(currentCategory.Items) > 0 {
+			if v, err := parseNumericValue(valValue); err == nil {
+				currentCategory.TotalCell = valCell
+				currentCategory.TotalValue = v
+			}
+			categories = append(categories, *currentCategory)
+			currentCategory = nil
+			continue
+		}
+
 		if isCatHeader && currentCategory != nil {
 			// Save current category and start new one
 			categories = append(categories, *currentCategory)
@@ -452,11 +467,13 @@ func (p *Parser) ExtractCategories(sheetName string, categoryCol, valueCol strin
 				Row:   rowIdx,
 				Items: make([]ItemExtraction, 0),
 			}
+			// A value on the header row is the category's total
+			if v, err := parseNumericValue(valValue); err == nil {
+				currentCategory.TotalCell = valCell
+				currentCategory.TotalValue = v
+			}
 		} else if currentCategory != nil {
 			// This is an item under the current category
-			formula, _ := p.file.GetCellFormula(sheetName, valCell)
-			value, _ := p.file.GetCellValue(sheetName, valCell)
-
 			item := ItemExtraction{
 				Name:      catValue,
 				Row:       rowIdx,
@@ -466,7 +483,7 @@ func (p *Parser) ExtractCategories(sheetName string, categoryCol, valueCol strin
 			}
 
 			// Parse value as float
-			if v, err := parseNumericValue(value); err == nil {
+			if v, err := parseNumericValue(valValue); err == nil {
 				item.Value = v
 			}
 
@@ -538,6 +555,12 @@ func isCategoryKeyword(value string) bool {
 	return false
 }
 
+// isTotalLabel checks if a row label marks a total, e.g. "Total" or "Subtotal habitação"
+func isTotalLabel(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.HasPrefix(value, "total") || strings.HasPrefix(value, "subtotal")
+}
+
 // colLetterToIdx converts a column letter (A, B, C...) to a 1-based index
 func colLetterToIdx(col string) int {
 	col = strings.ToUpper(col)
@@ -1,11 +1,13 @@
 package excel_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/excel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
 )
 
 // TestAnalyzeBudgetFile tests the Excel parser against the test budget file
@@ -103,3 +105,101 @@ func TestExtractCategories(t *testing.T) {
 
 	assert.NotEmpty(t, categories, "should extract at least one category")
 }
+
+// TestExtractCategories_EvaluatesFormulas checks that formulas without a stored
+// value (as written by tools other than Excel) are computed, and that category
+// totals are checked against their items
+func TestExtractCategories_EvaluatesFormulas(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := "Budget"
+	require.NoError(t, f.SetSheetName("Sheet1", sheet))
+	rows := [][]any{
+		{"HABITAÇÃO"},
+		{"Renda", 750},
+		{"Luz", "=B2*0.1"},
+		{"Total habitação", "=SUM(B2:B3)"},
+		{"TRANSPORTE", 100},
+		{"Combustível", 60},
+		{"Portagens", "=B6/2"},
+	}
+	for i, row := range rows {
+		for j, v := range row {
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+			if formula, ok := v.(string); ok && len(formula) > 0 && formula[0] == '=' {
+				require.NoError(t, f.SetCellFormula(sheet, cell, formula[1:]))
+			} else {
+				require.NoError(t, f.SetCellValue(sheet, cell, v))
+			}
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	parser, err := excel.NewParserFromReader(&buf)
+	require.NoError(t, err)
+	defer parser.Close()
+
+	categories, err := parser.ExtractCategories(sheet, "A", "B", 1)
+	require.NoError(t, err)
+	require.Len(t, categories, 2)
+
+	housing := categories[0]
+	require.Len(t, housing.Items, 2)
+	assert.Equal(t, 75.0, housing.Items[1].Value)
+	assert.True(t, housing.Items[1].IsFormula)
+	assert.Equal(t, "B4", housing.TotalCell)
+	assert.Equal(t, 825.0, housing.TotalValue)
+
+	transport := categories[1]
+	require.Len(t, transport.Items, 2)
+	assert.Equal(t, 30.0, transport.Items[1].Value)
+
+	mismatches := excel.ValidateTotals(categories)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "TRANSPORTE", mismatches[0].Category)
+	assert.Equal(t, 100.0, mismatches[0].SheetTotal)
+	assert.Equal(t, 90.0, mismatches[0].ItemsTotal)
+}
+
+func TestFillMonthValues_ReadsEachMonthColumn(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := "Budget"
+	require.NoError(t, f.SetSheetName("Sheet1", sheet))
+	rows := [][]any{
+		{"Category", "Marketing", "Nov", "Dez", "Jan"},
+		{"HABITAÇÃO"},
+		{"Renda", 1, 750, 750, 800},
+		{"Luz", 1, 60, 90, nil},
+	}
+	for i, row := range rows {
+		for j, v := range row {
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+			require.NoError(t, f.SetCellValue(sheet, cell, v))
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	parser, err := excel.NewParserFromReader(&buf)
+	require.NoError(t, err)
+	defer parser.Close()
+
+	months, err := parser.DetectMonthColumns(sheet, 2025)
+	require.NoError(t, err)
+	require.Len(t, months, 3, "Marketing isn't a month")
+	assert.Equal(t, excel.MonthColumn{Column: "C", Header: "Nov", Year: 2025, Month: 11}, months[0])
+	assert.Equal(t, excel.MonthColumn{Column: "E", Header: "Jan", Year: 2026, Month: 1}, months[2])
+
+	categories, err := parser.ExtractCategories(sheet, "A", "C", 2)
+	require.NoError(t, err)
+	require.NoError(t, parser.FillMonthValues(sheet, categories, months))
+	require.Len(t, categories, 1)
+
+	rent := categories[0].Items[0]
+	assert.Equal(t, []excel.MonthlyValue{
+		{Year: 2025, Month: 11, Value: 750},
+		{Year: 2025, Month: 12, Value: 750},
+		{Year: 2026, Month: 1, Value: 800},
+	}, rent.Months)
+	assert.Len(t, categories[0].Items[1].Months, 2, "blank months are skipped")
+}
@@ -26,19 +26,26 @@ func NewBudgetPeriodHandler(svc *service.BudgetPeriodService) *BudgetPeriodHandl
 
 // GetBudgetPeriod gets or creates a budget period for a specific month
 func (h *BudgetPeriodHandler) GetBudgetPeriod(ctx context.Context, req *connect.Request[echov1.GetBudgetPeriodRequest]) (*connect.Response[echov1.GetBudgetPeriodResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user not authenticated"))
 	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
 
 	planID, err := uuid.Parse(req.Msg.PlanId)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid plan ID"))
 	}
 
-	period, wasCreated, err := h.svc.GetOrCreatePeriod(ctx, planID, int(req.Msg.Year), int(req.Msg.Month))
+	period, wasCreated, err := h.svc.GetOrCreatePeriod(ctx, userID, planID, int(req.Msg.Year), int(req.Msg.Month))
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, budgetPeriodError(err)
+	}
+	if period == nil {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("plan not found"))
 	}
 
 	return connect.NewResponse(&echov1.GetBudgetPeriodResponse{
@@ -49,26 +56,33 @@ func (h *BudgetPeriodHandler) GetBudgetPeriod(ctx context.Context, req *connect.
 
 // ListBudgetPeriods lists all periods for a plan
 func (h *BudgetPeriodHandler) ListBudgetPeriods(ctx context.Context, req *connect.Request[echov1.ListBudgetPeriodsRequest]) (*connect.Response[echov1.ListBudgetPeriodsResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user not authenticated"))
 	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
 
 	planID, err := uuid.Parse(req.Msg.PlanId)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid plan ID"))
 	}
 
-	periods, err := h.svc.ListPeriods(ctx, planID)
+	periods, err := h.svc.ListPeriods(ctx, userID, planID)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
+	if periods == nil {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("plan not found"))
+	}
 
 	var protoPeriodsWithItems []*echov1.BudgetPeriod
 	for _, p := range periods {
 		// Get items for each period
-		periodWithItems, err := h.svc.GetPeriodByID(ctx, p.ID)
-		if err != nil {
+		periodWithItems, err := h.svc.GetPeriodByID(ctx, userID, p.ID)
+		if err != nil || periodWithItems == nil {
 			continue
 		}
 		protoPeriodsWithItems = append(protoPeriodsWithItems, toProtoBudgetPeriod(periodWithItems))
@@ -81,10 +95,14 @@ func (h *BudgetPeriodHandler) ListBudgetPeriods(ctx context.Context, req *connec
 
 // UpdateBudgetPeriodItem updates a specific item's values
 func (h *BudgetPeriodHandler) UpdateBudgetPeriodItem(ctx context.Context, req *connect.Request[echov1.UpdateBudgetPeriodItemRequest]) (*connect.Response[echov1.UpdateBudgetPeriodItemResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user not authenticated"))
 	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
 
 	periodItemID, err := uuid.Parse(req.Msg.PeriodItemId)
 	if err != nil {
@@ -107,9 +125,12 @@ func (h *BudgetPeriodHandler) UpdateBudgetPeriodItem(ctx context.Context, req *c
 		notes = &n
 	}
 
-	item, err := h.svc.UpdatePeriodItem(ctx, periodItemID, budgeted, actual, notes)
+	item, err := h.svc.UpdatePeriodItem(ctx, userID, periodItemID, budgeted, actual, notes)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, budgetPeriodError(err)
+	}
+	if item == nil {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("period item not found"))
 	}
 
 	return connect.NewResponse(&echov1.UpdateBudgetPeriodItemResponse{
@@ -119,10 +140,14 @@ func (h *BudgetPeriodHandler) UpdateBudgetPeriodItem(ctx context.Context, req *c
 
 // CopyBudgetPeriod copies values from one period to another
 func (h *BudgetPeriodHandler) CopyBudgetPeriod(ctx context.Context, req *connect.Request[echov1.CopyBudgetPeriodRequest]) (*connect.Response[echov1.CopyBudgetPeriodResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user not authenticated"))
 	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
 
 	sourcePeriodID, err := uuid.Parse(req.Msg.SourcePeriodId)
 	if err != nil {
@@ -134,9 +159,12 @@ func (h *BudgetPeriodHandler) CopyBudgetPeriod(ctx context.Context, req *connect
 		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("invalid target plan ID"))
 	}
 
-	period, err := h.svc.CopyPeriodItems(ctx, sourcePeriodID, targetPlanID, int(req.Msg.TargetYear), int(req.Msg.TargetMonth))
+	period, err := h.svc.CopyPeriodItems(ctx, userID, sourcePeriodID, targetPlanID, int(req.Msg.TargetYear), int(req.Msg.TargetMonth))
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, budgetPeriodError(err)
+	}
+	if period == nil {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("source period or target plan not found"))
 	}
 
 	return connect.NewResponse(&echov1.CopyBudgetPeriodResponse{
@@ -144,6 +172,18 @@ func (h *BudgetPeriodHandler) CopyBudgetPeriod(ctx context.Context, req *connect
 	}), nil
 }
 
+// budgetPeriodError maps budget period service errors to connect errors
+func budgetPeriodError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPeriod):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, service.ErrPeriodLocked):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
 // ============================================================================
 // Conversion helpers
 // ============================================================================
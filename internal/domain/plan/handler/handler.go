@@ -3,8 +3,12 @@ package handler
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
@@ -14,6 +18,8 @@ import (
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/excel"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	quotasservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/filescan"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
 	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
 )
@@ -22,6 +28,14 @@ import (
 type PlanHandler struct {
 	svc     *service.PlanService
 	storage storage.Storage
+	periods *BudgetPeriodHandler
+	files   FileGuard
+}
+
+// FileGuard refuses uploaded files that failed their scan or haven't had one.
+// Errors wrap filescan.ErrQuarantined or ErrNotScanned for those files.
+type FileGuard interface {
+	RequireScannedFile(ctx context.Context, userID, fileID uuid.UUID) error
 }
 
 // Ensure PlanHandler implements PlanServiceHandler
@@ -32,6 +46,35 @@ func NewPlanHandler(svc *service.PlanService, storage storage.Storage) *PlanHand
 	return &PlanHandler{svc: svc, storage: storage}
 }
 
+// WithBudgetPeriodService enables the budget period RPCs
+func (h *PlanHandler) WithBudgetPeriodService(svc *service.BudgetPeriodService) *PlanHandler {
+	h.periods = NewBudgetPeriodHandler(svc)
+	return h
+}
+
+// WithFileGuard makes the Excel imports refuse files that aren't scanned clean
+func (h *PlanHandler) WithFileGuard(files FileGuard) *PlanHandler {
+	h.files = files
+	return h
+}
+
+// openScannedFile opens an uploaded file once it has passed its scan
+func (h *PlanHandler) openScannedFile(ctx context.Context, userID, fileID uuid.UUID) (io.ReadCloser, error) {
+	if h.files != nil {
+		if err := h.files.RequireScannedFile(ctx, userID, fileID); err != nil {
+			if errors.Is(err, filescan.ErrQuarantined) || errors.Is(err, filescan.ErrNotScanned) {
+				return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+			}
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+	}
+	reader, err := h.storage.GetReader(ctx, userID, fileID)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	return reader, nil
+}
+
 // CreatePlan creates a new financial plan
 func (h *PlanHandler) CreatePlan(ctx context.Context, req *connect.Request[echov1.CreatePlanRequest]) (*connect.Response[echov1.CreatePlanResponse], error) {
 	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
@@ -91,7 +134,7 @@ func (h *PlanHandler) CreatePlan(ctx context.Context, req *connect.Request[echov
 
 	plan, err := h.svc.CreatePlan(ctx, userID, input)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, planCreateError(err)
 	}
 
 	return connect.NewResponse(&echov1.CreatePlanResponse{
@@ -123,9 +166,11 @@ func (h *PlanHandler) GetPlan(ctx context.Context, req *connect.Request[echov1.G
 		return nil, connect.NewError(connect.CodeNotFound, nil)
 	}
 
-	return connect.NewResponse(&echov1.GetPlanResponse{
+	res := connect.NewResponse(&echov1.GetPlanResponse{
 		Plan: toProtoPlanWithDetails(details),
-	}), nil
+	})
+	res.Header().Set("ETag", planETag(details.Plan.Version))
+	return res, nil
 }
 
 // ListPlans lists all plans for the current user
@@ -179,6 +224,10 @@ func (h *PlanHandler) UpdatePlanStructure(ctx context.Context, req *connect.Requ
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInvalidArgument, err)
 	}
+	expectedVersion, err := requirePlanVersion(req.Header().Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
 
 	var groups []service.CreateCategoryGroupInput
 	for _, g := range req.Msg.CategoryGroups {
@@ -237,14 +286,39 @@ func (h *PlanHandler) UpdatePlanStructure(ctx context.Context, req *connect.Requ
 		groups = append(groups, groupInput)
 	}
 
-	plan, err := h.svc.UpdatePlanStructure(ctx, userID, planID, groups)
+	plan, err := h.svc.UpdatePlanStructure(ctx, userID, planID, expectedVersion, groups)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, planAccessError(err)
 	}
 
-	return connect.NewResponse(&echov1.UpdatePlanStructureResponse{
+	res := connect.NewResponse(&echov1.UpdatePlanStructureResponse{
 		Plan: toProtoPlan(plan),
-	}), nil
+	})
+	res.Header().Set("ETag", planETag(plan.Version))
+	return res, nil
+}
+
+// planETag is the ETag of a plan version, sent back in If-Match to update it
+func planETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// errPlanVersionRequired rejects plan changes that don't say which version
+// they're based on, as they could silently overwrite another device's change
+var errPlanVersionRequired = errors.New("If-Match with the plan's ETag is required")
+
+// requirePlanVersion reads the plan version a change is based on from an
+// If-Match header, failing with FailedPrecondition when it's missing or "*"
+func requirePlanVersion(ifMatch string) (*int64, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, errPlanVersionRequired)
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`), 10, 64)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid If-Match plan version %q", ifMatch))
+	}
+	return &version, nil
 }
 
 // UpdatePlan updates an existing plan
@@ -263,6 +337,11 @@ func (h *PlanHandler) UpdatePlan(ctx context.Context, req *connect.Request[echov
 		return nil, connect.NewError(connect.CodeInvalidArgument, err)
 	}
 
+	expectedVersion, err := requirePlanVersion(req.Header().Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
 	var name, desc *string
 	if req.Msg.Name != nil {
 		name = req.Msg.Name
@@ -271,9 +350,9 @@ func (h *PlanHandler) UpdatePlan(ctx context.Context, req *connect.Request[echov
 		desc = req.Msg.Description
 	}
 
-	plan, err := h.svc.UpdatePlan(ctx, userID, planID, name, desc)
+	plan, err := h.svc.UpdatePlan(ctx, userID, planID, expectedVersion, name, desc)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, planAccessError(err)
 	}
 
 	// Update individual items if provided
@@ -283,13 +362,15 @@ func (h *PlanHandler) UpdatePlan(ctx context.Context, req *connect.Request[echov
 			continue
 		}
 		if err := h.svc.UpdatePlanItem(ctx, userID, planID, itemID, item.BudgetedMinor); err != nil {
-			return nil, connect.NewError(connect.CodeInternal, err)
+			return nil, planAccessError(err)
 		}
 	}
 
-	return connect.NewResponse(&echov1.UpdatePlanResponse{
+	res := connect.NewResponse(&echov1.UpdatePlanResponse{
 		Plan: toProtoPlan(plan),
-	}), nil
+	})
+	res.Header().Set("ETag", planETag(plan.Version))
+	return res, nil
 }
 
 // DeletePlan soft-deletes a plan
@@ -308,13 +389,44 @@ func (h *PlanHandler) DeletePlan(ctx context.Context, req *connect.Request[echov
 		return nil, connect.NewError(connect.CodeInvalidArgument, err)
 	}
 
-	if err := h.svc.DeletePlan(ctx, userID, planID); err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+	expectedVersion, err := requirePlanVersion(req.Header().Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.svc.DeletePlan(ctx, userID, planID, expectedVersion); err != nil {
+		return nil, planAccessError(err)
 	}
 
 	return connect.NewResponse(&echov1.DeletePlanResponse{}), nil
 }
 
+// planCreateError maps errors from adding a plan to connect errors
+func planCreateError(err error) error {
+	if errors.Is(err, quotasservice.ErrQuotaExceeded) {
+		return connect.NewError(connect.CodeResourceExhausted, err)
+	}
+	return connect.NewError(connect.CodeInternal, err)
+}
+
+// planAccessError maps errors from changing a possibly shared plan to connect errors
+func planAccessError(err error) error {
+	var conflict *repository.VersionConflictError
+	switch {
+	case errors.Is(err, service.ErrPlanReadOnly), errors.Is(err, service.ErrNotPlanOwner):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, sql.ErrNoRows):
+		return connect.NewError(connect.CodeNotFound, nil)
+	case errors.As(err, &conflict):
+		// The client reloads the plan, or retries with the current version in If-Match
+		connectErr := connect.NewError(connect.CodeFailedPrecondition, conflict)
+		connectErr.Meta().Set("ETag", planETag(conflict.Current))
+		return connectErr
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
 // SetActivePlan marks a plan as the active/live plan
 func (h *PlanHandler) SetActivePlan(ctx context.Context, req *connect.Request[echov1.SetActivePlanRequest]) (*connect.Response[echov1.SetActivePlanResponse], error) {
 	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
@@ -359,7 +471,7 @@ func (h *PlanHandler) DuplicatePlan(ctx context.Context, req *connect.Request[ec
 
 	plan, err := h.svc.DuplicatePlan(ctx, userID, planID, req.Msg.NewName)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, planCreateError(err)
 	}
 
 	return connect.NewResponse(&echov1.DuplicatePlanResponse{
@@ -384,9 +496,9 @@ func (h *PlanHandler) ImportPlanFromExcel(ctx context.Context, req *connect.Requ
 	}
 
 	// Get file from storage
-	reader, err := h.storage.GetReader(ctx, userID, fileID)
+	reader, err := h.openScannedFile(ctx, userID, fileID)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeNotFound, err)
+		return nil, err
 	}
 	defer reader.Close()
 
@@ -395,6 +507,7 @@ func (h *PlanHandler) ImportPlanFromExcel(ctx context.Context, req *connect.Requ
 		CategoryColumn: "A",
 		ValueColumn:    "B",
 		HeaderRow:      1,
+		SourceFileID:   &fileID,
 	}
 	if req.Msg.Mapping != nil {
 		if req.Msg.Mapping.CategoryColumn != "" {
@@ -417,7 +530,7 @@ func (h *PlanHandler) ImportPlanFromExcel(ctx context.Context, req *connect.Requ
 	// Import
 	importResult, err := h.svc.ImportFromExcel(ctx, userID, reader, req.Msg.SheetName, config, planName)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, planCreateError(err)
 	}
 
 	// Get plan with full category structure for response
@@ -450,9 +563,9 @@ func (h *PlanHandler) AnalyzeExcelForPlan(ctx context.Context, req *connect.Requ
 	}
 
 	// Get file from storage
-	reader, err := h.storage.GetReader(ctx, userID, fileID)
+	reader, err := h.openScannedFile(ctx, userID, fileID)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeNotFound, err)
+		return nil, err
 	}
 	defer reader.Close()
 
@@ -521,9 +634,9 @@ func (h *PlanHandler) AnalyzeExcelTree(ctx context.Context, req *connect.Request
 	}
 
 	// Get file from storage
-	reader, err := h.storage.GetReader(ctx, userID, fileID)
+	reader, err := h.openScannedFile(ctx, userID, fileID)
 	if err != nil {
-		return nil, connect.NewError(connect.CodeNotFound, err)
+		return nil, err
 	}
 	defer reader.Close()
 
@@ -697,6 +810,18 @@ func (h *PlanHandler) ComputePlanActuals(ctx context.Context, req *connect.Reque
 	}), nil
 }
 
+// ============================================================================
+// Plan Actuals Match Confidence (Internal Integration)
+// ============================================================================
+// ComputePlanActuals results carry per-item match confidence (Matches) and
+// suggested mappings for near-miss unmatched items, but the response only has
+// the unmatched reason ("low_confidence_match") until proto definitions exist.
+//
+// To expose, add the following proto definitions:
+// - ItemMatch message (item_id, match_type, category_id, category_name, confidence)
+//   and repeated ItemMatch matches on ComputePlanActualsResponse
+// - suggested_category_id, suggested_category_name and confidence on UnmatchedItem
+
 // ============================================================================
 // Conversion helpers
 // ============================================================================
@@ -769,6 +894,14 @@ func toProtoPlanWithDetails(d *service.PlanWithDetails) *echov1.UserPlan {
 			idStr := i.ConfigID.String()
 			item.ConfigId = &idStr
 		}
+		if i.SubscriptionID != nil {
+			idStr := i.SubscriptionID.String()
+			item.RecurringSubscriptionId = &idStr
+		}
+		if i.GoalID != nil {
+			idStr := i.GoalID.String()
+			item.GoalId = &idStr
+		}
 		if i.CategoryID != nil {
 			if cat, ok := categoryMap[*i.CategoryID]; ok {
 				cat.Items = append(cat.Items, item)
@@ -853,27 +986,138 @@ func toRepoFieldType(f echov1.FieldType) repository.FieldType {
 }
 
 // ============================================================================
-// Budget Period Methods (stub implementations - use BudgetPeriodHandler for full impl)
+// Plan Item Links (Internal Integration)
+// ============================================================================
+// Linked items are returned on PlanItem.recurring_subscription_id / goal_id.
+// Managing links is available on the plan service but requires proto definitions
+// to be exposed as API endpoints:
+//
+// - LinkPlanItem: link an item to a subscription or goal (or clear the link)
+// - ListLinkedItems: list a plan's linked items
+// - SetItemRollover: opt an item into envelope rollover (carry-in is stored on
+//   budget period items as rollover_minor)
+//
+// To expose as API endpoints, add the following proto definitions:
+// - LinkPlanItemRequest/Response
+// - ListLinkedPlanItemsRequest/Response
+// - SetItemRolloverRequest/Response
+// - BudgetPeriodItem.rollover_minor
+
+// ============================================================================
+// Flex Groups and Plan Summary (Internal Integration)
+// ============================================================================
+// Flex groups pool their items' budgets: item overspend is fine while the group
+// total holds. Summaries, budget alerts and pace are evaluated per group for
+// these. Available on the plan service but requires proto definitions:
+//
+// - SetGroupFlex: mark a category group as a flexible pool
+// - GetPlanSummary: budget lines (items, or flex groups as a whole) with pace
+//   and on_track/over_pace/over_budget status
+//
+// To expose as API endpoints, add the following proto definitions:
+// - PlanCategoryGroup.is_flex / CreateCategoryGroupInput.is_flex
+// - SetGroupFlexRequest/Response
+// - GetPlanSummaryRequest/Response
+
+// ============================================================================
+// Plan Revisions (Internal Integration)
+// ============================================================================
+// UpdatePlanStructure records an immutable revision (JSON snapshot of groups,
+// categories and items, with author and timestamp) for every change, plus a
+// baseline of the structure before the first one. Available on the plan service
+// but requires proto definitions to be exposed as API endpoints:
+//
+// - ListPlanRevisions: a plan's revisions, newest first
+// - GetPlanRevision: one revision with its snapshot
+// - RestorePlanRevision: put the structure back to a revision (recorded as a
+//   new revision, so a restore can itself be undone)
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ListPlanRevisionsRequest/Response, PlanRevision
+// - RestorePlanRevisionRequest/Response
+
+// ============================================================================
+// Plan Item Mappings (Internal Integration)
 // ============================================================================
+// Plan items can be mapped to one or more transaction categories and merchants.
+// ComputePlanActuals and ProcessTransaction match through these mappings first
+// and only fall back to item/category name matching for unmapped items. The
+// following methods are available on the plan service but require proto
+// definitions to be exposed as API endpoints:
+//
+// - SetItemMappings: replace an item's category IDs and merchant patterns
+// - ListItemMappings: all mappings of a plan's items
+//
+// To expose as API endpoints, add the following proto definitions:
+// - SetPlanItemMappingsRequest/Response, PlanItemMapping
+// - ListPlanItemMappingsRequest/Response
+
+// ============================================================================
+// Plan Period Configuration (Internal Integration)
+// ============================================================================
+// Plans window their actuals by a configurable budget period: monthly (from any
+// day of month), biweekly, or a custom date range. GetPlan already returns the
+// current period's actuals; the period itself and the previous period's actuals
+// (PlanWithDetails.PreviousActuals) are available on the plan service but
+// require proto definitions to be exposed:
+//
+// - SetPlanPeriod: set the period type, start and (custom) end date
+//
+// To expose as API endpoints, add the following proto definitions:
+// - UserPlan.period_type, period_start, period_end, current_period
+// - PlanItem.previous_actual
+// - SetPlanPeriodRequest/Response
+
+// ============================================================================
+// Plan Versions (Internal Integration)
+// ============================================================================
+// Every plan change bumps the plan's version. Until the proto carries it,
+// GetPlan, UpdatePlan and UpdatePlanStructure return the version as an ETag
+// header. UpdatePlan, UpdatePlanStructure and DeletePlan require an If-Match
+// header with it and fail with FailedPrecondition when it's missing, or when
+// another device changed the plan first (current version in the ETag error
+// metadata).
+//
+// To expose as API fields, add the following proto definitions:
+// - UserPlan.version
+// - UpdatePlanRequest, UpdatePlanStructureRequest and DeletePlanRequest.expected_version
+
+// ============================================================================
+// Budget Period Methods (delegate to BudgetPeriodHandler)
+// ============================================================================
+
+var errBudgetPeriodsDisabled = errors.New("budget periods not configured")
 
 // GetBudgetPeriod gets or creates a budget period for a specific month
 func (h *PlanHandler) GetBudgetPeriod(ctx context.Context, req *connect.Request[echov1.GetBudgetPeriodRequest]) (*connect.Response[echov1.GetBudgetPeriodResponse], error) {
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("use BudgetPeriodHandler"))
+	if h.periods == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errBudgetPeriodsDisabled)
+	}
+	return h.periods.GetBudgetPeriod(ctx, req)
 }
 
 // ListBudgetPeriods lists all periods for a plan
 func (h *PlanHandler) ListBudgetPeriods(ctx context.Context, req *connect.Request[echov1.ListBudgetPeriodsRequest]) (*connect.Response[echov1.ListBudgetPeriodsResponse], error) {
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("use BudgetPeriodHandler"))
+	if h.periods == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errBudgetPeriodsDisabled)
+	}
+	return h.periods.ListBudgetPeriods(ctx, req)
 }
 
 // UpdateBudgetPeriodItem updates a specific item's values
 func (h *PlanHandler) UpdateBudgetPeriodItem(ctx context.Context, req *connect.Request[echov1.UpdateBudgetPeriodItemRequest]) (*connect.Response[echov1.UpdateBudgetPeriodItemResponse], error) {
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("use BudgetPeriodHandler"))
+	if h.periods == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errBudgetPeriodsDisabled)
+	}
+	return h.periods.UpdateBudgetPeriodItem(ctx, req)
 }
 
 // CopyBudgetPeriod copies values from one period to another
 func (h *PlanHandler) CopyBudgetPeriod(ctx context.Context, req *connect.Request[echov1.CopyBudgetPeriodRequest]) (*connect.Response[echov1.CopyBudgetPeriodResponse], error) {
-	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("use BudgetPeriodHandler"))
+	if h.periods == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, errBudgetPeriodsDisabled)
+	}
+	return h.periods.CopyBudgetPeriod(ctx, req)
 }
 
 // ============================================================================
@@ -1028,10 +1272,14 @@ func toProtoItemType(t repository.ItemType) echov1.ItemType {
 
 // GetPlanItemsByTab returns items filtered by target tab
 func (h *PlanHandler) GetPlanItemsByTab(ctx context.Context, req *connect.Request[echov1.GetPlanItemsByTabRequest]) (*connect.Response[echov1.GetPlanItemsByTabResponse], error) {
-	_, ok := interceptors.GetUserIDFromContext(ctx)
+	userIDStr, ok := interceptors.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("user not authenticated"))
 	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
 
 	planID, err := uuid.Parse(req.Msg.PlanId)
 	if err != nil {
@@ -1040,10 +1288,13 @@ func (h *PlanHandler) GetPlanItemsByTab(ctx context.Context, req *connect.Reques
 
 	targetTab := toRepoTargetTab(req.Msg.TargetTab)
 
-	result, err := h.svc.GetItemsByTab(ctx, planID, targetTab)
+	result, err := h.svc.GetItemsByTab(ctx, userID, planID, targetTab)
 	if err != nil {
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
+	if result == nil {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
 
 	var protoItems []*echov1.PlanItemWithConfig
 	for _, item := range result.Items {
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// versionedPlanRepository holds one plan and bumps its version on changes like
+// the database. Other repository methods aren't used by these tests.
+type versionedPlanRepository struct {
+	repository.PlanRepository
+	plan    repository.UserPlan
+	changes int
+}
+
+func (f *versionedPlanRepository) GetPlanByID(_ context.Context, _ uuid.UUID) (*repository.UserPlan, error) {
+	plan := f.plan
+	return &plan, nil
+}
+
+func (f *versionedPlanRepository) bump(expectedVersion *int64) error {
+	if expectedVersion != nil && *expectedVersion != f.plan.Version {
+		return &repository.VersionConflictError{Current: f.plan.Version}
+	}
+	f.plan.Version++
+	f.changes++
+	return nil
+}
+
+func (f *versionedPlanRepository) UpdatePlan(_ context.Context, _ *repository.UserPlan, expectedVersion *int64) error {
+	return f.bump(expectedVersion)
+}
+
+func (f *versionedPlanRepository) DeletePlan(_ context.Context, _ uuid.UUID, expectedVersion *int64) error {
+	return f.bump(expectedVersion)
+}
+
+func TestPlanMutations_RequireVersion(t *testing.T) {
+	userID := uuid.New()
+	planID := uuid.New()
+	ctx := interceptors.ContextWithClaims(context.Background(), &interceptors.Claims{UserID: userID.String()})
+	name := "Renamed"
+
+	calls := map[string]func(h *PlanHandler, ifMatch string) error{
+		"UpdatePlan": func(h *PlanHandler, ifMatch string) error {
+			req := connect.NewRequest(&echov1.UpdatePlanRequest{PlanId: planID.String(), Name: &name})
+			req.Header().Set("If-Match", ifMatch)
+			_, err := h.UpdatePlan(ctx, req)
+			return err
+		},
+		"UpdatePlanStructure": func(h *PlanHandler, ifMatch string) error {
+			req := connect.NewRequest(&echov1.UpdatePlanStructureRequest{PlanId: planID.String()})
+			req.Header().Set("If-Match", ifMatch)
+			_, err := h.UpdatePlanStructure(ctx, req)
+			return err
+		},
+		"DeletePlan": func(h *PlanHandler, ifMatch string) error {
+			req := connect.NewRequest(&echov1.DeletePlanRequest{PlanId: planID.String()})
+			req.Header().Set("If-Match", ifMatch)
+			_, err := h.DeletePlan(ctx, req)
+			return err
+		},
+	}
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		wantCode connect.Code
+		wantETag string
+	}{
+		{"missing", "", connect.CodeFailedPrecondition, ""},
+		{"wildcard", "*", connect.CodeFailedPrecondition, ""},
+		{"malformed", `"v3"`, connect.CodeInvalidArgument, ""},
+		{"stale", `"2"`, connect.CodeFailedPrecondition, `"3"`},
+	}
+
+	for rpc, call := range calls {
+		for _, tt := range tests {
+			if rpc == "UpdatePlanStructure" && tt.wantETag != "" {
+				continue // The service tests cover stale structure updates
+			}
+			t.Run(rpc+"/"+tt.name, func(t *testing.T) {
+				repo := &versionedPlanRepository{plan: repository.UserPlan{ID: planID, UserID: userID, Version: 3}}
+				h := NewPlanHandler(service.NewPlanService(repo, nil, nil, slog.New(slog.DiscardHandler)), nil)
+
+				err := call(h, tt.ifMatch)
+				if connect.CodeOf(err) != tt.wantCode {
+					t.Fatalf("expected %v, got %v", tt.wantCode, err)
+				}
+				if tt.wantETag != "" {
+					var connectErr *connect.Error
+					if !errors.As(err, &connectErr) || connectErr.Meta().Get("ETag") != tt.wantETag {
+						t.Fatalf("expected the current version %s in the error's ETag, got %v", tt.wantETag, err)
+					}
+				}
+				if repo.changes != 0 {
+					t.Fatalf("expected the plan to be left alone, got %d changes", repo.changes)
+				}
+			})
+		}
+	}
+}
+
+func TestUpdatePlan_ReturnsNewVersion(t *testing.T) {
+	userID := uuid.New()
+	planID := uuid.New()
+	ctx := interceptors.ContextWithClaims(context.Background(), &interceptors.Claims{UserID: userID.String()})
+	repo := &versionedPlanRepository{plan: repository.UserPlan{ID: planID, UserID: userID, Version: 3}}
+	h := NewPlanHandler(service.NewPlanService(repo, nil, nil, slog.New(slog.DiscardHandler)), nil)
+
+	name := "Renamed"
+	req := connect.NewRequest(&echov1.UpdatePlanRequest{PlanId: planID.String(), Name: &name})
+	req.Header().Set("If-Match", `"3"`)
+	res, err := h.UpdatePlan(ctx, req)
+	if err != nil {
+		t.Fatalf("UpdatePlan failed: %v", err)
+	}
+	if got := res.Header().Get("ETag"); got != `"4"` {
+		t.Fatalf("expected ETag \"4\", got %s", got)
+	}
+}
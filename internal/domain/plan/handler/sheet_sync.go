@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+)
+
+// ============================================================================
+// Google Sheets Sync (Internal Integration)
+// ============================================================================
+// Linked plans are pushed to their sheet whenever budgets or actuals change and
+// pulled on the scheduler; when both sides changed a row since the last sync,
+// the most recent edit wins. Available on the sheet sync service but requires
+// proto definitions to be exposed as API endpoints:
+//
+// - AuthURL: Google consent URL for connecting the user's account
+// - LinkPlan / UnlinkPlan: map a plan to a spreadsheet tab (or stop syncing)
+// - GetSheetSyncStatus: the plan's mapping plus its recent push/pull log
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ConnectGoogleSheetsRequest/Response
+// - LinkPlanSheetRequest/Response
+// - UnlinkPlanSheetRequest/Response
+// - GetSheetSyncStatusRequest/Response, SheetSyncLogEntry
+
+// SheetsOAuthHandler completes the Google OAuth flow started from AuthURL
+type SheetsOAuthHandler struct {
+	svc    *service.SheetSyncService
+	logger *slog.Logger
+}
+
+// NewSheetsOAuthHandler creates a new Google OAuth callback handler
+func NewSheetsOAuthHandler(svc *service.SheetSyncService, logger *slog.Logger) *SheetsOAuthHandler {
+	return &SheetsOAuthHandler{svc: svc, logger: logger}
+}
+
+// ServeHTTP stores the user's Google tokens
+func (h *SheetsOAuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if errParam := query.Get("error"); errParam != "" {
+		http.Error(w, "Google Sheets access was not granted.", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.svc.CompleteOAuth(r.Context(), query.Get("state"), query.Get("code"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOAuthState) {
+			http.Error(w, "This link has expired. Please connect Google Sheets again.", http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("failed to complete google sheets oauth", slog.Any("error", err))
+		http.Error(w, "Failed to connect Google Sheets.", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("google sheets connected", slog.String("user_id", userID.String()))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("Google Sheets connected. You can close this window."))
+}
@@ -0,0 +1,36 @@
+// Package repository provides effective-dated plan item budgets
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetItemBudgetsAsOf returns each item's budget as it was at asOf, keyed by item
+// ID. Items created after asOf are absent.
+func (r *PostgresPlanRepository) GetItemBudgetsAsOf(ctx context.Context, planID uuid.UUID, asOf time.Time) (map[uuid.UUID]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (item_id) item_id, budgeted_minor
+		FROM plan_item_budget_history
+		WHERE plan_id = $1 AND effective_at <= $2
+		ORDER BY item_id, effective_at DESC, id DESC
+	`, planID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item budgets as of %s: %w", asOf.Format(time.RFC3339), err)
+	}
+	defer rows.Close()
+
+	budgets := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var itemID uuid.UUID
+		var budgeted int64
+		if err := rows.Scan(&itemID, &budgeted); err != nil {
+			return nil, fmt.Errorf("failed to scan item budget: %w", err)
+		}
+		budgets[itemID] = budgeted
+	}
+	return budgets, rows.Err()
+}
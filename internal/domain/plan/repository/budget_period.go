@@ -3,6 +3,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,13 +32,20 @@ type BudgetPeriodItem struct {
 	ItemID        uuid.UUID
 	ItemName      string
 	CategoryName  string
+	ItemType      ItemType // Set when listed with a period
 	BudgetedMinor int64
 	ActualMinor   int64
+	RolloverMinor int64 // Carried in from the previous period (negative after overspend)
 	Notes         *string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// AvailableMinor is what is left to spend this period, including rollover
+func (i *BudgetPeriodItem) AvailableMinor() int64 {
+	return i.BudgetedMinor + i.RolloverMinor - i.ActualMinor
+}
+
 // BudgetPeriodWithItems is a period with its items
 type BudgetPeriodWithItems struct {
 	Period *BudgetPeriod
@@ -59,6 +68,21 @@ type BudgetPeriodRepository interface {
 
 	// CopyPeriodItems copies items from one period to another
 	CopyPeriodItems(ctx context.Context, sourcePeriodID uuid.UUID, targetPlanID uuid.UUID, targetYear, targetMonth int) (*BudgetPeriodWithItems, error)
+
+	// FindPeriod gets a period for a specific month without creating it (nil if missing)
+	FindPeriod(ctx context.Context, planID uuid.UUID, year, month int) (*BudgetPeriod, error)
+
+	// GetPeriodForItem gets the period a period item belongs to
+	GetPeriodForItem(ctx context.Context, periodItemID uuid.UUID) (*BudgetPeriod, error)
+
+	// CopyBudgetedValues copies budgeted values between two existing periods
+	CopyBudgetedValues(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) error
+
+	// ApplyRollover carries the remaining amount of rollover-enabled items into the target period
+	ApplyRollover(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) (int, error)
+
+	// SnapshotPeriodActuals copies the plan items' current actuals into a period
+	SnapshotPeriodActuals(ctx context.Context, periodID uuid.UUID) error
 }
 
 // PostgresBudgetPeriodRepository implements BudgetPeriodRepository with PostgreSQL
@@ -85,7 +109,7 @@ func (r *PostgresBudgetPeriodRepository) GetOrCreatePeriod(ctx context.Context,
 	)
 
 	wasCreated := false
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		// Create new period (trigger will copy items)
 		err = r.pool.QueryRow(ctx, `
 			INSERT INTO budget_periods (plan_id, year, month)
@@ -151,6 +175,9 @@ func (r *PostgresBudgetPeriodRepository) GetPeriodByID(ctx context.Context, peri
 		&period.ID, &period.PlanID, &period.Year, &period.Month,
 		&period.IsLocked, &period.Notes, &period.CreatedAt, &period.UpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get budget period: %w", err)
 	}
@@ -174,12 +201,15 @@ func (r *PostgresBudgetPeriodRepository) UpdatePeriodItem(ctx context.Context, p
 			notes = COALESCE($4, notes),
 			updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, period_id, item_id, budgeted_minor, actual_minor, notes, created_at, updated_at
+		RETURNING id, period_id, item_id, budgeted_minor, actual_minor, rollover_minor, notes, created_at, updated_at
 	`, periodItemID, budgeted, actual, notes).Scan(
 		&item.ID, &item.PeriodID, &item.ItemID,
-		&item.BudgetedMinor, &item.ActualMinor, &item.Notes,
+		&item.BudgetedMinor, &item.ActualMinor, &item.RolloverMinor, &item.Notes,
 		&item.CreatedAt, &item.UpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to update period item: %w", err)
 	}
@@ -204,20 +234,104 @@ func (r *PostgresBudgetPeriodRepository) CopyPeriodItems(ctx context.Context, so
 	}
 
 	// Copy budgeted values from source period
-	_, err = r.pool.Exec(ctx, `
+	if err := r.CopyBudgetedValues(ctx, sourcePeriodID, target.Period.ID); err != nil {
+		return nil, err
+	}
+
+	// Refetch items with updated values
+	return r.GetPeriodByID(ctx, target.Period.ID)
+}
+
+// CopyBudgetedValues copies budgeted values for matching items between periods
+func (r *PostgresBudgetPeriodRepository) CopyBudgetedValues(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
 		UPDATE budget_period_items tgt
 		SET budgeted_minor = src.budgeted_minor
 		FROM budget_period_items src
 		WHERE src.period_id = $1
 		  AND tgt.period_id = $2
 		  AND src.item_id = tgt.item_id
-	`, sourcePeriodID, target.Period.ID)
+	`, sourcePeriodID, targetPeriodID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy period items: %w", err)
+		return fmt.Errorf("failed to copy period items: %w", err)
 	}
+	return nil
+}
 
-	// Refetch items with updated values
-	return r.GetPeriodByID(ctx, target.Period.ID)
+// ApplyRollover sets each rollover-enabled item's carry-in to what remained in the source period
+func (r *PostgresBudgetPeriodRepository) ApplyRollover(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE budget_period_items tgt
+		SET rollover_minor = src.budgeted_minor + src.rollover_minor - src.actual_minor,
+		    updated_at = NOW()
+		FROM budget_period_items src
+		JOIN plan_items pi ON pi.id = src.item_id
+		WHERE src.period_id = $1
+		  AND tgt.period_id = $2
+		  AND src.item_id = tgt.item_id
+		  AND pi.rollover_enabled
+	`, sourcePeriodID, targetPeriodID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply budget rollover: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// SnapshotPeriodActuals records the plan items' current actuals on a period's items
+func (r *PostgresBudgetPeriodRepository) SnapshotPeriodActuals(ctx context.Context, periodID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE budget_period_items bpi
+		SET actual_minor = pi.actual_minor
+		FROM plan_items pi
+		WHERE bpi.period_id = $1
+		  AND pi.id = bpi.item_id
+		  AND bpi.actual_minor IS DISTINCT FROM pi.actual_minor
+	`, periodID)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot period actuals: %w", err)
+	}
+	return nil
+}
+
+// FindPeriod gets a period for a month without creating it
+func (r *PostgresBudgetPeriodRepository) FindPeriod(ctx context.Context, planID uuid.UUID, year, month int) (*BudgetPeriod, error) {
+	var period BudgetPeriod
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, plan_id, year, month, is_locked, notes, created_at, updated_at
+		FROM budget_periods
+		WHERE plan_id = $1 AND year = $2 AND month = $3
+	`, planID, year, month).Scan(
+		&period.ID, &period.PlanID, &period.Year, &period.Month,
+		&period.IsLocked, &period.Notes, &period.CreatedAt, &period.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find budget period: %w", err)
+	}
+	return &period, nil
+}
+
+// GetPeriodForItem gets the period that owns a period item
+func (r *PostgresBudgetPeriodRepository) GetPeriodForItem(ctx context.Context, periodItemID uuid.UUID) (*BudgetPeriod, error) {
+	var period BudgetPeriod
+	err := r.pool.QueryRow(ctx, `
+		SELECT bp.id, bp.plan_id, bp.year, bp.month, bp.is_locked, bp.notes, bp.created_at, bp.updated_at
+		FROM budget_period_items bpi
+		JOIN budget_periods bp ON bp.id = bpi.period_id
+		WHERE bpi.id = $1
+	`, periodItemID).Scan(
+		&period.ID, &period.PlanID, &period.Year, &period.Month,
+		&period.IsLocked, &period.Notes, &period.CreatedAt, &period.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget period for item: %w", err)
+	}
+	return &period, nil
 }
 
 // getPeriodItems gets all items for a period with names
@@ -227,7 +341,8 @@ func (r *PostgresBudgetPeriodRepository) getPeriodItems(ctx context.Context, per
 			bpi.id, bpi.period_id, bpi.item_id,
 			pi.name as item_name,
 			COALESCE(pc.name, 'Uncategorized') as category_name,
-			bpi.budgeted_minor, bpi.actual_minor, bpi.notes,
+			pi.item_type,
+			bpi.budgeted_minor, bpi.actual_minor, bpi.rollover_minor, bpi.notes,
 			bpi.created_at, bpi.updated_at
 		FROM budget_period_items bpi
 		JOIN plan_items pi ON bpi.item_id = pi.id
@@ -245,8 +360,8 @@ func (r *PostgresBudgetPeriodRepository) getPeriodItems(ctx context.Context, per
 		var item BudgetPeriodItem
 		if err := rows.Scan(
 			&item.ID, &item.PeriodID, &item.ItemID,
-			&item.ItemName, &item.CategoryName,
-			&item.BudgetedMinor, &item.ActualMinor, &item.Notes,
+			&item.ItemName, &item.CategoryName, &item.ItemType,
+			&item.BudgetedMinor, &item.ActualMinor, &item.RolloverMinor, &item.Notes,
 			&item.CreatedAt, &item.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan period item: %w", err)
@@ -0,0 +1,94 @@
+// Package repository provides plan item links to subscriptions and goals
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetItemLink links a plan item to a subscription or goal owned by the plan's user.
+// Passing nil for both clears the link. Linking a subscription also resets the
+// item's budget to the subscription's monthly cost. Returns sql.ErrNoRows if the
+// item or the link target doesn't exist for the plan owner.
+func (r *PostgresPlanRepository) SetItemLink(ctx context.Context, planID, itemID uuid.UUID, subscriptionID, goalID *uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE plan_items pi
+		SET subscription_id = $3,
+		    goal_id = $4,
+		    budgeted_minor = COALESCE((
+		        SELECT recurring_monthly_minor(rs.amount_minor, rs.cadence, rs.status)
+		        FROM recurring_subscriptions rs
+		        WHERE rs.id = $3
+		    ), pi.budgeted_minor),
+		    updated_at = NOW()
+		FROM user_plans up
+		WHERE pi.id = $2
+		  AND pi.plan_id = $1
+		  AND up.id = pi.plan_id
+		  AND ($3::uuid IS NULL OR EXISTS (
+		      SELECT 1 FROM recurring_subscriptions rs WHERE rs.id = $3 AND rs.user_id = up.user_id
+		  ))
+		  AND ($4::uuid IS NULL OR EXISTS (
+		      SELECT 1 FROM goals g WHERE g.id = $4 AND g.user_id = up.user_id
+		  ))
+	`, planID, itemID, subscriptionID, goalID)
+	if err != nil {
+		return fmt.Errorf("failed to set item link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SyncSubscriptionLinkedItems reconciles the budget of every subscription-linked item
+// with its subscription's current monthly cost. Returns the number of items corrected.
+func (r *PostgresPlanRepository) SyncSubscriptionLinkedItems(ctx context.Context) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE plan_items pi
+		SET budgeted_minor = recurring_monthly_minor(rs.amount_minor, rs.cadence, rs.status),
+		    updated_at = NOW()
+		FROM recurring_subscriptions rs
+		WHERE pi.subscription_id = rs.id
+		  AND pi.budgeted_minor IS DISTINCT FROM recurring_monthly_minor(rs.amount_minor, rs.cadence, rs.status)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync subscription linked items: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetGoalContributionTotals sums contributions per goal within [start, end)
+func (r *PostgresPlanRepository) GetGoalContributionTotals(ctx context.Context, goalIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]int64, error) {
+	totals := make(map[uuid.UUID]int64, len(goalIDs))
+	if len(goalIDs) == 0 {
+		return totals, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT goal_id, COALESCE(SUM(amount_minor), 0)
+		FROM goal_contributions
+		WHERE goal_id = ANY($1)
+		  AND contributed_at >= $2
+		  AND contributed_at < $3
+		GROUP BY goal_id
+	`, goalIDs, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goal contribution totals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var goalID uuid.UUID
+		var total int64
+		if err := rows.Scan(&goalID, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan goal contribution total: %w", err)
+		}
+		totals[goalID] = total
+	}
+	return totals, rows.Err()
+}
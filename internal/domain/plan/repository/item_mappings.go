@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ItemMapping maps a transaction category or a merchant to a plan item
+type ItemMapping struct {
+	ID              uuid.UUID
+	PlanItemID      uuid.UUID
+	CategoryID      *uuid.UUID
+	CategoryName    *string // Populated on reads
+	MerchantPattern *string // Case-insensitive substring of the merchant name or description
+	CreatedAt       time.Time
+}
+
+// MappedTotal is the spending attributed to a plan item through its mappings
+type MappedTotal struct {
+	TotalMinor int64
+	Count      int
+}
+
+// ItemMappingRepository defines data access for plan item category/merchant mappings
+type ItemMappingRepository interface {
+	// SetItemMappings replaces an item's mappings. Returns sql.ErrNoRows if the item
+	// isn't in the plan or a category doesn't belong to the plan's owner.
+	SetItemMappings(ctx context.Context, planID, itemID uuid.UUID, categoryIDs []uuid.UUID, merchants []string) ([]*ItemMapping, error)
+	ListMappingsByPlan(ctx context.Context, planID uuid.UUID) ([]*ItemMapping, error)
+	// GetMerchantMappedTotals sums expenses within [start, end) at each item's mapped
+	// merchants, skipping transactions whose category is also mapped to the item
+	GetMerchantMappedTotals(ctx context.Context, planID, userID uuid.UUID, start, end time.Time) (map[uuid.UUID]MappedTotal, error)
+}
+
+// PostgresItemMappingRepository implements ItemMappingRepository using PostgreSQL
+type PostgresItemMappingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresItemMappingRepository creates a new PostgreSQL item mapping repository
+func NewPostgresItemMappingRepository(pool *pgxpool.Pool) *PostgresItemMappingRepository {
+	return &PostgresItemMappingRepository{pool: pool}
+}
+
+// SetItemMappings replaces an item's mappings in a single transaction
+func (r *PostgresItemMappingRepository) SetItemMappings(ctx context.Context, planID, itemID uuid.UUID, categoryIDs []uuid.UUID, merchants []string) ([]*ItemMapping, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var userID uuid.UUID
+	var householdID *uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT up.user_id, up.household_id
+		FROM plan_items pi
+		JOIN user_plans up ON up.id = pi.plan_id
+		WHERE pi.id = $1 AND pi.plan_id = $2
+		FOR UPDATE OF pi`, itemID, planID,
+	).Scan(&userID, &householdID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan item: %w", err)
+	}
+
+	if len(categoryIDs) > 0 {
+		// The plan owner's categories, and those shared with the plan's household
+		var owned int
+		err = tx.QueryRow(ctx, `
+			SELECT COUNT(DISTINCT id) FROM categories
+			WHERE id = ANY($1) AND (user_id = $2 OR household_id = $3)`,
+			categoryIDs, userID, householdID,
+		).Scan(&owned)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check categories: %w", err)
+		}
+		if owned != len(uniqueIDs(categoryIDs)) {
+			return nil, sql.ErrNoRows
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM plan_item_category_mappings WHERE plan_item_id = $1`, itemID); err != nil {
+		return nil, fmt.Errorf("failed to clear item mappings: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO plan_item_category_mappings (plan_item_id, category_id)
+		SELECT $1, c FROM UNNEST($2::uuid[]) AS c
+		ON CONFLICT DO NOTHING`, itemID, categoryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert category mappings: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO plan_item_category_mappings (plan_item_id, merchant_pattern)
+		SELECT $1, m FROM UNNEST($2::text[]) AS m
+		ON CONFLICT DO NOTHING`, itemID, merchants)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert merchant mappings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit item mappings: %w", err)
+	}
+
+	mappings, err := r.ListMappingsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*ItemMapping
+	for _, m := range mappings {
+		if m.PlanItemID == itemID {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+// ListMappingsByPlan lists all mappings for a plan's items
+func (r *PostgresItemMappingRepository) ListMappingsByPlan(ctx context.Context, planID uuid.UUID) ([]*ItemMapping, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.id, m.plan_item_id, m.category_id, c.name::text, m.merchant_pattern, m.created_at
+		FROM plan_item_category_mappings m
+		JOIN plan_items pi ON pi.id = m.plan_item_id
+		LEFT JOIN categories c ON c.id = m.category_id
+		WHERE pi.plan_id = $1
+		ORDER BY m.plan_item_id, m.created_at`, planID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list item mappings: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []*ItemMapping
+	for rows.Next() {
+		m := &ItemMapping{}
+		if err := rows.Scan(&m.ID, &m.PlanItemID, &m.CategoryID, &m.CategoryName, &m.MerchantPattern, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan item mapping: %w", err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+// GetMerchantMappedTotals sums expenses at mapped merchants per plan item, or
+// inflows (negated) for items with a negative budget. A transaction matching
+// several of an item's merchants is only counted once.
+func (r *PostgresItemMappingRepository) GetMerchantMappedTotals(ctx context.Context, planID, userID uuid.UUID, start, end time.Time) (map[uuid.UUID]MappedTotal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT plan_item_id, SUM(-amount_minor), COUNT(*)
+		FROM (
+			SELECT DISTINCT m.plan_item_id, t.id, t.amount_minor
+			FROM plan_item_category_mappings m
+			JOIN plan_items pi ON pi.id = m.plan_item_id
+			JOIN transactions t
+			  ON t.user_id = $2
+			 AND t.deleted_at IS NULL
+			 AND t.posted_at >= $3
+			 AND t.posted_at < $4
+			 AND (t.amount_minor < 0) = (pi.budgeted_minor >= 0 OR pi.item_type = 'income')
+			 AND t.amount_minor <> 0
+			 AND POSITION(LOWER(m.merchant_pattern) IN LOWER(COALESCE(t.merchant_name, '') || ' ' || t.description)) > 0
+			WHERE pi.plan_id = $1
+			  AND m.merchant_pattern IS NOT NULL
+			  AND NOT EXISTS (
+			      SELECT 1 FROM plan_item_category_mappings cm
+			      WHERE cm.plan_item_id = m.plan_item_id AND cm.category_id = t.category_id
+			  )
+		) matched
+		GROUP BY plan_item_id`, planID, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant mapped totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[uuid.UUID]MappedTotal)
+	for rows.Next() {
+		var itemID uuid.UUID
+		var total MappedTotal
+		if err := rows.Scan(&itemID, &total.TotalMinor, &total.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant mapped total: %w", err)
+		}
+		totals[itemID] = total
+	}
+	return totals, rows.Err()
+}
+
+// uniqueIDs removes duplicate IDs, keeping the first occurrence
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	result := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
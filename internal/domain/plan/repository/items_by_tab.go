@@ -128,7 +128,8 @@ func (r *PostgresPlanRepository) GetItemsByTab(ctx context.Context, planID uuid.
 	return items, nil
 }
 
-// GetItemsByTabWithTotals returns items and totals for a plan filtered by target tab
+// GetItemsByTabWithTotals returns items and totals for a plan filtered by target tab.
+// Credit items carry a negative budget and actual, so they net against the others.
 func (r *PostgresPlanRepository) GetItemsByTabWithTotals(ctx context.Context, planID uuid.UUID, targetTab TargetTab) ([]PlanItemWithConfig, int64, int64, error) {
 	items, err := r.GetItemsByTab(ctx, planID, targetTab)
 	if err != nil {
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -34,13 +36,15 @@ func (r *PostgresPlanRepository) CreatePlan(ctx context.Context, plan *UserPlan)
 	query := `
 		INSERT INTO user_plans (
 			id, user_id, name, description, status, source_type,
-			source_file_id, excel_sheet_name, config, currency_code
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			source_file_id, excel_sheet_name, config, currency_code,
+			period_type, period_start, period_end
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), 'monthly'), $12, $13)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
 		plan.ID, plan.UserID, plan.Name, plan.Description, plan.Status, plan.SourceType,
 		plan.SourceFileID, plan.ExcelSheetName, plan.Config, plan.CurrencyCode,
+		string(plan.PeriodType), plan.PeriodStart, plan.PeriodEnd,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create plan: %w", err)
@@ -55,8 +59,9 @@ func (r *PostgresPlanRepository) GetPlanByID(ctx context.Context, planID uuid.UU
 		SELECT id, user_id, name, description, status, source_type,
 		       source_file_id, excel_sheet_name, config,
 		       total_income_minor, total_expenses_minor, currency_code,
+		       period_type, period_start, period_end, household_id, version,
 		       created_at, updated_at
-		FROM user_plans WHERE id = $1
+		FROM user_plans WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var plan UserPlan
@@ -64,6 +69,7 @@ func (r *PostgresPlanRepository) GetPlanByID(ctx context.Context, planID uuid.UU
 		&plan.ID, &plan.UserID, &plan.Name, &plan.Description, &plan.Status, &plan.SourceType,
 		&plan.SourceFileID, &plan.ExcelSheetName, &plan.Config,
 		&plan.TotalIncomeMinor, &plan.TotalExpensesMinor, &plan.CurrencyCode,
+		&plan.PeriodType, &plan.PeriodStart, &plan.PeriodEnd, &plan.HouseholdID, &plan.Version,
 		&plan.CreatedAt, &plan.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -76,11 +82,14 @@ func (r *PostgresPlanRepository) GetPlanByID(ctx context.Context, planID uuid.UU
 	return &plan, nil
 }
 
-// ListPlansByUser lists all plans for a user
+// planAccessFilter matches the plans of user $1 and those shared with their household
+const planAccessFilter = `(user_id = $1 OR household_id IN (SELECT household_id FROM household_members WHERE user_id = $1))`
+
+// ListPlansByUser lists a user's plans and the plans shared with their household
 func (r *PostgresPlanRepository) ListPlansByUser(ctx context.Context, userID uuid.UUID, status *PlanStatus, limit, offset int) ([]*UserPlan, int, error) {
 	// Count query
 	countArgs := []any{userID}
-	countQuery := `SELECT COUNT(*) FROM user_plans WHERE user_id = $1 AND status != 'archived'`
+	countQuery := `SELECT COUNT(*) FROM user_plans WHERE ` + planAccessFilter + ` AND status != 'archived'`
 	if status != nil {
 		countQuery += ` AND status = $2`
 		countArgs = append(countArgs, *status)
@@ -97,9 +106,10 @@ func (r *PostgresPlanRepository) ListPlansByUser(ctx context.Context, userID uui
 		SELECT id, user_id, name, description, status, source_type,
 		       source_file_id, excel_sheet_name, config,
 		       total_income_minor, total_expenses_minor, currency_code,
+		       period_type, period_start, period_end, household_id, version,
 		       created_at, updated_at
 		FROM user_plans
-		WHERE user_id = $1 AND status != 'archived'
+		WHERE ` + planAccessFilter + ` AND status != 'archived'
 	`
 	argIdx := 2
 	if status != nil {
@@ -123,6 +133,7 @@ func (r *PostgresPlanRepository) ListPlansByUser(ctx context.Context, userID uui
 			&p.ID, &p.UserID, &p.Name, &p.Description, &p.Status, &p.SourceType,
 			&p.SourceFileID, &p.ExcelSheetName, &p.Config,
 			&p.TotalIncomeMinor, &p.TotalExpensesMinor, &p.CurrencyCode,
+			&p.PeriodType, &p.PeriodStart, &p.PeriodEnd, &p.HouseholdID, &p.Version,
 			&p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan plan: %w", err)
@@ -139,6 +150,7 @@ func (r *PostgresPlanRepository) ListAllActivePlans(ctx context.Context, limit,
 		SELECT id, user_id, name, description, status, source_type,
 		       source_file_id, excel_sheet_name, config,
 		       total_income_minor, total_expenses_minor, currency_code,
+		       period_type, period_start, period_end, household_id, version,
 		       created_at, updated_at
 		FROM user_plans
 		WHERE status = 'active'
@@ -159,6 +171,7 @@ func (r *PostgresPlanRepository) ListAllActivePlans(ctx context.Context, limit,
 			&p.ID, &p.UserID, &p.Name, &p.Description, &p.Status, &p.SourceType,
 			&p.SourceFileID, &p.ExcelSheetName, &p.Config,
 			&p.TotalIncomeMinor, &p.TotalExpensesMinor, &p.CurrencyCode,
+			&p.PeriodType, &p.PeriodStart, &p.PeriodEnd, &p.HouseholdID, &p.Version,
 			&p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan active plan: %w", err)
@@ -169,29 +182,84 @@ func (r *PostgresPlanRepository) ListAllActivePlans(ctx context.Context, limit,
 	return plans, nil
 }
 
-// UpdatePlan updates an existing plan
-func (r *PostgresPlanRepository) UpdatePlan(ctx context.Context, plan *UserPlan) error {
+// UpdatePlan updates an existing plan and bumps its version. With an expected
+// version, it fails with a VersionConflictError if the plan has moved on.
+func (r *PostgresPlanRepository) UpdatePlan(ctx context.Context, plan *UserPlan, expectedVersion *int64) error {
 	query := `
 		UPDATE user_plans SET
-			name = $2, description = $3, status = $4, config = $5
-		WHERE id = $1
+			name = $2, description = $3, status = $4, config = $5,
+			version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND ($6::BIGINT IS NULL OR version = $6)
 	`
 
-	_, err := r.pool.Exec(ctx, query, plan.ID, plan.Name, plan.Description, plan.Status, plan.Config)
+	tag, err := r.pool.Exec(ctx, query, plan.ID, plan.Name, plan.Description, plan.Status, plan.Config, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("failed to update plan: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return r.versionConflict(ctx, plan.ID)
+	}
+
+	return nil
+}
 
+// UpdatePlanPeriod sets how a plan's budget periods recur
+func (r *PostgresPlanRepository) UpdatePlanPeriod(ctx context.Context, planID uuid.UUID, periodType PlanPeriodType, start, end *time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE user_plans
+		SET period_type = $2, period_start = $3, period_end = $4, updated_at = NOW()
+		WHERE id = $1
+	`, planID, periodType, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to update plan period: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
-// DeletePlan soft-deletes a plan by setting status to archived
-func (r *PostgresPlanRepository) DeletePlan(ctx context.Context, planID uuid.UUID) error {
-	query := `UPDATE user_plans SET status = 'archived' WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, planID)
+// DeletePlan soft-deletes a plan by archiving it and moving it to the trash.
+// With an expected version, it fails with a VersionConflictError if the plan
+// has moved on.
+func (r *PostgresPlanRepository) DeletePlan(ctx context.Context, planID uuid.UUID, expectedVersion *int64) error {
+	query := `
+		UPDATE user_plans SET status = 'archived', deleted_at = NOW(), updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND ($2::BIGINT IS NULL OR version = $2)
+	`
+	tag, err := r.pool.Exec(ctx, query, planID, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("failed to delete plan: %w", err)
 	}
+	if tag.RowsAffected() == 0 && expectedVersion != nil {
+		return r.versionConflict(ctx, planID)
+	}
+	return nil
+}
+
+// versionConflict explains why a versioned plan update matched no row: the
+// plan is gone, or it's at another version than expected
+func (r *PostgresPlanRepository) versionConflict(ctx context.Context, planID uuid.UUID) error {
+	var version int64
+	err := r.pool.QueryRow(ctx, `SELECT version FROM user_plans WHERE id = $1 AND deleted_at IS NULL`, planID).Scan(&version)
+	if err == pgx.ErrNoRows {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get plan version: %w", err)
+	}
+	return &VersionConflictError{Current: version}
+}
+
+// SetPlanHousehold shares a plan with a household, or stops sharing it when nil
+func (r *PostgresPlanRepository) SetPlanHousehold(ctx context.Context, planID uuid.UUID, householdID *uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE user_plans SET household_id = $2, updated_at = NOW() WHERE id = $1`, planID, householdID)
+	if err != nil {
+		return fmt.Errorf("failed to set plan household: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
@@ -210,7 +278,7 @@ func (r *PostgresPlanRepository) SetActivePlan(ctx context.Context, userID, plan
 	}
 
 	// Activate selected plan
-	_, err = tx.Exec(ctx, `UPDATE user_plans SET status = 'active' WHERE id = $1 AND user_id = $2`, planID, userID)
+	_, err = tx.Exec(ctx, `UPDATE user_plans SET status = 'active' WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, planID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to activate plan: %w", err)
 	}
@@ -224,6 +292,7 @@ func (r *PostgresPlanRepository) GetActivePlan(ctx context.Context, userID uuid.
 		SELECT id, user_id, name, description, status, source_type,
 		       source_file_id, excel_sheet_name, config,
 		       total_income_minor, total_expenses_minor, currency_code,
+		       period_type, period_start, period_end, household_id, version,
 		       created_at, updated_at
 		FROM user_plans
 		WHERE user_id = $1 AND status = 'active'
@@ -234,6 +303,7 @@ func (r *PostgresPlanRepository) GetActivePlan(ctx context.Context, userID uuid.
 		&plan.ID, &plan.UserID, &plan.Name, &plan.Description, &plan.Status, &plan.SourceType,
 		&plan.SourceFileID, &plan.ExcelSheetName, &plan.Config,
 		&plan.TotalIncomeMinor, &plan.TotalExpensesMinor, &plan.CurrencyCode,
+		&plan.PeriodType, &plan.PeriodStart, &plan.PeriodEnd, &plan.HouseholdID, &plan.Version,
 		&plan.CreatedAt, &plan.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -257,12 +327,12 @@ func (r *PostgresPlanRepository) CreateCategoryGroup(ctx context.Context, group
 	}
 
 	query := `
-		INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels, is_flex)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
-		group.ID, group.PlanID, group.Name, group.Color, group.TargetPercent, group.SortOrder, group.Labels,
+		group.ID, group.PlanID, group.Name, group.Color, group.TargetPercent, group.SortOrder, group.Labels, group.IsFlex,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create category group: %w", err)
@@ -274,7 +344,7 @@ func (r *PostgresPlanRepository) CreateCategoryGroup(ctx context.Context, group
 // GetCategoryGroupsByPlan retrieves all category groups for a plan
 func (r *PostgresPlanRepository) GetCategoryGroupsByPlan(ctx context.Context, planID uuid.UUID) ([]*PlanCategoryGroup, error) {
 	query := `
-		SELECT id, plan_id, name, color, target_percent, sort_order, labels, created_at
+		SELECT id, plan_id, name, color, target_percent, sort_order, labels, is_flex, created_at
 		FROM plan_category_groups
 		WHERE plan_id = $1
 		ORDER BY sort_order
@@ -289,7 +359,7 @@ func (r *PostgresPlanRepository) GetCategoryGroupsByPlan(ctx context.Context, pl
 	var groups []*PlanCategoryGroup
 	for rows.Next() {
 		var g PlanCategoryGroup
-		if err := rows.Scan(&g.ID, &g.PlanID, &g.Name, &g.Color, &g.TargetPercent, &g.SortOrder, &g.Labels, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.PlanID, &g.Name, &g.Color, &g.TargetPercent, &g.SortOrder, &g.Labels, &g.IsFlex, &g.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan category group: %w", err)
 		}
 		groups = append(groups, &g)
@@ -412,7 +482,8 @@ func (r *PostgresPlanRepository) GetItemsByPlan(ctx context.Context, planID uuid
 	query := `
 		SELECT id, plan_id, category_id, name, budgeted_minor, actual_minor,
 		       excel_cell, formula, widget_type, field_type, sort_order,
-		       min_value, max_value, labels, item_type, config_id, created_at, updated_at
+		       min_value, max_value, labels, item_type, config_id, subscription_id, goal_id,
+		       rollover_enabled, created_at, updated_at
 		FROM plan_items
 		WHERE plan_id = $1
 		ORDER BY sort_order
@@ -430,7 +501,8 @@ func (r *PostgresPlanRepository) GetItemsByPlan(ctx context.Context, planID uuid
 		if err := rows.Scan(
 			&i.ID, &i.PlanID, &i.CategoryID, &i.Name, &i.BudgetedMinor, &i.ActualMinor,
 			&i.ExcelCell, &i.Formula, &i.WidgetType, &i.FieldType, &i.SortOrder,
-			&i.MinValue, &i.MaxValue, &i.Labels, &i.ItemType, &i.ConfigID, &i.CreatedAt, &i.UpdatedAt,
+			&i.MinValue, &i.MaxValue, &i.Labels, &i.ItemType, &i.ConfigID, &i.SubscriptionID, &i.GoalID,
+			&i.RolloverEnabled, &i.CreatedAt, &i.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan item: %w", err)
 		}
@@ -479,46 +551,72 @@ func (r *PostgresPlanRepository) UpdateItem(ctx context.Context, item *PlanItem)
 		UPDATE plan_items SET
 			name = $2, budgeted_minor = $3, actual_minor = $4,
 			widget_type = $5, field_type = $6, labels = $7, item_type = $8, config_id = $9
-		WHERE id = $1
+		WHERE id = $1 AND plan_id = $10
 	`
 
-	_, err := r.pool.Exec(ctx, query,
+	tag, err := r.pool.Exec(ctx, query,
 		item.ID, item.Name, item.BudgetedMinor, item.ActualMinor,
 		item.WidgetType, item.FieldType, item.Labels, item.ItemType, item.ConfigID,
+		item.PlanID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update item: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
 
 	return nil
 }
 
 // UpdateItemBudget updates just the budgeted amount for an item
-func (r *PostgresPlanRepository) UpdateItemBudget(ctx context.Context, itemID uuid.UUID, budgetedMinor int64) error {
-	query := `UPDATE plan_items SET budgeted_minor = $2 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, itemID, budgetedMinor)
+func (r *PostgresPlanRepository) UpdateItemBudget(ctx context.Context, planID, itemID uuid.UUID, budgetedMinor int64) error {
+	query := `UPDATE plan_items SET budgeted_minor = $3 WHERE id = $2 AND plan_id = $1`
+	tag, err := r.pool.Exec(ctx, query, planID, itemID, budgetedMinor)
 	if err != nil {
 		return fmt.Errorf("failed to update item budget: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
-// UpdatePlanItemActual updates just the actual amount for an item (from transaction sync)
-func (r *PostgresPlanRepository) UpdatePlanItemActual(ctx context.Context, itemID uuid.UUID, actualMinor int64) error {
-	query := `UPDATE plan_items SET actual_minor = $2, updated_at = NOW() WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, itemID, actualMinor)
+// SetItemRollover toggles whether an item's unspent budget carries into the next period
+func (r *PostgresPlanRepository) SetItemRollover(ctx context.Context, planID, itemID uuid.UUID, enabled bool) error {
+	query := `UPDATE plan_items SET rollover_enabled = $3, updated_at = NOW() WHERE id = $2 AND plan_id = $1`
+	tag, err := r.pool.Exec(ctx, query, planID, itemID, enabled)
 	if err != nil {
-		return fmt.Errorf("failed to update item actual: %w", err)
+		return fmt.Errorf("failed to update item rollover: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetGroupFlex toggles whether a category group is evaluated as one shared pool
+func (r *PostgresPlanRepository) SetGroupFlex(ctx context.Context, planID, groupID uuid.UUID, enabled bool) error {
+	query := `UPDATE plan_category_groups SET is_flex = $3 WHERE id = $2 AND plan_id = $1`
+	tag, err := r.pool.Exec(ctx, query, planID, groupID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update group flex: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
 	}
 	return nil
 }
 
-// IncrementPlanItemActual atomically increments the actual amount for a plan item
-func (r *PostgresPlanRepository) IncrementPlanItemActual(ctx context.Context, itemID uuid.UUID, amountMinor int64) error {
-	query := `UPDATE plan_items SET actual_minor = actual_minor + $2, updated_at = NOW() WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, itemID, amountMinor)
+// UpdatePlanItemActual updates just the actual amount for an item (from transaction sync)
+func (r *PostgresPlanRepository) UpdatePlanItemActual(ctx context.Context, planID, itemID uuid.UUID, actualMinor int64) error {
+	query := `UPDATE plan_items SET actual_minor = $3, updated_at = NOW() WHERE id = $2 AND plan_id = $1`
+	tag, err := r.pool.Exec(ctx, query, planID, itemID, actualMinor)
 	if err != nil {
-		return fmt.Errorf("failed to increment item actual: %w", err)
+		return fmt.Errorf("failed to update item actual: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
 	}
 	return nil
 }
@@ -576,11 +674,13 @@ func (r *PostgresPlanRepository) CreatePlanWithStructure(ctx context.Context, pl
 	_, err = tx.Exec(ctx, `
 		INSERT INTO user_plans (
 			id, user_id, name, description, status, source_type,
-			source_file_id, excel_sheet_name, config, currency_code
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			source_file_id, excel_sheet_name, config, currency_code,
+			period_type, period_start, period_end
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE(NULLIF($11, ''), 'monthly'), $12, $13)
 	`,
 		plan.ID, plan.UserID, plan.Name, plan.Description, plan.Status, plan.SourceType,
 		plan.SourceFileID, plan.ExcelSheetName, plan.Config, plan.CurrencyCode,
+		string(plan.PeriodType), plan.PeriodStart, plan.PeriodEnd,
 	)
 	if err != nil {
 		// Check if it's a foreign key violation for user_id
@@ -597,9 +697,9 @@ func (r *PostgresPlanRepository) CreatePlanWithStructure(ctx context.Context, pl
 		}
 		g.PlanID = plan.ID
 		_, err = tx.Exec(ctx, `
-			INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-		`, g.ID, g.PlanID, g.Name, g.Color, g.TargetPercent, g.SortOrder, g.Labels)
+			INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels, is_flex)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, g.ID, g.PlanID, g.Name, g.Color, g.TargetPercent, g.SortOrder, g.Labels, g.IsFlex)
 		if err != nil {
 			return fmt.Errorf("failed to create category group: %w", err)
 		}
@@ -645,14 +745,32 @@ func (r *PostgresPlanRepository) CreatePlanWithStructure(ctx context.Context, pl
 	return tx.Commit(ctx)
 }
 
-// UpdatePlanStructure updates the entire structure of a plan
-func (r *PostgresPlanRepository) UpdatePlanStructure(ctx context.Context, planID uuid.UUID, groups []*PlanCategoryGroup, categories []*PlanCategory, items []*PlanItem) error {
+// UpdatePlanStructure updates the entire structure of a plan and bumps its
+// version. With an expected version, it fails with a VersionConflictError if
+// the plan has moved on.
+func (r *PostgresPlanRepository) UpdatePlanStructure(ctx context.Context, planID uuid.UUID, expectedVersion *int64, groups []*PlanCategoryGroup, categories []*PlanCategory, items []*PlanItem) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	// Locks the plan, so concurrent updates expecting the same version conflict
+	var version int64
+	err = tx.QueryRow(ctx, `
+		UPDATE user_plans SET version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND ($2::BIGINT IS NULL OR version = $2)
+		RETURNING version`, planID, expectedVersion).Scan(&version)
+	if err == pgx.ErrNoRows {
+		if err := tx.QueryRow(ctx, `SELECT version FROM user_plans WHERE id = $1`, planID).Scan(&version); err != nil {
+			return fmt.Errorf("failed to get plan version: %w", err)
+		}
+		return &VersionConflictError{Current: version}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to bump plan version: %w", err)
+	}
+
 	// verify plan ownership/existence? Handled by service layer usually, but good to be safe by respecting planID in queries
 
 	// 1. Groups
@@ -693,9 +811,9 @@ func (r *PostgresPlanRepository) UpdatePlanStructure(ctx context.Context, planID
 		} else {
 			// INSERT new (whether ID was nil or pre-generated by frontend)
 			_, err := tx.Exec(ctx, `
-				INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels)
-				VALUES ($1, $2, $3, $4, $5, $6, $7)
-			`, g.ID, g.PlanID, g.Name, g.Color, g.TargetPercent, g.SortOrder, g.Labels)
+				INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels, is_flex)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, g.ID, g.PlanID, g.Name, g.Color, g.TargetPercent, g.SortOrder, g.Labels, g.IsFlex)
 			if err != nil {
 				return fmt.Errorf("failed to insert group: %w", err)
 			}
@@ -900,8 +1018,10 @@ func (r *PostgresPlanRepository) DuplicatePlan(ctx context.Context, sourcePlanID
 
 	// Copy plan
 	_, err = tx.Exec(ctx, `
-		INSERT INTO user_plans (id, user_id, name, description, status, source_type, config, currency_code)
-		SELECT $1, user_id, $2, description, 'draft', source_type, config, currency_code
+		INSERT INTO user_plans (id, user_id, name, description, status, source_type, config, currency_code,
+			period_type, period_start, period_end)
+		SELECT $1, user_id, $2, description, 'draft', source_type, config, currency_code,
+			period_type, period_start, period_end
 		FROM user_plans WHERE id = $3 AND user_id = $4
 	`, newPlanID, newName, sourcePlanID, userID)
 	if err != nil {
@@ -921,8 +1041,8 @@ func (r *PostgresPlanRepository) DuplicatePlan(ctx context.Context, sourcePlanID
 		groupMapping[oldID] = newID
 
 		_, err = tx.Exec(ctx, `
-			INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels)
-			SELECT $1, $2, name, color, target_percent, sort_order, labels
+			INSERT INTO plan_category_groups (id, plan_id, name, color, target_percent, sort_order, labels, is_flex)
+			SELECT $1, $2, name, color, target_percent, sort_order, labels, is_flex
 			FROM plan_category_groups WHERE id = $3
 		`, newID, newPlanID, oldID)
 		if err != nil {
@@ -1038,17 +1158,17 @@ func (r *PostgresPlanRepository) ListItemConfigs(ctx context.Context, userID uui
 	return configs, nil
 }
 
-// GetItemConfigByID retrieves a config by ID
-func (r *PostgresPlanRepository) GetItemConfigByID(ctx context.Context, configID uuid.UUID) (*ItemConfig, error) {
+// GetItemConfigByID retrieves one of the user's configs by ID
+func (r *PostgresPlanRepository) GetItemConfigByID(ctx context.Context, userID, configID uuid.UUID) (*ItemConfig, error) {
 	query := `
 		SELECT id, user_id, label, short_code, behavior, target_tab,
 		       color_hex, icon, is_system, sort_order, created_at, updated_at
 		FROM plan_item_configs
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $2
 	`
 
 	var c ItemConfig
-	err := r.pool.QueryRow(ctx, query, configID).Scan(
+	err := r.pool.QueryRow(ctx, query, configID, userID).Scan(
 		&c.ID, &c.UserID, &c.Label, &c.ShortCode, &c.Behavior, &c.TargetTab,
 		&c.ColorHex, &c.Icon, &c.IsSystem, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt,
 	)
@@ -1092,35 +1212,35 @@ func (r *PostgresPlanRepository) UpdateItemConfig(ctx context.Context, config *I
 		UPDATE plan_item_configs SET
 			label = $2, short_code = $3, behavior = $4, target_tab = $5,
 			color_hex = $6, icon = $7, sort_order = $8, updated_at = NOW()
-		WHERE id = $1 AND is_system = false
+		WHERE id = $1 AND user_id = $9 AND is_system = false
 	`
 
 	result, err := r.pool.Exec(ctx, query,
 		config.ID, config.Label, config.ShortCode, config.Behavior, config.TargetTab,
-		config.ColorHex, config.Icon, config.SortOrder,
+		config.ColorHex, config.Icon, config.SortOrder, config.UserID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update item config: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("item config not found or is a system config")
+		return fmt.Errorf("item config not found or is a system config: %w", sql.ErrNoRows)
 	}
 
 	return nil
 }
 
-// DeleteItemConfig deletes an item config (only non-system configs)
-func (r *PostgresPlanRepository) DeleteItemConfig(ctx context.Context, configID uuid.UUID) error {
-	query := `DELETE FROM plan_item_configs WHERE id = $1 AND is_system = false`
+// DeleteItemConfig deletes one of the user's item configs (only non-system configs)
+func (r *PostgresPlanRepository) DeleteItemConfig(ctx context.Context, userID, configID uuid.UUID) error {
+	query := `DELETE FROM plan_item_configs WHERE id = $1 AND user_id = $2 AND is_system = false`
 
-	result, err := r.pool.Exec(ctx, query, configID)
+	result, err := r.pool.Exec(ctx, query, configID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete item config: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("item config not found or is a system config")
+		return fmt.Errorf("item config not found or is a system config: %w", sql.ErrNoRows)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +27,15 @@ const (
 	PlanStatusArchived PlanStatus = "archived"
 )
 
+// PlanPeriodType defines how a plan's budget periods recur
+type PlanPeriodType string
+
+const (
+	PlanPeriodMonthly  PlanPeriodType = "monthly"  // Months starting on PeriodStart's day (calendar months when unset)
+	PlanPeriodBiweekly PlanPeriodType = "biweekly" // 14-day periods anchored on PeriodStart
+	PlanPeriodCustom   PlanPeriodType = "custom"   // A single range from PeriodStart to PeriodEnd (exclusive)
+)
+
 // WidgetType represents the UI widget type for a plan item
 type WidgetType string
 
@@ -82,6 +92,11 @@ type UserPlan struct {
 	TotalIncomeMinor   int64          `db:"total_income_minor"`
 	TotalExpensesMinor int64          `db:"total_expenses_minor"`
 	CurrencyCode       string         `db:"currency_code"`
+	PeriodType         PlanPeriodType `db:"period_type"`
+	PeriodStart        *time.Time     `db:"period_start"`
+	PeriodEnd          *time.Time     `db:"period_end"`
+	HouseholdID        *uuid.UUID     `db:"household_id"` // Shared with this household's members
+	Version            int64          `db:"version"`      // Bumped by every structure update
 	CreatedAt          time.Time      `db:"created_at"`
 	UpdatedAt          time.Time      `db:"updated_at"`
 }
@@ -94,7 +109,8 @@ type PlanCategoryGroup struct {
 	Color         *string   `db:"color"`
 	TargetPercent float64   `db:"target_percent"`
 	SortOrder     int       `db:"sort_order"`
-	Labels        []byte    `db:"labels"` // JSONB
+	Labels        []byte    `db:"labels"`  // JSONB
+	IsFlex        bool      `db:"is_flex"` // Items share one pool; overspend is fine while the group total holds
 	CreatedAt     time.Time `db:"created_at"`
 }
 
@@ -123,24 +139,27 @@ type PlanCategory struct {
 
 // PlanItem represents a single budget line item
 type PlanItem struct {
-	ID            uuid.UUID  `db:"id"`
-	PlanID        uuid.UUID  `db:"plan_id"`
-	CategoryID    *uuid.UUID `db:"category_id"`
-	Name          string     `db:"name"`
-	BudgetedMinor int64      `db:"budgeted_minor"`
-	ActualMinor   int64      `db:"actual_minor"`
-	ExcelCell     *string    `db:"excel_cell"`
-	Formula       *string    `db:"formula"`
-	WidgetType    WidgetType `db:"widget_type"`
-	FieldType     FieldType  `db:"field_type"`
-	SortOrder     int        `db:"sort_order"`
-	MinValue      *int64     `db:"min_value"`
-	MaxValue      *int64     `db:"max_value"`
-	Labels        []byte     `db:"labels"`    // JSONB
-	ItemType      ItemType   `db:"item_type"` // Legacy/Simple typing
-	ConfigID      *uuid.UUID `db:"config_id"` // Link to dynamic item config
-	CreatedAt     time.Time  `db:"created_at"`
-	UpdatedAt     time.Time  `db:"updated_at"`
+	ID              uuid.UUID  `db:"id"`
+	PlanID          uuid.UUID  `db:"plan_id"`
+	CategoryID      *uuid.UUID `db:"category_id"`
+	Name            string     `db:"name"`
+	BudgetedMinor   int64      `db:"budgeted_minor"`
+	ActualMinor     int64      `db:"actual_minor"`
+	ExcelCell       *string    `db:"excel_cell"`
+	Formula         *string    `db:"formula"`
+	WidgetType      WidgetType `db:"widget_type"`
+	FieldType       FieldType  `db:"field_type"`
+	SortOrder       int        `db:"sort_order"`
+	MinValue        *int64     `db:"min_value"`
+	MaxValue        *int64     `db:"max_value"`
+	Labels          []byte     `db:"labels"`           // JSONB
+	ItemType        ItemType   `db:"item_type"`        // Legacy/Simple typing
+	ConfigID        *uuid.UUID `db:"config_id"`        // Link to dynamic item config
+	SubscriptionID  *uuid.UUID `db:"subscription_id"`  // Budget follows a recurring subscription
+	GoalID          *uuid.UUID `db:"goal_id"`          // Actual follows goal contributions
+	RolloverEnabled bool       `db:"rollover_enabled"` // Unspent budget carries into the next period
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
 }
 
 // ItemConfig represents a user-configurable item type
@@ -160,23 +179,39 @@ type ItemConfig struct {
 }
 
 // PlanRepository defines the interface for plan data access
+// VersionConflictError is returned when a plan changed since the version an
+// update was based on
+type VersionConflictError struct {
+	Current int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("plan was changed by another update and is now at version %d", e.Current)
+}
+
 type PlanRepository interface {
 	// Plans
 	CreatePlan(ctx context.Context, plan *UserPlan) error
 	GetPlanByID(ctx context.Context, planID uuid.UUID) (*UserPlan, error)
 	ListPlansByUser(ctx context.Context, userID uuid.UUID, status *PlanStatus, limit, offset int) ([]*UserPlan, int, error)
 	ListAllActivePlans(ctx context.Context, limit, offset int) ([]*UserPlan, error) // For cron jobs
-	UpdatePlan(ctx context.Context, plan *UserPlan) error
-	DeletePlan(ctx context.Context, planID uuid.UUID) error
+	// UpdatePlan and DeletePlan bump the plan's version, failing with a
+	// VersionConflictError unless it's expectedVersion
+	UpdatePlan(ctx context.Context, plan *UserPlan, expectedVersion *int64) error
+	DeletePlan(ctx context.Context, planID uuid.UUID, expectedVersion *int64) error
 	SetActivePlan(ctx context.Context, userID, planID uuid.UUID) error
 	GetActivePlan(ctx context.Context, userID uuid.UUID) (*UserPlan, error)
+	UpdatePlanPeriod(ctx context.Context, planID uuid.UUID, periodType PlanPeriodType, start, end *time.Time) error
+	SetPlanHousehold(ctx context.Context, planID uuid.UUID, householdID *uuid.UUID) error
 
-	// UpdatePlanStructure updates the entire structure of a plan
-	UpdatePlanStructure(ctx context.Context, planID uuid.UUID, groups []*PlanCategoryGroup, categories []*PlanCategory, items []*PlanItem) error
+	// UpdatePlanStructure updates the entire structure of a plan and bumps its
+	// version, failing with a VersionConflictError unless it's expectedVersion
+	UpdatePlanStructure(ctx context.Context, planID uuid.UUID, expectedVersion *int64, groups []*PlanCategoryGroup, categories []*PlanCategory, items []*PlanItem) error
 
 	// Category Groups
 	CreateCategoryGroup(ctx context.Context, group *PlanCategoryGroup) error
 	GetCategoryGroupsByPlan(ctx context.Context, planID uuid.UUID) ([]*PlanCategoryGroup, error)
+	SetGroupFlex(ctx context.Context, planID, groupID uuid.UUID, enabled bool) error
 
 	// Categories
 	CreateCategory(ctx context.Context, category *PlanCategory) error
@@ -187,25 +222,33 @@ type PlanRepository interface {
 	CreateItem(ctx context.Context, item *PlanItem) error
 	GetItemsByPlan(ctx context.Context, planID uuid.UUID) ([]*PlanItem, error)
 	GetItemsByCategory(ctx context.Context, categoryID uuid.UUID) ([]*PlanItem, error)
+	// Item writes are scoped to the plan and return sql.ErrNoRows for an item
+	// of another plan
 	UpdateItem(ctx context.Context, item *PlanItem) error
-	UpdateItemBudget(ctx context.Context, itemID uuid.UUID, budgetedMinor int64) error
-	UpdatePlanItemActual(ctx context.Context, itemID uuid.UUID, actualMinor int64) error
-	IncrementPlanItemActual(ctx context.Context, itemID uuid.UUID, amountMinor int64) error
+	UpdateItemBudget(ctx context.Context, planID, itemID uuid.UUID, budgetedMinor int64) error
+	UpdatePlanItemActual(ctx context.Context, planID, itemID uuid.UUID, actualMinor int64) error
+	SetItemRollover(ctx context.Context, planID, itemID uuid.UUID, enabled bool) error
 	FindItemByCategoryAndType(ctx context.Context, planID uuid.UUID, categoryID uuid.UUID, itemTypes []ItemType) (*uuid.UUID, error)
+	GetItemBudgetsAsOf(ctx context.Context, planID uuid.UUID, asOf time.Time) (map[uuid.UUID]int64, error)
 
 	// Bulk operations
 	CreatePlanWithStructure(ctx context.Context, plan *UserPlan, groups []*PlanCategoryGroup, categories []*PlanCategory, items []*PlanItem) error
 	DuplicatePlan(ctx context.Context, sourcePlanID uuid.UUID, newName string, userID uuid.UUID) (*UserPlan, error)
 
-	// Item Configs (dynamic type configurations)
+	// Item Configs (dynamic type configurations), scoped to the owning user
 	ListItemConfigs(ctx context.Context, userID uuid.UUID) ([]*ItemConfig, error)
-	GetItemConfigByID(ctx context.Context, configID uuid.UUID) (*ItemConfig, error)
+	GetItemConfigByID(ctx context.Context, userID, configID uuid.UUID) (*ItemConfig, error)
 	CreateItemConfig(ctx context.Context, config *ItemConfig) error
 	UpdateItemConfig(ctx context.Context, config *ItemConfig) error
-	DeleteItemConfig(ctx context.Context, configID uuid.UUID) error
+	DeleteItemConfig(ctx context.Context, userID, configID uuid.UUID) error
 
 	// Filtered queries
 	GetItemsByTabWithTotals(ctx context.Context, planID uuid.UUID, targetTab TargetTab) ([]PlanItemWithConfig, int64, int64, error)
+
+	// Item links (subscriptions and goals)
+	SetItemLink(ctx context.Context, planID, itemID uuid.UUID, subscriptionID, goalID *uuid.UUID) error
+	SyncSubscriptionLinkedItems(ctx context.Context) (int, error)
+	GetGoalContributionTotals(ctx context.Context, goalIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]int64, error)
 }
 
 // CreatePlanInput is used for creating a new plan with its full structure
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RevisionReason describes what produced a plan revision
+type RevisionReason string
+
+const (
+	RevisionReasonBaseline        RevisionReason = "baseline" // Structure before the first recorded change
+	RevisionReasonStructureUpdate RevisionReason = "structure_update"
+	RevisionReasonRestore         RevisionReason = "restore"
+)
+
+// PlanRevision is an immutable snapshot of a plan's structure after a change
+type PlanRevision struct {
+	ID             uuid.UUID
+	PlanID         uuid.UUID
+	RevisionNumber int
+	AuthorID       *uuid.UUID
+	Reason         RevisionReason
+	RestoredFromID *uuid.UUID
+	Snapshot       json.RawMessage
+	CreatedAt      time.Time
+}
+
+// PlanRevisionRepository defines the interface for plan revision history
+type PlanRevisionRepository interface {
+	// CreateRevision appends a revision, assigning the next revision number
+	CreateRevision(ctx context.Context, rev *PlanRevision) error
+	// GetRevision returns sql.ErrNoRows if the revision doesn't belong to the plan
+	GetRevision(ctx context.Context, planID, revisionID uuid.UUID) (*PlanRevision, error)
+	// ListRevisions lists a plan's revisions newest first, without snapshots
+	ListRevisions(ctx context.Context, planID uuid.UUID, limit, offset int) ([]*PlanRevision, int, error)
+	CountRevisions(ctx context.Context, planID uuid.UUID) (int, error)
+}
+
+// PostgresPlanRevisionRepository implements PlanRevisionRepository using PostgreSQL
+type PostgresPlanRevisionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPlanRevisionRepository creates a new PostgreSQL plan revision repository
+func NewPostgresPlanRevisionRepository(pool *pgxpool.Pool) *PostgresPlanRevisionRepository {
+	return &PostgresPlanRevisionRepository{pool: pool}
+}
+
+// CreateRevision appends a revision
+func (r *PostgresPlanRevisionRepository) CreateRevision(ctx context.Context, rev *PlanRevision) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO plan_revisions (plan_id, revision_number, author_id, reason, restored_from_id, snapshot)
+		SELECT $1, COALESCE(MAX(revision_number), 0) + 1, $2, $3, $4, $5
+		FROM plan_revisions
+		WHERE plan_id = $1
+		RETURNING id, revision_number, created_at`,
+		rev.PlanID, rev.AuthorID, rev.Reason, rev.RestoredFromID, rev.Snapshot,
+	).Scan(&rev.ID, &rev.RevisionNumber, &rev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create plan revision: %w", err)
+	}
+	return nil
+}
+
+// GetRevision gets a revision with its snapshot
+func (r *PostgresPlanRevisionRepository) GetRevision(ctx context.Context, planID, revisionID uuid.UUID) (*PlanRevision, error) {
+	rev := &PlanRevision{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, plan_id, revision_number, author_id, reason, restored_from_id, snapshot, created_at
+		FROM plan_revisions
+		WHERE id = $1 AND plan_id = $2`, revisionID, planID,
+	).Scan(&rev.ID, &rev.PlanID, &rev.RevisionNumber, &rev.AuthorID, &rev.Reason, &rev.RestoredFromID, &rev.Snapshot, &rev.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan revision: %w", err)
+	}
+	return rev, nil
+}
+
+// ListRevisions lists a plan's revisions newest first
+func (r *PostgresPlanRevisionRepository) ListRevisions(ctx context.Context, planID uuid.UUID, limit, offset int) ([]*PlanRevision, int, error) {
+	total, err := r.CountRevisions(ctx, planID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, plan_id, revision_number, author_id, reason, restored_from_id, created_at
+		FROM plan_revisions
+		WHERE plan_id = $1
+		ORDER BY revision_number DESC
+		LIMIT $2 OFFSET $3`, planID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list plan revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*PlanRevision
+	for rows.Next() {
+		rev := &PlanRevision{}
+		if err := rows.Scan(&rev.ID, &rev.PlanID, &rev.RevisionNumber, &rev.AuthorID, &rev.Reason, &rev.RestoredFromID, &rev.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan plan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, total, rows.Err()
+}
+
+// CountRevisions counts a plan's revisions
+func (r *PostgresPlanRevisionRepository) CountRevisions(ctx context.Context, planID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM plan_revisions WHERE plan_id = $1`, planID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count plan revisions: %w", err)
+	}
+	return count, nil
+}
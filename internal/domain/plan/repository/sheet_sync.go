@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SheetSyncDirection is which way a sync run moved data
+type SheetSyncDirection string
+
+const (
+	SheetSyncPush SheetSyncDirection = "push"
+	SheetSyncPull SheetSyncDirection = "pull"
+)
+
+// SheetSyncRunStatus is the outcome of a sync run
+type SheetSyncRunStatus string
+
+const (
+	SheetSyncSucceeded SheetSyncRunStatus = "succeeded"
+	SheetSyncFailed    SheetSyncRunStatus = "failed"
+)
+
+// SheetConnection holds a user's Google OAuth tokens for Sheets access
+type SheetConnection struct {
+	UserID       uuid.UUID
+	AccessToken  string
+	RefreshToken string
+	TokenType    string
+	ExpiresAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// SheetLink maps a plan to a tab in a Google spreadsheet
+type SheetLink struct {
+	ID            uuid.UUID
+	PlanID        uuid.UUID
+	UserID        uuid.UUID
+	SpreadsheetID string
+	SheetName     string
+	LastPushedAt  *time.Time
+	LastPulledAt  *time.Time
+	LastError     *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// SheetSyncLogEntry records one push or pull
+type SheetSyncLogEntry struct {
+	ID           uuid.UUID
+	LinkID       uuid.UUID
+	Direction    SheetSyncDirection
+	Status       SheetSyncRunStatus
+	ItemsUpdated int
+	Conflicts    int
+	Error        *string
+	CreatedAt    time.Time
+}
+
+// SheetSyncRepository defines the interface for Google Sheets sync persistence
+type SheetSyncRepository interface {
+	UpsertConnection(ctx context.Context, conn *SheetConnection) error
+	// GetConnection returns sql.ErrNoRows if the user hasn't connected Google
+	GetConnection(ctx context.Context, userID uuid.UUID) (*SheetConnection, error)
+
+	// UpsertLink creates or replaces the sheet mapped to a plan
+	UpsertLink(ctx context.Context, link *SheetLink) error
+	// GetLinkByPlan returns sql.ErrNoRows if the plan isn't linked
+	GetLinkByPlan(ctx context.Context, planID uuid.UUID) (*SheetLink, error)
+	ListLinks(ctx context.Context) ([]*SheetLink, error)
+	DeleteLink(ctx context.Context, planID uuid.UUID) error
+
+	// GetRowStates returns the budgeted values per item as of the last sync
+	GetRowStates(ctx context.Context, linkID uuid.UUID) (map[uuid.UUID]int64, error)
+	// ReplaceRowStates replaces the synced values for a link
+	ReplaceRowStates(ctx context.Context, linkID uuid.UUID, states map[uuid.UUID]int64) error
+
+	// RecordSync stamps the link and appends a log entry
+	RecordSync(ctx context.Context, entry *SheetSyncLogEntry) error
+	ListSyncLog(ctx context.Context, linkID uuid.UUID, limit int) ([]*SheetSyncLogEntry, error)
+}
+
+// PostgresSheetSyncRepository implements SheetSyncRepository using PostgreSQL
+type PostgresSheetSyncRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSheetSyncRepository creates a new PostgreSQL sheet sync repository
+func NewPostgresSheetSyncRepository(pool *pgxpool.Pool) *PostgresSheetSyncRepository {
+	return &PostgresSheetSyncRepository{pool: pool}
+}
+
+// UpsertConnection stores a user's tokens, keeping the old refresh token if none was issued
+func (r *PostgresSheetSyncRepository) UpsertConnection(ctx context.Context, conn *SheetConnection) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO google_sheets_connections (user_id, access_token, refresh_token, token_type, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			access_token = EXCLUDED.access_token,
+			refresh_token = COALESCE(NULLIF(EXCLUDED.refresh_token, ''), google_sheets_connections.refresh_token),
+			token_type = EXCLUDED.token_type,
+			expires_at = EXCLUDED.expires_at
+		RETURNING created_at, updated_at`,
+		conn.UserID, conn.AccessToken, conn.RefreshToken, conn.TokenType, conn.ExpiresAt,
+	).Scan(&conn.CreatedAt, &conn.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save google sheets connection: %w", err)
+	}
+	return nil
+}
+
+// GetConnection gets a user's Google tokens
+func (r *PostgresSheetSyncRepository) GetConnection(ctx context.Context, userID uuid.UUID) (*SheetConnection, error) {
+	conn := &SheetConnection{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id, access_token, refresh_token, token_type, expires_at, created_at, updated_at
+		FROM google_sheets_connections
+		WHERE user_id = $1`, userID,
+	).Scan(&conn.UserID, &conn.AccessToken, &conn.RefreshToken, &conn.TokenType, &conn.ExpiresAt, &conn.CreatedAt, &conn.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get google sheets connection: %w", err)
+	}
+	return conn, nil
+}
+
+const sheetLinkColumns = `id, plan_id, user_id, spreadsheet_id, sheet_name, last_pushed_at, last_pulled_at, last_error, created_at, updated_at`
+
+func scanSheetLink(row pgx.Row) (*SheetLink, error) {
+	link := &SheetLink{}
+	err := row.Scan(&link.ID, &link.PlanID, &link.UserID, &link.SpreadsheetID, &link.SheetName,
+		&link.LastPushedAt, &link.LastPulledAt, &link.LastError, &link.CreatedAt, &link.UpdatedAt)
+	return link, err
+}
+
+// UpsertLink maps a plan to a sheet. Pointing a plan at a different sheet resets
+// its sync state.
+func (r *PostgresSheetSyncRepository) UpsertLink(ctx context.Context, link *SheetLink) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	saved, err := scanSheetLink(tx.QueryRow(ctx, `
+		INSERT INTO plan_sheet_links (plan_id, user_id, spreadsheet_id, sheet_name)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (plan_id) DO UPDATE SET
+			spreadsheet_id = EXCLUDED.spreadsheet_id,
+			sheet_name = EXCLUDED.sheet_name,
+			last_pushed_at = NULL,
+			last_pulled_at = NULL,
+			last_error = NULL
+		RETURNING `+sheetLinkColumns,
+		link.PlanID, link.UserID, link.SpreadsheetID, link.SheetName))
+	if err != nil {
+		return fmt.Errorf("failed to save sheet link: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM plan_sheet_row_states WHERE link_id = $1`, saved.ID); err != nil {
+		return fmt.Errorf("failed to reset sheet row states: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit sheet link: %w", err)
+	}
+	*link = *saved
+	return nil
+}
+
+// GetLinkByPlan gets the sheet mapped to a plan
+func (r *PostgresSheetSyncRepository) GetLinkByPlan(ctx context.Context, planID uuid.UUID) (*SheetLink, error) {
+	link, err := scanSheetLink(r.pool.QueryRow(ctx, `
+		SELECT `+sheetLinkColumns+`
+		FROM plan_sheet_links
+		WHERE plan_id = $1`, planID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sheet link: %w", err)
+	}
+	return link, nil
+}
+
+// ListLinks lists all sheet links of non-archived plans
+func (r *PostgresSheetSyncRepository) ListLinks(ctx context.Context) ([]*SheetLink, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT l.id, l.plan_id, l.user_id, l.spreadsheet_id, l.sheet_name, l.last_pushed_at,
+		       l.last_pulled_at, l.last_error, l.created_at, l.updated_at
+		FROM plan_sheet_links l
+		JOIN user_plans p ON p.id = l.plan_id
+		WHERE p.status <> 'archived'
+		ORDER BY l.created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sheet links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*SheetLink
+	for rows.Next() {
+		link, err := scanSheetLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sheet link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// DeleteLink stops syncing a plan
+func (r *PostgresSheetSyncRepository) DeleteLink(ctx context.Context, planID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM plan_sheet_links WHERE plan_id = $1`, planID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sheet link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetRowStates gets the last synced budgeted values for a link
+func (r *PostgresSheetSyncRepository) GetRowStates(ctx context.Context, linkID uuid.UUID) (map[uuid.UUID]int64, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT item_id, budgeted_minor
+		FROM plan_sheet_row_states
+		WHERE link_id = $1`, linkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sheet row states: %w", err)
+	}
+	defer rows.Close()
+
+	states := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var itemID uuid.UUID
+		var budgeted int64
+		if err := rows.Scan(&itemID, &budgeted); err != nil {
+			return nil, fmt.Errorf("failed to scan sheet row state: %w", err)
+		}
+		states[itemID] = budgeted
+	}
+	return states, rows.Err()
+}
+
+// ReplaceRowStates replaces the synced values for a link in one transaction
+func (r *PostgresSheetSyncRepository) ReplaceRowStates(ctx context.Context, linkID uuid.UUID, states map[uuid.UUID]int64) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM plan_sheet_row_states WHERE link_id = $1`, linkID); err != nil {
+		return fmt.Errorf("failed to clear sheet row states: %w", err)
+	}
+	for itemID, budgeted := range states {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO plan_sheet_row_states (link_id, item_id, budgeted_minor)
+			VALUES ($1, $2, $3)`, linkID, itemID, budgeted); err != nil {
+			return fmt.Errorf("failed to save sheet row state: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit sheet row states: %w", err)
+	}
+	return nil
+}
+
+// RecordSync stamps the link with the run's outcome and appends it to the sync log
+func (r *PostgresSheetSyncRepository) RecordSync(ctx context.Context, entry *SheetSyncLogEntry) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO plan_sheet_sync_log (link_id, direction, status, items_updated, conflicts, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		entry.LinkID, entry.Direction, entry.Status, entry.ItemsUpdated, entry.Conflicts, entry.Error,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert sheet sync log: %w", err)
+	}
+
+	// Only successful runs move the sync watermark; failures just record the error
+	_, err = tx.Exec(ctx, `
+		UPDATE plan_sheet_links
+		SET last_pushed_at = CASE WHEN $2 = 'succeeded' AND $3 = 'push' THEN $4 ELSE last_pushed_at END,
+		    last_pulled_at = CASE WHEN $2 = 'succeeded' AND $3 = 'pull' THEN $4 ELSE last_pulled_at END,
+		    last_error = $5
+		WHERE id = $1`,
+		entry.LinkID, entry.Status, entry.Direction, entry.CreatedAt, entry.Error)
+	if err != nil {
+		return fmt.Errorf("failed to update sheet link: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit sheet sync log: %w", err)
+	}
+	return nil
+}
+
+// ListSyncLog lists a link's most recent sync runs, newest first
+func (r *PostgresSheetSyncRepository) ListSyncLog(ctx context.Context, linkID uuid.UUID, limit int) ([]*SheetSyncLogEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, link_id, direction, status, items_updated, conflicts, error, created_at
+		FROM plan_sheet_sync_log
+		WHERE link_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`, linkID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sheet sync log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*SheetSyncLogEntry
+	for rows.Next() {
+		e := &SheetSyncLogEntry{}
+		if err := rows.Scan(&e.ID, &e.LinkID, &e.Direction, &e.Status, &e.ItemsUpdated, &e.Conflicts, &e.Error, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan sheet sync log: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// =============================================================================
+// Plan Budgets As Of a Date (Internal Integration)
+// =============================================================================
+// Every budget value a plan item takes is recorded with the time it took effect,
+// so a plan can be read with the budgets it had on a past date. Adherence for a
+// past period then compares its spending with what was budgeted back then, not
+// with budgets edited since.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetPlanRequest.as_of (google.protobuf.Timestamp)
+
+// GetPlanAsOf retrieves a plan with its items budgeted as they were at asOf and
+// actuals for the budget period containing asOf. Items added after asOf are
+// left out. Plan totals are the current ones. Returns nil if the plan is not
+// visible to the user.
+func (s *PlanService) GetPlanAsOf(ctx context.Context, userID, planID uuid.UUID, asOf time.Time) (*PlanWithDetails, error) {
+	if !asOf.Before(time.Now()) {
+		return s.GetPlanForPeriod(ctx, userID, planID, asOf) // Nothing has changed after now
+	}
+
+	details, err := s.getPlanStructure(ctx, userID, planID)
+	if err != nil || details == nil {
+		return nil, err
+	}
+	budgets, err := s.repo.GetItemBudgetsAsOf(ctx, planID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical budgets: %w", err)
+	}
+	// Before matching actuals, so items added later don't take spending from the others
+	details.Items = itemsAsOf(details.Items, budgets)
+	details.AsOf = &asOf
+	return s.withPeriodActuals(ctx, details, asOf)
+}
+
+// itemsAsOf keeps the items that had a budget then, with that budget
+func itemsAsOf(items []*repository.PlanItem, budgets map[uuid.UUID]int64) []*repository.PlanItem {
+	kept := make([]*repository.PlanItem, 0, len(items))
+	for _, item := range items {
+		budgeted, ok := budgets[item.ID]
+		if !ok {
+			continue
+		}
+		item.BudgetedMinor = budgeted
+		kept = append(kept, item)
+	}
+	return kept
+}
@@ -3,42 +3,190 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrInvalidPeriod is returned when a year/month pair is out of range
+	ErrInvalidPeriod = errors.New("invalid budget period: year must be 2000-2100 and month 1-12")
+	// ErrPeriodLocked is returned when modifying a locked period
+	ErrPeriodLocked = errors.New("budget period is locked")
+)
+
 // BudgetPeriodService handles budget period business logic
 type BudgetPeriodService struct {
-	repo repository.BudgetPeriodRepository
+	repo     repository.BudgetPeriodRepository
+	planRepo repository.PlanRepository
 }
 
 // NewBudgetPeriodService creates a new budget period service
-func NewBudgetPeriodService(repo repository.BudgetPeriodRepository) *BudgetPeriodService {
-	return &BudgetPeriodService{repo: repo}
+func NewBudgetPeriodService(repo repository.BudgetPeriodRepository, planRepo repository.PlanRepository) *BudgetPeriodService {
+	return &BudgetPeriodService{repo: repo, planRepo: planRepo}
 }
 
-// GetOrCreatePeriod gets or creates a budget period for a specific month
-func (s *BudgetPeriodService) GetOrCreatePeriod(ctx context.Context, planID uuid.UUID, year, month int) (*repository.BudgetPeriodWithItems, bool, error) {
-	return s.repo.GetOrCreatePeriod(ctx, planID, year, month)
+// GetOrCreatePeriod gets or creates a budget period for a specific month.
+// New periods start from the plan's items, inherit budgeted values from the
+// previous month's period when one exists, and carry in the remainder of
+// rollover-enabled items. Returns nil if the plan is not owned by the user.
+func (s *BudgetPeriodService) GetOrCreatePeriod(ctx context.Context, userID, planID uuid.UUID, year, month int) (*repository.BudgetPeriodWithItems, bool, error) {
+	if !validPeriod(year, month) {
+		return nil, false, ErrInvalidPeriod
+	}
+	owned, err := s.ownsPlan(ctx, userID, planID)
+	if err != nil || !owned {
+		return nil, false, err
+	}
+	return s.openPeriod(ctx, planID, year, month, false)
 }
 
-// ListPeriods lists all periods for a plan
-func (s *BudgetPeriodService) ListPeriods(ctx context.Context, planID uuid.UUID) ([]*repository.BudgetPeriod, error) {
-	return s.repo.ListPeriods(ctx, planID)
+// RollOverPeriod opens a month's period at month start (used by the scheduler).
+// The previous period's actuals are first snapshotted from the plan items, which
+// still hold that month's totals until the next actuals sync, and the rollover
+// carry-in is recomputed even if the period was already opened earlier.
+func (s *BudgetPeriodService) RollOverPeriod(ctx context.Context, planID uuid.UUID, year, month int) (*repository.BudgetPeriodWithItems, bool, error) {
+	if !validPeriod(year, month) {
+		return nil, false, ErrInvalidPeriod
+	}
+	return s.openPeriod(ctx, planID, year, month, true)
 }
 
-// GetPeriodByID gets a period by ID
-func (s *BudgetPeriodService) GetPeriodByID(ctx context.Context, periodID uuid.UUID) (*repository.BudgetPeriodWithItems, error) {
-	return s.repo.GetPeriodByID(ctx, periodID)
+// openPeriod gets or creates a period and seeds it from the previous month
+func (s *BudgetPeriodService) openPeriod(ctx context.Context, planID uuid.UUID, year, month int, closePrevious bool) (*repository.BudgetPeriodWithItems, bool, error) {
+	prevYear, prevMonth := previousMonth(year, month)
+	prev, err := s.repo.FindPeriod(ctx, planID, prevYear, prevMonth)
+	if err != nil {
+		return nil, false, err
+	}
+	if prev != nil && closePrevious && !prev.IsLocked {
+		if err := s.repo.SnapshotPeriodActuals(ctx, prev.ID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	period, wasCreated, err := s.repo.GetOrCreatePeriod(ctx, planID, year, month)
+	if err != nil {
+		return nil, false, err
+	}
+	if prev == nil || (!wasCreated && !closePrevious) || period.Period.IsLocked {
+		return period, wasCreated, nil
+	}
+
+	if wasCreated {
+		if err := s.repo.CopyBudgetedValues(ctx, prev.ID, period.Period.ID); err != nil {
+			return nil, true, err
+		}
+	}
+	if _, err := s.repo.ApplyRollover(ctx, prev.ID, period.Period.ID); err != nil {
+		return nil, wasCreated, err
+	}
+
+	period, err = s.repo.GetPeriodByID(ctx, period.Period.ID)
+	return period, wasCreated, err
 }
 
-// UpdatePeriodItem updates an item's values for a period
-func (s *BudgetPeriodService) UpdatePeriodItem(ctx context.Context, periodItemID uuid.UUID, budgeted, actual *int64, notes *string) (*repository.BudgetPeriodItem, error) {
+// ListPeriods lists all periods for a plan. Returns nil if the plan is not owned by the user,
+// and an empty slice if the plan has no periods yet.
+func (s *BudgetPeriodService) ListPeriods(ctx context.Context, userID, planID uuid.UUID) ([]*repository.BudgetPeriod, error) {
+	owned, err := s.ownsPlan(ctx, userID, planID)
+	if err != nil || !owned {
+		return nil, err
+	}
+	periods, err := s.repo.ListPeriods(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if periods == nil {
+		periods = []*repository.BudgetPeriod{}
+	}
+	return periods, nil
+}
+
+// GetPeriodByID gets a period by ID. Returns nil if it doesn't exist or is not owned by the user.
+func (s *BudgetPeriodService) GetPeriodByID(ctx context.Context, userID, periodID uuid.UUID) (*repository.BudgetPeriodWithItems, error) {
+	period, err := s.repo.GetPeriodByID(ctx, periodID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := s.ownsPlan(ctx, userID, period.Period.PlanID)
+	if err != nil || !owned {
+		return nil, err
+	}
+	return period, nil
+}
+
+// UpdatePeriodItem updates an item's values for a period.
+// Returns nil if the item doesn't exist or is not owned by the user.
+func (s *BudgetPeriodService) UpdatePeriodItem(ctx context.Context, userID, periodItemID uuid.UUID, budgeted, actual *int64, notes *string) (*repository.BudgetPeriodItem, error) {
+	period, err := s.repo.GetPeriodForItem(ctx, periodItemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := s.ownsPlan(ctx, userID, period.PlanID)
+	if err != nil || !owned {
+		return nil, err
+	}
+	if period.IsLocked {
+		return nil, ErrPeriodLocked
+	}
+
 	return s.repo.UpdatePeriodItem(ctx, periodItemID, budgeted, actual, notes)
 }
 
-// CopyPeriodItems copies values from one period to a new target period
-func (s *BudgetPeriodService) CopyPeriodItems(ctx context.Context, sourcePeriodID uuid.UUID, targetPlanID uuid.UUID, targetYear, targetMonth int) (*repository.BudgetPeriodWithItems, error) {
+// CopyPeriodItems copies values from one period to a target period, creating it if needed.
+// Returns nil if either the source period or the target plan is not owned by the user.
+func (s *BudgetPeriodService) CopyPeriodItems(ctx context.Context, userID, sourcePeriodID, targetPlanID uuid.UUID, targetYear, targetMonth int) (*repository.BudgetPeriodWithItems, error) {
+	if !validPeriod(targetYear, targetMonth) {
+		return nil, ErrInvalidPeriod
+	}
+
+	source, err := s.GetPeriodByID(ctx, userID, sourcePeriodID)
+	if err != nil || source == nil {
+		return nil, err
+	}
+	owned, err := s.ownsPlan(ctx, userID, targetPlanID)
+	if err != nil || !owned {
+		return nil, err
+	}
+
+	target, err := s.repo.FindPeriod(ctx, targetPlanID, targetYear, targetMonth)
+	if err != nil {
+		return nil, err
+	}
+	if target != nil && target.IsLocked {
+		return nil, ErrPeriodLocked
+	}
+
 	return s.repo.CopyPeriodItems(ctx, sourcePeriodID, targetPlanID, targetYear, targetMonth)
 }
+
+// ownsPlan reports whether the plan exists and belongs to the user
+func (s *BudgetPeriodService) ownsPlan(ctx context.Context, userID, planID uuid.UUID) (bool, error) {
+	plan, err := s.planRepo.GetPlanByID(ctx, planID)
+	if err != nil {
+		return false, err
+	}
+	return plan != nil && plan.UserID == userID, nil
+}
+
+func validPeriod(year, month int) bool {
+	return year >= 2000 && year <= 2100 && month >= 1 && month <= 12
+}
+
+func previousMonth(year, month int) (int, int) {
+	if month == 1 {
+		return year - 1, 12
+	}
+	return year, month - 1
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// fakeBudgetPeriodRepository keeps periods in memory; items are keyed by plan item ID.
+type fakeBudgetPeriodRepository struct {
+	periods  map[uuid.UUID]*repository.BudgetPeriodWithItems
+	base     map[uuid.UUID]int64 // plan item budgets copied into new periods
+	actuals  map[uuid.UUID]int64 // plan item actuals used by snapshots
+	rollover map[uuid.UUID]bool  // plan items with rollover enabled
+}
+
+func newFakeBudgetPeriodRepository(base map[uuid.UUID]int64) *fakeBudgetPeriodRepository {
+	return &fakeBudgetPeriodRepository{
+		periods:  map[uuid.UUID]*repository.BudgetPeriodWithItems{},
+		base:     base,
+		actuals:  map[uuid.UUID]int64{},
+		rollover: map[uuid.UUID]bool{},
+	}
+}
+
+func (f *fakeBudgetPeriodRepository) add(planID uuid.UUID, year, month int, budgets map[uuid.UUID]int64) *repository.BudgetPeriodWithItems {
+	p := &repository.BudgetPeriodWithItems{Period: &repository.BudgetPeriod{ID: uuid.New(), PlanID: planID, Year: year, Month: month}}
+	for itemID, budgeted := range budgets {
+		p.Items = append(p.Items, &repository.BudgetPeriodItem{ID: uuid.New(), PeriodID: p.Period.ID, ItemID: itemID, BudgetedMinor: budgeted})
+	}
+	f.periods[p.Period.ID] = p
+	return p
+}
+
+func (f *fakeBudgetPeriodRepository) GetOrCreatePeriod(ctx context.Context, planID uuid.UUID, year, month int) (*repository.BudgetPeriodWithItems, bool, error) {
+	if p, _ := f.FindPeriod(ctx, planID, year, month); p != nil {
+		return f.periods[p.ID], false, nil
+	}
+	return f.add(planID, year, month, f.base), true, nil
+}
+
+func (f *fakeBudgetPeriodRepository) ListPeriods(ctx context.Context, planID uuid.UUID) ([]*repository.BudgetPeriod, error) {
+	var out []*repository.BudgetPeriod
+	for _, p := range f.periods {
+		if p.Period.PlanID == planID {
+			out = append(out, p.Period)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeBudgetPeriodRepository) GetPeriodByID(ctx context.Context, periodID uuid.UUID) (*repository.BudgetPeriodWithItems, error) {
+	p, ok := f.periods[periodID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+func (f *fakeBudgetPeriodRepository) UpdatePeriodItem(ctx context.Context, periodItemID uuid.UUID, budgeted, actual *int64, notes *string) (*repository.BudgetPeriodItem, error) {
+	for _, p := range f.periods {
+		for _, item := range p.Items {
+			if item.ID == periodItemID {
+				if budgeted != nil {
+					item.BudgetedMinor = *budgeted
+				}
+				return item, nil
+			}
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeBudgetPeriodRepository) CopyPeriodItems(ctx context.Context, sourcePeriodID uuid.UUID, targetPlanID uuid.UUID, targetYear, targetMonth int) (*repository.BudgetPeriodWithItems, error) {
+	target, _, _ := f.GetOrCreatePeriod(ctx, targetPlanID, targetYear, targetMonth)
+	if err := f.CopyBudgetedValues(ctx, sourcePeriodID, target.Period.ID); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+func (f *fakeBudgetPeriodRepository) FindPeriod(ctx context.Context, planID uuid.UUID, year, month int) (*repository.BudgetPeriod, error) {
+	for _, p := range f.periods {
+		if p.Period.PlanID == planID && p.Period.Year == year && p.Period.Month == month {
+			return p.Period, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeBudgetPeriodRepository) GetPeriodForItem(ctx context.Context, periodItemID uuid.UUID) (*repository.BudgetPeriod, error) {
+	for _, p := range f.periods {
+		for _, item := range p.Items {
+			if item.ID == periodItemID {
+				return p.Period, nil
+			}
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeBudgetPeriodRepository) CopyBudgetedValues(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) error {
+	src := map[uuid.UUID]int64{}
+	for _, item := range f.periods[sourcePeriodID].Items {
+		src[item.ItemID] = item.BudgetedMinor
+	}
+	for _, item := range f.periods[targetPeriodID].Items {
+		if v, ok := src[item.ItemID]; ok {
+			item.BudgetedMinor = v
+		}
+	}
+	return nil
+}
+
+func (f *fakeBudgetPeriodRepository) ApplyRollover(ctx context.Context, sourcePeriodID, targetPeriodID uuid.UUID) (int, error) {
+	remaining := map[uuid.UUID]int64{}
+	for _, item := range f.periods[sourcePeriodID].Items {
+		if f.rollover[item.ItemID] {
+			remaining[item.ItemID] = item.AvailableMinor()
+		}
+	}
+	applied := 0
+	for _, item := range f.periods[targetPeriodID].Items {
+		if v, ok := remaining[item.ItemID]; ok {
+			item.RolloverMinor = v
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+func (f *fakeBudgetPeriodRepository) SnapshotPeriodActuals(ctx context.Context, periodID uuid.UUID) error {
+	for _, item := range f.periods[periodID].Items {
+		item.ActualMinor = f.actuals[item.ItemID]
+	}
+	return nil
+}
+
+func budgetByItem(p *repository.BudgetPeriodWithItems) map[uuid.UUID]int64 {
+	out := map[uuid.UUID]int64{}
+	for _, item := range p.Items {
+		out[item.ItemID] = item.BudgetedMinor
+	}
+	return out
+}
+
+func TestBudgetPeriodService_GetOrCreatePeriodCopiesPreviousMonth(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.MustParse("92131338-3069-42b7-84bc-8c3866be237a")
+	planID := uuid.New()
+	rent, food := uuid.New(), uuid.New()
+
+	repo := newFakeBudgetPeriodRepository(map[uuid.UUID]int64{rent: 100000, food: 40000})
+	repo.add(planID, 2024, 12, map[uuid.UUID]int64{rent: 110000, food: 45000})
+	svc := NewBudgetPeriodService(repo, &fakePlanRepository{})
+
+	period, created, err := svc.GetOrCreatePeriod(ctx, userID, planID, 2025, 1)
+	require.NoError(t, err)
+	require.NotNil(t, period)
+	assert.True(t, created)
+	assert.Equal(t, map[uuid.UUID]int64{rent: 110000, food: 45000}, budgetByItem(period))
+
+	// Second call returns the same snapshot without re-copying
+	again, created, err := svc.GetOrCreatePeriod(ctx, userID, planID, 2025, 1)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, period.Period.ID, again.Period.ID)
+}
+
+func TestBudgetPeriodService_OwnershipAndValidation(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.MustParse("92131338-3069-42b7-84bc-8c3866be237a")
+	planID := uuid.New()
+
+	repo := newFakeBudgetPeriodRepository(map[uuid.UUID]int64{uuid.New(): 1000})
+	existing := repo.add(planID, 2025, 3, map[uuid.UUID]int64{uuid.New(): 500})
+	svc := NewBudgetPeriodService(repo, &fakePlanRepository{})
+
+	_, _, err := svc.GetOrCreatePeriod(ctx, owner, planID, 2025, 13)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	period, _, err := svc.GetOrCreatePeriod(ctx, uuid.New(), planID, 2025, 4)
+	require.NoError(t, err)
+	assert.Nil(t, period)
+
+	periods, err := svc.ListPeriods(ctx, uuid.New(), planID)
+	require.NoError(t, err)
+	assert.Nil(t, periods)
+
+	item, err := svc.UpdatePeriodItem(ctx, uuid.New(), existing.Items[0].ID, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	existing.Period.IsLocked = true
+	_, err = svc.UpdatePeriodItem(ctx, owner, existing.Items[0].ID, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrPeriodLocked)
+}
+
+func TestBudgetPeriodService_RollOverPeriodCarriesRemainder(t *testing.T) {
+	ctx := context.Background()
+	planID := uuid.New()
+	groceries, fun, rent := uuid.New(), uuid.New(), uuid.New()
+
+	repo := newFakeBudgetPeriodRepository(map[uuid.UUID]int64{groceries: 40000, fun: 10000, rent: 100000})
+	repo.add(planID, 2025, 5, map[uuid.UUID]int64{groceries: 40000, fun: 10000, rent: 100000})
+	repo.actuals = map[uuid.UUID]int64{groceries: 35000, fun: 12500, rent: 100000}
+	repo.rollover = map[uuid.UUID]bool{groceries: true, fun: true}
+	svc := NewBudgetPeriodService(repo, &fakePlanRepository{})
+
+	period, created, err := svc.RollOverPeriod(ctx, planID, 2025, 6)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	carried := map[uuid.UUID]int64{}
+	for _, item := range period.Items {
+		carried[item.ItemID] = item.RolloverMinor
+	}
+	assert.Equal(t, int64(5000), carried[groceries]) // underspent
+	assert.Equal(t, int64(-2500), carried[fun])      // overspent
+	assert.Equal(t, int64(0), carried[rent])         // not opted in
+}
+
+func TestBudgetPeriodService_ComputeAdherenceStreaks(t *testing.T) {
+	ctx := context.Background()
+	planID := uuid.New()
+	groceries, dining, salary := uuid.New(), uuid.New(), uuid.New()
+
+	repo := newFakeBudgetPeriodRepository(nil)
+	addPeriod := func(year, month int, groceriesActual, diningActual int64) {
+		p := &repository.BudgetPeriodWithItems{Period: &repository.BudgetPeriod{ID: uuid.New(), PlanID: planID, Year: year, Month: month}}
+		p.Items = []*repository.BudgetPeriodItem{
+			{ItemID: groceries, ItemName: "Groceries", CategoryName: "Food", ItemType: repository.ItemTypeBudget, BudgetedMinor: 40000, ActualMinor: groceriesActual},
+			{ItemID: dining, ItemName: "Dining out", CategoryName: "Food", ItemType: repository.ItemTypeBudget, BudgetedMinor: 10000, ActualMinor: diningActual},
+			{ItemID: salary, ItemName: "Salary", CategoryName: "Income", ItemType: repository.ItemTypeIncome, BudgetedMinor: 300000, ActualMinor: 320000},
+		}
+		repo.periods[p.Period.ID] = p
+	}
+	addPeriod(2025, 1, 38000, 9000)
+	addPeriod(2025, 2, 39000, 9500)
+	addPeriod(2025, 3, 40000, 10000)
+	addPeriod(2025, 4, 36000, 16500) // Dining overspends; the category total still exceeds its budget
+	addPeriod(2025, 5, 30000, 20000) // Still open, so not evaluated yet
+	svc := NewBudgetPeriodService(repo, &fakePlanRepository{})
+
+	streaks, err := svc.ComputeAdherenceStreaks(ctx, planID, time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 4, streaks.PeriodsEvaluated)
+	assert.Equal(t, 0, streaks.Overall.Current)
+	assert.Equal(t, 3, streaks.Overall.Longest)
+
+	require.NotNil(t, streaks.Overall.LastBreak)
+	assert.Equal(t, dining, streaks.Overall.LastBreak.ItemID)
+	assert.Equal(t, 3, streaks.Overall.LastBreak.PreviousStreak)
+	assert.Equal(t, int64(6500), streaks.Overall.LastBreak.OverMinor())
+
+	// Income items don't count towards streaks
+	require.Len(t, streaks.Categories, 1)
+	assert.Equal(t, "Food", streaks.Categories[0].CategoryName)
+
+	broken := streaks.JustBroken(2)
+	require.Len(t, broken, 2)
+	assert.Empty(t, broken[0].CategoryName)
+	assert.Empty(t, streaks.JustBroken(4))
+
+	// A month without a period restarts the streak without a break
+	addPeriod(2025, 6, 30000, 5000)
+	addPeriod(2025, 8, 30000, 5000)
+	streaks, err = svc.ComputeAdherenceStreaks(ctx, planID, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1, streaks.Overall.Current)
+	assert.Equal(t, 2025, streaks.Overall.LastBreak.Year)
+	assert.Equal(t, 4, streaks.Overall.LastBreak.Month)
+	assert.Empty(t, streaks.JustBroken(1))
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// =============================================================================
+// Budget vs. Actual Report (Internal Integration)
+// =============================================================================
+// Compares the active plan's expense items with their actuals for one budget
+// period, rolled up by plan category and group, with each line's variance,
+// share of budget used, and projected end-of-period spend at the current pace.
+// Rows are flat and ordered for export; WriteCSV writes them as a CSV sheet.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetBudgetReportRequest/Response (PlanService.GetBudgetReport) with an
+//   optional google.protobuf.Timestamp as_of picking the period
+// - BudgetReportRow mirroring BudgetReportRow, with a BudgetReportLevel enum
+
+// ErrNoActivePlan is returned when the user has no active plan to report on
+var ErrNoActivePlan = errors.New("no active plan")
+
+// BudgetReportLevel is what a report row totals
+type BudgetReportLevel string
+
+const (
+	BudgetReportItem     BudgetReportLevel = "item"
+	BudgetReportCategory BudgetReportLevel = "category"
+	BudgetReportGroup    BudgetReportLevel = "group"
+	BudgetReportTotal    BudgetReportLevel = "total"
+)
+
+// BudgetReport is a plan's budget vs. actual for one budget period
+type BudgetReport struct {
+	PlanID         uuid.UUID
+	PlanName       string
+	CurrencyCode   string
+	Period         PlanPeriod
+	ElapsedPercent float64 // Share of the period elapsed; 100 for past periods
+	// Rows lists each group, then its categories each followed by their
+	// items, and ends with the plan total
+	Rows []BudgetReportRow
+}
+
+// BudgetReportRow is one line of a budget report. Group, Category and Item
+// name the line's place in the plan; the ones below its level are empty.
+type BudgetReportRow struct {
+	Level    BudgetReportLevel
+	Group    string
+	Category string
+	Item     string
+	ItemID   *uuid.UUID // Set for item rows
+
+	BudgetedMinor int64
+	ActualMinor   int64
+	VarianceMinor int64   // Budgeted - actual; negative when overspent
+	PercentUsed   float64 // Actual as a percentage of budgeted; 0 when nothing is budgeted
+	// ProjectedMinor is the period's spend if the pace so far continues
+	ProjectedMinor int64
+	// ProjectedOverrunMinor is how far ProjectedMinor exceeds the budget, or 0
+	ProjectedOverrunMinor int64
+}
+
+// Total returns the report's plan total row
+func (r *BudgetReport) Total() BudgetReportRow {
+	return r.Rows[len(r.Rows)-1]
+}
+
+// GetBudgetReport reports the user's active plan against its actuals for the
+// budget period containing asOf. Past periods use the budgets they had then.
+func (s *PlanService) GetBudgetReport(ctx context.Context, userID uuid.UUID, asOf time.Time) (*BudgetReport, error) {
+	plan, err := s.repo.GetActivePlan(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active plan: %w", err)
+	}
+	if plan == nil {
+		return nil, ErrNoActivePlan
+	}
+
+	details, err := s.GetPlanAsOf(ctx, userID, plan.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, ErrNoActivePlan
+	}
+	return buildBudgetReport(details, asOf, time.Now()), nil
+}
+
+// budgetReportBucket accumulates the rows of one group or category
+type budgetReportBucket struct {
+	row      BudgetReportRow
+	children []string // Category names, in plan order, for groups
+	items    []BudgetReportRow
+}
+
+// buildBudgetReport lays out the expense items of details for the period
+// containing asOf, projecting spend by how much of the period has passed by now
+func buildBudgetReport(details *PlanWithDetails, asOf, now time.Time) *BudgetReport {
+	period := PeriodFor(details.Plan, asOf)
+	if details.Period != nil {
+		period = *details.Period
+	}
+	elapsed := 1.0
+	if period.Contains(now) {
+		elapsed = period.Elapsed(now)
+	}
+
+	report := &BudgetReport{
+		PlanID:         details.Plan.ID,
+		PlanName:       details.Plan.Name,
+		CurrencyCode:   details.Plan.CurrencyCode,
+		Period:         period,
+		ElapsedPercent: elapsed * 100,
+	}
+
+	const other = "Other" // For items outside any category or group
+	groupNames := make(map[uuid.UUID]string, len(details.Groups))
+	var groupOrder []string
+	for _, g := range details.Groups {
+		groupNames[g.ID] = g.Name
+		groupOrder = append(groupOrder, g.Name)
+	}
+	categories := make(map[uuid.UUID]*repository.PlanCategory, len(details.Categories))
+	for _, c := range details.Categories {
+		categories[c.ID] = c
+	}
+
+	groups := make(map[string]*budgetReportBucket)
+	cats := make(map[[2]string]*budgetReportBucket)
+	for _, item := range details.Items {
+		if item.ItemType != repository.ItemTypeBudget && item.ItemType != repository.ItemTypeRecurring {
+			continue
+		}
+
+		groupName, categoryName := other, other
+		if item.CategoryID != nil {
+			if c, ok := categories[*item.CategoryID]; ok {
+				categoryName = c.Name
+				if c.GroupID != nil {
+					if name, ok := groupNames[*c.GroupID]; ok {
+						groupName = name
+					}
+				}
+			}
+		}
+
+		group := groups[groupName]
+		if group == nil {
+			group = &budgetReportBucket{row: BudgetReportRow{Level: BudgetReportGroup, Group: groupName}}
+			groups[groupName] = group
+		}
+		key := [2]string{groupName, categoryName}
+		category := cats[key]
+		if category == nil {
+			category = &budgetReportBucket{row: BudgetReportRow{Level: BudgetReportCategory, Group: groupName, Category: categoryName}}
+			cats[key] = category
+			group.children = append(group.children, categoryName)
+		}
+
+		id := item.ID
+		row := BudgetReportRow{
+			Level:         BudgetReportItem,
+			Group:         groupName,
+			Category:      categoryName,
+			Item:          item.Name,
+			ItemID:        &id,
+			BudgetedMinor: item.BudgetedMinor,
+			ActualMinor:   item.ActualMinor,
+		}
+		category.items = append(category.items, row)
+		for _, r := range []*BudgetReportRow{&category.row, &group.row} {
+			r.BudgetedMinor += item.BudgetedMinor
+			r.ActualMinor += item.ActualMinor
+		}
+	}
+
+	total := BudgetReportRow{Level: BudgetReportTotal}
+	if _, ok := groups[other]; ok {
+		groupOrder = append(groupOrder, other)
+	}
+	for _, groupName := range groupOrder {
+		group, ok := groups[groupName]
+		if !ok {
+			continue
+		}
+		delete(groups, groupName) // Groups sharing a name are reported once
+		report.Rows = append(report.Rows, group.row)
+		for _, categoryName := range group.children {
+			category := cats[[2]string{groupName, categoryName}]
+			report.Rows = append(report.Rows, category.row)
+			report.Rows = append(report.Rows, category.items...)
+		}
+		total.BudgetedMinor += group.row.BudgetedMinor
+		total.ActualMinor += group.row.ActualMinor
+	}
+	report.Rows = append(report.Rows, total)
+
+	for i := range report.Rows {
+		completeReportRow(&report.Rows[i], elapsed)
+	}
+	return report
+}
+
+// completeReportRow sets the row's variance, percent used and projection
+func completeReportRow(row *BudgetReportRow, elapsed float64) {
+	row.VarianceMinor = row.BudgetedMinor - row.ActualMinor
+	if row.BudgetedMinor > 0 {
+		row.PercentUsed = float64(row.ActualMinor) / float64(row.BudgetedMinor) * 100
+	}
+	row.ProjectedMinor = row.ActualMinor
+	if elapsed > 0 && elapsed < 1 {
+		row.ProjectedMinor = int64(float64(row.ActualMinor) / elapsed)
+	}
+	row.ProjectedOverrunMinor = max(row.ProjectedMinor-row.BudgetedMinor, 0)
+}
+
+// WriteCSV writes the report's rows as CSV with a header row. Amounts are in
+// minor units of the plan's currency.
+func (r *BudgetReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"level", "group", "category", "item", "currency",
+		"budgeted_minor", "actual_minor", "variance_minor", "percent_used",
+		"projected_minor", "projected_overrun_minor",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		record := []string{
+			string(row.Level), row.Group, row.Category, row.Item, r.CurrencyCode,
+			strconv.FormatInt(row.BudgetedMinor, 10),
+			strconv.FormatInt(row.ActualMinor, 10),
+			strconv.FormatInt(row.VarianceMinor, 10),
+			strconv.FormatFloat(row.PercentUsed, 'f', 1, 64),
+			strconv.FormatInt(row.ProjectedMinor, 10),
+			strconv.FormatInt(row.ProjectedOverrunMinor, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -6,6 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/excel"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
@@ -196,6 +200,10 @@ func (s *PlanService) LoadUserCorrections(ctx context.Context, userID uuid.UUID)
 
 // ImportFromExcel imports a plan from an Excel file
 func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r io.Reader, sheetName string, config *ExcelImportConfig, planName string) (*ExcelImportResult, error) {
+	if err := s.checkPlanQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	parser, err := excel.NewParserFromReader(r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse Excel: %w", err)
@@ -219,6 +227,31 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 		return nil, fmt.Errorf("failed to extract categories: %w", err)
 	}
 
+	// A column or a sheet per month becomes a budget period per month
+	months, err := parser.DetectMonthColumns(sheetName, time.Now().Year())
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect month columns: %w", err)
+	}
+	if len(months) > 0 {
+		err = parser.FillMonthValues(sheetName, categories, months)
+	} else if monthSheets := parser.DetectMonthSheets(time.Now().Year()); len(monthSheets) > 0 {
+		err = parser.FillMonthValuesFromSheets(categories, monthSheets, config.CategoryColumn, config.ValueColumn, config.HeaderRow)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract monthly values: %w", err)
+	}
+
+	// Totals that don't add up usually mean a misread layout; import anyway but report them
+	mismatches := excel.ValidateTotals(categories)
+	for _, m := range mismatches {
+		s.logger.Warn("excel category total doesn't match its items",
+			slog.String("sheet", sheetName),
+			slog.String("category", m.Category),
+			slog.String("total_cell", m.TotalCell),
+			slog.Float64("sheet_total", m.SheetTotal),
+			slog.Float64("items_total", m.ItemsTotal))
+	}
+
 	// Build plan structure
 	planConfig, _ := json.Marshal(map[string]any{
 		"chart_type":       "horizontal_bar",
@@ -232,6 +265,7 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 		Name:           planName,
 		Status:         repository.PlanStatusDraft,
 		SourceType:     repository.PlanSourceExcel,
+		SourceFileID:   config.SourceFileID,
 		ExcelSheetName: &sheetName,
 		CurrencyCode:   "EUR",
 		Config:         planConfig,
@@ -251,6 +285,7 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 
 	categoriesImported := 0
 	itemsImported := 0
+	monthly := make(map[uuid.UUID][]excel.MonthlyValue)
 
 	for catIdx, cat := range categories {
 		catID := uuid.New()
@@ -266,7 +301,7 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 
 		for itemIdx, item := range cat.Items {
 			// Convert value to minor units (cents)
-			budgetedMinor := int64(item.Value * 100)
+			budgetedMinor := int64(math.Round(item.Value * 100))
 
 			var excelCell *string
 			var formula *string
@@ -291,6 +326,9 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 			}
 			items = append(items, planItem)
 			itemsImported++
+			if len(item.Months) > 0 {
+				monthly[planItem.ID] = item.Months
+			}
 		}
 	}
 
@@ -299,6 +337,8 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 		return nil, fmt.Errorf("failed to save plan: %w", err)
 	}
 
+	periodsImported := s.importMonthlyBudgets(ctx, plan.ID, monthly)
+
 	// Reload plan to get computed totals
 	savedPlan, err := s.repo.GetPlanByID(ctx, plan.ID)
 	if err != nil {
@@ -309,5 +349,70 @@ func (s *PlanService) ImportFromExcel(ctx context.Context, userID uuid.UUID, r i
 		Plan:               savedPlan,
 		CategoriesImported: categoriesImported,
 		ItemsImported:      itemsImported,
+		TotalMismatches:    mismatches,
+		PeriodsImported:    periodsImported,
 	}, nil
 }
+
+// importMonthlyBudgets creates a budget period for each imported month and sets
+// its items' budgets to that month's values. The plan is already saved, so a
+// failure stops at the months done so far instead of failing the import.
+func (s *PlanService) importMonthlyBudgets(ctx context.Context, planID uuid.UUID, monthly map[uuid.UUID][]excel.MonthlyValue) int {
+	if s.periods == nil || len(monthly) == 0 {
+		return 0
+	}
+
+	type yearMonth struct{ year, month int }
+	budgets := make(map[yearMonth]map[uuid.UUID]int64)
+	for itemID, values := range monthly {
+		for _, v := range values {
+			key := yearMonth{v.Year, v.Month}
+			if budgets[key] == nil {
+				budgets[key] = make(map[uuid.UUID]int64)
+			}
+			budgets[key][itemID] = int64(math.Round(v.Value * 100))
+		}
+	}
+	keys := make([]yearMonth, 0, len(budgets))
+	for k := range budgets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].year != keys[j].year {
+			return keys[i].year < keys[j].year
+		}
+		return keys[i].month < keys[j].month
+	})
+
+	imported := 0
+	for _, k := range keys {
+		period, _, err := s.periods.GetOrCreatePeriod(ctx, planID, k.year, k.month)
+		if err == nil {
+			err = s.setPeriodBudgets(ctx, period, budgets[k])
+		}
+		if err != nil {
+			s.logger.Warn("failed to import monthly budgets from excel",
+				slog.String("plan_id", planID.String()),
+				slog.Int("year", k.year),
+				slog.Int("month", k.month),
+				slog.Any("error", err))
+			break
+		}
+		imported++
+	}
+	return imported
+}
+
+// setPeriodBudgets updates the period items whose budget differs from the month's
+func (s *PlanService) setPeriodBudgets(ctx context.Context, period *repository.BudgetPeriodWithItems, budgets map[uuid.UUID]int64) error {
+	for _, item := range period.Items {
+		budgeted, ok := budgets[item.ItemID]
+		if !ok || budgeted == item.BudgetedMinor {
+			continue
+		}
+		if _, err := s.periods.UpdatePeriodItem(ctx, item.ID, &budgeted, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/google/uuid"
+)
+
+// budgetPaceThreshold is the pace (percent of expected spend so far) above
+// which a budget line is flagged, matching the insights pace notification.
+const budgetPaceThreshold = 120.0
+
+// ErrGroupNotFound is returned when a category group doesn't exist in the plan
+var ErrGroupNotFound = errors.New("category group not found")
+
+// BudgetLineStatus is the evaluation of a budget line for the current budget period
+type BudgetLineStatus string
+
+const (
+	BudgetLineOnTrack    BudgetLineStatus = "on_track"
+	BudgetLineOverPace   BudgetLineStatus = "over_pace"
+	BudgetLineOverBudget BudgetLineStatus = "over_budget"
+)
+
+// BudgetLine is the unit budgets are evaluated at: a single item, or a whole
+// flex group whose items share one pool.
+type BudgetLine struct {
+	Name          string
+	ItemID        *uuid.UUID // Set for item lines
+	GroupID       *uuid.UUID // Set for flex group lines
+	ItemIDs       []uuid.UUID
+	BudgetedMinor int64
+	ActualMinor   int64
+	PacePercent   float64 // Actual vs. expected spend by this point in the period; 100 = on track
+	Status        BudgetLineStatus
+}
+
+// RemainingMinor is what's left of the line's budget (negative when overspent)
+func (l *BudgetLine) RemainingMinor() int64 {
+	return l.BudgetedMinor - l.ActualMinor
+}
+
+// PlanSummary evaluates a plan's spending against its budget lines
+type PlanSummary struct {
+	PlanID         uuid.UUID
+	CurrencyCode   string
+	AsOf           time.Time
+	ElapsedPercent float64 // Share of the budget period elapsed
+	BudgetedMinor  int64
+	ActualMinor    int64
+	Lines          []*BudgetLine
+	OverBudget     int
+	OverPace       int
+}
+
+// OverBudgetLines returns the lines that have exceeded their budget
+func (s *PlanSummary) OverBudgetLines() []*BudgetLine {
+	var lines []*BudgetLine
+	for _, l := range s.Lines {
+		if l.Status == BudgetLineOverBudget {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// SetGroupFlex marks a category group as a flexible pool (or back to per-item budgets)
+func (s *PlanService) SetGroupFlex(ctx context.Context, userID, planID, groupID uuid.UUID, enabled bool) error {
+	plan, err := s.getEditablePlan(ctx, userID, planID)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return ErrGroupNotFound
+	}
+
+	err = s.repo.SetGroupFlex(ctx, planID, groupID, enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGroupNotFound
+	}
+	return err
+}
+
+// GetPlanSummary evaluates the plan's expense items for the budget period containing asOf.
+// Items in flex groups are evaluated together, so one item's overspend doesn't
+// count against the plan while the group total holds.
+func (s *PlanService) GetPlanSummary(ctx context.Context, userID, planID uuid.UUID, asOf time.Time) (*PlanSummary, error) {
+	details, err := s.GetPlanForPeriod(ctx, userID, planID, asOf)
+	if err != nil || details == nil {
+		return nil, err
+	}
+	return summarizePlan(details, asOf), nil
+}
+
+// summarizePlan builds budget lines from plan details
+func summarizePlan(details *PlanWithDetails, asOf time.Time) *PlanSummary {
+	period := PeriodFor(details.Plan, asOf)
+	if details.Period != nil {
+		period = *details.Period
+	}
+	elapsed := period.Elapsed(asOf)
+
+	summary := &PlanSummary{
+		PlanID:         details.Plan.ID,
+		CurrencyCode:   details.Plan.CurrencyCode,
+		AsOf:           asOf,
+		ElapsedPercent: elapsed * 100,
+	}
+
+	flexGroups := make(map[uuid.UUID]*repository.PlanCategoryGroup)
+	for _, g := range details.Groups {
+		if g.IsFlex {
+			flexGroups[g.ID] = g
+		}
+	}
+	categoryGroup := make(map[uuid.UUID]uuid.UUID)
+	for _, c := range details.Categories {
+		if c.GroupID != nil {
+			if _, ok := flexGroups[*c.GroupID]; ok {
+				categoryGroup[c.ID] = *c.GroupID
+			}
+		}
+	}
+
+	groupLines := make(map[uuid.UUID]*BudgetLine)
+	for _, item := range details.Items {
+		if item.ItemType != repository.ItemTypeBudget && item.ItemType != repository.ItemTypeRecurring {
+			continue
+		}
+
+		var line *BudgetLine
+		if item.CategoryID != nil {
+			if groupID, ok := categoryGroup[*item.CategoryID]; ok {
+				line = groupLines[groupID]
+				if line == nil {
+					id := groupID
+					line = &BudgetLine{Name: flexGroups[groupID].Name, GroupID: &id}
+					groupLines[groupID] = line
+					summary.Lines = append(summary.Lines, line)
+				}
+			}
+		}
+		if line == nil {
+			id := item.ID
+			line = &BudgetLine{Name: item.Name, ItemID: &id}
+			summary.Lines = append(summary.Lines, line)
+		}
+
+		line.ItemIDs = append(line.ItemIDs, item.ID)
+		line.BudgetedMinor += item.BudgetedMinor
+		line.ActualMinor += item.ActualMinor
+	}
+
+	for _, line := range summary.Lines {
+		evaluateLine(line, elapsed)
+		summary.BudgetedMinor += line.BudgetedMinor
+		summary.ActualMinor += line.ActualMinor
+		switch line.Status {
+		case BudgetLineOverBudget:
+			summary.OverBudget++
+		case BudgetLineOverPace:
+			summary.OverPace++
+		}
+	}
+	return summary
+}
+
+// evaluateLine sets a line's pace and status
+func evaluateLine(line *BudgetLine, elapsed float64) {
+	line.Status = BudgetLineOnTrack
+	if line.BudgetedMinor <= 0 {
+		if line.ActualMinor > 0 {
+			line.Status = BudgetLineOverBudget
+		}
+		return
+	}
+
+	if expected := float64(line.BudgetedMinor) * elapsed; expected > 0 {
+		line.PacePercent = float64(line.ActualMinor) / expected * 100
+	}
+	switch {
+	case line.ActualMinor > line.BudgetedMinor:
+		line.Status = BudgetLineOverBudget
+	case line.PacePercent >= budgetPaceThreshold:
+		line.Status = BudgetLineOverPace
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// =============================================================================
+// Household Plan Sharing (Internal Integration)
+// =============================================================================
+// A plan shared with the owner's household is listed for every member. Editors
+// can change it like the owner; viewers only see it. Its actuals combine the
+// spending of all members.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - SharePlanRequest/Response (PlanService.SharePlan)
+// - UserPlan.household_id, UserPlan.access_role
+
+// HouseholdRole is a member's role in the household a plan is shared with
+type HouseholdRole string
+
+const (
+	HouseholdRoleOwner  HouseholdRole = "owner"
+	HouseholdRoleEditor HouseholdRole = "editor"
+	HouseholdRoleViewer HouseholdRole = "viewer"
+)
+
+var (
+	// ErrPlanReadOnly is returned when a viewer tries to change a shared plan
+	ErrPlanReadOnly = errors.New("plan is shared with you as a viewer")
+	// ErrNotPlanOwner is returned when someone other than the owner deletes or shares a plan
+	ErrNotPlanOwner = errors.New("only the plan's owner can do this")
+	// ErrNotHouseholdMember is returned when sharing a plan with a household the owner isn't in
+	ErrNotHouseholdMember = errors.New("plans can only be shared with your own household")
+)
+
+// HouseholdResolver resolves household membership for shared plans
+type HouseholdResolver interface {
+	// MemberRole returns the user's role in the household, or "" if they aren't a member
+	MemberRole(ctx context.Context, householdID, userID uuid.UUID) (HouseholdRole, error)
+	// MemberIDs lists the household's members
+	MemberIDs(ctx context.Context, householdID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// WithHouseholds lets members of a household see and edit the plans shared with it
+func (s *PlanService) WithHouseholds(households HouseholdResolver) *PlanService {
+	s.households = households
+	return s
+}
+
+// SharePlan shares the owner's plan with a household they belong to, or stops
+// sharing it when householdID is nil
+func (s *PlanService) SharePlan(ctx context.Context, userID, planID uuid.UUID, householdID *uuid.UUID) (*repository.UserPlan, error) {
+	plan, err := s.getOwnedPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	if householdID != nil {
+		if s.households == nil {
+			return nil, ErrNotHouseholdMember
+		}
+		role, err := s.households.MemberRole(ctx, *householdID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role == "" {
+			return nil, ErrNotHouseholdMember
+		}
+	}
+
+	if err := s.repo.SetPlanHousehold(ctx, planID, householdID); err != nil {
+		return nil, err
+	}
+	plan.HouseholdID = householdID
+	return plan, nil
+}
+
+// planRole is the user's access to a plan: owner for their own plans, their
+// household role for shared ones, or "" without access
+func (s *PlanService) planRole(ctx context.Context, userID uuid.UUID, plan *repository.UserPlan) (HouseholdRole, error) {
+	if plan.UserID == userID {
+		return HouseholdRoleOwner, nil
+	}
+	if plan.HouseholdID == nil || s.households == nil {
+		return "", nil
+	}
+	role, err := s.households.MemberRole(ctx, *plan.HouseholdID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == HouseholdRoleOwner {
+		// The household's owner edits shared plans but doesn't own them
+		role = HouseholdRoleEditor
+	}
+	return role, nil
+}
+
+// getEditablePlan retrieves a plan the user may change: their own or one shared
+// with them as editor. Returns nil without access and ErrPlanReadOnly for viewers.
+func (s *PlanService) getEditablePlan(ctx context.Context, userID, planID uuid.UUID) (*repository.UserPlan, error) {
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	role, err := s.planRole(ctx, userID, plan)
+	if err != nil {
+		return nil, err
+	}
+	if role == HouseholdRoleViewer {
+		return nil, ErrPlanReadOnly
+	}
+	return plan, nil
+}
+
+// getOwnedPlan retrieves a plan only its owner may change. Returns nil without
+// access and ErrNotPlanOwner for household members.
+func (s *PlanService) getOwnedPlan(ctx context.Context, userID, planID uuid.UUID) (*repository.UserPlan, error) {
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	if plan.UserID != userID {
+		return nil, ErrNotPlanOwner
+	}
+	return plan, nil
+}
+
+// planCategoryTotals returns the period's spending by category for the plan:
+// the owner's, plus every member's when the plan is shared with a household.
+// Members' categories with the same name are combined by item matching.
+func (s *PlanService) planCategoryTotals(ctx context.Context, plan *repository.UserPlan, start, end time.Time) ([]importrepo.CategoryTotal, error) {
+	userIDs := []uuid.UUID{plan.UserID}
+	if plan.HouseholdID != nil && s.households != nil {
+		members, err := s.households.MemberIDs(ctx, *plan.HouseholdID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range members {
+			if id != plan.UserID {
+				userIDs = append(userIDs, id)
+			}
+		}
+	}
+
+	var totals []importrepo.CategoryTotal
+	for _, id := range userIDs {
+		memberTotals, err := s.importRepo.GetCategoryTotals(ctx, id, start, end)
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, memberTotals...)
+	}
+	return totals, nil
+}
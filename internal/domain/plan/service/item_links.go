@@ -0,0 +1,139 @@
+// Package service provides plan item links to subscriptions and goals
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidItemLink is returned when an item is linked to both a subscription and a goal
+	ErrInvalidItemLink = errors.New("a plan item can link to a subscription or a goal, not both")
+	// ErrLinkTargetNotFound is returned when the item or the linked entity doesn't exist for the user
+	ErrLinkTargetNotFound = errors.New("plan item or link target not found")
+)
+
+// ItemLink identifies the entity a plan item follows. Both nil means unlinked.
+type ItemLink struct {
+	SubscriptionID *uuid.UUID
+	GoalID         *uuid.UUID
+}
+
+// LinkPlanItem links a plan item to a subscription or goal (or clears the link).
+// Subscription links keep budgeted_minor equal to the subscription's monthly cost;
+// goal links count the goal's contributions as the item's actual.
+func (s *PlanService) LinkPlanItem(ctx context.Context, userID, planID, itemID uuid.UUID, link ItemLink) (*repository.PlanItem, error) {
+	if link.SubscriptionID != nil && link.GoalID != nil {
+		return nil, ErrInvalidItemLink
+	}
+
+	plan, err := s.getEditablePlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	err = s.repo.SetItemLink(ctx, planID, itemID, link.SubscriptionID, link.GoalID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLinkTargetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItemsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ID == itemID {
+			if link.GoalID != nil {
+				s.refreshGoalLinkedActuals(ctx, []*repository.PlanItem{item}, currentMonth())
+			}
+			return item, nil
+		}
+	}
+	return nil, ErrLinkTargetNotFound
+}
+
+// ListLinkedItems returns the plan's items that follow a subscription or goal
+func (s *PlanService) ListLinkedItems(ctx context.Context, userID, planID uuid.UUID) ([]*repository.PlanItem, error) {
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetItemsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make([]*repository.PlanItem, 0)
+	for _, item := range items {
+		if item.SubscriptionID != nil || item.GoalID != nil {
+			linked = append(linked, item)
+		}
+	}
+	return linked, nil
+}
+
+// SyncLinkedItems reconciles subscription-linked budgets across all plans.
+// Goal-linked actuals are recomputed per plan by ComputePlanActuals.
+func (s *PlanService) SyncLinkedItems(ctx context.Context) (int, error) {
+	return s.repo.SyncSubscriptionLinkedItems(ctx)
+}
+
+// goalLinkedActuals sums each goal-linked item's contributions within [start, end)
+func (s *PlanService) goalLinkedActuals(ctx context.Context, items []*repository.PlanItem, start, end time.Time) (map[uuid.UUID]int64, error) {
+	var goalIDs []uuid.UUID
+	for _, item := range items {
+		if item.GoalID != nil {
+			goalIDs = append(goalIDs, *item.GoalID)
+		}
+	}
+	totals, err := s.repo.GetGoalContributionTotals(ctx, goalIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	actuals := make(map[uuid.UUID]int64, len(goalIDs))
+	for _, item := range items {
+		if item.GoalID != nil {
+			actuals[item.ID] = totals[*item.GoalID]
+		}
+	}
+	return actuals, nil
+}
+
+// refreshGoalLinkedActuals persists the given month's contribution totals on goal-linked items
+func (s *PlanService) refreshGoalLinkedActuals(ctx context.Context, items []*repository.PlanItem, start time.Time) {
+	actuals, err := s.goalLinkedActuals(ctx, items, start, start.AddDate(0, 1, 0))
+	if err != nil {
+		s.logger.Warn("failed to compute goal linked actuals", slog.Any("error", err))
+		return
+	}
+	for _, item := range items {
+		actual, ok := actuals[item.ID]
+		if !ok {
+			continue
+		}
+		if err := s.repo.UpdatePlanItemActual(ctx, item.PlanID, item.ID, actual); err != nil {
+			s.logger.Warn("failed to update goal linked actual",
+				slog.String("item_id", item.ID.String()),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		item.ActualMinor = actual
+	}
+}
+
+func currentMonth() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+var (
+	// ErrItemMappingsDisabled is returned when no item mapping repository is configured
+	ErrItemMappingsDisabled = errors.New("plan item mappings are not enabled")
+	// ErrMappingTargetNotFound is returned when the item or a mapped category doesn't exist for the user
+	ErrMappingTargetNotFound = errors.New("plan item or category not found")
+)
+
+// SetItemMappings replaces the transaction categories and merchants mapped to a
+// plan item. Mapped items take their actuals from these instead of name matching;
+// empty lists clear the mappings so the item falls back to name matching.
+func (s *PlanService) SetItemMappings(ctx context.Context, userID, planID, itemID uuid.UUID, categoryIDs []uuid.UUID, merchants []string) ([]*repository.ItemMapping, error) {
+	if s.mappings == nil {
+		return nil, ErrItemMappingsDisabled
+	}
+	plan, err := s.getEditablePlan(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, ErrMappingTargetNotFound
+	}
+
+	mappings, err := s.mappings.SetItemMappings(ctx, planID, itemID, categoryIDs, normalizeMerchants(merchants))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMappingTargetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// ListItemMappings lists the category and merchant mappings of a plan's items
+func (s *PlanService) ListItemMappings(ctx context.Context, userID, planID uuid.UUID) ([]*repository.ItemMapping, error) {
+	if s.mappings == nil {
+		return nil, ErrItemMappingsDisabled
+	}
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	return s.mappings.ListMappingsByPlan(ctx, planID)
+}
+
+// mappedActuals computes the actual of every item with explicit mappings: the sum
+// of its mapped categories' totals plus spending at its mapped merchants. Credit
+// items sum inflows instead, negated. Items without mappings are absent from the result.
+func (s *PlanService) mappedActuals(ctx context.Context, userID, planID uuid.UUID, categoryTotals []importrepo.CategoryTotal, credits map[uuid.UUID]bool, start, end time.Time) (map[uuid.UUID]int64, error) {
+	actuals := make(map[uuid.UUID]int64)
+	if s.mappings == nil {
+		return actuals, nil
+	}
+
+	mappings, err := s.mappings.ListMappingsByPlan(ctx, planID)
+	if err != nil || len(mappings) == 0 {
+		return actuals, err
+	}
+
+	byCategory := make(map[uuid.UUID]int64, len(categoryTotals))
+	inflowsByCategory := make(map[uuid.UUID]int64, len(categoryTotals))
+	for _, ct := range categoryTotals {
+		if ct.CategoryID != nil {
+			byCategory[*ct.CategoryID] += ct.TotalMinor
+			inflowsByCategory[*ct.CategoryID] += ct.InflowMinor
+		}
+	}
+
+	hasMerchants := false
+	for _, m := range mappings {
+		// Every mapped item gets an entry, so one with no spending is set to zero
+		var total int64
+		if m.CategoryID != nil && credits[m.PlanItemID] {
+			total = -inflowsByCategory[*m.CategoryID]
+		} else if m.CategoryID != nil {
+			total = byCategory[*m.CategoryID]
+		} else {
+			hasMerchants = true
+		}
+		actuals[m.PlanItemID] += total
+	}
+
+	if hasMerchants {
+		merchantTotals, err := s.mappings.GetMerchantMappedTotals(ctx, planID, userID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for itemID, total := range merchantTotals {
+			actuals[itemID] += total.TotalMinor
+		}
+	}
+	return actuals, nil
+}
+
+// normalizeMerchants trims merchant patterns and drops blanks and case-insensitive duplicates
+func normalizeMerchants(merchants []string) []string {
+	seen := make(map[string]bool, len(merchants))
+	result := make([]string, 0, len(merchants))
+	for _, m := range merchants {
+		m = strings.TrimSpace(m)
+		key := strings.ToLower(m)
+		if m == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, m)
+	}
+	return result
+}
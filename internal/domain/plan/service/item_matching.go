@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/categorization"
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+const (
+	// fuzzyMatchThreshold is the confidence (0-100) a keyword or fuzzy match needs
+	// to count towards an item's actual
+	fuzzyMatchThreshold = 80
+	// nearMissThreshold is the confidence above which a rejected match is still
+	// suggested as a mapping for the unmatched item
+	nearMissThreshold = 50
+)
+
+// MatchType is how a plan item's actual was found
+type MatchType string
+
+const (
+	MatchTypeGoal    MatchType = "goal"    // Savings goal contributions
+	MatchTypeMapping MatchType = "mapping" // Explicit category/merchant mappings
+	MatchTypeExact   MatchType = "exact"   // Category with the item's name
+	MatchTypeKeyword MatchType = "keyword" // Category name found in the item's name
+	MatchTypeFuzzy   MatchType = "fuzzy"   // Similarly spelled category
+)
+
+// Unmatched item reasons
+const (
+	UnmatchedReasonNoMapping = "no_category_mapping"
+	UnmatchedReasonNearMiss  = "low_confidence_match"
+)
+
+// ItemMatch describes how a plan item's actual was computed
+type ItemMatch struct {
+	ItemID       uuid.UUID
+	MatchType    MatchType
+	CategoryID   *uuid.UUID // Set for exact, keyword and fuzzy matches
+	CategoryName string
+	Confidence   int // 0-100; goal, mapping and exact matches are 100
+	ActualMinor  int64
+}
+
+// matchItems resolves each item's actual for the period: goal-linked items use
+// contributions, mapped items their mappings, and the rest are matched to
+// categoryTotals by name. Credit items are matched to their categories' inflows
+// and get a negative actual. Items that can't be matched are returned as unmatched.
+func (s *PlanService) matchItems(ctx context.Context, userID, planID uuid.UUID, items []*repository.PlanItem, categoryTotals []importrepo.CategoryTotal, start, end time.Time) (map[uuid.UUID]ItemMatch, []UnmatchedItem, error) {
+	// Goal-linked items take their actual from contributions, not transactions
+	goalActuals, err := s.goalLinkedActuals(ctx, items, start, end)
+	if err != nil {
+		s.logger.Error("failed to get goal contribution totals", slog.Any("error", err))
+		return nil, nil, err
+	}
+
+	credits := make(map[uuid.UUID]bool)
+	for _, item := range items {
+		if isCreditItem(item) {
+			credits[item.ID] = true
+		}
+	}
+
+	mapped, err := s.mappedActuals(ctx, userID, planID, categoryTotals, credits, start, end)
+	if err != nil {
+		s.logger.Error("failed to get mapped item totals", slog.Any("error", err))
+		return nil, nil, err
+	}
+
+	// First pass: goal-linked, mapped and exactly named items, so their
+	// categories aren't also claimed by a looser match
+	debitMatcher := newCategoryMatcher(categoryTotals, false)
+	creditMatcher := newCategoryMatcher(categoryTotals, true)
+	matcherFor := func(item *repository.PlanItem) *categoryMatcher {
+		if credits[item.ID] {
+			return creditMatcher
+		}
+		return debitMatcher
+	}
+
+	matches := make(map[uuid.UUID]ItemMatch, len(items))
+	var unmatchedItems []UnmatchedItem
+	for _, item := range items {
+		matcher := matcherFor(item)
+		if total, ok := goalActuals[item.ID]; ok {
+			matches[item.ID] = ItemMatch{ItemID: item.ID, MatchType: MatchTypeGoal, Confidence: 100, ActualMinor: total}
+		} else if total, ok := mapped[item.ID]; ok {
+			matches[item.ID] = ItemMatch{ItemID: item.ID, MatchType: MatchTypeMapping, Confidence: 100, ActualMinor: total}
+		} else if c := matcher.exact(item.Name); c != nil {
+			matches[item.ID] = c.itemMatch(item.ID)
+		}
+	}
+
+	// Second pass: keyword and fuzzy matching through the categorization engine
+	for _, item := range items {
+		if _, ok := matches[item.ID]; ok {
+			continue
+		}
+		c, matched := matcherFor(item).loose(item.Name)
+		if matched {
+			matches[item.ID] = c.itemMatch(item.ID)
+			continue
+		}
+
+		unmatched := UnmatchedItem{
+			ItemID:   item.ID,
+			ItemName: item.Name,
+			Reason:   UnmatchedReasonNoMapping,
+		}
+		if c != nil {
+			unmatched.Reason = UnmatchedReasonNearMiss
+			unmatched.SuggestedCategoryID = c.categoryID
+			unmatched.SuggestedCategoryName = c.categoryName
+			unmatched.Confidence = c.confidence
+		}
+		unmatchedItems = append(unmatchedItems, unmatched)
+	}
+	return matches, unmatchedItems, nil
+}
+
+// isCreditItem reports whether an item expects money back rather than spending,
+// e.g. an expected reimbursement in an expense group. Its negative budget nets
+// against the group's other items.
+func isCreditItem(item *repository.PlanItem) bool {
+	return item.BudgetedMinor < 0 && item.ItemType != repository.ItemTypeIncome
+}
+
+// categoryMatch is a transaction category matched to an item name
+type categoryMatch struct {
+	categoryID   *uuid.UUID
+	categoryName string
+	totalMinor   int64
+	matchType    MatchType
+	confidence   int
+}
+
+func (c *categoryMatch) itemMatch(itemID uuid.UUID) ItemMatch {
+	return ItemMatch{
+		ItemID:       itemID,
+		MatchType:    c.matchType,
+		CategoryID:   c.categoryID,
+		CategoryName: c.categoryName,
+		Confidence:   c.confidence,
+		ActualMinor:  c.totalMinor,
+	}
+}
+
+// categoryMatcher matches plan item names to the period's transaction categories.
+// Exact names are tried first, then the categorization engine: Aho-Corasick for
+// category names contained in the item name, and fuzzy matching for variations
+// and typos ("Grocery" vs "Groceries").
+type categoryMatcher struct {
+	totals  map[string]*categoryMatch // Keyed by uppercased category name
+	engine  *categorization.Engine
+	fuzzy   *categorization.FuzzyMatcher
+	claimed map[string]bool
+}
+
+// newCategoryMatcher indexes the period's categories for matching. A credit
+// matcher only sees categories with inflows, and its totals are the
+// negated inflows; a debit matcher sees those with spending. Zero-filled
+// categories, with neither, are seen by both.
+func newCategoryMatcher(categoryTotals []importrepo.CategoryTotal, credit bool) *categoryMatcher {
+	m := &categoryMatcher{
+		totals:  make(map[string]*categoryMatch, len(categoryTotals)),
+		claimed: make(map[string]bool),
+	}
+
+	// Categories are indexed as merchants so the engine's patterns map back to them
+	merchants := make([]categorization.Merchant, 0, len(categoryTotals))
+	for _, ct := range categoryTotals {
+		key := strings.ToUpper(strings.TrimSpace(ct.CategoryName))
+		if key == "" {
+			continue
+		}
+		total := ct.TotalMinor
+		if credit {
+			if ct.Count > 0 && ct.InflowMinor == 0 {
+				continue
+			}
+			total = -ct.InflowMinor
+		} else if ct.Count == 0 && ct.InflowMinor > 0 {
+			continue
+		}
+		if existing, ok := m.totals[key]; ok {
+			existing.totalMinor += total
+			continue
+		}
+		m.totals[key] = &categoryMatch{categoryID: ct.CategoryID, categoryName: ct.CategoryName, totalMinor: total}
+		merchants = append(merchants, categorization.Merchant{
+			ID:                uuid.New(),
+			RawPattern:        key,
+			CleanName:         key,
+			DefaultCategoryID: ct.CategoryID,
+		})
+	}
+
+	m.engine = categorization.NewEngine(nil, merchants)
+	m.fuzzy = categorization.NewFuzzyMatcher(nil, merchants)
+	return m
+}
+
+// exact returns the category named like the item and claims it, so it isn't
+// also matched loosely by another item
+func (m *categoryMatcher) exact(itemName string) *categoryMatch {
+	key := strings.ToUpper(strings.TrimSpace(itemName))
+	match, ok := m.totals[key]
+	if !ok {
+		return nil
+	}
+	m.claimed[key] = true
+	result := *match
+	result.matchType = MatchTypeExact
+	result.confidence = 100
+	return &result
+}
+
+// loose returns the best keyword or fuzzy match for an item among unclaimed
+// categories. Matches under fuzzyMatchThreshold are returned as near-misses
+// (matched = false) and aren't claimed.
+func (m *categoryMatcher) loose(itemName string) (match *categoryMatch, matched bool) {
+	name := strings.ToUpper(strings.TrimSpace(itemName))
+	if name == "" {
+		return nil, false
+	}
+
+	var best *categoryMatch
+	var bestKey string
+	consider := func(key string, matchType MatchType, confidence int) {
+		category, ok := m.totals[key]
+		if !ok || m.claimed[key] || (best != nil && best.confidence >= confidence) {
+			return
+		}
+		c := *category
+		c.matchType = matchType
+		c.confidence = confidence
+		best, bestKey = &c, key
+	}
+
+	for _, r := range m.engine.MatchAll(name) {
+		// Containment confidence grows with how much of the item name the category covers
+		consider(r.CleanName, MatchTypeKeyword, 75+25*len(r.CleanName)/len(name))
+	}
+	for _, r := range m.fuzzy.MatchAll(name, nearMissThreshold) {
+		consider(r.CleanName, MatchTypeFuzzy, r.Score)
+	}
+
+	if best == nil {
+		return nil, false
+	}
+	if best.confidence < fuzzyMatchThreshold {
+		return best, false
+	}
+	m.claimed[bestKey] = true
+	return best, true
+}
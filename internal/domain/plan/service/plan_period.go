@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+)
+
+// biweeklyPeriodDays is the length of a biweekly budget period
+const biweeklyPeriodDays = 14
+
+// ErrInvalidPlanPeriod is returned when a plan period configuration is incomplete or out of order
+var ErrInvalidPlanPeriod = errors.New("invalid plan period: biweekly periods need a start date, custom ranges a start before their end")
+
+// PlanPeriod is a budget period of a plan, from Start up to (not including) End
+type PlanPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls within the period
+func (p PlanPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// Days is the number of days in the period
+func (p PlanPeriod) Days() int {
+	return daysBetween(p.Start, p.End)
+}
+
+// Elapsed is the share (0-1) of the period elapsed by asOf, counting asOf's day
+func (p PlanPeriod) Elapsed(asOf time.Time) float64 {
+	days := p.Days()
+	if days <= 0 {
+		return 1
+	}
+	elapsed := daysBetween(p.Start, asOf) + 1
+	return min(max(float64(elapsed)/float64(days), 0), 1)
+}
+
+// PeriodFor returns the plan's budget period containing asOf. Monthly periods
+// start on the day of month of the plan's period start (the 1st when unset),
+// biweekly periods every 14 days from it, and a custom range doesn't recur so
+// it is always returned as configured.
+func PeriodFor(plan *repository.UserPlan, asOf time.Time) PlanPeriod {
+	loc := asOf.Location()
+	switch plan.PeriodType {
+	case repository.PlanPeriodBiweekly:
+		if plan.PeriodStart != nil {
+			anchor := dateIn(*plan.PeriodStart, loc)
+			days := daysBetween(anchor, asOf)
+			n := days / biweeklyPeriodDays
+			if days < 0 && days%biweeklyPeriodDays != 0 {
+				n-- // Round down for dates before the anchor
+			}
+			start := anchor.AddDate(0, 0, n*biweeklyPeriodDays)
+			return PlanPeriod{Start: start, End: start.AddDate(0, 0, biweeklyPeriodDays)}
+		}
+	case repository.PlanPeriodCustom:
+		if plan.PeriodStart != nil && plan.PeriodEnd != nil {
+			return PlanPeriod{Start: dateIn(*plan.PeriodStart, loc), End: dateIn(*plan.PeriodEnd, loc)}
+		}
+	}
+
+	day := 1
+	if plan.PeriodStart != nil {
+		day = plan.PeriodStart.Day()
+	}
+	month := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, loc)
+	start := calendar.WithDayClamped(month, day)
+	if start.After(asOf) {
+		month = month.AddDate(0, -1, 0)
+		start = calendar.WithDayClamped(month, day)
+	}
+	return PlanPeriod{Start: start, End: calendar.WithDayClamped(month.AddDate(0, 1, 0), day)}
+}
+
+// PreviousPeriod returns the plan's budget period before the given one. For a
+// custom range it is a range of the same length ending where it starts.
+func PreviousPeriod(plan *repository.UserPlan, period PlanPeriod) PlanPeriod {
+	switch plan.PeriodType {
+	case repository.PlanPeriodBiweekly, repository.PlanPeriodCustom:
+		return PlanPeriod{Start: period.Start.AddDate(0, 0, -period.Days()), End: period.Start}
+	default:
+		return PeriodFor(plan, period.Start.AddDate(0, 0, -1))
+	}
+}
+
+// PlanPeriodInput configures how a plan's budget periods recur
+type PlanPeriodInput struct {
+	Type  repository.PlanPeriodType
+	Start *time.Time // Monthly: optional, its day of month starts each period. Biweekly: anchor. Custom: range start.
+	End   *time.Time // Custom only: range end (exclusive)
+}
+
+// SetPlanPeriod sets the plan's budget period configuration. Actuals are
+// recomputed for the new period on the next reconciliation. Returns nil if the
+// plan is not owned by the user.
+func (s *PlanService) SetPlanPeriod(ctx context.Context, userID, planID uuid.UUID, input PlanPeriodInput) (*repository.UserPlan, error) {
+	switch input.Type {
+	case repository.PlanPeriodMonthly:
+		if input.End != nil {
+			return nil, ErrInvalidPlanPeriod
+		}
+	case repository.PlanPeriodBiweekly:
+		if input.Start == nil || input.End != nil {
+			return nil, ErrInvalidPlanPeriod
+		}
+	case repository.PlanPeriodCustom:
+		if input.Start == nil || input.End == nil || !input.End.After(*input.Start) {
+			return nil, ErrInvalidPlanPeriod
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown period type %q", ErrInvalidPlanPeriod, input.Type)
+	}
+
+	plan, err := s.getEditablePlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePlanPeriod(ctx, planID, input.Type, input.Start, input.End); err != nil {
+		return nil, err
+	}
+	plan.PeriodType, plan.PeriodStart, plan.PeriodEnd = input.Type, input.Start, input.End
+	s.notifyChanged(planID)
+	return plan, nil
+}
+
+// GetPlanForPeriod retrieves a plan with its structure, the actuals of the
+// budget period containing asOf and each item's actual in the previous period
+// for comparison. Items that don't match any category keep their stored actual.
+func (s *PlanService) GetPlanForPeriod(ctx context.Context, userID, planID uuid.UUID, asOf time.Time) (*PlanWithDetails, error) {
+	details, err := s.getPlanStructure(ctx, userID, planID)
+	if err != nil || details == nil {
+		return nil, err
+	}
+	return s.withPeriodActuals(ctx, details, asOf)
+}
+
+// withPeriodActuals sets the actuals of the budget period containing asOf, and
+// of the one before it, on a plan's items
+func (s *PlanService) withPeriodActuals(ctx context.Context, details *PlanWithDetails, asOf time.Time) (*PlanWithDetails, error) {
+	planID := details.Plan.ID
+	period := PeriodFor(details.Plan, asOf)
+	previous := PreviousPeriod(details.Plan, period)
+
+	currentTotals, err := s.planCategoryTotals(ctx, details.Plan, period.Start, period.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+	previousTotals, err := s.planCategoryTotals(ctx, details.Plan, previous.Start, previous.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous category totals: %w", err)
+	}
+
+	// Zero-fill each side with the other's categories so both periods match the same items
+	current, _, err := s.matchItems(ctx, details.Plan.UserID, planID, details.Items, withZeroTotals(currentTotals, previousTotals), period.Start, period.End)
+	if err != nil {
+		return nil, err
+	}
+	before, _, err := s.matchItems(ctx, details.Plan.UserID, planID, details.Items, withZeroTotals(previousTotals, currentTotals), previous.Start, previous.End)
+	if err != nil {
+		return nil, err
+	}
+
+	details.Period = &period
+	details.PreviousPeriod = &previous
+	details.PreviousActuals = make(map[uuid.UUID]int64, len(before))
+	for _, item := range details.Items {
+		if match, ok := current[item.ID]; ok {
+			item.ActualMinor = match.ActualMinor
+		}
+		if match, ok := before[item.ID]; ok {
+			details.PreviousActuals[item.ID] = match.ActualMinor
+		}
+	}
+	return details, nil
+}
+
+// PeriodChange is how much an item's actual changed from the previous period.
+// ok is false when the item's previous actual isn't known.
+func (d *PlanWithDetails) PeriodChange(item *repository.PlanItem) (deltaMinor int64, ok bool) {
+	previous, ok := d.PreviousActuals[item.ID]
+	if !ok {
+		return 0, false
+	}
+	return item.ActualMinor - previous, true
+}
+
+// dateIn returns t's calendar date at midnight in loc
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daysBetween counts calendar days from a to b, ignoring time of day and DST
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// ItemDrift is a plan item whose stored actual differed from its recomputed one
+type ItemDrift struct {
+	ItemID        uuid.UUID
+	ItemName      string
+	StoredMinor   int64
+	ComputedMinor int64
+}
+
+// DriftMinor is how far the stored actual was off (positive = overstated)
+func (d ItemDrift) DriftMinor() int64 {
+	return d.StoredMinor - d.ComputedMinor
+}
+
+// ActualsReconciliation reports a plan's actuals recomputed for the active period
+type ActualsReconciliation struct {
+	PlanID       uuid.UUID
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	ItemsChecked int
+	Drift        []ItemDrift
+}
+
+// TotalDriftMinor sums the absolute drift across items
+func (r *ActualsReconciliation) TotalDriftMinor() int64 {
+	var total int64
+	for _, d := range r.Drift {
+		if drift := d.DriftMinor(); drift < 0 {
+			total -= drift
+		} else {
+			total += drift
+		}
+	}
+	return total
+}
+
+// ReconcilePlanActuals recomputes a plan's actuals from transactions for the
+// plan's budget period containing asOf and overwrites the items that drifted.
+// Categories that had spending in the previous period but none yet in this one
+// are matched at zero, so actuals reset at period boundaries and after
+// transactions are edited or deleted. Items without any matching category are left alone, as their actuals
+// may be entered by hand.
+func (s *PlanService) ReconcilePlanActuals(ctx context.Context, plan *repository.UserPlan, asOf time.Time) (*ActualsReconciliation, error) {
+	period := PeriodFor(plan, asOf)
+	start, end := period.Start, period.End
+	previous := PreviousPeriod(plan, period)
+
+	items, err := s.repo.GetItemsByPlan(ctx, plan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plan items: %w", err)
+	}
+
+	categoryTotals, err := s.planCategoryTotals(ctx, plan, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+	previousTotals, err := s.planCategoryTotals(ctx, plan, previous.Start, previous.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous category totals: %w", err)
+	}
+	categoryTotals = withZeroTotals(categoryTotals, previousTotals)
+
+	matches, _, err := s.matchItems(ctx, plan.UserID, plan.ID, items, categoryTotals, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActualsReconciliation{PlanID: plan.ID, PeriodStart: start, PeriodEnd: end}
+	for _, item := range items {
+		match, ok := matches[item.ID]
+		if !ok {
+			continue
+		}
+		result.ItemsChecked++
+		if item.ActualMinor == match.ActualMinor {
+			continue
+		}
+
+		if err := s.repo.UpdatePlanItemActual(ctx, plan.ID, item.ID, match.ActualMinor); err != nil {
+			return nil, err
+		}
+		result.Drift = append(result.Drift, ItemDrift{
+			ItemID:        item.ID,
+			ItemName:      item.Name,
+			StoredMinor:   item.ActualMinor,
+			ComputedMinor: match.ActualMinor,
+		})
+	}
+
+	if len(result.Drift) > 0 {
+		s.notifyChanged(plan.ID)
+		s.logger.Info("reconciled plan actuals",
+			slog.String("plan_id", plan.ID.String()),
+			slog.Int("items_drifted", len(result.Drift)),
+			slog.Int64("total_drift_minor", result.TotalDriftMinor()),
+		)
+	}
+	return result, nil
+}
+
+// withZeroTotals adds a zero total for every previous category missing from current
+func withZeroTotals(current, previous []importrepo.CategoryTotal) []importrepo.CategoryTotal {
+	seen := make(map[string]bool, len(current))
+	for _, ct := range current {
+		seen[categoryKey(ct)] = true
+	}
+	for _, ct := range previous {
+		if key := categoryKey(ct); !seen[key] {
+			seen[key] = true
+			current = append(current, importrepo.CategoryTotal{CategoryID: ct.CategoryID, CategoryName: ct.CategoryName})
+		}
+	}
+	return current
+}
+
+func categoryKey(ct importrepo.CategoryTotal) string {
+	if ct.CategoryID != nil {
+		return ct.CategoryID.String()
+	}
+	return "name:" + ct.CategoryName
+}
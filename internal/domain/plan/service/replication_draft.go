@@ -28,13 +28,16 @@ type PatchPlanItemInput struct {
 // ReplicatePlan creates a deep clone of a plan with smart logic
 func (s *PlanService) ReplicatePlan(ctx context.Context, userID uuid.UUID, input ReplicatePlanInput) (*PlanWithDetails, error) {
 	// 1. Fetch Source Plan via Service Method (checks ownership validation logic inside if needed, but here we pass userID)
-	sourcePlan, err := s.GetPlanWithDetails(ctx, userID, input.SourcePlanID)
+	sourcePlan, err := s.getPlanStructure(ctx, userID, input.SourcePlanID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get source plan: %w", err)
 	}
 	if sourcePlan == nil {
 		return nil, fmt.Errorf("source plan not found")
 	}
+	if err := s.checkPlanQuota(ctx, userID); err != nil {
+		return nil, err
+	}
 
 	// 2. Prepare Target Plan
 	description := fmt.Sprintf("Replicated from %s", sourcePlan.Plan.Name)
@@ -46,14 +49,14 @@ func (s *PlanService) ReplicatePlan(ctx context.Context, userID uuid.UUID, input
 		Status:       repository.PlanStatusDraft,
 		SourceType:   repository.PlanSourceTemplate,
 		CurrencyCode: sourcePlan.Plan.CurrencyCode,
+		PeriodType:   sourcePlan.Plan.PeriodType,
+		PeriodStart:  sourcePlan.Plan.PeriodStart,
+		PeriodEnd:    sourcePlan.Plan.PeriodEnd,
 		// Config:       sourcePlan.Plan.Config, // Copy Config if needed
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
-	// Store period in description or config if schema doesn't support it yet
-	// For now, we omit Period field causing error
-
 	// 3. Create Plan in DB
 	if err := s.repo.CreatePlan(ctx, newPlan); err != nil {
 		return nil, fmt.Errorf("failed to create target plan: %w", err)
@@ -70,6 +73,7 @@ func (s *PlanService) ReplicatePlan(ctx context.Context, userID uuid.UUID, input
 			TargetPercent: group.TargetPercent,
 			SortOrder:     group.SortOrder,
 			Labels:        group.Labels,
+			IsFlex:        group.IsFlex,
 		}
 		if err := s.repo.CreateCategoryGroup(ctx, newGroup); err != nil {
 			return nil, err
@@ -134,7 +138,10 @@ func (s *PlanService) ReplicatePlan(ctx context.Context, userID uuid.UUID, input
 func (s *PlanService) PatchPlanItem(ctx context.Context, userID uuid.UUID, input PatchPlanItemInput) error {
 	// 1. Fetch Plan to verify ownership and get items
 	// This is expensive but safe. Optimally we'd have GetItemByID with owner check.
-	plan, err := s.GetPlanWithDetails(ctx, userID, input.PlanID)
+	if _, err := s.getEditablePlan(ctx, userID, input.PlanID); err != nil {
+		return err
+	}
+	plan, err := s.getPlanStructure(ctx, userID, input.PlanID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+var (
+	// ErrRevisionsDisabled is returned when no revision repository is configured
+	ErrRevisionsDisabled = errors.New("plan revisions are not enabled")
+	// ErrRevisionNotFound is returned when a revision doesn't exist for the plan
+	ErrRevisionNotFound = errors.New("plan revision not found")
+)
+
+// PlanSnapshot is the structure of a plan as stored in a revision
+type PlanSnapshot struct {
+	Groups []SnapshotGroup `json:"groups"`
+}
+
+// SnapshotGroup is a category group in a plan snapshot
+type SnapshotGroup struct {
+	ID            uuid.UUID          `json:"id"`
+	Name          string             `json:"name"`
+	Color         *string            `json:"color,omitempty"`
+	TargetPercent float64            `json:"target_percent"`
+	Labels        map[string]string  `json:"labels,omitempty"`
+	IsFlex        bool               `json:"is_flex"`
+	Categories    []SnapshotCategory `json:"categories"`
+}
+
+// SnapshotCategory is a category in a plan snapshot
+type SnapshotCategory struct {
+	ID     uuid.UUID         `json:"id"`
+	Name   string            `json:"name"`
+	Icon   *string           `json:"icon,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Items  []SnapshotItem    `json:"items"`
+}
+
+// SnapshotItem is a plan item in a plan snapshot
+type SnapshotItem struct {
+	ID            uuid.UUID             `json:"id"`
+	Name          string                `json:"name"`
+	BudgetedMinor int64                 `json:"budgeted_minor"`
+	ActualMinor   int64                 `json:"actual_minor"`
+	WidgetType    repository.WidgetType `json:"widget_type"`
+	FieldType     repository.FieldType  `json:"field_type"`
+	Labels        map[string]string     `json:"labels,omitempty"`
+	ItemType      repository.ItemType   `json:"item_type"`
+	ConfigID      *uuid.UUID            `json:"config_id,omitempty"`
+}
+
+// ListPlanRevisions lists a plan's revisions, newest first. Snapshots are not
+// loaded; use GetPlanRevision for one revision's contents.
+func (s *PlanService) ListPlanRevisions(ctx context.Context, userID, planID uuid.UUID, limit, offset int) ([]*repository.PlanRevision, int, error) {
+	if s.revisions == nil {
+		return nil, 0, ErrRevisionsDisabled
+	}
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	return s.revisions.ListRevisions(ctx, planID, limit, offset)
+}
+
+// GetPlanRevision gets a revision and its decoded snapshot
+func (s *PlanService) GetPlanRevision(ctx context.Context, userID, planID, revisionID uuid.UUID) (*repository.PlanRevision, *PlanSnapshot, error) {
+	if s.revisions == nil {
+		return nil, nil, ErrRevisionsDisabled
+	}
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if plan == nil {
+		return nil, nil, ErrRevisionNotFound
+	}
+
+	rev, err := s.revisions.GetRevision(ctx, planID, revisionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot := &PlanSnapshot{}
+	if err := json.Unmarshal(rev.Snapshot, snapshot); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode plan revision: %w", err)
+	}
+	return rev, snapshot, nil
+}
+
+// RestorePlanRevision puts a plan's structure back to a revision. Items that still
+// exist keep their current actuals; the restore is itself recorded as a revision.
+func (s *PlanService) RestorePlanRevision(ctx context.Context, userID, planID, revisionID uuid.UUID) (*repository.PlanRevision, error) {
+	if _, err := s.getEditablePlan(ctx, userID, planID); err != nil {
+		return nil, err
+	}
+	rev, snapshot, err := s.GetPlanRevision(ctx, userID, planID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordBaselineRevision(ctx, planID); err != nil {
+		return nil, err
+	}
+	current, err := s.repo.GetItemsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[uuid.UUID]bool, len(current))
+	for _, item := range current {
+		existing[item.ID] = true
+	}
+
+	if err := s.applyPlanStructure(ctx, planID, nil, snapshotToInput(snapshot, existing)); err != nil {
+		return nil, err
+	}
+	restored, err := s.recordRevision(ctx, userID, planID, repository.RevisionReasonRestore, &rev.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyChanged(planID)
+	return restored, nil
+}
+
+// recordBaselineRevision snapshots a plan's structure before its first recorded change
+func (s *PlanService) recordBaselineRevision(ctx context.Context, planID uuid.UUID) error {
+	if s.revisions == nil {
+		return nil
+	}
+	count, err := s.revisions.CountRevisions(ctx, planID)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = s.recordRevision(ctx, uuid.Nil, planID, repository.RevisionReasonBaseline, nil)
+	return err
+}
+
+// recordRevision snapshots the plan's current structure. A nil author is stored
+// for uuid.Nil (baseline revisions).
+func (s *PlanService) recordRevision(ctx context.Context, authorID, planID uuid.UUID, reason repository.RevisionReason, restoredFrom *uuid.UUID) (*repository.PlanRevision, error) {
+	if s.revisions == nil {
+		return nil, nil
+	}
+
+	groups, err := s.repo.GetCategoryGroupsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.GetCategoriesByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItemsByPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(buildPlanSnapshot(&PlanWithDetails{Groups: groups, Categories: categories, Items: items}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plan revision: %w", err)
+	}
+
+	rev := &repository.PlanRevision{PlanID: planID, Reason: reason, RestoredFromID: restoredFrom, Snapshot: data}
+	if authorID != uuid.Nil {
+		rev.AuthorID = &authorID
+	}
+	if err := s.revisions.CreateRevision(ctx, rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// buildPlanSnapshot nests a plan's categories and items under their groups
+func buildPlanSnapshot(details *PlanWithDetails) *PlanSnapshot {
+	itemsByCategory := make(map[uuid.UUID][]SnapshotItem)
+	for _, item := range details.Items {
+		if item.CategoryID == nil {
+			continue
+		}
+		itemsByCategory[*item.CategoryID] = append(itemsByCategory[*item.CategoryID], SnapshotItem{
+			ID:            item.ID,
+			Name:          item.Name,
+			BudgetedMinor: item.BudgetedMinor,
+			ActualMinor:   item.ActualMinor,
+			WidgetType:    item.WidgetType,
+			FieldType:     item.FieldType,
+			Labels:        unmarshalLabels(item.Labels),
+			ItemType:      item.ItemType,
+			ConfigID:      item.ConfigID,
+		})
+	}
+
+	categoriesByGroup := make(map[uuid.UUID][]SnapshotCategory)
+	for _, c := range details.Categories {
+		if c.GroupID == nil {
+			continue
+		}
+		categoriesByGroup[*c.GroupID] = append(categoriesByGroup[*c.GroupID], SnapshotCategory{
+			ID:     c.ID,
+			Name:   c.Name,
+			Icon:   c.Icon,
+			Labels: unmarshalLabels(c.Labels),
+			Items:  itemsByCategory[c.ID],
+		})
+	}
+
+	snapshot := &PlanSnapshot{Groups: make([]SnapshotGroup, 0, len(details.Groups))}
+	for _, g := range details.Groups {
+		snapshot.Groups = append(snapshot.Groups, SnapshotGroup{
+			ID:            g.ID,
+			Name:          g.Name,
+			Color:         g.Color,
+			TargetPercent: g.TargetPercent,
+			Labels:        unmarshalLabels(g.Labels),
+			IsFlex:        g.IsFlex,
+			Categories:    categoriesByGroup[g.ID],
+		})
+	}
+	return snapshot
+}
+
+// snapshotToInput converts a snapshot back into structure input. Snapshot actuals
+// only apply to items that no longer exist; existing items keep theirs.
+func snapshotToInput(snapshot *PlanSnapshot, existing map[uuid.UUID]bool) []CreateCategoryGroupInput {
+	groups := make([]CreateCategoryGroupInput, 0, len(snapshot.Groups))
+	for _, g := range snapshot.Groups {
+		groupID := g.ID
+		group := CreateCategoryGroupInput{
+			ID:            &groupID,
+			Name:          g.Name,
+			Color:         g.Color,
+			TargetPercent: g.TargetPercent,
+			Labels:        g.Labels,
+			IsFlex:        g.IsFlex,
+		}
+		for _, c := range g.Categories {
+			categoryID := c.ID
+			category := CreateCategoryInput{ID: &categoryID, Name: c.Name, Icon: c.Icon, Labels: c.Labels}
+			for _, item := range c.Items {
+				itemID := item.ID
+				input := CreateItemInput{
+					ID:            &itemID,
+					Name:          item.Name,
+					BudgetedMinor: item.BudgetedMinor,
+					WidgetType:    item.WidgetType,
+					FieldType:     item.FieldType,
+					Labels:        item.Labels,
+					ItemType:      item.ItemType,
+				}
+				if !existing[item.ID] {
+					actual := item.ActualMinor
+					input.InitialActualMinor = &actual
+				}
+				if item.ConfigID != nil {
+					configID := item.ConfigID.String()
+					input.ConfigID = &configID
+				}
+				category.Items = append(category.Items, input)
+			}
+			group.Categories = append(group.Categories, category)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// unmarshalLabels converts JSON bytes to a label map, ignoring malformed labels
+func unmarshalLabels(data []byte) map[string]string {
+	if len(data) == 0 {
+		return nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil || len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
@@ -6,13 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/excel"
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
 	"github.com/google/uuid"
 )
 
@@ -20,7 +21,14 @@ import (
 type PlanService struct {
 	repo       repository.PlanRepository
 	importRepo importrepo.ImportRepository
-	pool       *pgxpool.Pool // For ML correction persistence
+	pool       *pgxpool.Pool                     // For ML correction persistence
+	revisions  repository.PlanRevisionRepository // Optional: nil if revision history is disabled
+	mappings   repository.ItemMappingRepository  // Optional: nil matches items by name only
+	listener   PlanChangeListener                // Optional: nil if nothing follows plan changes
+	households HouseholdResolver                 // Optional: nil if plans can't be shared
+	periods    repository.BudgetPeriodRepository // Optional: nil imports Excel months into the plan only
+	quota      PlanQuota                         // Optional: nil leaves plans unlimited
+	queue      *workqueue.Queue                  // Optional: nil notifies the listener in its own goroutine
 	logger     *slog.Logger
 }
 
@@ -34,11 +42,86 @@ func NewPlanService(repo repository.PlanRepository, importRepo importrepo.Import
 	}
 }
 
+// WithRevisionRepository records a revision for every structure change
+func (s *PlanService) WithRevisionRepository(revisions repository.PlanRevisionRepository) *PlanService {
+	s.revisions = revisions
+	return s
+}
+
+// WithItemMappingRepository matches transactions to plan items through explicit
+// category and merchant mappings before falling back to name matching
+func (s *PlanService) WithItemMappingRepository(mappings repository.ItemMappingRepository) *PlanService {
+	s.mappings = mappings
+	return s
+}
+
+// WithBudgetPeriods lets Excel imports with one column or sheet per month
+// create a budget period for each month
+func (s *PlanService) WithBudgetPeriods(periods repository.BudgetPeriodRepository) *PlanService {
+	s.periods = periods
+	return s
+}
+
+// WithChangeListener notifies the listener after a plan's budgets or actuals change
+func (s *PlanService) WithChangeListener(listener PlanChangeListener) *PlanService {
+	s.listener = listener
+	return s
+}
+
+// WithWorkQueue notifies the change listener on the work queue's interactive
+// workers, so a sheet push isn't held up behind backfill
+func (s *PlanService) WithWorkQueue(queue *workqueue.Queue) *PlanService {
+	s.queue = queue
+	return s
+}
+
+// PlanQuota limits how many plans a user keeps (the quotas service)
+type PlanQuota interface {
+	CheckPlans(ctx context.Context, userID uuid.UUID) error
+}
+
+// WithPlanQuota refuses new plans once the user is at their plan quota
+func (s *PlanService) WithPlanQuota(quota PlanQuota) *PlanService {
+	s.quota = quota
+	return s
+}
+
+// checkPlanQuota returns the quota's error if the user can't add a plan
+func (s *PlanService) checkPlanQuota(ctx context.Context, userID uuid.UUID) error {
+	if s.quota == nil {
+		return nil
+	}
+	return s.quota.CheckPlans(ctx, userID)
+}
+
+// notifyChanged tells the listener about a plan change (async, non-blocking)
+func (s *PlanService) notifyChanged(planID uuid.UUID) {
+	if s.listener == nil {
+		return
+	}
+	err := s.queue.Submit(workqueue.Task{
+		Name:    "plan_changed",
+		Class:   workqueue.Interactive,
+		Timeout: sheetSyncTimeout,
+		Run: func(ctx context.Context) error {
+			s.listener.PlanChanged(ctx, planID)
+			return nil
+		},
+	})
+	if err != nil {
+		s.logger.Warn("failed to queue plan change", slog.String("plan_id", planID.String()), slog.Any("error", err))
+	}
+}
+
 // CreatePlan creates a new financial plan
 func (s *PlanService) CreatePlan(ctx context.Context, userID uuid.UUID, input *CreatePlanInput) (*PlanWithDetails, error) {
 	// Debug: log the user ID to help diagnose foreign key violations
 	s.logger.Info("creating plan", slog.String("user_id", userID.String()), slog.String("plan_name", input.Name))
 
+	if err := s.checkPlanQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	config, _ := json.Marshal(map[string]any{
 		"chart_type":       "horizontal_bar",
 		"show_percentages": true,
@@ -71,6 +154,7 @@ func (s *PlanService) CreatePlan(ctx context.Context, userID uuid.UUID, input *C
 			TargetPercent: groupInput.TargetPercent,
 			SortOrder:     sortOrder,
 			Labels:        marshalLabels(groupInput.Labels),
+			IsFlex:        groupInput.IsFlex,
 		}
 		groups = append(groups, group)
 		sortOrder++
@@ -130,19 +214,21 @@ func (s *PlanService) CreatePlan(ctx context.Context, userID uuid.UUID, input *C
 	return s.GetPlanWithDetails(ctx, userID, plan.ID)
 }
 
-// GetPlan retrieves a plan by ID with ownership check
+// GetPlan retrieves a plan the user owns or that is shared with their household.
+// Returns nil if the user can't see it.
 func (s *PlanService) GetPlan(ctx context.Context, userID, planID uuid.UUID) (*repository.UserPlan, error) {
 	plan, err := s.repo.GetPlanByID(ctx, planID)
-	if err != nil {
+	if err != nil || plan == nil {
 		return nil, err
 	}
-	if plan == nil || plan.UserID != userID {
-		return nil, nil
+	role, err := s.planRole(ctx, userID, plan)
+	if err != nil || role == "" {
+		return nil, err
 	}
 	return plan, nil
 }
 
-// ListPlans lists all plans for a user
+// ListPlans lists a user's plans and those shared with their household
 func (s *PlanService) ListPlans(ctx context.Context, userID uuid.UUID, status *repository.PlanStatus, limit, offset int) ([]*repository.UserPlan, int, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 20
@@ -150,9 +236,10 @@ func (s *PlanService) ListPlans(ctx context.Context, userID uuid.UUID, status *r
 	return s.repo.ListPlansByUser(ctx, userID, status, limit, offset)
 }
 
-// UpdatePlan updates a plan
-func (s *PlanService) UpdatePlan(ctx context.Context, userID, planID uuid.UUID, name, description *string) (*repository.UserPlan, error) {
-	plan, err := s.GetPlan(ctx, userID, planID)
+// UpdatePlan updates a plan. With an expected version, it fails with a
+// repository.VersionConflictError if the plan has moved on.
+func (s *PlanService) UpdatePlan(ctx context.Context, userID, planID uuid.UUID, expectedVersion *int64, name, description *string) (*repository.UserPlan, error) {
+	plan, err := s.getEditablePlan(ctx, userID, planID)
 	if err != nil || plan == nil {
 		return nil, err
 	}
@@ -164,7 +251,7 @@ func (s *PlanService) UpdatePlan(ctx context.Context, userID, planID uuid.UUID,
 		plan.Description = description
 	}
 
-	if err := s.repo.UpdatePlan(ctx, plan); err != nil {
+	if err := s.repo.UpdatePlan(ctx, plan, expectedVersion); err != nil {
 		return nil, err
 	}
 
@@ -174,21 +261,35 @@ func (s *PlanService) UpdatePlan(ctx context.Context, userID, planID uuid.UUID,
 // UpdatePlanItem updates a specific item's budgeted amount
 func (s *PlanService) UpdatePlanItem(ctx context.Context, userID, planID, itemID uuid.UUID, budgetedMinor int64) error {
 	// Verify ownership
-	plan, err := s.GetPlan(ctx, userID, planID)
+	plan, err := s.getEditablePlan(ctx, userID, planID)
 	if err != nil || plan == nil {
 		return err
 	}
 
-	return s.repo.UpdateItemBudget(ctx, itemID, budgetedMinor)
+	if err := s.repo.UpdateItemBudget(ctx, planID, itemID, budgetedMinor); err != nil {
+		return err
+	}
+	s.notifyChanged(planID)
+	return nil
+}
+
+// SetItemRollover opts an item in or out of carrying unspent budget into the next period
+func (s *PlanService) SetItemRollover(ctx context.Context, userID, planID, itemID uuid.UUID, enabled bool) error {
+	plan, err := s.getEditablePlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return err
+	}
+	return s.repo.SetItemRollover(ctx, planID, itemID, enabled)
 }
 
-// DeletePlan soft-deletes a plan
-func (s *PlanService) DeletePlan(ctx context.Context, userID, planID uuid.UUID) error {
-	plan, err := s.GetPlan(ctx, userID, planID)
+// DeletePlan soft-deletes a plan. With an expected version, it fails with a
+// repository.VersionConflictError if the plan has moved on.
+func (s *PlanService) DeletePlan(ctx context.Context, userID, planID uuid.UUID, expectedVersion *int64) error {
+	plan, err := s.getOwnedPlan(ctx, userID, planID)
 	if err != nil || plan == nil {
 		return err
 	}
-	return s.repo.DeletePlan(ctx, planID)
+	return s.repo.DeletePlan(ctx, planID, expectedVersion)
 }
 
 // SetActivePlan marks a plan as active
@@ -206,12 +307,22 @@ func (s *PlanService) DuplicatePlan(ctx context.Context, userID, planID uuid.UUI
 	if err != nil || plan == nil {
 		return nil, err
 	}
+	if err := s.checkPlanQuota(ctx, userID); err != nil {
+		return nil, err
+	}
 
 	return s.repo.DuplicatePlan(ctx, planID, newName, userID)
 }
 
-// GetPlanWithDetails retrieves a plan with all its structure (groups, categories, items)
+// GetPlanWithDetails retrieves a plan with all its structure (groups, categories,
+// items), with actuals for the current budget period and the previous period's
+// for comparison
 func (s *PlanService) GetPlanWithDetails(ctx context.Context, userID, planID uuid.UUID) (*PlanWithDetails, error) {
+	return s.GetPlanForPeriod(ctx, userID, planID, time.Now())
+}
+
+// getPlanStructure retrieves a plan's groups, categories and items as stored
+func (s *PlanService) getPlanStructure(ctx context.Context, userID, planID uuid.UUID) (*PlanWithDetails, error) {
 	plan, err := s.GetPlan(ctx, userID, planID)
 	if err != nil || plan == nil {
 		return nil, err
@@ -240,18 +351,36 @@ func (s *PlanService) GetPlanWithDetails(ctx context.Context, userID, planID uui
 	}, nil
 }
 
-// UpdatePlanStructure updates the entire structure of a plan
-func (s *PlanService) UpdatePlanStructure(ctx context.Context, userID, planID uuid.UUID, allowedGroups []CreateCategoryGroupInput) (*repository.UserPlan, error) {
+// UpdatePlanStructure updates the entire structure of a plan, recording a revision
+// so the change can be undone. With an expected version, the update fails with a
+// repository.VersionConflictError if another device changed the plan since.
+func (s *PlanService) UpdatePlanStructure(ctx context.Context, userID, planID uuid.UUID, expectedVersion *int64, allowedGroups []CreateCategoryGroupInput) (*repository.UserPlan, error) {
 	// 1. Verify Plan Ownership
-	plan, err := s.GetPlan(ctx, userID, planID)
+	plan, err := s.getEditablePlan(ctx, userID, planID)
 	if err != nil || plan == nil {
 		return nil, err
 	}
 
+	if err := s.recordBaselineRevision(ctx, planID); err != nil {
+		return nil, err
+	}
+	if err := s.applyPlanStructure(ctx, planID, expectedVersion, allowedGroups); err != nil {
+		return nil, err
+	}
+	if _, err := s.recordRevision(ctx, userID, planID, repository.RevisionReasonStructureUpdate, nil); err != nil {
+		return nil, err
+	}
+	s.notifyChanged(planID)
+
+	return s.repo.GetPlanByID(ctx, planID)
+}
+
+// applyPlanStructure replaces a plan's groups, categories and items
+func (s *PlanService) applyPlanStructure(ctx context.Context, planID uuid.UUID, expectedVersion *int64, allowedGroups []CreateCategoryGroupInput) error {
 	// 2. Fetch existing items to preserve actuals (if ID provided)
 	existingItems, err := s.repo.GetItemsByPlan(ctx, planID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch existing items: %w", err)
+		return fmt.Errorf("failed to fetch existing items: %w", err)
 	}
 	existingItemsMap := make(map[uuid.UUID]*repository.PlanItem)
 	for _, i := range existingItems {
@@ -278,6 +407,7 @@ func (s *PlanService) UpdatePlanStructure(ctx context.Context, userID, planID uu
 			TargetPercent: groupInput.TargetPercent,
 			SortOrder:     sortOrder,
 			Labels:        marshalLabels(groupInput.Labels),
+			IsFlex:        groupInput.IsFlex,
 		}
 		groups = append(groups, group)
 		sortOrder++
@@ -354,11 +484,10 @@ func (s *PlanService) UpdatePlanStructure(ctx context.Context, userID, planID uu
 	}
 
 	// 4. Update via Repo
-	if err := s.repo.UpdatePlanStructure(ctx, planID, groups, categories, items); err != nil {
-		return nil, fmt.Errorf("failed to update plan structure: %w", err)
+	if err := s.repo.UpdatePlanStructure(ctx, planID, expectedVersion, groups, categories, items); err != nil {
+		return fmt.Errorf("failed to update plan structure: %w", err)
 	}
-
-	return s.repo.GetPlanByID(ctx, planID)
+	return nil
 }
 
 // ComputePlanActualsInput contains the input for computing plan actuals
@@ -373,6 +502,7 @@ type ComputePlanActualsResult struct {
 	Plan                *PlanWithDetails
 	ItemsUpdated        int
 	TransactionsMatched int
+	Matches             []ItemMatch // How each matched item's actual was found, with confidence
 	UnmatchedItems      []UnmatchedItem
 }
 
@@ -380,71 +510,80 @@ type ComputePlanActualsResult struct {
 type UnmatchedItem struct {
 	ItemID   uuid.UUID
 	ItemName string
-	Reason   string // "no_category_mapping" or "no_transactions"
+	Reason   string // "no_category_mapping" or "low_confidence_match"
+
+	// Near-misses suggest the closest category as a mapping for the item
+	SuggestedCategoryID   *uuid.UUID
+	SuggestedCategoryName string
+	Confidence            int
 }
 
 // ComputePlanActuals syncs actual spending from transactions to plan items
 // This method queries transactions for the given period and aggregates spending
-// by category, then maps them to plan items via category name matching.
+// by category, then maps them to plan items: goal-linked items use contributions,
+// items with explicit category/merchant mappings use those, and the rest fall back
+// to category name matching (exact, then keyword and fuzzy). Loose matches below
+// fuzzyMatchThreshold are reported as near-misses with a suggested mapping.
 func (s *PlanService) ComputePlanActuals(ctx context.Context, userID, planID uuid.UUID, input *ComputePlanActualsInput) (*ComputePlanActualsResult, error) {
 	// Get plan with all its details
-	planDetails, err := s.GetPlanWithDetails(ctx, userID, planID)
+	planDetails, err := s.getPlanStructure(ctx, userID, planID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Query transaction totals by category for the period
-	categoryTotals, err := s.importRepo.GetCategoryTotals(ctx, userID, input.StartDate, input.EndDate)
+	categoryTotals, err := s.planCategoryTotals(ctx, planDetails.Plan, input.StartDate, input.EndDate)
 	if err != nil {
 		s.logger.Error("failed to get category totals", slog.Any("error", err))
 		return nil, err
 	}
 
-	// Build a map from category name (lowercased) to total spending
-	categoryMap := make(map[string]int64)
 	totalTransactions := 0
 	for _, ct := range categoryTotals {
-		categoryMap[strings.ToLower(ct.CategoryName)] = ct.TotalMinor
 		totalTransactions += ct.Count
 	}
 
-	// Match plan items to category totals
 	result := &ComputePlanActualsResult{
 		Plan:                planDetails,
 		ItemsUpdated:        0,
 		TransactionsMatched: totalTransactions,
+		Matches:             make([]ItemMatch, 0, len(planDetails.Items)),
 		UnmatchedItems:      make([]UnmatchedItem, 0),
 	}
 
+	matches, unmatched, err := s.matchItems(ctx, planDetails.Plan.UserID, planID, planDetails.Items, categoryTotals, input.StartDate, input.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	result.UnmatchedItems = append(result.UnmatchedItems, unmatched...)
+
 	for _, item := range planDetails.Items {
-		itemNameLower := strings.ToLower(item.Name)
-
-		// Try to find a matching category
-		if total, found := categoryMap[itemNameLower]; found {
-			// Update the item's actual amount
-			if input.Persist {
-				err := s.repo.UpdatePlanItemActual(ctx, item.ID, total)
-				if err != nil {
-					s.logger.Warn("failed to update plan item actual",
-						slog.String("item_id", item.ID.String()),
-						slog.Any("error", err),
-					)
-				} else {
-					result.ItemsUpdated++
-				}
+		match, ok := matches[item.ID]
+		if !ok {
+			continue
+		}
+		result.Matches = append(result.Matches, match)
+
+		// Update the item's actual amount
+		if input.Persist {
+			err := s.repo.UpdatePlanItemActual(ctx, item.PlanID, item.ID, match.ActualMinor)
+			if err != nil {
+				s.logger.Warn("failed to update plan item actual",
+					slog.String("item_id", item.ID.String()),
+					slog.Any("error", err),
+				)
 			} else {
 				result.ItemsUpdated++
 			}
 		} else {
-			// No matching category found
-			result.UnmatchedItems = append(result.UnmatchedItems, UnmatchedItem{
-				ItemID:   item.ID,
-				ItemName: item.Name,
-				Reason:   "no_category_mapping",
-			})
+			result.ItemsUpdated++
 		}
 	}
 
+	if input.Persist && result.ItemsUpdated > 0 {
+		s.notifyChanged(planID)
+	}
+
 	s.logger.Info("computed plan actuals",
 		slog.String("plan_id", planID.String()),
 		slog.Int("items_updated", result.ItemsUpdated),
@@ -456,12 +595,11 @@ func (s *PlanService) ComputePlanActuals(ctx context.Context, userID, planID uui
 	return result, nil
 }
 
-// ProcessTransaction handles real-time dual-impact updates.
-// It finds the active plan and updates the relevant budget item's actual spend.
-// Matching is done by category name (case-insensitive) since transaction categories
-// and plan categories are in different ID spaces.
-func (s *PlanService) ProcessTransaction(ctx context.Context, userID uuid.UUID, txAmountMinor int64, txCategoryID *uuid.UUID, txCategoryName string) error {
-	// 1. Get Active Plan
+// ProcessTransaction handles real-time dual-impact updates after a transaction
+// is recorded. Rather than incrementing the matched item, the active plan's
+// actuals are recomputed for the active period, so they stay consistent with
+// the nightly reconciliation.
+func (s *PlanService) ProcessTransaction(ctx context.Context, userID uuid.UUID) error {
 	activePlan, err := s.repo.GetActivePlan(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get active plan: %w", err)
@@ -471,60 +609,14 @@ func (s *PlanService) ProcessTransaction(ctx context.Context, userID uuid.UUID,
 		return nil
 	}
 
-	// 2. If transaction has no category name, we can't match it to a budget item
-	if txCategoryName == "" {
-		return nil
-	}
-
-	// 3. Get all items for the active plan
-	items, err := s.repo.GetItemsByPlan(ctx, activePlan.ID)
+	result, err := s.ReconcilePlanActuals(ctx, activePlan, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to fetch items for plan %s: %w", activePlan.ID, err)
-	}
-
-	// 4. Find matching item by name (case-insensitive)
-	txCategoryLower := strings.ToLower(txCategoryName)
-	var matchedItem *repository.PlanItem
-	for _, item := range items {
-		// Match by item name OR category name
-		if strings.ToLower(item.Name) == txCategoryLower {
-			// Only match budget/recurring items (not goals/income)
-			if item.ItemType == repository.ItemTypeBudget || item.ItemType == repository.ItemTypeRecurring {
-				matchedItem = item
-				break
-			}
-		}
+		return fmt.Errorf("failed to recompute plan actuals: %w", err)
 	}
-
-	if matchedItem != nil {
-		// 5. Update Actual (use absolute value since expenses are negative)
-		amountToAdd := txAmountMinor
-		if amountToAdd < 0 {
-			amountToAdd = -amountToAdd // Make positive for budget tracking
-		}
-
-		if err := s.repo.IncrementPlanItemActual(ctx, matchedItem.ID, amountToAdd); err != nil {
-			s.logger.Error("failed to increment plan item actual",
-				slog.String("item_id", matchedItem.ID.String()),
-				slog.Int64("amount", amountToAdd),
-				slog.Any("error", err),
-			)
-			return err
-		}
-		s.logger.Info("dual-impact update success",
-			slog.String("plan_id", activePlan.ID.String()),
-			slog.String("item_id", matchedItem.ID.String()),
-			slog.String("item_name", matchedItem.Name),
-			slog.String("category", txCategoryName),
-			slog.Int64("amount_added", amountToAdd),
-		)
-	} else {
-		s.logger.Debug("no matching budget item for transaction category",
-			slog.String("category", txCategoryName),
-			slog.String("plan_id", activePlan.ID.String()),
-		)
-	}
-
+	s.logger.Debug("dual-impact update success",
+		slog.String("plan_id", activePlan.ID.String()),
+		slog.Int("items_updated", len(result.Drift)),
+	)
 	return nil
 }
 
@@ -547,6 +639,7 @@ type CreateCategoryGroupInput struct {
 	Color         *string
 	TargetPercent float64
 	Labels        map[string]string
+	IsFlex        bool // Evaluate the group as one shared pool
 	Categories    []CreateCategoryInput
 }
 
@@ -578,6 +671,14 @@ type PlanWithDetails struct {
 	Groups     []*repository.PlanCategoryGroup
 	Categories []*repository.PlanCategory
 	Items      []*repository.PlanItem
+
+	// Set by GetPlanWithDetails: item actuals are for Period
+	Period          *PlanPeriod
+	PreviousPeriod  *PlanPeriod
+	PreviousActuals map[uuid.UUID]int64 // Item actuals in PreviousPeriod, for matched items
+
+	// Set by GetPlanAsOf: item budgets are as they were at AsOf
+	AsOf *time.Time
 }
 
 // ExcelAnalysisResult contains the result of analyzing an Excel file
@@ -612,6 +713,10 @@ type ExcelImportConfig struct {
 	CategoryColumn string `json:"category_column"`
 	ValueColumn    string `json:"value_column"`
 	HeaderRow      int    `json:"header_row"`
+
+	// SourceFileID is the uploaded file the plan is built from, if any. Once
+	// recorded, the file counts as imported and can be cleaned up.
+	SourceFileID *uuid.UUID `json:"-"`
 }
 
 // ExcelImportResult contains the result of importing a plan from Excel
@@ -619,6 +724,8 @@ type ExcelImportResult struct {
 	Plan               *repository.UserPlan
 	CategoriesImported int
 	ItemsImported      int
+	TotalMismatches    []excel.TotalMismatch // Category totals in the sheet that don't match their items
+	PeriodsImported    int                   // Budget periods created from month columns or sheets
 }
 
 // ============================================================================
@@ -630,9 +737,9 @@ func (s *PlanService) ListItemConfigs(ctx context.Context, userID uuid.UUID) ([]
 	return s.repo.ListItemConfigs(ctx, userID)
 }
 
-// GetItemConfigByID retrieves a specific item config
-func (s *PlanService) GetItemConfigByID(ctx context.Context, configID uuid.UUID) (*repository.ItemConfig, error) {
-	return s.repo.GetItemConfigByID(ctx, configID)
+// GetItemConfigByID retrieves one of the user's item configs
+func (s *PlanService) GetItemConfigByID(ctx context.Context, userID, configID uuid.UUID) (*repository.ItemConfig, error) {
+	return s.repo.GetItemConfigByID(ctx, userID, configID)
 }
 
 // CreateItemConfig creates a new item config
@@ -645,9 +752,9 @@ func (s *PlanService) UpdateItemConfig(ctx context.Context, config *repository.I
 	return s.repo.UpdateItemConfig(ctx, config)
 }
 
-// DeleteItemConfig deletes an item config
-func (s *PlanService) DeleteItemConfig(ctx context.Context, configID uuid.UUID) error {
-	return s.repo.DeleteItemConfig(ctx, configID)
+// DeleteItemConfig deletes one of the user's item configs
+func (s *PlanService) DeleteItemConfig(ctx context.Context, userID, configID uuid.UUID) error {
+	return s.repo.DeleteItemConfig(ctx, userID, configID)
 }
 
 // ItemsByTabResult represents the result of GetItemsByTab
@@ -657,8 +764,13 @@ type ItemsByTabResult struct {
 	TotalActual   int64
 }
 
-// GetItemsByTab returns items for a plan filtered by target tab
-func (s *PlanService) GetItemsByTab(ctx context.Context, planID uuid.UUID, targetTab repository.TargetTab) (*ItemsByTabResult, error) {
+// GetItemsByTab returns items for a plan the user can see, filtered by target
+// tab. Returns nil if the plan doesn't exist or isn't visible to the user.
+func (s *PlanService) GetItemsByTab(ctx context.Context, userID, planID uuid.UUID, targetTab repository.TargetTab) (*ItemsByTabResult, error) {
+	plan, err := s.GetPlan(ctx, userID, planID)
+	if err != nil || plan == nil {
+		return nil, err
+	}
 	items, totalBudgeted, totalActual, err := s.repo.GetItemsByTabWithTotals(ctx, planID, targetTab)
 	if err != nil {
 		return nil, err
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
@@ -19,7 +21,7 @@ func ptrInt64(i int64) *int64 { return &i }
 // fakePlanRepository implements repository.PlanRepository for testing
 type fakePlanRepository struct{}
 
-func (f *fakePlanRepository) UpdatePlanStructure(ctx context.Context, planID uuid.UUID, groups []*repository.PlanCategoryGroup, categories []*repository.PlanCategory, items []*repository.PlanItem) error {
+func (f *fakePlanRepository) UpdatePlanStructure(ctx context.Context, planID uuid.UUID, expectedVersion *int64, groups []*repository.PlanCategoryGroup, categories []*repository.PlanCategory, items []*repository.PlanItem) error {
 	return nil
 }
 
@@ -47,11 +49,11 @@ func (f *fakePlanRepository) GetPlanByID(ctx context.Context, planID uuid.UUID)
 	return &repository.UserPlan{ID: planID, UserID: uuid.MustParse("92131338-3069-42b7-84bc-8c3866be237a")}, nil
 }
 
-func (f *fakePlanRepository) UpdatePlan(ctx context.Context, plan *repository.UserPlan) error {
+func (f *fakePlanRepository) UpdatePlan(ctx context.Context, plan *repository.UserPlan, expectedVersion *int64) error {
 	return nil
 }
 
-func (f *fakePlanRepository) DeletePlan(ctx context.Context, planID uuid.UUID) error {
+func (f *fakePlanRepository) DeletePlan(ctx context.Context, planID uuid.UUID, expectedVersion *int64) error {
 	return nil
 }
 
@@ -59,6 +61,14 @@ func (f *fakePlanRepository) SetActivePlan(ctx context.Context, userID, planID u
 	return nil
 }
 
+func (f *fakePlanRepository) UpdatePlanPeriod(ctx context.Context, planID uuid.UUID, periodType repository.PlanPeriodType, start, end *time.Time) error {
+	return nil
+}
+
+func (f *fakePlanRepository) SetPlanHousehold(ctx context.Context, planID uuid.UUID, householdID *uuid.UUID) error {
+	return nil
+}
+
 func (f *fakePlanRepository) GetActivePlan(ctx context.Context, userID uuid.UUID) (*repository.UserPlan, error) {
 	return &repository.UserPlan{
 		ID:     uuid.New(),
@@ -68,10 +78,6 @@ func (f *fakePlanRepository) GetActivePlan(ctx context.Context, userID uuid.UUID
 	}, nil
 }
 
-func (f *fakePlanRepository) IncrementPlanItemActual(ctx context.Context, itemID uuid.UUID, amountMinor int64) error {
-	return nil
-}
-
 // Category Groups
 func (f *fakePlanRepository) CreateCategoryGroup(ctx context.Context, group *repository.PlanCategoryGroup) error {
 	return nil
@@ -111,11 +117,11 @@ func (f *fakePlanRepository) UpdateItem(ctx context.Context, item *repository.Pl
 	return nil
 }
 
-func (f *fakePlanRepository) UpdateItemBudget(ctx context.Context, itemID uuid.UUID, budgetedMinor int64) error {
+func (f *fakePlanRepository) UpdateItemBudget(ctx context.Context, planID, itemID uuid.UUID, budgetedMinor int64) error {
 	return nil
 }
 
-func (f *fakePlanRepository) UpdatePlanItemActual(ctx context.Context, itemID uuid.UUID, actualMinor int64) error {
+func (f *fakePlanRepository) UpdatePlanItemActual(ctx context.Context, planID, itemID uuid.UUID, actualMinor int64) error {
 	return nil
 }
 
@@ -129,7 +135,7 @@ func (f *fakePlanRepository) ListItemConfigs(ctx context.Context, userID uuid.UU
 	return nil, nil
 }
 
-func (f *fakePlanRepository) GetItemConfigByID(ctx context.Context, configID uuid.UUID) (*repository.ItemConfig, error) {
+func (f *fakePlanRepository) GetItemConfigByID(ctx context.Context, userID, configID uuid.UUID) (*repository.ItemConfig, error) {
 	return nil, nil
 }
 
@@ -141,7 +147,7 @@ func (f *fakePlanRepository) UpdateItemConfig(ctx context.Context, config *repos
 	return nil
 }
 
-func (f *fakePlanRepository) DeleteItemConfig(ctx context.Context, configID uuid.UUID) error {
+func (f *fakePlanRepository) DeleteItemConfig(ctx context.Context, userID, configID uuid.UUID) error {
 	return nil
 }
 
@@ -154,6 +160,31 @@ func (f *fakePlanRepository) FindItemByCategoryAndType(ctx context.Context, plan
 	return nil, nil
 }
 
+func (f *fakePlanRepository) GetItemBudgetsAsOf(ctx context.Context, planID uuid.UUID, asOf time.Time) (map[uuid.UUID]int64, error) {
+	return nil, nil
+}
+
+func (f *fakePlanRepository) SetItemRollover(ctx context.Context, planID, itemID uuid.UUID, enabled bool) error {
+	return nil
+}
+
+func (f *fakePlanRepository) SetGroupFlex(ctx context.Context, planID, groupID uuid.UUID, enabled bool) error {
+	return nil
+}
+
+// Item links
+func (f *fakePlanRepository) SetItemLink(ctx context.Context, planID, itemID uuid.UUID, subscriptionID, goalID *uuid.UUID) error {
+	return nil
+}
+
+func (f *fakePlanRepository) SyncSubscriptionLinkedItems(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (f *fakePlanRepository) GetGoalContributionTotals(ctx context.Context, goalIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]int64, error) {
+	return map[uuid.UUID]int64{}, nil
+}
+
 // fakeImportRepository
 type fakeImportRepository struct{}
 
@@ -193,6 +224,10 @@ func (f *fakeImportRepository) GetImportJobByID(ctx context.Context, id uuid.UUI
 	return nil, nil
 }
 
+func (f *fakeImportRepository) ListImportJobs(ctx context.Context, userID uuid.UUID, filter importrepo.ListImportJobsFilter) ([]*importrepo.ImportJob, int64, error) {
+	return nil, 0, nil
+}
+
 func (f *fakeImportRepository) GetImportJobStats(ctx context.Context, importJobID uuid.UUID) (*importrepo.ImportJobStats, error) {
 	return nil, nil
 }
@@ -267,12 +302,102 @@ func TestUpdatePlanStructure_Service(t *testing.T) {
 		},
 	}
 
-	_, err := svc.UpdatePlanStructure(ctx, userID, planID, allowedGroups)
+	_, err := svc.UpdatePlanStructure(ctx, userID, planID, nil, allowedGroups)
 	if err != nil {
 		t.Fatalf("UpdatePlanStructure failed: %v", err)
 	}
 }
 
+// versionedPlanRepository bumps the plan version on changes like the database
+type versionedPlanRepository struct {
+	fakePlanRepository
+	version int64
+}
+
+func (f *versionedPlanRepository) bump(expectedVersion *int64) error {
+	if expectedVersion != nil && *expectedVersion != f.version {
+		return &repository.VersionConflictError{Current: f.version}
+	}
+	f.version++
+	return nil
+}
+
+func (f *versionedPlanRepository) UpdatePlanStructure(_ context.Context, _ uuid.UUID, expectedVersion *int64, _ []*repository.PlanCategoryGroup, _ []*repository.PlanCategory, _ []*repository.PlanItem) error {
+	return f.bump(expectedVersion)
+}
+
+func (f *versionedPlanRepository) UpdatePlan(_ context.Context, _ *repository.UserPlan, expectedVersion *int64) error {
+	return f.bump(expectedVersion)
+}
+
+func (f *versionedPlanRepository) DeletePlan(_ context.Context, _ uuid.UUID, expectedVersion *int64) error {
+	return f.bump(expectedVersion)
+}
+
+func (f *versionedPlanRepository) GetPlanByID(ctx context.Context, planID uuid.UUID) (*repository.UserPlan, error) {
+	plan, err := f.fakePlanRepository.GetPlanByID(ctx, planID)
+	plan.Version = f.version
+	return plan, err
+}
+
+func TestUpdatePlanStructure_RejectsStaleVersion(t *testing.T) {
+	repo := &versionedPlanRepository{version: 1}
+	svc := NewPlanService(repo, &fakeImportRepository{}, nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+	userID := uuid.MustParse("92131338-3069-42b7-84bc-8c3866be237a")
+	planID := uuid.New()
+	groups := []CreateCategoryGroupInput{{Name: "Essentials", TargetPercent: 50}}
+
+	// Two devices loaded version 1; the first to save wins
+	plan, err := svc.UpdatePlanStructure(ctx, userID, planID, ptrInt64(1), groups)
+	if err != nil {
+		t.Fatalf("UpdatePlanStructure failed: %v", err)
+	}
+	if plan.Version != 2 {
+		t.Fatalf("expected version 2 after the update, got %d", plan.Version)
+	}
+
+	_, err = svc.UpdatePlanStructure(ctx, userID, planID, ptrInt64(1), groups)
+	var conflict *repository.VersionConflictError
+	if !errors.As(err, &conflict) || conflict.Current != 2 {
+		t.Fatalf("expected a version conflict at version 2, got %v", err)
+	}
+
+	if _, err := svc.UpdatePlanStructure(ctx, userID, planID, nil, groups); err != nil {
+		t.Fatalf("expected an update without a version to apply, got %v", err)
+	}
+}
+
+func TestUpdateAndDeletePlan_RejectStaleVersion(t *testing.T) {
+	repo := &versionedPlanRepository{version: 1}
+	svc := NewPlanService(repo, &fakeImportRepository{}, nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+	userID := uuid.MustParse("92131338-3069-42b7-84bc-8c3866be237a")
+	planID := uuid.New()
+
+	plan, err := svc.UpdatePlan(ctx, userID, planID, ptrInt64(1), ptrStr("Renamed"), nil)
+	if err != nil {
+		t.Fatalf("UpdatePlan failed: %v", err)
+	}
+	if plan.Version != 2 {
+		t.Fatalf("expected version 2 after the update, got %d", plan.Version)
+	}
+
+	var conflict *repository.VersionConflictError
+	_, err = svc.UpdatePlan(ctx, userID, planID, ptrInt64(1), ptrStr("Renamed elsewhere"), nil)
+	if !errors.As(err, &conflict) || conflict.Current != 2 {
+		t.Fatalf("expected the rename to conflict at version 2, got %v", err)
+	}
+
+	err = svc.DeletePlan(ctx, userID, planID, ptrInt64(1))
+	if !errors.As(err, &conflict) || conflict.Current != 2 {
+		t.Fatalf("expected the delete to conflict at version 2, got %v", err)
+	}
+	if err := svc.DeletePlan(ctx, userID, planID, ptrInt64(2)); err != nil {
+		t.Fatalf("DeletePlan failed: %v", err)
+	}
+}
+
 func TestCreatePlan_Service_WithInitialActual(t *testing.T) {
 	repo := &fakePlanRepository{}
 	importRepo := &fakeImportRepository{}
@@ -313,3 +438,610 @@ func TestCreatePlan_Service_WithInitialActual(t *testing.T) {
 		t.Fatalf("CreatePlan failed: %v", err)
 	}
 }
+
+func TestLinkPlanItem_RejectsSubscriptionAndGoal(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	svc := NewPlanService(&fakePlanRepository{}, &fakeImportRepository{}, nil, logger)
+
+	subscriptionID, goalID := uuid.New(), uuid.New()
+	_, err := svc.LinkPlanItem(context.Background(), uuid.New(), uuid.New(), uuid.New(), ItemLink{
+		SubscriptionID: &subscriptionID,
+		GoalID:         &goalID,
+	})
+	if !errors.Is(err, ErrInvalidItemLink) {
+		t.Fatalf("expected ErrInvalidItemLink, got %v", err)
+	}
+}
+
+func TestSummarizePlan_FlexGroupPoolsItems(t *testing.T) {
+	flexGroup, fixedGroup := uuid.New(), uuid.New()
+	dining, groceries, rent := uuid.New(), uuid.New(), uuid.New()
+	details := &PlanWithDetails{
+		Plan: &repository.UserPlan{ID: uuid.New(), CurrencyCode: "EUR"},
+		Groups: []*repository.PlanCategoryGroup{
+			{ID: flexGroup, Name: "Fun", IsFlex: true},
+			{ID: fixedGroup, Name: "Fundamentals"},
+		},
+		Categories: []*repository.PlanCategory{
+			{ID: dining, GroupID: &flexGroup},
+			{ID: groceries, GroupID: &fixedGroup},
+			{ID: rent, GroupID: &fixedGroup},
+		},
+		Items: []*repository.PlanItem{
+			// Restaurants overspends, but the Fun pool still holds
+			{ID: uuid.New(), CategoryID: &dining, Name: "Restaurants", ItemType: repository.ItemTypeBudget, BudgetedMinor: 10000, ActualMinor: 14000},
+			{ID: uuid.New(), CategoryID: &dining, Name: "Concerts", ItemType: repository.ItemTypeBudget, BudgetedMinor: 10000, ActualMinor: 2000},
+			{ID: uuid.New(), CategoryID: &groceries, Name: "Groceries", ItemType: repository.ItemTypeBudget, BudgetedMinor: 40000, ActualMinor: 41000},
+			{ID: uuid.New(), CategoryID: &rent, Name: "Rent", ItemType: repository.ItemTypeRecurring, BudgetedMinor: 100000, ActualMinor: 100000},
+			{ID: uuid.New(), Name: "Salary", ItemType: repository.ItemTypeIncome, BudgetedMinor: 300000},
+		},
+	}
+
+	summary := summarizePlan(details, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC))
+
+	if len(summary.Lines) != 3 {
+		t.Fatalf("expected 3 budget lines (Fun pool, Groceries, Rent), got %d", len(summary.Lines))
+	}
+	fun := summary.Lines[0]
+	if fun.GroupID == nil || *fun.GroupID != flexGroup || len(fun.ItemIDs) != 2 {
+		t.Fatalf("expected first line to be the Fun pool with 2 items, got %+v", fun)
+	}
+	if fun.BudgetedMinor != 20000 || fun.ActualMinor != 16000 || fun.Status != BudgetLineOnTrack {
+		t.Errorf("expected Fun pool 16000/20000 on track, got %d/%d %s", fun.ActualMinor, fun.BudgetedMinor, fun.Status)
+	}
+	if summary.OverBudget != 1 || summary.OverBudgetLines()[0].Name != "Groceries" {
+		t.Errorf("expected only Groceries over budget, got %d lines", summary.OverBudget)
+	}
+	if summary.BudgetedMinor != 160000 || summary.ActualMinor != 157000 {
+		t.Errorf("expected totals 157000/160000, got %d/%d", summary.ActualMinor, summary.BudgetedMinor)
+	}
+}
+
+func TestSummarizePlan_PaceUsesElapsedMonth(t *testing.T) {
+	details := &PlanWithDetails{
+		Plan:  &repository.UserPlan{ID: uuid.New()},
+		Items: []*repository.PlanItem{{ID: uuid.New(), Name: "Fuel", ItemType: repository.ItemTypeBudget, BudgetedMinor: 30000, ActualMinor: 20000}},
+	}
+
+	// 10 of 30 days elapsed: 20000 spent vs 10000 expected = 200% pace
+	summary := summarizePlan(details, time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC))
+	line := summary.Lines[0]
+	if line.Status != BudgetLineOverPace || line.PacePercent < 199 || line.PacePercent > 201 {
+		t.Errorf("expected over pace at ~200%%, got %s at %.1f%%", line.Status, line.PacePercent)
+	}
+}
+
+func TestReconcileSheetRows_MostRecentEditWins(t *testing.T) {
+	lastSync := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	sheetOnly := &repository.PlanItem{ID: uuid.New(), BudgetedMinor: 10000, UpdatedAt: lastSync}
+	appNewer := &repository.PlanItem{ID: uuid.New(), BudgetedMinor: 25000, UpdatedAt: lastSync.Add(3 * time.Hour)}
+	sheetNewer := &repository.PlanItem{ID: uuid.New(), BudgetedMinor: 5000, UpdatedAt: lastSync.Add(time.Hour)}
+	untouched := &repository.PlanItem{ID: uuid.New(), BudgetedMinor: 7000, UpdatedAt: lastSync}
+
+	states := map[uuid.UUID]int64{sheetOnly.ID: 10000, appNewer.ID: 20000, sheetNewer.ID: 4000, untouched.ID: 7000}
+	rows := [][]string{
+		{"Item ID", "Name", "Type", "Budgeted", "Actual"},
+		{sheetOnly.ID.String(), "Groceries", "budget", "120.50", "0"},
+		{appNewer.ID.String(), "Rent", "recurring", "210", "0"},
+		{sheetNewer.ID.String(), "Fuel", "budget", "€60.00", "0"},
+		{untouched.ID.String(), "Gym", "budget", "70", "0"},
+		{"", "Notes added by hand", "", "abc", ""},
+	}
+
+	rec := reconcileSheetRows([]*repository.PlanItem{sheetOnly, appNewer, sheetNewer, untouched}, rows, states, lastSync.Add(2*time.Hour))
+
+	if rec.Conflicts != 2 {
+		t.Errorf("expected 2 conflicts, got %d", rec.Conflicts)
+	}
+	if len(rec.Updates) != 2 {
+		t.Fatalf("expected 2 updates, got %v", rec.Updates)
+	}
+	if rec.Updates[sheetOnly.ID] != 12050 {
+		t.Errorf("expected sheet-only edit applied as 12050, got %d", rec.Updates[sheetOnly.ID])
+	}
+	if rec.Updates[sheetNewer.ID] != 6000 {
+		t.Errorf("expected newer sheet edit to win with 6000, got %d", rec.Updates[sheetNewer.ID])
+	}
+	if _, ok := rec.Updates[appNewer.ID]; ok {
+		t.Errorf("expected newer app edit to win over the sheet")
+	}
+}
+
+func TestSheetOAuthState(t *testing.T) {
+	svc := &SheetSyncService{stateSecret: []byte("secret")}
+	userID := uuid.New()
+	now := time.Now()
+
+	state := svc.signState(userID, now.Add(oauthStateTTL))
+	got, err := svc.verifyState(state, now)
+	if err != nil || got != userID {
+		t.Fatalf("expected state to verify for %s, got %s (%v)", userID, got, err)
+	}
+	if _, err := svc.verifyState(state, now.Add(2*oauthStateTTL)); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("expected expired state to be rejected, got %v", err)
+	}
+	tampered := uuid.New().String() + state[len(userID.String()):]
+	if _, err := svc.verifyState(tampered, now); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("expected tampered state to be rejected, got %v", err)
+	}
+}
+
+func TestPlanSnapshot_RoundTripsStructure(t *testing.T) {
+	groupID, categoryID := uuid.New(), uuid.New()
+	kept, removed := uuid.New(), uuid.New()
+	details := &PlanWithDetails{
+		Groups:     []*repository.PlanCategoryGroup{{ID: groupID, Name: "Essentials", TargetPercent: 50, IsFlex: true, Labels: []byte(`{"tab":"budget"}`)}},
+		Categories: []*repository.PlanCategory{{ID: categoryID, GroupID: &groupID, Name: "Home"}},
+		Items: []*repository.PlanItem{
+			{ID: kept, CategoryID: &categoryID, Name: "Rent", BudgetedMinor: 90000, ActualMinor: 90000, ItemType: repository.ItemTypeRecurring},
+			{ID: removed, CategoryID: &categoryID, Name: "Utilities", BudgetedMinor: 12000, ActualMinor: 8000, ItemType: repository.ItemTypeBudget},
+		},
+	}
+
+	// Snapshots are stored as JSON, so restore from the decoded form
+	data, err := json.Marshal(buildPlanSnapshot(details))
+	if err != nil {
+		t.Fatalf("marshal snapshot: %v", err)
+	}
+	var snapshot PlanSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("unmarshal snapshot: %v", err)
+	}
+
+	groups := snapshotToInput(&snapshot, map[uuid.UUID]bool{kept: true})
+	if len(groups) != 1 || *groups[0].ID != groupID || !groups[0].IsFlex || groups[0].Labels["tab"] != "budget" {
+		t.Fatalf("expected the Essentials flex group back, got %+v", groups)
+	}
+	items := groups[0].Categories[0].Items
+	if len(items) != 2 || *items[0].ID != kept || items[1].BudgetedMinor != 12000 {
+		t.Fatalf("expected both items back with their budgets, got %+v", items)
+	}
+	if items[0].InitialActualMinor != nil {
+		t.Errorf("expected existing item to keep its current actual")
+	}
+	if items[1].InitialActualMinor == nil || *items[1].InitialActualMinor != 8000 {
+		t.Errorf("expected removed item to get its snapshot actual back")
+	}
+}
+
+// fakeItemMappingRepository implements repository.ItemMappingRepository for testing
+type fakeItemMappingRepository struct {
+	mappings       []*repository.ItemMapping
+	merchantTotals map[uuid.UUID]repository.MappedTotal
+}
+
+func (f *fakeItemMappingRepository) SetItemMappings(ctx context.Context, planID, itemID uuid.UUID, categoryIDs []uuid.UUID, merchants []string) ([]*repository.ItemMapping, error) {
+	return nil, nil
+}
+
+func (f *fakeItemMappingRepository) ListMappingsByPlan(ctx context.Context, planID uuid.UUID) ([]*repository.ItemMapping, error) {
+	return f.mappings, nil
+}
+
+func (f *fakeItemMappingRepository) GetMerchantMappedTotals(ctx context.Context, planID, userID uuid.UUID, start, end time.Time) (map[uuid.UUID]repository.MappedTotal, error) {
+	return f.merchantTotals, nil
+}
+
+func TestMappedActuals_CombinesCategoriesAndMerchants(t *testing.T) {
+	groceries, dining, fuel := uuid.New(), uuid.New(), uuid.New()
+	food, transport, gifts := uuid.New(), uuid.New(), uuid.New()
+	mappings := &fakeItemMappingRepository{
+		mappings: []*repository.ItemMapping{
+			{PlanItemID: food, CategoryID: &groceries},
+			{PlanItemID: food, CategoryID: &dining},
+			{PlanItemID: transport, CategoryID: &fuel},
+			{PlanItemID: transport, MerchantPattern: ptrStr("uber")},
+			{PlanItemID: gifts, MerchantPattern: ptrStr("etsy")},
+		},
+		merchantTotals: map[uuid.UUID]repository.MappedTotal{transport: {TotalMinor: 2500, Count: 2}},
+	}
+	svc := NewPlanService(&fakePlanRepository{}, &fakeImportRepository{}, nil, slog.New(slog.DiscardHandler)).
+		WithItemMappingRepository(mappings)
+
+	totals := []importrepo.CategoryTotal{
+		{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 30000},
+		{CategoryID: &dining, CategoryName: "Dining", TotalMinor: 12000},
+		{CategoryID: &fuel, CategoryName: "Fuel", TotalMinor: 6000},
+		{CategoryName: "Uncategorized", TotalMinor: 999},
+	}
+	actuals, err := svc.mappedActuals(context.Background(), uuid.New(), uuid.New(), totals, nil, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if actuals[food] != 42000 {
+		t.Errorf("expected food to sum both mapped categories, got %d", actuals[food])
+	}
+	if actuals[transport] != 8500 {
+		t.Errorf("expected transport to add merchant spending, got %d", actuals[transport])
+	}
+	if actual, ok := actuals[gifts]; !ok || actual != 0 {
+		t.Errorf("expected mapped item without spending to be zero, got %d (present %v)", actual, ok)
+	}
+	if len(actuals) != 3 {
+		t.Errorf("expected only mapped items, got %d", len(actuals))
+	}
+}
+
+func TestCategoryMatcher_KeywordFuzzyAndNearMiss(t *testing.T) {
+	groceries, dining, transport := uuid.New(), uuid.New(), uuid.New()
+	matcher := newCategoryMatcher([]importrepo.CategoryTotal{
+		{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 30000},
+		{CategoryID: &dining, CategoryName: "Dining", TotalMinor: 12000},
+		{CategoryID: &transport, CategoryName: "Transport", TotalMinor: 6000},
+	}, false)
+
+	if c := matcher.exact("dining"); c == nil || c.confidence != 100 || c.totalMinor != 12000 {
+		t.Fatalf("expected exact case-insensitive match, got %+v", c)
+	}
+	// Dining is claimed by the exact match and can't be matched again
+	if c, matched := matcher.loose("Dining Out"); matched {
+		t.Errorf("expected claimed category to be skipped, got %+v", c)
+	}
+
+	c, matched := matcher.loose("Grocerie")
+	if !matched || c.categoryID == nil || *c.categoryID != groceries || c.matchType != MatchTypeFuzzy {
+		t.Fatalf("expected fuzzy match to groceries, got %+v (matched %v)", c, matched)
+	}
+	if c.confidence < fuzzyMatchThreshold || c.confidence >= 100 {
+		t.Errorf("expected fuzzy confidence below exact, got %d", c.confidence)
+	}
+
+	c, matched = matcher.loose("Transit")
+	if matched {
+		t.Fatalf("expected distant spelling to be a near-miss, got %+v", c)
+	}
+	if c == nil || c.categoryName != "Transport" || c.confidence < nearMissThreshold {
+		t.Errorf("expected transport to be suggested, got %+v", c)
+	}
+
+	// The transport category is still unclaimed after the near-miss
+	c, matched = matcher.loose("Public transport pass")
+	if !matched || c.matchType != MatchTypeKeyword || c.categoryName != "Transport" {
+		t.Errorf("expected keyword match to transport, got %+v (matched %v)", c, matched)
+	}
+
+	if c, matched := matcher.loose("Holidays"); matched || c != nil {
+		t.Errorf("expected no suggestion for an unrelated name, got %+v", c)
+	}
+}
+
+func TestMatchItems_CreditItemsNetAgainstSpending(t *testing.T) {
+	health, salary := uuid.New(), uuid.New()
+	healthItem := &repository.PlanItem{ID: uuid.New(), Name: "Health", BudgetedMinor: 10000, ItemType: repository.ItemTypeBudget}
+	refundItem := &repository.PlanItem{ID: uuid.New(), Name: "Health reimbursement", BudgetedMinor: -8000, ItemType: repository.ItemTypeBudget}
+	mappedRefund := &repository.PlanItem{ID: uuid.New(), Name: "Insurance payout", BudgetedMinor: -5000, ItemType: repository.ItemTypeBudget}
+	salaryItem := &repository.PlanItem{ID: uuid.New(), Name: "Salary", BudgetedMinor: 300000, ItemType: repository.ItemTypeBudget}
+	mappings := &fakeItemMappingRepository{
+		mappings: []*repository.ItemMapping{{PlanItemID: mappedRefund.ID, CategoryID: &health}},
+	}
+	svc := NewPlanService(&fakePlanRepository{}, &fakeImportRepository{}, nil, slog.New(slog.DiscardHandler)).
+		WithItemMappingRepository(mappings)
+
+	totals := []importrepo.CategoryTotal{
+		{CategoryID: &health, CategoryName: "Health", TotalMinor: 9500, InflowMinor: 6000, Count: 2},
+		{CategoryID: &salary, CategoryName: "Salary", InflowMinor: 310000}, // Inflows only
+	}
+	items := []*repository.PlanItem{healthItem, refundItem, mappedRefund, salaryItem}
+	matches, unmatched, err := svc.matchItems(context.Background(), uuid.New(), uuid.New(), items, totals, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m := matches[healthItem.ID]; m.ActualMinor != 9500 {
+		t.Errorf("expected health spending, got %+v", m)
+	}
+	if m := matches[refundItem.ID]; m.ActualMinor != -6000 || m.CategoryName != "Health" {
+		t.Errorf("expected reimbursement to match health inflows as a credit, got %+v", m)
+	}
+	if m := matches[mappedRefund.ID]; m.MatchType != MatchTypeMapping || m.ActualMinor != -6000 {
+		t.Errorf("expected mapped credit item to take negated inflows, got %+v", m)
+	}
+	if _, ok := matches[salaryItem.ID]; ok {
+		t.Errorf("expected an expense item not to match an inflow-only category")
+	}
+	if len(unmatched) != 1 || unmatched[0].ItemID != salaryItem.ID {
+		t.Errorf("expected only the salary item unmatched, got %+v", unmatched)
+	}
+
+	// The group nets the expected refund against its spending
+	net := matches[healthItem.ID].ActualMinor + matches[refundItem.ID].ActualMinor
+	if budget := healthItem.BudgetedMinor + refundItem.BudgetedMinor; net != 3500 || budget != 2000 {
+		t.Errorf("unexpected net group actual %d against budget %d", net, budget)
+	}
+}
+
+// reconcilePlanRepository serves fixed items and records actual updates
+type reconcilePlanRepository struct {
+	fakePlanRepository
+	items   []*repository.PlanItem
+	updated map[uuid.UUID]int64
+}
+
+func (f *reconcilePlanRepository) GetItemsByPlan(ctx context.Context, planID uuid.UUID) ([]*repository.PlanItem, error) {
+	return f.items, nil
+}
+
+func (f *reconcilePlanRepository) UpdatePlanItemActual(ctx context.Context, planID, itemID uuid.UUID, actualMinor int64) error {
+	f.updated[itemID] = actualMinor
+	return nil
+}
+
+// periodImportRepository returns category totals keyed by period start
+type periodImportRepository struct {
+	fakeImportRepository
+	totals map[time.Time][]importrepo.CategoryTotal
+}
+
+func (f *periodImportRepository) GetCategoryTotals(ctx context.Context, userID uuid.UUID, startDate, endDate time.Time) ([]importrepo.CategoryTotal, error) {
+	return f.totals[startDate], nil
+}
+
+func TestReconcilePlanActuals_ResetsAndReportsDrift(t *testing.T) {
+	groceries, dining := uuid.New(), uuid.New()
+	groceriesItem := &repository.PlanItem{ID: uuid.New(), Name: "Groceries", ActualMinor: 95000} // Accumulated across months
+	diningItem := &repository.PlanItem{ID: uuid.New(), Name: "Dining", ActualMinor: 4000}        // No dining yet this month
+	rentItem := &repository.PlanItem{ID: uuid.New(), Name: "Rent", ActualMinor: 80000}           // Entered by hand
+	planRepo := &reconcilePlanRepository{
+		items:   []*repository.PlanItem{groceriesItem, diningItem, rentItem},
+		updated: make(map[uuid.UUID]int64),
+	}
+
+	asOf := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	period := PeriodFor(&repository.UserPlan{}, asOf)
+	start, end := period.Start, period.End
+	if !start.Equal(time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected active period %s - %s", start, end)
+	}
+	importRepo := &periodImportRepository{totals: map[time.Time][]importrepo.CategoryTotal{
+		start: {{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 12000, Count: 3}},
+		start.AddDate(0, -1, 0): {
+			{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 40000, Count: 9},
+			{CategoryID: &dining, CategoryName: "Dining", TotalMinor: 4000, Count: 2},
+		},
+	}}
+	svc := NewPlanService(planRepo, importRepo, nil, slog.New(slog.DiscardHandler))
+
+	result, err := svc.ReconcilePlanActuals(context.Background(), &repository.UserPlan{ID: uuid.New(), UserID: uuid.New()}, asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ItemsChecked != 2 || len(result.Drift) != 2 {
+		t.Fatalf("expected 2 checked items with drift, got %d checked and %+v", result.ItemsChecked, result.Drift)
+	}
+	if planRepo.updated[groceriesItem.ID] != 12000 {
+		t.Errorf("expected groceries recomputed for the period, got %d", planRepo.updated[groceriesItem.ID])
+	}
+	if actual, ok := planRepo.updated[diningItem.ID]; !ok || actual != 0 {
+		t.Errorf("expected dining reset at the month boundary, got %d (updated %v)", actual, ok)
+	}
+	if _, ok := planRepo.updated[rentItem.ID]; ok {
+		t.Errorf("expected unmatched item to be left alone")
+	}
+	if result.TotalDriftMinor() != 83000+4000 {
+		t.Errorf("unexpected total drift %d", result.TotalDriftMinor())
+	}
+}
+
+func TestPeriodFor(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	asOf := time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)
+	payday := date(2025, time.January, 31)
+	anchor := date(2026, time.January, 5) // A Monday
+	rangeEnd := date(2026, time.April, 15)
+
+	tests := []struct {
+		name               string
+		plan               *repository.UserPlan
+		start, end         time.Time
+		prevStart, prevEnd time.Time
+	}{
+		{
+			name:  "calendar month",
+			plan:  &repository.UserPlan{},
+			start: date(2026, time.March, 1), end: date(2026, time.April, 1),
+			prevStart: date(2026, time.February, 1), prevEnd: date(2026, time.March, 1),
+		},
+		{
+			name:  "monthly from the 31st clamps to short months",
+			plan:  &repository.UserPlan{PeriodType: repository.PlanPeriodMonthly, PeriodStart: &payday},
+			start: date(2026, time.February, 28), end: date(2026, time.March, 31),
+			prevStart: date(2026, time.January, 31), prevEnd: date(2026, time.February, 28),
+		},
+		{
+			name:  "biweekly",
+			plan:  &repository.UserPlan{PeriodType: repository.PlanPeriodBiweekly, PeriodStart: &anchor},
+			start: date(2026, time.March, 2), end: date(2026, time.March, 16),
+			prevStart: date(2026, time.February, 16), prevEnd: date(2026, time.March, 2),
+		},
+		{
+			name:  "custom range",
+			plan:  &repository.UserPlan{PeriodType: repository.PlanPeriodCustom, PeriodStart: &anchor, PeriodEnd: &rangeEnd},
+			start: anchor, end: rangeEnd,
+			prevStart: date(2025, time.September, 27), prevEnd: anchor,
+		},
+	}
+	for _, tt := range tests {
+		period := PeriodFor(tt.plan, asOf)
+		if !period.Start.Equal(tt.start) || !period.End.Equal(tt.end) {
+			t.Errorf("%s: period = %s - %s", tt.name, period.Start.Format(time.DateOnly), period.End.Format(time.DateOnly))
+		}
+		if !period.Contains(asOf) {
+			t.Errorf("%s: expected period to contain %s", tt.name, asOf)
+		}
+		previous := PreviousPeriod(tt.plan, period)
+		if !previous.Start.Equal(tt.prevStart) || !previous.End.Equal(tt.prevEnd) {
+			t.Errorf("%s: previous = %s - %s", tt.name, previous.Start.Format(time.DateOnly), previous.End.Format(time.DateOnly))
+		}
+	}
+
+	// Dates before a biweekly anchor round down to the period containing them
+	before := PeriodFor(&repository.UserPlan{PeriodType: repository.PlanPeriodBiweekly, PeriodStart: &anchor}, date(2026, time.January, 1))
+	if !before.Start.Equal(date(2025, time.December, 22)) {
+		t.Errorf("period before anchor starts %s", before.Start.Format(time.DateOnly))
+	}
+}
+
+// detailsPlanRepository serves a single owned plan with fixed items
+type detailsPlanRepository struct {
+	reconcilePlanRepository
+	plan *repository.UserPlan
+}
+
+func (f *detailsPlanRepository) GetPlanByID(ctx context.Context, planID uuid.UUID) (*repository.UserPlan, error) {
+	return f.plan, nil
+}
+
+func TestGetPlanForPeriod_ComparesWithPreviousPeriod(t *testing.T) {
+	userID := uuid.New()
+	groceries, dining := uuid.New(), uuid.New()
+	groceriesItem := &repository.PlanItem{ID: uuid.New(), Name: "Groceries", ActualMinor: 95000}
+	diningItem := &repository.PlanItem{ID: uuid.New(), Name: "Dining", ActualMinor: 4000}
+	rentItem := &repository.PlanItem{ID: uuid.New(), Name: "Rent", ActualMinor: 80000}
+
+	anchor := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+	planRepo := &detailsPlanRepository{
+		reconcilePlanRepository: reconcilePlanRepository{items: []*repository.PlanItem{groceriesItem, diningItem, rentItem}},
+		plan:                    &repository.UserPlan{ID: uuid.New(), UserID: userID, PeriodType: repository.PlanPeriodBiweekly, PeriodStart: &anchor},
+	}
+	importRepo := &periodImportRepository{totals: map[time.Time][]importrepo.CategoryTotal{
+		time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC): {
+			{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 12000, Count: 3},
+		},
+		time.Date(2026, time.February, 16, 0, 0, 0, 0, time.UTC): {
+			{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 15000, Count: 4},
+			{CategoryID: &dining, CategoryName: "Dining", TotalMinor: 4000, Count: 2},
+		},
+	}}
+	svc := NewPlanService(planRepo, importRepo, nil, slog.New(slog.DiscardHandler))
+
+	details, err := svc.GetPlanForPeriod(context.Background(), userID, planRepo.plan.ID, time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if details.Period == nil || details.Period.Days() != 14 {
+		t.Fatalf("expected a biweekly period, got %+v", details.Period)
+	}
+
+	if groceriesItem.ActualMinor != 12000 || diningItem.ActualMinor != 0 {
+		t.Errorf("expected current period actuals, got groceries %d and dining %d", groceriesItem.ActualMinor, diningItem.ActualMinor)
+	}
+	if delta, ok := details.PeriodChange(groceriesItem); !ok || delta != -3000 {
+		t.Errorf("groceries change = %d (%v), want -3000", delta, ok)
+	}
+	if delta, ok := details.PeriodChange(diningItem); !ok || delta != -4000 {
+		t.Errorf("dining change = %d (%v), want -4000", delta, ok)
+	}
+	if _, ok := details.PeriodChange(rentItem); ok || rentItem.ActualMinor != 80000 {
+		t.Errorf("expected the unmatched item to keep its stored actual without a comparison")
+	}
+}
+
+type asOfPlanRepository struct {
+	detailsPlanRepository
+	budgets map[uuid.UUID]int64
+}
+
+func (f *asOfPlanRepository) GetItemBudgetsAsOf(ctx context.Context, planID uuid.UUID, asOf time.Time) (map[uuid.UUID]int64, error) {
+	return f.budgets, nil
+}
+
+func TestGetPlanAsOf_UsesBudgetsOfThatDate(t *testing.T) {
+	userID := uuid.New()
+	groceries, dining := uuid.New(), uuid.New()
+	groceriesItem := &repository.PlanItem{ID: uuid.New(), Name: "Groceries", BudgetedMinor: 50000} // Raised mid-month
+	diningItem := &repository.PlanItem{ID: uuid.New(), Name: "Dining", BudgetedMinor: 10000}       // Added later
+
+	planRepo := &asOfPlanRepository{
+		detailsPlanRepository: detailsPlanRepository{
+			reconcilePlanRepository: reconcilePlanRepository{items: []*repository.PlanItem{groceriesItem, diningItem}},
+			plan:                    &repository.UserPlan{ID: uuid.New(), UserID: userID},
+		},
+		budgets: map[uuid.UUID]int64{groceriesItem.ID: 30000},
+	}
+	importRepo := &periodImportRepository{totals: map[time.Time][]importrepo.CategoryTotal{
+		time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC): {
+			{CategoryID: &groceries, CategoryName: "Groceries", TotalMinor: 12000, Count: 3},
+			{CategoryID: &dining, CategoryName: "Dining", TotalMinor: 4000, Count: 2},
+		},
+	}}
+	svc := NewPlanService(planRepo, importRepo, nil, slog.New(slog.DiscardHandler))
+
+	asOf := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	details, err := svc.GetPlanAsOf(context.Background(), userID, planRepo.plan.ID, asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if details.AsOf == nil || !details.AsOf.Equal(asOf) {
+		t.Fatalf("expected the plan to be read as of %v, got %v", asOf, details.AsOf)
+	}
+	if len(details.Items) != 1 || details.Items[0].ID != groceriesItem.ID {
+		t.Fatalf("expected only the item that existed then, got %d items", len(details.Items))
+	}
+	if groceriesItem.BudgetedMinor != 30000 || groceriesItem.ActualMinor != 12000 {
+		t.Errorf("groceries = budget %d, actual %d; want 30000 and 12000", groceriesItem.BudgetedMinor, groceriesItem.ActualMinor)
+	}
+}
+
+func TestBuildBudgetReport_RollsUpAndProjects(t *testing.T) {
+	needs := uuid.New()
+	groceries, rent := uuid.New(), uuid.New()
+	period := PlanPeriod{
+		Start: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	details := &PlanWithDetails{
+		Plan:       &repository.UserPlan{ID: uuid.New(), Name: "Household", CurrencyCode: "EUR"},
+		Groups:     []*repository.PlanCategoryGroup{{ID: needs, Name: "Needs"}},
+		Categories: []*repository.PlanCategory{{ID: groceries, GroupID: &needs, Name: "Groceries"}, {ID: rent, GroupID: &needs, Name: "Rent"}},
+		Items: []*repository.PlanItem{
+			{ID: uuid.New(), CategoryID: &groceries, Name: "Supermarket", ItemType: repository.ItemTypeBudget, BudgetedMinor: 40000, ActualMinor: 30000},
+			{ID: uuid.New(), CategoryID: &rent, Name: "Rent", ItemType: repository.ItemTypeRecurring, BudgetedMinor: 100000, ActualMinor: 50000},
+			{ID: uuid.New(), Name: "Gifts", ItemType: repository.ItemTypeBudget, BudgetedMinor: 5000},
+			{ID: uuid.New(), Name: "Salary", ItemType: repository.ItemTypeIncome, BudgetedMinor: 300000},
+		},
+		Period: &period,
+	}
+
+	// Halfway through June: spend so far doubles by the end of the period
+	report := buildBudgetReport(details, period.Start, time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC))
+
+	levels := make([]BudgetReportLevel, len(report.Rows))
+	for i, row := range report.Rows {
+		levels[i] = row.Level
+	}
+	want := []BudgetReportLevel{
+		BudgetReportGroup, BudgetReportCategory, BudgetReportItem, BudgetReportCategory, BudgetReportItem,
+		BudgetReportGroup, BudgetReportCategory, BudgetReportItem,
+		BudgetReportTotal,
+	}
+	if len(levels) != len(want) {
+		t.Fatalf("expected rows %v, got %v", want, levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("expected rows %v, got %v", want, levels)
+		}
+	}
+
+	supermarket := report.Rows[2]
+	if supermarket.Item != "Supermarket" || supermarket.VarianceMinor != 10000 || supermarket.PercentUsed != 75 {
+		t.Errorf("expected Supermarket 75%% used with 10000 left, got %+v", supermarket)
+	}
+	if supermarket.ProjectedMinor != 60000 || supermarket.ProjectedOverrunMinor != 20000 {
+		t.Errorf("expected Supermarket projected 60000 (20000 over), got %d (%d over)", supermarket.ProjectedMinor, supermarket.ProjectedOverrunMinor)
+	}
+	if other := report.Rows[5]; other.Group != "Other" || other.BudgetedMinor != 5000 {
+		t.Errorf("expected uncategorized items under Other, got %+v", other)
+	}
+	total := report.Total()
+	if total.BudgetedMinor != 145000 || total.ActualMinor != 80000 || total.ProjectedOverrunMinor != 15000 {
+		t.Errorf("expected total 80000/145000 projected 15000 over, got %d/%d %d over", total.ActualMinor, total.BudgetedMinor, total.ProjectedOverrunMinor)
+	}
+	if report.ElapsedPercent != 50 {
+		t.Errorf("expected 50%% elapsed, got %.1f", report.ElapsedPercent)
+	}
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+// SheetsOAuthCallbackPath is where Google redirects after the user grants access
+const SheetsOAuthCallbackPath = "/plans/sheets/oauth/callback"
+
+const (
+	// oauthStateTTL bounds how long a Google connect link stays valid
+	oauthStateTTL = 15 * time.Minute
+	// sheetSyncLogLimit is how many recent runs GetSheetSyncStatus returns
+	sheetSyncLogLimit = 20
+	// sheetSyncTimeout bounds a change-triggered push
+	sheetSyncTimeout = 30 * time.Second
+)
+
+// sheetHeader is the first row of a synced sheet. Only the Budgeted column is
+// read back; the Item ID column ties rows to plan items.
+var sheetHeader = []any{"Item ID", "Name", "Type", "Budgeted", "Actual"}
+
+const (
+	sheetColItemID   = 0
+	sheetColBudgeted = 3
+)
+
+var (
+	// ErrSheetsNotConfigured is returned when no Google OAuth client is configured
+	ErrSheetsNotConfigured = errors.New("google sheets sync is not configured")
+	// ErrSheetsNotConnected is returned when the user hasn't connected a Google account
+	ErrSheetsNotConnected = errors.New("google account not connected")
+	// ErrInvalidOAuthState is returned for a tampered or expired OAuth state
+	ErrInvalidOAuthState = errors.New("invalid or expired oauth state")
+	// ErrSheetNotLinked is returned when the plan isn't mapped to a sheet
+	ErrSheetNotLinked = errors.New("plan is not linked to a sheet")
+)
+
+// PlanChangeListener is notified after a plan's budgets or actuals change
+type PlanChangeListener interface {
+	PlanChanged(ctx context.Context, planID uuid.UUID)
+}
+
+// SheetsClient reads and writes Google Sheets on behalf of a user
+type SheetsClient interface {
+	ReadRows(ctx context.Context, ts oauth2.TokenSource, spreadsheetID, sheetName string) ([][]string, error)
+	WriteRows(ctx context.Context, ts oauth2.TokenSource, spreadsheetID, sheetName string, rows [][]any) error
+	ModifiedTime(ctx context.Context, ts oauth2.TokenSource, spreadsheetID string) (time.Time, error)
+}
+
+// SheetSyncStatus is a plan's sheet mapping and its recent sync runs
+type SheetSyncStatus struct {
+	Connected bool // User has connected a Google account
+	Link      *repository.SheetLink
+	Log       []*repository.SheetSyncLogEntry
+}
+
+// SheetSyncResult summarizes a scheduled sync of all linked plans
+type SheetSyncResult struct {
+	Links     int
+	Pulled    int // Items updated from sheet edits
+	Conflicts int
+	Failed    int
+}
+
+// sheetReconciliation is the outcome of comparing sheet rows to plan items
+type sheetReconciliation struct {
+	Updates   map[uuid.UUID]int64 // Item budgets to take from the sheet
+	Conflicts int                 // Items edited on both sides since the last sync
+}
+
+// SheetSyncService keeps plans and Google Sheets in sync in both directions
+type SheetSyncService struct {
+	repo        repository.SheetSyncRepository
+	plans       *PlanService
+	client      SheetsClient
+	oauth       *oauth2.Config
+	stateSecret []byte
+	logger      *slog.Logger
+}
+
+// NewSheetSyncService creates a new sheet sync service. oauth may be nil when
+// Google isn't configured, in which case connecting returns ErrSheetsNotConfigured.
+func NewSheetSyncService(repo repository.SheetSyncRepository, plans *PlanService, client SheetsClient, oauth *oauth2.Config, stateSecret []byte, logger *slog.Logger) *SheetSyncService {
+	return &SheetSyncService{
+		repo:        repo,
+		plans:       plans,
+		client:      client,
+		oauth:       oauth,
+		stateSecret: stateSecret,
+		logger:      logger,
+	}
+}
+
+// AuthURL returns the Google consent URL for connecting the user's account
+func (s *SheetSyncService) AuthURL(userID uuid.UUID) (string, error) {
+	if s.oauth == nil {
+		return "", ErrSheetsNotConfigured
+	}
+	state := s.signState(userID, time.Now().Add(oauthStateTTL))
+	return s.oauth.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce), nil
+}
+
+// CompleteOAuth exchanges the callback code for tokens and stores them for the
+// user named in the state
+func (s *SheetSyncService) CompleteOAuth(ctx context.Context, state, code string) (uuid.UUID, error) {
+	if s.oauth == nil {
+		return uuid.Nil, ErrSheetsNotConfigured
+	}
+	userID, err := s.verifyState(state, time.Now())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	token, err := s.oauth.Exchange(ctx, code)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to exchange oauth code: %w", err)
+	}
+	if err := s.saveToken(ctx, userID, token); err != nil {
+		return uuid.Nil, err
+	}
+	return userID, nil
+}
+
+// LinkPlan maps a plan to a sheet tab and pushes the plan to it
+func (s *SheetSyncService) LinkPlan(ctx context.Context, userID, planID uuid.UUID, spreadsheetID, sheetName string) (*repository.SheetLink, error) {
+	plan, err := s.plans.getEditablePlan(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, sql.ErrNoRows
+	}
+	if _, err := s.repo.GetConnection(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSheetsNotConnected
+		}
+		return nil, err
+	}
+
+	spreadsheetID = strings.TrimSpace(spreadsheetID)
+	if spreadsheetID == "" {
+		return nil, fmt.Errorf("spreadsheet id is required")
+	}
+	if sheetName = strings.TrimSpace(sheetName); sheetName == "" {
+		sheetName = plan.Name
+	}
+
+	link := &repository.SheetLink{PlanID: planID, UserID: userID, SpreadsheetID: spreadsheetID, SheetName: sheetName}
+	if err := s.repo.UpsertLink(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.push(ctx, link); err != nil {
+		return link, err
+	}
+	return link, nil
+}
+
+// UnlinkPlan stops syncing a plan
+func (s *SheetSyncService) UnlinkPlan(ctx context.Context, userID, planID uuid.UUID) error {
+	plan, err := s.plans.getEditablePlan(ctx, userID, planID)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return ErrSheetNotLinked
+	}
+	if err := s.repo.DeleteLink(ctx, planID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSheetNotLinked
+		}
+		return err
+	}
+	return nil
+}
+
+// GetSheetSyncStatus returns the plan's sheet mapping and recent sync log
+func (s *SheetSyncService) GetSheetSyncStatus(ctx context.Context, userID, planID uuid.UUID) (*SheetSyncStatus, error) {
+	plan, err := s.plans.GetPlan(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	status := &SheetSyncStatus{}
+	if _, err := s.repo.GetConnection(ctx, userID); err == nil {
+		status.Connected = true
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	link, err := s.repo.GetLinkByPlan(ctx, planID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Link = link
+
+	status.Log, err = s.repo.ListSyncLog(ctx, link.ID, sheetSyncLogLimit)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// PlanChanged syncs a linked plan to its sheet, pulling pending sheet edits
+// first so the push doesn't overwrite them. It implements PlanChangeListener.
+func (s *SheetSyncService) PlanChanged(ctx context.Context, planID uuid.UUID) {
+	link, err := s.repo.GetLinkByPlan(ctx, planID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Warn("failed to get sheet link", slog.String("plan_id", planID.String()), slog.Any("error", err))
+		}
+		return
+	}
+	if _, err := s.SyncPlan(ctx, link); err != nil {
+		s.logger.Warn("failed to sync plan sheet", slog.String("plan_id", planID.String()), slog.Any("error", err))
+	}
+}
+
+// SyncPlan pulls sheet edits into the plan, then pushes the merged plan back
+func (s *SheetSyncService) SyncPlan(ctx context.Context, link *repository.SheetLink) (*sheetReconciliation, error) {
+	rec, err := s.pull(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	return rec, s.push(ctx, link)
+}
+
+// SyncAll syncs every linked plan; failures are logged per link and counted
+func (s *SheetSyncService) SyncAll(ctx context.Context) (*SheetSyncResult, error) {
+	links, err := s.repo.ListLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SheetSyncResult{Links: len(links)}
+	for _, link := range links {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		rec, err := s.SyncPlan(ctx, link)
+		if err != nil {
+			result.Failed++
+			s.logger.Warn("failed to sync plan sheet",
+				slog.String("plan_id", link.PlanID.String()),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		result.Pulled += len(rec.Updates)
+		result.Conflicts += rec.Conflicts
+	}
+	return result, nil
+}
+
+// pull applies sheet edits to plan budgets
+func (s *SheetSyncService) pull(ctx context.Context, link *repository.SheetLink) (rec *sheetReconciliation, err error) {
+	entry := &repository.SheetSyncLogEntry{LinkID: link.ID, Direction: repository.SheetSyncPull}
+	defer func() {
+		if rec != nil {
+			entry.ItemsUpdated = len(rec.Updates)
+			entry.Conflicts = rec.Conflicts
+		}
+		s.recordSync(ctx, entry, err)
+	}()
+
+	ts, err := s.tokenSource(ctx, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.client.ReadRows(ctx, ts, link.SpreadsheetID, link.SheetName)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := s.client.ModifiedTime(ctx, ts, link.SpreadsheetID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.plans.repo.GetItemsByPlan(ctx, link.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.repo.GetRowStates(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rec = reconcileSheetRows(items, rows, states, modified)
+	for itemID, budgeted := range rec.Updates {
+		if err := s.plans.repo.UpdateItemBudget(ctx, link.PlanID, itemID, budgeted); err != nil {
+			return rec, err
+		}
+	}
+	return rec, nil
+}
+
+// push writes the plan's current budgets and actuals to the sheet
+func (s *SheetSyncService) push(ctx context.Context, link *repository.SheetLink) (err error) {
+	entry := &repository.SheetSyncLogEntry{LinkID: link.ID, Direction: repository.SheetSyncPush}
+	defer func() { s.recordSync(ctx, entry, err) }()
+
+	ts, err := s.tokenSource(ctx, link.UserID)
+	if err != nil {
+		return err
+	}
+	items, err := s.plans.repo.GetItemsByPlan(ctx, link.PlanID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]any, 0, len(items)+1)
+	rows = append(rows, sheetHeader)
+	states := make(map[uuid.UUID]int64, len(items))
+	for _, item := range items {
+		rows = append(rows, []any{
+			item.ID.String(),
+			item.Name,
+			string(item.ItemType),
+			formatSheetAmount(item.BudgetedMinor),
+			formatSheetAmount(item.ActualMinor),
+		})
+		states[item.ID] = item.BudgetedMinor
+	}
+
+	if err := s.client.WriteRows(ctx, ts, link.SpreadsheetID, link.SheetName, rows); err != nil {
+		return err
+	}
+	if err := s.repo.ReplaceRowStates(ctx, link.ID, states); err != nil {
+		return err
+	}
+	entry.ItemsUpdated = len(items)
+	return nil
+}
+
+// recordSync logs a run's outcome; logging failures don't fail the sync
+func (s *SheetSyncService) recordSync(ctx context.Context, entry *repository.SheetSyncLogEntry, syncErr error) {
+	entry.Status = repository.SheetSyncSucceeded
+	if syncErr != nil {
+		entry.Status = repository.SheetSyncFailed
+		msg := syncErr.Error()
+		entry.Error = &msg
+	}
+	if err := s.repo.RecordSync(ctx, entry); err != nil {
+		s.logger.Warn("failed to record sheet sync", slog.String("link_id", entry.LinkID.String()), slog.Any("error", err))
+	}
+}
+
+// tokenSource returns a refreshing token source for the user, persisting any
+// refreshed access token
+func (s *SheetSyncService) tokenSource(ctx context.Context, userID uuid.UUID) (oauth2.TokenSource, error) {
+	if s.oauth == nil {
+		return nil, ErrSheetsNotConfigured
+	}
+	conn, err := s.repo.GetConnection(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSheetsNotConnected
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oauth2.Token{AccessToken: conn.AccessToken, RefreshToken: conn.RefreshToken, TokenType: conn.TokenType}
+	if conn.ExpiresAt != nil {
+		token.Expiry = *conn.ExpiresAt
+	}
+
+	ts := s.oauth.TokenSource(ctx, token)
+	fresh, err := ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh google token: %w", err)
+	}
+	if fresh.AccessToken != conn.AccessToken {
+		if err := s.saveToken(ctx, userID, fresh); err != nil {
+			return nil, err
+		}
+	}
+	return oauth2.StaticTokenSource(fresh), nil
+}
+
+func (s *SheetSyncService) saveToken(ctx context.Context, userID uuid.UUID, token *oauth2.Token) error {
+	conn := &repository.SheetConnection{
+		UserID:       userID,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.Type(),
+	}
+	if !token.Expiry.IsZero() {
+		expiry := token.Expiry
+		conn.ExpiresAt = &expiry
+	}
+	return s.repo.UpsertConnection(ctx, conn)
+}
+
+// signState encodes the user and an expiry, signed so the callback can trust it
+func (s *SheetSyncService) signState(userID uuid.UUID, expires time.Time) string {
+	payload := userID.String() + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + s.stateSignature(payload)
+}
+
+// verifyState returns the user a state was issued for
+func (s *SheetSyncService) verifyState(state string, now time.Time) (uuid.UUID, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, ErrInvalidOAuthState
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.stateSignature(payload))) {
+		return uuid.Nil, ErrInvalidOAuthState
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return uuid.Nil, ErrInvalidOAuthState
+	}
+	userID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, ErrInvalidOAuthState
+	}
+	return userID, nil
+}
+
+func (s *SheetSyncService) stateSignature(payload string) string {
+	mac := hmac.New(sha256.New, s.stateSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// reconcileSheetRows decides which sheet edits to apply. A row counts as edited
+// in the sheet when its budget differs from the last synced value, and in the app
+// when the item's budget does. Edits on one side win outright; when both sides
+// changed, the more recent edit wins (sheet modification time vs. item update).
+func reconcileSheetRows(items []*repository.PlanItem, rows [][]string, states map[uuid.UUID]int64, sheetModified time.Time) *sheetReconciliation {
+	rec := &sheetReconciliation{Updates: make(map[uuid.UUID]int64)}
+
+	byID := make(map[uuid.UUID]*repository.PlanItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	for _, row := range rows {
+		if len(row) <= sheetColBudgeted {
+			continue
+		}
+		itemID, err := uuid.Parse(strings.TrimSpace(row[sheetColItemID]))
+		if err != nil {
+			continue // Header or a row the user added
+		}
+		item, ok := byID[itemID]
+		if !ok {
+			continue
+		}
+		synced, ok := states[itemID]
+		if !ok {
+			continue // Never pushed, so nothing to compare against
+		}
+		sheetValue, ok := parseSheetAmount(row[sheetColBudgeted])
+		if !ok || sheetValue == synced {
+			continue
+		}
+
+		if item.BudgetedMinor != synced {
+			rec.Conflicts++
+			if !sheetModified.After(item.UpdatedAt) {
+				continue // App edit is more recent
+			}
+		}
+		if sheetValue != item.BudgetedMinor {
+			rec.Updates[itemID] = sheetValue
+		}
+	}
+	return rec
+}
+
+// formatSheetAmount renders minor units as a plain decimal the sheet treats as a number
+func formatSheetAmount(minor int64) string {
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+// parseSheetAmount parses a budget cell into minor units
+func parseSheetAmount(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	m, err := money.NewFromString(value, "EUR", false)
+	if err != nil {
+		return 0, false
+	}
+	return m.Amount(), true
+}
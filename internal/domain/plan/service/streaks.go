@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+)
+
+// StreakBreak is the period that ended a streak, with the item that went most
+// over its limit in it
+type StreakBreak struct {
+	Year           int
+	Month          int
+	PreviousStreak int // Periods the broken streak had lasted
+	ItemID         uuid.UUID
+	ItemName       string
+	CategoryName   string
+	LimitMinor     int64 // Budgeted plus rollover carried in
+	ActualMinor    int64
+}
+
+// OverMinor is how far the item went over its limit
+func (b *StreakBreak) OverMinor() int64 {
+	return b.ActualMinor - b.LimitMinor
+}
+
+// BudgetStreak counts consecutive closed periods spent within budget
+type BudgetStreak struct {
+	CategoryName string // Empty for the plan as a whole
+	Current      int
+	Longest      int
+	LastBreak    *StreakBreak
+}
+
+// AdherenceStreaks are a plan's overall and per-category budget streaks
+type AdherenceStreaks struct {
+	PlanID           uuid.UUID
+	Year             int // Latest closed period evaluated (zero when none)
+	Month            int
+	PeriodsEvaluated int
+	Overall          BudgetStreak
+	Categories       []*BudgetStreak // Sorted by name
+}
+
+// JustBroken returns the streaks the latest closed period ended after they had
+// lasted at least minLength periods, the overall streak first
+func (a *AdherenceStreaks) JustBroken(minLength int) []*BudgetStreak {
+	brokenNow := func(s *BudgetStreak) bool {
+		b := s.LastBreak
+		return b != nil && b.Year == a.Year && b.Month == a.Month && b.PreviousStreak >= minLength
+	}
+
+	var broken []*BudgetStreak
+	if brokenNow(&a.Overall) {
+		broken = append(broken, &a.Overall)
+	}
+	for _, c := range a.Categories {
+		if brokenNow(c) {
+			broken = append(broken, c)
+		}
+	}
+	return broken
+}
+
+// GetAdherenceStreaks returns the plan's budget streaks over the periods closed
+// before asOf. Returns nil if the plan is not owned by the user.
+func (s *BudgetPeriodService) GetAdherenceStreaks(ctx context.Context, userID, planID uuid.UUID, asOf time.Time) (*AdherenceStreaks, error) {
+	owned, err := s.ownsPlan(ctx, userID, planID)
+	if err != nil || !owned {
+		return nil, err
+	}
+	return s.ComputeAdherenceStreaks(ctx, planID, asOf)
+}
+
+// ComputeAdherenceStreaks evaluates every period closed before asOf's month
+// (used by the scheduler). A period is within budget when spending on expense
+// items stayed within their budgets plus rollover, overall or for a category as
+// a whole. A month without a period ends streaks without counting as a break.
+func (s *BudgetPeriodService) ComputeAdherenceStreaks(ctx context.Context, planID uuid.UUID, asOf time.Time) (*AdherenceStreaks, error) {
+	periods, err := s.repo.ListPeriods(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := periodIndex(asOf.Year(), int(asOf.Month()))
+	closed := make([]*repository.BudgetPeriodWithItems, 0, len(periods))
+	for _, p := range periods {
+		if periodIndex(p.Year, p.Month) >= current {
+			continue
+		}
+		period, err := s.repo.GetPeriodByID(ctx, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get budget period %d-%02d: %w", p.Year, p.Month, err)
+		}
+		closed = append(closed, period)
+	}
+
+	return computeStreaks(planID, closed), nil
+}
+
+// computeStreaks walks closed periods oldest first
+func computeStreaks(planID uuid.UUID, periods []*repository.BudgetPeriodWithItems) *AdherenceStreaks {
+	sort.Slice(periods, func(i, j int) bool {
+		return periodIndex(periods[i].Period.Year, periods[i].Period.Month) < periodIndex(periods[j].Period.Year, periods[j].Period.Month)
+	})
+
+	result := &AdherenceStreaks{PlanID: planID}
+	categories := make(map[string]*BudgetStreak)
+	lastSeen := make(map[string]int) // Category name -> period index it was last evaluated in
+	prev := 0
+
+	for _, p := range periods {
+		idx := periodIndex(p.Period.Year, p.Period.Month)
+		if prev != 0 && idx != prev+1 {
+			result.Overall.Current = 0
+		}
+		prev = idx
+
+		byCategory := make(map[string][]*repository.BudgetPeriodItem)
+		var expenses []*repository.BudgetPeriodItem
+		for _, item := range p.Items {
+			if item.ItemType != repository.ItemTypeBudget && item.ItemType != repository.ItemTypeRecurring {
+				continue
+			}
+			expenses = append(expenses, item)
+			byCategory[item.CategoryName] = append(byCategory[item.CategoryName], item)
+		}
+
+		result.Overall.record(p.Period, expenses)
+		for name, items := range byCategory {
+			streak, ok := categories[name]
+			if !ok {
+				streak = &BudgetStreak{CategoryName: name}
+				categories[name] = streak
+			}
+			if lastSeen[name] != idx-1 {
+				streak.Current = 0
+			}
+			lastSeen[name] = idx
+			streak.record(p.Period, items)
+		}
+
+		result.Year, result.Month = p.Period.Year, p.Period.Month
+		result.PeriodsEvaluated++
+	}
+
+	for name, streak := range categories {
+		if lastSeen[name] != prev {
+			streak.Current = 0 // No longer budgeted
+		}
+		result.Categories = append(result.Categories, streak)
+	}
+	sort.Slice(result.Categories, func(i, j int) bool {
+		return result.Categories[i].CategoryName < result.Categories[j].CategoryName
+	})
+	return result
+}
+
+// record extends the streak if the items stayed within budget in the period,
+// or ends it on the item that went most over
+func (s *BudgetStreak) record(period *repository.BudgetPeriod, items []*repository.BudgetPeriodItem) {
+	var available int64
+	var worst *repository.BudgetPeriodItem
+	for _, item := range items {
+		available += item.AvailableMinor()
+		if worst == nil || item.AvailableMinor() < worst.AvailableMinor() {
+			worst = item
+		}
+	}
+
+	if available >= 0 {
+		s.Current++
+		s.Longest = max(s.Longest, s.Current)
+		return
+	}
+
+	s.LastBreak = &StreakBreak{
+		Year:           period.Year,
+		Month:          period.Month,
+		PreviousStreak: s.Current,
+		ItemID:         worst.ItemID,
+		ItemName:       worst.ItemName,
+		CategoryName:   worst.CategoryName,
+		LimitMinor:     worst.BudgetedMinor + worst.RolloverMinor,
+		ActualMinor:    worst.ActualMinor,
+	}
+	s.Current = 0
+}
+
+// periodIndex numbers months consecutively so gaps between periods show up
+func periodIndex(year, month int) int {
+	return year*12 + month - 1
+}
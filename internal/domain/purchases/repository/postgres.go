@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresPurchaseRepository implements PurchaseRepository using PostgreSQL
+type PostgresPurchaseRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPurchaseRepository creates a new PostgreSQL purchase repository
+func NewPostgresPurchaseRepository(pool *pgxpool.Pool) *PostgresPurchaseRepository {
+	return &PostgresPurchaseRepository{pool: pool}
+}
+
+const purchaseColumns = `id, user_id, transaction_id, merchant_name, description, amount_minor, currency_code,
+	purchased_at, return_by, warranty_until, reminder_days, attachment_key, status,
+	return_reminded_at, warranty_reminded_at, created_at, updated_at`
+
+func scanPurchase(row pgx.Row) (*Purchase, error) {
+	p := &Purchase{}
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.TransactionID, &p.MerchantName, &p.Description, &p.AmountMinor, &p.CurrencyCode,
+		&p.PurchasedAt, &p.ReturnBy, &p.WarrantyUntil, &p.ReminderDays, &p.AttachmentKey, &p.Status,
+		&p.ReturnRemindedAt, &p.WarrantyRemindedAt, &p.CreatedAt, &p.UpdatedAt,
+	)
+	return p, err
+}
+
+// CreatePurchase inserts a tracked purchase
+func (r *PostgresPurchaseRepository) CreatePurchase(ctx context.Context, p *Purchase) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO tracked_purchases (id, user_id, transaction_id, merchant_name, description, amount_minor,
+			currency_code, purchased_at, return_by, warranty_until, reminder_days, attachment_key, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at, updated_at`,
+		p.ID, p.UserID, p.TransactionID, p.MerchantName, p.Description, p.AmountMinor,
+		p.CurrencyCode, p.PurchasedAt, p.ReturnBy, p.WarrantyUntil, p.ReminderDays, p.AttachmentKey, p.Status,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrTransactionAlreadyTracked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tracked purchase: %w", err)
+	}
+	return nil
+}
+
+// GetPurchase retrieves a tracked purchase by ID
+func (r *PostgresPurchaseRepository) GetPurchase(ctx context.Context, id uuid.UUID) (*Purchase, error) {
+	p, err := scanPurchase(r.pool.QueryRow(ctx, `SELECT `+purchaseColumns+` FROM tracked_purchases WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked purchase: %w", err)
+	}
+	return p, nil
+}
+
+// ListPurchases lists a user's tracked purchases, newest first
+func (r *PostgresPurchaseRepository) ListPurchases(ctx context.Context, userID uuid.UUID, status *PurchaseStatus) ([]*Purchase, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+purchaseColumns+`
+		FROM tracked_purchases
+		WHERE user_id = $1 AND ($2::text IS NULL OR status = $2)
+		ORDER BY purchased_at DESC, created_at DESC`, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked purchases: %w", err)
+	}
+	defer rows.Close()
+	return collectPurchases(rows)
+}
+
+// UpdateStatus sets a tracked purchase's status
+func (r *PostgresPurchaseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status PurchaseStatus) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE tracked_purchases SET status = $2 WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update tracked purchase status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetAttachment sets or clears a tracked purchase's receipt attachment
+func (r *PostgresPurchaseRepository) SetAttachment(ctx context.Context, id uuid.UUID, attachmentKey *string) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE tracked_purchases SET attachment_key = $2 WHERE id = $1`, id, attachmentKey)
+	if err != nil {
+		return fmt.Errorf("failed to set tracked purchase attachment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetTransaction loads one of the user's expense transactions
+func (r *PostgresPurchaseRepository) GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*PurchaseTransaction, error) {
+	t := &PurchaseTransaction{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, COALESCE(merchant_name, ''), description, ABS(amount_minor), currency_code, posted_at
+		FROM transactions
+		WHERE id = $1 AND user_id = $2 AND amount_minor < 0 AND deleted_at IS NULL`, transactionID, userID,
+	).Scan(&t.TransactionID, &t.MerchantName, &t.Description, &t.AmountMinor, &t.CurrencyCode, &t.PostedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchase transaction: %w", err)
+	}
+	return t, nil
+}
+
+// UpsertPolicy creates or replaces the user's policy for a merchant pattern
+func (r *PostgresPurchaseRepository) UpsertPolicy(ctx context.Context, policy *MerchantPolicy) error {
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO merchant_return_policies (id, user_id, merchant_pattern, return_days, warranty_months, reminder_days)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, merchant_pattern) DO UPDATE SET
+			return_days = EXCLUDED.return_days,
+			warranty_months = EXCLUDED.warranty_months,
+			reminder_days = EXCLUDED.reminder_days
+		RETURNING id, created_at, updated_at`,
+		policy.ID, policy.UserID, policy.MerchantPattern, policy.ReturnDays, policy.WarrantyMonths, policy.ReminderDays,
+	).Scan(&policy.ID, &policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save merchant return policy: %w", err)
+	}
+	return nil
+}
+
+// ListPolicies lists a user's merchant policies
+func (r *PostgresPurchaseRepository) ListPolicies(ctx context.Context, userID uuid.UUID) ([]*MerchantPolicy, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, merchant_pattern, return_days, warranty_months, reminder_days, created_at, updated_at
+		FROM merchant_return_policies
+		WHERE user_id = $1
+		ORDER BY merchant_pattern`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merchant return policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*MerchantPolicy
+	for rows.Next() {
+		p := &MerchantPolicy{}
+		if err := rows.Scan(&p.ID, &p.UserID, &p.MerchantPattern, &p.ReturnDays, &p.WarrantyMonths,
+			&p.ReminderDays, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan merchant return policy: %w", err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// DeletePolicy removes one of the user's merchant policies
+func (r *PostgresPurchaseRepository) DeletePolicy(ctx context.Context, userID, policyID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM merchant_return_policies WHERE id = $1 AND user_id = $2`, policyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant return policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListDueReturnReminders lists purchases whose return window closes within their reminder days
+func (r *PostgresPurchaseRepository) ListDueReturnReminders(ctx context.Context, asOf time.Time) ([]*Purchase, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+purchaseColumns+`
+		FROM tracked_purchases
+		WHERE status = 'active'
+		  AND return_reminded_at IS NULL
+		  AND return_by >= $1::date
+		  AND return_by <= $1::date + reminder_days
+		ORDER BY return_by`, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due return reminders: %w", err)
+	}
+	defer rows.Close()
+	return collectPurchases(rows)
+}
+
+// ListDueWarrantyReminders lists purchases whose warranty ends within leadDays
+func (r *PostgresPurchaseRepository) ListDueWarrantyReminders(ctx context.Context, asOf time.Time, leadDays int) ([]*Purchase, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+purchaseColumns+`
+		FROM tracked_purchases
+		WHERE status = 'active'
+		  AND warranty_reminded_at IS NULL
+		  AND warranty_until >= $1::date
+		  AND warranty_until <= $1::date + $2::int
+		ORDER BY warranty_until`, asOf, leadDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due warranty reminders: %w", err)
+	}
+	defer rows.Close()
+	return collectPurchases(rows)
+}
+
+// MarkReminded records that a reminder was sent, so it isn't sent again
+func (r *PostgresPurchaseRepository) MarkReminded(ctx context.Context, id uuid.UUID, kind ReminderKind, at time.Time) error {
+	column := "return_reminded_at"
+	if kind == ReminderWarranty {
+		column = "warranty_reminded_at"
+	}
+	tag, err := r.pool.Exec(ctx, `UPDATE tracked_purchases SET `+column+` = $2 WHERE id = $1 AND `+column+` IS NULL`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to mark purchase reminded: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func collectPurchases(rows pgx.Rows) ([]*Purchase, error) {
+	var purchases []*Purchase
+	for rows.Next() {
+		p, err := scanPurchase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tracked purchase: %w", err)
+		}
+		purchases = append(purchases, p)
+	}
+	return purchases, rows.Err()
+}
@@ -0,0 +1,97 @@
+// Package repository provides database operations for tracked purchases.
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTransactionAlreadyTracked is returned when a transaction is already tracked as a purchase
+var ErrTransactionAlreadyTracked = errors.New("transaction is already tracked")
+
+// PurchaseStatus represents the lifecycle of a tracked purchase
+type PurchaseStatus string
+
+const (
+	PurchaseStatusActive   PurchaseStatus = "active"
+	PurchaseStatusReturned PurchaseStatus = "returned"
+	PurchaseStatusArchived PurchaseStatus = "archived"
+)
+
+// ReminderKind is the deadline a reminder is about
+type ReminderKind string
+
+const (
+	ReminderReturnWindow ReminderKind = "return_window"
+	ReminderWarranty     ReminderKind = "warranty"
+)
+
+// MerchantPolicy is a user's return and warranty terms for a merchant
+type MerchantPolicy struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	MerchantPattern string // Case-insensitive substring of the merchant name
+	ReturnDays      int
+	WarrantyMonths  *int
+	ReminderDays    int // How many days before a deadline to remind
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Purchase is a purchase tracked for its return window and warranty
+type Purchase struct {
+	ID                 uuid.UUID
+	UserID             uuid.UUID
+	TransactionID      *uuid.UUID
+	MerchantName       string
+	Description        *string
+	AmountMinor        int64
+	CurrencyCode       string
+	PurchasedAt        time.Time
+	ReturnBy           *time.Time // Last day the purchase can be returned
+	WarrantyUntil      *time.Time // Last day of warranty cover
+	ReminderDays       int
+	AttachmentKey      *string // Receipt or warranty document in file storage
+	Status             PurchaseStatus
+	ReturnRemindedAt   *time.Time
+	WarrantyRemindedAt *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// PurchaseTransaction is an expense transaction a purchase is tracked from
+type PurchaseTransaction struct {
+	TransactionID uuid.UUID
+	MerchantName  string
+	Description   string
+	AmountMinor   int64 // Absolute value
+	CurrencyCode  string
+	PostedAt      time.Time
+}
+
+// PurchaseRepository defines the interface for tracked purchase persistence
+type PurchaseRepository interface {
+	CreatePurchase(ctx context.Context, p *Purchase) error
+	GetPurchase(ctx context.Context, id uuid.UUID) (*Purchase, error)
+	ListPurchases(ctx context.Context, userID uuid.UUID, status *PurchaseStatus) ([]*Purchase, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status PurchaseStatus) error
+	SetAttachment(ctx context.Context, id uuid.UUID, attachmentKey *string) error
+
+	// GetTransaction loads one of the user's expense transactions to track
+	GetTransaction(ctx context.Context, userID, transactionID uuid.UUID) (*PurchaseTransaction, error)
+
+	// Merchant policies
+	UpsertPolicy(ctx context.Context, policy *MerchantPolicy) error
+	ListPolicies(ctx context.Context, userID uuid.UUID) ([]*MerchantPolicy, error)
+	DeletePolicy(ctx context.Context, userID, policyID uuid.UUID) error
+
+	// Reminders list active, not yet reminded purchases whose deadline hasn't
+	// passed: return windows within each purchase's reminder days of asOf, and
+	// warranties within leadDays
+	ListDueReturnReminders(ctx context.Context, asOf time.Time) ([]*Purchase, error)
+	ListDueWarrantyReminders(ctx context.Context, asOf time.Time, leadDays int) ([]*Purchase, error)
+	MarkReminded(ctx context.Context, id uuid.UUID, kind ReminderKind, at time.Time) error
+}
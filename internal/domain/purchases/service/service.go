@@ -0,0 +1,326 @@
+// Package service provides business logic for tracked purchases: return
+// windows, warranty cover and reminders before either lapses.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
+)
+
+const (
+	// defaultReturnDays is the return window when no merchant policy applies
+	defaultReturnDays = 30
+	// defaultReminderDays is how many days before a return window closes to remind
+	defaultReminderDays = 3
+	// defaultWarrantyMonths is the statutory guarantee assumed for purchases with a
+	// receipt attached when no merchant policy or explicit warranty applies
+	defaultWarrantyMonths = 24
+	// warrantyReminderDays is how many days before warranty cover ends to remind
+	warrantyReminderDays = 30
+)
+
+var (
+	// ErrInvalidPurchase is returned for a purchase without a merchant or a positive amount
+	ErrInvalidPurchase = errors.New("a tracked purchase needs a merchant and a positive amount")
+	// ErrInvalidPolicy is returned for out-of-range merchant policy values
+	ErrInvalidPolicy = errors.New("merchant policies need a pattern, 0-365 return days, 1-240 warranty months and 0-60 reminder days")
+	// ErrPolicyNotFound is returned when a merchant policy doesn't exist or isn't the user's
+	ErrPolicyNotFound = errors.New("merchant policy not found")
+)
+
+// TrackPurchaseInput describes a purchase to track. Unset terms come from the
+// user's policy for the merchant, then the defaults.
+type TrackPurchaseInput struct {
+	TransactionID  *uuid.UUID // Optional: track an existing expense transaction
+	MerchantName   string
+	Description    *string
+	AmountMinor    int64
+	CurrencyCode   string
+	PurchasedAt    *time.Time // Defaults to the transaction date (or today)
+	ReturnDays     *int       // 0 for purchases that can't be returned
+	WarrantyMonths *int
+	ReminderDays   *int
+	AttachmentKey  *string // Receipt or warranty document in file storage
+}
+
+// Reminder is an upcoming return or warranty deadline of a purchase
+type Reminder struct {
+	Kind     repository.ReminderKind
+	Purchase *repository.Purchase
+	Deadline time.Time
+	DaysLeft int
+}
+
+// Service provides tracked purchase business logic
+type Service struct {
+	repo repository.PurchaseRepository
+}
+
+// NewService creates a new purchases service
+func NewService(repo repository.PurchaseRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// TrackPurchase starts tracking a purchase's return window and warranty. When a
+// transaction is given, its merchant, amount and date are used.
+func (s *Service) TrackPurchase(ctx context.Context, userID uuid.UUID, input TrackPurchaseInput) (*repository.Purchase, error) {
+	purchasedAt := time.Now()
+	if input.TransactionID != nil {
+		tx, err := s.repo.GetTransaction(ctx, userID, *input.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if input.MerchantName == "" {
+			input.MerchantName = tx.MerchantName
+		}
+		if input.MerchantName == "" {
+			input.MerchantName = tx.Description
+		}
+		if input.AmountMinor == 0 {
+			input.AmountMinor = tx.AmountMinor
+		}
+		if input.CurrencyCode == "" {
+			input.CurrencyCode = tx.CurrencyCode
+		}
+		purchasedAt = tx.PostedAt
+	}
+	if input.PurchasedAt != nil {
+		purchasedAt = *input.PurchasedAt
+	}
+	if input.CurrencyCode == "" {
+		input.CurrencyCode = "EUR"
+	}
+
+	input.MerchantName = strings.TrimSpace(input.MerchantName)
+	if input.MerchantName == "" || input.AmountMinor <= 0 {
+		return nil, ErrInvalidPurchase
+	}
+
+	policies, err := s.repo.ListPolicies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	returnDays, warrantyMonths, reminderDays := resolveTerms(input, MatchPolicy(policies, input.MerchantName))
+
+	purchase := &repository.Purchase{
+		UserID:        userID,
+		TransactionID: input.TransactionID,
+		MerchantName:  input.MerchantName,
+		Description:   input.Description,
+		AmountMinor:   input.AmountMinor,
+		CurrencyCode:  input.CurrencyCode,
+		PurchasedAt:   dateOf(purchasedAt),
+		ReminderDays:  reminderDays,
+		AttachmentKey: input.AttachmentKey,
+		Status:        repository.PurchaseStatusActive,
+	}
+	purchase.ReturnBy, purchase.WarrantyUntil = Deadlines(purchase.PurchasedAt, returnDays, warrantyMonths)
+
+	if err := s.repo.CreatePurchase(ctx, purchase); err != nil {
+		return nil, err
+	}
+	return purchase, nil
+}
+
+// GetPurchase retrieves a purchase owned by the user (nil if missing or not owned)
+func (s *Service) GetPurchase(ctx context.Context, userID, purchaseID uuid.UUID) (*repository.Purchase, error) {
+	purchase, err := s.repo.GetPurchase(ctx, purchaseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if purchase.UserID != userID {
+		return nil, nil
+	}
+	return purchase, nil
+}
+
+// ListPurchases lists a user's tracked purchases
+func (s *Service) ListPurchases(ctx context.Context, userID uuid.UUID, status *repository.PurchaseStatus) ([]*repository.Purchase, error) {
+	return s.repo.ListPurchases(ctx, userID, status)
+}
+
+// MarkReturned records that a purchase was returned; it gets no further reminders
+func (s *Service) MarkReturned(ctx context.Context, userID, purchaseID uuid.UUID) (*repository.Purchase, error) {
+	return s.setStatus(ctx, userID, purchaseID, repository.PurchaseStatusReturned)
+}
+
+// ArchivePurchase stops tracking a purchase the user is keeping
+func (s *Service) ArchivePurchase(ctx context.Context, userID, purchaseID uuid.UUID) (*repository.Purchase, error) {
+	return s.setStatus(ctx, userID, purchaseID, repository.PurchaseStatusArchived)
+}
+
+func (s *Service) setStatus(ctx context.Context, userID, purchaseID uuid.UUID, status repository.PurchaseStatus) (*repository.Purchase, error) {
+	purchase, err := s.GetPurchase(ctx, userID, purchaseID)
+	if err != nil || purchase == nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateStatus(ctx, purchaseID, status); err != nil {
+		return nil, err
+	}
+	purchase.Status = status
+	return purchase, nil
+}
+
+// AttachReceipt sets (or, with nil, clears) the receipt document of a purchase
+func (s *Service) AttachReceipt(ctx context.Context, userID, purchaseID uuid.UUID, attachmentKey *string) (*repository.Purchase, error) {
+	purchase, err := s.GetPurchase(ctx, userID, purchaseID)
+	if err != nil || purchase == nil {
+		return nil, err
+	}
+	if err := s.repo.SetAttachment(ctx, purchaseID, attachmentKey); err != nil {
+		return nil, err
+	}
+	purchase.AttachmentKey = attachmentKey
+	return purchase, nil
+}
+
+// SetMerchantPolicy creates or replaces the user's return and warranty terms
+// for merchants whose name contains pattern
+func (s *Service) SetMerchantPolicy(ctx context.Context, userID uuid.UUID, pattern string, returnDays int, warrantyMonths *int, reminderDays int) (*repository.MerchantPolicy, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || returnDays < 0 || returnDays > 365 || reminderDays < 0 || reminderDays > 60 ||
+		(warrantyMonths != nil && (*warrantyMonths < 1 || *warrantyMonths > 240)) {
+		return nil, ErrInvalidPolicy
+	}
+
+	policy := &repository.MerchantPolicy{
+		UserID:          userID,
+		MerchantPattern: pattern,
+		ReturnDays:      returnDays,
+		WarrantyMonths:  warrantyMonths,
+		ReminderDays:    reminderDays,
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListMerchantPolicies lists the user's merchant policies
+func (s *Service) ListMerchantPolicies(ctx context.Context, userID uuid.UUID) ([]*repository.MerchantPolicy, error) {
+	return s.repo.ListPolicies(ctx, userID)
+}
+
+// DeleteMerchantPolicy removes one of the user's merchant policies. Purchases
+// already tracked keep their deadlines.
+func (s *Service) DeleteMerchantPolicy(ctx context.Context, userID, policyID uuid.UUID) error {
+	err := s.repo.DeletePolicy(ctx, userID, policyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPolicyNotFound
+	}
+	return err
+}
+
+// DueReminders lists the return windows and warranties that are about to lapse
+// and haven't been reminded about yet, across all users
+func (s *Service) DueReminders(ctx context.Context, asOf time.Time) ([]Reminder, error) {
+	returns, err := s.repo.ListDueReturnReminders(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	warranties, err := s.repo.ListDueWarrantyReminders(ctx, asOf, warrantyReminderDays)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders := make([]Reminder, 0, len(returns)+len(warranties))
+	for _, p := range returns {
+		reminders = append(reminders, newReminder(repository.ReminderReturnWindow, p, *p.ReturnBy, asOf))
+	}
+	for _, p := range warranties {
+		reminders = append(reminders, newReminder(repository.ReminderWarranty, p, *p.WarrantyUntil, asOf))
+	}
+	return reminders, nil
+}
+
+// MarkReminded records a sent reminder. Returns false if it was already recorded
+// (e.g. by a concurrent run).
+func (s *Service) MarkReminded(ctx context.Context, reminder Reminder, at time.Time) (bool, error) {
+	err := s.repo.MarkReminded(ctx, reminder.Purchase.ID, reminder.Kind, at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to mark %s reminder: %w", reminder.Kind, err)
+	}
+	return true, nil
+}
+
+// MatchPolicy returns the policy whose pattern matches the merchant name,
+// preferring the longest (most specific) pattern, or nil
+func MatchPolicy(policies []*repository.MerchantPolicy, merchantName string) *repository.MerchantPolicy {
+	name := strings.ToUpper(merchantName)
+	var best *repository.MerchantPolicy
+	for _, p := range policies {
+		pattern := strings.ToUpper(strings.TrimSpace(p.MerchantPattern))
+		if pattern == "" || !strings.Contains(name, pattern) {
+			continue
+		}
+		if best == nil || len(pattern) > len(strings.TrimSpace(best.MerchantPattern)) {
+			best = p
+		}
+	}
+	return best
+}
+
+// Deadlines returns the last day a purchase can be returned (nil when it can't
+// be) and the last day of its warranty cover (nil without a warranty)
+func Deadlines(purchasedAt time.Time, returnDays, warrantyMonths int) (returnBy, warrantyUntil *time.Time) {
+	if returnDays > 0 {
+		t := purchasedAt.AddDate(0, 0, returnDays)
+		returnBy = &t
+	}
+	if warrantyMonths > 0 {
+		t := calendar.AddMonthsClamped(purchasedAt, warrantyMonths).AddDate(0, 0, -1)
+		warrantyUntil = &t
+	}
+	return returnBy, warrantyUntil
+}
+
+// resolveTerms picks each term from the input, then the merchant policy, then
+// the defaults. Warranties are only assumed for purchases with a receipt.
+func resolveTerms(input TrackPurchaseInput, policy *repository.MerchantPolicy) (returnDays, warrantyMonths, reminderDays int) {
+	returnDays, reminderDays = defaultReturnDays, defaultReminderDays
+	if input.AttachmentKey != nil {
+		warrantyMonths = defaultWarrantyMonths
+	}
+	if policy != nil {
+		returnDays, reminderDays = policy.ReturnDays, policy.ReminderDays
+		if policy.WarrantyMonths != nil {
+			warrantyMonths = *policy.WarrantyMonths
+		}
+	}
+
+	if input.ReturnDays != nil {
+		returnDays = *input.ReturnDays
+	}
+	if input.WarrantyMonths != nil {
+		warrantyMonths = *input.WarrantyMonths
+	}
+	if input.ReminderDays != nil {
+		reminderDays = *input.ReminderDays
+	}
+	return returnDays, warrantyMonths, reminderDays
+}
+
+func newReminder(kind repository.ReminderKind, p *repository.Purchase, deadline, asOf time.Time) Reminder {
+	days := int(dateOf(deadline).Sub(dateOf(asOf)).Hours() / 24)
+	return Reminder{Kind: kind, Purchase: p, Deadline: deadline, DaysLeft: days}
+}
+
+// dateOf truncates t to its calendar date in UTC, matching DATE columns
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/repository"
+)
+
+// fakePurchaseRepository keeps purchases and policies in memory
+type fakePurchaseRepository struct {
+	repository.PurchaseRepository
+	purchases    map[uuid.UUID]*repository.Purchase
+	policies     []*repository.MerchantPolicy
+	transactions map[uuid.UUID]*repository.PurchaseTransaction
+}
+
+func newFakePurchaseRepository() *fakePurchaseRepository {
+	return &fakePurchaseRepository{
+		purchases:    make(map[uuid.UUID]*repository.Purchase),
+		transactions: make(map[uuid.UUID]*repository.PurchaseTransaction),
+	}
+}
+
+func (f *fakePurchaseRepository) CreatePurchase(_ context.Context, p *repository.Purchase) error {
+	p.ID = uuid.New()
+	f.purchases[p.ID] = p
+	return nil
+}
+
+func (f *fakePurchaseRepository) GetTransaction(_ context.Context, _, transactionID uuid.UUID) (*repository.PurchaseTransaction, error) {
+	t, ok := f.transactions[transactionID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+func (f *fakePurchaseRepository) ListPolicies(context.Context, uuid.UUID) ([]*repository.MerchantPolicy, error) {
+	return f.policies, nil
+}
+
+func (f *fakePurchaseRepository) ListDueReturnReminders(_ context.Context, asOf time.Time) ([]*repository.Purchase, error) {
+	var due []*repository.Purchase
+	for _, p := range f.purchases {
+		if p.ReturnBy != nil && p.ReturnRemindedAt == nil && !p.ReturnBy.Before(dateOf(asOf)) &&
+			!p.ReturnBy.After(dateOf(asOf).AddDate(0, 0, p.ReminderDays)) {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakePurchaseRepository) ListDueWarrantyReminders(_ context.Context, asOf time.Time, leadDays int) ([]*repository.Purchase, error) {
+	var due []*repository.Purchase
+	for _, p := range f.purchases {
+		if p.WarrantyUntil != nil && p.WarrantyRemindedAt == nil && !p.WarrantyUntil.Before(dateOf(asOf)) &&
+			!p.WarrantyUntil.After(dateOf(asOf).AddDate(0, 0, leadDays)) {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakePurchaseRepository) MarkReminded(_ context.Context, id uuid.UUID, kind repository.ReminderKind, at time.Time) error {
+	p := f.purchases[id]
+	field := &p.ReturnRemindedAt
+	if kind == repository.ReminderWarranty {
+		field = &p.WarrantyRemindedAt
+	}
+	if *field != nil {
+		return sql.ErrNoRows
+	}
+	*field = &at
+	return nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestMatchPolicy_PrefersLongestPattern(t *testing.T) {
+	generic := &repository.MerchantPolicy{MerchantPattern: "amazon", ReturnDays: 30}
+	specific := &repository.MerchantPolicy{MerchantPattern: "Amazon Warehouse", ReturnDays: 14}
+	policies := []*repository.MerchantPolicy{generic, specific}
+
+	assert.Same(t, specific, MatchPolicy(policies, "AMAZON WAREHOUSE DEALS"))
+	assert.Same(t, generic, MatchPolicy(policies, "Amazon.de Marketplace"))
+	assert.Nil(t, MatchPolicy(policies, "IKEA"))
+}
+
+func TestDeadlines(t *testing.T) {
+	purchased := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	returnBy, warrantyUntil := Deadlines(purchased, 30, 1)
+	require.NotNil(t, returnBy)
+	require.NotNil(t, warrantyUntil)
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), *returnBy)
+	assert.Equal(t, time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC), *warrantyUntil)
+
+	returnBy, warrantyUntil = Deadlines(purchased, 0, 0)
+	assert.Nil(t, returnBy)
+	assert.Nil(t, warrantyUntil)
+}
+
+func TestTrackPurchase_ResolvesTermsFromPolicyAndTransaction(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	repo := newFakePurchaseRepository()
+	repo.policies = []*repository.MerchantPolicy{
+		{MerchantPattern: "mediamarkt", ReturnDays: 14, WarrantyMonths: intPtr(36), ReminderDays: 5},
+	}
+	txID := uuid.New()
+	repo.transactions[txID] = &repository.PurchaseTransaction{
+		TransactionID: txID,
+		MerchantName:  "MediaMarkt Lisboa",
+		AmountMinor:   89900,
+		CurrencyCode:  "EUR",
+		PostedAt:      time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC),
+	}
+	svc := NewService(repo)
+
+	purchase, err := svc.TrackPurchase(ctx, userID, TrackPurchaseInput{TransactionID: &txID})
+	require.NoError(t, err)
+	assert.Equal(t, "MediaMarkt Lisboa", purchase.MerchantName)
+	assert.Equal(t, int64(89900), purchase.AmountMinor)
+	assert.Equal(t, 5, purchase.ReminderDays)
+	assert.Equal(t, time.Date(2025, 3, 24, 0, 0, 0, 0, time.UTC), *purchase.ReturnBy)
+	assert.Equal(t, time.Date(2028, 3, 9, 0, 0, 0, 0, time.UTC), *purchase.WarrantyUntil)
+
+	// Without a policy, the default window applies and a warranty is only
+	// assumed once a receipt is attached
+	purchased := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	plain, err := svc.TrackPurchase(ctx, userID, TrackPurchaseInput{MerchantName: "Corner Shop", AmountMinor: 2500, PurchasedAt: &purchased})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), *plain.ReturnBy)
+	assert.Nil(t, plain.WarrantyUntil)
+
+	receipt := "receipts/kettle.pdf"
+	withReceipt, err := svc.TrackPurchase(ctx, userID, TrackPurchaseInput{MerchantName: "Corner Shop", AmountMinor: 2500, PurchasedAt: &purchased, AttachmentKey: &receipt})
+	require.NoError(t, err)
+	require.NotNil(t, withReceipt.WarrantyUntil)
+	assert.Equal(t, time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC), *withReceipt.WarrantyUntil)
+
+	_, err = svc.TrackPurchase(ctx, userID, TrackPurchaseInput{MerchantName: " ", AmountMinor: 100})
+	assert.ErrorIs(t, err, ErrInvalidPurchase)
+}
+
+func TestDueReminders_RemindsOncePerDeadline(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakePurchaseRepository()
+	svc := NewService(repo)
+
+	purchased := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.TrackPurchase(ctx, uuid.New(), TrackPurchaseInput{MerchantName: "Zara", AmountMinor: 4999, PurchasedAt: &purchased})
+	require.NoError(t, err)
+
+	// Return window closes May 31 and is reminded about 3 days before
+	asOf := time.Date(2025, 5, 27, 9, 0, 0, 0, time.UTC)
+	reminders, err := svc.DueReminders(ctx, asOf)
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+
+	asOf = asOf.AddDate(0, 0, 1)
+	reminders, err = svc.DueReminders(ctx, asOf)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, repository.ReminderReturnWindow, reminders[0].Kind)
+	assert.Equal(t, 3, reminders[0].DaysLeft)
+
+	claimed, err := svc.MarkReminded(ctx, reminders[0], asOf)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = svc.MarkReminded(ctx, reminders[0], asOf)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	reminders, err = svc.DueReminders(ctx, asOf.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+}
+
+func TestSetMerchantPolicy_Validates(t *testing.T) {
+	svc := NewService(newFakePurchaseRepository())
+	for name, tc := range map[string]struct {
+		pattern        string
+		returnDays     int
+		warrantyMonths *int
+		reminderDays   int
+	}{
+		"empty pattern":     {" ", 30, nil, 3},
+		"negative window":   {"ikea", -1, nil, 3},
+		"zero warranty":     {"ikea", 30, intPtr(0), 3},
+		"too many reminder": {"ikea", 30, nil, 61},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.SetMerchantPolicy(context.Background(), uuid.New(), tc.pattern, tc.returnDays, tc.warrantyMonths, tc.reminderDays)
+			assert.ErrorIs(t, err, ErrInvalidPolicy)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresUsageRepository implements UsageRepository using PostgreSQL
+type PostgresUsageRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUsageRepository creates a new PostgreSQL usage repository
+func NewPostgresUsageRepository(pool *pgxpool.Pool) *PostgresUsageRepository {
+	return &PostgresUsageRepository{pool: pool}
+}
+
+// GetCounts counts a user's transactions and plans. Only the user's own plans
+// count, not those shared with them by their household.
+func (r *PostgresUsageRepository) GetCounts(ctx context.Context, userID uuid.UUID) (*Counts, error) {
+	c := &Counts{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND deleted_at IS NULL),
+			(SELECT COUNT(*) FROM user_plans WHERE user_id = $1 AND status <> 'archived' AND deleted_at IS NULL)
+	`, userID).Scan(&c.Transactions, &c.Plans)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage counts: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,19 @@
+// Package repository provides database access for per-user usage counts.
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Counts are the records a user keeps that count against their quotas
+type Counts struct {
+	Transactions int64 // Not in the trash
+	Plans        int   // Neither archived nor in the trash
+}
+
+// UsageRepository defines data access for per-user usage
+type UsageRepository interface {
+	GetCounts(ctx context.Context, userID uuid.UUID) (*Counts, error)
+}
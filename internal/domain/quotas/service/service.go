@@ -0,0 +1,145 @@
+// Package service enforces per-user data quotas and reports usage against them.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/repository"
+)
+
+// =============================================================================
+// Usage Quotas (Internal Integration)
+// =============================================================================
+// Each user has quotas on stored file bytes, transactions and plans that
+// depend on their tier. Storage is checked by the import service on upload;
+// transactions are checked before imports, manual entries and offline pushes
+// add them, and plans before one is created, duplicated or imported. A check
+// that fails returns ErrQuotaExceeded, which handlers answer with
+// ResourceExhausted.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetUsageRequest/Response (UserService.GetUsage) with Usage and its
+//   StorageUsage
+
+// Tier decides a user's quotas
+type Tier string
+
+const (
+	TierFree    Tier = "free"
+	TierPremium Tier = "premium"
+)
+
+// Default limits of free users; paying users are unlimited by default
+const (
+	DefaultFreeMaxTransactions int64 = 50000
+	DefaultFreeMaxPlans              = 10
+)
+
+// ErrQuotaExceeded is returned when a change would put a user over a quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Limits are the quotas of a tier. Zero is unlimited.
+type Limits struct {
+	MaxTransactions int64
+	MaxPlans        int
+}
+
+// TierLimits are the limits per tier. Tiers without limits are unlimited.
+type TierLimits map[Tier]Limits
+
+// DefaultTierLimits limit free users only
+var DefaultTierLimits = TierLimits{
+	TierFree: {MaxTransactions: DefaultFreeMaxTransactions, MaxPlans: DefaultFreeMaxPlans},
+}
+
+// StorageUsage is a user's stored bytes against their storage quota
+type StorageUsage struct {
+	Tier       Tier
+	UsedBytes  int64
+	QuotaBytes int64
+}
+
+// StorageMeter measures a user's file storage and decides their tier (the
+// import service)
+type StorageMeter interface {
+	GetStorageUsage(ctx context.Context, userID uuid.UUID) (*StorageUsage, error)
+}
+
+// Usage is what a user keeps against their quotas
+type Usage struct {
+	Tier            Tier
+	Storage         *StorageUsage // nil without file storage
+	Transactions    int64
+	MaxTransactions int64 // 0 = unlimited
+	Plans           int
+	MaxPlans        int // 0 = unlimited
+}
+
+// Service checks and reports usage quotas
+type Service struct {
+	repo    repository.UsageRepository
+	storage StorageMeter
+	limits  TierLimits
+}
+
+// NewService creates a new quota service using DefaultTierLimits. Without a
+// storage meter every user is on the free tier.
+func NewService(repo repository.UsageRepository, storage StorageMeter) *Service {
+	return &Service{repo: repo, storage: storage, limits: DefaultTierLimits}
+}
+
+// WithTierLimits replaces the limits per tier
+func (s *Service) WithTierLimits(limits TierLimits) *Service {
+	s.limits = limits
+	return s
+}
+
+// GetUsage returns what the user keeps against their tier's quotas
+func (s *Service) GetUsage(ctx context.Context, userID uuid.UUID) (*Usage, error) {
+	usage := &Usage{Tier: TierFree}
+	if s.storage != nil {
+		storage, err := s.storage.GetStorageUsage(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		usage.Tier, usage.Storage = storage.Tier, storage
+	}
+
+	counts, err := s.repo.GetCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	limits := s.limits[usage.Tier]
+	usage.Transactions, usage.MaxTransactions = counts.Transactions, limits.MaxTransactions
+	usage.Plans, usage.MaxPlans = counts.Plans, limits.MaxPlans
+	return usage, nil
+}
+
+// CheckTransactions returns ErrQuotaExceeded if adding more transactions
+// would put the user over their quota
+func (s *Service) CheckTransactions(ctx context.Context, userID uuid.UUID, adding int) error {
+	usage, err := s.GetUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if usage.MaxTransactions > 0 && usage.Transactions+int64(adding) > usage.MaxTransactions {
+		return fmt.Errorf("%w: %d of %d transactions used", ErrQuotaExceeded, usage.Transactions, usage.MaxTransactions)
+	}
+	return nil
+}
+
+// CheckPlans returns ErrQuotaExceeded if the user can't create another plan
+func (s *Service) CheckPlans(ctx context.Context, userID uuid.UUID) error {
+	usage, err := s.GetUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if usage.MaxPlans > 0 && usage.Plans >= usage.MaxPlans {
+		return fmt.Errorf("%w: %d of %d plans used", ErrQuotaExceeded, usage.Plans, usage.MaxPlans)
+	}
+	return nil
+}
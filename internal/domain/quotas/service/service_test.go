@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/quotas/repository"
+)
+
+// fakeUsageRepository returns fixed counts.
+type fakeUsageRepository struct {
+	counts repository.Counts
+}
+
+func (f *fakeUsageRepository) GetCounts(ctx context.Context, userID uuid.UUID) (*repository.Counts, error) {
+	counts := f.counts
+	return &counts, nil
+}
+
+// fakeStorageMeter puts every user on one tier.
+type fakeStorageMeter struct {
+	tier Tier
+}
+
+func (f *fakeStorageMeter) GetStorageUsage(ctx context.Context, userID uuid.UUID) (*StorageUsage, error) {
+	return &StorageUsage{Tier: f.tier, UsedBytes: 1 << 20, QuotaBytes: 250 << 20}, nil
+}
+
+func TestGetUsage(t *testing.T) {
+	repo := &fakeUsageRepository{counts: repository.Counts{Transactions: 1200, Plans: 3}}
+	svc := NewService(repo, &fakeStorageMeter{tier: TierFree})
+
+	usage, err := svc.GetUsage(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, TierFree, usage.Tier)
+	assert.Equal(t, int64(1200), usage.Transactions)
+	assert.Equal(t, DefaultFreeMaxTransactions, usage.MaxTransactions)
+	assert.Equal(t, 3, usage.Plans)
+	assert.Equal(t, DefaultFreeMaxPlans, usage.MaxPlans)
+	require.NotNil(t, usage.Storage)
+	assert.Equal(t, int64(1<<20), usage.Storage.UsedBytes)
+
+	usage, err = NewService(repo, nil).GetUsage(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, TierFree, usage.Tier, "without a storage meter everyone is free")
+	assert.Nil(t, usage.Storage)
+}
+
+func TestCheckTransactions(t *testing.T) {
+	repo := &fakeUsageRepository{counts: repository.Counts{Transactions: 95}}
+	svc := NewService(repo, &fakeStorageMeter{tier: TierFree}).
+		WithTierLimits(TierLimits{TierFree: {MaxTransactions: 100}})
+	ctx := context.Background()
+
+	assert.NoError(t, svc.CheckTransactions(ctx, uuid.New(), 5))
+	err := svc.CheckTransactions(ctx, uuid.New(), 6)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Contains(t, err.Error(), "95 of 100")
+
+	premium := NewService(repo, &fakeStorageMeter{tier: TierPremium}).
+		WithTierLimits(TierLimits{TierFree: {MaxTransactions: 100}})
+	assert.NoError(t, premium.CheckTransactions(ctx, uuid.New(), 1000), "tiers without limits are unlimited")
+}
+
+func TestCheckPlans(t *testing.T) {
+	repo := &fakeUsageRepository{counts: repository.Counts{Plans: 2}}
+	svc := NewService(repo, &fakeStorageMeter{tier: TierFree}).
+		WithTierLimits(TierLimits{TierFree: {MaxPlans: 3}, TierPremium: {MaxPlans: 0}})
+	ctx := context.Background()
+
+	assert.NoError(t, svc.CheckPlans(ctx, uuid.New()))
+
+	repo.counts.Plans = 3
+	assert.ErrorIs(t, svc.CheckPlans(ctx, uuid.New()), ErrQuotaExceeded)
+
+	premium := NewService(repo, &fakeStorageMeter{tier: TierPremium}).
+		WithTierLimits(TierLimits{TierFree: {MaxPlans: 3}, TierPremium: {MaxPlans: 0}})
+	assert.NoError(t, premium.CheckPlans(ctx, uuid.New()), "zero is unlimited")
+}
@@ -0,0 +1,66 @@
+// Package handler serves exported report downloads over HTTP.
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/reports/service"
+)
+
+// DownloadHandler serves exported reports to whoever holds a download token
+type DownloadHandler struct {
+	svc    *service.Service
+	logger *slog.Logger
+}
+
+// NewDownloadHandler creates a new report download handler
+func NewDownloadHandler(svc *service.Service, logger *slog.Logger) *DownloadHandler {
+	return &DownloadHandler{svc: svc, logger: logger}
+}
+
+// ServeHTTP answers GET DownloadPath<token> with the exported file as an
+// attachment, or inline for share images
+func (h *DownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	token := strings.TrimPrefix(r.URL.Path, service.DownloadPath)
+	file, info, err := h.svc.OpenDownload(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDownload) {
+			http.Error(w, "This download link is invalid or has expired.", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to open report download", slog.Any("error", err))
+		http.Error(w, "Failed to download report.", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	// Share images open in the browser so they can be posted straight away
+	disposition := "attachment"
+	if info.ContentType == service.ReportFormatPNG.ContentType() {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": info.Name}))
+	if info.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.Warn("failed to send report download", slog.Any("error", err))
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+const (
+	pdfFont       = "Helvetica"
+	pdfLineHeight = 6.0
+)
+
+// renderPDF writes doc as an A4 PDF: title, then each section's heading,
+// paragraphs and table, flowing onto new pages as needed
+func renderPDF(w io.Writer, doc *Document) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	// Core fonts are cp1252; translate so currency symbols and accents survive
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := pageWidth - left - right
+
+	pdf.SetFont(pdfFont, "B", 18)
+	pdf.MultiCell(width, 9, tr(doc.Title), "", "L", false)
+	if doc.Subtitle != "" {
+		pdf.SetFont(pdfFont, "", 11)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.MultiCell(width, pdfLineHeight, tr(doc.Subtitle), "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	for _, section := range doc.Sections {
+		pdf.Ln(pdfLineHeight)
+		if section.Heading != "" {
+			pdf.SetFont(pdfFont, "B", 13)
+			pdf.MultiCell(width, 8, tr(section.Heading), "", "L", false)
+		}
+		pdf.SetFont(pdfFont, "", 10)
+		for _, p := range section.Paragraphs {
+			pdf.MultiCell(width, pdfLineHeight, tr(p), "", "L", false)
+		}
+		if section.Table != nil && len(section.Table.Columns) > 0 {
+			writePDFTable(pdf, tr, width, section.Table, doc.CurrencyCode)
+		}
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render pdf: %w", err)
+	}
+	return nil
+}
+
+// writePDFTable writes a table with a shaded header. The first column takes
+// the remaining width so names have room; the others share a fixed width.
+func writePDFTable(pdf *gofpdf.Fpdf, tr func(string) string, width float64, table *Table, currency string) {
+	cols := len(table.Columns)
+	colWidth := width / float64(cols)
+	if cols > 1 {
+		colWidth = min(width*0.6/float64(cols-1), 32)
+	}
+	firstWidth := width - colWidth*float64(cols-1)
+	widthOf := func(i int) float64 {
+		if i == 0 {
+			return firstWidth
+		}
+		return colWidth
+	}
+
+	pdf.SetFont(pdfFont, "B", 9)
+	pdf.SetFillColor(230, 230, 230)
+	for i, c := range table.Columns {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widthOf(i), 7, tr(c), "B", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(pdfFont, "", 9)
+	for _, row := range table.Rows {
+		for i := 0; i < cols; i++ {
+			var cell Cell
+			if i < len(row) {
+				cell = row[i]
+			}
+			text, align := cell.Text, "L"
+			switch {
+			case cell.Minor != nil:
+				text, align = money.New(*cell.Minor, currency).Display(), "R"
+			case cell.Percent != nil:
+				text, align = strconv.FormatFloat(*cell.Percent, 'f', 1, 64)+"%", "R"
+			}
+			pdf.CellFormat(widthOf(i), pdfLineHeight, tr(text), "", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
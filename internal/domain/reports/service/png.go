@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// shareTemplate lays out share images: a portrait card sized for social feeds
+// with the document's title on top, the section in the middle and a footer
+type shareTemplate struct {
+	Width, Height int
+	Margin        int
+	Background    color.RGBA
+	Text          color.RGBA
+	Muted         color.RGBA
+	Accent        color.RGBA // Used when the section has no accent
+	BandHeight    int        // Accent band across the top
+
+	TitleSize, HeadingSize, BodySize, FooterSize float64
+	Footer                                       string
+}
+
+// wrappedShareTemplate is the template of wrapped share images
+var wrappedShareTemplate = shareTemplate{
+	Width:       1080,
+	Height:      1350,
+	Margin:      96,
+	Background:  color.RGBA{0x0F, 0x17, 0x2A, 0xFF},
+	Text:        color.RGBA{0xF8, 0xFA, 0xFC, 0xFF},
+	Muted:       color.RGBA{0x94, 0xA3, 0xB8, 0xFF},
+	Accent:      color.RGBA{0x63, 0x66, 0xF1, 0xFF}, // Echo indigo
+	BandHeight:  24,
+	TitleSize:   40,
+	HeadingSize: 72,
+	BodySize:    48,
+	FooterSize:  32,
+	Footer:      "echo",
+}
+
+var shareBoldFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(gobold.TTF)
+})
+
+var shareRegularFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// renderSharePNG writes one section of doc as a PNG share image
+func renderSharePNG(w io.Writer, doc *Document, section Section, tpl shareTemplate) error {
+	bold, err := shareBoldFont()
+	if err != nil {
+		return fmt.Errorf("failed to load font: %w", err)
+	}
+	regular, err := shareRegularFont()
+	if err != nil {
+		return fmt.Errorf("failed to load font: %w", err)
+	}
+	face := func(f *opentype.Font, size float64) (font.Face, error) {
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	}
+	titleFace, err := face(regular, tpl.TitleSize)
+	if err != nil {
+		return err
+	}
+	headingFace, err := face(bold, tpl.HeadingSize)
+	if err != nil {
+		return err
+	}
+	bodyFace, err := face(regular, tpl.BodySize)
+	if err != nil {
+		return err
+	}
+	footerFace, err := face(bold, tpl.FooterSize)
+	if err != nil {
+		return err
+	}
+
+	accent := tpl.Accent
+	if c, ok := parseHexColor(section.Accent); ok {
+		accent = c
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, tpl.Width, tpl.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(tpl.Background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, tpl.Width, tpl.BandHeight), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	width := tpl.Width - 2*tpl.Margin
+	y := tpl.BandHeight + tpl.Margin
+	y = drawLines(img, titleFace, tpl.Muted, tpl.Margin, y, wrapText(titleFace, doc.Title, width))
+	if doc.Subtitle != "" {
+		y = drawLines(img, titleFace, accent, tpl.Margin, y, wrapText(titleFace, doc.Subtitle, width))
+	}
+
+	// The section sits a third of the way down, below the title
+	y = max(y+tpl.Margin, tpl.Height/3)
+	y = drawLines(img, headingFace, accent, tpl.Margin, y, wrapText(headingFace, section.Heading, width))
+	y += tpl.Margin / 3
+	for _, p := range section.Paragraphs {
+		y = drawLines(img, bodyFace, tpl.Text, tpl.Margin, y, wrapText(bodyFace, p, width))
+		y += tpl.Margin / 4
+	}
+
+	footerY := tpl.Height - tpl.Margin - footerFace.Metrics().Height.Ceil()
+	drawLines(img, footerFace, tpl.Muted, tpl.Margin, footerY, []string{tpl.Footer})
+
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("failed to render png: %w", err)
+	}
+	return nil
+}
+
+// drawLines draws lines top-down from y and returns the y below the last one
+func drawLines(img draw.Image, face font.Face, c color.Color, x, y int, lines []string) int {
+	metrics := face.Metrics()
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face}
+	for _, line := range lines {
+		d.Dot = fixed.P(x, y+metrics.Ascent.Ceil())
+		d.DrawString(line)
+		y += metrics.Height.Ceil()
+	}
+	return y
+}
+
+// wrapText breaks s into lines no wider than width, splitting on spaces.
+// A single word wider than width gets a line of its own.
+func wrapText(face font.Face, s string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.MeasureString(face, candidate).Ceil() > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseHexColor parses "#RRGGBB"
+func parseHexColor(s string) (color.RGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, true
+}
@@ -0,0 +1,320 @@
+// Package service renders reports — monthly insights, budget reports and
+// wrapped summaries — to PDF and XLSX files that users download and share,
+// e.g. with an accountant. It also exports transactions to CSV and XLSX.
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// =============================================================================
+// Report Export (Internal Integration)
+// =============================================================================
+// Exports are rendered once, uploaded to file storage and downloaded over plain
+// HTTP at DownloadPath with a signed, expiring token. Exported files aren't
+// tracked as user uploads, so storage maintenance removes them once the orphan
+// grace period has passed; links don't outlive DownloadTTL anyway.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ExportReportRequest/Response (ReportsService.ExportReport) with
+//   ReportKind, ReportFormat, month_start (monthly insights), as_of (budget
+//   report) and period/period_start/period_end (wrapped)
+// - ExportedReport (file_id, filename, content_type, size_bytes,
+//   download_url, expires_at)
+
+// DownloadPath is the public route exports are downloaded at; the token is appended
+const DownloadPath = "/reports/download/"
+
+// DownloadTTL is how long an export's download URL stays valid
+const DownloadTTL = 24 * time.Hour
+
+var (
+	// ErrInvalidReportKind is returned for an unknown kind or a kind without its period
+	ErrInvalidReportKind = errors.New("export monthly insights, a budget report or a wrapped summary")
+	// ErrInvalidReportFormat is returned for formats other than PDF and XLSX
+	ErrInvalidReportFormat = errors.New("reports export to pdf or xlsx")
+	// ErrReportNotFound is returned when there is nothing to report on, such as no active plan
+	ErrReportNotFound = errors.New("nothing to export: report not found")
+	// ErrInvalidDownload is returned for a tampered, expired or unknown download token
+	ErrInvalidDownload = errors.New("download link is invalid or has expired")
+)
+
+// ReportKind is what a report covers
+type ReportKind string
+
+const (
+	ReportKindMonthlyInsights ReportKind = "monthly_insights"
+	ReportKindBudget          ReportKind = "budget"
+	ReportKindWrapped         ReportKind = "wrapped"
+)
+
+// ReportFormat is the file format a report is rendered to
+type ReportFormat string
+
+const (
+	ReportFormatPDF  ReportFormat = "pdf"
+	ReportFormatXLSX ReportFormat = "xlsx"
+	ReportFormatCSV  ReportFormat = "csv" // Transactions only
+	ReportFormatPNG  ReportFormat = "png" // Wrapped share images only
+)
+
+// ContentType returns the MIME type of files in the format
+func (f ReportFormat) ContentType() string {
+	switch f {
+	case ReportFormatPDF:
+		return "application/pdf"
+	case ReportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ReportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ReportFormatPNG:
+		return "image/png"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Document is a report laid out independently of its file format
+type Document struct {
+	Title        string
+	Subtitle     string
+	CurrencyCode string // Currency of the document's Amount cells
+	Sections     []Section
+}
+
+// Section is a titled part of a document: paragraphs, then an optional table
+type Section struct {
+	Heading    string
+	Paragraphs []string
+	Table      *Table
+	Accent     string // Optional "#RRGGBB"; only share images use it
+}
+
+// Table is a grid of cells under a header row
+type Table struct {
+	Columns []string
+	Rows    [][]Cell
+}
+
+// Cell is a table cell. Amounts and percentages stay numeric in spreadsheets.
+type Cell struct {
+	Text    string
+	Minor   *int64   // Amount in minor units of the document's currency
+	Percent *float64 // Percentage, e.g. 12.5 for 12.5%
+}
+
+// Text returns a text cell
+func Text(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Amount returns a cell holding an amount in minor units
+func Amount(minor int64) Cell {
+	return Cell{Minor: &minor}
+}
+
+// Percent returns a cell holding a percentage
+func Percent(p float64) Cell {
+	return Cell{Percent: &p}
+}
+
+// ReportSource lays out the reports that can be exported. Methods return nil
+// when there is nothing to report on.
+type ReportSource interface {
+	// MonthlyInsightsDocument lays out the user's insights for the month starting at monthStart
+	MonthlyInsightsDocument(ctx context.Context, userID uuid.UUID, monthStart time.Time) (*Document, error)
+	// BudgetReportDocument lays out the user's active plan for the budget period containing asOf
+	BudgetReportDocument(ctx context.Context, userID uuid.UUID, asOf time.Time) (*Document, error)
+	// WrappedDocument lays out the user's wrapped summary for period ("month" or "year")
+	WrappedDocument(ctx context.Context, userID uuid.UUID, period string, start, end time.Time) (*Document, error)
+}
+
+// ExportReportInput describes a report to export
+type ExportReportInput struct {
+	Kind   ReportKind
+	Format ReportFormat
+
+	MonthStart *time.Time // For monthly insights; any day in the month
+	AsOf       *time.Time // For budget reports; defaults to now
+
+	// For wrapped summaries
+	Period      string // "month" or "year"
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+}
+
+// ExportedReport is a rendered report in file storage
+type ExportedReport struct {
+	FileID      uuid.UUID
+	Filename    string
+	ContentType string
+	SizeBytes   int64
+	DownloadURL string
+	ExpiresAt   time.Time
+}
+
+// Service provides report export business logic
+type Service struct {
+	source       ReportSource
+	transactions TransactionLister
+	storage      storage.Storage
+	secret       []byte
+	baseURL      string
+	now          func() time.Time
+}
+
+// NewService creates a new report export service. Download tokens are signed
+// with secret and download URLs start with baseURL.
+func NewService(source ReportSource, fileStorage storage.Storage, secret []byte, baseURL string) *Service {
+	return &Service{
+		source:  source,
+		storage: fileStorage,
+		secret:  secret,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		now:     time.Now,
+	}
+}
+
+// ExportReport renders a report, stores the file and returns a download URL for it
+func (s *Service) ExportReport(ctx context.Context, userID uuid.UUID, input ExportReportInput) (*ExportedReport, error) {
+	if input.Format != ReportFormatPDF && input.Format != ReportFormatXLSX {
+		return nil, ErrInvalidReportFormat
+	}
+
+	now := s.now()
+	var (
+		doc  *Document
+		name string
+		err  error
+	)
+	switch {
+	case input.Kind == ReportKindMonthlyInsights && input.MonthStart != nil:
+		year, month, _ := input.MonthStart.Date()
+		monthStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		doc, err = s.source.MonthlyInsightsDocument(ctx, userID, monthStart)
+		name = "monthly-insights-" + monthStart.Format("2006-01")
+	case input.Kind == ReportKindBudget:
+		asOf := now
+		if input.AsOf != nil {
+			asOf = *input.AsOf
+		}
+		doc, err = s.source.BudgetReportDocument(ctx, userID, asOf)
+		name = "budget-report-" + asOf.Format("2006-01-02")
+	case input.Kind == ReportKindWrapped && input.PeriodStart != nil && input.PeriodEnd != nil:
+		period := input.Period
+		if period != "year" {
+			period = "month"
+		}
+		doc, err = s.source.WrappedDocument(ctx, userID, period, *input.PeriodStart, *input.PeriodEnd)
+		name = "wrapped-" + input.PeriodStart.Format("2006-01-02")
+	default:
+		return nil, ErrInvalidReportKind
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s report: %w", input.Kind, err)
+	}
+	if doc == nil {
+		return nil, ErrReportNotFound
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, doc, input.Format); err != nil {
+		return nil, err
+	}
+
+	filename := name + "." + string(input.Format)
+	contentType := input.Format.ContentType()
+	info, err := s.storage.Upload(ctx, userID, filename, contentType, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+
+	expiresAt := now.Add(DownloadTTL).Truncate(time.Second)
+	return &ExportedReport{
+		FileID:      info.ID,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   info.Size,
+		DownloadURL: s.baseURL + DownloadPath + s.token(userID, info.ID, expiresAt),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// Render writes doc to w in the given format
+func Render(w io.Writer, doc *Document, format ReportFormat) error {
+	switch format {
+	case ReportFormatPDF:
+		return renderPDF(w, doc)
+	case ReportFormatXLSX:
+		return renderXLSX(w, doc)
+	default:
+		return ErrInvalidReportFormat
+	}
+}
+
+// OpenDownload returns the exported file behind a download token. It fails
+// with ErrInvalidDownload unless the token is authentic and unexpired and the
+// file still exists. The caller must close the returned reader.
+func (s *Service) OpenDownload(ctx context.Context, token string) (io.ReadCloser, *storage.FileInfo, error) {
+	userID, fileID, err := s.verifyToken(token, s.now())
+	if err != nil {
+		return nil, nil, err
+	}
+	r, info, err := s.storage.Download(ctx, userID, fileID)
+	if err != nil {
+		return nil, nil, ErrInvalidDownload
+	}
+	return r, info, nil
+}
+
+// token returns the signed download token for a stored file
+func (s *Service) token(userID, fileID uuid.UUID, expiresAt time.Time) string {
+	payload := userID.String() + "." + fileID.String() + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.signature(payload)
+}
+
+// verifyToken returns the owner and file a token was issued for
+func (s *Service) verifyToken(token string, now time.Time) (uuid.UUID, uuid.UUID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return uuid.Nil, uuid.Nil, ErrInvalidDownload
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.signature(payload))) {
+		return uuid.Nil, uuid.Nil, ErrInvalidDownload
+	}
+
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return uuid.Nil, uuid.Nil, ErrInvalidDownload
+	}
+	userID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidDownload
+	}
+	fileID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidDownload
+	}
+	return userID, fileID, nil
+}
+
+func (s *Service) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("report-download:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
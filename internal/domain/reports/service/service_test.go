@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/storage"
+)
+
+// fakeReportSource returns a fixed budget report and nothing else
+type fakeReportSource struct{}
+
+func (fakeReportSource) MonthlyInsightsDocument(context.Context, uuid.UUID, time.Time) (*Document, error) {
+	return nil, nil
+}
+
+func (fakeReportSource) BudgetReportDocument(context.Context, uuid.UUID, time.Time) (*Document, error) {
+	return &Document{
+		Title:        "Budget report · Household",
+		Subtitle:     "1 Jun 2025 – 30 Jun 2025",
+		CurrencyCode: "EUR",
+		Sections: []Section{{
+			Heading: "Budget vs. actual",
+			Table: &Table{
+				Columns: []string{"Line", "Budgeted", "Actual", "Used"},
+				Rows: [][]Cell{
+					{Text("Groceries"), Amount(40000), Amount(30050), Percent(75.125)},
+					{Text("Total"), Amount(40000), Amount(30050), Percent(75.125)},
+				},
+			},
+		}},
+	}, nil
+}
+
+func (fakeReportSource) WrappedDocument(context.Context, uuid.UUID, string, time.Time, time.Time) (*Document, error) {
+	return nil, nil
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	return NewService(fakeReportSource{}, fileStorage, []byte("secret"), "https://app.example.com/")
+}
+
+func download(t *testing.T, svc *Service, report *ExportedReport) []byte {
+	t.Helper()
+	token := strings.TrimPrefix(report.DownloadURL, "https://app.example.com"+DownloadPath)
+	r, info, err := svc.OpenDownload(context.Background(), token)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	assert.Equal(t, report.ContentType, info.ContentType)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestExportReport_XLSXKeepsAmountsNumeric(t *testing.T) {
+	svc := newTestService(t)
+	userID := uuid.New()
+	asOf := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	report, err := svc.ExportReport(context.Background(), userID, ExportReportInput{
+		Kind: ReportKindBudget, Format: ReportFormatXLSX, AsOf: &asOf,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "budget-report-2025-06-15.xlsx", report.Filename)
+
+	f, err := excelize.OpenReader(bytes.NewReader(download(t, svc, report)))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rows, err := f.GetRows(xlsxSheet, excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	var groceries []string
+	for _, row := range rows {
+		if len(row) > 0 && row[0] == "Groceries" {
+			groceries = row
+		}
+	}
+	require.NotNil(t, groceries, "expected a Groceries row")
+	assert.Equal(t, []string{"Groceries", "400", "300.5", "0.75125"}, groceries)
+}
+
+func TestExportReport_PDF(t *testing.T) {
+	svc := newTestService(t)
+
+	report, err := svc.ExportReport(context.Background(), uuid.New(), ExportReportInput{
+		Kind: ReportKindBudget, Format: ReportFormatPDF,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", report.ContentType)
+	assert.True(t, bytes.HasPrefix(download(t, svc, report), []byte("%PDF-")))
+}
+
+func TestExportReport_RejectsInvalidInput(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	month := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.ExportReport(ctx, uuid.New(), ExportReportInput{Kind: ReportKindBudget, Format: "docx"})
+	assert.ErrorIs(t, err, ErrInvalidReportFormat)
+
+	_, err = svc.ExportReport(ctx, uuid.New(), ExportReportInput{Kind: ReportKindWrapped, Format: ReportFormatPDF})
+	assert.ErrorIs(t, err, ErrInvalidReportKind, "wrapped needs its period")
+
+	_, err = svc.ExportReport(ctx, uuid.New(), ExportReportInput{Kind: ReportKindMonthlyInsights, Format: ReportFormatPDF, MonthStart: &month})
+	assert.ErrorIs(t, err, ErrReportNotFound)
+}
+
+func TestOpenDownload_RejectsTamperedAndExpiredTokens(t *testing.T) {
+	svc := newTestService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	report, err := svc.ExportReport(ctx, userID, ExportReportInput{Kind: ReportKindBudget, Format: ReportFormatPDF})
+	require.NoError(t, err)
+	token := strings.TrimPrefix(report.DownloadURL, "https://app.example.com"+DownloadPath)
+
+	// Someone else's user ID with the same file and signature
+	forged := uuid.NewString() + token[len(userID.String()):]
+	_, _, err = svc.OpenDownload(ctx, forged)
+	assert.ErrorIs(t, err, ErrInvalidDownload)
+
+	svc.now = func() time.Time { return time.Now().Add(DownloadTTL + time.Minute) }
+	_, _, err = svc.OpenDownload(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidDownload)
+}
+
+// wrappedReportSource returns a two-card wrapped summary
+type wrappedReportSource struct{ fakeReportSource }
+
+func (wrappedReportSource) WrappedDocument(_ context.Context, _ uuid.UUID, period string, start, _ time.Time) (*Document, error) {
+	return &Document{
+		Title:    "Your " + period + ", wrapped",
+		Subtitle: start.Format("2006"),
+		Sections: []Section{
+			{Heading: "Top Merchant", Paragraphs: []string{"You visited 42 times", "Corner Café"}, Accent: "#6366F1"},
+			{Heading: "Biggest Change", Paragraphs: []string{"You spent 30% less on Dining out than the year before"}, Accent: "#22c55e"},
+		},
+	}, nil
+}
+
+func TestGetWrappedShareImages_OnePNGPerCard(t *testing.T) {
+	fileStorage, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	svc := NewService(wrappedReportSource{}, fileStorage, []byte("secret"), "https://app.example.com/")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	images, err := svc.GetWrappedShareImages(context.Background(), uuid.New(), "year", start, start.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	require.Len(t, images, 2)
+	assert.Equal(t, "wrapped-2025-01-01-1.png", images[0].Filename)
+	assert.Equal(t, "image/png", images[1].ContentType)
+
+	img, err := png.Decode(bytes.NewReader(download(t, svc, images[1])))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, wrappedShareTemplate.Width, wrappedShareTemplate.Height), img.Bounds())
+	// The accent band takes the card's colour
+	r, g, b, _ := img.At(wrappedShareTemplate.Width/2, 1).RGBA()
+	assert.Equal(t, [3]uint32{0x22, 0xc5, 0x5e}, [3]uint32{r >> 8, g >> 8, b >> 8})
+}
+
+func TestGetWrappedShareImages_NothingToShare(t *testing.T) {
+	svc := newTestService(t)
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := svc.GetWrappedShareImages(context.Background(), uuid.New(), "month", start, start.AddDate(0, 1, 0))
+	assert.ErrorIs(t, err, ErrReportNotFound)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// Wrapped Share Images (Internal Integration)
+// =============================================================================
+// Each card of a wrapped summary is rendered to a PNG from
+// wrappedShareTemplate, uploaded to file storage and shared through the same
+// signed, expiring links as exported reports, so users can post their
+// month or year in review.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - GetWrappedShareImagesRequest/Response (InsightsService.GetWrappedShareImages)
+//   with the GetWrappedRequest period fields, returning repeated
+//   WrappedShareImage
+// - WrappedShareImage (card_title, file_id, content_type, size_bytes, width,
+//   height, download_url, expires_at)
+
+// GetWrappedShareImages renders the user's wrapped summary for period ("month"
+// or "year") to one PNG per card, stores them and returns their download URLs
+// in card order
+func (s *Service) GetWrappedShareImages(ctx context.Context, userID uuid.UUID, period string, periodStart, periodEnd time.Time) ([]*ExportedReport, error) {
+	if period != "year" {
+		period = "month"
+	}
+	doc, err := s.source.WrappedDocument(ctx, userID, period, periodStart, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build wrapped summary: %w", err)
+	}
+	if doc == nil || len(doc.Sections) == 0 {
+		return nil, ErrReportNotFound
+	}
+
+	now := s.now()
+	expiresAt := now.Add(DownloadTTL).Truncate(time.Second)
+	contentType := ReportFormatPNG.ContentType()
+	images := make([]*ExportedReport, 0, len(doc.Sections))
+	for i, section := range doc.Sections {
+		var buf bytes.Buffer
+		if err := renderSharePNG(&buf, doc, section, wrappedShareTemplate); err != nil {
+			return nil, err
+		}
+
+		filename := "wrapped-" + periodStart.Format("2006-01-02") + "-" + strconv.Itoa(i+1) + ".png"
+		info, err := s.storage.Upload(ctx, userID, filename, contentType, &buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store share image: %w", err)
+		}
+		images = append(images, &ExportedReport{
+			FileID:      info.ID,
+			Filename:    filename,
+			ContentType: contentType,
+			SizeBytes:   info.Size,
+			DownloadURL: s.baseURL + DownloadPath + s.token(userID, info.ID, expiresAt),
+			ExpiresAt:   expiresAt,
+		})
+	}
+	return images, nil
+}
@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+// =============================================================================
+// Transactions Export (Internal Integration)
+// =============================================================================
+// Exports every transaction matching a ListTransactions filter to CSV or
+// XLSX. Transactions are read and written in chunks of transactionExportChunk
+// and streamed into file storage, so large exports never sit in memory; the
+// file is downloaded through the same signed links as reports.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ExportTransactionsRequest/Response (FinanceService.ExportTransactions)
+//   with the ListTransactionsRequest filters, format (csv or xlsx), columns
+//   and locale, returning an ExportedReport
+
+// transactionExportChunk is how many transactions are read per query, the
+// most ListTransactions returns at once
+const transactionExportChunk = 100
+
+var (
+	// ErrInvalidTransactionFormat is returned for formats other than CSV and XLSX
+	ErrInvalidTransactionFormat = errors.New("transactions export to csv or xlsx")
+	// ErrInvalidTransactionColumn is returned for an unknown or repeated column
+	ErrInvalidTransactionColumn = errors.New("unknown or repeated transaction export column")
+)
+
+// TransactionColumn is a column of a transactions export
+type TransactionColumn string
+
+const (
+	TransactionColumnDate        TransactionColumn = "date"
+	TransactionColumnDescription TransactionColumn = "description"
+	TransactionColumnMerchant    TransactionColumn = "merchant"
+	TransactionColumnCategory    TransactionColumn = "category"
+	TransactionColumnAmount      TransactionColumn = "amount"
+	TransactionColumnCurrency    TransactionColumn = "currency"
+	TransactionColumnNotes       TransactionColumn = "notes"
+	TransactionColumnInstitution TransactionColumn = "institution"
+	TransactionColumnSource      TransactionColumn = "source"
+	TransactionColumnAccountID   TransactionColumn = "account_id"
+	TransactionColumnID          TransactionColumn = "id"
+)
+
+// transactionColumnHeaders are the header row labels of each column
+var transactionColumnHeaders = map[TransactionColumn]string{
+	TransactionColumnDate:        "Date",
+	TransactionColumnDescription: "Description",
+	TransactionColumnMerchant:    "Merchant",
+	TransactionColumnCategory:    "Category",
+	TransactionColumnAmount:      "Amount",
+	TransactionColumnCurrency:    "Currency",
+	TransactionColumnNotes:       "Notes",
+	TransactionColumnInstitution: "Institution",
+	TransactionColumnSource:      "Source",
+	TransactionColumnAccountID:   "Account ID",
+	TransactionColumnID:          "Transaction ID",
+}
+
+// DefaultTransactionColumns is the layout used when none is chosen
+var DefaultTransactionColumns = []TransactionColumn{
+	TransactionColumnDate,
+	TransactionColumnDescription,
+	TransactionColumnCategory,
+	TransactionColumnAmount,
+	TransactionColumnCurrency,
+}
+
+// ExportLocale holds the number and date conventions of an export. CSV
+// files are written in them; XLSX files keep numbers and dates typed and only
+// take the date display format, as spreadsheets localize numbers themselves.
+type ExportLocale struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	DateLayout         string // Go layout for CSV dates
+	XLSXDateFormat     string // Spreadsheet number format for XLSX dates
+	CSVDelimiter       rune   // Semicolon where the comma is the decimal separator
+}
+
+// exportLocales are keyed by language, or by locale where countries differ
+var exportLocales = map[string]ExportLocale{
+	"en":    {".", ",", "2006-01-02", "yyyy-mm-dd", ','},
+	"en-us": {".", ",", "01/02/2006", "mm/dd/yyyy", ','},
+	"en-gb": {".", ",", "02/01/2006", "dd/mm/yyyy", ','},
+	"pt":    {",", ".", "02/01/2006", "dd/mm/yyyy", ';'},
+	"es":    {",", ".", "02/01/2006", "dd/mm/yyyy", ';'},
+	"it":    {",", ".", "02/01/2006", "dd/mm/yyyy", ';'},
+	"fr":    {",", " ", "02/01/2006", "dd/mm/yyyy", ';'},
+	"de":    {",", ".", "02.01.2006", "dd.mm.yyyy", ';'},
+	"nl":    {",", ".", "02-01-2006", "dd-mm-yyyy", ';'},
+}
+
+// LookupExportLocale returns the conventions for a locale such as "pt-BR" or
+// "de_DE", falling back to its language and then to English with ISO dates
+func LookupExportLocale(locale string) ExportLocale {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if l, ok := exportLocales[locale]; ok {
+		return l
+	}
+	if i := strings.IndexByte(locale, '-'); i >= 0 {
+		if l, ok := exportLocales[locale[:i]]; ok {
+			return l
+		}
+	}
+	return exportLocales["en"]
+}
+
+// FormatAmount writes an amount in minor units with the currency's decimal
+// places and the locale's separators, e.g. "-1.234,50"
+func (l ExportLocale) FormatAmount(minor int64, currencyCode string) string {
+	m := money.New(minor, currencyCode)
+	fixed := m.ToDecimal().Abs().StringFixed(int32(m.Fraction()))
+
+	whole, frac, _ := strings.Cut(fixed, ".")
+	var b strings.Builder
+	if minor < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.ThousandsSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if frac != "" {
+		b.WriteString(l.DecimalSeparator)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// TransactionLister pages through a user's transactions; the import
+// repository implements it
+type TransactionLister interface {
+	ListTransactions(ctx context.Context, userID uuid.UUID, filter importrepo.ListTransactionsFilter) ([]*importrepo.Transaction, int64, error)
+}
+
+// ExportTransactionsInput describes a transactions export
+type ExportTransactionsInput struct {
+	Filter  importrepo.ListTransactionsFilter // Limit and Offset are ignored
+	Format  ReportFormat                      // CSV or XLSX
+	Columns []TransactionColumn               // Defaults to DefaultTransactionColumns
+	Locale  string                            // e.g. "pt-PT"; defaults to English with ISO dates
+}
+
+// WithTransactions enables transaction exports
+func (s *Service) WithTransactions(transactions TransactionLister) *Service {
+	s.transactions = transactions
+	return s
+}
+
+// ExportTransactions writes the user's transactions matching the filter to a
+// file, newest first, stores it and returns a download URL for it
+func (s *Service) ExportTransactions(ctx context.Context, userID uuid.UUID, input ExportTransactionsInput) (*ExportedReport, error) {
+	columns, err := validateTransactionExport(&input)
+	if err != nil {
+		return nil, err
+	}
+	if s.transactions == nil {
+		return nil, errors.New("transaction exports are not configured")
+	}
+
+	// Rows are written into the pipe as storage reads them, one chunk at a time
+	pr, pw := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		_, err := s.writeTransactions(ctx, pw, userID, input, columns)
+		_ = pw.CloseWithError(err)
+		written <- err
+	}()
+
+	now := s.now()
+	filename := "transactions-" + now.Format("2006-01-02") + "." + string(input.Format)
+	contentType := input.Format.ContentType()
+	info, err := s.storage.Upload(ctx, userID, filename, contentType, pr)
+	// Unblocks the writer if storage stopped reading early
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	writeErr := <-written
+	if err != nil {
+		return nil, fmt.Errorf("failed to store transactions export: %w", err)
+	}
+	if writeErr != nil {
+		_ = s.storage.Delete(ctx, userID, info.ID)
+		return nil, writeErr
+	}
+
+	expiresAt := now.Add(DownloadTTL).Truncate(time.Second)
+	return &ExportedReport{
+		FileID:      info.ID,
+		Filename:    filename,
+		ContentType: contentType,
+		SizeBytes:   info.Size,
+		DownloadURL: s.baseURL + DownloadPath + s.token(userID, info.ID, expiresAt),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// WriteTransactions writes the user's transactions matching the filter to w,
+// for callers that stream the file themselves. Returns how many were written.
+func (s *Service) WriteTransactions(ctx context.Context, w io.Writer, userID uuid.UUID, input ExportTransactionsInput) (int, error) {
+	columns, err := validateTransactionExport(&input)
+	if err != nil {
+		return 0, err
+	}
+	if s.transactions == nil {
+		return 0, errors.New("transaction exports are not configured")
+	}
+	return s.writeTransactions(ctx, w, userID, input, columns)
+}
+
+// validateTransactionExport checks the format and returns the column layout
+func validateTransactionExport(input *ExportTransactionsInput) ([]TransactionColumn, error) {
+	if input.Format != ReportFormatCSV && input.Format != ReportFormatXLSX {
+		return nil, ErrInvalidTransactionFormat
+	}
+	columns := input.Columns
+	if len(columns) == 0 {
+		columns = DefaultTransactionColumns
+	}
+	seen := make(map[TransactionColumn]bool, len(columns))
+	for _, c := range columns {
+		if _, ok := transactionColumnHeaders[c]; !ok || seen[c] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTransactionColumn, c)
+		}
+		seen[c] = true
+	}
+	return columns, nil
+}
+
+// transactionRowWriter writes the rows of one file format
+type transactionRowWriter interface {
+	writeHeader(columns []TransactionColumn) error
+	writeRow(tx *importrepo.Transaction, columns []TransactionColumn) error
+	// flush pushes the rows written so far to the output
+	flush() error
+	// close finishes the file
+	close() error
+	// release frees what the writer holds, whether or not it was closed
+	release()
+}
+
+func (s *Service) writeTransactions(ctx context.Context, w io.Writer, userID uuid.UUID, input ExportTransactionsInput, columns []TransactionColumn) (int, error) {
+	locale := LookupExportLocale(input.Locale)
+	var rw transactionRowWriter
+	if input.Format == ReportFormatXLSX {
+		xw, err := newXLSXTransactionWriter(w, locale)
+		if err != nil {
+			return 0, err
+		}
+		rw = xw
+	} else {
+		rw = newCSVTransactionWriter(w, locale)
+	}
+	defer rw.release()
+
+	if err := rw.writeHeader(columns); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	filter := input.Filter
+	filter.Limit = transactionExportChunk
+	filter.Offset = 0
+	written := 0
+	for {
+		page, _, err := s.transactions.ListTransactions(ctx, userID, filter)
+		if err != nil {
+			return written, fmt.Errorf("failed to list transactions: %w", err)
+		}
+		for _, tx := range page {
+			if err := rw.writeRow(tx, columns); err != nil {
+				return written, fmt.Errorf("failed to write transaction %s: %w", tx.ID, err)
+			}
+			written++
+		}
+		if err := rw.flush(); err != nil {
+			return written, fmt.Errorf("failed to write transactions: %w", err)
+		}
+		if len(page) < transactionExportChunk {
+			break
+		}
+		filter.Offset += len(page)
+	}
+
+	if err := rw.close(); err != nil {
+		return written, fmt.Errorf("failed to finish transactions export: %w", err)
+	}
+	return written, nil
+}
+
+// transactionText returns a column's value as text, amounts and dates in the locale
+func transactionText(tx *importrepo.Transaction, column TransactionColumn, locale ExportLocale) string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	switch column {
+	case TransactionColumnDate:
+		return tx.Date.Format(locale.DateLayout)
+	case TransactionColumnDescription:
+		return tx.Description
+	case TransactionColumnMerchant:
+		return deref(tx.MerchantName)
+	case TransactionColumnCategory:
+		return deref(tx.CategoryName)
+	case TransactionColumnAmount:
+		return locale.FormatAmount(tx.AmountCents, tx.CurrencyCode)
+	case TransactionColumnCurrency:
+		return tx.CurrencyCode
+	case TransactionColumnNotes:
+		return deref(tx.Notes)
+	case TransactionColumnInstitution:
+		return deref(tx.InstitutionName)
+	case TransactionColumnSource:
+		return tx.Source
+	case TransactionColumnAccountID:
+		if tx.AccountID == nil {
+			return ""
+		}
+		return tx.AccountID.String()
+	case TransactionColumnID:
+		return tx.ID.String()
+	default:
+		return ""
+	}
+}
+
+// csvTransactionWriter writes CSV in the locale's conventions, with a byte
+// order mark so spreadsheets open it as UTF-8
+type csvTransactionWriter struct {
+	w      *csv.Writer
+	out    io.Writer
+	locale ExportLocale
+}
+
+func newCSVTransactionWriter(w io.Writer, locale ExportLocale) *csvTransactionWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = locale.CSVDelimiter
+	return &csvTransactionWriter{w: cw, out: w, locale: locale}
+}
+
+func (c *csvTransactionWriter) writeHeader(columns []TransactionColumn) error {
+	if _, err := io.WriteString(c.out, "\ufeff"); err != nil {
+		return err
+	}
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = transactionColumnHeaders[col]
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvTransactionWriter) writeRow(tx *importrepo.Transaction, columns []TransactionColumn) error {
+	record := make([]string, len(columns))
+	for i, col := range columns {
+		record[i] = transactionText(tx, col, c.locale)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvTransactionWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvTransactionWriter) close() error {
+	return c.flush()
+}
+
+func (c *csvTransactionWriter) release() {}
+
+// xlsxTransactionWriter writes a single-sheet workbook through excelize's
+// stream writer, which spills rows to a temporary file instead of memory.
+// Amounts and dates stay typed so they can be summed and sorted.
+type xlsxTransactionWriter struct {
+	out    io.Writer
+	f      *excelize.File
+	sw     *excelize.StreamWriter
+	row    int
+	bold   int
+	amount int
+	date   int
+}
+
+func newXLSXTransactionWriter(w io.Writer, locale ExportLocale) (*xlsxTransactionWriter, error) {
+	f := excelize.NewFile()
+	x := &xlsxTransactionWriter{out: w, f: f, row: 1}
+	fail := func(err error) (*xlsxTransactionWriter, error) {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if err := f.SetSheetName("Sheet1", "Transactions"); err != nil {
+		return fail(fmt.Errorf("failed to name sheet: %w", err))
+	}
+	var err error
+	if x.bold, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return fail(fmt.Errorf("failed to create style: %w", err))
+	}
+	if x.amount, err = f.NewStyle(&excelize.Style{NumFmt: xlsxNumFmtAmount}); err != nil {
+		return fail(fmt.Errorf("failed to create style: %w", err))
+	}
+	dateFormat := locale.XLSXDateFormat
+	if x.date, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return fail(fmt.Errorf("failed to create style: %w", err))
+	}
+	if x.sw, err = f.NewStreamWriter("Transactions"); err != nil {
+		return fail(fmt.Errorf("failed to create stream writer: %w", err))
+	}
+	return x, nil
+}
+
+func (x *xlsxTransactionWriter) setRow(cells []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	x.row++
+	return x.sw.SetRow(cell, cells)
+}
+
+func (x *xlsxTransactionWriter) writeHeader(columns []TransactionColumn) error {
+	cells := make([]any, len(columns))
+	for i, col := range columns {
+		cells[i] = excelize.Cell{StyleID: x.bold, Value: transactionColumnHeaders[col]}
+	}
+	return x.setRow(cells)
+}
+
+func (x *xlsxTransactionWriter) writeRow(tx *importrepo.Transaction, columns []TransactionColumn) error {
+	cells := make([]any, len(columns))
+	for i, col := range columns {
+		switch col {
+		case TransactionColumnDate:
+			cells[i] = excelize.Cell{StyleID: x.date, Value: tx.Date}
+		case TransactionColumnAmount:
+			amount := money.New(tx.AmountCents, tx.CurrencyCode).ToDecimal().InexactFloat64()
+			cells[i] = excelize.Cell{StyleID: x.amount, Value: amount}
+		default:
+			cells[i] = transactionText(tx, col, ExportLocale{})
+		}
+	}
+	return x.setRow(cells)
+}
+
+// flush is a no-op: the workbook can only be written out once it's complete
+func (x *xlsxTransactionWriter) flush() error {
+	return nil
+}
+
+func (x *xlsxTransactionWriter) close() error {
+	if err := x.sw.Flush(); err != nil {
+		return err
+	}
+	_, err := x.f.WriteTo(x.out)
+	return err
+}
+
+// release removes the stream writer's temporary files
+func (x *xlsxTransactionWriter) release() {
+	_ = x.f.Close()
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+
+	importrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/repository"
+)
+
+// fakeTransactionLister pages through fixed transactions, newest first,
+// capping pages at 100 like the repository
+type fakeTransactionLister struct {
+	transactions []*importrepo.Transaction
+	pages        int
+}
+
+func (l *fakeTransactionLister) ListTransactions(_ context.Context, _ uuid.UUID, filter importrepo.ListTransactionsFilter) ([]*importrepo.Transaction, int64, error) {
+	l.pages++
+	limit := min(filter.Limit, 100)
+	start := min(filter.Offset, len(l.transactions))
+	end := min(start+limit, len(l.transactions))
+	return l.transactions[start:end], int64(len(l.transactions)), nil
+}
+
+func newTestTransactions(n int) []*importrepo.Transaction {
+	groceries := "Groceries"
+	transactions := make([]*importrepo.Transaction, n)
+	for i := range transactions {
+		transactions[i] = &importrepo.Transaction{
+			ID:           uuid.New(),
+			Date:         time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -i),
+			Description:  fmt.Sprintf("Shop %d", i),
+			CategoryName: &groceries,
+			AmountCents:  -123450 - int64(i),
+			CurrencyCode: "EUR",
+		}
+	}
+	return transactions
+}
+
+func TestExportTransactions_CSVInChunksWithLocaleFormatting(t *testing.T) {
+	lister := &fakeTransactionLister{transactions: newTestTransactions(250)}
+	svc := newTestService(t).WithTransactions(lister)
+
+	report, err := svc.ExportTransactions(context.Background(), uuid.New(), ExportTransactionsInput{
+		Format:  ReportFormatCSV,
+		Columns: []TransactionColumn{TransactionColumnDate, TransactionColumnDescription, TransactionColumnAmount},
+		Locale:  "pt_PT",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", report.ContentType)
+	assert.Equal(t, 3, lister.pages, "read 100 at a time")
+
+	lines := strings.Split(strings.TrimSuffix(string(download(t, svc, report)), "\n"), "\n")
+	require.Len(t, lines, 251)
+	assert.Equal(t, "\ufeffDate;Description;Amount", lines[0])
+	assert.Equal(t, "30/06/2025;Shop 0;-1.234,50", lines[1])
+	assert.Equal(t, "Shop 249", strings.Split(lines[250], ";")[1])
+}
+
+func TestExportTransactions_XLSXKeepsTypes(t *testing.T) {
+	svc := newTestService(t).WithTransactions(&fakeTransactionLister{transactions: newTestTransactions(2)})
+
+	report, err := svc.ExportTransactions(context.Background(), uuid.New(), ExportTransactionsInput{
+		Format:  ReportFormatXLSX,
+		Columns: []TransactionColumn{TransactionColumnAmount, TransactionColumnCategory, TransactionColumnDate},
+		Locale:  "de-DE",
+	})
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(bytes.NewReader(download(t, svc, report)))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rows, err := f.GetRows("Transactions", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"Amount", "Category", "Date"}, rows[0])
+	assert.Equal(t, "-1234.5", rows[1][0])
+	assert.Equal(t, "Groceries", rows[1][1])
+
+	date, err := f.GetCellValue("Transactions", "C2")
+	require.NoError(t, err)
+	assert.Equal(t, "30.06.2025", date)
+}
+
+func TestExportTransactions_RejectsInvalidInput(t *testing.T) {
+	svc := newTestService(t).WithTransactions(&fakeTransactionLister{})
+	ctx := context.Background()
+
+	_, err := svc.ExportTransactions(ctx, uuid.New(), ExportTransactionsInput{Format: ReportFormatPDF})
+	assert.ErrorIs(t, err, ErrInvalidTransactionFormat)
+
+	_, err = svc.ExportTransactions(ctx, uuid.New(), ExportTransactionsInput{
+		Format:  ReportFormatCSV,
+		Columns: []TransactionColumn{TransactionColumnDate, TransactionColumnDate},
+	})
+	assert.ErrorIs(t, err, ErrInvalidTransactionColumn)
+}
+
+func TestExportLocale_FormatAmount(t *testing.T) {
+	tests := []struct {
+		locale   string
+		minor    int64
+		currency string
+		want     string
+	}{
+		{"en", 123456789, "USD", "1,234,567.89"},
+		{"fr-FR", -5, "EUR", "-0,05"},
+		{"de", 100000, "JPY", "100.000"},
+		{"xx", 99, "EUR", "0.99"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, LookupExportLocale(tt.locale).FormatAmount(tt.minor, tt.currency), tt.locale)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+const xlsxSheet = "Report"
+
+// Built-in excelize number formats
+const (
+	xlsxNumFmtAmount  = 4  // #,##0.00
+	xlsxNumFmtPercent = 10 // 0.00%
+)
+
+// renderXLSX writes doc as a single-sheet workbook. Sections follow each other
+// down the sheet; amounts and percentages are numbers so they can be summed.
+func renderXLSX(w io.Writer, doc *Document) error {
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	if err := f.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		return fmt.Errorf("failed to name sheet: %w", err)
+	}
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	title, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 16}})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	amount, err := f.NewStyle(&excelize.Style{NumFmt: xlsxNumFmtAmount})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	percent, err := f.NewStyle(&excelize.Style{NumFmt: xlsxNumFmtPercent})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+
+	row := 1
+	set := func(col int, value any, style int) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(xlsxSheet, cell, value); err != nil {
+			return err
+		}
+		if style != 0 {
+			return f.SetCellStyle(xlsxSheet, cell, cell, style)
+		}
+		return nil
+	}
+
+	if err := set(1, doc.Title, title); err != nil {
+		return fmt.Errorf("failed to write title: %w", err)
+	}
+	row++
+	if doc.Subtitle != "" {
+		if err := set(1, doc.Subtitle, 0); err != nil {
+			return fmt.Errorf("failed to write subtitle: %w", err)
+		}
+		row++
+	}
+	if doc.CurrencyCode != "" {
+		if err := set(1, "Amounts in "+doc.CurrencyCode, 0); err != nil {
+			return fmt.Errorf("failed to write currency: %w", err)
+		}
+		row++
+	}
+
+	for _, section := range doc.Sections {
+		row++
+		if section.Heading != "" {
+			if err := set(1, section.Heading, bold); err != nil {
+				return fmt.Errorf("failed to write section heading: %w", err)
+			}
+			row++
+		}
+		for _, p := range section.Paragraphs {
+			if err := set(1, p, 0); err != nil {
+				return fmt.Errorf("failed to write paragraph: %w", err)
+			}
+			row++
+		}
+		if section.Table == nil {
+			continue
+		}
+
+		for i, c := range section.Table.Columns {
+			if err := set(i+1, c, bold); err != nil {
+				return fmt.Errorf("failed to write table header: %w", err)
+			}
+		}
+		row++
+		for _, cells := range section.Table.Rows {
+			for i, cell := range cells {
+				var err error
+				switch {
+				case cell.Minor != nil:
+					value := money.New(*cell.Minor, doc.CurrencyCode).ToDecimal().InexactFloat64()
+					err = set(i+1, value, amount)
+				case cell.Percent != nil:
+					err = set(i+1, *cell.Percent/100, percent)
+				default:
+					err = set(i+1, cell.Text, 0)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write table cell: %w", err)
+				}
+			}
+			row++
+		}
+	}
+
+	if err := f.SetColWidth(xlsxSheet, "A", "A", 40); err != nil {
+		return fmt.Errorf("failed to size columns: %w", err)
+	}
+	if err := f.SetColWidth(xlsxSheet, "B", "H", 16); err != nil {
+		return fmt.Errorf("failed to size columns: %w", err)
+	}
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to render xlsx: %w", err)
+	}
+	return nil
+}
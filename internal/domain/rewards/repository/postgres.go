@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRewardRepository implements RewardRepository using PostgreSQL
+type PostgresRewardRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRewardRepository creates a new PostgreSQL reward repository
+func NewPostgresRewardRepository(pool *pgxpool.Pool) *PostgresRewardRepository {
+	return &PostgresRewardRepository{pool: pool}
+}
+
+// ListCandidateCredits lists unflagged credits, oldest first
+func (r *PostgresRewardRepository) ListCandidateCredits(ctx context.Context, userID uuid.UUID, since time.Time) ([]*Credit, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, description, COALESCE(merchant_name, ''), amount_minor, currency_code, posted_at
+		FROM transactions
+		WHERE user_id = $1
+		  AND deleted_at IS NULL
+		  AND posted_at >= $2
+		  AND amount_minor > 0
+		  AND NOT is_reward
+		  AND reward_rule IS NULL -- Not previously unflagged by the user
+		ORDER BY posted_at`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidate credits: %w", err)
+	}
+	defer rows.Close()
+
+	var credits []*Credit
+	for rows.Next() {
+		c := &Credit{}
+		if err := rows.Scan(&c.TransactionID, &c.UserID, &c.Description, &c.MerchantName, &c.AmountMinor, &c.CurrencyCode, &c.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan credit: %w", err)
+		}
+		credits = append(credits, c)
+	}
+	return credits, rows.Err()
+}
+
+// ListCategorizedExpenses lists categorized expenses, newest first
+func (r *PostgresRewardRepository) ListCategorizedExpenses(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*Expense, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT COALESCE(merchant_name, ''), description, category_id, posted_at
+		FROM transactions
+		WHERE user_id = $1
+		  AND deleted_at IS NULL
+		  AND posted_at >= $2
+		  AND posted_at < $3
+		  AND amount_minor < 0
+		  AND category_id IS NOT NULL
+		ORDER BY posted_at DESC`, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categorized expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*Expense
+	for rows.Next() {
+		e := &Expense{}
+		if err := rows.Scan(&e.MerchantName, &e.Description, &e.CategoryID, &e.PostedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		expenses = append(expenses, e)
+	}
+	return expenses, rows.Err()
+}
+
+// MarkRewards flags credits as rewards in one transaction
+func (r *PostgresRewardRepository) MarkRewards(ctx context.Context, userID uuid.UUID, matches []RewardMatch) (int, error) {
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	marked := 0
+	for _, m := range matches {
+		tag, err := tx.Exec(ctx, `
+			UPDATE transactions
+			SET is_reward = true, reward_category_id = $3, reward_rule = $4
+			WHERE id = $1 AND user_id = $2 AND amount_minor > 0 AND NOT is_reward AND deleted_at IS NULL`,
+			m.TransactionID, userID, m.CategoryID, m.Rule)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark reward: %w", err)
+		}
+		marked += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit rewards: %w", err)
+	}
+	return marked, nil
+}
+
+// SetReward flags or unflags a credit. Unflagged credits keep reward_rule =
+// 'manual' so detection doesn't flag them again.
+func (r *PostgresRewardRepository) SetReward(ctx context.Context, userID, transactionID uuid.UUID, isReward bool, categoryID *uuid.UUID) error {
+	if !isReward {
+		categoryID = nil
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE transactions
+		SET is_reward = $3, reward_category_id = $4, reward_rule = $5
+		WHERE id = $1 AND user_id = $2 AND amount_minor > 0 AND deleted_at IS NULL`,
+		transactionID, userID, isReward, categoryID, RuleManual)
+	if err != nil {
+		return fmt.Errorf("failed to set reward: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListUsersWithCredits lists users with unflagged credits since the given time
+func (r *PostgresRewardRepository) ListUsersWithCredits(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT user_id
+		FROM transactions
+		WHERE posted_at >= $1 AND amount_minor > 0 AND NOT is_reward AND reward_rule IS NULL AND deleted_at IS NULL`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users with credits: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
+// GetRewardTotals aggregates rewards in [start, end) by month, category and rule
+func (r *PostgresRewardRepository) GetRewardTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]*RewardTotal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT
+			EXTRACT(MONTH FROM t.posted_at)::int AS month,
+			t.reward_category_id,
+			COALESCE(c.name, 'Unattributed') AS category_name,
+			COALESCE(t.reward_rule, 'manual') AS rule,
+			t.currency_code,
+			SUM(t.amount_minor) AS total_minor,
+			COUNT(*) AS reward_count
+		FROM transactions t
+		LEFT JOIN categories c ON c.id = t.reward_category_id
+		WHERE t.user_id = $1
+		  AND t.deleted_at IS NULL
+		  AND t.is_reward
+		  AND t.posted_at >= $2
+		  AND t.posted_at < $3
+		GROUP BY 1, 2, 3, 4, 5
+		ORDER BY 1, total_minor DESC`, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reward totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []*RewardTotal
+	for rows.Next() {
+		t := &RewardTotal{}
+		if err := rows.Scan(&t.Month, &t.CategoryID, &t.CategoryName, &t.Rule, &t.CurrencyCode, &t.TotalMinor, &t.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan reward total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
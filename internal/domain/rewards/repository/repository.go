@@ -0,0 +1,63 @@
+// Package repository provides database operations for cash-back and reward credits.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleManual marks rewards flagged by the user rather than a detection rule
+const RuleManual = "manual"
+
+// Credit is an incoming transaction that may be a reward
+type Credit struct {
+	TransactionID uuid.UUID
+	UserID        uuid.UUID
+	Description   string
+	MerchantName  string
+	AmountMinor   int64
+	CurrencyCode  string
+	PostedAt      time.Time
+}
+
+// Expense is a recent purchase a reward can be attributed to
+type Expense struct {
+	MerchantName string
+	Description  string
+	CategoryID   uuid.UUID
+	PostedAt     time.Time
+}
+
+// RewardMatch flags a credit as a reward
+type RewardMatch struct {
+	TransactionID uuid.UUID
+	CategoryID    *uuid.UUID // Originating category, if attributed
+	Rule          string
+}
+
+// RewardTotal is an aggregate of rewards for one month, category and rule
+type RewardTotal struct {
+	Month        int
+	CategoryID   *uuid.UUID
+	CategoryName string // "Unattributed" when CategoryID is nil
+	Rule         string
+	CurrencyCode string
+	TotalMinor   int64
+	Count        int
+}
+
+// RewardRepository defines the interface for reward persistence
+type RewardRepository interface {
+	// ListCandidateCredits lists credits since the given time not yet flagged as rewards
+	ListCandidateCredits(ctx context.Context, userID uuid.UUID, since time.Time) ([]*Credit, error)
+	// ListCategorizedExpenses lists categorized expenses in [from, to) for attribution
+	ListCategorizedExpenses(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*Expense, error)
+	MarkRewards(ctx context.Context, userID uuid.UUID, matches []RewardMatch) (int, error)
+	// SetReward flags or unflags a single transaction; sql.ErrNoRows if not found, not owned or not a credit
+	SetReward(ctx context.Context, userID, transactionID uuid.UUID, isReward bool, categoryID *uuid.UUID) error
+	ListUsersWithCredits(ctx context.Context, since time.Time) ([]uuid.UUID, error)
+
+	GetRewardTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]*RewardTotal, error)
+}
@@ -0,0 +1,263 @@
+// Package service provides business logic for cash-back and reward credits.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/repository"
+)
+
+const (
+	// attributionWindow is how far back a reward's originating purchase is searched
+	attributionWindow = 45 * 24 * time.Hour
+	// defaultLookback is how far back scheduled detection scans for new credits
+	defaultLookback = 7 * 24 * time.Hour
+	// minMerchantLength avoids attributing on very short, ambiguous merchant names
+	minMerchantLength = 3
+)
+
+// ErrNotReward is returned when a transaction can't be flagged (not found, not owned or not a credit)
+var ErrNotReward = errors.New("transaction not found or not a credit")
+
+// Rule is a detection rule for a common cash-back/reward transaction format
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules match the description formats banks and card issuers use for rewards
+var DefaultRules = []Rule{
+	{Name: "cashback", Pattern: regexp.MustCompile(`(?i)\bcash[\s-]?back\b`)},
+	{Name: "card_offer", Pattern: regexp.MustCompile(`(?i)\b(amex|chase|citi|capital one|bank ?of ?america|bofa)\b.*\b(offer|deal)s?\b`)},
+	{Name: "statement_credit", Pattern: regexp.MustCompile(`(?i)\bstatement credit\b`)},
+	{Name: "points_redemption", Pattern: regexp.MustCompile(`(?i)\b(points?|miles)\b.*\b(redemption|redeemed|redeem)\b|\b(redemption|redeemed)\b.*\b(points?|miles)\b`)},
+	{Name: "reward", Pattern: regexp.MustCompile(`(?i)\brewards?\b.*\b(credit|redemption|payout|bonus)\b|\b(credit|redemption|payout|bonus)\b.*\brewards?\b`)},
+}
+
+// DetectionResult summarizes a detection run
+type DetectionResult struct {
+	Detected   int
+	Attributed int // Rewards credited back to an originating category
+}
+
+// CategoryRewards is a year's rewards for one category
+type CategoryRewards struct {
+	CategoryID   *uuid.UUID
+	CategoryName string
+	TotalMinor   int64
+	Count        int
+}
+
+// YearlySummary aggregates a user's rewards for a calendar year
+type YearlySummary struct {
+	Year         int
+	CurrencyCode string
+	TotalMinor   int64
+	Count        int
+	ByMonth      [12]int64 // Index 0 = January
+	ByCategory   []*CategoryRewards
+	ByRule       map[string]int64
+}
+
+// Service provides reward tracking business logic
+type Service struct {
+	repo  repository.RewardRepository
+	rules []Rule
+}
+
+// NewService creates a new rewards service using the default detection rules
+func NewService(repo repository.RewardRepository) *Service {
+	return &Service{repo: repo, rules: DefaultRules}
+}
+
+// WithRules replaces the detection rules
+func (s *Service) WithRules(rules []Rule) *Service {
+	s.rules = rules
+	return s
+}
+
+// MatchRule returns the name of the first rule matching the description, or ""
+func (s *Service) MatchRule(description string) string {
+	for _, r := range s.rules {
+		if r.Pattern.MatchString(description) {
+			return r.Name
+		}
+	}
+	return ""
+}
+
+// DetectRewards flags credits since the given time that match a detection rule,
+// attributing each to the category of the purchase that earned it.
+func (s *Service) DetectRewards(ctx context.Context, userID uuid.UUID, since time.Time) (*DetectionResult, error) {
+	credits, err := s.repo.ListCandidateCredits(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []repository.RewardMatch
+	for _, c := range credits {
+		if rule := s.MatchRule(c.Description + " " + c.MerchantName); rule != "" {
+			matches = append(matches, repository.RewardMatch{TransactionID: c.TransactionID, Rule: rule})
+		}
+	}
+	if len(matches) == 0 {
+		return &DetectionResult{}, nil
+	}
+
+	expenses, err := s.repo.ListCategorizedExpenses(ctx, userID, since.Add(-attributionWindow), time.Now().Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	creditsByID := make(map[uuid.UUID]*repository.Credit, len(credits))
+	for _, c := range credits {
+		creditsByID[c.TransactionID] = c
+	}
+
+	result := &DetectionResult{}
+	for i := range matches {
+		if categoryID := attribute(creditsByID[matches[i].TransactionID], expenses); categoryID != nil {
+			matches[i].CategoryID = categoryID
+			result.Attributed++
+		}
+	}
+
+	result.Detected, err = s.repo.MarkRewards(ctx, userID, matches)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DetectForAllUsers runs detection over the recent credits of every user
+func (s *Service) DetectForAllUsers(ctx context.Context) (*DetectionResult, error) {
+	since := time.Now().Add(-defaultLookback)
+	userIDs, err := s.repo.ListUsersWithCredits(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	total := &DetectionResult{}
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		res, err := s.DetectRewards(ctx, userID, since)
+		if err != nil {
+			return total, fmt.Errorf("failed to detect rewards for user %s: %w", userID, err)
+		}
+		total.Detected += res.Detected
+		total.Attributed += res.Attributed
+	}
+	return total, nil
+}
+
+// MarkReward flags a credit as a reward, optionally crediting it to a category
+func (s *Service) MarkReward(ctx context.Context, userID, transactionID uuid.UUID, categoryID *uuid.UUID) error {
+	return s.setReward(ctx, userID, transactionID, true, categoryID)
+}
+
+// UnmarkReward treats a credit as regular income again; detection won't re-flag it
+func (s *Service) UnmarkReward(ctx context.Context, userID, transactionID uuid.UUID) error {
+	return s.setReward(ctx, userID, transactionID, false, nil)
+}
+
+func (s *Service) setReward(ctx context.Context, userID, transactionID uuid.UUID, isReward bool, categoryID *uuid.UUID) error {
+	err := s.repo.SetReward(ctx, userID, transactionID, isReward, categoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotReward
+	}
+	return err
+}
+
+// GetYearlySummary aggregates the user's rewards for a calendar year
+func (s *Service) GetYearlySummary(ctx context.Context, userID uuid.UUID, year int) (*YearlySummary, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := s.repo.GetRewardTotals(ctx, userID, start, start.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	return summarize(year, totals), nil
+}
+
+// summarize folds month/category/rule totals into a yearly summary
+func summarize(year int, totals []*repository.RewardTotal) *YearlySummary {
+	summary := &YearlySummary{Year: year, ByRule: make(map[string]int64)}
+
+	byCategory := make(map[string]*CategoryRewards)
+	for _, t := range totals {
+		if summary.CurrencyCode == "" {
+			summary.CurrencyCode = t.CurrencyCode
+		}
+		summary.TotalMinor += t.TotalMinor
+		summary.Count += t.Count
+		if t.Month >= 1 && t.Month <= 12 {
+			summary.ByMonth[t.Month-1] += t.TotalMinor
+		}
+		summary.ByRule[t.Rule] += t.TotalMinor
+
+		key := ""
+		if t.CategoryID != nil {
+			key = t.CategoryID.String()
+		}
+		cat := byCategory[key]
+		if cat == nil {
+			cat = &CategoryRewards{CategoryID: t.CategoryID, CategoryName: t.CategoryName}
+			byCategory[key] = cat
+			summary.ByCategory = append(summary.ByCategory, cat)
+		}
+		cat.TotalMinor += t.TotalMinor
+		cat.Count += t.Count
+	}
+
+	sort.SliceStable(summary.ByCategory, func(i, j int) bool {
+		return summary.ByCategory[i].TotalMinor > summary.ByCategory[j].TotalMinor
+	})
+	return summary
+}
+
+// attribute returns the category of the most recent purchase, within the
+// attribution window before the credit, whose merchant the credit mentions.
+// Expenses are expected newest first.
+func attribute(credit *repository.Credit, expenses []*repository.Expense) *uuid.UUID {
+	if credit == nil {
+		return nil
+	}
+	text := normalizeMerchant(credit.Description + " " + credit.MerchantName)
+
+	for _, e := range expenses {
+		if e.PostedAt.After(credit.PostedAt) || credit.PostedAt.Sub(e.PostedAt) > attributionWindow {
+			continue
+		}
+		merchant := normalizeMerchant(e.MerchantName)
+		if merchant == "" {
+			merchant = normalizeMerchant(e.Description)
+		}
+		if len(merchant) >= minMerchantLength && strings.Contains(text, merchant) {
+			id := e.CategoryID
+			return &id
+		}
+	}
+	return nil
+}
+
+// normalizeMerchant lowercases and strips everything but letters and digits
+func normalizeMerchant(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
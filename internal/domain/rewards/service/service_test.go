@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/repository"
+)
+
+// fakeRewardRepository keeps credits and expenses in memory.
+type fakeRewardRepository struct {
+	credits  []*repository.Credit
+	expenses []*repository.Expense
+	marked   map[uuid.UUID]repository.RewardMatch
+}
+
+func (f *fakeRewardRepository) ListCandidateCredits(ctx context.Context, userID uuid.UUID, since time.Time) ([]*repository.Credit, error) {
+	var out []*repository.Credit
+	for _, c := range f.credits {
+		if _, ok := f.marked[c.TransactionID]; !ok && !c.PostedAt.Before(since) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRewardRepository) ListCategorizedExpenses(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*repository.Expense, error) {
+	return f.expenses, nil
+}
+
+func (f *fakeRewardRepository) MarkRewards(ctx context.Context, userID uuid.UUID, matches []repository.RewardMatch) (int, error) {
+	for _, m := range matches {
+		f.marked[m.TransactionID] = m
+	}
+	return len(matches), nil
+}
+
+func (f *fakeRewardRepository) SetReward(ctx context.Context, userID, transactionID uuid.UUID, isReward bool, categoryID *uuid.UUID) error {
+	return sql.ErrNoRows
+}
+
+func (f *fakeRewardRepository) ListUsersWithCredits(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (f *fakeRewardRepository) GetRewardTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]*repository.RewardTotal, error) {
+	return nil, nil
+}
+
+func TestMatchRule(t *testing.T) {
+	svc := NewService(&fakeRewardRepository{})
+
+	cases := map[string]string{
+		"CASHBACK BONUS":                       "cashback",
+		"Cash-back redemption":                 "cashback",
+		"AMEX OFFER CREDIT - STARBUCKS":        "card_offer",
+		"Statement Credit Travel":              "statement_credit",
+		"Ultimate Rewards points redeemed":     "points_redemption",
+		"REWARDS CREDIT":                       "reward",
+		"PAYROLL ACME CORP":                    "",
+		"Refund AMAZON":                        "",
+		"Transfer from savings (rewards goal)": "",
+	}
+	for description, want := range cases {
+		assert.Equal(t, want, svc.MatchRule(description), description)
+	}
+}
+
+func TestDetectRewards_AttributesToOriginatingCategory(t *testing.T) {
+	now := time.Now()
+	coffee, groceries := uuid.New(), uuid.New()
+	offer := &repository.Credit{TransactionID: uuid.New(), Description: "AMEX OFFER CREDIT STARBUCKS", AmountMinor: 500, PostedAt: now}
+	cashback := &repository.Credit{TransactionID: uuid.New(), Description: "CASHBACK", AmountMinor: 1200, PostedAt: now}
+	salary := &repository.Credit{TransactionID: uuid.New(), Description: "SALARY", AmountMinor: 300000, PostedAt: now}
+
+	repo := &fakeRewardRepository{
+		credits: []*repository.Credit{offer, cashback, salary},
+		expenses: []*repository.Expense{
+			{MerchantName: "Starbucks", CategoryID: coffee, PostedAt: now.AddDate(0, 0, -3)},
+			{MerchantName: "Whole Foods", CategoryID: groceries, PostedAt: now.AddDate(0, 0, -5)},
+			// Too old to have earned this reward
+			{MerchantName: "Starbucks", CategoryID: groceries, PostedAt: now.AddDate(0, 0, -90)},
+		},
+		marked: map[uuid.UUID]repository.RewardMatch{},
+	}
+	svc := NewService(repo)
+
+	result, err := svc.DetectRewards(context.Background(), uuid.New(), now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Detected)
+	assert.Equal(t, 1, result.Attributed)
+
+	require.Contains(t, repo.marked, offer.TransactionID)
+	assert.Equal(t, "card_offer", repo.marked[offer.TransactionID].Rule)
+	assert.Equal(t, coffee, *repo.marked[offer.TransactionID].CategoryID)
+	assert.Nil(t, repo.marked[cashback.TransactionID].CategoryID)
+	assert.NotContains(t, repo.marked, salary.TransactionID)
+}
+
+func TestSummarize(t *testing.T) {
+	coffee := uuid.New()
+	summary := summarize(2026, []*repository.RewardTotal{
+		{Month: 1, CategoryID: &coffee, CategoryName: "Coffee", Rule: "card_offer", CurrencyCode: "EUR", TotalMinor: 500, Count: 1},
+		{Month: 1, CategoryName: "Unattributed", Rule: "cashback", CurrencyCode: "EUR", TotalMinor: 1200, Count: 2},
+		{Month: 3, CategoryID: &coffee, CategoryName: "Coffee", Rule: "card_offer", CurrencyCode: "EUR", TotalMinor: 300, Count: 1},
+	})
+
+	assert.Equal(t, int64(2000), summary.TotalMinor)
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, int64(1700), summary.ByMonth[0])
+	assert.Equal(t, int64(300), summary.ByMonth[2])
+	assert.Equal(t, int64(800), summary.ByRule["card_offer"])
+	require.Len(t, summary.ByCategory, 2)
+	assert.Equal(t, "Unattributed", summary.ByCategory[0].CategoryName)
+	assert.Equal(t, int64(800), summary.ByCategory[1].TotalMinor)
+}
+
+func TestUnmarkReward_NotFound(t *testing.T) {
+	svc := NewService(&fakeRewardRepository{})
+	assert.ErrorIs(t, svc.UnmarkReward(context.Background(), uuid.New(), uuid.New()), ErrNotReward)
+}
@@ -0,0 +1,61 @@
+// Package handler serves read-only share links over HTTP.
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// ShareLinkHandler serves the snapshot behind a share link without authentication
+type ShareLinkHandler struct {
+	svc     *service.Service
+	logger  *slog.Logger
+	proxies *interceptors.TrustedProxies
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(svc *service.Service, logger *slog.Logger) *ShareLinkHandler {
+	return &ShareLinkHandler{svc: svc, logger: logger}
+}
+
+// WithTrustedProxies records the address of viewers behind proxies from
+// X-Forwarded-For; otherwise views get the peer address
+func (h *ShareLinkHandler) WithTrustedProxies(proxies *interceptors.TrustedProxies) *ShareLinkHandler {
+	h.proxies = proxies
+	return h
+}
+
+// ServeHTTP answers GET SharePath<token> with the link's snapshot as JSON
+func (h *ShareLinkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Shared finances must never end up in caches or search results
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	token := strings.TrimPrefix(r.URL.Path, service.SharePath)
+	view, err := h.svc.OpenShareLink(r.Context(), token, h.proxies.ClientIP(r.Header, r.RemoteAddr), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidShareLink) {
+			http.Error(w, "This link is invalid, has expired or was revoked.", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to open share link", slog.Any("error", err))
+		http.Error(w, "Failed to open shared report.", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(view)
+}
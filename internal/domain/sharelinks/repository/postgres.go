@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresShareLinkRepository implements ShareLinkRepository using PostgreSQL
+type PostgresShareLinkRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresShareLinkRepository creates a new PostgreSQL share link repository
+func NewPostgresShareLinkRepository(pool *pgxpool.Pool) *PostgresShareLinkRepository {
+	return &PostgresShareLinkRepository{pool: pool}
+}
+
+const linkColumns = `id, user_id, kind, plan_id, month_start, title, snapshot, expires_at, revoked_at,
+	access_count, last_accessed_at, created_at`
+
+func scanLink(row pgx.Row) (*ShareLink, error) {
+	l := &ShareLink{}
+	err := row.Scan(
+		&l.ID, &l.UserID, &l.Kind, &l.PlanID, &l.MonthStart, &l.Title, &l.Snapshot, &l.ExpiresAt, &l.RevokedAt,
+		&l.AccessCount, &l.LastAccessedAt, &l.CreatedAt,
+	)
+	return l, err
+}
+
+// CreateLink inserts a share link
+func (r *PostgresShareLinkRepository) CreateLink(ctx context.Context, l *ShareLink) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO share_links (id, user_id, kind, plan_id, month_start, title, snapshot, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at`,
+		l.ID, l.UserID, l.Kind, l.PlanID, l.MonthStart, l.Title, l.Snapshot, l.ExpiresAt,
+	).Scan(&l.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+	return nil
+}
+
+// GetLink retrieves a share link by ID
+func (r *PostgresShareLinkRepository) GetLink(ctx context.Context, id uuid.UUID) (*ShareLink, error) {
+	l, err := scanLink(r.pool.QueryRow(ctx, `SELECT `+linkColumns+` FROM share_links WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	return l, nil
+}
+
+// ListLinks lists a user's share links, newest first
+func (r *PostgresShareLinkRepository) ListLinks(ctx context.Context, userID uuid.UUID) ([]*ShareLink, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+linkColumns+`
+		FROM share_links
+		WHERE user_id = $1
+		ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*ShareLink
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// RevokeLink revokes a user's share link
+func (r *PostgresShareLinkRepository) RevokeLink(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE share_links SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RecordAccess logs an access and bumps the link's access count
+func (r *PostgresShareLinkRepository) RecordAccess(ctx context.Context, a *Access) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO share_link_accesses (link_id, ip_address, user_agent)
+		VALUES ($1, $2, $3)
+		RETURNING id, accessed_at`,
+		a.LinkID, a.IPAddress, a.UserAgent,
+	).Scan(&a.ID, &a.AccessedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record share link access: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE share_links SET access_count = access_count + 1, last_accessed_at = $2
+		WHERE id = $1`, a.LinkID, a.AccessedAt); err != nil {
+		return fmt.Errorf("failed to update share link access count: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListAccesses lists a link's most recent accesses
+func (r *PostgresShareLinkRepository) ListAccesses(ctx context.Context, linkID uuid.UUID, limit int) ([]*Access, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, link_id, ip_address, user_agent, accessed_at
+		FROM share_link_accesses
+		WHERE link_id = $1
+		ORDER BY accessed_at DESC
+		LIMIT $2`, linkID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share link accesses: %w", err)
+	}
+	defer rows.Close()
+
+	var accesses []*Access
+	for rows.Next() {
+		a := &Access{}
+		if err := rows.Scan(&a.ID, &a.LinkID, &a.IPAddress, &a.UserAgent, &a.AccessedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share link access: %w", err)
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
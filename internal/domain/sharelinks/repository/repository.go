@@ -0,0 +1,55 @@
+// Package repository provides database operations for read-only share links.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LinkKind is what a share link gives access to
+type LinkKind string
+
+const (
+	LinkKindPlan          LinkKind = "plan"
+	LinkKindMonthlyReport LinkKind = "monthly_report"
+)
+
+// ShareLink is a read-only link to a snapshot of a plan or monthly report
+type ShareLink struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Kind           LinkKind
+	PlanID         *uuid.UUID // Set for plan links
+	MonthStart     *time.Time // Set for monthly report links
+	Title          string
+	Snapshot       []byte // JSONB, frozen when the link was created
+	ExpiresAt      time.Time
+	RevokedAt      *time.Time
+	AccessCount    int
+	LastAccessedAt *time.Time
+	CreatedAt      time.Time
+}
+
+// Access is one opening of a share link
+type Access struct {
+	ID         int64
+	LinkID     uuid.UUID
+	IPAddress  *string
+	UserAgent  *string
+	AccessedAt time.Time
+}
+
+// ShareLinkRepository defines data access for share links
+type ShareLinkRepository interface {
+	CreateLink(ctx context.Context, link *ShareLink) error
+	GetLink(ctx context.Context, id uuid.UUID) (*ShareLink, error)
+	ListLinks(ctx context.Context, userID uuid.UUID) ([]*ShareLink, error)
+	// RevokeLink revokes a user's link; sql.ErrNoRows if it isn't theirs or is already revoked
+	RevokeLink(ctx context.Context, userID, id uuid.UUID) error
+
+	// RecordAccess logs an access and bumps the link's access count
+	RecordAccess(ctx context.Context, access *Access) error
+	ListAccesses(ctx context.Context, linkID uuid.UUID, limit int) ([]*Access, error)
+}
@@ -0,0 +1,314 @@
+// Package service provides business logic for read-only share links: signed,
+// expiring links to a snapshot of a plan or monthly report that can be opened
+// without an account, e.g. by a financial advisor.
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/repository"
+)
+
+// =============================================================================
+// Share Links (Internal Integration)
+// =============================================================================
+// Links are opened over plain HTTP at SharePath, without authentication. Creating,
+// listing and revoking them is available on the service but requires proto
+// definitions to be exposed as API endpoints.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateShareLinkRequest/Response (ShareService.CreateShareLink)
+// - ListShareLinksRequest/Response, RevokeShareLinkRequest/Response
+// - ListShareLinkAccessesRequest/Response
+// - ShareLink, ShareLinkKind, ShareLinkAccess
+
+// SharePath is the public route share links are opened at; the token is appended
+const SharePath = "/share/"
+
+const (
+	// DefaultLinkTTL is how long a link stays valid when no expiry is given
+	DefaultLinkTTL = 7 * 24 * time.Hour
+	// MaxLinkTTL caps how long a link can stay valid
+	MaxLinkTTL = 90 * 24 * time.Hour
+	// accessLogLimit caps how many accesses are listed per link
+	accessLogLimit = 100
+)
+
+var (
+	// ErrInvalidShareLink is returned for a tampered, expired, revoked or unknown link.
+	// Callers opening a link can't tell these apart on purpose.
+	ErrInvalidShareLink = errors.New("share link is invalid or has expired")
+	// ErrInvalidLinkKind is returned for an unknown kind or a kind without its target
+	ErrInvalidLinkKind = errors.New("share a plan or a monthly report")
+	// ErrShareTargetNotFound is returned when the plan or report to share doesn't exist
+	ErrShareTargetNotFound = errors.New("nothing to share: plan or report not found")
+	// ErrShareLinkNotFound is returned when a user's link doesn't exist or is already revoked
+	ErrShareLinkNotFound = errors.New("share link not found")
+)
+
+// PlanSnapshot is the read-only view of a plan frozen into a link
+type PlanSnapshot struct {
+	Name               string          `json:"name"`
+	CurrencyCode       string          `json:"currency_code"`
+	PeriodStart        *time.Time      `json:"period_start,omitempty"`
+	PeriodEnd          *time.Time      `json:"period_end,omitempty"`
+	TotalBudgetedMinor int64           `json:"total_budgeted_minor"`
+	TotalActualMinor   int64           `json:"total_actual_minor"`
+	Groups             []SnapshotGroup `json:"groups"`
+}
+
+// SnapshotGroup is a group of plan items
+type SnapshotGroup struct {
+	Name  string         `json:"name"`
+	Items []SnapshotItem `json:"items"`
+}
+
+// SnapshotItem is a plan item's budget and actual
+type SnapshotItem struct {
+	Name          string `json:"name"`
+	ItemType      string `json:"item_type"`
+	BudgetedMinor int64  `json:"budgeted_minor"`
+	ActualMinor   int64  `json:"actual_minor"`
+}
+
+// ReportSnapshot is the read-only view of a monthly report frozen into a link
+type ReportSnapshot struct {
+	MonthStart         time.Time        `json:"month_start"`
+	TotalSpendMinor    int64            `json:"total_spend_minor"`
+	TotalIncomeMinor   int64            `json:"total_income_minor"`
+	NetMinor           int64            `json:"net_minor"`
+	SpendChangePercent float64          `json:"spend_change_percent"`
+	TopCategories      []SnapshotAmount `json:"top_categories"`
+	TopMerchants       []SnapshotAmount `json:"top_merchants"`
+	Highlights         []string         `json:"highlights"`
+}
+
+// SnapshotAmount is a named amount, such as a category's spend
+type SnapshotAmount struct {
+	Name        string `json:"name"`
+	AmountMinor int64  `json:"amount_minor"`
+}
+
+// SnapshotSource builds the snapshots frozen into links
+type SnapshotSource interface {
+	// PlanSnapshot returns the plan as the user sees it, or nil if they can't see it
+	PlanSnapshot(ctx context.Context, userID, planID uuid.UUID) (*PlanSnapshot, error)
+	// MonthlyReportSnapshot returns the user's report for the month starting at monthStart
+	MonthlyReportSnapshot(ctx context.Context, userID uuid.UUID, monthStart time.Time) (*ReportSnapshot, error)
+}
+
+// CreateShareLinkInput describes a link to create
+type CreateShareLinkInput struct {
+	Kind       repository.LinkKind
+	PlanID     *uuid.UUID    // For plan links
+	MonthStart *time.Time    // For monthly report links; any day in the month
+	TTL        time.Duration // Defaults to DefaultLinkTTL, capped at MaxLinkTTL
+}
+
+// SharedView is what someone opening a link sees
+type SharedView struct {
+	Kind      repository.LinkKind `json:"kind"`
+	Title     string              `json:"title"`
+	SharedAt  time.Time           `json:"shared_at"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	Snapshot  json.RawMessage     `json:"snapshot"`
+}
+
+// Service provides share link business logic
+type Service struct {
+	repo    repository.ShareLinkRepository
+	source  SnapshotSource
+	secret  []byte
+	baseURL string
+	now     func() time.Time
+}
+
+// NewService creates a new share link service. Tokens are signed with secret and
+// link URLs start with baseURL.
+func NewService(repo repository.ShareLinkRepository, source SnapshotSource, secret []byte, baseURL string) *Service {
+	return &Service{
+		repo:    repo,
+		source:  source,
+		secret:  secret,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		now:     time.Now,
+	}
+}
+
+// CreateShareLink freezes a snapshot of the plan or monthly report and issues a
+// link to it. Later changes to the plan or transactions don't show through the link.
+func (s *Service) CreateShareLink(ctx context.Context, userID uuid.UUID, input CreateShareLinkInput) (*repository.ShareLink, error) {
+	ttl := input.TTL
+	if ttl <= 0 {
+		ttl = DefaultLinkTTL
+	}
+	if ttl > MaxLinkTTL {
+		ttl = MaxLinkTTL
+	}
+
+	link := &repository.ShareLink{
+		UserID:    userID,
+		Kind:      input.Kind,
+		ExpiresAt: s.now().Add(ttl).Truncate(time.Second),
+	}
+
+	var snapshot any
+	switch {
+	case input.Kind == repository.LinkKindPlan && input.PlanID != nil:
+		plan, err := s.source.PlanSnapshot(ctx, userID, *input.PlanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot plan: %w", err)
+		}
+		if plan == nil {
+			return nil, ErrShareTargetNotFound
+		}
+		link.PlanID = input.PlanID
+		link.Title = plan.Name
+		snapshot = plan
+	case input.Kind == repository.LinkKindMonthlyReport && input.MonthStart != nil:
+		year, month, _ := input.MonthStart.Date()
+		monthStart := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		report, err := s.source.MonthlyReportSnapshot(ctx, userID, monthStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot monthly report: %w", err)
+		}
+		if report == nil {
+			return nil, ErrShareTargetNotFound
+		}
+		link.MonthStart = &monthStart
+		link.Title = "Monthly report · " + monthStart.Format("January 2006")
+		snapshot = report
+	default:
+		return nil, ErrInvalidLinkKind
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	link.Snapshot = data
+
+	if err := s.repo.CreateLink(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListShareLinks lists the user's links, including revoked and expired ones
+func (s *Service) ListShareLinks(ctx context.Context, userID uuid.UUID) ([]*repository.ShareLink, error) {
+	return s.repo.ListLinks(ctx, userID)
+}
+
+// RevokeShareLink disables one of the user's links immediately
+func (s *Service) RevokeShareLink(ctx context.Context, userID, linkID uuid.UUID) error {
+	err := s.repo.RevokeLink(ctx, userID, linkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareLinkNotFound
+	}
+	return err
+}
+
+// ListAccesses lists the most recent times one of the user's links was opened
+func (s *Service) ListAccesses(ctx context.Context, userID, linkID uuid.UUID) ([]*repository.Access, error) {
+	link, err := s.repo.GetLink(ctx, linkID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && link.UserID != userID) {
+		return nil, ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListAccesses(ctx, linkID, accessLogLimit)
+}
+
+// OpenShareLink returns the snapshot behind a token and logs the access. It
+// fails with ErrInvalidShareLink unless the token is authentic, unexpired and
+// its link hasn't been revoked.
+func (s *Service) OpenShareLink(ctx context.Context, token, ipAddress, userAgent string) (*SharedView, error) {
+	now := s.now()
+	linkID, err := s.verifyToken(token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.GetLink(ctx, linkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidShareLink
+	}
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt != nil || now.After(link.ExpiresAt) {
+		return nil, ErrInvalidShareLink
+	}
+
+	access := &repository.Access{LinkID: link.ID}
+	if ipAddress != "" {
+		access.IPAddress = &ipAddress
+	}
+	if userAgent != "" {
+		access.UserAgent = &userAgent
+	}
+	if err := s.repo.RecordAccess(ctx, access); err != nil {
+		return nil, err
+	}
+
+	return &SharedView{
+		Kind:      link.Kind,
+		Title:     link.Title,
+		SharedAt:  link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+		Snapshot:  link.Snapshot,
+	}, nil
+}
+
+// Token returns the signed token for a link. Tokens aren't stored: the same link
+// always yields the same token, so the owner can copy it again later.
+func (s *Service) Token(link *repository.ShareLink) string {
+	payload := link.ID.String() + "." + strconv.FormatInt(link.ExpiresAt.Unix(), 10)
+	return payload + "." + s.signature(payload)
+}
+
+// LinkURL returns the public URL for a link
+func (s *Service) LinkURL(link *repository.ShareLink) string {
+	return s.baseURL + SharePath + s.Token(link)
+}
+
+// verifyToken returns the link a token was issued for
+func (s *Service) verifyToken(token string, now time.Time) (uuid.UUID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, ErrInvalidShareLink
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(payload))) {
+		return uuid.Nil, ErrInvalidShareLink
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return uuid.Nil, ErrInvalidShareLink
+	}
+	linkID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, ErrInvalidShareLink
+	}
+	return linkID, nil
+}
+
+func (s *Service) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("share-link:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
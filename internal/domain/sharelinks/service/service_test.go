@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/sharelinks/repository"
+)
+
+// fakeShareLinkRepository keeps links and accesses in memory
+type fakeShareLinkRepository struct {
+	links    map[uuid.UUID]*repository.ShareLink
+	accesses []*repository.Access
+}
+
+func newFakeShareLinkRepository() *fakeShareLinkRepository {
+	return &fakeShareLinkRepository{links: make(map[uuid.UUID]*repository.ShareLink)}
+}
+
+func (f *fakeShareLinkRepository) CreateLink(_ context.Context, l *repository.ShareLink) error {
+	l.ID = uuid.New()
+	l.CreatedAt = time.Now()
+	f.links[l.ID] = l
+	return nil
+}
+
+func (f *fakeShareLinkRepository) GetLink(_ context.Context, id uuid.UUID) (*repository.ShareLink, error) {
+	l, ok := f.links[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return l, nil
+}
+
+func (f *fakeShareLinkRepository) ListLinks(_ context.Context, userID uuid.UUID) ([]*repository.ShareLink, error) {
+	var links []*repository.ShareLink
+	for _, l := range f.links {
+		if l.UserID == userID {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeShareLinkRepository) RevokeLink(_ context.Context, userID, id uuid.UUID) error {
+	l, ok := f.links[id]
+	if !ok || l.UserID != userID || l.RevokedAt != nil {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	l.RevokedAt = &now
+	return nil
+}
+
+func (f *fakeShareLinkRepository) RecordAccess(_ context.Context, a *repository.Access) error {
+	a.AccessedAt = time.Now()
+	f.accesses = append(f.accesses, a)
+	f.links[a.LinkID].AccessCount++
+	return nil
+}
+
+func (f *fakeShareLinkRepository) ListAccesses(_ context.Context, linkID uuid.UUID, _ int) ([]*repository.Access, error) {
+	var accesses []*repository.Access
+	for _, a := range f.accesses {
+		if a.LinkID == linkID {
+			accesses = append(accesses, a)
+		}
+	}
+	return accesses, nil
+}
+
+// fakeSnapshotSource serves a single plan and any month's report
+type fakeSnapshotSource struct {
+	planID uuid.UUID
+}
+
+func (f *fakeSnapshotSource) PlanSnapshot(_ context.Context, _, planID uuid.UUID) (*PlanSnapshot, error) {
+	if planID != f.planID {
+		return nil, nil
+	}
+	return &PlanSnapshot{
+		Name:               "Household budget",
+		CurrencyCode:       "EUR",
+		TotalBudgetedMinor: 50000,
+		Groups: []SnapshotGroup{{
+			Name:  "Essentials",
+			Items: []SnapshotItem{{Name: "Rent", ItemType: "budget", BudgetedMinor: 50000}},
+		}},
+	}, nil
+}
+
+func (f *fakeSnapshotSource) MonthlyReportSnapshot(_ context.Context, _ uuid.UUID, monthStart time.Time) (*ReportSnapshot, error) {
+	return &ReportSnapshot{MonthStart: monthStart, TotalSpendMinor: 120000}, nil
+}
+
+func newTestService() (*Service, *fakeShareLinkRepository, uuid.UUID) {
+	repo := newFakeShareLinkRepository()
+	planID := uuid.New()
+	svc := NewService(repo, &fakeSnapshotSource{planID: planID}, []byte("secret"), "https://app.example.com/")
+	return svc, repo, planID
+}
+
+func TestCreateAndOpenShareLink(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, planID := newTestService()
+	userID := uuid.New()
+
+	link, err := svc.CreateShareLink(ctx, userID, CreateShareLinkInput{Kind: repository.LinkKindPlan, PlanID: &planID})
+	require.NoError(t, err)
+	assert.Equal(t, "Household budget", link.Title)
+	assert.WithinDuration(t, time.Now().Add(DefaultLinkTTL), link.ExpiresAt, time.Minute)
+	assert.Contains(t, svc.LinkURL(link), "https://app.example.com"+SharePath)
+
+	view, err := svc.OpenShareLink(ctx, svc.Token(link), "203.0.113.7", "Advisor/1.0")
+	require.NoError(t, err)
+	assert.Equal(t, repository.LinkKindPlan, view.Kind)
+
+	var snapshot PlanSnapshot
+	require.NoError(t, json.Unmarshal(view.Snapshot, &snapshot))
+	require.Len(t, snapshot.Groups, 1)
+	assert.Equal(t, "Rent", snapshot.Groups[0].Items[0].Name)
+
+	accesses, err := svc.ListAccesses(ctx, userID, link.ID)
+	require.NoError(t, err)
+	require.Len(t, accesses, 1)
+	assert.Equal(t, "203.0.113.7", *accesses[0].IPAddress)
+	assert.Equal(t, 1, repo.links[link.ID].AccessCount)
+
+	// Someone else's link can't be inspected
+	_, err = svc.ListAccesses(ctx, uuid.New(), link.ID)
+	assert.ErrorIs(t, err, ErrShareLinkNotFound)
+}
+
+func TestCreateShareLink_ValidatesTarget(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService()
+	userID := uuid.New()
+
+	_, err := svc.CreateShareLink(ctx, userID, CreateShareLinkInput{Kind: repository.LinkKindPlan})
+	assert.ErrorIs(t, err, ErrInvalidLinkKind)
+
+	unknown := uuid.New()
+	_, err = svc.CreateShareLink(ctx, userID, CreateShareLinkInput{Kind: repository.LinkKindPlan, PlanID: &unknown})
+	assert.ErrorIs(t, err, ErrShareTargetNotFound)
+
+	month := time.Date(2026, time.March, 17, 0, 0, 0, 0, time.UTC)
+	link, err := svc.CreateShareLink(ctx, userID, CreateShareLinkInput{
+		Kind:       repository.LinkKindMonthlyReport,
+		MonthStart: &month,
+		TTL:        365 * 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Monthly report · March 2026", link.Title)
+	assert.Equal(t, 1, link.MonthStart.Day())
+	assert.WithinDuration(t, time.Now().Add(MaxLinkTTL), link.ExpiresAt, time.Minute)
+}
+
+func TestOpenShareLink_RejectsTamperedExpiredAndRevoked(t *testing.T) {
+	ctx := context.Background()
+	svc, _, planID := newTestService()
+	userID := uuid.New()
+
+	link, err := svc.CreateShareLink(ctx, userID, CreateShareLinkInput{Kind: repository.LinkKindPlan, PlanID: &planID})
+	require.NoError(t, err)
+	token := svc.Token(link)
+
+	_, err = svc.OpenShareLink(ctx, token+"x", "", "")
+	assert.ErrorIs(t, err, ErrInvalidShareLink)
+
+	// A different secret doesn't verify
+	other := NewService(newFakeShareLinkRepository(), &fakeSnapshotSource{}, []byte("other"), "")
+	_, err = other.OpenShareLink(ctx, token, "", "")
+	assert.ErrorIs(t, err, ErrInvalidShareLink)
+
+	svc.now = func() time.Time { return link.ExpiresAt.Add(time.Minute) }
+	_, err = svc.OpenShareLink(ctx, token, "", "")
+	assert.ErrorIs(t, err, ErrInvalidShareLink)
+	svc.now = time.Now
+
+	require.NoError(t, svc.RevokeShareLink(ctx, userID, link.ID))
+	_, err = svc.OpenShareLink(ctx, token, "", "")
+	assert.ErrorIs(t, err, ErrInvalidShareLink)
+	assert.ErrorIs(t, svc.RevokeShareLink(ctx, userID, link.ID), ErrShareLinkNotFound)
+}
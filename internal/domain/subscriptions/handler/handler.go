@@ -106,7 +106,7 @@ func (h *SubscriptionsHandler) UpdateSubscriptionStatus(
 	ctx context.Context,
 	req *connect.Request[echov1.UpdateSubscriptionStatusRequest],
 ) (*connect.Response[echov1.UpdateSubscriptionStatusResponse], error) {
-	_, err := getUserID(ctx)
+	userID, err := getUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +118,7 @@ func (h *SubscriptionsHandler) UpdateSubscriptionStatus(
 
 	status := protoToStatus(req.Msg.Status)
 
-	sub, err := h.svc.UpdateStatus(ctx, subID, status)
+	sub, err := h.svc.UpdateStatus(ctx, userID, subID, status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("subscription not found"))
@@ -163,6 +163,26 @@ func (h *SubscriptionsHandler) GetSubscriptionReviewChecklist(
 	}), nil
 }
 
+// ============================================================================
+// Installment Purchases (Internal Integration)
+// ============================================================================
+// The following methods are available on the installments service but require
+// proto definitions to be exposed as API endpoints:
+//
+// - CreatePlan: split a purchase into scheduled charges (e.g. pay in 3)
+// - ListPlans / GetPlan: list a user's installment plans and their schedules
+// - CancelPlan: stop splitting a purchase
+// - UpcomingCharges: unpaid charges for cash-flow forecasts
+//
+// Scheduled charges already feed plan actuals (by due month) and the balance
+// forecast's upcoming bills; charge matching and completion run on the scheduler.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateInstallmentPlanRequest/Response
+// - ListInstallmentPlansRequest/Response
+// - CancelInstallmentPlanRequest/Response
+// - ListUpcomingInstallmentsRequest/Response
+
 // Helper functions
 
 func getUserID(ctx context.Context) (uuid.UUID, error) {
@@ -52,16 +52,16 @@ func (r *PostgresSubscriptionRepository) Create(ctx context.Context, sub *Recurr
 	return nil
 }
 
-// GetByID retrieves a subscription by ID
-func (r *PostgresSubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*RecurringSubscription, error) {
+// GetByID retrieves one of the user's subscriptions by ID
+func (r *PostgresSubscriptionRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*RecurringSubscription, error) {
 	query := `
 		SELECT id, user_id, merchant_name, amount_minor, currency_code, cadence, status,
 			first_seen_at, last_seen_at, next_expected_at, occurrence_count, created_at, updated_at
 		FROM recurring_subscriptions
-		WHERE id = $1`
+		WHERE id = $1 AND user_id = $2`
 
 	sub := &RecurringSubscription{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
 		&sub.ID,
 		&sub.UserID,
 		&sub.MerchantName,
@@ -92,7 +92,7 @@ func (r *PostgresSubscriptionRepository) Update(ctx context.Context, sub *Recurr
 		UPDATE recurring_subscriptions
 		SET merchant_name = $2, amount_minor = $3, cadence = $4, status = $5,
 			last_seen_at = $6, next_expected_at = $7, occurrence_count = $8
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $9
 		RETURNING updated_at`
 
 	err := r.pool.QueryRow(ctx, query,
@@ -104,6 +104,7 @@ func (r *PostgresSubscriptionRepository) Update(ctx context.Context, sub *Recurr
 		sub.LastSeenAt,
 		sub.NextExpectedAt,
 		sub.OccurrenceCount,
+		sub.UserID,
 	).Scan(&sub.UpdatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -115,10 +116,10 @@ func (r *PostgresSubscriptionRepository) Update(ctx context.Context, sub *Recurr
 	return nil
 }
 
-// Delete removes a subscription
-func (r *PostgresSubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM recurring_subscriptions WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, id)
+// Delete removes one of the user's subscriptions
+func (r *PostgresSubscriptionRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	query := `DELETE FROM recurring_subscriptions WHERE id = $1 AND user_id = $2`
+	result, err := r.pool.Exec(ctx, query, id, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
@@ -215,6 +216,30 @@ func (r *PostgresSubscriptionRepository) GetByUserAndMerchant(ctx context.Contex
 	return sub, nil
 }
 
+// ListUsersWithExpensesSince returns users with at least one expense since the given time (for cron jobs)
+func (r *PostgresSubscriptionRepository) ListUsersWithExpensesSince(ctx context.Context, since time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT user_id
+		FROM transactions
+		WHERE posted_at >= $1 AND amount_minor < 0 AND deleted_at IS NULL`
+
+	rows, err := r.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users with expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
+
 // GetMerchantTransactionGroups finds recurring patterns in transactions
 func (r *PostgresSubscriptionRepository) GetMerchantTransactionGroups(ctx context.Context, userID uuid.UUID, since time.Time, minOccurrences int) ([]*MerchantTransactionGroup, error) {
 	query := `
@@ -227,6 +252,7 @@ func (r *PostgresSubscriptionRepository) GetMerchantTransactionGroups(ctx contex
 			MAX(category_id) as category_id
 		FROM transactions
 		WHERE user_id = $1
+			AND deleted_at IS NULL
 			AND posted_at >= $2
 			AND amount_minor < 0  -- Only expenses
 			AND COALESCE(merchant_name, description) != ''
@@ -261,9 +287,9 @@ func (r *PostgresSubscriptionRepository) GetMerchantTransactionGroups(ctx contex
 }
 
 // UpdateStatus updates the status of a subscription
-func (r *PostgresSubscriptionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status RecurringStatus) error {
-	query := `UPDATE recurring_subscriptions SET status = $2 WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, id, status)
+func (r *PostgresSubscriptionRepository) UpdateStatus(ctx context.Context, userID, id uuid.UUID, status RecurringStatus) error {
+	query := `UPDATE recurring_subscriptions SET status = $2 WHERE id = $1 AND user_id = $3`
+	result, err := r.pool.Exec(ctx, query, id, status, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
@@ -274,12 +300,12 @@ func (r *PostgresSubscriptionRepository) UpdateStatus(ctx context.Context, id uu
 }
 
 // IncrementOccurrence increments the occurrence count and updates last seen
-func (r *PostgresSubscriptionRepository) IncrementOccurrence(ctx context.Context, id uuid.UUID, lastSeenAt time.Time) error {
+func (r *PostgresSubscriptionRepository) IncrementOccurrence(ctx context.Context, userID, id uuid.UUID, lastSeenAt time.Time) error {
 	query := `
 		UPDATE recurring_subscriptions
 		SET occurrence_count = occurrence_count + 1, last_seen_at = $2
-		WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, id, lastSeenAt)
+		WHERE id = $1 AND user_id = $3`
+	result, err := r.pool.Exec(ctx, query, id, lastSeenAt, userID)
 	if err != nil {
 		return fmt.Errorf("failed to increment occurrence: %w", err)
 	}
@@ -57,18 +57,20 @@ type MerchantTransactionGroup struct {
 
 // SubscriptionRepository defines the interface for subscription persistence
 type SubscriptionRepository interface {
-	// CRUD operations
+	// CRUD operations. Lookups by ID are scoped to the owning user and return
+	// sql.ErrNoRows for another user's subscription.
 	Create(ctx context.Context, sub *RecurringSubscription) error
-	GetByID(ctx context.Context, id uuid.UUID) (*RecurringSubscription, error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*RecurringSubscription, error)
 	Update(ctx context.Context, sub *RecurringSubscription) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
 	ListByUserID(ctx context.Context, userID uuid.UUID, statusFilter *RecurringStatus, includeCanceled bool) ([]*RecurringSubscription, error)
 
 	// Detection
 	GetByUserAndMerchant(ctx context.Context, userID uuid.UUID, merchantName string) (*RecurringSubscription, error)
 	GetMerchantTransactionGroups(ctx context.Context, userID uuid.UUID, since time.Time, minOccurrences int) ([]*MerchantTransactionGroup, error)
+	ListUsersWithExpensesSince(ctx context.Context, since time.Time) ([]uuid.UUID, error)
 
 	// Status management
-	UpdateStatus(ctx context.Context, id uuid.UUID, status RecurringStatus) error
-	IncrementOccurrence(ctx context.Context, id uuid.UUID, lastSeenAt time.Time) error
+	UpdateStatus(ctx context.Context, userID, id uuid.UUID, status RecurringStatus) error
+	IncrementOccurrence(ctx context.Context, userID, id uuid.UUID, lastSeenAt time.Time) error
 }
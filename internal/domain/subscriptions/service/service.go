@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/calendar"
 )
 
 // ReviewReason represents why a subscription should be reviewed
@@ -43,7 +44,8 @@ type DetectionResult struct {
 
 // Service provides subscription management business logic
 type Service struct {
-	repo repository.SubscriptionRepository
+	repo      repository.SubscriptionRepository
+	calendars *calendar.Resolver
 }
 
 // NewService creates a new subscriptions service
@@ -51,22 +53,29 @@ func NewService(repo repository.SubscriptionRepository) *Service {
 	return &Service{repo: repo}
 }
 
+// WithCalendars sets the resolver used to move predicted charges off weekends
+// and the user's bank holidays. Without it only weekends are skipped.
+func (s *Service) WithCalendars(calendars *calendar.Resolver) *Service {
+	s.calendars = calendars
+	return s
+}
+
 // ListSubscriptions retrieves all subscriptions for a user
 func (s *Service) ListSubscriptions(ctx context.Context, userID uuid.UUID, statusFilter *repository.RecurringStatus, includeCanceled bool) ([]*repository.RecurringSubscription, error) {
 	return s.repo.ListByUserID(ctx, userID, statusFilter, includeCanceled)
 }
 
-// GetSubscription retrieves a subscription by ID
-func (s *Service) GetSubscription(ctx context.Context, id uuid.UUID) (*repository.RecurringSubscription, error) {
-	return s.repo.GetByID(ctx, id)
+// GetSubscription retrieves one of the user's subscriptions by ID
+func (s *Service) GetSubscription(ctx context.Context, userID, id uuid.UUID) (*repository.RecurringSubscription, error) {
+	return s.repo.GetByID(ctx, userID, id)
 }
 
-// UpdateStatus updates the status of a subscription
-func (s *Service) UpdateStatus(ctx context.Context, id uuid.UUID, status repository.RecurringStatus) (*repository.RecurringSubscription, error) {
-	if err := s.repo.UpdateStatus(ctx, id, status); err != nil {
+// UpdateStatus updates the status of one of the user's subscriptions
+func (s *Service) UpdateStatus(ctx context.Context, userID, id uuid.UUID, status repository.RecurringStatus) (*repository.RecurringSubscription, error) {
+	if err := s.repo.UpdateStatus(ctx, userID, id, status); err != nil {
 		return nil, err
 	}
-	return s.repo.GetByID(ctx, id)
+	return s.repo.GetByID(ctx, userID, id)
 }
 
 // DetectSubscriptions analyzes transaction history to find recurring patterns
@@ -84,6 +93,7 @@ func (s *Service) DetectSubscriptions(ctx context.Context, userID uuid.UUID, sin
 	result := &DetectionResult{
 		Detected: make([]*repository.RecurringSubscription, 0),
 	}
+	cal := s.calendars.ForUser(ctx, userID)
 
 	for _, group := range groups {
 		// Analyze the pattern to determine cadence
@@ -108,7 +118,7 @@ func (s *Service) DetectSubscriptions(ctx context.Context, userID uuid.UUID, sin
 
 		firstSeen := group.TransactionDates[0]
 		lastSeen := group.TransactionDates[len(group.TransactionDates)-1]
-		nextExpected := s.calculateNextExpected(lastSeen, cadence)
+		nextExpected := s.calculateNextExpected(cal, group.TransactionDates, cadence)
 
 		if existing != nil {
 			// Update existing subscription
@@ -149,6 +159,28 @@ func (s *Service) DetectSubscriptions(ctx context.Context, userID uuid.UUID, sin
 	return result, nil
 }
 
+// DetectSubscriptionsForAllUsers runs detection for every user with recent expenses.
+// Per-user failures are skipped so one bad account doesn't stop the batch.
+func (s *Service) DetectSubscriptionsForAllUsers(ctx context.Context, since time.Time, minOccurrences int) (usersScanned, detected int, err error) {
+	userIDs, err := s.repo.ListUsersWithExpensesSince(ctx, since)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return usersScanned, detected, ctx.Err()
+		}
+		result, err := s.DetectSubscriptions(ctx, userID, since, minOccurrences)
+		if err != nil {
+			continue
+		}
+		usersScanned++
+		detected += result.NewCount
+	}
+	return usersScanned, detected, nil
+}
+
 // detectCadence analyzes transaction dates to determine the recurring pattern
 func (s *Service) detectCadence(dates []time.Time) (repository.RecurringCadence, float64) {
 	if len(dates) < 2 {
@@ -236,24 +268,60 @@ func (s *Service) calculateAmountStats(amounts []int64) (avgAmount int64, varian
 	return int64(avg), variance
 }
 
-// calculateNextExpected predicts when the next charge will occur
-func (s *Service) calculateNextExpected(lastSeen time.Time, cadence repository.RecurringCadence) *time.Time {
+// calculateNextExpected predicts when the next charge will occur. Monthly and
+// longer cadences are anchored on the usual billing day rather than the last
+// posting date, so a charge that slid past a weekend doesn't drift the schedule;
+// the prediction is then moved to the next business day, as banks post it.
+func (s *Service) calculateNextExpected(cal *calendar.Calendar, dates []time.Time, cadence repository.RecurringCadence) *time.Time {
+	lastSeen := dates[len(dates)-1]
+
 	var next time.Time
 	switch cadence {
 	case repository.RecurringCadenceWeekly:
 		next = lastSeen.AddDate(0, 0, 7)
-	case repository.RecurringCadenceMonthly:
-		next = lastSeen.AddDate(0, 1, 0)
 	case repository.RecurringCadenceQuarterly:
-		next = lastSeen.AddDate(0, 3, 0)
+		next = nextAnchored(dates, 3)
 	case repository.RecurringCadenceAnnual:
-		next = lastSeen.AddDate(1, 0, 0)
+		next = nextAnchored(dates, 12)
 	default:
-		next = lastSeen.AddDate(0, 1, 0) // Default to monthly
+		next = nextAnchored(dates, 1) // Default to monthly
 	}
+
+	next = cal.Adjust(next, calendar.Following)
 	return &next
 }
 
+// nextAnchored returns the billing anchor day, months after the last charge's
+// billing month
+func nextAnchored(dates []time.Time, months int) time.Time {
+	lastSeen := dates[len(dates)-1]
+	anchor := anchorDay(dates)
+
+	// A charge due late in the month can post early the next month; bill from
+	// the month it was due in
+	billed := time.Date(lastSeen.Year(), lastSeen.Month(), 1, lastSeen.Hour(), lastSeen.Minute(), lastSeen.Second(), 0, lastSeen.Location())
+	if anchor-lastSeen.Day() > 20 {
+		billed = billed.AddDate(0, -1, 0)
+	}
+	return calendar.WithDayClamped(billed.AddDate(0, months, 0), anchor)
+}
+
+// anchorDay returns the most common day of month across the charges, preferring
+// the earliest day on ties since postings only ever slide forward
+func anchorDay(dates []time.Time) int {
+	counts := make(map[int]int, len(dates))
+	anchor := dates[len(dates)-1].Day()
+	for _, d := range dates {
+		counts[d.Day()]++
+	}
+	for day, count := range counts {
+		if count > counts[anchor] || (count == counts[anchor] && day < anchor) {
+			anchor = day
+		}
+	}
+	return anchor
+}
+
 // GetReviewChecklist returns subscriptions that should be reviewed
 func (s *Service) GetReviewChecklist(ctx context.Context, userID uuid.UUID) ([]*SubscriptionReviewItem, int64, error) {
 	subs, err := s.repo.ListByUserID(ctx, userID, nil, false)
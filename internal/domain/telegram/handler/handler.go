@@ -0,0 +1,52 @@
+// Package handler receives Telegram bot updates over HTTP.
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/telegram"
+)
+
+// maxUpdateSize caps the body of an update
+const maxUpdateSize = 1 << 20
+
+// WebhookHandler accepts the updates Telegram posts to the bot's webhook
+type WebhookHandler struct {
+	svc    *service.Service
+	secret string
+	logger *slog.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler. Requests must carry secret,
+// the token passed to setWebhook.
+func NewWebhookHandler(svc *service.Service, secret string, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{svc: svc, secret: secret, logger: logger}
+}
+
+// ServeHTTP answers POST WebhookPath. Updates that fail are logged and still
+// acknowledged, since Telegram would otherwise redeliver them.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+	if !telegram.VerifySecret(r, h.secret) {
+		http.Error(w, "Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+
+	var update telegram.Update
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxUpdateSize)).Decode(&update); err != nil {
+		http.Error(w, "Invalid update.", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.HandleUpdate(r.Context(), &update); err != nil {
+		h.logger.Error("failed to handle telegram update", slog.Int64("update_id", update.UpdateID), slog.Any("error", err))
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresTelegramRepository implements TelegramRepository using PostgreSQL
+type PostgresTelegramRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresTelegramRepository creates a new PostgreSQL Telegram repository
+func NewPostgresTelegramRepository(pool *pgxpool.Pool) *PostgresTelegramRepository {
+	return &PostgresTelegramRepository{pool: pool}
+}
+
+// SaveLinkCode stores the hash of a new link code, replacing the user's previous one
+func (r *PostgresTelegramRepository) SaveLinkCode(ctx context.Context, userID uuid.UUID, codeHash string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO telegram_link_codes (code_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			code_hash = EXCLUDED.code_hash,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW()`, codeHash, userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save telegram link code: %w", err)
+	}
+	return nil
+}
+
+// ConsumeLinkCode deletes an unexpired code and returns its user
+func (r *PostgresTelegramRepository) ConsumeLinkCode(ctx context.Context, codeHash string, now time.Time) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		DELETE FROM telegram_link_codes
+		WHERE code_hash = $1 AND expires_at > $2
+		RETURNING user_id`, codeHash, now).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to consume telegram link code: %w", err)
+	}
+	return userID, nil
+}
+
+// SaveLink links the chat to the user, replacing the user's previous chat and
+// any other account the chat was linked to
+func (r *PostgresTelegramRepository) SaveLink(ctx context.Context, link *Link) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM telegram_links WHERE chat_id = $1 AND user_id <> $2`, link.ChatID, link.UserID); err != nil {
+		return fmt.Errorf("failed to unlink telegram chat: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO telegram_links (user_id, chat_id, username)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			chat_id = EXCLUDED.chat_id,
+			username = EXCLUDED.username,
+			linked_at = NOW()
+		RETURNING linked_at`, link.UserID, link.ChatID, link.Username).Scan(&link.LinkedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save telegram link: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit telegram link: %w", err)
+	}
+	return nil
+}
+
+// GetLinkByUser returns the chat linked to the user
+func (r *PostgresTelegramRepository) GetLinkByUser(ctx context.Context, userID uuid.UUID) (*Link, error) {
+	return r.getLink(ctx, `user_id = $1`, userID)
+}
+
+// GetLinkByChat returns the user the chat is linked to
+func (r *PostgresTelegramRepository) GetLinkByChat(ctx context.Context, chatID int64) (*Link, error) {
+	return r.getLink(ctx, `chat_id = $1`, chatID)
+}
+
+func (r *PostgresTelegramRepository) getLink(ctx context.Context, where string, arg any) (*Link, error) {
+	link := &Link{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id, chat_id, username, linked_at
+		FROM telegram_links
+		WHERE `+where, arg).Scan(&link.UserID, &link.ChatID, &link.Username, &link.LinkedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telegram link: %w", err)
+	}
+	return link, nil
+}
+
+// DeleteLinkByChat unlinks a chat
+func (r *PostgresTelegramRepository) DeleteLinkByChat(ctx context.Context, chatID int64) error {
+	return r.deleteLink(ctx, `chat_id = $1`, chatID)
+}
+
+// DeleteLinkByUser unlinks the user's chat
+func (r *PostgresTelegramRepository) DeleteLinkByUser(ctx context.Context, userID uuid.UUID) error {
+	return r.deleteLink(ctx, `user_id = $1`, userID)
+}
+
+func (r *PostgresTelegramRepository) deleteLink(ctx context.Context, where string, arg any) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM telegram_links WHERE `+where, arg)
+	if err != nil {
+		return fmt.Errorf("failed to delete telegram link: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
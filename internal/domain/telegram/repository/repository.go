@@ -0,0 +1,38 @@
+// Package repository provides database operations for Telegram chat links and link codes.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Link connects a user's account to a Telegram chat
+type Link struct {
+	UserID   uuid.UUID
+	ChatID   int64
+	Username *string // Telegram username at link time, if the user has one
+	LinkedAt time.Time
+}
+
+// TelegramRepository defines the interface for Telegram link persistence
+type TelegramRepository interface {
+	// Link codes
+	// SaveLinkCode stores the hash of a new link code, replacing the user's previous one
+	SaveLinkCode(ctx context.Context, userID uuid.UUID, codeHash string, expiresAt time.Time) error
+	// ConsumeLinkCode deletes an unexpired code and returns its user.
+	// Returns sql.ErrNoRows for unknown, used or expired codes.
+	ConsumeLinkCode(ctx context.Context, codeHash string, now time.Time) (uuid.UUID, error)
+
+	// Links
+	// SaveLink links the chat to the user, replacing the user's previous chat
+	// and any other account the chat was linked to
+	SaveLink(ctx context.Context, link *Link) error
+	GetLinkByUser(ctx context.Context, userID uuid.UUID) (*Link, error)
+	GetLinkByChat(ctx context.Context, chatID int64) (*Link, error)
+	// DeleteLinkByChat unlinks a chat; returns sql.ErrNoRows if it wasn't linked
+	DeleteLinkByChat(ctx context.Context, chatID int64) error
+	// DeleteLinkByUser unlinks the user's chat; returns sql.ErrNoRows if none was linked
+	DeleteLinkByUser(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,364 @@
+// Package service provides business logic for the Telegram bot: linking a chat
+// to an account with one-time codes and recording Quick Capture transactions
+// sent as messages. Alerts reach linked chats through the notifications
+// service's Telegram channel.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/telegram"
+)
+
+// =============================================================================
+// Telegram Bot (Internal Integration)
+// =============================================================================
+// Telegram posts updates to WebhookPath over plain HTTP. Issuing link codes,
+// reading and removing the link are available on the service but require proto
+// definitions to be exposed as API endpoints.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateTelegramLinkCodeRequest/Response (TelegramService.CreateLinkCode)
+// - GetTelegramLinkRequest/Response, UnlinkTelegramRequest/Response
+// - TelegramLink, TelegramLinkCode
+
+// WebhookPath is the route Telegram delivers bot updates to
+const WebhookPath = "/telegram/webhook"
+
+const (
+	// LinkCodeTTL is how long a link code can be used
+	LinkCodeTTL = 10 * time.Minute
+	// linkCodeLength is the number of characters in a link code
+	linkCodeLength = 8
+	// linkCodeAlphabet leaves out characters that are easily confused
+	linkCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// maxLinkAttempts is how many wrong codes a chat may send per linkAttemptWindow
+	maxLinkAttempts   = 5
+	linkAttemptWindow = 15 * time.Minute
+)
+
+var (
+	// ErrNotLinked is returned when the user has no linked chat
+	ErrNotLinked = errors.New("no telegram chat linked")
+	// ErrBotDisabled is returned when no bot is configured
+	ErrBotDisabled = errors.New("telegram bot is not configured")
+)
+
+// Bot replies
+const (
+	replyHelp = "Send a purchase like \"coffee 2.5€\" and I'll record it. " +
+		"Alerts about your budget pace and subscriptions arrive here too.\n\n" +
+		"/link <code> - link this chat with a code from the app\n" +
+		"/unlink - stop using this chat\n" +
+		"/help - show this message"
+	replyNotLinked    = "This chat isn't linked to an account yet. Get a code in the app and send /link <code>."
+	replyLinked       = "Linked! Send a purchase like \"coffee 2.5€\" to record it."
+	replyInvalidCode  = "That code is invalid or has expired. Get a new one in the app."
+	replyTooManyCodes = "Too many wrong codes. Wait a few minutes and try again."
+	replyUnlinked     = "This chat is no longer linked. Alerts and Quick Capture are off."
+	replyNoAmount     = "I couldn't find an amount. Try something like \"coffee 2.5€\"."
+	replyFailure      = "Sorry, something went wrong. Please try again."
+	replyPrivateOnly  = "I only work in private chats."
+)
+
+// CapturedTransaction is a transaction recorded from a bot message
+type CapturedTransaction struct {
+	Description  string
+	AmountMinor  int64 // Negative for expenses
+	CurrencyCode string
+}
+
+// QuickCapture records a transaction from Quick Capture text
+type QuickCapture interface {
+	// Capture returns nil without an error when the text holds no amount
+	Capture(ctx context.Context, userID uuid.UUID, text string) (*CapturedTransaction, error)
+}
+
+// MessageSender sends bot messages
+type MessageSender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// LinkCode is a one-time code the user sends to the bot to link a chat
+type LinkCode struct {
+	Code      string
+	ExpiresAt time.Time
+	DeepLink  string // t.me link that sends the code on open; empty without a bot username
+}
+
+// linkAttempts counts a chat's wrong codes in the current window
+type linkAttempts struct {
+	count       int
+	windowStart time.Time
+}
+
+// Service links Telegram chats to accounts and answers bot messages
+type Service struct {
+	repo        repository.TelegramRepository
+	capture     QuickCapture
+	sender      MessageSender
+	botUsername string
+	logger      *slog.Logger
+	now         func() time.Time
+
+	mu       sync.Mutex
+	attempts map[int64]*linkAttempts
+}
+
+// NewService creates a new Telegram bot service. sender may be nil when no
+// bot is configured; updates are then ignored.
+func NewService(repo repository.TelegramRepository, capture QuickCapture, sender MessageSender, logger *slog.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		capture:  capture,
+		sender:   sender,
+		logger:   logger,
+		now:      time.Now,
+		attempts: make(map[int64]*linkAttempts),
+	}
+}
+
+// WithBotUsername adds t.me deep links to link codes
+func (s *Service) WithBotUsername(username string) *Service {
+	s.botUsername = strings.TrimPrefix(username, "@")
+	return s
+}
+
+// CreateLinkCode issues a one-time code that links the chat it is sent from to
+// the user, replacing any code issued before
+func (s *Service) CreateLinkCode(ctx context.Context, userID uuid.UUID) (*LinkCode, error) {
+	if s.sender == nil {
+		return nil, ErrBotDisabled
+	}
+	code, err := generateLinkCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := s.now().Add(LinkCodeTTL)
+	if err := s.repo.SaveLinkCode(ctx, userID, hashLinkCode(code), expiresAt); err != nil {
+		return nil, err
+	}
+
+	linkCode := &LinkCode{Code: code, ExpiresAt: expiresAt}
+	if s.botUsername != "" {
+		linkCode.DeepLink = "https://t.me/" + s.botUsername + "?start=" + code
+	}
+	return linkCode, nil
+}
+
+// GetLink returns the user's linked chat
+func (s *Service) GetLink(ctx context.Context, userID uuid.UUID) (*repository.Link, error) {
+	link, err := s.repo.GetLinkByUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotLinked
+	}
+	return link, err
+}
+
+// Unlink stops alerts and Quick Capture through the user's chat
+func (s *Service) Unlink(ctx context.Context, userID uuid.UUID) error {
+	err := s.repo.DeleteLinkByUser(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotLinked
+	}
+	return err
+}
+
+// HandleUpdate answers a message sent to the bot. Commands manage the link;
+// any other text from a linked chat is recorded as a Quick Capture transaction.
+func (s *Service) HandleUpdate(ctx context.Context, update *telegram.Update) error {
+	if s.sender == nil || update.Message == nil {
+		return nil
+	}
+	msg := update.Message
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		return nil
+	}
+	if msg.Chat.Type != "private" {
+		return s.reply(ctx, msg.Chat.ID, replyPrivateOnly)
+	}
+
+	command, arg := splitCommand(text)
+	switch command {
+	case "/start", "/link":
+		if arg == "" {
+			return s.reply(ctx, msg.Chat.ID, replyHelp)
+		}
+		return s.reply(ctx, msg.Chat.ID, s.linkChat(ctx, msg, arg))
+	case "/unlink":
+		if err := s.repo.DeleteLinkByChat(ctx, msg.Chat.ID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return s.reply(ctx, msg.Chat.ID, replyUnlinked)
+	case "":
+	default: // "/help" and unknown commands
+		return s.reply(ctx, msg.Chat.ID, replyHelp)
+	}
+
+	link, err := s.repo.GetLinkByChat(ctx, msg.Chat.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.reply(ctx, msg.Chat.ID, replyNotLinked)
+	}
+	if err != nil {
+		return err
+	}
+	return s.reply(ctx, msg.Chat.ID, s.captureText(ctx, link.UserID, text))
+}
+
+// linkChat links the chat with a code and returns the reply
+func (s *Service) linkChat(ctx context.Context, msg *telegram.Message, code string) string {
+	if !s.allowLinkAttempt(msg.Chat.ID) {
+		return replyTooManyCodes
+	}
+
+	userID, err := s.repo.ConsumeLinkCode(ctx, hashLinkCode(normalizeLinkCode(code)), s.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		s.recordFailedLinkAttempt(msg.Chat.ID)
+		return replyInvalidCode
+	}
+	if err != nil {
+		s.logError("failed to consume telegram link code", err)
+		return replyFailure
+	}
+
+	link := &repository.Link{UserID: userID, ChatID: msg.Chat.ID}
+	if msg.From != nil && msg.From.Username != "" {
+		link.Username = &msg.From.Username
+	}
+	if err := s.repo.SaveLink(ctx, link); err != nil {
+		s.logError("failed to link telegram chat", err)
+		return replyFailure
+	}
+	s.clearLinkAttempts(msg.Chat.ID)
+	return replyLinked
+}
+
+// captureText records the text as a transaction and returns the reply
+func (s *Service) captureText(ctx context.Context, userID uuid.UUID, text string) string {
+	tx, err := s.capture.Capture(ctx, userID, text)
+	if err != nil {
+		s.logError("failed to capture telegram message", err)
+		return replyFailure
+	}
+	if tx == nil {
+		return replyNoAmount
+	}
+	kind := "expense"
+	amount := tx.AmountMinor
+	if amount > 0 {
+		kind = "income"
+	} else {
+		amount = -amount
+	}
+	return fmt.Sprintf("Recorded %s: %s %d.%02d %s", kind, tx.Description, amount/100, amount%100, tx.CurrencyCode)
+}
+
+// reply sends text to the chat, unlinking chats that blocked the bot
+func (s *Service) reply(ctx context.Context, chatID int64, text string) error {
+	err := s.sender.SendMessage(ctx, chatID, text)
+	if errors.Is(err, telegram.ErrChatBlocked) {
+		if err := s.repo.DeleteLinkByChat(ctx, chatID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return nil
+	}
+	return err
+}
+
+// allowLinkAttempt reports whether the chat may try another code
+func (s *Service) allowLinkAttempt(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attempts[chatID]
+	if !ok || s.now().Sub(a.windowStart) >= linkAttemptWindow {
+		return true
+	}
+	return a.count < maxLinkAttempts
+}
+
+func (s *Service) recordFailedLinkAttempt(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	a, ok := s.attempts[chatID]
+	if !ok || now.Sub(a.windowStart) >= linkAttemptWindow {
+		// Drop stale windows so the map doesn't grow with every chat seen
+		for id, other := range s.attempts {
+			if now.Sub(other.windowStart) >= linkAttemptWindow {
+				delete(s.attempts, id)
+			}
+		}
+		a = &linkAttempts{windowStart: now}
+		s.attempts[chatID] = a
+	}
+	a.count++
+}
+
+func (s *Service) clearLinkAttempts(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, chatID)
+}
+
+func (s *Service) logError(msg string, err error) {
+	if s.logger != nil {
+		s.logger.Error(msg, slog.Any("error", err))
+	}
+}
+
+// splitCommand splits "/link@EchoBot ABC" into ("/link", "ABC"); text that
+// isn't a command returns an empty command
+func splitCommand(text string) (string, string) {
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+	command, arg, _ := strings.Cut(text, " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(arg)
+}
+
+// generateLinkCode returns a random code from linkCodeAlphabet
+func generateLinkCode() (string, error) {
+	var b strings.Builder
+	size := big.NewInt(int64(len(linkCodeAlphabet)))
+	for range linkCodeLength {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate link code: %w", err)
+		}
+		b.WriteByte(linkCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeLinkCode accepts codes typed in lowercase or with separators
+func normalizeLinkCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+// hashLinkCode returns the hex SHA-256 of a code; codes are stored only hashed
+func hashLinkCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
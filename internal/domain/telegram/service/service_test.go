@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/telegram/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/telegram"
+)
+
+// fakeTelegramRepository keeps codes and links in memory
+type fakeTelegramRepository struct {
+	codes map[string]fakeLinkCode // Keyed by hash
+	links map[int64]*repository.Link
+}
+
+type fakeLinkCode struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+func newFakeTelegramRepository() *fakeTelegramRepository {
+	return &fakeTelegramRepository{codes: make(map[string]fakeLinkCode), links: make(map[int64]*repository.Link)}
+}
+
+func (f *fakeTelegramRepository) SaveLinkCode(_ context.Context, userID uuid.UUID, codeHash string, expiresAt time.Time) error {
+	for hash, c := range f.codes {
+		if c.userID == userID {
+			delete(f.codes, hash)
+		}
+	}
+	f.codes[codeHash] = fakeLinkCode{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeTelegramRepository) ConsumeLinkCode(_ context.Context, codeHash string, now time.Time) (uuid.UUID, error) {
+	c, ok := f.codes[codeHash]
+	if !ok || !c.expiresAt.After(now) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	delete(f.codes, codeHash)
+	return c.userID, nil
+}
+
+func (f *fakeTelegramRepository) SaveLink(_ context.Context, link *repository.Link) error {
+	for chatID, l := range f.links {
+		if l.UserID == link.UserID {
+			delete(f.links, chatID)
+		}
+	}
+	f.links[link.ChatID] = link
+	return nil
+}
+
+func (f *fakeTelegramRepository) GetLinkByUser(_ context.Context, userID uuid.UUID) (*repository.Link, error) {
+	for _, l := range f.links {
+		if l.UserID == userID {
+			return l, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeTelegramRepository) GetLinkByChat(_ context.Context, chatID int64) (*repository.Link, error) {
+	if l, ok := f.links[chatID]; ok {
+		return l, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeTelegramRepository) DeleteLinkByChat(_ context.Context, chatID int64) error {
+	if _, ok := f.links[chatID]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(f.links, chatID)
+	return nil
+}
+
+func (f *fakeTelegramRepository) DeleteLinkByUser(ctx context.Context, userID uuid.UUID) error {
+	l, err := f.GetLinkByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	delete(f.links, l.ChatID)
+	return nil
+}
+
+// fakeCapture records "coffee 2.5" style texts as expenses
+type fakeCapture struct {
+	captured map[uuid.UUID][]string
+}
+
+func (f *fakeCapture) Capture(_ context.Context, userID uuid.UUID, text string) (*CapturedTransaction, error) {
+	if !strings.ContainsAny(text, "0123456789") {
+		return nil, nil
+	}
+	f.captured[userID] = append(f.captured[userID], text)
+	return &CapturedTransaction{Description: "Coffee", AmountMinor: -250, CurrencyCode: "EUR"}, nil
+}
+
+// fakeSender records the replies sent per chat
+type fakeSender struct {
+	sent    map[int64][]string
+	blocked map[int64]bool
+}
+
+func (f *fakeSender) SendMessage(_ context.Context, chatID int64, text string) error {
+	if f.blocked[chatID] {
+		return telegram.ErrChatBlocked
+	}
+	f.sent[chatID] = append(f.sent[chatID], text)
+	return nil
+}
+
+func (f *fakeSender) last(chatID int64) string {
+	msgs := f.sent[chatID]
+	if len(msgs) == 0 {
+		return ""
+	}
+	return msgs[len(msgs)-1]
+}
+
+func newTestService() (*Service, *fakeTelegramRepository, *fakeCapture, *fakeSender) {
+	repo := newFakeTelegramRepository()
+	capture := &fakeCapture{captured: make(map[uuid.UUID][]string)}
+	sender := &fakeSender{sent: make(map[int64][]string), blocked: make(map[int64]bool)}
+	return NewService(repo, capture, sender, nil), repo, capture, sender
+}
+
+func message(chatID int64, text string) *telegram.Update {
+	return &telegram.Update{Message: &telegram.Message{
+		Chat: telegram.Chat{ID: chatID, Type: "private"},
+		From: &telegram.User{ID: chatID, Username: "ana"},
+		Text: text,
+	}}
+}
+
+func TestLinkAndCapture(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, capture, sender := newTestService()
+	svc.WithBotUsername("@EchoBot")
+	userID := uuid.New()
+	const chatID = 42
+
+	// Unlinked chats are told how to link
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "coffee 2.5€")))
+	assert.Equal(t, replyNotLinked, sender.last(chatID))
+
+	code, err := svc.CreateLinkCode(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, code.Code, linkCodeLength)
+	assert.Equal(t, "https://t.me/EchoBot?start="+code.Code, code.DeepLink)
+	for hash := range repo.codes {
+		assert.NotContains(t, hash, code.Code, "codes are stored hashed")
+	}
+
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/start "+strings.ToLower(code.Code))))
+	assert.Equal(t, replyLinked, sender.last(chatID))
+	link, err := svc.GetLink(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(chatID), link.ChatID)
+	require.NotNil(t, link.Username)
+	assert.Equal(t, "ana", *link.Username)
+
+	// Codes are single-use
+	require.NoError(t, svc.HandleUpdate(ctx, message(7, "/link "+code.Code)))
+	assert.Equal(t, replyInvalidCode, sender.last(7))
+
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "coffee 2.5€")))
+	assert.Equal(t, "Recorded expense: Coffee 2.50 EUR", sender.last(chatID))
+	assert.Equal(t, []string{"coffee 2.5€"}, capture.captured[userID])
+
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "just a coffee")))
+	assert.Equal(t, replyNoAmount, sender.last(chatID))
+
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/unlink")))
+	assert.Equal(t, replyUnlinked, sender.last(chatID))
+	_, err = svc.GetLink(ctx, userID)
+	assert.ErrorIs(t, err, ErrNotLinked)
+}
+
+func TestLinkCode_ExpiresAndLimitsAttempts(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, sender := newTestService()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+	userID := uuid.New()
+	const chatID = 42
+
+	code, err := svc.CreateLinkCode(ctx, userID)
+	require.NoError(t, err)
+	for range maxLinkAttempts {
+		require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/link WRONGCOD")))
+		assert.Equal(t, replyInvalidCode, sender.last(chatID))
+	}
+
+	// Even the right code is refused once a chat used up its attempts
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/link "+code.Code)))
+	assert.Equal(t, replyTooManyCodes, sender.last(chatID))
+
+	// By the time the window passes, the code has expired too
+	now = now.Add(linkAttemptWindow)
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/link "+code.Code)))
+	assert.Equal(t, replyInvalidCode, sender.last(chatID))
+
+	code, err = svc.CreateLinkCode(ctx, userID)
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleUpdate(ctx, message(chatID, "/link "+code.Code)))
+	assert.Equal(t, replyLinked, sender.last(chatID))
+}
+
+func TestHandleUpdate_UnlinksBlockedChats(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, sender := newTestService()
+	userID := uuid.New()
+	require.NoError(t, repo.SaveLink(ctx, &repository.Link{UserID: userID, ChatID: 42}))
+
+	sender.blocked[42] = true
+	require.NoError(t, svc.HandleUpdate(ctx, message(42, "coffee 2.5€")))
+	_, err := svc.GetLink(ctx, userID)
+	assert.ErrorIs(t, err, ErrNotLinked)
+
+	group := message(43, "coffee 2.5€")
+	group.Message.Chat.Type = "group"
+	require.NoError(t, svc.HandleUpdate(ctx, group))
+	assert.Equal(t, replyPrivateOnly, sender.last(43))
+}
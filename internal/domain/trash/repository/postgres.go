@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// trashItems selects the deleted rows of every trashed table as items
+const trashItems = `
+	SELECT 'transaction' AS entity_type, id, COALESCE(NULLIF(merchant_name, ''), description)::TEXT AS name,
+	       amount_minor, currency_code::TEXT, deleted_at
+	FROM transactions WHERE user_id = $1 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'plan', id, name::TEXT, NULL, COALESCE(currency_code, '')::TEXT, deleted_at
+	FROM user_plans WHERE user_id = $1 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'goal', id, name::TEXT, target_amount_minor, currency_code::TEXT, deleted_at
+	FROM goals WHERE user_id = $1 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'rule', id, match_pattern, NULL, '', deleted_at
+	FROM category_rules WHERE user_id = $1 AND deleted_at IS NOT NULL`
+
+// restoreQueries undelete an item of each entity type. Plans come back as
+// drafts so they can't clash with the user's active plan.
+var restoreQueries = map[string]string{
+	EntityTransaction: `UPDATE transactions SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+	EntityPlan:        `UPDATE user_plans SET deleted_at = NULL, status = 'draft', updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+	EntityGoal:        `UPDATE goals SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+	EntityRule:        `UPDATE category_rules SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`,
+}
+
+// PostgresTrashRepository implements TrashRepository using PostgreSQL
+type PostgresTrashRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresTrashRepository creates a new PostgreSQL trash repository
+func NewPostgresTrashRepository(pool *pgxpool.Pool) *PostgresTrashRepository {
+	return &PostgresTrashRepository{pool: pool}
+}
+
+// ListTrash lists a user's deleted items, most recently deleted first
+func (r *PostgresTrashRepository) ListTrash(ctx context.Context, userID uuid.UUID, entityType string, limit int) ([]*Item, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT entity_type, id, name, amount_minor, currency_code, deleted_at
+		FROM (`+trashItems+`) trash
+		WHERE $2 = '' OR entity_type = $2
+		ORDER BY deleted_at DESC, id
+		LIMIT $3`, userID, entityType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*Item
+	for rows.Next() {
+		item := &Item{}
+		if err := rows.Scan(&item.EntityType, &item.ID, &item.Name, &item.AmountMinor, &item.CurrencyCode, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Restore undeletes an item in the user's trash
+func (r *PostgresTrashRepository) Restore(ctx context.Context, userID uuid.UUID, entityType string, id uuid.UUID) error {
+	query, ok := restoreQueries[entityType]
+	if !ok {
+		return fmt.Errorf("entity type %q has no trash", entityType)
+	}
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w", entityType, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Purge permanently deletes everything deleted before the given time. Rows
+// referencing purged ones are removed or unlinked by their foreign keys.
+func (r *PostgresTrashRepository) Purge(ctx context.Context, before time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+	steps := []struct {
+		table string
+		count *int64
+	}{
+		{"transactions", &result.Transactions},
+		{"user_plans", &result.Plans},
+		{"goals", &result.Goals},
+		{"category_rules", &result.Rules},
+	}
+	for _, step := range steps {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM `+step.table+` WHERE deleted_at < $1`, before)
+		if err != nil {
+			return result, fmt.Errorf("failed to purge %s: %w", step.table, err)
+		}
+		*step.count = tag.RowsAffected()
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+// Package repository provides database operations for deleted items awaiting purge.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entity types that go to the trash when deleted
+const (
+	EntityTransaction = "transaction"
+	EntityPlan        = "plan"
+	EntityGoal        = "goal"
+	EntityRule        = "rule"
+)
+
+// Item is a deleted entity that can still be restored
+type Item struct {
+	EntityType   string
+	ID           uuid.UUID
+	Name         string // Merchant or description, plan or goal name, rule pattern
+	AmountMinor  *int64 // Transaction amount or goal target, nil for plans and rules
+	CurrencyCode string
+	DeletedAt    time.Time
+}
+
+// PurgeResult counts the rows purged from the trash, per entity type
+type PurgeResult struct {
+	Transactions int64
+	Plans        int64
+	Goals        int64
+	Rules        int64
+}
+
+// Total returns the number of rows purged
+func (r *PurgeResult) Total() int64 {
+	return r.Transactions + r.Plans + r.Goals + r.Rules
+}
+
+// TrashRepository defines the interface for trash persistence
+type TrashRepository interface {
+	// ListTrash lists a user's deleted items, most recently deleted first. An
+	// empty entityType lists all types.
+	ListTrash(ctx context.Context, userID uuid.UUID, entityType string, limit int) ([]*Item, error)
+	// Restore undeletes an item; sql.ErrNoRows if it isn't in the user's trash
+	Restore(ctx context.Context, userID uuid.UUID, entityType string, id uuid.UUID) error
+	// Purge permanently deletes everything deleted before the given time
+	Purge(ctx context.Context, before time.Time) (*PurgeResult, error)
+}
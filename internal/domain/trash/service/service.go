@@ -0,0 +1,142 @@
+// Package service provides business logic for the trash of deleted items.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/trash/repository"
+)
+
+// =============================================================================
+// Trash (Internal Integration)
+// =============================================================================
+// Deleting a transaction, plan, goal or categorization rule moves it to the
+// trash instead of removing it. Deleted items are left out of lists, totals
+// and insights, can be restored until they've been in the trash for
+// PurgeAfter, and are then purged for good by the trash purge job.
+//
+// To expose as API endpoints, add the following proto definitions:
+// - ListTrashRequest/Response (FinanceService.ListTrash) with TrashItem
+// - RestoreTrashItemRequest/Response (FinanceService.RestoreTrashItem)
+
+const (
+	// PurgeAfter is how long deleted items stay restorable
+	PurgeAfter = 30 * 24 * time.Hour
+	// DefaultListLimit is the number of items listed when no limit is given
+	DefaultListLimit = 100
+	// MaxListLimit caps the items listed at once
+	MaxListLimit = 500
+)
+
+var (
+	// ErrUnknownEntityType is returned for entity types that have no trash
+	ErrUnknownEntityType = errors.New("unknown entity type")
+	// ErrNotInTrash is returned when restoring an item that isn't in the user's trash
+	ErrNotInTrash = errors.New("item not found in trash")
+)
+
+// entityTypes are the entity types with a trash
+var entityTypes = map[string]bool{
+	repository.EntityTransaction: true,
+	repository.EntityPlan:        true,
+	repository.EntityGoal:        true,
+	repository.EntityRule:        true,
+}
+
+// Item is a deleted entity and when it will be purged
+type Item struct {
+	*repository.Item
+	PurgeAt time.Time
+}
+
+// RuleInvalidator drops cached categorization rules (the categorization
+// service), so restored rules apply straight away
+type RuleInvalidator interface {
+	InvalidateRules(userID uuid.UUID)
+}
+
+// DashboardInvalidator drops a user's cached dashboard queries (the insights
+// service), so restored transactions count straight away
+type DashboardInvalidator interface {
+	InvalidateDashboard(ctx context.Context, userID uuid.UUID, blockTypes ...string)
+}
+
+// Service provides trash business logic
+type Service struct {
+	repo      repository.TrashRepository
+	rules     RuleInvalidator
+	dashboard DashboardInvalidator
+}
+
+// NewService creates a new trash service
+func NewService(repo repository.TrashRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// WithRuleInvalidator sets what drops cached rules when one is restored
+func (s *Service) WithRuleInvalidator(rules RuleInvalidator) *Service {
+	s.rules = rules
+	return s
+}
+
+// WithDashboardInvalidator sets what refreshes the dashboard when a
+// transaction is restored
+func (s *Service) WithDashboardInvalidator(dashboard DashboardInvalidator) *Service {
+	s.dashboard = dashboard
+	return s
+}
+
+// ListTrash lists a user's deleted items, most recently deleted first. An
+// empty entityType lists every type.
+func (s *Service) ListTrash(ctx context.Context, userID uuid.UUID, entityType string, limit int) ([]*Item, error) {
+	if entityType != "" && !entityTypes[entityType] {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEntityType, entityType)
+	}
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+
+	deleted, err := s.repo.ListTrash(ctx, userID, entityType, limit)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*Item, len(deleted))
+	for i, d := range deleted {
+		items[i] = &Item{Item: d, PurgeAt: d.DeletedAt.Add(PurgeAfter)}
+	}
+	return items, nil
+}
+
+// Restore takes an item out of the user's trash. Restored plans come back as
+// drafts.
+func (s *Service) Restore(ctx context.Context, userID uuid.UUID, entityType string, id uuid.UUID) error {
+	if !entityTypes[entityType] {
+		return fmt.Errorf("%w: %q", ErrUnknownEntityType, entityType)
+	}
+	if err := s.repo.Restore(ctx, userID, entityType, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotInTrash
+		}
+		return err
+	}
+	if entityType == repository.EntityRule && s.rules != nil {
+		s.rules.InvalidateRules(userID)
+	}
+	if entityType == repository.EntityTransaction && s.dashboard != nil {
+		s.dashboard.InvalidateDashboard(ctx, userID)
+	}
+	return nil
+}
+
+// Purge permanently deletes the items that have been in the trash for
+// PurgeAfter as of now
+func (s *Service) Purge(ctx context.Context, now time.Time) (*repository.PurgeResult, error) {
+	return s.repo.Purge(ctx, now.Add(-PurgeAfter))
+}
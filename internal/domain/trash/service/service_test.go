@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/trash/repository"
+)
+
+// fakeTrashRepository keeps deleted items in memory.
+type fakeTrashRepository struct {
+	items     []*repository.Item
+	limit     int
+	purgedAt  time.Time
+	restored  []uuid.UUID
+	listedFor string
+}
+
+func (f *fakeTrashRepository) ListTrash(ctx context.Context, userID uuid.UUID, entityType string, limit int) ([]*repository.Item, error) {
+	f.limit, f.listedFor = limit, entityType
+	return f.items, nil
+}
+
+func (f *fakeTrashRepository) Restore(ctx context.Context, userID uuid.UUID, entityType string, id uuid.UUID) error {
+	for _, item := range f.items {
+		if item.ID == id && item.EntityType == entityType {
+			f.restored = append(f.restored, id)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeTrashRepository) Purge(ctx context.Context, before time.Time) (*repository.PurgeResult, error) {
+	f.purgedAt = before
+	return &repository.PurgeResult{Transactions: 2, Rules: 1}, nil
+}
+
+type fakeRuleInvalidator struct {
+	invalidated []uuid.UUID
+}
+
+func (f *fakeRuleInvalidator) InvalidateRules(userID uuid.UUID) {
+	f.invalidated = append(f.invalidated, userID)
+}
+
+func TestListTrash(t *testing.T) {
+	deletedAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	repo := &fakeTrashRepository{items: []*repository.Item{
+		{EntityType: repository.EntityGoal, ID: uuid.New(), Name: "Holiday", DeletedAt: deletedAt},
+	}}
+	svc := NewService(repo)
+
+	items, err := svc.ListTrash(context.Background(), uuid.New(), "", 0)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Holiday", items[0].Name)
+	assert.Equal(t, time.Date(2026, 4, 9, 12, 0, 0, 0, time.UTC), items[0].PurgeAt)
+	assert.Equal(t, DefaultListLimit, repo.limit)
+
+	_, err = svc.ListTrash(context.Background(), uuid.New(), repository.EntityPlan, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, MaxListLimit, repo.limit)
+	assert.Equal(t, repository.EntityPlan, repo.listedFor)
+
+	_, err = svc.ListTrash(context.Background(), uuid.New(), "account", 10)
+	assert.ErrorIs(t, err, ErrUnknownEntityType)
+}
+
+func TestRestore(t *testing.T) {
+	userID := uuid.New()
+	rule := &repository.Item{EntityType: repository.EntityRule, ID: uuid.New()}
+	tx := &repository.Item{EntityType: repository.EntityTransaction, ID: uuid.New()}
+	repo := &fakeTrashRepository{items: []*repository.Item{rule, tx}}
+	rules := &fakeRuleInvalidator{}
+	svc := NewService(repo).WithRuleInvalidator(rules)
+
+	require.NoError(t, svc.Restore(context.Background(), userID, repository.EntityTransaction, tx.ID))
+	assert.Empty(t, rules.invalidated, "restoring a transaction leaves the rule cache alone")
+
+	require.NoError(t, svc.Restore(context.Background(), userID, repository.EntityRule, rule.ID))
+	assert.Equal(t, []uuid.UUID{userID}, rules.invalidated)
+	assert.Equal(t, []uuid.UUID{tx.ID, rule.ID}, repo.restored)
+
+	err := svc.Restore(context.Background(), userID, repository.EntityGoal, tx.ID)
+	assert.ErrorIs(t, err, ErrNotInTrash)
+
+	err = svc.Restore(context.Background(), userID, "category", tx.ID)
+	assert.ErrorIs(t, err, ErrUnknownEntityType)
+}
+
+func TestPurge(t *testing.T) {
+	repo := &fakeTrashRepository{}
+	svc := NewService(repo)
+	now := time.Date(2026, 3, 31, 3, 45, 0, 0, time.UTC)
+
+	result, err := svc.Purge(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Total())
+	assert.Equal(t, time.Date(2026, 3, 1, 3, 45, 0, 0, time.UTC), repo.purgedAt)
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresWebhookRepository implements WebhookRepository using PostgreSQL
+type PostgresWebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresWebhookRepository creates a new PostgreSQL webhook repository
+func NewPostgresWebhookRepository(pool *pgxpool.Pool) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{pool: pool}
+}
+
+const endpointColumns = `id, user_id, url, secret, event_types, description, enabled, created_at, updated_at`
+
+func scanEndpoint(row pgx.Row) (*Endpoint, error) {
+	e := &Endpoint{}
+	err := row.Scan(&e.ID, &e.UserID, &e.URL, &e.Secret, &e.EventTypes, &e.Description, &e.Enabled, &e.CreatedAt, &e.UpdatedAt)
+	return e, err
+}
+
+// CreateEndpoint stores a new endpoint
+func (r *PostgresWebhookRepository) CreateEndpoint(ctx context.Context, e *Endpoint) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO webhook_endpoints (id, user_id, url, secret, event_types, description, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at, updated_at`,
+		e.ID, e.UserID, e.URL, e.Secret, e.EventTypes, e.Description, e.Enabled,
+	).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// GetEndpoint returns one of the user's endpoints
+func (r *PostgresWebhookRepository) GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*Endpoint, error) {
+	e, err := scanEndpoint(r.pool.QueryRow(ctx, `
+		SELECT `+endpointColumns+` FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, endpointID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// ListEndpoints lists the user's endpoints, oldest first
+func (r *PostgresWebhookRepository) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*Endpoint, error) {
+	return r.listEndpoints(ctx, `
+		SELECT `+endpointColumns+` FROM webhook_endpoints
+		WHERE user_id = $1
+		ORDER BY created_at`, userID)
+}
+
+// ListSubscribedEndpoints lists the user's enabled endpoints for an event type
+func (r *PostgresWebhookRepository) ListSubscribedEndpoints(ctx context.Context, userID uuid.UUID, eventType string) ([]*Endpoint, error) {
+	return r.listEndpoints(ctx, `
+		SELECT `+endpointColumns+` FROM webhook_endpoints
+		WHERE user_id = $1 AND enabled AND $2 = ANY (event_types)
+		ORDER BY created_at`, userID, eventType)
+}
+
+func (r *PostgresWebhookRepository) listEndpoints(ctx context.Context, query string, args ...any) ([]*Endpoint, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*Endpoint
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, rows.Err()
+}
+
+// CountEndpoints counts the user's endpoints
+func (r *PostgresWebhookRepository) CountEndpoints(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM webhook_endpoints WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count webhook endpoints: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateEndpoint saves an endpoint's URL, secret, event types, description and state
+func (r *PostgresWebhookRepository) UpdateEndpoint(ctx context.Context, e *Endpoint) error {
+	err := r.pool.QueryRow(ctx, `
+		UPDATE webhook_endpoints
+		SET url = $3, secret = $4, event_types = $5, description = $6, enabled = $7
+		WHERE id = $1 AND user_id = $2
+		RETURNING updated_at`,
+		e.ID, e.UserID, e.URL, e.Secret, e.EventTypes, e.Description, e.Enabled,
+	).Scan(&e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log
+func (r *PostgresWebhookRepository) DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`, endpointID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// CreateDeliveries queues deliveries in one batch
+func (r *PostgresWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		if d.ID == uuid.Nil {
+			d.ID = uuid.New()
+		}
+		payload, err := json.Marshal(d.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook payload: %w", err)
+		}
+		batch.Queue(`
+			INSERT INTO webhook_deliveries (id, endpoint_id, user_id, event_id, event_type, payload, status, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING created_at`,
+			d.ID, d.EndpointID, d.UserID, d.EventID, d.EventType, payload, d.Status, d.NextAttemptAt,
+		).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&d.CreatedAt)
+		})
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+const deliveryColumns = `d.id, d.endpoint_id, d.user_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+func deliveryDest(d *Delivery, payload *[]byte) []any {
+	return []any{&d.ID, &d.EndpointID, &d.UserID, &d.EventID, &d.EventType, payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt}
+}
+
+// ClaimDeliveries leases due pending deliveries until leaseUntil
+func (r *PostgresWebhookRepository) ClaimDeliveries(ctx context.Context, ids []uuid.UUID, now, leaseUntil time.Time, limit int) ([]*ClaimedDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			  AND ($3::uuid[] IS NULL OR id = ANY ($3))
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM due, webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id
+		RETURNING `+deliveryColumns+`, e.url, e.secret`, now, leaseUntil, ids, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var claimed []*ClaimedDelivery
+	for rows.Next() {
+		c := &ClaimedDelivery{Delivery: &Delivery{}}
+		var payload []byte
+		if err := rows.Scan(append(deliveryDest(c.Delivery, &payload), &c.URL, &c.Secret)...); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		if err := json.Unmarshal(payload, &c.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook payload: %w", err)
+		}
+		claimed = append(claimed, c)
+	}
+	return claimed, rows.Err()
+}
+
+// RecordAttempt stores the outcome of an attempt
+func (r *PostgresWebhookRepository) RecordAttempt(ctx context.Context, d *Delivery) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1`,
+		d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries lists the user's deliveries, newest first
+func (r *PostgresWebhookRepository) ListDeliveries(ctx context.Context, userID uuid.UUID, endpointID *uuid.UUID, limit int) ([]*Delivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.user_id = $1 AND ($2::uuid IS NULL OR d.endpoint_id = $2)
+		ORDER BY d.created_at DESC
+		LIMIT $3`, userID, endpointID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		d := &Delivery{}
+		var payload []byte
+		if err := rows.Scan(deliveryDest(d, &payload)...); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		if err := json.Unmarshal(payload, &d.Payload); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook payload: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RequeueDelivery makes a failed delivery of the user's pending again
+func (r *PostgresWebhookRepository) RequeueDelivery(ctx context.Context, userID, deliveryID uuid.UUID, at time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'failed'`, deliveryID, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package repository provides database operations for user webhook endpoints and their deliveries.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus tracks a delivery through its retries
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending" // Waiting for its next attempt
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed" // Gave up after the last retry
+)
+
+// Endpoint is a URL a user registered to receive events
+type Endpoint struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	URL         string
+	Secret      string // Signs every payload
+	EventTypes  []string
+	Description *string
+	Enabled     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Subscribes reports whether the endpoint wants events of the type
+func (e *Endpoint) Subscribes(eventType string) bool {
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event sent, or to be sent, to one endpoint
+type Delivery struct {
+	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	UserID         uuid.UUID
+	EventID        uuid.UUID // Shared by every endpoint that receives the event
+	EventType      string
+	Payload        map[string]any
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastStatusCode *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// ClaimedDelivery is a delivery leased for an attempt, with where to send it
+type ClaimedDelivery struct {
+	*Delivery
+	URL    string
+	Secret string
+}
+
+// WebhookRepository defines the interface for webhook persistence
+type WebhookRepository interface {
+	// Endpoints
+	CreateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	// GetEndpoint returns one of the user's endpoints, or sql.ErrNoRows
+	GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*Endpoint, error)
+	ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*Endpoint, error)
+	CountEndpoints(ctx context.Context, userID uuid.UUID) (int, error)
+	UpdateEndpoint(ctx context.Context, endpoint *Endpoint) error
+	DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error
+	// ListSubscribedEndpoints lists the user's enabled endpoints for an event type
+	ListSubscribedEndpoints(ctx context.Context, userID uuid.UUID, eventType string) ([]*Endpoint, error)
+
+	// Deliveries
+	CreateDeliveries(ctx context.Context, deliveries []*Delivery) error
+	// ClaimDeliveries leases due pending deliveries until leaseUntil so no other
+	// worker attempts them meanwhile. With ids, only those deliveries are claimed.
+	ClaimDeliveries(ctx context.Context, ids []uuid.UUID, now, leaseUntil time.Time, limit int) ([]*ClaimedDelivery, error)
+	// RecordAttempt stores the outcome of an attempt
+	RecordAttempt(ctx context.Context, delivery *Delivery) error
+	// ListDeliveries lists the user's deliveries, newest first, optionally for one endpoint
+	ListDeliveries(ctx context.Context, userID uuid.UUID, endpointID *uuid.UUID, limit int) ([]*Delivery, error)
+	// RequeueDelivery makes a failed delivery of the user's pending again with
+	// a fresh set of retries
+	RequeueDelivery(ctx context.Context, userID, deliveryID uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,393 @@
+// Package service provides business logic for outbound webhooks: users register
+// endpoints for account events, and each event is delivered as a signed JSON
+// POST that is retried with exponential backoff until it succeeds.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webhook"
+)
+
+// =============================================================================
+// Outbound Webhooks (Internal Integration)
+// =============================================================================
+// Events are published by the import, goals, insights and plan services through
+// adapters and delivered in the background; the scheduler retries failed
+// deliveries. Payloads are signed like notification webhooks (see
+// webhook.SignatureHeader).
+//
+// To expose as API endpoints, add the following proto definitions:
+// - CreateWebhookEndpointRequest/Response (WebhookService.CreateWebhookEndpoint)
+// - ListWebhookEndpointsRequest/Response, UpdateWebhookEndpointRequest/Response
+// - DeleteWebhookEndpointRequest/Response, RotateWebhookSecretRequest/Response
+// - ListWebhookDeliveriesRequest/Response, RetryWebhookDeliveryRequest/Response
+// - WebhookEndpoint, WebhookDelivery, WebhookEventType
+
+// EventType is an account event endpoints can subscribe to
+type EventType string
+
+const (
+	EventTransactionImported EventType = "transaction.imported" // An import job finished
+	EventGoalMilestone       EventType = "goal.milestone"       // A goal crossed 25, 50, 75 or 100%
+	EventAlertCreated        EventType = "alert.created"
+	EventPlanUpdated         EventType = "plan.updated" // A plan's budgets or actuals changed
+)
+
+// EventTypes lists every event type, for subscription choices
+var EventTypes = []EventType{EventTransactionImported, EventGoalMilestone, EventAlertCreated, EventPlanUpdated}
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it fails
+	MaxAttempts = 8
+	// retryBaseDelay is the wait after the first failed attempt; it doubles
+	// after each further failure
+	retryBaseDelay = time.Minute
+	// maxRetryDelay caps the wait between attempts
+	maxRetryDelay = 6 * time.Hour
+
+	// deliveryLease is how long a claimed delivery is held before another
+	// worker may try it, should this one die mid-attempt
+	deliveryLease = 5 * time.Minute
+	// retryBatchSize caps the deliveries one retry run attempts
+	retryBatchSize = 100
+	// deliveryTimeout bounds an event's background delivery
+	deliveryTimeout = 30 * time.Second
+
+	maxEndpointsPerUser    = 10
+	maxDescriptionLength   = 200
+	defaultDeliveryLimit   = 50
+	maxDeliveryLimit       = 200
+	maxRecordedErrorLength = 500
+	secretBytes            = 24
+	secretPrefix           = "whsec_"
+)
+
+var (
+	// ErrEndpointNotFound is returned when the user has no such endpoint
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+	// ErrDeliveryNotFound is returned when the user has no such failed delivery
+	ErrDeliveryNotFound = errors.New("failed webhook delivery not found")
+	// ErrInvalidEndpoint is returned for an invalid URL, event type or description
+	ErrInvalidEndpoint = errors.New("invalid webhook endpoint")
+	// ErrTooManyEndpoints is returned when the user already has the most endpoints allowed
+	ErrTooManyEndpoints = errors.New("too many webhook endpoints")
+)
+
+// Sender posts signed events to URLs
+type Sender interface {
+	Send(ctx context.Context, targetURL, secret string, event *webhook.Event) error
+}
+
+// EndpointInput describes an endpoint to create or the new state of one
+type EndpointInput struct {
+	URL         string
+	EventTypes  []EventType
+	Description *string
+	Enabled     bool
+}
+
+// Service manages webhook endpoints and delivers events to them
+type Service struct {
+	repo   repository.WebhookRepository
+	sender Sender
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new webhook service
+func NewService(repo repository.WebhookRepository, sender Sender, logger *slog.Logger) *Service {
+	return &Service{repo: repo, sender: sender, logger: logger, now: time.Now}
+}
+
+// CreateWebhookEndpoint registers an endpoint with a new signing secret, which
+// is returned on the endpoint
+func (s *Service) CreateWebhookEndpoint(ctx context.Context, userID uuid.UUID, input EndpointInput) (*repository.Endpoint, error) {
+	endpoint := &repository.Endpoint{UserID: userID, Enabled: true}
+	if err := applyEndpointInput(endpoint, input); err != nil {
+		return nil, err
+	}
+	endpoint.Enabled = true
+
+	count, err := s.repo.CountEndpoints(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxEndpointsPerUser {
+		return nil, ErrTooManyEndpoints
+	}
+
+	if endpoint.Secret, err = generateSecret(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// ListWebhookEndpoints lists the user's endpoints
+func (s *Service) ListWebhookEndpoints(ctx context.Context, userID uuid.UUID) ([]*repository.Endpoint, error) {
+	return s.repo.ListEndpoints(ctx, userID)
+}
+
+// UpdateWebhookEndpoint replaces an endpoint's URL, event types, description
+// and enabled state; the secret is kept
+func (s *Service) UpdateWebhookEndpoint(ctx context.Context, userID, endpointID uuid.UUID, input EndpointInput) (*repository.Endpoint, error) {
+	endpoint, err := s.getEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyEndpointInput(endpoint, input); err != nil {
+		return nil, err
+	}
+	return endpoint, s.saveEndpoint(ctx, endpoint)
+}
+
+// RotateWebhookSecret gives an endpoint a new signing secret
+func (s *Service) RotateWebhookSecret(ctx context.Context, userID, endpointID uuid.UUID) (*repository.Endpoint, error) {
+	endpoint, err := s.getEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Secret, err = generateSecret(); err != nil {
+		return nil, err
+	}
+	return endpoint, s.saveEndpoint(ctx, endpoint)
+}
+
+// DeleteWebhookEndpoint removes an endpoint and its deliveries
+func (s *Service) DeleteWebhookEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	err := s.repo.DeleteEndpoint(ctx, userID, endpointID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEndpointNotFound
+	}
+	return err
+}
+
+// ListWebhookDeliveries lists the user's recent deliveries, newest first, with
+// their attempts and last error, optionally for one endpoint
+func (s *Service) ListWebhookDeliveries(ctx context.Context, userID uuid.UUID, endpointID *uuid.UUID, limit int) ([]*repository.Delivery, error) {
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		limit = maxDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, userID, endpointID, limit)
+}
+
+// RetryWebhookDelivery queues a failed delivery for another round of attempts
+func (s *Service) RetryWebhookDelivery(ctx context.Context, userID, deliveryID uuid.UUID) error {
+	err := s.repo.RequeueDelivery(ctx, userID, deliveryID, s.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDeliveryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.deliverInBackground([]uuid.UUID{deliveryID})
+	return nil
+}
+
+// Publish queues an event for each of the user's endpoints subscribed to it
+// and starts delivering it in the background
+func (s *Service) Publish(ctx context.Context, userID uuid.UUID, eventType EventType, data map[string]any) error {
+	endpoints, err := s.repo.ListSubscribedEndpoints(ctx, userID, string(eventType))
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	now := s.now()
+	eventID := uuid.New()
+	deliveries := make([]*repository.Delivery, 0, len(endpoints))
+	ids := make([]uuid.UUID, 0, len(endpoints))
+	for _, e := range endpoints {
+		d := &repository.Delivery{
+			ID:            uuid.New(),
+			EndpointID:    e.ID,
+			UserID:        userID,
+			EventID:       eventID,
+			EventType:     string(eventType),
+			Payload:       data,
+			Status:        repository.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		}
+		deliveries = append(deliveries, d)
+		ids = append(ids, d.ID)
+	}
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+
+	s.deliverInBackground(ids)
+	return nil
+}
+
+// DeliverDue attempts the deliveries whose retry is due. Returns how many were attempted.
+func (s *Service) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	return s.deliver(ctx, nil, now)
+}
+
+// deliverInBackground makes a first attempt at new deliveries without holding
+// up the caller; the retry job picks up whatever this misses
+func (s *Service) deliverInBackground(ids []uuid.UUID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		defer cancel()
+		if _, err := s.deliver(ctx, ids, s.now()); err != nil && s.logger != nil {
+			s.logger.Warn("failed to deliver webhooks", slog.Any("error", err))
+		}
+	}()
+}
+
+// deliver claims and attempts due deliveries, only those in ids when set
+func (s *Service) deliver(ctx context.Context, ids []uuid.UUID, now time.Time) (int, error) {
+	claimed, err := s.repo.ClaimDeliveries(ctx, ids, now, now.Add(deliveryLease), retryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range claimed {
+		s.attempt(ctx, c)
+	}
+	return len(claimed), nil
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry on failure
+func (s *Service) attempt(ctx context.Context, c *repository.ClaimedDelivery) {
+	d := c.Delivery
+	event := &webhook.Event{
+		ID:        d.EventID.String(),
+		Type:      d.EventType,
+		CreatedAt: d.CreatedAt,
+		Data:      d.Payload,
+	}
+	err := s.sender.Send(ctx, c.URL, c.Secret, event)
+
+	now := s.now()
+	d.Attempts++
+	d.LastStatusCode = nil
+	d.LastError = nil
+	var statusErr *webhook.StatusError
+	if errors.As(err, &statusErr) {
+		d.LastStatusCode = &statusErr.StatusCode
+	}
+
+	switch {
+	case err == nil:
+		d.Status = repository.DeliveryStatusDelivered
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+	case d.Attempts >= MaxAttempts:
+		d.Status = repository.DeliveryStatusFailed
+		d.NextAttemptAt = nil
+	default:
+		next := now.Add(RetryDelay(d.Attempts))
+		d.NextAttemptAt = &next
+	}
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > maxRecordedErrorLength {
+			msg = msg[:maxRecordedErrorLength]
+		}
+		d.LastError = &msg
+	}
+
+	if err := s.repo.RecordAttempt(ctx, d); err != nil && s.logger != nil {
+		s.logger.Warn("failed to record webhook attempt", slog.String("delivery_id", d.ID.String()), slog.Any("error", err))
+	}
+}
+
+// RetryDelay is the wait before the next attempt after the given number of
+// failed attempts: one minute, doubling each time, capped at six hours
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func (s *Service) getEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*repository.Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, userID, endpointID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEndpointNotFound
+	}
+	return endpoint, err
+}
+
+func (s *Service) saveEndpoint(ctx context.Context, endpoint *repository.Endpoint) error {
+	err := s.repo.UpdateEndpoint(ctx, endpoint)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrEndpointNotFound
+	}
+	return err
+}
+
+// applyEndpointInput validates input and copies it onto the endpoint
+func applyEndpointInput(endpoint *repository.Endpoint, input EndpointInput) error {
+	url := strings.TrimSpace(input.URL)
+	if err := webhook.ValidateURL(url); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEndpoint, err)
+	}
+	if len(input.EventTypes) == 0 {
+		return fmt.Errorf("%w: subscribe to at least one event type", ErrInvalidEndpoint)
+	}
+
+	eventTypes := make([]string, 0, len(input.EventTypes))
+	seen := make(map[EventType]bool)
+	for _, t := range input.EventTypes {
+		if !knownEventType(t) {
+			return fmt.Errorf("%w: unknown event type %q", ErrInvalidEndpoint, t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, string(t))
+		}
+	}
+
+	var description *string
+	if input.Description != nil {
+		if d := strings.TrimSpace(*input.Description); d != "" {
+			if len([]rune(d)) > maxDescriptionLength {
+				return fmt.Errorf("%w: description is too long", ErrInvalidEndpoint)
+			}
+			description = &d
+		}
+	}
+
+	endpoint.URL = url
+	endpoint.EventTypes = eventTypes
+	endpoint.Description = description
+	endpoint.Enabled = input.Enabled
+	return nil
+}
+
+func knownEventType(t EventType) bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/repository"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/webhook"
+)
+
+// fakeWebhookRepository keeps endpoints and deliveries in memory. It's locked
+// because deliveries are attempted in the background.
+type fakeWebhookRepository struct {
+	mu         sync.Mutex
+	endpoints  []*repository.Endpoint
+	deliveries []*repository.Delivery
+}
+
+func (f *fakeWebhookRepository) CreateEndpoint(_ context.Context, e *repository.Endpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e.ID = uuid.New()
+	f.endpoints = append(f.endpoints, e)
+	return nil
+}
+
+func (f *fakeWebhookRepository) GetEndpoint(_ context.Context, userID, endpointID uuid.UUID) (*repository.Endpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, e := range f.endpoints {
+		if e.ID == endpointID && e.UserID == userID {
+			copied := *e
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeWebhookRepository) ListEndpoints(_ context.Context, userID uuid.UUID) ([]*repository.Endpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var endpoints []*repository.Endpoint
+	for _, e := range f.endpoints {
+		if e.UserID == userID {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints, nil
+}
+
+func (f *fakeWebhookRepository) CountEndpoints(ctx context.Context, userID uuid.UUID) (int, error) {
+	endpoints, err := f.ListEndpoints(ctx, userID)
+	return len(endpoints), err
+}
+
+func (f *fakeWebhookRepository) UpdateEndpoint(_ context.Context, endpoint *repository.Endpoint) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, e := range f.endpoints {
+		if e.ID == endpoint.ID && e.UserID == endpoint.UserID {
+			f.endpoints[i] = endpoint
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeWebhookRepository) DeleteEndpoint(_ context.Context, userID, endpointID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, e := range f.endpoints {
+		if e.ID == endpointID && e.UserID == userID {
+			f.endpoints = append(f.endpoints[:i], f.endpoints[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeWebhookRepository) ListSubscribedEndpoints(_ context.Context, userID uuid.UUID, eventType string) ([]*repository.Endpoint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var endpoints []*repository.Endpoint
+	for _, e := range f.endpoints {
+		if e.UserID == userID && e.Enabled && e.Subscribes(eventType) {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints, nil
+}
+
+func (f *fakeWebhookRepository) CreateDeliveries(_ context.Context, deliveries []*repository.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range deliveries {
+		copied := *d
+		f.deliveries = append(f.deliveries, &copied)
+	}
+	return nil
+}
+
+func (f *fakeWebhookRepository) ClaimDeliveries(_ context.Context, ids []uuid.UUID, now, leaseUntil time.Time, limit int) ([]*repository.ClaimedDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var claimed []*repository.ClaimedDelivery
+	for _, d := range f.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if d.Status != repository.DeliveryStatusPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if ids != nil && !containsID(ids, d.ID) {
+			continue
+		}
+		lease := leaseUntil
+		d.NextAttemptAt = &lease
+		copied := *d
+		c := &repository.ClaimedDelivery{Delivery: &copied}
+		for _, e := range f.endpoints {
+			if e.ID == d.EndpointID {
+				c.URL, c.Secret = e.URL, e.Secret
+			}
+		}
+		claimed = append(claimed, c)
+	}
+	return claimed, nil
+}
+
+func (f *fakeWebhookRepository) RecordAttempt(_ context.Context, delivery *repository.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, d := range f.deliveries {
+		if d.ID == delivery.ID {
+			copied := *delivery
+			f.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (f *fakeWebhookRepository) ListDeliveries(_ context.Context, userID uuid.UUID, endpointID *uuid.UUID, limit int) ([]*repository.Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deliveries []*repository.Delivery
+	for i := len(f.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := f.deliveries[i]
+		if d.UserID == userID && (endpointID == nil || d.EndpointID == *endpointID) {
+			copied := *d
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	return deliveries, nil
+}
+
+func (f *fakeWebhookRepository) RequeueDelivery(_ context.Context, userID, deliveryID uuid.UUID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.ID == deliveryID && d.UserID == userID && d.Status == repository.DeliveryStatusFailed {
+			d.Status = repository.DeliveryStatusPending
+			d.Attempts = 0
+			d.NextAttemptAt = &at
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeWebhookRepository) delivery(id uuid.UUID) repository.Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.ID == id {
+			return *d
+		}
+	}
+	return repository.Delivery{}
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeSender records the events sent per URL and fails with err when set
+type fakeSender struct {
+	mu   sync.Mutex
+	sent map[string][]*webhook.Event
+	err  error
+}
+
+func (f *fakeSender) Send(_ context.Context, targetURL, _ string, event *webhook.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.sent[targetURL] = append(f.sent[targetURL], event)
+	return nil
+}
+
+func (f *fakeSender) sentTo(targetURL string) []*webhook.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sent[targetURL]
+}
+
+func newTestService() (*Service, *fakeWebhookRepository, *fakeSender) {
+	repo := &fakeWebhookRepository{}
+	sender := &fakeSender{sent: make(map[string][]*webhook.Event)}
+	return NewService(repo, sender, nil), repo, sender
+}
+
+func TestPublish_DeliversToSubscribedEndpoints(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, sender := newTestService()
+	userID := uuid.New()
+
+	imports, err := svc.CreateWebhookEndpoint(ctx, userID, EndpointInput{
+		URL:        "https://example.com/imports",
+		EventTypes: []EventType{EventTransactionImported, EventTransactionImported},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"transaction.imported"}, imports.EventTypes)
+	assert.True(t, imports.Enabled)
+	assert.Contains(t, imports.Secret, secretPrefix)
+
+	_, err = svc.CreateWebhookEndpoint(ctx, userID, EndpointInput{
+		URL:        "https://example.com/goals",
+		EventTypes: []EventType{EventGoalMilestone},
+	})
+	require.NoError(t, err)
+	_, err = svc.CreateWebhookEndpoint(ctx, uuid.New(), EndpointInput{
+		URL:        "https://example.com/other-user",
+		EventTypes: []EventType{EventTransactionImported},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Publish(ctx, userID, EventTransactionImported, map[string]any{"rows_imported": 12}))
+
+	assert.Eventually(t, func() bool {
+		return len(sender.sentTo("https://example.com/imports")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, sender.sentTo("https://example.com/goals"))
+	assert.Empty(t, sender.sentTo("https://example.com/other-user"))
+
+	event := sender.sentTo("https://example.com/imports")[0]
+	assert.Equal(t, "transaction.imported", event.Type)
+	assert.Equal(t, 12, event.Data["rows_imported"])
+
+	deliveries, err := svc.ListWebhookDeliveries(ctx, userID, &imports.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, event.ID, deliveries[0].EventID.String())
+	assert.Eventually(t, func() bool {
+		return repo.delivery(deliveries[0].ID).Status == repository.DeliveryStatusDelivered
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDeliverDue_BacksOffThenFails(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, sender := newTestService()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	sender.err = &webhook.StatusError{StatusCode: http.StatusServiceUnavailable}
+	userID := uuid.New()
+
+	endpoint, err := svc.CreateWebhookEndpoint(ctx, userID, EndpointInput{
+		URL:        "https://example.com/hook",
+		EventTypes: []EventType{EventPlanUpdated},
+	})
+	require.NoError(t, err)
+	delivery := &repository.Delivery{
+		ID: uuid.New(), EndpointID: endpoint.ID, UserID: userID, EventID: uuid.New(),
+		EventType: string(EventPlanUpdated), Status: repository.DeliveryStatusPending, NextAttemptAt: &now,
+	}
+	require.NoError(t, repo.CreateDeliveries(ctx, []*repository.Delivery{delivery}))
+
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		attempted, err := svc.DeliverDue(ctx, now)
+		require.NoError(t, err)
+		require.Equal(t, 1, attempted, "attempt %d", attempt)
+
+		d := repo.delivery(delivery.ID)
+		assert.Equal(t, attempt, d.Attempts)
+		require.NotNil(t, d.LastStatusCode)
+		assert.Equal(t, http.StatusServiceUnavailable, *d.LastStatusCode)
+		require.NotNil(t, d.LastError)
+		if attempt < MaxAttempts {
+			assert.Equal(t, repository.DeliveryStatusPending, d.Status)
+			assert.Equal(t, now.Add(RetryDelay(attempt)), *d.NextAttemptAt)
+
+			// Nothing is due until the backoff elapses
+			attempted, err = svc.DeliverDue(ctx, now.Add(RetryDelay(attempt)-time.Second))
+			require.NoError(t, err)
+			assert.Zero(t, attempted)
+			now = *d.NextAttemptAt
+		} else {
+			assert.Equal(t, repository.DeliveryStatusFailed, d.Status)
+			assert.Nil(t, d.NextAttemptAt)
+		}
+	}
+
+	// A retry requested from the delivery log starts over and succeeds
+	sender.mu.Lock()
+	sender.err = nil
+	sender.mu.Unlock()
+	require.NoError(t, svc.RetryWebhookDelivery(ctx, userID, delivery.ID))
+	assert.Eventually(t, func() bool {
+		d := repo.delivery(delivery.ID)
+		return d.Status == repository.DeliveryStatusDelivered && d.Attempts == 1 && d.LastError == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, svc.RetryWebhookDelivery(ctx, userID, delivery.ID), ErrDeliveryNotFound)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, RetryDelay(1))
+	assert.Equal(t, 2*time.Minute, RetryDelay(2))
+	assert.Equal(t, 64*time.Minute, RetryDelay(7))
+	assert.Equal(t, 6*time.Hour, RetryDelay(20))
+}
+
+func TestCreateWebhookEndpoint_Validates(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService()
+	userID := uuid.New()
+
+	tests := []struct {
+		name  string
+		input EndpointInput
+	}{
+		{"plain http", EndpointInput{URL: "http://example.com/hook", EventTypes: []EventType{EventAlertCreated}}},
+		{"loopback", EndpointInput{URL: "https://127.0.0.1/hook", EventTypes: []EventType{EventAlertCreated}}},
+		{"cloud metadata", EndpointInput{URL: "https://169.254.169.254/latest/meta-data", EventTypes: []EventType{EventAlertCreated}}},
+		{"no event types", EndpointInput{URL: "https://example.com/hook"}},
+		{"unknown event type", EndpointInput{URL: "https://example.com/hook", EventTypes: []EventType{"account.deleted"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateWebhookEndpoint(ctx, userID, tt.input)
+			assert.ErrorIs(t, err, ErrInvalidEndpoint)
+		})
+	}
+
+	for range maxEndpointsPerUser {
+		_, err := svc.CreateWebhookEndpoint(ctx, userID, EndpointInput{URL: "https://example.com/hook", EventTypes: EventTypes})
+		require.NoError(t, err)
+	}
+	_, err := svc.CreateWebhookEndpoint(ctx, userID, EndpointInput{URL: "https://example.com/hook", EventTypes: EventTypes})
+	assert.ErrorIs(t, err, ErrTooManyEndpoints)
+}
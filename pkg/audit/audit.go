@@ -0,0 +1,97 @@
+// Package audit records who changed what through sensitive RPCs: the caller,
+// the procedure, the entities it named, a summary of the entity before and of
+// the request itself, and where the call came from.
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// OutcomeOK is the outcome of a call that succeeded; failed calls record their Connect error code
+const OutcomeOK = "ok"
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ErrAdminRequired is returned when a non-admin lists audit events
+var ErrAdminRequired = errors.New("audit events are only available to admins")
+
+// Event is one audited call
+type Event struct {
+	ID         uuid.UUID
+	OccurredAt time.Time
+	ActorID    *uuid.UUID // Nil for unauthenticated calls
+	Procedure  string
+	EntityIDs  []string       // IDs named in the request, e.g. plan_id
+	Before     map[string]any // Summary of the entity before the call, if taken
+	After      map[string]any // Summary of the request
+	Outcome    string         // OutcomeOK or the Connect error code
+	Error      *string
+	IP         string
+	UserAgent  string
+	RequestID  string
+}
+
+// ListFilter narrows an audit event listing. Zero values don't filter.
+type ListFilter struct {
+	ActorID   *uuid.UUID
+	Procedure string
+	EntityID  string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int // Defaults to 50, at most 500
+	Offset    int
+}
+
+// Store persists audit events
+type Store interface {
+	// Record appends an event
+	Record(ctx context.Context, event *Event) error
+	// List returns the events matching the filter, newest first
+	List(ctx context.Context, filter ListFilter) ([]*Event, error)
+}
+
+// Service reads the audit log
+type Service struct {
+	store Store
+}
+
+// NewService creates a new audit log service
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// ListAuditEvents lists audit events, newest first. Only admins may list them.
+func (s *Service) ListAuditEvents(ctx context.Context, filter ListFilter) ([]*Event, error) {
+	claims, err := interceptors.GetClaimsFromContext(ctx)
+	if err != nil || claims.Role != "admin" {
+		return nil, ErrAdminRequired
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+	filter.Limit = min(filter.Limit, maxListLimit)
+	filter.Offset = max(filter.Offset, 0)
+	return s.store.List(ctx, filter)
+}
+
+// ============================================================================
+// Audit Log (Internal Integration)
+// ============================================================================
+// Events are recorded by Interceptor for the procedures it's given. Listing
+// them requires proto definitions on the admin service:
+//
+// - ListAuditEvents: audit events filtered by actor, procedure, entity and time (admin only)
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AdminListAuditEventsRequest/Response (AdminUserService.ListAuditEvents)
+// - AuditEvent (id, occurred_at, actor_id, procedure, entity_ids, before and
+//   after as google.protobuf.Struct, outcome, error, ip, user_agent, request_id)
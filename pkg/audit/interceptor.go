@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+const (
+	// recordTimeout bounds writing an event, which happens after the call returns
+	recordTimeout = 5 * time.Second
+	// maxSummaryString and maxSummaryList keep request summaries small
+	maxSummaryString = 200
+	maxSummaryList   = 20
+)
+
+// SnapshotFunc summarizes the entity a call is about to change, for the
+// event's before summary. It runs before the handler; a nil summary is fine.
+type SnapshotFunc func(ctx context.Context, actorID uuid.UUID, req any) (map[string]any, error)
+
+// Interceptor records an audit event for every call to the procedures it
+// audits, whether the call succeeds or not. It must run after authentication,
+// which puts the caller in the context. Failing to record an event is logged
+// and doesn't fail the call.
+type Interceptor struct {
+	store      Store
+	logger     *slog.Logger
+	procedures map[string]SnapshotFunc
+	now        func() time.Time
+	proxies    *interceptors.TrustedProxies
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor creates an interceptor auditing the given procedures
+func NewInterceptor(store Store, logger *slog.Logger, procedures ...string) *Interceptor {
+	i := &Interceptor{
+		store:      store,
+		logger:     logger,
+		procedures: make(map[string]SnapshotFunc, len(procedures)),
+		now:        time.Now,
+	}
+	for _, p := range procedures {
+		i.procedures[p] = nil
+	}
+	return i
+}
+
+// WithTrustedProxies records the client address of calls through proxies
+// from X-Forwarded-For; otherwise events get the peer address
+func (i *Interceptor) WithTrustedProxies(proxies *interceptors.TrustedProxies) *Interceptor {
+	i.proxies = proxies
+	return i
+}
+
+// WithSnapshot audits procedure, taking a before summary with fn
+func (i *Interceptor) WithSnapshot(procedure string, fn SnapshotFunc) *Interceptor {
+	i.procedures[procedure] = fn
+	return i
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		snapshot, audited := i.procedures[req.Spec().Procedure]
+		if !audited || req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		event := &Event{
+			ID:        uuid.New(),
+			Procedure: req.Spec().Procedure,
+			IP:        i.proxies.ClientIP(req.Header(), req.Peer().Addr),
+			UserAgent: req.Header().Get("User-Agent"),
+		}
+		if userID, ok := interceptors.GetUserIDFromContext(ctx); ok {
+			if parsed, err := uuid.Parse(userID); err == nil {
+				event.ActorID = &parsed
+			}
+		}
+		if requestID, ok := interceptors.RequestIDFromContext(ctx); ok {
+			event.RequestID = requestID
+		}
+		if msg, ok := req.Any().(proto.Message); ok {
+			event.EntityIDs = entityIDs(msg.ProtoReflect())
+			event.After = summarize(msg)
+		}
+		if snapshot != nil && event.ActorID != nil {
+			before, err := snapshot(ctx, *event.ActorID, req.Any())
+			if err != nil {
+				i.logger.Warn("failed to snapshot audited entity",
+					slog.String("procedure", event.Procedure), slog.Any("error", err))
+			}
+			event.Before = before
+		}
+
+		resp, err := next(ctx, req)
+
+		event.OccurredAt = i.now()
+		event.Outcome = OutcomeOK
+		if err != nil {
+			event.Outcome = connect.CodeOf(err).String()
+			msg := err.Error()
+			event.Error = &msg
+		}
+
+		// Record even if the caller has gone away; the change may have happened
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+		defer cancel()
+		if recordErr := i.store.Record(recordCtx, event); recordErr != nil {
+			i.logger.Error("failed to record audit event",
+				slog.String("procedure", event.Procedure), slog.Any("error", recordErr))
+		}
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor. Streams aren't audited.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// entityIDs returns the request's top-level ID fields that are set: string
+// fields named id or ending in _id, and repeated ones ending in _ids
+func entityIDs(msg protoreflect.Message) []string {
+	var ids []string
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.StringKind {
+			return true
+		}
+		name := string(fd.Name())
+		switch {
+		case fd.IsList() && strings.HasSuffix(name, "_ids"):
+			list := v.List()
+			for j := 0; j < list.Len(); j++ {
+				ids = append(ids, list.Get(j).String())
+			}
+		case !fd.IsList() && !fd.IsMap() && (name == "id" || strings.HasSuffix(name, "_id")):
+			if s := v.String(); s != "" {
+				ids = append(ids, s)
+			}
+		}
+		return true
+	})
+	return ids
+}
+
+// summarize returns the request as JSON fields, with long strings and lists
+// cut short and bytes fields (file uploads) left out
+func summarize(msg proto.Message) map[string]any {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return map[string]any{"error": fmt.Sprintf("failed to summarize request: %v", err)}
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.BytesKind {
+			fields[string(fd.Name())] = "[omitted]"
+		}
+		return true
+	})
+	for k, v := range fields {
+		fields[k] = trimSummary(v)
+	}
+	return fields
+}
+
+func trimSummary(v any) any {
+	switch v := v.(type) {
+	case string:
+		if len(v) > maxSummaryString {
+			cut := maxSummaryString
+			for cut > 0 && !utf8.RuneStart(v[cut]) {
+				cut--
+			}
+			return v[:cut] + "…"
+		}
+		return v
+	case []any:
+		if len(v) > maxSummaryList {
+			v = append(v[:maxSummaryList:maxSummaryList], fmt.Sprintf("… %d more", len(v)-maxSummaryList))
+		}
+		for j := range v {
+			v[j] = trimSummary(v[j])
+		}
+		return v
+	case map[string]any:
+		for k, inner := range v {
+			v[k] = trimSummary(inner)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// memoryStore keeps recorded events in memory
+type memoryStore struct {
+	events []*Event
+}
+
+func (s *memoryStore) Record(_ context.Context, e *Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memoryStore) List(context.Context, ListFilter) ([]*Event, error) {
+	return s.events, nil
+}
+
+// planHandler deletes nothing and fails plans it doesn't know
+type planHandler struct {
+	echov1connect.UnimplementedPlanServiceHandler
+	known string
+}
+
+func (h *planHandler) DeletePlan(_ context.Context, req *connect.Request[echov1.DeletePlanRequest]) (*connect.Response[echov1.DeletePlanResponse], error) {
+	if req.Msg.PlanId != h.known {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("plan not found"))
+	}
+	return connect.NewResponse(&echov1.DeletePlanResponse{}), nil
+}
+
+func (h *planHandler) GetPlan(context.Context, *connect.Request[echov1.GetPlanRequest]) (*connect.Response[echov1.GetPlanResponse], error) {
+	return connect.NewResponse(&echov1.GetPlanResponse{}), nil
+}
+
+// withUser stands in for the auth interceptor
+func withUser(userID string) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, userID), req)
+		}
+	}
+}
+
+func TestInterceptor_RecordsAuditedCalls(t *testing.T) {
+	store := &memoryStore{}
+	userID, planID := uuid.New(), uuid.NewString()
+	var snapshotted string
+	proxies, err := interceptors.ParseTrustedProxies([]string{"127.0.0.1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	auditor := NewInterceptor(store, slog.New(slog.NewTextHandler(io.Discard, nil)), echov1connect.PlanServiceDeletePlanProcedure).
+		WithSnapshot(echov1connect.PlanServiceDeletePlanProcedure, func(_ context.Context, actorID uuid.UUID, req any) (map[string]any, error) {
+			snapshotted = actorID.String()
+			return map[string]any{"name": "Household"}, nil
+		}).
+		WithTrustedProxies(proxies)
+
+	path, h := echov1connect.NewPlanServiceHandler(&planHandler{known: planID},
+		connect.WithInterceptors(withUser(userID.String()), auditor))
+	mux := http.NewServeMux()
+	mux.Handle(path, h)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewPlanServiceClient(server.Client(), server.URL)
+	ctx := context.Background()
+
+	req := connect.NewRequest(&echov1.DeletePlanRequest{PlanId: planID})
+	req.Header().Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	_, err = client.DeletePlan(ctx, req)
+	require.NoError(t, err)
+	_, err = client.DeletePlan(ctx, connect.NewRequest(&echov1.DeletePlanRequest{PlanId: "missing"}))
+	require.Error(t, err)
+	_, err = client.GetPlan(ctx, connect.NewRequest(&echov1.GetPlanRequest{PlanId: planID}))
+	require.NoError(t, err)
+
+	require.Len(t, store.events, 2, "reads aren't audited")
+	ok, failed := store.events[0], store.events[1]
+	assert.Equal(t, echov1connect.PlanServiceDeletePlanProcedure, ok.Procedure)
+	require.NotNil(t, ok.ActorID)
+	assert.Equal(t, userID, *ok.ActorID)
+	assert.Equal(t, userID.String(), snapshotted)
+	assert.Equal(t, []string{planID}, ok.EntityIDs)
+	assert.Equal(t, map[string]any{"name": "Household"}, ok.Before)
+	assert.Equal(t, map[string]any{"plan_id": planID}, ok.After)
+	assert.Equal(t, OutcomeOK, ok.Outcome)
+	assert.Equal(t, "203.0.113.7", ok.IP)
+	assert.Nil(t, ok.Error)
+
+	assert.Equal(t, "not_found", failed.Outcome)
+	assert.Equal(t, "127.0.0.1", failed.IP, "the proxy's own address without X-Forwarded-For")
+	require.NotNil(t, failed.Error)
+	assert.Contains(t, *failed.Error, "plan not found")
+	assert.Equal(t, "127.0.0.1", failed.IP)
+}
+
+func TestSummarize_TrimsLongValuesAndOmitsBytes(t *testing.T) {
+	summary := summarize(&echov1.ImportTransactionsCsvRequest{
+		CsvBytes:        []byte("Date,Amount\n"),
+		InstitutionName: strings.Repeat("é", 150),
+	})
+
+	assert.Equal(t, "[omitted]", summary["csv_bytes"])
+	institution, ok := summary["institution_name"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(institution, "…"))
+	assert.LessOrEqual(t, len(institution), maxSummaryString+len("…"))
+}
+
+func TestListAuditEvents_AdminOnly(t *testing.T) {
+	svc := NewService(&memoryStore{})
+	_, err := svc.ListAuditEvents(context.Background(), ListFilter{})
+	assert.ErrorIs(t, err, ErrAdminRequired)
+}
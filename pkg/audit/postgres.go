@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore implements Store using PostgreSQL
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore creates a new PostgreSQL audit store
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// Record appends an event
+func (s *PostgresStore) Record(ctx context.Context, e *Event) error {
+	entityIDs := e.EntityIDs
+	if entityIDs == nil {
+		entityIDs = []string{}
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO audit_events (id, occurred_at, actor_id, procedure, entity_ids, before, after,
+			outcome, error, ip, user_agent, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, ''))`,
+		e.ID, e.OccurredAt, e.ActorID, e.Procedure, entityIDs, e.Before, e.After,
+		e.Outcome, e.Error, e.IP, e.UserAgent, e.RequestID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// List returns the events matching the filter, newest first
+func (s *PostgresStore) List(ctx context.Context, filter ListFilter) ([]*Event, error) {
+	var where []string
+	var args []any
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if filter.ActorID != nil {
+		add("actor_id = $%d", *filter.ActorID)
+	}
+	if filter.Procedure != "" {
+		add("procedure = $%d", filter.Procedure)
+	}
+	if filter.EntityID != "" {
+		add("$%d = ANY(entity_ids)", filter.EntityID)
+	}
+	if filter.Since != nil {
+		add("occurred_at >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		add("occurred_at < $%d", *filter.Until)
+	}
+	whereSQL := ""
+	if len(where) > 0 {
+		whereSQL = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(`
+		SELECT id, occurred_at, actor_id, procedure, entity_ids, before, after, outcome, error,
+			COALESCE(ip, ''), COALESCE(user_agent, ''), COALESCE(request_id, '')
+		FROM audit_events
+		%s
+		ORDER BY occurred_at DESC, id
+		LIMIT $%d OFFSET $%d`, whereSQL, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.OccurredAt, &e.ActorID, &e.Procedure, &e.EntityIDs, &e.Before, &e.After,
+			&e.Outcome, &e.Error, &e.IP, &e.UserAgent, &e.RequestID); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,110 @@
+// Package cache keeps computed per-user results, such as dashboard queries,
+// for a short TTL. Concurrent misses for the same entry share one load, and
+// a user's entries are dropped together when their data changes.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultTTL is how long entries are kept when no TTL is configured
+const DefaultTTL = 5 * time.Minute
+
+// Store keeps encoded entries per user. Each user has a generation that
+// Invalidate moves on: entries are stored and read under a generation, so a
+// load that started before an invalidation can't put stale data back.
+type Store interface {
+	// Generation returns the user's current generation
+	Generation(ctx context.Context, userID uuid.UUID) (int64, error)
+	// Get returns the entry stored under the generation, if it's still fresh
+	Get(ctx context.Context, userID uuid.UUID, generation int64, key string) ([]byte, bool, error)
+	// Set stores an entry under the generation for ttl
+	Set(ctx context.Context, userID uuid.UUID, generation int64, key string, value []byte, ttl time.Duration) error
+	// Invalidate drops all of the user's entries
+	Invalidate(ctx context.Context, userID uuid.UUID) error
+}
+
+// Cache loads entries through a store. A nil *Cache caches nothing, so
+// services can hold one optionally.
+type Cache struct {
+	store  Store
+	ttl    time.Duration
+	group  singleflight.Group
+	logger *slog.Logger
+}
+
+// New creates a cache keeping entries in store for ttl, DefaultTTL when zero
+func New(store Store, ttl time.Duration, logger *slog.Logger) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{store: store, ttl: ttl, logger: logger}
+}
+
+// Invalidate drops the user's entries after their data changed
+func (c *Cache) Invalidate(ctx context.Context, userID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	if err := c.store.Invalidate(ctx, userID); err != nil {
+		c.warn("failed to invalidate cache", userID, "", err)
+	}
+}
+
+// GetOrLoad returns the user's entry under key, calling load and storing its
+// result on a miss. The cache is best-effort: when the store fails, the
+// result is loaded directly.
+func GetOrLoad[T any](ctx context.Context, c *Cache, userID uuid.UUID, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	if c == nil {
+		return load(ctx)
+	}
+
+	generation, err := c.store.Generation(ctx, userID)
+	if err != nil {
+		c.warn("failed to read cache generation", userID, key, err)
+		return load(ctx)
+	}
+	if data, ok, err := c.store.Get(ctx, userID, generation, key); err != nil {
+		c.warn("failed to read cache entry", userID, key, err)
+	} else if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	flight := fmt.Sprintf("%s/%d/%s", userID, generation, key)
+	result, err, _ := c.group.Do(flight, func() (any, error) {
+		value, err := load(ctx)
+		if err != nil {
+			return value, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			c.warn("failed to encode cache entry", userID, key, err)
+			return value, nil
+		}
+		if err := c.store.Set(ctx, userID, generation, key, data, c.ttl); err != nil {
+			c.warn("failed to write cache entry", userID, key, err)
+		}
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.(T), nil
+}
+
+func (c *Cache) warn(msg string, userID uuid.UUID, key string, err error) {
+	if c.logger != nil {
+		c.logger.Warn(msg, "userID", userID, "key", key, "error", err)
+	}
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pulse struct {
+	SpendMinor int64
+	Categories []string
+}
+
+func TestGetOrLoad(t *testing.T) {
+	c := New(NewMemoryStore(), time.Minute, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	loads := 0
+	load := func(context.Context) (*pulse, error) {
+		loads++
+		return &pulse{SpendMinor: int64(loads * 100), Categories: []string{"Groceries"}}, nil
+	}
+
+	first, err := GetOrLoad(ctx, c, userID, "pulse", load)
+	require.NoError(t, err)
+	second, err := GetOrLoad(ctx, c, userID, "pulse", load)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, loads)
+
+	_, err = GetOrLoad(ctx, c, uuid.New(), "pulse", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads, "entries are per user")
+
+	c.Invalidate(ctx, userID)
+	third, err := GetOrLoad(ctx, c, userID, "pulse", load)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), third.SpendMinor, "invalidation drops the user's entries")
+}
+
+func TestGetOrLoad_Expires(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	c := New(store, time.Minute, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	loads := 0
+	load := func(context.Context) (int, error) { loads++; return loads, nil }
+
+	_, err := GetOrLoad(ctx, c, userID, "count", load)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	value, err := GetOrLoad(ctx, c, userID, "count", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+}
+
+func TestGetOrLoad_Errors(t *testing.T) {
+	c := New(NewMemoryStore(), time.Minute, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := GetOrLoad(ctx, c, userID, "pulse", func(context.Context) (int, error) { return 0, errors.New("db down") })
+	require.Error(t, err)
+
+	value, err := GetOrLoad(ctx, c, userID, "pulse", func(context.Context) (int, error) { return 7, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 7, value, "errors aren't cached")
+
+	var nilCache *Cache
+	value, err = GetOrLoad(ctx, nilCache, userID, "pulse", func(context.Context) (int, error) { return 9, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 9, value, "a nil cache loads directly")
+	nilCache.Invalidate(ctx, userID)
+}
+
+func TestGetOrLoad_SharesConcurrentLoads(t *testing.T) {
+	c := New(NewMemoryStore(), time.Minute, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrLoad(ctx, c, userID, "pulse", load)
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestGetOrLoad_InvalidatedDuringLoad(t *testing.T) {
+	c := New(NewMemoryStore(), time.Minute, nil)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	_, err := GetOrLoad(ctx, c, userID, "pulse", func(ctx context.Context) (string, error) {
+		c.Invalidate(ctx, userID) // A write lands while the load runs
+		return "stale", nil
+	})
+	require.NoError(t, err)
+
+	value, err := GetOrLoad(ctx, c, userID, "pulse", func(context.Context) (string, error) { return "fresh", nil })
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", value, "a load that raced an invalidation isn't stored")
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryUser holds a user's generation and the entries stored under it
+type memoryUser struct {
+	generation    int64
+	invalidatedAt time.Time
+	entries       map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore keeps entries in this process
+type MemoryStore struct {
+	mu     sync.Mutex
+	users  map[uuid.UUID]*memoryUser
+	swept  time.Time
+	maxTTL time.Duration
+	now    func() time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: make(map[uuid.UUID]*memoryUser), now: time.Now}
+}
+
+// Generation implements Store.
+func (s *MemoryStore) Generation(_ context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u, ok := s.users[userID]; ok {
+		return u.generation, nil
+	}
+	return 0, nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, userID uuid.UUID, generation int64, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok || u.generation != generation {
+		return nil, false, nil
+	}
+	e, ok := u.entries[key]
+	if !ok || !s.now().Before(e.expiresAt) {
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements Store. Entries for an old generation are dropped.
+func (s *MemoryStore) Set(_ context.Context, userID uuid.UUID, generation int64, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.maxTTL = max(s.maxTTL, ttl)
+	if now.Sub(s.swept) > s.maxTTL {
+		s.sweep(now)
+	}
+
+	u, ok := s.users[userID]
+	if !ok {
+		u = &memoryUser{}
+		s.users[userID] = u
+	}
+	if u.generation != generation {
+		return nil
+	}
+	if u.entries == nil {
+		u.entries = make(map[string]memoryEntry)
+	}
+	u.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Invalidate implements Store.
+func (s *MemoryStore) Invalidate(_ context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userID]
+	if !ok {
+		u = &memoryUser{}
+		s.users[userID] = u
+	}
+	u.generation++
+	u.invalidatedAt = s.now()
+	u.entries = nil
+	return nil
+}
+
+// sweep drops expired entries, and users left without any. Recently
+// invalidated users are kept, so a load that started before the invalidation
+// still finds its generation outdated.
+func (s *MemoryStore) sweep(now time.Time) {
+	for userID, u := range s.users {
+		for key, e := range u.entries {
+			if !now.Before(e.expiresAt) {
+				delete(u.entries, key)
+			}
+		}
+		if len(u.entries) == 0 && now.Sub(u.invalidatedAt) > s.maxTTL {
+			delete(s.users, userID)
+		}
+	}
+	s.swept = now
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps entries in Redis, sharing them between servers. Entries
+// are keyed by the user's generation, so invalidating is a single INCR and
+// entries of old generations just expire.
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore creates a Redis store
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{client: client, prefix: "cache:"}
+}
+
+func (s *RedisStore) generationKey(userID uuid.UUID) string {
+	return s.prefix + "gen:" + userID.String()
+}
+
+func (s *RedisStore) entryKey(userID uuid.UUID, generation int64, key string) string {
+	return fmt.Sprintf("%s%s:%d:%s", s.prefix, userID, generation, key)
+}
+
+// Generation implements Store.
+func (s *RedisStore) Generation(ctx context.Context, userID uuid.UUID) (int64, error) {
+	generation, err := s.client.Get(ctx, s.generationKey(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cache generation: %w", err)
+	}
+	return generation, nil
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, userID uuid.UUID, generation int64, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.entryKey(userID, generation, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+	return value, true, nil
+}
+
+// Set implements Store.
+func (s *RedisStore) Set(ctx context.Context, userID uuid.UUID, generation int64, key string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.entryKey(userID, generation, key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set cache entry: %w", err)
+	}
+	return nil
+}
+
+// Invalidate implements Store.
+func (s *RedisStore) Invalidate(ctx context.Context, userID uuid.UUID) error {
+	if err := s.client.Incr(ctx, s.generationKey(userID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+	return nil
+}
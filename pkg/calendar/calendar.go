@@ -0,0 +1,180 @@
+// Package calendar provides business-day logic with per-country bank holidays,
+// so predicted dates (charges, paydays, forecasts) land on days banks post.
+package calendar
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Convention decides which business day a non-business day moves to
+type Convention int
+
+const (
+	// Following moves to the next business day (card charges, direct debits)
+	Following Convention = iota
+	// Preceding moves to the previous business day (salaries paid early)
+	Preceding
+	// ModifiedFollowing moves forward unless that crosses into the next month,
+	// in which case it moves back
+	ModifiedFollowing
+)
+
+// maxAdjustDays bounds adjustment loops; no calendar has this many consecutive closures
+const maxAdjustDays = 14
+
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// Calendar is a country's business calendar: weekdays that aren't bank holidays
+type Calendar struct {
+	country  string
+	holidays func(year int) map[date]string
+
+	mu    sync.Mutex
+	years map[int]map[date]string
+}
+
+func newCalendar(country string, holidays func(year int) map[date]string) *Calendar {
+	return &Calendar{country: country, holidays: holidays, years: make(map[int]map[date]string)}
+}
+
+// Default is the weekends-only calendar used when a country is unknown
+var Default = newCalendar("", func(int) map[date]string { return nil })
+
+// Country returns the calendar's ISO 3166-1 alpha-2 code ("" for Default)
+func (c *Calendar) Country() string {
+	return c.country
+}
+
+// Holiday returns the name of the bank holiday on t's date, if any
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	d := dateOf(t)
+
+	c.mu.Lock()
+	year, ok := c.years[d.year]
+	if !ok {
+		year = c.holidays(d.year)
+		c.years[d.year] = year
+	}
+	c.mu.Unlock()
+
+	name, ok := year[d]
+	return name, ok
+}
+
+// IsBusinessDay reports whether t falls on a weekday that isn't a bank holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(t)
+	return !holiday
+}
+
+// Adjust moves t to a business day using the convention. Business days are
+// returned unchanged; the time of day is preserved.
+func (c *Calendar) Adjust(t time.Time, conv Convention) time.Time {
+	switch conv {
+	case Preceding:
+		return c.step(t, -1)
+	case ModifiedFollowing:
+		if next := c.step(t, 1); next.Month() == t.Month() {
+			return next
+		}
+		return c.step(t, -1)
+	default:
+		return c.step(t, 1)
+	}
+}
+
+// AddBusinessDays moves t by n business days (negative n moves back)
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, dir)
+		if c.IsBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// step walks from t in dir until it reaches a business day
+func (c *Calendar) step(t time.Time, dir int) time.Time {
+	for i := 0; i < maxAdjustDays && !c.IsBusinessDay(t); i++ {
+		t = t.AddDate(0, 0, dir)
+	}
+	return t
+}
+
+// ForCountry returns the calendar for an ISO 3166-1 alpha-2/alpha-3 code or an
+// English or native country name, falling back to Default when unknown
+func ForCountry(country string) *Calendar {
+	key := strings.ToLower(strings.TrimSpace(country))
+	if code, ok := countryAliases[key]; ok {
+		key = code
+	}
+	if cal, ok := calendars[strings.ToUpper(key)]; ok {
+		return cal
+	}
+	return Default
+}
+
+// AddMonthsClamped adds months keeping the day within the target month
+// (Jan 31 + 1 month = Feb 28), unlike time.AddDate which overflows into March
+func AddMonthsClamped(t time.Time, months int) time.Time {
+	return WithDayClamped(time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), t.Day())
+}
+
+// WithDayClamped sets t's day of month, clamped to the month's last day
+func WithDayClamped(t time.Time, day int) time.Time {
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(t.Year(), t.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// CountryLookup finds the country a user's dates should follow
+type CountryLookup interface {
+	UserCountry(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// Resolver picks each user's calendar from their profile country
+type Resolver struct {
+	lookup CountryLookup
+}
+
+// NewResolver creates a resolver backed by a country lookup
+func NewResolver(lookup CountryLookup) *Resolver {
+	return &Resolver{lookup: lookup}
+}
+
+// ForUser returns the user's calendar. A nil resolver, failed lookup or unknown
+// country yields Default, so date logic still skips weekends.
+func (r *Resolver) ForUser(ctx context.Context, userID uuid.UUID) *Calendar {
+	if r == nil || r.lookup == nil {
+		return Default
+	}
+	country, err := r.lookup.UserCountry(ctx, userID)
+	if err != nil {
+		return Default
+	}
+	return ForCountry(country)
+}
@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEasterSunday(t *testing.T) {
+	tests := map[int]time.Time{
+		2024: day(2024, time.March, 31),
+		2025: day(2025, time.April, 20),
+		2026: day(2026, time.April, 5),
+		2027: day(2027, time.March, 28),
+	}
+	for year, want := range tests {
+		if got := easterSunday(year); !got.Equal(want) {
+			t.Errorf("easter %d = %s, want %s", year, got.Format(time.DateOnly), want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestForCountry(t *testing.T) {
+	for _, country := range []string{"PT", "pt", "PRT", "Portugal", " portugal "} {
+		if got := ForCountry(country).Country(); got != "PT" {
+			t.Errorf("ForCountry(%q) = %q, want PT", country, got)
+		}
+	}
+	if ForCountry("Testland") != Default || ForCountry("") != Default {
+		t.Errorf("expected unknown countries to use the default calendar")
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	tests := []struct {
+		country string
+		on      time.Time
+		want    string
+	}{
+		{"PT", day(2026, time.April, 3), "Sexta-feira Santa"},
+		{"PT", day(2026, time.June, 4), "Corpo de Deus"},
+		{"PT", day(2026, time.December, 8), "Imaculada Conceição"},
+		{"DE", day(2026, time.May, 14), "Christi Himmelfahrt"},
+		{"FR", day(2026, time.May, 25), "Lundi de Pentecôte"},
+		{"GB", day(2026, time.May, 25), "Spring bank holiday"},
+		{"GB", day(2026, time.December, 28), "Boxing Day"}, // 26th is a Saturday
+		{"GB", day(2027, time.December, 27), "Christmas Day"},
+		{"GB", day(2027, time.December, 28), "Boxing Day"},
+		{"GB", day(2028, time.January, 3), "New Year's Day"},
+		{"US", day(2026, time.November, 26), "Thanksgiving Day"},
+		{"US", day(2026, time.January, 19), "Martin Luther King Jr. Day"},
+		{"US", day(2022, time.December, 26), "Christmas Day"}, // Sunday observed Monday
+	}
+	for _, tt := range tests {
+		got, ok := ForCountry(tt.country).Holiday(tt.on)
+		if !ok || got != tt.want {
+			t.Errorf("%s %s = %q (%v), want %q", tt.country, tt.on.Format(time.DateOnly), got, ok, tt.want)
+		}
+	}
+
+	// The Fed doesn't observe Saturday holidays on the Friday before
+	if _, ok := ForCountry("US").Holiday(day(2026, time.July, 3)); ok {
+		t.Errorf("expected Friday before a Saturday Independence Day to be a business day")
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	pt := ForCountry("PT")
+
+	// Saturday 4 April 2026 follows Good Friday: Following skips to Monday,
+	// Preceding goes back past Good Friday to Thursday
+	saturday := day(2026, time.April, 4)
+	if got := pt.Adjust(saturday, Following); !got.Equal(day(2026, time.April, 6)) {
+		t.Errorf("following = %s", got.Format(time.DateOnly))
+	}
+	if got := pt.Adjust(saturday, Preceding); !got.Equal(day(2026, time.April, 2)) {
+		t.Errorf("preceding = %s", got.Format(time.DateOnly))
+	}
+
+	// Saturday 31 January 2026: moving forward would leave the month
+	if got := pt.Adjust(day(2026, time.January, 31), ModifiedFollowing); !got.Equal(day(2026, time.January, 30)) {
+		t.Errorf("modified following = %s", got.Format(time.DateOnly))
+	}
+
+	business := day(2026, time.April, 7)
+	if got := pt.Adjust(business, Following); !got.Equal(business) {
+		t.Errorf("expected business days to be unchanged, got %s", got.Format(time.DateOnly))
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	pt := ForCountry("PT")
+	// Thursday 2 April 2026 + 1 business day skips Good Friday and the weekend
+	if got := pt.AddBusinessDays(day(2026, time.April, 2), 1); !got.Equal(day(2026, time.April, 6)) {
+		t.Errorf("add = %s", got.Format(time.DateOnly))
+	}
+	if got := pt.AddBusinessDays(day(2026, time.April, 6), -1); !got.Equal(day(2026, time.April, 2)) {
+		t.Errorf("subtract = %s", got.Format(time.DateOnly))
+	}
+}
+
+func TestAddMonthsClamped(t *testing.T) {
+	if got := AddMonthsClamped(day(2026, time.January, 31), 1); !got.Equal(day(2026, time.February, 28)) {
+		t.Errorf("got %s", got.Format(time.DateOnly))
+	}
+	if got := AddMonthsClamped(day(2026, time.November, 30), 3); !got.Equal(day(2027, time.February, 28)) {
+		t.Errorf("got %s", got.Format(time.DateOnly))
+	}
+}
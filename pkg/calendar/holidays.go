@@ -0,0 +1,210 @@
+package calendar
+
+import "time"
+
+// calendars holds the supported countries' national bank holidays. Regional
+// holidays (German Länder, Spanish autonomous communities, etc.) aren't included.
+var calendars = map[string]*Calendar{
+	"PT": newCalendar("PT", portugal),
+	"ES": newCalendar("ES", spain),
+	"FR": newCalendar("FR", france),
+	"DE": newCalendar("DE", germany),
+	"GB": newCalendar("GB", unitedKingdom),
+	"US": newCalendar("US", unitedStates),
+}
+
+// countryAliases maps lowercased alpha-3 codes and country names to alpha-2 codes
+var countryAliases = map[string]string{
+	"prt": "pt", "portugal": "pt",
+	"esp": "es", "spain": "es", "españa": "es", "espana": "es",
+	"fra": "fr", "france": "fr",
+	"deu": "de", "germany": "de", "deutschland": "de",
+	"gbr": "gb", "uk": "gb", "united kingdom": "gb", "great britain": "gb", "england": "gb",
+	"usa": "us", "united states": "us", "united states of america": "us",
+}
+
+func portugal(year int) map[date]string {
+	easter := easterSunday(year)
+	return holidaySet(
+		fixed(year, time.January, 1, "Ano Novo"),
+		relative(easter, -2, "Sexta-feira Santa"),
+		fixed(year, time.April, 25, "Dia da Liberdade"),
+		fixed(year, time.May, 1, "Dia do Trabalhador"),
+		relative(easter, 60, "Corpo de Deus"),
+		fixed(year, time.June, 10, "Dia de Portugal"),
+		fixed(year, time.August, 15, "Assunção de Nossa Senhora"),
+		fixed(year, time.October, 5, "Implantação da República"),
+		fixed(year, time.November, 1, "Dia de Todos-os-Santos"),
+		fixed(year, time.December, 1, "Restauração da Independência"),
+		fixed(year, time.December, 8, "Imaculada Conceição"),
+		fixed(year, time.December, 25, "Natal"),
+	)
+}
+
+func spain(year int) map[date]string {
+	easter := easterSunday(year)
+	return holidaySet(
+		fixed(year, time.January, 1, "Año Nuevo"),
+		fixed(year, time.January, 6, "Epifanía del Señor"),
+		relative(easter, -2, "Viernes Santo"),
+		fixed(year, time.May, 1, "Fiesta del Trabajo"),
+		fixed(year, time.August, 15, "Asunción de la Virgen"),
+		fixed(year, time.October, 12, "Fiesta Nacional de España"),
+		fixed(year, time.November, 1, "Todos los Santos"),
+		fixed(year, time.December, 6, "Día de la Constitución"),
+		fixed(year, time.December, 8, "Inmaculada Concepción"),
+		fixed(year, time.December, 25, "Navidad"),
+	)
+}
+
+func france(year int) map[date]string {
+	easter := easterSunday(year)
+	return holidaySet(
+		fixed(year, time.January, 1, "Jour de l'an"),
+		relative(easter, 1, "Lundi de Pâques"),
+		fixed(year, time.May, 1, "Fête du Travail"),
+		fixed(year, time.May, 8, "Victoire 1945"),
+		relative(easter, 39, "Ascension"),
+		relative(easter, 50, "Lundi de Pentecôte"),
+		fixed(year, time.July, 14, "Fête nationale"),
+		fixed(year, time.August, 15, "Assomption"),
+		fixed(year, time.November, 1, "Toussaint"),
+		fixed(year, time.November, 11, "Armistice 1918"),
+		fixed(year, time.December, 25, "Noël"),
+	)
+}
+
+func germany(year int) map[date]string {
+	easter := easterSunday(year)
+	return holidaySet(
+		fixed(year, time.January, 1, "Neujahr"),
+		relative(easter, -2, "Karfreitag"),
+		relative(easter, 1, "Ostermontag"),
+		fixed(year, time.May, 1, "Tag der Arbeit"),
+		relative(easter, 39, "Christi Himmelfahrt"),
+		relative(easter, 50, "Pfingstmontag"),
+		fixed(year, time.October, 3, "Tag der Deutschen Einheit"),
+		fixed(year, time.December, 25, "1. Weihnachtstag"),
+		fixed(year, time.December, 26, "2. Weihnachtstag"),
+	)
+}
+
+// unitedKingdom covers England and Wales bank holidays, with weekend holidays
+// substituted by the next free weekday
+func unitedKingdom(year int) map[date]string {
+	easter := easterSunday(year)
+	christmas := time.Date(year, time.December, 25, 0, 0, 0, 0, time.UTC)
+	boxingDay := christmas.AddDate(0, 0, 1)
+	switch christmas.Weekday() {
+	case time.Friday:
+		boxingDay = christmas.AddDate(0, 0, 3) // Boxing Day moves to Monday
+	case time.Saturday, time.Sunday:
+		christmas = time.Date(year, time.December, 27, 0, 0, 0, 0, time.UTC)
+		boxingDay = time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC)
+	}
+
+	return holidaySet(
+		nextMonday(fixed(year, time.January, 1, "New Year's Day")),
+		relative(easter, -2, "Good Friday"),
+		relative(easter, 1, "Easter Monday"),
+		nthWeekday(year, time.May, time.Monday, 1, "Early May bank holiday"),
+		lastWeekday(year, time.May, time.Monday, "Spring bank holiday"),
+		lastWeekday(year, time.August, time.Monday, "Summer bank holiday"),
+		holiday{christmas, "Christmas Day"},
+		holiday{boxingDay, "Boxing Day"},
+	)
+}
+
+// unitedStates covers Federal Reserve holidays: Sunday holidays are observed on
+// Monday, Saturday holidays aren't observed
+func unitedStates(year int) map[date]string {
+	set := []holiday{
+		sundayToMonday(fixed(year, time.January, 1, "New Year's Day")),
+		nthWeekday(year, time.January, time.Monday, 3, "Martin Luther King Jr. Day"),
+		nthWeekday(year, time.February, time.Monday, 3, "Washington's Birthday"),
+		lastWeekday(year, time.May, time.Monday, "Memorial Day"),
+		sundayToMonday(fixed(year, time.July, 4, "Independence Day")),
+		nthWeekday(year, time.September, time.Monday, 1, "Labor Day"),
+		nthWeekday(year, time.October, time.Monday, 2, "Columbus Day"),
+		sundayToMonday(fixed(year, time.November, 11, "Veterans Day")),
+		nthWeekday(year, time.November, time.Thursday, 4, "Thanksgiving Day"),
+		sundayToMonday(fixed(year, time.December, 25, "Christmas Day")),
+	}
+	if year >= 2021 {
+		set = append(set, sundayToMonday(fixed(year, time.June, 19, "Juneteenth")))
+	}
+	return holidaySet(set...)
+}
+
+type holiday struct {
+	on   time.Time
+	name string
+}
+
+func holidaySet(holidays ...holiday) map[date]string {
+	set := make(map[date]string, len(holidays))
+	for _, h := range holidays {
+		set[dateOf(h.on)] = h.name
+	}
+	return set
+}
+
+func fixed(year int, month time.Month, day int, name string) holiday {
+	return holiday{time.Date(year, month, day, 0, 0, 0, 0, time.UTC), name}
+}
+
+func relative(base time.Time, days int, name string) holiday {
+	return holiday{base.AddDate(0, 0, days), name}
+}
+
+// nthWeekday is the nth given weekday of the month (1 = first)
+func nthWeekday(year int, month time.Month, wd time.Weekday, n int, name string) holiday {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(wd) - int(first.Weekday()) + 7) % 7
+	return holiday{first.AddDate(0, 0, offset+7*(n-1)), name}
+}
+
+// lastWeekday is the last given weekday of the month
+func lastWeekday(year int, month time.Month, wd time.Weekday, name string) holiday {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	offset := (int(last.Weekday()) - int(wd) + 7) % 7
+	return holiday{last.AddDate(0, 0, -offset), name}
+}
+
+// nextMonday substitutes a weekend holiday with the following Monday
+func nextMonday(h holiday) holiday {
+	switch h.on.Weekday() {
+	case time.Saturday:
+		h.on = h.on.AddDate(0, 0, 2)
+	case time.Sunday:
+		h.on = h.on.AddDate(0, 0, 1)
+	}
+	return h
+}
+
+// sundayToMonday substitutes a Sunday holiday with the following Monday
+func sundayToMonday(h holiday) holiday {
+	if h.on.Weekday() == time.Sunday {
+		h.on = h.on.AddDate(0, 0, 1)
+	}
+	return h
+}
+
+// easterSunday computes Western Easter using the anonymous Gregorian algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
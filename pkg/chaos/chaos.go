@@ -0,0 +1,170 @@
+// Package chaos provides env-gated failure injection for resilience testing.
+// Faults are configured per RPC procedure and applied to the repository calls
+// made while handling that RPC, so retry and recovery paths can be exercised
+// against a real database. It must never be enabled in production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrInjected is the error returned by repository calls failed by an injected fault
+var ErrInjected = errors.New("chaos: injected fault")
+
+// FaultKind is the effect a fault has on a repository call
+type FaultKind string
+
+const (
+	FaultError FaultKind = "error" // Fail the call with ErrInjected
+	FaultDelay FaultKind = "delay" // Slow the call down before it runs
+)
+
+// Fault injects failures into the repository calls of matching RPCs
+type Fault struct {
+	// Procedure is a full procedure ("/echo.v1.PlanService/GetPlan"), a service
+	// wildcard ("/echo.v1.PlanService/*") or "*" for every RPC
+	Procedure   string
+	Kind        FaultKind
+	Delay       time.Duration
+	Probability float64 // Chance each call is affected, 0-1
+}
+
+// Matches reports whether the fault applies to a procedure
+func (f Fault) Matches(procedure string) bool {
+	if f.Procedure == "*" || f.Procedure == procedure {
+		return true
+	}
+	if service, ok := strings.CutSuffix(f.Procedure, "/*"); ok {
+		return strings.HasPrefix(procedure, service+"/")
+	}
+	return false
+}
+
+// ParseFaults parses a fault spec of semicolon-separated rules in the form
+// "procedure=error[@probability]" or "procedure=delay:duration[@probability]", e.g.
+//
+//	/echo.v1.ImportService/*=error@0.3;/echo.v1.PlanService/GetPlan=delay:2s
+//
+// Probability defaults to 1 (every call).
+func ParseFaults(spec string) ([]Fault, error) {
+	var faults []Fault
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		procedure, effect, ok := strings.Cut(rule, "=")
+		if !ok || procedure == "" {
+			return nil, fmt.Errorf("invalid fault %q: expected procedure=effect", rule)
+		}
+		fault := Fault{Procedure: strings.TrimSpace(procedure), Probability: 1}
+
+		effect, probability, hasProbability := strings.Cut(strings.TrimSpace(effect), "@")
+		if hasProbability {
+			p, err := strconv.ParseFloat(probability, 64)
+			if err != nil || p < 0 || p > 1 {
+				return nil, fmt.Errorf("invalid fault %q: probability must be between 0 and 1", rule)
+			}
+			fault.Probability = p
+		}
+
+		kind, arg, _ := strings.Cut(effect, ":")
+		switch FaultKind(kind) {
+		case FaultError:
+			fault.Kind = FaultError
+		case FaultDelay:
+			delay, err := time.ParseDuration(arg)
+			if err != nil || delay <= 0 {
+				return nil, fmt.Errorf("invalid fault %q: delay requires a positive duration", rule)
+			}
+			fault.Kind = FaultDelay
+			fault.Delay = delay
+		default:
+			return nil, fmt.Errorf("invalid fault %q: unknown effect %q", rule, kind)
+		}
+
+		faults = append(faults, fault)
+	}
+	return faults, nil
+}
+
+type faultsKey struct{}
+
+// Injector applies configured faults to repository calls
+type Injector struct {
+	faults []Fault
+	roll   func() float64
+}
+
+// NewInjector creates an injector for the given faults
+func NewInjector(faults []Fault) *Injector {
+	return &Injector{faults: faults, roll: rand.Float64}
+}
+
+// WithProcedure returns a context carrying the faults that apply to a procedure
+func (i *Injector) WithProcedure(ctx context.Context, procedure string) context.Context {
+	var matched []Fault
+	for _, f := range i.faults {
+		if f.Matches(procedure) {
+			matched = append(matched, f)
+		}
+	}
+	if len(matched) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, faultsKey{}, matched)
+}
+
+// Inject applies the faults carried by ctx to one call: delays first, then errors.
+// It returns ErrInjected for an injected failure, or the context's error if the
+// context ends during a delay.
+func (i *Injector) Inject(ctx context.Context) error {
+	faults, _ := ctx.Value(faultsKey{}).([]Fault)
+	for _, f := range faults {
+		if f.Kind != FaultDelay || i.roll() >= f.Probability {
+			continue
+		}
+		timer := time.NewTimer(f.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	for _, f := range faults {
+		if f.Kind == FaultError && i.roll() < f.Probability {
+			return fmt.Errorf("%w (%s)", ErrInjected, f.Procedure)
+		}
+	}
+	return nil
+}
+
+// NewInterceptor tags each RPC's context with the faults for its procedure
+func (i *Injector) NewInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(i.WithProcedure(ctx, req.Spec().Procedure), req)
+		}
+	}
+}
+
+// Install hooks the injector into a pool, so every connection acquire (each
+// repository query, or the start of a transaction) can be failed or slowed.
+func (i *Injector) Install(cfg *pgxpool.Config) {
+	cfg.PrepareConn = func(ctx context.Context, _ *pgx.Conn) (bool, error) {
+		// Keep the connection either way; only the instigating call fails
+		return true, i.Inject(ctx)
+	}
+}
@@ -0,0 +1,92 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("/echo.v1.ImportService/*=error@0.3; /echo.v1.PlanService/GetPlan=delay:2s")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(faults) != 2 {
+		t.Fatalf("expected 2 faults, got %d", len(faults))
+	}
+	if faults[0].Kind != FaultError || faults[0].Probability != 0.3 {
+		t.Errorf("unexpected error fault: %+v", faults[0])
+	}
+	if faults[1].Kind != FaultDelay || faults[1].Delay != 2*time.Second || faults[1].Probability != 1 {
+		t.Errorf("unexpected delay fault: %+v", faults[1])
+	}
+
+	for _, spec := range []string{"error", "/a/B=explode", "/a/B=delay", "/a/B=error@1.5"} {
+		if _, err := ParseFaults(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestFaultMatches(t *testing.T) {
+	tests := []struct {
+		procedure string
+		target    string
+		want      bool
+	}{
+		{"*", "/echo.v1.PlanService/GetPlan", true},
+		{"/echo.v1.PlanService/GetPlan", "/echo.v1.PlanService/GetPlan", true},
+		{"/echo.v1.PlanService/*", "/echo.v1.PlanService/UpdatePlan", true},
+		{"/echo.v1.PlanService/*", "/echo.v1.PlanServiceV2/GetPlan", false},
+		{"/echo.v1.PlanService/GetPlan", "/echo.v1.PlanService/GetPlans", false},
+	}
+	for _, tt := range tests {
+		if got := (Fault{Procedure: tt.procedure}).Matches(tt.target); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.procedure, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestInjector_OnlyAffectsMatchingRPCs(t *testing.T) {
+	inj := NewInjector([]Fault{{Procedure: "/echo.v1.ImportService/*", Kind: FaultError, Probability: 1}})
+
+	if err := inj.Inject(inj.WithProcedure(context.Background(), "/echo.v1.ImportService/ImportTransactions")); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if err := inj.Inject(inj.WithProcedure(context.Background(), "/echo.v1.PlanService/GetPlan")); err != nil {
+		t.Fatalf("expected no fault for other services, got %v", err)
+	}
+	// Calls outside an RPC (scheduler jobs, migrations) are never affected
+	if err := inj.Inject(context.Background()); err != nil {
+		t.Fatalf("expected no fault without a procedure, got %v", err)
+	}
+}
+
+func TestInjector_Probability(t *testing.T) {
+	inj := NewInjector([]Fault{{Procedure: "*", Kind: FaultError, Probability: 0.5}})
+	ctx := inj.WithProcedure(context.Background(), "/echo.v1.PlanService/GetPlan")
+
+	inj.roll = func() float64 { return 0.7 }
+	if err := inj.Inject(ctx); err != nil {
+		t.Fatalf("expected roll above probability to pass, got %v", err)
+	}
+	inj.roll = func() float64 { return 0.2 }
+	if err := inj.Inject(ctx); !errors.Is(err, ErrInjected) {
+		t.Fatalf("expected roll below probability to fail, got %v", err)
+	}
+}
+
+func TestInjector_DelayRespectsContext(t *testing.T) {
+	inj := NewInjector([]Fault{{Procedure: "*", Kind: FaultDelay, Delay: time.Minute, Probability: 1}})
+	ctx, cancel := context.WithTimeout(inj.WithProcedure(context.Background(), "/echo.v1.PlanService/GetPlan"), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := inj.Inject(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("delay ignored context deadline")
+	}
+}
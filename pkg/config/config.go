@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	// Load environment variables from .env files when present.
 	_ "github.com/joho/godotenv"
@@ -18,6 +19,21 @@ type Config struct {
 	Observability ObservabilityConfig
 	Profiling     ProfilingConfig
 	Gemini        GeminiConfig
+	Google        GoogleConfig
+	OAuth         OAuthConfig
+	Scheduler     SchedulerConfig
+	Chaos         ChaosConfig
+	Storage       StorageConfig
+	Quota         QuotaConfig
+	WebPush       WebPushConfig
+	Telegram      TelegramConfig
+	Stripe        StripeConfig
+	Plaid         PlaidConfig
+	RateLimit     RateLimitConfig
+	Cache         CacheConfig
+	Validation    ValidationConfig
+	Speech        SpeechConfig
+	FX            FXConfig
 }
 
 type GeminiConfig struct {
@@ -25,12 +41,139 @@ type GeminiConfig struct {
 	Model  string
 }
 
+// GoogleConfig holds the OAuth client used for Google Sheets sync and Google
+// sign-in. Both are disabled when ClientID is empty.
+type GoogleConfig struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthConfig holds the Sign in with Apple key and the app URLs OAuth sign-in
+// may return tokens to. Apple sign-in is disabled when AppleClientID is empty.
+type OAuthConfig struct {
+	AppleClientID   string   // Services ID the web flow signs in with
+	AppleTeamID     string   // Apple developer team that owns the key
+	AppleKeyID      string   // ID of the Sign in with Apple key
+	ApplePrivateKey string   // PKCS#8 PEM of the key, used to sign client secrets
+	RedirectURLs    []string // Allowed redirect_uri values; tokens are returned as JSON when none is given
+}
+
+// ChaosConfig enables failure injection for resilience tests. Faults is a spec
+// parsed by chaos.ParseFaults. Never enable outside test environments.
+type ChaosConfig struct {
+	Enabled bool
+	Faults  string
+}
+
+// StorageConfig holds the per-user storage quotas for uploaded files, by
+// subscription tier, the antivirus uploads are scanned with, and the master
+// keys files are encrypted with. Uploads still get type and size checks when
+// ClamAVAddress is empty, and are stored unencrypted when EncryptionKeys is.
+type StorageConfig struct {
+	FreeQuotaMB    int
+	PremiumQuotaMB int
+	ClamAVAddress  string // clamd "host:port" or unix socket path
+	EncryptionKeys string // "id:base64key,..." with the primary key first
+}
+
+// QuotaConfig holds the per-user transaction and plan quotas, by
+// subscription tier. Zero is unlimited.
+type QuotaConfig struct {
+	FreeMaxTransactions    int
+	FreeMaxPlans           int
+	PremiumMaxTransactions int
+	PremiumMaxPlans        int
+}
+
+// WebPushConfig holds the VAPID key browsers subscribe to web push with.
+// Web push is disabled when VAPIDPrivateKey is empty.
+type WebPushConfig struct {
+	VAPIDPrivateKey string // Base64url raw P-256 private key
+	Subject         string // "mailto:" or "https:" contact for push services; defaults to the server base URL
+}
+
+// TelegramConfig holds the bot used for quick capture and alerts over Telegram.
+// The bot is disabled when BotToken is empty.
+type TelegramConfig struct {
+	BotToken      string
+	WebhookSecret string // Sent by Telegram in every webhook request; required to accept updates
+	BotUsername   string // Used to build t.me deep links for account linking
+}
+
+// StripeConfig holds the Stripe account paid plans are billed through and the
+// prices of those plans. Billing is disabled when SecretKey is empty.
+type StripeConfig struct {
+	SecretKey     string
+	WebhookSecret string // Signs every webhook event; required to accept them
+	PriceMonthly  string // Price ID of the premium_monthly plan
+	PriceAnnual   string // Price ID of the premium_annual plan
+}
+
+// PlaidConfig holds the bank-data aggregator connected accounts sync through.
+// Readiness doesn't probe it when BaseURL is empty.
+type PlaidConfig struct {
+	BaseURL string // API host of the Plaid environment, like https://production.plaid.com
+}
+
+// SpeechConfig holds the speech-to-text voice capture transcribes with: a
+// local whisper.cpp binary when WhisperModel is set, otherwise a cloud API
+// speaking OpenAI's protocol when APIKey is. Voice capture is disabled when
+// neither is.
+type SpeechConfig struct {
+	WhisperBinary string // Path to whisper.cpp's "whisper-cli"
+	WhisperModel  string // Path to a ggml model file
+	APIKey        string
+	APIURL        string // Base URL of the cloud API; defaults to OpenAI's
+	Model         string // Cloud transcription model; defaults to whisper-1
+}
+
+// FXConfig holds the exchange-rate feed amounts are converted with:
+// "ecb" for the European Central Bank's euro reference rates or
+// "exchangeratehost" for exchangerate.host, which needs AccessKey. ManualRates
+// override the feed, e.g. "EUR:AOA=950.5,CVE=110.265". Conversion is disabled
+// when neither Provider nor ManualRates is set.
+type FXConfig struct {
+	Provider    string
+	AccessKey   string
+	Source      string // Currency exchangerate.host quotes against; defaults to EUR
+	ManualRates string
+}
+
 type ServerConfig struct {
 	Host               string
 	Port               int
 	BaseURL            string
 	RateLimitPerSecond int
 	RateLimitBurst     int
+	TrustedProxies     []string // Proxy addresses or CIDRs whose X-Forwarded-For is believed
+}
+
+// RateLimitConfig holds the per-user and per-IP budgets on top of the global
+// server limit. Budgets are shared between servers through Redis when
+// RedisURL is set, and kept in memory otherwise. A zero rate disables a budget.
+type RateLimitConfig struct {
+	RedisURL          string
+	UserPerSecond     int // Calls a signed-in user may make a second
+	UserBurst         int
+	IPPerSecond       int // Calls a client address may make a second
+	IPBurst           int
+	AuthPerMinute     int // Sign-in, sign-up and refresh attempts per client address
+	ImportMBPerMinute int // Megabytes of files a user may upload for import
+}
+
+// CacheConfig holds the cache of per-user dashboard queries. Entries are
+// shared between servers through Redis when RedisURL is set, and kept in
+// memory otherwise.
+type CacheConfig struct {
+	RedisURL   string
+	TTLSeconds int // How long an entry is kept unless the user's data changes
+}
+
+// ValidationConfig holds the request bounds checked before handlers run
+type ValidationConfig struct {
+	MaxCSVMB    int // Size of a CSV upload
+	MaxCSVRows  int // Lines of a CSV upload, header included
+	MaxPageSize int // Largest page a list call may ask for
 }
 
 type DatabaseConfig struct {
@@ -40,16 +183,37 @@ type DatabaseConfig struct {
 	Password string
 	Database string
 	SSLMode  string
+
+	// Connection pool tuning
+	MaxConns                int
+	MinConns                int
+	MaxConnLifetimeSeconds  int
+	MaxConnIdleSeconds      int
+	HealthCheckSeconds      int
+	StatementTimeoutSeconds int // Server-side cap on any statement; 0 disables
+	// AnalyticsTimeoutSeconds caps the heavy insights queries so one slow
+	// dashboard can't hold connections other requests need; 0 disables
+	AnalyticsTimeoutSeconds int
+	// ReplicaURL is the DSN of a read replica for uncached insights reports,
+	// transaction searches and data exports; empty runs everything on the primary
+	ReplicaURL string
 }
 
 type AuthConfig struct {
 	JWTSecret  string
 	AdminEmail string
+	// AccountDeletionGraceDays is how long a deleted account can be restored
+	// before all of its data is erased
+	AccountDeletionGraceDays int
 }
 
 type ObservabilityConfig struct {
-	MetricsEnabled bool
-	MetricsPort    int
+	MetricsEnabled     bool
+	MetricsPort        int
+	TracingEnabled     bool
+	OTLPEndpoint       string // OTLP/HTTP collector host:port
+	TracingInsecure    bool
+	TraceSamplePercent int // Share of new traces recorded, 0-100
 }
 
 type ProfilingConfig struct {
@@ -57,6 +221,39 @@ type ProfilingConfig struct {
 	Port    int
 }
 
+// SchedulerConfig holds cron schedules for background maintenance jobs.
+// An empty schedule disables the corresponding job.
+type SchedulerConfig struct {
+	Enabled bool
+	// Workers per work queue priority class; zero keeps the queue's default
+	InteractiveWorkers            int
+	ScheduledWorkers              int
+	BackfillWorkers               int
+	PlanActualsSchedule           string
+	MonthlyInsightsSchedule       string
+	SubscriptionDetectionSchedule string
+	BudgetRolloverSchedule        string
+	FXRefreshSchedule             string
+	DataSourceHealthSchedule      string
+	AggregateRollupsSchedule      string
+	WeeklyDigestSchedule          string
+	PlanItemLinkSchedule          string
+	InstallmentMatchingSchedule   string
+	PurchaseRemindersSchedule     string
+	RewardsDetectionSchedule      string
+	PushReceiptsSchedule          string
+	DeferredNotificationsSchedule string
+	WebhookDeliveriesSchedule     string
+	BudgetAlertsSchedule          string
+	SheetSyncSchedule             string
+	DatabaseMaintenanceSchedule   string
+	AccountClosureSchedule        string
+	DataExportSchedule            string
+	AlertCleanupSchedule          string
+	FileScanSchedule              string
+	TrashPurgeSchedule            string
+}
+
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -66,6 +263,7 @@ func Load() (*Config, error) {
 			BaseURL:            getEnv("BASE_URL", "http://localhost:8080"),
 			RateLimitPerSecond: getEnvAsInt("SERVER_RATE_LIMIT_PER_SECOND", 100),
 			RateLimitBurst:     getEnvAsInt("SERVER_RATE_LIMIT_BURST", 200),
+			TrustedProxies:     getEnvAsList("SERVER_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("POSTGRES_HOST", "localhost"),
@@ -74,14 +272,28 @@ func Load() (*Config, error) {
 			Password: getEnv("POSTGRES_PASSWORD", "postgres"),
 			Database: getEnv("POSTGRES_DB", "echo-dev"),
 			SSLMode:  getEnv("POSTGRES_SSLMODE", "disable"),
+
+			MaxConns:                getEnvAsInt("POSTGRES_MAX_CONNS", 25),
+			MinConns:                getEnvAsInt("POSTGRES_MIN_CONNS", 5),
+			MaxConnLifetimeSeconds:  getEnvAsInt("POSTGRES_MAX_CONN_LIFETIME_SECONDS", 300),
+			MaxConnIdleSeconds:      getEnvAsInt("POSTGRES_MAX_CONN_IDLE_SECONDS", 600),
+			HealthCheckSeconds:      getEnvAsInt("POSTGRES_HEALTH_CHECK_SECONDS", 60),
+			StatementTimeoutSeconds: getEnvAsInt("POSTGRES_STATEMENT_TIMEOUT_SECONDS", 0),
+			AnalyticsTimeoutSeconds: getEnvAsInt("POSTGRES_ANALYTICS_TIMEOUT_SECONDS", 10),
+			ReplicaURL:              getEnv("POSTGRES_REPLICA_URL", ""),
 		},
 		Auth: AuthConfig{
-			JWTSecret:  getEnv("JWT_SECRET", "changeme"),
-			AdminEmail: getEnv("ADMIN_EMAIL", ""),
+			JWTSecret:                getEnv("JWT_SECRET", "changeme"),
+			AdminEmail:               getEnv("ADMIN_EMAIL", ""),
+			AccountDeletionGraceDays: getEnvAsInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
 		},
 		Observability: ObservabilityConfig{
-			MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
-			MetricsPort:    getEnvAsInt("METRICS_PORT", 9090),
+			MetricsEnabled:     getEnvAsBool("METRICS_ENABLED", true),
+			MetricsPort:        getEnvAsInt("METRICS_PORT", 9090),
+			TracingEnabled:     getEnvAsBool("TRACING_ENABLED", false),
+			OTLPEndpoint:       getEnv("OTLP_ENDPOINT", ""),
+			TracingInsecure:    getEnvAsBool("OTLP_INSECURE", false),
+			TraceSamplePercent: getEnvAsInt("TRACE_SAMPLE_PERCENT", 10),
 		},
 		Profiling: ProfilingConfig{
 			Enabled: getEnvAsBool("PPROF_ENABLED", false),
@@ -91,6 +303,111 @@ func Load() (*Config, error) {
 			APIKey: getEnv("GEMINI_API_KEY", ""),
 			Model:  getEnv("GEMINI_MODEL", ""),
 		},
+		Google: GoogleConfig{
+			ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+			ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		},
+		OAuth: OAuthConfig{
+			AppleClientID:   getEnv("APPLE_CLIENT_ID", ""),
+			AppleTeamID:     getEnv("APPLE_TEAM_ID", ""),
+			AppleKeyID:      getEnv("APPLE_KEY_ID", ""),
+			ApplePrivateKey: getEnv("APPLE_PRIVATE_KEY", ""),
+			RedirectURLs:    getEnvAsList("OAUTH_REDIRECT_URLS"),
+		},
+		WebPush: WebPushConfig{
+			VAPIDPrivateKey: getEnv("WEB_PUSH_VAPID_PRIVATE_KEY", ""),
+			Subject:         getEnv("WEB_PUSH_SUBJECT", ""),
+		},
+		Telegram: TelegramConfig{
+			BotToken:      getEnv("TELEGRAM_BOT_TOKEN", ""),
+			WebhookSecret: getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+			BotUsername:   getEnv("TELEGRAM_BOT_USERNAME", ""),
+		},
+		Stripe: StripeConfig{
+			SecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
+			WebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			PriceMonthly:  getEnv("STRIPE_PRICE_PREMIUM_MONTHLY", ""),
+			PriceAnnual:   getEnv("STRIPE_PRICE_PREMIUM_ANNUAL", ""),
+		},
+		Plaid: PlaidConfig{
+			BaseURL: getEnv("PLAID_BASE_URL", ""),
+		},
+		Chaos: ChaosConfig{
+			Enabled: getEnvAsBool("CHAOS_ENABLED", false),
+			Faults:  getEnv("CHAOS_FAULTS", ""),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:                       getEnvAsBool("SCHEDULER_ENABLED", true),
+			InteractiveWorkers:            getEnvAsInt("SCHEDULER_INTERACTIVE_WORKERS", 8),
+			ScheduledWorkers:              getEnvAsInt("SCHEDULER_SCHEDULED_WORKERS", 4),
+			BackfillWorkers:               getEnvAsInt("SCHEDULER_BACKFILL_WORKERS", 1),
+			PlanActualsSchedule:           getEnvSchedule("SCHEDULER_PLAN_ACTUALS", "0 2 * * *"),
+			MonthlyInsightsSchedule:       getEnvSchedule("SCHEDULER_MONTHLY_INSIGHTS", "0 3 * * *"),
+			SubscriptionDetectionSchedule: getEnvSchedule("SCHEDULER_SUBSCRIPTION_DETECTION", "0 4 * * *"),
+			BudgetRolloverSchedule:        getEnvSchedule("SCHEDULER_BUDGET_ROLLOVER", "5 0 1 * *"),
+			FXRefreshSchedule:             getEnvSchedule("SCHEDULER_FX_REFRESH", "0 */6 * * *"),
+			DataSourceHealthSchedule:      getEnvSchedule("SCHEDULER_DATA_SOURCE_HEALTH", "*/30 * * * *"),
+			AggregateRollupsSchedule:      getEnvSchedule("SCHEDULER_AGGREGATE_ROLLUPS", "*/30 * * * *"),
+			WeeklyDigestSchedule:          getEnvSchedule("SCHEDULER_WEEKLY_DIGEST", "0 8 * * *"),
+			PlanItemLinkSchedule:          getEnvSchedule("SCHEDULER_PLAN_ITEM_LINKS", "30 1 * * *"),
+			InstallmentMatchingSchedule:   getEnvSchedule("SCHEDULER_INSTALLMENT_MATCHING", "30 4 * * *"),
+			PurchaseRemindersSchedule:     getEnvSchedule("SCHEDULER_PURCHASE_REMINDERS", "0 9 * * *"),
+			RewardsDetectionSchedule:      getEnvSchedule("SCHEDULER_REWARDS_DETECTION", "15 4 * * *"),
+			PushReceiptsSchedule:          getEnvSchedule("SCHEDULER_PUSH_RECEIPTS", "*/15 * * * *"),
+			DeferredNotificationsSchedule: getEnvSchedule("SCHEDULER_DEFERRED_NOTIFICATIONS", "*/5 * * * *"),
+			WebhookDeliveriesSchedule:     getEnvSchedule("SCHEDULER_WEBHOOK_DELIVERIES", "* * * * *"),
+			BudgetAlertsSchedule:          getEnvSchedule("SCHEDULER_BUDGET_ALERTS", "30 2 * * *"),
+			SheetSyncSchedule:             getEnvSchedule("SCHEDULER_SHEET_SYNC", "*/30 * * * *"),
+			DatabaseMaintenanceSchedule:   getEnvSchedule("SCHEDULER_DB_MAINTENANCE", "30 5 * * *"),
+			AccountClosureSchedule:        getEnvSchedule("SCHEDULER_ACCOUNT_CLOSURE", "0 5 * * *"),
+			DataExportSchedule:            getEnvSchedule("SCHEDULER_DATA_EXPORT", "*/5 * * * *"),
+			AlertCleanupSchedule:          getEnvSchedule("SCHEDULER_ALERT_CLEANUP", "15 3 * * *"),
+			FileScanSchedule:              getEnvSchedule("SCHEDULER_FILE_SCAN", "*/10 * * * *"),
+			TrashPurgeSchedule:            getEnvSchedule("SCHEDULER_TRASH_PURGE", "45 3 * * *"),
+		},
+		RateLimit: RateLimitConfig{
+			RedisURL:          getEnv("RATE_LIMIT_REDIS_URL", ""),
+			UserPerSecond:     getEnvAsInt("RATE_LIMIT_USER_PER_SECOND", 20),
+			UserBurst:         getEnvAsInt("RATE_LIMIT_USER_BURST", 60),
+			IPPerSecond:       getEnvAsInt("RATE_LIMIT_IP_PER_SECOND", 30),
+			IPBurst:           getEnvAsInt("RATE_LIMIT_IP_BURST", 90),
+			AuthPerMinute:     getEnvAsInt("RATE_LIMIT_AUTH_PER_MINUTE", 10),
+			ImportMBPerMinute: getEnvAsInt("RATE_LIMIT_IMPORT_MB_PER_MINUTE", 20),
+		},
+		Cache: CacheConfig{
+			RedisURL:   getEnv("CACHE_REDIS_URL", ""),
+			TTLSeconds: getEnvAsInt("CACHE_TTL_SECONDS", 300),
+		},
+		Validation: ValidationConfig{
+			MaxCSVMB:    getEnvAsInt("VALIDATION_MAX_CSV_MB", 20),
+			MaxCSVRows:  getEnvAsInt("VALIDATION_MAX_CSV_ROWS", 100000),
+			MaxPageSize: getEnvAsInt("VALIDATION_MAX_PAGE_SIZE", 200),
+		},
+		Storage: StorageConfig{
+			FreeQuotaMB:    getEnvAsInt("STORAGE_QUOTA_FREE_MB", 250),
+			PremiumQuotaMB: getEnvAsInt("STORAGE_QUOTA_PREMIUM_MB", 10240),
+			ClamAVAddress:  getEnv("CLAMAV_ADDRESS", ""),
+			EncryptionKeys: getEnv("STORAGE_ENCRYPTION_KEYS", ""),
+		},
+		Quota: QuotaConfig{
+			FreeMaxTransactions:    getEnvAsInt("QUOTA_FREE_MAX_TRANSACTIONS", 50000),
+			FreeMaxPlans:           getEnvAsInt("QUOTA_FREE_MAX_PLANS", 10),
+			PremiumMaxTransactions: getEnvAsInt("QUOTA_PREMIUM_MAX_TRANSACTIONS", 0),
+			PremiumMaxPlans:        getEnvAsInt("QUOTA_PREMIUM_MAX_PLANS", 0),
+		},
+		Speech: SpeechConfig{
+			WhisperBinary: getEnv("SPEECH_WHISPER_BINARY", "whisper-cli"),
+			WhisperModel:  getEnv("SPEECH_WHISPER_MODEL", ""),
+			APIKey:        getEnv("SPEECH_API_KEY", ""),
+			APIURL:        getEnv("SPEECH_API_URL", ""),
+			Model:         getEnv("SPEECH_MODEL", ""),
+		},
+		FX: FXConfig{
+			Provider:    getEnv("FX_PROVIDER", ""),
+			AccessKey:   getEnv("FX_ACCESS_KEY", ""),
+			Source:      getEnv("FX_SOURCE", "EUR"),
+			ManualRates: getEnv("FX_MANUAL_RATES", ""),
+		},
 	}
 
 	if cfg.Gemini.APIKey == "" {
@@ -119,6 +436,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvAsList splits a comma-separated variable, dropping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	valueStr := os.Getenv(key)
 	if value, err := strconv.Atoi(valueStr); err == nil {
@@ -134,3 +462,12 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvSchedule reads a cron schedule; "off" disables the job.
+func getEnvSchedule(key, defaultValue string) string {
+	value := getEnv(key, defaultValue)
+	if value == "off" {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,577 @@
+package cron
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/admin"
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	"github.com/FACorreiaa/smart-finance-tracker/internal/domain/insights"
+	installmentsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/installments/service"
+	notificationsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/notifications/service"
+	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	purchasesrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/repository"
+	purchasesservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/purchases/service"
+	rewardsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/rewards/service"
+	subscriptionsservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/service"
+	trashservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/trash/service"
+	webhooksservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/webhooks/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
+)
+
+// subscriptionLookback is how far back subscription detection scans.
+const subscriptionLookback = 180 * 24 * time.Hour
+
+// MonthlyInsightsJob precomputes monthly insights. It targets the month of
+// yesterday, so the run on the 1st finalizes the month that just ended.
+func MonthlyInsightsJob(svc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "monthly_insights_precompute",
+		Schedule: schedule,
+		Class:    workqueue.Backfill,
+		Run: func(ctx context.Context) error {
+			month := time.Now().AddDate(0, 0, -1)
+			processed, err := svc.PrecomputeMonthlyInsights(ctx, month)
+			if err != nil {
+				return err
+			}
+			logger.Info("monthly insights precomputed",
+				slog.String("month", month.Format("2006-01")),
+				slog.Int("users", processed),
+			)
+			return nil
+		},
+	}
+}
+
+// SubscriptionDetectionJob runs recurring-charge detection for all users with recent expenses.
+func SubscriptionDetectionJob(svc *subscriptionsservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "subscription_detection",
+		Schedule: schedule,
+		Class:    workqueue.Backfill,
+		Run: func(ctx context.Context) error {
+			users, detected, err := svc.DetectSubscriptionsForAllUsers(ctx, time.Now().Add(-subscriptionLookback), 3)
+			if err != nil {
+				return err
+			}
+			logger.Info("subscription detection completed",
+				slog.Int("users", users),
+				slog.Int("new_subscriptions", detected),
+			)
+			return nil
+		},
+	}
+}
+
+// streakAlertMinLength is how long a budget streak must have lasted for its
+// end to be worth an alert
+const streakAlertMinLength = 2
+
+// BudgetRolloverJob opens the current month's budget period for every active plan,
+// carrying over unspent amounts of rollover-enabled items. With the previous
+// period closed, it alerts users whose budget streaks it ended.
+func BudgetRolloverJob(planRepo planrepo.PlanRepository, periods *planservice.BudgetPeriodService, insightsSvc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "budget_period_rollover",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			now := time.Now()
+			created, failed, streakAlerts := 0, 0, 0
+
+			err := ForEachActivePlan(ctx, planRepo, func(plan *planrepo.UserPlan) {
+				_, wasCreated, err := periods.RollOverPeriod(ctx, plan.ID, now.Year(), int(now.Month()))
+				if err != nil {
+					logger.Warn("failed to roll over budget period",
+						slog.String("plan_id", plan.ID.String()),
+						slog.Any("error", err),
+					)
+					failed++
+					return
+				}
+				if wasCreated {
+					created++
+				}
+
+				alerted, err := alertBrokenStreaks(ctx, periods, insightsSvc, plan, now)
+				if err != nil {
+					logger.Warn("failed to check budget streaks",
+						slog.String("plan_id", plan.ID.String()),
+						slog.Any("error", err),
+					)
+					return
+				}
+				if alerted {
+					streakAlerts++
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			logger.Info("budget period rollover completed",
+				slog.Int("periods_created", created),
+				slog.Int("plans_failed", failed),
+				slog.Int("streak_alerts", streakAlerts),
+			)
+			return nil
+		},
+	}
+}
+
+// alertBrokenStreaks alerts the plan's owner about streaks the last closed period ended
+func alertBrokenStreaks(ctx context.Context, periods *planservice.BudgetPeriodService, insightsSvc *insights.Service, plan *planrepo.UserPlan, now time.Time) (bool, error) {
+	streaks, err := periods.ComputeAdherenceStreaks(ctx, plan.ID, now)
+	if err != nil {
+		return false, err
+	}
+
+	var breaks []insights.StreakBreak
+	for _, s := range streaks.JustBroken(streakAlertMinLength) {
+		b := s.LastBreak
+		breaks = append(breaks, insights.StreakBreak{
+			CategoryName:   s.CategoryName,
+			PreviousStreak: b.PreviousStreak,
+			PeriodStart:    time.Date(b.Year, time.Month(b.Month), 1, 0, 0, 0, 0, now.Location()),
+			ItemID:         b.ItemID,
+			ItemName:       b.ItemName,
+			LimitMinor:     b.LimitMinor,
+			ActualMinor:    b.ActualMinor,
+		})
+	}
+	if len(breaks) == 0 {
+		return false, nil
+	}
+	return true, insightsSvc.TriggerStreakBrokenAlert(ctx, plan.UserID, plan.ID, breaks)
+}
+
+// BudgetAlertsJob alerts users whose active plan has over-budget lines. Flex
+// groups are evaluated as a whole, so an item overspend inside one only alerts
+// once the group total is exceeded.
+func BudgetAlertsJob(planRepo planrepo.PlanRepository, planSvc *planservice.PlanService, insightsSvc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "budget_alerts",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			now := time.Now()
+			alerted, failed := 0, 0
+
+			err := ForEachActivePlan(ctx, planRepo, func(plan *planrepo.UserPlan) {
+				summary, err := planSvc.GetPlanSummary(ctx, plan.UserID, plan.ID, now)
+				if err != nil || summary == nil {
+					if err != nil {
+						logger.Warn("failed to summarize plan",
+							slog.String("plan_id", plan.ID.String()),
+							slog.Any("error", err),
+						)
+						failed++
+					}
+					return
+				}
+
+				var lines []insights.BudgetOverspend
+				for _, l := range summary.OverBudgetLines() {
+					lines = append(lines, insights.BudgetOverspend{
+						Name:          l.Name,
+						BudgetedMinor: l.BudgetedMinor,
+						ActualMinor:   l.ActualMinor,
+						IsGroup:       l.GroupID != nil,
+					})
+				}
+				if len(lines) == 0 {
+					return
+				}
+				if err := insightsSvc.TriggerBudgetAlert(ctx, plan.UserID, plan.ID, lines); err != nil {
+					logger.Warn("failed to trigger budget alert",
+						slog.String("plan_id", plan.ID.String()),
+						slog.Any("error", err),
+					)
+					failed++
+					return
+				}
+				alerted++
+			})
+			if err != nil {
+				return err
+			}
+
+			logger.Info("budget alerts evaluated",
+				slog.Int("plans_alerted", alerted),
+				slog.Int("plans_failed", failed),
+			)
+			return nil
+		},
+	}
+}
+
+// PlanItemLinkSyncJob reconciles subscription-linked plan item budgets with their subscriptions.
+func PlanItemLinkSyncJob(svc *planservice.PlanService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "plan_item_link_sync",
+		Schedule: schedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			corrected, err := svc.SyncLinkedItems(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("plan item links reconciled", slog.Int("items_corrected", corrected))
+			return nil
+		},
+	}
+}
+
+// InstallmentMatchingJob matches arrived charges to installment schedules and
+// completes plans whose final charge has been paid.
+func InstallmentMatchingJob(svc *installmentsservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "installment_matching",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			result, err := svc.MatchChargesForAllUsers(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("installment charges matched",
+				slog.Int("matched", result.Matched),
+				slog.Int("plans_completed", result.Completed),
+			)
+			return nil
+		},
+	}
+}
+
+// PurchaseRemindersJob alerts users whose tracked purchases' return windows or
+// warranties are about to lapse. Each deadline is reminded about once.
+func PurchaseRemindersJob(svc *purchasesservice.Service, insightsSvc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "purchase_reminders",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			now := time.Now()
+			reminders, err := svc.DueReminders(ctx, now)
+			if err != nil {
+				return err
+			}
+
+			sent, failed := 0, 0
+			for _, r := range reminders {
+				// Claim the reminder first so concurrent runs don't alert twice
+				claimed, err := svc.MarkReminded(ctx, r, now)
+				if err != nil || !claimed {
+					if err != nil {
+						logger.Warn("failed to mark purchase reminded",
+							slog.String("purchase_id", r.Purchase.ID.String()),
+							slog.Any("error", err),
+						)
+						failed++
+					}
+					continue
+				}
+
+				if err := insightsSvc.TriggerPurchaseReminder(ctx, r.Purchase.UserID, insights.PurchaseReminder{
+					PurchaseID:    r.Purchase.ID,
+					MerchantName:  r.Purchase.MerchantName,
+					AmountMinor:   r.Purchase.AmountMinor,
+					PurchasedAt:   r.Purchase.PurchasedAt,
+					Deadline:      r.Deadline,
+					DaysLeft:      r.DaysLeft,
+					Warranty:      r.Kind == purchasesrepo.ReminderWarranty,
+					HasAttachment: r.Purchase.AttachmentKey != nil,
+				}); err != nil {
+					logger.Warn("failed to trigger purchase reminder",
+						slog.String("purchase_id", r.Purchase.ID.String()),
+						slog.Any("error", err),
+					)
+					failed++
+					continue
+				}
+				sent++
+			}
+
+			logger.Info("purchase reminders sent",
+				slog.Int("sent", sent),
+				slog.Int("failed", failed),
+			)
+			return nil
+		},
+	}
+}
+
+// SheetSyncJob pulls edits from linked Google Sheets and pushes plans back.
+func SheetSyncJob(svc *planservice.SheetSyncService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "plan_sheet_sync",
+		Schedule: schedule,
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			result, err := svc.SyncAll(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("plan sheets synced",
+				slog.Int("links", result.Links),
+				slog.Int("items_pulled", result.Pulled),
+				slog.Int("conflicts", result.Conflicts),
+				slog.Int("failed", result.Failed),
+			)
+			return nil
+		},
+	}
+}
+
+// DatabaseMaintenanceJob runs the managed maintenance tasks off-peak. Each task's
+// result is recorded for the admin service; failures don't stop later tasks.
+func DatabaseMaintenanceJob(svc *admin.MaintenanceService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "database_maintenance",
+		Schedule: schedule,
+		Timeout:  time.Hour,
+		Class:    workqueue.Backfill,
+		Run: func(ctx context.Context) error {
+			runs, err := svc.RunAll(ctx)
+			for _, run := range runs {
+				logger.Info("maintenance task finished",
+					slog.String("task", string(run.Task)),
+					slog.String("status", string(run.Status)),
+					slog.Int64("rows_affected", run.RowsAffected),
+				)
+			}
+			return err
+		},
+	}
+}
+
+// AccountClosureJob anonymizes closed accounts once their grace period ends and
+// verifies no personal data was left behind.
+func AccountClosureJob(svc *admin.AccountClosureService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "account_closure_anonymization",
+		Schedule: schedule,
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			anonymized, err := svc.AnonymizeDue(ctx)
+			if anonymized > 0 {
+				logger.Info("closed accounts anonymized", slog.Int("accounts", anonymized))
+			}
+			return err
+		},
+	}
+}
+
+// DataExportJob builds requested user data exports.
+func DataExportJob(svc *admin.DataExportService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "data_export",
+		Schedule: schedule,
+		Timeout:  15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			built, err := svc.ProcessPending(ctx)
+			if built > 0 {
+				logger.Info("data exports built", slog.Int("exports", built))
+			}
+			return err
+		},
+	}
+}
+
+// RewardsDetectionJob flags recent cash-back and reward credits so they're
+// tracked apart from income.
+func RewardsDetectionJob(svc *rewardsservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "rewards_detection",
+		Schedule: schedule,
+		Class:    workqueue.Backfill,
+		Run: func(ctx context.Context) error {
+			result, err := svc.DetectForAllUsers(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("rewards detected",
+				slog.Int("detected", result.Detected),
+				slog.Int("attributed", result.Attributed),
+			)
+			return nil
+		},
+	}
+}
+
+// PushReceiptsJob records push delivery receipts for sent notifications.
+func PushReceiptsJob(svc *notificationsservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "notification_push_receipts",
+		Schedule: schedule,
+		Timeout:  2 * time.Minute,
+		Run: func(ctx context.Context) error {
+			result, err := svc.CheckPushReceipts(ctx)
+			if err != nil {
+				return err
+			}
+			if result.Delivered+result.Failed > 0 {
+				logger.Info("push receipts recorded",
+					slog.Int("delivered", result.Delivered),
+					slog.Int("failed", result.Failed),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// DeferredNotificationsJob sends push notifications held back by quiet hours
+// once the hours end.
+func DeferredNotificationsJob(svc *notificationsservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "notification_deferred_delivery",
+		Schedule: schedule,
+		Timeout:  2 * time.Minute,
+		Run: func(ctx context.Context) error {
+			processed, err := svc.DeliverDeferred(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if processed > 0 {
+				logger.Info("deferred notifications delivered", slog.Int("notifications", processed))
+			}
+			return nil
+		},
+	}
+}
+
+// WebhookDeliveriesJob retries webhook deliveries whose backoff has elapsed.
+func WebhookDeliveriesJob(svc *webhooksservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "webhook_delivery_retry",
+		Schedule: schedule,
+		Timeout:  5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			attempted, err := svc.DeliverDue(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if attempted > 0 {
+				logger.Info("webhook deliveries retried", slog.Int("deliveries", attempted))
+			}
+			return nil
+		},
+	}
+}
+
+// FileScanJob rescans uploaded files left unscanned, usually because the
+// antivirus was unavailable when they were uploaded.
+func FileScanJob(svc *importservice.ImportService, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "file_scan_retry",
+		Schedule: schedule,
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			summary, err := svc.ScanPendingFiles(ctx)
+			if err != nil {
+				return err
+			}
+			if summary.Clean+summary.Quarantined+summary.Pending > 0 {
+				logger.Info("pending files scanned",
+					slog.Int("clean", summary.Clean),
+					slog.Int("quarantined", summary.Quarantined),
+					slog.Int("pending", summary.Pending),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// TrashPurgeJob permanently deletes items that have been in the trash past
+// the restore window.
+func TrashPurgeJob(svc *trashservice.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "trash_purge",
+		Schedule: schedule,
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			result, err := svc.Purge(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			if result.Total() > 0 {
+				logger.Info("trash purged",
+					slog.Int64("transactions", result.Transactions),
+					slog.Int64("plans", result.Plans),
+					slog.Int64("goals", result.Goals),
+					slog.Int64("rules", result.Rules),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// DataSourceHealthJob refreshes the data_source_health materialized view.
+func DataSourceHealthJob(svc *insights.Service, schedule string) Job {
+	return Job{
+		Name:     "data_source_health_refresh",
+		Schedule: schedule,
+		Timeout:  5 * time.Minute,
+		Run:      svc.RefreshDataSourceHealth,
+	}
+}
+
+// AlertCleanupJob dismisses stale alerts and deletes old read or dismissed ones.
+func AlertCleanupJob(svc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "alert_cleanup",
+		Schedule: schedule,
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			result, err := svc.CleanupAlerts(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			logger.Info("alerts cleaned up",
+				slog.Int64("expired", result.Expired),
+				slog.Int64("deleted", result.Deleted),
+			)
+			return nil
+		},
+	}
+}
+
+// WeeklyDigestJob sends the weekly digest to opted-in users whose delivery day
+// is today. It runs daily; users already sent this week's digest are skipped.
+func WeeklyDigestJob(svc *insights.Service, schedule string, logger *slog.Logger) Job {
+	return Job{
+		Name:     "weekly_digest",
+		Schedule: schedule,
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			sent, err := svc.SendWeeklyDigests(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			logger.Info("weekly digests sent", slog.Int("sent", sent))
+			return nil
+		},
+	}
+}
+
+// AggregateRollupsJob refreshes the transaction_monthly_rollups materialized view.
+func AggregateRollupsJob(svc *insights.Service, schedule string) Job {
+	return Job{
+		Name:     "aggregate_rollups_refresh",
+		Schedule: schedule,
+		Timeout:  10 * time.Minute,
+		Run:      svc.RefreshAggregateRollups,
+	}
+}
+
+// FXRefreshJob refreshes cached exchange rates using the given refresh function.
+func FXRefreshJob(refresh func(ctx context.Context) error, schedule string) Job {
+	return Job{
+		Name:     "fx_rate_refresh",
+		Schedule: schedule,
+		Timeout:  2 * time.Minute,
+		Run:      refresh,
+	}
+}
@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKeyPrefix namespaces job advisory locks from any other advisory lock users.
+const lockKeyPrefix = "echo:cron:"
+
+// Locker guarantees a job runs on a single replica at a time.
+type Locker interface {
+	// TryLock attempts to take the lock for name without blocking.
+	// When acquired is true the caller must invoke unlock once done.
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// PostgresLocker implements Locker using session-level PostgreSQL advisory locks.
+// The lock is bound to a dedicated pooled connection, so it is released
+// automatically if the replica dies mid-run.
+type PostgresLocker struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLocker creates a new advisory-lock based locker.
+func NewPostgresLocker(pool *pgxpool.Pool) *PostgresLocker {
+	return &PostgresLocker{pool: pool}
+}
+
+// TryLock implements Locker.
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection for job lock: %w", err)
+	}
+
+	key := lockKeyPrefix + name
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("failed to take job lock: %w", err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// Use a fresh context: the job context may already be cancelled
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.Exec(unlockCtx, `SELECT pg_advisory_unlock(hashtext($1))`, key)
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+// noopLocker always grants the lock; used when no Locker is configured.
+type noopLocker struct{}
+
+func (noopLocker) TryLock(context.Context, string) (func(), bool, error) {
+	return func() {}, true, nil
+}
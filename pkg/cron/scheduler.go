@@ -3,21 +3,49 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 
 	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
 	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
 )
 
+// DefaultPlanActualsSchedule runs the plan actuals sync daily at 2:00 AM.
+const DefaultPlanActualsSchedule = "0 2 * * *"
+
+// defaultJobTimeout bounds a job run when the job doesn't set its own timeout.
+const defaultJobTimeout = 30 * time.Minute
+
+// activePlansPageSize is the page size used when iterating all active plans.
+const activePlansPageSize = 500
+
+// Job is a named unit of recurring work.
+type Job struct {
+	Name     string
+	Schedule string          // Standard 5-field cron expression
+	Timeout  time.Duration   // Defaults to 30 minutes
+	Class    workqueue.Class // Defaults to workqueue.Scheduled
+	Run      func(ctx context.Context) error
+}
+
 // Scheduler manages background scheduled jobs using robfig/cron.
 type Scheduler struct {
-	cron        *cron.Cron
-	planRepo    planrepo.PlanRepository
-	planService *planservice.PlanService
-	logger      *slog.Logger
+	cron                *cron.Cron
+	planRepo            planrepo.PlanRepository
+	planService         *planservice.PlanService
+	locker              Locker
+	queue               *workqueue.Queue
+	planActualsSchedule string
+	logger              *slog.Logger
+
+	mu   sync.Mutex
+	jobs map[string]Job
 }
 
 // NewScheduler creates a new job scheduler.
@@ -26,17 +54,69 @@ func NewScheduler(planRepo planrepo.PlanRepository, planService *planservice.Pla
 	c := cron.New(cron.WithLogger(cron.VerbosePrintfLogger(slog.NewLogLogger(logger.Handler(), slog.LevelDebug))))
 
 	return &Scheduler{
-		cron:        c,
-		planRepo:    planRepo,
-		planService: planService,
-		logger:      logger,
+		cron:                c,
+		planRepo:            planRepo,
+		planService:         planService,
+		locker:              noopLocker{},
+		planActualsSchedule: DefaultPlanActualsSchedule,
+		logger:              logger,
+		jobs:                make(map[string]Job),
+	}
+}
+
+// WithLocker sets the distributed lock used to prevent replicas double-running jobs.
+func (s *Scheduler) WithLocker(locker Locker) *Scheduler {
+	if locker != nil {
+		s.locker = locker
+	}
+	return s
+}
+
+// WithQueue runs jobs on the work queue in their class's worker pool, instead
+// of on cron's goroutines. A nil queue keeps running them directly.
+func (s *Scheduler) WithQueue(queue *workqueue.Queue) *Scheduler {
+	s.queue = queue
+	return s
+}
+
+// WithPlanActualsSchedule overrides the plan actuals sync schedule.
+// An empty schedule disables the job.
+func (s *Scheduler) WithPlanActualsSchedule(schedule string) *Scheduler {
+	s.planActualsSchedule = schedule
+	return s
+}
+
+// Register adds a job to the scheduler. Jobs with an empty schedule are skipped.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job requires a name and a run function")
+	}
+	if job.Schedule == "" {
+		s.logger.Info("cron job disabled", slog.String("job", job.Name))
+		return nil
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %q already registered", job.Name)
+	}
+
+	if _, err := s.cron.AddFunc(job.Schedule, func() { s.dispatch(job) }); err != nil {
+		return fmt.Errorf("invalid schedule for job %q: %w", job.Name, err)
+	}
+	s.jobs[job.Name] = job
+	return nil
 }
 
 // Start begins scheduled jobs.
 func (s *Scheduler) Start() error {
-	// Plan actuals sync: runs daily at 2:00 AM
-	_, err := s.cron.AddFunc("0 2 * * *", s.syncAllActivePlans)
+	err := s.Register(Job{
+		Name:     "plan_actuals_sync",
+		Schedule: s.planActualsSchedule,
+		Run:      s.syncAllActivePlans,
+	})
 	if err != nil {
 		return err
 	}
@@ -56,39 +136,125 @@ func (s *Scheduler) Stop() context.Context {
 
 // RunNow manually triggers the plan actuals sync (for testing/admin).
 func (s *Scheduler) RunNow() {
-	go s.syncAllActivePlans()
+	if err := s.submit(Job{Name: "plan_actuals_sync", Run: s.syncAllActivePlans}); err != nil {
+		s.logger.Warn("cron job not queued", slog.String("job", "plan_actuals_sync"), slog.Any("error", err))
+	}
+}
+
+// RunJob manually triggers a registered job by name in the background.
+func (s *Scheduler) RunJob(name string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("job %q not registered", name)
+	}
+
+	return s.submit(job)
+}
+
+// dispatch runs a job on the work queue, or inline without one
+func (s *Scheduler) dispatch(job Job) {
+	if s.queue == nil {
+		s.runJob(job)
+		return
+	}
+	if err := s.submit(job); err != nil {
+		s.logger.Warn("cron job not queued", slog.String("job", job.Name), slog.Any("error", err))
+	}
+}
+
+// submit queues a job in its class; a nil queue runs it in its own goroutine
+func (s *Scheduler) submit(job Job) error {
+	class := job.Class
+	if class == "" {
+		class = workqueue.Scheduled
+	}
+	return s.queue.Submit(workqueue.Task{
+		Name:  job.Name,
+		Class: class,
+		Run:   func(ctx context.Context) error { return s.runJobContext(ctx, job) },
+	})
 }
 
-// syncAllActivePlans syncs actuals for all active plans.
-func (s *Scheduler) syncAllActivePlans() {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+// runJob executes a job under its lock, skipping the run if another replica holds it.
+func (s *Scheduler) runJob(job Job) {
+	_ = s.runJobContext(context.Background(), job)
+}
+
+// runJobContext is runJob under ctx, returning the job's error for the work
+// queue's metrics after logging it.
+func (s *Scheduler) runJobContext(ctx context.Context, job Job) (err error) {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = defaultJobTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	s.logger.Info("starting daily plan actuals sync")
+	logger := s.logger.With(slog.String("job", job.Name))
 
-	// Get all active plans across all users
-	plans, err := s.planRepo.ListAllActivePlans(ctx, 1000, 0)
+	unlock, acquired, err := s.locker.TryLock(ctx, job.Name)
 	if err != nil {
-		s.logger.Error("failed to list active plans", slog.Any("error", err))
-		return
+		logger.Error("failed to acquire job lock", slog.Any("error", err))
+		return err
+	}
+	if !acquired {
+		logger.Debug("job already running on another replica, skipping")
+		return nil
 	}
+	defer unlock()
 
-	// Calculate period: current month
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("cron job panicked", slog.Any("panic", r))
+			err = fmt.Errorf("cron job panicked: %v", r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		logger.Error("cron job failed",
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err),
+		)
+		return err
+	}
 
-	synced := 0
-	failed := 0
+	logger.Info("cron job completed", slog.Duration("duration", time.Since(start)))
+	return nil
+}
 
-	for _, plan := range plans {
-		input := &planservice.ComputePlanActualsInput{
-			StartDate: startOfMonth,
-			EndDate:   endOfMonth,
-			Persist:   true,
+// ForEachActivePlan pages through every active plan across all users.
+func ForEachActivePlan(ctx context.Context, repo planrepo.PlanRepository, fn func(plan *planrepo.UserPlan)) error {
+	for offset := 0; ; offset += activePlansPageSize {
+		plans, err := repo.ListAllActivePlans(ctx, activePlansPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list active plans: %w", err)
+		}
+		for _, plan := range plans {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fn(plan)
+		}
+		if len(plans) < activePlansPageSize {
+			return nil
 		}
+	}
+}
+
+// syncAllActivePlans recomputes actuals for all active plans from the active
+// period's transactions, reporting plans whose stored actuals had drifted.
+func (s *Scheduler) syncAllActivePlans(ctx context.Context) error {
+	s.logger.Info("starting daily plan actuals sync")
+
+	now := time.Now()
+	synced, drifted, failed := 0, 0, 0
+	var totalDrift int64
 
-		result, err := s.planService.ComputePlanActuals(ctx, plan.UserID, plan.ID, input)
+	err := ForEachActivePlan(ctx, s.planRepo, func(plan *planrepo.UserPlan) {
+		result, err := s.planService.ReconcilePlanActuals(ctx, plan, now)
 		if err != nil {
 			s.logger.Warn("failed to sync plan actuals",
 				slog.String("plan_id", plan.ID.String()),
@@ -96,19 +262,34 @@ func (s *Scheduler) syncAllActivePlans() {
 				slog.Any("error", err),
 			)
 			failed++
-			continue
+			return
 		}
 
-		s.logger.Debug("synced plan actuals",
-			slog.String("plan_id", plan.ID.String()),
-			slog.Int("items_updated", result.ItemsUpdated),
-			slog.Int("transactions_matched", result.TransactionsMatched),
-		)
 		synced++
+		if len(result.Drift) == 0 {
+			return
+		}
+		drifted++
+		totalDrift += result.TotalDriftMinor()
+		for _, d := range result.Drift {
+			s.logger.Warn("plan item actual drifted",
+				slog.String("plan_id", plan.ID.String()),
+				slog.String("item_id", d.ItemID.String()),
+				slog.String("item_name", d.ItemName),
+				slog.Int64("stored_minor", d.StoredMinor),
+				slog.Int64("computed_minor", d.ComputedMinor),
+			)
+		}
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("daily plan actuals sync completed",
 		slog.Int("plans_synced", synced),
+		slog.Int("plans_drifted", drifted),
+		slog.Int64("total_drift_minor", totalDrift),
 		slog.Int("plans_failed", failed),
 	)
+	return nil
 }
@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/workqueue"
+)
+
+// fakeLocker grants or denies locks and records unlocks
+type fakeLocker struct {
+	grant    bool
+	err      error
+	mu       sync.Mutex
+	unlocked int
+}
+
+func (f *fakeLocker) TryLock(context.Context, string) (func(), bool, error) {
+	if f.err != nil || !f.grant {
+		return nil, false, f.err
+	}
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.unlocked++
+	}, true, nil
+}
+
+func newTestScheduler(locker Locker) *Scheduler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewScheduler(nil, nil, logger).WithLocker(locker)
+}
+
+func TestRunJob_SkipsWhenLockHeldElsewhere(t *testing.T) {
+	locker := &fakeLocker{grant: false}
+	s := newTestScheduler(locker)
+
+	ran := false
+	s.runJob(Job{Name: "test", Run: func(context.Context) error { ran = true; return nil }})
+
+	assert.False(t, ran)
+	assert.Equal(t, 0, locker.unlocked)
+}
+
+func TestRunJob_ReleasesLockOnFailureAndPanic(t *testing.T) {
+	locker := &fakeLocker{grant: true}
+	s := newTestScheduler(locker)
+
+	s.runJob(Job{Name: "fails", Run: func(context.Context) error { return errors.New("boom") }})
+	s.runJob(Job{Name: "panics", Run: func(context.Context) error { panic("boom") }})
+
+	assert.Equal(t, 2, locker.unlocked)
+}
+
+func TestRegister_ValidatesJobs(t *testing.T) {
+	s := newTestScheduler(nil)
+	noop := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Register(Job{Name: "disabled", Run: noop}))
+	assert.Empty(t, s.jobs)
+
+	require.NoError(t, s.Register(Job{Name: "nightly", Schedule: "0 1 * * *", Run: noop}))
+	assert.Error(t, s.Register(Job{Name: "nightly", Schedule: "0 1 * * *", Run: noop}))
+	assert.Error(t, s.Register(Job{Name: "bad", Schedule: "not a cron", Run: noop}))
+	assert.Error(t, s.Register(Job{Schedule: "0 1 * * *", Run: noop}))
+	assert.Error(t, s.RunJob("missing"))
+}
+
+func TestRunJob_QueuesInJobClass(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	queue := workqueue.New(map[workqueue.Class]workqueue.Pool{workqueue.Backfill: {Workers: 1}}, logger)
+	defer func() { _ = queue.Stop(context.Background()) }()
+	s := newTestScheduler(&fakeLocker{grant: true}).WithQueue(queue)
+
+	// Hold the only backfill worker
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	require.NoError(t, s.Register(Job{Name: "sweep", Schedule: "0 4 * * *", Class: workqueue.Backfill, Run: func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}}))
+	require.NoError(t, s.RunJob("sweep"))
+	<-started
+
+	digested := make(chan struct{})
+	require.NoError(t, s.Register(Job{Name: "digest", Schedule: "0 8 * * *", Run: func(context.Context) error {
+		close(digested)
+		return nil
+	}}))
+	require.NoError(t, s.RunJob("digest"))
+
+	select {
+	case <-digested:
+	case <-time.After(time.Second):
+		t.Fatal("scheduled job waited behind backfill")
+	}
+}
@@ -0,0 +1,102 @@
+-- +goose Up
+-- Migration: 0026_plan_item_links
+-- Description: Link plan items to recurring subscriptions and goals so their amounts stay in sync
+
+ALTER TABLE plan_items
+ADD COLUMN subscription_id UUID REFERENCES recurring_subscriptions (id) ON DELETE SET NULL,
+ADD COLUMN goal_id UUID REFERENCES goals (id) ON DELETE SET NULL,
+ADD CONSTRAINT plan_items_single_link_chk CHECK (
+    subscription_id IS NULL
+    OR goal_id IS NULL
+);
+
+CREATE INDEX idx_plan_items_subscription ON plan_items (subscription_id)
+WHERE
+    subscription_id IS NOT NULL;
+
+CREATE INDEX idx_plan_items_goal ON plan_items (goal_id)
+WHERE
+    goal_id IS NOT NULL;
+
+-- Normalizes a recurring charge to its monthly cost (inactive subscriptions cost nothing)
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION recurring_monthly_minor(amount BIGINT, cadence recurring_cadence, status recurring_status)
+RETURNS BIGINT AS $$
+BEGIN
+  IF status <> 'active' THEN
+    RETURN 0;
+  END IF;
+  RETURN CASE cadence
+    WHEN 'weekly' THEN ROUND(amount * 52 / 12.0)
+    WHEN 'quarterly' THEN ROUND(amount / 3.0)
+    WHEN 'annual' THEN ROUND(amount / 12.0)
+    ELSE amount
+  END;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+-- +goose StatementEnd
+
+-- Subscription price/cadence/status changes update the budget of linked items
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION sync_subscription_linked_items()
+RETURNS TRIGGER AS $$
+BEGIN
+  UPDATE plan_items
+  SET budgeted_minor = recurring_monthly_minor(NEW.amount_minor, NEW.cadence, NEW.status),
+      updated_at = NOW()
+  WHERE subscription_id = NEW.id;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trigger_sync_subscription_linked_items
+AFTER UPDATE OF amount_minor, cadence, status ON recurring_subscriptions
+FOR EACH ROW EXECUTE FUNCTION sync_subscription_linked_items();
+
+-- Contributions dated in the current month count towards linked items' actuals
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION sync_goal_linked_items()
+RETURNS TRIGGER AS $$
+DECLARE
+  c RECORD;
+  delta BIGINT;
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    c := OLD;
+    delta := -OLD.amount_minor;
+  ELSE
+    c := NEW;
+    delta := NEW.amount_minor;
+  END IF;
+
+  IF date_trunc('month', c.contributed_at) = date_trunc('month', NOW()) THEN
+    UPDATE plan_items
+    SET actual_minor = GREATEST(actual_minor + delta, 0),
+        updated_at = NOW()
+    WHERE goal_id = c.goal_id;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trigger_sync_goal_linked_items
+AFTER INSERT OR DELETE ON goal_contributions
+FOR EACH ROW EXECUTE FUNCTION sync_goal_linked_items();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_sync_goal_linked_items ON goal_contributions;
+
+DROP FUNCTION IF EXISTS sync_goal_linked_items ();
+
+DROP TRIGGER IF EXISTS trigger_sync_subscription_linked_items ON recurring_subscriptions;
+
+DROP FUNCTION IF EXISTS sync_subscription_linked_items ();
+
+DROP FUNCTION IF EXISTS recurring_monthly_minor (BIGINT, recurring_cadence, recurring_status);
+
+ALTER TABLE plan_items
+DROP CONSTRAINT IF EXISTS plan_items_single_link_chk,
+DROP COLUMN IF EXISTS goal_id,
+DROP COLUMN IF EXISTS subscription_id;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Migration: 0027_budget_rollover
+-- Description: Opt-in envelope rollover of unspent (or overspent) budget into the next period
+
+ALTER TABLE plan_items
+ADD COLUMN rollover_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- Amount carried in from the previous period; available = budgeted + rollover - actual
+ALTER TABLE budget_period_items
+ADD COLUMN rollover_minor BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE budget_period_items
+DROP COLUMN IF EXISTS rollover_minor;
+
+ALTER TABLE plan_items
+DROP COLUMN IF EXISTS rollover_enabled;
@@ -0,0 +1,62 @@
+-- +goose Up
+-- Migration: 0028_installment_plans
+-- Description: Deferred/installment purchases (e.g. pay in 3) spread across future periods
+
+CREATE TABLE installment_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- Original purchase; excluded from spending in favour of its scheduled charges
+    transaction_id UUID REFERENCES transactions (id) ON DELETE SET NULL,
+    category_id UUID REFERENCES categories (id) ON DELETE SET NULL,
+    merchant_name TEXT NOT NULL,
+    total_minor BIGINT NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    installment_count INT NOT NULL,
+    cadence recurring_cadence NOT NULL DEFAULT 'monthly',
+    first_due_at DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT installment_plans_currency_code_chk CHECK (currency_code ~ '^[A-Z]{3}$'),
+    CONSTRAINT installment_plans_total_chk CHECK (total_minor > 0),
+    CONSTRAINT installment_plans_count_chk CHECK (installment_count BETWEEN 2 AND 120),
+    CONSTRAINT installment_plans_status_chk CHECK (status IN ('active', 'completed', 'canceled'))
+);
+
+CREATE INDEX idx_installment_plans_user_status ON installment_plans (user_id, status);
+
+CREATE UNIQUE INDEX uniq_installment_plans_transaction ON installment_plans (transaction_id)
+WHERE
+    transaction_id IS NOT NULL
+    AND status <> 'canceled';
+
+CREATE TRIGGER trigger_set_installment_plans_updated_at
+BEFORE UPDATE ON installment_plans
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- One row per scheduled charge; transaction_id is set once the charge arrives
+CREATE TABLE installment_charges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    plan_id UUID NOT NULL REFERENCES installment_plans (id) ON DELETE CASCADE,
+    sequence INT NOT NULL,
+    due_at DATE NOT NULL,
+    amount_minor BIGINT NOT NULL,
+    transaction_id UUID REFERENCES transactions (id) ON DELETE SET NULL,
+    paid_at TIMESTAMPTZ,
+    CONSTRAINT installment_charges_plan_sequence_uniq UNIQUE (plan_id, sequence),
+    CONSTRAINT installment_charges_amount_chk CHECK (amount_minor > 0)
+);
+
+CREATE INDEX idx_installment_charges_due ON installment_charges (due_at)
+WHERE
+    transaction_id IS NULL;
+
+CREATE UNIQUE INDEX uniq_installment_charges_transaction ON installment_charges (transaction_id)
+WHERE
+    transaction_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS installment_charges;
+
+DROP TABLE IF EXISTS installment_plans;
@@ -0,0 +1,67 @@
+-- +goose Up
+-- Migration: 0029_notifications
+-- Description: Notification inbox unifying alerts, digests and system messages with per-channel delivery state
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL, -- 'alert', 'digest', 'system'
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    metadata JSONB,
+    -- Originating record (e.g. 'alert' + alerts.id)
+    source_type TEXT,
+    source_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT notifications_kind_chk CHECK (kind IN ('alert', 'digest', 'system'))
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications (user_id, created_at DESC);
+
+CREATE INDEX idx_notifications_source ON notifications (source_type, source_id)
+WHERE
+    source_id IS NOT NULL;
+
+-- One row per channel a notification was sent through
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    notification_id UUID NOT NULL REFERENCES notifications (id) ON DELETE CASCADE,
+    channel TEXT NOT NULL, -- 'in_app', 'email', 'push'
+    -- pending -> sent -> delivered -> opened/read, or failed
+    status TEXT NOT NULL DEFAULT 'pending',
+    provider_message_id TEXT, -- e.g. Expo push ticket ID
+    error TEXT,
+    sent_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    opened_at TIMESTAMPTZ, -- in-app read or email opened
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_deliveries_channel_uniq UNIQUE (notification_id, channel),
+    CONSTRAINT notification_deliveries_channel_chk CHECK (channel IN ('in_app', 'email', 'push')),
+    CONSTRAINT notification_deliveries_status_chk CHECK (status IN ('pending', 'sent', 'delivered', 'opened', 'failed'))
+);
+
+-- Push deliveries awaiting a provider receipt
+CREATE INDEX idx_notification_deliveries_awaiting_receipt ON notification_deliveries (sent_at)
+WHERE
+    channel = 'push'
+    AND status = 'sent';
+
+CREATE TRIGGER trigger_set_notification_deliveries_updated_at
+BEFORE UPDATE ON notification_deliveries
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Backfill existing alerts into the inbox
+INSERT INTO notifications (user_id, kind, title, body, metadata, source_type, source_id, created_at)
+SELECT user_id, 'alert', title, message, metadata, 'alert', id, created_at
+FROM alerts;
+
+INSERT INTO notification_deliveries (notification_id, channel, status, sent_at, delivered_at, opened_at)
+SELECT n.id, 'in_app', CASE WHEN a.is_read THEN 'opened' ELSE 'delivered' END, a.created_at, a.created_at, a.read_at
+FROM notifications n
+JOIN alerts a ON a.id = n.source_id
+WHERE n.source_type = 'alert';
+
+-- +goose Down
+DROP TABLE IF EXISTS notification_deliveries;
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Migration: 0030_flex_groups
+-- Description: Flexible category groups whose items share a single budget pool
+
+ALTER TABLE plan_category_groups
+ADD COLUMN is_flex BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN plan_category_groups.is_flex IS 'Item overspend is fine while the group total holds; summaries, alerts and pace evaluate the group as one pool';
+
+-- +goose Down
+ALTER TABLE plan_category_groups DROP COLUMN IF EXISTS is_flex;
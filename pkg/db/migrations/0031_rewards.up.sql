@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: 0031_rewards
+-- Description: Cash-back and reward credits tracked separately from income
+
+ALTER TABLE transactions
+ADD COLUMN is_reward BOOLEAN NOT NULL DEFAULT false,
+-- Category the reward is credited back to (reduces that category's net spend)
+ADD COLUMN reward_category_id UUID REFERENCES categories (id) ON DELETE SET NULL,
+-- Detection rule that matched, or 'manual'
+ADD COLUMN reward_rule TEXT;
+
+CREATE INDEX idx_transactions_user_rewards ON transactions (user_id, posted_at)
+WHERE
+    is_reward;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_user_rewards;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS reward_rule,
+DROP COLUMN IF EXISTS reward_category_id,
+DROP COLUMN IF EXISTS is_reward;
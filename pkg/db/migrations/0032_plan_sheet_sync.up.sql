@@ -0,0 +1,65 @@
+-- +goose Up
+-- Migration: 0032_plan_sheet_sync
+-- Description: Two-way Google Sheets sync for plans
+
+-- Google account authorized for Sheets access (one per user)
+CREATE TABLE google_sheets_connections (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    access_token TEXT NOT NULL,
+    refresh_token TEXT NOT NULL,
+    token_type TEXT NOT NULL DEFAULT 'Bearer',
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_google_sheets_connections_updated_at
+BEFORE UPDATE ON google_sheets_connections
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- A plan mapped to a sheet tab
+CREATE TABLE plan_sheet_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    plan_id UUID NOT NULL UNIQUE REFERENCES user_plans (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    spreadsheet_id TEXT NOT NULL,
+    sheet_name TEXT NOT NULL,
+    last_pushed_at TIMESTAMPTZ,
+    last_pulled_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_plan_sheet_links_updated_at
+BEFORE UPDATE ON plan_sheet_links
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Budget values as of the last sync, used to tell sheet edits from app edits
+CREATE TABLE plan_sheet_row_states (
+    link_id UUID NOT NULL REFERENCES plan_sheet_links (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES plan_items (id) ON DELETE CASCADE,
+    budgeted_minor BIGINT NOT NULL,
+    PRIMARY KEY (link_id, item_id)
+);
+
+CREATE TABLE plan_sheet_sync_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    link_id UUID NOT NULL REFERENCES plan_sheet_links (id) ON DELETE CASCADE,
+    direction TEXT NOT NULL, -- 'push', 'pull'
+    status TEXT NOT NULL, -- 'succeeded', 'failed'
+    items_updated INT NOT NULL DEFAULT 0,
+    conflicts INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT plan_sheet_sync_log_direction_chk CHECK (direction IN ('push', 'pull')),
+    CONSTRAINT plan_sheet_sync_log_status_chk CHECK (status IN ('succeeded', 'failed'))
+);
+
+CREATE INDEX idx_plan_sheet_sync_log_link_created ON plan_sheet_sync_log (link_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS plan_sheet_sync_log;
+DROP TABLE IF EXISTS plan_sheet_row_states;
+DROP TABLE IF EXISTS plan_sheet_links;
+DROP TABLE IF EXISTS google_sheets_connections;
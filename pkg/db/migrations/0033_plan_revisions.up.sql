@@ -0,0 +1,37 @@
+-- +goose Up
+-- Migration: 0033_plan_revisions
+-- Description: Immutable plan revisions (structure snapshots) for history and restore
+
+CREATE TABLE plan_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    plan_id UUID NOT NULL REFERENCES user_plans (id) ON DELETE CASCADE,
+    revision_number INT NOT NULL,
+    author_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    reason TEXT NOT NULL, -- 'baseline', 'structure_update', 'restore'
+    restored_from_id UUID REFERENCES plan_revisions (id) ON DELETE SET NULL,
+    snapshot JSONB NOT NULL, -- Groups, categories and items after the change
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT plan_revisions_number_uniq UNIQUE (plan_id, revision_number),
+    CONSTRAINT plan_revisions_reason_chk CHECK (reason IN ('baseline', 'structure_update', 'restore'))
+);
+
+-- Revisions are append-only; only FK ON DELETE SET NULL may touch a row
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION prevent_plan_revision_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'plan revisions are immutable';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trigger_plan_revisions_immutable
+BEFORE UPDATE ON plan_revisions
+FOR EACH ROW
+WHEN (OLD.snapshot IS DISTINCT FROM NEW.snapshot OR OLD.revision_number IS DISTINCT FROM NEW.revision_number)
+EXECUTE FUNCTION prevent_plan_revision_update();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_plan_revisions_immutable ON plan_revisions;
+DROP FUNCTION IF EXISTS prevent_plan_revision_update();
+DROP TABLE IF EXISTS plan_revisions;
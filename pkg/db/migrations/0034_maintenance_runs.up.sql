@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration: 0034_maintenance_runs
+-- Description: Results of scheduled database maintenance tasks
+
+CREATE TABLE maintenance_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    task TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    triggered_by UUID REFERENCES users (id) ON DELETE SET NULL, -- NULL for scheduled runs
+    rows_affected BIGINT NOT NULL DEFAULT 0,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    CONSTRAINT maintenance_runs_task_chk CHECK (task IN ('vacuum_analyze', 'rollup_compaction', 'orphaned_files')),
+    CONSTRAINT maintenance_runs_status_chk CHECK (status IN ('running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_maintenance_runs_task_started_at ON maintenance_runs (task, started_at DESC);
+
+CREATE INDEX idx_maintenance_runs_started_at ON maintenance_runs (started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS maintenance_runs;
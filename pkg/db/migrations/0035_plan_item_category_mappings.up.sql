@@ -0,0 +1,32 @@
+-- +goose Up
+-- Migration: 0035_plan_item_category_mappings
+-- Description: Explicit transaction category and merchant mappings for plan items
+
+-- Each row maps either a transaction category or a merchant to a plan item.
+-- Mapped items take their actuals from these instead of name matching.
+CREATE TABLE plan_item_category_mappings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    plan_item_id UUID NOT NULL REFERENCES plan_items (id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories (id) ON DELETE CASCADE,
+    merchant_pattern TEXT, -- Case-insensitive substring of the merchant name or description
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT plan_item_category_mappings_target_chk CHECK (
+        (category_id IS NOT NULL) <> (merchant_pattern IS NOT NULL)
+    ),
+    CONSTRAINT plan_item_category_mappings_merchant_chk CHECK (
+        merchant_pattern IS NULL OR LENGTH(TRIM(merchant_pattern)) > 0
+    )
+);
+
+CREATE UNIQUE INDEX uniq_plan_item_category_mappings_category ON plan_item_category_mappings (plan_item_id, category_id)
+WHERE
+    category_id IS NOT NULL;
+
+CREATE UNIQUE INDEX uniq_plan_item_category_mappings_merchant ON plan_item_category_mappings (plan_item_id, LOWER(merchant_pattern))
+WHERE
+    merchant_pattern IS NOT NULL;
+
+CREATE INDEX idx_plan_item_category_mappings_category_id ON plan_item_category_mappings (category_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS plan_item_category_mappings;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: 0036_goal_priorities
+-- Description: Goal terms and priority ordering for surplus allocation
+
+-- term groups goals as short/medium/long term; NULL derives it from end_at.
+-- priority ranks goals for surplus allocation (1 is funded first); 0 is unranked.
+ALTER TABLE goals
+    ADD COLUMN term TEXT,
+    ADD COLUMN priority INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT goals_term_chk CHECK (term IS NULL OR term IN ('short_term', 'medium_term', 'long_term')),
+    ADD CONSTRAINT goals_priority_chk CHECK (priority >= 0);
+
+CREATE INDEX idx_goals_user_id_priority ON goals (user_id, priority) WHERE status = 'active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goals_user_id_priority;
+
+ALTER TABLE goals
+DROP CONSTRAINT IF EXISTS goals_priority_chk,
+DROP CONSTRAINT IF EXISTS goals_term_chk,
+DROP COLUMN IF EXISTS priority,
+DROP COLUMN IF EXISTS term;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Migration: 0037_plan_periods
+-- Description: Per-plan budget period configuration (monthly, biweekly, custom range)
+
+-- period_type sets how a plan's actuals are windowed:
+--   monthly:  months starting on period_start's day of month (calendar months when NULL)
+--   biweekly: 14-day periods anchored on period_start
+--   custom:   a single fixed range from period_start to period_end (exclusive)
+ALTER TABLE user_plans
+    ADD COLUMN period_type TEXT NOT NULL DEFAULT 'monthly',
+    ADD COLUMN period_start DATE,
+    ADD COLUMN period_end DATE,
+    ADD CONSTRAINT user_plans_period_type_chk CHECK (period_type IN ('monthly', 'biweekly', 'custom')),
+    ADD CONSTRAINT user_plans_period_range_chk CHECK (
+        (period_type = 'monthly' AND period_end IS NULL)
+        OR (period_type = 'biweekly' AND period_start IS NOT NULL AND period_end IS NULL)
+        OR (period_type = 'custom' AND period_start IS NOT NULL AND period_end > period_start)
+    );
+
+-- +goose Down
+ALTER TABLE user_plans
+DROP CONSTRAINT IF EXISTS user_plans_period_range_chk,
+DROP CONSTRAINT IF EXISTS user_plans_period_type_chk,
+DROP COLUMN IF EXISTS period_end,
+DROP COLUMN IF EXISTS period_start,
+DROP COLUMN IF EXISTS period_type;
@@ -0,0 +1,74 @@
+-- +goose Up
+-- Migration: 0038_tracked_purchases
+-- Description: Return-window and warranty tracking for purchases, with per-merchant policies
+
+-- A user's return and warranty terms for a merchant; merchant_pattern matches
+-- purchase merchant names case-insensitively as a substring.
+CREATE TABLE merchant_return_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    merchant_pattern TEXT NOT NULL,
+    return_days INT NOT NULL,
+    warranty_months INT,
+    reminder_days INT NOT NULL DEFAULT 3,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT merchant_return_policies_user_pattern_uniq UNIQUE (user_id, merchant_pattern),
+    CONSTRAINT merchant_return_policies_return_days_chk CHECK (return_days BETWEEN 0 AND 365),
+    CONSTRAINT merchant_return_policies_warranty_chk CHECK (warranty_months IS NULL OR warranty_months BETWEEN 1 AND 240),
+    CONSTRAINT merchant_return_policies_reminder_chk CHECK (reminder_days BETWEEN 0 AND 60)
+);
+
+CREATE TRIGGER trigger_set_merchant_return_policies_updated_at
+BEFORE UPDATE ON merchant_return_policies
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Purchases tracked for returns and warranty claims. attachment_key points at the
+-- receipt or warranty document in file storage.
+CREATE TABLE tracked_purchases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    transaction_id UUID REFERENCES transactions (id) ON DELETE SET NULL,
+    merchant_name TEXT NOT NULL,
+    description TEXT,
+    amount_minor BIGINT NOT NULL,
+    currency_code CHAR(3) NOT NULL,
+    purchased_at DATE NOT NULL,
+    return_by DATE,
+    warranty_until DATE,
+    reminder_days INT NOT NULL DEFAULT 3,
+    attachment_key TEXT,
+    status TEXT NOT NULL DEFAULT 'active',
+    return_reminded_at TIMESTAMPTZ,
+    warranty_reminded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tracked_purchases_currency_code_chk CHECK (currency_code ~ '^[A-Z]{3}$'),
+    CONSTRAINT tracked_purchases_amount_chk CHECK (amount_minor > 0),
+    CONSTRAINT tracked_purchases_status_chk CHECK (status IN ('active', 'returned', 'archived'))
+);
+
+CREATE INDEX idx_tracked_purchases_user_status ON tracked_purchases (user_id, status);
+
+CREATE INDEX idx_tracked_purchases_return_by ON tracked_purchases (return_by)
+WHERE
+    status = 'active'
+    AND return_reminded_at IS NULL;
+
+CREATE INDEX idx_tracked_purchases_warranty_until ON tracked_purchases (warranty_until)
+WHERE
+    status = 'active'
+    AND warranty_reminded_at IS NULL;
+
+CREATE UNIQUE INDEX uniq_tracked_purchases_transaction ON tracked_purchases (transaction_id)
+WHERE
+    transaction_id IS NOT NULL;
+
+CREATE TRIGGER trigger_set_tracked_purchases_updated_at
+BEFORE UPDATE ON tracked_purchases
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TABLE IF EXISTS tracked_purchases;
+
+DROP TABLE IF EXISTS merchant_return_policies;
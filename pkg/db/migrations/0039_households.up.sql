@@ -0,0 +1,69 @@
+-- +goose Up
+-- Migration: 0039_households
+-- Description: Households so partners can share plans, accounts and categories
+
+CREATE TABLE households (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    name TEXT NOT NULL,
+    created_by UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_households_updated_at
+BEFORE UPDATE ON households
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- A user belongs to at most one household. Owners manage members and invites,
+-- editors can change shared plans and viewers can only see them.
+CREATE TABLE household_members (
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (household_id, user_id),
+    CONSTRAINT household_members_user_uniq UNIQUE (user_id),
+    CONSTRAINT household_members_role_chk CHECK (role IN ('owner', 'editor', 'viewer'))
+);
+
+CREATE TABLE household_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    email CITEXT NOT NULL,
+    role TEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    invited_by UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT household_invites_role_chk CHECK (role IN ('editor', 'viewer'))
+);
+
+-- One open invite per email and household; re-inviting replaces it
+CREATE UNIQUE INDEX uniq_household_invites_pending
+ON household_invites (household_id, email)
+WHERE accepted_at IS NULL;
+
+-- Shared resources stay owned by their creator; household_id makes them visible
+-- to the other members
+ALTER TABLE user_plans
+    ADD COLUMN household_id UUID REFERENCES households (id) ON DELETE SET NULL;
+ALTER TABLE accounts
+    ADD COLUMN household_id UUID REFERENCES households (id) ON DELETE SET NULL;
+ALTER TABLE categories
+    ADD COLUMN household_id UUID REFERENCES households (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_user_plans_household_id ON user_plans (household_id) WHERE household_id IS NOT NULL;
+CREATE INDEX idx_accounts_household_id ON accounts (household_id) WHERE household_id IS NOT NULL;
+CREATE INDEX idx_categories_household_id ON categories (household_id) WHERE household_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_categories_household_id;
+DROP INDEX IF EXISTS idx_accounts_household_id;
+DROP INDEX IF EXISTS idx_user_plans_household_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS household_id;
+ALTER TABLE accounts DROP COLUMN IF EXISTS household_id;
+ALTER TABLE user_plans DROP COLUMN IF EXISTS household_id;
+DROP TABLE IF EXISTS household_invites;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Migration: 0040_share_links
+-- Description: Read-only, expiring share links for plan snapshots and monthly reports
+
+-- A link to a frozen snapshot of a plan or monthly report. The link's token is
+-- signed rather than stored; revoking or expiring the row disables it.
+CREATE TABLE share_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    plan_id UUID REFERENCES user_plans (id) ON DELETE CASCADE,
+    month_start DATE,
+    title TEXT NOT NULL,
+    snapshot JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    access_count INT NOT NULL DEFAULT 0,
+    last_accessed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT share_links_kind_chk CHECK (kind IN ('plan', 'monthly_report')),
+    CONSTRAINT share_links_target_chk CHECK (
+        (kind = 'plan' AND plan_id IS NOT NULL)
+        OR (kind = 'monthly_report' AND month_start IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_share_links_user ON share_links (user_id, created_at DESC);
+
+-- Every time a link is opened
+CREATE TABLE share_link_accesses (
+    id BIGSERIAL PRIMARY KEY,
+    link_id UUID NOT NULL REFERENCES share_links (id) ON DELETE CASCADE,
+    ip_address TEXT,
+    user_agent TEXT,
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_share_link_accesses_link ON share_link_accesses (link_id, accessed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS share_link_accesses;
+
+DROP TABLE IF EXISTS share_links;
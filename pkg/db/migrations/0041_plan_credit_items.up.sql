@@ -0,0 +1,77 @@
+-- +goose Up
+-- Migration: 0041_plan_credit_items
+-- Description: Sign-aware plan totals so credit items (negative budgets, e.g. an
+-- expected reimbursement) net against the other expenses
+
+-- Income comes from income items (by config tab, else item type); every other
+-- item is an expense, with credit items reducing the total.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION update_plan_totals()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE user_plans p
+    SET
+        total_income_minor = totals.income_minor,
+        total_expenses_minor = GREATEST(totals.expenses_minor, 0),
+        updated_at = NOW()
+    FROM (
+        SELECT
+            COALESCE(SUM(items.budgeted_minor) FILTER (WHERE items.is_income), 0) AS income_minor,
+            COALESCE(SUM(items.budgeted_minor) FILTER (WHERE NOT items.is_income), 0) AS expenses_minor
+        FROM (
+            SELECT pi.budgeted_minor,
+                   COALESCE(pic.target_tab::TEXT = 'income', pi.item_type = 'income', FALSE) AS is_income
+            FROM plan_items pi
+            LEFT JOIN plan_item_configs pic ON pic.id = pi.config_id
+            WHERE pi.plan_id = COALESCE(NEW.plan_id, OLD.plan_id)
+        ) items
+    ) totals
+    WHERE p.id = COALESCE(NEW.plan_id, OLD.plan_id);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Recompute existing plans with the new rules
+UPDATE user_plans p
+SET
+    total_income_minor = totals.income_minor,
+    total_expenses_minor = GREATEST(totals.expenses_minor, 0)
+FROM (
+    SELECT
+        pi.plan_id,
+        COALESCE(SUM(pi.budgeted_minor) FILTER (WHERE COALESCE(pic.target_tab::TEXT = 'income', pi.item_type = 'income', FALSE)), 0) AS income_minor,
+        COALESCE(SUM(pi.budgeted_minor) FILTER (WHERE NOT COALESCE(pic.target_tab::TEXT = 'income', pi.item_type = 'income', FALSE)), 0) AS expenses_minor
+    FROM plan_items pi
+    LEFT JOIN plan_item_configs pic ON pic.id = pi.config_id
+    GROUP BY pi.plan_id
+) totals
+WHERE p.id = totals.plan_id;
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION update_plan_totals()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE user_plans
+    SET
+        total_income_minor = COALESCE((
+            SELECT SUM(budgeted_minor)
+            FROM plan_items
+            WHERE plan_id = COALESCE(NEW.plan_id, OLD.plan_id)
+            AND budgeted_minor > 0
+        ), 0),
+        total_expenses_minor = COALESCE((
+            SELECT ABS(SUM(budgeted_minor))
+            FROM plan_items
+            WHERE plan_id = COALESCE(NEW.plan_id, OLD.plan_id)
+            AND budgeted_minor < 0
+        ), 0),
+        updated_at = NOW()
+    WHERE id = COALESCE(NEW.plan_id, OLD.plan_id);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Migration: 0042_user_file_type_json
+-- Description: Track JSON bulk transaction imports as their own file type
+
+ALTER TYPE user_file_type ADD VALUE IF NOT EXISTS 'json';
+
+-- +goose Down
+-- Enum values can't be dropped; 'json' stays unused after rolling back
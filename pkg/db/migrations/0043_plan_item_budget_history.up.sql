@@ -0,0 +1,46 @@
+-- +goose Up
+-- Migration: 0043_plan_item_budget_history
+-- Description: Effective-dated plan item budgets, so a plan can be read as it was
+-- budgeted at any past date
+
+CREATE TABLE plan_item_budget_history (
+    id BIGSERIAL PRIMARY KEY,
+    plan_id UUID NOT NULL REFERENCES user_plans (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES plan_items (id) ON DELETE CASCADE,
+    budgeted_minor BIGINT NOT NULL,
+    effective_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_plan_item_budget_history_lookup ON plan_item_budget_history (plan_id, item_id, effective_at DESC);
+
+-- Existing items: their current budget is the best known value since creation
+INSERT INTO plan_item_budget_history (plan_id, item_id, budgeted_minor, effective_at)
+SELECT plan_id, id, COALESCE(budgeted_minor, 0), COALESCE(created_at, NOW())
+FROM plan_items;
+
+-- ============================================================================
+-- Trigger: Record every budget value an item takes, from any write path
+-- ============================================================================
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION track_plan_item_budget()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' OR OLD.budgeted_minor IS DISTINCT FROM NEW.budgeted_minor THEN
+    INSERT INTO plan_item_budget_history (plan_id, item_id, budgeted_minor)
+    VALUES (NEW.plan_id, NEW.id, COALESCE(NEW.budgeted_minor, 0));
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_track_plan_item_budget
+  AFTER INSERT OR UPDATE OF budgeted_minor ON plan_items
+  FOR EACH ROW
+  EXECUTE FUNCTION track_plan_item_budget();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_track_plan_item_budget ON plan_items;
+DROP FUNCTION IF EXISTS track_plan_item_budget();
+DROP TABLE IF EXISTS plan_item_budget_history;
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Migration: 0044_user_file_storage
+-- Description: Classify stored files for per-user storage quotas and let imported
+-- statement files be purged while their rows and checksums stay for audit
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'user_file_purpose') THEN
+        CREATE TYPE user_file_purpose AS ENUM (
+            'statement',
+            'receipt',
+            'report',
+            'other'
+        );
+    END IF;
+END
+$$;
+
+ALTER TABLE user_files
+    ADD COLUMN purpose user_file_purpose NOT NULL DEFAULT 'other',
+    ADD COLUMN purged_at TIMESTAMPTZ;
+
+-- Existing files: spreadsheets and imports are statements, images and documents receipts
+UPDATE user_files uf
+SET purpose = CASE
+    WHEN uf.type IN ('csv', 'xlsx', 'json') THEN 'statement'::user_file_purpose
+    WHEN uf.type = 'image' THEN 'receipt'::user_file_purpose
+    WHEN EXISTS (SELECT 1 FROM documents d WHERE d.file_id = uf.id) THEN 'receipt'::user_file_purpose
+    WHEN EXISTS (SELECT 1 FROM tracked_purchases tp WHERE tp.attachment_key = uf.id::TEXT) THEN 'receipt'::user_file_purpose
+    ELSE 'statement'::user_file_purpose
+END;
+
+-- Usage only counts files whose content is still stored
+CREATE INDEX idx_user_files_stored ON user_files (user_id, purpose)
+WHERE purged_at IS NULL AND storage_url IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_files_stored;
+ALTER TABLE user_files
+    DROP COLUMN IF EXISTS purged_at,
+    DROP COLUMN IF EXISTS purpose;
+DROP TYPE IF EXISTS user_file_purpose;
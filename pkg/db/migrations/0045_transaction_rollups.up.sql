@@ -0,0 +1,39 @@
+-- +goose Up
+-- Migration: 0045_transaction_rollups
+-- Description: Monthly transaction rollups backing ad-hoc insights aggregations
+
+-- One row per user, month, category, merchant, account, currency and direction.
+-- Refreshed periodically; sums and counts roll up further to any coarser grouping.
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW transaction_monthly_rollups AS
+SELECT
+    user_id,
+    date_trunc('month', posted_at)::DATE AS month,
+    category_id,
+    COALESCE(NULLIF(merchant_name, ''), description) AS merchant_name,
+    account_id,
+    currency_code,
+    amount_minor > 0 AS is_income,
+    SUM(amount_minor) AS sum_minor,
+    COUNT(*) AS tx_count
+FROM transactions
+GROUP BY 1, 2, 3, 4, 5, 6, 7;
+
+-- Unique index for concurrent refresh
+CREATE UNIQUE INDEX idx_transaction_monthly_rollups_unique ON transaction_monthly_rollups (
+    user_id,
+    month,
+    category_id,
+    merchant_name,
+    account_id,
+    currency_code,
+    is_income
+);
+
+CREATE INDEX idx_transaction_monthly_rollups_user_month ON transaction_monthly_rollups (user_id, month);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP MATERIALIZED VIEW IF EXISTS transaction_monthly_rollups;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Migration: 0046_weekly_digest
+-- Description: Per-user opt-in and delivery day for the weekly digest
+
+-- delivery_weekday follows Go's time.Weekday (0 = Sunday).
+-- last_sent_at is claimed before sending so a digest goes out at most once a week.
+-- +goose StatementBegin
+CREATE TABLE weekly_digest_settings (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    delivery_weekday SMALLINT NOT NULL DEFAULT 1,
+    last_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT weekly_digest_settings_weekday_chk CHECK (delivery_weekday BETWEEN 0 AND 6)
+);
+
+CREATE INDEX idx_weekly_digest_settings_weekday ON weekly_digest_settings (delivery_weekday) WHERE enabled;
+
+CREATE TRIGGER trigger_set_weekly_digest_settings_updated_at
+BEFORE UPDATE ON weekly_digest_settings
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS weekly_digest_settings;
+-- +goose StatementEnd
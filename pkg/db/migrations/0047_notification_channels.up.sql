@@ -0,0 +1,68 @@
+-- +goose Up
+-- Migration: 0047_notification_channels
+-- Description: Web push and webhook channels, per-user channel preferences and quiet hours
+
+-- Users without a row get the defaults: email, push and web push on, no webhook, no quiet hours.
+-- Quiet hours are minutes after midnight in the user's timezone and may wrap midnight;
+-- push and web push are held back until they end.
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    web_push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    webhook_url TEXT,
+    webhook_secret TEXT,
+    quiet_start_minute SMALLINT,
+    quiet_end_minute SMALLINT,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT notification_preferences_quiet_chk CHECK (
+        (quiet_start_minute IS NULL) = (quiet_end_minute IS NULL)
+        AND quiet_start_minute BETWEEN 0 AND 1439
+        AND quiet_end_minute BETWEEN 0 AND 1439
+    )
+);
+
+CREATE TRIGGER trigger_set_notification_preferences_updated_at
+BEFORE UPDATE ON notification_preferences
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Browser push subscriptions; a user may have one per browser
+CREATE TABLE web_push_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL,
+    auth TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_web_push_subscriptions_user_id ON web_push_subscriptions (user_id);
+
+-- Deliveries held back by quiet hours keep their push payload until scheduled_for
+ALTER TABLE notification_deliveries
+    ADD COLUMN scheduled_for TIMESTAMPTZ,
+    ADD COLUMN payload JSONB,
+    DROP CONSTRAINT notification_deliveries_channel_chk,
+    ADD CONSTRAINT notification_deliveries_channel_chk CHECK (channel IN ('in_app', 'email', 'push', 'web_push', 'webhook'));
+
+CREATE INDEX idx_notification_deliveries_scheduled ON notification_deliveries (scheduled_for)
+WHERE
+    status = 'pending'
+    AND scheduled_for IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_notification_deliveries_scheduled;
+
+DELETE FROM notification_deliveries WHERE channel IN ('web_push', 'webhook');
+
+ALTER TABLE notification_deliveries
+DROP CONSTRAINT notification_deliveries_channel_chk,
+ADD CONSTRAINT notification_deliveries_channel_chk CHECK (channel IN ('in_app', 'email', 'push')),
+DROP COLUMN IF EXISTS payload,
+DROP COLUMN IF EXISTS scheduled_for;
+
+DROP TABLE IF EXISTS web_push_subscriptions;
+
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration: 0048_categorization_outcomes
+-- Description: Remember what the categorization engine predicted so later corrections measure its accuracy
+
+-- auto_category_id is the category the engine assigned; a transaction whose
+-- category_id later differs was corrected. The rule or merchant that matched
+-- attributes the prediction for per-rule accuracy.
+ALTER TABLE transactions
+    ADD COLUMN auto_category_id UUID REFERENCES categories (id) ON DELETE SET NULL,
+    ADD COLUMN categorized_by_rule_id UUID REFERENCES category_rules (id) ON DELETE SET NULL,
+    ADD COLUMN categorized_by_merchant_id UUID REFERENCES merchants (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_user_id_categorized_by_rule ON transactions (user_id, categorized_by_rule_id)
+WHERE
+    auto_category_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_user_id_categorized_by_rule;
+
+ALTER TABLE transactions
+DROP COLUMN IF EXISTS categorized_by_merchant_id,
+DROP COLUMN IF EXISTS categorized_by_rule_id,
+DROP COLUMN IF EXISTS auto_category_id;
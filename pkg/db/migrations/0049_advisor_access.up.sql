@@ -0,0 +1,51 @@
+-- +goose Up
+-- Migration: 0049_advisor_access
+-- Description: Read-only advisor access to a client's reports, trends and plans, with advisor annotations
+
+-- A client invites an advisor by email; advisor_id is set once the invite is
+-- accepted. Revoking keeps the row so past annotations stay attributed.
+CREATE TABLE advisor_grants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    client_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    advisor_id UUID REFERENCES users (id) ON DELETE CASCADE,
+    email CITEXT NOT NULL,
+    token TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT advisor_grants_not_self_chk CHECK (advisor_id IS NULL OR advisor_id <> client_id)
+);
+
+-- One open invite or active grant per client and advisor email
+CREATE UNIQUE INDEX uniq_advisor_grants_open
+ON advisor_grants (client_id, email)
+WHERE revoked_at IS NULL;
+
+CREATE INDEX idx_advisor_grants_advisor_id
+ON advisor_grants (advisor_id)
+WHERE accepted_at IS NOT NULL AND revoked_at IS NULL;
+
+CREATE TABLE advisor_annotations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    grant_id UUID NOT NULL REFERENCES advisor_grants (id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    advisor_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'note',
+    subject TEXT NOT NULL DEFAULT 'general',
+    plan_id UUID REFERENCES user_plans (id) ON DELETE SET NULL,
+    month_start DATE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT advisor_annotations_kind_chk CHECK (kind IN ('note', 'recommendation')),
+    CONSTRAINT advisor_annotations_subject_chk CHECK (subject IN ('general', 'report', 'trend', 'plan'))
+);
+
+CREATE INDEX idx_advisor_annotations_client_id ON advisor_annotations (client_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_advisor_annotations_client_id;
+DROP TABLE IF EXISTS advisor_annotations;
+DROP INDEX IF EXISTS idx_advisor_grants_advisor_id;
+DROP INDEX IF EXISTS uniq_advisor_grants_open;
+DROP TABLE IF EXISTS advisor_grants;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Migration: 0050_telegram_bot
+-- Description: Telegram chats linked to accounts for quick capture and alerts, and the Telegram notification channel
+
+-- A user links at most one chat and a chat belongs to at most one user
+CREATE TABLE telegram_links (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL UNIQUE,
+    username TEXT,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One-time codes the user sends to the bot to link a chat. Only a SHA-256 hash
+-- is stored; a code is deleted when used, and issuing a new one replaces it.
+CREATE TABLE telegram_link_codes (
+    code_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notification_preferences
+    ADD COLUMN telegram_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE notification_deliveries
+    DROP CONSTRAINT notification_deliveries_channel_chk,
+    ADD CONSTRAINT notification_deliveries_channel_chk CHECK (channel IN ('in_app', 'email', 'push', 'web_push', 'webhook', 'telegram'));
+
+-- +goose Down
+DELETE FROM notification_deliveries WHERE channel = 'telegram';
+
+ALTER TABLE notification_deliveries
+DROP CONSTRAINT notification_deliveries_channel_chk,
+ADD CONSTRAINT notification_deliveries_channel_chk CHECK (channel IN ('in_app', 'email', 'push', 'web_push', 'webhook'));
+
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS telegram_enabled;
+
+DROP TABLE IF EXISTS telegram_link_codes;
+DROP TABLE IF EXISTS telegram_links;
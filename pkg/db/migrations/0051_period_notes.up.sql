@@ -0,0 +1,26 @@
+-- +goose Up
+-- Migration: 0051_period_notes
+-- Description: User notes on a month or a category in a month, returned with trends and monthly insights
+
+-- A note without a category explains the whole month ("moved apartments in May")
+CREATE TABLE period_notes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    month_start DATE NOT NULL,
+    category_id UUID REFERENCES categories (id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT period_notes_month_start_chk CHECK (EXTRACT(DAY FROM month_start) = 1)
+);
+
+CREATE INDEX idx_period_notes_user_month ON period_notes (user_id, month_start);
+
+CREATE TRIGGER trigger_set_period_notes_updated_at
+BEFORE UPDATE ON period_notes
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_period_notes_updated_at ON period_notes;
+DROP INDEX IF EXISTS idx_period_notes_user_month;
+DROP TABLE IF EXISTS period_notes;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Migration: 0053_account_closures
+-- Description: Staged anonymization of closed accounts: access revoked on closure, data anonymized after a grace period
+
+-- One row per closed account. The user row is kept, anonymized, so transactions
+-- retained for aggregate stats keep a valid owner.
+CREATE TABLE account_closures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    state TEXT NOT NULL DEFAULT 'closed',
+    reason TEXT,
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    purge_after TIMESTAMPTZ NOT NULL, -- End of the grace period, when the account can no longer be reopened
+    reopened_at TIMESTAMPTZ,
+    anonymized_at TIMESTAMPTZ,
+    anonymized_rows JSONB NOT NULL DEFAULT '{}'::jsonb, -- Rows changed per step
+    verified_at TIMESTAMPTZ, -- Last verification that found no personal data left
+    residual JSONB NOT NULL DEFAULT '{}'::jsonb, -- Personal data found per check at the last verification
+    last_error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT account_closures_state_chk CHECK (state IN ('closed', 'reopened', 'anonymized'))
+);
+
+CREATE INDEX idx_account_closures_due ON account_closures (purge_after) WHERE state = 'closed';
+
+CREATE TRIGGER trigger_set_account_closures_updated_at
+BEFORE UPDATE ON account_closures
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_account_closures_updated_at ON account_closures;
+DROP INDEX IF EXISTS idx_account_closures_due;
+DROP TABLE IF EXISTS account_closures;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration: 0054_alert_settings
+-- Description: Per-user alert settings: an enable flag, threshold and delivery channels per alert type
+
+-- Alert types without a row use the defaults in the insights service
+CREATE TABLE alert_settings (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    alert_type VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    threshold DOUBLE PRECISION, -- NULL uses the default; unit depends on the alert type
+    channels TEXT[], -- NULL uses the default routing; empty means the in-app inbox only
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, alert_type),
+    CONSTRAINT alert_settings_threshold_chk CHECK (threshold IS NULL OR threshold > 0)
+);
+
+CREATE TRIGGER trigger_set_alert_settings_updated_at
+BEFORE UPDATE ON alert_settings
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_alert_settings_updated_at ON alert_settings;
+DROP TABLE IF EXISTS alert_settings;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Migration: 0055_alert_lifecycle
+-- Description: Alert snoozing and expiry, so stale alerts (e.g. last month's pace warnings) leave the list
+
+ALTER TABLE alerts
+ADD COLUMN snoozed_until TIMESTAMPTZ, -- Hidden from the unread list until then
+ADD COLUMN expires_at TIMESTAMPTZ; -- Dismissed by the cleanup job from then on
+
+-- Month-scoped alerts go stale when the month ends; the rest after 30 days
+UPDATE alerts
+SET expires_at = CASE
+    WHEN alert_type IN ('pace_warning', 'surprise_expense', 'budget_overspend', 'streak_broken')
+        THEN date_trunc('month', alert_date) + INTERVAL '1 month'
+    ELSE alert_date + INTERVAL '30 days'
+END;
+
+CREATE INDEX idx_alerts_expires_at ON alerts (expires_at)
+WHERE is_dismissed = false;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_alerts_expires_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE alerts DROP COLUMN IF EXISTS snoozed_until;
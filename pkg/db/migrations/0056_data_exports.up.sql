@@ -0,0 +1,40 @@
+-- +goose Up
+-- Migration: 0056_data_exports
+-- Description: Full user data exports (GDPR), built in the background and downloaded through signed links
+
+-- One row per requested export. Rows outlive the archive itself as the record
+-- of what was exported, when, and how often it was downloaded.
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ, -- The download link stops working then
+    file_id UUID, -- The archive in file storage
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    checksum_sha256 TEXT,
+    row_counts JSONB NOT NULL DEFAULT '{}'::jsonb, -- Rows exported per dataset
+    download_count INT NOT NULL DEFAULT 0,
+    last_downloaded_at TIMESTAMPTZ,
+    last_error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT data_exports_status_chk CHECK (status IN ('pending', 'running', 'ready', 'failed'))
+);
+
+CREATE INDEX idx_data_exports_user ON data_exports (user_id, requested_at DESC);
+
+-- A user has at most one export in progress
+CREATE UNIQUE INDEX idx_data_exports_in_progress ON data_exports (user_id)
+WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER trigger_set_data_exports_updated_at
+BEFORE UPDATE ON data_exports
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_data_exports_updated_at ON data_exports;
+DROP INDEX IF EXISTS idx_data_exports_in_progress;
+DROP INDEX IF EXISTS idx_data_exports_user;
+DROP TABLE IF EXISTS data_exports;
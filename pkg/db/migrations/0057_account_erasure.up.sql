@@ -0,0 +1,24 @@
+-- +goose Up
+-- Migration: 0057_account_erasure
+-- Description: Account deletion: closures that erase all data after the grace period instead of anonymizing it
+
+ALTER TABLE account_closures
+ADD COLUMN mode TEXT NOT NULL DEFAULT 'anonymize',
+ADD CONSTRAINT account_closures_mode_chk CHECK (mode IN ('anonymize', 'erase'));
+
+-- Erasing an account deletes its user row, and the closure with it. The
+-- tombstone is what's left: proof the account existed and when it was erased,
+-- without any personal data. It has no foreign key on purpose.
+CREATE TABLE account_tombstones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL UNIQUE,
+    closed_at TIMESTAMPTZ NOT NULL,
+    erased_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    erased_rows JSONB NOT NULL DEFAULT '{}'::jsonb, -- Rows deleted per table
+    files_deleted INT NOT NULL DEFAULT 0 -- Stored files removed from file storage
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS account_tombstones;
+ALTER TABLE account_closures DROP CONSTRAINT IF EXISTS account_closures_mode_chk;
+ALTER TABLE account_closures DROP COLUMN IF EXISTS mode;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Migration: 0058_audit_events
+-- Description: Audit log of sensitive mutations: who called which RPC, on what, from where
+
+-- Append-only. actor_id has no foreign key so events outlive the accounts
+-- that made them.
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_id UUID,
+    procedure TEXT NOT NULL, -- e.g. /echo.v1.PlanService/DeletePlan
+    entity_ids TEXT[] NOT NULL DEFAULT '{}', -- IDs named in the request
+    before JSONB, -- Summary of the entity before the call, where one is taken
+    after JSONB, -- Summary of the request
+    outcome TEXT NOT NULL, -- 'ok' or the Connect error code
+    error TEXT,
+    ip TEXT,
+    user_agent TEXT,
+    request_id TEXT
+);
+
+CREATE INDEX idx_audit_events_occurred ON audit_events (occurred_at DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor_id, occurred_at DESC);
+CREATE INDEX idx_audit_events_entities ON audit_events USING GIN (entity_ids);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: 0059_api_keys
+-- Description: Scoped long-lived API keys (personal access tokens) for scripts
+
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL, -- First characters of the key, shown to identify it
+    key_hash TEXT NOT NULL UNIQUE, -- SHA-256 of the key; the key itself is never stored
+    scope TEXT NOT NULL CHECK (scope IN ('read_only', 'import_only', 'full')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ, -- NULL never expires
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Migration: 0060_oauth_identity_profile
+-- Description: Remember the email and last sign-in of each linked OAuth identity
+
+ALTER TABLE user_oauth_identities
+    ADD COLUMN email TEXT, -- Email the provider reported, which may differ from the user's
+    ADD COLUMN last_login_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE user_oauth_identities
+    DROP COLUMN IF EXISTS last_login_at,
+    DROP COLUMN IF EXISTS email;
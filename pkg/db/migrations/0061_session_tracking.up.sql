@@ -0,0 +1,25 @@
+-- +goose Up
+-- Migration: 0061_session_tracking
+-- Description: Device and last-seen tracking for user sessions, revocation, and refresh token rotation
+
+ALTER TABLE user_sessions
+    ADD COLUMN device_name TEXT,
+    ADD COLUMN access_token_id TEXT, -- jti of the latest access token issued for the session
+    ADD COLUMN previous_refresh_token_hash VARCHAR(255), -- Replaced by the last rotation; reuse means theft
+    ADD COLUMN last_seen_at TIMESTAMPTZ,
+    ADD COLUMN last_seen_ip TEXT,
+    ADD COLUMN revoked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_user_sessions_access_token ON user_sessions (access_token_id);
+CREATE INDEX idx_user_sessions_previous_token ON user_sessions (previous_refresh_token_hash);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_sessions_previous_token;
+DROP INDEX IF EXISTS idx_user_sessions_access_token;
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS last_seen_ip,
+    DROP COLUMN IF EXISTS last_seen_at,
+    DROP COLUMN IF EXISTS previous_refresh_token_hash,
+    DROP COLUMN IF EXISTS access_token_id,
+    DROP COLUMN IF EXISTS device_name;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration: 0062_idempotency_keys
+-- Description: Responses of mutating RPCs by client Idempotency-Key, replayed to retries
+
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    procedure TEXT NOT NULL,
+    request_hash BYTEA NOT NULL, -- SHA-256 of the procedure and request, to reject reused keys
+    response BYTEA, -- Serialized response; NULL while the call is in progress
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Migration: 0063_plan_versions
+-- Description: Plan structure version for optimistic concurrency between devices
+
+ALTER TABLE user_plans ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE user_plans DROP COLUMN IF EXISTS version;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Migration: 0064_feature_flags
+-- Description: Feature flags enabled globally, per role cohort, by percentage rollout or per user
+
+CREATE TABLE feature_flags (
+    key TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT FALSE, -- On for everyone
+    roles TEXT[] NOT NULL DEFAULT '{}', -- On for users with any of these roles
+    rollout_percent SMALLINT NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    updated_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Per-user overrides win over the flag's rules, either way
+CREATE TABLE feature_flag_overrides (
+    flag_key TEXT NOT NULL REFERENCES feature_flags (key) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (flag_key, user_id)
+);
+
+INSERT INTO feature_flags (key, description) VALUES
+    ('aggregator_sync', 'Bank account sync through an aggregator'),
+    ('ml_analyzer', 'Machine-learned spending analysis'),
+    ('ai_assistant', 'Conversational assistant over the user''s finances');
+
+-- +goose Down
+DROP TABLE IF EXISTS feature_flag_overrides;
+DROP TABLE IF EXISTS feature_flags;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Migration: 0065_upload_sessions
+-- Description: Resumable uploads sent in chunks, assembled into a user file once complete
+
+CREATE TABLE upload_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    file_type user_file_type NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    chunk_size INTEGER NOT NULL,
+    checksum_sha256 TEXT NOT NULL, -- Of the whole file, verified on completion
+    status TEXT NOT NULL DEFAULT 'pending',
+    file_id UUID REFERENCES user_files (id) ON DELETE SET NULL, -- Set once completed
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ,
+    CONSTRAINT upload_sessions_status_chk CHECK (status IN ('pending', 'completed', 'aborted')),
+    CONSTRAINT upload_sessions_size_chk CHECK (size_bytes >= 1 AND size_bytes <= 20000000 AND chunk_size >= 1),
+    CONSTRAINT upload_sessions_checksum_chk CHECK (checksum_sha256 ~ '^[a-f0-9]{64}$')
+);
+
+CREATE INDEX idx_upload_sessions_user_id ON upload_sessions (user_id, created_at DESC);
+CREATE INDEX idx_upload_sessions_expires ON upload_sessions (expires_at) WHERE status = 'pending';
+
+CREATE TABLE upload_chunks (
+    upload_id UUID NOT NULL REFERENCES upload_sessions (id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    storage_file_id UUID NOT NULL, -- Where the chunk is staged in file storage
+    size_bytes INTEGER NOT NULL,
+    checksum_sha256 TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (upload_id, chunk_index)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS upload_chunks;
+DROP TABLE IF EXISTS upload_sessions;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Migration: 0066_user_file_scans
+-- Description: Record the malware/type scan of each uploaded file so importers
+-- refuse files that are quarantined or haven't been scanned yet
+
+ALTER TABLE user_files
+    ADD COLUMN scan_status TEXT NOT NULL DEFAULT 'pending',
+    ADD COLUMN scan_detail TEXT,
+    ADD COLUMN scanned_at TIMESTAMPTZ,
+    ADD CONSTRAINT user_files_scan_status_chk CHECK (scan_status IN ('pending', 'clean', 'quarantined'));
+
+-- Files stored before scanning existed are picked up by the rescan job
+CREATE INDEX idx_user_files_scan_pending ON user_files (created_at)
+WHERE scan_status = 'pending' AND storage_url IS NOT NULL AND purged_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_files_scan_pending;
+ALTER TABLE user_files
+    DROP CONSTRAINT IF EXISTS user_files_scan_status_chk,
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_detail,
+    DROP COLUMN IF EXISTS scan_status;
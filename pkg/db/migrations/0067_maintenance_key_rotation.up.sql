@@ -0,0 +1,16 @@
+-- +goose Up
+-- Migration: 0067_maintenance_key_rotation
+-- Description: Record runs of the storage encryption key rotation task
+
+ALTER TABLE maintenance_runs DROP CONSTRAINT maintenance_runs_task_chk;
+
+ALTER TABLE maintenance_runs
+ADD CONSTRAINT maintenance_runs_task_chk CHECK (task IN ('vacuum_analyze', 'rollup_compaction', 'orphaned_files', 'storage_key_rotation'));
+
+-- +goose Down
+DELETE FROM maintenance_runs WHERE task = 'storage_key_rotation';
+
+ALTER TABLE maintenance_runs DROP CONSTRAINT maintenance_runs_task_chk;
+
+ALTER TABLE maintenance_runs
+ADD CONSTRAINT maintenance_runs_task_chk CHECK (task IN ('vacuum_analyze', 'rollup_compaction', 'orphaned_files'));
@@ -0,0 +1,79 @@
+-- +goose Up
+-- Migration: 0068_canonical_merchants
+-- Description: Canonical merchants with brand details and MCC, seeded from public brand data
+
+CREATE TABLE canonical_merchants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    slug TEXT NOT NULL UNIQUE, -- e.g. 'uber-eats'
+    name TEXT NOT NULL, -- Display name, e.g. 'Uber Eats'
+    aliases TEXT[] NOT NULL DEFAULT '{}', -- Normalized description prefixes, e.g. '{UBER EATS,UBEREATS}'
+    website TEXT,
+    logo_url TEXT, -- Overrides the logo derived from the website
+    brand_color TEXT,
+    mcc TEXT, -- ISO 18245 merchant category code
+    source TEXT NOT NULL DEFAULT 'heuristic',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT canonical_merchants_brand_color_chk CHECK (brand_color ~ '^#[0-9A-F]{6}$'),
+    CONSTRAINT canonical_merchants_mcc_chk CHECK (mcc ~ '^[0-9]{4}$'),
+    CONSTRAINT canonical_merchants_source_chk CHECK (source IN ('dataset', 'heuristic'))
+);
+
+CREATE INDEX idx_canonical_merchants_aliases ON canonical_merchants USING GIN (aliases);
+
+CREATE TRIGGER trigger_set_canonical_merchants_updated_at
+BEFORE UPDATE ON canonical_merchants
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+INSERT INTO canonical_merchants (slug, name, aliases, website, brand_color, mcc, source)
+VALUES
+    ('netflix', 'Netflix', '{NETFLIX}', 'https://www.netflix.com', '#E50914', '4899', 'dataset'),
+    ('spotify', 'Spotify', '{SPOTIFY}', 'https://www.spotify.com', '#1DB954', '5815', 'dataset'),
+    ('disney-plus', 'Disney+', '{DISNEY PLUS,DISNEYPLUS}', 'https://www.disneyplus.com', '#113CCF', '4899', 'dataset'),
+    ('youtube', 'YouTube', '{YOUTUBE,GOOGLE YOUTUBE}', 'https://www.youtube.com', '#FF0000', '4899', 'dataset'),
+    ('amazon', 'Amazon', '{AMAZON,AMZN,AMZN MKTP,AMAZON MKTPLACE}', 'https://www.amazon.com', '#FF9900', '5942', 'dataset'),
+    ('amazon-prime', 'Amazon Prime', '{AMAZON PRIME,PRIME VIDEO,AMZN PRIME}', 'https://www.amazon.com', '#00A8E1', '4899', 'dataset'),
+    ('apple', 'Apple', '{APPLE,APPLE COM,APPLE COM BILL}', 'https://www.apple.com', '#000000', '5734', 'dataset'),
+    ('google', 'Google', '{GOOGLE}', 'https://www.google.com', '#4285F4', '5734', 'dataset'),
+    ('microsoft', 'Microsoft', '{MICROSOFT,MSFT}', 'https://www.microsoft.com', '#00A4EF', '5734', 'dataset'),
+    ('paypal', 'PayPal', '{PAYPAL}', 'https://www.paypal.com', '#003087', '6012', 'dataset'),
+    ('uber', 'Uber', '{UBER,UBER TRIP}', 'https://www.uber.com', '#000000', '4121', 'dataset'),
+    ('uber-eats', 'Uber Eats', '{UBER EATS,UBEREATS}', 'https://www.ubereats.com', '#06C167', '5814', 'dataset'),
+    ('lyft', 'Lyft', '{LYFT}', 'https://www.lyft.com', '#FF00BF', '4121', 'dataset'),
+    ('bolt', 'Bolt', '{BOLT,BOLT EU}', 'https://bolt.eu', '#34D186', '4121', 'dataset'),
+    ('deliveroo', 'Deliveroo', '{DELIVEROO}', 'https://deliveroo.com', '#00CCBC', '5814', 'dataset'),
+    ('glovo', 'Glovo', '{GLOVO}', 'https://glovoapp.com', '#FFC244', '5814', 'dataset'),
+    ('starbucks', 'Starbucks', '{STARBUCKS}', 'https://www.starbucks.com', '#00704A', '5814', 'dataset'),
+    ('mcdonalds', 'McDonald''s', '{MCDONALDS,MC DONALDS}', 'https://www.mcdonalds.com', '#FFC72C', '5814', 'dataset'),
+    ('burger-king', 'Burger King', '{BURGER KING}', 'https://www.bk.com', '#D62300', '5814', 'dataset'),
+    ('kfc', 'KFC', '{KFC}', 'https://www.kfc.com', '#A3080C', '5814', 'dataset'),
+    ('subway', 'Subway', '{SUBWAY}', 'https://www.subway.com', '#008C15', '5814', 'dataset'),
+    ('lidl', 'Lidl', '{LIDL}', 'https://www.lidl.com', '#0050AA', '5411', 'dataset'),
+    ('aldi', 'Aldi', '{ALDI}', 'https://www.aldi.com', '#00005F', '5411', 'dataset'),
+    ('tesco', 'Tesco', '{TESCO}', 'https://www.tesco.com', '#00539F', '5411', 'dataset'),
+    ('carrefour', 'Carrefour', '{CARREFOUR}', 'https://www.carrefour.com', '#004E9F', '5411', 'dataset'),
+    ('continente', 'Continente', '{CONTINENTE}', 'https://www.continente.pt', '#E30613', '5411', 'dataset'),
+    ('pingo-doce', 'Pingo Doce', '{PINGO DOCE}', 'https://www.pingodoce.pt', '#6FB42C', '5411', 'dataset'),
+    ('walmart', 'Walmart', '{WALMART,WAL MART,WM SUPERCENTER}', 'https://www.walmart.com', '#0071CE', '5411', 'dataset'),
+    ('costco', 'Costco', '{COSTCO}', 'https://www.costco.com', '#005DAA', '5300', 'dataset'),
+    ('target', 'Target', '{TARGET}', 'https://www.target.com', '#CC0000', '5311', 'dataset'),
+    ('ikea', 'IKEA', '{IKEA}', 'https://www.ikea.com', '#0058A3', '5712', 'dataset'),
+    ('zara', 'Zara', '{ZARA}', 'https://www.zara.com', '#000000', '5651', 'dataset'),
+    ('h-and-m', 'H&M', '{HM,H M,H AND M}', 'https://www.hm.com', '#E50010', '5651', 'dataset'),
+    ('primark', 'Primark', '{PRIMARK}', 'https://www.primark.com', '#0DB5E7', '5651', 'dataset'),
+    ('decathlon', 'Decathlon', '{DECATHLON}', 'https://www.decathlon.com', '#0082C3', '5941', 'dataset'),
+    ('shell', 'Shell', '{SHELL}', 'https://www.shell.com', '#FBCE07', '5541', 'dataset'),
+    ('bp', 'BP', '{BP}', 'https://www.bp.com', '#009900', '5541', 'dataset'),
+    ('galp', 'Galp', '{GALP}', 'https://www.galp.com', '#FF6600', '5541', 'dataset'),
+    ('repsol', 'Repsol', '{REPSOL}', 'https://www.repsol.com', '#FF8200', '5541', 'dataset'),
+    ('airbnb', 'Airbnb', '{AIRBNB}', 'https://www.airbnb.com', '#FF5A5F', '7011', 'dataset'),
+    ('booking-com', 'Booking.com', '{BOOKING COM,BOOKING}', 'https://www.booking.com', '#003580', '4722', 'dataset'),
+    ('ryanair', 'Ryanair', '{RYANAIR}', 'https://www.ryanair.com', '#073590', '4511', 'dataset'),
+    ('easyjet', 'easyJet', '{EASYJET}', 'https://www.easyjet.com', '#FF6600', '4511', 'dataset'),
+    ('vodafone', 'Vodafone', '{VODAFONE}', 'https://www.vodafone.com', '#E60000', '4814', 'dataset'),
+    ('cvs', 'CVS Pharmacy', '{CVS,CVS PHARMACY}', 'https://www.cvs.com', '#CC0000', '5912', 'dataset'),
+    ('walgreens', 'Walgreens', '{WALGREENS}', 'https://www.walgreens.com', '#E31837', '5912', 'dataset')
+ON CONFLICT (slug) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS canonical_merchants;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Migration: 0069_transaction_locations
+-- Description: Optional coordinates and place on transactions, from aggregator data or pinned by hand
+
+ALTER TABLE transactions
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD COLUMN place_name TEXT, -- e.g. 'Pingo Doce Alvalade'
+    ADD COLUMN location_source TEXT, -- 'aggregator' or 'manual'
+    ADD CONSTRAINT transactions_latitude_chk CHECK (latitude BETWEEN -90 AND 90),
+    ADD CONSTRAINT transactions_longitude_chk CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT transactions_coordinates_chk CHECK ((latitude IS NULL) = (longitude IS NULL)),
+    ADD CONSTRAINT transactions_location_source_chk CHECK (
+        location_source IN ('aggregator', 'manual')
+        AND (latitude IS NOT NULL OR place_name IS NOT NULL)
+        OR location_source IS NULL AND latitude IS NULL AND place_name IS NULL
+    );
+
+-- Map views only look at located transactions
+CREATE INDEX idx_transactions_user_located ON transactions (user_id, posted_at)
+WHERE latitude IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_user_located;
+
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_location_source_chk,
+    DROP CONSTRAINT IF EXISTS transactions_coordinates_chk,
+    DROP CONSTRAINT IF EXISTS transactions_longitude_chk,
+    DROP CONSTRAINT IF EXISTS transactions_latitude_chk,
+    DROP COLUMN IF EXISTS location_source,
+    DROP COLUMN IF EXISTS place_name,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
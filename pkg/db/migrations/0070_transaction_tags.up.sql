@@ -0,0 +1,13 @@
+-- +goose Up
+-- Migration: 0070_transaction_tags
+-- Description: Free-form tags on transactions, e.g. from Quick Capture hashtags
+
+ALTER TABLE transactions
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_transactions_tags ON transactions USING GIN (tags);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_tags;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,95 @@
+-- +goose Up
+-- Migration: 0071_sync_changes
+-- Description: Change feed offline clients sync from, one row per changed entity
+
+CREATE SEQUENCE sync_change_seq;
+
+-- The latest change to each synced entity. seq is the cursor clients page by;
+-- every change takes a new one, so an entity appears once in the feed however
+-- often it changed. Deleted entities stay as tombstones.
+CREATE TABLE sync_changes (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    seq BIGINT NOT NULL DEFAULT nextval('sync_change_seq'),
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Writing transaction, so readers can hold back changes of transactions
+    -- still in flight, which may commit with a lower seq than one already read
+    xid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, entity_type, entity_id),
+    CONSTRAINT sync_changes_entity_type_chk CHECK (entity_type IN ('transaction', 'category', 'rule', 'plan', 'goal'))
+);
+
+CREATE UNIQUE INDEX idx_sync_changes_user_seq ON sync_changes (user_id, seq);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_sync_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_user_id UUID;
+    row_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_user_id := OLD.user_id;
+        row_id := OLD.id;
+    ELSE
+        row_user_id := NEW.user_id;
+        row_id := NEW.id;
+    END IF;
+
+    -- Rows removed because their user was deleted have no one to sync to
+    IF NOT EXISTS (SELECT 1 FROM users WHERE id = row_user_id) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO sync_changes (user_id, entity_type, entity_id, deleted)
+    VALUES (row_user_id, TG_ARGV[0], row_id, TG_OP = 'DELETE')
+    ON CONFLICT (user_id, entity_type, entity_id) DO UPDATE
+    SET seq = nextval('sync_change_seq'),
+        deleted = EXCLUDED.deleted,
+        xid = pg_current_xact_id(),
+        changed_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trigger_sync_transactions
+AFTER INSERT OR UPDATE OR DELETE ON transactions
+FOR EACH ROW EXECUTE FUNCTION record_sync_change('transaction');
+
+CREATE TRIGGER trigger_sync_categories
+AFTER INSERT OR UPDATE OR DELETE ON categories
+FOR EACH ROW EXECUTE FUNCTION record_sync_change('category');
+
+CREATE TRIGGER trigger_sync_category_rules
+AFTER INSERT OR UPDATE OR DELETE ON category_rules
+FOR EACH ROW EXECUTE FUNCTION record_sync_change('rule');
+
+-- Item changes reach the feed through the plan totals they update
+CREATE TRIGGER trigger_sync_user_plans
+AFTER INSERT OR UPDATE OR DELETE ON user_plans
+FOR EACH ROW EXECUTE FUNCTION record_sync_change('plan');
+
+CREATE TRIGGER trigger_sync_goals
+AFTER INSERT OR UPDATE OR DELETE ON goals
+FOR EACH ROW EXECUTE FUNCTION record_sync_change('goal');
+
+-- Existing entities enter the feed so a first sync downloads everything
+INSERT INTO sync_changes (user_id, entity_type, entity_id)
+SELECT user_id, 'transaction', id FROM transactions
+UNION ALL SELECT user_id, 'category', id FROM categories
+UNION ALL SELECT user_id, 'rule', id FROM category_rules
+UNION ALL SELECT user_id, 'plan', id FROM user_plans
+UNION ALL SELECT user_id, 'goal', id FROM goals;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_sync_goals ON goals;
+DROP TRIGGER IF EXISTS trigger_sync_user_plans ON user_plans;
+DROP TRIGGER IF EXISTS trigger_sync_category_rules ON category_rules;
+DROP TRIGGER IF EXISTS trigger_sync_categories ON categories;
+DROP TRIGGER IF EXISTS trigger_sync_transactions ON transactions;
+DROP FUNCTION IF EXISTS record_sync_change();
+DROP TABLE IF EXISTS sync_changes;
+DROP SEQUENCE IF EXISTS sync_change_seq;
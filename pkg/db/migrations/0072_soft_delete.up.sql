@@ -0,0 +1,215 @@
+-- +goose Up
+-- Migration: 0072_soft_delete
+-- Description: Deleted transactions, plans, goals and rules go to a trash, restorable until purged
+
+ALTER TABLE transactions ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE user_plans ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE goals ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE category_rules ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- The trash, and the purge of what has been in it too long
+CREATE INDEX idx_transactions_deleted ON transactions (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_user_plans_deleted ON user_plans (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_goals_deleted ON goals (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_category_rules_deleted ON category_rules (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Rollups leave deleted transactions out
+DROP MATERIALIZED VIEW IF EXISTS transaction_monthly_rollups;
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW transaction_monthly_rollups AS
+SELECT
+    user_id,
+    date_trunc('month', posted_at)::DATE AS month,
+    category_id,
+    COALESCE(NULLIF(merchant_name, ''), description) AS merchant_name,
+    account_id,
+    currency_code,
+    amount_minor > 0 AS is_income,
+    SUM(amount_minor) AS sum_minor,
+    COUNT(*) AS tx_count
+FROM transactions
+WHERE deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5, 6, 7;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX idx_transaction_monthly_rollups_unique ON transaction_monthly_rollups (
+    user_id,
+    month,
+    category_id,
+    merchant_name,
+    account_id,
+    currency_code,
+    is_income
+);
+
+CREATE INDEX idx_transaction_monthly_rollups_user_month ON transaction_monthly_rollups (user_id, month);
+
+DROP MATERIALIZED VIEW IF EXISTS data_source_health;
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW data_source_health AS
+SELECT
+    user_id,
+    COALESCE(institution_name, 'Unknown') as institution_name,
+    source as source_type,
+    COUNT(*) as transaction_count,
+    MIN(posted_at) as first_transaction,
+    MAX(posted_at) as last_transaction,
+    MAX(created_at) as last_import,
+    ROUND(
+        COUNT(*) FILTER (WHERE category_id IS NOT NULL)::DECIMAL /
+        NULLIF(COUNT(*), 0), 4
+    ) as categorization_rate,
+    COUNT(*) FILTER (WHERE category_id IS NULL) as uncategorized_count
+FROM transactions
+WHERE deleted_at IS NULL
+GROUP BY user_id, COALESCE(institution_name, 'Unknown'), source;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX idx_data_source_health_unique ON data_source_health (
+    user_id,
+    institution_name,
+    source_type
+);
+
+-- Trashed entities leave the sync feed as deletions; restoring brings them back
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_sync_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_user_id UUID;
+    row_id UUID;
+    row_deleted BOOLEAN;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_user_id := OLD.user_id;
+        row_id := OLD.id;
+        row_deleted := TRUE;
+    ELSE
+        row_user_id := NEW.user_id;
+        row_id := NEW.id;
+        row_deleted := (to_jsonb(NEW) ->> 'deleted_at') IS NOT NULL;
+    END IF;
+
+    -- Rows removed because their user was deleted have no one to sync to
+    IF NOT EXISTS (SELECT 1 FROM users WHERE id = row_user_id) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO sync_changes (user_id, entity_type, entity_id, deleted)
+    VALUES (row_user_id, TG_ARGV[0], row_id, row_deleted)
+    ON CONFLICT (user_id, entity_type, entity_id) DO UPDATE
+    SET seq = nextval('sync_change_seq'),
+        deleted = EXCLUDED.deleted,
+        xid = pg_current_xact_id(),
+        changed_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION record_sync_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    row_user_id UUID;
+    row_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_user_id := OLD.user_id;
+        row_id := OLD.id;
+    ELSE
+        row_user_id := NEW.user_id;
+        row_id := NEW.id;
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM users WHERE id = row_user_id) THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO sync_changes (user_id, entity_type, entity_id, deleted)
+    VALUES (row_user_id, TG_ARGV[0], row_id, TG_OP = 'DELETE')
+    ON CONFLICT (user_id, entity_type, entity_id) DO UPDATE
+    SET seq = nextval('sync_change_seq'),
+        deleted = EXCLUDED.deleted,
+        xid = pg_current_xact_id(),
+        changed_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Trashed rows were meant to be deleted
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DELETE FROM user_plans WHERE deleted_at IS NOT NULL;
+DELETE FROM goals WHERE deleted_at IS NOT NULL;
+DELETE FROM category_rules WHERE deleted_at IS NOT NULL;
+
+DROP MATERIALIZED VIEW IF EXISTS data_source_health;
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW data_source_health AS
+SELECT
+    user_id,
+    COALESCE(institution_name, 'Unknown') as institution_name,
+    source as source_type,
+    COUNT(*) as transaction_count,
+    MIN(posted_at) as first_transaction,
+    MAX(posted_at) as last_transaction,
+    MAX(created_at) as last_import,
+    ROUND(
+        COUNT(*) FILTER (WHERE category_id IS NOT NULL)::DECIMAL /
+        NULLIF(COUNT(*), 0), 4
+    ) as categorization_rate,
+    COUNT(*) FILTER (WHERE category_id IS NULL) as uncategorized_count
+FROM transactions
+GROUP BY user_id, COALESCE(institution_name, 'Unknown'), source;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX idx_data_source_health_unique ON data_source_health (
+    user_id,
+    institution_name,
+    source_type
+);
+
+DROP MATERIALIZED VIEW IF EXISTS transaction_monthly_rollups;
+
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW transaction_monthly_rollups AS
+SELECT
+    user_id,
+    date_trunc('month', posted_at)::DATE AS month,
+    category_id,
+    COALESCE(NULLIF(merchant_name, ''), description) AS merchant_name,
+    account_id,
+    currency_code,
+    amount_minor > 0 AS is_income,
+    SUM(amount_minor) AS sum_minor,
+    COUNT(*) AS tx_count
+FROM transactions
+GROUP BY 1, 2, 3, 4, 5, 6, 7;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX idx_transaction_monthly_rollups_unique ON transaction_monthly_rollups (
+    user_id,
+    month,
+    category_id,
+    merchant_name,
+    account_id,
+    currency_code,
+    is_income
+);
+
+CREATE INDEX idx_transaction_monthly_rollups_user_month ON transaction_monthly_rollups (user_id, month);
+
+DROP INDEX IF EXISTS idx_category_rules_deleted;
+DROP INDEX IF EXISTS idx_goals_deleted;
+DROP INDEX IF EXISTS idx_user_plans_deleted;
+DROP INDEX IF EXISTS idx_transactions_deleted;
+
+ALTER TABLE category_rules DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE goals DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE user_plans DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Migration: 0073_billing
+-- Description: Stripe customers on subscriptions, and the time of the last
+-- webhook event applied so late deliveries can't undo newer ones
+
+ALTER TABLE subscriptions
+    ADD COLUMN external_customer_id TEXT UNIQUE,
+    ADD COLUMN external_updated_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS external_updated_at,
+    DROP COLUMN IF EXISTS external_customer_id;
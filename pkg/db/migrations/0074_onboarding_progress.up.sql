@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration: 0074_onboarding_progress
+-- Description: Onboarding steps users skipped, and when they finished it.
+-- Completed steps are worked out from the user's data instead.
+
+CREATE TABLE onboarding_progress (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    skipped_steps TEXT[] NOT NULL DEFAULT '{}',
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_onboarding_progress_updated_at
+BEFORE UPDATE ON onboarding_progress
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TABLE IF EXISTS onboarding_progress;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: 0075_demo_data
+-- Description: Flag gating sample data for new accounts
+
+INSERT INTO feature_flags (key, description) VALUES
+    ('demo_data', 'Seed new accounts with sample data to explore the app')
+ON CONFLICT (key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM feature_flags WHERE key = 'demo_data';
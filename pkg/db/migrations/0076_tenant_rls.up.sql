@@ -0,0 +1,61 @@
+-- +goose Up
+-- Migration: 0076_tenant_rls
+-- Description: Row-level security policies scoping user data to app.user_id
+--
+-- Repositories already filter by user; these policies are a safety net for
+-- sessions that declare who they act for with
+--   SELECT set_config('app.user_id', '<uuid>', true);
+-- Sessions that don't set it (migrations, cron jobs) see every row. The table
+-- owner bypasses RLS unless the table is switched to FORCE ROW LEVEL SECURITY,
+-- so policies only bind restricted roles until an operator opts in.
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION app_current_user_id() RETURNS UUID AS $$
+    SELECT NULLIF(current_setting('app.user_id', true), '')::uuid
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE goals ENABLE ROW LEVEL SECURITY;
+CREATE POLICY goals_tenant ON goals
+    USING (app_current_user_id() IS NULL OR user_id = app_current_user_id());
+
+ALTER TABLE goal_contributions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY goal_contributions_tenant ON goal_contributions
+    USING (
+        app_current_user_id() IS NULL
+        OR EXISTS (SELECT 1 FROM goals g WHERE g.id = goal_id AND g.user_id = app_current_user_id())
+    );
+
+ALTER TABLE recurring_subscriptions ENABLE ROW LEVEL SECURITY;
+CREATE POLICY recurring_subscriptions_tenant ON recurring_subscriptions
+    USING (app_current_user_id() IS NULL OR user_id = app_current_user_id());
+
+ALTER TABLE plan_item_configs ENABLE ROW LEVEL SECURITY;
+CREATE POLICY plan_item_configs_tenant ON plan_item_configs
+    USING (app_current_user_id() IS NULL OR user_id = app_current_user_id());
+
+-- Plan items follow their plan, which household members share
+ALTER TABLE plan_items ENABLE ROW LEVEL SECURITY;
+CREATE POLICY plan_items_tenant ON plan_items
+    USING (
+        app_current_user_id() IS NULL
+        OR EXISTS (
+            SELECT 1 FROM user_plans p
+            LEFT JOIN household_members hm ON hm.household_id = p.household_id
+            WHERE p.id = plan_id
+              AND (p.user_id = app_current_user_id() OR hm.user_id = app_current_user_id())
+        )
+    );
+
+-- +goose Down
+DROP POLICY IF EXISTS plan_items_tenant ON plan_items;
+ALTER TABLE plan_items DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS plan_item_configs_tenant ON plan_item_configs;
+ALTER TABLE plan_item_configs DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS recurring_subscriptions_tenant ON recurring_subscriptions;
+ALTER TABLE recurring_subscriptions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS goal_contributions_tenant ON goal_contributions;
+ALTER TABLE goal_contributions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS goals_tenant ON goals;
+ALTER TABLE goals DISABLE ROW LEVEL SECURITY;
+DROP FUNCTION IF EXISTS app_current_user_id();
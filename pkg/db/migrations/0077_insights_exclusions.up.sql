@@ -0,0 +1,13 @@
+-- +goose Up
+-- Migration: 0077_insights_exclusions
+-- Description: Per-user flag keeping accounts and categories out of insights
+--
+-- Flagged accounts and categories are left out of the spending pulse and
+-- monthly insights, e.g. a business account mixed in with personal spending.
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS excluded_from_insights BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS excluded_from_insights BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN IF EXISTS excluded_from_insights;
+ALTER TABLE accounts DROP COLUMN IF EXISTS excluded_from_insights;
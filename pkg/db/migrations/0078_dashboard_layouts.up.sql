@@ -0,0 +1,19 @@
+-- +goose Up
+-- Migration: 0078_dashboard_layouts
+-- Description: Per-user dashboard layouts: which blocks are shown, in what order and size
+
+-- Users without a row get the default layout from the insights service
+CREATE TABLE dashboard_layouts (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    blocks JSONB NOT NULL, -- [{"type": "status", "size": "large"}, ...] in display order
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_set_dashboard_layouts_updated_at
+BEFORE UPDATE ON dashboard_layouts
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_dashboard_layouts_updated_at ON dashboard_layouts;
+DROP TABLE IF EXISTS dashboard_layouts;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Migration: 0079_transaction_rules
+-- Description: Import-time rules that tag and rename transactions, with hit counters
+
+-- A rule fires when the description contains match_pattern and the absolute
+-- amount is within the optional bounds; it adds its tags and, if set, renames
+-- the transaction
+CREATE TABLE transaction_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    match_pattern TEXT NOT NULL, -- e.g. 'UBER'
+    amount_above_minor BIGINT, -- Absolute amount must be greater than this
+    amount_below_minor BIGINT, -- Absolute amount must be less than this
+    add_tags TEXT[] NOT NULL DEFAULT '{}',
+    rename_to TEXT, -- New merchant name, e.g. 'Uber ride'
+    priority INTEGER NOT NULL DEFAULT 0, -- Higher priority renames win
+    hit_count BIGINT NOT NULL DEFAULT 0, -- Transactions the rule fired on, for debugging
+    last_hit_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_transaction_rules_user ON transaction_rules (user_id, priority DESC)
+WHERE deleted_at IS NULL;
+
+CREATE TRIGGER trigger_set_transaction_rules_updated_at
+BEFORE UPDATE ON transaction_rules
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_set_transaction_rules_updated_at ON transaction_rules;
+DROP TABLE IF EXISTS transaction_rules;
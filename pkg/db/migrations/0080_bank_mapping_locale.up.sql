@@ -0,0 +1,10 @@
+-- +goose Up
+-- Migration: 0080_bank_mapping_locale
+-- Description: Locale pack per bank mapping, for cleaning the institution's descriptors
+
+-- Country code of a built-in locale pack (PT, ES, DE); NULL cleans nothing
+ALTER TABLE bank_mappings
+    ADD COLUMN locale TEXT;
+
+-- +goose Down
+ALTER TABLE bank_mappings DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Migration: 0081_transaction_category_review
+-- Description: When a transaction left the categorization review queue
+
+-- Set when the user resolved or dismissed the transaction in the review queue,
+-- so confirmed low-confidence categories don't come back
+ALTER TABLE transactions
+    ADD COLUMN category_reviewed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_transactions_review_queue ON transactions (user_id, posted_at DESC)
+WHERE deleted_at IS NULL AND category_reviewed_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_review_queue;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS category_reviewed_at;
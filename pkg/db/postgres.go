@@ -6,12 +6,16 @@ import (
 	"embed"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	// Register pgx database/sql driver.
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/chaos"
 )
 
 //go:embed migrations/*.sql
@@ -32,6 +36,14 @@ type Config struct {
 	MinConns        int32
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
+	// HealthCheckPeriod is how often idle connections are checked; zero keeps
+	// the pgxpool default
+	HealthCheckPeriod time.Duration
+	// StatementTimeout is set as the session's statement_timeout, so the
+	// server cancels any statement running longer; zero disables
+	StatementTimeout time.Duration
+	FaultInjector    *chaos.Injector // Resilience testing only; nil disables
+	Tracer           pgx.QueryTracer // Traces every query; nil disables
 }
 
 // New creates a new database connection pool using pgxpool
@@ -49,6 +61,18 @@ func New(cfg Config, logger *slog.Logger) (*DB, error) {
 	poolConfig.MinConns = cfg.MinConns
 	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
 	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	if cfg.FaultInjector != nil {
+		cfg.FaultInjector.Install(poolConfig)
+	}
+	if cfg.Tracer != nil {
+		poolConfig.ConnConfig.Tracer = cfg.Tracer
+	}
 
 	// Create pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -71,6 +95,46 @@ func New(cfg Config, logger *slog.Logger) (*DB, error) {
 	return db, nil
 }
 
+// WithTimeout bounds a query's context by timeout, so a slow query gives its
+// connection back to the pool instead of holding it. A timeout of zero or less
+// returns ctx unchanged.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// LiftStatementTimeout lifts Config.StatementTimeout for the rest of tx, for
+// long-running work like import merges and data exports
+func LiftStatementTimeout(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to lift statement timeout: %w", err)
+	}
+	return nil
+}
+
+// WithoutStatementTimeout runs fn on a connection without Config.StatementTimeout,
+// for long-running commands that can't run in a transaction, like VACUUM. The
+// timeout is restored before the connection goes back to the pool, or the
+// connection is closed if that fails.
+func WithoutStatementTimeout(ctx context.Context, pool *pgxpool.Pool, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SET statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to lift statement timeout: %w", err)
+	}
+	fnErr := fn(conn)
+	if _, err := conn.Exec(context.WithoutCancel(ctx), "RESET statement_timeout"); err != nil {
+		_ = conn.Conn().Close(context.WithoutCancel(ctx))
+	}
+	return fnErr
+}
+
 // WaitForDB waits for the database connection pool to be available
 func (d *DB) WaitForDB(ctx context.Context) bool {
 	maxAttempts := defaultRetries
@@ -0,0 +1,82 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Run against any Postgres database:
+//
+//	TEST_DATABASE_URL=... go test -tags integration -run StatementTimeout ./pkg/db/
+
+// timeoutPool connects with a statement timeout short enough to trip on purpose
+func timeoutPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	database, err := New(Config{
+		DSN:              dsn,
+		MaxConns:         1, // Every call gets the same connection back
+		StatementTimeout: 50 * time.Millisecond,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(database.Close)
+	return database.Pool
+}
+
+func TestStatementTimeout_CancelsSlowStatements(t *testing.T) {
+	pool := timeoutPool(t)
+	if _, err := pool.Exec(context.Background(), "SELECT pg_sleep(0.2)"); err == nil {
+		t.Fatalf("expected the pool's statement timeout to cancel a slow statement")
+	}
+}
+
+func TestWithoutStatementTimeout(t *testing.T) {
+	pool := timeoutPool(t)
+	ctx := context.Background()
+
+	err := WithoutStatementTimeout(ctx, pool, func(conn *pgxpool.Conn) error {
+		_, err := conn.Exec(ctx, "SELECT pg_sleep(0.2)")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("expected a slow statement to finish without the timeout, got %v", err)
+	}
+
+	var timeout string
+	if err := pool.QueryRow(ctx, "SHOW statement_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("failed to show statement timeout: %v", err)
+	}
+	if timeout != "50ms" {
+		t.Fatalf("expected the connection to get its timeout back, got %q", timeout)
+	}
+}
+
+func TestLiftStatementTimeout(t *testing.T) {
+	pool := timeoutPool(t)
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := LiftStatementTimeout(ctx, tx); err != nil {
+		t.Fatalf("LiftStatementTimeout: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_sleep(0.2)"); err != nil {
+		t.Fatalf("expected a slow statement to finish in the transaction, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package featureflags turns risky features on for a subset of users. A flag
+// is on for a user when, in order of precedence:
+//
+//   - the user has an override, which wins either way
+//   - the flag is enabled for everyone
+//   - the user's role is in the flag's cohort roles
+//   - the user falls in the flag's percentage rollout
+//
+// Flags are read from a cache refreshed in the background of requests, so a
+// change made on another server shows up within the cache TTL.
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Flags gating features still being rolled out
+const (
+	AggregatorSync = "aggregator_sync"
+	MLAnalyzer     = "ml_analyzer"
+	AIAssistant    = "ai_assistant"
+	DemoData       = "demo_data"
+)
+
+var (
+	// ErrAdminRequired is returned when a non-admin changes or lists flags
+	ErrAdminRequired = errors.New("feature flags are only managed by admins")
+	// ErrFlagNotFound is returned when overriding a flag that doesn't exist
+	ErrFlagNotFound = errors.New("feature flag not found")
+	// ErrInvalidFlag is returned for flags with an empty key or a rollout outside 0-100
+	ErrInvalidFlag = errors.New("feature flag key is required and rollout must be between 0 and 100")
+)
+
+// Flag is a feature flag and the rules turning it on
+type Flag struct {
+	Key            string
+	Description    string
+	Enabled        bool     // On for everyone
+	Roles          []string // On for users with any of these roles
+	RolloutPercent int      // On for this share of users, picked by a stable hash of the user ID
+	UpdatedBy      *uuid.UUID
+	UpdatedAt      time.Time
+}
+
+// Override forces a flag on or off for one user
+type Override struct {
+	FlagKey string
+	UserID  uuid.UUID
+	Enabled bool
+}
+
+// Store persists flags and overrides
+type Store interface {
+	// ListFlags returns every flag
+	ListFlags(ctx context.Context) ([]*Flag, error)
+	// ListOverrides returns every per-user override
+	ListOverrides(ctx context.Context) ([]*Override, error)
+	// UpsertFlag creates or replaces a flag's rules
+	UpsertFlag(ctx context.Context, flag *Flag) error
+	// SetOverride forces a flag for a user; nil enabled removes the override.
+	// It returns ErrFlagNotFound for unknown flags.
+	SetOverride(ctx context.Context, flagKey string, userID uuid.UUID, enabled *bool) error
+}
+
+// Evaluated holds the flags evaluated for the caller of a request
+type Evaluated map[string]bool
+
+type contextKey struct{}
+
+// WithEvaluated returns a context carrying evaluated flags
+func WithEvaluated(ctx context.Context, flags Evaluated) context.Context {
+	return context.WithValue(ctx, contextKey{}, flags)
+}
+
+// FromContext returns the flags evaluated for the request's caller, or nil
+// outside requests the interceptor has seen
+func FromContext(ctx context.Context) Evaluated {
+	flags, _ := ctx.Value(contextKey{}).(Evaluated)
+	return flags
+}
+
+// Enabled reports whether key is on for the request's caller. Flags are off
+// for unknown keys and outside requests.
+func Enabled(ctx context.Context, key string) bool {
+	return FromContext(ctx)[key]
+}
@@ -0,0 +1,58 @@
+package featureflags
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// Interceptor evaluates the caller's flags once per request and puts them in
+// the context for handlers to read with Enabled. It must run after
+// authentication; unauthenticated callers get the flags enabled for everyone.
+type Interceptor struct {
+	service *Service
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor creates an interceptor evaluating flags with service
+func NewInterceptor(service *Service) *Interceptor {
+	return &Interceptor{service: service}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		return next(i.evaluate(ctx), req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(i.evaluate(ctx), conn)
+	}
+}
+
+func (i *Interceptor) evaluate(ctx context.Context) context.Context {
+	var userID *uuid.UUID
+	var role string
+	if claims, err := interceptors.GetClaimsFromContext(ctx); err == nil {
+		if id, err := uuid.Parse(claims.UserID); err == nil {
+			userID = &id
+			role = claims.Role
+		}
+	}
+	return WithEvaluated(ctx, i.service.Evaluate(ctx, userID, role))
+}
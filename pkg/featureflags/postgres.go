@@ -0,0 +1,115 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore implements Store using PostgreSQL
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore creates a new PostgreSQL feature flag store
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// ListFlags returns every flag, by key
+func (s *PostgresStore) ListFlags(ctx context.Context) ([]*Flag, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT key, description, enabled, roles, rollout_percent, updated_by, updated_at
+		FROM feature_flags
+		ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []*Flag
+	for rows.Next() {
+		f := &Flag{}
+		var rollout int16
+		if err := rows.Scan(&f.Key, &f.Description, &f.Enabled, &f.Roles, &rollout, &f.UpdatedBy, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		f.RolloutPercent = int(rollout)
+		flags = append(flags, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	return flags, nil
+}
+
+// ListOverrides returns every per-user override
+func (s *PostgresStore) ListOverrides(ctx context.Context) ([]*Override, error) {
+	rows, err := s.pool.Query(ctx, `SELECT flag_key, user_id, enabled FROM feature_flag_overrides`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*Override
+	for rows.Next() {
+		o := &Override{}
+		if err := rows.Scan(&o.FlagKey, &o.UserID, &o.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag override: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// UpsertFlag creates or replaces a flag's rules
+func (s *PostgresStore) UpsertFlag(ctx context.Context, f *Flag) error {
+	roles := f.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO feature_flags (key, description, enabled, roles, rollout_percent, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (key) DO UPDATE
+		SET description = EXCLUDED.description, enabled = EXCLUDED.enabled, roles = EXCLUDED.roles,
+			rollout_percent = EXCLUDED.rollout_percent, updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`,
+		f.Key, f.Description, f.Enabled, roles, f.RolloutPercent, f.UpdatedBy, f.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert feature flag: %w", err)
+	}
+	return nil
+}
+
+// SetOverride forces a flag for a user, or removes the override when enabled is nil
+func (s *PostgresStore) SetOverride(ctx context.Context, flagKey string, userID uuid.UUID, enabled *bool) error {
+	if enabled == nil {
+		_, err := s.pool.Exec(ctx, `
+			DELETE FROM feature_flag_overrides WHERE flag_key = $1 AND user_id = $2`, flagKey, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete feature flag override: %w", err)
+		}
+		return nil
+	}
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO feature_flag_overrides (flag_key, user_id, enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (flag_key, user_id) DO UPDATE SET enabled = EXCLUDED.enabled`,
+		flagKey, userID, *enabled)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "feature_flag_overrides_flag_key_fkey" {
+		return ErrFlagNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+	return nil
+}
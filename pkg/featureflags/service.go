@@ -0,0 +1,279 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// DefaultCacheTTL is how long flags are served from memory before reloading
+const DefaultCacheTTL = 30 * time.Second
+
+// snapshot is the flags and overrides loaded at one time
+type snapshot struct {
+	flags     []*Flag
+	overrides map[string]map[uuid.UUID]bool // By flag key, then user
+	loadedAt  time.Time
+}
+
+// Service evaluates flags from a cached copy of the store and lets admins
+// change them
+type Service struct {
+	store        Store
+	logger       *slog.Logger
+	ttl          time.Duration
+	now          func() time.Time
+	entitlements Entitlements    // Optional: nil leaves no flag gated by plan
+	gated        map[string]bool // Flags only on for users whose plan includes them
+
+	mu      sync.RWMutex
+	current *snapshot
+
+	reloadMu sync.Mutex // Lets one caller reload a stale snapshot at a time
+}
+
+// NewService creates a feature flag service caching flags for DefaultCacheTTL
+func NewService(store Store, logger *slog.Logger) *Service {
+	return &Service{
+		store:  store,
+		logger: logger,
+		ttl:    DefaultCacheTTL,
+		now:    time.Now,
+	}
+}
+
+// WithCacheTTL sets how long flags are served from memory
+func (s *Service) WithCacheTTL(ttl time.Duration) *Service {
+	s.ttl = ttl
+	return s
+}
+
+// Entitlements tells which paid features a user's plan includes (the billing
+// service)
+type Entitlements interface {
+	// Entitled returns the features included in the user's plan
+	Entitled(ctx context.Context, userID uuid.UUID) (map[string]bool, error)
+}
+
+// WithEntitlements keeps the gated flags off for users whose plan doesn't
+// include them, whatever the flags' rules say. Overrides still win, so a flag
+// can be turned on for a user on any plan.
+func (s *Service) WithEntitlements(entitlements Entitlements, gated ...string) *Service {
+	s.entitlements = entitlements
+	s.gated = make(map[string]bool, len(gated))
+	for _, key := range gated {
+		s.gated[key] = true
+	}
+	return s
+}
+
+// Evaluate returns every flag's state for a user with role. A nil userID
+// evaluates for anonymous callers, who only get flags enabled for everyone.
+func (s *Service) Evaluate(ctx context.Context, userID *uuid.UUID, role string) Evaluated {
+	snap := s.snapshot(ctx)
+	if snap == nil {
+		return Evaluated{}
+	}
+	evaluated := make(Evaluated, len(snap.flags))
+	var entitled map[string]bool
+	loaded := false
+	for _, flag := range snap.flags {
+		on := evaluate(flag, snap.overrides[flag.Key], userID, role)
+		if on && s.gated[flag.Key] && !overridden(snap.overrides[flag.Key], userID) {
+			if !loaded {
+				entitled, loaded = s.entitled(ctx, userID), true
+			}
+			on = entitled[flag.Key]
+		}
+		evaluated[flag.Key] = on
+	}
+	return evaluated
+}
+
+// entitled returns the features of the user's plan. Gated flags stay off for
+// anonymous callers and when the plan can't be read.
+func (s *Service) entitled(ctx context.Context, userID *uuid.UUID) map[string]bool {
+	if userID == nil || s.entitlements == nil {
+		return nil
+	}
+	entitled, err := s.entitlements.Entitled(ctx, *userID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load entitlements", slog.Any("error", err))
+		return nil
+	}
+	return entitled
+}
+
+func overridden(overrides map[uuid.UUID]bool, userID *uuid.UUID) bool {
+	if userID == nil {
+		return false
+	}
+	_, ok := overrides[*userID]
+	return ok
+}
+
+func evaluate(flag *Flag, overrides map[uuid.UUID]bool, userID *uuid.UUID, role string) bool {
+	if userID != nil {
+		if enabled, ok := overrides[*userID]; ok {
+			return enabled
+		}
+	}
+	if flag.Enabled {
+		return true
+	}
+	if userID == nil {
+		return false
+	}
+	if role != "" && slices.Contains(flag.Roles, role) {
+		return true
+	}
+	return flag.RolloutPercent > 0 && rolloutBucket(flag.Key, *userID) < flag.RolloutPercent
+}
+
+// rolloutBucket places a user in 0-99 per flag, so raising a rollout keeps
+// the users it already had and flags don't all pick the same users
+func rolloutBucket(key string, userID uuid.UUID) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write(userID[:])
+	return int(h.Sum32() % 100)
+}
+
+// snapshot returns the cached flags, reloading them once stale. A failed
+// reload keeps serving the previous flags; with none loaded yet, every flag
+// is off.
+func (s *Service) snapshot(ctx context.Context) *snapshot {
+	s.mu.RLock()
+	snap := s.current
+	s.mu.RUnlock()
+	if snap != nil && s.now().Sub(snap.loadedAt) < s.ttl {
+		return snap
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.mu.RLock()
+	if s.current != snap {
+		// Another caller reloaded while this one waited
+		snap = s.current
+		s.mu.RUnlock()
+		return snap
+	}
+	s.mu.RUnlock()
+
+	loaded, err := s.load(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to load feature flags", slog.Any("error", err))
+		return snap
+	}
+	s.mu.Lock()
+	s.current = loaded
+	s.mu.Unlock()
+	return loaded
+}
+
+func (s *Service) load(ctx context.Context) (*snapshot, error) {
+	flags, err := s.store.ListFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.store.ListOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byFlag := make(map[string]map[uuid.UUID]bool)
+	for _, o := range overrides {
+		if byFlag[o.FlagKey] == nil {
+			byFlag[o.FlagKey] = make(map[uuid.UUID]bool)
+		}
+		byFlag[o.FlagKey][o.UserID] = o.Enabled
+	}
+	return &snapshot{flags: flags, overrides: byFlag, loadedAt: s.now()}, nil
+}
+
+// invalidate makes the next evaluation reload the flags
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	if s.current != nil {
+		stale := *s.current
+		stale.loadedAt = time.Time{}
+		s.current = &stale
+	}
+	s.mu.Unlock()
+}
+
+// ListFlags returns every flag, read from the store. Only admins may list them.
+func (s *Service) ListFlags(ctx context.Context) ([]*Flag, error) {
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.store.ListFlags(ctx)
+}
+
+// SetFlag creates or replaces a flag's rules. Only admins may set flags.
+func (s *Service) SetFlag(ctx context.Context, flag *Flag) error {
+	adminID, err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if flag.Key == "" || flag.RolloutPercent < 0 || flag.RolloutPercent > 100 {
+		return ErrInvalidFlag
+	}
+	flag.UpdatedBy = adminID
+	flag.UpdatedAt = s.now()
+	if err := s.store.UpsertFlag(ctx, flag); err != nil {
+		return fmt.Errorf("failed to set feature flag: %w", err)
+	}
+	s.invalidate()
+	return nil
+}
+
+// SetOverride forces a flag on or off for a user; nil enabled returns the user
+// to the flag's rules. Only admins may set overrides.
+func (s *Service) SetOverride(ctx context.Context, flagKey string, userID uuid.UUID, enabled *bool) error {
+	if _, err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	if err := s.store.SetOverride(ctx, flagKey, userID, enabled); err != nil {
+		return fmt.Errorf("failed to set feature flag override: %w", err)
+	}
+	s.invalidate()
+	return nil
+}
+
+// requireAdmin returns the calling admin's ID, if the token carries a valid one
+func requireAdmin(ctx context.Context) (*uuid.UUID, error) {
+	claims, err := interceptors.GetClaimsFromContext(ctx)
+	if err != nil || claims.Role != "admin" {
+		return nil, ErrAdminRequired
+	}
+	if id, err := uuid.Parse(claims.UserID); err == nil {
+		return &id, nil
+	}
+	return nil, nil
+}
+
+// ============================================================================
+// Feature Flags (Internal Integration)
+// ============================================================================
+// Handlers read the caller's flags with Enabled; the interceptor evaluates
+// them once per request. Managing flags is available on the service but
+// requires proto definitions on the admin service:
+//
+// - ListFeatureFlags: every flag and its rules (admin only)
+// - SetFeatureFlag: create or change a flag's rules (admin only)
+// - SetFeatureFlagOverride: force a flag for one user, or clear it (admin only)
+//
+// To expose as API endpoints, add the following proto definitions:
+// - AdminListFeatureFlagsRequest/Response (AdminUserService.ListFeatureFlags)
+// - AdminSetFeatureFlagRequest/Response (AdminUserService.SetFeatureFlag)
+// - AdminSetFeatureFlagOverrideRequest/Response (AdminUserService.SetFeatureFlagOverride)
+// - FeatureFlag (key, description, enabled, roles, rollout_percent, updated_by, updated_at)
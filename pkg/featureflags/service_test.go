@@ -0,0 +1,138 @@
+package featureflags
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps flags in memory, counting loads
+type memoryStore struct {
+	flags     []*Flag
+	overrides []*Override
+	loads     int
+}
+
+func (s *memoryStore) ListFlags(context.Context) ([]*Flag, error) {
+	s.loads++
+	return s.flags, nil
+}
+
+func (s *memoryStore) ListOverrides(context.Context) ([]*Override, error) {
+	return s.overrides, nil
+}
+
+func (s *memoryStore) UpsertFlag(_ context.Context, flag *Flag) error {
+	s.flags = append(s.flags, flag)
+	return nil
+}
+
+func (s *memoryStore) SetOverride(_ context.Context, flagKey string, userID uuid.UUID, enabled *bool) error {
+	s.overrides = append(s.overrides, &Override{FlagKey: flagKey, UserID: userID, Enabled: *enabled})
+	return nil
+}
+
+func TestEvaluate(t *testing.T) {
+	optedOut, beta := uuid.New(), uuid.New()
+	store := &memoryStore{
+		flags: []*Flag{
+			{Key: AIAssistant, Enabled: true},
+			{Key: MLAnalyzer, Roles: []string{"beta"}},
+			{Key: AggregatorSync, RolloutPercent: 100},
+			{Key: "new_dashboard"},
+		},
+		overrides: []*Override{
+			{FlagKey: AIAssistant, UserID: optedOut, Enabled: false},
+			{FlagKey: "new_dashboard", UserID: beta, Enabled: true},
+		},
+	}
+	svc := NewService(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	assert.Equal(t, Evaluated{AIAssistant: false, MLAnalyzer: false, AggregatorSync: true, "new_dashboard": false},
+		svc.Evaluate(ctx, &optedOut, "member"), "an override wins over a flag on for everyone")
+	assert.Equal(t, Evaluated{AIAssistant: true, MLAnalyzer: true, AggregatorSync: true, "new_dashboard": true},
+		svc.Evaluate(ctx, &beta, "beta"))
+	assert.Equal(t, Evaluated{AIAssistant: true, MLAnalyzer: false, AggregatorSync: false, "new_dashboard": false},
+		svc.Evaluate(ctx, nil, ""), "anonymous callers only get flags on for everyone")
+}
+
+func TestEvaluate_CachesUntilTTL(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := uuid.New()
+	store := &memoryStore{flags: []*Flag{{Key: MLAnalyzer}}}
+	svc := NewService(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.False(t, svc.Evaluate(ctx, &user, "member")[MLAnalyzer])
+	store.overrides = []*Override{{FlagKey: MLAnalyzer, UserID: user, Enabled: true}}
+	assert.False(t, svc.Evaluate(ctx, &user, "member")[MLAnalyzer], "served from cache")
+
+	now = now.Add(DefaultCacheTTL)
+	assert.True(t, svc.Evaluate(ctx, &user, "member")[MLAnalyzer])
+	assert.Equal(t, 2, store.loads)
+}
+
+// planEntitlements includes features for users on a paid plan
+type planEntitlements struct {
+	paid  map[uuid.UUID]bool
+	calls int
+}
+
+func (e *planEntitlements) Entitled(_ context.Context, userID uuid.UUID) (map[string]bool, error) {
+	e.calls++
+	return map[string]bool{AggregatorSync: e.paid[userID]}, nil
+}
+
+func TestEvaluate_Entitlements(t *testing.T) {
+	free, paid, tester := uuid.New(), uuid.New(), uuid.New()
+	store := &memoryStore{
+		flags: []*Flag{
+			{Key: AggregatorSync, Enabled: true},
+			{Key: MLAnalyzer, Enabled: true},
+		},
+		overrides: []*Override{{FlagKey: AggregatorSync, UserID: tester, Enabled: true}},
+	}
+	entitlements := &planEntitlements{paid: map[uuid.UUID]bool{paid: true}}
+	svc := NewService(store, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithEntitlements(entitlements, AggregatorSync)
+	ctx := context.Background()
+
+	assert.Equal(t, Evaluated{AggregatorSync: false, MLAnalyzer: true}, svc.Evaluate(ctx, &free, "member"))
+	assert.Equal(t, Evaluated{AggregatorSync: true, MLAnalyzer: true}, svc.Evaluate(ctx, &paid, "member"))
+	assert.Equal(t, Evaluated{AggregatorSync: true, MLAnalyzer: true}, svc.Evaluate(ctx, &tester, "member"),
+		"an override wins over the plan")
+	assert.Equal(t, Evaluated{AggregatorSync: false, MLAnalyzer: true}, svc.Evaluate(ctx, nil, ""))
+	assert.Equal(t, 2, entitlements.calls, "only loaded for gated flags without an override")
+}
+
+func TestRolloutBucket_Stable(t *testing.T) {
+	inRollout := 0
+	for range 1000 {
+		bucket := rolloutBucket(MLAnalyzer, uuid.New())
+		require.GreaterOrEqual(t, bucket, 0)
+		require.Less(t, bucket, 100)
+		if bucket < 25 {
+			inRollout++
+		}
+	}
+	assert.InDelta(t, 250, inRollout, 60, "a 25% rollout reaches about a quarter of users")
+
+	user := uuid.New()
+	assert.Equal(t, rolloutBucket(MLAnalyzer, user), rolloutBucket(MLAnalyzer, user))
+}
+
+func TestSetFlag_AdminOnly(t *testing.T) {
+	store := &memoryStore{}
+	svc := NewService(store, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err := svc.SetFlag(context.Background(), &Flag{Key: AIAssistant, Enabled: true})
+	assert.ErrorIs(t, err, ErrAdminRequired)
+	assert.Empty(t, store.flags)
+}
@@ -0,0 +1,104 @@
+package filescan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultClamAVTimeout bounds a whole scan, connection included
+	DefaultClamAVTimeout = 30 * time.Second
+	// clamAVChunkSize is the size of the chunks content is streamed to clamd in
+	clamAVChunkSize = 64 << 10
+)
+
+// ClamAV scans content with a clamd daemon over its INSTREAM command
+type ClamAV struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+var _ Antivirus = (*ClamAV)(nil)
+
+// NewClamAV creates a clamd client for address, either "host:port" or a
+// unix socket path
+func NewClamAV(address string) *ClamAV {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &ClamAV{network: network, address: address, timeout: DefaultClamAVTimeout}
+}
+
+// WithTimeout sets how long a scan may take
+func (c *ClamAV) WithTimeout(timeout time.Duration) *ClamAV {
+	c.timeout = timeout
+	return c
+}
+
+// Scan streams data to clamd and returns the matched signature, if any
+func (c *ClamAV) Scan(ctx context.Context, data []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return "", fmt.Errorf("failed to send to clamd: %w", err)
+	}
+	var size [4]byte
+	for chunk := range slices.Chunk(data, clamAVChunkSize) {
+		binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+		if _, err := w.Write(size[:]); err != nil {
+			return "", fmt.Errorf("failed to send to clamd: %w", err)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return "", fmt.Errorf("failed to send to clamd: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := w.Write(size[:]); err != nil {
+		return "", fmt.Errorf("failed to send to clamd: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to send to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamAVReply(string(bytes.TrimRight(reply, "\x00")))
+}
+
+// parseClamAVReply reads "stream: OK", "stream: <signature> FOUND" or
+// "<message> ERROR"
+func parseClamAVReply(reply string) (string, error) {
+	reply = strings.TrimSpace(reply)
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return signature, nil
+	case strings.HasSuffix(reply, ": OK"):
+		return "", nil
+	default:
+		return "", fmt.Errorf("clamd: %s", reply)
+	}
+}
@@ -0,0 +1,306 @@
+// Package filescan checks uploaded files before anything parses them: the
+// content must match the declared file type by its magic bytes and fit the
+// size limit, spreadsheets lose their VBA macros, and an optional antivirus
+// (ClamAV) looks for known malware.
+package filescan
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Status is the outcome of a scan, as stored on user_files.scan_status
+type Status string
+
+const (
+	// StatusPending files haven't been scanned yet, usually because the
+	// antivirus was unavailable. Importers refuse them until a rescan.
+	StatusPending Status = "pending"
+	// StatusClean files passed every check
+	StatusClean Status = "clean"
+	// StatusQuarantined files carry malware or macros that can't be removed.
+	// They are kept for review but never processed.
+	StatusQuarantined Status = "quarantined"
+)
+
+var (
+	// ErrEmptyFile is returned for files with no content
+	ErrEmptyFile = errors.New("file is empty")
+	// ErrFileTooLarge is returned for files over the scanner's size limit
+	ErrFileTooLarge = errors.New("file is too large")
+	// ErrTypeMismatch is returned when a file's content doesn't match its
+	// declared type
+	ErrTypeMismatch = errors.New("file content doesn't match its type")
+	// ErrQuarantined is returned when processing a quarantined file
+	ErrQuarantined = errors.New("file is quarantined")
+	// ErrNotScanned is returned when processing a file still pending a scan
+	ErrNotScanned = errors.New("file hasn't been scanned yet")
+)
+
+// Antivirus scans content for malware
+type Antivirus interface {
+	// Scan returns the name of the signature data matched, or "" if it is clean
+	Scan(ctx context.Context, data []byte) (string, error)
+}
+
+// Result is the outcome of scanning a file
+type Result struct {
+	Status   Status
+	Detail   string // Why the file was quarantined or left pending
+	MimeType string // Detected from the content
+	Data     []byte // The content to store, with macros removed
+	// MacrosStripped is set when VBA macros were removed, so Data differs
+	// from the upload
+	MacrosStripped bool
+}
+
+// Scanner checks uploaded files
+type Scanner struct {
+	maxBytes int64
+	av       Antivirus
+}
+
+// New creates a scanner accepting files up to maxBytes
+func New(maxBytes int64) *Scanner {
+	return &Scanner{maxBytes: maxBytes}
+}
+
+// WithAntivirus adds a malware scan. Without one, files are clean once they
+// pass the type and size checks.
+func (s *Scanner) WithAntivirus(av Antivirus) *Scanner {
+	s.av = av
+	return s
+}
+
+// Scan checks data declared as fileType ("csv", "json", "xlsx", "pdf" or
+// "image"). Files that are empty, too large or not what they claim to be are
+// rejected with an error and shouldn't be stored. Otherwise the result says
+// whether the file can be processed.
+func (s *Scanner) Scan(ctx context.Context, fileType string, data []byte) (*Result, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptyFile
+	}
+	if s.maxBytes > 0 && int64(len(data)) > s.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrFileTooLarge, len(data), s.maxBytes)
+	}
+	mimeType, err := sniff(fileType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Status: StatusClean, MimeType: mimeType, Data: data}
+	if fileType == "xlsx" {
+		if err := stripMacros(result); err != nil {
+			return nil, err
+		}
+		if result.Status == StatusQuarantined {
+			return result, nil
+		}
+	}
+
+	if s.av != nil {
+		signature, err := s.av.Scan(ctx, result.Data)
+		switch {
+		case err != nil:
+			result.Status = StatusPending
+			result.Detail = fmt.Sprintf("antivirus unavailable: %v", err)
+		case signature != "":
+			result.Status = StatusQuarantined
+			result.Detail = "malware detected: " + signature
+		}
+	}
+	return result, nil
+}
+
+// =============================================================================
+// Magic bytes
+// =============================================================================
+
+const xlsxMimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+var signatures = []struct {
+	prefix   string
+	offset   int
+	mimeType string
+}{
+	{"%PDF-", 0, "application/pdf"},
+	{"\xFF\xD8\xFF", 0, "image/jpeg"},
+	{"\x89PNG\r\n\x1a\n", 0, "image/png"},
+	{"GIF87a", 0, "image/gif"},
+	{"GIF89a", 0, "image/gif"},
+	{"WEBP", 8, "image/webp"},
+	{"ftypheic", 4, "image/heic"},
+	{"ftypheix", 4, "image/heic"},
+	{"ftypmif1", 4, "image/heif"},
+	{"PK\x03\x04", 0, "application/zip"},
+	{"\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1", 0, "application/x-ole-storage"}, // Legacy .xls/.doc
+	{"MZ", 0, "application/x-msdownload"},
+	{"\x7FELF", 0, "application/x-executable"},
+	{"\x1F\x8B", 0, "application/gzip"},
+}
+
+// detect returns the MIME type of a known binary format, or "" for anything
+// else, which is treated as text
+func detect(data []byte) string {
+	for _, sig := range signatures {
+		end := sig.offset + len(sig.prefix)
+		if len(data) >= end && string(data[sig.offset:end]) == sig.prefix {
+			return sig.mimeType
+		}
+	}
+	return ""
+}
+
+// sniff checks data is a fileType and returns its MIME type
+func sniff(fileType string, data []byte) (string, error) {
+	detected := detect(data)
+	mismatch := func() (string, error) {
+		if detected == "" {
+			detected = "text"
+		}
+		return "", fmt.Errorf("%w: declared %s, found %s", ErrTypeMismatch, fileType, detected)
+	}
+
+	switch fileType {
+	case "csv", "json":
+		// Bank exports come in many text encodings, so anything that isn't a
+		// known binary format passes; the parsers reject what they can't read
+		if detected != "" {
+			return mismatch()
+		}
+		if fileType == "json" {
+			return "application/json", nil
+		}
+		return "text/csv", nil
+	case "xlsx":
+		if detected != "application/zip" || !isWorkbook(data) {
+			return mismatch()
+		}
+		return xlsxMimeType, nil
+	case "pdf":
+		if detected != "application/pdf" {
+			return mismatch()
+		}
+		return detected, nil
+	case "image":
+		if !strings.HasPrefix(detected, "image/") {
+			return mismatch()
+		}
+		return detected, nil
+	default:
+		return "", fmt.Errorf("%w: unknown type %q", ErrTypeMismatch, fileType)
+	}
+}
+
+// isWorkbook reports whether a zip archive is an Office Open XML workbook
+func isWorkbook(data []byte) bool {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+	var contentTypes, workbook bool
+	for _, f := range zr.File {
+		switch f.Name {
+		case "[Content_Types].xml":
+			contentTypes = true
+		case "xl/workbook.xml":
+			workbook = true
+		}
+	}
+	return contentTypes && workbook
+}
+
+// =============================================================================
+// Macros
+// =============================================================================
+
+const (
+	macroEnabledMainType = "application/vnd.ms-excel.sheet.macroEnabled.main+xml"
+	workbookMainType     = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"
+)
+
+var (
+	// vbaPart matches the parts holding a workbook's VBA project
+	vbaPart = regexp.MustCompile(`(?i)^xl/(vbaProject\.bin|vbaProjectSignature\.bin|vbaData\.xml|_rels/vbaProject\.bin\.rels)$`)
+	// vbaReference matches the relationships, overrides and defaults naming them
+	vbaReference = regexp.MustCompile(`(?i)<(Relationship|Override|Default)\b[^>]*(vbaProject|vbaData)[^>]*/>`)
+)
+
+// stripMacros removes the VBA project from a workbook, turning an .xlsm into
+// a plain .xlsx. Excel 4.0 macro sheets are part of the workbook structure
+// and can't be cut out safely, so those files are quarantined instead.
+func stripMacros(result *Result) error {
+	zr, err := zip.NewReader(bytes.NewReader(result.Data), int64(len(result.Data)))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+	}
+
+	var hasVBA bool
+	for _, f := range zr.File {
+		name := strings.ToLower(f.Name)
+		if strings.HasPrefix(name, "xl/macrosheets/") {
+			result.Status = StatusQuarantined
+			result.Detail = "workbook contains Excel 4.0 macro sheets"
+			return nil
+		}
+		if vbaPart.MatchString(f.Name) {
+			hasVBA = true
+		}
+	}
+	if !hasVBA {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		if vbaPart.MatchString(f.Name) {
+			continue
+		}
+		if err := copyPart(zw, f); err != nil {
+			return fmt.Errorf("failed to strip macros: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to strip macros: %w", err)
+	}
+
+	result.Data = buf.Bytes()
+	result.MacrosStripped = true
+	return nil
+}
+
+// copyPart copies a zip entry, dropping references to the VBA project from
+// content types and relationships
+func copyPart(zw *zip.Writer, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	header := f.FileHeader
+	w, err := zw.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	if f.Name != "[Content_Types].xml" && !strings.HasSuffix(f.Name, ".rels") {
+		_, err = io.Copy(w, rc)
+		return err
+	}
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	content = vbaReference.ReplaceAll(content, nil)
+	content = bytes.ReplaceAll(content, []byte(macroEnabledMainType), []byte(workbookMainType))
+	_, err = w.Write(content)
+	return err
+}
@@ -0,0 +1,200 @@
+package filescan
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workbook builds a zip with the given parts
+func workbook(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func readParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		parts[f.Name] = string(content)
+	}
+	return parts
+}
+
+type fakeAntivirus struct {
+	signature string
+	err       error
+}
+
+func (f *fakeAntivirus) Scan(context.Context, []byte) (string, error) {
+	return f.signature, f.err
+}
+
+func TestScan_TypeAndSize(t *testing.T) {
+	s := New(1000)
+	ctx := context.Background()
+	xlsx := workbook(t, map[string]string{"[Content_Types].xml": "<Types/>", "xl/workbook.xml": "<workbook/>"})
+
+	tests := []struct {
+		name     string
+		fileType string
+		data     []byte
+		mimeType string
+		err      error
+	}{
+		{"csv", "csv", []byte("Date,Amount\n01/02/2024,-2.50\n"), "text/csv", nil},
+		{"latin-1 csv", "csv", []byte("Descri\xe7\xe3o;Valor\n"), "text/csv", nil},
+		{"json", "json", []byte(`[{"amount": 1}]`), "application/json", nil},
+		{"png", "image", []byte("\x89PNG\r\n\x1a\n...."), "image/png", nil},
+		{"pdf", "pdf", []byte("%PDF-1.7 ..."), "application/pdf", nil},
+		{"empty", "csv", nil, "", ErrEmptyFile},
+		{"too large", "csv", bytes.Repeat([]byte("a"), 1001), "", ErrFileTooLarge},
+		{"executable as csv", "csv", []byte("MZ\x90\x00 program"), "", ErrTypeMismatch},
+		{"pdf as image", "image", []byte("%PDF-1.7 ..."), "", ErrTypeMismatch},
+		{"text as xlsx", "xlsx", []byte("Date,Amount\n"), "", ErrTypeMismatch},
+		{"zip as xlsx", "xlsx", workbook(t, map[string]string{"a.txt": "a"}), "", ErrTypeMismatch},
+		{"unknown type", "exe", []byte("hello"), "", ErrTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Scan(ctx, tt.fileType, tt.data)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, StatusClean, result.Status)
+			assert.Equal(t, tt.mimeType, result.MimeType)
+		})
+	}
+
+	result, err := New(0).Scan(ctx, "xlsx", xlsx)
+	require.NoError(t, err)
+	assert.Equal(t, xlsxMimeType, result.MimeType)
+	assert.False(t, result.MacrosStripped)
+	assert.Equal(t, xlsx, result.Data)
+}
+
+func TestScan_StripsVBAMacros(t *testing.T) {
+	xlsm := workbook(t, map[string]string{
+		"[Content_Types].xml": `<Types><Default Extension="bin" ContentType="application/vnd.ms-office.vbaProject"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="` + macroEnabledMainType + `"/></Types>`,
+		"xl/workbook.xml": "<workbook/>",
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId9" Type="http://schemas.microsoft.com/office/2006/relationships/vbaProject" Target="vbaProject.bin"/></Relationships>`,
+		"xl/worksheets/sheet1.xml": "<worksheet/>",
+		"xl/vbaProject.bin":        "Attribute VB_Name = \"Module1\"",
+	})
+
+	result, err := New(0).Scan(context.Background(), "xlsx", xlsm)
+	require.NoError(t, err)
+	assert.Equal(t, StatusClean, result.Status)
+	assert.True(t, result.MacrosStripped)
+
+	parts := readParts(t, result.Data)
+	assert.NotContains(t, parts, "xl/vbaProject.bin")
+	assert.Equal(t, "<worksheet/>", parts["xl/worksheets/sheet1.xml"])
+	assert.NotContains(t, parts["xl/_rels/workbook.xml.rels"], "vbaProject")
+	assert.Contains(t, parts["xl/_rels/workbook.xml.rels"], "worksheets/sheet1.xml")
+	assert.NotContains(t, parts["[Content_Types].xml"], "vbaProject")
+	assert.Contains(t, parts["[Content_Types].xml"], workbookMainType)
+}
+
+func TestScan_Quarantine(t *testing.T) {
+	ctx := context.Background()
+	csv := []byte("Date,Amount\n")
+
+	result, err := New(0).WithAntivirus(&fakeAntivirus{signature: "Eicar-Test-Signature"}).Scan(ctx, "csv", csv)
+	require.NoError(t, err)
+	assert.Equal(t, StatusQuarantined, result.Status)
+	assert.Contains(t, result.Detail, "Eicar-Test-Signature")
+
+	result, err = New(0).WithAntivirus(&fakeAntivirus{err: errors.New("connection refused")}).Scan(ctx, "csv", csv)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, result.Status, "unscanned files wait for a rescan")
+
+	xlm := workbook(t, map[string]string{
+		"[Content_Types].xml":       "<Types/>",
+		"xl/workbook.xml":           "<workbook/>",
+		"xl/macrosheets/sheet1.xml": "<xm:macrosheet/>",
+	})
+	result, err = New(0).Scan(ctx, "xlsx", xlm)
+	require.NoError(t, err)
+	assert.Equal(t, StatusQuarantined, result.Status)
+}
+
+// fakeClamd answers INSTREAM scans, replying FOUND if the stream contains "EICAR"
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			command, _ := r.ReadString(0)
+			var stream bytes.Buffer
+			for command == "zINSTREAM\x00" {
+				var size uint32
+				if binary.Read(r, binary.BigEndian, &size) != nil || size == 0 {
+					break
+				}
+				_, _ = io.CopyN(&stream, r, int64(size))
+			}
+			reply := "stream: OK\x00"
+			if strings.Contains(stream.String(), "EICAR") {
+				reply = "stream: Eicar-Test-Signature FOUND\x00"
+			}
+			_, _ = conn.Write([]byte(reply))
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClamAV_Scan(t *testing.T) {
+	av := NewClamAV(fakeClamd(t))
+	ctx := context.Background()
+
+	signature, err := av.Scan(ctx, bytes.Repeat([]byte("a"), 3*clamAVChunkSize+1))
+	require.NoError(t, err)
+	assert.Empty(t, signature)
+
+	signature, err = av.Scan(ctx, append(bytes.Repeat([]byte("a"), clamAVChunkSize), "EICAR"...))
+	require.NoError(t, err)
+	assert.Equal(t, "Eicar-Test-Signature", signature)
+
+	_, err = parseClamAVReply("INSTREAM size limit exceeded. ERROR")
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+// Package idempotency lets clients retry mutating RPCs safely. A call sent
+// with an Idempotency-Key header runs once; retries with the same key get the
+// stored response instead of repeating the change.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Header carries the client's key for a call
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from an earlier call
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultTTL is how long a key's response is replayed
+	DefaultTTL = 24 * time.Hour
+	// maxKeyLength bounds the keys clients may send; UUIDs are recommended
+	maxKeyLength = 255
+	// abandonAfter is when a call that never completed, e.g. because the
+	// server stopped, no longer blocks retries with its key
+	abandonAfter = 2 * time.Minute
+)
+
+var (
+	// ErrInProgress is returned for a retry while the first call is still running
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+	// ErrKeyReused is returned when a key is sent with a different request
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+	// ErrKeyTooLong is returned for keys over 255 characters
+	ErrKeyTooLong = errors.New("idempotency key must be at most 255 characters")
+)
+
+// Record is a call made with an idempotency key
+type Record struct {
+	UserID      uuid.UUID
+	Key         string
+	Procedure   string
+	RequestHash []byte
+	Response    []byte // Serialized response; nil while the call is in progress
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Store persists idempotency records
+type Store interface {
+	// Reserve claims a key for a new call. If the key is already held, by an
+	// unexpired record or a call in progress since abandonedBefore, it returns
+	// that record and false.
+	Reserve(ctx context.Context, record *Record, abandonedBefore time.Time) (*Record, bool, error)
+	// Complete stores the response of a reserved call
+	Complete(ctx context.Context, userID uuid.UUID, key string, response []byte) error
+	// Release frees the key of a reserved call that failed, so it can be retried
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
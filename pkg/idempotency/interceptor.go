@@ -0,0 +1,180 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// storeTimeout bounds saving the outcome of a call, which happens after it returns
+const storeTimeout = 5 * time.Second
+
+// ReplayFunc rebuilds a procedure's response from its stored form
+type ReplayFunc func(data []byte) (connect.AnyResponse, error)
+
+// Replay is the ReplayFunc of procedures responding with T, e.g.
+// Replay[echov1.ContributeToGoalResponse]
+func Replay[T any, PT interface {
+	*T
+	proto.Message
+}](data []byte) (connect.AnyResponse, error) {
+	msg := PT(new(T))
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode stored response: %w", err)
+	}
+	return connect.NewResponse[T](msg), nil
+}
+
+// Interceptor runs calls to its procedures once per Idempotency-Key and
+// replays the response to retries. Calls without a key run as usual. It must
+// run after authentication, as keys are scoped to the caller. Failed calls
+// free their key so the client can retry them.
+type Interceptor struct {
+	store      Store
+	logger     *slog.Logger
+	procedures map[string]ReplayFunc
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor creates an interceptor keeping responses for DefaultTTL
+func NewInterceptor(store Store, logger *slog.Logger) *Interceptor {
+	return &Interceptor{
+		store:      store,
+		logger:     logger,
+		procedures: make(map[string]ReplayFunc),
+		ttl:        DefaultTTL,
+		now:        time.Now,
+	}
+}
+
+// WithProcedure makes procedure idempotent, replaying responses with replay
+func (i *Interceptor) WithProcedure(procedure string, replay ReplayFunc) *Interceptor {
+	i.procedures[procedure] = replay
+	return i
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		replay, ok := i.procedures[req.Spec().Procedure]
+		key := req.Header().Get(Header)
+		if !ok || key == "" || req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		if len(key) > maxKeyLength {
+			return nil, connect.NewError(connect.CodeInvalidArgument, ErrKeyTooLong)
+		}
+		rawUserID, _ := interceptors.GetUserIDFromContext(ctx)
+		userID, err := uuid.Parse(rawUserID)
+		if err != nil {
+			return next(ctx, req)
+		}
+
+		hash, err := requestHash(req)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		now := i.now()
+		existing, reserved, err := i.store.Reserve(ctx, &Record{
+			UserID:      userID,
+			Key:         key,
+			Procedure:   req.Spec().Procedure,
+			RequestHash: hash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.ttl),
+		}, now.Add(-abandonAfter))
+		if err != nil {
+			// Running the call without its key could duplicate it
+			i.logger.ErrorContext(ctx, "failed to reserve idempotency key", slog.Any("error", err))
+			return nil, connect.NewError(connect.CodeUnavailable, errors.New("failed to check idempotency key"))
+		}
+		if !reserved {
+			return i.replay(existing, hash, replay)
+		}
+
+		resp, callErr := next(ctx, req)
+
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+		defer cancel()
+		if callErr != nil {
+			if err := i.store.Release(storeCtx, userID, key); err != nil {
+				i.logger.WarnContext(ctx, "failed to release idempotency key", slog.Any("error", err))
+			}
+			return resp, callErr
+		}
+		if err := i.complete(storeCtx, userID, key, resp); err != nil {
+			// The call succeeded; retries are refused as in progress until the key is abandoned
+			i.logger.ErrorContext(ctx, "failed to store idempotent response", slog.Any("error", err))
+		}
+		return resp, nil
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor. Streams aren't replayable.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// replay answers a retry from the record holding its key
+func (i *Interceptor) replay(existing *Record, hash []byte, replay ReplayFunc) (connect.AnyResponse, error) {
+	if !bytes.Equal(existing.RequestHash, hash) {
+		return nil, connect.NewError(connect.CodeInvalidArgument, ErrKeyReused)
+	}
+	if existing.Response == nil {
+		return nil, connect.NewError(connect.CodeAborted, ErrInProgress)
+	}
+	resp, err := replay(existing.Response)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	resp.Header().Set(ReplayedHeader, "true")
+	return resp, nil
+}
+
+func (i *Interceptor) complete(ctx context.Context, userID uuid.UUID, key string, resp connect.AnyResponse) error {
+	msg, ok := resp.Any().(proto.Message)
+	if !ok {
+		return fmt.Errorf("response %T is not a proto message", resp.Any())
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	if data == nil {
+		data = []byte{} // An empty message, still a completed call
+	}
+	return i.store.Complete(ctx, userID, key, data)
+}
+
+// requestHash identifies a call by its procedure and request message
+func requestHash(req connect.AnyRequest) ([]byte, error) {
+	h := sha256.New()
+	h.Write([]byte(req.Spec().Procedure))
+	h.Write([]byte{0})
+	if msg, ok := req.Any().(proto.Message); ok {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash request: %w", err)
+		}
+		h.Write(data)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,121 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/echo-tracker/echo/connectrpc/go/echo/v1/echov1connect"
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"connectrpc.com/connect"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/interceptors"
+)
+
+// memoryStore keeps records in memory
+type memoryStore struct {
+	records map[string]*Record
+}
+
+func (s *memoryStore) Reserve(_ context.Context, r *Record, abandonedBefore time.Time) (*Record, bool, error) {
+	k := r.UserID.String() + "/" + r.Key
+	if existing, ok := s.records[k]; ok && existing.ExpiresAt.After(r.CreatedAt) &&
+		(existing.Response != nil || !existing.CreatedAt.Before(abandonedBefore)) {
+		return existing, false, nil
+	}
+	s.records[k] = r
+	return nil, true, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, userID uuid.UUID, key string, response []byte) error {
+	s.records[userID.String()+"/"+key].Response = response
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, userID uuid.UUID, key string) error {
+	delete(s.records, userID.String()+"/"+key)
+	return nil
+}
+
+// financeHandler creates a transaction per call, failing the first one when asked
+type financeHandler struct {
+	echov1connect.UnimplementedFinanceServiceHandler
+	created  int
+	failNext bool
+}
+
+func (h *financeHandler) CreateManualTransaction(_ context.Context, req *connect.Request[echov1.CreateManualTransactionRequest]) (*connect.Response[echov1.CreateManualTransactionResponse], error) {
+	if h.failNext {
+		h.failNext = false
+		return nil, connect.NewError(connect.CodeUnavailable, errors.New("database is down"))
+	}
+	h.created++
+	return connect.NewResponse(&echov1.CreateManualTransactionResponse{
+		Transaction:       &echov1.Transaction{Id: uuid.NewString()},
+		ParsedDescription: req.Msg.RawText,
+	}), nil
+}
+
+// withUser stands in for the auth interceptor
+func withUser(userID string) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			return next(context.WithValue(ctx, interceptors.UserIDKey, userID), req)
+		}
+	}
+}
+
+func TestInterceptor_ReplaysRetries(t *testing.T) {
+	store := &memoryStore{records: map[string]*Record{}}
+	idempotent := NewInterceptor(store, slog.New(slog.NewTextHandler(io.Discard, nil))).
+		WithProcedure(echov1connect.FinanceServiceCreateManualTransactionProcedure, Replay[echov1.CreateManualTransactionResponse])
+
+	finance := &financeHandler{}
+	path, h := echov1connect.NewFinanceServiceHandler(finance,
+		connect.WithInterceptors(withUser(uuid.NewString()), idempotent))
+	mux := http.NewServeMux()
+	mux.Handle(path, h)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := echov1connect.NewFinanceServiceClient(server.Client(), server.URL)
+
+	create := func(key, text string) (*connect.Response[echov1.CreateManualTransactionResponse], error) {
+		req := connect.NewRequest(&echov1.CreateManualTransactionRequest{RawText: text})
+		if key != "" {
+			req.Header().Set(Header, key)
+		}
+		return client.CreateManualTransaction(context.Background(), req)
+	}
+
+	// A failed call frees its key for the retry
+	finance.failNext = true
+	_, err := create("key-1", "Coffee 2€")
+	require.Error(t, err)
+
+	first, err := create("key-1", "Coffee 2€")
+	require.NoError(t, err)
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+
+	retry, err := create("key-1", "Coffee 2€")
+	require.NoError(t, err)
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, first.Msg.Transaction.Id, retry.Msg.Transaction.Id)
+	assert.Equal(t, 1, finance.created, "the retry must not create another transaction")
+
+	_, err = create("key-1", "Dinner 25€")
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "a key can't be reused for another request")
+
+	_, err = create("", "Coffee 2€")
+	require.NoError(t, err)
+	_, err = create("", "Coffee 2€")
+	require.NoError(t, err)
+	assert.Equal(t, 3, finance.created, "calls without a key aren't deduplicated")
+}
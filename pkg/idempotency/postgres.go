@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore implements Store using PostgreSQL
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore creates a new PostgreSQL idempotency store
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// Reserve claims a key, taking over expired and abandoned records
+func (s *PostgresStore) Reserve(ctx context.Context, r *Record, abandonedBefore time.Time) (*Record, bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, procedure, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET procedure = EXCLUDED.procedure, request_hash = EXCLUDED.request_hash, response = NULL,
+			created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.response IS NULL AND idempotency_keys.created_at < $7)`,
+		r.UserID, r.Key, r.Procedure, r.RequestHash, r.CreatedAt, r.ExpiresAt, abandonedBefore)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	existing := &Record{UserID: r.UserID, Key: r.Key}
+	err = s.pool.QueryRow(ctx, `
+		SELECT procedure, request_hash, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`, r.UserID, r.Key).
+		Scan(&existing.Procedure, &existing.RequestHash, &existing.Response, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released between the insert and the read; the caller's retry will claim it
+		return &Record{RequestHash: r.RequestHash}, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return existing, false, nil
+}
+
+// Complete stores the response of a reserved call
+func (s *PostgresStore) Complete(ctx context.Context, userID uuid.UUID, key string, response []byte) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE idempotency_keys SET response = $3 WHERE user_id = $1 AND key = $2`, userID, key, response)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release frees the key of a call that failed
+func (s *PostgresStore) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	_, err := s.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND response IS NULL`, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package interceptors
 import (
 	"context"
 	"errors"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,9 +18,35 @@ type Claims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Scope    string `json:"scope,omitempty"` // Set for API keys, empty for user sessions
+	// ImpersonatedBy is the admin reading the account on the user's behalf,
+	// set on tokens with ScopeImpersonation
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ScopeImpersonation marks tokens support staff use to see a user's account
+// as the user does. They may only call Get and List procedures.
+const ScopeImpersonation = "impersonation"
+
+// APIKeyPrefix starts every API key, telling them apart from session JWTs
+const APIKeyPrefix = "echo_pat_"
+
+// ErrAPIKeyScope is returned by an APIKeyVerifier when a valid key's scope
+// doesn't cover the procedure being called
+var ErrAPIKeyScope = errors.New("api key scope does not allow this call")
+
+// ErrImpersonationReadOnly is returned when an impersonation token calls a
+// procedure that could change the account
+var ErrImpersonationReadOnly = errors.New("impersonation tokens are read-only")
+
+// APIKeyVerifier checks API keys presented as bearer tokens
+type APIKeyVerifier interface {
+	// VerifyAPIKey returns the claims of the key's owner if the key is valid
+	// and its scope allows calling procedure
+	VerifyAPIKey(ctx context.Context, key, procedure, clientIP string) (*Claims, error)
+}
+
 // Context keys for storing user information
 type contextKey string
 
@@ -27,10 +55,20 @@ const (
 	claimsKey contextKey = "claims"
 )
 
+// SessionValidator checks that the session an access token was issued for
+// hasn't been revoked, recording the activity
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, claims *Claims, clientIP string) error
+}
+
 // AuthInterceptor handles JWT authentication for Connect RPC
 type AuthInterceptor struct {
 	jwtSecret          []byte
 	optionalProcedures map[string]struct{}
+	apiKeys            APIKeyVerifier
+	sessions           SessionValidator
+	serviceRoles       map[string][]string // By service path prefix
+	proxies            *TrustedProxies
 }
 
 var _ connect.Interceptor = (*AuthInterceptor)(nil)
@@ -50,6 +88,85 @@ func NewAuthInterceptor(jwtSecret []byte, optionalProcedures ...string) *AuthInt
 	}
 }
 
+// WithAPIKeys accepts API keys as bearer tokens alongside session JWTs
+func (a *AuthInterceptor) WithAPIKeys(verifier APIKeyVerifier) *AuthInterceptor {
+	a.apiKeys = verifier
+	return a
+}
+
+// WithSessions rejects access tokens of revoked sessions
+func (a *AuthInterceptor) WithSessions(validator SessionValidator) *AuthInterceptor {
+	a.sessions = validator
+	return a
+}
+
+// WithTrustedProxies reads the client address of calls through proxies from
+// X-Forwarded-For
+func (a *AuthInterceptor) WithTrustedProxies(proxies *TrustedProxies) *AuthInterceptor {
+	a.proxies = proxies
+	return a
+}
+
+// WithServiceRoles limits calls to the service mounted at path, like
+// "/echo.v1.AdminUserService/", to callers with one of roles. Admins always
+// pass, and impersonation tokens never do.
+func (a *AuthInterceptor) WithServiceRoles(path string, roles ...string) *AuthInterceptor {
+	if a.serviceRoles == nil {
+		a.serviceRoles = make(map[string][]string)
+	}
+	a.serviceRoles[path] = roles
+	return a
+}
+
+// authorize checks authenticated claims against the procedure's role guard
+// and the read-only limit of impersonation tokens
+func (a *AuthInterceptor) authorize(claims *Claims, procedure string) error {
+	impersonating := claims.Scope == ScopeImpersonation
+	for path, roles := range a.serviceRoles {
+		if !strings.HasPrefix(procedure, path) {
+			continue
+		}
+		if impersonating || (claims.Role != "admin" && !slices.Contains(roles, claims.Role)) {
+			return connect.NewError(connect.CodePermissionDenied, errors.New("insufficient permissions"))
+		}
+	}
+	if impersonating {
+		method := procedure[strings.LastIndex(procedure, "/")+1:]
+		if !strings.HasPrefix(method, "Get") && !strings.HasPrefix(method, "List") {
+			return connect.NewError(connect.CodePermissionDenied, ErrImpersonationReadOnly)
+		}
+	}
+	return nil
+}
+
+// validateSession runs the session validator, if any, on verified JWT claims
+func (a *AuthInterceptor) validateSession(ctx context.Context, claims *Claims, header http.Header, peerAddr string) error {
+	if a.sessions == nil {
+		return nil
+	}
+	if err := a.sessions.ValidateSession(ctx, claims, a.proxies.ClientIP(header, peerAddr)); err != nil {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("session has been signed out"))
+	}
+	return nil
+}
+
+// authenticateAPIKey verifies an API key and adds its owner's claims to the context
+func (a *AuthInterceptor) authenticateAPIKey(ctx context.Context, key, procedure string, header http.Header, peerAddr string) (context.Context, error) {
+	claims, err := a.apiKeys.VerifyAPIKey(ctx, key, procedure, a.proxies.ClientIP(header, peerAddr))
+	if errors.Is(err, ErrAPIKeyScope) {
+		return nil, connect.NewError(connect.CodePermissionDenied, err)
+	}
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid api key"))
+	}
+	if err := a.authorize(claims, procedure); err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, claimsKey, claims)
+	ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+	return ctx, nil
+}
+
 // UnaryInterceptor returns a Connect unary interceptor that validates JWT tokens
 func (a *AuthInterceptor) UnaryInterceptor() connect.UnaryInterceptorFunc {
 	return func(next connect.UnaryFunc) connect.UnaryFunc {
@@ -88,6 +205,15 @@ func (a *AuthInterceptor) UnaryInterceptor() connect.UnaryInterceptorFunc {
 
 			tokenString := parts[1]
 
+			// API keys for scripts are checked against their scope instead
+			if a.apiKeys != nil && strings.HasPrefix(tokenString, APIKeyPrefix) {
+				keyCtx, err := a.authenticateAPIKey(ctx, tokenString, req.Spec().Procedure, req.Header(), req.Peer().Addr)
+				if err != nil {
+					return nil, err
+				}
+				return next(keyCtx, req)
+			}
+
 			// Parse and validate JWT
 			claims := &Claims{}
 			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -119,6 +245,13 @@ func (a *AuthInterceptor) UnaryInterceptor() connect.UnaryInterceptorFunc {
 				)
 			}
 
+			if err := a.validateSession(ctx, claims, req.Header(), req.Peer().Addr); err != nil {
+				return nil, err
+			}
+			if err := a.authorize(claims, req.Spec().Procedure); err != nil {
+				return nil, err
+			}
+
 			// Add claims to context
 			ctx = context.WithValue(ctx, claimsKey, claims)
 			ctx = context.WithValue(ctx, UserIDKey, claims.UserID) // Backward compatibility
@@ -163,6 +296,13 @@ func (a *AuthInterceptor) OptionalAuthInterceptor() connect.UnaryInterceptorFunc
 	}
 }
 
+// ContextWithClaims returns a context authenticated as claims, as the auth
+// interceptor leaves it. Used by jobs and tests acting as a user.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = context.WithValue(ctx, claimsKey, claims)
+	return context.WithValue(ctx, UserIDKey, claims.UserID)
+}
+
 // GetClaimsFromContext retrieves the JWT claims from the context
 func GetClaimsFromContext(ctx context.Context) (*Claims, error) {
 	claims, ok := ctx.Value(claimsKey).(*Claims)
@@ -273,6 +413,14 @@ func (a *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc
 
 		tokenString := parts[1]
 
+		if a.apiKeys != nil && strings.HasPrefix(tokenString, APIKeyPrefix) {
+			keyCtx, err := a.authenticateAPIKey(ctx, tokenString, conn.Spec().Procedure, conn.RequestHeader(), conn.Peer().Addr)
+			if err != nil {
+				return err
+			}
+			return next(keyCtx, conn)
+		}
+
 		// Parse and validate JWT
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -304,6 +452,13 @@ func (a *AuthInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc
 			)
 		}
 
+		if err := a.validateSession(ctx, claims, conn.RequestHeader(), conn.Peer().Addr); err != nil {
+			return err
+		}
+		if err := a.authorize(claims, conn.Spec().Procedure); err != nil {
+			return err
+		}
+
 		// Add claims to context
 		ctx = context.WithValue(ctx, claimsKey, claims)
 		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
@@ -0,0 +1,91 @@
+package interceptors
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the reverse proxies whose X-Forwarded-For is believed.
+// The header is set by the client too, so from anyone else it is ignored. A
+// nil *TrustedProxies trusts no one and always uses the peer address.
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// ParseTrustedProxies parses proxy addresses and CIDR ranges, like
+// "10.0.0.0/8" or "192.168.1.10"
+func ParseTrustedProxies(proxies []string) (*TrustedProxies, error) {
+	p := &TrustedProxies{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		p.nets = append(p.nets, network)
+	}
+	return p, nil
+}
+
+// trusts reports whether addr is one of the proxies
+func (p *TrustedProxies) trusts(addr string) bool {
+	if p == nil {
+		return false
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range p.nets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the caller's address. X-Forwarded-For is only read when
+// the peer is a trusted proxy, and then walked from the right, skipping
+// trusted hops: the first untrusted one is the client, as every hop before it
+// may have been written by the client itself.
+func (p *TrustedProxies) ClientIP(header http.Header, peerAddr string) string {
+	client := peerAddr
+	if host, _, err := net.SplitHostPort(peerAddr); err == nil {
+		client = host
+	}
+	if !p.trusts(client) {
+		return client
+	}
+
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if net.ParseIP(hops[i]) == nil {
+			break // Garbled; keep the last hop a trusted proxy vouched for
+		}
+		client = hops[i]
+		if !p.trusts(client) {
+			break
+		}
+	}
+	return client
+}
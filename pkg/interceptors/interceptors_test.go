@@ -2,7 +2,11 @@ package interceptors
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	"golang.org/x/time/rate"
@@ -25,6 +29,112 @@ func TestRequestIDInterceptor_GeneratesID(t *testing.T) {
 	}
 }
 
+func TestRateBudgetInterceptor_PerUserBudget(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	interceptor := NewRateBudgetInterceptor(store, RateClass{
+		Name:    "api",
+		PerUser: Budget{Rate: 1, Burst: 2},
+		PerIP:   Budget{Rate: 10, Burst: 10},
+	}, slog.Default())
+	handler := interceptor.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+	call := func(userID string) error {
+		req := connect.NewRequest(&emptypb.Empty{})
+		req.Header().Set("X-Forwarded-For", "198.51.100.7")
+		_, err := handler(context.WithValue(context.Background(), UserIDKey, userID), req)
+		return err
+	}
+
+	for range 2 {
+		if err := call("user-1"); err != nil {
+			t.Fatalf("expected call within budget to pass, got %v", err)
+		}
+	}
+	err := call("user-1")
+	if connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("expected resource exhausted, got %v", err)
+	}
+	if retryAfter, ok := RetryAfter(err); !ok || retryAfter != time.Second {
+		t.Fatalf("expected to retry after 1s, got %v (%v)", retryAfter, ok)
+	}
+	if err := call("user-2"); err != nil {
+		t.Fatalf("expected another user's budget to be separate, got %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if err := call("user-1"); err != nil {
+		t.Fatalf("expected the budget to refill, got %v", err)
+	}
+}
+
+func TestRateBudgetInterceptor_SpoofedForwardedFor(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	interceptor := NewRateBudgetInterceptor(store, RateClass{
+		Name:  "auth",
+		PerIP: Budget{Rate: 1, Burst: 2},
+	}, slog.Default())
+	handler := interceptor.WrapUnary(func(_ context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		return connect.NewResponse(&emptypb.Empty{}), nil
+	})
+	call := func(forwardedFor string) error {
+		req := connect.NewRequest(&emptypb.Empty{})
+		req.Header().Set("X-Forwarded-For", forwardedFor)
+		_, err := handler(context.Background(), req)
+		return err
+	}
+
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+		if err := call(ip); err != nil {
+			t.Fatalf("expected call within budget to pass, got %v", err)
+		}
+	}
+	if err := call("198.51.100.3"); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("expected a new spoofed address to share the peer's budget, got %v", err)
+	}
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("parse trusted proxies: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		proxies      *TrustedProxies
+		peerAddr     string
+		forwardedFor string
+		want         string
+	}{
+		{"untrusted peer ignores header", proxies, "203.0.113.9:5123", "198.51.100.7", "203.0.113.9"},
+		{"no proxies ignores header", nil, "10.1.2.3:5123", "198.51.100.7", "10.1.2.3"},
+		{"trusted peer uses forwarded client", proxies, "10.1.2.3:5123", "198.51.100.7", "198.51.100.7"},
+		{"right-most untrusted hop wins", proxies, "10.1.2.3:5123", "6.6.6.6, 198.51.100.7, 192.0.2.1", "198.51.100.7"},
+		{"garbled hop keeps the last trusted one", proxies, "10.1.2.3:5123", "198.51.100.7, not-an-ip", "10.1.2.3"},
+		{"trusted peer without header", proxies, "192.0.2.1:443", "", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.forwardedFor != "" {
+				header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			if got := tt.proxies.ClientIP(header, tt.peerAddr); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"not-a-proxy"}); err == nil {
+		t.Fatalf("expected an invalid proxy to fail")
+	}
+}
+
 func TestRateLimitInterceptor_ExceedsLimit(t *testing.T) {
 	limiter := rate.NewLimiter(0, 0)
 	interceptor := NewRateLimitInterceptor(limiter)
@@ -37,3 +147,71 @@ func TestRateLimitInterceptor_ExceedsLimit(t *testing.T) {
 		t.Fatalf("expected resource exhausted, got %v", err)
 	}
 }
+
+type stubAPIKeyVerifier struct {
+	err error
+}
+
+func (v stubAPIKeyVerifier) VerifyAPIKey(context.Context, string, string, string) (*Claims, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	return &Claims{UserID: "user-1", Scope: "read_only"}, nil
+}
+
+func TestAuthInterceptor_APIKeys(t *testing.T) {
+	handler := func(v APIKeyVerifier) connect.UnaryFunc {
+		return NewAuthInterceptor([]byte("secret")).WithAPIKeys(v).WrapUnary(func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+			claims, err := GetClaimsFromContext(ctx)
+			if err != nil || claims.UserID != "user-1" || claims.Scope != "read_only" {
+				t.Fatalf("expected api key claims in context, got %+v", claims)
+			}
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		})
+	}
+	call := func(v APIKeyVerifier) error {
+		req := connect.NewRequest(&emptypb.Empty{})
+		req.Header().Set("Authorization", "Bearer "+APIKeyPrefix+"abc")
+		_, err := handler(v)(context.Background(), req)
+		return err
+	}
+
+	if err := call(stubAPIKeyVerifier{}); err != nil {
+		t.Fatalf("expected valid api key to pass, got %v", err)
+	}
+	if err := call(stubAPIKeyVerifier{err: ErrAPIKeyScope}); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+	if err := call(stubAPIKeyVerifier{err: errors.New("revoked")}); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected unauthenticated, got %v", err)
+	}
+}
+
+func TestAuthInterceptor_AuthorizeRolesAndImpersonation(t *testing.T) {
+	const adminPath = "/echo.v1.AdminUserService/"
+	a := NewAuthInterceptor([]byte("secret")).WithServiceRoles(adminPath, "support")
+
+	cases := []struct {
+		name      string
+		claims    *Claims
+		procedure string
+		allowed   bool
+	}{
+		{"admin", &Claims{Role: "admin"}, adminPath + "GetUser", true},
+		{"support", &Claims{Role: "support"}, adminPath + "ListUsers", true},
+		{"user on admin service", &Claims{Role: "user"}, adminPath + "GetUser", false},
+		{"user elsewhere", &Claims{Role: "user"}, "/echo.v1.PlanService/UpdatePlan", true},
+		{"impersonation read", &Claims{Role: "user", Scope: ScopeImpersonation}, "/echo.v1.PlanService/GetPlan", true},
+		{"impersonation write", &Claims{Role: "user", Scope: ScopeImpersonation}, "/echo.v1.PlanService/UpdatePlan", false},
+		{"impersonation of admin service", &Claims{Role: "admin", Scope: ScopeImpersonation}, adminPath + "GetUser", false},
+	}
+	for _, tc := range cases {
+		err := a.authorize(tc.claims, tc.procedure)
+		if tc.allowed && err != nil {
+			t.Fatalf("%s: expected allowed, got %v", tc.name, err)
+		}
+		if !tc.allowed && connect.CodeOf(err) != connect.CodePermissionDenied {
+			t.Fatalf("%s: expected permission denied, got %v", tc.name, err)
+		}
+	}
+}
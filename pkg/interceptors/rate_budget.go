@@ -0,0 +1,222 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// Budget is a token bucket: Rate tokens a second, holding at most Burst.
+// A zero Rate doesn't limit.
+type Budget struct {
+	Rate  float64
+	Burst int
+}
+
+// RateClass is a group of RPCs sharing budgets. Calls cost one token each,
+// or their request size in bytes when Bytes is set.
+type RateClass struct {
+	Name    string
+	PerUser Budget // Budget of each signed-in user
+	PerIP   Budget // Budget of each client address, signed in or not
+	Bytes   bool
+}
+
+// RateLimitStore holds token buckets, in memory or shared between servers
+type RateLimitStore interface {
+	// Take removes cost tokens from the bucket at key if it has them. If not,
+	// it reports how long until it will.
+	Take(ctx context.Context, key string, cost float64, budget Budget) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateBudgetInterceptor enforces per-user and per-IP token bucket budgets by
+// RPC class. It must run after authentication to know the user. Calls over
+// budget fail with ResourceExhausted and a Retry-After header; store failures
+// are logged and let calls through.
+type RateBudgetInterceptor struct {
+	store        RateLimitStore
+	logger       *slog.Logger
+	defaultClass RateClass
+	classes      map[string]RateClass
+	proxies      *TrustedProxies
+}
+
+var _ connect.Interceptor = (*RateBudgetInterceptor)(nil)
+
+// NewRateBudgetInterceptor creates an interceptor applying defaultClass to
+// every procedure not given a class of its own
+func NewRateBudgetInterceptor(store RateLimitStore, defaultClass RateClass, logger *slog.Logger) *RateBudgetInterceptor {
+	return &RateBudgetInterceptor{
+		store:        store,
+		logger:       logger,
+		defaultClass: defaultClass,
+		classes:      make(map[string]RateClass),
+	}
+}
+
+// WithClass applies class to the given procedures
+func (i *RateBudgetInterceptor) WithClass(class RateClass, procedures ...string) *RateBudgetInterceptor {
+	for _, p := range procedures {
+		i.classes[p] = class
+	}
+	return i
+}
+
+// WithTrustedProxies reads the client address of calls through proxies from
+// X-Forwarded-For; otherwise per-IP budgets are keyed on the peer address
+func (i *RateBudgetInterceptor) WithTrustedProxies(proxies *TrustedProxies) *RateBudgetInterceptor {
+	i.proxies = proxies
+	return i
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *RateBudgetInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		cost := 1.0
+		class := i.class(req.Spec().Procedure)
+		if msg, ok := req.Any().(proto.Message); ok && class.Bytes {
+			cost = float64(proto.Size(msg))
+		}
+		if err := i.take(ctx, class, cost, i.proxies.ClientIP(req.Header(), req.Peer().Addr)); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *RateBudgetInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor. Opening a stream
+// costs one token; streamed bytes aren't counted.
+func (i *RateBudgetInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		class := i.class(conn.Spec().Procedure)
+		if err := i.take(ctx, class, 1, i.proxies.ClientIP(conn.RequestHeader(), conn.Peer().Addr)); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *RateBudgetInterceptor) class(procedure string) RateClass {
+	if class, ok := i.classes[procedure]; ok {
+		return class
+	}
+	return i.defaultClass
+}
+
+// take charges the caller's IP and, when signed in, user budgets
+func (i *RateBudgetInterceptor) take(ctx context.Context, class RateClass, cost float64, clientIP string) error {
+	type bucket struct {
+		key    string
+		budget Budget
+	}
+	buckets := []bucket{{key: class.Name + ":ip:" + clientIP, budget: class.PerIP}}
+	if userID, ok := GetUserIDFromContext(ctx); ok && userID != "" {
+		buckets = append(buckets, bucket{key: class.Name + ":user:" + userID, budget: class.PerUser})
+	}
+
+	for _, b := range buckets {
+		if b.budget.Rate <= 0 || b.budget.Burst <= 0 {
+			continue
+		}
+		// A single call larger than the bucket could never go through
+		allowed, retryAfter, err := i.store.Take(ctx, b.key, math.Min(cost, float64(b.budget.Burst)), b.budget)
+		if err != nil {
+			i.logger.WarnContext(ctx, "rate limit store failed", slog.String("class", class.Name), slog.Any("error", err))
+			continue
+		}
+		if !allowed {
+			return rateLimitedError(class, retryAfter)
+		}
+	}
+	return nil
+}
+
+func rateLimitedError(class RateClass, retryAfter time.Duration) error {
+	err := connect.NewError(connect.CodeResourceExhausted,
+		fmt.Errorf("rate limit exceeded for %s calls, retry in %s", class.Name, retryAfter.Round(time.Second)))
+	err.Meta().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return err
+}
+
+// RetryAfter returns how long a ResourceExhausted error asks callers to wait
+func RetryAfter(err error) (time.Duration, bool) {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return 0, false
+	}
+	seconds, convErr := strconv.Atoi(connectErr.Meta().Get("Retry-After"))
+	if convErr != nil {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// memoryBucketIdle is how long an untouched bucket is kept before its
+// memory is reclaimed; by then it has refilled in all but extreme configs
+const memoryBucketIdle = 10 * time.Minute
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryRateLimitStore keeps buckets in this process
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// NewMemoryRateLimitStore creates an in-memory bucket store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, cost float64, budget Budget) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.swept) > memoryBucketIdle {
+		for k, b := range s.buckets {
+			if now.Sub(b.updated) > memoryBucketIdle {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(budget.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(budget.Burst), b.tokens+now.Sub(b.updated).Seconds()*budget.Rate)
+	b.updated = now
+
+	if b.tokens < cost {
+		return false, time.Duration((cost - b.tokens) / budget.Rate * float64(time.Second)), nil
+	}
+	b.tokens -= cost
+	return true, 0, nil
+}
@@ -0,0 +1,65 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and charges a bucket atomically. Buckets are hashes of
+// tokens and the last update in milliseconds, expiring once they'd be full.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / 1000
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local now = redis.call("TIME")
+now = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	wait = math.ceil((cost - tokens) / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate) + 1000)
+return {allowed, wait}
+`)
+
+// RedisRateLimitStore keeps buckets in Redis, sharing budgets between servers
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+var _ RateLimitStore = (*RedisRateLimitStore)(nil)
+
+// NewRedisRateLimitStore creates a Redis bucket store
+func NewRedisRateLimitStore(client redis.Scripter) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: "ratelimit:"}
+}
+
+// Take implements RateLimitStore.
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, cost float64, budget Budget) (bool, time.Duration, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		strconv.FormatFloat(budget.Rate, 'f', -1, 64), budget.Burst,
+		strconv.FormatFloat(math.Ceil(cost), 'f', -1, 64)).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit tokens: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
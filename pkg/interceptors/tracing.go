@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -27,6 +28,8 @@ func NewTracingInterceptor(tracer trace.Tracer) *TracingInterceptor {
 // WrapUnary implements connect.Interceptor.
 func (i *TracingInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		// Continue the caller's trace when it sent a traceparent header
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Header()))
 		ctx, span := i.tracer.Start(ctx, req.Spec().Procedure, trace.WithSpanKind(trace.SpanKindServer))
 		serviceName := serviceFromProcedure(req.Spec().Procedure)
 		span.SetAttributes(
@@ -57,6 +60,7 @@ func (i *TracingInterceptor) WrapStreamingClient(next connect.StreamingClientFun
 // WrapStreamingHandler implements connect.Interceptor.
 func (i *TracingInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
 	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
 		ctx, span := i.tracer.Start(ctx, conn.Spec().Procedure, trace.WithSpanKind(trace.SpanKindServer))
 		serviceName := serviceFromProcedure(conn.Spec().Procedure)
 		span.SetAttributes(
@@ -0,0 +1,161 @@
+package fx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultECBURL is where the ECB publishes its euro reference rates
+const DefaultECBURL = "https://www.ecb.europa.eu/stats/eurofxref"
+
+// ecbRecentDays is how far back the ECB's short history goes; older dates
+// need the full history since 1999
+const ecbRecentDays = 90
+
+// ECB serves the European Central Bank's euro reference rates, published
+// around 16:00 CET on working days
+type ECB struct {
+	client  *http.Client
+	baseURL string
+	ttl     time.Duration // How long a downloaded history is reused
+	now     func() time.Time
+
+	mu      sync.Mutex
+	history map[string]ecbHistory // By file
+}
+
+type ecbHistory struct {
+	days      []*RateTable // Oldest first
+	fetchedAt time.Time
+}
+
+var _ RateProvider = (*ECB)(nil)
+
+// NewECB creates a client of the ECB's reference rates
+func NewECB() *ECB {
+	return &ECB{
+		client:  &http.Client{Timeout: DefaultTimeout},
+		baseURL: DefaultECBURL,
+		ttl:     DefaultLatestTTL,
+		now:     time.Now,
+		history: make(map[string]ecbHistory),
+	}
+}
+
+// WithBaseURL points the client at a mirror of the ECB's files
+func (e *ECB) WithBaseURL(baseURL string) *ECB {
+	e.baseURL = strings.TrimRight(baseURL, "/")
+	return e
+}
+
+// Rates returns the reference rates on date, or the latest ones when date is
+// zero. The ECB publishes no rates on weekends and TARGET holidays; those
+// days get the last rates before them.
+func (e *ECB) Rates(ctx context.Context, date time.Time) (*RateTable, error) {
+	if date.IsZero() {
+		days, err := e.fetch(ctx, "eurofxref-daily.xml")
+		if err != nil {
+			return nil, err
+		}
+		return days[len(days)-1], nil
+	}
+
+	file := "eurofxref-hist.xml"
+	if e.now().Sub(date) < (ecbRecentDays-5)*24*time.Hour {
+		file = "eurofxref-hist-90d.xml"
+	}
+	days, err := e.historyOf(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	// Last day on or before date
+	i := sort.Search(len(days), func(i int) bool { return days[i].Date.After(date) })
+	if i == 0 {
+		return nil, fmt.Errorf("%w: ECB has no rates before %s", ErrRateNotFound, days[0].Date.Format(time.DateOnly))
+	}
+	return days[i-1], nil
+}
+
+// historyOf returns the days of a history file, downloading it at most once
+// per TTL
+func (e *ECB) historyOf(ctx context.Context, file string) ([]*RateTable, error) {
+	e.mu.Lock()
+	cached, ok := e.history[file]
+	e.mu.Unlock()
+	if ok && e.now().Sub(cached.fetchedAt) < e.ttl {
+		return cached.days, nil
+	}
+
+	days, err := e.fetch(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.history[file] = ecbHistory{days: days, fetchedAt: e.now()}
+	e.mu.Unlock()
+	return days, nil
+}
+
+// ecbEnvelope is the layout of the ECB's files: a Cube per day holding a
+// Cube per currency
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// fetch downloads and parses one of the ECB's files, returning its days
+// oldest first
+func (e *ECB) fetch(ctx context.Context, file string) ([]*RateTable, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+"/"+file, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ecb request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ecb request failed: %s", resp.Status)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode ecb rates: %w", err)
+	}
+	days := make([]*RateTable, 0, len(envelope.Days))
+	for _, d := range envelope.Days {
+		date, err := time.Parse(time.DateOnly, d.Time)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ecb rates: %w", err)
+		}
+		table := &RateTable{Base: "EUR", Date: date, Rates: make(map[string]decimal.Decimal, len(d.Rates))}
+		for _, r := range d.Rates {
+			rate, err := decimal.NewFromString(r.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode ecb rate of %s: %w", r.Currency, err)
+			}
+			table.Rates[r.Currency] = rate
+		}
+		days = append(days, table)
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("%w: ecb file %s has no rates", ErrRateNotFound, file)
+	}
+	// The ECB lists the newest day first
+	sort.Slice(days, func(a, b int) bool { return days[a].Date.Before(days[b].Date) })
+	return days, nil
+}
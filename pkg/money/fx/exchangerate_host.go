@@ -0,0 +1,102 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultExchangeRateHostURL is exchangerate.host's API
+const DefaultExchangeRateHostURL = "https://api.exchangerate.host"
+
+// ExchangeRateHost serves rates from exchangerate.host, which covers more
+// currencies than the ECB
+type ExchangeRateHost struct {
+	client    *http.Client
+	baseURL   string
+	accessKey string
+	source    string
+}
+
+var _ RateProvider = (*ExchangeRateHost)(nil)
+
+// NewExchangeRateHost creates a client of exchangerate.host with the given
+// access key, quoting rates against source (e.g. "EUR")
+func NewExchangeRateHost(accessKey, source string) *ExchangeRateHost {
+	return &ExchangeRateHost{
+		client:    &http.Client{Timeout: DefaultTimeout},
+		baseURL:   DefaultExchangeRateHostURL,
+		accessKey: accessKey,
+		source:    strings.ToUpper(source),
+	}
+}
+
+// WithBaseURL points the client at another host speaking the same API
+func (x *ExchangeRateHost) WithBaseURL(baseURL string) *ExchangeRateHost {
+	x.baseURL = strings.TrimRight(baseURL, "/")
+	return x
+}
+
+// exchangeRateHostResponse is the body of the live and historical endpoints
+type exchangeRateHostResponse struct {
+	Success bool                       `json:"success"`
+	Source  string                     `json:"source"`
+	Date    string                     `json:"date"`
+	Quotes  map[string]decimal.Decimal `json:"quotes"` // Keyed source+currency, e.g. "EURUSD"
+	Error   *struct {
+		Code int    `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// Rates returns the rates at the end of date, or the live rates when date is
+// zero
+func (x *ExchangeRateHost) Rates(ctx context.Context, date time.Time) (*RateTable, error) {
+	params := url.Values{"access_key": {x.accessKey}, "source": {x.source}}
+	endpoint := "/live"
+	if !date.IsZero() {
+		endpoint = "/historical"
+		params.Set("date", date.Format(time.DateOnly))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, x.baseURL+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchangerate.host request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchangerate.host request failed: %s", resp.Status)
+	}
+
+	var body exchangeRateHostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode exchangerate.host rates: %w", err)
+	}
+	if !body.Success {
+		if body.Error != nil {
+			return nil, fmt.Errorf("exchangerate.host error %d: %s", body.Error.Code, body.Error.Info)
+		}
+		return nil, fmt.Errorf("exchangerate.host request failed")
+	}
+
+	table := &RateTable{Base: body.Source, Date: date, Rates: make(map[string]decimal.Decimal, len(body.Quotes))}
+	if d, err := time.Parse(time.DateOnly, body.Date); err == nil {
+		table.Date = d
+	}
+	for pair, rate := range body.Quotes {
+		if code, ok := strings.CutPrefix(pair, body.Source); ok && code != "" {
+			table.Rates[code] = rate
+		}
+	}
+	return table, nil
+}
@@ -0,0 +1,211 @@
+// Package fx looks up exchange rates from the ECB, exchangerate.host or
+// manually configured rates, and converts Money with them through a cached
+// Converter, either at the latest rates or at the rates of a past day.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+const (
+	// DefaultTimeout bounds a single request to a rate provider
+	DefaultTimeout = 30 * time.Second
+	// DefaultLatestTTL is how long the latest rates are reused; the ECB
+	// publishes once a working day
+	DefaultLatestTTL = 6 * time.Hour
+)
+
+// ErrRateNotFound is returned when no provider has a rate for the currencies
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateTable is a provider's exchange rates on one day, quoted against Base
+type RateTable struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]decimal.Decimal // Units of each currency one unit of Base buys
+}
+
+// Rate returns how many units of to one unit of from buys, crossing through
+// the table's base when neither currency is it
+func (t *RateTable) Rate(from, to string) (decimal.Decimal, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return decimal.NewFromInt(1), true
+	}
+	perBase := func(code string) (decimal.Decimal, bool) {
+		if code == t.Base {
+			return decimal.NewFromInt(1), true
+		}
+		rate, ok := t.Rates[code]
+		return rate, ok && rate.IsPositive()
+	}
+	fromRate, ok := perBase(from)
+	if !ok {
+		return decimal.Zero, false
+	}
+	toRate, ok := perBase(to)
+	if !ok {
+		return decimal.Zero, false
+	}
+	return toRate.Div(fromRate), true
+}
+
+// RateProvider looks up exchange rates
+type RateProvider interface {
+	// Rates returns the rates on date, or the latest rates when date is zero.
+	// Days without rates, such as weekends, get the last rates before them.
+	Rates(ctx context.Context, date time.Time) (*RateTable, error)
+}
+
+// Converter converts Money with rates from its providers, asked in order
+// until one has both currencies, so a Manual provider listed first overrides
+// the rates of the feeds after it. Latest rates are cached for a TTL; rates
+// of past days don't change and are cached for good.
+type Converter struct {
+	providers []RateProvider
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu     sync.Mutex
+	tables map[tableKey]cachedTable
+	group  singleflight.Group
+}
+
+type tableKey struct {
+	provider int
+	day      string // "" for the latest rates
+}
+
+type cachedTable struct {
+	table     *RateTable
+	fetchedAt time.Time
+}
+
+// NewConverter creates a converter asking providers in order
+func NewConverter(providers ...RateProvider) *Converter {
+	return &Converter{
+		providers: providers,
+		ttl:       DefaultLatestTTL,
+		now:       time.Now,
+		tables:    make(map[tableKey]cachedTable),
+	}
+}
+
+// WithLatestTTL sets how long the latest rates are reused
+func (c *Converter) WithLatestTTL(ttl time.Duration) *Converter {
+	c.ttl = ttl
+	return c
+}
+
+// Rate returns how many units of to one unit of from buys on date, or at the
+// latest rates when date is zero, today or later
+func (c *Converter) Rate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+
+	day := c.day(date)
+	var errs []error
+	for i, p := range c.providers {
+		table, err := c.table(ctx, i, p, day)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rate, ok := table.Rate(from, to); ok {
+			return rate, nil
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return decimal.Zero, fmt.Errorf("%w for %s/%s: %w", ErrRateNotFound, from, to, err)
+	}
+	return decimal.Zero, fmt.Errorf("%w for %s/%s", ErrRateNotFound, from, to)
+}
+
+// Convert converts m to currency to at the rates of date, or at the latest
+// rates when date is zero, today or later
+func (c *Converter) Convert(ctx context.Context, m *money.Money, to string, date time.Time) (*money.Money, error) {
+	if m == nil || m.Currency() == "" {
+		return money.Zero(to), nil
+	}
+	rate, err := c.Rate(ctx, m.Currency(), to, date)
+	if err != nil {
+		return nil, err
+	}
+	return m.Convert(to, rate), nil
+}
+
+// Refresh fetches the latest rates of every provider, replacing the cached
+// ones. Providers that fail keep their cached rates.
+func (c *Converter) Refresh(ctx context.Context) error {
+	var errs []error
+	for i, p := range c.providers {
+		table, err := p.Rates(ctx, time.Time{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.store(tableKey{provider: i}, table)
+	}
+	return errors.Join(errs...)
+}
+
+// day returns the date's day, or "" for the latest rates
+func (c *Converter) day(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	day := date.UTC().Format(time.DateOnly)
+	if day >= c.now().UTC().Format(time.DateOnly) {
+		return ""
+	}
+	return day
+}
+
+// table returns the provider's rates on day, from the cache when fresh
+func (c *Converter) table(ctx context.Context, i int, p RateProvider, day string) (*RateTable, error) {
+	key := tableKey{provider: i, day: day}
+	c.mu.Lock()
+	cached, ok := c.tables[key]
+	c.mu.Unlock()
+	if ok && (day != "" || c.now().Sub(cached.fetchedAt) < c.ttl) {
+		return cached.table, nil
+	}
+
+	table, err, _ := c.group.Do(fmt.Sprintf("%d/%s", i, day), func() (any, error) {
+		var date time.Time
+		if day != "" {
+			date, _ = time.Parse(time.DateOnly, day)
+		}
+		table, err := p.Rates(ctx, date)
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, table)
+		return table, nil
+	})
+	if err != nil {
+		if ok {
+			return cached.table, nil // Stale latest rates beat none
+		}
+		return nil, err
+	}
+	return table.(*RateTable), nil
+}
+
+func (c *Converter) store(key tableKey, table *RateTable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables[key] = cachedTable{table: table, fetchedAt: c.now()}
+}
@@ -0,0 +1,211 @@
+package fx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/money"
+)
+
+const ecbDailyXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2025-06-13">
+			<Cube currency="USD" rate="1.1512"/>
+			<Cube currency="JPY" rate="166.03"/>
+			<Cube currency="GBP" rate="0.85060"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const ecbHistoryXML = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2025-06-13"><Cube currency="USD" rate="1.1512"/></Cube>
+		<Cube time="2025-06-12"><Cube currency="USD" rate="1.1583"/></Cube>
+		<Cube time="2025-06-06"><Cube currency="USD" rate="1.1395"/></Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+func newECBServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/eurofxref-daily.xml":
+			_, _ = w.Write([]byte(ecbDailyXML))
+		case "/eurofxref-hist-90d.xml", "/eurofxref-hist.xml":
+			_, _ = w.Write([]byte(ecbHistoryXML))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRateTable_CrossRates(t *testing.T) {
+	table := &RateTable{Base: "EUR", Rates: map[string]decimal.Decimal{"USD": d("1.25"), "GBP": d("0.5")}}
+
+	rate, ok := table.Rate("EUR", "USD")
+	require.True(t, ok)
+	assert.True(t, rate.Equal(d("1.25")))
+
+	rate, ok = table.Rate("usd", "EUR")
+	require.True(t, ok)
+	assert.True(t, rate.Equal(d("0.8")))
+
+	rate, ok = table.Rate("GBP", "USD")
+	require.True(t, ok)
+	assert.True(t, rate.Equal(d("2.5")))
+
+	_, ok = table.Rate("EUR", "AOA")
+	assert.False(t, ok)
+}
+
+func TestECB_LatestAndHistoricalRates(t *testing.T) {
+	var requests atomic.Int32
+	srv := newECBServer(t, &requests)
+	ecb := NewECB().WithBaseURL(srv.URL)
+	ecb.now = func() time.Time { return time.Date(2025, time.June, 14, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	latest, err := ecb.Rates(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", latest.Base)
+	assert.Equal(t, "2025-06-13", latest.Date.Format(time.DateOnly))
+	assert.True(t, latest.Rates["JPY"].Equal(d("166.03")))
+
+	// A Sunday gets the Friday before it
+	sunday, err := ecb.Rates(ctx, time.Date(2025, time.June, 8, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "2025-06-06", sunday.Date.Format(time.DateOnly))
+	assert.True(t, sunday.Rates["USD"].Equal(d("1.1395")))
+
+	thursday, err := ecb.Rates(ctx, time.Date(2025, time.June, 12, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, thursday.Rates["USD"].Equal(d("1.1583")))
+	assert.Equal(t, int32(2), requests.Load(), "the history is downloaded once")
+
+	_, err = ecb.Rates(ctx, time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestExchangeRateHost_Rates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_key") != "secret" {
+			_, _ = w.Write([]byte(`{"success": false, "error": {"code": 101, "info": "invalid access key"}}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/live":
+			_, _ = w.Write([]byte(`{"success": true, "source": "USD", "quotes": {"USDAOA": 912.5, "USDEUR": 0.87}}`))
+		case "/historical":
+			assert.Equal(t, "2020-03-02", r.URL.Query().Get("date"))
+			_, _ = w.Write([]byte(`{"success": true, "historical": true, "date": "2020-03-02", "source": "USD", "quotes": {"USDEUR": 0.9}}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	live, err := NewExchangeRateHost("secret", "usd").WithBaseURL(srv.URL).Rates(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", live.Base)
+	assert.True(t, live.Rates["AOA"].Equal(d("912.5")))
+
+	old, err := NewExchangeRateHost("secret", "USD").WithBaseURL(srv.URL).Rates(ctx, time.Date(2020, time.March, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, old.Rates["EUR"].Equal(d("0.9")))
+
+	_, err = NewExchangeRateHost("wrong", "USD").WithBaseURL(srv.URL).Rates(ctx, time.Time{})
+	assert.ErrorContains(t, err, "invalid access key")
+}
+
+func TestParseManual(t *testing.T) {
+	m, err := ParseManual("eur: AOA=950.5, CVE=110.265")
+	require.NoError(t, err)
+	table, err := m.Rates(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", table.Base)
+	assert.True(t, table.Rates["CVE"].Equal(d("110.265")))
+
+	for _, spec := range []string{"AOA=950.5", "EUR:AOA", "EUR:AOA=abc", "EUR:AOA=-1"} {
+		_, err := ParseManual(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestConverter_CachesAndOverrides(t *testing.T) {
+	var requests atomic.Int32
+	srv := newECBServer(t, &requests)
+	now := time.Date(2025, time.June, 14, 12, 0, 0, 0, time.UTC)
+	ecb := NewECB().WithBaseURL(srv.URL)
+	ecb.now = func() time.Time { return now }
+	manual := NewManual("EUR", map[string]decimal.Decimal{"USD": d("1.2"), "AOA": d("950")})
+	ctx := context.Background()
+
+	c := NewConverter(ecb)
+	c.now = func() time.Time { return now }
+
+	// Yen have no minor units: €10.00 at 166.03 is 1660 yen
+	yen, err := c.Convert(ctx, money.New(1000, money.EUR), money.JPY, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1660), yen.Amount())
+	assert.Equal(t, money.JPY, yen.Currency())
+
+	_, err = c.Rate(ctx, "EUR", "USD", now) // Today uses the latest rates
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "latest rates are cached")
+
+	old, err := c.Convert(ctx, money.New(10000, money.EUR), money.USD, time.Date(2025, time.June, 8, 15, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, int64(11395), old.Amount())
+
+	now = now.Add(DefaultLatestTTL)
+	_, err = c.Rate(ctx, "EUR", "USD", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load(), "latest rates expire")
+
+	_, err = c.Rate(ctx, "EUR", "AOA", time.Time{})
+	assert.ErrorIs(t, err, ErrRateNotFound)
+
+	// Manual rates listed first override the feed and fill its gaps
+	c = NewConverter(manual, ecb)
+	rate, err := c.Rate(ctx, "USD", "EUR", time.Time{})
+	require.NoError(t, err)
+	assert.True(t, rate.Round(4).Equal(d("0.8333")))
+	kwanza, err := c.Convert(ctx, money.New(200, money.EUR), "AOA", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(190000), kwanza.Amount())
+	rate, err = c.Rate(ctx, "EUR", "GBP", time.Time{})
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(d("0.8506")))
+}
+
+func TestConverter_RefreshKeepsStaleRatesOnFailure(t *testing.T) {
+	var requests atomic.Int32
+	srv := newECBServer(t, &requests)
+	ecb := NewECB().WithBaseURL(srv.URL)
+	c := NewConverter(ecb)
+	ctx := context.Background()
+
+	require.NoError(t, c.Refresh(ctx))
+	srv.Close()
+	assert.Error(t, c.Refresh(ctx))
+
+	c.now = func() time.Time { return time.Now().Add(2 * DefaultLatestTTL) }
+	rate, err := c.Rate(ctx, "EUR", "USD", time.Time{})
+	require.NoError(t, err, "stale rates are used while the feed is down")
+	assert.True(t, rate.Equal(d("1.1512")))
+}
@@ -0,0 +1,58 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Manual serves fixed rates on every date, for currencies the feeds don't
+// cover or rates users agreed on
+type Manual struct {
+	table *RateTable
+}
+
+var _ RateProvider = (*Manual)(nil)
+
+// NewManual creates a provider of fixed rates: units of each currency one
+// unit of base buys
+func NewManual(base string, rates map[string]decimal.Decimal) *Manual {
+	table := &RateTable{Base: strings.ToUpper(base), Rates: make(map[string]decimal.Decimal, len(rates))}
+	for code, rate := range rates {
+		table.Rates[strings.ToUpper(code)] = rate
+	}
+	return &Manual{table: table}
+}
+
+// ParseManual parses rates written as "EUR:AOA=950.5,CVE=110.265", the base
+// currency followed by what one unit of it buys
+func ParseManual(spec string) (*Manual, error) {
+	base, list, ok := strings.Cut(spec, ":")
+	if !ok || strings.TrimSpace(base) == "" {
+		return nil, fmt.Errorf("invalid manual rates %q: want BASE:CUR=rate,...", spec)
+	}
+	rates := make(map[string]decimal.Decimal)
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid manual rate %q: want CUR=rate", entry)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("invalid manual rate %q: want a positive number", entry)
+		}
+		rates[strings.TrimSpace(code)] = rate
+	}
+	return NewManual(strings.TrimSpace(base), rates), nil
+}
+
+// Rates returns the fixed rates, dated date
+func (m *Manual) Rates(_ context.Context, date time.Time) (*RateTable, error) {
+	return &RateTable{Base: m.table.Base, Date: date, Rates: m.table.Rates}, nil
+}
@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/Rhymond/go-money"
@@ -36,42 +38,38 @@ type Money struct {
 
 // New creates a new Money value from cents (minor units) and currency code.
 // For JPY and other zero-decimal currencies, amount is the actual value.
+// Currency codes are case-insensitive.
 func New(amountCents int64, currencyCode string) *Money {
 	return &Money{
-		m: money.New(amountCents, currencyCode),
+		m: money.New(amountCents, strings.ToUpper(currencyCode)),
 	}
 }
 
-// NewFromFloat creates Money from a floating-point value.
+// NewFromFloat creates Money from a floating-point value in major units,
+// rounded to the currency's minor units (cents for USD, yen for JPY).
 // Use with caution - prefer New() with integer cents when possible.
 func NewFromFloat(amount float64, currencyCode string) *Money {
-	currency := money.GetCurrency(currencyCode)
-	if currency == nil {
-		currency = money.GetCurrency(USD)
-	}
-
-	// Convert to cents using decimal for precision
-	d := decimal.NewFromFloat(amount)
-	multiplier := decimal.New(1, int32(currency.Fraction))
-	cents := d.Mul(multiplier).Round(0).IntPart()
-
-	return New(cents, currencyCode)
+	return NewFromDecimal(decimal.NewFromFloat(amount), currencyCode)
 }
 
-// NewFromDecimal creates Money from a decimal.Decimal value.
+// NewFromDecimal creates Money from a decimal.Decimal value in major units.
 // This is the safest way to create Money from a non-integer value.
 func NewFromDecimal(amount decimal.Decimal, currencyCode string) *Money {
-	currency := money.GetCurrency(currencyCode)
-	if currency == nil {
-		currency = money.GetCurrency(USD)
-	}
-
-	multiplier := decimal.New(1, int32(currency.Fraction))
+	multiplier := decimal.New(1, int32(currencyFraction(currencyCode)))
 	cents := amount.Mul(multiplier).Round(0).IntPart()
 
 	return New(cents, currencyCode)
 }
 
+// currencyFraction returns the number of minor unit digits of the currency,
+// 2 for currencies go-money doesn't know
+func currencyFraction(currencyCode string) int {
+	if currency := money.GetCurrency(currencyCode); currency != nil {
+		return currency.Fraction
+	}
+	return 2
+}
+
 // NewFromString parses a string amount and currency.
 // Accepts formats like "100.50", "1,234.56", "1.234,56" (European)
 func NewFromString(amount string, currencyCode string, europeanFormat bool) (*Money, error) {
@@ -130,6 +128,14 @@ func (m *Money) CurrencySymbol() string {
 	return m.m.Currency().Grapheme
 }
 
+// Fraction returns the number of decimal places of the currency (e.g., 2 for EUR, 0 for JPY)
+func (m *Money) Fraction() int {
+	if m == nil || m.m == nil {
+		return 2
+	}
+	return m.m.Currency().Fraction
+}
+
 // IsZero returns true if the amount is zero
 func (m *Money) IsZero() bool {
 	return m == nil || m.m == nil || m.m.IsZero()
@@ -341,6 +347,115 @@ func (m *Money) Allocate(ratios []int) ([]*Money, error) {
 	return result, nil
 }
 
+// RemainderStrategy decides who gets the minor units left over when an
+// allocation doesn't divide evenly
+type RemainderStrategy int
+
+const (
+	// LargestRemainder gives one unit each to the parts whose exact share
+	// lost the most to rounding down (Hamilton's method); ties go to the
+	// earlier part
+	LargestRemainder RemainderStrategy = iota
+	// FirstBucket gives all leftover units to the first part with a weight
+	FirstBucket
+	// RoundRobin gives one unit each to the parts with a weight, in order
+	RoundRobin
+)
+
+// String returns the strategy's name
+func (s RemainderStrategy) String() string {
+	switch s {
+	case LargestRemainder:
+		return "largest_remainder"
+	case FirstBucket:
+		return "first_bucket"
+	case RoundRobin:
+		return "round_robin"
+	default:
+		return fmt.Sprintf("RemainderStrategy(%d)", int(s))
+	}
+}
+
+// AllocateDecimal splits money according to decimal weights, which are
+// relative like Allocate's ratios but keep fractional precision (e.g. 33.3,
+// 33.3, 33.4). Each part gets its exact share rounded down, then strategy
+// hands out the units left over. Parts always sum to m. Weights must not be
+// negative and at least one must be positive.
+func (m *Money) AllocateDecimal(weights []decimal.Decimal, strategy RemainderStrategy) ([]*Money, error) {
+	if m == nil || m.m == nil {
+		return nil, errors.New("cannot allocate nil money")
+	}
+	if len(weights) == 0 {
+		return nil, errors.New("no weights to allocate by")
+	}
+	total := decimal.Zero
+	for _, w := range weights {
+		if w.IsNegative() {
+			return nil, errors.New("weights must not be negative")
+		}
+		total = total.Add(w)
+	}
+	if !total.IsPositive() {
+		return nil, errors.New("at least one weight must be positive")
+	}
+
+	// Allocate the absolute amount so rounding down always moves towards zero
+	amount := m.Amount()
+	sign := int64(1)
+	if amount < 0 {
+		sign = -1
+	}
+	abs := decimal.NewFromInt(amount * sign)
+
+	shares := make([]int64, len(weights))
+	remainders := make([]decimal.Decimal, len(weights))
+	left := amount * sign
+	for i, w := range weights {
+		q, r := abs.Mul(w).QuoRem(total, 0)
+		shares[i] = q.IntPart()
+		remainders[i] = r // Over total, so remainders compare directly
+		left -= shares[i]
+	}
+
+	switch strategy {
+	case LargestRemainder:
+		order := make([]int, len(weights))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return remainders[order[a]].GreaterThan(remainders[order[b]])
+		})
+		for i := 0; left > 0; i++ {
+			shares[order[i]]++
+			left--
+		}
+	case FirstBucket:
+		for i, w := range weights {
+			if w.IsPositive() {
+				shares[i] += left
+				break
+			}
+		}
+	case RoundRobin:
+		for i := 0; left > 0; i = (i + 1) % len(weights) {
+			if weights[i].IsPositive() {
+				shares[i]++
+				left--
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown remainder strategy %d", int(strategy))
+	}
+
+	currencyCode := m.Currency()
+	result := make([]*Money, len(shares))
+	for i, share := range shares {
+		result[i] = New(share*sign, currencyCode)
+	}
+	return result, nil
+}
+
 // JSON marshaling
 func (m *Money) MarshalJSON() ([]byte, error) {
 	if m == nil || m.m == nil {
@@ -361,29 +476,73 @@ func (m *Money) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &v); err != nil {
 		return err
 	}
-	m.m = money.New(v.Amount, v.Currency)
+	m.m = New(v.Amount, v.Currency).m
 	return nil
 }
 
-// SQL scanning
+// Scan implements sql.Scanner. It accepts:
+//   - an integer amount in minor units, as a number or text
+//   - a float amount in major units
+//   - an amount in minor units and currency as a row in text form, e.g.
+//     "(1234,EUR)" from SELECT (amount_minor, currency_code)::TEXT
+//
+// Amounts without a currency keep the currency m already has, so scan into
+// Zero(currency) when the column's currency is known; otherwise they are USD.
+// NULL, including a NULL amount in a row, scans to a nil Money.
 func (m *Money) Scan(value interface{}) error {
 	if value == nil {
 		m.m = nil
 		return nil
 	}
 
+	currencyCode := m.Currency()
+	if currencyCode == "" {
+		currencyCode = USD // Default to USD if only amount provided
+	}
+
 	switch v := value.(type) {
 	case int64:
-		m.m = money.New(v, USD) // Default to USD if only amount provided
+		m.m = New(v, currencyCode).m
 		return nil
 	case float64:
-		m.m = money.New(int64(v*100), USD)
+		m.m = NewFromFloat(v, currencyCode).m
 		return nil
+	case []byte:
+		return m.scanString(string(v), currencyCode)
+	case string:
+		return m.scanString(v, currencyCode)
 	default:
 		return fmt.Errorf("cannot scan %T into Money", value)
 	}
 }
 
+// scanString scans an amount or an (amount,currency) row
+func (m *Money) scanString(s, currencyCode string) error {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		amount, currency, ok := strings.Cut(s[1:len(s)-1], ",")
+		if !ok {
+			return fmt.Errorf("cannot scan %q into Money: want (amount,currency)", s)
+		}
+		if amount = strings.TrimSpace(amount); amount == "" {
+			m.m = nil
+			return nil
+		}
+		if currency = strings.Trim(strings.TrimSpace(currency), `"`); currency != "" {
+			currencyCode = currency
+		}
+		s = amount
+	}
+
+	minor, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into Money: want an amount in minor units", s)
+	}
+	m.m = New(minor, currencyCode).m
+	return nil
+}
+
+// Value implements driver.Valuer, storing the amount in minor units
 func (m *Money) Value() (driver.Value, error) {
 	if m == nil || m.m == nil {
 		return nil, nil
@@ -391,6 +550,69 @@ func (m *Money) Value() (driver.Value, error) {
 	return m.Amount(), nil
 }
 
+// MoneyNullable is a Money that may be NULL, for nullable amount columns.
+// Like sql.NullInt64, Valid is false for NULL.
+type MoneyNullable struct {
+	Money Money
+	Valid bool
+}
+
+// NewNullable returns m as a MoneyNullable, NULL when m is nil
+func NewNullable(m *Money) MoneyNullable {
+	if m == nil || m.m == nil {
+		return MoneyNullable{}
+	}
+	return MoneyNullable{Money: *m, Valid: true}
+}
+
+// Ptr returns the Money, or nil when NULL
+func (n MoneyNullable) Ptr() *Money {
+	if !n.Valid {
+		return nil
+	}
+	m := n.Money
+	return &m
+}
+
+// Scan implements sql.Scanner with the formats Money.Scan accepts. Set
+// n.Money to Zero(currency) beforehand to scan bare amounts in that currency.
+func (n *MoneyNullable) Scan(value interface{}) error {
+	if err := n.Money.Scan(value); err != nil {
+		return err
+	}
+	n.Valid = n.Money.m != nil
+	return nil
+}
+
+// Value implements driver.Valuer
+func (n MoneyNullable) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Money.Value()
+}
+
+// MarshalJSON encodes NULL as null and other values like Money
+func (n MoneyNullable) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return n.Money.MarshalJSON()
+}
+
+// UnmarshalJSON decodes null as NULL and other values like Money
+func (n *MoneyNullable) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*n = MoneyNullable{}
+		return nil
+	}
+	if err := n.Money.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
 // SameCurrency returns true if both have the same currency
 func (m *Money) SameCurrency(other *Money) bool {
 	if m == nil || m.m == nil || other == nil || other.m == nil {
@@ -542,9 +764,71 @@ func (m *Money) WithSimpleInterest(annualRate float64, years float64) *Money {
 	return result
 }
 
+// powDigits is how many significant digits Pow keeps in intermediate
+// results: far more than any amount has, without letting exact products
+// grow without bound
+const powDigits = 40
+
+// Pow returns base raised to exponent. Integer exponents are computed by
+// repeated squaring; fractional ones as exp(exponent × ln(base)). Errors for
+// 0^0, 0 to a negative power and negative bases with fractional exponents.
+func Pow(base, exponent decimal.Decimal) (decimal.Decimal, error) {
+	if base.IsZero() {
+		if exponent.Sign() <= 0 {
+			return decimal.Zero, fmt.Errorf("cannot raise 0 to %s", exponent)
+		}
+		return decimal.Zero, nil
+	}
+	if exponent.IsNegative() {
+		result, err := Pow(base, exponent.Neg())
+		if err != nil {
+			return decimal.Zero, err
+		}
+		places := powDigits + int32(result.NumDigits()) + result.Exponent()
+		return roundSignificant(decimal.NewFromInt(1).DivRound(result, places), powDigits), nil
+	}
+
+	intPart := exponent.Truncate(0)
+	fracPart := exponent.Sub(intPart)
+	if base.IsNegative() && !fracPart.IsZero() {
+		return decimal.Zero, fmt.Errorf("cannot raise negative %s to fractional %s", base, exponent)
+	}
+
+	result := decimal.NewFromInt(1)
+	square := base
+	for n := intPart.BigInt(); n.Sign() > 0; n.Rsh(n, 1) {
+		if n.Bit(0) == 1 {
+			result = roundSignificant(result.Mul(square), powDigits)
+		}
+		square = roundSignificant(square.Mul(square), powDigits)
+	}
+
+	if !fracPart.IsZero() {
+		ln, err := base.Ln(powDigits)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		frac, err := ln.Mul(fracPart).ExpTaylor(powDigits)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		result = roundSignificant(result.Mul(frac), powDigits)
+	}
+	return result, nil
+}
+
+// roundSignificant rounds d to digits significant digits
+func roundSignificant(d decimal.Decimal, digits int32) decimal.Decimal {
+	if d.IsZero() {
+		return d
+	}
+	intDigits := int32(d.NumDigits()) + d.Exponent() // Digits before the point; negative for leading zeros after it
+	return d.Round(digits - intDigits)
+}
+
 // CompoundInterest calculates compound interest.
 // annualRate is the annual interest rate as a percentage (e.g., 5.5 for 5.5%)
-// years is the time period in years
+// years is the time period in years (can be fractional, e.g., 2.5)
 // compoundingsPerYear is how often interest compounds (e.g., 12 for monthly, 365 for daily)
 func (m *Money) CompoundInterest(annualRate float64, years float64, compoundingsPerYear int) *Money {
 	if m == nil || m.m == nil || compoundingsPerYear <= 0 {
@@ -558,32 +842,13 @@ func (m *Money) CompoundInterest(annualRate float64, years float64, compoundings
 	n := decimal.NewFromInt(int64(compoundingsPerYear))
 	t := decimal.NewFromFloat(years)
 
-	// Calculate (1 + r/n)
-	ratePerPeriod := rate.Div(n)
-	base := decimal.NewFromInt(1).Add(ratePerPeriod)
-
-	// Calculate exponent n*t
-	exponent := n.Mul(t).IntPart()
-
-	// Calculate base^exponent using repeated multiplication for precision
-	result := decimal.NewFromInt(1)
-	for i := int64(0); i < exponent; i++ {
-		result = result.Mul(base)
-	}
-
-	// Handle fractional exponent part if any
-	fractionalExp := n.Mul(t).Sub(decimal.NewFromInt(exponent))
-	if !fractionalExp.IsZero() {
-		// Approximate fractional exponent using linear interpolation
-		// This is a simplification; for exact results, use math.Pow
-		fractionalResult := decimal.NewFromInt(1).Add(ratePerPeriod.Mul(fractionalExp))
-		result = result.Mul(fractionalResult)
+	base := decimal.NewFromInt(1).Add(rate.DivRound(n, powDigits))
+	growth, err := Pow(base, n.Mul(t))
+	if err != nil {
+		return Zero(m.Currency())
 	}
 
-	// Final amount
-	finalAmount := principal.Mul(result)
-	interest := finalAmount.Sub(principal)
-
+	interest := principal.Mul(growth).Sub(principal)
 	return NewFromDecimal(interest, m.Currency())
 }
 
@@ -614,14 +879,12 @@ func (m *Money) MonthlyPayment(annualRate float64, months int) *Money {
 	// M = P * [r(1+r)^n] / [(1+r)^n - 1]
 	// Where: P = principal, r = monthly rate, n = number of months
 	principal := m.ToDecimal()
-	monthlyRate := decimal.NewFromFloat(annualRate).Div(decimal.NewFromInt(100)).Div(decimal.NewFromInt(12))
-	n := int64(months)
+	monthlyRate := decimal.NewFromFloat(annualRate).Div(decimal.NewFromInt(100)).DivRound(decimal.NewFromInt(12), powDigits)
 
 	// Calculate (1+r)^n
-	onePlusR := decimal.NewFromInt(1).Add(monthlyRate)
-	power := decimal.NewFromInt(1)
-	for i := int64(0); i < n; i++ {
-		power = power.Mul(onePlusR)
+	power, err := Pow(decimal.NewFromInt(1).Add(monthlyRate), decimal.NewFromInt(int64(months)))
+	if err != nil {
+		return Zero(m.Currency())
 	}
 
 	// Calculate numerator: r * (1+r)^n
@@ -631,7 +894,7 @@ func (m *Money) MonthlyPayment(annualRate float64, months int) *Money {
 	denominator := power.Sub(decimal.NewFromInt(1))
 
 	// Monthly payment
-	payment := principal.Mul(numerator).Div(denominator)
+	payment := principal.Mul(numerator).DivRound(denominator, powDigits)
 
 	return NewFromDecimal(payment, m.Currency())
 }
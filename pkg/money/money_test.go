@@ -3,6 +3,7 @@ package money
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -299,32 +300,90 @@ func TestSimpleInterest(t *testing.T) {
 	assert.Equal(t, int64(1100000), withInterest.Amount()) // $11,000
 }
 
-func TestCompoundInterest(t *testing.T) {
-	// $10,000 at 5% compounded monthly for 1 year
-	principal := New(1000000, USD) // $10,000
-	interest := principal.CompoundInterest(5, 1, 12)
+func TestPow(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name     string
+		base     string
+		exponent string
+		want     string
+		places   int32
+	}{
+		{"integer", "2", "10", "1024", 0},
+		{"zero exponent", "1.05", "0", "1", 0},
+		{"square root", "2", "0.5", "1.41421356237309504880", 20},
+		{"fractional", "1.005", "30.6", "1.16488081", 8},
+		{"negative exponent", "2", "-3", "0.125", 3},
+		{"below one", "0.5", "100", "0.00000000000000000000000000000078886090522101180541", 50},
+		// Daily compounding at 5% over 30 years
+		{"many periods", "1.000136986301369863013698630136986301", "10950", "4.48122869", 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Pow(d(tt.base), d(tt.exponent))
+			require.NoError(t, err)
+			assert.Equal(t, d(tt.want).StringFixed(tt.places), got.StringFixed(tt.places))
+		})
+	}
 
-	// Expected: ~$511.62 (5.1162% effective rate)
-	// Allow some tolerance for decimal precision
-	assert.InDelta(t, 51162, interest.Amount(), 100)
+	for _, tt := range []struct{ base, exponent string }{{"0", "0"}, {"0", "-1"}, {"-2", "0.5"}} {
+		_, err := Pow(d(tt.base), d(tt.exponent))
+		assert.Error(t, err, "%s^%s", tt.base, tt.exponent)
+	}
 }
 
-func TestMonthlyPayment(t *testing.T) {
-	// $200,000 mortgage at 6% for 30 years (360 months)
-	principal := New(20000000, USD) // $200,000
-	monthly := principal.MonthlyPayment(6, 360)
+// Golden values from the closed-form formulas at 60-digit precision, rounded
+// to the cent
+func TestCompoundInterest(t *testing.T) {
+	tests := []struct {
+		name        string
+		principal   int64
+		rate, years float64
+		perYear     int
+		want        int64
+	}{
+		{"monthly for a year", 1000000, 5, 1, 12, 51162},
+		{"daily for 30 years", 1000000, 5, 30, 365, 3481229},
+		{"quarterly for 10 years", 250000, 3.5, 10, 4, 104227},
+		// Linear interpolation of the half period gave $50.00
+		{"half a year compounded yearly", 100000, 10, 0.5, 1, 4881},
+		{"fractional periods", 100000, 6, 2.55, 12, 16488},
+	}
 
-	// Expected: ~$1,199.10 per month
-	assert.InDelta(t, 119910, monthly.Amount(), 100)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interest := New(tt.principal, USD).CompoundInterest(tt.rate, tt.years, tt.perYear)
+			assert.Equal(t, tt.want, interest.Amount())
+		})
+	}
+}
 
-	// Total cost and interest
-	totalCost := principal.TotalLoanCost(6, 360)
-	totalInterest := principal.LoanInterest(6, 360)
+// Payments from standard amortization tables
+func TestMonthlyPayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     int64
+		rate          float64
+		months        int
+		wantPayment   int64
+		wantInterest  int64
+		wantTotalCost int64
+	}{
+		{"$200k at 6% for 30 years", 20000000, 6, 360, 119910, 23167600, 43167600},
+		{"$100k at 4.5% for 30 years", 10000000, 4.5, 360, 50669, 8240840, 18240840},
+		{"$250k at 3.75% for 15 years", 25000000, 3.75, 180, 181806, 7725080, 32725080},
+		{"$25k car loan at 7.9% for 5 years", 2500000, 7.9, 60, 50571, 534260, 3034260},
+	}
 
-	// Total payments: ~$431,676
-	assert.InDelta(t, 43167600, totalCost.Amount(), 50000)
-	// Total interest: ~$231,676
-	assert.InDelta(t, 23167600, totalInterest.Amount(), 50000)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := New(tt.principal, USD)
+			assert.Equal(t, tt.wantPayment, principal.MonthlyPayment(tt.rate, tt.months).Amount())
+			assert.Equal(t, tt.wantTotalCost, principal.TotalLoanCost(tt.rate, tt.months).Amount())
+			assert.Equal(t, tt.wantInterest, principal.LoanInterest(tt.rate, tt.months).Amount())
+		})
+	}
 }
 
 func TestMonthlyPaymentZeroInterest(t *testing.T) {
@@ -460,6 +519,70 @@ func TestAllocate(t *testing.T) {
 	assert.InDelta(t, 2000, parts[2].Amount(), 1)
 }
 
+func TestAllocateDecimal(t *testing.T) {
+	d := decimal.RequireFromString
+	thirds := []decimal.Decimal{d("33.3"), d("33.3"), d("33.4")}
+	amounts := func(parts []*Money) []int64 {
+		out := make([]int64, len(parts))
+		for i, p := range parts {
+			out[i] = p.Amount()
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		amount   int64
+		weights  []decimal.Decimal
+		strategy RemainderStrategy
+		want     []int64
+	}{
+		{"even split", 10000, []decimal.Decimal{d("1"), d("1")}, LargestRemainder, []int64{5000, 5000}},
+		// Exact shares 33.3, 33.3, 33.4: the last loses most to rounding down
+		{"largest remainder", 100, thirds, LargestRemainder, []int64{33, 33, 34}},
+		// Exact shares 3.33, 3.33, 3.34: one unit left, ties go first
+		{"largest remainder ties", 10, []decimal.Decimal{d("1"), d("1"), d("1")}, LargestRemainder, []int64{4, 3, 3}},
+		{"first bucket", 101, thirds, FirstBucket, []int64{35, 33, 33}},
+		{"round robin", 101, thirds, RoundRobin, []int64{34, 34, 33}},
+		{"zero weights get nothing", 101, []decimal.Decimal{d("0"), d("1"), d("2")}, FirstBucket, []int64{0, 34, 67}},
+		{"round robin skips zero weights", 5, []decimal.Decimal{d("0"), d("1"), d("1"), d("1")}, RoundRobin, []int64{0, 2, 2, 1}},
+		{"negative amounts", -100, thirds, LargestRemainder, []int64{-33, -33, -34}},
+		{"fractional weights", 1000, []decimal.Decimal{d("0.125"), d("0.875")}, LargestRemainder, []int64{125, 875}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := New(tt.amount, USD).AllocateDecimal(tt.weights, tt.strategy)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, amounts(parts))
+			for _, p := range parts {
+				assert.Equal(t, USD, p.Currency())
+			}
+		})
+	}
+
+	t.Run("yen", func(t *testing.T) {
+		parts, err := New(1000, JPY).AllocateDecimal(thirds, LargestRemainder)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{333, 333, 334}, amounts(parts))
+		assert.Equal(t, JPY, parts[0].Currency())
+	})
+
+	t.Run("invalid weights", func(t *testing.T) {
+		m := New(100, USD)
+		_, err := m.AllocateDecimal(nil, LargestRemainder)
+		assert.Error(t, err)
+		_, err = m.AllocateDecimal([]decimal.Decimal{d("0"), d("0")}, LargestRemainder)
+		assert.Error(t, err)
+		_, err = m.AllocateDecimal([]decimal.Decimal{d("2"), d("-1")}, LargestRemainder)
+		assert.Error(t, err)
+		_, err = m.AllocateDecimal(thirds, RemainderStrategy(99))
+		assert.Error(t, err)
+		_, err = (*Money)(nil).AllocateDecimal(thirds, LargestRemainder)
+		assert.Error(t, err)
+	})
+}
+
 // ============================================================================
 // Currency Conversion Tests
 // ============================================================================
@@ -558,6 +681,144 @@ func TestToFloat64(t *testing.T) {
 	assert.InDelta(t, 123.45, f, 0.001)
 }
 
+// ============================================================================
+// Zero-Decimal Currency Tests
+// ============================================================================
+
+func TestZeroDecimalCurrencies(t *testing.T) {
+	t.Run("float amounts are whole yen", func(t *testing.T) {
+		assert.Equal(t, int64(1234), NewFromFloat(1234, JPY).Amount())
+		assert.Equal(t, int64(1235), NewFromFloat(1234.5, JPY).Amount())
+		assert.Equal(t, int64(1234), NewFromFloat(1234, "jpy").Amount(), "codes are case-insensitive")
+		assert.Equal(t, JPY, NewFromFloat(1234, "jpy").Currency())
+	})
+
+	t.Run("strings and decimals", func(t *testing.T) {
+		m, err := NewFromString("¥1,234", JPY, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1234), m.Amount())
+		assert.Equal(t, int64(100), NewFromDecimal(decimal.NewFromInt(100), JPY).Amount())
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		m := New(1234, JPY)
+		assert.Equal(t, 0, m.Fraction())
+		assert.Equal(t, "1234", m.String())
+		assert.InDelta(t, 1234.0, m.ToFloat64(), 0.0001)
+		assert.Contains(t, m.Display(), "1,234")
+	})
+
+	t.Run("arithmetic", func(t *testing.T) {
+		assert.Equal(t, int64(100), New(1000, JPY).Percentage(10).Amount())
+		// $10.00 at 150 yen to the dollar
+		assert.Equal(t, int64(1500), New(1000, USD).Convert(JPY, decimal.NewFromInt(150)).Amount())
+		// 1500 yen back to dollars
+		assert.Equal(t, int64(1000), New(1500, JPY).Convert(USD, decimal.RequireFromString("0.0066666667")).Amount())
+
+		parts, err := New(1000, JPY).Split(3)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{334, 333, 333}, []int64{parts[0].Amount(), parts[1].Amount(), parts[2].Amount()})
+	})
+
+	t.Run("three-decimal currencies", func(t *testing.T) {
+		assert.Equal(t, int64(12345), NewFromFloat(12.345, "KWD").Amount())
+		assert.Equal(t, "12.345", New(12345, "KWD").String())
+	})
+}
+
+// ============================================================================
+// SQL Scanning Tests
+// ============================================================================
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name         string
+		into         *Money
+		value        interface{}
+		wantAmount   int64
+		wantCurrency string
+	}{
+		{"minor units default to USD", &Money{}, int64(1234), 1234, USD},
+		{"minor units keep the currency", Zero(JPY), int64(1234), 1234, JPY},
+		{"float in major units", &Money{}, 0.29, 29, USD},
+		{"float in yen", Zero(JPY), 1234.0, 1234, JPY},
+		{"integer text", Zero(EUR), []byte("1234"), 1234, EUR},
+		{"row with currency", &Money{}, "(1234,EUR)", 1234, EUR},
+		{"row in yen", Zero(USD), []byte("(1234,JPY)"), 1234, JPY},
+		{"row with quoted currency", &Money{}, `(-500,"GBP")`, -500, GBP},
+		{"row without currency", Zero(CHF), "(700,)", 700, CHF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.into.Scan(tt.value))
+			assert.Equal(t, tt.wantAmount, tt.into.Amount())
+			assert.Equal(t, tt.wantCurrency, tt.into.Currency())
+		})
+	}
+}
+
+func TestScanNullAndInvalid(t *testing.T) {
+	m := New(100, EUR)
+	require.NoError(t, m.Scan(nil))
+	assert.Equal(t, "", m.Currency())
+
+	m = New(100, EUR)
+	require.NoError(t, m.Scan("(,EUR)"))
+	assert.Equal(t, "", m.Currency(), "a NULL amount in a row is NULL")
+
+	assert.Error(t, (&Money{}).Scan("abc"))
+	assert.Error(t, (&Money{}).Scan("12.34"), "text amounts are in minor units")
+	assert.Error(t, (&Money{}).Scan("(1234)"))
+	assert.Error(t, (&Money{}).Scan(true))
+}
+
+func TestMoneyNullable(t *testing.T) {
+	t.Run("scan", func(t *testing.T) {
+		var n MoneyNullable
+		require.NoError(t, n.Scan("(1234,JPY)"))
+		assert.True(t, n.Valid)
+		assert.Equal(t, int64(1234), n.Ptr().Amount())
+		assert.Equal(t, JPY, n.Ptr().Currency())
+
+		require.NoError(t, n.Scan(nil))
+		assert.False(t, n.Valid)
+		assert.Nil(t, n.Ptr())
+
+		n = MoneyNullable{Money: *Zero(EUR)}
+		require.NoError(t, n.Scan(int64(250)))
+		assert.Equal(t, EUR, n.Ptr().Currency())
+	})
+
+	t.Run("value", func(t *testing.T) {
+		v, err := MoneyNullable{}.Value()
+		require.NoError(t, err)
+		assert.Nil(t, v)
+
+		v, err = NewNullable(New(250, EUR)).Value()
+		require.NoError(t, err)
+		assert.Equal(t, int64(250), v)
+
+		assert.False(t, NewNullable(nil).Valid)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(struct {
+			Budget MoneyNullable `json:"budget"`
+		}{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"budget": null}`, string(data))
+
+		var n MoneyNullable
+		require.NoError(t, json.Unmarshal([]byte(`{"amount": 500, "currency": "JPY"}`), &n))
+		assert.True(t, n.Valid)
+		assert.Equal(t, int64(500), n.Ptr().Amount())
+
+		require.NoError(t, json.Unmarshal([]byte(`null`), &n))
+		assert.False(t, n.Valid)
+	})
+}
+
 // ============================================================================
 // Edge Cases and Nil Safety Tests
 // ============================================================================
@@ -647,6 +908,16 @@ func TestTestDataGenerator(t *testing.T) {
 		txs := gen.MonthlyTransactionSet(USD)
 		assert.Greater(t, len(txs), 20)
 	})
+
+	t.Run("generates a month of transactions", func(t *testing.T) {
+		month := time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC)
+		txs := gen.MonthOfTransactions(USD, month, New(400000, USD))
+		require.Greater(t, len(txs), 25)
+		assert.Equal(t, int64(400000), txs[0].Amount.Amount(), "the salary comes first")
+		for _, tx := range txs {
+			assert.Equal(t, time.February, tx.Date.Month())
+		}
+	})
 }
 
 // ============================================================================
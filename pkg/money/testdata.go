@@ -457,6 +457,47 @@ func (g *TestDataGenerator) MonthlyTransactionSet(currency string) []TestTransac
 	return txs
 }
 
+// MonthOfTransactions generates a realistic month of transactions dated within
+// the month of month: the salary on the first, 5-10 bills in the first week
+// and 20-40 small daily expenses.
+func (g *TestDataGenerator) MonthOfTransactions(currency string, month time.Time, salary *Money) []TestTransaction {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	days := start.AddDate(0, 1, -1).Day()
+	on := func(day int) time.Time {
+		return start.AddDate(0, 0, day-1).Add(time.Duration(g.faker.Number(8, 21)) * time.Hour)
+	}
+
+	txs := make([]TestTransaction, 0, 50)
+	txs = append(txs, TestTransaction{
+		ID:          uuid.New(),
+		Date:        on(1),
+		Description: "Monthly salary deposit",
+		Amount:      salary,
+		Category:    "Salary",
+		Merchant:    g.faker.Company(),
+		Tags:        []string{"recurring", "monthly"},
+	})
+
+	billCount := g.faker.Number(5, 10)
+	for i := 0; i < billCount; i++ {
+		tx := g.ExpenseTransaction(currency)
+		tx.Date = on(g.faker.Number(1, 7))
+		tx.Amount = g.Bill(currency).Negate()
+		tx.Category = "Bills & Utilities"
+		txs = append(txs, tx)
+	}
+
+	expenseCount := g.faker.Number(20, 40)
+	for i := 0; i < expenseCount; i++ {
+		tx := g.ExpenseTransaction(currency)
+		tx.Date = on(g.faker.Number(1, days))
+		tx.Amount = g.SmallPurchase(currency).Negate()
+		txs = append(txs, tx)
+	}
+
+	return txs
+}
+
 // TaxableTransactionSet generates transactions suitable for tax testing.
 func (g *TestDataGenerator) TaxableTransactionSet(currency string, taxRate float64) []TestTransaction {
 	txs := make([]TestTransaction, 0, 20)
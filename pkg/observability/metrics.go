@@ -37,6 +37,106 @@ var (
 		},
 		[]string{"procedure"},
 	)
+
+	// CategorizationPredictionsTotal tracks categories assigned by the
+	// categorization engine, by what matched ("rule" or "merchant")
+	CategorizationPredictionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "echo_categorization_predictions_total",
+			Help: "Total number of transactions categorized automatically",
+		},
+		[]string{"source"},
+	)
+
+	// CategorizationDescriptionsTotal tracks descriptions run through batch
+	// categorization, by method ("fast" or "standard"); its rate is throughput
+	CategorizationDescriptionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "echo_categorization_descriptions_total",
+			Help: "Total number of descriptions run through batch categorization",
+		},
+		[]string{"method"},
+	)
+
+	// CategorizationBatchDuration tracks how long batch categorization takes
+	CategorizationBatchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "echo_categorization_batch_duration_seconds",
+			Help:    "Batch categorization duration in seconds",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		},
+		[]string{"method"},
+	)
+
+	// ImportRowsTotal tracks imported file rows by outcome ("imported" or
+	// "failed"); its rate is import rows per second
+	ImportRowsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "echo_import_rows_total",
+			Help: "Total number of file rows processed by imports",
+		},
+		[]string{"outcome"},
+	)
+
+	// ImportDuration tracks how long import jobs take, by final status
+	ImportDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "echo_import_duration_seconds",
+			Help:    "Import job duration in seconds",
+			Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+		},
+		[]string{"status"},
+	)
+
+	// CategorizationCorrectionsTotal tracks users overriding an automatic category
+	CategorizationCorrectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "echo_categorization_corrections_total",
+			Help: "Total number of automatic categories corrected by users",
+		},
+		[]string{"source"},
+	)
+
+	// WorkQueueWait tracks how long background tasks wait for a worker, by
+	// priority class; it's the latency a busy class adds
+	WorkQueueWait = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "echo_work_queue_wait_seconds",
+			Help:    "Time background tasks wait for a worker in seconds",
+			Buckets: []float64{.005, .01, .05, .1, .5, 1, 5, 30, 60, 300, 900},
+		},
+		[]string{"class"},
+	)
+
+	// WorkQueueRunDuration tracks how long background tasks run, by priority
+	// class and status ("ok", "failed" or "panicked")
+	WorkQueueRunDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "echo_work_queue_run_duration_seconds",
+			Help:    "Background task run duration in seconds",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 30, 60, 300, 900, 1800},
+		},
+		[]string{"class", "status"},
+	)
+
+	// WorkQueueDepth tracks background tasks waiting for a worker, by priority class
+	WorkQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "echo_work_queue_depth",
+			Help: "Number of background tasks waiting for a worker",
+		},
+		[]string{"class"},
+	)
+
+	// WorkQueueRejectedTotal tracks background tasks turned away because their
+	// class's backlog was full or the queue had stopped
+	WorkQueueRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "echo_work_queue_rejected_total",
+			Help: "Total number of background tasks rejected",
+		},
+		[]string{"class"},
+	)
 )
 
 // NewMetricsInterceptor creates an interceptor that collects Prometheus metrics
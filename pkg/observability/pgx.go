@@ -0,0 +1,103 @@
+package observability
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLength keeps long generated statements out of span attributes
+const maxStatementLength = 2000
+
+// DBQueryDuration tracks database query duration by SQL operation
+var DBQueryDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "echo_db_query_duration_seconds",
+		Help:    "Database query duration in seconds",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	},
+	[]string{"operation", "status"},
+)
+
+// QueryTracer traces and times every query run through a pgx connection.
+// Install it on pgx.ConnConfig.Tracer; queries become children of the RPC span.
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+var _ pgx.QueryTracer = (*QueryTracer)(nil)
+
+// NewQueryTracer creates a query tracer using the global tracer provider
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{tracer: otel.Tracer("echo/db")}
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	operation string
+	at        time.Time
+	span      trace.Span
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+	statement := data.SQL
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	ctx, span := t.tracer.Start(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(statement),
+		))
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{operation: operation, at: time.Now(), span: span})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+	status := "ok"
+	if data.Err != nil {
+		status = "error"
+		start.span.RecordError(data.Err)
+		start.span.SetStatus(codes.Error, data.Err.Error())
+	}
+	start.span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	start.span.End()
+	DBQueryDuration.WithLabelValues(start.operation, status).Observe(time.Since(start.at).Seconds())
+}
+
+// sqlOperation is the statement's leading keyword, e.g. SELECT, after any comment lines
+func sqlOperation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "--") {
+		_, rest, _ := strings.Cut(sql, "\n")
+		sql = strings.TrimSpace(rest)
+	}
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	switch op := strings.ToUpper(fields[0]); op {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH", "VACUUM", "ANALYZE", "REFRESH", "COPY":
+		return op
+	default:
+		return "OTHER"
+	}
+}
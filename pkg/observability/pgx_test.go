@@ -0,0 +1,21 @@
+package observability
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT id FROM users":                                                   "SELECT",
+		"\n\t\tinsert into idempotency_keys (key) VALUES ($1)":                   "INSERT",
+		"-- name: refresh\n-- keeps the view fresh\nREFRESH MATERIALIZED VIEW x": "REFRESH",
+		"WITH totals AS (SELECT 1) SELECT * FROM totals":                         "WITH",
+		"SET LOCAL statement_timeout = 0":                                        "OTHER",
+		"  ":                                                                     "UNKNOWN",
+	}
+	for sql, want := range tests {
+		assert.Equal(t, want, sqlOperation(sql), sql)
+	}
+}
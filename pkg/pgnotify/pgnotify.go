@@ -0,0 +1,106 @@
+// Package pgnotify relays Postgres LISTEN/NOTIFY messages to the process, so an
+// event raised on one replica reaches clients connected to any replica.
+package pgnotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxPayload is the largest payload Postgres accepts in a notification, in bytes
+const MaxPayload = 7999
+
+// defaultReconnectDelay is the wait before listening again after the
+// connection is lost
+const defaultReconnectDelay = 5 * time.Second
+
+// ErrPayloadTooLarge is returned when a payload exceeds MaxPayload
+var ErrPayloadTooLarge = errors.New("notification payload too large")
+
+// Execer runs a statement; both *pgxpool.Pool and pgx.Tx satisfy it. Within a
+// transaction the notification is only sent when it commits.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Notify sends a payload on the channel
+func Notify(ctx context.Context, db Execer, channel, payload string) error {
+	if len(payload) > MaxPayload {
+		return fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, len(payload))
+	}
+	if _, err := db.Exec(ctx, `SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listener listens on one channel over a dedicated connection and hands every
+// payload to its handler
+type Listener struct {
+	pool           *pgxpool.Pool
+	channel        string
+	handler        func(payload string)
+	logger         *slog.Logger
+	reconnectDelay time.Duration
+}
+
+// NewListener creates a listener. The handler is called from the listening
+// goroutine, so it must not block.
+func NewListener(pool *pgxpool.Pool, channel string, handler func(payload string), logger *slog.Logger) *Listener {
+	return &Listener{
+		pool:           pool,
+		channel:        channel,
+		handler:        handler,
+		logger:         logger,
+		reconnectDelay: defaultReconnectDelay,
+	}
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection is lost.
+// Notifications sent while reconnecting are missed.
+func (l *Listener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Warn("notification listener disconnected",
+			slog.String("channel", l.channel),
+			slog.Any("error", err),
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.reconnectDelay):
+		}
+	}
+}
+
+// listen holds a connection out of the pool, so the LISTEN never leaks to
+// other queries, and relays notifications until it fails
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer func() { _ = conn.Close(context.WithoutCancel(ctx)) }()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", l.channel, err)
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.handler(n.Payload)
+	}
+}
@@ -17,6 +17,9 @@ const (
 	// ExpoPushURL is the Expo Push API endpoint
 	ExpoPushURL = "https://exp.host/--/api/v2/push/send"
 
+	// ExpoReceiptsURL is the Expo Push receipts endpoint
+	ExpoReceiptsURL = "https://exp.host/--/api/v2/push/getReceipts"
+
 	// RequestTimeout for push requests
 	RequestTimeout = 10 * time.Second
 )
@@ -48,6 +51,20 @@ type TicketResponse struct {
 	} `json:"details,omitempty"`
 }
 
+// ReceiptsResponse represents the Expo Push receipts API response
+type ReceiptsResponse struct {
+	Data map[string]Receipt `json:"data"`
+}
+
+// Receipt reports whether Expo delivered a ticket to the platform push service
+type Receipt struct {
+	Status  string `json:"status"` // "ok" or "error"
+	Message string `json:"message,omitempty"`
+	Details struct {
+		Error string `json:"error,omitempty"`
+	} `json:"details,omitempty"`
+}
+
 // Service handles Expo Push notifications
 type Service struct {
 	client *http.Client
@@ -66,13 +83,20 @@ func NewService(logger *slog.Logger) *Service {
 
 // Send sends a push notification to a single token
 func (s *Service) Send(ctx context.Context, msg *Message) error {
+	_, err := s.SendTicket(ctx, msg)
+	return err
+}
+
+// SendTicket sends a push notification to a single token and returns the Expo
+// ticket ID, which can later be exchanged for a delivery receipt
+func (s *Service) SendTicket(ctx context.Context, msg *Message) (string, error) {
 	if msg.To == "" {
-		return errors.New("push token is required")
+		return "", errors.New("push token is required")
 	}
 
 	// Validate Expo push token format
 	if !isValidExpoPushToken(msg.To) {
-		return fmt.Errorf("invalid Expo push token format: %s", msg.To)
+		return "", fmt.Errorf("invalid Expo push token format: %s", msg.To)
 	}
 
 	// Default sound
@@ -83,50 +107,86 @@ func (s *Service) Send(ctx context.Context, msg *Message) error {
 	// Marshal message
 	payload, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal push message: %w", err)
+		return "", fmt.Errorf("failed to marshal push message: %w", err)
 	}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", ExpoPushURL, bytes.NewReader(payload))
+	body, err := s.post(ctx, ExpoPushURL, payload)
 	if err != nil {
-		return fmt.Errorf("failed to create push request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	// Send request
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send push notification: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read push response: %w", err)
+		return "", fmt.Errorf("failed to send push notification: %w", err)
 	}
 
 	// Parse response
 	var pushResp Response
 	if err := json.Unmarshal(body, &pushResp); err != nil {
 		s.logger.Error("failed to parse push response", "body", string(body), "error", err)
-		return fmt.Errorf("failed to parse push response: %w", err)
+		return "", fmt.Errorf("failed to parse push response: %w", err)
+	}
+	if len(pushResp.Data) == 0 {
+		return "", errors.New("push response contained no tickets")
 	}
 
 	// Check for errors
-	if len(pushResp.Data) > 0 && pushResp.Data[0].Status == "error" {
+	if pushResp.Data[0].Status == "error" {
 		errMsg := pushResp.Data[0].Message
 		if pushResp.Data[0].Details.Error != "" {
 			errMsg = pushResp.Data[0].Details.Error
 		}
 		s.logger.Warn("push notification failed", "error", errMsg, "token", msg.To[:20]+"...")
-		return fmt.Errorf("push notification failed: %s", errMsg)
+		return "", fmt.Errorf("push notification failed: %s", errMsg)
 	}
 
 	s.logger.Info("push notification sent", "ticketId", pushResp.Data[0].ID)
-	return nil
+	return pushResp.Data[0].ID, nil
+}
+
+// GetReceipts fetches delivery receipts for previously issued tickets. Tickets
+// without a receipt yet are absent from the result.
+func (s *Service) GetReceipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error) {
+	if len(ticketIDs) == 0 {
+		return map[string]Receipt{}, nil
+	}
+
+	payload, err := json.Marshal(map[string][]string{"ids": ticketIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal receipt request: %w", err)
+	}
+
+	body, err := s.post(ctx, ExpoReceiptsURL, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch push receipts: %w", err)
+	}
+
+	var receiptResp ReceiptsResponse
+	if err := json.Unmarshal(body, &receiptResp); err != nil {
+		return nil, fmt.Errorf("failed to parse push receipts: %w", err)
+	}
+	return receiptResp.Data, nil
+}
+
+// post sends a JSON payload to the Expo API and returns the response body
+func (s *Service) post(ctx context.Context, url string, payload []byte) ([]byte, error) {
+	// Create request
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	// Send request
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read push response: %w", err)
+	}
+	return body, nil
 }
 
 // SendBatch sends push notifications to multiple tokens
@@ -0,0 +1,182 @@
+// Package sheets provides a minimal Google Sheets client for plan sync
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// SheetsAPIURL is the Google Sheets API spreadsheets endpoint
+	SheetsAPIURL = "https://sheets.googleapis.com/v4/spreadsheets"
+
+	// DriveFilesURL is the Google Drive API files endpoint (for modification times)
+	DriveFilesURL = "https://www.googleapis.com/drive/v3/files"
+
+	// RequestTimeout for Google API requests
+	RequestTimeout = 15 * time.Second
+)
+
+// Scopes needed to read and write a user's sheets and their modification times
+var Scopes = []string{
+	"https://www.googleapis.com/auth/spreadsheets",
+	"https://www.googleapis.com/auth/drive.metadata.readonly",
+}
+
+// Endpoint is Google's OAuth 2.0 endpoint
+var Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://accounts.google.com/o/oauth2/auth",
+	TokenURL: "https://oauth2.googleapis.com/token",
+}
+
+// NewOAuthConfig creates the OAuth config used to connect a Google account
+func NewOAuthConfig(clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       Scopes,
+		Endpoint:     Endpoint,
+	}
+}
+
+// valueRange is the Sheets API representation of a block of cells
+type valueRange struct {
+	Range          string  `json:"range,omitempty"`
+	MajorDimension string  `json:"majorDimension,omitempty"`
+	Values         [][]any `json:"values"`
+}
+
+// Client calls the Sheets and Drive APIs on behalf of a user
+type Client struct{}
+
+// NewClient creates a new Sheets client
+func NewClient() *Client {
+	return &Client{}
+}
+
+// ReadRows reads all rows of a sheet tab as unformatted cell text
+func (c *Client) ReadRows(ctx context.Context, ts oauth2.TokenSource, spreadsheetID, sheetName string) ([][]string, error) {
+	endpoint := fmt.Sprintf("%s/%s/values/%s?majorDimension=ROWS&valueRenderOption=UNFORMATTED_VALUE",
+		SheetsAPIURL, url.PathEscape(spreadsheetID), url.PathEscape(quoteSheet(sheetName)))
+
+	var resp valueRange
+	if err := c.do(ctx, ts, http.MethodGet, endpoint, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read sheet: %w", err)
+	}
+
+	rows := make([][]string, len(resp.Values))
+	for i, row := range resp.Values {
+		rows[i] = make([]string, len(row))
+		for j, cell := range row {
+			rows[i][j] = cellString(cell)
+		}
+	}
+	return rows, nil
+}
+
+// WriteRows replaces the contents of a sheet tab with the given rows
+func (c *Client) WriteRows(ctx context.Context, ts oauth2.TokenSource, spreadsheetID, sheetName string, rows [][]any) error {
+	rng := quoteSheet(sheetName)
+
+	clearURL := fmt.Sprintf("%s/%s/values/%s:clear", SheetsAPIURL, url.PathEscape(spreadsheetID), url.PathEscape(rng))
+	if err := c.do(ctx, ts, http.MethodPost, clearURL, struct{}{}, nil); err != nil {
+		return fmt.Errorf("failed to clear sheet: %w", err)
+	}
+
+	updateURL := fmt.Sprintf("%s/%s/values/%s?valueInputOption=USER_ENTERED",
+		SheetsAPIURL, url.PathEscape(spreadsheetID), url.PathEscape(rng))
+	body := valueRange{Range: rng, MajorDimension: "ROWS", Values: rows}
+	if err := c.do(ctx, ts, http.MethodPut, updateURL, body, nil); err != nil {
+		return fmt.Errorf("failed to write sheet: %w", err)
+	}
+	return nil
+}
+
+// ModifiedTime returns when the spreadsheet was last edited
+func (c *Client) ModifiedTime(ctx context.Context, ts oauth2.TokenSource, spreadsheetID string) (time.Time, error) {
+	endpoint := fmt.Sprintf("%s/%s?fields=modifiedTime", DriveFilesURL, url.PathEscape(spreadsheetID))
+
+	var resp struct {
+		ModifiedTime time.Time `json:"modifiedTime"`
+	}
+	if err := c.do(ctx, ts, http.MethodGet, endpoint, nil, &resp); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get spreadsheet modified time: %w", err)
+	}
+	return resp.ModifiedTime, nil
+}
+
+// do sends an authorized JSON request and decodes the response into out (if non-nil)
+func (c *Client) do(ctx context.Context, ts oauth2.TokenSource, method, endpoint string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := oauth2.NewClient(ctx, ts)
+	httpClient.Timeout = RequestTimeout
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("google api returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// quoteSheet builds an A1 range covering a whole tab, quoting the tab name
+func quoteSheet(name string) string {
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// cellString renders an unformatted cell value as text
+func cellString(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
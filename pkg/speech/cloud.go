@@ -0,0 +1,116 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultCloudURL is OpenAI's API; Groq and other compatible providers
+	// take the same requests at their own base URL
+	DefaultCloudURL = "https://api.openai.com/v1"
+	// DefaultCloudModel is the transcription model used when none is configured
+	DefaultCloudModel = "whisper-1"
+)
+
+// Cloud transcribes with a hosted API implementing OpenAI's
+// /audio/transcriptions endpoint
+type Cloud struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+var _ Transcriber = (*Cloud)(nil)
+
+// NewCloud creates a client for OpenAI's API with the given key
+func NewCloud(apiKey string) *Cloud {
+	return &Cloud{
+		client:  &http.Client{Timeout: DefaultTimeout},
+		baseURL: DefaultCloudURL,
+		apiKey:  apiKey,
+		model:   DefaultCloudModel,
+	}
+}
+
+// WithBaseURL points the client at another OpenAI-compatible provider
+func (c *Cloud) WithBaseURL(baseURL string) *Cloud {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+	return c
+}
+
+// WithModel sets the transcription model
+func (c *Cloud) WithModel(model string) *Cloud {
+	c.model = model
+	return c
+}
+
+// Transcribe uploads the clip and returns the transcript
+func (c *Cloud) Transcribe(ctx context.Context, audio []byte, mimeType, language string) (string, error) {
+	if err := checkAudio(audio); err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "voice"+extensionFor(mimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	if _, err := fw.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	fields := map[string]string{"model": c.model, "response_format": "json"}
+	if lang := baseLanguage(language); lang != "" {
+		fields["language"] = lang
+	}
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			return "", fmt.Errorf("failed to build transcription request: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transcription request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Text  string `json:"text"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	if resp.StatusCode >= 300 {
+		msg := http.StatusText(resp.StatusCode)
+		if result.Error != nil {
+			msg = result.Error.Message
+		}
+		return "", fmt.Errorf("transcription API returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	text := strings.TrimSpace(result.Text)
+	if text == "" {
+		return "", ErrNoSpeech
+	}
+	return text, nil
+}
@@ -0,0 +1,75 @@
+// Package speech turns short voice notes into text, either with a local
+// whisper.cpp binary or with a cloud API that speaks OpenAI's transcription
+// protocol
+package speech
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// MaxAudioBytes caps a voice note; Quick Capture clips are a few seconds long
+	MaxAudioBytes = 10 << 20
+	// DefaultTimeout bounds a single transcription
+	DefaultTimeout = 60 * time.Second
+)
+
+var (
+	// ErrEmptyAudio is returned for clips with no content
+	ErrEmptyAudio = errors.New("audio is empty")
+	// ErrAudioTooLarge is returned for clips over MaxAudioBytes
+	ErrAudioTooLarge = errors.New("audio is too large")
+	// ErrNoSpeech is returned when nothing was recognized in the clip
+	ErrNoSpeech = errors.New("no speech recognized")
+)
+
+// Transcriber converts speech to text
+type Transcriber interface {
+	// Transcribe returns what was said in audio, a clip of the given MIME type
+	// (e.g. "audio/ogg"). language is a hint such as "pt" or "pt-PT"; "" lets
+	// the model detect it.
+	Transcribe(ctx context.Context, audio []byte, mimeType, language string) (string, error)
+}
+
+// checkAudio validates a clip before it is sent anywhere
+func checkAudio(audio []byte) error {
+	switch {
+	case len(audio) == 0:
+		return ErrEmptyAudio
+	case len(audio) > MaxAudioBytes:
+		return ErrAudioTooLarge
+	}
+	return nil
+}
+
+// baseLanguage reduces a locale such as "pt-BR" to the language code speech
+// models take
+func baseLanguage(locale string) string {
+	for i, r := range locale {
+		if r == '-' || r == '_' {
+			return locale[:i]
+		}
+	}
+	return locale
+}
+
+// extensionFor returns a file extension for a MIME type, which both
+// whisper.cpp and the cloud APIs use to pick a decoder
+func extensionFor(mimeType string) string {
+	switch mimeType {
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a", "audio/aac":
+		return ".m4a"
+	case "audio/webm":
+		return ".webm"
+	case "audio/flac":
+		return ".flac"
+	default:
+		return ".wav"
+	}
+}
@@ -0,0 +1,75 @@
+package speech
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudTranscribe(t *testing.T) {
+	var got struct {
+		auth, model, language, filename string
+		audio                           []byte
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audio/transcriptions", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		got.auth = r.Header.Get("Authorization")
+		got.model, got.language = r.FormValue("model"), r.FormValue("language")
+		f, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		got.filename = header.Filename
+		got.audio, _ = io.ReadAll(f)
+		_, _ = w.Write([]byte(`{"text": " café 2 euros ontem "}`))
+	}))
+	defer srv.Close()
+
+	cloud := NewCloud("sk-test").WithBaseURL(srv.URL + "/v1/")
+	text, err := cloud.Transcribe(context.Background(), []byte("OggS..."), "audio/ogg", "pt-PT")
+	require.NoError(t, err)
+	assert.Equal(t, "café 2 euros ontem", text)
+	assert.Equal(t, "Bearer sk-test", got.auth)
+	assert.Equal(t, DefaultCloudModel, got.model)
+	assert.Equal(t, "pt", got.language)
+	assert.Equal(t, "voice.ogg", got.filename)
+	assert.Equal(t, []byte("OggS..."), got.audio)
+}
+
+func TestCloudTranscribe_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("model") == "silent" {
+			_, _ = w.Write([]byte(`{"text": ""}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": {"message": "invalid api key"}}`))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := NewCloud("bad").WithBaseURL(srv.URL).Transcribe(ctx, []byte("RIFF"), "audio/wav", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid api key")
+
+	_, err = NewCloud("key").WithBaseURL(srv.URL).WithModel("silent").Transcribe(ctx, []byte("RIFF"), "audio/wav", "")
+	assert.ErrorIs(t, err, ErrNoSpeech)
+
+	_, err = NewCloud("key").WithBaseURL(srv.URL).Transcribe(ctx, nil, "audio/wav", "")
+	assert.ErrorIs(t, err, ErrEmptyAudio)
+	_, err = NewCloud("key").WithBaseURL(srv.URL).Transcribe(ctx, make([]byte, MaxAudioBytes+1), "audio/wav", "")
+	assert.ErrorIs(t, err, ErrAudioTooLarge)
+}
+
+func TestTranscriptFrom(t *testing.T) {
+	text, err := transcriptFrom("\n Lunch twelve euros\n [MUSIC]\n on revolut.\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Lunch twelve euros on revolut.", text)
+
+	_, err = transcriptFrom(" [BLANK_AUDIO]\n (wind blowing)\n")
+	assert.ErrorIs(t, err, ErrNoSpeech)
+}
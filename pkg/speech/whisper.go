@@ -0,0 +1,99 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// WhisperCPP transcribes with a local whisper.cpp command line binary
+// ("whisper-cli"), so voice notes never leave the server. Formats other than
+// 16 kHz WAV need a binary built with ffmpeg support.
+type WhisperCPP struct {
+	binary  string
+	model   string
+	threads int
+	timeout time.Duration
+}
+
+var _ Transcriber = (*WhisperCPP)(nil)
+
+// NewWhisperCPP runs binary with the ggml model file at model
+func NewWhisperCPP(binary, model string) *WhisperCPP {
+	return &WhisperCPP{binary: binary, model: model, timeout: DefaultTimeout}
+}
+
+// WithThreads sets how many threads whisper.cpp decodes with; zero uses its default
+func (w *WhisperCPP) WithThreads(threads int) *WhisperCPP {
+	w.threads = threads
+	return w
+}
+
+// WithTimeout sets how long a transcription may take
+func (w *WhisperCPP) WithTimeout(timeout time.Duration) *WhisperCPP {
+	w.timeout = timeout
+	return w
+}
+
+// Transcribe writes the clip to a temporary file and runs whisper.cpp on it
+func (w *WhisperCPP) Transcribe(ctx context.Context, audio []byte, mimeType, language string) (string, error) {
+	if err := checkAudio(audio); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "voice-*"+extensionFor(mimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(audio); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write audio file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	lang := baseLanguage(language)
+	if lang == "" {
+		lang = "auto"
+	}
+	args := []string{"-m", w.model, "-f", f.Name(), "-l", lang, "--no-timestamps", "--no-prints"}
+	if w.threads > 0 {
+		args = append(args, "-t", fmt.Sprint(w.threads))
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, w.binary, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run whisper.cpp: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return transcriptFrom(stdout.String())
+}
+
+// transcriptFrom joins whisper.cpp's output lines into one transcript,
+// dropping the markers it prints for silence and noise
+func transcriptFrom(output string) (string, error) {
+	var parts []string
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		// e.g. "[BLANK_AUDIO]" or "(wind blowing)"
+		marker := strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") ||
+			strings.HasPrefix(line, "(") && strings.HasSuffix(line, ")")
+		if line == "" || marker {
+			continue
+		}
+		parts = append(parts, line)
+	}
+	if len(parts) == 0 {
+		return "", ErrNoSpeech
+	}
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,506 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// Encrypted files start with a header naming the master key and holding the
+// file's wrapped data key, followed by the content sealed with AES-256-GCM in
+// chunks, so files are encrypted and decrypted as they stream:
+//
+//	magic (8) | version (1) | key ID length (1) | key ID |
+//	wrapped key length (2) | wrapped key | nonce prefix (7)
+//
+// Each chunk's nonce is the prefix, the chunk's index and a flag marking the
+// last chunk, so chunks can't be reordered, dropped or truncated unnoticed.
+const (
+	encryptedMagic   = "\x89SFTENC\n"
+	encryptedVersion = 1
+	dataKeySize      = 32
+	noncePrefixSize  = 7
+	encryptChunkSize = 64 << 10
+)
+
+var (
+	// ErrCorruptFile is returned when an encrypted file fails authentication
+	ErrCorruptFile = errors.New("encrypted file is corrupt or was tampered with")
+	// ErrReplaceUnsupported is returned when rotating keys on a backend that
+	// can't overwrite files
+	ErrReplaceUnsupported = errors.New("storage backend can't replace files")
+)
+
+// Rotation is what RotateFileKey did to a file
+type Rotation string
+
+const (
+	// RotationNone means the file was already wrapped with the primary key
+	RotationNone Rotation = "none"
+	// RotationRewrapped means the data key was rewrapped with the primary key
+	RotationRewrapped Rotation = "rewrapped"
+	// RotationEncrypted means a file stored before encryption was encrypted
+	RotationEncrypted Rotation = "encrypted"
+)
+
+// EncryptedStorage encrypts files at rest on top of another backend. Every
+// file gets its own random data key, wrapped by a master key from keys and
+// stored in the file's header. Files stored before encryption was enabled
+// are read as they are until RotateFileKey encrypts them.
+type EncryptedStorage struct {
+	Storage
+	keys KeyWrapper
+}
+
+// NewEncryptedStorage wraps base so files are encrypted with keys
+func NewEncryptedStorage(base Storage, keys KeyWrapper) *EncryptedStorage {
+	return &EncryptedStorage{Storage: base, keys: keys}
+}
+
+// Upload encrypts and stores a file. The returned size is the plaintext size.
+func (s *EncryptedStorage) Upload(ctx context.Context, userID uuid.UUID, filename string, contentType string, r io.Reader) (*FileInfo, error) {
+	er, err := s.encrypt(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.Storage.Upload(ctx, userID, filename, contentType, er)
+	if err != nil {
+		return nil, err
+	}
+	info.Size = er.n
+	return info, nil
+}
+
+// Download retrieves and decrypts a file
+func (s *EncryptedStorage) Download(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (io.ReadCloser, *FileInfo, error) {
+	rc, info, err := s.Storage.Download(ctx, userID, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	dr, headerLen, err := s.decrypt(ctx, rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, nil, err
+	}
+	info.Size = plaintextSize(info.Size, headerLen)
+	return dr, info, nil
+}
+
+// GetReader returns a reader decrypting a file as it is read
+func (s *EncryptedStorage) GetReader(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (io.ReadCloser, error) {
+	rc, err := s.Storage.GetReader(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	dr, _, err := s.decrypt(ctx, rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return dr, nil
+}
+
+// GetInfo returns metadata for a file, with its plaintext size
+func (s *EncryptedStorage) GetInfo(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (*FileInfo, error) {
+	info, err := s.Storage.GetInfo(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fixSize(ctx, userID, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// List returns all files for a user, with their plaintext sizes. Files whose
+// header can't be read keep their stored size, so one damaged file doesn't
+// hide the rest.
+func (s *EncryptedStorage) List(ctx context.Context, userID uuid.UUID) ([]*FileInfo, error) {
+	files, err := s.Storage.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range files {
+		_ = s.fixSize(ctx, userID, info)
+	}
+	return files, nil
+}
+
+// RotateFileKey makes sure a file is encrypted under the primary master key.
+// Encrypted files only have their data key rewrapped, so the content isn't
+// re-encrypted; files stored before encryption was enabled are encrypted.
+// The backend must implement Replacer.
+func (s *EncryptedStorage) RotateFileKey(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (Rotation, error) {
+	replacer, ok := s.Storage.(Replacer)
+	if !ok {
+		return RotationNone, ErrReplaceUnsupported
+	}
+
+	rc, err := s.Storage.GetReader(ctx, userID, fileID)
+	if err != nil {
+		return RotationNone, err
+	}
+	defer func() { _ = rc.Close() }()
+	br := bufio.NewReader(rc)
+	h, err := readHeader(br)
+	if err != nil {
+		return RotationNone, err
+	}
+
+	if h == nil {
+		er, err := s.encrypt(ctx, br)
+		if err != nil {
+			return RotationNone, err
+		}
+		if _, err := replacer.Replace(ctx, userID, fileID, er); err != nil {
+			return RotationNone, fmt.Errorf("failed to encrypt file: %w", err)
+		}
+		return RotationEncrypted, nil
+	}
+	if h.keyID == s.keys.PrimaryKeyID() {
+		return RotationNone, nil
+	}
+
+	dataKey, err := s.keys.UnwrapKey(ctx, h.keyID, h.wrappedKey)
+	if err != nil {
+		return RotationNone, err
+	}
+	keyID, wrapped, err := s.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return RotationNone, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	rewrapped := &fileHeader{keyID: keyID, wrappedKey: wrapped, noncePrefix: h.noncePrefix}
+	header, err := rewrapped.marshal()
+	if err != nil {
+		return RotationNone, err
+	}
+	if _, err := replacer.Replace(ctx, userID, fileID, io.MultiReader(bytes.NewReader(header), br)); err != nil {
+		return RotationNone, fmt.Errorf("failed to rewrap file: %w", err)
+	}
+	return RotationRewrapped, nil
+}
+
+// encrypt returns a reader producing r's content encrypted under a new data key
+func (s *EncryptedStorage) encrypt(ctx context.Context, r io.Reader) (*encryptReader, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	keyID, wrapped, err := s.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	h := &fileHeader{keyID: keyID, wrappedKey: wrapped, noncePrefix: make([]byte, noncePrefixSize)}
+	if _, err := rand.Read(h.noncePrefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header, err := h.marshal()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		src:    bufio.NewReaderSize(r, encryptChunkSize),
+		aead:   aead,
+		prefix: h.noncePrefix,
+		plain:  make([]byte, encryptChunkSize),
+		out:    header,
+	}, nil
+}
+
+// decrypt returns a reader decrypting rc and the length of its header.
+// Content without an encryption header is passed through unchanged.
+func (s *EncryptedStorage) decrypt(ctx context.Context, rc io.ReadCloser) (io.ReadCloser, int, error) {
+	br := bufio.NewReaderSize(rc, encryptChunkSize)
+	h, err := readHeader(br)
+	if err != nil {
+		return nil, 0, err
+	}
+	if h == nil {
+		return readCloser{Reader: br, Closer: rc}, 0, nil
+	}
+
+	dataKey, err := s.keys.UnwrapKey(ctx, h.keyID, h.wrappedKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &decryptReader{
+		src:    br,
+		closer: rc,
+		aead:   aead,
+		prefix: h.noncePrefix,
+		sealed: make([]byte, encryptChunkSize+aead.Overhead()),
+	}, h.size(), nil
+}
+
+// fixSize replaces a stored file's size with its plaintext size
+func (s *EncryptedStorage) fixSize(ctx context.Context, userID uuid.UUID, info *FileInfo) error {
+	rc, err := s.Storage.GetReader(ctx, userID, info.ID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	h, err := readHeader(bufio.NewReader(rc))
+	if err != nil {
+		return err
+	}
+	if h != nil {
+		info.Size = plaintextSize(info.Size, h.size())
+	}
+	return nil
+}
+
+// plaintextSize is the size of an encrypted file's content given its stored
+// size and header length. Unencrypted files (no header) keep their size.
+func plaintextSize(stored int64, headerLen int) int64 {
+	if headerLen == 0 {
+		return stored
+	}
+	body := stored - int64(headerLen)
+	sealedChunk := int64(encryptChunkSize + aesGCMOverhead)
+	chunks := (body + sealedChunk - 1) / sealedChunk
+	return body - chunks*aesGCMOverhead
+}
+
+// =============================================================================
+// Header
+// =============================================================================
+
+// aesGCMOverhead is the tag length AES-GCM adds to every chunk
+const aesGCMOverhead = 16
+
+type fileHeader struct {
+	keyID       string
+	wrappedKey  []byte
+	noncePrefix []byte
+}
+
+func (h *fileHeader) size() int {
+	return len(encryptedMagic) + 2 + len(h.keyID) + 2 + len(h.wrappedKey) + noncePrefixSize
+}
+
+func (h *fileHeader) marshal() ([]byte, error) {
+	if len(h.keyID) == 0 || len(h.keyID) > math.MaxUint8 {
+		return nil, fmt.Errorf("invalid key ID %q", h.keyID)
+	}
+	if len(h.wrappedKey) > math.MaxUint16 {
+		return nil, fmt.Errorf("wrapped data key too long: %d bytes", len(h.wrappedKey))
+	}
+	buf := make([]byte, 0, h.size())
+	buf = append(buf, encryptedMagic...)
+	buf = append(buf, encryptedVersion, byte(len(h.keyID)))
+	buf = append(buf, h.keyID...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(h.wrappedKey)))
+	buf = append(buf, h.wrappedKey...)
+	buf = append(buf, h.noncePrefix...)
+	return buf, nil
+}
+
+// readHeader reads an encryption header, returning nil without consuming
+// anything if the content isn't encrypted
+func readHeader(br *bufio.Reader) (*fileHeader, error) {
+	magic, err := br.Peek(len(encryptedMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if string(magic) != encryptedMagic {
+		return nil, nil
+	}
+	_, _ = br.Discard(len(encryptedMagic))
+
+	corrupt := func(err error) (*fileHeader, error) {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrCorruptFile, err)
+	}
+	var fixed [2]byte
+	if _, err := io.ReadFull(br, fixed[:]); err != nil {
+		return corrupt(err)
+	}
+	if fixed[0] != encryptedVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d", fixed[0])
+	}
+	keyID := make([]byte, fixed[1])
+	if _, err := io.ReadFull(br, keyID); err != nil {
+		return corrupt(err)
+	}
+	if _, err := io.ReadFull(br, fixed[:]); err != nil {
+		return corrupt(err)
+	}
+	h := &fileHeader{
+		keyID:       string(keyID),
+		wrappedKey:  make([]byte, binary.BigEndian.Uint16(fixed[:])),
+		noncePrefix: make([]byte, noncePrefixSize),
+	}
+	if _, err := io.ReadFull(br, h.wrappedKey); err != nil {
+		return corrupt(err)
+	}
+	if _, err := io.ReadFull(br, h.noncePrefix); err != nil {
+		return corrupt(err)
+	}
+	return h, nil
+}
+
+// =============================================================================
+// Chunked AES-GCM
+// =============================================================================
+
+func newChunkCipher(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != dataKeySize {
+		return nil, fmt.Errorf("%w: invalid data key", ErrCorruptFile)
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce builds the nonce of the chunk at index
+func chunkNonce(prefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, 0, noncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, index)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// atEOF reports whether br has no more data
+func atEOF(br *bufio.Reader) (bool, error) {
+	if _, err := br.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
+
+// encryptReader encrypts its source chunk by chunk as it is read
+type encryptReader struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	plain  []byte
+	sealed []byte
+	out    []byte // Encrypted bytes not read yet, starting with the header
+	done   bool
+	n      int64 // Plaintext bytes encrypted
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encryptReader) next() error {
+	n, err := io.ReadFull(e.src, e.plain)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		e.done = true
+	case err != nil:
+		return err
+	default:
+		if e.done, err = atEOF(e.src); err != nil {
+			return err
+		}
+	}
+	if !e.done && e.index == math.MaxUint32 {
+		return errors.New("file too large to encrypt")
+	}
+
+	e.sealed = e.aead.Seal(e.sealed[:0], chunkNonce(e.prefix, e.index, e.done), e.plain[:n], nil)
+	e.out = e.sealed
+	e.index++
+	e.n += int64(n)
+	return nil
+}
+
+// decryptReader decrypts and authenticates its source chunk by chunk
+type decryptReader struct {
+	src    *bufio.Reader
+	closer io.Closer
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	sealed []byte
+	plain  []byte
+	out    []byte // Decrypted bytes not read yet
+	done   bool
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.src, d.sealed)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		d.done = true
+	case err != nil:
+		return err
+	default:
+		if d.done, err = atEOF(d.src); err != nil {
+			return err
+		}
+	}
+
+	// A file cut at a chunk boundary fails here: its new last chunk was
+	// sealed without the last flag
+	d.plain, err = d.aead.Open(d.plain[:0], chunkNonce(d.prefix, d.index, d.done), d.sealed[:n], nil)
+	if err != nil {
+		return ErrCorruptFile
+	}
+	d.out = d.plain
+	d.index++
+	return nil
+}
+
+func (d *decryptReader) Close() error {
+	return d.closer.Close()
+}
+
+// readCloser pairs a buffered reader with the file it reads
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, masterKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func readAll(t *testing.T, s Storage, userID, fileID uuid.UUID) []byte {
+	t.Helper()
+	r, err := s.GetReader(context.Background(), userID, fileID)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func storedPath(t *testing.T, local *LocalStorage, userID, fileID uuid.UUID) string {
+	t.Helper()
+	info, err := local.GetInfo(context.Background(), userID, fileID)
+	require.NoError(t, err)
+	return filepath.Join(local.basePath, userID.String(), info.Path)
+}
+
+func TestParseKeyring(t *testing.T) {
+	k, err := ParseKeyring("2026-10:" + testKey(t) + ", 2026-01:" + testKey(t))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", k.PrimaryKeyID())
+
+	_, err = ParseKeyring("")
+	assert.Error(t, err)
+	_, err = ParseKeyring("short:" + base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+	_, err = ParseKeyring("a:" + testKey(t) + ",a:" + testKey(t))
+	assert.Error(t, err)
+}
+
+func TestEncryptedStorage_RoundTrip(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	keys, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	s := NewEncryptedStorage(local, keys)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, size := range []int{0, 1, encryptChunkSize, 3*encryptChunkSize + 17} {
+		content := make([]byte, size)
+		_, _ = rand.Read(content)
+
+		info, err := s.Upload(ctx, userID, "statement.csv", "text/csv", bytes.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), info.Size)
+
+		stored, err := os.ReadFile(storedPath(t, local, userID, info.ID))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(stored), encryptedMagic))
+		// Shorter content turns up in random ciphertext by chance
+		if size >= 16 {
+			assert.False(t, bytes.Contains(stored, content), "content stored in the clear")
+		}
+
+		assert.Equal(t, content, readAll(t, s, userID, info.ID))
+		got, err := s.GetInfo(ctx, userID, info.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), got.Size)
+	}
+}
+
+func TestEncryptedStorage_DetectsTampering(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	keys, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	s := NewEncryptedStorage(local, keys)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	content := bytes.Repeat([]byte("Date,Amount\n"), encryptChunkSize/6)
+	info, err := s.Upload(ctx, userID, "statement.csv", "text/csv", bytes.NewReader(content))
+	require.NoError(t, err)
+	path := storedPath(t, local, userID, info.ID)
+	stored, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	flipped := bytes.Clone(stored)
+	flipped[len(flipped)-1] ^= 1
+	require.NoError(t, os.WriteFile(path, flipped, 0o644))
+	r, err := s.GetReader(ctx, userID, info.ID)
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, ErrCorruptFile)
+
+	// Cut after the first chunk, which wasn't sealed as the last one
+	h, err := readHeader(bufio.NewReader(bytes.NewReader(stored)))
+	require.NoError(t, err)
+	truncated := stored[:h.size()+encryptChunkSize+aesGCMOverhead]
+	require.NoError(t, os.WriteFile(path, truncated, 0o644))
+	r, err = s.GetReader(ctx, userID, info.ID)
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, ErrCorruptFile)
+}
+
+func TestEncryptedStorage_RotateFileKey(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	oldKey, newKey := "old:"+testKey(t), "new:"+testKey(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	legacy, err := local.Upload(ctx, userID, "legacy.csv", "text/csv", strings.NewReader("Date,Amount\n01/02/2024,-2.50\n"))
+	require.NoError(t, err)
+
+	oldKeys, err := ParseKeyring(oldKey)
+	require.NoError(t, err)
+	s := NewEncryptedStorage(local, oldKeys)
+	assert.Equal(t, "Date,Amount\n01/02/2024,-2.50\n", string(readAll(t, s, userID, legacy.ID)), "unencrypted files read as they are")
+	encrypted, err := s.Upload(ctx, userID, "new.csv", "text/csv", strings.NewReader("Date,Amount\n03/04/2024,10.00\n"))
+	require.NoError(t, err)
+
+	rotated, err := ParseKeyring(newKey + "," + oldKey)
+	require.NoError(t, err)
+	s = NewEncryptedStorage(local, rotated)
+
+	rotation, err := s.RotateFileKey(ctx, userID, legacy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RotationEncrypted, rotation)
+	rotation, err = s.RotateFileKey(ctx, userID, encrypted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RotationRewrapped, rotation)
+	rotation, err = s.RotateFileKey(ctx, userID, encrypted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, RotationNone, rotation)
+
+	// Once rotated, the old key can be retired
+	newKeys, err := ParseKeyring(newKey)
+	require.NoError(t, err)
+	s = NewEncryptedStorage(local, newKeys)
+	assert.Equal(t, "Date,Amount\n01/02/2024,-2.50\n", string(readAll(t, s, userID, legacy.ID)))
+	assert.Equal(t, "Date,Amount\n03/04/2024,10.00\n", string(readAll(t, s, userID, encrypted.ID)))
+
+	files, err := s.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, f := range files {
+		assert.Equal(t, int64(29), f.Size)
+	}
+}
+
+func TestEncryptedStorage_UnknownKey(t *testing.T) {
+	local, err := NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	keys, err := ParseKeyring("k1:" + testKey(t))
+	require.NoError(t, err)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	info, err := NewEncryptedStorage(local, keys).Upload(ctx, userID, "a.csv", "text/csv", strings.NewReader("a,b\n"))
+	require.NoError(t, err)
+
+	other, err := ParseKeyring("k2:" + testKey(t))
+	require.NoError(t, err)
+	_, err = NewEncryptedStorage(local, other).GetReader(ctx, userID, info.ID)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// masterKeySize is the length of a master key (AES-256)
+const masterKeySize = 32
+
+// ErrUnknownKey is returned when a file's data key was wrapped with a master
+// key the keyring doesn't hold
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// KeyWrapper protects per-file data keys with a master key. Keyring holds the
+// master keys in memory; a KMS client can implement it instead so they never
+// leave the KMS.
+type KeyWrapper interface {
+	// PrimaryKeyID names the master key new data keys are wrapped with
+	PrimaryKeyID() string
+	// WrapKey encrypts a data key with the primary master key, returning the
+	// ID of that key and the wrapped data key
+	WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error)
+	// UnwrapKey decrypts a data key wrapped with the master key keyID
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Keyring wraps data keys with AES-256-GCM master keys held in memory. It
+// keeps retired master keys so files wrapped with them stay readable until
+// they are rotated.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring from master keys by ID, wrapping new data keys
+// with the primary one
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("%w: primary key %q", ErrUnknownKey, primary)
+	}
+
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key ID %q: must be 1-255 bytes", id)
+		}
+		if len(key) != masterKeySize {
+			return nil, fmt.Errorf("invalid key %q: must be %d bytes, got %d", id, masterKeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// ParseKeyring creates a keyring from a comma-separated list of
+// "id:base64key" pairs, as set in STORAGE_ENCRYPTION_KEYS. The first key is
+// the primary; to rotate, put the new key first and keep the old ones until
+// the key rotation task has rewrapped every file.
+func ParseKeyring(spec string) (*Keyring, error) {
+	var primary string
+	keys := map[string][]byte{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid encryption key entry: expected id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %q: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("duplicate encryption key %q", id)
+		}
+		if primary == "" {
+			primary = id
+		}
+		keys[id] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys configured")
+	}
+	return NewKeyring(primary, keys)
+}
+
+// PrimaryKeyID names the master key new data keys are wrapped with
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// WrapKey encrypts a data key with the primary master key
+func (k *Keyring) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	aead := k.keys[k.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(dataKey)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The key ID is authenticated so a wrapped key can't be relabelled
+	return k.primary, aead.Seal(nonce, nonce, dataKey, []byte(k.primary)), nil
+}
+
+// UnwrapKey decrypts a data key wrapped with the master key keyID
+func (k *Keyring) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrCorruptFile
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to unwrap data key", ErrCorruptFile)
+	}
+	return dataKey, nil
+}
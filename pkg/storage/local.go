@@ -180,6 +180,74 @@ func (s *LocalStorage) GetReader(ctx context.Context, userID uuid.UUID, fileID u
 	return f, nil
 }
 
+// Replace overwrites a file's content. The new content is written to a
+// temporary file and renamed over the old one, so readers never see a
+// partial file.
+func (s *LocalStorage) Replace(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, r io.Reader) (*FileInfo, error) {
+	info, err := s.GetInfo(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath := filepath.Join(s.basePath, userID.String(), info.Path)
+	f, err := os.CreateTemp(filepath.Dir(filePath), ".replace-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(f.Name()) // No-op once renamed
+
+	size, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		return nil, fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	info.Size = size
+	if err := s.saveMetadata(userID, fileID, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// ListOwners returns the IDs of all users with a storage directory
+func (s *LocalStorage) ListOwners(ctx context.Context) ([]uuid.UUID, error) {
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage directory: %w", err)
+	}
+
+	owners := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		id, err := uuid.Parse(entry.Name())
+		if err != nil {
+			continue
+		}
+		owners = append(owners, id)
+	}
+
+	return owners, nil
+}
+
+// Ping checks the storage directory is writable
+func (s *LocalStorage) Ping(ctx context.Context) error {
+	f, err := os.CreateTemp(s.basePath, ".ping-*")
+	if err != nil {
+		return fmt.Errorf("storage directory not writable: %w", err)
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
 // saveMetadata saves file metadata to a JSON file
 func (s *LocalStorage) saveMetadata(userID, fileID uuid.UUID, info *FileInfo) error {
 	metaDir := filepath.Join(s.basePath, userID.String(), ".meta")
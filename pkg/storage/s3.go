@@ -145,3 +145,16 @@ func (s *S3Storage) GetReader(ctx context.Context, userID uuid.UUID, fileID uuid
 	reader, _, err := s.Download(ctx, userID, fileID)
 	return reader, err
 }
+
+// ListOwners returns the IDs of all users with files in S3
+func (s *S3Storage) ListOwners(ctx context.Context) ([]uuid.UUID, error) {
+	// TODO: Implement S3 list
+	// This would typically list common prefixes with delimiter "/"
+	return nil, fmt.Errorf("S3 storage not implemented")
+}
+
+// Ping checks the bucket is reachable
+func (s *S3Storage) Ping(ctx context.Context) error {
+	// TODO: Implement with HeadBucket
+	return fmt.Errorf("S3 storage not implemented")
+}
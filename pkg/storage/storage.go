@@ -38,6 +38,19 @@ type Storage interface {
 
 	// GetReader returns a reader for a file (for streaming processing)
 	GetReader(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (io.ReadCloser, error)
+
+	// ListOwners returns the IDs of all users with stored files (for maintenance)
+	ListOwners(ctx context.Context) ([]uuid.UUID, error)
+
+	// Ping checks the backend accepts writes (for readiness checks)
+	Ping(ctx context.Context) error
+}
+
+// Replacer is implemented by backends that can overwrite a stored file in
+// place, keeping its ID and metadata (used to re-encrypt files)
+type Replacer interface {
+	// Replace swaps a file's content for r and returns its updated metadata
+	Replace(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, r io.Reader) (*FileInfo, error)
 }
 
 // StorageType identifies the storage backend
@@ -0,0 +1,258 @@
+// Package stripe creates Checkout and billing portal sessions through the
+// Stripe API and verifies the events Stripe posts to the billing webhook
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RequestTimeout for Stripe API requests
+	RequestTimeout = 15 * time.Second
+	// DefaultAPIURL is the Stripe API base URL
+	DefaultAPIURL = "https://api.stripe.com"
+
+	// SignatureHeader carries the signature of every webhook event
+	SignatureHeader = "Stripe-Signature"
+	// SignatureTolerance is how old a signed event may be, against replays
+	SignatureTolerance = 5 * time.Minute
+)
+
+// ErrInvalidSignature is returned for webhook events that aren't signed with
+// the endpoint's secret, or were signed too long ago
+var ErrInvalidSignature = errors.New("invalid stripe signature")
+
+// Event types the billing webhook handles
+const (
+	EventCheckoutCompleted   = "checkout.session.completed"
+	EventSubscriptionCreated = "customer.subscription.created"
+	EventSubscriptionUpdated = "customer.subscription.updated"
+	EventSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+// Event is a webhook event; Data.Object depends on Type
+type Event struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// CheckoutSession is the object of checkout.session.completed events
+type CheckoutSession struct {
+	ID                string `json:"id"`
+	URL               string `json:"url"`
+	Customer          string `json:"customer"`
+	Subscription      string `json:"subscription"`
+	ClientReferenceID string `json:"client_reference_id"`
+}
+
+// Subscription is the object of customer.subscription.* events
+type Subscription struct {
+	ID                string `json:"id"`
+	Customer          string `json:"customer"`
+	Status            string `json:"status"` // "trialing", "active", "past_due", "unpaid", "canceled", "incomplete", ...
+	CancelAtPeriodEnd bool   `json:"cancel_at_period_end"`
+	CurrentPeriodEnd  int64  `json:"current_period_end"`
+	StartDate         int64  `json:"start_date"`
+	TrialEnd          *int64 `json:"trial_end"`
+	EndedAt           *int64 `json:"ended_at"`
+	Items             struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// PriceID returns the price of the subscription's first item
+func (s *Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// CheckoutParams describe a Checkout session for a subscription
+type CheckoutParams struct {
+	CustomerID        string
+	PriceID           string
+	ClientReferenceID string // Comes back on checkout.session.completed
+	SuccessURL        string
+	CancelURL         string
+}
+
+// Client calls the Stripe API with a secret key
+type Client struct {
+	client    *http.Client
+	apiURL    string
+	secretKey string
+}
+
+// NewClient creates a new Stripe API client
+func NewClient(secretKey string) *Client {
+	return &Client{
+		client:    &http.Client{Timeout: RequestTimeout},
+		apiURL:    DefaultAPIURL,
+		secretKey: secretKey,
+	}
+}
+
+// WithAPIURL points the client at another API server, such as stripe-mock
+func (c *Client) WithAPIURL(apiURL string) *Client {
+	c.apiURL = apiURL
+	return c
+}
+
+// CreateCustomer creates a customer and returns its ID
+func (c *Client) CreateCustomer(ctx context.Context, email, userID string) (string, error) {
+	var customer struct {
+		ID string `json:"id"`
+	}
+	err := c.post(ctx, "/v1/customers", url.Values{
+		"email":             {email},
+		"metadata[user_id]": {userID},
+	}, &customer)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stripe customer: %w", err)
+	}
+	return customer.ID, nil
+}
+
+// CreateCheckoutSession starts a subscription checkout and returns the session
+// with the URL to send the user to
+func (c *Client) CreateCheckoutSession(ctx context.Context, params CheckoutParams) (*CheckoutSession, error) {
+	var session CheckoutSession
+	err := c.post(ctx, "/v1/checkout/sessions", url.Values{
+		"mode":                    {"subscription"},
+		"customer":                {params.CustomerID},
+		"client_reference_id":     {params.ClientReferenceID},
+		"line_items[0][price]":    {params.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"success_url":             {params.SuccessURL},
+		"cancel_url":              {params.CancelURL},
+	}, &session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stripe checkout session: %w", err)
+	}
+	return &session, nil
+}
+
+// CreatePortalSession returns the URL of a billing portal session where the
+// customer manages their subscription, coming back to returnURL
+func (c *Client) CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error) {
+	var session struct {
+		URL string `json:"url"`
+	}
+	err := c.post(ctx, "/v1/billing_portal/sessions", url.Values{
+		"customer":   {customerID},
+		"return_url": {returnURL},
+	}, &session)
+	if err != nil {
+		return "", fmt.Errorf("failed to create stripe portal session: %w", err)
+	}
+	return session.URL, nil
+}
+
+// post sends a form-encoded request and decodes the JSON response into out
+func (c *Client) post(ctx context.Context, path string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("stripe returned status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// ConstructEvent verifies the signature header of a webhook payload against
+// the endpoint's secret and decodes the event. Events signed more than
+// SignatureTolerance before now are rejected.
+func ConstructEvent(payload []byte, header, secret string, now time.Time) (*Event, error) {
+	if secret == "" {
+		return nil, ErrInvalidSignature
+	}
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(signedAt, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return nil, ErrInvalidSignature
+	}
+
+	expected := Sign(payload, secret, signedAt)
+	valid := false
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode stripe event: %w", err)
+	}
+	return &event, nil
+}
+
+// Sign returns the v1 signature of a payload signed at the given Unix time
+func Sign(payload []byte, secret string, signedAt int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(signedAt, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
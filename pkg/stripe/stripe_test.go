@@ -0,0 +1,35 @@
+package stripe
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructEvent(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"customer.subscription.updated","created":1772366400,"data":{"object":{"id":"sub_1"}}}`)
+	now := time.Unix(1772366400, 0)
+	header := fmt.Sprintf("t=%d,v1=%s,v0=ignored", now.Unix(), Sign(payload, secret, now.Unix()))
+
+	event, err := ConstructEvent(payload, header, secret, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, EventSubscriptionUpdated, event.Type)
+	assert.JSONEq(t, `{"id":"sub_1"}`, string(event.Data.Object))
+
+	_, err = ConstructEvent(payload, header, "whsec_other", now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = ConstructEvent(payload, header, secret, now.Add(SignatureTolerance+time.Second))
+	assert.ErrorIs(t, err, ErrInvalidSignature, "old events could be replays")
+
+	_, err = ConstructEvent(append(payload, ' '), header, secret, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = ConstructEvent(payload, "v1=abc", secret, now)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
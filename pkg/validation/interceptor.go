@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"context"
+	"errors"
+
+	"buf.build/go/protovalidate"
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// Interceptor rejects requests breaking Limits with CodeInvalidArgument. Like
+// the protovalidate interceptor it runs beside, it attaches the violations as
+// a buf.validate.Violations error detail, so clients handle both the same way.
+type Interceptor struct {
+	limits Limits
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor creates a validation interceptor enforcing limits
+func NewInterceptor(limits Limits) *Interceptor {
+	return &Interceptor{limits: limits}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if !req.Spec().IsClient {
+			if err := i.check(req.Any()); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor, checking each received message.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(ctx, &streamingConn{StreamingHandlerConn: conn, interceptor: i})
+	}
+}
+
+func (i *Interceptor) check(msg any) error {
+	protoMsg, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+	err := Check(protoMsg, i.limits)
+	if err == nil {
+		return nil
+	}
+	connectErr := connect.NewError(connect.CodeInvalidArgument, err)
+	if validationErr := new(protovalidate.ValidationError); errors.As(err, &validationErr) {
+		if detail, detailErr := connect.NewErrorDetail(validationErr.ToProto()); detailErr == nil {
+			connectErr.AddDetail(detail)
+		}
+	}
+	return connectErr
+}
+
+type streamingConn struct {
+	connect.StreamingHandlerConn
+	interceptor *Interceptor
+}
+
+func (c *streamingConn) Receive(msg any) error {
+	if err := c.StreamingHandlerConn.Receive(msg); err != nil {
+		return err
+	}
+	return c.interceptor.check(msg)
+}
@@ -0,0 +1,221 @@
+// Package validation checks request fields against bounds the proto rules
+// leave open: amounts, currency codes, timestamps and their ranges, page sizes
+// and upload sizes. Rules key off field names and types, so new messages are
+// covered without registering them.
+package validation
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"buf.build/go/protovalidate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// timestampName is the full name of google.protobuf.Timestamp
+const timestampName protoreflect.FullName = "google.protobuf.Timestamp"
+
+// Limits bounds request fields
+type Limits struct {
+	MaxAmountMinor int64         // Largest magnitude of *amount_minor fields
+	MaxPageSize    int32         // Largest page_size and limit; 0 leaves the handler's default
+	MaxCSVBytes    int           // Size of csv_bytes fields
+	MaxCSVRows     int           // Lines in csv_bytes fields, header included
+	MaxFileBytes   int           // Size of other bytes fields
+	EarliestTime   time.Time     // Earliest timestamp accepted
+	LatestTime     time.Time     // Timestamps must be before it
+	MaxTimeRange   time.Duration // Longest span between paired start and end timestamps
+}
+
+// DefaultLimits matches the bounds the proto rules set where they have any
+func DefaultLimits() Limits {
+	return Limits{
+		MaxAmountMinor: 900_000_000_000_000,
+		MaxPageSize:    200,
+		MaxCSVBytes:    20_000_000,
+		MaxCSVRows:     100_000,
+		MaxFileBytes:   20_000_000,
+		EarliestTime:   time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+		LatestTime:     time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC),
+		MaxTimeRange:   100 * 365 * 24 * time.Hour,
+	}
+}
+
+// Check validates msg and its nested messages, returning a
+// *protovalidate.ValidationError listing every violation
+func Check(msg proto.Message, limits Limits) error {
+	c := &checker{limits: limits}
+	c.message(msg.ProtoReflect(), nil)
+	if len(c.violations) == 0 {
+		return nil
+	}
+	return &protovalidate.ValidationError{Violations: c.violations}
+}
+
+type checker struct {
+	limits     Limits
+	violations []*protovalidate.Violation
+}
+
+func (c *checker) message(msg protoreflect.Message, path []*validate.FieldPathElement) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			// No request keys amounts, dates or uploads by map
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				c.value(fd, list.Get(i), append(path, element(fd, &i)))
+			}
+		default:
+			c.value(fd, v, append(path, element(fd, nil)))
+		}
+		return true
+	})
+	c.timeRanges(msg, path)
+}
+
+func (c *checker) value(fd protoreflect.FieldDescriptor, v protoreflect.Value, path []*validate.FieldPathElement) {
+	name := string(fd.Name())
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() == timestampName {
+			c.timestamp(v.Message(), path)
+			return
+		}
+		c.message(v.Message(), path)
+	case protoreflect.StringKind:
+		if (name == "currency_code" || strings.HasSuffix(name, "_currency")) &&
+			v.String() != "" && !currencyCodePattern.MatchString(v.String()) {
+			c.add(path, "currency_code", "must be a 3-letter ISO 4217 code")
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if strings.HasSuffix(name, "amount_minor") {
+			if amount := v.Int(); amount > c.limits.MaxAmountMinor || amount < -c.limits.MaxAmountMinor {
+				c.add(path, "amount_minor", fmt.Sprintf("must be between -%d and %d", c.limits.MaxAmountMinor, c.limits.MaxAmountMinor))
+			}
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		switch {
+		case name == "page_size" || name == "limit":
+			if size := int32(v.Int()); size < 0 || size > c.limits.MaxPageSize {
+				c.add(path, "page_size", fmt.Sprintf("must be between 0 and %d", c.limits.MaxPageSize))
+			}
+		case name == "offset":
+			if v.Int() < 0 {
+				c.add(path, "offset", "must not be negative")
+			}
+		}
+	case protoreflect.BytesKind:
+		c.upload(name, v.Bytes(), path)
+	}
+}
+
+func (c *checker) upload(name string, data []byte, path []*validate.FieldPathElement) {
+	if !strings.HasPrefix(name, "csv") {
+		if len(data) > c.limits.MaxFileBytes {
+			c.add(path, "file_size", fmt.Sprintf("must be at most %d bytes", c.limits.MaxFileBytes))
+		}
+		return
+	}
+	if len(data) > c.limits.MaxCSVBytes {
+		c.add(path, "csv_size", fmt.Sprintf("must be at most %d bytes", c.limits.MaxCSVBytes))
+		return
+	}
+	rows := bytes.Count(data, []byte{'\n'})
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		rows++
+	}
+	if rows > c.limits.MaxCSVRows {
+		c.add(path, "csv_rows", fmt.Sprintf("must have at most %d rows", c.limits.MaxCSVRows))
+	}
+}
+
+func (c *checker) timestamp(ts protoreflect.Message, path []*validate.FieldPathElement) {
+	t, ok := timestampValue(ts)
+	if !ok {
+		return
+	}
+	if !c.inBounds(t) {
+		c.add(path, "timestamp", fmt.Sprintf("must be between %d and %d",
+			c.limits.EarliestTime.Year(), c.limits.LatestTime.Year()))
+	}
+}
+
+// timeRanges checks each start timestamp against its end, pairing fields by
+// name: start_time and end_time, period_start and period_end, and so on
+func (c *checker) timeRanges(msg protoreflect.Message, path []*validate.FieldPathElement) {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		startField := fields.Get(i)
+		name := string(startField.Name())
+		if !strings.Contains(name, "start") || !isTimestamp(startField) {
+			continue
+		}
+		endField := fields.ByName(protoreflect.Name(strings.Replace(name, "start", "end", 1)))
+		if endField == nil || !isTimestamp(endField) || !msg.Has(startField) || !msg.Has(endField) {
+			continue
+		}
+		start, okStart := timestampValue(msg.Get(startField).Message())
+		end, okEnd := timestampValue(msg.Get(endField).Message())
+		if !okStart || !okEnd || !c.inBounds(start) || !c.inBounds(end) {
+			continue // Already reported
+		}
+		endPath := append(path[:len(path):len(path)], element(endField, nil))
+		switch {
+		case end.Before(start):
+			c.add(endPath, "time_range.order", fmt.Sprintf("must not be before %s", name))
+		case end.Sub(start) > c.limits.MaxTimeRange:
+			c.add(endPath, "time_range.span", fmt.Sprintf("must be within %d days of %s", int(c.limits.MaxTimeRange.Hours()/24), name))
+		}
+	}
+}
+
+func (c *checker) inBounds(t time.Time) bool {
+	return !t.Before(c.limits.EarliestTime) && t.Before(c.limits.LatestTime)
+}
+
+func (c *checker) add(path []*validate.FieldPathElement, rule, message string) {
+	c.violations = append(c.violations, &protovalidate.Violation{
+		Proto: validate.Violation_builder{
+			Field:   validate.FieldPath_builder{Elements: append([]*validate.FieldPathElement(nil), path...)}.Build(),
+			RuleId:  proto.String("echo." + rule),
+			Message: proto.String(message),
+		}.Build(),
+	})
+}
+
+func isTimestamp(fd protoreflect.FieldDescriptor) bool {
+	return !fd.IsList() && fd.Message() != nil && fd.Message().FullName() == timestampName
+}
+
+// timestampValue reads a google.protobuf.Timestamp without depending on its Go type
+func timestampValue(ts protoreflect.Message) (time.Time, bool) {
+	fields := ts.Descriptor().Fields()
+	seconds, nanos := fields.ByName("seconds"), fields.ByName("nanos")
+	if seconds == nil || nanos == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(ts.Get(seconds).Int(), ts.Get(nanos).Int()).UTC(), true
+}
+
+// element is fd's entry in a violation's field path; index is set for list items
+func element(fd protoreflect.FieldDescriptor, index *int) *validate.FieldPathElement {
+	b := validate.FieldPathElement_builder{
+		FieldNumber: proto.Int32(int32(fd.Number())),
+		FieldName:   proto.String(string(fd.Name())),
+		FieldType:   descriptorpb.FieldDescriptorProto_Type(fd.Kind()).Enum(),
+	}
+	if index != nil {
+		b.Index = proto.Uint64(uint64(*index))
+	}
+	return b.Build()
+}
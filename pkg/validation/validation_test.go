@@ -0,0 +1,93 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	echov1 "buf.build/gen/go/echo-tracker/echo/protocolbuffers/go/echo/v1"
+	"buf.build/go/protovalidate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCheck(t *testing.T) {
+	limits := DefaultLimits()
+	limits.MaxCSVRows = 3
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		msg   proto.Message
+		field string
+		rule  string
+	}{
+		{
+			name: "valid",
+			msg: &echov1.ComputePlanActualsRequest{
+				StartDate: timestamppb.New(day),
+				EndDate:   timestamppb.New(day.AddDate(0, 1, 0)),
+			},
+		},
+		{
+			name:  "lowercase currency",
+			msg:   &echov1.CreatePlanRequest{Name: "2026", CurrencyCode: "eur"},
+			field: "currency_code",
+			rule:  "echo.currency_code",
+		},
+		{
+			name:  "amount out of bounds",
+			msg:   &echov1.SetOpeningBalanceRequest{AmountMinor: 1 << 62, CurrencyCode: "EUR"},
+			field: "amount_minor",
+			rule:  "echo.amount_minor",
+		},
+		{
+			name: "range ending before it starts",
+			msg: &echov1.ComputePlanActualsRequest{
+				StartDate: timestamppb.New(day),
+				EndDate:   timestamppb.New(day.AddDate(0, 0, -1)),
+			},
+			field: "end_date",
+			rule:  "echo.time_range.order",
+		},
+		{
+			name: "nested range from year one",
+			msg: &echov1.ListTransactionsRequest{TimeRange: &echov1.TimeRange{
+				StartTime: timestamppb.New(time.Time{}),
+				EndTime:   timestamppb.New(day),
+			}},
+			field: "time_range.start_time",
+			rule:  "echo.timestamp",
+		},
+		{
+			name:  "oversized page",
+			msg:   &echov1.ListPlansRequest{Limit: 5000},
+			field: "limit",
+			rule:  "echo.page_size",
+		},
+		{
+			name:  "too many CSV rows",
+			msg:   &echov1.ImportTransactionsCsvRequest{CsvBytes: []byte(strings.Repeat("2026-03-01,Coffee,-2.00\n", 4))},
+			field: "csv_bytes",
+			rule:  "echo.csv_rows",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.msg, limits)
+			if tt.rule == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *protovalidate.ValidationError
+			require.True(t, errors.As(err, &validationErr), "got %v", err)
+			require.Len(t, validationErr.Violations, 1)
+			violation := validationErr.Violations[0].Proto
+			assert.Equal(t, tt.field, protovalidate.FieldPathString(violation.GetField()))
+			assert.Equal(t, tt.rule, violation.GetRuleId())
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package webhook posts signed JSON events to user-configured URLs
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// RequestTimeout for webhook requests
+	RequestTimeout = 10 * time.Second
+
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>" of
+	// "<timestamp>.<body>" keyed with the webhook secret
+	SignatureHeader = "X-Echo-Signature"
+	// EventHeader carries the event type
+	EventHeader = "X-Echo-Event"
+)
+
+// Event is the JSON body posted to a webhook
+type Event struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"` // e.g. "notification.alert"
+	CreatedAt time.Time      `json:"created_at"`
+	Data      map[string]any `json:"data"`
+}
+
+// ErrBlockedAddress is returned for webhook URLs on loopback, private,
+// link-local or unspecified addresses, checked again after DNS resolution so
+// endpoints can't reach the server's own network
+var ErrBlockedAddress = errors.New("webhook URL must not point to a loopback, private or link-local address")
+
+// StatusError is returned when the endpoint answers outside 2xx
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook returned status %d", e.StatusCode)
+}
+
+// Service delivers webhook events
+type Service struct {
+	client *http.Client
+}
+
+// NewService creates a new webhook sender. It dials public addresses only and
+// doesn't follow redirects, which could point back into the internal network.
+func NewService() *Service {
+	dialer := &net.Dialer{Timeout: RequestTimeout, Control: refuseBlockedAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would dial the endpoint past the address check
+	transport.DialContext = dialer.DialContext
+	return &Service{client: &http.Client{
+		Timeout:   RequestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// refuseBlockedAddress is a net.Dialer Control that refuses connections to
+// blocked addresses, after the host name has been resolved
+func refuseBlockedAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// blockedIP reports whether ip is on the server's own or a private network
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// Send posts the event to targetURL, signed with secret when one is set.
+// Any status outside 2xx is a *StatusError.
+func (s *Service) Send(ctx context.Context, targetURL, secret string, event *Event) error {
+	if err := ValidateURL(targetURL); err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body sent at t
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateURL accepts absolute https URLs only, refusing localhost and blocked
+// IP addresses up front. Host names resolving to blocked addresses are refused
+// when Send dials them.
+func ValidateURL(targetURL string) error {
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("webhook URL must be an absolute https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && blockedIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
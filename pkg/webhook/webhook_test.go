@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		blocked bool
+		invalid bool
+	}{
+		{url: "https://hooks.example.com/echo"},
+		{url: "https://203.0.113.10/hook"},
+		{url: "http://hooks.example.com/echo", invalid: true},
+		{url: "/hook", invalid: true},
+		{url: "https://127.0.0.1/hook", blocked: true},
+		{url: "https://localhost:8443/hook", blocked: true},
+		{url: "https://api.localhost/hook", blocked: true},
+		{url: "https://10.0.3.7/hook", blocked: true},
+		{url: "https://192.168.1.1/hook", blocked: true},
+		{url: "https://169.254.169.254/latest/meta-data", blocked: true},
+		{url: "https://0.0.0.0/hook", blocked: true},
+		{url: "https://[::1]/hook", blocked: true},
+		{url: "https://[fd00::1]/hook", blocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateURL(tt.url)
+			switch {
+			case tt.blocked:
+				assert.ErrorIs(t, err, ErrBlockedAddress)
+			case tt.invalid:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSend_RefusesBlockedAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	s := NewService()
+
+	err := s.Send(context.Background(), server.URL, "secret", &Event{ID: "evt", Type: "test.ping"})
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+
+	// A host name that resolves to a blocked address gets past ValidateURL, so
+	// the dialer refuses it after resolution
+	_, err = s.client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Zero(t, hits.Load(), "the endpoint was reached")
+}
+
+func TestSend_DoesNotFollowRedirects(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Redirect(w, r, "https://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// Reach the test server past the address check, keeping the redirect policy
+	client := *NewService().client
+	client.Transport = server.Client().Transport
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, int32(1), hits.Load())
+}
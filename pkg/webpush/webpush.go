@@ -0,0 +1,222 @@
+// Package webpush sends browser push notifications using the Web Push
+// protocol (RFC 8030), with message encryption (RFC 8291) and VAPID (RFC 8292)
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// RequestTimeout for push service requests
+	RequestTimeout = 10 * time.Second
+	// DefaultTTL is how long push services keep an undelivered message
+	DefaultTTL = 24 * time.Hour
+
+	// recordSize is the aes128gcm record size; messages fit in a single record
+	recordSize = 4096
+	// vapidExpiry is the lifetime of VAPID tokens (at most 24 hours per RFC 8292)
+	vapidExpiry = 12 * time.Hour
+)
+
+var (
+	// ErrSubscriptionGone is returned when the push service no longer knows the
+	// subscription; it should be deleted
+	ErrSubscriptionGone = errors.New("web push subscription expired or unsubscribed")
+	// ErrPayloadTooLarge is returned for messages that don't fit in one record
+	ErrPayloadTooLarge = errors.New("web push payload too large")
+)
+
+// Subscription is a browser's PushSubscription
+type Subscription struct {
+	Endpoint string
+	P256dh   string // Base64url user agent public key
+	Auth     string // Base64url authentication secret
+}
+
+// Message is the JSON payload delivered to the service worker
+type Message struct {
+	Title string         `json:"title"`
+	Body  string         `json:"body"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+// Service sends Web Push messages signed with the application's VAPID key
+type Service struct {
+	client    *http.Client
+	key       *ecdsa.PrivateKey
+	publicKey string // Base64url uncompressed public key, sent with each request
+	subject   string // Contact for push services, "mailto:" or "https:"
+}
+
+// NewService creates a Web Push sender from a base64url VAPID private key
+// (the raw 32-byte P-256 scalar) and a contact subject
+func NewService(vapidPrivateKey, subject string) (*Service, error) {
+	raw, err := decodeBase64(vapidPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse VAPID private key: %w", err)
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode VAPID public key: %w", err)
+	}
+	return &Service{
+		client:    &http.Client{Timeout: RequestTimeout},
+		key:       key,
+		publicKey: base64.RawURLEncoding.EncodeToString(pub),
+		subject:   subject,
+	}, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with (applicationServerKey)
+func (s *Service) PublicKey() string {
+	return s.publicKey
+}
+
+// Send encrypts msg for the subscription and posts it to its push service.
+// Returns ErrSubscriptionGone when the subscription should be removed.
+func (s *Service) Send(ctx context.Context, sub Subscription, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal web push message: %w", err)
+	}
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" {
+		return fmt.Errorf("invalid web push endpoint %q", sub.Endpoint)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(vapidExpiry).Unix(),
+		"sub": s.subject,
+	}).SignedString(s.key)
+	if err != nil {
+		return fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create web push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", fmt.Sprintf("%d", int(DefaultTTL.Seconds())))
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, s.publicKey))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send web push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("web push service returned status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// encrypt encodes payload as a single aes128gcm record for the subscription (RFC 8291)
+func encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	uaPublic, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subscription key: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subscription auth secret: %w", err)
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+
+	// A fresh key pair and salt for every message
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate web push key: %w", err)
+	}
+	asPublic := asKey.PublicKey().Bytes()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate web push salt: %w", err)
+	}
+
+	shared, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive web push secret: %w", err)
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header (salt, record size, key ID) + payload + last-record delimiter + tag
+	if len(payload)+1+gcm.Overhead() > recordSize {
+		return nil, ErrPayloadTooLarge
+	}
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decodeBase64 accepts the padded and unpadded base64url keys browsers produce
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
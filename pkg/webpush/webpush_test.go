@@ -0,0 +1,127 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decrypt reverses encrypt with the user agent's keys, as a browser would
+func decrypt(t *testing.T, uaKey *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+	salt, rs, idLen := body[:16], binary.BigEndian.Uint32(body[16:20]), int(body[20])
+	if rs != recordSize {
+		t.Fatalf("record size = %d, want %d", rs, recordSize)
+	}
+	asPublic := body[21 : 21+idLen]
+	ciphertext := body[21+idLen:]
+
+	asKey, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		t.Fatalf("invalid key ID: %v", err)
+	}
+	shared, err := uaKey.ECDH(asKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ikm, _ := hkdf.Key(sha256.New, shared, authSecret, "WebPush: info\x00"+string(uaKey.PublicKey().Bytes())+string(asPublic), 32)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("missing last-record delimiter")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+func TestSend_EncryptsForSubscription(t *testing.T) {
+	uaKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	authSecret := make([]byte, 16)
+	_, _ = rand.Read(authSecret)
+
+	vapidKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	raw, _ := vapidKey.Bytes()
+	svc, err := NewService(base64.RawURLEncoding.EncodeToString(raw), "mailto:ops@example.com")
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	var body []byte
+	var authorization string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" {
+			t.Errorf("Content-Encoding = %q", r.Header.Get("Content-Encoding"))
+		}
+		authorization = r.Header.Get("Authorization")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	svc.client = srv.Client()
+
+	sub := Subscription{
+		Endpoint: srv.URL + "/push/abc",
+		P256dh:   base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(authSecret),
+	}
+	if err := svc.Send(context.Background(), sub, &Message{Title: "Budget exceeded", Body: "Groceries went €12 over"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if !strings.HasPrefix(authorization, "vapid t=") || !strings.HasSuffix(authorization, "k="+svc.PublicKey()) {
+		t.Errorf("Authorization = %q", authorization)
+	}
+
+	var got Message
+	if err := json.Unmarshal(decrypt(t, uaKey, authSecret, body), &got); err != nil {
+		t.Fatalf("payload isn't JSON: %v", err)
+	}
+	if got.Title != "Budget exceeded" || got.Body != "Groceries went €12 over" {
+		t.Errorf("payload = %+v", got)
+	}
+}
+
+func TestSend_ReportsGoneSubscription(t *testing.T) {
+	uaKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	vapidKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	raw, _ := vapidKey.Bytes()
+	svc, err := NewService(base64.RawURLEncoding.EncodeToString(raw), "mailto:ops@example.com")
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+	svc.client = srv.Client()
+
+	err = svc.Send(context.Background(), Subscription{
+		Endpoint: srv.URL,
+		P256dh:   base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+	}, &Message{Title: "Hi"})
+	if err != ErrSubscriptionGone {
+		t.Errorf("err = %v, want ErrSubscriptionGone", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package workqueue runs background work in priority classes, each with its
+// own workers, so a large backfill can't hold up work a user is waiting on.
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+)
+
+// Class is a task's priority class; each class gets its own workers.
+type Class string
+
+const (
+	// Interactive is work a user just triggered and is waiting to see, like
+	// insights recomputed after an import or a plan pushed to its sheet
+	Interactive Class = "interactive"
+	// Scheduled is recurring work like digests and reminders
+	Scheduled Class = "scheduled"
+	// Backfill is bulk work over every user's history, like detection sweeps
+	Backfill Class = "backfill"
+)
+
+// Pool sizes a class: its workers and how many tasks may wait for one.
+type Pool struct {
+	Workers int
+	Backlog int
+}
+
+// DefaultPools gives interactive work the most workers and backfill a single
+// one, so backfill only ever takes one connection's worth of load.
+func DefaultPools() map[Class]Pool {
+	return map[Class]Pool{
+		Interactive: {Workers: 8, Backlog: 256},
+		Scheduled:   {Workers: 4, Backlog: 64},
+		Backfill:    {Workers: 1, Backlog: 64},
+	}
+}
+
+var (
+	// ErrQueueFull is returned when a class's backlog is full
+	ErrQueueFull = errors.New("work queue is full")
+	// ErrStopped is returned once the queue is stopping
+	ErrStopped = errors.New("work queue is stopped")
+)
+
+// Task is a unit of background work. Run logs its own failures; the queue
+// only counts them.
+type Task struct {
+	Name    string
+	Class   Class         // Defaults to Scheduled
+	Timeout time.Duration // Zero leaves the run unbounded
+	Run     func(ctx context.Context) error
+}
+
+// queued is a task waiting for a worker
+type queued struct {
+	task     Task
+	enqueued time.Time
+}
+
+// Queue runs tasks on per-class worker pools. A nil *Queue runs each task in
+// its own goroutine, with no priorities or limits.
+type Queue struct {
+	logger  *slog.Logger
+	classes map[Class]chan queued
+
+	ctx    context.Context // Cancelled when Stop gives up waiting
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// New starts a queue with the given pools. Classes missing from pools get
+// their DefaultPools size.
+func New(pools map[Class]Pool, logger *slog.Logger) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		logger:  logger,
+		classes: make(map[Class]chan queued),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for class, pool := range DefaultPools() {
+		if p, ok := pools[class]; ok {
+			if p.Workers > 0 {
+				pool.Workers = p.Workers
+			}
+			if p.Backlog > 0 {
+				pool.Backlog = p.Backlog
+			}
+		}
+		tasks := make(chan queued, pool.Backlog)
+		q.classes[class] = tasks
+		for range pool.Workers {
+			q.wg.Add(1)
+			go q.work(class, tasks)
+		}
+	}
+	return q
+}
+
+// Submit queues a task without blocking. It returns ErrQueueFull when the
+// task's class has no room left, rather than stalling the caller.
+func (q *Queue) Submit(task Task) error {
+	if task.Run == nil {
+		return fmt.Errorf("task %q has no run function", task.Name)
+	}
+	if task.Class == "" {
+		task.Class = Scheduled
+	}
+	if q == nil {
+		go func() {
+			ctx, cancel := q.taskContext(context.Background(), task)
+			defer cancel()
+			_ = task.Run(ctx)
+		}()
+		return nil
+	}
+
+	tasks, ok := q.classes[task.Class]
+	if !ok {
+		return fmt.Errorf("unknown work queue class %q", task.Class)
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		observability.WorkQueueRejectedTotal.WithLabelValues(string(task.Class)).Inc()
+		return ErrStopped
+	}
+	select {
+	case tasks <- queued{task: task, enqueued: time.Now()}:
+		observability.WorkQueueDepth.WithLabelValues(string(task.Class)).Inc()
+		return nil
+	default:
+		observability.WorkQueueRejectedTotal.WithLabelValues(string(task.Class)).Inc()
+		return ErrQueueFull
+	}
+}
+
+// Stop stops taking tasks and waits for queued and running ones to finish.
+// If ctx ends first, running tasks are cancelled and ctx's error returned.
+func (q *Queue) Stop(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		for _, tasks := range q.classes {
+			close(tasks)
+		}
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ctx.Err()
+	}
+}
+
+// work runs a class's tasks until the queue stops
+func (q *Queue) work(class Class, tasks <-chan queued) {
+	defer q.wg.Done()
+	for item := range tasks {
+		observability.WorkQueueDepth.WithLabelValues(string(class)).Dec()
+		observability.WorkQueueWait.WithLabelValues(string(class)).Observe(time.Since(item.enqueued).Seconds())
+		q.run(item.task)
+	}
+}
+
+// run runs one task, recording how long it took and how it ended
+func (q *Queue) run(task Task) {
+	ctx, cancel := q.taskContext(q.ctx, task)
+	defer cancel()
+
+	start := time.Now()
+	status := "ok"
+	defer func() {
+		if r := recover(); r != nil {
+			status = "panicked"
+			q.logger.Error("background task panicked",
+				slog.String("task", task.Name),
+				slog.String("class", string(task.Class)),
+				slog.Any("panic", r),
+			)
+		}
+		observability.WorkQueueRunDuration.WithLabelValues(string(task.Class), status).Observe(time.Since(start).Seconds())
+	}()
+
+	if err := task.Run(ctx); err != nil {
+		status = "failed"
+	}
+}
+
+// taskContext bounds ctx by the task's timeout, if it has one
+func (q *Queue) taskContext(ctx context.Context, task Task) (context.Context, context.CancelFunc) {
+	if task.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, task.Timeout)
+}
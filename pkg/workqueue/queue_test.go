@@ -0,0 +1,166 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/observability"
+)
+
+func newTestQueue(t *testing.T, pools map[Class]Pool) *Queue {
+	t.Helper()
+	q := New(pools, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { _ = q.Stop(context.Background()) })
+	return q
+}
+
+// block returns a task run that holds its worker until release is closed
+func block(started chan<- struct{}, release <-chan struct{}) func(context.Context) error {
+	return func(ctx context.Context) error {
+		started <- struct{}{}
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}
+}
+
+func TestQueue_BackfillDoesNotDelayInteractive(t *testing.T) {
+	q := newTestQueue(t, map[Class]Pool{Backfill: {Workers: 1, Backlog: 8}})
+
+	started := make(chan struct{}, 8)
+	release := make(chan struct{})
+	defer close(release)
+	for range 4 {
+		require.NoError(t, q.Submit(Task{Name: "backfill", Class: Backfill, Run: block(started, release)}))
+	}
+	<-started // The only backfill worker is now busy, with three tasks behind it
+
+	done := make(chan struct{})
+	require.NoError(t, q.Submit(Task{Name: "recompute", Class: Interactive, Run: func(context.Context) error {
+		close(done)
+		return nil
+	}}))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("interactive task waited behind backfill")
+	}
+}
+
+func TestQueue_RejectsWhenClassIsFull(t *testing.T) {
+	q := newTestQueue(t, map[Class]Pool{Backfill: {Workers: 1, Backlog: 1}})
+	rejected := testutil.ToFloat64(observability.WorkQueueRejectedTotal.WithLabelValues(string(Backfill)))
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, q.Submit(Task{Name: "running", Class: Backfill, Run: block(started, release)}))
+	<-started
+	require.NoError(t, q.Submit(Task{Name: "waiting", Class: Backfill, Run: block(started, release)}))
+
+	err := q.Submit(Task{Name: "overflow", Class: Backfill, Run: block(started, release)})
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.Equal(t, rejected+1, testutil.ToFloat64(observability.WorkQueueRejectedTotal.WithLabelValues(string(Backfill))))
+
+	// A full backfill class leaves the others open
+	assert.NoError(t, q.Submit(Task{Name: "digest", Run: func(context.Context) error { return nil }}))
+}
+
+// runs counts a class's finished runs with the given status
+func runs(t *testing.T, class Class, status string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	observer := observability.WorkQueueRunDuration.WithLabelValues(string(class), status)
+	require.NoError(t, observer.(prometheus.Histogram).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestQueue_RecordsOutcomes(t *testing.T) {
+	q := newTestQueue(t, nil)
+	before := map[string]uint64{}
+	for _, status := range []string{"ok", "failed", "panicked"} {
+		before[status] = runs(t, Interactive, status)
+	}
+
+	require.NoError(t, q.Submit(Task{Name: "ok", Class: Interactive, Run: func(context.Context) error { return nil }}))
+	require.NoError(t, q.Submit(Task{Name: "fails", Class: Interactive, Run: func(context.Context) error { return errors.New("boom") }}))
+	require.NoError(t, q.Submit(Task{Name: "panics", Class: Interactive, Run: func(context.Context) error { panic("boom") }}))
+	require.NoError(t, q.Stop(context.Background()))
+
+	for status, n := range before {
+		assert.Equal(t, n+1, runs(t, Interactive, status), status)
+	}
+}
+
+func TestQueue_StopDrainsThenRejects(t *testing.T) {
+	q := newTestQueue(t, map[Class]Pool{Scheduled: {Workers: 1, Backlog: 8}})
+
+	ran := make(chan string, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, q.Submit(Task{Name: name, Run: func(context.Context) error {
+			ran <- name
+			return nil
+		}}))
+	}
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Len(t, ran, 3)
+
+	err := q.Submit(Task{Name: "late", Run: func(context.Context) error { return nil }})
+	assert.ErrorIs(t, err, ErrStopped)
+}
+
+func TestQueue_StopCancelsRunningTasksOnTimeout(t *testing.T) {
+	q := newTestQueue(t, nil)
+
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{})
+	require.NoError(t, q.Submit(Task{Name: "stuck", Class: Backfill, Run: func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Stop(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("running task was not cancelled")
+	}
+}
+
+func TestQueue_NilRunsInBackground(t *testing.T) {
+	var q *Queue
+
+	done := make(chan struct{})
+	require.NoError(t, q.Submit(Task{Name: "detached", Class: Interactive, Run: func(context.Context) error {
+		close(done)
+		return nil
+	}}))
+	<-done
+	assert.NoError(t, q.Stop(context.Background()))
+}
+
+func TestQueue_ValidatesTasks(t *testing.T) {
+	q := newTestQueue(t, nil)
+
+	assert.Error(t, q.Submit(Task{Name: "no-run"}))
+	assert.Error(t, q.Submit(Task{Name: "odd", Class: "urgent", Run: func(context.Context) error { return nil }}))
+}
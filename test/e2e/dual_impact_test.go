@@ -0,0 +1,250 @@
+//go:build integration
+
+package e2etest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/FACorreiaa/smart-finance-tracker/cmd/api"
+	goalsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+	importservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/import/service"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/config"
+	"github.com/FACorreiaa/smart-finance-tracker/pkg/cron"
+)
+
+// newTestDependencies wires the application against TEST_DATABASE_URL the same
+// way the server does, with migrations applied and the scheduler off so jobs
+// only run when a test calls them.
+func newTestDependencies(t *testing.T) *api.Dependencies {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	u, err := url.Parse(dbURL)
+	require.NoError(t, err, "failed to parse TEST_DATABASE_URL")
+
+	port := 5432
+	if p := u.Port(); p != "" {
+		port, err = strconv.Atoi(p)
+		require.NoError(t, err)
+	}
+	password, _ := u.User.Password()
+	sslMode := u.Query().Get("sslmode")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{
+			Host:     u.Hostname(),
+			Port:     port,
+			User:     u.User.Username(),
+			Password: password,
+			Database: strings.TrimPrefix(u.Path, "/"),
+			SSLMode:  sslMode,
+		},
+		Auth:      config.AuthConfig{JWTSecret: "integration-test"},
+		Server:    config.ServerConfig{BaseURL: "http://localhost:8080"},
+		Scheduler: config.SchedulerConfig{Enabled: false},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	deps, err := api.InitDependencies(cfg, logger)
+	require.NoError(t, err, "failed to init dependencies")
+	t.Cleanup(deps.Cleanup)
+	return deps
+}
+
+// seedUser creates a user with the given categories and returns their IDs by
+// name. Deleting the user at cleanup cascades to everything the flow creates.
+func seedUser(t *testing.T, pool *pgxpool.Pool, categories ...string) (uuid.UUID, map[string]uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+
+	var userID uuid.UUID
+	email := fmt.Sprintf("dual-impact-%s@example.com", uuid.NewString())
+	err := pool.QueryRow(ctx, `INSERT INTO users (email) VALUES ($1) RETURNING id`, email).Scan(&userID)
+	require.NoError(t, err, "failed to seed user")
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	ids := make(map[string]uuid.UUID, len(categories))
+	for _, name := range categories {
+		var id uuid.UUID
+		err := pool.QueryRow(ctx,
+			`INSERT INTO categories (user_id, name) VALUES ($1, $2) RETURNING id`, userID, name,
+		).Scan(&id)
+		require.NoError(t, err, "failed to seed category %s", name)
+		ids[name] = id
+	}
+	return userID, ids
+}
+
+func countRows(t *testing.T, pool *pgxpool.Pool, query string, args ...any) int {
+	t.Helper()
+	var n int
+	require.NoError(t, pool.QueryRow(context.Background(), query, args...).Scan(&n))
+	return n
+}
+
+// TestDualImpactFlow runs a statement through every domain that reacts to new
+// transactions: import → categorization → plan actuals → goal-linked plan
+// items → subscription detection → alerts, then checks the rows each step
+// left behind. New cross-domain side effects should extend this scenario.
+//
+// Run with: TEST_DATABASE_URL=postgres://... go test -tags integration ./test/e2e/
+func TestDualImpactFlow(t *testing.T) {
+	deps := newTestDependencies(t)
+	pool := deps.DB.Pool
+	ctx := context.Background()
+
+	userID, categoryIDs := seedUser(t, pool, "Groceries", "Streaming")
+
+	// Categorization: rules assign categories and clean merchant names on import
+	_, _, err := deps.CategorizationService.CreateRule(ctx, userID, "NETFLIX", "Netflix", ptr(categoryIDs["Streaming"]), true, false)
+	require.NoError(t, err)
+	_, _, err = deps.CategorizationService.CreateRule(ctx, userID, "PINGO DOCE", "Pingo Doce", ptr(categoryIDs["Groceries"]), false, false)
+	require.NoError(t, err)
+
+	// Four monthly Netflix charges and one grocery shop this month that blows
+	// the groceries budget
+	today := time.Now().UTC()
+	var csv strings.Builder
+	csv.WriteString("Date,Description,Amount\n")
+	for months := 3; months >= 0; months-- {
+		fmt.Fprintf(&csv, "%s,NETFLIX.COM 866-579-7172,-15.99\n", today.AddDate(0, -months, 0).Format("2006-01-02"))
+	}
+	fmt.Fprintf(&csv, "%s,PINGO DOCE LISBOA,-250.00\n", today.Format("2006-01-02"))
+
+	// 1. Import
+	result, err := deps.ImportService.ImportWithMapping(ctx, userID, nil, []byte(csv.String()), importservice.ColumnMapping{
+		DateCol:     0,
+		DescCol:     1,
+		CategoryCol: -1,
+		AmountCol:   2,
+		DebitCol:    -1,
+		CreditCol:   -1,
+		DateFormat:  "2006-01-02",
+		Delimiter:   ',',
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.RowsImported)
+	assert.Zero(t, result.RowsFailed)
+
+	// 2. Categorization
+	assert.Equal(t, 4, countRows(t, pool,
+		`SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND category_id = $2 AND merchant_name = 'Netflix'`,
+		userID, categoryIDs["Streaming"]))
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM transactions WHERE user_id = $1 AND category_id = $2 AND amount_minor = -25000`,
+		userID, categoryIDs["Groceries"]))
+
+	// 3. Plan actuals
+	plan, err := deps.PlanService.CreatePlan(ctx, userID, &planservice.CreatePlanInput{
+		Name:         "Household",
+		CurrencyCode: "EUR",
+		CategoryGroups: []planservice.CreateCategoryGroupInput{
+			{Name: "Essentials", Categories: []planservice.CreateCategoryInput{
+				{Name: "Groceries", Items: []planservice.CreateItemInput{{Name: "Groceries", BudgetedMinor: 10000}}},
+			}},
+			{Name: "Subscriptions", Categories: []planservice.CreateCategoryInput{
+				{Name: "Streaming", Items: []planservice.CreateItemInput{{Name: "Streaming", BudgetedMinor: 1599}}},
+			}},
+			{Name: "Savings", Categories: []planservice.CreateCategoryInput{
+				{Name: "Savings", Items: []planservice.CreateItemInput{{Name: "Emergency fund", BudgetedMinor: 20000}}},
+			}},
+		},
+	})
+	require.NoError(t, err)
+	_, err = deps.PlanService.SetActivePlan(ctx, userID, plan.Plan.ID)
+	require.NoError(t, err)
+	itemIDs := make(map[string]uuid.UUID, len(plan.Items))
+	for _, item := range plan.Items {
+		itemIDs[item.Name] = item.ID
+	}
+
+	// 4. Goal contributions count as the linked item's actual. There is no
+	// automatic contribution from transactions yet, so contribute directly.
+	goal, err := deps.GoalsService.CreateGoal(ctx, userID, "Emergency fund", goalsrepo.GoalTypeSave, 500000, "EUR",
+		today.AddDate(0, -1, 0), today.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	_, err = deps.PlanService.LinkPlanItem(ctx, userID, plan.Plan.ID, itemIDs["Emergency fund"], planservice.ItemLink{GoalID: &goal.ID})
+	require.NoError(t, err)
+	_, _, err = deps.GoalsService.ContributeToGoal(ctx, userID, goal.ID, 7500, "EUR", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, deps.PlanService.ProcessTransaction(ctx, userID))
+
+	actuals := make(map[uuid.UUID]int64)
+	rows, err := pool.Query(ctx, `SELECT id, actual_minor FROM plan_items WHERE plan_id = $1`, plan.Plan.ID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id uuid.UUID
+		var actual int64
+		require.NoError(t, rows.Scan(&id, &actual))
+		actuals[id] = actual
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, int64(25000), actuals[itemIDs["Groceries"]])
+	assert.Equal(t, int64(1599), actuals[itemIDs["Streaming"]])
+	assert.Equal(t, int64(7500), actuals[itemIDs["Emergency fund"]])
+
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM goal_contributions WHERE goal_id = $1 AND amount_minor = 7500`, goal.ID))
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM goals WHERE id = $1 AND current_amount_minor = 7500`, goal.ID))
+
+	// 5. Subscription detection
+	detection, err := deps.SubscriptionsService.DetectSubscriptions(ctx, userID, today.AddDate(0, -6, 0), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, detection.NewCount)
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM recurring_subscriptions
+		 WHERE user_id = $1 AND merchant_name = 'Netflix' AND cadence = 'monthly' AND amount_minor = 1599`,
+		userID))
+
+	// 6. Alerts
+	budgetAlerts := cron.BudgetAlertsJob(deps.PlanRepo, deps.PlanService, deps.InsightsService, "", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, budgetAlerts.Run(ctx))
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM alerts
+		 WHERE user_id = $1 AND alert_type = 'budget_overspend' AND expires_at IS NOT NULL`,
+		userID))
+	// Evaluating again the same day doesn't repeat the alert
+	require.NoError(t, budgetAlerts.Run(ctx))
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM alerts WHERE user_id = $1 AND alert_type = 'budget_overspend'`, userID))
+
+	// Import side effects run in the background
+	assert.Eventually(t, func() bool {
+		return countRows(t, pool, `SELECT COUNT(*) FROM import_job_insights WHERE import_job_id = $1`, result.JobID) == 1
+	}, 10*time.Second, 100*time.Millisecond, "import insights were not stored")
+	assert.Equal(t, 1, countRows(t, pool,
+		`SELECT COUNT(*) FROM import_jobs WHERE id = $1 AND status = 'succeeded' AND rows_imported = 5`, result.JobID))
+	importInsights, err := deps.InsightsService.GetImportInsights(ctx, userID, result.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, importInsights.RowsTotal)
+	assert.Equal(t, 5, importInsights.RowsImported)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,157 @@
+//go:build integration
+
+package e2etest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goalsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/goals/repository"
+	planrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/repository"
+	planservice "github.com/FACorreiaa/smart-finance-tracker/internal/domain/plan/service"
+	subscriptionsrepo "github.com/FACorreiaa/smart-finance-tracker/internal/domain/subscriptions/repository"
+)
+
+// TestCrossUserAccessIsNotFound has one user reach for another user's goals,
+// subscriptions, item configs and plan items by ID. Every path must behave as
+// if the row didn't exist and leave it untouched.
+//
+// Run with: TEST_DATABASE_URL=postgres://... go test -tags integration ./test/e2e/
+func TestCrossUserAccessIsNotFound(t *testing.T) {
+	deps := newTestDependencies(t)
+	pool := deps.DB.Pool
+	ctx := context.Background()
+
+	owner, _ := seedUser(t, pool)
+	intruder, _ := seedUser(t, pool)
+	now := time.Now().UTC()
+
+	t.Run("goals", func(t *testing.T) {
+		goal, err := deps.GoalsService.CreateGoal(ctx, owner, "Holiday", goalsrepo.GoalTypeSave, 100000, "EUR",
+			now, now.AddDate(1, 0, 0))
+		require.NoError(t, err)
+
+		_, err = deps.GoalsService.GetGoal(ctx, intruder, goal.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = deps.GoalsService.GetGoalProgress(ctx, intruder, goal.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = deps.GoalsService.UpdateGoal(ctx, intruder, goal.ID, ptr("Mine now"), nil, nil, nil)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, _, err = deps.GoalsService.ContributeToGoal(ctx, intruder, goal.ID, 5000, "EUR", nil)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = deps.GoalsService.ImportGoalContributions(ctx, intruder, goal.ID, []byte("2025-01-01,50\n"))
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = deps.GoalsService.SetGoalTerm(ctx, intruder, goal.ID, nil)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, deps.GoalsService.DeleteGoal(ctx, intruder, goal.ID), sql.ErrNoRows)
+
+		// Repository paths the service doesn't reach directly
+		assert.ErrorIs(t, deps.GoalsRepo.AddContribution(ctx, intruder, &goalsrepo.GoalContribution{
+			GoalID: goal.ID, AmountMinor: 5000, CurrencyCode: "EUR",
+		}), sql.ErrNoRows)
+		assert.ErrorIs(t, deps.GoalsRepo.UpdateCurrentAmount(ctx, intruder, goal.ID, 0), sql.ErrNoRows)
+		contributions, err := deps.GoalsRepo.ListAllContributions(ctx, intruder, goal.ID)
+		require.NoError(t, err)
+		assert.Empty(t, contributions)
+
+		stored, err := deps.GoalsService.GetGoal(ctx, owner, goal.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Holiday", stored.Name)
+		assert.Zero(t, stored.CurrentAmountMinor)
+	})
+
+	t.Run("subscriptions", func(t *testing.T) {
+		sub := &subscriptionsrepo.RecurringSubscription{
+			UserID:       owner,
+			MerchantName: "Netflix",
+			AmountMinor:  1599,
+			CurrencyCode: "EUR",
+			Cadence:      subscriptionsrepo.RecurringCadenceMonthly,
+			Status:       subscriptionsrepo.RecurringStatusActive,
+		}
+		require.NoError(t, deps.SubscriptionsRepo.Create(ctx, sub))
+
+		_, err := deps.SubscriptionsService.GetSubscription(ctx, intruder, sub.ID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = deps.SubscriptionsService.UpdateStatus(ctx, intruder, sub.ID, subscriptionsrepo.RecurringStatusCanceled)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, deps.SubscriptionsRepo.IncrementOccurrence(ctx, intruder, sub.ID, now), sql.ErrNoRows)
+		assert.ErrorIs(t, deps.SubscriptionsRepo.Delete(ctx, intruder, sub.ID), sql.ErrNoRows)
+
+		stored, err := deps.SubscriptionsService.GetSubscription(ctx, owner, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, subscriptionsrepo.RecurringStatusActive, stored.Status)
+	})
+
+	t.Run("item configs", func(t *testing.T) {
+		config := &planrepo.ItemConfig{
+			UserID:    owner,
+			Label:     "Side hustle",
+			ShortCode: "SH",
+			Behavior:  planrepo.ItemBehaviorInflow,
+			TargetTab: planrepo.TargetTabIncome,
+			ColorHex:  "#00AA00",
+		}
+		require.NoError(t, deps.PlanService.CreateItemConfig(ctx, config))
+
+		found, err := deps.PlanService.GetItemConfigByID(ctx, intruder, config.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		hijack := *config
+		hijack.UserID = intruder
+		hijack.Label = "Mine now"
+		assert.ErrorIs(t, deps.PlanService.UpdateItemConfig(ctx, &hijack), sql.ErrNoRows)
+		assert.ErrorIs(t, deps.PlanService.DeleteItemConfig(ctx, intruder, config.ID), sql.ErrNoRows)
+
+		stored, err := deps.PlanService.GetItemConfigByID(ctx, owner, config.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, "Side hustle", stored.Label)
+	})
+
+	t.Run("plan items", func(t *testing.T) {
+		newPlan := func(userID uuid.UUID) *planservice.PlanWithDetails {
+			plan, err := deps.PlanService.CreatePlan(ctx, userID, &planservice.CreatePlanInput{
+				Name:         "Budget",
+				CurrencyCode: "EUR",
+				CategoryGroups: []planservice.CreateCategoryGroupInput{
+					{Name: "Essentials", Categories: []planservice.CreateCategoryInput{
+						{Name: "Groceries", Items: []planservice.CreateItemInput{{Name: "Groceries", BudgetedMinor: 10000}}},
+					}},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, plan.Items, 1)
+			return plan
+		}
+		ownerPlan := newPlan(owner)
+		intruderPlan := newPlan(intruder)
+		item := ownerPlan.Items[0]
+
+		details, err := deps.PlanService.GetPlanWithDetails(ctx, intruder, ownerPlan.Plan.ID)
+		require.NoError(t, err)
+		assert.Nil(t, details)
+		byTab, err := deps.PlanService.GetItemsByTab(ctx, intruder, ownerPlan.Plan.ID, planrepo.TargetTabBudgets)
+		require.NoError(t, err)
+		assert.Nil(t, byTab)
+
+		// Editing their own plan doesn't reach items of another plan
+		err = deps.PlanService.UpdatePlanItem(ctx, intruder, intruderPlan.Plan.ID, item.ID, 1)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, deps.PlanRepo.UpdatePlanItemActual(ctx, intruderPlan.Plan.ID, item.ID, 1), sql.ErrNoRows)
+
+		var budgeted, actual int64
+		require.NoError(t, pool.QueryRow(ctx,
+			`SELECT budgeted_minor, actual_minor FROM plan_items WHERE id = $1`, item.ID,
+		).Scan(&budgeted, &actual))
+		assert.Equal(t, int64(10000), budgeted)
+		assert.Zero(t, actual)
+	})
+}